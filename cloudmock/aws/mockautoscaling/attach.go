@@ -38,10 +38,90 @@ func (m *MockAutoscaling) AttachLoadBalancers(request *autoscaling.AttachLoadBal
 		return nil, fmt.Errorf("Group %q not found", name)
 	}
 
-	asg.LoadBalancerNames = request.LoadBalancerNames
+	for _, lb := range request.LoadBalancerNames {
+		if !containsString(asg.LoadBalancerNames, aws.StringValue(lb)) {
+			asg.LoadBalancerNames = append(asg.LoadBalancerNames, lb)
+		}
+	}
 	return &autoscaling.AttachLoadBalancersOutput{}, nil
 }
 
+func (m *MockAutoscaling) DetachLoadBalancers(request *autoscaling.DetachLoadBalancersInput) (*autoscaling.DetachLoadBalancersOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	glog.Infof("DetachLoadBalancers: %v", request)
+
+	name := *request.AutoScalingGroupName
+
+	asg := m.Groups[name]
+	if asg == nil {
+		return nil, fmt.Errorf("Group %q not found", name)
+	}
+
+	var remaining []*string
+	for _, lb := range asg.LoadBalancerNames {
+		if !containsString(request.LoadBalancerNames, aws.StringValue(lb)) {
+			remaining = append(remaining, lb)
+		}
+	}
+	asg.LoadBalancerNames = remaining
+	return &autoscaling.DetachLoadBalancersOutput{}, nil
+}
+
+func (m *MockAutoscaling) AttachLoadBalancerTargetGroups(request *autoscaling.AttachLoadBalancerTargetGroupsInput) (*autoscaling.AttachLoadBalancerTargetGroupsOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	glog.Infof("AttachLoadBalancerTargetGroups: %v", request)
+
+	name := *request.AutoScalingGroupName
+
+	asg := m.Groups[name]
+	if asg == nil {
+		return nil, fmt.Errorf("Group %q not found", name)
+	}
+
+	for _, arn := range request.TargetGroupARNs {
+		if !containsString(asg.TargetGroupARNs, aws.StringValue(arn)) {
+			asg.TargetGroupARNs = append(asg.TargetGroupARNs, arn)
+		}
+	}
+	return &autoscaling.AttachLoadBalancerTargetGroupsOutput{}, nil
+}
+
+func (m *MockAutoscaling) DetachLoadBalancerTargetGroups(request *autoscaling.DetachLoadBalancerTargetGroupsInput) (*autoscaling.DetachLoadBalancerTargetGroupsOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	glog.Infof("DetachLoadBalancerTargetGroups: %v", request)
+
+	name := *request.AutoScalingGroupName
+
+	asg := m.Groups[name]
+	if asg == nil {
+		return nil, fmt.Errorf("Group %q not found", name)
+	}
+
+	var remaining []*string
+	for _, arn := range asg.TargetGroupARNs {
+		if !containsString(request.TargetGroupARNs, aws.StringValue(arn)) {
+			remaining = append(remaining, arn)
+		}
+	}
+	asg.TargetGroupARNs = remaining
+	return &autoscaling.DetachLoadBalancerTargetGroupsOutput{}, nil
+}
+
+func containsString(values []*string, s string) bool {
+	for _, v := range values {
+		if aws.StringValue(v) == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *MockAutoscaling) AttachLoadBalancersWithContext(aws.Context, *autoscaling.AttachLoadBalancersInput, ...request.Option) (*autoscaling.AttachLoadBalancersOutput, error) {
 	glog.Fatalf("Not implemented")
 	return nil, nil
@@ -93,6 +93,30 @@ func (m *MockAutoscaling) CreateAutoScalingGroup(input *autoscaling.CreateAutoSc
 	return &autoscaling.CreateAutoScalingGroupOutput{}, nil
 }
 
+func (m *MockAutoscaling) UpdateAutoScalingGroup(input *autoscaling.UpdateAutoScalingGroupInput) (*autoscaling.UpdateAutoScalingGroupOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	glog.V(2).Infof("UpdateAutoScalingGroup %v", input)
+
+	g := m.Groups[aws.StringValue(input.AutoScalingGroupName)]
+	if g == nil {
+		return nil, fmt.Errorf("AutoScalingGroup not found")
+	}
+
+	if input.MinSize != nil {
+		g.MinSize = input.MinSize
+	}
+	if input.MaxSize != nil {
+		g.MaxSize = input.MaxSize
+	}
+	if input.DesiredCapacity != nil {
+		g.DesiredCapacity = input.DesiredCapacity
+	}
+
+	return &autoscaling.UpdateAutoScalingGroupOutput{}, nil
+}
+
 func (m *MockAutoscaling) EnableMetricsCollection(request *autoscaling.EnableMetricsCollectionInput) (*autoscaling.EnableMetricsCollectionOutput, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -32,10 +32,6 @@ func (m *MockAutoscaling) AttachInstancesRequest(*autoscaling.AttachInstancesInp
 	return nil, nil
 }
 
-func (m *MockAutoscaling) AttachLoadBalancerTargetGroups(*autoscaling.AttachLoadBalancerTargetGroupsInput) (*autoscaling.AttachLoadBalancerTargetGroupsOutput, error) {
-	glog.Fatalf("Not implemented")
-	return nil, nil
-}
 func (m *MockAutoscaling) AttachLoadBalancerTargetGroupsWithContext(aws.Context, *autoscaling.AttachLoadBalancerTargetGroupsInput, ...request.Option) (*autoscaling.AttachLoadBalancerTargetGroupsOutput, error) {
 	glog.Fatalf("Not implemented")
 	return nil, nil
@@ -398,10 +394,6 @@ func (m *MockAutoscaling) DetachInstancesRequest(*autoscaling.DetachInstancesInp
 	return nil, nil
 }
 
-func (m *MockAutoscaling) DetachLoadBalancerTargetGroups(*autoscaling.DetachLoadBalancerTargetGroupsInput) (*autoscaling.DetachLoadBalancerTargetGroupsOutput, error) {
-	glog.Fatalf("Not implemented")
-	return nil, nil
-}
 func (m *MockAutoscaling) DetachLoadBalancerTargetGroupsWithContext(aws.Context, *autoscaling.DetachLoadBalancerTargetGroupsInput, ...request.Option) (*autoscaling.DetachLoadBalancerTargetGroupsOutput, error) {
 	glog.Fatalf("Not implemented")
 	return nil, nil
@@ -411,10 +403,6 @@ func (m *MockAutoscaling) DetachLoadBalancerTargetGroupsRequest(*autoscaling.Det
 	return nil, nil
 }
 
-func (m *MockAutoscaling) DetachLoadBalancers(*autoscaling.DetachLoadBalancersInput) (*autoscaling.DetachLoadBalancersOutput, error) {
-	glog.Fatalf("Not implemented")
-	return nil, nil
-}
 func (m *MockAutoscaling) DetachLoadBalancersWithContext(aws.Context, *autoscaling.DetachLoadBalancersInput, ...request.Option) (*autoscaling.DetachLoadBalancersOutput, error) {
 	glog.Fatalf("Not implemented")
 	return nil, nil
@@ -624,10 +612,6 @@ func (m *MockAutoscaling) TerminateInstanceInAutoScalingGroupRequest(*autoscalin
 	return nil, nil
 }
 
-func (m *MockAutoscaling) UpdateAutoScalingGroup(*autoscaling.UpdateAutoScalingGroupInput) (*autoscaling.UpdateAutoScalingGroupOutput, error) {
-	glog.Fatalf("Not implemented")
-	return nil, nil
-}
 func (m *MockAutoscaling) UpdateAutoScalingGroupWithContext(aws.Context, *autoscaling.UpdateAutoScalingGroupInput, ...request.Option) (*autoscaling.UpdateAutoScalingGroupOutput, error) {
 	glog.Fatalf("Not implemented")
 	return nil, nil
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockec2
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+)
+
+// defaultAccountAttributes are the limits of a new AWS account, reported when AccountAttributes is not set
+var defaultAccountAttributes = map[string]string{
+	"max-instances":       "20",
+	"vpc-max-elastic-ips": "5",
+}
+
+func (m *MockEC2) DescribeAccountAttributesWithContext(aws.Context, *ec2.DescribeAccountAttributesInput, ...request.Option) (*ec2.DescribeAccountAttributesOutput, error) {
+	panic("Not implemented")
+	return nil, nil
+}
+
+func (m *MockEC2) DescribeAccountAttributesRequest(*ec2.DescribeAccountAttributesInput) (*request.Request, *ec2.DescribeAccountAttributesOutput) {
+	panic("Not implemented")
+	return nil, nil
+}
+
+func (m *MockEC2) DescribeAccountAttributes(request *ec2.DescribeAccountAttributesInput) (*ec2.DescribeAccountAttributesOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	glog.Infof("DescribeAccountAttributes: %v", request)
+
+	attributes := m.AccountAttributes
+	if attributes == nil {
+		attributes = defaultAccountAttributes
+	}
+
+	var names []string
+	if len(request.AttributeNames) != 0 {
+		names = aws.StringValueSlice(request.AttributeNames)
+	} else {
+		for name := range attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	response := &ec2.DescribeAccountAttributesOutput{}
+	for _, name := range names {
+		value, found := attributes[name]
+		if !found {
+			continue
+		}
+		response.AccountAttributes = append(response.AccountAttributes, &ec2.AccountAttribute{
+			AttributeName: s(name),
+			AttributeValues: []*ec2.AccountAttributeValue{
+				{AttributeValue: s(value)},
+			},
+		})
+	}
+
+	return response, nil
+}
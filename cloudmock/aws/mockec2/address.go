@@ -146,6 +146,13 @@ func (m *MockEC2) DescribeAddresses(request *ec2.DescribeAddressesInput) (*ec2.D
 					}
 				}
 
+			case "domain":
+				for _, v := range filter.Values {
+					if aws.StringValue(address.Domain) == *v {
+						match = true
+					}
+				}
+
 			default:
 				return nil, fmt.Errorf("unknown filter name: %q", *filter.Name)
 			}
@@ -31,6 +31,9 @@ type MockEC2 struct {
 
 	mutex sync.Mutex
 
+	// AccountAttributes are the account limits reported by DescribeAccountAttributes, or the defaults of a new account if nil
+	AccountAttributes map[string]string
+
 	addressNumber int
 	Addresses     map[string]*ec2.Address
 
@@ -801,19 +801,6 @@ func (m *MockEC2) DeregisterImageRequest(*ec2.DeregisterImageInput) (*request.Re
 	return nil, nil
 }
 
-func (m *MockEC2) DescribeAccountAttributes(*ec2.DescribeAccountAttributesInput) (*ec2.DescribeAccountAttributesOutput, error) {
-	panic("Not implemented")
-	return nil, nil
-}
-func (m *MockEC2) DescribeAccountAttributesWithContext(aws.Context, *ec2.DescribeAccountAttributesInput, ...request.Option) (*ec2.DescribeAccountAttributesOutput, error) {
-	panic("Not implemented")
-	return nil, nil
-}
-func (m *MockEC2) DescribeAccountAttributesRequest(*ec2.DescribeAccountAttributesInput) (*request.Request, *ec2.DescribeAccountAttributesOutput) {
-	panic("Not implemented")
-	return nil, nil
-}
-
 func (m *MockEC2) DescribeAggregateIdFormat(*ec2.DescribeAggregateIdFormatInput) (*ec2.DescribeAggregateIdFormatOutput, error) {
 	panic("Not implemented")
 	return nil, nil
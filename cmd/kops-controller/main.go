@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main // import "k8s.io/kops/cmd/kops-controller"
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops"
+	"k8s.io/kops/pkg/kopscontroller"
+	"k8s.io/kops/pkg/pki"
+)
+
+func main() {
+	gitVersion := ""
+	if kops.GitVersion != "" {
+		gitVersion = " (git-" + kops.GitVersion + ")"
+	}
+	fmt.Printf("kops-controller version %s%s\n", kops.Version, gitVersion)
+
+	var clusterName string
+	flag.StringVar(&clusterName, "cluster-name", clusterName, "Name of the cluster the controller is running in")
+	cloud := "aws"
+	flag.StringVar(&cloud, "cloud", cloud, "Cloud provider used to verify the identity of nodes; only aws is supported")
+	listen := ":3988"
+	flag.StringVar(&listen, "listen", listen, "Address to serve bootstrap requests on")
+	var tlsCertFile, tlsKeyFile string
+	flag.StringVar(&tlsCertFile, "tls-cert-file", tlsCertFile, "Path to the serving certificate")
+	flag.StringVar(&tlsKeyFile, "tls-private-key-file", tlsKeyFile, "Path to the private key of the serving certificate")
+	var caCertFile, caKeyFile string
+	flag.StringVar(&caCertFile, "ca-cert-file", caCertFile, "Path to the cluster certificate authority kubelet certificates are signed by")
+	flag.StringVar(&caKeyFile, "ca-key-file", caKeyFile, "Path to the private key of the cluster certificate authority")
+	certificateTTL := 365 * 24 * time.Hour
+	flag.DurationVar(&certificateTTL, "certificate-ttl", certificateTTL, "Lifetime of the kubelet certificates")
+	var kubeconfig string
+	flag.StringVar(&kubeconfig, "kubeconfig", kubeconfig, "Path to a kubeconfig; defaults to the in-cluster configuration")
+	namespace := "kube-system"
+	flag.StringVar(&namespace, "namespace", namespace, "Namespace holding the leader election lock")
+	labelInterval := 5 * time.Minute
+	flag.DurationVar(&labelInterval, "label-interval", labelInterval, "How often the leader applies the instance group labels to new nodes")
+
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	if clusterName == "" {
+		glog.Exitf("--cluster-name is required")
+	}
+	if cloud != "aws" {
+		glog.Exitf("cloud %q is not supported", cloud)
+	}
+
+	ca, err := readCertificate(caCertFile)
+	if err != nil {
+		glog.Exitf("%v", err)
+	}
+	caKey, err := readPrivateKey(caKeyFile)
+	if err != nil {
+		glog.Exitf("%v", err)
+	}
+
+	identifier, err := kopscontroller.NewAWSIdentifier(clusterName)
+	if err != nil {
+		glog.Exitf("%v", err)
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		glog.Exitf("error building kubernetes client configuration: %v", err)
+	}
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		glog.Exitf("error building kubernetes client: %v", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		glog.Exitf("error getting hostname: %v", err)
+	}
+
+	// every master serves bootstrap requests, but only the leader labels nodes
+	elector := &kopscontroller.LeaderElector{
+		Client:        k8sClient,
+		Namespace:     namespace,
+		Name:          "kops-controller-leader",
+		Identity:      hostname,
+		LeaseDuration: 30 * time.Second,
+		RenewDeadline: 20 * time.Second,
+		RetryPeriod:   5 * time.Second,
+	}
+	labeler := &kopscontroller.NodeLabeler{
+		Client:   k8sClient,
+		Source:   identifier,
+		Interval: labelInterval,
+	}
+	go elector.Run(make(chan struct{}), labeler.Run)
+
+	server := &kopscontroller.BootstrapServer{
+		Verifier:       identifier,
+		CA:             ca,
+		CAKey:          caKey,
+		CertificateTTL: certificateTTL,
+	}
+	glog.Infof("serving bootstrap requests on %s", listen)
+	glog.Exitf("%v", http.ListenAndServeTLS(listen, tlsCertFile, tlsKeyFile, server))
+}
+
+func readCertificate(p string) (*pki.Certificate, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading certificate %q: %v", p, err)
+	}
+	certificate, err := pki.ParsePEMCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing certificate %q: %v", p, err)
+	}
+	return certificate, nil
+}
+
+func readPrivateKey(p string) (*pki.PrivateKey, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading private key %q: %v", p, err)
+	}
+	key, err := pki.ParsePEMPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key %q: %v", p, err)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no private key found in %q", p)
+	}
+	return key, nil
+}
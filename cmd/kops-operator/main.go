@@ -0,0 +1,127 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main // import "k8s.io/kops/cmd/kops-operator"
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/operator"
+)
+
+func main() {
+	gitVersion := ""
+	if kops.GitVersion != "" {
+		gitVersion = " (git-" + kops.GitVersion + ")"
+	}
+	fmt.Printf("kops-operator version %s%s\n", kops.Version, gitVersion)
+
+	state := os.Getenv("KOPS_STATE_STORE")
+	flag.StringVar(&state, "state", state, "Location of the kops state store; defaults to KOPS_STATE_STORE")
+	var kubeconfig string
+	flag.StringVar(&kubeconfig, "kubeconfig", kubeconfig, "Path to the kubeconfig of the cluster holding the kops custom resources; defaults to the in-cluster configuration")
+	namespace := "kops"
+	flag.StringVar(&namespace, "namespace", namespace, "Namespace holding the kops custom resources")
+	resyncPeriod := 10 * time.Minute
+	flag.DurationVar(&resyncPeriod, "resync-period", resyncPeriod, "How often to reconcile every cluster, even when its custom resources have not changed")
+	rollingUpdate := false
+	flag.BoolVar(&rollingUpdate, "rolling-update", rollingUpdate, "Replace out of date instances after updating a cluster, as kops rolling-update cluster --yes does")
+	refreshImages := false
+	flag.BoolVar(&refreshImages, "refresh-images", refreshImages, "Set the image of instance groups with an imageAlias to the newest matching image, and replace their instances")
+	var maintenanceWindow string
+	flag.StringVar(&maintenanceWindow, "maintenance-window", maintenanceWindow, "When images may be refreshed, in UTC, e.g. \"Sat,Sun 02:00-06:00\"; defaults to any time")
+	user := "kops-operator"
+	flag.StringVar(&user, "user", user, "User to record in the audit log, and to check cluster locks against")
+	once := false
+	flag.BoolVar(&once, "once", once, "Reconcile every cluster once, then exit")
+
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	if state == "" {
+		glog.Exitf("--state is required")
+	}
+
+	var window *operator.MaintenanceWindow
+	if maintenanceWindow != "" {
+		w, err := operator.ParseMaintenanceWindow(maintenanceWindow)
+		if err != nil {
+			glog.Exitf("%v", err)
+		}
+		window = w
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		glog.Exitf("error building kubernetes client configuration: %v", err)
+	}
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		glog.Exitf("error building kubernetes client: %v", err)
+	}
+
+	factoryOptions := &util.FactoryOptions{}
+	factoryOptions.RegistryPath = state
+	f := util.NewFactory(factoryOptions)
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		glog.Exitf("%v", err)
+	}
+	auditLog, err := f.AuditLog()
+	if err != nil {
+		glog.Exitf("%v", err)
+	}
+	locker, err := f.ClusterLocker()
+	if err != nil {
+		glog.Exitf("%v", err)
+	}
+
+	source := &operator.CustomResourceSource{
+		Client:    k8sClient.CoreV1().RESTClient(),
+		Namespace: namespace,
+	}
+
+	audit.SetCurrentUser(user)
+	o := operator.NewOperator(clientset, source)
+	o.AuditLog = auditLog
+	o.Locker = locker
+	o.User = user
+	o.RollingUpdate = rollingUpdate
+	o.RefreshImages = refreshImages
+	o.MaintenanceWindow = window
+
+	if once {
+		if err := o.ReconcileAll(); err != nil {
+			glog.Exitf("%v", err)
+		}
+		return
+	}
+
+	stop := make(chan struct{})
+	changes := make(chan struct{}, 1)
+	source.Watch(changes, stop)
+	o.Run(resyncPeriod, changes, stop)
+}
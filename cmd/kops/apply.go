@@ -0,0 +1,715 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	applyLong = templates.LongDesc(i18n.T(`
+	Apply a configuration to clusters from manifest files or directories.
+
+	Every Cluster, InstanceGroup and SSHCredential document found is created, or replaces the
+	existing object in the state store.  Instance groups and SSH credentials belong to the cluster
+	named by their kops.k8s.io/cluster label, or to the only cluster in the manifests.  Objects
+	which are not in the manifests are left as they are.
+
+	Without --yes, the changes to the state store are listed, along with whether the cloud
+	resources would change (as kops update cluster would) and whether instances would then need
+	replacing.  With --yes, the objects are written, the cloud resources are updated, and the
+	instances which need updating are listed; add --auto-roll to replace them, as kops
+	rolling-update cluster --yes would.`))
+
+	applyExample = templates.Examples(i18n.T(`
+	# Preview the changes of all the manifests in a directory
+	kops apply -f ./clusters/
+
+	# Apply them, and update the cloud resources
+	kops apply -f ./clusters/ --yes
+
+	# Apply them, update the cloud resources, and replace the instances which need it
+	kops apply -f ./clusters/ --yes --auto-roll
+	`))
+
+	applyShort = i18n.T("Create or update clusters from manifests, and apply them to the cloud.")
+)
+
+type ApplyOptions struct {
+	// Filenames are the manifest files or directories to apply
+	Filenames []string
+
+	// Yes writes the objects and updates the cloud; otherwise the changes are previewed
+	Yes bool
+	// AutoRoll performs a rolling update of the instances which need updating
+	AutoRoll bool
+	// CloudOnly performs the rolling update without confirming progress with the kubernetes API
+	CloudOnly bool
+
+	// Policies are rego files or webhook URLs that must allow the update and rolling update
+	Policies []string
+}
+
+func NewCmdApply(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ApplyOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "apply -f FILENAME",
+		Short:   applyShort,
+		Long:    applyLong,
+		Example: applyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(options.Filenames) == 0 {
+				cmd.Help()
+				return
+			}
+			if err := RunApply(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&options.Filenames, "filename", "f", options.Filenames, "Manifest files, or directories of manifests, to apply")
+	cmd.MarkFlagRequired("filename")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Apply the changes; without --yes they are only previewed")
+	cmd.Flags().BoolVar(&options.AutoRoll, "auto-roll", options.AutoRoll, "After updating the cloud, replace the instances which need updating")
+	cmd.Flags().BoolVar(&options.CloudOnly, "cloudonly", options.CloudOnly, "Perform the rolling update without confirming progress with k8s")
+	cmd.Flags().StringSliceVar(&options.Policies, "policy", options.Policies, "Policy that must allow the update and rolling update: a .rego file (evaluated with opa) or a webhook URL; may be repeated")
+
+	return cmd
+}
+
+// applyManifests are the objects read from the manifests, keyed by cluster name
+type applyManifests struct {
+	Clusters       map[string]*kopsapi.Cluster
+	InstanceGroups map[string][]*kopsapi.InstanceGroup
+	SSHCredentials map[string][]*kopsapi.SSHCredential
+}
+
+// ClusterNames returns the names of the clusters with objects in the manifests
+func (m *applyManifests) ClusterNames() []string {
+	names := make(map[string]bool)
+	for name := range m.Clusters {
+		names[name] = true
+	}
+	for name := range m.InstanceGroups {
+		names[name] = true
+	}
+	for name := range m.SSHCredentials {
+		names[name] = true
+	}
+
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// applyChange is a change to an object in the state store
+type applyChange struct {
+	// Resource is the kind and name of the object, e.g. instancegroup/nodes
+	Resource string
+	// Action is create, update or unchanged
+	Action string
+}
+
+const (
+	applyActionCreate    = "create"
+	applyActionUpdate    = "update"
+	applyActionUnchanged = "unchanged"
+)
+
+func RunApply(f *util.Factory, out io.Writer, options *ApplyOptions) error {
+	manifests, err := readApplyManifests(options.Filenames)
+	if err != nil {
+		return err
+	}
+
+	clusterNames := manifests.ClusterNames()
+	if len(clusterNames) == 0 {
+		return fmt.Errorf("no Cluster, InstanceGroup or SSHCredential documents found")
+	}
+
+	for _, clusterName := range clusterNames {
+		if err := applyCluster(f, out, options, clusterName, manifests); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyCluster(f *util.Factory, out io.Writer, options *ApplyOptions, clusterName string, manifests *applyManifests) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	existing, err := clientset.GetCluster(clusterName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("error fetching cluster %q: %v", clusterName, err)
+		}
+		existing = nil
+	}
+
+	cluster := manifests.Clusters[clusterName]
+	if cluster == nil {
+		if existing == nil {
+			return fmt.Errorf("cluster %q not found; include its Cluster manifest to create it", clusterName)
+		}
+		cluster = existing
+	}
+
+	var existingInstanceGroups []*kopsapi.InstanceGroup
+	if existing != nil {
+		list, err := clientset.InstanceGroupsFor(existing).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			existingInstanceGroups = append(existingInstanceGroups, &list.Items[i])
+		}
+	}
+
+	// existingSSHKeys are the public keys of the existing SSH credentials, by credential name
+	existingSSHKeys := make(map[string][]string)
+	if existing != nil && len(manifests.SSHCredentials[clusterName]) != 0 {
+		sshCredentialStore, err := clientset.SSHCredentialStore(existing)
+		if err != nil {
+			return err
+		}
+		credentials, err := sshCredentialStore.ListSSHCredentials()
+		if err != nil {
+			return fmt.Errorf("error listing SSH credentials: %v", err)
+		}
+		for _, k := range credentials {
+			existingSSHKeys[k.ObjectMeta.Name] = append(existingSSHKeys[k.ObjectMeta.Name], strings.TrimSpace(k.Spec.PublicKey))
+		}
+	}
+
+	changes := planApply(existing, existingInstanceGroups, existingSSHKeys, manifests.Clusters[clusterName], manifests.InstanceGroups[clusterName], manifests.SSHCredentials[clusterName])
+
+	fmt.Fprintf(out, "Cluster %s:\n", clusterName)
+	hasChanges := false
+	for _, c := range changes {
+		fmt.Fprintf(out, "  %s: %s\n", c.Resource, c.Action)
+		if c.Action != applyActionUnchanged {
+			hasChanges = true
+		}
+	}
+	fmt.Fprintf(out, "\n")
+
+	if !options.Yes {
+		if existing == nil {
+			fmt.Fprintf(out, "The cluster will be created, and kops update cluster will create its cloud resources.\n")
+			fmt.Fprintf(out, "\nMust specify --yes to apply changes\n\n")
+			return nil
+		}
+		return previewApply(f, out, cluster, mergeInstanceGroups(existingInstanceGroups, manifests.InstanceGroups[clusterName]), hasChanges)
+	}
+
+	if existing != nil {
+		if err := commands.CheckClusterLock(f, clusterName); err != nil {
+			return err
+		}
+	}
+
+	if err := writeApplyObjects(f, existing, existingInstanceGroups, manifests, clusterName, changes); err != nil {
+		return err
+	}
+
+	updateOptions := &UpdateClusterOptions{}
+	updateOptions.InitDefaults()
+	updateOptions.Yes = true
+	updateOptions.Policies = options.Policies
+	if _, err := RunUpdateCluster(f, clusterName, out, updateOptions); err != nil {
+		return err
+	}
+
+	if existing == nil {
+		// The instances of a new cluster are all current
+		return nil
+	}
+
+	rollingUpdateOptions := &RollingUpdateOptions{}
+	rollingUpdateOptions.InitDefaults()
+	rollingUpdateOptions.ClusterName = clusterName
+	rollingUpdateOptions.Yes = options.AutoRoll
+	rollingUpdateOptions.CloudOnly = options.CloudOnly
+	rollingUpdateOptions.Policies = options.Policies
+	if err := RunRollingUpdateCluster(f, out, rollingUpdateOptions); err != nil {
+		return err
+	}
+	if !options.AutoRoll {
+		fmt.Fprintf(out, "To replace the instances which need updating, re-run kops apply with --auto-roll, or run: kops rolling-update cluster %s --yes\n\n", clusterName)
+	}
+	return nil
+}
+
+// previewApply does a dry-run of kops update cluster with the objects from the manifests, reporting whether the cloud
+// resources would change and whether instances would then need replacing
+func previewApply(f *util.Factory, out io.Writer, cluster *kopsapi.Cluster, instanceGroups []*kopsapi.InstanceGroup, storeChanges bool) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	var igs []*kopsapi.InstanceGroup
+	for _, ig := range instanceGroups {
+		igs = append(igs, ig.DeepCopy())
+	}
+
+	runTasksOptions := &fi.RunTasksOptions{}
+	runTasksOptions.InitDefaults()
+
+	applyCmd := &cloudup.ApplyClusterCmd{
+		Clientset:       clientset,
+		Cluster:         cluster.DeepCopy(),
+		DryRun:          true,
+		InstanceGroups:  igs,
+		RunTasksOptions: runTasksOptions,
+		Models:          cloudup.CloudupModels,
+		TargetName:      cloudup.TargetDryRun,
+		DryRunOut:       out,
+	}
+	if err := applyCmd.Run(); err != nil {
+		return err
+	}
+
+	target := applyCmd.Target.(*fi.DryRunTarget)
+	if target.HasChanges() {
+		fmt.Fprintf(out, "The cloud resources would be updated.\n")
+		if instanceConfigurationChanged(target.ChangedTasks()) {
+			fmt.Fprintf(out, "The instance configuration would change, so instances would need a rolling update.\n")
+		}
+	} else {
+		fmt.Fprintf(out, "The cloud resources are up to date.\n")
+	}
+
+	if storeChanges || target.HasChanges() {
+		fmt.Fprintf(out, "\nMust specify --yes to apply changes\n\n")
+	} else {
+		fmt.Fprintf(out, "\nNo changes need to be applied\n\n")
+	}
+	return nil
+}
+
+// instanceConfigurationChanged is true if any of the tasks configure the instances that groups launch
+func instanceConfigurationChanged(tasks []fi.Task) bool {
+	for _, t := range tasks {
+		switch t.(type) {
+		case *awstasks.LaunchConfiguration, *awstasks.LaunchTemplate, *gcetasks.InstanceTemplate:
+			return true
+		}
+	}
+	return false
+}
+
+// writeApplyObjects creates or replaces the objects of the cluster in the state store
+func writeApplyObjects(f *util.Factory, existing *kopsapi.Cluster, existingInstanceGroups []*kopsapi.InstanceGroup, manifests *applyManifests, clusterName string, changes []*applyChange) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	action := make(map[string]string)
+	for _, c := range changes {
+		action[c.Resource] = c.Action
+	}
+
+	cluster := existing
+	if v := manifests.Clusters[clusterName]; v != nil {
+		v.ObjectMeta.ResourceVersion = ""
+		switch action["cluster/"+clusterName] {
+		case applyActionCreate:
+			if err := cloudup.PerformAssignments(v); err != nil {
+				return fmt.Errorf("error populating configuration: %v", err)
+			}
+			cluster, err = clientset.CreateCluster(v)
+			if err != nil {
+				return fmt.Errorf("error creating cluster: %v", err)
+			}
+			commands.RecordAuditChange(f, clusterName, audit.OperationCreate, "cluster", nil, v)
+		case applyActionUpdate:
+			statusDiscovery := &commands.CloudDiscoveryStatusStore{}
+			status, err := statusDiscovery.FindClusterStatus(v)
+			if err != nil {
+				return err
+			}
+			cluster, err = clientset.UpdateCluster(v, status)
+			if err != nil {
+				return fmt.Errorf("error replacing cluster: %v", err)
+			}
+			commands.RecordAuditChange(f, clusterName, audit.OperationReplace, "cluster", existing, v)
+		}
+	}
+
+	existingByName := make(map[string]*kopsapi.InstanceGroup)
+	for _, ig := range existingInstanceGroups {
+		existingByName[ig.ObjectMeta.Name] = ig
+	}
+	for _, v := range manifests.InstanceGroups[clusterName] {
+		name := v.ObjectMeta.Name
+		v.ObjectMeta.ResourceVersion = ""
+		switch action["instancegroup/"+name] {
+		case applyActionCreate:
+			if _, err := clientset.InstanceGroupsFor(cluster).Create(v); err != nil {
+				return fmt.Errorf("error creating instanceGroup %q: %v", name, err)
+			}
+			commands.RecordAuditChange(f, clusterName, audit.OperationCreate, "instancegroup/"+name, nil, v)
+		case applyActionUpdate:
+			if _, err := clientset.InstanceGroupsFor(cluster).Update(v); err != nil {
+				return fmt.Errorf("error replacing instanceGroup %q: %v", name, err)
+			}
+			commands.RecordAuditChange(f, clusterName, audit.OperationReplace, "instancegroup/"+name, existingByName[name], v)
+		}
+	}
+
+	proposedSSHKeys := sshCredentialKeys(manifests.SSHCredentials[clusterName])
+	var sshCredentialStore fi.SSHCredentialStore
+	for _, name := range sortedKeys(proposedSSHKeys) {
+		switch action["sshcredential/"+name] {
+		case applyActionCreate, applyActionUpdate:
+		default:
+			continue
+		}
+
+		if sshCredentialStore == nil {
+			sshCredentialStore, err = clientset.SSHCredentialStore(cluster)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, key := range proposedSSHKeys[name] {
+			if err := sshCredentialStore.AddSSHPublicKey(name, []byte(key)); err != nil {
+				return fmt.Errorf("error adding SSHCredential %q: %v", name, err)
+			}
+		}
+
+		// Keys no longer in the manifests were rotated out
+		existing, err := sshCredentialStore.FindSSHPublicKeys(name)
+		if err != nil {
+			return fmt.Errorf("error reading SSHCredential %q: %v", name, err)
+		}
+		for _, k := range existing {
+			if !containsString(proposedSSHKeys[name], strings.TrimSpace(k.Spec.PublicKey)) {
+				k.Name = name
+				if err := deleteReplacedSSHCredential(sshCredentialStore, k); err != nil {
+					return err
+				}
+			}
+		}
+
+		operation := audit.OperationCreate
+		if action["sshcredential/"+name] == applyActionUpdate {
+			operation = audit.OperationReplace
+		}
+		commands.RecordAudit(f, clusterName, operation, "sshcredential/"+name, "")
+	}
+
+	return nil
+}
+
+// planApply returns the changes the manifests make to the objects of a cluster in the state store
+func planApply(existing *kopsapi.Cluster, existingInstanceGroups []*kopsapi.InstanceGroup, existingSSHKeys map[string][]string,
+	cluster *kopsapi.Cluster, instanceGroups []*kopsapi.InstanceGroup, sshCredentials []*kopsapi.SSHCredential) []*applyChange {
+	var changes []*applyChange
+
+	if cluster != nil {
+		c := &applyChange{Resource: "cluster/" + cluster.ObjectMeta.Name, Action: applyActionUpdate}
+		if existing == nil {
+			c.Action = applyActionCreate
+		} else if objectUnchanged(&existing.ObjectMeta, &cluster.ObjectMeta, existing.Spec, cluster.Spec) {
+			c.Action = applyActionUnchanged
+		}
+		changes = append(changes, c)
+	}
+
+	existingByName := make(map[string]*kopsapi.InstanceGroup)
+	for _, ig := range existingInstanceGroups {
+		existingByName[ig.ObjectMeta.Name] = ig
+	}
+	for _, ig := range instanceGroups {
+		c := &applyChange{Resource: "instancegroup/" + ig.ObjectMeta.Name, Action: applyActionUpdate}
+		if e := existingByName[ig.ObjectMeta.Name]; e == nil {
+			c.Action = applyActionCreate
+		} else if objectUnchanged(&e.ObjectMeta, &ig.ObjectMeta, e.Spec, ig.Spec) {
+			c.Action = applyActionUnchanged
+		}
+		changes = append(changes, c)
+	}
+
+	proposedSSHKeys := sshCredentialKeys(sshCredentials)
+	for _, name := range sortedKeys(proposedSSHKeys) {
+		c := &applyChange{Resource: "sshcredential/" + name, Action: applyActionUnchanged}
+		existing := existingSSHKeys[name]
+		if len(existing) == 0 {
+			c.Action = applyActionCreate
+		} else if !sameStrings(existing, proposedSSHKeys[name]) {
+			c.Action = applyActionUpdate
+		}
+		changes = append(changes, c)
+	}
+
+	return changes
+}
+
+// sshCredentialKeys returns the trimmed public keys of the SSHCredential manifests, by credential name
+func sshCredentialKeys(sshCredentials []*kopsapi.SSHCredential) map[string][]string {
+	keys := make(map[string][]string)
+	for _, v := range sshCredentials {
+		name := sshCredentialName(v)
+		key := strings.TrimSpace(v.Spec.PublicKey)
+		if !containsString(keys[name], key) {
+			keys[name] = append(keys[name], key)
+		}
+	}
+	return keys
+}
+
+// sameStrings is true if a and b hold the same set of strings
+func sameStrings(a []string, b []string) bool {
+	for _, s := range a {
+		if !containsString(b, s) {
+			return false
+		}
+	}
+	for _, s := range b {
+		if !containsString(a, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// objectUnchanged is true if the labels, annotations and spec of an object are unchanged
+func objectUnchanged(existing *metav1.ObjectMeta, proposed *metav1.ObjectMeta, existingSpec interface{}, proposedSpec interface{}) bool {
+	if !labelsEqual(existing.Labels, proposed.Labels) || !labelsEqual(existing.Annotations, proposed.Annotations) {
+		return false
+	}
+	return reflect.DeepEqual(existingSpec, proposedSpec)
+}
+
+// labelsEqual compares two maps, treating nil and empty maps as equal
+func labelsEqual(a map[string]string, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// mergeInstanceGroups returns the existing instance groups, with those in the manifests replacing or adding to them
+func mergeInstanceGroups(existing []*kopsapi.InstanceGroup, manifests []*kopsapi.InstanceGroup) []*kopsapi.InstanceGroup {
+	byName := make(map[string]*kopsapi.InstanceGroup)
+	for _, ig := range existing {
+		byName[ig.ObjectMeta.Name] = ig
+	}
+	for _, ig := range manifests {
+		byName[ig.ObjectMeta.Name] = ig
+	}
+
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var merged []*kopsapi.InstanceGroup
+	for _, name := range names {
+		merged = append(merged, byName[name])
+	}
+	return merged
+}
+
+// readApplyManifests reads the objects in the manifest files, and in the .yaml, .yml and .json files of directories
+func readApplyManifests(filenames []string) (*applyManifests, error) {
+	var paths []string
+	for _, filename := range filenames {
+		if filename == "-" {
+			paths = append(paths, filename)
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil || !info.IsDir() {
+			// Files may also be in a VFS path, e.g. s3://
+			paths = append(paths, filename)
+			continue
+		}
+		err = filepath.Walk(filename, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json":
+				paths = append(paths, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory %q: %v", filename, err)
+		}
+	}
+
+	manifests := &applyManifests{
+		Clusters:       make(map[string]*kopsapi.Cluster),
+		InstanceGroups: make(map[string][]*kopsapi.InstanceGroup),
+		SSHCredentials: make(map[string][]*kopsapi.SSHCredential),
+	}
+
+	codec := kopscodecs.Codecs.UniversalDecoder(kopsapi.SchemeGroupVersion)
+
+	// Instance groups and SSH credentials without a cluster label belong to the only cluster, once we know it
+	var unlabelledInstanceGroups []*kopsapi.InstanceGroup
+	var unlabelledSSHCredentials []*kopsapi.SSHCredential
+	seenInstanceGroups := make(map[string]string)
+
+	for _, p := range paths {
+		var contents []byte
+		var err error
+		if p == "-" {
+			contents, err = ConsumeStdin()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			contents, err = vfs.Context.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("error reading file %q: %v", p, err)
+			}
+		}
+
+		sections := bytes.Split(bytes.Replace(contents, []byte("\r\n"), []byte("\n"), -1), []byte("\n---\n"))
+		for _, section := range sections {
+			if len(bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(section), []byte("---")))) == 0 {
+				continue
+			}
+			o, gvk, err := codec.Decode(section, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing file %q: %v", p, err)
+			}
+
+			switch v := o.(type) {
+			case *kopsapi.Cluster:
+				if manifests.Clusters[v.ObjectMeta.Name] != nil {
+					return nil, fmt.Errorf("cluster %q is defined more than once", v.ObjectMeta.Name)
+				}
+				manifests.Clusters[v.ObjectMeta.Name] = v
+
+			case *kopsapi.InstanceGroup:
+				clusterName := v.ObjectMeta.Labels[kopsapi.LabelClusterName]
+				key := clusterName + "/" + v.ObjectMeta.Name
+				if previous, found := seenInstanceGroups[key]; found {
+					return nil, fmt.Errorf("instanceGroup %q is defined in both %q and %q", v.ObjectMeta.Name, previous, p)
+				}
+				seenInstanceGroups[key] = p
+				if clusterName == "" {
+					unlabelledInstanceGroups = append(unlabelledInstanceGroups, v)
+				} else {
+					manifests.InstanceGroups[clusterName] = append(manifests.InstanceGroups[clusterName], v)
+				}
+
+			case *kopsapi.SSHCredential:
+				if v.Spec.PublicKey == "" {
+					return nil, fmt.Errorf("spec.PublicKey is required in SSHCredential in %q", p)
+				}
+				clusterName := v.ObjectMeta.Labels[kopsapi.LabelClusterName]
+				if clusterName == "" {
+					unlabelledSSHCredentials = append(unlabelledSSHCredentials, v)
+				} else {
+					manifests.SSHCredentials[clusterName] = append(manifests.SSHCredentials[clusterName], v)
+				}
+
+			default:
+				return nil, fmt.Errorf("Unhandled kind %q in %q", gvk, p)
+			}
+		}
+	}
+
+	if len(unlabelledInstanceGroups) != 0 || len(unlabelledSSHCredentials) != 0 {
+		if len(manifests.Clusters) != 1 {
+			return nil, fmt.Errorf("must specify %q label with cluster name on instanceGroups and SSHCredentials, unless the manifests contain exactly one cluster", kopsapi.LabelClusterName)
+		}
+		for clusterName := range manifests.Clusters {
+			for _, ig := range unlabelledInstanceGroups {
+				if _, found := seenInstanceGroups[clusterName+"/"+ig.ObjectMeta.Name]; found {
+					return nil, fmt.Errorf("instanceGroup %q is defined more than once", ig.ObjectMeta.Name)
+				}
+				if ig.ObjectMeta.Labels == nil {
+					ig.ObjectMeta.Labels = make(map[string]string)
+				}
+				ig.ObjectMeta.Labels[kopsapi.LabelClusterName] = clusterName
+				manifests.InstanceGroups[clusterName] = append(manifests.InstanceGroups[clusterName], ig)
+			}
+			manifests.SSHCredentials[clusterName] = append(manifests.SSHCredentials[clusterName], unlabelledSSHCredentials...)
+		}
+	}
+
+	for _, igs := range manifests.InstanceGroups {
+		sort.Slice(igs, func(i, j int) bool {
+			return igs[i].ObjectMeta.Name < igs[j].ObjectMeta.Name
+		})
+	}
+
+	return manifests, nil
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+func TestReadApplyManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"cluster.yaml": `apiVersion: kops/v1alpha2
+kind: Cluster
+metadata:
+  name: example.com
+spec:
+  kubernetesVersion: 1.10.0
+---
+apiVersion: kops/v1alpha2
+kind: SSHCredential
+metadata:
+  name: admin
+spec:
+  publicKey: ssh-rsa AAAA
+`,
+		"igs/nodes.yml": `apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: nodes
+spec:
+  role: Node
+---
+apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: master-us-east-1a
+  labels:
+    kops.k8s.io/cluster: example.com
+spec:
+  role: Master
+`,
+		"README.md": "not a manifest",
+	}
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("error creating dir: %v", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+	}
+
+	manifests, err := readApplyManifests([]string{dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if names := manifests.ClusterNames(); !reflect.DeepEqual(names, []string{"example.com"}) {
+		t.Fatalf("unexpected cluster names: %v", names)
+	}
+	if manifests.Clusters["example.com"].Spec.KubernetesVersion != "1.10.0" {
+		t.Errorf("unexpected cluster: %v", manifests.Clusters["example.com"])
+	}
+
+	var igNames []string
+	for _, ig := range manifests.InstanceGroups["example.com"] {
+		igNames = append(igNames, ig.ObjectMeta.Name)
+		if ig.ObjectMeta.Labels[api.LabelClusterName] != "example.com" {
+			t.Errorf("instanceGroup %q was not labelled with the cluster", ig.ObjectMeta.Name)
+		}
+	}
+	if !reflect.DeepEqual(igNames, []string{"master-us-east-1a", "nodes"}) {
+		t.Errorf("unexpected instanceGroups: %v", igNames)
+	}
+
+	if len(manifests.SSHCredentials["example.com"]) != 1 {
+		t.Errorf("expected one SSHCredential, got %d", len(manifests.SSHCredentials["example.com"]))
+	}
+}
+
+func TestReadApplyManifestsUnlabelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: nodes
+spec:
+  role: Node
+`
+	p := filepath.Join(dir, "nodes.yaml")
+	if err := ioutil.WriteFile(p, []byte(manifest), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	if _, err := readApplyManifests([]string{p}); err == nil {
+		t.Errorf("expected an error for an instanceGroup without a cluster")
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	cluster := func(version string) *api.Cluster {
+		c := &api.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "example.com"}}
+		c.Spec.KubernetesVersion = version
+		return c
+	}
+	ig := func(name string, size int32) *api.InstanceGroup {
+		g := &api.InstanceGroup{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{api.LabelClusterName: "example.com"}}}
+		g.Spec.MinSize = &size
+		g.Spec.MaxSize = &size
+		return g
+	}
+	ssh := &api.SSHCredential{Spec: api.SSHCredentialSpec{PublicKey: "ssh-rsa AAAA\n"}}
+	alice := &api.SSHCredential{ObjectMeta: metav1.ObjectMeta{Name: "alice"}, Spec: api.SSHCredentialSpec{PublicKey: "ssh-rsa CCCC alice"}}
+
+	grid := []struct {
+		Name            string
+		Existing        *api.Cluster
+		ExistingIGs     []*api.InstanceGroup
+		ExistingSSHKeys map[string][]string
+		Cluster         *api.Cluster
+		IGs             []*api.InstanceGroup
+		SSH             []*api.SSHCredential
+		Expected        []applyChange
+	}{
+		{
+			Name:    "new cluster",
+			Cluster: cluster("1.10.0"),
+			IGs:     []*api.InstanceGroup{ig("nodes", 2)},
+			SSH:     []*api.SSHCredential{ssh},
+			Expected: []applyChange{
+				{Resource: "cluster/example.com", Action: applyActionCreate},
+				{Resource: "instancegroup/nodes", Action: applyActionCreate},
+				{Resource: "sshcredential/admin", Action: applyActionCreate},
+			},
+		},
+		{
+			Name:            "unchanged",
+			Existing:        cluster("1.10.0"),
+			ExistingIGs:     []*api.InstanceGroup{ig("nodes", 2)},
+			ExistingSSHKeys: map[string][]string{"admin": {"ssh-rsa AAAA"}},
+			Cluster:         cluster("1.10.0"),
+			IGs:             []*api.InstanceGroup{ig("nodes", 2)},
+			SSH:             []*api.SSHCredential{ssh},
+			Expected: []applyChange{
+				{Resource: "cluster/example.com", Action: applyActionUnchanged},
+				{Resource: "instancegroup/nodes", Action: applyActionUnchanged},
+				{Resource: "sshcredential/admin", Action: applyActionUnchanged},
+			},
+		},
+		{
+			Name:            "changed",
+			Existing:        cluster("1.9.0"),
+			ExistingIGs:     []*api.InstanceGroup{ig("nodes", 2)},
+			ExistingSSHKeys: map[string][]string{"admin": {"ssh-rsa BBBB"}},
+			Cluster:         cluster("1.10.0"),
+			IGs:             []*api.InstanceGroup{ig("nodes", 3), ig("extra", 1)},
+			SSH:             []*api.SSHCredential{ssh},
+			Expected: []applyChange{
+				{Resource: "cluster/example.com", Action: applyActionUpdate},
+				{Resource: "instancegroup/nodes", Action: applyActionUpdate},
+				{Resource: "instancegroup/extra", Action: applyActionCreate},
+				{Resource: "sshcredential/admin", Action: applyActionUpdate},
+			},
+		},
+		{
+			Name:            "additional ssh credential",
+			Existing:        cluster("1.10.0"),
+			ExistingSSHKeys: map[string][]string{"admin": {"ssh-rsa AAAA"}},
+			SSH:             []*api.SSHCredential{alice, ssh},
+			Expected: []applyChange{
+				{Resource: "sshcredential/admin", Action: applyActionUnchanged},
+				{Resource: "sshcredential/alice", Action: applyActionCreate},
+			},
+		},
+		{
+			Name:        "instance groups only",
+			Existing:    cluster("1.10.0"),
+			ExistingIGs: []*api.InstanceGroup{ig("nodes", 2)},
+			IGs:         []*api.InstanceGroup{ig("nodes", 2)},
+			Expected: []applyChange{
+				{Resource: "instancegroup/nodes", Action: applyActionUnchanged},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		changes := planApply(g.Existing, g.ExistingIGs, g.ExistingSSHKeys, g.Cluster, g.IGs, g.SSH)
+		var actual []applyChange
+		for _, c := range changes {
+			actual = append(actual, *c)
+		}
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("%s: unexpected changes %v, expected %v", g.Name, actual, g.Expected)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/approval"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	approveLong = templates.LongDesc(i18n.T(`
+	Approve a plan, so that the operation it describes can be executed.
+
+	When a cluster sets spec.requireApproval, "kops update cluster --yes" and "kops delete cluster --yes"
+	do not change anything until a second operator has approved a plan for them.  Instead they record
+	a signed plan in the state store and print its id.  The plan must be approved by a different user
+	than the one who requested it; the operation is then executed by re-running the original command.
+
+	On AWS users are the IAM principals of their credentials, and the approval records proof from STS
+	of who approved the plan; on other clouds users are identified by name, and approval is advisory.
+
+	An approval only applies to the cluster and instance group specs the plan was made against, and
+	can only be used once.
+
+	Without --yes the plan is displayed, but not approved.`))
+
+	approveExample = templates.Examples(i18n.T(`
+	# Review a plan
+	kops approve --name k8s-cluster.example.com 20180601-120000-1a2b3c4d
+
+	# Approve it
+	kops approve --name k8s-cluster.example.com 20180601-120000-1a2b3c4d --yes
+	`))
+
+	approveShort = i18n.T(`Approve a plan requested by another operator.`)
+)
+
+type ApproveOptions struct {
+	ClusterName string
+	PlanID      string
+	Yes         bool
+}
+
+func NewCmdApprove(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ApproveOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "approve PLAN_ID",
+		Short:   approveShort,
+		Long:    approveLong,
+		Example: approveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("Specify the id of the plan to approve"))
+			}
+			options.PlanID = args[0]
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunApprove(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Approve the plan; without --yes the plan is only displayed")
+
+	return cmd
+}
+
+func RunApprove(f *util.Factory, out io.Writer, options *ApproveOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	store, err := f.ApprovalStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	plan, err := store.Get(options.PlanID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Plan:         %s\n", plan.ID)
+	fmt.Fprintf(out, "Operation:    %s\n", plan.Operation)
+	fmt.Fprintf(out, "Requested by: %s at %s\n", plan.RequestedBy, plan.RequestedAt.Format(time.RFC3339))
+	fmt.Fprintf(out, "Command:      %s\n", plan.Command)
+	if plan.ApprovedAt != nil {
+		fmt.Fprintf(out, "Approved by:  %s at %s\n", plan.ApprovedBy, plan.ApprovedAt.Format(time.RFC3339))
+	}
+	if plan.ExecutedAt != nil {
+		fmt.Fprintf(out, "Executed at:  %s\n", plan.ExecutedAt.Format(time.RFC3339))
+	}
+	if plan.Summary != "" {
+		fmt.Fprintf(out, "\n%s\n", plan.Summary)
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to approve the plan.\n")
+		return nil
+	}
+
+	plan, err = store.Approve(plan.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nPlan %s approved; %s can now run %q\n", plan.ID, plan.RequestedBy, plan.Command)
+	return nil
+}
+
+// approvalGate holds back an operation on a cluster that requires approval, until a plan for it has been approved
+type approvalGate struct {
+	store     *approval.Store
+	operation string
+	digest    string
+
+	// plan is the approved plan, if any
+	plan *approval.Plan
+}
+
+// newApprovalGate returns the gate for the operation, or nil if the cluster does not require approval
+func newApprovalGate(f *util.Factory, cluster *api.Cluster, instanceGroups []*api.InstanceGroup, operation string) (*approvalGate, error) {
+	if !fi.BoolValue(cluster.Spec.RequireApproval) {
+		return nil, nil
+	}
+
+	store, err := f.ApprovalStore(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := approval.Digest(cluster, instanceGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := store.FindApproved(operation, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &approvalGate{
+		store:     store,
+		operation: operation,
+		digest:    digest,
+		plan:      plan,
+	}, nil
+}
+
+// Approved is true if the operation may proceed
+func (g *approvalGate) Approved() bool {
+	return g.plan != nil
+}
+
+// Request records a plan for the operation, and tells the user how to get it approved
+func (g *approvalGate) Request(out io.Writer, clusterName string, summary string) error {
+	plan, err := g.store.Request(g.operation, g.digest, summary)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nCluster %q requires approval for %s.\n", clusterName, g.operation)
+	fmt.Fprintf(out, "Created plan %s; another operator must approve it with:\n\n", plan.ID)
+	fmt.Fprintf(out, "  kops approve --name %s %s --yes\n\n", clusterName, plan.ID)
+	fmt.Fprintf(out, "and then this command must be run again.\n")
+	return nil
+}
+
+// Executed records that the approved plan has been used, so it cannot be used again
+func (g *approvalGate) Executed() error {
+	return g.store.MarkExecuted(g.plan)
+}
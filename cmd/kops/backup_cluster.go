@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	backupClusterLong = templates.LongDesc(i18n.T(`
+	Back up a cluster to a single bundle in the state store.
+
+	The bundle contains the cluster and instance group specs, the keys and secrets of the cluster,
+	and the latest backup that etcd-manager has taken of each etcd cluster.  It is written to
+	backups/cluster in the state store of the cluster, and can be restored into a new state store,
+	account or region with kops restore cluster.
+
+	The bundle contains the private keys of the cluster, so should be protected like the state store.
+	`))
+
+	backupClusterExample = templates.Examples(i18n.T(`
+	# Back up a cluster
+	kops backup cluster k8s-cluster.example.com --state s3://example.com
+	`))
+
+	backupClusterShort = i18n.T(`Back up a cluster to the state store.`)
+)
+
+type BackupClusterOptions struct {
+	ClusterName string
+}
+
+func NewCmdBackupCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &BackupClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   backupClusterShort,
+		Long:    backupClusterLong,
+		Example: backupClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunBackupCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func RunBackupCluster(f *util.Factory, out io.Writer, options *BackupClusterOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading instance groups for cluster %q: %v", options.ClusterName, err)
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	backup, err := commands.BuildClusterBackup(clientset, cluster, instanceGroups, time.Now())
+	if err != nil {
+		return err
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := backup.Write(&b); err != nil {
+		return err
+	}
+
+	p := configBase.Join("backups", "cluster", backup.Manifest.Timestamp.Format("20060102T150405Z")+".tar.gz")
+	if err := p.WriteFile(bytes.NewReader(b.Bytes()), nil); err != nil {
+		return fmt.Errorf("error writing backup to %s: %v", p, err)
+	}
+
+	fmt.Fprintf(out, "\nCluster %q has been backed up to %s\n\n", options.ClusterName, p)
+	fmt.Fprintf(out, "Restore it with: kops restore cluster --from %s\n\n", p)
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/bundle"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/baremetal"
+)
+
+// configureBareMetalCloud lets a bare metal cloud enroll the hosts it images, by copying a bundle to them over SSH
+func configureBareMetalCloud(cloud fi.Cloud, clientset simple.Clientset, cluster *kops.Cluster) {
+	baremetalCloud, ok := cloud.(*baremetal.Cloud)
+	if !ok {
+		return
+	}
+	baremetalCloud.Enroller = &bundle.SSHEnroller{
+		Clientset:   clientset,
+		Cluster:     cluster,
+		SSHIdentity: filepath.Join(homedir.HomeDir(), ".ssh", "id_rsa"),
+	}
+}
+
+// imageNewBareMetalHosts images and enrolls the hosts that kops has never imaged, one at a time.
+// Hosts that were imaged before are re-imaged by rolling-update when their configuration changes.
+func imageNewBareMetalHosts(out io.Writer, cloud fi.Cloud, instanceGroups []*kops.InstanceGroup) error {
+	baremetalCloud, ok := cloud.(*baremetal.Cloud)
+	if !ok {
+		return nil
+	}
+
+	for _, ig := range instanceGroups {
+		if ig.Spec.BareMetal == nil {
+			continue
+		}
+		for i := range ig.Spec.BareMetal.Hosts {
+			host := &ig.Spec.BareMetal.Hosts[i]
+			imaged, err := baremetalCloud.IsImaged(host.Name)
+			if err != nil {
+				return err
+			}
+			if imaged {
+				continue
+			}
+
+			fmt.Fprintf(out, "Imaging host %q of instance group %q\n", host.Name, ig.ObjectMeta.Name)
+			if err := baremetalCloud.Reimage(ig, host); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+)
+
+// BatchOptions selects a set of clusters to run a command against, instead of a single named cluster
+type BatchOptions struct {
+	commands.ClusterSelector
+
+	// Parallelism is the maximum number of clusters operated on at the same time
+	Parallelism int
+}
+
+func (o *BatchOptions) InitDefaults() {
+	o.Parallelism = 1
+}
+
+// AddFlags registers the batch selection flags on the command
+func (o *BatchOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.All, "all-clusters", o.All, "Run against every cluster in the state store")
+	cmd.Flags().StringVar(&o.NameGlob, "cluster-glob", o.NameGlob, "Run against every cluster whose name matches the glob, e.g. '*.prod.example.com'")
+	cmd.Flags().StringVar(&o.LabelSelector, "cluster-selector", o.LabelSelector, "Run against every cluster whose labels match the selector, e.g. 'env=prod'")
+	cmd.Flags().StringVar(&o.Group, "cluster-group", o.Group, "Run against every cluster in the group of per-region clusters")
+	cmd.Flags().IntVar(&o.Parallelism, "parallel", o.Parallelism, "Maximum number of clusters to operate on at once, when running against multiple clusters")
+}
+
+// runBatch runs fn against each selected cluster, and prints a summary report.
+// It returns an error if any cluster failed.
+func runBatch(f *util.Factory, args []string, out io.Writer, o *BatchOptions, fn func(clusterName string, out io.Writer) error) error {
+	if len(args) != 0 || rootCommand.clusterName != "" {
+		return fmt.Errorf("cannot specify a cluster name when selecting multiple clusters")
+	}
+	if o.Parallelism < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.ListClusters(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	clusterNames, err := o.Select(list.Items)
+	if err != nil {
+		return err
+	}
+	if len(clusterNames) == 0 {
+		return fmt.Errorf("no clusters matched")
+	}
+
+	results := commands.RunBatch(out, clusterNames, o.Parallelism, fn)
+	if err := commands.WriteBatchReport(out, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != nil {
+			failed++
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d clusters failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	cloneLong = templates.LongDesc(i18n.T(`
+	Create a new resource by copying an existing one.`))
+
+	cloneExample = templates.Examples(i18n.T(`
+	# Create a staging cluster with the same configuration as production
+	kops clone cluster --from prod.example.com --to staging.example.com
+	`))
+
+	cloneShort = i18n.T(`Copy a resource.`)
+)
+
+func NewCmdClone(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "clone",
+		Short:   cloneShort,
+		Long:    cloneLong,
+		Example: cloneExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdCloneCluster(f, out))
+
+	return cmd
+}
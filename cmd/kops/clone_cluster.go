@@ -0,0 +1,211 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	cloneClusterLong = templates.LongDesc(i18n.T(`
+	Create a new cluster with the same configuration as an existing cluster.
+
+	The cluster and instance group specs are copied; DNS names and state store paths that refer to
+	the source cluster are rewritten to refer to the new cluster.  Secrets and keys are not copied,
+	so the new cluster will generate its own when it is first updated.  The SSH public key of the
+	source cluster is copied, unless another is specified.
+
+	The new cluster is only created in the state store; run kops update cluster to create it in the cloud.
+	`))
+
+	cloneClusterExample = templates.Examples(i18n.T(`
+	# Create a staging cluster with the same configuration as production
+	kops clone cluster --from prod.example.com --to staging.example.com
+
+	# Clone into a different DNS zone and VPC network range, reviewing the result first
+	kops clone cluster --from prod.example.com --to dev.example.net \
+	  --dns-zone example.net --network-cidr 172.21.0.0/16 --dry-run -o yaml
+	`))
+
+	cloneClusterShort = i18n.T(`Create a new cluster by copying an existing cluster.`)
+)
+
+type CloneClusterOptions struct {
+	From string
+	commands.CloneClusterOptions
+
+	// SSHPublicKey is the path to an SSH public key to use instead of the source cluster's key
+	SSHPublicKey string
+
+	DryRun bool
+	Output string
+}
+
+func NewCmdCloneCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &CloneClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   cloneClusterShort,
+		Long:    cloneClusterLong,
+		Example: cloneClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := RunCloneCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.From, "from", options.From, "Name of the cluster to copy")
+	cmd.Flags().StringVar(&options.To, "to", options.To, "Name of the new cluster")
+	cmd.Flags().StringVar(&options.DNSZone, "dns-zone", options.DNSZone, "DNS zone for the new cluster, if different from the source cluster")
+	cmd.Flags().StringVar(&options.NetworkCIDR, "network-cidr", options.NetworkCIDR, "Network CIDR for the new cluster; subnets are moved to the same offsets within the new range")
+	cmd.Flags().StringVar(&options.SSHPublicKey, "ssh-public-key", options.SSHPublicKey, "SSH public key to use, instead of copying the key of the source cluster")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", options.DryRun, "If true, only print the object that would be created, without creating it.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format. One of json|yaml. Used with the --dry-run flag.")
+
+	return cmd
+}
+
+func RunCloneCluster(f *util.Factory, out io.Writer, c *CloneClusterOptions) error {
+	if c.From == "" {
+		return fmt.Errorf("--from is required")
+	}
+	if c.To == "" {
+		return fmt.Errorf("--to is required")
+	}
+	if c.DryRun && c.Output == "" {
+		return fmt.Errorf("unable to execute --dry-run without setting --output")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, c.From)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading instance groups for cluster %q: %v", c.From, err)
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	clone, cloneGroups, err := commands.CloneClusterSpec(cluster, instanceGroups, &c.CloneClusterOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := validation.ValidateCluster(clone, false); err != nil {
+		return fmt.Errorf("cloned cluster is not valid: %v", err)
+	}
+	for _, ig := range cloneGroups {
+		if err := validation.CrossValidateInstanceGroup(ig, clone, false); err != nil {
+			return fmt.Errorf("cloned instance group %q is not valid: %v", ig.ObjectMeta.Name, err)
+		}
+	}
+
+	if c.DryRun {
+		obj := []runtime.Object{clone}
+		for _, ig := range cloneGroups {
+			obj = append(obj, ig)
+		}
+		switch c.Output {
+		case OutputYaml:
+			return fullOutputYAML(out, obj...)
+		case OutputJSON:
+			return fullOutputJSON(out, obj...)
+		default:
+			return fmt.Errorf("unsupported output type %q", c.Output)
+		}
+	}
+
+	if _, err := clientset.GetCluster(c.To); err == nil {
+		return fmt.Errorf("cluster %q already exists", c.To)
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("error checking for existing cluster %q: %v", c.To, err)
+	}
+
+	// Load the SSH key before we write anything
+	sshPublicKeys := make(map[string][]byte)
+	if c.SSHPublicKey != "" {
+		data, err := ioutil.ReadFile(utils.ExpandPath(c.SSHPublicKey))
+		if err != nil {
+			return fmt.Errorf("error reading SSH public key %q: %v", c.SSHPublicKey, err)
+		}
+		sshPublicKeys[fi.SecretNameSSHPrimary] = data
+	} else {
+		sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
+		if err != nil {
+			return err
+		}
+		keys, err := sshCredentialStore.FindSSHPublicKeys(fi.SecretNameSSHPrimary)
+		if err != nil {
+			return fmt.Errorf("error reading SSH public key for cluster %q: %v", c.From, err)
+		}
+		for _, key := range keys {
+			sshPublicKeys[fi.SecretNameSSHPrimary] = []byte(key.Spec.PublicKey)
+		}
+	}
+
+	if err := registry.CreateClusterConfig(clientset, clone, cloneGroups); err != nil {
+		return fmt.Errorf("error writing cluster configuration: %v", err)
+	}
+	commands.RecordAuditChange(f, c.To, audit.OperationCreate, "cluster", nil, clone)
+
+	if len(sshPublicKeys) != 0 {
+		sshCredentialStore, err := clientset.SSHCredentialStore(clone)
+		if err != nil {
+			return err
+		}
+		for k, data := range sshPublicKeys {
+			if err := sshCredentialStore.AddSSHPublicKey(k, data); err != nil {
+				return fmt.Errorf("error adding SSH public key: %v", err)
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\nCluster %q has been created as a copy of %q.\n\n", c.To, c.From)
+	fmt.Fprintf(out, "Suggestions:\n")
+	fmt.Fprintf(out, " * review the configuration with: kops edit cluster %s\n", c.To)
+	fmt.Fprintf(out, " * create the cluster with: kops update cluster %s --yes\n\n", c.To)
+
+	return nil
+}
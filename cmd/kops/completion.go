@@ -44,6 +44,71 @@ const boilerPlate = `
 # limitations under the License.
 `
 
+// bashCompletionFunc completes cluster and instance group names, by calling the hidden __complete-names command.
+// The --state and --name flags on the command line being completed are passed through.
+const bashCompletionFunc = `
+__kops_override_flag_list=(--state --name)
+__kops_override_flags()
+{
+    local ${__kops_override_flag_list[*]##*-} two_word_of of var
+    for w in "${words[@]}"; do
+        if [ -n "${two_word_of}" ]; then
+            eval "${two_word_of##*-}=\"${two_word_of}=\${w}\""
+            two_word_of=
+            continue
+        fi
+        for of in "${__kops_override_flag_list[@]}"; do
+            case "${w}" in
+                ${of}=*)
+                    eval "${of##*-}=\"${w}\""
+                    ;;
+                ${of})
+                    two_word_of="${of}"
+                    ;;
+            esac
+        done
+    done
+    for var in "${__kops_override_flag_list[@]##*-}"; do
+        if eval "test -n \"\$${var}\""; then
+            eval "echo -n \${${var}}' '"
+        fi
+    done
+}
+
+__kops_get_names()
+{
+    local kops_out
+    if kops_out=$(kops $(__kops_override_flags) __complete-names "$1" 2>/dev/null); then
+        COMPREPLY=( $( compgen -W "${kops_out[*]}" -- "$cur" ) )
+    fi
+}
+
+__kops_get_clusters()
+{
+    __kops_get_names clusters
+}
+
+__kops_get_instancegroups()
+{
+    __kops_get_names instancegroups
+}
+
+__custom_func() {
+    case ${last_command} in
+        kops_get | kops_get_clusters | kops_delete_cluster | kops_edit_cluster | kops_export_kubecfg | kops_rolling-update_cluster | kops_set_cluster | kops_update_cluster | kops_upgrade_cluster | kops_validate_cluster)
+            __kops_get_clusters
+            return
+            ;;
+        kops_get_instancegroups | kops_delete_instancegroup | kops_edit_instancegroup | kops_set_instancegroup)
+            __kops_get_instancegroups
+            return
+            ;;
+        *)
+            ;;
+    esac
+}
+`
+
 var (
 	completionShells = map[string]func(out io.Writer, cmd *cobra.Command) error{
 		"bash": runCompletionBash,
@@ -62,6 +127,9 @@ var (
 	completion of kops commands.  This can be done by sourcing it from
 	the .bash_profile.
 
+	Cluster and instance group names are completed by listing them from the state store.  The names are
+	cached for a minute under ~/.kops/cache/completion, so newly created resources may take a moment to appear.
+
 	Note: this requires the bash-completion framework, which is not installed
 	by default on Mac. Once installed, bash_completion must be evaluated.  This can be done by adding the
 	following line to the .bash_profile
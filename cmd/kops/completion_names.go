@@ -0,0 +1,186 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/kops/cmd/kops/util"
+)
+
+const (
+	completionNamesClusters       = "clusters"
+	completionNamesInstanceGroups = "instancegroups"
+
+	// completionNamesTTL is how long listed names are reused; completion is invoked on every tab press,
+	// so we avoid listing the state store each time, at the cost of briefly offering stale names
+	completionNamesTTL = 60 * time.Second
+)
+
+// NewCmdCompletionNames builds the hidden command that the shell completion functions call to list resource names
+func NewCmdCompletionNames(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "__complete-names",
+		Short:  "List resource names for shell completion",
+		Hidden: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			// We never report errors; the shell simply offers no suggestions
+			if err := RunCompletionNames(f, out, args); err != nil {
+				glog.V(2).Infof("error listing names for completion: %v", err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// RunCompletionNames prints the names of the clusters or instance groups, separated by spaces
+func RunCompletionNames(f *util.Factory, out io.Writer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one of %s or %s", completionNamesClusters, completionNamesInstanceGroups)
+	}
+	kind := args[0]
+
+	clusterName := ""
+	switch kind {
+	case completionNamesClusters:
+	case completionNamesInstanceGroups:
+		clusterName = rootCommand.ClusterName()
+		if clusterName == "" {
+			return fmt.Errorf("--name is required")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", kind)
+	}
+
+	cacheFile := completionCacheFile(rootCommand.RegistryPath, kind, clusterName)
+
+	names := readCompletionCache(cacheFile, completionNamesTTL)
+	if names == nil {
+		var err error
+		names, err = listCompletionNames(f, kind, clusterName)
+		if err != nil {
+			return err
+		}
+		writeCompletionCache(cacheFile, names)
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(names, " "))
+	return err
+}
+
+// listCompletionNames reads the names from the state store
+func listCompletionNames(f *util.Factory, kind string, clusterName string) ([]string, error) {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	switch kind {
+	case completionNamesClusters:
+		list, err := clientset.ListClusters(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].ObjectMeta.Name)
+		}
+
+	case completionNamesInstanceGroups:
+		cluster, err := clientset.GetCluster(clusterName)
+		if err != nil {
+			return nil, err
+		}
+		if cluster == nil {
+			return nil, fmt.Errorf("cluster %q not found", clusterName)
+		}
+		list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].ObjectMeta.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// completionCache is the on-disk representation of cached names
+type completionCache struct {
+	Timestamp time.Time `json:"timestamp"`
+	Names     []string  `json:"names"`
+}
+
+// completionCacheFile returns the path of the cache file for the names of the kind in the state store (and cluster)
+func completionCacheFile(registryPath string, kind string, clusterName string) string {
+	hash := sha256.Sum256([]byte(registryPath + "\x00" + kind + "\x00" + clusterName))
+	return filepath.Join(homedir.HomeDir(), ".kops", "cache", "completion", hex.EncodeToString(hash[:16])+".json")
+}
+
+// readCompletionCache returns the cached names, or nil if they are missing or older than ttl
+func readCompletionCache(p string, ttl time.Duration) []string {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			glog.V(2).Infof("error reading completion cache %q: %v", p, err)
+		}
+		return nil
+	}
+
+	cached := &completionCache{}
+	if err := json.Unmarshal(b, cached); err != nil {
+		glog.V(2).Infof("error parsing completion cache %q: %v", p, err)
+		return nil
+	}
+	if time.Since(cached.Timestamp) > ttl || cached.Names == nil {
+		return nil
+	}
+	return cached.Names
+}
+
+// writeCompletionCache records the names; failures only cost us a slower completion next time
+func writeCompletionCache(p string, names []string) {
+	b, err := json.Marshal(&completionCache{Timestamp: time.Now(), Names: names})
+	if err != nil {
+		glog.V(2).Infof("error serializing completion cache: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		glog.V(2).Infof("error creating completion cache directory: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(p, b, 0600); err != nil {
+		glog.V(2).Infof("error writing completion cache %q: %v", p, err)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompletionCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "completion")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "cache", "names.json")
+
+	if names := readCompletionCache(p, time.Hour); names != nil {
+		t.Fatalf("expected no names from missing cache, got %v", names)
+	}
+
+	writeCompletionCache(p, []string{"a.example.com", "b.example.com"})
+
+	names := readCompletionCache(p, time.Hour)
+	if !reflect.DeepEqual(names, []string{"a.example.com", "b.example.com"}) {
+		t.Fatalf("unexpected names from cache: %v", names)
+	}
+
+	if names := readCompletionCache(p, -time.Second); names != nil {
+		t.Fatalf("expected expired cache to be ignored, got %v", names)
+	}
+
+	// An empty list is a valid cached result
+	writeCompletionCache(p, []string{})
+	if names := readCompletionCache(p, time.Hour); names == nil || len(names) != 0 {
+		t.Fatalf("expected empty names from cache, got %v", names)
+	}
+}
+
+func TestCompletionCacheFile(t *testing.T) {
+	a := completionCacheFile("s3://bucket", completionNamesInstanceGroups, "a.example.com")
+	b := completionCacheFile("s3://bucket", completionNamesInstanceGroups, "b.example.com")
+	c := completionCacheFile("s3://other", completionNamesInstanceGroups, "a.example.com")
+	if a == b || a == c {
+		t.Fatalf("expected distinct cache files, got %q, %q and %q", a, b, c)
+	}
+	if a != completionCacheFile("s3://bucket", completionNamesInstanceGroups, "a.example.com") {
+		t.Fatalf("expected cache file to be stable")
+	}
+}
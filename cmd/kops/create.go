@@ -29,7 +29,10 @@ import (
 	"k8s.io/kops/cmd/kops/util"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/v1alpha1"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
 	"k8s.io/kops/util/pkg/vfs"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
@@ -110,6 +113,7 @@ func NewCmdCreate(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateCluster(f, out))
 	cmd.AddCommand(NewCmdCreateInstanceGroup(f, out))
 	cmd.AddCommand(NewCmdCreateSecret(f, out))
+	cmd.AddCommand(NewCmdCreateSSHCredential(f, out))
 	return cmd
 }
 
@@ -128,17 +132,17 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 	//var cSpec = false
 	var sb bytes.Buffer
 	fmt.Fprintf(&sb, "\n")
-	for _, f := range c.Filenames {
+	for _, filename := range c.Filenames {
 		var contents []byte
-		if f == "-" {
+		if filename == "-" {
 			contents, err = ConsumeStdin()
 			if err != nil {
 				return err
 			}
 		} else {
-			contents, err = vfs.Context.ReadFile(f)
+			contents, err = vfs.Context.ReadFile(filename)
 			if err != nil {
-				return fmt.Errorf("error reading file %q: %v", f, err)
+				return fmt.Errorf("error reading file %q: %v", filename, err)
 			}
 		}
 		// TODO: this does not support a JSON array
@@ -150,7 +154,7 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 			}
 			o, gvk, err := codec.Decode(section, defaults, nil)
 			if err != nil {
-				return fmt.Errorf("error parsing file %q: %v", f, err)
+				return fmt.Errorf("error parsing file %q: %v", filename, err)
 			}
 
 			switch v := o.(type) {
@@ -169,6 +173,7 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 					return fmt.Errorf("error creating cluster: %v", err)
 				} else {
 					fmt.Fprintf(&sb, "Created cluster/%s\n", v.ObjectMeta.Name)
+					commands.RecordAuditChange(f, v.ObjectMeta.Name, audit.OperationCreate, "cluster", nil, v)
 					//cSpec = true
 				}
 
@@ -186,6 +191,10 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 					return fmt.Errorf("cluster %q not found", clusterName)
 				}
 
+				if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+					return err
+				}
+
 				_, err = clientset.InstanceGroupsFor(cluster).Create(v)
 				if err != nil {
 					if apierrors.IsAlreadyExists(err) {
@@ -194,6 +203,7 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 					return fmt.Errorf("error creating instanceGroup: %v", err)
 				} else {
 					fmt.Fprintf(&sb, "Created instancegroup/%s\n", v.ObjectMeta.Name)
+					commands.RecordAuditChange(f, clusterName, audit.OperationCreate, "instancegroup/"+v.ObjectMeta.Name, nil, v)
 				}
 
 			case *kopsapi.SSHCredential:
@@ -210,13 +220,17 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 					return err
 				}
 
+				if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+					return err
+				}
+
 				sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
 				if err != nil {
 					return err
 				}
 
 				sshKeyArr := []byte(v.Spec.PublicKey)
-				err = sshCredentialStore.AddSSHPublicKey("admin", sshKeyArr)
+				err = sshCredentialStore.AddSSHPublicKey(sshCredentialName(v), sshKeyArr)
 				if err != nil {
 					return err
 				} else {
@@ -225,7 +239,7 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 
 			default:
 				glog.V(2).Infof("Type of object was %T", v)
-				return fmt.Errorf("Unhandled kind %q in %s", gvk, f)
+				return fmt.Errorf("Unhandled kind %q in %s", gvk, filename)
 			}
 		}
 
@@ -246,6 +260,14 @@ func RunCreate(f *util.Factory, out io.Writer, c *CreateOptions) error {
 	return nil
 }
 
+// sshCredentialName returns the name of an SSHCredential manifest, which defaults to the primary (admin) key
+func sshCredentialName(v *kopsapi.SSHCredential) string {
+	if v.ObjectMeta.Name == "" {
+		return fi.SecretNameSSHPrimary
+	}
+	return v.ObjectMeta.Name
+}
+
 // ConsumeStdin reads all the bytes available from stdin
 func ConsumeStdin() ([]byte, error) {
 	file := os.Stdin
@@ -31,6 +31,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kops"
@@ -40,6 +41,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/kops/validation"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/featureflag"
@@ -113,6 +115,9 @@ type CreateClusterOptions struct {
 	// Specify tags for AWS instance groups
 	CloudLabels string
 
+	// Group is the group of per-region clusters that the cluster joins
+	Group string
+
 	// Egress configuration - FOR TESTING ONLY
 	Egress string
 
@@ -138,10 +143,15 @@ type CreateClusterOptions struct {
 	// We need VSphereDatastore to support Kubernetes vSphere Cloud Provider (v1.5.3)
 	// We can remove this once we support higher versions.
 	VSphereDatastore string
+	// VSphereEtcdDatastore is the datastore of the etcd disks of the masters, typically a vSAN datastore
+	VSphereEtcdDatastore string
 
 	// ConfigBase is the location where we will store the configuration, it defaults to the state store
 	ConfigBase string
 
+	// QuotaCheck fails with --yes before any cloud resources are created if the cloud quotas do not leave room for the cluster
+	QuotaCheck bool
+
 	// DryRun mode output a cluster manifest of Output type.
 	DryRun bool
 	// Output type during a DryRun
@@ -152,6 +162,7 @@ func (o *CreateClusterOptions) InitDefaults() {
 	o.Yes = false
 	o.Target = cloudup.TargetDirect
 	o.Models = strings.Join(cloudup.CloudupModels, ",")
+	o.QuotaCheck = true
 	o.Networking = "kubenet"
 	o.Channel = api.DefaultChannel
 	o.Topology = api.TopologyPublic
@@ -216,6 +227,11 @@ var (
 	--state=s3://kops-state-1234 --zones=eu-west-1a \
 	--node-count=2 --dry-run -oyaml
 
+	# Add a second region to a group of active-active clusters, sharing the labels of the group
+	kops create cluster --name=payments-eu.example.com \
+	--state=s3://kops-state-1234 --zones=eu-west-1a \
+	--group=payments
+
 	`))
 
 	createClusterShort = i18n.T("Create a Kubernetes cluster.")
@@ -263,6 +279,7 @@ func NewCmdCreateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately create the cluster")
 	cmd.Flags().StringVar(&options.Target, "target", options.Target, fmt.Sprintf("Valid targets: %s, %s, %s. Set this flag to %s if you want kops to generate terraform", cloudup.TargetDirect, cloudup.TargetTerraform, cloudup.TargetCloudformation, cloudup.TargetTerraform))
 	cmd.Flags().StringVar(&options.Models, "model", options.Models, "Models to apply (separate multiple models with commas)")
+	cmd.Flags().BoolVar(&options.QuotaCheck, "quota-check", options.QuotaCheck, "Check that the cloud quotas leave room for the cluster before creating any cloud resources")
 
 	// Configuration / state location
 	if featureflag.EnableSeparateConfigBase.Enabled() {
@@ -327,6 +344,8 @@ func NewCmdCreateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	// Allow custom tags from the CLI
 	cmd.Flags().StringVar(&options.CloudLabels, "cloud-labels", options.CloudLabels, "A list of KV pairs used to tag all instance groups in AWS (eg \"Owner=John Doe,Team=Some Team\").")
 
+	cmd.Flags().StringVar(&options.Group, "group", options.Group, "Group of per-region clusters to add the cluster to; it shares the labels of the group, and must be the only cluster of the group in its region.")
+
 	// Master and Node Tenancy
 	cmd.Flags().StringVar(&options.MasterTenancy, "master-tenancy", options.MasterTenancy, "The tenancy of the master group on AWS. Can either be default or dedicated.")
 	cmd.Flags().StringVar(&options.NodeTenancy, "node-tenancy", options.NodeTenancy, "The tenancy of the node group on AWS. Can be either default or dedicated.")
@@ -352,6 +371,7 @@ func NewCmdCreateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		cmd.Flags().StringVar(&options.VSphereResourcePool, "vsphere-resource-pool", options.VSphereDatacenter, "vsphere-resource-pool is required for vSphere. Set a valid Cluster, Host or Resource Pool in which to deploy Kubernetes VMs.")
 		cmd.Flags().StringVar(&options.VSphereCoreDNSServer, "vsphere-coredns-server", options.VSphereCoreDNSServer, "vsphere-coredns-server is required for vSphere.")
 		cmd.Flags().StringVar(&options.VSphereDatastore, "vsphere-datastore", options.VSphereDatastore, "vsphere-datastore is required for vSphere.  Set a valid datastore in which to store dynamic provision volumes.")
+		cmd.Flags().StringVar(&options.VSphereEtcdDatastore, "vsphere-etcd-datastore", options.VSphereEtcdDatastore, "Datastore in which to store the etcd disks of the masters, e.g. a vSAN datastore. Defaults to vsphere-datastore.")
 	}
 	return cmd
 }
@@ -686,6 +706,12 @@ func RunCreateCluster(f *util.Factory, out io.Writer, c *CreateClusterOptions) e
 			etcd := &api.EtcdClusterSpec{}
 			etcd.Name = etcdCluster
 
+			// New clusters use etcd-manager where it is supported, so that etcd can be upgraded by changing the version
+			switch api.CloudProviderID(cluster.Spec.CloudProvider) {
+			case api.CloudProviderAWS, api.CloudProviderGCE:
+				etcd.Manager = &api.EtcdManagerSpec{}
+			}
+
 			var names []string
 			for _, ig := range masters {
 				name := ig.ObjectMeta.Name
@@ -839,6 +865,10 @@ func RunCreateCluster(f *util.Factory, out io.Writer, c *CreateClusterOptions) e
 				return fmt.Errorf("vsphere-datastore is required for vSphere. Set a valid datastore in which to store dynamic provision volumes.")
 			}
 			cluster.Spec.CloudConfig.VSphereDatastore = fi.String(c.VSphereDatastore)
+
+			if c.VSphereEtcdDatastore != "" {
+				cluster.Spec.CloudConfig.VSphereEtcdDatastore = fi.String(c.VSphereEtcdDatastore)
+			}
 		}
 	}
 
@@ -1098,6 +1128,16 @@ func RunCreateCluster(f *util.Factory, out io.Writer, c *CreateClusterOptions) e
 		return err
 	}
 
+	if c.Group != "" {
+		list, err := clientset.ListClusters(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if err := commands.JoinClusterGroup(cluster, c.Group, list.Items); err != nil {
+			return err
+		}
+	}
+
 	assetBuilder := assets.NewAssetBuilder(cluster, "")
 	fullCluster, err := cloudup.PopulateClusterSpec(clientset, cluster, assetBuilder)
 	if err != nil {
@@ -1156,6 +1196,8 @@ func RunCreateCluster(f *util.Factory, out io.Writer, c *CreateClusterOptions) e
 		return fmt.Errorf("error writing completed cluster spec: %v", err)
 	}
 
+	commands.RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationCreate, "cluster", nil, cluster)
+
 	if len(c.SSHPublicKeys) == 0 {
 		autoloadSSHPublicKeys := true
 		switch c.Cloud {
@@ -1207,6 +1249,7 @@ func RunCreateCluster(f *util.Factory, out io.Writer, c *CreateClusterOptions) e
 		updateClusterOptions.Target = c.Target
 		updateClusterOptions.Models = c.Models
 		updateClusterOptions.OutDir = c.OutDir
+		updateClusterOptions.QuotaCheck = c.QuotaCheck
 
 		// SSHPublicKey has already been mapped
 		updateClusterOptions.SSHPublicKey = ""
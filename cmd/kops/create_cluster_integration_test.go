@@ -215,6 +215,8 @@ func runCreateClusterIntegrationTest(t *testing.T, srcDir string, version string
 
 	for _, cluster := range clusters.Items {
 		cluster.ObjectMeta.CreationTimestamp = MagicTimestamp
+		// The resourceVersion is a hash of the stored object, so changes with any change to the defaults
+		cluster.ObjectMeta.ResourceVersion = ""
 		actualYAMLBytes, err := kopscodecs.ToVersionedYamlWithVersion(&cluster, schema.GroupVersion{Group: "kops", Version: version})
 		if err != nil {
 			t.Fatalf("unexpected error serializing cluster: %v", err)
@@ -233,6 +235,7 @@ func runCreateClusterIntegrationTest(t *testing.T, srcDir string, version string
 
 	for _, ig := range instanceGroups.Items {
 		ig.ObjectMeta.CreationTimestamp = MagicTimestamp
+		ig.ObjectMeta.ResourceVersion = ""
 
 		actualYAMLBytes, err := kopscodecs.ToVersionedYamlWithVersion(&ig, schema.GroupVersion{Group: "kops", Version: version})
 		if err != nil {
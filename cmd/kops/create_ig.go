@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,6 +31,8 @@ import (
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/kopscodecs"
 	"k8s.io/kops/pkg/try"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
@@ -47,6 +50,10 @@ type CreateInstanceGroupOptions struct {
 	Output string
 	// Edit will launch an editor when creating an instance group
 	Edit bool
+	// From is the name of an existing instance group whose spec is copied
+	From string
+	// Template is the path to a YAML file containing the instance group to create
+	Template string
 }
 
 var (
@@ -67,6 +74,14 @@ var (
 		# Create a YAML manifest for an instancegroup for the k8s-cluster.example.com cluster.
 		kops create ig --name=k8s-cluster.example.com node-example \
 		  --role node --subnet my-subnet-name --dry-run -oyaml
+
+		# Create an instancegroup in another zone, copying the spec of the nodes instancegroup.
+		kops create ig --name=k8s-cluster.example.com nodes-us-east-1c \
+		  --from nodes --subnet us-east-1c
+
+		# Create an instancegroup from a YAML file.
+		kops create ig --name=k8s-cluster.example.com gpu-nodes \
+		  --template gpu-nodes.yaml --edit=false
 		`))
 
 	createIgShort = i18n.T(`Create an instancegroup.`)
@@ -105,6 +120,10 @@ func NewCmdCreateInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().BoolVar(&options.DryRun, "dry-run", options.DryRun, "If true, only print the object that would be sent, without sending it. This flag can be used to create a cluster YAML or JSON manifest.")
 	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format. One of json|yaml")
 	cmd.Flags().BoolVar(&options.Edit, "edit", options.Edit, "If true, an editor will be opened to edit default values.")
+	cmd.Flags().StringVar(&options.From, "from", options.From, "Name of an existing instance group to copy the spec from. The role and subnets can be overridden with --role and --subnet.")
+	cmd.Flags().StringVar(&options.Template, "template", options.Template, "Path to a YAML file containing the instance group spec. The role and subnets can be overridden with --role and --subnet.")
+	cmd.MarkFlagCustom("from", "__kops_get_instancegroups")
+	cmd.MarkFlagFilename("template", "yaml", "yml")
 
 	return cmd
 }
@@ -123,6 +142,10 @@ func RunCreateInstanceGroup(f *util.Factory, cmd *cobra.Command, args []string,
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	clientset, err := rootCommand.Clientset()
 	if err != nil {
 		return err
@@ -145,17 +168,40 @@ func RunCreateInstanceGroup(f *util.Factory, cmd *cobra.Command, args []string,
 		return fmt.Errorf("instance group %q already exists", groupName)
 	}
 
+	if options.From != "" && options.Template != "" {
+		return fmt.Errorf("cannot specify both --from and --template")
+	}
+
 	// Populate some defaults
 	ig := &api.InstanceGroup{}
+	if options.From != "" {
+		source, err := clientset.InstanceGroupsFor(cluster).Get(options.From, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error reading InstanceGroup %q: %v", options.From, err)
+		}
+		if source == nil {
+			return fmt.Errorf("InstanceGroup %q not found", options.From)
+		}
+		ig = copyInstanceGroup(source, groupName)
+	} else if options.Template != "" {
+		ig, err = readInstanceGroupTemplate(options.Template, groupName)
+		if err != nil {
+			return err
+		}
+	}
 	ig.ObjectMeta.Name = groupName
 
-	role, ok := api.ParseInstanceGroupRole(options.Role, true)
-	if !ok {
-		return fmt.Errorf("unknown role %q", options.Role)
+	if ig.Spec.Role == "" || cmd.Flags().Changed("role") {
+		role, ok := api.ParseInstanceGroupRole(options.Role, true)
+		if !ok {
+			return fmt.Errorf("unknown role %q", options.Role)
+		}
+		ig.Spec.Role = role
 	}
-	ig.Spec.Role = role
 
-	ig.Spec.Subnets = options.Subnets
+	if len(options.Subnets) != 0 || (options.From == "" && options.Template == "") {
+		ig.Spec.Subnets = options.Subnets
+	}
 
 	ig, err = cloudup.PopulateInstanceGroupSpec(cluster, ig, channel)
 	if err != nil {
@@ -221,18 +267,54 @@ func RunCreateInstanceGroup(f *util.Factory, cmd *cobra.Command, args []string,
 			return fmt.Errorf("unexpected object type: %T", obj)
 		}
 
-		err = validation.ValidateInstanceGroup(group)
-		if err != nil {
-			return err
-		}
-
 		ig = group
 	}
 
+	err = validation.ValidateInstanceGroup(ig)
+	if err != nil {
+		return err
+	}
+
 	_, err = clientset.InstanceGroupsFor(cluster).Create(ig)
 	if err != nil {
 		return fmt.Errorf("error storing InstanceGroup: %v", err)
 	}
 
+	commands.RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationCreate, "instancegroup/"+ig.ObjectMeta.Name, nil, ig)
+
 	return nil
 }
+
+// copyInstanceGroup returns a copy of the spec of the source instance group, named name.
+// Only the labels of the source metadata are kept.
+func copyInstanceGroup(source *api.InstanceGroup, name string) *api.InstanceGroup {
+	ig := &api.InstanceGroup{}
+	ig.ObjectMeta.Name = name
+	for k, v := range source.ObjectMeta.Labels {
+		if ig.ObjectMeta.Labels == nil {
+			ig.ObjectMeta.Labels = make(map[string]string)
+		}
+		ig.ObjectMeta.Labels[k] = v
+	}
+	source.Spec.DeepCopyInto(&ig.Spec)
+	return ig
+}
+
+// readInstanceGroupTemplate parses the instance group in the YAML file, naming it name
+func readInstanceGroupTemplate(p string, name string) (*api.InstanceGroup, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template %q: %v", p, err)
+	}
+
+	obj, _, err := kopscodecs.ParseVersionedYaml(b)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %q: %v", p, err)
+	}
+	ig, ok := obj.(*api.InstanceGroup)
+	if !ok {
+		return nil, fmt.Errorf("template %q contains a %T, expected an InstanceGroup", p, obj)
+	}
+
+	return copyInstanceGroup(ig, name), nil
+}
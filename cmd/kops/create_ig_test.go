@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestCopyInstanceGroup(t *testing.T) {
+	source := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "nodes",
+			Labels:          map[string]string{api.LabelClusterName: "cluster.example.com"},
+			ResourceVersion: "12",
+		},
+		Spec: api.InstanceGroupSpec{
+			Role:        api.InstanceGroupRoleNode,
+			MachineType: "m4.large",
+			MaxSize:     fi.Int32(5),
+			Subnets:     []string{"us-east-1a"},
+			NodeLabels:  map[string]string{api.NodeLabelInstanceGroup: "nodes"},
+		},
+	}
+
+	ig := copyInstanceGroup(source, "newnodes")
+	ig.Spec.Subnets = []string{"us-east-1c"}
+	ig.AddInstanceGroupNodeLabel()
+
+	if ig.ObjectMeta.Name != "newnodes" || ig.ObjectMeta.ResourceVersion != "" {
+		t.Errorf("unexpected metadata: %v", ig.ObjectMeta)
+	}
+	if !reflect.DeepEqual(ig.ObjectMeta.Labels, source.ObjectMeta.Labels) {
+		t.Errorf("unexpected labels: %v", ig.ObjectMeta.Labels)
+	}
+	if ig.Spec.MachineType != "m4.large" || fi.Int32Value(ig.Spec.MaxSize) != 5 {
+		t.Errorf("spec was not copied: %v", ig.Spec)
+	}
+	if ig.Spec.NodeLabels[api.NodeLabelInstanceGroup] != "newnodes" {
+		t.Errorf("unexpected node labels: %v", ig.Spec.NodeLabels)
+	}
+
+	// The source must not be modified
+	if source.Spec.Subnets[0] != "us-east-1a" || source.Spec.NodeLabels[api.NodeLabelInstanceGroup] != "nodes" {
+		t.Errorf("source was modified: %v", source.Spec)
+	}
+}
+
+func TestReadInstanceGroupTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "create-ig")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "ig.yaml")
+	template := `apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: template
+spec:
+  role: Node
+  machineType: p2.xlarge
+  maxSize: 2
+  minSize: 0
+  subnets:
+  - us-east-1a
+`
+	if err := ioutil.WriteFile(p, []byte(template), 0644); err != nil {
+		t.Fatalf("error writing template: %v", err)
+	}
+
+	ig, err := readInstanceGroupTemplate(p, "gpu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ig.ObjectMeta.Name != "gpu" {
+		t.Errorf("unexpected name %q", ig.ObjectMeta.Name)
+	}
+	if ig.Spec.Role != api.InstanceGroupRoleNode || ig.Spec.MachineType != "p2.xlarge" || fi.Int32Value(ig.Spec.MaxSize) != 2 {
+		t.Errorf("unexpected spec: %v", ig.Spec)
+	}
+
+	if _, err := readInstanceGroupTemplate(filepath.Join(dir, "missing.yaml"), "gpu"); err == nil {
+		t.Errorf("expected error reading missing template")
+	}
+}
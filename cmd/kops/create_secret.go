@@ -39,6 +39,9 @@ var (
 
 	kops create secret encryptionconfig -f ~/.encryptionconfig.yaml \
 		--name k8s-cluster.example.com --state s3://example.com
+
+	kops create secret registrycredentials mirror-credentials --username mirror --password-file ~/.mirror-password \
+		--name k8s-cluster.example.com --state s3://example.com
 	`))
 
 	createSecretShort = i18n.T(`Create a secret.`)
@@ -56,6 +59,7 @@ func NewCmdCreateSecret(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateSecretPublicKey(f, out))
 	cmd.AddCommand(NewCmdCreateSecretDockerConfig(f, out))
 	cmd.AddCommand(NewCmdCreateSecretEncryptionConfig(f, out))
+	cmd.AddCommand(NewCmdCreateSecretRegistryCredentials(f, out))
 	cmd.AddCommand(NewCmdCreateKeypairSecret(f, out))
 	cmd.AddCommand(NewCmdCreateSecretWeaveEncryptionConfig(f, out))
 
@@ -25,6 +25,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -101,6 +103,10 @@ func RunCreateSecretDockerConfig(f *util.Factory, out io.Writer, options *Create
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return err
@@ -139,5 +145,11 @@ func RunCreateSecretDockerConfig(f *util.Factory, out io.Writer, options *Create
 		}
 	}
 
+	operation := audit.OperationCreate
+	if options.Force {
+		operation = audit.OperationReplace
+	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, operation, "secret/dockerconfig", "")
+
 	return nil
 }
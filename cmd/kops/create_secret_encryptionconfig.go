@@ -25,6 +25,8 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/kops/cmd/kops/util"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -102,6 +104,10 @@ func RunCreateSecretEncryptionConfig(f *util.Factory, out io.Writer, options *Cr
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return err
@@ -140,5 +146,11 @@ func RunCreateSecretEncryptionConfig(f *util.Factory, out io.Writer, options *Cr
 		}
 	}
 
+	operation := audit.OperationCreate
+	if options.Force {
+		operation = audit.OperationReplace
+	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, operation, "secret/encryptionconfig", "")
+
 	return nil
 }
@@ -26,6 +26,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/pki"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/utils"
@@ -99,6 +101,10 @@ func RunCreateSecretCaCert(f *util.Factory, out io.Writer, options *CreateSecret
 		return fmt.Errorf("error getting cluster: %q: %v", options.ClusterName, err)
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	clientSet, err := f.Clientset()
 	if err != nil {
 		return fmt.Errorf("error getting clientset: %v", err)
@@ -134,6 +140,7 @@ func RunCreateSecretCaCert(f *util.Factory, out io.Writer, options *CreateSecret
 	if err != nil {
 		return fmt.Errorf("error storing user provided keys %q %q: %v", options.CaCertPath, options.CaPrivateKeyPath, err)
 	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationCreate, "keypair/"+fi.CertificateId_CA, "")
 
 	glog.Infof("using user provided cert: %v\n", options.CaCertPath)
 	glog.Infof("using user provided private key: %v\n", options.CaPrivateKeyPath)
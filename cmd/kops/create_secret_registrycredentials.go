@@ -0,0 +1,160 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	createSecretRegistryCredentialsLong = templates.LongDesc(i18n.T(`
+	Create the credentials for a container registry mirror, and store them in the state store.
+	The credentials are referenced by name from spec.containerRegistryMirrors, and written into the
+	docker config on each master or node.`))
+
+	createSecretRegistryCredentialsExample = templates.Examples(i18n.T(`
+	# Create the credentials for a registry mirror.
+	kops create secret registrycredentials mirror-credentials --username mirror --password-file /path/to/password \
+		--name k8s-cluster.example.com --state s3://example.com
+	# Replace existing registry mirror credentials.
+	kops create secret registrycredentials mirror-credentials --username mirror --password-file /path/to/password --force \
+		--name k8s-cluster.example.com --state s3://example.com
+	`))
+
+	createSecretRegistryCredentialsShort = i18n.T(`Create the credentials for a container registry mirror.`)
+)
+
+type CreateSecretRegistryCredentialsOptions struct {
+	ClusterName  string
+	SecretName   string
+	Username     string
+	PasswordPath string
+	Force        bool
+}
+
+func NewCmdCreateSecretRegistryCredentials(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &CreateSecretRegistryCredentialsOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "registrycredentials",
+		Short:   createSecretRegistryCredentialsShort,
+		Long:    createSecretRegistryCredentialsLong,
+		Example: createSecretRegistryCredentialsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("syntax: NAME --username <username> --password-file <PasswordPath>"))
+			}
+			options.SecretName = args[0]
+
+			err := rootCommand.ProcessArgs(args[1:])
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err = RunCreateSecretRegistryCredentials(f, os.Stdout, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Username, "username", "", "Username for the registry mirror")
+	cmd.Flags().StringVar(&options.PasswordPath, "password-file", "", "Path to a file holding the password for the registry mirror")
+	cmd.Flags().BoolVar(&options.Force, "force", options.Force, "Force replace the kops secret if it already exists")
+
+	return cmd
+}
+
+func RunCreateSecretRegistryCredentials(f *util.Factory, out io.Writer, options *CreateSecretRegistryCredentialsOptions) error {
+	if options.SecretName == "" {
+		return fmt.Errorf("secret name is required")
+	}
+	if options.Username == "" {
+		return fmt.Errorf("username is required (use --username)")
+	}
+	if strings.Contains(options.Username, ":") {
+		return fmt.Errorf("username must not contain ':'")
+	}
+	if options.PasswordPath == "" {
+		return fmt.Errorf("password file is required (use --password-file)")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	password, err := ioutil.ReadFile(options.PasswordPath)
+	if err != nil {
+		return fmt.Errorf("error reading password %v: %v", options.PasswordPath, err)
+	}
+
+	secret := &fi.Secret{
+		Data: []byte(options.Username + ":" + strings.TrimSpace(string(password))),
+	}
+
+	if !options.Force {
+		_, created, err := secretStore.GetOrCreateSecret(options.SecretName, secret)
+		if err != nil {
+			return fmt.Errorf("error adding registry credentials secret: %v", err)
+		}
+		if !created {
+			return fmt.Errorf("failed to create the %s secret as it already exists. The `--force` flag can be passed to replace an existing secret.", options.SecretName)
+		}
+	} else {
+		_, err := secretStore.ReplaceSecret(options.SecretName, secret)
+		if err != nil {
+			return fmt.Errorf("error updating registry credentials secret: %v", err)
+		}
+	}
+
+	operation := audit.OperationCreate
+	if options.Force {
+		operation = audit.OperationReplace
+	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, operation, "secret/"+options.SecretName, "")
+
+	return nil
+}
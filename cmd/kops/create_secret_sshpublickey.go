@@ -24,6 +24,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
 )
@@ -98,6 +100,10 @@ func RunCreateSecretPublicKey(f *util.Factory, out io.Writer, options *CreateSec
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return err
@@ -117,6 +123,7 @@ func RunCreateSecretPublicKey(f *util.Factory, out io.Writer, options *CreateSec
 	if err != nil {
 		return fmt.Errorf("error adding SSH public key: %v", err)
 	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationCreate, "sshcredential/"+options.Name, "")
 
 	return nil
 }
@@ -24,6 +24,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -101,6 +103,10 @@ func RunCreateSecretWeaveEncryptionConfig(f *util.Factory, options *CreateSecret
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return err
@@ -135,5 +141,11 @@ func RunCreateSecretWeaveEncryptionConfig(f *util.Factory, options *CreateSecret
 		}
 	}
 
+	operation := audit.OperationCreate
+	if options.Force {
+		operation = audit.OperationReplace
+	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, operation, "secret/weavepassword", "")
+
 	return nil
 }
@@ -0,0 +1,197 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/sshcredentials"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	createSSHCredentialLong = templates.LongDesc(i18n.T(`
+	Create an SSH credential, authorizing an SSH public key on the instances of the cluster.
+
+	The admin credential is the primary key, which the cloud installs on the instances.  Any
+	other credentials are authorized for the same user alongside it, so that several people
+	or systems can each have their own key.
+
+	Use --replace to rotate the key of an existing credential.  The instances pick up a new
+	or rotated key when they are replaced: run kops update cluster, then kops rolling-update
+	cluster, which will report the instance groups as needing an update.`))
+
+	createSSHCredentialExample = templates.Examples(i18n.T(`
+	# Create the primary SSH credential
+	kops create sshcredential admin -i ~/.ssh/id_rsa.pub --name k8s-cluster.example.com
+
+	# Authorize an additional key
+	kops create sshcredential alice -i alice.pub --name k8s-cluster.example.com
+
+	# Rotate the primary key, and replace the instances
+	kops create sshcredential admin -i new_id_rsa.pub --replace --name k8s-cluster.example.com
+	kops update cluster k8s-cluster.example.com --yes
+	kops rolling-update cluster k8s-cluster.example.com --yes
+	`))
+
+	createSSHCredentialShort = i18n.T(`Create an SSH credential.`)
+)
+
+type CreateSSHCredentialOptions struct {
+	ClusterName   string
+	Name          string
+	PublicKeyPath string
+
+	// Replace removes the existing keys of the credential, rotating it
+	Replace bool
+}
+
+func NewCmdCreateSSHCredential(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &CreateSSHCredentialOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "sshcredential NAME -i PUBLIC_KEY_FILE",
+		Short:   createSSHCredentialShort,
+		Long:    createSSHCredentialLong,
+		Example: createSSHCredentialExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("syntax: NAME -i <PublicKeyPath>"))
+			}
+			options.Name = args[0]
+
+			err := rootCommand.ProcessArgs(args[1:])
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err = RunCreateSSHCredential(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.PublicKeyPath, "pubkey", "i", "", "Path to SSH public key")
+	cmd.Flags().BoolVar(&options.Replace, "replace", options.Replace, "Replace the existing key of the credential")
+
+	return cmd
+}
+
+func RunCreateSSHCredential(f *util.Factory, out io.Writer, options *CreateSSHCredentialOptions) error {
+	if options.PublicKeyPath == "" {
+		return fmt.Errorf("public key path is required (use -i)")
+	}
+
+	if options.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(options.PublicKeyPath)
+	if err != nil {
+		return fmt.Errorf("error reading SSH public key %v: %v", options.PublicKeyPath, err)
+	}
+
+	fingerprint, err := sshcredentials.Fingerprint(string(data))
+	if err != nil {
+		return fmt.Errorf("error parsing SSH public key %v: %v", options.PublicKeyPath, err)
+	}
+
+	existing, err := sshCredentialStore.FindSSHPublicKeys(options.Name)
+	if err != nil {
+		return fmt.Errorf("error reading SSH credential %q: %v", options.Name, err)
+	}
+
+	var replaced []*kopsapi.SSHCredential
+	for _, k := range existing {
+		if strings.TrimSpace(k.Spec.PublicKey) == strings.TrimSpace(string(data)) {
+			fmt.Fprintf(out, "SSH credential %q already has key %s\n", options.Name, fingerprint)
+			return nil
+		}
+		replaced = append(replaced, k)
+	}
+	if len(replaced) != 0 && !options.Replace {
+		return fmt.Errorf("SSH credential %q already exists with a different key; use --replace to rotate it", options.Name)
+	}
+
+	err = sshCredentialStore.AddSSHPublicKey(options.Name, data)
+	if err != nil {
+		return fmt.Errorf("error adding SSH public key: %v", err)
+	}
+
+	operation := audit.OperationCreate
+	if len(replaced) != 0 {
+		operation = audit.OperationReplace
+		for _, k := range replaced {
+			k.Name = options.Name
+			if err := deleteReplacedSSHCredential(sshCredentialStore, k); err != nil {
+				return err
+			}
+		}
+	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, operation, "sshcredential/"+options.Name, "")
+
+	fmt.Fprintf(out, "SSH credential %q set to key %s\n", options.Name, fingerprint)
+	fmt.Fprintf(out, "\nThe key is authorized on instances created after the next update:\n")
+	fmt.Fprintf(out, " * run kops update cluster %s --yes\n", cluster.ObjectMeta.Name)
+	fmt.Fprintf(out, " * then kops rolling-update cluster %s --yes to replace the existing instances\n", cluster.ObjectMeta.Name)
+
+	return nil
+}
+
+// deleteReplacedSSHCredential removes a key replaced by a rotation.  The clientset store keeps a single key per
+// credential, so it was already overwritten and there is nothing left to delete.
+func deleteReplacedSSHCredential(sshCredentialStore fi.SSHCredentialStore, k *kopsapi.SSHCredential) error {
+	if _, ok := sshCredentialStore.(*fi.ClientsetCAStore); ok {
+		return nil
+	}
+	if err := sshCredentialStore.DeleteSSHCredential(k); err != nil {
+		return fmt.Errorf("error removing the replaced key of SSH credential %q: %v", k.Name, err)
+	}
+	return nil
+}
@@ -134,6 +134,7 @@ func RunDelete(factory *util.Factory, out io.Writer, d *DeleteOptions) error {
 					ClusterName: v.ObjectMeta.Labels[kopsapi.LabelClusterName],
 					Yes:         d.Yes,
 				}
+				options.InitDefaults()
 
 				// If the cluster has been already deleted we cannot delete the ig
 				if deletedClusters.Has(options.ClusterName) {
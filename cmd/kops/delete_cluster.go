@@ -17,13 +17,18 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/approval"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/kubeconfig"
 	"k8s.io/kops/pkg/resources"
 	resourceops "k8s.io/kops/pkg/resources/ops"
@@ -121,6 +126,30 @@ func RunDeleteCluster(f *util.Factory, out io.Writer, options *DeleteClusterOpti
 		}
 	}
 
+	if options.Yes && cluster != nil {
+		if err := commands.CheckClusterLock(f, clusterName); err != nil {
+			return err
+		}
+	}
+
+	// If the cluster requires approval and no plan has been approved, list what would be deleted and record it as a plan
+	var gate *approvalGate
+	if options.Yes && cluster != nil {
+		gate, err = newDeleteClusterApprovalGate(f, cluster)
+		if err != nil {
+			return err
+		}
+	}
+	approvalPending := gate != nil && !gate.Approved()
+	if gate != nil && gate.Approved() {
+		// The plan is used up before anything is deleted, as deleting the cluster also deletes the secret plans are signed with
+		if err := gate.Executed(); err != nil {
+			return err
+		}
+	}
+	var summary bytes.Buffer
+	fmt.Fprintf(&summary, "Delete cluster %q\n", clusterName)
+
 	wouldDeleteCloudResources := false
 
 	if !options.Unregister {
@@ -164,7 +193,7 @@ func RunDeleteCluster(f *util.Factory, out io.Writer, options *DeleteClusterOpti
 				l = append(l, v)
 			}
 
-			err := t.Render(l, out, "TYPE", "NAME", "ID")
+			err := t.Render(l, io.MultiWriter(out, &summary), "TYPE", "NAME", "ID")
 			if err != nil {
 				return err
 			}
@@ -173,6 +202,9 @@ func RunDeleteCluster(f *util.Factory, out io.Writer, options *DeleteClusterOpti
 				fmt.Fprintf(out, "\nMust specify --yes to delete cluster\n")
 				return nil
 			}
+			if approvalPending {
+				return gate.Request(out, clusterName, summary.String())
+			}
 
 			fmt.Fprintf(out, "\n")
 
@@ -192,6 +224,9 @@ func RunDeleteCluster(f *util.Factory, out io.Writer, options *DeleteClusterOpti
 			}
 			return nil
 		}
+		if approvalPending {
+			return gate.Request(out, clusterName, summary.String())
+		}
 		clientset, err := f.Clientset()
 		if err != nil {
 			return err
@@ -200,6 +235,8 @@ func RunDeleteCluster(f *util.Factory, out io.Writer, options *DeleteClusterOpti
 		if err != nil {
 			return fmt.Errorf("error removing cluster from state store: %v", err)
 		}
+
+		commands.RecordAuditChange(f, clusterName, audit.OperationDelete, "cluster", cluster, nil)
 	}
 
 	b := kubeconfig.NewKubeconfigBuilder()
@@ -212,3 +249,22 @@ func RunDeleteCluster(f *util.Factory, out io.Writer, options *DeleteClusterOpti
 	fmt.Fprintf(out, "\nDeleted cluster: %q\n", clusterName)
 	return nil
 }
+
+// newDeleteClusterApprovalGate returns the approval gate for deleting the cluster, or nil if it does not require approval
+func newDeleteClusterApprovalGate(f *util.Factory, cluster *api.Cluster) (*approvalGate, error) {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	return newApprovalGate(f, cluster, instanceGroups, approval.OperationDeleteCluster)
+}
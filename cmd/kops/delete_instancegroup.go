@@ -18,15 +18,21 @@ package main
 
 import (
 	"fmt"
-
 	"io"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/pkg/retrypolicy"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/kutil"
 	"k8s.io/kops/util/pkg/ui"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -36,7 +42,12 @@ var (
 	deleteIgLong = templates.LongDesc(i18n.T(`
 		Delete an instancegroup configuration.  kops has the concept of "instance groups",
 		which are a group of similar virtual machines. On AWS, they map to an
-		AutoScalingGroup. An ig work either as a Kubernetes master or a node.`))
+		AutoScalingGroup. An ig work either as a Kubernetes master or a node.
+
+		Before the cloud resources are deleted, the nodes in the instance group are
+		cordoned and drained, respecting any PodDisruptionBudgets, and kops waits for
+		the evicted pods to be scheduled on other nodes. Use --cloudonly to delete the
+		cloud resources without draining the nodes.`))
 
 	deleteIgExample = templates.Examples(i18n.T(`
 
@@ -44,6 +55,10 @@ var (
 		# The --yes option runs the command immediately.
 		# Note that the cloud resources will be deleted immediately, without running "kops update cluster"
 		kops delete ig --name=k8s-cluster.example.com node-example --yes
+
+		# Delete an instancegroup without draining its nodes, for example
+		# when the kubernetes API is unreachable.
+		kops delete ig --name=k8s-cluster.example.com node-example --yes --cloudonly
 		`))
 
 	deleteIgShort = i18n.T(`Delete instancegroup`)
@@ -53,10 +68,25 @@ type DeleteInstanceGroupOptions struct {
 	Yes         bool
 	ClusterName string
 	GroupName   string
+
+	// CloudOnly deletes the cloud resources without draining the nodes first
+	CloudOnly bool
+
+	// DrainTimeout is the maximum time to wait for each node to drain
+	DrainTimeout time.Duration
+
+	// RescheduleTimeout is the maximum time to wait for evicted pods to be scheduled on other nodes; zero does not wait
+	RescheduleTimeout time.Duration
+}
+
+func (o *DeleteInstanceGroupOptions) InitDefaults() {
+	o.DrainTimeout = 5 * time.Minute
+	o.RescheduleTimeout = 5 * time.Minute
 }
 
 func NewCmdDeleteInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
 	options := &DeleteInstanceGroupOptions{}
+	options.InitDefaults()
 
 	cmd := &cobra.Command{
 		Use:     "instancegroup",
@@ -106,15 +136,15 @@ func NewCmdDeleteInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately delete the instance group")
+	cmd.Flags().BoolVar(&options.CloudOnly, "cloudonly", options.CloudOnly, "Delete the cloud resources without draining the nodes in the instance group")
+	cmd.Flags().DurationVar(&options.DrainTimeout, "drain-timeout", options.DrainTimeout, "Maximum time to wait for each node to drain (0 means no limit)")
+	cmd.Flags().DurationVar(&options.RescheduleTimeout, "reschedule-timeout", options.RescheduleTimeout, "Maximum time to wait for evicted pods to be scheduled on other nodes (0 means do not wait)")
 
 	return cmd
 }
 
 // RunDeleteInstanceGroup runs the deletion of an instance group
 func RunDeleteInstanceGroup(f *util.Factory, out io.Writer, options *DeleteInstanceGroupOptions) error {
-
-	// TODO make this drain and validate the ig?
-	// TODO implement drain and validate logic
 	groupName := options.GroupName
 	if groupName == "" {
 		return fmt.Errorf("GroupName is required")
@@ -155,16 +185,42 @@ func RunDeleteInstanceGroup(f *util.Factory, out io.Writer, options *DeleteInsta
 		return nil
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	d := &instancegroups.DeleteInstanceGroup{}
 	d.Cluster = cluster
 	d.Cloud = cloud
 	d.Clientset = clientset
+	d.CloudOnly = options.CloudOnly
+	d.DrainTimeout = options.DrainTimeout
+	d.RescheduleTimeout = options.RescheduleTimeout
+
+	if !options.CloudOnly {
+		contextName := cluster.ObjectMeta.Name
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			clientcmd.NewDefaultClientConfigLoadingRules(),
+			&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+		if err != nil {
+			return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+		}
+		retrypolicy.ConfigureKubernetes(config)
+
+		d.K8sClient, err = kubernetes.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("cannot build kube client for %q: %v", contextName, err)
+		}
+		d.ClientConfig = kutil.NewClientConfig(config, "kube-system")
+	}
 
 	err = d.DeleteInstanceGroup(group)
 	if err != nil {
 		return err
 	}
 
+	commands.RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationDelete, "instancegroup/"+group.ObjectMeta.Name, group, nil)
+
 	fmt.Fprintf(out, "\nDeleted InstanceGroup: %q\n", group.ObjectMeta.Name)
 
 	return nil
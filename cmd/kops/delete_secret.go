@@ -23,6 +23,8 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/kops/cmd/kops/util"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -33,8 +35,11 @@ var (
 		Delete a secret.`))
 
 	deleteSecretExample = templates.Examples(i18n.T(`
+		# Delete the docker registry credentials
+		kops delete secret dockerconfig dockerconfig
 
-		`))
+		# Delete a keypair, specifying the id when there are several
+		kops delete secret keypair kubelet 6523071283218479466954306131`))
 
 	deleteSecretShort = i18n.T(`Delete a secret`)
 )
@@ -98,6 +103,10 @@ func RunDeleteSecret(f *util.Factory, out io.Writer, options *DeleteSecretOption
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	keyStore, err := clientset.KeyStore(cluster)
 	if err != nil {
 		return err
@@ -138,7 +147,7 @@ func RunDeleteSecret(f *util.Factory, out io.Writer, options *DeleteSecretOption
 	}
 
 	switch secrets[0].Type {
-	case kops.SecretTypeSecret:
+	case kops.SecretTypeSecret, SecretTypeDockerConfig, SecretTypeEncryptionConfig:
 		err = secretStore.DeleteSecret(secrets[0].Name)
 	case SecretTypeSSHPublicKey:
 		sshCredential := &kops.SSHCredential{}
@@ -147,15 +156,18 @@ func RunDeleteSecret(f *util.Factory, out io.Writer, options *DeleteSecretOption
 			sshCredential.Spec.PublicKey = string(secrets[0].Data)
 		}
 		err = sshCredentialStore.DeleteSSHCredential(sshCredential)
-	default:
+	case kops.SecretTypeKeypair, SecretTypeCA:
 		keyset := &kops.Keyset{}
 		keyset.Name = secrets[0].Name
-		keyset.Spec.Type = secrets[0].Type
+		keyset.Spec.Type = kops.SecretTypeKeypair
 		err = keyStore.DeleteKeysetItem(keyset, secrets[0].Id)
+	default:
+		return fmt.Errorf("unhandled secret type %q", secrets[0].Type)
 	}
 	if err != nil {
 		return fmt.Errorf("error deleting secret: %v", err)
 	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationDelete, string(secrets[0].Type)+"/"+secrets[0].Name, "")
 
 	return nil
 }
@@ -0,0 +1,46 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/discoverycache"
+)
+
+// configureDiscoveryCache enables the on-disk cache of cloud discovery results for the cluster.
+// It must be called before the cloud is built; a zero ttl disables the cache.
+func configureDiscoveryCache(clusterName string, ttl time.Duration, refresh bool) error {
+	if ttl <= 0 {
+		discoverycache.Configure(discoverycache.Options{})
+		return nil
+	}
+
+	dir, err := discoverycache.DefaultDir(clusterName)
+	if err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("caching cloud discovery results in %s for %v (refresh=%v)", dir, ttl, refresh)
+	discoverycache.Configure(discoverycache.Options{
+		Dir:     dir,
+		TTL:     ttl,
+		Refresh: refresh,
+	})
+	return nil
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/kops/validation"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/edit"
 	"k8s.io/kops/pkg/kopscodecs"
@@ -42,6 +43,8 @@ import (
 )
 
 type EditClusterOptions struct {
+	// Force overwrites the cluster even if it was changed by someone else during the edit
+	Force bool
 }
 
 var (
@@ -52,7 +55,10 @@ var (
     	To set your preferred editor, you can define the EDITOR environment variable.
     	When you have done this, kops will use the editor that you have set.
 
-	kops edit does not update the cloud resources, to apply the changes use "kops update cluster".`))
+	kops edit does not update the cloud resources, to apply the changes use "kops update cluster".
+
+	If the cluster is changed by someone else while it is being edited, the edit fails rather than
+	overwriting their changes; use --force to overwrite them.`))
 
 	editClusterExample = templates.Examples(i18n.T(`
 		# Edit a cluster configuration in AWS.
@@ -76,6 +82,8 @@ func NewCmdEditCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&options.Force, "force", options.Force, "Overwrite the cluster even if it was changed since it was read")
+
 	return cmd
 }
 
@@ -90,6 +98,10 @@ func RunEditCluster(f *util.Factory, cmd *cobra.Command, args []string, out io.W
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, oldCluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	err = oldCluster.FillDefaults()
 	if err != nil {
 		return err
@@ -241,6 +253,12 @@ func RunEditCluster(f *util.Factory, cmd *cobra.Command, args []string, out io.W
 			return err
 		}
 
+		// Fail if the cluster was changed since we read it, unless forced
+		newCluster.ObjectMeta.ResourceVersion = oldCluster.ObjectMeta.ResourceVersion
+		if options.Force {
+			newCluster.ObjectMeta.ResourceVersion = ""
+		}
+
 		// Note we perform as much validation as we can, before writing a bad config
 		_, err = clientset.UpdateCluster(newCluster, status)
 		if err != nil {
@@ -252,6 +270,8 @@ func RunEditCluster(f *util.Factory, cmd *cobra.Command, args []string, out io.W
 			return preservedFile(fmt.Errorf("error writing completed cluster spec: %v", err), file, out)
 		}
 
+		commands.RecordAuditChange(f, newCluster.ObjectMeta.Name, audit.OperationEdit, "cluster", oldCluster, newCluster)
+
 		return nil
 	}
 }
@@ -29,6 +29,8 @@ import (
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/validation"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/kopscodecs"
 	"k8s.io/kops/pkg/try"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
@@ -45,7 +47,10 @@ var (
     	To set your preferred editor, you can define the EDITOR environment variable.
     	When you have done this, kops will use the editor that you have set.
 
-	kops edit does not update the cloud resources, to apply the changes use "kops update cluster".`))
+	kops edit does not update the cloud resources, to apply the changes use "kops update cluster".
+
+	If the instancegroup is changed by someone else while it is being edited, the edit fails rather than
+	overwriting their changes; use --force to overwrite them.`))
 
 	editInstancegroupExample = templates.Examples(i18n.T(`
 	# Edit an instancegroup desired configuration.
@@ -56,6 +61,8 @@ var (
 )
 
 type EditInstanceGroupOptions struct {
+	// Force overwrites the instance group even if it was changed by someone else during the edit
+	Force bool
 }
 
 func NewCmdEditInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
@@ -76,6 +83,8 @@ func NewCmdEditInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&options.Force, "force", options.Force, "Overwrite the instance group even if it was changed since it was read")
+
 	return cmd
 }
 
@@ -94,6 +103,10 @@ func RunEditInstanceGroup(f *util.Factory, cmd *cobra.Command, args []string, ou
 		return err
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	channel, err := cloudup.ChannelForCluster(cluster)
 	if err != nil {
 		return err
@@ -180,11 +193,19 @@ func RunEditInstanceGroup(f *util.Factory, cmd *cobra.Command, args []string, ou
 		return err
 	}
 
+	// Fail if the instance group was changed since we read it, unless forced
+	fullGroup.ObjectMeta.ResourceVersion = oldGroup.ObjectMeta.ResourceVersion
+	if options.Force {
+		fullGroup.ObjectMeta.ResourceVersion = ""
+	}
+
 	// Note we perform as much validation as we can, before writing a bad config
 	_, err = clientset.InstanceGroupsFor(cluster).Update(fullGroup)
 	if err != nil {
 		return err
 	}
 
+	commands.RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationEdit, "instancegroup/"+fullGroup.ObjectMeta.Name, oldGroup, fullGroup)
+
 	return nil
 }
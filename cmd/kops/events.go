@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/eventstream"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+// newEventStream builds a stream of the cloud events of the instance groups, and of the kubernetes events
+// if k8sClient is not nil, that happen from now on
+func newEventStream(out io.Writer, cloud fi.Cloud, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, k8sClient kubernetes.Interface) *eventstream.Stream {
+	since := time.Now()
+
+	s := &eventstream.Stream{Out: out}
+
+	if awsCloud, ok := cloud.(awsup.AWSCloud); ok {
+		modelContext := &model.KopsModelContext{Cluster: cluster}
+
+		var groupNames []string
+		for _, ig := range instanceGroups {
+			groupNames = append(groupNames, modelContext.AutoscalingGroupName(ig))
+		}
+
+		s.Sources = append(s.Sources,
+			eventstream.NewAutoscalingSource(awsCloud.Autoscaling(), groupNames, since),
+			eventstream.NewLoadBalancerSource(awsCloud.ELB(), eventstream.AutoscalingLoadBalancerNames(awsCloud.Autoscaling(), groupNames)))
+	} else if cloud != nil {
+		glog.Warningf("cloud events are not supported on %s; only kubernetes events will be shown", cloud.ProviderID())
+	}
+
+	if k8sClient != nil {
+		s.Sources = append(s.Sources, eventstream.NewKubernetesSource(k8sClient, since))
+	}
+
+	return s
+}
+
+// buildEventsKubernetesClient builds a client for the kubernetes events of the cluster, returning nil if the
+// cluster can't be reached yet, e.g. when it is being created
+func buildEventsKubernetesClient(cluster *kops.Cluster) kubernetes.Interface {
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		glog.V(2).Infof("not showing kubernetes events, as kubecfg settings for %q could not be loaded: %v", contextName, err)
+		return nil
+	}
+	retrypolicy.ConfigureKubernetes(config)
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		glog.V(2).Infof("not showing kubernetes events, as a kube client for %q could not be built: %v", contextName, err)
+		return nil
+	}
+	return k8sClient
+}
@@ -98,5 +98,5 @@ func RunExportKubecfg(f *util.Factory, out io.Writer, options *ExportKubecfgOpti
 		return err
 	}
 
-	return conf.WriteKubecfg()
+	return conf.WriteKubecfg(out)
 }
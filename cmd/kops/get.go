@@ -53,7 +53,13 @@ var (
 	kops get secrets kube -oplaintext
 
 	# Get the admin password for a cluster
-	kops get secrets admin -oplaintext`))
+	kops get secrets admin -oplaintext
+
+	# Get the kubernetes version of a cluster
+	kops get cluster k8s-cluster.example.com -o jsonpath='{.spec.kubernetesVersion}'
+
+	# Get the name and maximum size of each instancegroup
+	kops get instancegroups --name k8s-cluster.example.com -o go-template='{{range .items}}{{.metadata.name}} {{.spec.maxSize}}{{"\n"}}{{end}}'`))
 
 	getShort = i18n.T(`Get one or many resources.`)
 )
@@ -100,12 +106,14 @@ func NewCmdGet(f *util.Factory, out io.Writer) *cobra.Command {
 		},
 	}
 
-	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "output format.  One of: table, yaml, json")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "output format.  One of: table, yaml, json, "+outputTemplateSyntaxes)
 
 	// create subcommands
+	cmd.AddCommand(NewCmdGetAudit(f, out, options))
 	cmd.AddCommand(NewCmdGetCluster(f, out, options))
 	cmd.AddCommand(NewCmdGetInstanceGroups(f, out, options))
 	cmd.AddCommand(NewCmdGetSecrets(f, out, options))
+	cmd.AddCommand(NewCmdGetSSHCredentials(f, out, options))
 
 	return cmd
 }
@@ -175,7 +183,7 @@ func RunGet(context Factory, out io.Writer, options *GetOptions) error {
 
 	case OutputTable:
 		fmt.Fprintf(os.Stdout, "Cluster\n")
-		err = clusterOutputTable(clusters, out)
+		err = clusterOutputTable(clusters, out, false)
 		if err != nil {
 			return err
 		}
@@ -186,6 +194,9 @@ func RunGet(context Factory, out io.Writer, options *GetOptions) error {
 		}
 
 	default:
+		if isTemplateOutput(options.output) {
+			return templateOutputObjects(out, options.output, obj...)
+		}
 		return fmt.Errorf("Unknown output format: %q", options.output)
 	}
 
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	getAuditLong = templates.LongDesc(i18n.T(`
+	Display the audit log of the state store.
+
+	Every create, edit, replace, update, rolling-update and delete made through kops is
+	recorded with the user, host, time and command that made it. Changes to the cluster
+	or instance group spec are recorded as a diff, visible with -o yaml.
+
+	When --name is not given, the audit log for every cluster in the state store is shown.`))
+
+	getAuditExample = templates.Examples(i18n.T(`
+	# Get the audit log for a cluster
+	kops get audit --name k8s-cluster.example.com
+
+	# Get the audit log for all clusters, including the spec diffs
+	kops get audit -o yaml
+	`))
+
+	getAuditShort = i18n.T(`Get the audit log of mutating operations.`)
+)
+
+type GetAuditOptions struct {
+	*GetOptions
+}
+
+func NewCmdGetAudit(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := GetAuditOptions{
+		GetOptions: getOptions,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Short:   getAuditShort,
+		Long:    getAuditLong,
+		Example: getAuditExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := RunGetAudit(f, out, &options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func RunGetAudit(f *util.Factory, out io.Writer, options *GetAuditOptions) error {
+	log, err := f.AuditLog()
+	if err != nil {
+		return err
+	}
+	if log == nil {
+		return fmt.Errorf("the audit log is not supported for this state store")
+	}
+
+	entries, err := log.List(rootCommand.ClusterName())
+	if err != nil {
+		return err
+	}
+
+	return auditOutput(out, options.output, entries)
+}
+
+func auditOutput(out io.Writer, output string, entries []*audit.Entry) error {
+	switch output {
+	case OutputTable:
+		t := &tables.Table{}
+		t.AddColumn("TIME", func(e *audit.Entry) string {
+			return e.Timestamp.Format(time.RFC3339)
+		})
+		t.AddColumn("USER", func(e *audit.Entry) string {
+			return e.User
+		})
+		t.AddColumn("HOST", func(e *audit.Entry) string {
+			return e.Host
+		})
+		t.AddColumn("CLUSTER", func(e *audit.Entry) string {
+			return e.Cluster
+		})
+		t.AddColumn("OPERATION", func(e *audit.Entry) string {
+			return e.Operation
+		})
+		t.AddColumn("OBJECT", func(e *audit.Entry) string {
+			return e.Object
+		})
+		t.AddColumn("COMMAND", func(e *audit.Entry) string {
+			return e.Command
+		})
+		// HOST is not selected by default, to keep the table narrow
+		return t.Render(entries, out, "TIME", "USER", "CLUSTER", "OPERATION", "OBJECT", "COMMAND")
+
+	case OutputYaml:
+		b, err := yaml.Marshal(entries)
+		if err != nil {
+			return fmt.Errorf("error marshaling audit log to yaml: %v", err)
+		}
+		_, err = out.Write(b)
+		return err
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling audit log to json: %v", err)
+		}
+		_, err = out.Write(append(b, '\n'))
+		return err
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", output)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/audit"
+)
+
+func TestAuditOutput(t *testing.T) {
+	entries := []*audit.Entry{
+		{
+			Timestamp: time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC),
+			User:      "alice",
+			Host:      "laptop",
+			Cluster:   "example.com",
+			Operation: audit.OperationEdit,
+			Object:    "instancegroup/nodes",
+			Command:   "kops edit ig nodes",
+			Diff:      "-  maxSize: 2\n+  maxSize: 3\n",
+		},
+	}
+
+	var table bytes.Buffer
+	if err := auditOutput(&table, OutputTable, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"TIME", "OPERATION", "2018-06-01T12:00:00Z", "alice", "instancegroup/nodes", "kops edit ig nodes"} {
+		if !strings.Contains(table.String(), s) {
+			t.Errorf("table output did not contain %q:\n%s", s, table.String())
+		}
+	}
+	if strings.Contains(table.String(), "laptop") {
+		t.Errorf("table output should not contain the host by default:\n%s", table.String())
+	}
+
+	var yaml bytes.Buffer
+	if err := auditOutput(&yaml, OutputYaml, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"user: alice", "host: laptop", "maxSize: 3"} {
+		if !strings.Contains(yaml.String(), s) {
+			t.Errorf("yaml output did not contain %q:\n%s", s, yaml.String())
+		}
+	}
+
+	if err := auditOutput(&bytes.Buffer{}, "xml", entries); err == nil {
+		t.Errorf("expected error for unknown output format")
+	}
+}
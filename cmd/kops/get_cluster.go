@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -29,6 +30,10 @@ import (
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
 	"k8s.io/kops/util/pkg/tables"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -45,11 +50,24 @@ var (
 	# Get a cluster
 	kops get cluster k8s-cluster.example.com
 
+	# Get the clusters owned by a team, showing their labels
+	kops get clusters -l team=payments --show-labels
+
+	# Get the per-region clusters of a group
+	kops get clusters --group payments
+
 	# Get a cluster YAML desired configuration
 	kops get cluster k8s-cluster.example.com -o yaml
 
 	# Save a cluster desired configuration to YAML file
 	kops get cluster k8s-cluster.example.com -o yaml > cluster-desired-config.yaml
+
+	# Get the kubernetes version of a cluster
+	kops get cluster k8s-cluster.example.com -o jsonpath='{.spec.kubernetesVersion}'
+
+	# Show the effective configuration, with all defaults and component configuration
+	# computed from the current spec, exactly as update cluster would apply it
+	kops get cluster k8s-cluster.example.com --full --materialize-defaults -o yaml
 	`))
 
 	getClusterShort = i18n.T(`Get one or many clusters.`)
@@ -76,8 +94,20 @@ type GetClusterOptions struct {
 	// FullSpec determines if we should output the completed (fully populated) spec
 	FullSpec bool
 
+	// MaterializeDefaults computes the completed spec from the current spec, rather than reading the last applied spec
+	MaterializeDefaults bool
+
 	// ClusterNames is a list of cluster names to show; if not specified all clusters will be shown
 	ClusterNames []string
+
+	// Selector is a label selector restricting the clusters shown
+	Selector string
+
+	// Group restricts the clusters shown to a group of per-region clusters
+	Group string
+
+	// ShowLabels adds the cluster labels to the table output
+	ShowLabels bool
 }
 
 func NewCmdGetCluster(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
@@ -112,6 +142,10 @@ func NewCmdGetCluster(f *util.Factory, out io.Writer, getOptions *GetOptions) *c
 	}
 
 	cmd.Flags().BoolVar(&options.FullSpec, "full", options.FullSpec, "Show fully populated configuration")
+	cmd.Flags().StringVarP(&options.Selector, "selector", "l", options.Selector, "Label selector to filter clusters on, e.g. -l team=payments,env!=dev")
+	cmd.Flags().StringVar(&options.Group, "group", options.Group, "Only show the clusters in the group of per-region clusters")
+	cmd.Flags().BoolVar(&options.ShowLabels, "show-labels", options.ShowLabels, "Show cluster labels as the last column in table output")
+	cmd.Flags().BoolVar(&options.MaterializeDefaults, "materialize-defaults", options.MaterializeDefaults, "Compute the fully populated configuration by applying all defaults to the current spec, rather than showing the configuration from the last update (implies --full)")
 
 	return cmd
 }
@@ -122,11 +156,19 @@ func RunGetClusters(context Factory, out io.Writer, options *GetClusterOptions)
 		return err
 	}
 
-	clusterList, err := client.ListClusters(metav1.ListOptions{})
+	clusterList, err := client.ListClusters(metav1.ListOptions{LabelSelector: options.Selector})
 	if err != nil {
 		return err
 	}
 
+	if options.Group != "" {
+		var members []api.Cluster
+		for _, c := range commands.ClusterGroupMembers(clusterList.Items, options.Group) {
+			members = append(members, *c)
+		}
+		clusterList.Items = members
+	}
+
 	clusters, err := buildClusters(options.ClusterNames, clusterList)
 	if err != nil {
 		return err
@@ -136,9 +178,13 @@ func RunGetClusters(context Factory, out io.Writer, options *GetClusterOptions)
 		return fmt.Errorf("no clusters found")
 	}
 
-	if options.FullSpec {
+	if options.FullSpec || options.MaterializeDefaults {
 		var err error
-		clusters, err = fullClusterSpecs(clusters)
+		if options.MaterializeDefaults {
+			clusters, err = materializedClusterSpecs(client, clusters)
+		} else {
+			clusters, err = fullClusterSpecs(clusters)
+		}
 		if err != nil {
 			return err
 		}
@@ -155,12 +201,15 @@ func RunGetClusters(context Factory, out io.Writer, options *GetClusterOptions)
 
 	switch options.output {
 	case OutputTable:
-		return clusterOutputTable(clusters, out)
+		return clusterOutputTable(clusters, out, options.ShowLabels)
 	case OutputYaml:
 		return fullOutputYAML(out, obj...)
 	case OutputJSON:
 		return fullOutputJSON(out, obj...)
 	default:
+		if isTemplateOutput(options.output) {
+			return templateOutputObjects(out, options.output, obj...)
+		}
 		return fmt.Errorf("Unknown output format: %q", options.output)
 	}
 }
@@ -191,7 +240,7 @@ func buildClusters(args []string, clusterList *api.ClusterList) ([]*api.Cluster,
 	return clusters, nil
 }
 
-func clusterOutputTable(clusters []*api.Cluster, out io.Writer) error {
+func clusterOutputTable(clusters []*api.Cluster, out io.Writer, showLabels bool) error {
 	t := &tables.Table{}
 	t.AddColumn("NAME", func(c *api.Cluster) string {
 		return c.ObjectMeta.Name
@@ -209,7 +258,34 @@ func clusterOutputTable(clusters []*api.Cluster, out io.Writer) error {
 		return strings.Join(zones.List(), ",")
 	})
 
-	return t.Render(clusters, out, "NAME", "CLOUD", "ZONES")
+	t.AddColumn("LABELS", func(c *api.Cluster) string {
+		var l []string
+		for k, v := range c.ObjectMeta.Labels {
+			l = append(l, k+"="+v)
+		}
+		sort.Strings(l)
+		return strings.Join(l, ",")
+	})
+
+	t.AddColumn("GROUP", func(c *api.Cluster) string {
+		return commands.ClusterGroup(c)
+	})
+	t.AddColumn("REGION", func(c *api.Cluster) string {
+		return commands.ClusterRegion(c)
+	})
+
+	columns := []string{"NAME", "CLOUD", "ZONES"}
+	for _, c := range clusters {
+		if commands.ClusterGroup(c) != "" {
+			// Only clusters in groups are likely to span regions
+			columns = []string{"NAME", "GROUP", "CLOUD", "REGION", "ZONES"}
+			break
+		}
+	}
+	if showLabels {
+		columns = append(columns, "LABELS")
+	}
+	return t.Render(clusters, out, columns...)
 }
 
 // fullOutputJson outputs the marshalled JSON of a list of clusters and instance groups.  It will handle
@@ -275,3 +351,18 @@ func fullClusterSpecs(clusters []*api.Cluster) ([]*api.Cluster, error) {
 	}
 	return fullSpecs, nil
 }
+
+// materializedClusterSpecs runs the complete defaulting pipeline (as update cluster does) over the current cluster specs,
+// so that the output reflects exactly what would be applied, even if the cluster has not been updated since the spec changed.
+func materializedClusterSpecs(clientset simple.Clientset, clusters []*api.Cluster) ([]*api.Cluster, error) {
+	var fullSpecs []*api.Cluster
+	for _, cluster := range clusters {
+		assetBuilder := assets.NewAssetBuilder(cluster, "")
+		fullSpec, err := cloudup.PopulateClusterSpec(clientset, cluster, assetBuilder)
+		if err != nil {
+			return nil, fmt.Errorf("error computing full cluster spec for %q: %v", cluster.ObjectMeta.Name, err)
+		}
+		fullSpecs = append(fullSpecs, fullSpec)
+	}
+	return fullSpecs, nil
+}
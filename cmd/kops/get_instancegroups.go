@@ -46,6 +46,9 @@ var (
 
 	# Save a cluster's instancegroups desired configuration to YAML file
 	kops get ig --name k8s-cluster.example.com -o yaml > instancegroups-desired-config.yaml
+
+	# Get the machine type of a cluster's instancegroup
+	kops get ig --name k8s-cluster.example.com nodes -o jsonpath='{.spec.machineType}'
 	`))
 
 	getInstancegroupsShort = i18n.T(`Get one or many instancegroups`)
@@ -128,6 +131,9 @@ func RunGetInstanceGroups(options *GetInstanceGroupsOptions, args []string) erro
 	case OutputJSON:
 		return fullOutputJSON(out, obj...)
 	default:
+		if isTemplateOutput(options.output) {
+			return templateOutputObjects(out, options.output, obj...)
+		}
 		return fmt.Errorf("Unknown output format: %q", options.output)
 	}
 }
@@ -29,13 +29,25 @@ import (
 	"k8s.io/kops/pkg/sshcredentials"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kops/util/pkg/ui"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
 )
 
-// SecretTypeSSHPublicKey is set in a KeysetItem.Type for an SSH public keypair
-// As we move fully to using API objects this should go away.
-const SecretTypeSSHPublicKey = kops.KeysetType("SSHPublicKey")
+const (
+	// SecretTypeSSHPublicKey is set in a KeysetItem.Type for an SSH public keypair
+	// As we move fully to using API objects this should go away.
+	SecretTypeSSHPublicKey = kops.KeysetType("SSHPublicKey")
+
+	// SecretTypeCA is set in a KeystoreItem.Type for the cluster certificate authority keypair
+	SecretTypeCA = kops.KeysetType("CA")
+
+	// SecretTypeDockerConfig is set in a KeystoreItem.Type for the docker registry credentials secret
+	SecretTypeDockerConfig = kops.KeysetType("DockerConfig")
+
+	// SecretTypeEncryptionConfig is set in a KeystoreItem.Type for the apiserver encryption config secret
+	SecretTypeEncryptionConfig = kops.KeysetType("EncryptionConfig")
+)
 
 var (
 	getSecretLong = templates.LongDesc(i18n.T(`
@@ -46,7 +58,13 @@ var (
 	kops get secrets kube -oplaintext
 
 	# Get the admin password for a cluster
-	kops get secrets admin -oplaintext`))
+	kops get secrets admin -oplaintext
+
+	# Get the ids of the keypairs for a cluster
+	kops get secrets --type keypair -o go-template='{{range .items}}{{.name}} {{.id}}{{"\n"}}{{end}}'
+
+	# Get the certificate and private key of the cluster CA, without prompting
+	kops get secrets --type ca -oplaintext --yes`))
 
 	getSecretShort = i18n.T(`Get one or many secrets.`)
 )
@@ -54,6 +72,9 @@ var (
 type GetSecretsOptions struct {
 	*GetOptions
 	Type string
+
+	// Yes skips the confirmation prompt before private key material is written
+	Yes bool
 }
 
 func NewCmdGetSecrets(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
@@ -74,10 +95,53 @@ func NewCmdGetSecrets(f *util.Factory, out io.Writer, getOptions *GetOptions) *c
 		},
 	}
 
-	cmd.Flags().StringVarP(&options.Type, "type", "", "", "Filter by secret type")
+	cmd.Flags().StringVarP(&options.Type, "type", "", "", "Filter by secret type: ca, keypair, secret, dockerconfig, encryptionconfig or sshpublickey")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", false, "Output private key material with -oplaintext without prompting for confirmation")
 	return cmd
 }
 
+// keysetItemType returns the type we display for a keyset, distinguishing the cluster CA from other keypairs
+func keysetItemType(keyset *kops.Keyset) kops.KeysetType {
+	if keyset.Name == fi.CertificateId_CA && keyset.Spec.Type == kops.SecretTypeKeypair {
+		return SecretTypeCA
+	}
+	return keyset.Spec.Type
+}
+
+// secretItemType returns the type we display for a named secret in the secret store
+func secretItemType(name string) kops.KeysetType {
+	switch name {
+	case "dockerconfig":
+		return SecretTypeDockerConfig
+	case "encryptionconfig":
+		return SecretTypeEncryptionConfig
+	default:
+		return kops.SecretTypeSecret
+	}
+}
+
+// matchesSecretType returns true if an item of the specified type should be included when filtering by findType.
+// The "keypair" and "secret" filters continue to match the more specific types they were previously reported as.
+func matchesSecretType(findType string, t kops.KeysetType) bool {
+	if findType == "" || findType == strings.ToLower(string(t)) {
+		return true
+	}
+
+	switch t {
+	case SecretTypeCA:
+		return findType == strings.ToLower(string(kops.SecretTypeKeypair))
+	case SecretTypeDockerConfig, SecretTypeEncryptionConfig:
+		return findType == strings.ToLower(string(kops.SecretTypeSecret))
+	default:
+		return false
+	}
+}
+
+// isKeypairType returns true if items of the type are stored in the keystore
+func isKeypairType(t kops.KeysetType) bool {
+	return t == kops.SecretTypeKeypair || t == SecretTypeCA
+}
+
 func listSecrets(keyStore fi.CAStore, secretStore fi.SecretStore, sshCredentialStore fi.SSHCredentialStore, secretType string, names []string) ([]*fi.KeystoreItem, error) {
 	var items []*fi.KeystoreItem
 
@@ -85,7 +149,7 @@ func listSecrets(keyStore fi.CAStore, secretStore fi.SecretStore, sshCredentialS
 	switch findType {
 	case "":
 	// OK
-	case "sshpublickey", "keypair", "secret":
+	case "sshpublickey", "keypair", "secret", "ca", "dockerconfig", "encryptionconfig":
 	// OK
 	default:
 		return nil, fmt.Errorf("unknown secret type %q", secretType)
@@ -98,13 +162,14 @@ func listSecrets(keyStore fi.CAStore, secretStore fi.SecretStore, sshCredentialS
 		}
 
 		for _, keyset := range l {
-			if findType != "" && findType != strings.ToLower(string(keyset.Spec.Type)) {
+			t := keysetItemType(keyset)
+			if !matchesSecretType(findType, t) {
 				continue
 			}
 			for _, key := range keyset.Spec.Keys {
 				item := &fi.KeystoreItem{
 					Name: keyset.Name,
-					Type: keyset.Spec.Type,
+					Type: t,
 					Id:   key.Id,
 				}
 				items = append(items, item)
@@ -112,7 +177,7 @@ func listSecrets(keyStore fi.CAStore, secretStore fi.SecretStore, sshCredentialS
 		}
 	}
 
-	if findType == "" || findType == strings.ToLower(string(kops.SecretTypeSecret)) {
+	if matchesSecretType(findType, kops.SecretTypeSecret) || matchesSecretType(findType, SecretTypeDockerConfig) || matchesSecretType(findType, SecretTypeEncryptionConfig) {
 		names, err := secretStore.ListSecrets()
 		if err != nil {
 			return nil, fmt.Errorf("error listing secrets %v", err)
@@ -121,9 +186,9 @@ func listSecrets(keyStore fi.CAStore, secretStore fi.SecretStore, sshCredentialS
 		for _, name := range names {
 			i := &fi.KeystoreItem{
 				Name: name,
-				Type: kops.SecretTypeSecret,
+				Type: secretItemType(name),
 			}
-			if findType != "" && findType != strings.ToLower(string(i.Type)) {
+			if !matchesSecretType(findType, i.Type) {
 				continue
 			}
 
@@ -150,7 +215,7 @@ func listSecrets(keyStore fi.CAStore, secretStore fi.SecretStore, sshCredentialS
 			if l[i].Spec.PublicKey != "" {
 				item.Data = []byte(l[i].Spec.PublicKey)
 			}
-			if findType != "" && findType != strings.ToLower(string(item.Type)) {
+			if !matchesSecretType(findType, item.Type) {
 				continue
 			}
 
@@ -236,10 +301,32 @@ func RunGetSecrets(options *GetSecretsOptions, args []string) error {
 	case OutputJSON:
 		return fmt.Errorf("json output format is not (currently) supported for secrets")
 	case "plaintext":
+		var keypairs int
+		for _, i := range items {
+			if isKeypairType(i.Type) {
+				keypairs++
+			}
+		}
+		if keypairs != 0 && !options.Yes {
+			c := &ui.ConfirmArgs{
+				Out:     os.Stderr,
+				Message: fmt.Sprintf("Output will include the private key of %d keypair(s), do you wish to continue?", keypairs),
+				Default: "no",
+				Retries: 2,
+			}
+			confirmed, err := ui.GetConfirm(c)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return fmt.Errorf("not outputting private key material; specify --yes to skip the confirmation")
+			}
+		}
+
 		for _, i := range items {
 			var data string
 			switch i.Type {
-			case kops.SecretTypeSecret:
+			case kops.SecretTypeSecret, SecretTypeDockerConfig, SecretTypeEncryptionConfig:
 				secret, err := secretStore.FindSecret(i.Name)
 				if err != nil {
 					return fmt.Errorf("error getting secret %q: %v", i.Name, err)
@@ -249,6 +336,15 @@ func RunGetSecrets(options *GetSecretsOptions, args []string) error {
 				}
 				data = string(secret.Data)
 
+			case kops.SecretTypeKeypair, SecretTypeCA:
+				data, err = keypairPlaintext(keyStore, i)
+				if err != nil {
+					return err
+				}
+
+			case SecretTypeSSHPublicKey:
+				data = string(i.Data)
+
 			default:
 				return fmt.Errorf("secret type %v cannot (currently) be exported as plaintext", i.Type)
 			}
@@ -261,6 +357,51 @@ func RunGetSecrets(options *GetSecretsOptions, args []string) error {
 		return nil
 
 	default:
+		if isTemplateOutput(options.output) {
+			// We expose only the metadata of each secret, the secret material is available with -oplaintext
+			var data []interface{}
+			for _, i := range items {
+				data = append(data, map[string]interface{}{
+					"name": i.Name,
+					"id":   i.Id,
+					"type": string(i.Type),
+				})
+			}
+			return templateOutput(os.Stdout, options.output, data)
+		}
 		return fmt.Errorf("Unknown output format: %q", options.output)
 	}
 }
+
+// keypairPlaintext returns the PEM encoded certificate and private key for the keystore item
+func keypairPlaintext(keyStore fi.CAStore, i *fi.KeystoreItem) (string, error) {
+	certificates, err := keyStore.FindCertificateKeyset(i.Name)
+	if err != nil {
+		return "", fmt.Errorf("error reading certificates for keypair %q: %v", i.Name, err)
+	}
+	privateKeys, err := keyStore.FindPrivateKeyset(i.Name)
+	if err != nil {
+		return "", fmt.Errorf("error reading private keys for keypair %q: %v", i.Name, err)
+	}
+
+	var data []byte
+	if certificates != nil {
+		for _, key := range certificates.Spec.Keys {
+			if key.Id == i.Id {
+				data = append(data, key.PublicMaterial...)
+			}
+		}
+	}
+	if privateKeys != nil {
+		for _, key := range privateKeys.Spec.Keys {
+			if key.Id == i.Id {
+				data = append(data, key.PrivateMaterial...)
+			}
+		}
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("cannot find keypair %q with id %q", i.Name, i.Id)
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestSecretTypes(t *testing.T) {
+	ca := &kops.Keyset{}
+	ca.Name = "ca"
+	ca.Spec.Type = kops.SecretTypeKeypair
+	if got := keysetItemType(ca); got != SecretTypeCA {
+		t.Errorf("unexpected type for ca keyset: %q", got)
+	}
+
+	kubelet := &kops.Keyset{}
+	kubelet.Name = "kubelet"
+	kubelet.Spec.Type = kops.SecretTypeKeypair
+	if got := keysetItemType(kubelet); got != kops.SecretTypeKeypair {
+		t.Errorf("unexpected type for kubelet keyset: %q", got)
+	}
+
+	for name, expected := range map[string]kops.KeysetType{
+		"dockerconfig":     SecretTypeDockerConfig,
+		"encryptionconfig": SecretTypeEncryptionConfig,
+		"admin":            kops.SecretTypeSecret,
+	} {
+		if got := secretItemType(name); got != expected {
+			t.Errorf("unexpected type for secret %q: got %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestMatchesSecretType(t *testing.T) {
+	grid := []struct {
+		findType string
+		t        kops.KeysetType
+		expected bool
+	}{
+		{"", SecretTypeCA, true},
+		{"", SecretTypeSSHPublicKey, true},
+		{"ca", SecretTypeCA, true},
+		{"ca", kops.SecretTypeKeypair, false},
+		{"keypair", SecretTypeCA, true},
+		{"keypair", kops.SecretTypeKeypair, true},
+		{"keypair", kops.SecretTypeSecret, false},
+		{"secret", kops.SecretTypeSecret, true},
+		{"secret", SecretTypeDockerConfig, true},
+		{"secret", SecretTypeEncryptionConfig, true},
+		{"secret", kops.SecretTypeKeypair, false},
+		{"dockerconfig", SecretTypeDockerConfig, true},
+		{"dockerconfig", kops.SecretTypeSecret, false},
+		{"encryptionconfig", SecretTypeEncryptionConfig, true},
+		{"sshpublickey", SecretTypeSSHPublicKey, true},
+		{"sshpublickey", kops.SecretTypeKeypair, false},
+	}
+	for _, g := range grid {
+		if got := matchesSecretType(g.findType, g.t); got != g.expected {
+			t.Errorf("matchesSecretType(%q, %q) = %v, expected %v", g.findType, g.t, got, g.expected)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/sshcredentials"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	getSSHCredentialsLong = templates.LongDesc(i18n.T(`
+	Display the SSH credentials of a cluster: the public keys authorized on its instances.`))
+
+	getSSHCredentialsExample = templates.Examples(i18n.T(`
+	# Get the SSH credentials of a cluster
+	kops get sshcredentials --name k8s-cluster.example.com
+
+	# Save them as manifests, for use with kops create -f or kops apply -f
+	kops get sshcredentials --name k8s-cluster.example.com -o yaml > sshcredentials.yaml
+	`))
+
+	getSSHCredentialsShort = i18n.T(`Get the SSH credentials of a cluster.`)
+)
+
+type GetSSHCredentialsOptions struct {
+	*GetOptions
+}
+
+func NewCmdGetSSHCredentials(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := GetSSHCredentialsOptions{
+		GetOptions: getOptions,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "sshcredentials",
+		Aliases: []string{"sshcredential"},
+		Short:   getSSHCredentialsShort,
+		Long:    getSSHCredentialsLong,
+		Example: getSSHCredentialsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := RunGetSSHCredentials(f, out, &options, args)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func RunGetSSHCredentials(f *util.Factory, out io.Writer, options *GetSSHCredentialsOptions, args []string) error {
+	cluster, err := rootCommand.Cluster()
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	list, err := sshCredentialStore.ListSSHCredentials()
+	if err != nil {
+		return fmt.Errorf("error listing SSH credentials: %v", err)
+	}
+
+	credentials := filterSSHCredentials(list, args)
+	if len(credentials) == 0 {
+		return fmt.Errorf("No SSHCredential objects found")
+	}
+
+	var obj []runtime.Object
+	if options.output != OutputTable {
+		for _, c := range credentials {
+			c = c.DeepCopy()
+			if c.ObjectMeta.Labels == nil {
+				c.ObjectMeta.Labels = make(map[string]string)
+			}
+			c.ObjectMeta.Labels[api.LabelClusterName] = cluster.ObjectMeta.Name
+			obj = append(obj, c)
+		}
+	}
+
+	switch options.output {
+	case OutputTable:
+		return sshCredentialsOutputTable(out, credentials)
+	case OutputYaml:
+		return fullOutputYAML(out, obj...)
+	case OutputJSON:
+		return fullOutputJSON(out, obj...)
+	default:
+		if isTemplateOutput(options.output) {
+			return templateOutputObjects(out, options.output, obj...)
+		}
+		return fmt.Errorf("Unknown output format: %q", options.output)
+	}
+}
+
+// filterSSHCredentials returns the credentials with the given names (or all, if no names are given), sorted by name
+func filterSSHCredentials(list []*api.SSHCredential, names []string) []*api.SSHCredential {
+	var credentials []*api.SSHCredential
+	for _, c := range list {
+		if len(names) != 0 {
+			found := false
+			for _, name := range names {
+				if c.ObjectMeta.Name == name {
+					found = true
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		credentials = append(credentials, c)
+	}
+
+	sort.SliceStable(credentials, func(i, j int) bool {
+		return credentials[i].ObjectMeta.Name < credentials[j].ObjectMeta.Name
+	})
+	return credentials
+}
+
+func sshCredentialsOutputTable(out io.Writer, credentials []*api.SSHCredential) error {
+	t := &tables.Table{}
+	t.AddColumn("NAME", func(c *api.SSHCredential) string {
+		return c.ObjectMeta.Name
+	})
+	t.AddColumn("FINGERPRINT", func(c *api.SSHCredential) string {
+		fingerprint, err := sshcredentials.Fingerprint(c.Spec.PublicKey)
+		if err != nil {
+			return "-"
+		}
+		return fingerprint
+	})
+	t.AddColumn("COMMENT", func(c *api.SSHCredential) string {
+		_, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(c.Spec.PublicKey))
+		if err != nil {
+			return ""
+		}
+		return comment
+	})
+	return t.Render(credentials, out, "NAME", "FINGERPRINT", "COMMENT")
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+func TestSSHCredentialsOutputTable(t *testing.T) {
+	credential := func(name string, key string) *api.SSHCredential {
+		return &api.SSHCredential{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       api.SSHCredentialSpec{PublicKey: key},
+		}
+	}
+	list := []*api.SSHCredential{
+		credential("bob", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINzmfzzPnRboJsCHAHf3vFBfqd81uwBIE/crM/1hgeDI bob@example.com\n"),
+		credential("admin", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJ/skk63h1a1u0NKDm93DmCPdIT9fr1vekL/KqRE6t8t"),
+		credential("broken", "not a key"),
+	}
+
+	if filtered := filterSSHCredentials(list, []string{"bob"}); len(filtered) != 1 || filtered[0].ObjectMeta.Name != "bob" {
+		t.Errorf("unexpected credentials filtered by name: %v", filtered)
+	}
+
+	var out bytes.Buffer
+	if err := sshCredentialsOutputTable(&out, filterSSHCredentials(list, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]string{
+		{"NAME", "FINGERPRINT", "COMMENT"},
+		{"admin", "92:2c:e8:a2:c3:d6:e7:10:e1:18:8a:2c:44:22:ee:f7"},
+		{"bob", "8b:32:93:57:62:08:0d:60:da:32:c6:29:6c:5e:52:4d", "bob@example.com"},
+		{"broken", "-"},
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected table:\n%s", out.String())
+	}
+	for i, line := range lines {
+		if fields := strings.Fields(line); !reflect.DeepEqual(fields, expected[i]) {
+			t.Errorf("line %d: got %q, expected %q", i, fields, expected[i])
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	OutputJSONPath         = "jsonpath"
+	OutputJSONPathFile     = "jsonpath-file"
+	OutputGoTemplate       = "go-template"
+	OutputGoTemplateFile   = "go-template-file"
+	outputTemplateSyntaxes = OutputJSONPath + "=..., " + OutputJSONPathFile + "=..., " + OutputGoTemplate + "=..., " + OutputGoTemplateFile + "=..."
+)
+
+// isTemplateOutput returns true if the output format is one of the template formats, e.g. jsonpath={.metadata.name}
+func isTemplateOutput(output string) bool {
+	_, _, found := splitTemplateOutput(output)
+	return found
+}
+
+// splitTemplateOutput splits an output format of the form <kind>=<template> into its parts
+func splitTemplateOutput(output string) (string, string, bool) {
+	tokens := strings.SplitN(output, "=", 2)
+	if len(tokens) != 2 {
+		return "", "", false
+	}
+	switch tokens[0] {
+	case OutputJSONPath, OutputJSONPathFile, OutputGoTemplate, OutputGoTemplateFile:
+		return tokens[0], tokens[1], true
+	default:
+		return "", "", false
+	}
+}
+
+// templateOutputObjects renders the objects through the template output format.
+// A single object is passed to the template directly; multiple objects are wrapped in a List with an items field, as kubectl does.
+func templateOutputObjects(out io.Writer, output string, args ...runtime.Object) error {
+	var items []interface{}
+	for _, obj := range args {
+		b, err := marshalJSON(obj)
+		if err != nil {
+			return err
+		}
+		var item interface{}
+		if err := json.Unmarshal(b, &item); err != nil {
+			return fmt.Errorf("error parsing json: %v", err)
+		}
+		items = append(items, item)
+	}
+	return templateOutput(out, output, items)
+}
+
+// templateOutput renders the items (which should be JSON-like maps) through the template output format
+func templateOutput(out io.Writer, output string, items []interface{}) error {
+	kind, text, found := splitTemplateOutput(output)
+	if !found {
+		return fmt.Errorf("Unknown output format: %q", output)
+	}
+
+	if kind == OutputJSONPathFile || kind == OutputGoTemplateFile {
+		b, err := ioutil.ReadFile(text)
+		if err != nil {
+			return fmt.Errorf("error reading template file %q: %v", text, err)
+		}
+		text = string(b)
+	}
+
+	var data interface{}
+	if len(items) == 1 {
+		data = items[0]
+	} else {
+		if items == nil {
+			items = []interface{}{}
+		}
+		data = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}
+	}
+
+	switch kind {
+	case OutputJSONPath, OutputJSONPathFile:
+		// Like kubectl, we accept a bare expression such as .spec.kubernetesVersion
+		if !strings.Contains(text, "{") {
+			text = "{" + text + "}"
+		}
+		j := jsonpath.New("output")
+		if err := j.Parse(text); err != nil {
+			return fmt.Errorf("error parsing jsonpath %q: %v", text, err)
+		}
+		if err := j.Execute(out, data); err != nil {
+			return fmt.Errorf("error executing jsonpath %q: %v", text, err)
+		}
+
+	case OutputGoTemplate, OutputGoTemplateFile:
+		t, err := template.New("output").Parse(text)
+		if err != nil {
+			return fmt.Errorf("error parsing template %q: %v", text, err)
+		}
+		if err := t.Execute(out, data); err != nil {
+			return fmt.Errorf("error executing template %q: %v", text, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+func TestTemplateOutputObjects(t *testing.T) {
+	cluster := &api.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster.example.com"},
+		Spec:       api.ClusterSpec{KubernetesVersion: "1.11.9"},
+	}
+	ig := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec:       api.InstanceGroupSpec{Role: api.InstanceGroupRoleNode},
+	}
+
+	grid := []struct {
+		Output   string
+		Objects  []runtime.Object
+		Expected string
+	}{
+		{
+			Output:   "jsonpath={.spec.kubernetesVersion}",
+			Objects:  []runtime.Object{cluster},
+			Expected: "1.11.9",
+		},
+		{
+			Output:   "jsonpath=.metadata.name",
+			Objects:  []runtime.Object{cluster},
+			Expected: "cluster.example.com",
+		},
+		{
+			Output:   "jsonpath={.items[*].metadata.name}",
+			Objects:  []runtime.Object{cluster, ig},
+			Expected: "cluster.example.com nodes",
+		},
+		{
+			Output:   "go-template={{.metadata.name}}={{.spec.kubernetesVersion}}",
+			Objects:  []runtime.Object{cluster},
+			Expected: "cluster.example.com=1.11.9",
+		},
+		{
+			Output:   "go-template={{range .items}}{{.kind}} {{end}}",
+			Objects:  []runtime.Object{cluster, ig},
+			Expected: "Cluster InstanceGroup ",
+		},
+	}
+
+	for _, g := range grid {
+		var b bytes.Buffer
+		if err := templateOutputObjects(&b, g.Output, g.Objects...); err != nil {
+			t.Errorf("unexpected error for %q: %v", g.Output, err)
+			continue
+		}
+		if b.String() != g.Expected {
+			t.Errorf("unexpected output for %q: expected %q, got %q", g.Output, g.Expected, b.String())
+		}
+	}
+}
+
+func TestIsTemplateOutput(t *testing.T) {
+	for output, expected := range map[string]bool{
+		"jsonpath={.metadata.name}":      true,
+		"jsonpath-file=template.txt":     true,
+		"go-template={{.metadata.name}}": true,
+		"go-template-file=template.txt":  true,
+		"yaml":                           false,
+		"jsonpath":                       false,
+		"template={{.metadata.name}}":    false,
+	} {
+		if actual := isTemplateOutput(output); actual != expected {
+			t.Errorf("isTemplateOutput(%q) = %v, expected %v", output, actual, expected)
+		}
+	}
+}
@@ -290,3 +290,61 @@ func runLifecycleTestAWS(o *LifecycleTestOptions) {
 		t.Fatalf("resources changed by cluster create / destroy: %v -> %v", beforeIds, afterIds)
 	}
 }
+
+// TestLifecycleQuotaExceeded checks that update cluster refuses to create a cluster that does not fit in the account's instance limit,
+// and that it does so before making any changes
+func TestLifecycleQuotaExceeded(t *testing.T) {
+	o := &LifecycleTestOptions{
+		t:      t,
+		SrcDir: "minimal",
+	}
+	o.AddDefaults()
+
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	h.MockKopsVersion("1.8.1")
+	cloud := h.SetupMockAWS()
+	cloud.MockEC2.(*mockec2.MockEC2).AccountAttributes = map[string]string{
+		"max-instances":       "1",
+		"vpc-max-elastic-ips": "5",
+	}
+
+	beforeResources := AllResources(cloud)
+
+	var stdout bytes.Buffer
+
+	factoryOptions := &util.FactoryOptions{}
+	factoryOptions.RegistryPath = "memfs://tests"
+
+	factory := util.NewFactory(factoryOptions)
+
+	{
+		options := &CreateOptions{}
+		options.Filenames = []string{path.Join(o.SrcDir, "in-"+o.Version+".yaml")}
+		if err := RunCreate(factory, &stdout, options); err != nil {
+			t.Fatalf("error running create: %v", err)
+		}
+	}
+
+	{
+		options := &UpdateClusterOptions{}
+		options.InitDefaults()
+		options.RunTasksOptions.MaxTaskDuration = 10 * time.Second
+		options.Yes = true
+		options.CreateKubecfg = false
+
+		_, err := RunUpdateCluster(factory, o.ClusterName, &stdout, options)
+		if err == nil {
+			t.Fatalf("expected update cluster %q to fail the quota check", o.ClusterName)
+		}
+		if !strings.Contains(err.Error(), "insufficient cloud quota") || !strings.Contains(err.Error(), "--quota-check=false") {
+			t.Fatalf("unexpected error from update cluster %q: %v", o.ClusterName, err)
+		}
+	}
+
+	afterResources := AllResources(cloud)
+	if len(afterResources) != len(beforeResources) {
+		t.Fatalf("update cluster made changes although the quota check failed: %d -> %d resources", len(beforeResources), len(afterResources))
+	}
+}
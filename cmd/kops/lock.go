@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	lockLong = templates.LongDesc(i18n.T(`
+	Lock a cluster, so that other operators cannot change it.`))
+
+	lockExample = templates.Examples(i18n.T(`
+	# Lock a cluster while upgrading it
+	kops lock cluster k8s-cluster.example.com --reason "upgrading to 1.10"
+	`))
+
+	lockShort = i18n.T(`Lock a cluster.`)
+
+	lockClusterLong = templates.LongDesc(i18n.T(`
+	Lock a cluster, so that other operators cannot change it.
+
+	The lock is recorded in the state store, with the user holding it and the reason.  While the
+	cluster is locked, every kops command that would change the cluster fails for any other user,
+	naming the lock holder and the reason.  The holder can keep using kops as usual, and releases
+	the lock with "kops unlock cluster".
+
+	Locking a cluster that you have already locked updates the reason.`))
+
+	lockClusterExample = templates.Examples(i18n.T(`
+	# Lock a cluster while upgrading it
+	kops lock cluster k8s-cluster.example.com --reason "upgrading to 1.10"
+	`))
+
+	lockClusterShort = i18n.T(`Lock a cluster against changes by other operators.`)
+)
+
+func NewCmdLock(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "lock",
+		Short:   lockShort,
+		Long:    lockLong,
+		Example: lockExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdLockCluster(f, out))
+
+	return cmd
+}
+
+type LockClusterOptions struct {
+	ClusterName string
+	Reason      string
+}
+
+func NewCmdLockCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &LockClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   lockClusterShort,
+		Long:    lockClusterLong,
+		Example: lockClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunLockCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Reason, "reason", options.Reason, "Why the cluster is locked; shown to other operators")
+
+	return cmd
+}
+
+func RunLockCluster(f *util.Factory, out io.Writer, options *LockClusterOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	// Make sure the cluster exists
+	if _, err := GetCluster(f, options.ClusterName); err != nil {
+		return err
+	}
+
+	locker, err := f.ClusterLocker()
+	if err != nil {
+		return err
+	}
+	if locker == nil {
+		return fmt.Errorf("locking is not supported for this state store")
+	}
+
+	lock, err := locker.Lock(options.ClusterName, audit.CurrentUser(), options.Reason)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Cluster %q locked by %s\n", options.ClusterName, lock.Holder)
+	return nil
+}
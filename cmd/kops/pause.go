@@ -0,0 +1,170 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	pauseLong = templates.LongDesc(i18n.T(`
+	Pause the instance groups of a cluster.`))
+
+	pauseExample = templates.Examples(i18n.T(`
+	# Scale the gpu-nodes instance group to zero instances
+	kops pause ig --name k8s-cluster.example.com gpu-nodes --yes
+	`))
+
+	pauseShort = i18n.T(`Pause the instance groups of a cluster.`)
+
+	pauseIgLong = templates.LongDesc(i18n.T(`
+		Pause an instancegroup, scaling it to zero instances.
+
+		The minimum and maximum size of the instance group are recorded and set to zero,
+		and the cloud group is resized immediately. The rest of the instance group spec is
+		preserved, and "kops update cluster" keeps the group at zero instances until it is
+		resumed with "kops resume instancegroup".`))
+
+	pauseIgExample = templates.Examples(i18n.T(`
+		# Scale the gpu-nodes instance group to zero instances
+		kops pause ig --name k8s-cluster.example.com gpu-nodes --yes
+		`))
+
+	pauseIgShort = i18n.T(`Pause instancegroup`)
+)
+
+func NewCmdPause(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pause",
+		Short:   pauseShort,
+		Long:    pauseLong,
+		Example: pauseExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdPauseInstanceGroup(f, out))
+
+	return cmd
+}
+
+type PauseInstanceGroupOptions struct {
+	Yes         bool
+	ClusterName string
+	GroupName   string
+}
+
+func NewCmdPauseInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &PauseInstanceGroupOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "instancegroup",
+		Aliases: []string{"instancegroups", "ig"},
+		Short:   pauseIgShort,
+		Long:    pauseIgLong,
+		Example: pauseIgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				exitWithError(fmt.Errorf("Specify name of instance group to pause"))
+			}
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("Can only pause one instance group at a time!"))
+			}
+
+			options.GroupName = args[0]
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunPauseInstanceGroup(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately pause the instance group")
+
+	return cmd
+}
+
+// RunPauseInstanceGroup scales an instance group to zero, recording its sizes so that it can be resumed
+func RunPauseInstanceGroup(f *util.Factory, out io.Writer, options *PauseInstanceGroupOptions) error {
+	groupName := options.GroupName
+	if groupName == "" {
+		return fmt.Errorf("GroupName is required")
+	}
+
+	clusterName := options.ClusterName
+	if clusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, clusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	group, err := clientset.InstanceGroupsFor(cluster).Get(groupName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading InstanceGroup %q: %v", groupName, err)
+	}
+	if group == nil {
+		return fmt.Errorf("InstanceGroup %q not found", groupName)
+	}
+	oldGroup := group.DeepCopy()
+
+	if err := instancegroups.PauseInstanceGroup(group); err != nil {
+		return err
+	}
+
+	fullGroup, err := populateInstanceGroup(clientset, cluster, group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "InstanceGroup %q will be scaled to zero instances\n", groupName)
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to pause instancegroup\n")
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	if err := updateAndResizeInstanceGroup(f, cluster, audit.OperationPause, oldGroup, fullGroup, fi.Int(0)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nPaused InstanceGroup: %q\n", groupName)
+
+	return nil
+}
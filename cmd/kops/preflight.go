@@ -0,0 +1,224 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kopsbase "k8s.io/kops"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/preflight"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	preflightLong = templates.LongDesc(i18n.T(`
+	Checks a cluster for problems before it is updated or upgraded, and reports each check as
+	pass, warn or fail.
+
+	The checks are:
+
+	* version: this version of kops supports the kubernetes version of the cluster, and the
+	  channel does not require or recommend an upgrade of either.
+	* deprecation: the stored cluster and instance group specs do not use deprecated fields.
+	* quota: the cloud quotas (instances of each type, elastic IPs and VPCs on AWS; CPUs and
+	  instances on GCE) leave room for the instance groups at their maximum size.  It warns if
+	  there is not room to duplicate a node group, as a blue/green rolling update does.
+	* permissions: the caller's IAM policies allow the actions kops needs (AWS only).
+
+	The command exits with an error if any check fails.`))
+
+	preflightExample = templates.Examples(i18n.T(`
+	# Check a cluster before updating it
+	kops preflight --name k8s-cluster.example.com
+	`))
+
+	preflightShort = i18n.T(`Check a cluster for problems before changing it`)
+)
+
+type PreflightOptions struct {
+	ClusterName string
+	Output      string
+}
+
+func (o *PreflightOptions) InitDefaults() {
+	o.Output = OutputTable
+}
+
+func NewCmdPreflight(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &PreflightOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "preflight",
+		Short:   preflightShort,
+		Long:    preflightLong,
+		Example: preflightExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunPreflight(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "output format.  One of: table, yaml, json")
+
+	return cmd
+}
+
+func RunPreflight(f *util.Factory, out io.Writer, options *PreflightOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	report := &preflight.Report{}
+
+	channel, err := cloudup.ChannelForCluster(cluster)
+	if err != nil {
+		glog.Warningf("error reading channel %q: %v", cluster.Spec.Channel, err)
+		channel = nil
+	}
+	preflight.CheckVersions(report, kopsbase.Version, cluster, channel)
+	preflight.CheckDeprecatedFields(report, cluster, instanceGroups)
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+
+	var nodeGroups []*api.InstanceGroup
+	for _, ig := range instanceGroups {
+		if ig.Spec.Role == api.InstanceGroupRoleNode {
+			nodeGroups = append(nodeGroups, ig)
+		}
+	}
+	quotas, err := preflight.CloudQuotas(cloud, cluster, instanceGroups, nodeGroups)
+	if err != nil {
+		report.Add("quota", preflight.StatusWarn, "unable to read the cloud quotas: %v", err)
+	} else if len(quotas) == 0 {
+		report.Add("quota", preflight.StatusWarn, "quota checks are only supported on AWS and GCE")
+	} else {
+		preflight.CheckQuotas(report, quotas)
+	}
+
+	if awsCloud, ok := cloud.(awsup.AWSCloud); ok {
+		callerARN, err := awsup.CallerARN(awsCloud)
+		if err != nil {
+			report.Add("permissions", preflight.StatusWarn, "unable to determine the caller's identity: %v", err)
+		} else {
+			preflight.CheckAWSPermissions(report, awsCloud.IAM(), callerARN, preflight.RequiredAWSActions(cluster))
+		}
+	} else {
+		report.Add("permissions", preflight.StatusWarn, "permission checks are only supported on AWS")
+	}
+
+	switch options.Output {
+	case OutputTable:
+		t := &tables.Table{}
+		t.AddColumn("CHECK", func(r *preflight.Result) string {
+			return r.Check
+		})
+		t.AddColumn("STATUS", func(r *preflight.Result) string {
+			return string(r.Status)
+		})
+		t.AddColumn("MESSAGE", func(r *preflight.Result) string {
+			return r.Message
+		})
+		if err := t.Render(report.Results, out, "CHECK", "STATUS", "MESSAGE"); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n%d passed, %d warnings, %d failed\n", report.Count(preflight.StatusPass), report.Count(preflight.StatusWarn), report.Count(preflight.StatusFail))
+
+	case OutputYaml:
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("error marshaling preflight report to yaml: %v", err)
+		}
+		if _, err := out.Write(b); err != nil {
+			return err
+		}
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling preflight report to json: %v", err)
+		}
+		if _, err := out.Write(append(b, '\n')); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+
+	if report.Failed() {
+		return fmt.Errorf("preflight checks failed")
+	}
+	return nil
+}
+
+// verifyQuotas returns an error if the cloud quotas do not leave room for the instance groups at their maximum size,
+// and for the surge groups added one at a time while the change is made.  It only warns if the quotas can't be read.
+func verifyQuotas(cloud fi.Cloud, cluster *api.Cluster, instanceGroups []*api.InstanceGroup, surge []*api.InstanceGroup) error {
+	quotas, err := preflight.CloudQuotas(cloud, cluster, instanceGroups, surge)
+	if err != nil {
+		glog.Warningf("unable to check cloud quotas: %v", err)
+		return nil
+	}
+	if err := preflight.VerifyQuotas(quotas); err != nil {
+		return fmt.Errorf("%v\nuse --quota-check=false to skip this check", err)
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/cmd/kops/util"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/kopscodecs"
 	"k8s.io/kops/util/pkg/vfs"
@@ -38,7 +39,10 @@ import (
 
 var (
 	replaceLong = templates.LongDesc(i18n.T(`
-		Replace a resource desired configuration by filename or stdin.`))
+		Replace a resource desired configuration by filename or stdin.
+
+		If a resource has a metadata.resourceVersion, as written by "kops get -o yaml", the replace fails
+		if the resource was changed since it was read.  Use --force to overwrite it anyway.`))
 
 	replaceExample = templates.Examples(i18n.T(`
 		# Replace a cluster desired configuration using a YAML file
@@ -81,7 +85,7 @@ func NewCmdReplace(f *util.Factory, out io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringSliceVarP(&options.Filenames, "filename", "f", options.Filenames, "A list of one or more files separated by a comma.")
-	cmd.Flags().BoolVarP(&options.force, "force", "", false, "Force any changes, which will also create any non-existing resource")
+	cmd.Flags().BoolVarP(&options.force, "force", "", false, "Force any changes, which will also create any non-existing resource and overwrite resources changed since they were read")
 	cmd.MarkFlagRequired("filename")
 
 	return cmd
@@ -99,17 +103,17 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 
 	codec := codecs.UniversalDecoder(kopsapi.SchemeGroupVersion)
 
-	for _, f := range c.Filenames {
+	for _, filename := range c.Filenames {
 		var contents []byte
-		if f == "-" {
+		if filename == "-" {
 			contents, err = ConsumeStdin()
 			if err != nil {
 				return err
 			}
 		} else {
-			contents, err = vfs.Context.ReadFile(f)
+			contents, err = vfs.Context.ReadFile(filename)
 			if err != nil {
-				return fmt.Errorf("error reading file %q: %v", f, err)
+				return fmt.Errorf("error reading file %q: %v", filename, err)
 			}
 		}
 		sections := bytes.Split(contents, []byte("\n---\n"))
@@ -117,7 +121,7 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 		for _, section := range sections {
 			o, gvk, err := codec.Decode(section, nil, nil)
 			if err != nil {
-				return fmt.Errorf("error parsing file %q: %v", f, err)
+				return fmt.Errorf("error parsing file %q: %v", filename, err)
 			}
 
 			switch v := o.(type) {
@@ -148,11 +152,20 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 						if err != nil {
 							return fmt.Errorf("error creating cluster: %v", err)
 						}
+						commands.RecordAuditChange(f, clusterName, audit.OperationCreate, "cluster", nil, v)
 					} else {
+						if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+							return err
+						}
+
+						if c.force {
+							v.ObjectMeta.ResourceVersion = ""
+						}
 						_, err = clientset.UpdateCluster(v, status)
 						if err != nil {
 							return fmt.Errorf("error replacing cluster: %v", err)
 						}
+						commands.RecordAuditChange(f, clusterName, audit.OperationReplace, "cluster", cluster, v)
 					}
 				}
 
@@ -169,6 +182,11 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 						return fmt.Errorf("error fetching cluster %q: %v", clusterName, err)
 					}
 				}
+
+				if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+					return err
+				}
+
 				// check if the instancegroup exists already
 				igName := v.ObjectMeta.Name
 				ig, err := clientset.InstanceGroupsFor(cluster).Get(igName, metav1.GetOptions{})
@@ -188,11 +206,16 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 					if err != nil {
 						return fmt.Errorf("error creating instanceGroup: %v", err)
 					}
+					commands.RecordAuditChange(f, clusterName, audit.OperationCreate, "instancegroup/"+igName, nil, v)
 				default:
+					if c.force {
+						v.ObjectMeta.ResourceVersion = ""
+					}
 					_, err = clientset.InstanceGroupsFor(cluster).Update(v)
 					if err != nil {
 						return fmt.Errorf("error replacing instanceGroup: %v", err)
 					}
+					commands.RecordAuditChange(f, clusterName, audit.OperationReplace, "instancegroup/"+igName, ig, v)
 				}
 			case *kopsapi.SSHCredential:
 				clusterName := v.ObjectMeta.Labels[kopsapi.LabelClusterName]
@@ -208,6 +231,10 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 					return err
 				}
 
+				if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+					return err
+				}
+
 				sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
 				if err != nil {
 					return err
@@ -220,7 +247,7 @@ func RunReplace(f *util.Factory, cmd *cobra.Command, out io.Writer, c *replaceOp
 				}
 			default:
 				glog.V(2).Infof("Type of object was %T", v)
-				return fmt.Errorf("Unhandled kind %q in %q", gvk, f)
+				return fmt.Errorf("Unhandled kind %q in %q", gvk, filename)
 			}
 		}
 	}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	restoreLong = templates.LongDesc(i18n.T(`
+	Restore a cluster from a backup.`))
+
+	restoreExample = templates.Examples(i18n.T(`
+	# Restore a cluster from a backup into a new state store
+	kops restore cluster --from s3://old.example.com/k8s-cluster.example.com/backups/cluster/20180601T120000Z.tar.gz --state s3://new.example.com
+	`))
+
+	restoreShort = i18n.T(`Restore a cluster from a backup.`)
+)
+
+func NewCmdRestore(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   restoreShort,
+		Long:    restoreLong,
+		Example: restoreExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdRestoreCluster(f, out))
+
+	return cmd
+}
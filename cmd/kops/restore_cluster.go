@@ -0,0 +1,152 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	restoreClusterLong = templates.LongDesc(i18n.T(`
+	Restore a cluster from a bundle written by kops backup cluster.
+
+	The cluster and instance group specs, keys and secrets are written to the state store, and
+	etcd-manager is told to restore the etcd backups in the bundle when the control plane starts.
+	The state store may be in another account than the one the cluster was backed up from, and
+	--region moves the cluster to the zones with the same suffix in another region.
+
+	The cluster is only created in the state store; run kops update cluster to create it in the cloud.
+	The cluster must not already exist in the state store.
+	`))
+
+	restoreClusterExample = templates.Examples(i18n.T(`
+	# Restore a cluster into a new state store
+	kops restore cluster --from s3://old.example.com/k8s-cluster.example.com/backups/cluster/20180601T120000Z.tar.gz --state s3://new.example.com
+	kops update cluster k8s-cluster.example.com --state s3://new.example.com --yes
+
+	# Restore a cluster into another region
+	kops restore cluster --from s3://old.example.com/k8s-cluster.example.com/backups/cluster/20180601T120000Z.tar.gz \
+	  --region us-west-2 --state s3://new.example.com
+	`))
+
+	restoreClusterShort = i18n.T(`Restore a cluster from a backup.`)
+)
+
+type RestoreClusterOptions struct {
+	From string
+	commands.RestoreClusterOptions
+}
+
+func NewCmdRestoreCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RestoreClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   restoreClusterShort,
+		Long:    restoreClusterLong,
+		Example: restoreClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := RunRestoreCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.From, "from", options.From, "Path of the backup bundle to restore")
+	cmd.Flags().StringVar(&options.Region, "region", options.Region, "Region to restore the cluster into, if different from the backed up cluster")
+
+	return cmd
+}
+
+func RunRestoreCluster(f *util.Factory, out io.Writer, c *RestoreClusterOptions) error {
+	if c.From == "" {
+		return fmt.Errorf("--from is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	p, err := vfs.Context.BuildVfsPath(c.From)
+	if err != nil {
+		return fmt.Errorf("error building path for %q: %v", c.From, err)
+	}
+	data, err := p.ReadFile()
+	if err != nil {
+		return fmt.Errorf("error reading backup %s: %v", p, err)
+	}
+	backup, err := commands.ReadClusterBackup(data)
+	if err != nil {
+		return err
+	}
+
+	cluster, instanceGroups, err := commands.RestoreClusterSpec(backup, &c.RestoreClusterOptions)
+	if err != nil {
+		return err
+	}
+	clusterName := cluster.ObjectMeta.Name
+
+	if err := validation.ValidateCluster(cluster, false); err != nil {
+		return fmt.Errorf("restored cluster is not valid: %v", err)
+	}
+	for _, ig := range instanceGroups {
+		if err := validation.CrossValidateInstanceGroup(ig, cluster, false); err != nil {
+			return fmt.Errorf("restored instance group %q is not valid: %v", ig.ObjectMeta.Name, err)
+		}
+	}
+
+	if _, err := clientset.GetCluster(clusterName); err == nil {
+		return fmt.Errorf("cluster %q already exists", clusterName)
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("error checking for existing cluster %q: %v", clusterName, err)
+	}
+
+	if err := registry.CreateClusterConfig(clientset, cluster, instanceGroups); err != nil {
+		return fmt.Errorf("error writing cluster configuration: %v", err)
+	}
+	commands.RecordAuditChange(f, clusterName, audit.OperationCreate, "cluster", nil, cluster)
+
+	// Read the cluster back, so that its paths in the new state store are set
+	cluster, err = clientset.GetCluster(clusterName)
+	if err != nil {
+		return fmt.Errorf("error reading restored cluster %q: %v", clusterName, err)
+	}
+	if err := commands.RestoreClusterState(clientset, backup, cluster, time.Now()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nCluster %q has been restored from the backup taken at %s.\n\n", clusterName, backup.Manifest.Timestamp)
+	fmt.Fprintf(out, "Suggestions:\n")
+	fmt.Fprintf(out, " * review the configuration with: kops edit cluster %s\n", clusterName)
+	fmt.Fprintf(out, " * create the cluster with: kops update cluster %s --yes\n\n", clusterName)
+
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	resumeLong = templates.LongDesc(i18n.T(`
+	Resume the paused instance groups of a cluster.`))
+
+	resumeExample = templates.Examples(i18n.T(`
+	# Restore the gpu-nodes instance group to the size it had when it was paused
+	kops resume ig --name k8s-cluster.example.com gpu-nodes --yes
+	`))
+
+	resumeShort = i18n.T(`Resume the paused instance groups of a cluster.`)
+
+	resumeIgLong = templates.LongDesc(i18n.T(`
+		Resume an instancegroup paused by "kops pause instancegroup".
+
+		The minimum and maximum size recorded when the instance group was paused are
+		restored, and the cloud group is resized immediately.`))
+
+	resumeIgExample = templates.Examples(i18n.T(`
+		# Restore the gpu-nodes instance group to the size it had when it was paused
+		kops resume ig --name k8s-cluster.example.com gpu-nodes --yes
+		`))
+
+	resumeIgShort = i18n.T(`Resume instancegroup`)
+)
+
+func NewCmdResume(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "resume",
+		Short:   resumeShort,
+		Long:    resumeLong,
+		Example: resumeExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdResumeInstanceGroup(f, out))
+
+	return cmd
+}
+
+type ResumeInstanceGroupOptions struct {
+	Yes         bool
+	ClusterName string
+	GroupName   string
+}
+
+func NewCmdResumeInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ResumeInstanceGroupOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "instancegroup",
+		Aliases: []string{"instancegroups", "ig"},
+		Short:   resumeIgShort,
+		Long:    resumeIgLong,
+		Example: resumeIgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				exitWithError(fmt.Errorf("Specify name of instance group to resume"))
+			}
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("Can only resume one instance group at a time!"))
+			}
+
+			options.GroupName = args[0]
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunResumeInstanceGroup(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately resume the instance group")
+
+	return cmd
+}
+
+// RunResumeInstanceGroup restores the sizes of an instance group that was paused
+func RunResumeInstanceGroup(f *util.Factory, out io.Writer, options *ResumeInstanceGroupOptions) error {
+	groupName := options.GroupName
+	if groupName == "" {
+		return fmt.Errorf("GroupName is required")
+	}
+
+	clusterName := options.ClusterName
+	if clusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, clusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	group, err := clientset.InstanceGroupsFor(cluster).Get(groupName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading InstanceGroup %q: %v", groupName, err)
+	}
+	if group == nil {
+		return fmt.Errorf("InstanceGroup %q not found", groupName)
+	}
+	oldGroup := group.DeepCopy()
+
+	if err := instancegroups.ResumeInstanceGroup(group); err != nil {
+		return err
+	}
+
+	fullGroup, err := populateInstanceGroup(clientset, cluster, group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "InstanceGroup %q will be scaled to min=%d max=%d\n", groupName, fi.Int32Value(fullGroup.Spec.MinSize), fi.Int32Value(fullGroup.Spec.MaxSize))
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to resume instancegroup\n")
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	if err := updateAndResizeInstanceGroup(f, cluster, audit.OperationResume, oldGroup, fullGroup, nil); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nResumed InstanceGroup: %q\n", groupName)
+
+	return nil
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -33,11 +35,19 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/diff"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/pkg/policy"
 	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/kutil"
 	"k8s.io/kops/util/pkg/tables"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
@@ -67,10 +77,22 @@ var (
 		# Preview a rolling-update.
 		kops rolling-update cluster
 
+		# Preview a rolling-update, showing why the instances need updating,
+		# e.g. because the image or the user data changed.
+		kops rolling-update cluster --show-reason
+
+		# Preview a rolling-update, showing how the launch configuration of the
+		# instances that need updating differs from the current one (AWS only).
+		kops rolling-update cluster --show-diff
+
 		# Roll the currently selected kops cluster with defaults.
 		# Nodes will be drained and the cluster will be validated between node replacement.
 		kops rolling-update cluster --yes
 
+		# Roll the currently selected kops cluster, showing the instance launches
+		# and terminations, load balancer health and kubernetes events as they happen.
+		kops rolling-update cluster --yes --events
+
 		# Roll the k8s-cluster.example.com kops cluster,
 		# do not fail if the cluster does not validate,
 		# wait 8 min to create new node, and wait at least
@@ -95,6 +117,25 @@ var (
 		  --fail-on-validate-error="false" \
 		  --node-interval 8m \
 		  --instance-group nodes
+
+		# Roll the nodes of k8s-cluster.example.com, waiting for the
+		# ingress pods in the infra namespace to be ready after each node.
+		kops rolling-update cluster k8s-cluster.example.com --yes \
+		  --instance-group nodes \
+		  --validate-pods-selector app=ingress \
+		  --validate-pods-namespace infra
+
+		# Roll the nodes of a GCE cluster using the managed instance
+		# group's own rolling replace, two extra instances at a time.
+		kops rolling-update cluster k8s-cluster.example.com --yes \
+		  --instance-group nodes \
+		  --strategy native \
+		  --max-surge 2
+
+		# Roll every production cluster, two clusters at a time,
+		# printing a summary of the results at the end.
+		kops rolling-update cluster --cluster-glob '*.prod.example.com' --yes \
+		  --parallel 2
 		`))
 
 	rollingupdateShort = i18n.T(`Rolling update a cluster.`)
@@ -119,6 +160,36 @@ type RollingUpdateOptions struct {
 	// PostDrainDelay is the duration of a pause after a drain operation
 	PostDrainDelay time.Duration
 
+	// DrainTimeout is the maximum time to wait for a node to drain; zero means no limit
+	DrainTimeout time.Duration
+
+	// PodEvictionGracePeriod overrides the termination grace period of evicted pods; zero uses each pod's own grace period
+	PodEvictionGracePeriod time.Duration
+
+	// SkipPodsWithLocalStorage leaves pods with emptyDir volumes running when draining a node, instead of evicting them
+	SkipPodsWithLocalStorage bool
+
+	// WaitForReschedule waits, after draining a node and before terminating it, until the evicted pods are ready elsewhere
+	WaitForReschedule bool
+
+	// RescheduleTimeout is the maximum time to wait for the evicted pods to be ready elsewhere
+	RescheduleTimeout time.Duration
+
+	// APIProbeSuccesses is the number of consecutive successful apiserver probes required after replacing a master
+	APIProbeSuccesses int
+
+	// APIProbeInterval is the interval between apiserver probes
+	APIProbeInterval time.Duration
+
+	// EtcdQuorumCheck refuses to terminate a master if an etcd member is unhealthy or etcd would lose quorum
+	EtcdQuorumCheck bool
+
+	// RollbackOnFailure stops the rolling update when the cluster fails validation, and reverts the instance group spec
+	RollbackOnFailure bool
+
+	// RollbackAfterFailures is the number of consecutive failed validations that trigger a rollback
+	RollbackAfterFailures int
+
 	// ValidationTimeout is the timeout for validation to succeed after the drain and pause
 	ValidationTimeout time.Duration
 
@@ -134,6 +205,12 @@ type RollingUpdateOptions struct {
 	// Interactive rolling-update prompts user to continue after each instances is updated.
 	Interactive bool
 
+	// ShowReason shows why the instances of each instance group need updating, e.g. the image or user data changed
+	ShowReason bool
+
+	// ShowDiff shows how the launch configuration of the instances that need updating differs from the current one
+	ShowDiff bool
+
 	ClusterName string
 
 	// InstanceGroups is the list of instance groups to rolling-update;
@@ -143,6 +220,43 @@ type RollingUpdateOptions struct {
 	// InstanceGroupRoles is the list of roles we should rolling-update
 	// if not specified, all instance groups will be updated
 	InstanceGroupRoles []string
+
+	// DiscoveryCacheTTL is how long read-only cloud API results are cached on disk; zero disables the cache
+	DiscoveryCacheTTL time.Duration
+
+	// Refresh ignores any cached cloud discovery results
+	Refresh bool
+
+	// Policies are rego files or webhook URLs that must allow the rolling update before it is performed
+	Policies []string
+
+	// Batch selects multiple clusters to rolling-update
+	Batch BatchOptions
+
+	// DetectClusterAutoscaler coordinates the rolling update with cluster-autoscaler, if it is running in the cluster
+	DetectClusterAutoscaler bool
+
+	// ValidateConditions are the node conditions, other than Ready, that fail validation when they are true
+	ValidateConditions []string
+
+	// ValidatePodsNamespace and ValidatePodsSelectors select additional pods which must be ready for validation to pass
+	ValidatePodsNamespace string
+	ValidatePodsSelectors []string
+
+	// Strategy is how instances are replaced: "replace" deletes them one at a time, "native" uses the cloud's rolling update,
+	// "duplicate" replaces each node group with a new group
+	Strategy string
+
+	// MaxSurge and MaxUnavailable bound the cloud's rolling update, with the native strategy
+	MaxSurge       int
+	MaxUnavailable int
+
+	// Events streams the cloud and kubernetes events of the instance groups while they are updated
+	Events bool
+
+	// QuotaCheck fails the rolling update before it starts if the cloud quotas do not leave room for the instances
+	// the strategy adds while replacing a group
+	QuotaCheck bool
 }
 
 func (o *RollingUpdateOptions) InitDefaults() {
@@ -159,7 +273,20 @@ func (o *RollingUpdateOptions) InitDefaults() {
 
 	o.PostDrainDelay = 90 * time.Second
 	o.ValidationTimeout = 5 * time.Minute
+	o.RescheduleTimeout = 5 * time.Minute
+	o.RollbackAfterFailures = 3
+	o.EtcdQuorumCheck = true
+	o.APIProbeInterval = instancegroups.DefaultAPIProbeInterval
+
+	o.DetectClusterAutoscaler = true
+	o.ValidateConditions = validation.DefaultNodeConditions
 
+	o.Strategy = instancegroups.RollingUpdateStrategyReplace
+	o.MaxSurge = 1
+	o.MaxUnavailable = 0
+	o.QuotaCheck = true
+
+	o.Batch.InitDefaults()
 }
 
 func NewCmdRollingUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
@@ -177,20 +304,53 @@ func NewCmdRollingUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Perform rolling update immediately, without --yes rolling-update executes a dry-run")
 	cmd.Flags().BoolVar(&options.Force, "force", options.Force, "Force rolling update, even if no changes")
 	cmd.Flags().BoolVar(&options.CloudOnly, "cloudonly", options.CloudOnly, "Perform rolling update without confirming progress with k8s")
+	cmd.Flags().BoolVar(&options.ShowReason, "show-reason", options.ShowReason, "Show why the instances of each instance group need updating")
+	cmd.Flags().BoolVar(&options.ShowDiff, "show-diff", options.ShowDiff, "Show how the launch configuration of the instances that need updating differs from the current one (AWS only)")
 
 	cmd.Flags().DurationVar(&options.MasterInterval, "master-interval", options.MasterInterval, "Time to wait between restarting masters")
 	cmd.Flags().DurationVar(&options.NodeInterval, "node-interval", options.NodeInterval, "Time to wait between restarting nodes")
 	cmd.Flags().DurationVar(&options.BastionInterval, "bastion-interval", options.BastionInterval, "Time to wait between restarting bastions")
 	cmd.Flags().BoolVarP(&options.Interactive, "interactive", "i", options.Interactive, "Prompt to continue after each instance is updated")
 	cmd.Flags().StringSliceVar(&options.InstanceGroups, "instance-group", options.InstanceGroups, "List of instance groups to update (defaults to all if not specified)")
-	cmd.Flags().StringSliceVar(&options.InstanceGroupRoles, "instance-group-roles", options.InstanceGroupRoles, "If specified, only instance groups of the specified role will be updated (e.g. Master,Node,Bastion)")
+	cmd.MarkFlagCustom("instance-group", "__kops_get_instancegroups")
+	cmd.Flags().StringSliceVar(&options.InstanceGroupRoles, "instance-group-roles", options.InstanceGroupRoles, "If specified, only instance groups of the specified role will be updated (e.g. Master,Node,Bastion,Etcd,APIServer)")
+	cmd.Flags().DurationVar(&options.DiscoveryCacheTTL, "discovery-cache-ttl", options.DiscoveryCacheTTL, "Cache read-only cloud API results on disk for this long, to speed up repeated invocations (0 disables the cache)")
+	cmd.Flags().BoolVar(&options.Refresh, "refresh", options.Refresh, "Ignore any cached cloud discovery results")
+	options.Batch.AddFlags(cmd)
+	cmd.Flags().BoolVar(&options.DetectClusterAutoscaler, "detect-cluster-autoscaler", options.DetectClusterAutoscaler, "If cluster-autoscaler is running, keep it from scaling down node groups and replacement nodes during the rolling update")
+	cmd.Flags().StringVar(&options.Strategy, "strategy", options.Strategy, "How instances are replaced: replace (drain and delete each instance), native (the cloud's own rolling update; GCE only) or duplicate (create a new node group, then drain and delete the old one)")
+	cmd.Flags().IntVar(&options.MaxSurge, "max-surge", options.MaxSurge, "Number of instances the cloud may create above the target size of a group, with --strategy=native")
+	cmd.Flags().IntVar(&options.MaxUnavailable, "max-unavailable", options.MaxUnavailable, "Number of instances the cloud may take down at once in a group, with --strategy=native")
+	cmd.Flags().BoolVar(&options.QuotaCheck, "quota-check", options.QuotaCheck, "Check that the cloud quotas leave room for the instances added while replacing a group, with --strategy=native or duplicate, before starting")
+	cmd.Flags().BoolVar(&options.Events, "events", options.Events, "Show cloud events (e.g. instance launches and load balancer health) and kubernetes events (e.g. node registrations and pod evictions) during the rolling update")
+	cmd.Flags().StringSliceVar(&options.Policies, "policy", options.Policies, "Policy that must allow the rolling update: a .rego file (evaluated with opa) or a webhook URL; may be repeated")
 
 	if featureflag.DrainAndValidateRollingUpdate.Enabled() {
 		cmd.Flags().BoolVar(&options.FailOnDrainError, "fail-on-drain-error", true, "The rolling-update will fail if draining a node fails.")
 		cmd.Flags().BoolVar(&options.FailOnValidate, "fail-on-validate-error", true, "The rolling-update will fail if the cluster fails to validate.")
+		cmd.Flags().DurationVar(&options.DrainTimeout, "drain-timeout", options.DrainTimeout, "Maximum time to wait for each node to drain (0 for no limit); pods still running are handled by --fail-on-drain-error")
+		cmd.Flags().DurationVar(&options.PodEvictionGracePeriod, "pod-eviction-grace-period", options.PodEvictionGracePeriod, "Termination grace period of the pods evicted when draining a node (0 uses each pod's own terminationGracePeriodSeconds)")
+		cmd.Flags().BoolVar(&options.WaitForReschedule, "wait-for-reschedule", options.WaitForReschedule, "After draining a node and before terminating it, wait until the evicted pods (except DaemonSet pods) have been replaced by ready pods on other nodes")
+		cmd.Flags().DurationVar(&options.RescheduleTimeout, "reschedule-timeout", options.RescheduleTimeout, "Maximum time to wait for the evicted pods to be ready on other nodes, with --wait-for-reschedule; a timeout is handled by --fail-on-drain-error")
+		cmd.Flags().IntVar(&options.APIProbeSuccesses, "api-probe-successes", options.APIProbeSuccesses, "After replacing a master, probe the apiserver through its load balancer until it answers this many probes in a row before validating and moving on (0 to disable)")
+		cmd.Flags().DurationVar(&options.APIProbeInterval, "api-probe-interval", options.APIProbeInterval, "Interval between apiserver probes, with --api-probe-successes")
+		cmd.Flags().BoolVar(&options.EtcdQuorumCheck, "etcd-quorum-check", options.EtcdQuorumCheck, "Before terminating a master, check that every other etcd member is healthy and that etcd keeps quorum without it")
+		cmd.Flags().BoolVar(&options.RollbackOnFailure, "rollback-on-failure", options.RollbackOnFailure, "If the cluster fails validation after replacing instances, stop the rolling update and revert a configuration-only change to the instance group spec in the state store")
+		cmd.Flags().IntVar(&options.RollbackAfterFailures, "rollback-after-failures", options.RollbackAfterFailures, "Number of consecutive failed validations after which --rollback-on-failure stops the rolling update, without waiting for --validation-timeout (0 waits for the timeout)")
+		cmd.Flags().BoolVar(&options.SkipPodsWithLocalStorage, "skip-pods-with-local-storage", options.SkipPodsWithLocalStorage, "Leave pods with emptyDir volumes running when draining a node, instead of evicting them; they are stopped when the instance is deleted")
+		cmd.Flags().StringArrayVar(&options.ValidatePodsSelectors, "validate-pods-selector", options.ValidatePodsSelectors, "Label selector for pods, in addition to kube-system pods, which must be ready for validation to pass; may be repeated")
+		cmd.Flags().StringVar(&options.ValidatePodsNamespace, "validate-pods-namespace", options.ValidatePodsNamespace, "Namespace of the pods selected by --validate-pods-selector (defaults to all namespaces)")
+		cmd.Flags().StringSliceVar(&options.ValidateConditions, "validate-conditions", options.ValidateConditions, "Node conditions, other than Ready, that fail validation when true; e.g. add KernelDeadlock to check node-problem-detector conditions")
 	}
 
 	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if options.Batch.IsSet() {
+			if err := RunRollingUpdateClusters(f, args, os.Stdout, &options); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+
 		err := rootCommand.ProcessArgs(args)
 		if err != nil {
 			exitWithError(err)
@@ -216,7 +376,55 @@ func NewCmdRollingUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	return cmd
 }
 
+// RunRollingUpdateClusters performs a rolling update of each of the clusters selected by the batch options
+func RunRollingUpdateClusters(f *util.Factory, args []string, out io.Writer, o *RollingUpdateOptions) error {
+	if o.DiscoveryCacheTTL != 0 {
+		return fmt.Errorf("--discovery-cache-ttl cannot be used when updating multiple clusters")
+	}
+	if o.Interactive && o.Batch.Parallelism > 1 {
+		return fmt.Errorf("--interactive cannot be used with --parallel")
+	}
+
+	return runBatch(f, args, out, &o.Batch, func(clusterName string, out io.Writer) error {
+		options := *o
+		options.ClusterName = clusterName
+		return RunRollingUpdateCluster(f, out, &options)
+	})
+}
+
 func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpdateOptions) error {
+	switch options.Strategy {
+	case "", instancegroups.RollingUpdateStrategyReplace:
+	case instancegroups.RollingUpdateStrategyNative:
+		if options.Interactive {
+			return fmt.Errorf("--interactive cannot be used with --strategy=%s", options.Strategy)
+		}
+		if options.MaxSurge <= 0 && options.MaxUnavailable <= 0 {
+			return fmt.Errorf("--max-surge or --max-unavailable must be greater than zero with --strategy=%s", options.Strategy)
+		}
+	case instancegroups.RollingUpdateStrategyDuplicate:
+		if options.Interactive {
+			return fmt.Errorf("--interactive cannot be used with --strategy=%s", options.Strategy)
+		}
+		if options.CloudOnly {
+			return fmt.Errorf("--cloudonly cannot be used with --strategy=%s, which waits for the new nodes to be ready", options.Strategy)
+		}
+	default:
+		return fmt.Errorf("unknown rolling-update strategy %q; must be %q, %q or %q", options.Strategy, instancegroups.RollingUpdateStrategyReplace, instancegroups.RollingUpdateStrategyNative, instancegroups.RollingUpdateStrategyDuplicate)
+	}
+
+	if options.DrainTimeout < 0 {
+		return fmt.Errorf("--drain-timeout must not be negative")
+	}
+	if options.PodEvictionGracePeriod < 0 {
+		return fmt.Errorf("--pod-eviction-grace-period must not be negative")
+	}
+	if options.APIProbeSuccesses < 0 {
+		return fmt.Errorf("--api-probe-successes must not be negative")
+	}
+	if options.RollbackAfterFailures < 0 {
+		return fmt.Errorf("--rollback-after-failures must not be negative")
+	}
 
 	clientset, err := f.Clientset()
 	if err != nil {
@@ -235,6 +443,7 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 	if err != nil {
 		return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
 	}
+	retrypolicy.ConfigureKubernetes(config)
 
 	var nodes []v1.Node
 	var k8sClient kubernetes.Interface
@@ -246,8 +455,8 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 
 		nodeList, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Unable to reach the kubernetes API.\n")
-			fmt.Fprintf(os.Stderr, "Use --cloudonly to do a rolling-update without confirming progress with the k8s API\n\n")
+			fmt.Fprintf(out, "Unable to reach the kubernetes API.\n")
+			fmt.Fprintf(out, "Use --cloudonly to do a rolling-update without confirming progress with the k8s API\n\n")
 			return fmt.Errorf("error listing nodes in cluster: %v", err)
 		}
 
@@ -310,10 +519,15 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 		warnUnmatched = false
 	}
 
+	if err := configureDiscoveryCache(cluster.ObjectMeta.Name, options.DiscoveryCacheTTL, options.Refresh); err != nil {
+		return err
+	}
+
 	cloud, err := cloudup.BuildCloud(cluster)
 	if err != nil {
 		return err
 	}
+	configureBareMetalCloud(cloud, clientset, cluster)
 
 	groups, err := cloud.GetCloudGroups(cluster, instanceGroups, warnUnmatched, nodes)
 	if err != nil {
@@ -354,6 +568,9 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 			}
 			return strconv.Itoa(len(nodes))
 		})
+		t.AddColumn("REASON", func(r *cloudinstances.CloudInstanceGroup) string {
+			return strings.Join(r.UpdateReasons(), ",")
+		})
 		var l []*cloudinstances.CloudInstanceGroup
 		for _, v := range groups {
 			l = append(l, v)
@@ -363,12 +580,21 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 		if !options.CloudOnly {
 			columns = append(columns, "NODES")
 		}
+		if options.ShowReason {
+			columns = append(columns, "REASON")
+		}
 		err := t.Render(l, out, columns...)
 		if err != nil {
 			return err
 		}
 	}
 
+	if options.ShowDiff {
+		if err := printInstanceLaunchDiffs(out, cloud, groups); err != nil {
+			return err
+		}
+	}
+
 	needUpdate := false
 	for _, group := range groups {
 		if len(group.NeedUpdate) != 0 {
@@ -377,15 +603,35 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 	}
 
 	if !needUpdate && !options.Force {
-		fmt.Printf("\nNo rolling-update required.\n")
+		fmt.Fprintf(out, "\nNo rolling-update required.\n")
 		return nil
 	}
 
 	if !options.Yes {
-		fmt.Printf("\nMust specify --yes to rolling-update.\n")
+		fmt.Fprintf(out, "\nMust specify --yes to rolling-update.\n")
 		return nil
 	}
 
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	if err := policy.Enforce(options.Policies, policy.OperationRollingUpdateCluster, cluster, instanceGroups); err != nil {
+		return err
+	}
+
+	if options.QuotaCheck {
+		if surge := rollingUpdateSurge(options, groups); len(surge) != 0 {
+			var all []*api.InstanceGroup
+			for i := range list.Items {
+				all = append(all, &list.Items[i])
+			}
+			if err := verifyQuotas(cloud, cluster, all, surge); err != nil {
+				return err
+			}
+		}
+	}
+
 	if featureflag.DrainAndValidateRollingUpdate.Enabled() {
 		glog.V(2).Infof("Rolling update with drain and validate enabled.")
 	}
@@ -404,6 +650,194 @@ func RunRollingUpdateCluster(f *util.Factory, out io.Writer, options *RollingUpd
 		ClusterName:       options.ClusterName,
 		PostDrainDelay:    options.PostDrainDelay,
 		ValidationTimeout: options.ValidationTimeout,
+
+		DrainTimeout:             options.DrainTimeout,
+		PodEvictionGracePeriod:   options.PodEvictionGracePeriod,
+		SkipPodsWithLocalStorage: options.SkipPodsWithLocalStorage,
+		WaitForReschedule:        options.WaitForReschedule,
+		RescheduleTimeout:        options.RescheduleTimeout,
+
+		APIProbeSuccesses:          options.APIProbeSuccesses,
+		APIProbeInterval:           options.APIProbeInterval,
+		EtcdQuorumCheck:            options.EtcdQuorumCheck,
+		RollbackOnFailure:          options.RollbackOnFailure,
+		ValidationFailureThreshold: options.RollbackAfterFailures,
+
+		DetectClusterAutoscaler: options.DetectClusterAutoscaler,
+		ValidateConditions:      options.ValidateConditions,
+		ValidatePodsNamespace:   options.ValidatePodsNamespace,
+		ValidatePodsSelectors:   options.ValidatePodsSelectors,
+
+		Strategy:       options.Strategy,
+		MaxSurge:       options.MaxSurge,
+		MaxUnavailable: options.MaxUnavailable,
+
+		Out: out,
+	}
+
+	if options.Strategy == instancegroups.RollingUpdateStrategyDuplicate {
+		d.Clientset = clientset
+		d.ApplyCluster = func(cluster *api.Cluster, instanceGroups []*api.InstanceGroup) error {
+			applyCmd := &cloudup.ApplyClusterCmd{
+				Clientset:      clientset,
+				Cluster:        cluster,
+				InstanceGroups: instanceGroups,
+				Models:         cloudup.CloudupModels,
+				TargetName:     cloudup.TargetDirect,
+				Out:            out,
+			}
+			return applyCmd.Run()
+		}
+		d.AuditLog, err = f.AuditLog()
+		if err != nil {
+			glog.Warningf("unable to open the audit log: %v", err)
+		}
+	}
+
+	if options.Events {
+		events := newEventStream(out, cloud, cluster, instanceGroups, k8sClient)
+		events.Start()
+		defer events.Stop()
+	}
+
+	if err := d.RollingUpdate(groups, cluster, list); err != nil {
+		if options.RollbackOnFailure && d.FailedValidationGroup() != "" {
+			if rollbackErr := rollbackInstanceGroup(f, out, cluster, d.FailedValidationGroup()); rollbackErr != nil {
+				glog.Errorf("Unable to roll back instance group %q: %v", d.FailedValidationGroup(), rollbackErr)
+			}
+		}
+		return err
+	}
+
+	// The rollback of a later failure only considers the changes made since the last rolling update of each group
+	for _, ig := range instanceGroups {
+		commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationRollingUpdate, "instancegroup/"+ig.ObjectMeta.Name, "")
+	}
+	return nil
+}
+
+// rollbackInstanceGroup reverts the spec of an instance group which failed validation during the rolling update,
+// and prints the actions which remain for the user
+func rollbackInstanceGroup(f *util.Factory, out io.Writer, cluster *api.Cluster, groupName string) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+	auditLog, err := f.AuditLog()
+	if err != nil {
+		return err
+	}
+
+	result, err := instancegroups.RollbackInstanceGroup(clientset, auditLog, cluster, groupName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nRolling update stopped: the cluster failed validation after replacing instances in instance group %q.\n", groupName)
+	if result.Reverted {
+		fmt.Fprintf(out, "Rolled back: %s.\n", result.Reason)
+		commands.RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationRollback, "instancegroup/"+groupName, result.Before, result.After)
+	} else {
+		fmt.Fprintf(out, "Not rolled back: %s.\n", result.Reason)
+	}
+	if len(result.ManualActions) != 0 {
+		fmt.Fprintf(out, "To complete the rollback, run:\n")
+		for _, action := range result.ManualActions {
+			fmt.Fprintf(out, "  %s\n", action)
+		}
+	}
+	return nil
+}
+
+// printInstanceLaunchDiffs prints, for each group, how the launch configuration of the instances needing update
+// differs from the current launch configuration of the group
+func printInstanceLaunchDiffs(out io.Writer, cloud fi.Cloud, groups map[string]*cloudinstances.CloudInstanceGroup) error {
+	awsCloud, ok := cloud.(awsup.AWSCloud)
+	if !ok {
+		fmt.Fprintf(out, "\n--show-diff is not supported on %s; use --show-reason instead.\n", cloud.ProviderID())
+		return nil
+	}
+
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		group := groups[name]
+		launchDiffs, err := awsup.DiffCloudInstanceGroup(awsCloud, group)
+		if err != nil {
+			return fmt.Errorf("error comparing launch configurations of instance group %q: %v", group.InstanceGroup.ObjectMeta.Name, err)
+		}
+		for _, d := range launchDiffs {
+			fmt.Fprintf(out, "\nInstance group %s: %d instance(s) launched from %s, current is %s\n", group.InstanceGroup.ObjectMeta.Name, len(d.Instances), d.From, d.To)
+			fmt.Fprintf(out, "  instances: %s\n", strings.Join(d.Instances, ", "))
+			fmt.Fprint(out, formatInstanceLaunchDiff(d))
+		}
+	}
+	return nil
+}
+
+// formatInstanceLaunchDiff renders the differences of an InstanceLaunchDiff, showing a line diff for multi-line values
+func formatInstanceLaunchDiff(d *awsup.InstanceLaunchDiff) string {
+	var b bytes.Buffer
+	if d.From == d.To {
+		b.WriteString("  launched from the current launch configuration; see --show-reason for why they need updating\n")
+		return b.String()
+	}
+	if d.Missing {
+		fmt.Fprintf(&b, "  %s no longer exists, so can't be compared\n", d.From)
+		return b.String()
+	}
+	if len(d.Diffs) == 0 {
+		b.WriteString("  no differences found in the image, instance type, user data, security groups, volumes or IAM profile\n")
+		return b.String()
+	}
+	for _, c := range d.Diffs {
+		if !strings.Contains(c.Old, "\n") && !strings.Contains(c.New, "\n") {
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", c.Field, formatDiffValue(c.Old), formatDiffValue(c.New))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s:\n", c.Field)
+		for _, line := range strings.Split(strings.TrimSuffix(diff.FormatDiff(c.Old, c.New), "\n"), "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// formatDiffValue returns the value for display, marking an empty value
+func formatDiffValue(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// rollingUpdateSurge returns the instances the strategy adds while it replaces each group that needs updating,
+// as instance groups: a copy of each node group with the duplicate strategy, and max-surge instances of each
+// group with the native strategy.  The replace strategy deletes instances before they are replaced.
+func rollingUpdateSurge(options *RollingUpdateOptions, groups map[string]*cloudinstances.CloudInstanceGroup) []*api.InstanceGroup {
+	var surge []*api.InstanceGroup
+	for _, group := range groups {
+		if len(group.NeedUpdate) == 0 && !options.Force {
+			continue
+		}
+		ig := group.InstanceGroup
+		switch options.Strategy {
+		case instancegroups.RollingUpdateStrategyDuplicate:
+			if ig.Spec.Role == api.InstanceGroupRoleNode {
+				surge = append(surge, ig)
+			}
+		case instancegroups.RollingUpdateStrategyNative:
+			if options.MaxSurge > 0 {
+				ig = ig.DeepCopy()
+				ig.Spec.MinSize = fi.Int32(int32(options.MaxSurge))
+				ig.Spec.MaxSize = fi.Int32(int32(options.MaxSurge))
+				surge = append(surge, ig)
+			}
+		}
 	}
-	return d.RollingUpdate(groups, cluster, list)
+	return surge
 }
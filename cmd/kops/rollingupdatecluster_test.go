@@ -0,0 +1,63 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func TestFormatInstanceLaunchDiff(t *testing.T) {
+	grid := []struct {
+		Diff     *awsup.InstanceLaunchDiff
+		Expected string
+	}{
+		{
+			Diff: &awsup.InstanceLaunchDiff{
+				From: "nodes.example.com-20180601",
+				To:   "nodes.example.com-20180612",
+				Diffs: []*awsup.LaunchSpecDiff{
+					{Field: "image", Old: "ami-11111111", New: "ami-22222222"},
+					{Field: "keyName", Old: "", New: "admin"},
+					{Field: "userData:kube_env.yaml", Old: "a: 1\nb: 2", New: "a: 1\nb: 3"},
+				},
+			},
+			Expected: "  image: ami-11111111 -> ami-22222222\n" +
+				"  keyName: <none> -> admin\n" +
+				"  userData:kube_env.yaml:\n" +
+				"      a: 1\n" +
+				"    - b: 2\n" +
+				"    + b: 3\n",
+		},
+		{
+			Diff: &awsup.InstanceLaunchDiff{
+				From:    "nodes.example.com-20180101",
+				To:      "nodes.example.com-20180612",
+				Missing: true,
+			},
+			Expected: "  nodes.example.com-20180101 no longer exists, so can't be compared\n",
+		},
+	}
+
+	for _, g := range grid {
+		actual := formatInstanceLaunchDiff(g.Diff)
+		if actual != g.Expected {
+			t.Errorf("unexpected output for %s:\n%s\nexpected:\n%s", g.Diff.From, actual, g.Expected)
+		}
+	}
+}
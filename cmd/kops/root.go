@@ -33,7 +33,11 @@ import (
 	"k8s.io/kops/cmd/kops/util"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/cloudtrace"
 	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	"k8s.io/kops/upup/pkg/kutil"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
@@ -77,6 +81,15 @@ type RootCmd struct {
 
 	clusterName string
 
+	// cloudTrace is "summary" to print a summary of cloud API calls on exit, or a file path to also write a full trace
+	cloudTrace string
+
+	// cloudPlugins are the Go plugins, or directories of them, that add cloud providers
+	cloudPlugins []string
+
+	// awsAssumeRoleSessionTags are the key=value tags of the session of the role set with --aws-assume-role-arn
+	awsAssumeRoleSessionTags []string
+
 	cobraCommand *cobra.Command
 }
 
@@ -96,6 +109,7 @@ func Execute() {
 	if err := rootCommand.cobraCommand.Execute(); err != nil {
 		exitWithError(err)
 	}
+	reportCloudTrace()
 }
 
 func init() {
@@ -132,19 +146,69 @@ func NewCmdRoot(f *util.Factory, out io.Writer) *cobra.Command {
 
 	defaultClusterName := os.Getenv("KOPS_CLUSTER_NAME")
 	cmd.PersistentFlags().StringVarP(&rootCommand.clusterName, "name", "", defaultClusterName, "Name of cluster. Overrides KOPS_CLUSTER_NAME environment variable")
+	cobra.MarkFlagCustom(cmd.PersistentFlags(), "name", "__kops_get_clusters")
+
+	cmd.BashCompletionFunction = bashCompletionFunc
+
+	cmd.PersistentFlags().StringVar(&rootCommand.cloudTrace, "cloud-trace", "", "Record every cloud API call: \"summary\" prints a per-operation summary on exit, any other value is a file to which the full trace is also written")
+
+	cmd.PersistentFlags().Duration("timeout", 0, "Maximum duration of each cloud or kubernetes API call, including its retries (0 for no limit). Overrides KOPS_TIMEOUT environment variable")
+	viper.BindPFlag("KOPS_TIMEOUT", cmd.PersistentFlags().Lookup("timeout"))
+	viper.BindEnv("KOPS_TIMEOUT")
+
+	cmd.PersistentFlags().Int("retry-max-attempts", 0, "Maximum attempts of each cloud or kubernetes API call, including the first (0 for each client's default). Overrides KOPS_RETRY_MAX_ATTEMPTS environment variable")
+	viper.BindPFlag("KOPS_RETRY_MAX_ATTEMPTS", cmd.PersistentFlags().Lookup("retry-max-attempts"))
+	viper.BindEnv("KOPS_RETRY_MAX_ATTEMPTS")
+
+	cmd.PersistentFlags().Duration("retry-backoff", 0, "Delay before the first retry of a failed API call, doubling with each retry (0 for each client's default). Overrides KOPS_RETRY_BACKOFF environment variable")
+	viper.BindPFlag("KOPS_RETRY_BACKOFF", cmd.PersistentFlags().Lookup("retry-backoff"))
+	viper.BindEnv("KOPS_RETRY_BACKOFF")
+
+	cmd.PersistentFlags().Duration("retry-max-backoff", 0, "Maximum delay between retries of a failed API call (0 for each client's default). Overrides KOPS_RETRY_MAX_BACKOFF environment variable")
+	viper.BindPFlag("KOPS_RETRY_MAX_BACKOFF", cmd.PersistentFlags().Lookup("retry-max-backoff"))
+	viper.BindEnv("KOPS_RETRY_MAX_BACKOFF")
+
+	cmd.PersistentFlags().String("aws-assume-role-arn", "", "IAM role that kops assumes for all its AWS API calls, e.g. in the account of the cluster; takes precedence over cloudConfig.awsAssumeRole in the cluster spec. Overrides KOPS_AWS_ASSUME_ROLE_ARN environment variable")
+	viper.BindPFlag("KOPS_AWS_ASSUME_ROLE_ARN", cmd.PersistentFlags().Lookup("aws-assume-role-arn"))
+	viper.BindEnv("KOPS_AWS_ASSUME_ROLE_ARN")
+
+	cmd.PersistentFlags().String("aws-assume-role-external-id", "", "External id required by the trust policy of the role set with --aws-assume-role-arn. Overrides KOPS_AWS_ASSUME_ROLE_EXTERNAL_ID environment variable")
+	viper.BindPFlag("KOPS_AWS_ASSUME_ROLE_EXTERNAL_ID", cmd.PersistentFlags().Lookup("aws-assume-role-external-id"))
+	viper.BindEnv("KOPS_AWS_ASSUME_ROLE_EXTERNAL_ID")
+
+	cmd.PersistentFlags().StringSliceVar(&rootCommand.awsAssumeRoleSessionTags, "aws-assume-role-session-tag", nil, "Tag of the session of the role set with --aws-assume-role-arn, as key=value; may be repeated")
+
+	cmd.PersistentFlags().StringSliceVar(&rootCommand.cloudPlugins, "cloud-plugin", nil, "Go plugin, or directory of plugins, that adds a cloud provider; may be repeated. Overrides KOPS_CLOUD_PLUGINS environment variable (default ~/.kops/plugins/cloud)")
 
 	// create subcommands
+	cmd.AddCommand(NewCmdApply(f, out))
+	cmd.AddCommand(NewCmdApprove(f, out))
+	cmd.AddCommand(NewCmdBackup(f, out))
+	cmd.AddCommand(NewCmdClone(f, out))
 	cmd.AddCommand(NewCmdCompletion(f, out))
+	cmd.AddCommand(NewCmdCompletionNames(f, out))
 	cmd.AddCommand(NewCmdCreate(f, out))
 	cmd.AddCommand(NewCmdDelete(f, out))
 	cmd.AddCommand(NewCmdEdit(f, out))
 	cmd.AddCommand(NewCmdExport(f, out))
 	cmd.AddCommand(NewCmdGet(f, out))
+	cmd.AddCommand(NewCmdLock(f, out))
 	cmd.AddCommand(NewCmdUpdate(f, out))
 	cmd.AddCommand(NewCmdReplace(f, out))
 	cmd.AddCommand(NewCmdRollingUpdate(f, out))
+	cmd.AddCommand(NewCmdRotate(f, out))
+	cmd.AddCommand(NewCmdPause(f, out))
+	cmd.AddCommand(NewCmdPreflight(f, out))
+	cmd.AddCommand(NewCmdRestore(f, out))
+	cmd.AddCommand(NewCmdResume(f, out))
+	cmd.AddCommand(NewCmdScale(f, out))
+	cmd.AddCommand(NewCmdServer(f, out))
 	cmd.AddCommand(NewCmdSet(f, out))
+	cmd.AddCommand(NewCmdSSH(f, out))
+	cmd.AddCommand(NewCmdStart(f, out))
+	cmd.AddCommand(NewCmdStop(f, out))
 	cmd.AddCommand(NewCmdToolbox(f, out))
+	cmd.AddCommand(NewCmdUnlock(f, out))
 	cmd.AddCommand(NewCmdValidate(f, out))
 
 	return cmd
@@ -184,6 +248,85 @@ func initConfig() {
 
 	// Tolerate multiple slashes at end
 	rootCommand.RegistryPath = strings.TrimSuffix(rootCommand.RegistryPath, "/")
+
+	if rootCommand.cloudTrace != "" {
+		cloudtrace.Enable()
+	}
+
+	policy := retrypolicy.Policy{
+		Timeout:     viper.GetDuration("KOPS_TIMEOUT"),
+		MaxAttempts: viper.GetInt("KOPS_RETRY_MAX_ATTEMPTS"),
+		Backoff:     viper.GetDuration("KOPS_RETRY_BACKOFF"),
+		MaxBackoff:  viper.GetDuration("KOPS_RETRY_MAX_BACKOFF"),
+	}
+	if err := policy.Validate(); err != nil {
+		exitWithError(fmt.Errorf("invalid retry policy: %v", err))
+	}
+	retrypolicy.Set(policy)
+
+	if roleARN := viper.GetString("KOPS_AWS_ASSUME_ROLE_ARN"); roleARN != "" {
+		sessionTags, err := awsup.ParseSessionTags(rootCommand.awsAssumeRoleSessionTags)
+		if err != nil {
+			exitWithError(err)
+		}
+		awsup.SetDefaultAssumeRole(&awsup.AssumeRole{
+			RoleARN:     roleARN,
+			ExternalID:  viper.GetString("KOPS_AWS_ASSUME_ROLE_EXTERNAL_ID"),
+			SessionTags: sessionTags,
+		})
+	} else if len(rootCommand.awsAssumeRoleSessionTags) != 0 {
+		exitWithError(fmt.Errorf("--aws-assume-role-session-tag requires --aws-assume-role-arn"))
+	}
+
+	if err := loadCloudPlugins(rootCommand.cloudPlugins); err != nil {
+		exitWithError(err)
+	}
+}
+
+// loadCloudPlugins registers the cloud providers of the plugins at the paths, or else of those listed in
+// KOPS_CLOUD_PLUGINS (separated like PATH), or else of those in ~/.kops/plugins/cloud if it exists.
+// Only plugins that were asked for are an error if they fail to load; a broken plugin that was merely left in
+// ~/.kops/plugins/cloud must not stop commands that do not use its cloud, so we warn and carry on.
+func loadCloudPlugins(paths []string) error {
+	if len(paths) == 0 {
+		if env := os.Getenv("KOPS_CLOUD_PLUGINS"); env != "" {
+			paths = filepath.SplitList(env)
+		}
+	}
+	if len(paths) != 0 {
+		return cloudplugin.Load(paths)
+	}
+
+	dir := cloudplugin.DefaultPluginDir(homedir.HomeDir())
+	files, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil
+	}
+	for _, f := range files {
+		if err := cloudplugin.Load([]string{f}); err != nil {
+			glog.Warningf("ignoring cloud plugin: %v", err)
+		}
+	}
+	return nil
+}
+
+// reportCloudTrace prints the summary of cloud API calls, and writes the trace file, if tracing was requested
+func reportCloudTrace() {
+	recorder := cloudtrace.Active()
+	if recorder == nil {
+		return
+	}
+
+	if err := recorder.WriteSummary(os.Stderr); err != nil {
+		glog.Warningf("error writing cloud trace summary: %v", err)
+	}
+	if rootCommand.cloudTrace != "summary" {
+		if err := recorder.WriteTrace(rootCommand.cloudTrace); err != nil {
+			glog.Warningf("%v", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "\nCloud trace written to %s\n", rootCommand.cloudTrace)
+		}
+	}
 }
 
 func (c *RootCmd) AddCommand(cmd *cobra.Command) {
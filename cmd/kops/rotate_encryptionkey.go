@@ -0,0 +1,229 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/encryptionconfig"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	rotateEncryptionKeyLong = templates.LongDesc(i18n.T(`
+	Rotate the aescbc key used by the kube-apiserver to encrypt secrets at rest.
+
+	Rotation is done in three steps, with an update and rolling-update of the
+	masters and any dedicated APIServer instance groups after each one, so that
+	every kube-apiserver can always read the secrets written by the others:
+
+	1. kops rotate encryption-key adds a new key, which the kube-apiservers can use for decryption.
+	2. kops rotate encryption-key --promote makes the new key the key used for encryption.
+	3. kops rotate encryption-key --reencrypt rewrites every secret with the new key, and
+	   removes the old keys from the encryption config.`))
+
+	rotateEncryptionKeyExample = templates.Examples(i18n.T(`
+	# Add a new encryption key
+	kops rotate encryption-key --name k8s-cluster.example.com --state s3://example.com
+	kops update cluster k8s-cluster.example.com --yes
+	kops rolling-update cluster k8s-cluster.example.com --instance-group-roles=Master,APIServer --force --yes
+
+	# Start encrypting with the new key
+	kops rotate encryption-key --promote --name k8s-cluster.example.com --state s3://example.com
+	kops update cluster k8s-cluster.example.com --yes
+	kops rolling-update cluster k8s-cluster.example.com --instance-group-roles=Master,APIServer --force --yes
+
+	# Re-encrypt all secrets with the new key, and remove the old key
+	kops rotate encryption-key --reencrypt --name k8s-cluster.example.com --state s3://example.com
+	kops update cluster k8s-cluster.example.com --yes
+	kops rolling-update cluster k8s-cluster.example.com --instance-group-roles=Master,APIServer --force --yes
+	`))
+
+	rotateEncryptionKeyShort = i18n.T(`Rotate the encryption-at-rest key.`)
+)
+
+type RotateEncryptionKeyOptions struct {
+	ClusterName string
+	Promote     bool
+	Reencrypt   bool
+}
+
+func NewCmdRotateEncryptionKey(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RotateEncryptionKeyOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "encryption-key",
+		Short:   rotateEncryptionKeyShort,
+		Long:    rotateEncryptionKeyLong,
+		Example: rotateEncryptionKeyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err = RunRotateEncryptionKey(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.Promote, "promote", options.Promote, "Use the newest key to encrypt secrets")
+	cmd.Flags().BoolVar(&options.Reencrypt, "reencrypt", options.Reencrypt, "Re-encrypt all secrets with the current key, and remove the old keys")
+
+	return cmd
+}
+
+func RunRotateEncryptionKey(f *util.Factory, out io.Writer, options *RotateEncryptionKeyOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+	if options.Promote && options.Reencrypt {
+		return fmt.Errorf("--promote and --reencrypt cannot be used together; the masters must be updated in between")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	if !fi.BoolValue(cluster.Spec.EncryptionConfig) {
+		return fmt.Errorf("encryptionConfig is not enabled for cluster %q", cluster.ObjectMeta.Name)
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	secret, err := secretStore.FindSecret(encryptionconfig.SecretName)
+	if err != nil {
+		return fmt.Errorf("error reading encryption config: %v", err)
+	}
+	if secret == nil {
+		return fmt.Errorf("encryption config not found; run kops update cluster to generate it")
+	}
+
+	config, err := encryptionconfig.Parse(secret.Data)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case options.Promote:
+		key, err := config.PromoteNewestKey()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Promoted key %q to be used for encryption\n", key.Name)
+
+	case options.Reencrypt:
+		if err := reencryptSecrets(out, cluster.ObjectMeta.Name); err != nil {
+			return err
+		}
+		if !config.SupportsKeyRotation() {
+			// Nothing to prune; KMS keys are rotated by the KMS itself
+			return nil
+		}
+		pruned, err := config.PruneKeys()
+		if err != nil {
+			return err
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		for _, key := range pruned {
+			fmt.Fprintf(out, "Removed key %q\n", key.Name)
+		}
+
+	default:
+		key, err := config.AddKey()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Added key %q\n", key.Name)
+	}
+
+	data, err := config.ToYAML()
+	if err != nil {
+		return fmt.Errorf("error serializing encryption config: %v", err)
+	}
+
+	if _, err := secretStore.ReplaceSecret(encryptionconfig.SecretName, &fi.Secret{Data: data}); err != nil {
+		return fmt.Errorf("error updating encryption config: %v", err)
+	}
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationRotate, "secret/"+encryptionconfig.SecretName, "")
+
+	fmt.Fprintf(out, "\nThe encryption config has been updated; to apply it, run:\n  kops update cluster %s --yes\n  kops rolling-update cluster %s --instance-group-roles=Master,APIServer --force --yes\n", cluster.ObjectMeta.Name, cluster.ObjectMeta.Name)
+
+	return nil
+}
+
+// reencryptSecrets rewrites every secret in the cluster, so the kube-apiserver stores it using the current encryption key
+func reencryptSecrets(out io.Writer, contextName string) error {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+	retrypolicy.ConfigureKubernetes(config)
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kube client for %q: %v", contextName, err)
+	}
+
+	secrets, err := k8sClient.CoreV1().Secrets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing secrets: %v", err)
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if _, err := k8sClient.CoreV1().Secrets(secret.Namespace).Update(secret); err != nil {
+			return fmt.Errorf("error re-encrypting secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+	}
+
+	fmt.Fprintf(out, "Re-encrypted %d secrets\n", len(secrets.Items))
+
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	scaleLong = templates.LongDesc(i18n.T(`
+	Scale the cloud resources of a cluster.`))
+
+	scaleExample = templates.Examples(i18n.T(`
+	# Allow the nodes instance group to scale between 5 and 20 instances
+	kops scale ig --name k8s-cluster.example.com nodes --min 5 --max 20 --yes
+	`))
+
+	scaleShort = i18n.T(`Scale the cloud resources of a cluster.`)
+)
+
+func NewCmdScale(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "scale",
+		Short:   scaleShort,
+		Long:    scaleLong,
+		Example: scaleExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdScaleInstanceGroup(f, out))
+
+	return cmd
+}
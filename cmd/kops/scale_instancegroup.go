@@ -0,0 +1,291 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	scaleIgLong = templates.LongDesc(i18n.T(`
+		Scale an instancegroup.
+
+		The minimum and maximum size of the instance group are updated in the registry,
+		and the cloud group (for example the AWS AutoScalingGroup) is resized immediately,
+		without running "kops update cluster". Use --desired to also set the number of
+		instances the cloud group should run now.`))
+
+	scaleIgExample = templates.Examples(i18n.T(`
+		# Allow the nodes instance group to scale between 5 and 20 instances
+		kops scale ig --name k8s-cluster.example.com nodes --min 5 --max 20 --yes
+
+		# Run 10 nodes now, keeping the existing minimum and maximum
+		kops scale ig --name k8s-cluster.example.com nodes --desired 10 --yes
+		`))
+
+	scaleIgShort = i18n.T(`Scale instancegroup`)
+)
+
+type ScaleInstanceGroupOptions struct {
+	Yes         bool
+	ClusterName string
+	GroupName   string
+
+	// MinSize and MaxSize are the new sizes of the group, or nil to keep the current size
+	MinSize *int32
+	MaxSize *int32
+
+	// DesiredCapacity is the number of instances the cloud group should run, or nil to leave it to the cloud
+	DesiredCapacity *int
+}
+
+func NewCmdScaleInstanceGroup(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ScaleInstanceGroupOptions{}
+
+	var minSize, maxSize int32
+	var desired int
+
+	cmd := &cobra.Command{
+		Use:     "instancegroup",
+		Aliases: []string{"instancegroups", "ig"},
+		Short:   scaleIgShort,
+		Long:    scaleIgLong,
+		Example: scaleIgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				exitWithError(fmt.Errorf("Specify name of instance group to scale"))
+			}
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("Can only scale one instance group at a time!"))
+			}
+
+			options.GroupName = args[0]
+			options.ClusterName = rootCommand.ClusterName()
+
+			if cmd.Flags().Changed("min") {
+				options.MinSize = &minSize
+			}
+			if cmd.Flags().Changed("max") {
+				options.MaxSize = &maxSize
+			}
+			if cmd.Flags().Changed("desired") {
+				options.DesiredCapacity = &desired
+			}
+
+			if err := RunScaleInstanceGroup(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately scale the instance group")
+	cmd.Flags().Int32Var(&minSize, "min", minSize, "Minimum number of instances in the instance group")
+	cmd.Flags().Int32Var(&maxSize, "max", maxSize, "Maximum number of instances in the instance group")
+	cmd.Flags().IntVar(&desired, "desired", desired, "Number of instances the cloud group should run now")
+
+	return cmd
+}
+
+// RunScaleInstanceGroup updates the size of an instance group and resizes its cloud groups
+func RunScaleInstanceGroup(f *util.Factory, out io.Writer, options *ScaleInstanceGroupOptions) error {
+	groupName := options.GroupName
+	if groupName == "" {
+		return fmt.Errorf("GroupName is required")
+	}
+
+	clusterName := options.ClusterName
+	if clusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	if options.MinSize == nil && options.MaxSize == nil && options.DesiredCapacity == nil {
+		return fmt.Errorf("specify at least one of --min, --max or --desired")
+	}
+
+	cluster, err := GetCluster(f, clusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	group, err := clientset.InstanceGroupsFor(cluster).Get(groupName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading InstanceGroup %q: %v", groupName, err)
+	}
+	if group == nil {
+		return fmt.Errorf("InstanceGroup %q not found", groupName)
+	}
+	oldGroup := group.DeepCopy()
+
+	if instancegroups.IsPaused(group) {
+		return fmt.Errorf("InstanceGroup %q is paused; use \"kops resume instancegroup\" first", groupName)
+	}
+
+	if options.MinSize != nil {
+		group.Spec.MinSize = fi.Int32(*options.MinSize)
+	}
+	if options.MaxSize != nil {
+		group.Spec.MaxSize = fi.Int32(*options.MaxSize)
+	}
+
+	fullGroup, err := populateInstanceGroup(clientset, cluster, group)
+	if err != nil {
+		return err
+	}
+
+	minSize := int(fi.Int32Value(fullGroup.Spec.MinSize))
+	maxSize := int(fi.Int32Value(fullGroup.Spec.MaxSize))
+
+	fmt.Fprintf(out, "InstanceGroup %q will be scaled to min=%d max=%d", groupName, minSize, maxSize)
+	if options.DesiredCapacity != nil {
+		fmt.Fprintf(out, " desired=%d", *options.DesiredCapacity)
+	}
+	fmt.Fprintf(out, "\n")
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to scale instancegroup\n")
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	if err := updateAndResizeInstanceGroup(f, cluster, audit.OperationScale, oldGroup, fullGroup, options.DesiredCapacity); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nScaled InstanceGroup: %q\n", groupName)
+
+	return nil
+}
+
+// populateInstanceGroup validates the instance group against the cluster, returning the fully populated instance group
+func populateInstanceGroup(clientset simple.Clientset, cluster *api.Cluster, group *api.InstanceGroup) (*api.InstanceGroup, error) {
+	if err := validation.ValidateInstanceGroup(group); err != nil {
+		return nil, err
+	}
+
+	channel, err := cloudup.ChannelForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	fullGroup, err := cloudup.PopulateInstanceGroupSpec(cluster, group, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	// We need the full cluster spec to perform deep validation
+	// Note that we don't write it back though
+	err = cloudup.PerformAssignments(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error populating configuration: %v", err)
+	}
+
+	assetBuilder := assets.NewAssetBuilder(cluster, "")
+	fullCluster, err := cloudup.PopulateClusterSpec(clientset, cluster, assetBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.CrossValidateInstanceGroup(fullGroup, fullCluster, true); err != nil {
+		return nil, err
+	}
+
+	return fullGroup, nil
+}
+
+// updateAndResizeInstanceGroup writes the instance group to the registry, recording the operation which changed it
+// from oldGroup in the audit log, and resizes its cloud groups to match
+func updateAndResizeInstanceGroup(f *util.Factory, cluster *api.Cluster, operation string, oldGroup *api.InstanceGroup, fullGroup *api.InstanceGroup, desiredCapacity *int) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+
+	groups, err := cloud.GetCloudGroups(cluster, []*api.InstanceGroup{fullGroup}, false, nil)
+	if err != nil {
+		return fmt.Errorf("error finding CloudInstanceGroups: %v", err)
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("no cloud resources found for InstanceGroup %q; use \"kops update cluster\" to create them", fullGroup.ObjectMeta.Name)
+	}
+
+	// Note we perform as much validation as we can, before writing a bad config
+	if _, err := clientset.InstanceGroupsFor(cluster).Update(fullGroup); err != nil {
+		return err
+	}
+	commands.RecordAuditChange(f, cluster.ObjectMeta.Name, operation, "instancegroup/"+fullGroup.ObjectMeta.Name, oldGroup, fullGroup)
+
+	minSize := int(fi.Int32Value(fullGroup.Spec.MinSize))
+	maxSize := int(fi.Int32Value(fullGroup.Spec.MaxSize))
+	for _, g := range groups {
+		if err := instancegroups.ScaleCloudGroup(cloud, g, minSize, maxSize, desiredCapacity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateClusterAnnotations writes the cluster, whose annotations have changed, back to the registry, recording the
+// operation which changed it from oldCluster in the audit log
+func updateClusterAnnotations(f *util.Factory, operation string, oldCluster *api.Cluster, cluster *api.Cluster) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the current status of the cluster.  This will eventually be part of the cluster object.
+	statusDiscovery := &commands.CloudDiscoveryStatusStore{}
+	status, err := statusDiscovery.FindClusterStatus(cluster)
+	if err != nil {
+		return err
+	}
+
+	if _, err := clientset.UpdateCluster(cluster, status); err != nil {
+		return fmt.Errorf("error updating cluster %q: %v", cluster.ObjectMeta.Name, err)
+	}
+	commands.RecordAuditChange(f, cluster.ObjectMeta.Name, operation, "cluster", oldCluster, cluster)
+	return nil
+}
@@ -0,0 +1,545 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/gorilla/mux"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/clusterlock"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	serverLong = templates.LongDesc(i18n.T(`
+	Serve the kops cluster operations over an authenticated REST API.
+
+	Clients authenticate with a bearer token from the token file, which has one
+	"token,user" pair per line.  Every change made through the API is recorded in the
+	audit log and checked against cluster locks as the user the token belongs to.
+
+	Requests are handled one at a time, so a rolling update blocks other requests
+	until it completes.`))
+
+	serverExample = templates.Examples(i18n.T(`
+	# Serve the API over TLS on port 8443
+	kops server --state=s3://kops-state-1234 --listen=:8443 \
+		--token-file=/etc/kops/tokens.csv \
+		--tls-cert-file=/etc/kops/server.crt --tls-private-key-file=/etc/kops/server.key
+
+	# List the clusters through the API
+	curl -H "Authorization: Bearer $TOKEN" https://localhost:8443/api/v1/clusters
+	`))
+
+	serverShort = i18n.T(`Serve the kops cluster operations over a REST API.`)
+)
+
+// maxServerRequestBody is the largest request body the server will read
+const maxServerRequestBody = 1024 * 1024
+
+type ServerOptions struct {
+	// Listen is the address the server listens on
+	Listen string
+
+	// TokenFile is the path of a file of "token,user" lines used to authenticate requests
+	TokenFile string
+
+	// TLSCertFile and TLSKeyFile are the certificate and key to serve; if not set the server uses plain HTTP
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+func (o *ServerOptions) InitDefaults() {
+	o.Listen = ":8080"
+}
+
+func NewCmdServer(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ServerOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "server",
+		Short:   serverShort,
+		Long:    serverLong,
+		Example: serverExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := RunServer(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Listen, "listen", options.Listen, "Address to listen on")
+	cmd.Flags().StringVar(&options.TokenFile, "token-file", options.TokenFile, "File of \"token,user\" lines used to authenticate requests")
+	cmd.Flags().StringVar(&options.TLSCertFile, "tls-cert-file", options.TLSCertFile, "TLS certificate to serve")
+	cmd.Flags().StringVar(&options.TLSKeyFile, "tls-private-key-file", options.TLSKeyFile, "TLS private key for --tls-cert-file")
+
+	return cmd
+}
+
+func RunServer(f *util.Factory, out io.Writer, options *ServerOptions) error {
+	if options.TokenFile == "" {
+		return fmt.Errorf("--token-file is required")
+	}
+	if (options.TLSCertFile == "") != (options.TLSKeyFile == "") {
+		return fmt.Errorf("--tls-cert-file and --tls-private-key-file must be specified together")
+	}
+
+	tokens, err := readServerTokens(options.TokenFile)
+	if err != nil {
+		return err
+	}
+
+	s := newKopsServer(f, tokens)
+
+	fmt.Fprintf(out, "Serving kops API on %s\n", options.Listen)
+	if options.TLSCertFile == "" {
+		glog.Warningf("serving without TLS; bearer tokens will be sent in the clear")
+		return http.ListenAndServe(options.Listen, s)
+	}
+	return http.ListenAndServeTLS(options.Listen, options.TLSCertFile, options.TLSKeyFile, s)
+}
+
+// readServerTokens reads a token file, mapping each token to its user
+func readServerTokens(p string) (map[string]string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading token file %q: %v", p, err)
+	}
+	defer file.Close()
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ",", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) == "" || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("error parsing token file %q line %d: expected \"token,user\"", p, n)
+		}
+		tokens[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading token file %q: %v", p, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token file %q does not contain any tokens", p)
+	}
+	return tokens, nil
+}
+
+// kopsServer serves the cluster operations over HTTP
+type kopsServer struct {
+	f      *util.Factory
+	tokens map[string]string
+	router *mux.Router
+
+	// mutex serializes requests; the audit user and the commands we call are not safe for concurrent use
+	mutex sync.Mutex
+}
+
+func newKopsServer(f *util.Factory, tokens map[string]string) *kopsServer {
+	s := &kopsServer{
+		f:      f,
+		tokens: tokens,
+		router: mux.NewRouter(),
+	}
+
+	r := s.router.PathPrefix("/api/v1").Subrouter()
+	r.HandleFunc("/clusters", s.listClusters).Methods(http.MethodGet)
+	r.HandleFunc("/clusters", s.createCluster).Methods(http.MethodPost)
+	r.HandleFunc("/clusters/{cluster}", s.getCluster).Methods(http.MethodGet)
+	r.HandleFunc("/clusters/{cluster}", s.replaceCluster).Methods(http.MethodPut)
+	r.HandleFunc("/clusters/{cluster}", s.deleteCluster).Methods(http.MethodDelete)
+	r.HandleFunc("/clusters/{cluster}/instancegroups", s.listInstanceGroups).Methods(http.MethodGet)
+	r.HandleFunc("/clusters/{cluster}/instancegroups", s.createInstanceGroup).Methods(http.MethodPost)
+	r.HandleFunc("/clusters/{cluster}/instancegroups/{ig}", s.getInstanceGroup).Methods(http.MethodGet)
+	r.HandleFunc("/clusters/{cluster}/instancegroups/{ig}", s.replaceInstanceGroup).Methods(http.MethodPut)
+	r.HandleFunc("/clusters/{cluster}/instancegroups/{ig}", s.deleteInstanceGroup).Methods(http.MethodDelete)
+	r.HandleFunc("/clusters/{cluster}/update", s.updateCluster).Methods(http.MethodPost)
+	r.HandleFunc("/clusters/{cluster}/validate", s.validateCluster).Methods(http.MethodPost)
+	r.HandleFunc("/clusters/{cluster}/rolling-update", s.rollingUpdateCluster).Methods(http.MethodPost)
+
+	return s
+}
+
+// ServeHTTP authenticates the request and runs it as the user the token belongs to
+func (s *kopsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := ""
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		user = s.tokens[strings.TrimPrefix(auth, "Bearer ")]
+	}
+	if user == "" {
+		writeServerError(w, http.StatusUnauthorized, fmt.Errorf("a valid bearer token is required"))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	audit.SetCurrentUser(user)
+	defer audit.SetCurrentUser("")
+
+	glog.Infof("%s %s %s", user, r.Method, r.URL.Path)
+	s.router.ServeHTTP(w, r)
+}
+
+func (s *kopsServer) listClusters(w http.ResponseWriter, r *http.Request) {
+	clientset, err := s.f.Clientset()
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	list, err := clientset.ListClusters(metav1.ListOptions{})
+	writeServerResult(w, list, err)
+}
+
+func (s *kopsServer) getCluster(w http.ResponseWriter, r *http.Request) {
+	cluster, err := s.findCluster(r)
+	writeServerResult(w, cluster, err)
+}
+
+func (s *kopsServer) createCluster(w http.ResponseWriter, r *http.Request) {
+	cluster := &kopsapi.Cluster{}
+	if err := readServerObject(r, cluster); err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+
+	err := func() error {
+		clientset, err := s.f.Clientset()
+		if err != nil {
+			return err
+		}
+		if err := cloudup.PerformAssignments(cluster); err != nil {
+			return fmt.Errorf("error populating configuration: %v", err)
+		}
+		if _, err := clientset.CreateCluster(cluster); err != nil {
+			return err
+		}
+		commands.RecordAuditChange(s.f, cluster.ObjectMeta.Name, audit.OperationCreate, "cluster", nil, cluster)
+		return nil
+	}()
+	writeServerResult(w, cluster, err)
+}
+
+func (s *kopsServer) replaceCluster(w http.ResponseWriter, r *http.Request) {
+	cluster := &kopsapi.Cluster{}
+	if err := readServerObject(r, cluster); err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+
+	err := func() error {
+		existing, err := s.findCluster(r)
+		if err != nil {
+			return err
+		}
+		if cluster.ObjectMeta.Name != existing.ObjectMeta.Name {
+			return apierrors.NewBadRequest(fmt.Sprintf("cluster name %q does not match %q", cluster.ObjectMeta.Name, existing.ObjectMeta.Name))
+		}
+		if err := commands.CheckClusterLock(s.f, existing.ObjectMeta.Name); err != nil {
+			return err
+		}
+
+		clientset, err := s.f.Clientset()
+		if err != nil {
+			return err
+		}
+		statusDiscovery := &commands.CloudDiscoveryStatusStore{}
+		status, err := statusDiscovery.FindClusterStatus(cluster)
+		if err != nil {
+			return err
+		}
+		if _, err := clientset.UpdateCluster(cluster, status); err != nil {
+			return err
+		}
+		commands.RecordAuditChange(s.f, existing.ObjectMeta.Name, audit.OperationReplace, "cluster", existing, cluster)
+		return nil
+	}()
+	writeServerResult(w, cluster, err)
+}
+
+func (s *kopsServer) deleteCluster(w http.ResponseWriter, r *http.Request) {
+	options := &DeleteClusterOptions{
+		ClusterName: mux.Vars(r)["cluster"],
+		Yes:         serverQueryBool(r, "yes"),
+	}
+	s.runOperation(w, func(out io.Writer) error {
+		return RunDeleteCluster(s.f, out, options)
+	})
+}
+
+func (s *kopsServer) listInstanceGroups(w http.ResponseWriter, r *http.Request) {
+	cluster, err := s.findCluster(r)
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	clientset, err := s.f.Clientset()
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	writeServerResult(w, list, err)
+}
+
+func (s *kopsServer) getInstanceGroup(w http.ResponseWriter, r *http.Request) {
+	cluster, err := s.findCluster(r)
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	clientset, err := s.f.Clientset()
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	ig, err := clientset.InstanceGroupsFor(cluster).Get(mux.Vars(r)["ig"], metav1.GetOptions{})
+	writeServerResult(w, ig, err)
+}
+
+func (s *kopsServer) createInstanceGroup(w http.ResponseWriter, r *http.Request) {
+	ig := &kopsapi.InstanceGroup{}
+	if err := readServerObject(r, ig); err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+
+	err := func() error {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return err
+		}
+		if err := commands.CheckClusterLock(s.f, cluster.ObjectMeta.Name); err != nil {
+			return err
+		}
+
+		clientset, err := s.f.Clientset()
+		if err != nil {
+			return err
+		}
+		if ig.ObjectMeta.Labels == nil {
+			ig.ObjectMeta.Labels = make(map[string]string)
+		}
+		ig.ObjectMeta.Labels[kopsapi.LabelClusterName] = cluster.ObjectMeta.Name
+		if _, err := clientset.InstanceGroupsFor(cluster).Create(ig); err != nil {
+			return err
+		}
+		commands.RecordAuditChange(s.f, cluster.ObjectMeta.Name, audit.OperationCreate, "instancegroup/"+ig.ObjectMeta.Name, nil, ig)
+		return nil
+	}()
+	writeServerResult(w, ig, err)
+}
+
+func (s *kopsServer) replaceInstanceGroup(w http.ResponseWriter, r *http.Request) {
+	ig := &kopsapi.InstanceGroup{}
+	if err := readServerObject(r, ig); err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+
+	err := func() error {
+		cluster, err := s.findCluster(r)
+		if err != nil {
+			return err
+		}
+		if ig.ObjectMeta.Name != mux.Vars(r)["ig"] {
+			return apierrors.NewBadRequest(fmt.Sprintf("instance group name %q does not match %q", ig.ObjectMeta.Name, mux.Vars(r)["ig"]))
+		}
+		if err := commands.CheckClusterLock(s.f, cluster.ObjectMeta.Name); err != nil {
+			return err
+		}
+
+		clientset, err := s.f.Clientset()
+		if err != nil {
+			return err
+		}
+		existing, err := clientset.InstanceGroupsFor(cluster).Get(ig.ObjectMeta.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, err := clientset.InstanceGroupsFor(cluster).Update(ig); err != nil {
+			return err
+		}
+		commands.RecordAuditChange(s.f, cluster.ObjectMeta.Name, audit.OperationReplace, "instancegroup/"+ig.ObjectMeta.Name, existing, ig)
+		return nil
+	}()
+	writeServerResult(w, ig, err)
+}
+
+func (s *kopsServer) deleteInstanceGroup(w http.ResponseWriter, r *http.Request) {
+	options := &DeleteInstanceGroupOptions{}
+	options.InitDefaults()
+	options.ClusterName = mux.Vars(r)["cluster"]
+	options.GroupName = mux.Vars(r)["ig"]
+	options.Yes = serverQueryBool(r, "yes")
+	options.CloudOnly = serverQueryBool(r, "cloudonly")
+	s.runOperation(w, func(out io.Writer) error {
+		return RunDeleteInstanceGroup(s.f, out, options)
+	})
+}
+
+func (s *kopsServer) updateCluster(w http.ResponseWriter, r *http.Request) {
+	options := &UpdateClusterOptions{}
+	options.InitDefaults()
+	options.Yes = serverQueryBool(r, "yes")
+	options.CreateKubecfg = false
+	clusterName := mux.Vars(r)["cluster"]
+	s.runOperation(w, func(out io.Writer) error {
+		_, err := RunUpdateCluster(s.f, clusterName, out, options)
+		return err
+	})
+}
+
+func (s *kopsServer) validateCluster(w http.ResponseWriter, r *http.Request) {
+	cluster, err := s.findCluster(r)
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	options := &ValidateClusterOptions{}
+	options.InitDefaults()
+	result, err := validateCluster(s.f, cluster, ioutil.Discard, options)
+	if err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	writeServerJSON(w, http.StatusOK, result)
+}
+
+func (s *kopsServer) rollingUpdateCluster(w http.ResponseWriter, r *http.Request) {
+	options := &RollingUpdateOptions{}
+	options.InitDefaults()
+	options.ClusterName = mux.Vars(r)["cluster"]
+	options.Yes = serverQueryBool(r, "yes")
+	options.Force = serverQueryBool(r, "force")
+	options.CloudOnly = serverQueryBool(r, "cloudonly")
+	options.InstanceGroups = r.URL.Query()["instance-group"]
+	s.runOperation(w, func(out io.Writer) error {
+		return RunRollingUpdateCluster(s.f, out, options)
+	})
+}
+
+// findCluster returns the cluster named in the request path
+func (s *kopsServer) findCluster(r *http.Request) (*kopsapi.Cluster, error) {
+	clientset, err := s.f.Clientset()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.GetCluster(mux.Vars(r)["cluster"])
+}
+
+// runOperation runs a command, returning the output it would have printed
+func (s *kopsServer) runOperation(w http.ResponseWriter, fn func(out io.Writer) error) {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		writeServerResult(w, nil, err)
+		return
+	}
+	writeServerJSON(w, http.StatusOK, map[string]string{"output": buf.String()})
+}
+
+// readServerObject parses the request body, in any supported API version, into obj
+func readServerObject(r *http.Request, obj runtime.Object) error {
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxServerRequestBody))
+	if err != nil {
+		return err
+	}
+	o, _, err := kopscodecs.ParseVersionedYaml(data)
+	if err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("error parsing request body: %v", err))
+	}
+
+	switch v := obj.(type) {
+	case *kopsapi.Cluster:
+		if c, ok := o.(*kopsapi.Cluster); ok {
+			*v = *c
+			return nil
+		}
+	case *kopsapi.InstanceGroup:
+		if ig, ok := o.(*kopsapi.InstanceGroup); ok {
+			*v = *ig
+			return nil
+		}
+	}
+	return apierrors.NewBadRequest(fmt.Sprintf("expected %T in request body, got %T", obj, o))
+}
+
+func serverQueryBool(r *http.Request, key string) bool {
+	v := r.URL.Query().Get(key)
+	return v == "true" || v == "1" || (v == "" && r.URL.Query()[key] != nil)
+}
+
+// writeServerResult writes obj as versioned JSON, or err with a matching status code
+func writeServerResult(w http.ResponseWriter, obj runtime.Object, err error) {
+	if err != nil {
+		code := http.StatusInternalServerError
+		if _, ok := err.(*clusterlock.LockedError); ok {
+			code = http.StatusLocked
+		} else if status, ok := err.(apierrors.APIStatus); ok {
+			code = int(status.Status().Code)
+		}
+		writeServerError(w, code, err)
+		return
+	}
+
+	data, err := kopscodecs.ToVersionedJSON(obj)
+	if err != nil {
+		writeServerError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func writeServerError(w http.ResponseWriter, code int, err error) {
+	writeServerJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func writeServerJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		glog.Warningf("error writing response: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/testutils"
+)
+
+func TestServer(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	h.SetupMockAWS()
+
+	factoryOptions := &util.FactoryOptions{}
+	factoryOptions.RegistryPath = "memfs://tests"
+	factory := util.NewFactory(factoryOptions)
+
+	{
+		options := &CreateOptions{}
+		options.Filenames = []string{path.Join(updateClusterTestBase, "minimal", "in-v1alpha2.yaml")}
+		if err := RunCreate(factory, &bytes.Buffer{}, options); err != nil {
+			t.Fatalf("error creating cluster: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(newKopsServer(factory, map[string]string{"secret-token": "alice"}))
+	defer server.Close()
+
+	do := func(method string, p string, token string, body string) (int, string) {
+		req, err := http.NewRequest(method, server.URL+p, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("error building request: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("error sending request: %v", err)
+		}
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+		return resp.StatusCode, string(data)
+	}
+
+	if code, _ := do("GET", "/api/v1/clusters", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected %d without a token, got %d", http.StatusUnauthorized, code)
+	}
+	if code, _ := do("GET", "/api/v1/clusters", "wrong-token", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected %d with an unknown token, got %d", http.StatusUnauthorized, code)
+	}
+
+	if code, body := do("GET", "/api/v1/clusters", "secret-token", ""); code != http.StatusOK || !strings.Contains(body, "minimal.example.com") {
+		t.Errorf("unexpected cluster list %d: %s", code, body)
+	}
+	if code, _ := do("GET", "/api/v1/clusters/missing.example.com", "secret-token", ""); code != http.StatusNotFound {
+		t.Errorf("expected %d for a missing cluster, got %d", http.StatusNotFound, code)
+	}
+	if code, body := do("GET", "/api/v1/clusters/minimal.example.com/instancegroups", "secret-token", ""); code != http.StatusOK || !strings.Contains(body, "nodes") {
+		t.Errorf("unexpected instance group list %d: %s", code, body)
+	}
+
+	code, cluster := do("GET", "/api/v1/clusters/minimal.example.com", "secret-token", "")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status getting cluster %d: %s", code, cluster)
+	}
+	if !strings.Contains(cluster, `"resourceVersion":"`) {
+		t.Fatalf("cluster did not include a resourceVersion: %s", cluster)
+	}
+
+	stale := strings.Replace(cluster, `"resourceVersion":"`, `"resourceVersion":"stale`, 1)
+	if code, body := do("PUT", "/api/v1/clusters/minimal.example.com", "secret-token", stale); code != http.StatusConflict {
+		t.Errorf("expected %d replacing a stale cluster, got %d: %s", http.StatusConflict, code, body)
+	}
+	if code, body := do("PUT", "/api/v1/clusters/minimal.example.com", "secret-token", cluster); code != http.StatusOK {
+		t.Errorf("unexpected status replacing cluster %d: %s", code, body)
+	}
+	if code, body := do("PUT", "/api/v1/clusters/other.example.com", "secret-token", cluster); code != http.StatusNotFound {
+		t.Errorf("expected %d replacing a missing cluster, got %d: %s", http.StatusNotFound, code, body)
+	}
+	if code, body := do("POST", "/api/v1/clusters", "secret-token", "not yaml"); code != http.StatusBadRequest {
+		t.Errorf("expected %d for an invalid body, got %d: %s", http.StatusBadRequest, code, body)
+	}
+}
+
+func TestReadServerTokens(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	p := path.Join(h.TempDir, "tokens.csv")
+	if err := ioutil.WriteFile(p, []byte("# tokens\nabc, alice\n\ndef,bob\n"), 0600); err != nil {
+		t.Fatalf("error writing token file: %v", err)
+	}
+	tokens, err := readServerTokens(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tokens) != 2 || tokens["abc"] != "alice" || tokens["def"] != "bob" {
+		t.Errorf("unexpected tokens: %v", tokens)
+	}
+
+	if err := ioutil.WriteFile(p, []byte("abc\n"), 0600); err != nil {
+		t.Fatalf("error writing token file: %v", err)
+	}
+	if _, err := readServerTokens(p); err == nil {
+		t.Errorf("expected error for a line without a user")
+	}
+}
@@ -28,12 +28,20 @@ import (
 var (
 	setLong = templates.LongDesc(i18n.T(`Set a configuration field.
 
+        Fields are addressed by their path in the YAML representation, e.g. spec.kubernetesVersion.
+
         kops set does not update the cloud resources, to apply the changes use "kops update cluster".
     `))
 
 	setExample = templates.Examples(i18n.T(`
     # Set cluster to run kubernetes version 1.10.0
     kops set cluster k8s-cluster.example.com spec.kubernetesVersion=1.10.0
+
+    # Set the version of every etcd cluster
+    kops set cluster k8s-cluster.example.com spec.etcdClusters[*].version=3.2.18
+
+    # Set instance group to run on 20 nodes at most
+    kops set instancegroup --name k8s-cluster.example.com nodes spec.maxSize=20
 	`))
 )
 
@@ -47,6 +55,7 @@ func NewCmdSet(f *util.Factory, out io.Writer) *cobra.Command {
 
 	// create subcommands
 	cmd.AddCommand(NewCmdSetCluster(f, out))
+	cmd.AddCommand(NewCmdSetInstancegroup(f, out))
 
 	return cmd
 }
@@ -0,0 +1,232 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	sshLong = templates.LongDesc(i18n.T(`
+	SSH to an instance of the cluster.
+
+	The target is an instance id, a node name or the name of an instance group, in which case
+	the first running instance of the group is used.  kops connects in the way the cluster is
+	reachable: through AWS Session Manager when spec.topology.sessionManager is set (this needs
+	the aws cli and its session-manager-plugin), through the bastion when the cluster has one,
+	and otherwise directly to the instance.
+
+	Any arguments after -- are run on the instance as a command.`))
+
+	sshExample = templates.Examples(i18n.T(`
+	# SSH to the first instance of the nodes instance group
+	kops ssh nodes --name k8s-cluster.example.com
+
+	# Run a command on a master
+	kops ssh master-us-east-1a --name k8s-cluster.example.com -- sudo journalctl -u kubelet
+
+	# SSH to an instance as the ubuntu user
+	kops ssh i-0123456789abcdef0 --user ubuntu --name k8s-cluster.example.com
+	`))
+
+	sshShort = i18n.T(`SSH to an instance of the cluster.`)
+)
+
+type SSHOptions struct {
+	ClusterName string
+
+	// Target is the instance id, node name or instance group name to connect to
+	Target string
+	// User is the user to log in as
+	User string
+	// Command is run on the instance, rather than an interactive shell
+	Command []string
+}
+
+func (o *SSHOptions) InitDefaults() {
+	o.User = "admin"
+}
+
+func NewCmdSSH(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &SSHOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "ssh TARGET [-- COMMAND...]",
+		Short:   sshShort,
+		Long:    sshLong,
+		Example: sshExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				exitWithError(fmt.Errorf("syntax: TARGET [-- COMMAND...]"))
+			}
+			options.Target = args[0]
+			options.Command = args[1:]
+
+			err := rootCommand.ProcessArgs(nil)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err = RunSSH(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.User, "user", options.User, "User to log in as, which depends on the image (e.g. admin on Debian, ubuntu on Ubuntu, ec2-user on Amazon Linux)")
+
+	return cmd
+}
+
+func RunSSH(f *util.Factory, out io.Writer, options *SSHOptions) error {
+	if options.Target == "" {
+		return fmt.Errorf("target is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+	awsCloud, ok := cloud.(awsup.AWSCloud)
+	if !ok {
+		return fmt.Errorf("kops ssh is not supported on %s", cloud.ProviderID())
+	}
+
+	instance, err := findSSHInstance(awsCloud, cluster.ObjectMeta.Name, options.Target)
+	if err != nil {
+		return err
+	}
+
+	argv := buildSSHArgs(cluster, awsCloud.Region(), options.User, instance, options.Command)
+
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = out
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("error running %s: %v", strings.Join(argv, " "), err)
+	}
+	return nil
+}
+
+// findSSHInstance returns the first running instance of the cluster matching the target
+func findSSHInstance(cloud awsup.AWSCloud, clusterName string, target string) (*ec2.Instance, error) {
+	request := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			awsup.NewEC2Filter("tag:"+awsup.TagClusterName, clusterName),
+			awsup.NewEC2Filter("instance-state-name", "running"),
+		},
+	}
+
+	var found *ec2.Instance
+	err := cloud.EC2().DescribeInstancesPages(request, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if matchesSSHTarget(instance, clusterName, target) {
+					found = instance
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing instances: %v", err)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no running instance found matching %q", target)
+	}
+	return found, nil
+}
+
+// matchesSSHTarget returns true if the target is the id or node name of the instance, or the name of its instance group
+func matchesSSHTarget(instance *ec2.Instance, clusterName string, target string) bool {
+	if aws.StringValue(instance.InstanceId) == target || aws.StringValue(instance.PrivateDnsName) == target {
+		return true
+	}
+	for _, tag := range instance.Tags {
+		switch aws.StringValue(tag.Key) {
+		case "aws:autoscaling:groupName", "Name":
+			if aws.StringValue(tag.Value) == target+"."+clusterName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildSSHArgs returns the ssh command line for the instance, going through session manager or the bastion as the cluster requires
+func buildSSHArgs(cluster *kops.Cluster, region string, user string, instance *ec2.Instance, command []string) []string {
+	argv := []string{"ssh"}
+
+	topology := cluster.Spec.Topology
+	var host string
+	switch {
+	case topology != nil && topology.SessionManager != nil:
+		// ssh connects to the instance id, which session manager resolves
+		proxy := "aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p"
+		if region != "" {
+			proxy += " --region " + region
+		}
+		argv = append(argv, "-o", "ProxyCommand="+proxy)
+		host = aws.StringValue(instance.InstanceId)
+
+	case topology != nil && topology.Bastion != nil:
+		bastionName := topology.Bastion.BastionPublicName
+		if bastionName == "" {
+			bastionName = "bastion." + cluster.ObjectMeta.Name
+		}
+		argv = append(argv, "-J", user+"@"+bastionName)
+		host = aws.StringValue(instance.PrivateIpAddress)
+
+	default:
+		host = aws.StringValue(instance.PublicIpAddress)
+		if host == "" {
+			host = aws.StringValue(instance.PrivateIpAddress)
+		}
+	}
+
+	argv = append(argv, user+"@"+host)
+	if len(command) != 0 {
+		argv = append(argv, "--")
+		argv = append(argv, command...)
+	}
+	return argv
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestBuildSSHArgs(t *testing.T) {
+	instance := &ec2.Instance{
+		InstanceId:       aws.String("i-0123456789abcdef0"),
+		PrivateIpAddress: aws.String("172.20.32.10"),
+		PublicIpAddress:  aws.String("54.0.0.10"),
+	}
+
+	grid := []struct {
+		Topology *kops.TopologySpec
+		Command  []string
+		Expected []string
+	}{
+		{
+			Expected: []string{"ssh", "admin@54.0.0.10"},
+		},
+		{
+			Topology: &kops.TopologySpec{Bastion: &kops.BastionSpec{}},
+			Command:  []string{"uptime"},
+			Expected: []string{"ssh", "-J", "admin@bastion.cluster.example.com", "admin@172.20.32.10", "--", "uptime"},
+		},
+		{
+			Topology: &kops.TopologySpec{Bastion: &kops.BastionSpec{BastionPublicName: "jump.example.com"}},
+			Expected: []string{"ssh", "-J", "admin@jump.example.com", "admin@172.20.32.10"},
+		},
+		{
+			Topology: &kops.TopologySpec{SessionManager: &kops.SessionManagerSpec{}},
+			Expected: []string{
+				"ssh",
+				"-o", "ProxyCommand=aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p --region us-east-1",
+				"admin@i-0123456789abcdef0",
+			},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster.example.com"},
+			Spec:       kops.ClusterSpec{Topology: g.Topology},
+		}
+		actual := buildSSHArgs(cluster, "us-east-1", "admin", instance, g.Command)
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("unexpected ssh args %q, expected %q", actual, g.Expected)
+		}
+	}
+}
+
+func TestMatchesSSHTarget(t *testing.T) {
+	instance := &ec2.Instance{
+		InstanceId:     aws.String("i-0123456789abcdef0"),
+		PrivateDnsName: aws.String("ip-172-20-32-10.ec2.internal"),
+		Tags: []*ec2.Tag{
+			{Key: aws.String("aws:autoscaling:groupName"), Value: aws.String("nodes.cluster.example.com")},
+		},
+	}
+
+	for target, expected := range map[string]bool{
+		"i-0123456789abcdef0":          true,
+		"ip-172-20-32-10.ec2.internal": true,
+		"nodes":                        true,
+		"masters":                      false,
+		"nodes.cluster.example.com":    false,
+	} {
+		if actual := matchesSSHTarget(instance, "cluster.example.com", target); actual != expected {
+			t.Errorf("matchesSSHTarget(%q) = %v, expected %v", target, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	startLong = templates.LongDesc(i18n.T(`
+	Start a cluster that was stopped.`))
+
+	startExample = templates.Examples(i18n.T(`
+	# Start the k8s-cluster.example.com cluster again
+	kops start cluster k8s-cluster.example.com --yes
+	`))
+
+	startShort = i18n.T(`Start a cluster that was stopped.`)
+
+	startClusterLong = templates.LongDesc(i18n.T(`
+		Start a cluster stopped by "kops stop cluster".
+
+		The instance groups stopped with the cluster are resumed, masters first, restoring the
+		sizes they had when the cluster was stopped. The masters attach their existing etcd
+		volumes when they start.`))
+
+	startClusterExample = templates.Examples(i18n.T(`
+		# Start the k8s-cluster.example.com cluster again
+		kops start cluster k8s-cluster.example.com --yes
+		`))
+
+	startClusterShort = i18n.T(`Start a cluster.`)
+)
+
+func NewCmdStart(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "start",
+		Short:   startShort,
+		Long:    startLong,
+		Example: startExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdStartCluster(f, out))
+
+	return cmd
+}
+
+type StartClusterOptions struct {
+	Yes         bool
+	ClusterName string
+}
+
+func NewCmdStartCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &StartClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   startClusterShort,
+		Long:    startClusterLong,
+		Example: startClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunStartCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately start the cluster")
+
+	return cmd
+}
+
+// RunStartCluster restores the instance groups of a cluster stopped by RunStopCluster
+func RunStartCluster(f *util.Factory, out io.Writer, options *StartClusterOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	allGroups, err := commands.ReadAllInstanceGroups(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	oldCluster := cluster.DeepCopy()
+	oldGroups := make(map[string]*api.InstanceGroup)
+	for _, ig := range allGroups {
+		oldGroups[ig.ObjectMeta.Name] = ig.DeepCopy()
+	}
+
+	changed, err := instancegroups.StartCluster(cluster, allGroups)
+	if err != nil {
+		return err
+	}
+
+	var fullGroups []*api.InstanceGroup
+	for _, ig := range changed {
+		fullGroup, err := populateInstanceGroup(clientset, cluster.DeepCopy(), ig)
+		if err != nil {
+			return err
+		}
+		fullGroups = append(fullGroups, fullGroup)
+		fmt.Fprintf(out, "InstanceGroup %q will be scaled to min=%d max=%d\n", ig.ObjectMeta.Name, fi.Int32Value(fullGroup.Spec.MinSize), fi.Int32Value(fullGroup.Spec.MaxSize))
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to start the cluster\n")
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	for _, ig := range fullGroups {
+		if err := updateAndResizeInstanceGroup(f, cluster, audit.OperationStart, oldGroups[ig.ObjectMeta.Name], ig, nil); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Started InstanceGroup: %q\n", ig.ObjectMeta.Name)
+	}
+
+	// The cluster is only marked started once its groups have been scaled up, so that a failed start can be retried
+	instancegroups.MarkClusterStarted(cluster)
+	if err := updateClusterAnnotations(f, audit.OperationStart, oldCluster, cluster); err != nil {
+		return err
+	}
+
+	return nil
+}
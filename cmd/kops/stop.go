@@ -0,0 +1,177 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	stopLong = templates.LongDesc(i18n.T(`
+	Stop a cluster, scaling all of its instance groups to zero.`))
+
+	stopExample = templates.Examples(i18n.T(`
+	# Stop the k8s-cluster.example.com cluster overnight
+	kops stop cluster k8s-cluster.example.com --yes
+	`))
+
+	stopShort = i18n.T(`Stop a cluster, scaling all of its instance groups to zero.`)
+
+	stopClusterLong = templates.LongDesc(i18n.T(`
+		Stop a cluster, scaling every instance group, including the masters, to zero instances.
+
+		The instance groups are paused as by "kops pause instancegroup", and the groups that
+		were stopped are recorded on the cluster so that "kops start cluster" resumes only those.
+		Instance groups that were already paused stay paused. Volumes, such as the etcd volumes
+		of the masters, are not deleted; they are attached again when the cluster is started.`))
+
+	stopClusterExample = templates.Examples(i18n.T(`
+		# Stop the k8s-cluster.example.com cluster overnight
+		kops stop cluster k8s-cluster.example.com --yes
+		`))
+
+	stopClusterShort = i18n.T(`Stop a cluster.`)
+)
+
+func NewCmdStop(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "stop",
+		Short:   stopShort,
+		Long:    stopLong,
+		Example: stopExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdStopCluster(f, out))
+
+	return cmd
+}
+
+type StopClusterOptions struct {
+	Yes         bool
+	ClusterName string
+}
+
+func NewCmdStopCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &StopClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   stopClusterShort,
+		Long:    stopClusterLong,
+		Example: stopClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunStopCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Specify --yes to immediately stop the cluster")
+
+	return cmd
+}
+
+// RunStopCluster scales every instance group of a cluster to zero, recording which groups were stopped
+func RunStopCluster(f *util.Factory, out io.Writer, options *StopClusterOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	allGroups, err := commands.ReadAllInstanceGroups(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	oldCluster := cluster.DeepCopy()
+	oldGroups := make(map[string]*api.InstanceGroup)
+	for _, ig := range allGroups {
+		oldGroups[ig.ObjectMeta.Name] = ig.DeepCopy()
+	}
+
+	changed, err := instancegroups.StopCluster(cluster, allGroups)
+	if err != nil {
+		return err
+	}
+
+	var fullGroups []*api.InstanceGroup
+	for _, ig := range changed {
+		fullGroup, err := populateInstanceGroup(clientset, cluster.DeepCopy(), ig)
+		if err != nil {
+			return err
+		}
+		fullGroups = append(fullGroups, fullGroup)
+		fmt.Fprintf(out, "InstanceGroup %q will be scaled to zero instances\n", ig.ObjectMeta.Name)
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to stop the cluster\n")
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	// The cluster is only marked stopped once its groups have been scaled down.  If a group fails to scale down, the
+	// groups already stopped are recorded, so that "kops start cluster" resumes them.
+	var stopped []*api.InstanceGroup
+	for _, ig := range fullGroups {
+		if err := updateAndResizeInstanceGroup(f, cluster, audit.OperationStop, oldGroups[ig.ObjectMeta.Name], ig, fi.Int(0)); err != nil {
+			if len(stopped) != 0 {
+				instancegroups.MarkClusterStopped(cluster, stopped)
+				if recordErr := updateClusterAnnotations(f, audit.OperationStop, oldCluster, cluster); recordErr != nil {
+					glog.Warningf("unable to record the instance groups already stopped: %v", recordErr)
+				}
+			}
+			return fmt.Errorf("error stopping InstanceGroup %q: %v", ig.ObjectMeta.Name, err)
+		}
+		stopped = append(stopped, ig)
+		fmt.Fprintf(out, "Stopped InstanceGroup: %q\n", ig.ObjectMeta.Name)
+	}
+
+	instancegroups.MarkClusterStopped(cluster, stopped)
+	return updateClusterAnnotations(f, audit.OperationStop, oldCluster, cluster)
+}
@@ -46,9 +46,19 @@ func NewCmdToolbox(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdToolboxConvertImported(f, out))
+	cmd.AddCommand(NewCmdToolboxCost(f, out))
 	cmd.AddCommand(NewCmdToolboxDump(f, out))
+	cmd.AddCommand(NewCmdToolboxBuildImage(f, out))
 	cmd.AddCommand(NewCmdToolboxBundle(f, out))
+	cmd.AddCommand(NewCmdToolboxEnroll(f, out))
+	cmd.AddCommand(NewCmdToolboxGossipStatus(f, out))
+	cmd.AddCommand(NewCmdToolboxMigrateRegion(f, out))
+	cmd.AddCommand(NewCmdToolboxNodeBootReport(f, out))
+	cmd.AddCommand(NewCmdToolboxPatchNodes(f, out))
+	cmd.AddCommand(NewCmdToolboxPlanSubnets(f, out))
+	cmd.AddCommand(NewCmdToolboxRecommend(f, out))
 	cmd.AddCommand(NewCmdToolboxTemplate(f, out))
+	cmd.AddCommand(NewCmdToolboxTerraformImport(f, out))
 
 	return cmd
 }
@@ -0,0 +1,293 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/imagebuilder"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxBuildImageLong = templates.LongDesc(i18n.T(`
+	Builds a custom node image from a declarative spec: the base image, extra OS packages, container
+	images to preload, and commands to run.
+
+	An instance of the base image is launched in the network of the cluster, and the build runs as it boots.
+	Once the build completes the instance powers off, an image of it is registered, and the instance is
+	removed.  AWS and GCE are supported.
+
+	The image of the instance groups given with --instance-group is set to the new image; run
+	kops update cluster and kops rolling-update cluster to replace their instances.
+
+	Without --yes, the build script is shown.`))
+
+	toolboxBuildImageExample = templates.Examples(i18n.T(`
+	# Show the build script for an image
+	kops toolbox build-image -f image.yaml --name k8s-cluster.example.com
+
+	# Build the image, and use it for the nodes instance group
+	kops toolbox build-image -f image.yaml --name k8s-cluster.example.com --instance-group nodes --yes
+	`))
+
+	toolboxBuildImageShort = i18n.T(`Build a custom node image.`)
+)
+
+type ToolboxBuildImageOptions struct {
+	ClusterName string
+
+	// Filename is the path to the image spec
+	Filename string
+
+	// Yes builds the image; otherwise the build script is shown
+	Yes bool
+
+	// InstanceGroups are the instance groups that are updated to use the built image
+	InstanceGroups []string
+
+	// Subnet is the ID of the AWS subnet the build instance is launched in, overriding the subnets of the cluster
+	Subnet string
+
+	// Zone is the GCE zone the build instance is created in, overriding the zones of the cluster
+	Zone string
+
+	// Timeout is how long to wait for the build to complete
+	Timeout time.Duration
+}
+
+func (o *ToolboxBuildImageOptions) InitDefaults() {
+	o.Yes = false
+	o.Timeout = 45 * time.Minute
+}
+
+func NewCmdToolboxBuildImage(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxBuildImageOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "build-image",
+		Short:   toolboxBuildImageShort,
+		Long:    toolboxBuildImageLong,
+		Example: toolboxBuildImageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+			if options.ClusterName == "" {
+				exitWithError(fmt.Errorf("--name is required"))
+			}
+
+			err = RunToolboxBuildImage(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Filename, "filename", "f", options.Filename, "Path to the image spec")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Build the image, without --yes the build script is shown")
+	cmd.Flags().StringSliceVar(&options.InstanceGroups, "instance-group", options.InstanceGroups, "Instance groups to update to use the built image")
+	cmd.MarkFlagCustom("instance-group", "__kops_get_instancegroups")
+	cmd.Flags().StringVar(&options.Subnet, "subnet", options.Subnet, "ID of the subnet to launch the build instance in (AWS only, defaults to a subnet of the cluster)")
+	cmd.Flags().StringVar(&options.Zone, "zone", options.Zone, "Zone to create the build instance in (GCE only, defaults to a zone of the cluster)")
+	cmd.Flags().DurationVar(&options.Timeout, "build-timeout", options.Timeout, "Time to wait for the build to complete")
+
+	return cmd
+}
+
+func RunToolboxBuildImage(f *util.Factory, out io.Writer, options *ToolboxBuildImageOptions) error {
+	if options.Filename == "" {
+		return fmt.Errorf("the image spec is required (use -f)")
+	}
+
+	data, err := ioutil.ReadFile(options.Filename)
+	if err != nil {
+		return fmt.Errorf("error reading image spec %q: %v", options.Filename, err)
+	}
+	spec, err := imagebuilder.ParseImageSpec(data)
+	if err != nil {
+		return fmt.Errorf("error in image spec %q: %v", options.Filename, err)
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	var instanceGroups []*api.InstanceGroup
+	for _, name := range options.InstanceGroups {
+		ig, err := clientset.InstanceGroupsFor(cluster).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error reading InstanceGroup %q: %v", name, err)
+		}
+		if ig == nil {
+			return fmt.Errorf("InstanceGroup %q not found", name)
+		}
+		instanceGroups = append(instanceGroups, ig)
+	}
+
+	script := imagebuilder.BuildScript(spec)
+	imageName := spec.ImageName(time.Now())
+
+	if !options.Yes {
+		fmt.Fprintf(out, "Will build image %q from %q with the build script:\n\n%s\n", imageName, spec.BaseImage, script)
+		for _, ig := range instanceGroups {
+			fmt.Fprintf(out, "Will update InstanceGroup %q to use the image\n", ig.ObjectMeta.Name)
+		}
+		fmt.Fprintf(out, "\nMust specify --yes to build the image.\n")
+		return nil
+	}
+
+	if len(instanceGroups) != 0 {
+		if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+			return err
+		}
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+
+	var builder imagebuilder.Builder
+	switch c := cloud.(type) {
+	case awsup.AWSCloud:
+		subnetID, publicIP := options.Subnet, true
+		if subnetID == "" {
+			subnetID, publicIP, err = findImageBuildSubnet(c, cluster)
+			if err != nil {
+				return err
+			}
+		}
+		builder = &imagebuilder.AWSBuilder{
+			Cloud:        c,
+			SubnetID:     subnetID,
+			PublicIP:     publicIP,
+			Timeout:      options.Timeout,
+			PollInterval: 15 * time.Second,
+		}
+
+	case gce.GCECloud:
+		zone := options.Zone
+		if zone == "" {
+			zones, err := c.Zones()
+			if err != nil {
+				return err
+			}
+			if len(zones) == 0 {
+				return fmt.Errorf("cannot determine the zone to build the image in; specify --zone")
+			}
+			zone = zones[0]
+		}
+		network := cluster.Spec.NetworkID
+		if network == "" {
+			network = "default"
+		}
+		builder = &imagebuilder.GCEBuilder{
+			Cloud:        c,
+			Zone:         zone,
+			Network:      network,
+			Timeout:      options.Timeout,
+			PollInterval: 15 * time.Second,
+		}
+
+	default:
+		return fmt.Errorf("building images is not supported on cloud provider %q", cluster.Spec.CloudProvider)
+	}
+
+	fmt.Fprintf(out, "Building image %q from %q, this may take a while\n", imageName, spec.BaseImage)
+	image, err := builder.BuildImage(spec, imageName, script)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Built image %q\n", image)
+
+	for _, ig := range instanceGroups {
+		oldGroup := ig.DeepCopy()
+		ig.Spec.Image = image
+
+		if _, err := clientset.InstanceGroupsFor(cluster).Update(ig); err != nil {
+			return fmt.Errorf("error updating InstanceGroup %q: %v", ig.ObjectMeta.Name, err)
+		}
+
+		commands.RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationEdit, "instancegroup/"+ig.ObjectMeta.Name, oldGroup, ig)
+		fmt.Fprintf(out, "Updated InstanceGroup %q to use the image\n", ig.ObjectMeta.Name)
+	}
+
+	if len(instanceGroups) != 0 {
+		fmt.Fprintf(out, "\nRun kops update cluster and kops rolling-update cluster to replace the instances of the updated instance groups.\n")
+	}
+
+	return nil
+}
+
+// findImageBuildSubnet returns a subnet of the cluster to launch the build instance in, preferring the subnets that
+// route directly to the internet, and whether the instance needs a public IP in it
+func findImageBuildSubnet(cloud awsup.AWSCloud, cluster *api.Cluster) (string, bool, error) {
+	for _, subnetType := range []api.SubnetType{api.SubnetTypeUtility, api.SubnetTypePublic, api.SubnetTypePrivate} {
+		for _, subnet := range cluster.Spec.Subnets {
+			if subnet.Type != subnetType {
+				continue
+			}
+			public := subnetType != api.SubnetTypePrivate
+
+			if subnet.ProviderID != "" {
+				return subnet.ProviderID, public, nil
+			}
+
+			request := &ec2.DescribeSubnetsInput{
+				Filters: []*ec2.Filter{
+					awsup.NewEC2Filter("tag:"+awsup.TagClusterName, cluster.ObjectMeta.Name),
+					awsup.NewEC2Filter("tag:Name", subnet.Name+"."+cluster.ObjectMeta.Name),
+				},
+			}
+			response, err := cloud.EC2().DescribeSubnets(request)
+			if err != nil {
+				return "", false, fmt.Errorf("error listing subnets: %v", err)
+			}
+			if len(response.Subnets) != 0 {
+				return aws.StringValue(response.Subnets[0].SubnetId), public, nil
+			}
+		}
+	}
+
+	return "", false, fmt.Errorf("cannot find a subnet of cluster %q to build the image in; specify --subnet", cluster.ObjectMeta.Name)
+}
@@ -18,14 +18,12 @@ package main
 
 import (
 	"archive/tar"
-	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,7 +32,6 @@ import (
 	"k8s.io/kops/pkg/bundle"
 	"k8s.io/kops/pkg/try"
 	"k8s.io/kops/upup/pkg/kutil"
-	"k8s.io/kops/util/pkg/vfs"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
 )
@@ -132,57 +129,7 @@ func RunToolboxBundle(context Factory, out io.Writer, options *ToolboxBundleOpti
 		return err
 	}
 
-	sshClient, err := nodeSSH.GetSSHClient()
-	if err != nil {
-		return fmt.Errorf("error getting SSH client: %v", err)
-	}
-
-	if err := runSshCommand(sshClient, "sudo mkdir -p /etc/kubernetes/bootstrap"); err != nil {
-		return err
-	}
-
-	root, err := nodeSSH.Root()
-	if err != nil {
-		return fmt.Errorf("error connecting to nodeSSH: %v", err)
-	}
-	for _, file := range bundleData.Files {
-		sshAcl := &vfs.SSHAcl{
-			Mode: file.Header.FileInfo().Mode(),
-		}
-		p := root.Join("etc", "kubernetes", "bootstrap", file.Header.Name)
-		glog.Infof("writing %s", p)
-		if err := p.WriteFile(bytes.NewReader(file.Data), sshAcl); err != nil {
-			return fmt.Errorf("error writing file %q: %v", file.Header.Name, err)
-		}
-	}
-
-	if err := runSshCommand(sshClient, "sudo /etc/kubernetes/bootstrap/bootstrap.sh"); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func runSshCommand(sshClient *ssh.Client, cmd string) error {
-	s, err := sshClient.NewSession()
-	if err != nil {
-		return fmt.Errorf("error creating ssh session: %v", err)
-	}
-
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-
-	s.Stdout = io.MultiWriter(&stdout, os.Stdout)
-	s.Stderr = io.MultiWriter(&stderr, os.Stderr)
-
-	glog.Infof("running %s", cmd)
-	if err := s.Run(cmd); err != nil {
-		return fmt.Errorf("error running %s: %v\nstdout: %s\nstderr: %s", cmd, err, stdout.String(), stderr.String())
-	}
-
-	glog.Infof("stdout: %s", stdout.String())
-	glog.Infof("stderr: %s", stderr.String())
-	return nil
+	return bundle.Install(nodeSSH, bundleData)
 }
 
 func writeToTar(files []*bundle.DataFile, bundlePath string) error {
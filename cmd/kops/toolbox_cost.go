@@ -0,0 +1,439 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cost"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxCostLong = templates.LongDesc(i18n.T(`
+	Estimates the monthly cost of a cluster from its spec.
+
+	The estimate covers the instances of each instance group, at their minimum and maximum
+	size, their root and additional volumes, the etcd volumes, NAT gateways and load balancers.
+	Data transfer and data processing charges are not included.
+
+	Prices come from a snapshot of on-demand prices built into kops, a price list file given
+	with --prices, or, on AWS, the AWS price list and spot price APIs with --online.
+
+	To see what an edit would cost before making it, pass the edited cluster or instance
+	group manifests with -f; the estimate of the cluster with the manifests applied is shown
+	next to the current one.`))
+
+	toolboxCostExample = templates.Examples(i18n.T(`
+	# Estimate the monthly cost of a cluster
+	kops toolbox cost --name k8s-cluster.example.com
+
+	# Use the current AWS prices of the cluster's region
+	kops toolbox cost --name k8s-cluster.example.com --online
+
+	# Show how the cost would change with an edited instance group
+	kops get ig nodes --name k8s-cluster.example.com -o yaml > nodes.yaml
+	# (edit nodes.yaml)
+	kops toolbox cost --name k8s-cluster.example.com -f nodes.yaml
+	`))
+
+	toolboxCostShort = i18n.T(`Estimate the monthly cost of a cluster`)
+)
+
+type ToolboxCostOptions struct {
+	ClusterName string
+
+	// Filenames are manifests of the cluster or instance groups, whose cost is compared to the current cost
+	Filenames []string
+	// Prices is a price list file to use instead of the built-in snapshot
+	Prices string
+	// Online fetches the current prices from the cloud's pricing APIs
+	Online bool
+
+	Output string
+}
+
+func (o *ToolboxCostOptions) InitDefaults() {
+	o.Output = OutputTable
+}
+
+func NewCmdToolboxCost(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxCostOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "cost",
+		Short:   toolboxCostShort,
+		Long:    toolboxCostLong,
+		Example: toolboxCostExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunToolboxCost(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&options.Filenames, "filename", "f", options.Filenames, "Cluster or instance group manifests to estimate the cost of, compared to the current cost")
+	cmd.Flags().StringVar(&options.Prices, "prices", options.Prices, "Price list file to use instead of the built-in price snapshot")
+	cmd.Flags().BoolVar(&options.Online, "online", options.Online, "Fetch the current prices from the cloud's pricing APIs (AWS only)")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "output format.  One of: table, json")
+
+	return cmd
+}
+
+// toolboxCostResult is the json output of kops toolbox cost
+type toolboxCostResult struct {
+	Current  *cost.Estimate    `json:"current"`
+	Proposed *cost.Estimate    `json:"proposed,omitempty"`
+	Delta    []*cost.ItemDelta `json:"delta,omitempty"`
+}
+
+func RunToolboxCost(f *util.Factory, out io.Writer, options *ToolboxCostOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	result := &toolboxCostResult{}
+
+	proposedCluster := cluster
+	proposedInstanceGroups := instanceGroups
+	if len(options.Filenames) != 0 {
+		proposedCluster, proposedInstanceGroups, err = applyCostManifests(cluster, instanceGroups, options.Filenames)
+		if err != nil {
+			return err
+		}
+	}
+
+	region, err := findClusterRegion(cluster)
+	if err != nil {
+		return err
+	}
+
+	prices, err := buildCostPrices(cluster, region, options, append(instanceGroups, proposedInstanceGroups...))
+	if err != nil {
+		return err
+	}
+
+	result.Current, err = cost.EstimateCluster(cluster, instanceGroups, region, prices)
+	if err != nil {
+		return err
+	}
+	if len(options.Filenames) != 0 {
+		result.Proposed, err = cost.EstimateCluster(proposedCluster, proposedInstanceGroups, region, prices)
+		if err != nil {
+			return err
+		}
+		result.Delta = cost.Delta(result.Current, result.Proposed)
+	}
+
+	return toolboxCostOutput(out, options, result)
+}
+
+// findClusterRegion returns the region of the cluster, from its subnets
+func findClusterRegion(cluster *api.Cluster) (string, error) {
+	switch api.CloudProviderID(cluster.Spec.CloudProvider) {
+	case api.CloudProviderAWS:
+		return awsup.FindRegion(cluster)
+	case api.CloudProviderGCE:
+		for _, subnet := range cluster.Spec.Subnets {
+			if subnet.Region != "" {
+				return subnet.Region, nil
+			}
+			if subnet.Zone != "" {
+				return gce.ZoneToRegion(subnet.Zone)
+			}
+		}
+		return "", fmt.Errorf("unable to determine the region of cluster %q", cluster.ObjectMeta.Name)
+	default:
+		return "", fmt.Errorf("cost estimation is not supported on cloud provider %q", cluster.Spec.CloudProvider)
+	}
+}
+
+// buildCostPrices returns the price list to estimate the cost with, as chosen by the options
+func buildCostPrices(cluster *api.Cluster, region string, options *ToolboxCostOptions, instanceGroups []*api.InstanceGroup) (*cost.Prices, error) {
+	var prices *cost.Prices
+	if options.Prices != "" {
+		data, err := vfs.Context.ReadFile(options.Prices)
+		if err != nil {
+			return nil, fmt.Errorf("error reading price list %q: %v", options.Prices, err)
+		}
+		prices, err = cost.ParsePrices(data)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		prices = cost.SnapshotPrices(cluster.Spec.CloudProvider)
+		if prices == nil {
+			return nil, fmt.Errorf("no price snapshot for cloud provider %q; specify a price list with --prices", cluster.Spec.CloudProvider)
+		}
+	}
+
+	if !options.Online {
+		return prices, nil
+	}
+	if api.CloudProviderID(cluster.Spec.CloudProvider) != api.CloudProviderAWS {
+		return nil, fmt.Errorf("--online is only supported on AWS; specify a price list with --prices")
+	}
+
+	var machineTypes []string
+	seen := make(map[string]bool)
+	for _, ig := range instanceGroups {
+		if ig.Spec.MachineType != "" && !seen[ig.Spec.MachineType] {
+			seen[ig.Spec.MachineType] = true
+			machineTypes = append(machineTypes, ig.Spec.MachineType)
+		}
+	}
+	sort.Strings(machineTypes)
+
+	prices, err := cost.FetchAWSPrices(region, machineTypes, prices)
+	if err != nil {
+		return nil, err
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return nil, err
+	}
+	prices.SpotInstances, err = cost.FetchAWSSpotPrices(cloud.(awsup.AWSCloud).EC2(), machineTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// applyCostManifests returns the cluster and instance groups with the objects in the manifest files replacing them
+func applyCostManifests(cluster *api.Cluster, instanceGroups []*api.InstanceGroup, filenames []string) (*api.Cluster, []*api.InstanceGroup, error) {
+	codec := kopscodecs.Codecs.UniversalDecoder(api.SchemeGroupVersion)
+
+	byName := make(map[string]*api.InstanceGroup)
+	for _, ig := range instanceGroups {
+		byName[ig.ObjectMeta.Name] = ig
+	}
+
+	for _, filename := range filenames {
+		var contents []byte
+		var err error
+		if filename == "-" {
+			contents, err = ConsumeStdin()
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			contents, err = vfs.Context.ReadFile(filename)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error reading file %q: %v", filename, err)
+			}
+		}
+
+		for _, section := range bytes.Split(contents, []byte("\n---\n")) {
+			if len(bytes.TrimSpace(section)) == 0 {
+				continue
+			}
+			o, gvk, err := codec.Decode(section, nil, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error parsing file %q: %v", filename, err)
+			}
+
+			switch v := o.(type) {
+			case *api.Cluster:
+				if v.ObjectMeta.Name != cluster.ObjectMeta.Name {
+					return nil, nil, fmt.Errorf("file %q is for cluster %q, not %q", filename, v.ObjectMeta.Name, cluster.ObjectMeta.Name)
+				}
+				cluster = v
+			case *api.InstanceGroup:
+				if clusterName := v.ObjectMeta.Labels[api.LabelClusterName]; clusterName != "" && clusterName != cluster.ObjectMeta.Name {
+					return nil, nil, fmt.Errorf("instance group %q in file %q is for cluster %q, not %q", v.ObjectMeta.Name, filename, clusterName, cluster.ObjectMeta.Name)
+				}
+				byName[v.ObjectMeta.Name] = v
+			default:
+				return nil, nil, fmt.Errorf("unhandled kind %q in file %q", gvk, filename)
+			}
+		}
+	}
+
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []*api.InstanceGroup
+	for _, name := range names {
+		result = append(result, byName[name])
+	}
+	return cluster, result, nil
+}
+
+func toolboxCostOutput(out io.Writer, options *ToolboxCostOptions, result *toolboxCostResult) error {
+	switch options.Output {
+	case OutputTable:
+		current := result.Current
+		if result.Proposed == nil {
+			t := &tables.Table{}
+			t.AddColumn("ITEM", func(i *cost.LineItem) string {
+				return i.Name
+			})
+			t.AddColumn("TYPE", func(i *cost.LineItem) string {
+				return i.Type
+			})
+			t.AddColumn("COUNT", func(i *cost.LineItem) string {
+				if i.Kind == cost.KindVolumes {
+					return formatCostRange(float64(i.MinCount), float64(i.MaxCount), "%.0f") + " GB"
+				}
+				return formatCostRange(float64(i.MinCount), float64(i.MaxCount), "%.0f")
+			})
+			t.AddColumn("UNIT PRICE", func(i *cost.LineItem) string {
+				if i.Kind == cost.KindVolumes {
+					return fmt.Sprintf("%.4g/GB-month", i.UnitPrice)
+				}
+				return fmt.Sprintf("%.4g/hour", i.UnitPrice)
+			})
+			t.AddColumn("MONTHLY", func(i *cost.LineItem) string {
+				return formatCostRange(i.MinMonthly, i.MaxMonthly, "%.2f")
+			})
+			t.AddColumn("NOTE", func(i *cost.LineItem) string {
+				return i.Note
+			})
+			if err := t.Render(current.Items, out, "ITEM", "TYPE", "COUNT", "UNIT PRICE", "MONTHLY", "NOTE"); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "\nEstimated monthly cost: %s %s\n", formatCostRange(current.MinMonthly, current.MaxMonthly, "%.2f"), current.Currency)
+		} else {
+			proposed := result.Proposed
+			t := &tables.Table{}
+			t.AddColumn("ITEM", func(d *cost.ItemDelta) string {
+				return d.Name
+			})
+			t.AddColumn("CURRENT", func(d *cost.ItemDelta) string {
+				if d.Old == nil {
+					return "-"
+				}
+				return formatCostItem(d.Old)
+			})
+			t.AddColumn("PROPOSED", func(d *cost.ItemDelta) string {
+				if d.New == nil {
+					return "-"
+				}
+				return formatCostItem(d.New)
+			})
+			t.AddColumn("CHANGE", func(d *cost.ItemDelta) string {
+				return formatCostRange(d.MinMonthly, d.MaxMonthly, "%+.2f")
+			})
+			if len(result.Delta) == 0 {
+				fmt.Fprintf(out, "No change in the estimated cost.\n")
+			} else if err := t.Render(result.Delta, out, "ITEM", "CURRENT", "PROPOSED", "CHANGE"); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "\nEstimated monthly cost: %s %s, currently %s %s (%s)\n",
+				formatCostRange(proposed.MinMonthly, proposed.MaxMonthly, "%.2f"), proposed.Currency,
+				formatCostRange(current.MinMonthly, current.MaxMonthly, "%.2f"), current.Currency,
+				formatCostRange(proposed.MinMonthly-current.MinMonthly, proposed.MaxMonthly-current.MaxMonthly, "%+.2f"))
+		}
+
+		fmt.Fprintf(out, "Prices for %s", current.Region)
+		if current.PriceDate != "" {
+			fmt.Fprintf(out, " as of %s", current.PriceDate)
+		}
+		fmt.Fprintf(out, "; data transfer is not included.\n")
+		warnings := current.Warnings
+		if result.Proposed != nil {
+			warnings = result.Proposed.Warnings
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(out, "Warning: %s\n", w)
+		}
+		return nil
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling cost estimate to json: %v", err)
+		}
+		_, err = out.Write(append(b, '\n'))
+		return err
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+}
+
+// formatCostItem returns the count and monthly cost of a line item, e.g. "3 x m4.large, 219.00", or a range when the group can scale
+func formatCostItem(i *cost.LineItem) string {
+	var s []string
+	count := formatCostRange(float64(i.MinCount), float64(i.MaxCount), "%.0f")
+	if i.Kind == cost.KindVolumes {
+		count += " GB"
+	}
+	if i.Type != "" {
+		s = append(s, count+" x "+i.Type)
+	} else {
+		s = append(s, count)
+	}
+	s = append(s, formatCostRange(i.MinMonthly, i.MaxMonthly, "%.2f"))
+	return strings.Join(s, ", ")
+}
+
+// formatCostRange formats a value that depends on the size of the instance groups, e.g. 2..4
+func formatCostRange(min float64, max float64, format string) string {
+	if fmt.Sprintf(format, min) == fmt.Sprintf(format, max) {
+		return fmt.Sprintf(format, min)
+	}
+	return fmt.Sprintf(format, min) + ".." + fmt.Sprintf(format, max)
+}
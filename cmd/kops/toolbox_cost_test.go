@@ -0,0 +1,112 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestApplyCostManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cost")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: nodes
+  labels:
+    kops.k8s.io/cluster: test.example.com
+spec:
+  role: Node
+  machineType: m4.xlarge
+  minSize: 5
+  maxSize: 5
+---
+apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: extra
+spec:
+  role: Node
+  machineType: t2.medium
+`
+	p := filepath.Join(dir, "igs.yaml")
+	if err := ioutil.WriteFile(p, []byte(manifest), 0644); err != nil {
+		t.Fatalf("error writing manifest: %v", err)
+	}
+
+	cluster := &api.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test.example.com"}}
+	igs := []*api.InstanceGroup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "master"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleMaster, MachineType: "m4.large"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nodes"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleNode, MachineType: "m4.large", MinSize: fi.Int32(2)}},
+	}
+
+	proposedCluster, proposed, err := applyCostManifests(cluster, igs, []string{p})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proposedCluster != cluster {
+		t.Errorf("expected the cluster to be unchanged")
+	}
+	if len(proposed) != 3 {
+		t.Fatalf("expected 3 instance groups, got %d", len(proposed))
+	}
+	if proposed[0].ObjectMeta.Name != "extra" || proposed[1].ObjectMeta.Name != "master" || proposed[2].ObjectMeta.Name != "nodes" {
+		t.Errorf("unexpected instance groups %s, %s, %s", proposed[0].ObjectMeta.Name, proposed[1].ObjectMeta.Name, proposed[2].ObjectMeta.Name)
+	}
+	if proposed[2].Spec.MachineType != "m4.xlarge" || fi.Int32Value(proposed[2].Spec.MinSize) != 5 {
+		t.Errorf("expected the nodes instance group to be replaced, got %v", proposed[2].Spec)
+	}
+	if igs[1].Spec.MachineType != "m4.large" {
+		t.Errorf("the current instance groups should not be modified")
+	}
+
+	cluster.ObjectMeta.Name = "other.example.com"
+	if _, _, err := applyCostManifests(cluster, igs, []string{p}); err == nil {
+		t.Errorf("expected an error for instance groups of another cluster")
+	}
+}
+
+func TestFormatCostRange(t *testing.T) {
+	grid := []struct {
+		Min      float64
+		Max      float64
+		Format   string
+		Expected string
+	}{
+		{Min: 2, Max: 2, Format: "%.0f", Expected: "2"},
+		{Min: 2, Max: 4, Format: "%.0f", Expected: "2..4"},
+		{Min: 10.001, Max: 10.004, Format: "%.2f", Expected: "10.00"},
+		{Min: -5, Max: 10, Format: "%+.2f", Expected: "-5.00..+10.00"},
+	}
+	for _, g := range grid {
+		actual := formatCostRange(g.Min, g.Max, g.Format)
+		if actual != g.Expected {
+			t.Errorf("formatCostRange(%v, %v, %q) = %q, expected %q", g.Min, g.Max, g.Format, actual, g.Expected)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/kutil"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxEnrollLong = templates.LongDesc(i18n.T(`
+	Adopts existing, manually created instances into an instance group, to ease migrating from a
+	hand-rolled cluster.  Only AWS is supported.
+
+	Each instance is given the instance profile (if it has none), the security groups and the tags
+	of the instance group, and is attached to the autoscaling group of the instance group, increasing
+	its desired capacity; instances of directly managed instance groups are found by their tags.  The
+	instance is then bootstrapped over SSH by running the user data of the group, which installs
+	nodeup and its configuration.
+
+	Instances cannot be adopted into directly managed instance groups while the kops-controller is
+	enabled, as it only issues certificates to instances launched from the group's launch template.
+
+	Adopted instances are reported by kops rolling-update cluster as needing update, as they were not
+	launched from the current configuration of the group, so they are replaced by managed instances at
+	the next rolling update.
+
+	Without --yes, the changes that would be made to each instance are listed.`))
+
+	toolboxEnrollExample = templates.Examples(i18n.T(`
+	# List the changes that adopting two instances into the nodes instance group would make
+	kops toolbox enroll --name k8s-cluster.example.com --instance-group nodes --instance i-0123456789abcdef0 --instance i-0fedcba9876543210
+
+	# Adopt the instances, connecting to them as the ubuntu user
+	kops toolbox enroll --name k8s-cluster.example.com --instance-group nodes --instance i-0123456789abcdef0 --ssh-user ubuntu --yes
+	`))
+
+	toolboxEnrollShort = i18n.T(`Adopt existing instances into an instance group`)
+)
+
+type ToolboxEnrollOptions struct {
+	ClusterName string
+
+	// InstanceGroup is the instance group that the instances join
+	InstanceGroup string
+	// Instances are the ids of the instances to adopt
+	Instances []string
+
+	// Yes adopts the instances; otherwise the changes that would be made are listed
+	Yes bool
+
+	// SkipBootstrap does not run the user data of the group on the instances, e.g. because it is run some other way
+	SkipBootstrap bool
+
+	// SSHUser is the user to connect to the instances as
+	SSHUser string
+	// SSHIdentity is the private key to connect to the instances with
+	SSHIdentity string
+	// InternalIP connects to the instances by their private IP, rather than their public IP
+	InternalIP bool
+}
+
+func (o *ToolboxEnrollOptions) InitDefaults() {
+	o.SSHUser = "admin"
+	o.SSHIdentity = filepath.Join(homedir.HomeDir(), ".ssh", "id_rsa")
+}
+
+func NewCmdToolboxEnroll(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxEnrollOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "enroll",
+		Short:   toolboxEnrollShort,
+		Long:    toolboxEnrollLong,
+		Example: toolboxEnrollExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+			if options.ClusterName == "" {
+				exitWithError(fmt.Errorf("--name is required"))
+			}
+
+			err = RunToolboxEnroll(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.InstanceGroup, "instance-group", options.InstanceGroup, "Instance group that the instances join")
+	cmd.MarkFlagCustom("instance-group", "__kops_get_instancegroups")
+	cmd.Flags().StringSliceVar(&options.Instances, "instance", options.Instances, "Id of an instance to adopt; may be repeated")
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Adopt the instances immediately, without --yes the changes that would be made are listed")
+	cmd.Flags().BoolVar(&options.SkipBootstrap, "skip-bootstrap", options.SkipBootstrap, "Do not run the user data of the instance group on the instances over SSH")
+	cmd.Flags().StringVar(&options.SSHUser, "ssh-user", options.SSHUser, "User to connect to the instances as")
+	cmd.Flags().StringVar(&options.SSHIdentity, "ssh-identity", options.SSHIdentity, "Private key to connect to the instances with")
+	cmd.Flags().BoolVar(&options.InternalIP, "internal-ip", options.InternalIP, "Connect to the instances by their private IP, e.g. through a VPN, rather than their public IP")
+
+	return cmd
+}
+
+func RunToolboxEnroll(f *util.Factory, out io.Writer, options *ToolboxEnrollOptions) error {
+	if options.InstanceGroup == "" {
+		return fmt.Errorf("--instance-group is required")
+	}
+	if len(options.Instances) == 0 {
+		return fmt.Errorf("--instance is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if api.CloudProviderID(cluster.Spec.CloudProvider) != api.CloudProviderAWS {
+		return fmt.Errorf("adopting instances is only supported on AWS, not %q", cluster.Spec.CloudProvider)
+	}
+
+	ig, err := clientset.InstanceGroupsFor(cluster).Get(options.InstanceGroup, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading instance group %q: %v", options.InstanceGroup, err)
+	}
+	if ig == nil {
+		return fmt.Errorf("instance group %q not found", options.InstanceGroup)
+	}
+	if ig.IsDirectlyManaged() && cluster.Spec.KopsControllerEnabled() {
+		// kops-controller only issues certificates to the instances of a directly managed group that were launched
+		// from its launch template, so adopted instances would never be able to join
+		return fmt.Errorf("cannot adopt instances into directly managed instance group %q while the kops-controller is enabled", ig.ObjectMeta.Name)
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+	awsCloud := cloud.(awsup.AWSCloud)
+
+	groups, err := cloud.GetCloudGroups(cluster, []*api.InstanceGroup{ig}, false, nil)
+	if err != nil {
+		return err
+	}
+	cg := groups[ig.ObjectMeta.Name]
+	if cg == nil {
+		return fmt.Errorf("instance group %q has not been created in the cloud; run kops update cluster first", ig.ObjectMeta.Name)
+	}
+
+	spec, tags, err := awsup.FindEnrollmentSpec(awsCloud, cg)
+	if err != nil {
+		return err
+	}
+
+	if g, ok := cg.Raw.(*autoscaling.Group); ok {
+		if size := len(g.Instances) + len(options.Instances); int64(size) > aws.Int64Value(g.MaxSize) {
+			return fmt.Errorf("instance group %q would have %d instances, more than its maxSize of %d; raise maxSize with kops edit ig and kops update cluster first", ig.ObjectMeta.Name, size, aws.Int64Value(g.MaxSize))
+		}
+	}
+
+	response, err := awsCloud.EC2().DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice(options.Instances),
+	})
+	if err != nil {
+		return fmt.Errorf("error describing instances: %v", err)
+	}
+	instances := make(map[string]*ec2.Instance)
+	for _, r := range response.Reservations {
+		for _, i := range r.Instances {
+			instances[aws.StringValue(i.InstanceId)] = i
+		}
+	}
+
+	var enrollments []*awsup.InstanceEnrollment
+	for _, id := range options.Instances {
+		i := instances[id]
+		if i == nil {
+			return fmt.Errorf("instance %q not found", id)
+		}
+		e, err := awsup.PlanInstanceEnrollment(cg, spec, tags, i, options.InternalIP)
+		if err != nil {
+			return err
+		}
+		enrollments = append(enrollments, e)
+	}
+
+	if !options.Yes {
+		if err := enrollmentOutputTable(enrollments, out); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\nMust specify --yes to adopt the instances into instance group %q\n", ig.ObjectMeta.Name)
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	var sshConfig ssh.ClientConfig
+	if !options.SkipBootstrap {
+		if spec.UserData == "" {
+			return fmt.Errorf("instance group %q has no user data to bootstrap the instances with", ig.ObjectMeta.Name)
+		}
+		sshConfig = ssh.ClientConfig{
+			User:            options.SSHUser,
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		}
+		if err := kutil.AddSSHIdentity(&sshConfig, options.SSHIdentity); err != nil {
+			return fmt.Errorf("error reading SSH identity %q: %v", options.SSHIdentity, err)
+		}
+	}
+
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationEnroll, "instancegroup/"+ig.ObjectMeta.Name, "")
+
+	for _, e := range enrollments {
+		fmt.Fprintf(out, "Adopting instance %q into instance group %q\n", e.InstanceID, ig.ObjectMeta.Name)
+
+		if err := awsup.ApplyInstanceEnrollment(awsCloud, e); err != nil {
+			return err
+		}
+
+		// The instance is attached before it is bootstrapped, as the kops-controller only issues kubelet certificates
+		// to the members of an autoscaling group
+		if err := awsup.AttachEnrolledInstance(awsCloud, e); err != nil {
+			return err
+		}
+
+		if !options.SkipBootstrap {
+			// The instance profile can take a few seconds to be usable, but nodeup retries until it can read its configuration
+			output, err := runEnrollBootstrap(e, sshConfig, spec.UserData)
+			if err != nil {
+				return fmt.Errorf("error bootstrapping instance %q: %v\n%s", e.InstanceID, err, output)
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d instances adopted into instance group %q; check that they join with: kops validate cluster %s\n", len(enrollments), ig.ObjectMeta.Name, cluster.ObjectMeta.Name)
+	return nil
+}
+
+// runEnrollBootstrap runs the user data of the instance group on the instance, as cloud-init would at boot
+func runEnrollBootstrap(e *awsup.InstanceEnrollment, sshConfig ssh.ClientConfig, userData string) (string, error) {
+	if e.Address == "" {
+		return "", fmt.Errorf("instance has no address to connect to")
+	}
+
+	nodeSSH := &kutil.NodeSSH{
+		Hostname:  e.Address,
+		SSHConfig: sshConfig,
+	}
+	sshClient, err := nodeSSH.GetSSHClient()
+	if err != nil {
+		return "", err
+	}
+	defer sshClient.Close()
+
+	s, err := sshClient.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("error creating ssh session: %v", err)
+	}
+	defer s.Close()
+
+	var output bytes.Buffer
+	s.Stdin = strings.NewReader(userData)
+	s.Stdout = &output
+	s.Stderr = &output
+
+	err = s.Run("sudo bash -s")
+	return output.String(), err
+}
+
+func enrollmentOutputTable(enrollments []*awsup.InstanceEnrollment, out io.Writer) error {
+	t := &tables.Table{}
+	t.AddColumn("INSTANCE", func(e *awsup.InstanceEnrollment) string {
+		return e.InstanceID
+	})
+	t.AddColumn("ADDRESS", func(e *awsup.InstanceEnrollment) string {
+		return e.Address
+	})
+	t.AddColumn("INSTANCE PROFILE", func(e *awsup.InstanceEnrollment) string {
+		return e.AssociateIAMInstanceProfile
+	})
+	t.AddColumn("SECURITY GROUPS", func(e *awsup.InstanceEnrollment) string {
+		return strings.Join(e.SecurityGroups, ",")
+	})
+	t.AddColumn("TAGS", func(e *awsup.InstanceEnrollment) string {
+		return fmt.Sprintf("%d", len(e.Tags))
+	})
+	t.AddColumn("AUTOSCALING GROUP", func(e *awsup.InstanceEnrollment) string {
+		return e.AutoscalingGroup
+	})
+	return t.Render(enrollments, out, "INSTANCE", "ADDRESS", "INSTANCE PROFILE", "SECURITY GROUPS", "TAGS", "AUTOSCALING GROUP")
+}
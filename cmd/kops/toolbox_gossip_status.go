@@ -0,0 +1,227 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxGossipStatusLong = templates.LongDesc(i18n.T(`
+	Displays the state of gossip DNS on each node of a gossip (.k8s.local) cluster.
+
+	The status is fetched from protokube on each node, through the Kubernetes API server node proxy,
+	so the cluster must be in your kubeconfig.  For each gossip protocol the number of connected peers is shown,
+	along with a hash of the DNS records; nodes whose records have converged have the same hash.`))
+
+	toolboxGossipStatusExample = templates.Examples(i18n.T(`
+	# Show the gossip status of each node
+	kops toolbox gossip-status --name k8s-cluster.k8s.local
+	`))
+
+	toolboxGossipStatusShort = i18n.T(`Display the gossip DNS status of each node`)
+)
+
+type ToolboxGossipStatusOptions struct {
+	ClusterName string
+}
+
+func NewCmdToolboxGossipStatus(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxGossipStatusOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "gossip-status",
+		Short:   toolboxGossipStatusShort,
+		Long:    toolboxGossipStatusLong,
+		Example: toolboxGossipStatusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunToolboxGossipStatus(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// nodeGossipStatus is the gossip status of a node, or the error fetching it
+type nodeGossipStatus struct {
+	Node   string
+	Status *gossip.NodeStatus
+	Error  error
+}
+
+func RunToolboxGossipStatus(f *util.Factory, out io.Writer, options *ToolboxGossipStatusOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if !dns.IsGossipHostname(cluster.Spec.MasterInternalName) {
+		return fmt.Errorf("cluster %q does not use gossip DNS", cluster.ObjectMeta.Name)
+	}
+
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("Cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+	retrypolicy.ConfigureKubernetes(config)
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("Cannot build kubernetes api client for %q: %v", contextName, err)
+	}
+
+	nodes, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	_, port, err := net.SplitHostPort(gossip.DefaultStatusListen)
+	if err != nil {
+		return fmt.Errorf("error parsing gossip status address: %v", err)
+	}
+
+	var statuses []*nodeGossipStatus
+	for i := range nodes.Items {
+		node := nodes.Items[i].Name
+		s := &nodeGossipStatus{Node: node}
+		statuses = append(statuses, s)
+
+		data, err := k8sClient.CoreV1().RESTClient().Get().
+			Resource("nodes").
+			Name(node + ":" + port).
+			SubResource("proxy").
+			Suffix(gossip.StatusPath).
+			DoRaw()
+		if err != nil {
+			s.Error = fmt.Errorf("error fetching gossip status: %v", err)
+			continue
+		}
+
+		status := &gossip.NodeStatus{}
+		if err := json.Unmarshal(data, status); err != nil {
+			s.Error = fmt.Errorf("error parsing gossip status: %v", err)
+			continue
+		}
+		s.Status = status
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Node < statuses[j].Node
+	})
+
+	// The most common hash is taken to be the converged state
+	hashCounts := make(map[string]int)
+	for _, s := range statuses {
+		if s.Status != nil {
+			hashCounts[s.Status.RecordsHash]++
+		}
+	}
+	majorityHash := ""
+	for hash, count := range hashCounts {
+		if count > hashCounts[majorityHash] || (count == hashCounts[majorityHash] && hash < majorityHash) {
+			majorityHash = hash
+		}
+	}
+
+	t := &tables.Table{}
+	t.AddColumn("NODE", func(s *nodeGossipStatus) string {
+		return s.Node
+	})
+	t.AddColumn("PROTOCOLS", func(s *nodeGossipStatus) string {
+		if s.Status == nil {
+			return ""
+		}
+		var protocols []string
+		for _, p := range s.Status.Protocols {
+			protocols = append(protocols, fmt.Sprintf("%s(%d peers)", p.Protocol, len(p.Peers)))
+		}
+		return strings.Join(protocols, ",")
+	})
+	t.AddColumn("RECORDS", func(s *nodeGossipStatus) string {
+		if s.Status == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d", s.Status.Records)
+	})
+	t.AddColumn("HASH", func(s *nodeGossipStatus) string {
+		if s.Status == nil || len(s.Status.RecordsHash) < 12 {
+			return ""
+		}
+		return s.Status.RecordsHash[:12]
+	})
+	t.AddColumn("STATUS", func(s *nodeGossipStatus) string {
+		if s.Error != nil {
+			return s.Error.Error()
+		}
+		var problems []string
+		for _, p := range s.Status.Protocols {
+			if len(p.Peers) == 0 && len(statuses) > 1 {
+				problems = append(problems, fmt.Sprintf("no %s peers", p.Protocol))
+			}
+		}
+		if s.Status.RecordsHash != majorityHash {
+			problems = append(problems, "records differ from other nodes")
+		}
+		if len(problems) == 0 {
+			return "ok"
+		}
+		return strings.Join(problems, "; ")
+	})
+
+	if err := t.Render(statuses, out, "NODE", "PROTOCOLS", "RECORDS", "HASH", "STATUS"); err != nil {
+		return err
+	}
+
+	if len(hashCounts) > 1 {
+		fmt.Fprintf(out, "\nGossip records have not converged: found %d different record sets\n", len(hashCounts))
+	}
+
+	return nil
+}
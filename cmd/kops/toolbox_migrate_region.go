@@ -0,0 +1,240 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxMigrateRegionLong = templates.LongDesc(i18n.T(`
+	Create a copy of a cluster in another region, from a backup of the cluster, and print a checklist
+	for cutting over to it.
+
+	The cluster is cloned under a new name, as by kops clone cluster.  Its zones are moved to the zones
+	with the same suffix in the new region, and subnets, instance groups and etcd members named after a
+	zone are renamed to match.  Existing networks, and images that are specific to the old region, are
+	dropped, so kops creates or finds new ones; other existing resources are listed in the checklist.
+
+	The keys and secrets of the cluster are copied, so existing credentials remain valid, and
+	etcd-manager restores the latest etcd backup of the cluster (or the one in --backup) when the
+	control plane of the new cluster starts.
+
+	The new cluster is only created in the state store; run kops update cluster to create it in the cloud.
+	`))
+
+	toolboxMigrateRegionExample = templates.Examples(i18n.T(`
+	# Preview a copy of a cluster in another region
+	kops toolbox migrate-region --name k8s-cluster.example.com --to k8s-eu.example.com --region eu-west-1 --dry-run -o yaml
+
+	# Create the copy from a backup taken with kops backup cluster
+	kops toolbox migrate-region --name k8s-cluster.example.com --to k8s-eu.example.com --region eu-west-1 \
+	  --backup s3://example.com/k8s-cluster.example.com/backups/cluster/20180601T120000Z.tar.gz
+	`))
+
+	toolboxMigrateRegionShort = i18n.T(`Copy a cluster into another region`)
+)
+
+type ToolboxMigrateRegionOptions struct {
+	ClusterName string
+	commands.MigrateRegionOptions
+
+	// Backup is the path of a bundle written by kops backup cluster; if empty, the cluster is backed up
+	Backup string
+
+	DryRun bool
+	Output string
+}
+
+func NewCmdToolboxMigrateRegion(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxMigrateRegionOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "migrate-region",
+		Short:   toolboxMigrateRegionShort,
+		Long:    toolboxMigrateRegionLong,
+		Example: toolboxMigrateRegionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunToolboxMigrateRegion(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.To, "to", options.To, "Name of the new cluster")
+	cmd.Flags().StringVar(&options.Region, "region", options.Region, "Region of the new cluster")
+	cmd.Flags().StringVar(&options.DNSZone, "dns-zone", options.DNSZone, "DNS zone for the new cluster, if different from the source cluster")
+	cmd.Flags().StringVar(&options.NetworkCIDR, "network-cidr", options.NetworkCIDR, "Network CIDR for the new cluster; subnets are moved to the same offsets within the new range")
+	cmd.Flags().StringVar(&options.Image, "image", options.Image, "Image for all instance groups of the new cluster (defaults to the image in the channel, for images specific to the old region)")
+	cmd.Flags().StringVar(&options.Backup, "backup", options.Backup, "Backup written by kops backup cluster to restore (defaults to backing up the cluster)")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", options.DryRun, "If true, only print the objects that would be created, and the checklist, without creating them.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format. One of json|yaml. Used with the --dry-run flag.")
+
+	return cmd
+}
+
+func RunToolboxMigrateRegion(f *util.Factory, out io.Writer, options *ToolboxMigrateRegionOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+	if options.To == "" {
+		return fmt.Errorf("--to is required")
+	}
+	if options.Region == "" {
+		return fmt.Errorf("--region is required")
+	}
+	if options.DryRun && options.Output == "" {
+		return fmt.Errorf("unable to execute --dry-run without setting --output")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading instance groups for cluster %q: %v", options.ClusterName, err)
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	migration, err := commands.PlanRegionMigration(cluster, instanceGroups, &options.MigrateRegionOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := validation.ValidateCluster(migration.Cluster, false); err != nil {
+		return fmt.Errorf("migrated cluster is not valid: %v", err)
+	}
+	for _, ig := range migration.InstanceGroups {
+		if err := validation.CrossValidateInstanceGroup(ig, migration.Cluster, false); err != nil {
+			return fmt.Errorf("migrated instance group %q is not valid: %v", ig.ObjectMeta.Name, err)
+		}
+	}
+
+	if options.DryRun {
+		obj := []runtime.Object{migration.Cluster}
+		for _, ig := range migration.InstanceGroups {
+			obj = append(obj, ig)
+		}
+		switch options.Output {
+		case OutputYaml:
+			if err := fullOutputYAML(out, obj...); err != nil {
+				return err
+			}
+		case OutputJSON:
+			if err := fullOutputJSON(out, obj...); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported output type %q", options.Output)
+		}
+		// The checklist is not part of the objects, so keep it out of the output
+		printMigrationChecklist(os.Stderr, migration)
+		return nil
+	}
+
+	if _, err := clientset.GetCluster(options.To); err == nil {
+		return fmt.Errorf("cluster %q already exists", options.To)
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("error checking for existing cluster %q: %v", options.To, err)
+	}
+
+	// Read the backup before we write anything
+	var backup *commands.ClusterBackup
+	if options.Backup != "" {
+		p, err := vfs.Context.BuildVfsPath(options.Backup)
+		if err != nil {
+			return fmt.Errorf("error building path for %q: %v", options.Backup, err)
+		}
+		data, err := p.ReadFile()
+		if err != nil {
+			return fmt.Errorf("error reading backup %s: %v", p, err)
+		}
+		backup, err = commands.ReadClusterBackup(data)
+		if err != nil {
+			return err
+		}
+		if backup.Manifest.ClusterName != options.ClusterName {
+			return fmt.Errorf("backup %s is of cluster %q, not %q", p, backup.Manifest.ClusterName, options.ClusterName)
+		}
+	} else {
+		backup, err = commands.BuildClusterBackup(clientset, cluster, instanceGroups, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := registry.CreateClusterConfig(clientset, migration.Cluster, migration.InstanceGroups); err != nil {
+		return fmt.Errorf("error writing cluster configuration: %v", err)
+	}
+	commands.RecordAuditChange(f, options.To, audit.OperationCreate, "cluster", nil, migration.Cluster)
+
+	// Read the cluster back, so that its paths in the state store are set
+	migrated, err := clientset.GetCluster(options.To)
+	if err != nil {
+		return fmt.Errorf("error reading cluster %q: %v", options.To, err)
+	}
+	if err := commands.RestoreClusterState(clientset, backup, migrated, time.Now()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nCluster %q has been created in %s as a copy of %q, from the backup taken at %s.\n", options.To, options.Region, options.ClusterName, backup.Manifest.Timestamp)
+	printMigrationChecklist(out, migration)
+
+	return nil
+}
+
+func printMigrationChecklist(out io.Writer, migration *commands.RegionMigration) {
+	fmt.Fprintf(out, "\nCutover checklist:\n")
+	for i, step := range migration.Checklist {
+		fmt.Fprintf(out, " %d. %s\n", i+1, step)
+	}
+	fmt.Fprintf(out, "\n")
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/bootreport"
+	"k8s.io/kops/util/pkg/slice"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxNodeBootReportLong = templates.LongDesc(i18n.T(`
+	Summarizes how long nodeup took to bootstrap the instances of the cluster.
+
+	When nodeBootReports is enabled in the cluster spec, nodeup records how long each of its
+	tasks took and publishes a report to the state store.  This command aggregates the reports,
+	showing the boot time of each instance group and the slowest tasks, so that the steps that
+	prolong rolling updates and scale-ups can be found and fixed.`))
+
+	toolboxNodeBootReportExample = templates.Examples(i18n.T(`
+	# Summarize the boot reports of a cluster
+	kops toolbox node-boot-report --name k8s-cluster.example.com
+
+	# Only consider the nodes instance group, booted in the last day
+	kops toolbox node-boot-report --name k8s-cluster.example.com --instance-group nodes --since 24h
+	`))
+
+	toolboxNodeBootReportShort = i18n.T(`Summarize the nodeup boot timing reports`)
+)
+
+type ToolboxNodeBootReportOptions struct {
+	ClusterName string
+
+	// InstanceGroups limits the reports to these instance groups
+	InstanceGroups []string
+	// Since limits the reports to instances that booted within this duration
+	Since time.Duration
+	// Tasks is the number of slowest tasks to show
+	Tasks int
+
+	Output string
+}
+
+func (o *ToolboxNodeBootReportOptions) InitDefaults() {
+	o.Tasks = 10
+	o.Output = OutputTable
+}
+
+func NewCmdToolboxNodeBootReport(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxNodeBootReportOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "node-boot-report",
+		Short:   toolboxNodeBootReportShort,
+		Long:    toolboxNodeBootReportLong,
+		Example: toolboxNodeBootReportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunToolboxNodeBootReport(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.InstanceGroups, "instance-group", options.InstanceGroups, "Only include the reports of these instance groups")
+	cmd.Flags().DurationVar(&options.Since, "since", options.Since, "Only include the reports of instances that booted within this duration, e.g. 24h")
+	cmd.Flags().IntVar(&options.Tasks, "tasks", options.Tasks, "Number of slowest tasks to show")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "output format.  One of: table, json")
+
+	return cmd
+}
+
+func RunToolboxNodeBootReport(f *util.Factory, out io.Writer, options *ToolboxNodeBootReportOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	configBase, err := registry.ConfigBase(cluster)
+	if err != nil {
+		return err
+	}
+
+	reports, err := bootreport.List(configBase)
+	if err != nil {
+		return err
+	}
+
+	reports = filterBootReports(reports, options.InstanceGroups, options.Since, time.Now())
+	if len(reports) == 0 {
+		if !cluster.Spec.NodeBootReportsEnabled() {
+			return fmt.Errorf("no boot reports found; set nodeBootReports: true in the cluster spec, and update the cluster, to publish them")
+		}
+		return fmt.Errorf("no boot reports found")
+	}
+
+	return nodeBootReportOutput(out, options, bootreport.Summarize(reports))
+}
+
+// filterBootReports returns the reports of the instance groups (or all, if none are given) that started within since of now
+func filterBootReports(reports []*bootreport.Report, instanceGroups []string, since time.Duration, now time.Time) []*bootreport.Report {
+	var filtered []*bootreport.Report
+	for _, r := range reports {
+		if len(instanceGroups) != 0 && !slice.Contains(instanceGroups, r.InstanceGroup) {
+			continue
+		}
+		if since != 0 && r.StartTime.Time.Before(now.Add(-since)) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
+
+func nodeBootReportOutput(out io.Writer, options *ToolboxNodeBootReportOptions, summary *bootreport.Summary) error {
+	if options.Tasks >= 0 && len(summary.Tasks) > options.Tasks {
+		summary.Tasks = summary.Tasks[:options.Tasks]
+	}
+
+	switch options.Output {
+	case OutputTable:
+		igTable := &tables.Table{}
+		igTable.AddColumn("INSTANCEGROUP", func(s *bootreport.InstanceGroupSummary) string {
+			return s.Name
+		})
+		igTable.AddColumn("INSTANCES", func(s *bootreport.InstanceGroupSummary) string {
+			return fmt.Sprintf("%d", s.Instances)
+		})
+		igTable.AddColumn("FAILED", func(s *bootreport.InstanceGroupSummary) string {
+			return fmt.Sprintf("%d", s.Failed)
+		})
+		igTable.AddColumn("START DELAY", func(s *bootreport.InstanceGroupSummary) string {
+			return formatBootDuration(s.MedianStartDelay)
+		})
+		igTable.AddColumn("MEDIAN", func(s *bootreport.InstanceGroupSummary) string {
+			return formatBootDuration(s.MedianDuration)
+		})
+		igTable.AddColumn("MAX", func(s *bootreport.InstanceGroupSummary) string {
+			return formatBootDuration(s.MaxDuration)
+		})
+		if err := igTable.Render(summary.InstanceGroups, out, "INSTANCEGROUP", "INSTANCES", "FAILED", "START DELAY", "MEDIAN", "MAX"); err != nil {
+			return err
+		}
+
+		if len(summary.Tasks) == 0 {
+			return nil
+		}
+		fmt.Fprintf(out, "\n")
+
+		taskTable := &tables.Table{}
+		taskTable.AddColumn("TASK", func(s *bootreport.TaskSummary) string {
+			return s.Key
+		})
+		taskTable.AddColumn("INSTANCES", func(s *bootreport.TaskSummary) string {
+			return fmt.Sprintf("%d", s.Instances)
+		})
+		taskTable.AddColumn("FAILURES", func(s *bootreport.TaskSummary) string {
+			return fmt.Sprintf("%d", s.Failures)
+		})
+		taskTable.AddColumn("MEDIAN", func(s *bootreport.TaskSummary) string {
+			return formatBootDuration(s.MedianDuration)
+		})
+		taskTable.AddColumn("MAX", func(s *bootreport.TaskSummary) string {
+			return formatBootDuration(s.MaxDuration)
+		})
+		return taskTable.Render(summary.Tasks, out, "TASK", "INSTANCES", "FAILURES", "MEDIAN", "MAX")
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling boot report summary to json: %v", err)
+		}
+		_, err = out.Write(append(b, '\n'))
+		return err
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+}
+
+// formatBootDuration rounds d to a tenth of a second, which is precise enough to compare bootstrap steps
+func formatBootDuration(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
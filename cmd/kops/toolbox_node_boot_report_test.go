@@ -0,0 +1,91 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/bootreport"
+)
+
+func TestFilterBootReports(t *testing.T) {
+	now := time.Date(2018, 6, 2, 12, 0, 0, 0, time.UTC)
+	reports := []*bootreport.Report{
+		{Hostname: "a", InstanceGroup: "nodes", StartTime: metav1.NewTime(now.Add(-2 * time.Hour))},
+		{Hostname: "b", InstanceGroup: "nodes", StartTime: metav1.NewTime(now.Add(-48 * time.Hour))},
+		{Hostname: "c", InstanceGroup: "master-us-east-1a", StartTime: metav1.NewTime(now.Add(-time.Hour))},
+	}
+
+	grid := []struct {
+		InstanceGroups []string
+		Since          time.Duration
+		Expected       []string
+	}{
+		{Expected: []string{"a", "b", "c"}},
+		{InstanceGroups: []string{"nodes"}, Expected: []string{"a", "b"}},
+		{Since: 24 * time.Hour, Expected: []string{"a", "c"}},
+		{InstanceGroups: []string{"nodes"}, Since: 24 * time.Hour, Expected: []string{"a"}},
+	}
+
+	for i, g := range grid {
+		var actual []string
+		for _, r := range filterBootReports(reports, g.InstanceGroups, g.Since, now) {
+			actual = append(actual, r.Hostname)
+		}
+		if strings.Join(actual, ",") != strings.Join(g.Expected, ",") {
+			t.Errorf("case %d: expected %v, got %v", i, g.Expected, actual)
+		}
+	}
+}
+
+func TestNodeBootReportOutput(t *testing.T) {
+	summary := &bootreport.Summary{
+		InstanceGroups: []*bootreport.InstanceGroupSummary{
+			{Name: "nodes", Instances: 3, MedianStartDelay: 31 * time.Second, MedianDuration: 94230 * time.Millisecond, MaxDuration: 2 * time.Minute},
+		},
+		Tasks: []*bootreport.TaskSummary{
+			{Key: "Package/docker-ce", Instances: 3, MedianDuration: 40 * time.Second, MaxDuration: time.Minute},
+			{Key: "Service/kubelet.service", Instances: 3, MedianDuration: 2 * time.Second, MaxDuration: 3 * time.Second},
+		},
+	}
+
+	options := &ToolboxNodeBootReportOptions{}
+	options.InitDefaults()
+	options.Tasks = 1
+
+	var table bytes.Buffer
+	if err := nodeBootReportOutput(&table, options, summary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"INSTANCEGROUP", "nodes", "1m34.2s", "Package/docker-ce", "40s"} {
+		if !strings.Contains(table.String(), s) {
+			t.Errorf("table output did not contain %q:\n%s", s, table.String())
+		}
+	}
+	if strings.Contains(table.String(), "kubelet") {
+		t.Errorf("table output should be limited to the slowest task:\n%s", table.String())
+	}
+
+	options.Output = "xml"
+	if err := nodeBootReportOutput(&bytes.Buffer{}, options, summary); err == nil {
+		t.Errorf("expected error for unknown output format")
+	}
+}
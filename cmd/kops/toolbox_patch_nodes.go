@@ -0,0 +1,348 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/kutil"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxPatchNodesLong = templates.LongDesc(i18n.T(`
+	Installs OS package updates on the nodes of a cluster in place, without replacing the instances.
+
+	One node at a time, the node is cordoned and drained (respecting PodDisruptionBudgets), the updates
+	are installed over SSH with unattended-upgrades, apt-get or yum, and the node is rebooted if the
+	updates require it.  The node is then uncordoned, and the cluster must validate before the next
+	node is patched.  Masters are patched first.
+
+	Without --yes, the nodes that would be patched are listed.`))
+
+	toolboxPatchNodesExample = templates.Examples(i18n.T(`
+	# List the nodes that would be patched
+	kops toolbox patch-nodes --name k8s-cluster.example.com
+
+	# Patch all the nodes of the cluster
+	kops toolbox patch-nodes --name k8s-cluster.example.com --yes
+
+	# Patch only the nodes of one instance group, connecting as the centos user
+	kops toolbox patch-nodes --name k8s-cluster.example.com --instance-group nodes --ssh-user centos --yes
+	`))
+
+	toolboxPatchNodesShort = i18n.T(`Install OS updates on the nodes of a cluster, one node at a time.`)
+)
+
+type ToolboxPatchNodesOptions struct {
+	ClusterName string
+
+	// Yes patches the nodes; otherwise the nodes that would be patched are listed
+	Yes bool
+
+	// InstanceGroups is the list of instance groups whose nodes are patched;
+	// if not specified, the nodes of all instance groups are patched
+	InstanceGroups []string
+
+	// InstanceGroupRoles limits patching to the instance groups of these roles
+	InstanceGroupRoles []string
+
+	// SSHUser is the user to connect to the nodes as
+	SSHUser string
+
+	// SSHIdentity is the private key to connect to the nodes with
+	SSHIdentity string
+
+	// InternalIP connects to the nodes by their internal IP, rather than their external IP
+	InternalIP bool
+
+	// Interval is the minimum time to wait after patching a node, before patching the next
+	Interval time.Duration
+
+	// PostDrainDelay is how long to wait after draining a node, for pods to stabilize
+	PostDrainDelay time.Duration
+
+	// RebootTimeout is how long to wait for a node to become ready after it is rebooted
+	RebootTimeout time.Duration
+
+	// ValidationTimeout is how long to wait for the cluster to validate after a node is patched
+	ValidationTimeout time.Duration
+
+	// FailOnValidate stops patching when the cluster does not validate
+	FailOnValidate bool
+
+	// ValidateConditions are the node conditions, other than Ready, that fail validation when they are true
+	ValidateConditions []string
+}
+
+func (o *ToolboxPatchNodesOptions) InitDefaults() {
+	o.Yes = false
+	o.SSHUser = "admin"
+	o.SSHIdentity = filepath.Join(homedir.HomeDir(), ".ssh", "id_rsa")
+	o.Interval = 0
+	o.PostDrainDelay = 90 * time.Second
+	o.RebootTimeout = 10 * time.Minute
+	o.ValidationTimeout = 5 * time.Minute
+	o.FailOnValidate = true
+	o.ValidateConditions = validation.DefaultNodeConditions
+}
+
+func NewCmdToolboxPatchNodes(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxPatchNodesOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "patch-nodes",
+		Short:   toolboxPatchNodesShort,
+		Long:    toolboxPatchNodesLong,
+		Example: toolboxPatchNodesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+			if options.ClusterName == "" {
+				exitWithError(fmt.Errorf("--name is required"))
+			}
+
+			err = RunToolboxPatchNodes(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Patch the nodes immediately, without --yes the nodes that would be patched are listed")
+	cmd.Flags().StringSliceVar(&options.InstanceGroups, "instance-group", options.InstanceGroups, "List of instance groups whose nodes are patched (defaults to all if not specified)")
+	cmd.MarkFlagCustom("instance-group", "__kops_get_instancegroups")
+	cmd.Flags().StringSliceVar(&options.InstanceGroupRoles, "instance-group-roles", options.InstanceGroupRoles, "If specified, only the nodes of instance groups of the specified role will be patched (e.g. Master,Node)")
+	cmd.Flags().StringVar(&options.SSHUser, "ssh-user", options.SSHUser, "User to connect to the nodes as")
+	cmd.Flags().StringVar(&options.SSHIdentity, "ssh-identity", options.SSHIdentity, "Private key to connect to the nodes with")
+	cmd.Flags().BoolVar(&options.InternalIP, "internal-ip", options.InternalIP, "Connect to the nodes by their internal IP, e.g. through a VPN, rather than their external IP")
+	cmd.Flags().DurationVar(&options.Interval, "interval", options.Interval, "Minimum time to wait after patching a node, before patching the next")
+	cmd.Flags().DurationVar(&options.PostDrainDelay, "post-drain-delay", options.PostDrainDelay, "Time to wait after draining a node, for pods to stabilize")
+	cmd.Flags().DurationVar(&options.RebootTimeout, "reboot-timeout", options.RebootTimeout, "Time to wait for a node to become ready after it is rebooted")
+	cmd.Flags().DurationVar(&options.ValidationTimeout, "validation-timeout", options.ValidationTimeout, "Time to wait for the cluster to validate after a node is patched")
+	cmd.Flags().BoolVar(&options.FailOnValidate, "fail-on-validate-error", options.FailOnValidate, "Stop patching if the cluster fails to validate")
+	cmd.Flags().StringSliceVar(&options.ValidateConditions, "validate-conditions", options.ValidateConditions, "Node conditions, other than Ready, that fail validation when true")
+
+	return cmd
+}
+
+func RunToolboxPatchNodes(f *util.Factory, out io.Writer, options *ToolboxPatchNodesOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	if len(options.InstanceGroups) != 0 {
+		var filtered []*api.InstanceGroup
+		for _, instanceGroupName := range options.InstanceGroups {
+			var found *api.InstanceGroup
+			for _, ig := range instanceGroups {
+				if ig.ObjectMeta.Name == instanceGroupName {
+					found = ig
+					break
+				}
+			}
+			if found == nil {
+				return fmt.Errorf("InstanceGroup %q not found", instanceGroupName)
+			}
+			filtered = append(filtered, found)
+		}
+		instanceGroups = filtered
+	}
+
+	if len(options.InstanceGroupRoles) != 0 {
+		var filtered []*api.InstanceGroup
+		for _, ig := range instanceGroups {
+			for _, role := range options.InstanceGroupRoles {
+				if ig.Spec.Role == api.InstanceGroupRole(strings.Title(strings.ToLower(role))) {
+					filtered = append(filtered, ig)
+					break
+				}
+			}
+		}
+		instanceGroups = filtered
+	}
+
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+	retrypolicy.ConfigureKubernetes(config)
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kube client for %q: %v", contextName, err)
+	}
+
+	if !options.Yes {
+		nodeList, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing nodes in cluster: %v", err)
+		}
+
+		groups := make(map[string]bool)
+		for _, ig := range instanceGroups {
+			groups[ig.ObjectMeta.Name] = true
+		}
+
+		fmt.Fprintf(out, "Nodes to patch:\n")
+		for _, node := range nodeList.Items {
+			ig := node.Labels[api.NodeLabelInstanceGroup]
+			if groups[ig] {
+				fmt.Fprintf(out, "  %s\t%s\n", node.Name, ig)
+			}
+		}
+		fmt.Fprintf(out, "\nMust specify --yes to patch the nodes.\n")
+		return nil
+	}
+
+	if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	sshConfig := ssh.ClientConfig{
+		User:            options.SSHUser,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	if err := kutil.AddSSHIdentity(&sshConfig, options.SSHIdentity); err != nil {
+		return fmt.Errorf("error reading SSH identity %q: %v", options.SSHIdentity, err)
+	}
+
+	p := &instancegroups.NodePatcher{
+		K8sClient:          k8sClient,
+		ClientConfig:       kutil.NewClientConfig(config, "kube-system"),
+		Interval:           options.Interval,
+		PostDrainDelay:     options.PostDrainDelay,
+		RebootTimeout:      options.RebootTimeout,
+		ValidationTimeout:  options.ValidationTimeout,
+		FailOnValidate:     options.FailOnValidate,
+		ValidateConditions: options.ValidateConditions,
+		Exec: func(node *v1.Node, command string) (string, error) {
+			return runNodeSSHCommand(node, sshConfig, options.InternalIP, command)
+		},
+	}
+
+	igList := &api.InstanceGroupList{}
+	for _, ig := range list.Items {
+		igList.Items = append(igList.Items, ig)
+	}
+
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationPatchNodes, "cluster", "")
+
+	return p.PatchNodes(cluster, igList, instanceGroups)
+}
+
+// runNodeSSHCommand runs a command on a node over a new SSH connection, as the node may have rebooted since the last command
+func runNodeSSHCommand(node *v1.Node, sshConfig ssh.ClientConfig, internalIP bool, command string) (string, error) {
+	address := nodeAddress(node, internalIP)
+	if address == "" {
+		return "", fmt.Errorf("node %q has no address to connect to", node.Name)
+	}
+
+	nodeSSH := &kutil.NodeSSH{
+		Hostname:  address,
+		SSHConfig: sshConfig,
+	}
+	sshClient, err := nodeSSH.GetSSHClient()
+	if err != nil {
+		return "", err
+	}
+	defer sshClient.Close()
+
+	s, err := sshClient.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("error creating ssh session: %v", err)
+	}
+	defer s.Close()
+
+	var output bytes.Buffer
+	s.Stdout = &output
+	s.Stderr = &output
+	if glog.V(2) {
+		s.Stdout = io.MultiWriter(&output, os.Stdout)
+		s.Stderr = io.MultiWriter(&output, os.Stderr)
+	}
+
+	err = s.Run(command)
+	return output.String(), err
+}
+
+// nodeAddress returns the address to connect to a node on, preferring its external IP unless internalIP is set
+func nodeAddress(node *v1.Node, internalIP bool) string {
+	var external, internal string
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case v1.NodeExternalIP:
+			if external == "" {
+				external = address.Address
+			}
+		case v1.NodeInternalIP:
+			if internal == "" {
+				internal = address.Address
+			}
+		}
+	}
+	if internalIP || external == "" {
+		return internal
+	}
+	return external
+}
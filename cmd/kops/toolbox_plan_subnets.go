@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/subnets"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxPlanSubnetsLong = templates.LongDesc(i18n.T(`
+	Preview the subnets, and their CIDRs, that kops would create for a cluster.
+
+	The subnets are laid out as by "kops create cluster": one subnet per zone, and for the private
+	topology a utility subnet per zone. CIDRs are assigned within the network CIDR so that they do not
+	overlap each other, the subnets already in an existing VPC, or any additional CIDRs given.`))
+
+	toolboxPlanSubnetsExample = templates.Examples(i18n.T(`
+	# Preview the subnets of a private topology across three zones
+	kops toolbox plan-subnets --zones us-east-1a,us-east-1b,us-east-1c --topology private
+
+	# Preview the subnets in an existing VPC, avoiding the subnets it already contains
+	kops toolbox plan-subnets --zones us-east-1a,us-east-1b --topology private --vpc vpc-12345678
+	`))
+
+	toolboxPlanSubnetsShort = i18n.T(`Preview the subnet layout of a cluster`)
+)
+
+type ToolboxPlanSubnetsOptions struct {
+	// NetworkCIDR is the CIDR of the cluster network; defaults to the CIDR of the VPC, or 172.20.0.0/16
+	NetworkCIDR string
+	Zones       []string
+	Topology    string
+
+	// VPCID is an existing AWS VPC, whose subnets are avoided
+	VPCID string
+	// ExistingCIDRs are additional CIDRs which must not be used
+	ExistingCIDRs []string
+}
+
+func (o *ToolboxPlanSubnetsOptions) InitDefaults() {
+	o.Topology = api.TopologyPublic
+}
+
+func NewCmdToolboxPlanSubnets(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxPlanSubnetsOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "plan-subnets",
+		Short:   toolboxPlanSubnetsShort,
+		Long:    toolboxPlanSubnetsLong,
+		Example: toolboxPlanSubnetsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := RunToolboxPlanSubnets(out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.Zones, "zones", options.Zones, "Zones in which to lay out subnets")
+	cmd.Flags().StringVar(&options.Topology, "topology", options.Topology, "Network topology: public or private")
+	cmd.Flags().StringVar(&options.NetworkCIDR, "network-cidr", options.NetworkCIDR, "Network CIDR of the cluster (defaults to the CIDR of --vpc, or 172.20.0.0/16)")
+	cmd.Flags().StringVar(&options.VPCID, "vpc", options.VPCID, "Existing AWS VPC in which to lay out subnets, avoiding its existing subnets")
+	cmd.Flags().StringSliceVar(&options.ExistingCIDRs, "existing-cidr", options.ExistingCIDRs, "Additional CIDRs which the subnets must not overlap")
+
+	return cmd
+}
+
+// RunToolboxPlanSubnets prints the planned subnets
+func RunToolboxPlanSubnets(out io.Writer, options *ToolboxPlanSubnetsOptions) error {
+	if len(options.Zones) == 0 {
+		return fmt.Errorf("--zones is required")
+	}
+
+	networkCIDR := options.NetworkCIDR
+
+	existing, err := subnets.ParseCIDRs(options.ExistingCIDRs)
+	if err != nil {
+		return err
+	}
+
+	if options.VPCID != "" {
+		vpcCIDR, vpcSubnets, err := findVPCSubnetCIDRs(options.VPCID, options.Zones[0])
+		if err != nil {
+			return err
+		}
+		if networkCIDR == "" {
+			networkCIDR = vpcCIDR
+		}
+		existing = append(existing, vpcSubnets...)
+	}
+
+	if networkCIDR == "" {
+		networkCIDR = "172.20.0.0/16"
+	}
+
+	planned, err := subnets.Plan(networkCIDR, options.Zones, options.Topology, existing)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Network CIDR: %s\n\n", networkCIDR)
+
+	t := &tables.Table{}
+	t.AddColumn("NAME", func(s *api.ClusterSubnetSpec) string {
+		return s.Name
+	})
+	t.AddColumn("TYPE", func(s *api.ClusterSubnetSpec) string {
+		return string(s.Type)
+	})
+	t.AddColumn("ZONE", func(s *api.ClusterSubnetSpec) string {
+		return s.Zone
+	})
+	t.AddColumn("CIDR", func(s *api.ClusterSubnetSpec) string {
+		return s.CIDR
+	})
+
+	var items []*api.ClusterSubnetSpec
+	for i := range planned {
+		items = append(items, &planned[i])
+	}
+	return t.Render(items, out, "NAME", "TYPE", "ZONE", "CIDR")
+}
+
+// findVPCSubnetCIDRs returns the CIDR of the AWS VPC, and the CIDRs of its subnets
+func findVPCSubnetCIDRs(vpcID string, zone string) (string, []*net.IPNet, error) {
+	if len(zone) < 2 {
+		return "", nil, fmt.Errorf("invalid zone %q", zone)
+	}
+	region := zone[:len(zone)-1]
+	awsCloud, err := awsup.NewAWSCloud(region, map[string]string{})
+	if err != nil {
+		return "", nil, fmt.Errorf("error loading cloud: %v", err)
+	}
+
+	vpcInfo, err := awsCloud.FindVPCInfo(vpcID)
+	if err != nil {
+		return "", nil, fmt.Errorf("error describing VPC: %v", err)
+	}
+	if vpcInfo == nil {
+		return "", nil, fmt.Errorf("VPC %q not found", vpcID)
+	}
+
+	var cidrs []string
+	for _, subnetInfo := range vpcInfo.Subnets {
+		if subnetInfo.CIDR != "" {
+			cidrs = append(cidrs, subnetInfo.CIDR)
+		}
+	}
+	existing, err := subnets.ParseCIDRs(cidrs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return vpcInfo.CIDR, existing, nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunToolboxPlanSubnets(t *testing.T) {
+	options := &ToolboxPlanSubnetsOptions{}
+	options.InitDefaults()
+	options.Zones = []string{"us-test-1a", "us-test-1b"}
+	options.Topology = "private"
+	options.ExistingCIDRs = []string{"172.20.32.0/24"}
+
+	var out bytes.Buffer
+	if err := RunToolboxPlanSubnets(&out, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Network CIDR: 172.20.0.0/16",
+		"us-test-1a\tPrivate\tus-test-1a\t172.20.64.0/19",
+		"utility-us-test-1b\tUtility\tus-test-1b\t172.20.4.0/22",
+	} {
+		actual := strings.Join(strings.Fields(out.String()), " ")
+		if !strings.Contains(actual, strings.Join(strings.Fields(expected), " ")) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	options.Zones = nil
+	if err := RunToolboxPlanSubnets(&out, options); err == nil {
+		t.Errorf("expected error when no zones are given")
+	}
+}
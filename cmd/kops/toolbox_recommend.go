@@ -0,0 +1,364 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/pkg/recommend"
+	"k8s.io/kops/pkg/retrypolicy"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/slice"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+	metrics "k8s.io/metrics/pkg/client/clientset_generated/clientset"
+)
+
+var (
+	toolboxRecommendLong = templates.LongDesc(i18n.T(`
+	Recommends machine type and size changes for the instance groups of a cluster, from the
+	utilization of their nodes.
+
+	The demand on each node is the greater of its measured usage and the requests of the pods
+	scheduled on it, for CPU and memory.  Usage is read from metrics-server, which only has
+	the current usage, or, with --prometheus, from Prometheus at a percentile over a window.
+
+	Instance groups above the high utilization threshold are recommended more nodes; those
+	below the low threshold are recommended fewer nodes, or a smaller machine type of the same
+	family (AWS only) when the minimum number of nodes is already needed.  Recommendations aim
+	for the target utilization.  Master and bastion instance groups are not resized.
+
+	With -o yaml, the recommended instance groups are output as manifests which can be applied
+	with kops replace -f, then kops update cluster.`))
+
+	toolboxRecommendExample = templates.Examples(i18n.T(`
+	# Recommend instance group changes from the current usage of the nodes
+	kops toolbox recommend --name k8s-cluster.example.com
+
+	# Use the 95th percentile of usage over the last week, from Prometheus
+	kops toolbox recommend --name k8s-cluster.example.com --prometheus http://localhost:9090
+
+	# Apply the recommendations
+	kops toolbox recommend --name k8s-cluster.example.com -o yaml > recommended.yaml
+	kops replace -f recommended.yaml
+	`))
+
+	toolboxRecommendShort = i18n.T(`Recommend instance group sizes from node utilization`)
+)
+
+type ToolboxRecommendOptions struct {
+	ClusterName string
+
+	// InstanceGroups limits the recommendations to these instance groups
+	InstanceGroups []string
+
+	// Prometheus is the URL of Prometheus to read usage from; if not set, metrics-server is used
+	Prometheus string
+	// PrometheusCPUQuery and PrometheusMemoryQuery return the usage of each node, in cores and bytes
+	PrometheusCPUQuery    string
+	PrometheusMemoryQuery string
+	// Window is how far back usage is read from Prometheus
+	Window time.Duration
+	// Percentile is the percentile of the usage over the window that is used
+	Percentile float64
+
+	Thresholds recommend.Options
+
+	Output string
+}
+
+func (o *ToolboxRecommendOptions) InitDefaults() {
+	o.PrometheusCPUQuery = recommend.DefaultPrometheusCPUQuery
+	o.PrometheusMemoryQuery = recommend.DefaultPrometheusMemoryQuery
+	o.Window = 7 * 24 * time.Hour
+	o.Percentile = 95
+	o.Thresholds.InitDefaults()
+	o.Output = OutputTable
+}
+
+func NewCmdToolboxRecommend(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxRecommendOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "recommend",
+		Short:   toolboxRecommendShort,
+		Long:    toolboxRecommendLong,
+		Example: toolboxRecommendExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunToolboxRecommend(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&options.InstanceGroups, "instance-group", options.InstanceGroups, "Only recommend changes to these instance groups")
+	cmd.MarkFlagCustom("instance-group", "__kops_get_instancegroups")
+	cmd.Flags().StringVar(&options.Prometheus, "prometheus", options.Prometheus, "URL of Prometheus to read node usage from, instead of metrics-server")
+	cmd.Flags().StringVar(&options.PrometheusCPUQuery, "prometheus-cpu-query", options.PrometheusCPUQuery, "Prometheus query for the CPU usage of each node in cores, labelled with node or instance")
+	cmd.Flags().StringVar(&options.PrometheusMemoryQuery, "prometheus-memory-query", options.PrometheusMemoryQuery, "Prometheus query for the memory usage of each node in bytes, labelled with node or instance")
+	cmd.Flags().DurationVar(&options.Window, "window", options.Window, "How far back to read node usage from Prometheus")
+	cmd.Flags().Float64Var(&options.Percentile, "percentile", options.Percentile, "Percentile of the node usage over the window to size for")
+	cmd.Flags().Float64Var(&options.Thresholds.Target, "target-utilization", options.Thresholds.Target, "Utilization that recommendations aim for")
+	cmd.Flags().Float64Var(&options.Thresholds.Low, "low-utilization", options.Thresholds.Low, "Utilization below which fewer or smaller nodes are recommended")
+	cmd.Flags().Float64Var(&options.Thresholds.High, "high-utilization", options.Thresholds.High, "Utilization above which more nodes are recommended")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "output format.  One of: table, yaml, json")
+
+	return cmd
+}
+
+func RunToolboxRecommend(f *util.Factory, out io.Writer, options *ToolboxRecommendOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+	t := options.Thresholds
+	if !(t.Low < t.Target && t.Target < t.High) || t.Low <= 0 || t.High > 1 {
+		return fmt.Errorf("utilization thresholds must be 0 < low < target < high <= 1")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		ig := &list.Items[i]
+		if len(options.InstanceGroups) != 0 && !slice.Contains(options.InstanceGroups, ig.ObjectMeta.Name) {
+			continue
+		}
+		instanceGroups = append(instanceGroups, ig)
+	}
+	if len(instanceGroups) == 0 {
+		return fmt.Errorf("no instance groups found")
+	}
+
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+	retrypolicy.ConfigureKubernetes(config)
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kube client for %q: %v", contextName, err)
+	}
+
+	nodes, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes: %v", err)
+	}
+	pods, err := k8sClient.CoreV1().Pods("").List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing pods: %v", err)
+	}
+
+	var cpuUsage, memoryUsage map[string]int64
+	if options.Prometheus != "" {
+		c := &recommend.PrometheusClient{URL: options.Prometheus}
+		cpuUsage, memoryUsage, err = c.NodeUsage(options.PrometheusCPUQuery, options.PrometheusMemoryQuery, options.Window, options.Percentile, time.Now())
+		if err != nil {
+			return err
+		}
+	} else {
+		metricsClient, err := metrics.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("cannot build metrics client for %q: %v", contextName, err)
+		}
+		nodeMetrics, err := metricsClient.MetricsV1beta1().NodeMetricses().List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error reading node metrics from metrics-server (use --prometheus if it isn't installed): %v", err)
+		}
+		cpuUsage = make(map[string]int64)
+		memoryUsage = make(map[string]int64)
+		for _, m := range nodeMetrics.Items {
+			cpuUsage[m.ObjectMeta.Name] = m.Usage.Cpu().MilliValue()
+			memoryUsage[m.ObjectMeta.Name] = m.Usage.Memory().Value()
+		}
+	}
+
+	usage := buildNodeUsage(nodes.Items, pods.Items, cpuUsage, memoryUsage)
+
+	var machineTypes []*recommend.MachineType
+	if api.CloudProviderID(cluster.Spec.CloudProvider) == api.CloudProviderAWS {
+		for _, m := range awsup.MachineTypes {
+			machineTypes = append(machineTypes, &recommend.MachineType{
+				Name:   m.Name,
+				CPU:    int64(m.Cores) * 1000,
+				Memory: int64(float64(m.MemoryGB) * 1024 * 1024 * 1024),
+			})
+		}
+	}
+
+	var recommendations []*recommend.Recommendation
+	recommended := make(map[string]*api.InstanceGroup)
+	for _, ig := range instanceGroups {
+		r := recommend.Recommend(ig, usage[ig.ObjectMeta.Name], machineTypes, &options.Thresholds)
+		recommendations = append(recommendations, r)
+		if r.HasChanges() {
+			recommended[ig.ObjectMeta.Name] = r.Apply(ig)
+		}
+	}
+
+	switch options.Output {
+	case OutputTable:
+		t := &tables.Table{}
+		t.AddColumn("INSTANCEGROUP", func(r *recommend.Recommendation) string {
+			return r.InstanceGroup
+		})
+		t.AddColumn("MACHINETYPE", func(r *recommend.Recommendation) string {
+			if r.NewMachineType != "" {
+				return r.MachineType + " -> " + r.NewMachineType
+			}
+			return r.MachineType
+		})
+		t.AddColumn("NODES", func(r *recommend.Recommendation) string {
+			return fmt.Sprintf("%d", r.Nodes)
+		})
+		t.AddColumn("MIN", func(r *recommend.Recommendation) string {
+			return formatRecommendedSize(r.MinSize, r.NewMinSize)
+		})
+		t.AddColumn("MAX", func(r *recommend.Recommendation) string {
+			return formatRecommendedSize(r.MaxSize, r.NewMaxSize)
+		})
+		t.AddColumn("CPU", func(r *recommend.Recommendation) string {
+			return fmt.Sprintf("%.0f%%", r.CPUUtilization*100)
+		})
+		t.AddColumn("MEMORY", func(r *recommend.Recommendation) string {
+			return fmt.Sprintf("%.0f%%", r.MemoryUtilization*100)
+		})
+		t.AddColumn("REASON", func(r *recommend.Recommendation) string {
+			return r.Reason
+		})
+		if err := t.Render(recommendations, out, "INSTANCEGROUP", "MACHINETYPE", "NODES", "MIN", "MAX", "CPU", "MEMORY", "REASON"); err != nil {
+			return err
+		}
+		if options.Prometheus == "" {
+			fmt.Fprintf(out, "\nUsage is the current usage from metrics-server; use --prometheus to size for usage over time.\n")
+		}
+		return nil
+
+	case OutputYaml:
+		var manifests []string
+		for _, r := range recommendations {
+			ig := recommended[r.InstanceGroup]
+			if ig == nil {
+				continue
+			}
+			b, err := kopscodecs.ToVersionedYaml(ig)
+			if err != nil {
+				return fmt.Errorf("error marshaling instance group %q: %v", ig.ObjectMeta.Name, err)
+			}
+			manifests = append(manifests, string(b))
+		}
+		if len(manifests) == 0 {
+			return nil
+		}
+		_, err := io.WriteString(out, strings.Join(manifests, "\n---\n\n"))
+		return err
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(recommendations, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling recommendations to json: %v", err)
+		}
+		_, err = out.Write(append(b, '\n'))
+		return err
+
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+}
+
+// buildNodeUsage returns the usage of the nodes, keyed by the instance group they belong to
+func buildNodeUsage(nodes []v1.Node, pods []v1.Pod, cpuUsage map[string]int64, memoryUsage map[string]int64) map[string][]*recommend.NodeUsage {
+	byName := make(map[string]*recommend.NodeUsage)
+	usage := make(map[string][]*recommend.NodeUsage)
+	for i := range nodes {
+		node := &nodes[i]
+		ig := node.ObjectMeta.Labels[api.NodeLabelInstanceGroup]
+		if ig == "" {
+			continue
+		}
+		n := &recommend.NodeUsage{
+			Name:              node.ObjectMeta.Name,
+			InstanceGroup:     ig,
+			CPUAllocatable:    node.Status.Allocatable.Cpu().MilliValue(),
+			MemoryAllocatable: node.Status.Allocatable.Memory().Value(),
+			CPUUsage:          cpuUsage[node.ObjectMeta.Name],
+			MemoryUsage:       memoryUsage[node.ObjectMeta.Name],
+		}
+		byName[n.Name] = n
+		usage[ig] = append(usage[ig], n)
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		n := byName[pod.Spec.NodeName]
+		if n == nil {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			n.CPURequests += c.Resources.Requests.Cpu().MilliValue()
+			n.MemoryRequests += c.Resources.Requests.Memory().Value()
+		}
+	}
+
+	return usage
+}
+
+// formatRecommendedSize returns the size, showing the recommended size if it differs
+func formatRecommendedSize(current int32, recommended *int32) string {
+	if recommended == nil {
+		return fmt.Sprintf("%d", current)
+	}
+	return fmt.Sprintf("%d -> %d", current, *recommended)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+func TestBuildNodeUsage(t *testing.T) {
+	node := func(name string, ig string) v1.Node {
+		n := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		if ig != "" {
+			n.ObjectMeta.Labels[api.NodeLabelInstanceGroup] = ig
+		}
+		n.Status.Allocatable = v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("3800m"),
+			v1.ResourceMemory: resource.MustParse("15Gi"),
+		}
+		return n
+	}
+	pod := func(nodeName string, phase v1.PodPhase, cpu string, memory string) v1.Pod {
+		p := v1.Pod{}
+		p.Spec.NodeName = nodeName
+		p.Status.Phase = phase
+		p.Spec.Containers = []v1.Container{{
+			Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}}
+		return p
+	}
+
+	nodes := []v1.Node{node("a", "nodes"), node("b", "nodes"), node("m", "master-us-east-1a"), node("x", "")}
+	pods := []v1.Pod{
+		pod("a", v1.PodRunning, "500m", "1Gi"),
+		pod("a", v1.PodRunning, "250m", "1Gi"),
+		pod("a", v1.PodSucceeded, "1", "1Gi"),
+		pod("x", v1.PodRunning, "1", "1Gi"),
+	}
+
+	usage := buildNodeUsage(nodes, pods, map[string]int64{"a": 1200, "b": 100}, map[string]int64{"a": 1 << 30})
+	if len(usage) != 2 || len(usage["nodes"]) != 2 || len(usage["master-us-east-1a"]) != 1 {
+		t.Fatalf("unexpected grouping of nodes: %v", usage)
+	}
+
+	a := usage["nodes"][0]
+	if a.Name != "a" || a.CPUAllocatable != 3800 || a.MemoryAllocatable != 15<<30 {
+		t.Errorf("unexpected capacity of node a: %+v", a)
+	}
+	if a.CPUUsage != 1200 || a.MemoryUsage != 1<<30 {
+		t.Errorf("unexpected usage of node a: %+v", a)
+	}
+	if a.CPURequests != 750 || a.MemoryRequests != 2<<30 {
+		t.Errorf("unexpected requests of node a: %+v", a)
+	}
+	if b := usage["nodes"][1]; b.CPUUsage != 100 || b.CPURequests != 0 {
+		t.Errorf("unexpected usage of node b: %+v", b)
+	}
+}
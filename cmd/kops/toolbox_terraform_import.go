@@ -0,0 +1,176 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxTerraformImportLong = templates.LongDesc(i18n.T(`
+	Generate the terraform import commands that adopt the cloud resources of an existing cluster
+	into terraform state.
+
+	The commands use the resource addresses of the output of "kops update cluster --target=terraform",
+	so that after running them in the terraform output directory, "terraform plan" shows no changes
+	for the imported resources. Resources that exist but cannot be imported are listed as comments.`))
+
+	toolboxTerraformImportExample = templates.Examples(i18n.T(`
+	# Generate the terraform configuration and the import commands for a cluster
+	kops update cluster --name k8s-cluster.example.com --target=terraform --out=.
+	kops toolbox terraform-import --name k8s-cluster.example.com > import.sh
+	`))
+
+	toolboxTerraformImportShort = i18n.T(`Generate terraform import commands for an existing cluster`)
+)
+
+type ToolboxTerraformImportOptions struct {
+	ClusterName string
+}
+
+func NewCmdToolboxTerraformImport(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxTerraformImportOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "terraform-import",
+		Short:   toolboxTerraformImportShort,
+		Long:    toolboxTerraformImportLong,
+		Example: toolboxTerraformImportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunToolboxTerraformImport(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// RunToolboxTerraformImport prints the terraform import commands for the existing resources of a cluster
+func RunToolboxTerraformImport(f *util.Factory, out io.Writer, options *ToolboxTerraformImportOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	instanceGroups, err := commands.ReadAllInstanceGroups(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	// A dry-run finds the existing resources for each task, without making any changes
+	applyCmd := &cloudup.ApplyClusterCmd{
+		Clientset:      clientset,
+		Cluster:        cluster,
+		DryRun:         true,
+		InstanceGroups: instanceGroups,
+		Models:         cloudup.CloudupModels,
+		TargetName:     cloudup.TargetDryRun,
+		DryRunOut:      ioutil.Discard,
+	}
+	if err := applyCmd.Run(); err != nil {
+		return err
+	}
+
+	target, ok := applyCmd.Target.(*fi.DryRunTarget)
+	if !ok {
+		return fmt.Errorf("unexpected target type %T", applyCmd.Target)
+	}
+
+	imports, skipped := buildTerraformImports(applyCmd.TaskMap, target.Existing)
+
+	fmt.Fprintf(out, "#!/bin/sh\n")
+	fmt.Fprintf(out, "# terraform import commands for cluster %s\n", cluster.ObjectMeta.Name)
+	fmt.Fprintf(out, "set -e\n\n")
+	for _, c := range imports {
+		fmt.Fprintf(out, "%s\n", c)
+	}
+	if len(skipped) != 0 {
+		fmt.Fprintf(out, "\n# The following resources exist, but cannot be imported automatically:\n")
+		for _, key := range skipped {
+			fmt.Fprintf(out, "#   %s\n", key)
+		}
+	}
+
+	return nil
+}
+
+// buildTerraformImports returns the import commands for the tasks that were found, ordered by terraform address,
+// and the names of the found tasks that are rendered to terraform but cannot be imported
+func buildTerraformImports(taskMap map[string]fi.Task, existing func(fi.Task) fi.Task) ([]*terraform.ImportCommand, []string) {
+	var keys []string
+	for key := range taskMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var imports []*terraform.ImportCommand
+	var skipped []string
+	for _, key := range keys {
+		e := taskMap[key]
+		actual := existing(e)
+		if actual == nil {
+			continue
+		}
+
+		importable, ok := e.(terraform.Importable)
+		if !ok {
+			if _, rendered := reflect.TypeOf(e).MethodByName("RenderTerraform"); rendered {
+				skipped = append(skipped, key)
+			}
+			continue
+		}
+
+		if c := importable.TerraformImport(actual); c != nil {
+			imports = append(imports, c)
+		}
+	}
+
+	sort.SliceStable(imports, func(i, j int) bool {
+		return imports[i].Address() < imports[j].Address()
+	})
+
+	return imports, skipped
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+)
+
+func TestBuildTerraformImports(t *testing.T) {
+	vpc := &awstasks.VPC{Name: fi.String("minimal.example.com")}
+	subnet := &awstasks.Subnet{Name: fi.String("us-test-1a.minimal.example.com")}
+	missing := &awstasks.Subnet{Name: fi.String("us-test-1b.minimal.example.com")}
+	rule := &awstasks.SecurityGroupRule{Name: fi.String("all-master-to-master")}
+
+	taskMap := map[string]fi.Task{
+		"VPC/minimal.example.com":                vpc,
+		"Subnet/us-test-1a.minimal.example.com":  subnet,
+		"Subnet/us-test-1b.minimal.example.com":  missing,
+		"SecurityGroupRule/all-master-to-master": rule,
+	}
+
+	existing := map[fi.Task]fi.Task{
+		vpc:    &awstasks.VPC{Name: fi.String("minimal.example.com"), ID: fi.String("vpc-12345678")},
+		subnet: &awstasks.Subnet{Name: fi.String("us-test-1a.minimal.example.com"), ID: fi.String("subnet-12345678")},
+		rule:   &awstasks.SecurityGroupRule{Name: fi.String("all-master-to-master")},
+	}
+
+	commands, skipped := buildTerraformImports(taskMap, func(e fi.Task) fi.Task {
+		return existing[e]
+	})
+
+	expected := []string{
+		"terraform import aws_subnet.us-test-1a-minimal-example-com subnet-12345678",
+		"terraform import aws_vpc.minimal-example-com vpc-12345678",
+	}
+	if len(commands) != len(expected) {
+		t.Fatalf("unexpected commands %v", commands)
+	}
+	for i := range expected {
+		if commands[i].String() != expected[i] {
+			t.Errorf("unexpected command %q, expected %q", commands[i], expected[i])
+		}
+	}
+
+	if len(skipped) != 1 || skipped[0] != "SecurityGroupRule/all-master-to-master" {
+		t.Errorf("unexpected skipped resources %v", skipped)
+	}
+}
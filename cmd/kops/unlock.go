@@ -0,0 +1,123 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	unlockLong = templates.LongDesc(i18n.T(`
+	Unlock a cluster locked with "kops lock".`))
+
+	unlockExample = templates.Examples(i18n.T(`
+	# Unlock a cluster
+	kops unlock cluster k8s-cluster.example.com
+	`))
+
+	unlockShort = i18n.T(`Unlock a cluster.`)
+
+	unlockClusterLong = templates.LongDesc(i18n.T(`
+	Unlock a cluster locked with "kops lock cluster", so that other operators can change it again.
+
+	Only the user holding the lock can unlock the cluster, unless --force is used; for example
+	when the holder is not available.`))
+
+	unlockClusterExample = templates.Examples(i18n.T(`
+	# Unlock a cluster
+	kops unlock cluster k8s-cluster.example.com
+
+	# Unlock a cluster locked by another user
+	kops unlock cluster k8s-cluster.example.com --force
+	`))
+
+	unlockClusterShort = i18n.T(`Unlock a cluster.`)
+)
+
+func NewCmdUnlock(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "unlock",
+		Short:   unlockShort,
+		Long:    unlockLong,
+		Example: unlockExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdUnlockCluster(f, out))
+
+	return cmd
+}
+
+type UnlockClusterOptions struct {
+	ClusterName string
+	Force       bool
+}
+
+func NewCmdUnlockCluster(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &UnlockClusterOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   unlockClusterShort,
+		Long:    unlockClusterLong,
+		Example: unlockClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := rootCommand.ProcessArgs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			if err := RunUnlockCluster(f, out, options); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.Force, "force", options.Force, "Unlock the cluster even if it is locked by another user")
+
+	return cmd
+}
+
+func RunUnlockCluster(f *util.Factory, out io.Writer, options *UnlockClusterOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	locker, err := f.ClusterLocker()
+	if err != nil {
+		return err
+	}
+	if locker == nil {
+		return fmt.Errorf("locking is not supported for this state store")
+	}
+
+	if err := locker.Unlock(options.ClusterName, audit.CurrentUser(), options.Force); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Cluster %q unlocked\n", options.ClusterName)
+	return nil
+}
@@ -23,18 +23,24 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/cmd/kops/util"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/approval"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/policy"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
 	"k8s.io/kops/upup/pkg/fi/utils"
 	"k8s.io/kops/upup/pkg/kutil"
+	"k8s.io/kops/util/pkg/vfs"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
 )
@@ -51,6 +57,21 @@ var (
 	updateClusterExample = templates.Examples(i18n.T(`
 	# After cluster has been edited or upgraded, configure it with:
 	kops update cluster k8s-cluster.example.com --yes --state=s3://kops-state-1234 --yes
+
+	# Preview changes, caching cloud discovery results for repeated runs (e.g. in CI)
+	kops update cluster k8s-cluster.example.com --discovery-cache-ttl=10m
+
+	# Write the terraform configuration, reporting where it differs from the current terraform state
+	kops update cluster k8s-cluster.example.com --target=terraform --out=. --diff-against-state=terraform.tfstate
+
+	# Preview changes to every cluster labelled env=staging
+	kops update cluster --cluster-selector env=staging
+
+	# Apply changes, showing the instance launches, load balancer health and kubernetes events as they happen
+	kops update cluster k8s-cluster.example.com --yes --events
+
+	# Apply changes only if they are allowed by the operator's policies
+	kops update cluster k8s-cluster.example.com --yes --policy policies/cluster.rego --policy https://policy.example.com/kops
 	`))
 
 	updateClusterShort = i18n.T("Update a cluster.")
@@ -70,6 +91,27 @@ type UpdateClusterOptions struct {
 	// LifecycleOverrides is a slice of taskName=lifecycle name values.  This slice is used
 	// to populate the LifecycleOverrides struct member in ApplyClusterCmd struct.
 	LifecycleOverrides []string
+
+	// DiscoveryCacheTTL is how long read-only cloud API results are cached on disk; zero disables the cache
+	DiscoveryCacheTTL time.Duration
+
+	// Refresh ignores any cached cloud discovery results
+	Refresh bool
+
+	// Policies are rego files or webhook URLs that must allow the update before it is applied
+	Policies []string
+
+	// DiffAgainstState is the path of a terraform.tfstate file to compare with the generated terraform configuration
+	DiffAgainstState string
+
+	// Batch selects multiple clusters to update
+	Batch BatchOptions
+
+	// Events streams the cloud and kubernetes events of the cluster while the changes are applied
+	Events bool
+
+	// QuotaCheck fails the update before any changes are made if the cloud quotas do not leave room for the cluster
+	QuotaCheck bool
 }
 
 func (o *UpdateClusterOptions) InitDefaults() {
@@ -79,7 +121,9 @@ func (o *UpdateClusterOptions) InitDefaults() {
 	o.SSHPublicKey = ""
 	o.OutDir = ""
 	o.CreateKubecfg = true
+	o.QuotaCheck = true
 	o.RunTasksOptions.InitDefaults()
+	o.Batch.InitDefaults()
 }
 
 func NewCmdUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
@@ -92,6 +136,13 @@ func NewCmdUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		Long:    updateClusterLong,
 		Example: updateClusterExample,
 		Run: func(cmd *cobra.Command, args []string) {
+			if options.Batch.IsSet() {
+				if err := RunUpdateClusters(f, args, out, options); err != nil {
+					exitWithError(err)
+				}
+				return
+			}
+
 			err := rootCommand.ProcessArgs(args)
 			if err != nil {
 				exitWithError(err)
@@ -110,13 +161,35 @@ func NewCmdUpdateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&options.Models, "model", options.Models, "Models to apply (separate multiple models with commas)")
 	cmd.Flags().StringVar(&options.SSHPublicKey, "ssh-public-key", options.SSHPublicKey, "SSH public key to use (deprecated: use kops create secret instead)")
 	cmd.Flags().StringVar(&options.OutDir, "out", options.OutDir, "Path to write any local output")
+	cmd.Flags().StringVar(&options.DiffAgainstState, "diff-against-state", options.DiffAgainstState, "With --target=terraform, report the generated resources that differ from this terraform.tfstate file")
 	cmd.Flags().BoolVar(&options.CreateKubecfg, "create-kube-config", options.CreateKubecfg, "Will control automatically creating the kube config file on your local filesystem")
 	cmd.Flags().StringVar(&options.Phase, "phase", options.Phase, "Subset of tasks to run: "+strings.Join(cloudup.Phases.List(), ", "))
 	cmd.Flags().StringSliceVar(&options.LifecycleOverrides, "lifecycle-overrides", options.LifecycleOverrides, "comma separated list of phase overrides, example: SecurityGroups=Ignore,InternetGateway=ExistsAndWarnIfChanges")
+	cmd.Flags().DurationVar(&options.DiscoveryCacheTTL, "discovery-cache-ttl", options.DiscoveryCacheTTL, "Cache read-only cloud API results on disk for this long, to speed up repeated invocations (0 disables the cache)")
+	cmd.Flags().BoolVar(&options.Refresh, "refresh", options.Refresh, "Ignore any cached cloud discovery results")
+	options.Batch.AddFlags(cmd)
+	cmd.Flags().BoolVar(&options.QuotaCheck, "quota-check", options.QuotaCheck, "Check that the cloud quotas leave room for the instance groups at their maximum size before making any changes")
+	cmd.Flags().BoolVar(&options.Events, "events", options.Events, "Show cloud events (e.g. instance launches and load balancer health) and kubernetes events (e.g. node registrations and pod evictions) while applying changes")
+	cmd.Flags().StringSliceVar(&options.Policies, "policy", options.Policies, "Policy that must allow the update: a .rego file (evaluated with opa) or a webhook URL; may be repeated")
 
 	return cmd
 }
 
+// RunUpdateClusters updates each of the clusters selected by the batch options.
+// The kubeconfig is not updated, as it would otherwise end up pointing at whichever cluster finished last.
+func RunUpdateClusters(f *util.Factory, args []string, out io.Writer, c *UpdateClusterOptions) error {
+	if c.DiscoveryCacheTTL != 0 {
+		return fmt.Errorf("--discovery-cache-ttl cannot be used when updating multiple clusters")
+	}
+
+	return runBatch(f, args, out, &c.Batch, func(clusterName string, out io.Writer) error {
+		options := *c
+		options.CreateKubecfg = false
+		_, err := RunUpdateCluster(f, clusterName, out, &options)
+		return err
+	})
+}
+
 type UpdateClusterResults struct {
 	// Target is the fi.Target we will operated against.  This can be used to get dryrun results (primarily for tests)
 	Target fi.Target
@@ -131,6 +204,10 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 	isDryrun := false
 	targetName := c.Target
 
+	if c.DiffAgainstState != "" && c.Target != cloudup.TargetTerraform {
+		return nil, fmt.Errorf("--diff-against-state can only be used with --target=%s", cloudup.TargetTerraform)
+	}
+
 	// direct requires --yes (others do not, because they don't do anything!)
 	if c.Target == cloudup.TargetDirect {
 		if !c.Yes {
@@ -158,6 +235,12 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 		return results, err
 	}
 
+	if !isDryrun {
+		if err := commands.CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+			return results, err
+		}
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return results, err
@@ -240,6 +323,42 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 		}
 	}
 
+	if !isDryrun {
+		if err := policy.Enforce(c.Policies, policy.OperationUpdateCluster, cluster, instanceGroups); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := configureDiscoveryCache(cluster.ObjectMeta.Name, c.DiscoveryCacheTTL, c.Refresh); err != nil {
+		return nil, err
+	}
+
+	// If the cluster requires approval and no plan has been approved, do a dry-run and record it as a plan
+	var gate *approvalGate
+	var dryRunReport bytes.Buffer
+	var dryRunOut io.Writer
+	if !isDryrun && c.Target == cloudup.TargetDirect {
+		gate, err = newApprovalGate(f, cluster, instanceGroups, approval.OperationUpdateCluster)
+		if err != nil {
+			return nil, err
+		}
+		if gate != nil && !gate.Approved() {
+			isDryrun = true
+			targetName = cloudup.TargetDryRun
+			dryRunOut = io.MultiWriter(out, &dryRunReport)
+		}
+	}
+
+	if c.QuotaCheck && !isDryrun && c.Target == cloudup.TargetDirect {
+		cloud, err := cloudup.BuildCloud(cluster)
+		if err != nil {
+			return results, err
+		}
+		if err := verifyQuotas(cloud, cluster, instanceGroups, nil); err != nil {
+			return results, err
+		}
+	}
+
 	applyCmd := &cloudup.ApplyClusterCmd{
 		Clientset:          clientset,
 		Cluster:            cluster,
@@ -251,6 +370,18 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 		Phase:              phase,
 		TargetName:         targetName,
 		LifecycleOverrides: lifecycleOverrideMap,
+		DryRunOut:          dryRunOut,
+		Out:                out,
+	}
+
+	if c.Events && !isDryrun && c.Target == cloudup.TargetDirect {
+		cloud, err := cloudup.BuildCloud(cluster)
+		if err != nil {
+			return results, err
+		}
+		events := newEventStream(out, cloud, cluster, instanceGroups, buildEventsKubernetesClient(cluster))
+		events.Start()
+		defer events.Stop()
 	}
 
 	if err := applyCmd.Run(); err != nil {
@@ -260,6 +391,24 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 	results.Target = applyCmd.Target
 	results.TaskMap = applyCmd.TaskMap
 
+	if c.DiffAgainstState != "" {
+		if err := diffTerraformState(out, applyCmd.Target, c.DiffAgainstState); err != nil {
+			return results, err
+		}
+	}
+
+	if gate != nil && !gate.Approved() {
+		target := applyCmd.Target.(*fi.DryRunTarget)
+		if !target.HasChanges() {
+			fmt.Fprintf(out, "No changes need to be applied\n")
+			return results, nil
+		}
+		if err := gate.Request(out, cluster.ObjectMeta.Name, dryRunReport.String()); err != nil {
+			return results, err
+		}
+		return results, nil
+	}
+
 	if isDryrun {
 		target := applyCmd.Target.(*fi.DryRunTarget)
 		if target.HasChanges() {
@@ -270,6 +419,26 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 		return results, nil
 	}
 
+	if gate != nil {
+		if err := gate.Executed(); err != nil {
+			return results, err
+		}
+	}
+
+	commands.RecordAudit(f, cluster.ObjectMeta.Name, audit.OperationUpdate, "cluster", "")
+
+	// Bare metal hosts are enrolled by kops over SSH, rather than joining the cluster when they boot
+	if c.Target == cloudup.TargetDirect && kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderBareMetal {
+		cloud, err := cloudup.BuildCloud(cluster)
+		if err != nil {
+			return results, err
+		}
+		configureBareMetalCloud(cloud, clientset, cluster)
+		if err := imageNewBareMetalHosts(out, cloud, instanceGroups); err != nil {
+			return results, err
+		}
+	}
+
 	firstRun := false
 
 	if !isDryrun && c.CreateKubecfg {
@@ -292,7 +461,7 @@ func RunUpdateCluster(f *util.Factory, clusterName string, out io.Writer, c *Upd
 			if err != nil {
 				return nil, err
 			}
-			err = conf.WriteKubecfg()
+			err = conf.WriteKubecfg(out)
 			if err != nil {
 				return nil, err
 			}
@@ -417,3 +586,24 @@ func hasKubecfg(contextName string) (bool, error) {
 	}
 	return false, nil
 }
+
+// diffTerraformState reports the differences between the generated terraform configuration and a terraform state file
+func diffTerraformState(out io.Writer, target fi.Target, statePath string) error {
+	tf, ok := target.(*terraform.TerraformTarget)
+	if !ok {
+		return fmt.Errorf("unexpected target type %T", target)
+	}
+
+	stateData, err := vfs.Context.ReadFile(statePath)
+	if err != nil {
+		return fmt.Errorf("error reading terraform state %q: %v", statePath, err)
+	}
+
+	diff, err := tf.DiffState(stateData)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nComparing with terraform state %s\n", statePath)
+	return diff.Print(out)
+}
@@ -28,6 +28,7 @@ import (
 	"k8s.io/kops"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
@@ -273,6 +274,16 @@ func (c *UpgradeClusterCmd) Run(args []string) error {
 		fmt.Printf("\nMust specify --yes to perform upgrade\n")
 		return nil
 	} else {
+		if err := commands.CheckClusterLock(rootCommand.factory, cluster.ObjectMeta.Name); err != nil {
+			return err
+		}
+
+		oldCluster := cluster.DeepCopy()
+		oldGroups := make(map[string]*api.InstanceGroup)
+		for _, g := range instanceGroups {
+			oldGroups[g.ObjectMeta.Name] = g.DeepCopy()
+		}
+
 		for _, action := range actions {
 			action.apply()
 		}
@@ -280,12 +291,14 @@ func (c *UpgradeClusterCmd) Run(args []string) error {
 		if err := commands.UpdateCluster(clientset, cluster, instanceGroups); err != nil {
 			return err
 		}
+		commands.RecordAuditChange(rootCommand.factory, cluster.ObjectMeta.Name, audit.OperationUpgrade, "cluster", oldCluster, cluster)
 
 		for _, g := range instanceGroups {
 			_, err := clientset.InstanceGroupsFor(cluster).Update(g)
 			if err != nil {
 				return fmt.Errorf("error writing InstanceGroup %q: %v", g.ObjectMeta.Name, err)
 			}
+			commands.RecordAuditChange(rootCommand.factory, cluster.ObjectMeta.Name, audit.OperationUpgrade, "instancegroup/"+g.ObjectMeta.Name, oldGroups[g.ObjectMeta.Name], g)
 		}
 
 		fmt.Printf("\nUpdates applied to configuration.\n")
@@ -26,10 +26,16 @@ import (
 	"k8s.io/client-go/rest"
 	gceacls "k8s.io/kops/pkg/acls/gce"
 	s3acls "k8s.io/kops/pkg/acls/s3"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/approval"
+	"k8s.io/kops/pkg/audit"
 	kopsclient "k8s.io/kops/pkg/client/clientset_generated/clientset"
 	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/client/simple/api"
 	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/clusterlock"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -118,3 +124,99 @@ func (f *Factory) Clientset() (simple.Clientset, error) {
 
 	return f.clientset, nil
 }
+
+// AuditLog returns the audit log kept in the state store, or nil if the state store doesn't keep one
+func (f *Factory) AuditLog() (*audit.Log, error) {
+	registryPath := f.options.RegistryPath
+	if registryPath == "" {
+		return nil, field.Required(field.NewPath("State Store"), STATE_ERROR)
+	}
+
+	// The kops API server is responsible for its own auditing
+	if strings.HasPrefix(registryPath, "k8s://") {
+		return nil, nil
+	}
+
+	basePath, err := vfs.Context.BuildVfsPath(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error building path for %q: %v", registryPath, err)
+	}
+	return audit.NewLog(basePath), nil
+}
+
+// ClusterLocker returns the lock records of the state store, or nil if the state store doesn't support locking
+func (f *Factory) ClusterLocker() (*clusterlock.Locker, error) {
+	registryPath := f.options.RegistryPath
+	if registryPath == "" {
+		return nil, field.Required(field.NewPath("State Store"), STATE_ERROR)
+	}
+
+	if strings.HasPrefix(registryPath, "k8s://") {
+		return nil, nil
+	}
+
+	basePath, err := vfs.Context.BuildVfsPath(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error building path for %q: %v", registryPath, err)
+	}
+	return clusterlock.NewLocker(basePath), nil
+}
+
+// approvalSecretName is the name of the secret used to sign the plans of a cluster
+const approvalSecretName = "kops-approval"
+
+// ApprovalStore returns the store of plans awaiting approval for the cluster
+func (f *Factory) ApprovalStore(cluster *kops.Cluster) (*approval.Store, error) {
+	registryPath := f.options.RegistryPath
+	if registryPath == "" {
+		return nil, field.Required(field.NewPath("State Store"), STATE_ERROR)
+	}
+	if strings.HasPrefix(registryPath, "k8s://") {
+		return nil, fmt.Errorf("approvals are not supported with state store %q", registryPath)
+	}
+
+	basePath, err := vfs.Context.BuildVfsPath(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error building path for %q: %v", registryPath, err)
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return nil, err
+	}
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := fi.CreateSecret()
+	if err != nil {
+		return nil, err
+	}
+	secret, _, err = secretStore.GetOrCreateSecret(approvalSecretName, secret)
+	if err != nil {
+		return nil, fmt.Errorf("error reading approval signing secret: %v", err)
+	}
+
+	identity, err := approvalIdentity(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return approval.NewStore(basePath, cluster.ObjectMeta.Name, secret.Data, identity), nil
+}
+
+// approvalIdentity returns how the users requesting and approving plans are identified.  On AWS they are the IAM
+// principals of their credentials, proven to STS; elsewhere they are identified by name, and approvals are advisory,
+// as anyone able to write the state store could record an approval in any name.
+func approvalIdentity(cluster *kops.Cluster) (approval.Identity, error) {
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderAWS {
+		region, err := awsup.FindRegion(cluster)
+		if err != nil {
+			return nil, err
+		}
+		return approval.NewAWSIdentity(region)
+	}
+
+	glog.Warningf("approvals of %s clusters are advisory: the approving user is identified by name, which is not verified", cluster.Spec.CloudProvider)
+	return &approval.UserIdentity{Name: audit.CurrentUser()}, nil
+}
@@ -33,13 +33,26 @@ var (
 	2. All k8s nodes are running and have "Ready" status.
 	3. Componentstatues returns healthy for all components.
 	4. All pods in the kube-system namespace are running and healthy.
+
+	With --cis, the configuration kops renders for the apiserver, controller-manager, etcd and
+	kubelets is instead checked against the CIS Kubernetes Benchmark, reporting pass or fail for
+	each control.  This reads only the state store, so it can be run before an update as well as after.
 	`))
 
 	validateExample = templates.Examples(i18n.T(`
 	# Validate a cluster.
 	# This command uses the currently selected kops cluster as
 	# set by the kubectl config.
-	kops validate cluster`))
+	kops validate cluster
+
+	# Validate every production cluster in the state store, four at a time.
+	kops validate cluster --cluster-glob '*.prod.example.com' --parallel 4
+
+	# Check the cluster's configuration against the CIS Kubernetes Benchmark.
+	kops validate cluster --name k8s-cluster.example.com --cis
+
+	# Validate a cluster manifest offline, e.g. in a pre-commit hook.
+	kops validate manifest -f cluster.yaml`))
 
 	validateShort = i18n.T(`Validate a kops cluster.`)
 )
@@ -54,6 +67,7 @@ func NewCmdValidate(f *util.Factory, out io.Writer) *cobra.Command {
 
 	// create subcommands
 	cmd.AddCommand(NewCmdValidateCluster(f, out))
+	cmd.AddCommand(NewCmdValidateManifest(f, out))
 
 	return cmd
 }
@@ -33,7 +33,11 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/cis"
+	"k8s.io/kops/pkg/retrypolicy"
 	"k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
 	"k8s.io/kops/util/pkg/tables"
 )
 
@@ -46,10 +50,25 @@ func init() {
 
 type ValidateClusterOptions struct {
 	output string
+
+	// conditions are the node conditions, other than Ready, that fail validation when they are true
+	conditions []string
+
+	// podsNamespace and podsSelectors select additional pods which must be ready
+	podsNamespace string
+	podsSelectors []string
+
+	// cis checks the rendered component configuration against the CIS Kubernetes Benchmark, rather than validating the running cluster
+	cis bool
+
+	// Batch selects multiple clusters to validate
+	Batch BatchOptions
 }
 
 func (o *ValidateClusterOptions) InitDefaults() {
 	o.output = OutputTable
+	o.conditions = validation.DefaultNodeConditions
+	o.Batch.InitDefaults()
 }
 
 func NewCmdValidateCluster(f *util.Factory, out io.Writer) *cobra.Command {
@@ -62,6 +81,24 @@ func NewCmdValidateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 		Long:    validateLong,
 		Example: validateExample,
 		Run: func(cmd *cobra.Command, args []string) {
+			if options.Batch.IsSet() {
+				if err := RunValidateClusters(f, args, os.Stdout, options); err != nil {
+					exitWithError(err)
+				}
+				return
+			}
+
+			if options.cis {
+				failed, err := RunValidateClusterCIS(f, args, os.Stdout, options)
+				if err != nil {
+					exitWithError(err)
+				}
+				if len(failed) != 0 {
+					os.Exit(2)
+				}
+				return
+			}
+
 			result, err := RunValidateCluster(f, cmd, args, os.Stdout, options)
 			if err != nil {
 				exitWithError(err)
@@ -75,6 +112,11 @@ func NewCmdValidateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of json|yaml|table.")
+	cmd.Flags().StringArrayVar(&options.podsSelectors, "validate-pods-selector", options.podsSelectors, "Label selector for pods, in addition to kube-system pods, which must be ready for validation to pass; may be repeated")
+	cmd.Flags().StringVar(&options.podsNamespace, "validate-pods-namespace", options.podsNamespace, "Namespace of the pods selected by --validate-pods-selector (defaults to all namespaces)")
+	cmd.Flags().StringSliceVar(&options.conditions, "validate-conditions", options.conditions, "Node conditions, other than Ready, that fail validation when true; e.g. add KernelDeadlock to check node-problem-detector conditions")
+	cmd.Flags().BoolVar(&options.cis, "cis", options.cis, "Check the rendered configuration of the cluster components against the CIS Kubernetes Benchmark, instead of validating the running cluster")
+	options.Batch.AddFlags(cmd)
 
 	return cmd
 }
@@ -90,6 +132,42 @@ func RunValidateCluster(f *util.Factory, cmd *cobra.Command, args []string, out
 		return nil, err
 	}
 
+	return validateCluster(f, cluster, out, options)
+}
+
+// RunValidateClusters validates each of the clusters selected by the batch options
+func RunValidateClusters(f *util.Factory, args []string, out io.Writer, options *ValidateClusterOptions) error {
+	if options.output != OutputTable {
+		return fmt.Errorf("only table output is supported when validating multiple clusters")
+	}
+
+	return runBatch(f, args, out, &options.Batch, func(clusterName string, out io.Writer) error {
+		cluster, err := GetCluster(f, clusterName)
+		if err != nil {
+			return err
+		}
+		if options.cis {
+			failed, err := validateClusterCIS(f, cluster, out, options)
+			if err != nil {
+				return err
+			}
+			if len(failed) != 0 {
+				return fmt.Errorf("cluster failed %d CIS benchmark controls", len(failed))
+			}
+			return nil
+		}
+		result, err := validateCluster(f, cluster, out, options)
+		if err != nil {
+			return err
+		}
+		if len(result.Failures) != 0 {
+			return fmt.Errorf("cluster did not validate: %d failures", len(result.Failures))
+		}
+		return nil
+	})
+}
+
+func validateCluster(f *util.Factory, cluster *api.Cluster, out io.Writer, options *ValidateClusterOptions) (*validation.ValidationCluster, error) {
 	clientSet, err := f.Clientset()
 	if err != nil {
 		return nil, err
@@ -122,13 +200,18 @@ func RunValidateCluster(f *util.Factory, cmd *cobra.Command, args []string, out
 	if err != nil {
 		return nil, fmt.Errorf("Cannot load kubecfg settings for %q: %v", contextName, err)
 	}
+	retrypolicy.ConfigureKubernetes(config)
 
 	k8sClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot build kubernetes api client for %q: %v", contextName, err)
 	}
 
-	result, err := validation.ValidateCluster(cluster, list, k8sClient)
+	result, err := validation.ValidateClusterWithOptions(cluster, list, k8sClient, &validation.ValidationOptions{
+		NodeConditions: options.conditions,
+		PodNamespace:   options.podsNamespace,
+		PodSelectors:   options.podsSelectors,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error during validation: %v", err)
 	}
@@ -164,6 +247,109 @@ func RunValidateCluster(f *util.Factory, cmd *cobra.Command, args []string, out
 	return result, nil
 }
 
+// RunValidateClusterCIS checks the cluster against the CIS Kubernetes Benchmark, returning the failed controls
+func RunValidateClusterCIS(f *util.Factory, args []string, out io.Writer, options *ValidateClusterOptions) ([]*cis.Result, error) {
+	err := rootCommand.ProcessArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := rootCommand.Cluster()
+	if err != nil {
+		return nil, err
+	}
+
+	return validateClusterCIS(f, cluster, out, options)
+}
+
+func validateClusterCIS(f *util.Factory, cluster *api.Cluster, out io.Writer, options *ValidateClusterOptions) ([]*cis.Result, error) {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get InstanceGroups for %q: %v", cluster.ObjectMeta.Name, err)
+	}
+	var instanceGroups []*api.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	assetBuilder := assets.NewAssetBuilder(cluster, "")
+	fullCluster, err := cloudup.PopulateClusterSpec(clientset, cluster, assetBuilder)
+	if err != nil {
+		return nil, fmt.Errorf("error computing full cluster spec for %q: %v", cluster.ObjectMeta.Name, err)
+	}
+
+	results, err := cis.CheckCluster(fullCluster, instanceGroups)
+	if err != nil {
+		return nil, err
+	}
+	failed := cis.Failed(results)
+
+	switch options.output {
+	case OutputTable:
+		fmt.Fprintf(out, "Checking cluster %v against the CIS Kubernetes Benchmark\n\n", cluster.ObjectMeta.Name)
+
+		t := &tables.Table{}
+		t.AddColumn("ID", func(r *cis.Result) string {
+			return r.ID
+		})
+		t.AddColumn("COMPONENT", func(r *cis.Result) string {
+			return r.Component
+		})
+		t.AddColumn("TARGET", func(r *cis.Result) string {
+			return r.Target
+		})
+		t.AddColumn("STATUS", func(r *cis.Result) string {
+			return r.Status
+		})
+		t.AddColumn("DESCRIPTION", func(r *cis.Result) string {
+			return r.Description
+		})
+		t.AddColumn("REMEDIATION", func(r *cis.Result) string {
+			return r.Remediation
+		})
+		if err := t.Render(results, out, "ID", "COMPONENT", "TARGET", "STATUS", "DESCRIPTION"); err != nil {
+			return nil, fmt.Errorf("error rendering results table: %v", err)
+		}
+
+		if len(failed) != 0 {
+			fmt.Fprintln(out, "\nREMEDIATIONS")
+			if err := t.Render(failed, out, "ID", "COMPONENT", "TARGET", "REMEDIATION"); err != nil {
+				return nil, fmt.Errorf("error rendering remediations table: %v", err)
+			}
+		}
+
+		fmt.Fprintf(out, "\n%d of %d controls passed\n", len(results)-len(failed), len(results))
+
+	case OutputYaml:
+		y, err := yaml.Marshal(results)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return nil, fmt.Errorf("error writing to output: %v", err)
+		}
+
+	case OutputJSON:
+		j, err := json.Marshal(results)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := out.Write(j); err != nil {
+			return nil, fmt.Errorf("error writing to output: %v", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("Unknown output format: %q", options.output)
+	}
+
+	return failed, nil
+}
+
 func validateClusterOutputTable(result *validation.ValidationCluster, cluster *api.Cluster, instanceGroups []api.InstanceGroup, out io.Writer) error {
 	t := &tables.Table{}
 	t.AddColumn("NAME", func(c api.InstanceGroup) string {
@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	validateManifestLong = templates.LongDesc(i18n.T(`
+	Validate Cluster and InstanceGroup manifests, without contacting the cloud or the state store.
+
+	Documents are converted from their API version, and validated individually and against each other;
+	for example instance groups must reference subnets defined in their cluster.  This is suitable
+	for pre-commit hooks and CI.
+	`))
+
+	validateManifestExample = templates.Examples(i18n.T(`
+	# Validate a cluster manifest, as produced by kops get cluster -o yaml
+	kops validate manifest -f cluster.yaml
+
+	# Validate a cluster and its instance groups defined in separate files
+	kops validate manifest -f cluster.yaml -f instancegroups.yaml
+	`))
+
+	validateManifestShort = i18n.T(`Validate cluster manifests offline.`)
+)
+
+type ValidateManifestOptions struct {
+	Filenames []string
+}
+
+func NewCmdValidateManifest(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ValidateManifestOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "manifest -f FILENAME",
+		Short:   validateManifestShort,
+		Long:    validateManifestLong,
+		Example: validateManifestExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := RunValidateManifest(out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+			// As with validate cluster, exit non-zero if validation found a problem
+			if len(result.Problems) != 0 {
+				os.Exit(2)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&options.Filenames, "filename", "f", options.Filenames, "Filename of the manifest to validate (- for stdin)")
+	cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func RunValidateManifest(out io.Writer, options *ValidateManifestOptions) (*commands.ManifestValidation, error) {
+	if len(options.Filenames) == 0 {
+		return nil, fmt.Errorf("must specify at least one manifest with -f")
+	}
+
+	result := &commands.ManifestValidation{}
+	for _, f := range options.Filenames {
+		var contents []byte
+		var err error
+		if f == "-" {
+			contents, err = ConsumeStdin()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			contents, err = vfs.Context.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("error reading file %q: %v", f, err)
+			}
+		}
+		result.DecodeManifest(f, contents)
+	}
+
+	result.Validate()
+
+	if len(result.Problems) == 0 {
+		fmt.Fprintf(out, "Manifest is valid: %d objects validated\n", len(result.Objects))
+		return result, nil
+	}
+
+	t := &tables.Table{}
+	t.AddColumn("SOURCE", func(p *commands.ManifestProblem) string {
+		return p.Source
+	})
+	t.AddColumn("KIND", func(p *commands.ManifestProblem) string {
+		return p.Kind
+	})
+	t.AddColumn("NAME", func(p *commands.ManifestProblem) string {
+		return p.Name
+	})
+	t.AddColumn("MESSAGE", func(p *commands.ManifestProblem) string {
+		return p.Message
+	})
+	if err := t.Render(result.Problems, out, "SOURCE", "KIND", "NAME", "MESSAGE"); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "\nManifest is not valid: found %d problems\n", len(result.Problems))
+
+	return result, nil
+}
@@ -38,8 +38,10 @@ import (
 	"k8s.io/kops/dns-controller/pkg/watchers"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/cloudflare"
 	k8scoredns "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/coredns"
 	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/google/clouddns"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/infoblox"
 	_ "k8s.io/kops/pkg/resources/digitalocean/dns"
 	"k8s.io/kops/protokube/pkg/gossip"
 	gossipdns "k8s.io/kops/protokube/pkg/gossip/dns"
@@ -55,8 +57,8 @@ var (
 func main() {
 	fmt.Printf("dns-controller version %s\n", BuildVersion)
 	var dnsServer, dnsProviderID, gossipListen, gossipSecret, watchNamespace, metricsListen string
-	var gossipSeeds, zones []string
-	var watchIngress bool
+	var gossipSeeds, zones, dnsConfig []string
+	var watchIngress, preferPrivateZones bool
 	var updateInterval int
 
 	// Be sure to get the glog flags
@@ -66,7 +68,9 @@ func main() {
 	flags.BoolVar(&watchIngress, "watch-ingress", true, "Configure hostnames found in ingress resources")
 	flags.StringSliceVar(&gossipSeeds, "gossip-seed", gossipSeeds, "If set, will enable gossip zones and seed using the provided addresses")
 	flags.StringSliceVarP(&zones, "zone", "z", []string{}, "Configure permitted zones and their mappings")
-	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, digitalocean, coredns, gossip)")
+	flags.BoolVar(&preferPrivateZones, "prefer-private-zones", preferPrivateZones, "If a public and a private zone have the same name, manage the private zone")
+	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, digitalocean, coredns, cloudflare, infoblox, gossip)")
+	flags.StringSliceVar(&dnsConfig, "dns-config", dnsConfig, "Configuration for the DNS provider, as key=value pairs")
 	flags.StringVar(&gossipListen, "gossip-listen", "0.0.0.0:3998", "The address on which to listen if gossip is enabled")
 	flags.StringVar(&gossipSecret, "gossip-secret", gossipSecret, "Secret to use to secure gossip")
 	flags.StringVar(&watchNamespace, "watch-namespace", "", "Limits the functionality for pods, services and ingress to specific namespace, by default all")
@@ -93,6 +97,7 @@ func main() {
 		glog.Errorf("unexpected zone flags: %q", err)
 		os.Exit(1)
 	}
+	zoneRules.PreferPrivate = preferPrivateZones
 
 	config, err := rest.InClusterConfig()
 	if err != nil {
@@ -108,7 +113,13 @@ func main() {
 	var dnsProviders []dnsprovider.Interface
 	if dnsProviderID != "gossip" {
 		var file io.Reader
-		if dnsProviderID == k8scoredns.ProviderName {
+		if len(dnsConfig) != 0 {
+			file, err = dns.BuildProviderConfig(dnsConfig)
+			if err != nil {
+				glog.Errorf("Error parsing DNS provider config: %v", err)
+				os.Exit(1)
+			}
+		} else if dnsProviderID == k8scoredns.ProviderName {
 			var lines []string
 			lines = append(lines, "etcd-endpoints = "+dnsServer)
 			lines = append(lines, "zones = "+zones[0])
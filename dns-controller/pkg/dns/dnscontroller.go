@@ -357,6 +357,10 @@ func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache) (*dnsOp, error) {
 			matches = append(matches, zones...)
 		}
 
+		if len(matches) > 1 && zoneRules.PreferPrivate {
+			matches = filterPrivateZones(matches)
+		}
+
 		if len(matches) == 1 {
 			zoneMap[name] = matches[0]
 		} else if len(matches) > 1 {
@@ -381,6 +385,20 @@ func EnsureDotSuffix(s string) string {
 	return s
 }
 
+// filterPrivateZones returns the private zones, if there are any; otherwise it returns all the zones
+func filterPrivateZones(zones []dnsprovider.Zone) []dnsprovider.Zone {
+	var private []dnsprovider.Zone
+	for _, zone := range zones {
+		if p, ok := zone.(dnsprovider.PrivateZone); ok && p.IsPrivate() {
+			private = append(private, zone)
+		}
+	}
+	if len(private) == 0 {
+		return zones
+	}
+	return private
+}
+
 func (o *dnsOp) findZone(fqdn string) dnsprovider.Zone {
 	zoneName := EnsureDotSuffix(fqdn)
 	for {
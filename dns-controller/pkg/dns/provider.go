@@ -0,0 +1,38 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BuildProviderConfig builds the configuration file for a DNS provider from key=value pairs, as passed with --dns-config
+func BuildProviderConfig(values []string) (io.Reader, error) {
+	var lines []string
+	for _, v := range values {
+		tokens := strings.SplitN(v, "=", 2)
+		if len(tokens) != 2 || strings.TrimSpace(tokens[0]) == "" {
+			return nil, fmt.Errorf("DNS provider config %q must be of the form key=value", v)
+		}
+		lines = append(lines, strings.TrimSpace(tokens[0])+" = "+strings.TrimSpace(tokens[1]))
+	}
+	config := "[global]\n" + strings.Join(lines, "\n") + "\n"
+	return bytes.NewReader([]byte(config)), nil
+}
@@ -51,6 +51,9 @@ type ZoneRules struct {
 	// We don't use a map so we can support e.g. *.example.com later
 	Zones    []*ZoneSpec
 	Wildcard bool
+
+	// PreferPrivate selects the private zone when both a public and a private zone match a name (split-horizon DNS)
+	PreferPrivate bool
 }
 
 func ParseZoneRules(zones []string) (*ZoneRules, error) {
@@ -19,6 +19,8 @@ package dns
 import (
 	"reflect"
 	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 )
 
 func TestEnsureDotSuffix(t *testing.T) {
@@ -156,3 +158,30 @@ func TestParseZoneRules(t *testing.T) {
 // 		}
 // 	}
 // }
+
+type fakeZone struct {
+	id      string
+	private bool
+}
+
+var _ dnsprovider.PrivateZone = &fakeZone{}
+
+func (z *fakeZone) Name() string                                               { return "example.com." }
+func (z *fakeZone) ID() string                                                 { return z.id }
+func (z *fakeZone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) { return nil, false }
+func (z *fakeZone) IsPrivate() bool                                            { return z.private }
+
+func TestFilterPrivateZones(t *testing.T) {
+	public := &fakeZone{id: "public"}
+	private := &fakeZone{id: "private", private: true}
+
+	actual := filterPrivateZones([]dnsprovider.Zone{public, private})
+	if len(actual) != 1 || actual[0].ID() != "private" {
+		t.Errorf("expected only the private zone, got %v", actual)
+	}
+
+	actual = filterPrivateZones([]dnsprovider.Zone{public})
+	if len(actual) != 1 || actual[0].ID() != "public" {
+		t.Errorf("expected public zone when there is no private zone, got %v", actual)
+	}
+}
@@ -49,6 +49,12 @@ type Zone interface {
 	ResourceRecordSets() (ResourceRecordSets, bool)
 }
 
+// PrivateZone is implemented by zones that may be private, i.e. only resolvable from within a network such as a VPC
+type PrivateZone interface {
+	// IsPrivate returns true if the zone is private
+	IsPrivate() bool
+}
+
 type ResourceRecordSets interface {
 	// List returns the ResourceRecordSets of the Zone, or an error if the list operation failed.
 	List() ([]ResourceRecordSet, error)
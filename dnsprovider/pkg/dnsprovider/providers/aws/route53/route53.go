@@ -18,13 +18,21 @@ limitations under the License.
 package route53
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/golang/glog"
+	"gopkg.in/gcfg.v1"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 )
 
@@ -32,6 +40,21 @@ const (
 	ProviderName = "aws-route53"
 )
 
+// Config to override defaults
+type Config struct {
+	Global struct {
+		// AssumeRoleARN is an IAM role that is assumed for the Route53 API calls, e.g. when the hosted zone is
+		// in another account
+		AssumeRoleARN string `gcfg:"assume-role-arn"`
+		// AssumeRoleExternalID is the external id required by the trust policy of the role
+		AssumeRoleExternalID string `gcfg:"assume-role-external-id"`
+		// AssumeRoleSessionName is the name of the role session
+		AssumeRoleSessionName string `gcfg:"assume-role-session-name"`
+		// AssumeRoleSessionTags are the tags of the role session, as key=value; may be repeated
+		AssumeRoleSessionTags []string `gcfg:"assume-role-session-tag"`
+	}
+}
+
 // MaxBatchSize is used to limit the max size of resource record changesets
 var MaxBatchSize = 900
 
@@ -55,7 +78,12 @@ func route53HandlerLogger(req *request.Request) {
 
 // newRoute53 creates a new instance of an AWS Route53 DNS Interface.
 func newRoute53(config io.Reader) (*Interface, error) {
-	// Connect to AWS Route53 - TODO: Do more sophisticated auth
+	var cfg Config
+	if config != nil {
+		if err := gcfg.ReadInto(&cfg, config); err != nil {
+			return nil, fmt.Errorf("error reading Route53 config: %v", err)
+		}
+	}
 
 	awsConfig := aws.NewConfig()
 
@@ -63,7 +91,23 @@ func newRoute53(config io.Reader) (*Interface, error) {
 	// e.g. https://github.com/kubernetes/kops/issues/605
 	awsConfig = awsConfig.WithCredentialsChainVerboseErrors(true)
 
-	svc := route53.New(session.New(), awsConfig)
+	sess := session.New(awsConfig)
+
+	if cfg.Global.AssumeRoleARN != "" {
+		client, err := newSessionTagsAssumeRoler(sess, cfg.Global.AssumeRoleSessionTags)
+		if err != nil {
+			return nil, err
+		}
+		glog.Infof("Using role %q for Route53", cfg.Global.AssumeRoleARN)
+		awsConfig = awsConfig.Copy().WithCredentials(stscreds.NewCredentialsWithClient(client, cfg.Global.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if cfg.Global.AssumeRoleExternalID != "" {
+				p.ExternalID = aws.String(cfg.Global.AssumeRoleExternalID)
+			}
+			p.RoleSessionName = cfg.Global.AssumeRoleSessionName
+		}))
+	}
+
+	svc := route53.New(sess, awsConfig)
 
 	// Add our handler that will log requests
 	svc.Handlers.Sign.PushFrontNamed(request.NamedHandler{
@@ -73,3 +117,69 @@ func newRoute53(config io.Reader) (*Interface, error) {
 
 	return New(svc), nil
 }
+
+// sessionTagsAssumeRoler assumes roles with session tags. The vendored aws-sdk-go predates session tags, so they
+// are added to the query parameters of the request.
+type sessionTagsAssumeRoler struct {
+	client *sts.STS
+	tags   url.Values
+}
+
+var _ stscreds.AssumeRoler = &sessionTagsAssumeRoler{}
+
+// newSessionTagsAssumeRoler parses the session tags, given as key=value strings
+func newSessionTagsAssumeRoler(sess *session.Session, tags []string) (*sessionTagsAssumeRoler, error) {
+	var keys []string
+	values := make(map[string]string)
+	for _, tag := range tags {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("session tag %q must be of the form key=value", tag)
+		}
+		if _, found := values[kv[0]]; !found {
+			keys = append(keys, kv[0])
+		}
+		values[kv[0]] = kv[1]
+	}
+	sort.Strings(keys)
+
+	r := &sessionTagsAssumeRoler{
+		client: sts.New(sess),
+		tags:   url.Values{},
+	}
+	for i, k := range keys {
+		r.tags.Set(fmt.Sprintf("Tags.member.%d.Key", i+1), k)
+		r.tags.Set(fmt.Sprintf("Tags.member.%d.Value", i+1), values[k])
+	}
+	return r, nil
+}
+
+func (r *sessionTagsAssumeRoler) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	req, out := r.client.AssumeRoleRequest(input)
+	if len(r.tags) != 0 {
+		req.Handlers.Build.PushBack(func(req *request.Request) {
+			if req.Error != nil {
+				return
+			}
+			if _, err := req.Body.Seek(0, 0); err != nil {
+				req.Error = err
+				return
+			}
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				req.Error = err
+				return
+			}
+			values, err := url.ParseQuery(string(body))
+			if err != nil {
+				req.Error = err
+				return
+			}
+			for k, v := range r.tags {
+				values[k] = v
+			}
+			req.SetBufferBody([]byte(values.Encode()))
+		})
+	}
+	return out, req.Send()
+}
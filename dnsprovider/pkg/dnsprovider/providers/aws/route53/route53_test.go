@@ -20,6 +20,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
 )
@@ -293,3 +295,27 @@ func TestResourceRecordSetsDifferentTypes(t *testing.T) {
 	zone := firstZone(t)
 	tests.CommonTestResourceRecordSetsDifferentTypes(t, zone)
 }
+
+func TestNewRoute53AssumeRole(t *testing.T) {
+	config := "[global]\nassume-role-arn = arn:aws:iam::123456789012:role/dns\nassume-role-session-tag = cluster=example.com\n"
+	if _, err := newRoute53(strings.NewReader(config)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config = "[global]\nassume-role-arn = arn:aws:iam::123456789012:role/dns\nassume-role-session-tag = cluster\n"
+	if _, err := newRoute53(strings.NewReader(config)); err == nil {
+		t.Fatalf("expected an error for a session tag without a value")
+	}
+}
+
+func TestSessionTagsAssumeRoler(t *testing.T) {
+	r, err := newSessionTagsAssumeRoler(session.New(), []string{"team=platform", "cluster=example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Tags.member.1.Key=cluster&Tags.member.1.Value=example.com&Tags.member.2.Key=team&Tags.member.2.Value=platform"
+	if actual := r.tags.Encode(); actual != expected {
+		t.Errorf("unexpected tags: expected %q, got %q", expected, actual)
+	}
+}
@@ -42,6 +42,11 @@ func (zone *Zone) ID() string {
 	return id
 }
 
+// IsPrivate implements dnsprovider.PrivateZone
+func (zone *Zone) IsPrivate() bool {
+	return zone.impl.Config != nil && aws.BoolValue(zone.impl.Config.PrivateZone)
+}
+
 func (zone *Zone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) {
 	return &ResourceRecordSets{zone}, true
 }
@@ -0,0 +1,224 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/recordapi"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+const (
+	// minTTL is the smallest TTL CloudFlare accepts on all plans; 1 means "automatic"
+	minTTL = 120
+
+	pageSize = 100
+)
+
+// api implements recordapi.API using the CloudFlare v4 API
+type api struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+var _ recordapi.API = &api{}
+var _ recordapi.TTLNormalizer = &api{}
+
+func newAPI(endpoint string, token string) *api {
+	return &api{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// response is the envelope for all CloudFlare API responses
+type response struct {
+	Success    bool            `json:"success"`
+	Errors     []responseError `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo *resultInfo     `json:"result_info"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type resultInfo struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+}
+
+type zone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type dnsRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int64  `json:"ttl"`
+}
+
+func (a *api) do(method string, path string, query url.Values, body interface{}) (*response, error) {
+	u := a.endpoint + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling CloudFlare API %s %s: %v", method, path, err)
+	}
+	defer httpResponse.Body.Close()
+
+	data, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CloudFlare API response: %v", err)
+	}
+
+	r := &response{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("error parsing CloudFlare API response (status %d): %v", httpResponse.StatusCode, err)
+	}
+	if !r.Success {
+		var messages []string
+		for _, e := range r.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return nil, fmt.Errorf("CloudFlare API %s %s failed (status %d): %s", method, path, httpResponse.StatusCode, strings.Join(messages, "; "))
+	}
+	return r, nil
+}
+
+// list fetches every page of results, passing each page to fn
+func (a *api) list(path string, query url.Values, fn func(result json.RawMessage) error) error {
+	for page := 1; ; page++ {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(pageSize))
+
+		r, err := a.do(http.MethodGet, path, q, nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(r.Result); err != nil {
+			return fmt.Errorf("error parsing CloudFlare API response: %v", err)
+		}
+		if r.ResultInfo == nil || r.ResultInfo.Page >= r.ResultInfo.TotalPages {
+			return nil
+		}
+	}
+}
+
+func (a *api) ListZones() ([]*recordapi.ZoneInfo, error) {
+	var zones []*recordapi.ZoneInfo
+	err := a.list("/zones", nil, func(result json.RawMessage) error {
+		var page []zone
+		if err := json.Unmarshal(result, &page); err != nil {
+			return err
+		}
+		for _, z := range page {
+			zones = append(zones, &recordapi.ZoneInfo{ID: z.ID, Name: z.Name})
+		}
+		return nil
+	})
+	return zones, err
+}
+
+func (a *api) ListRecords(z *recordapi.ZoneInfo, name string) ([]*recordapi.Record, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+
+	var records []*recordapi.Record
+	err := a.list("/zones/"+z.ID+"/dns_records", query, func(result json.RawMessage) error {
+		var page []dnsRecord
+		if err := json.Unmarshal(result, &page); err != nil {
+			return err
+		}
+		for _, r := range page {
+			records = append(records, &recordapi.Record{
+				ID:    r.ID,
+				Name:  r.Name,
+				Type:  rrstype.RrsType(r.Type),
+				Value: r.Content,
+				TTL:   r.TTL,
+			})
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (a *api) CreateRecord(z *recordapi.ZoneInfo, record *recordapi.Record) error {
+	request := &dnsRecord{
+		Type:    string(record.Type),
+		Name:    record.Name,
+		Content: strings.TrimSuffix(record.Value, "."),
+		TTL:     record.TTL,
+	}
+	_, err := a.do(http.MethodPost, "/zones/"+z.ID+"/dns_records", nil, request)
+	return err
+}
+
+// NormalizeTTL implements recordapi.TTLNormalizer
+func (a *api) NormalizeTTL(ttl int64) int64 {
+	if ttl < minTTL {
+		return minTTL
+	}
+	return ttl
+}
+
+func (a *api) DeleteRecord(z *recordapi.ZoneInfo, record *recordapi.Record) error {
+	_, err := a.do(http.MethodDelete, "/zones/"+z.ID+"/dns_records/"+record.ID, nil, nil)
+	return err
+}
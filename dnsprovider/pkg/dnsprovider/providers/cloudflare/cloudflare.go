@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudflare is the implementation of pkg/dnsprovider interface for CloudFlare
+package cloudflare
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/glog"
+	"gopkg.in/gcfg.v1"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/recordapi"
+)
+
+const (
+	// ProviderName is the name of the CloudFlare DNS provider
+	ProviderName = "cloudflare"
+
+	// APITokenEnvVar is the environment variable from which the API token is read, if not set in the config
+	APITokenEnvVar = "CLOUDFLARE_API_TOKEN"
+
+	defaultEndpoint = "https://api.cloudflare.com/client/v4"
+)
+
+// Config to override defaults
+type Config struct {
+	Global struct {
+		// APIToken is a CloudFlare API token, with permission to edit DNS records in the zones
+		APIToken string `gcfg:"api-token"`
+		// Endpoint overrides the CloudFlare API endpoint
+		Endpoint string `gcfg:"endpoint"`
+	}
+}
+
+func init() {
+	dnsprovider.RegisterDnsProvider(ProviderName, func(config io.Reader) (dnsprovider.Interface, error) {
+		return newCloudFlareProviderInterface(config)
+	})
+}
+
+// newCloudFlareProviderInterface creates a new instance of a CloudFlare DNS Interface.
+func newCloudFlareProviderInterface(config io.Reader) (dnsprovider.Interface, error) {
+	var cfg Config
+	if config != nil {
+		if err := gcfg.ReadInto(&cfg, config); err != nil {
+			return nil, fmt.Errorf("error reading CloudFlare config: %v", err)
+		}
+	}
+
+	token := cfg.Global.APIToken
+	if token == "" {
+		token = os.Getenv(APITokenEnvVar)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("CloudFlare API token not found; set %s", APITokenEnvVar)
+	}
+
+	endpoint := cfg.Global.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	glog.Infof("Using CloudFlare DNS provider")
+	return recordapi.New(newAPI(endpoint, token)), nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// fakeCloudFlare serves a minimal subset of the CloudFlare v4 API
+type fakeCloudFlare struct {
+	t       *testing.T
+	records []dnsRecord
+	nextID  int
+}
+
+func (f *fakeCloudFlare) reply(w http.ResponseWriter, result interface{}, info *resultInfo) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		f.t.Fatalf("error serializing result: %v", err)
+	}
+	json.NewEncoder(w).Encode(&response{Success: true, Result: data, ResultInfo: info})
+}
+
+func (f *fakeCloudFlare) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer secret-token" {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(&response{Errors: []responseError{{Code: 9109, Message: "Invalid access token"}}})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones":
+		// Return one zone per page, to check we follow pagination
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		zones := []zone{{ID: "z1", Name: "example.com"}, {ID: "z2", Name: "test.com"}}
+		f.reply(w, []zone{zones[page-1]}, &resultInfo{Page: page, TotalPages: len(zones)})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/zones/z2/dns_records":
+		var matches []dnsRecord
+		for _, record := range f.records {
+			if name := r.URL.Query().Get("name"); name == "" || name == record.Name {
+				matches = append(matches, record)
+			}
+		}
+		f.reply(w, matches, &resultInfo{Page: 1, TotalPages: 1})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/zones/z2/dns_records":
+		record := dnsRecord{}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			f.t.Fatalf("error parsing request: %v", err)
+		}
+		f.nextID++
+		record.ID = fmt.Sprintf("r%d", f.nextID)
+		f.records = append(f.records, record)
+		f.reply(w, record, nil)
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/zones/z2/dns_records/"):
+		id := strings.TrimPrefix(r.URL.Path, "/zones/z2/dns_records/")
+		var kept []dnsRecord
+		for _, record := range f.records {
+			if record.ID != id {
+				kept = append(kept, record)
+			}
+		}
+		f.records = kept
+		f.reply(w, map[string]string{"id": id}, nil)
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCloudFlare(t *testing.T) {
+	fake := &fakeCloudFlare{t: t}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	intf, err := dnsprovider.GetDnsProvider(ProviderName, strings.NewReader("[global]\napi-token = secret-token\nendpoint = "+server.URL+"\n"))
+	if err != nil {
+		t.Fatalf("error building provider: %v", err)
+	}
+
+	zones, _ := intf.Zones()
+	zoneList, err := zones.List()
+	if err != nil {
+		t.Fatalf("error listing zones: %v", err)
+	}
+	if len(zoneList) != 2 {
+		t.Fatalf("expected 2 zones, got %d", len(zoneList))
+	}
+	zone := zoneList[1]
+	if zone.Name() != "test.com" || zone.ID() != "z2" {
+		t.Fatalf("unexpected zone %s/%s", zone.Name(), zone.ID())
+	}
+
+	rrsets, _ := zone.ResourceRecordSets()
+	rrset := rrsets.New("api.test.com.", []string{"10.0.0.1", "10.0.0.2"}, 60, rrstype.A)
+	if err := rrsets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("error creating records: %v", err)
+	}
+	if len(fake.records) != 2 || fake.records[0].TTL != minTTL {
+		t.Fatalf("unexpected records %v", fake.records)
+	}
+
+	// Repeating the upsert should not make any changes
+	if err := rrsets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("error repeating upsert: %v", err)
+	}
+	if fake.nextID != 2 {
+		t.Fatalf("expected unchanged records not to be recreated, records %v", fake.records)
+	}
+
+	found, err := rrsets.Get("api.test.com.")
+	if err != nil {
+		t.Fatalf("error getting records: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Rrdatas()) != 2 {
+		t.Fatalf("unexpected records %v", found)
+	}
+
+	if err := rrsets.StartChangeset().Remove(found[0]).Apply(); err != nil {
+		t.Fatalf("error removing records: %v", err)
+	}
+	if len(fake.records) != 0 {
+		t.Fatalf("expected records to be removed, found %v", fake.records)
+	}
+}
+
+func TestCloudFlare_InvalidToken(t *testing.T) {
+	server := httptest.NewServer(&fakeCloudFlare{t: t})
+	defer server.Close()
+
+	intf, err := dnsprovider.GetDnsProvider(ProviderName, strings.NewReader("[global]\napi-token = wrong\nendpoint = "+server.URL+"\n"))
+	if err != nil {
+		t.Fatalf("error building provider: %v", err)
+	}
+	zones, _ := intf.Zones()
+	_, err = zones.List()
+	if err == nil || !strings.Contains(err.Error(), "Invalid access token") {
+		t.Fatalf("expected invalid token error, got %v", err)
+	}
+}
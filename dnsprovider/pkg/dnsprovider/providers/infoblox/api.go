@@ -0,0 +1,249 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infoblox
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/recordapi"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+const pageSize = 1000
+
+// recordTypes maps the record types we manage to their WAPI object type, and the field holding the value
+var recordTypes = []struct {
+	rrsType    rrstype.RrsType
+	objectType string
+	valueField string
+}{
+	{rrsType: rrstype.A, objectType: "record:a", valueField: "ipv4addr"},
+	{rrsType: rrstype.AAAA, objectType: "record:aaaa", valueField: "ipv6addr"},
+	{rrsType: rrstype.CNAME, objectType: "record:cname", valueField: "canonical"},
+}
+
+// api implements recordapi.API using the Infoblox WAPI
+type api struct {
+	baseURL  string
+	view     string
+	username string
+	password string
+	client   *http.Client
+}
+
+var _ recordapi.API = &api{}
+
+func newAPI(cfg *Config) *api {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if cfg.Global.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &api{
+		baseURL:  cfg.Global.Scheme + "://" + cfg.Global.Host + "/wapi/v" + cfg.Global.WAPIVersion + "/",
+		view:     cfg.Global.View,
+		username: cfg.Global.Username,
+		password: cfg.Global.Password,
+		client:   &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}
+}
+
+// pagedResponse is returned for queries made with _return_as_object
+type pagedResponse struct {
+	Result     json.RawMessage `json:"result"`
+	NextPageID string          `json:"next_page_id"`
+}
+
+// errorResponse is returned when a request fails
+type errorResponse struct {
+	Error string `json:"Error"`
+	Text  string `json:"text"`
+}
+
+func (a *api) do(method string, path string, query url.Values, body interface{}) ([]byte, error) {
+	u := a.baseURL + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %v", err)
+	}
+	req.SetBasicAuth(a.username, a.password)
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling Infoblox WAPI %s %s: %v", method, path, err)
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Infoblox WAPI response: %v", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		e := &errorResponse{}
+		if err := json.Unmarshal(data, e); err == nil && e.Text != "" {
+			return nil, fmt.Errorf("Infoblox WAPI %s %s failed (status %d): %s", method, path, response.StatusCode, e.Text)
+		}
+		return nil, fmt.Errorf("Infoblox WAPI %s %s failed (status %d): %s", method, path, response.StatusCode, string(data))
+	}
+	return data, nil
+}
+
+// list fetches every page of objects, passing each page to fn
+func (a *api) list(objectType string, query url.Values, fn func(result json.RawMessage) error) error {
+	pageID := ""
+	for {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("_paging", "1")
+		q.Set("_return_as_object", "1")
+		q.Set("_max_results", fmt.Sprintf("%d", pageSize))
+		if pageID != "" {
+			q.Set("_page_id", pageID)
+		}
+
+		data, err := a.do(http.MethodGet, objectType, q, nil)
+		if err != nil {
+			return err
+		}
+
+		page := &pagedResponse{}
+		if err := json.Unmarshal(data, page); err != nil {
+			return fmt.Errorf("error parsing Infoblox WAPI response: %v", err)
+		}
+		if err := fn(page.Result); err != nil {
+			return fmt.Errorf("error parsing Infoblox WAPI response: %v", err)
+		}
+
+		if page.NextPageID == "" {
+			return nil
+		}
+		pageID = page.NextPageID
+	}
+}
+
+func (a *api) ListZones() ([]*recordapi.ZoneInfo, error) {
+	query := url.Values{}
+	query.Set("view", a.view)
+	query.Set("zone_format", "FORWARD")
+	query.Set("_return_fields", "fqdn")
+
+	var zones []*recordapi.ZoneInfo
+	err := a.list("zone_auth", query, func(result json.RawMessage) error {
+		var objects []struct {
+			Ref  string `json:"_ref"`
+			FQDN string `json:"fqdn"`
+		}
+		if err := json.Unmarshal(result, &objects); err != nil {
+			return err
+		}
+		for _, o := range objects {
+			zones = append(zones, &recordapi.ZoneInfo{ID: o.Ref, Name: o.FQDN})
+		}
+		return nil
+	})
+	return zones, err
+}
+
+func (a *api) ListRecords(zone *recordapi.ZoneInfo, name string) ([]*recordapi.Record, error) {
+	var records []*recordapi.Record
+	for _, recordType := range recordTypes {
+		query := url.Values{}
+		query.Set("view", a.view)
+		query.Set("zone", zone.Name)
+		if name != "" {
+			query.Set("name", name)
+		}
+		query.Set("_return_fields", "name,ttl,"+recordType.valueField)
+
+		valueField := recordType.valueField
+		rrsType := recordType.rrsType
+		err := a.list(recordType.objectType, query, func(result json.RawMessage) error {
+			var objects []map[string]interface{}
+			if err := json.Unmarshal(result, &objects); err != nil {
+				return err
+			}
+			for _, o := range objects {
+				record := &recordapi.Record{Type: rrsType}
+				record.ID, _ = o["_ref"].(string)
+				record.Name, _ = o["name"].(string)
+				record.Value, _ = o[valueField].(string)
+				// ttl is not returned if the record inherits the zone TTL
+				if ttl, ok := o["ttl"].(float64); ok {
+					record.TTL = int64(ttl)
+				}
+				records = append(records, record)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+func (a *api) CreateRecord(zone *recordapi.ZoneInfo, record *recordapi.Record) error {
+	for _, recordType := range recordTypes {
+		if recordType.rrsType != record.Type {
+			continue
+		}
+
+		request := map[string]interface{}{
+			"name":                record.Name,
+			"view":                a.view,
+			"ttl":                 record.TTL,
+			"use_ttl":             true,
+			recordType.valueField: strings.TrimSuffix(record.Value, "."),
+		}
+		_, err := a.do(http.MethodPost, recordType.objectType, nil, request)
+		return err
+	}
+	return fmt.Errorf("record type %q is not supported by Infoblox", record.Type)
+}
+
+func (a *api) DeleteRecord(zone *recordapi.ZoneInfo, record *recordapi.Record) error {
+	_, err := a.do(http.MethodDelete, record.ID, nil, nil)
+	return err
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package infoblox is the implementation of pkg/dnsprovider interface for Infoblox, using the WAPI REST API
+package infoblox
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/glog"
+	"gopkg.in/gcfg.v1"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/recordapi"
+)
+
+const (
+	// ProviderName is the name of the Infoblox DNS provider
+	ProviderName = "infoblox"
+
+	// UsernameEnvVar is the environment variable from which the username is read, if not set in the config
+	UsernameEnvVar = "INFOBLOX_USERNAME"
+	// PasswordEnvVar is the environment variable from which the password is read, if not set in the config
+	PasswordEnvVar = "INFOBLOX_PASSWORD"
+
+	// DefaultWAPIVersion is the WAPI version we use if none is configured
+	DefaultWAPIVersion = "2.7"
+	// DefaultView is the DNS view we manage if none is configured
+	DefaultView = "default"
+)
+
+// Config to override defaults
+type Config struct {
+	Global struct {
+		// Host is the hostname (or host:port) of the Infoblox grid master
+		Host string `gcfg:"host"`
+		// WAPIVersion is the version of the WAPI to use
+		WAPIVersion string `gcfg:"wapi-version"`
+		// View is the DNS view in which zones and records are managed
+		View string `gcfg:"view"`
+		// Username and Password are the credentials for the WAPI
+		Username string `gcfg:"username"`
+		Password string `gcfg:"password"`
+		// InsecureSkipVerify disables verification of the grid master's certificate
+		InsecureSkipVerify bool `gcfg:"insecure-skip-verify"`
+		// Scheme overrides the URL scheme (https)
+		Scheme string `gcfg:"scheme"`
+	}
+}
+
+func init() {
+	dnsprovider.RegisterDnsProvider(ProviderName, func(config io.Reader) (dnsprovider.Interface, error) {
+		return newInfobloxProviderInterface(config)
+	})
+}
+
+// newInfobloxProviderInterface creates a new instance of an Infoblox DNS Interface.
+func newInfobloxProviderInterface(config io.Reader) (dnsprovider.Interface, error) {
+	var cfg Config
+	if config != nil {
+		if err := gcfg.ReadInto(&cfg, config); err != nil {
+			return nil, fmt.Errorf("error reading Infoblox config: %v", err)
+		}
+	}
+
+	if cfg.Global.Host == "" {
+		return nil, fmt.Errorf("Infoblox host must be configured")
+	}
+	if cfg.Global.WAPIVersion == "" {
+		cfg.Global.WAPIVersion = DefaultWAPIVersion
+	}
+	if cfg.Global.View == "" {
+		cfg.Global.View = DefaultView
+	}
+	if cfg.Global.Scheme == "" {
+		cfg.Global.Scheme = "https"
+	}
+	if cfg.Global.Username == "" {
+		cfg.Global.Username = os.Getenv(UsernameEnvVar)
+	}
+	if cfg.Global.Password == "" {
+		cfg.Global.Password = os.Getenv(PasswordEnvVar)
+	}
+	if cfg.Global.Username == "" || cfg.Global.Password == "" {
+		return nil, fmt.Errorf("Infoblox credentials not found; set %s and %s", UsernameEnvVar, PasswordEnvVar)
+	}
+
+	glog.Infof("Using Infoblox DNS provider at %s (view %q)", cfg.Global.Host, cfg.Global.View)
+	return recordapi.New(newAPI(&cfg)), nil
+}
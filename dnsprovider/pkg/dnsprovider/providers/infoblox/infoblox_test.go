@@ -0,0 +1,162 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infoblox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// fakeWAPI serves a minimal subset of the Infoblox WAPI
+type fakeWAPI struct {
+	t       *testing.T
+	objects map[string]map[string]interface{}
+	nextID  int
+}
+
+func (f *fakeWAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if username, password, _ := r.BasicAuth(); username != "admin" || password != "infoblox" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/wapi/v2.7/")
+	query := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		var result []map[string]interface{}
+		if path == "zone_auth" {
+			if query.Get("view") != "internal" {
+				f.t.Errorf("unexpected view %q", query.Get("view"))
+			}
+			result = append(result, map[string]interface{}{"_ref": "zone_auth/ZG5z:example.com/internal", "fqdn": "example.com"})
+		} else {
+			for ref, o := range f.objects {
+				if !strings.HasPrefix(ref, path+"/") {
+					continue
+				}
+				if name := query.Get("name"); name != "" && name != o["name"] {
+					continue
+				}
+				result = append(result, o)
+			}
+		}
+		data, _ := json.Marshal(result)
+		json.NewEncoder(w).Encode(&pagedResponse{Result: data})
+
+	case http.MethodPost:
+		o := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			f.t.Fatalf("error parsing request: %v", err)
+		}
+		if o["view"] != "internal" || o["use_ttl"] != true {
+			f.t.Errorf("unexpected record %v", o)
+		}
+		f.nextID++
+		ref := fmt.Sprintf("%s/ZG5z%d:%s/internal", path, f.nextID, o["name"])
+		o["_ref"] = ref
+		delete(o, "view")
+		delete(o, "use_ttl")
+		f.objects[ref] = o
+		json.NewEncoder(w).Encode(ref)
+
+	case http.MethodDelete:
+		if _, found := f.objects[path]; !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&errorResponse{Error: "AdmConDataNotFoundError", Text: "Reference not found"})
+			return
+		}
+		delete(f.objects, path)
+		json.NewEncoder(w).Encode(path)
+	}
+}
+
+func TestInfoblox(t *testing.T) {
+	fake := &fakeWAPI{t: t, objects: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config := "[global]\nhost = " + strings.TrimPrefix(server.URL, "http://") + "\nscheme = http\nview = internal\n"
+	config += "username = admin\npassword = infoblox\n"
+	intf, err := dnsprovider.GetDnsProvider(ProviderName, strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("error building provider: %v", err)
+	}
+
+	zones, _ := intf.Zones()
+	zoneList, err := zones.List()
+	if err != nil {
+		t.Fatalf("error listing zones: %v", err)
+	}
+	if len(zoneList) != 1 || zoneList[0].Name() != "example.com" {
+		t.Fatalf("unexpected zones %v", zoneList)
+	}
+
+	rrsets, _ := zoneList[0].ResourceRecordSets()
+	err = rrsets.StartChangeset().
+		Upsert(rrsets.New("api.example.com.", []string{"10.0.0.1", "10.0.0.2"}, 60, rrstype.A)).
+		Upsert(rrsets.New("www.example.com.", []string{"lb.example.net."}, 60, rrstype.CNAME)).
+		Apply()
+	if err != nil {
+		t.Fatalf("error creating records: %v", err)
+	}
+	if len(fake.objects) != 3 {
+		t.Fatalf("expected 3 records, got %v", fake.objects)
+	}
+
+	all, err := rrsets.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 record sets, got %v", all)
+	}
+
+	found, err := rrsets.Get("www.example.com.")
+	if err != nil {
+		t.Fatalf("error getting records: %v", err)
+	}
+	if len(found) != 1 || found[0].Type() != rrstype.CNAME || found[0].Rrdatas()[0] != "lb.example.net" || found[0].Ttl() != 60 {
+		t.Fatalf("unexpected records %v", found)
+	}
+
+	if err := rrsets.StartChangeset().Upsert(rrsets.New("api.example.com.", []string{"10.0.0.3"}, 60, rrstype.A)).Apply(); err != nil {
+		t.Fatalf("error updating records: %v", err)
+	}
+	found, err = rrsets.Get("api.example.com")
+	if err != nil {
+		t.Fatalf("error getting records: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Rrdatas()) != 1 || found[0].Rrdatas()[0] != "10.0.0.3" {
+		t.Fatalf("unexpected records after update %v", found)
+	}
+}
+
+func TestInfoblox_RequiresHost(t *testing.T) {
+	_, err := dnsprovider.GetDnsProvider(ProviderName, strings.NewReader("[global]\nusername = admin\npassword = infoblox\n"))
+	if err == nil {
+		t.Fatalf("expected error when host is not configured")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recordapi implements the dnsprovider interfaces on top of DNS APIs that manage
+// individual records, rather than record sets; the CloudFlare and Infoblox providers are built on it.
+package recordapi
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// ZoneInfo identifies a zone in the underlying API
+type ZoneInfo struct {
+	// ID is the provider identifier for the zone
+	ID string
+	// Name is the name of the zone, e.g. example.com
+	Name string
+}
+
+// Record is a single record in the underlying API; a record set with several values is made up of several records
+type Record struct {
+	// ID is the provider identifier for the record, used to delete it
+	ID string
+	// Name is the fully qualified name of the record, without a trailing dot
+	Name string
+	// Type is the type of the record (A, CNAME etc)
+	Type rrstype.RrsType
+	// Value is the value of the record, e.g. the IP address of an A record
+	Value string
+	// TTL is the time-to-live of the record, in seconds
+	TTL int64
+}
+
+// API is the set of operations a provider must implement
+type API interface {
+	// ListZones returns the zones visible to the provider
+	ListZones() ([]*ZoneInfo, error)
+	// ListRecords returns the records in the zone; if name is not empty, only the records with that name are returned
+	ListRecords(zone *ZoneInfo, name string) ([]*Record, error)
+	// CreateRecord creates the record in the zone
+	CreateRecord(zone *ZoneInfo, record *Record) error
+	// DeleteRecord deletes the record, which was returned by ListRecords, from the zone
+	DeleteRecord(zone *ZoneInfo, record *Record) error
+}
+
+// TTLNormalizer is implemented by APIs that do not accept every TTL; the TTL of a record set
+// is normalized before it is compared with existing records, or used to create records
+type TTLNormalizer interface {
+	NormalizeTTL(ttl int64) int64
+}
+
+// Compile time check for interface adherence
+var _ dnsprovider.Interface = &Interface{}
+
+// Interface is a dnsprovider.Interface backed by an API
+type Interface struct {
+	api API
+}
+
+// New builds a dnsprovider.Interface using the API
+func New(api API) *Interface {
+	return &Interface{api: api}
+}
+
+func (i *Interface) Zones() (dnsprovider.Zones, bool) {
+	return &Zones{intf: i}, true
+}
+
+func (i *Interface) normalizeTTL(ttl int64) int64 {
+	if n, ok := i.api.(TTLNormalizer); ok {
+		return n.NormalizeTTL(ttl)
+	}
+	return ttl
+}
+
+// trimDot removes any trailing dot; neither the names nor the values of records carry one in the underlying APIs
+func trimDot(s string) string {
+	return strings.TrimSuffix(s, ".")
+}
+
+// supportedTypes are the record types we manage; other records are ignored when listing
+var supportedTypes = map[rrstype.RrsType]bool{
+	rrstype.A:     true,
+	rrstype.AAAA:  true,
+	rrstype.CNAME: true,
+}
+
+func checkSupportedType(t rrstype.RrsType) error {
+	if !supportedTypes[t] {
+		return fmt.Errorf("record type %q is not supported", t)
+	}
+	return nil
+}
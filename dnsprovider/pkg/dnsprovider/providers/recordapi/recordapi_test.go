@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recordapi
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
+)
+
+// fakeAPI is an in-memory API
+type fakeAPI struct {
+	zones   []*ZoneInfo
+	records map[string][]*Record
+	nextID  int
+
+	creates int
+	deletes int
+}
+
+var _ API = &fakeAPI{}
+
+func newFakeAPI(zoneNames ...string) *fakeAPI {
+	f := &fakeAPI{records: make(map[string][]*Record)}
+	for i, name := range zoneNames {
+		f.zones = append(f.zones, &ZoneInfo{ID: "zone-" + strconv.Itoa(i), Name: name})
+	}
+	return f
+}
+
+func (f *fakeAPI) ListZones() ([]*ZoneInfo, error) {
+	return f.zones, nil
+}
+
+func (f *fakeAPI) ListRecords(zone *ZoneInfo, name string) ([]*Record, error) {
+	var records []*Record
+	for _, r := range f.records[zone.ID] {
+		if name == "" || r.Name == name {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func (f *fakeAPI) CreateRecord(zone *ZoneInfo, record *Record) error {
+	for _, r := range f.records[zone.ID] {
+		if r.Type == rrstype.CNAME && r.Name == record.Name {
+			return fmt.Errorf("CNAME %q already exists", r.Name)
+		}
+	}
+	f.nextID++
+	r := *record
+	r.ID = strconv.Itoa(f.nextID)
+	f.records[zone.ID] = append(f.records[zone.ID], &r)
+	f.creates++
+	return nil
+}
+
+func (f *fakeAPI) DeleteRecord(zone *ZoneInfo, record *Record) error {
+	var kept []*Record
+	found := false
+	for _, r := range f.records[zone.ID] {
+		if r.ID == record.ID {
+			found = true
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if !found {
+		return fmt.Errorf("record %q not found", record.ID)
+	}
+	f.records[zone.ID] = kept
+	f.deletes++
+	return nil
+}
+
+func firstZone(t *testing.T, intf dnsprovider.Interface) dnsprovider.Zone {
+	zones, _ := intf.Zones()
+	zoneList, err := zones.List()
+	if err != nil {
+		t.Fatalf("error listing zones: %v", err)
+	}
+	if len(zoneList) == 0 {
+		t.Fatalf("no zones found")
+	}
+	return zoneList[0]
+}
+
+func TestResourceRecordSetsReplace(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplace(t, firstZone(t, New(newFakeAPI("test.com"))))
+}
+
+func TestResourceRecordSetsReplaceAll(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplaceAll(t, firstZone(t, New(newFakeAPI("test.com"))))
+}
+
+func TestResourceRecordSetsDifferentTypes(t *testing.T) {
+	tests.CommonTestResourceRecordSetsDifferentTypes(t, firstZone(t, New(newFakeAPI("test.com"))))
+}
+
+func TestUpsert_KeepsUnchangedRecords(t *testing.T) {
+	api := newFakeAPI("test.com")
+	zone := firstZone(t, New(api))
+	rrsets, _ := zone.ResourceRecordSets()
+
+	if err := rrsets.StartChangeset().Upsert(rrsets.New("api.test.com.", []string{"10.0.0.1", "10.0.0.2"}, 60, rrstype.A)).Apply(); err != nil {
+		t.Fatalf("error creating records: %v", err)
+	}
+	if err := rrsets.StartChangeset().Upsert(rrsets.New("api.test.com.", []string{"10.0.0.2", "10.0.0.3"}, 60, rrstype.A)).Apply(); err != nil {
+		t.Fatalf("error updating records: %v", err)
+	}
+
+	if api.creates != 3 || api.deletes != 1 {
+		t.Fatalf("expected 3 creates and 1 delete, got %d and %d", api.creates, api.deletes)
+	}
+
+	found, err := rrsets.Get("api.test.com.")
+	if err != nil {
+		t.Fatalf("error getting records: %v", err)
+	}
+	if len(found) != 1 || fmt.Sprintf("%v", found[0].Rrdatas()) != "[10.0.0.2 10.0.0.3]" {
+		t.Fatalf("unexpected records after upsert: %v", found)
+	}
+}
+
+func TestUpsert_ReplacesCNAME(t *testing.T) {
+	api := newFakeAPI("test.com")
+	zone := firstZone(t, New(api))
+	rrsets, _ := zone.ResourceRecordSets()
+
+	if err := rrsets.StartChangeset().Upsert(rrsets.New("api.test.com.", []string{"elb-1.example.com."}, 60, rrstype.CNAME)).Apply(); err != nil {
+		t.Fatalf("error creating record: %v", err)
+	}
+	if err := rrsets.StartChangeset().Upsert(rrsets.New("api.test.com.", []string{"elb-2.example.com."}, 60, rrstype.CNAME)).Apply(); err != nil {
+		t.Fatalf("error replacing record: %v", err)
+	}
+
+	found, err := rrsets.Get("api.test.com")
+	if err != nil {
+		t.Fatalf("error getting records: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Rrdatas()) != 1 || found[0].Rrdatas()[0] != "elb-2.example.com." {
+		t.Fatalf("unexpected records after upsert: %v", found)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recordapi
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// Compile time check for interface adherence
+var _ dnsprovider.ResourceRecordChangeset = &ResourceRecordChangeset{}
+
+type changeType string
+
+const (
+	changeAdd    = changeType("ADD")
+	changeRemove = changeType("REMOVE")
+	changeUpsert = changeType("UPSERT")
+)
+
+type change struct {
+	changeType changeType
+	rrset      dnsprovider.ResourceRecordSet
+}
+
+type ResourceRecordChangeset struct {
+	rrsets *ResourceRecordSets
+
+	changes []change
+}
+
+func (c *ResourceRecordChangeset) Add(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.changes = append(c.changes, change{changeType: changeAdd, rrset: rrset})
+	return c
+}
+
+func (c *ResourceRecordChangeset) Remove(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.changes = append(c.changes, change{changeType: changeRemove, rrset: rrset})
+	return c
+}
+
+func (c *ResourceRecordChangeset) Upsert(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.changes = append(c.changes, change{changeType: changeUpsert, rrset: rrset})
+	return c
+}
+
+func (c *ResourceRecordChangeset) IsEmpty() bool {
+	return len(c.changes) == 0
+}
+
+// ResourceRecordSets returns the parent ResourceRecordSets
+func (c *ResourceRecordChangeset) ResourceRecordSets() dnsprovider.ResourceRecordSets {
+	return c.rrsets
+}
+
+// Apply applies the changes.  The underlying APIs are not transactional, so we apply removals first
+// (allowing a record set to be replaced with a Remove and an Add), and if a change fails the earlier
+// changes are not rolled back.
+func (c *ResourceRecordChangeset) Apply() error {
+	api := c.rrsets.api()
+	zone := c.rrsets.zone.info
+
+	var ordered []change
+	for _, change := range c.changes {
+		if change.changeType == changeRemove {
+			ordered = append(ordered, change)
+		}
+	}
+	for _, change := range c.changes {
+		if change.changeType != changeRemove {
+			ordered = append(ordered, change)
+		}
+	}
+
+	for _, change := range ordered {
+		rrset := change.rrset
+		if err := checkSupportedType(rrset.Type()); err != nil {
+			return err
+		}
+
+		ttl := c.rrsets.zone.zones.intf.normalizeTTL(rrset.Ttl())
+
+		existing, err := c.rrsets.findRecords(rrset.Name(), rrset.Type())
+		if err != nil {
+			return fmt.Errorf("error listing records for %q: %v", rrset.Name(), err)
+		}
+
+		// Records we have to delete, and values we have to create
+		var deletions []*Record
+		var creations []string
+
+		switch change.changeType {
+		case changeAdd:
+			if len(existing) != 0 {
+				return fmt.Errorf("record %s %q already exists", rrset.Type(), rrset.Name())
+			}
+			creations = rrset.Rrdatas()
+
+		case changeRemove:
+			for _, r := range existing {
+				if containsValue(rrset.Rrdatas(), r.Value) {
+					deletions = append(deletions, r)
+				}
+			}
+
+		case changeUpsert:
+			// Keep the records that are unchanged, to avoid a window in which the name does not resolve
+			var kept []string
+			for _, r := range existing {
+				if r.TTL == ttl && containsValue(rrset.Rrdatas(), r.Value) && !containsValue(kept, r.Value) {
+					kept = append(kept, r.Value)
+				} else {
+					deletions = append(deletions, r)
+				}
+			}
+			for _, value := range rrset.Rrdatas() {
+				if !containsValue(kept, value) {
+					creations = append(creations, value)
+				}
+			}
+
+		default:
+			return fmt.Errorf("unhandled change type %q", change.changeType)
+		}
+
+		// A name can only have a single CNAME record, so it must be removed before it is replaced
+		if rrset.Type() == rrstype.CNAME {
+			if err := deleteRecords(api, zone, deletions); err != nil {
+				return err
+			}
+			deletions = nil
+		}
+
+		for _, value := range creations {
+			record := &Record{
+				Name:  trimDot(rrset.Name()),
+				Type:  rrset.Type(),
+				Value: value,
+				TTL:   ttl,
+			}
+			glog.V(4).Infof("creating record %s %s %s", record.Type, record.Name, record.Value)
+			if err := api.CreateRecord(zone, record); err != nil {
+				return fmt.Errorf("error creating record %s %q: %v", record.Type, record.Name, err)
+			}
+		}
+
+		if err := deleteRecords(api, zone, deletions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteRecords(api API, zone *ZoneInfo, records []*Record) error {
+	for _, record := range records {
+		glog.V(4).Infof("deleting record %s %s %s", record.Type, record.Name, record.Value)
+		if err := api.DeleteRecord(zone, record); err != nil {
+			return fmt.Errorf("error deleting record %s %q: %v", record.Type, record.Name, err)
+		}
+	}
+	return nil
+}
+
+// containsValue checks if the record value is in the list, ignoring any trailing dot (as found on CNAME targets)
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if trimDot(v) == trimDot(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recordapi
+
+import (
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// Compile time check for interface adherence
+var _ dnsprovider.ResourceRecordSets = &ResourceRecordSets{}
+
+type ResourceRecordSets struct {
+	zone *Zone
+}
+
+func (rrsets *ResourceRecordSets) api() API {
+	return rrsets.zone.zones.intf.api
+}
+
+func (rrsets *ResourceRecordSets) List() ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := rrsets.api().ListRecords(rrsets.zone.info, "")
+	if err != nil {
+		return nil, err
+	}
+	return groupRecords(records), nil
+}
+
+func (rrsets *ResourceRecordSets) Get(name string) ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := rrsets.api().ListRecords(rrsets.zone.info, trimDot(name))
+	if err != nil {
+		return nil, err
+	}
+	return groupRecords(records), nil
+}
+
+func (rrsets *ResourceRecordSets) StartChangeset() dnsprovider.ResourceRecordChangeset {
+	return &ResourceRecordChangeset{
+		rrsets: rrsets,
+	}
+}
+
+func (rrsets *ResourceRecordSets) New(name string, rrdatas []string, ttl int64, rrsType rrstype.RrsType) dnsprovider.ResourceRecordSet {
+	return &ResourceRecordSet{
+		name:    name,
+		rrdatas: rrdatas,
+		ttl:     ttl,
+		rrsType: rrsType,
+	}
+}
+
+// Zone returns the parent zone
+func (rrsets *ResourceRecordSets) Zone() dnsprovider.Zone {
+	return rrsets.zone
+}
+
+// findRecords returns the records with the name and type
+func (rrsets *ResourceRecordSets) findRecords(name string, rrsType rrstype.RrsType) ([]*Record, error) {
+	records, err := rrsets.api().ListRecords(rrsets.zone.info, trimDot(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Record
+	for _, r := range records {
+		if r.Type == rrsType {
+			matches = append(matches, r)
+		}
+	}
+	return matches, nil
+}
+
+// groupRecords builds record sets from the records, grouping them by name and type
+func groupRecords(records []*Record) []dnsprovider.ResourceRecordSet {
+	var rrsets []dnsprovider.ResourceRecordSet
+	byKey := make(map[string]*ResourceRecordSet)
+	for _, r := range records {
+		if !supportedTypes[r.Type] {
+			continue
+		}
+
+		key := string(r.Type) + "::" + r.Name
+		rrset := byKey[key]
+		if rrset == nil {
+			rrset = &ResourceRecordSet{
+				name:    r.Name,
+				ttl:     r.TTL,
+				rrsType: r.Type,
+			}
+			byKey[key] = rrset
+			rrsets = append(rrsets, rrset)
+		}
+		rrset.rrdatas = append(rrset.rrdatas, r.Value)
+	}
+	return rrsets
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recordapi
+
+import (
+	"fmt"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// Compile time check for interface adherence
+var _ dnsprovider.Zones = &Zones{}
+
+// Zones lists the zones from the API; zones must be created outside of kops
+type Zones struct {
+	intf *Interface
+}
+
+func (zones *Zones) List() ([]dnsprovider.Zone, error) {
+	infos, err := zones.intf.api.ListZones()
+	if err != nil {
+		return nil, err
+	}
+
+	var zoneList []dnsprovider.Zone
+	for _, info := range infos {
+		zoneList = append(zoneList, &Zone{info: info, zones: zones})
+	}
+	return zoneList, nil
+}
+
+func (zones *Zones) Add(zone dnsprovider.Zone) (dnsprovider.Zone, error) {
+	return nil, fmt.Errorf("OperationNotSupported")
+}
+
+func (zones *Zones) Remove(zone dnsprovider.Zone) error {
+	return fmt.Errorf("OperationNotSupported")
+}
+
+func (zones *Zones) New(name string) (dnsprovider.Zone, error) {
+	return nil, fmt.Errorf("OperationNotSupported")
+}
@@ -202,17 +202,31 @@ func (a *awsNodeAuthorizer) validateNodeInstance(ctx context.Context, doc *ec2me
 
 // validateIdentityDocument is responsible for validate the aws identity document
 func (a *awsNodeAuthorizer) validateIdentityDocument(_ context.Context, signed []byte, document interface{}) (string, error) {
+	verified, err := VerifyIdentityDocument(signed, document)
+	if err != nil {
+		return "", err
+	}
+	if !verified {
+		return "invalid signature", nil
+	}
+
+	return "", nil
+}
+
+// VerifyIdentityDocument checks the base64 encoded pkcs7 signature of an identity document against the AWS public
+// certificates loaded by GetPublicCertificates, decoding the document when one of them validates it
+func VerifyIdentityDocument(signed []byte, document interface{}) (bool, error) {
 	// @step: decode the signed document
 	decoded, err := base64.StdEncoding.DecodeString(string(signed))
 	if err != nil {
-		return "", err
+		return false, err
 	}
 
 	// @step: get the digest
 	for _, x := range publicCertificates {
 		parsed, err := pkcs7.Parse(decoded)
 		if err != nil {
-			return "", err
+			return false, err
 		}
 
 		parsed.Certificates = []*x509.Certificate{x}
@@ -221,11 +235,11 @@ func (a *awsNodeAuthorizer) validateIdentityDocument(_ context.Context, signed [
 				zap.String("common-name", x.Subject.CommonName),
 				zap.Error(err))
 		} else {
-			return "", json.NewDecoder(bytes.NewReader(parsed.Content)).Decode(document)
+			return true, json.NewDecoder(bytes.NewReader(parsed.Content)).Decode(document)
 		}
 	}
 
-	return "invalid signature", nil
+	return false, nil
 }
 
 // validateNodeRegistrationRequest is responsible for validating the request itself
@@ -48,6 +48,10 @@ type NodeupModelContext struct {
 
 	// IsMaster is true if the InstanceGroup has a role of master (populated by Init)
 	IsMaster bool
+	// IsEtcd is true if the InstanceGroup has a role of etcd, running etcd members on dedicated instances (populated by Init)
+	IsEtcd bool
+	// IsAPIServer is true if the InstanceGroup has a role of apiserver, running only kube-apiserver (populated by Init)
+	IsAPIServer bool
 
 	kubernetesVersion semver.Version
 }
@@ -64,6 +68,10 @@ func (c *NodeupModelContext) Init() error {
 		glog.Warningf("cannot determine role, InstanceGroup not set")
 	} else if c.InstanceGroup.Spec.Role == kops.InstanceGroupRoleMaster {
 		c.IsMaster = true
+	} else if c.InstanceGroup.Spec.Role == kops.InstanceGroupRoleEtcd {
+		c.IsEtcd = true
+	} else if c.InstanceGroup.Spec.Role == kops.InstanceGroupRoleAPIServer {
+		c.IsAPIServer = true
 	}
 
 	return nil
@@ -144,6 +152,16 @@ func (c *NodeupModelContext) KubeletKubeConfig() string {
 	return "/var/lib/kubelet/kubeconfig"
 }
 
+// KubeletClientCertificatePath is the path of the kubelet client certificate issued by the kops-controller
+func (c *NodeupModelContext) KubeletClientCertificatePath() string {
+	return "/var/lib/kubelet/pki/kubelet-client.crt"
+}
+
+// KubeletClientKeyPath is the path of the private key of the kubelet client certificate
+func (c *NodeupModelContext) KubeletClientKeyPath() string {
+	return "/var/lib/kubelet/pki/kubelet-client.key"
+}
+
 // CNIConfDir returns the CNI directory
 func (c *NodeupModelContext) CNIConfDir() string {
 	return "/etc/cni/net.d/"
@@ -175,6 +193,22 @@ func (c *NodeupModelContext) BuildKubeConfig(username string, ca, certificate, p
 		ClientCertificateData: certificate,
 		ClientKeyData:         privateKey,
 	}
+
+	return c.buildKubeConfig(username, ca, user)
+}
+
+// BuildKubeConfigFromFiles builds a kubeconfig which reads the client certificate and key from disk
+func (c *NodeupModelContext) BuildKubeConfigFromFiles(username string, ca []byte, certificatePath, keyPath string) (string, error) {
+	user := kubeconfig.KubectlUser{
+		ClientCertificate: certificatePath,
+		ClientKey:         keyPath,
+	}
+
+	return c.buildKubeConfig(username, ca, user)
+}
+
+// buildKubeConfig builds a kubeconfig for the user
+func (c *NodeupModelContext) buildKubeConfig(username string, ca []byte, user kubeconfig.KubectlUser) (string, error) {
 	cluster := kubeconfig.KubectlCluster{
 		CertificateAuthorityData: ca,
 	}
@@ -295,6 +329,12 @@ func (c *NodeupModelContext) UsesSecondaryIP() bool {
 	return false
 }
 
+// UseKopsController checks if the kubelet obtains its certificate from the kops-controller, which only
+// issues certificates to nodes
+func (c *NodeupModelContext) UseKopsController() bool {
+	return c.Cluster.Spec.KopsControllerEnabled() && !c.IsMaster && !c.IsAPIServer && !c.IsEtcd
+}
+
 // UseBootstrapTokens checks if we are using bootstrap tokens
 func (c *NodeupModelContext) UseBootstrapTokens() bool {
 	if c.IsMaster {
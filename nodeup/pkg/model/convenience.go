@@ -26,6 +26,7 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -119,6 +120,49 @@ func addHostPathMapping(pod *v1.Pod, container *v1.Container, name, path string)
 	return &container.VolumeMounts[len(container.VolumeMounts)-1]
 }
 
+// buildControlPlaneResources returns the resources of a control plane static pod: the default cpu request, overridden
+// by any resources set for the component in the cluster spec
+func buildControlPlaneResources(defaultCPURequest string, r *kops.ControlPlaneResources) (v1.ResourceRequirements, error) {
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU: resource.MustParse(defaultCPURequest),
+		},
+	}
+	if r == nil {
+		return resources, nil
+	}
+
+	for _, x := range []struct {
+		value string
+		name  v1.ResourceName
+		list  *v1.ResourceList
+	}{
+		{r.CPURequest, v1.ResourceCPU, &resources.Requests},
+		{r.MemoryRequest, v1.ResourceMemory, &resources.Requests},
+		{r.CPULimit, v1.ResourceCPU, &resources.Limits},
+		{r.MemoryLimit, v1.ResourceMemory, &resources.Limits},
+	} {
+		if x.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(x.value)
+		if err != nil {
+			return resources, fmt.Errorf("error parsing %s quantity %q: %v", x.name, x.value, err)
+		}
+		if *x.list == nil {
+			*x.list = v1.ResourceList{}
+		}
+		(*x.list)[x.name] = q
+	}
+
+	// a cpu limit below the default request would make the pod invalid, so the request follows the limit down
+	if limit, found := resources.Limits[v1.ResourceCPU]; found && r.CPURequest == "" && limit.Cmp(resources.Requests[v1.ResourceCPU]) < 0 {
+		resources.Requests[v1.ResourceCPU] = limit
+	}
+
+	return resources, nil
+}
+
 // convEtcdSettingsToMs converts etcd settings to a string rep of int milliseconds
 func convEtcdSettingsToMs(dur *metav1.Duration) string {
 	return strconv.FormatInt(dur.Nanoseconds()/1000000, 10)
@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// KopsControllerBuilder installs the certificates of the kops-controller on the masters, and obtains the kubelet
+// certificate from it on the nodes
+type KopsControllerBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &KopsControllerBuilder{}
+
+// Build is responsible for the kops-controller certificates
+func (b *KopsControllerBuilder) Build(c *fi.ModelBuilderContext) error {
+	if !b.Cluster.Spec.KopsControllerEnabled() {
+		return nil
+	}
+
+	if b.IsMaster {
+		name := "kops-controller"
+		// creates /srv/kubernetes/kops-controller/{tls,tls-key}.pem
+		if err := b.BuildCertificatePairTask(c, name, name, "tls"); err != nil {
+			return err
+		}
+		// creates /srv/kubernetes/kops-controller/{ca,ca-key}.pem, which the kubelet certificates are signed with
+		if err := b.BuildCertificateTask(c, fi.CertificateId_CA, filepath.Join(name, "ca.pem")); err != nil {
+			return err
+		}
+		if err := b.BuildPrivateKeyTask(c, fi.CertificateId_CA, filepath.Join(name, "ca-key.pem")); err != nil {
+			return err
+		}
+	}
+
+	if b.UseKopsController() {
+		ca, err := b.FindCert(fi.CertificateId_CA)
+		if err != nil {
+			return err
+		}
+
+		c.AddTask(&nodetasks.BootstrapClientTask{
+			Name:     "kubelet",
+			Server:   fmt.Sprintf("https://kops-controller.internal.%s:%d", b.Cluster.ObjectMeta.Name, b.Cluster.Spec.KopsController.Port),
+			CA:       string(ca),
+			CertPath: b.KubeletClientCertificatePath(),
+			KeyPath:  b.KubeletClientKeyPath(),
+		})
+	}
+
+	return nil
+}
@@ -22,6 +22,8 @@ import (
 	"strings"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/pkg/encryptionconfig"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/k8scodecs"
 	"k8s.io/kops/pkg/kubeconfig"
@@ -31,7 +33,6 @@ import (
 	"k8s.io/kops/util/pkg/exec"
 
 	"k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -48,7 +49,7 @@ var _ fi.ModelBuilder = &KubeAPIServerBuilder{}
 
 // Build is responsible for generating the configuration for the kube-apiserver
 func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
-	if !b.IsMaster {
+	if !b.IsMaster && !b.IsAPIServer {
 		return nil
 	}
 
@@ -59,7 +60,7 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 	if b.Cluster.Spec.EncryptionConfig != nil {
 		if *b.Cluster.Spec.EncryptionConfig && b.IsKubernetesGTE("1.7") {
 			b.Cluster.Spec.KubeAPIServer.ExperimentalEncryptionProviderConfig = fi.String(filepath.Join(b.PathSrvKubernetes(), "encryptionconfig.yaml"))
-			key := "encryptionconfig"
+			key := encryptionconfig.SecretName
 			encryptioncfg, _ := b.SecretStore.Secret(key)
 			if encryptioncfg != nil {
 				contents := string(encryptioncfg.Data)
@@ -91,6 +92,17 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 		})
 	}
 
+	// @check if we are an apiserver-only instance, which has no protokube to write the etcd client certificates
+	if b.IsAPIServer && b.UseEtcdTLS() {
+		name := "etcd-client"
+		if err := b.BuildCertificateTask(c, name, name+".pem"); err != nil {
+			return err
+		}
+		if err := b.BuildPrivateKeyTask(c, name, name+"-key.pem"); err != nil {
+			return err
+		}
+	}
+
 	// @check if we are using secure client certificates for kubelet and grab the certificates
 	if b.UseSecureKubelet() {
 		name := "kubelet-api"
@@ -274,6 +286,11 @@ func (b *KubeAPIServerBuilder) writeAuthenticationConfig(c *fi.ModelBuilderConte
 		return nil
 	}
 
+	if b.Cluster.Spec.Authentication.OIDC != nil {
+		// OIDC is configured entirely through apiserver flags
+		return nil
+	}
+
 	return fmt.Errorf("Unrecognized authentication config %v", b.Cluster.Spec.Authentication)
 }
 
@@ -294,6 +311,16 @@ func (b *KubeAPIServerBuilder) buildPod() (*v1.Pod, error) {
 		kubeAPIServer.EtcdServersOverrides = []string{"/events#https://127.0.0.1:4002"}
 	}
 
+	// @check if we are an apiserver-only instance, in which case etcd is not running locally
+	if b.IsAPIServer {
+		servers, overrides, err := b.buildRemoteEtcdServers()
+		if err != nil {
+			return nil, err
+		}
+		kubeAPIServer.EtcdServers = servers
+		kubeAPIServer.EtcdServersOverrides = overrides
+	}
+
 	// @check if we are using secure kubelet client certificates
 	if b.UseSecureKubelet() {
 		// @note we are making assumption were using the ones created by the pki model, not custom defined ones
@@ -377,6 +404,11 @@ func (b *KubeAPIServerBuilder) buildPod() (*v1.Pod, error) {
 		probeAction.Scheme = v1.URISchemeHTTPS
 	}
 
+	resources, err := buildControlPlaneResources("150m", b.Cluster.Spec.KubeAPIServer.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("error building kube-apiserver resources: %v", err)
+	}
+
 	container := &v1.Container{
 		Name:  "kube-apiserver",
 		Image: b.Cluster.Spec.KubeAPIServer.Image,
@@ -404,11 +436,7 @@ func (b *KubeAPIServerBuilder) buildPod() (*v1.Pod, error) {
 				HostPort:      8080,
 			},
 		},
-		Resources: v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceCPU: resource.MustParse("150m"),
-			},
-		},
+		Resources: resources,
 	}
 
 	for _, path := range b.SSLHostPaths() {
@@ -450,6 +478,15 @@ func (b *KubeAPIServerBuilder) buildPod() (*v1.Pod, error) {
 		}
 	}
 
+	// The kms encryption provider talks to the KMS plugin over a unix socket on the host
+	if fi.BoolValue(b.Cluster.Spec.EncryptionConfig) && b.Cluster.Spec.EncryptionProvider != nil {
+		kms := b.Cluster.Spec.EncryptionProvider.KMS
+		if b.Cluster.Spec.EncryptionProvider.Type == encryptionconfig.ProviderKMS && kms != nil && strings.HasPrefix(kms.Endpoint, "unix://") {
+			socketDir := filepath.Dir(strings.TrimPrefix(kms.Endpoint, "unix://"))
+			addHostPathMapping(pod, container, "kmsplugin", socketDir).ReadOnly = false
+		}
+	}
+
 	pod.Spec.Containers = append(pod.Spec.Containers, *container)
 
 	kubemanifest.MarkPodAsCritical(pod)
@@ -457,6 +494,45 @@ func (b *KubeAPIServerBuilder) buildPod() (*v1.Pod, error) {
 	return pod, nil
 }
 
+// buildRemoteEtcdServers returns the etcd servers and overrides for an apiserver-only instance, addressing the etcd
+// members by the internal dns names that protokube or etcd-manager publish for them
+func (b *KubeAPIServerBuilder) buildRemoteEtcdServers() ([]string, []string, error) {
+	scheme := "http"
+	if b.UseEtcdTLS() {
+		scheme = "https"
+	}
+
+	internalSuffix := ".internal." + b.Cluster.ObjectMeta.Name
+	if dns.IsGossipHostname(b.Cluster.Spec.MasterInternalName) {
+		internalSuffix = "." + strings.TrimPrefix(b.Cluster.Spec.MasterInternalName, "api.")
+	}
+
+	var servers, overrides []string
+	for _, etcdCluster := range b.Cluster.Spec.EtcdClusters {
+		var urls []string
+		switch etcdCluster.Name {
+		case "main":
+			for _, m := range etcdCluster.Members {
+				urls = append(urls, fmt.Sprintf("%s://etcd-%s%s:4001", scheme, m.Name, internalSuffix))
+			}
+			servers = urls
+		case "events":
+			for _, m := range etcdCluster.Members {
+				urls = append(urls, fmt.Sprintf("%s://etcd-events-%s%s:4002", scheme, m.Name, internalSuffix))
+			}
+			overrides = []string{"/events#" + strings.Join(urls, ";")}
+		default:
+			return nil, nil, fmt.Errorf("unknown etcd cluster %q", etcdCluster.Name)
+		}
+	}
+
+	if len(servers) == 0 {
+		return nil, nil, fmt.Errorf("unable to find the members of the main etcd cluster")
+	}
+
+	return servers, overrides, nil
+}
+
 func (b *KubeAPIServerBuilder) buildAnnotations() map[string]string {
 	annotations := make(map[string]string)
 
@@ -17,11 +17,15 @@ limitations under the License.
 package model
 
 import (
+	"sort"
+	"strings"
 	"testing"
 
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/upup/pkg/fi"
+
+	"k8s.io/api/core/v1"
 )
 
 func Test_KubeAPIServer_BuildFlags(t *testing.T) {
@@ -91,3 +95,104 @@ func Test_KubeAPIServer_BuildFlags(t *testing.T) {
 		}
 	}
 }
+
+func Test_KubeAPIServer_RemoteEtcdServers(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = "example.com"
+	cluster.Spec.MasterInternalName = "api.internal.example.com"
+	cluster.Spec.EtcdClusters = []*kops.EtcdClusterSpec{
+		{
+			Name:          "main",
+			EnableEtcdTLS: true,
+			Members:       []*kops.EtcdMemberSpec{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			Name:          "events",
+			EnableEtcdTLS: true,
+			Members:       []*kops.EtcdMemberSpec{{Name: "a"}, {Name: "b"}},
+		},
+	}
+
+	b := &KubeAPIServerBuilder{
+		&NodeupModelContext{
+			Cluster:     cluster,
+			IsAPIServer: true,
+		},
+	}
+
+	servers, overrides, err := b.buildRemoteEtcdServers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedServers := "https://etcd-a.internal.example.com:4001,https://etcd-b.internal.example.com:4001"
+	if strings.Join(servers, ",") != expectedServers {
+		t.Errorf("expected etcd servers %q, got %q", expectedServers, strings.Join(servers, ","))
+	}
+	expectedOverrides := "/events#https://etcd-events-a.internal.example.com:4002;https://etcd-events-b.internal.example.com:4002"
+	if strings.Join(overrides, ",") != expectedOverrides {
+		t.Errorf("expected etcd servers overrides %q, got %q", expectedOverrides, strings.Join(overrides, ","))
+	}
+
+	cluster.Spec.EtcdClusters[0].EnableEtcdTLS = false
+	cluster.Spec.EtcdClusters[1].EnableEtcdTLS = false
+	cluster.ObjectMeta.Name = "example.k8s.local"
+	cluster.Spec.MasterInternalName = "api.internal.example.k8s.local"
+	servers, _, err = b.buildRemoteEtcdServers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if servers[0] != "http://etcd-a.internal.example.k8s.local:4001" {
+		t.Errorf("expected a plain http etcd server on a gossip cluster, got %q", servers[0])
+	}
+}
+
+func Test_BuildControlPlaneResources(t *testing.T) {
+	grid := []struct {
+		resources *kops.ControlPlaneResources
+		requests  string
+		limits    string
+	}{
+		{
+			resources: nil,
+			requests:  "cpu=150m",
+			limits:    "",
+		},
+		{
+			resources: &kops.ControlPlaneResources{CPURequest: "1", MemoryRequest: "1Gi", MemoryLimit: "2Gi"},
+			requests:  "cpu=1,memory=1Gi",
+			limits:    "memory=2Gi",
+		},
+		{
+			resources: &kops.ControlPlaneResources{CPULimit: "100m"},
+			requests:  "cpu=100m",
+			limits:    "cpu=100m",
+		},
+	}
+	for _, g := range grid {
+		resources, err := buildControlPlaneResources("150m", g.resources)
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", g.resources, err)
+			continue
+		}
+		if actual := resourceListString(resources.Requests); actual != g.requests {
+			t.Errorf("expected requests %q, got %q", g.requests, actual)
+		}
+		if actual := resourceListString(resources.Limits); actual != g.limits {
+			t.Errorf("expected limits %q, got %q", g.limits, actual)
+		}
+	}
+
+	if _, err := buildControlPlaneResources("150m", &kops.ControlPlaneResources{MemoryLimit: "lots"}); err == nil {
+		t.Errorf("expected an error for an invalid quantity")
+	}
+}
+
+func resourceListString(list v1.ResourceList) string {
+	var s []string
+	for name, q := range list {
+		s = append(s, string(name)+"="+q.String())
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
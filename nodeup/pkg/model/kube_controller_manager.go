@@ -29,7 +29,6 @@ import (
 	"k8s.io/kops/util/pkg/exec"
 
 	"k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -151,6 +150,11 @@ func (b *KubeControllerManagerBuilder) buildPod() (*v1.Pod, error) {
 		},
 	}
 
+	resources, err := buildControlPlaneResources("100m", b.Cluster.Spec.KubeControllerManager.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("error building kube-controller-manager resources: %v", err)
+	}
+
 	container := &v1.Container{
 		Name:  "kube-controller-manager",
 		Image: b.Cluster.Spec.KubeControllerManager.Image,
@@ -170,11 +174,7 @@ func (b *KubeControllerManagerBuilder) buildPod() (*v1.Pod, error) {
 			InitialDelaySeconds: 15,
 			TimeoutSeconds:      15,
 		},
-		Resources: v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceCPU: resource.MustParse("100m"),
-			},
-		},
+		Resources: resources,
 	}
 
 	for _, path := range b.SSLHostPaths() {
@@ -18,6 +18,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/flagbuilder"
@@ -49,6 +50,11 @@ func (b *KubeProxyBuilder) Build(c *fi.ModelBuilderContext) error {
 		return nil
 	}
 
+	if b.IsEtcd {
+		glog.V(2).Infof("Running on a dedicated etcd instance; skipping kube-proxy installation")
+		return nil
+	}
+
 	if b.IsMaster {
 		// If this is a master that is not isolated, run it as a normal node also (start kube-proxy etc)
 		// This lets e.g. daemonset pods communicate with other pods in the system
@@ -89,6 +95,17 @@ func (b *KubeProxyBuilder) Build(c *fi.ModelBuilderContext) error {
 		})
 	}
 
+	// kube-proxy falls back to iptables mode if the ipvs modules are not loaded, so we load them on boot
+	if b.Cluster.Spec.KubeProxy.ProxyMode == "ipvs" {
+		modules := ipvsKernelModules(b.Cluster.Spec.KubeProxy.IPVSScheduler)
+		c.AddTask(&nodetasks.File{
+			Path:            "/etc/modules-load.d/kube-proxy-ipvs.conf",
+			Contents:        fi.NewStringResource(strings.Join(modules, "\n") + "\n"),
+			Type:            nodetasks.FileType_File,
+			OnChangeExecute: [][]string{{"systemctl", "restart", "systemd-modules-load.service"}},
+		})
+	}
+
 	{
 		c.AddTask(&nodetasks.File{
 			Path:        "/var/log/kube-proxy.log",
@@ -267,6 +284,15 @@ func (b *KubeProxyBuilder) buildPod() (*v1.Pod, error) {
 	return pod, nil
 }
 
+// ipvsKernelModules returns the kernel modules needed by kube-proxy in ipvs mode with the specified scheduler
+func ipvsKernelModules(scheduler string) []string {
+	modules := []string{"ip_vs", "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh", "nf_conntrack_ipv4"}
+	if scheduler != "" && scheduler != "rr" && scheduler != "wrr" && scheduler != "sh" {
+		modules = append(modules, "ip_vs_"+scheduler)
+	}
+	return modules
+}
+
 func tolerateMasterTaints() []v1.Toleration {
 	tolerations := []v1.Toleration{}
 
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_IPVSKernelModules(t *testing.T) {
+	grid := []struct {
+		scheduler string
+		expected  []string
+	}{
+		{scheduler: "", expected: []string{"ip_vs", "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh", "nf_conntrack_ipv4"}},
+		{scheduler: "sh", expected: []string{"ip_vs", "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh", "nf_conntrack_ipv4"}},
+		{scheduler: "lc", expected: []string{"ip_vs", "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh", "nf_conntrack_ipv4", "ip_vs_lc"}},
+	}
+
+	for _, g := range grid {
+		actual := ipvsKernelModules(g.scheduler)
+		if !reflect.DeepEqual(actual, g.expected) {
+			t.Errorf("expected modules %v for scheduler %q, got %v", g.expected, g.scheduler, actual)
+		}
+	}
+}
@@ -27,7 +27,6 @@ import (
 	"k8s.io/kops/util/pkg/exec"
 
 	"k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -122,6 +121,11 @@ func (b *KubeSchedulerBuilder) buildPod() (*v1.Pod, error) {
 		},
 	}
 
+	resources, err := buildControlPlaneResources("100m", b.Cluster.Spec.KubeScheduler.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("error building kube-scheduler resources: %v", err)
+	}
+
 	container := &v1.Container{
 		Name:  "kube-scheduler",
 		Image: c.Image,
@@ -141,11 +145,7 @@ func (b *KubeSchedulerBuilder) buildPod() (*v1.Pod, error) {
 			InitialDelaySeconds: 15,
 			TimeoutSeconds:      15,
 		},
-		Resources: v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceCPU: resource.MustParse("100m"),
-			},
-		},
+		Resources: resources,
 	}
 	addHostPathMapping(pod, container, "varlibkubescheduler", "/var/lib/kube-scheduler")
 	addHostPathMapping(pod, container, "logfile", "/var/log/kube-scheduler.log").ReadOnly = false
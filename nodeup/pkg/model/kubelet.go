@@ -89,7 +89,8 @@ func (b *KubeletBuilder) Build(c *fi.ModelBuilderContext) error {
 		})
 	}
 
-	{
+	// dedicated etcd instances run a standalone kubelet, which has no credentials for the API
+	if !b.IsEtcd {
 		// @check if bootstrap tokens are enabled and create the appropreiate certificates
 		if b.UseBootstrapTokens() {
 			// @check if a master and if so, we bypass the token strapping and instead generate our own kubeconfig
@@ -102,6 +103,23 @@ func (b *KubeletBuilder) Build(c *fi.ModelBuilderContext) error {
 				}
 				c.AddTask(task)
 			}
+		} else if b.UseKopsController() {
+			// @note: the certificate is issued by the kops-controller, see KopsControllerBuilder
+			ca, err := b.FindCert(fi.CertificateId_CA)
+			if err != nil {
+				return err
+			}
+			kubeconfig, err := b.BuildKubeConfigFromFiles("kubelet", ca, b.KubeletClientCertificatePath(), b.KubeletClientKeyPath())
+			if err != nil {
+				return err
+			}
+
+			c.AddTask(&nodetasks.File{
+				Path:     b.KubeletKubeConfig(),
+				Contents: fi.NewStringResource(kubeconfig),
+				Type:     nodetasks.FileType_File,
+				Mode:     s("0400"),
+			})
 		} else {
 			kubeconfig, err := b.BuildPKIKubeconfig("kubelet")
 			if err != nil {
@@ -229,7 +247,7 @@ func (b *KubeletBuilder) buildSystemdService() *nodetasks.Service {
 	manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/kubelet")
 
 	// @check if we are using bootstrap tokens and file checker
-	if !b.IsMaster && b.UseBootstrapTokens() {
+	if !b.IsMaster && !b.IsEtcd && b.UseBootstrapTokens() {
 		manifest.Set("Service", "ExecStartPre",
 			fmt.Sprintf("/bin/bash -c 'while [ ! -f %s ]; do sleep 5; done;'", b.KubeletBootstrapKubeconfig()))
 	}
@@ -249,8 +267,8 @@ func (b *KubeletBuilder) buildSystemdService() *nodetasks.Service {
 		Definition: s(manifestString),
 	}
 
-	// @check if we are a master allow protokube to start kubelet
-	if b.IsMaster {
+	// @check if we are a master (or dedicated etcd instance) allow protokube to start kubelet
+	if b.IsMaster || b.IsEtcd {
 		service.Running = fi.Bool(false)
 	}
 
@@ -411,6 +429,10 @@ const RoleNodeLabelValue15 = "node"
 const RoleLabelMaster16 = "node-role.kubernetes.io/master"
 const RoleLabelNode16 = "node-role.kubernetes.io/node"
 
+// RoleLabelAPIServer16 marks the nodes of apiserver-only instance groups; they are also tainted with it
+const RoleLabelAPIServer16 = "node-role.kubernetes.io/api-server"
+const RoleAPIServerLabelValue15 = "api-server"
+
 // NodeLabels are defined in the InstanceGroup, but set flags on the kubelet config.
 // We have a conflict here: on the one hand we want an easy to use abstract specification
 // for the cluster, on the other hand we don't want two fields that do the same thing.
@@ -425,7 +447,7 @@ const RoleLabelNode16 = "node-role.kubernetes.io/node"
 func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, error) {
 	// Merge KubeletConfig for NodeLabels
 	c := &kops.KubeletConfigSpec{}
-	if b.InstanceGroup.Spec.Role == kops.InstanceGroupRoleMaster {
+	if b.InstanceGroup.Spec.Role == kops.InstanceGroupRoleMaster || b.InstanceGroup.Spec.Role == kops.InstanceGroupRoleEtcd {
 		reflectutils.JsonMergeStruct(c, b.Cluster.Spec.MasterKubelet)
 	} else {
 		reflectutils.JsonMergeStruct(c, b.Cluster.Spec.Kubelet)
@@ -445,12 +467,32 @@ func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, erro
 		reflectutils.JsonMergeStruct(c, b.InstanceGroup.Spec.Kubelet)
 	}
 
+	// pods resolve through the node-local-dns cache, which forwards cluster queries to the dns service
+	if b.Cluster.Spec.KubeDNS.NodeLocalDNSEnabled() {
+		c.ClusterDNS = b.Cluster.Spec.KubeDNS.NodeLocalDNS.LocalIP
+	}
+
+	// dedicated etcd instances only run the etcd static pods, so the kubelet runs standalone and never registers
+	if b.IsEtcd {
+		c.APIServers = ""
+		c.BootstrapKubeconfig = ""
+		c.KubeconfigPath = ""
+		c.RequireKubeconfig = nil
+		c.RegisterNode = fi.Bool(false)
+	}
+
 	if b.InstanceGroup.Spec.Role == kops.InstanceGroupRoleMaster {
 		if c.NodeLabels == nil {
 			c.NodeLabels = make(map[string]string)
 		}
 		c.NodeLabels[RoleLabelMaster16] = ""
 		c.NodeLabels[RoleLabelName15] = RoleMasterLabelValue15
+	} else if b.IsAPIServer {
+		if c.NodeLabels == nil {
+			c.NodeLabels = make(map[string]string)
+		}
+		c.NodeLabels[RoleLabelAPIServer16] = ""
+		c.NodeLabels[RoleLabelName15] = RoleAPIServerLabelValue15
 	} else {
 		if c.NodeLabels == nil {
 			c.NodeLabels = make(map[string]string)
@@ -477,6 +519,11 @@ func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, erro
 			c.Taints = append(c.Taints, RoleLabelMaster16+"=:"+string(v1.TaintEffectNoSchedule))
 		}
 
+		// apiserver-only instances are reserved for kube-apiserver, so keep workloads off them
+		if len(c.Taints) == 0 && b.IsAPIServer {
+			c.Taints = append(c.Taints, RoleLabelAPIServer16+"=:"+string(v1.TaintEffectNoSchedule))
+		}
+
 		// Enable scheduling since it can be controlled via taints.
 		// For pre-1.6.0 clusters, this is handled by tainter.go
 		c.RegisterSchedulable = fi.Bool(true)
@@ -60,6 +60,134 @@ func Test_InstanceGroupKubeletMerge(t *testing.T) {
 	}
 }
 
+func Test_EtcdKubeletStandalone(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubernetesVersion = "1.10.0"
+	cluster.Spec.Kubelet = &kops.KubeletConfigSpec{
+		KubeconfigPath: "/var/lib/kubelet/kubeconfig",
+	}
+	cluster.Spec.MasterKubelet = &kops.KubeletConfigSpec{
+		KubeconfigPath:    "/var/lib/kubelet/kubeconfig",
+		RequireKubeconfig: fi.Bool(true),
+		PodManifestPath:   "/etc/kubernetes/manifests",
+	}
+
+	ig := &kops.InstanceGroup{Spec: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleEtcd}}
+
+	b := &KubeletBuilder{
+		&NodeupModelContext{
+			Cluster:       cluster,
+			InstanceGroup: ig,
+		},
+	}
+	if err := b.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if !b.IsEtcd || b.IsMaster {
+		t.Fatalf("expected IsEtcd and not IsMaster, got IsEtcd=%v IsMaster=%v", b.IsEtcd, b.IsMaster)
+	}
+
+	c, err := b.buildKubeletConfigSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.PodManifestPath != "/etc/kubernetes/manifests" {
+		t.Errorf("expected the master kubelet config to be used, got PodManifestPath %q", c.PodManifestPath)
+	}
+	if c.KubeconfigPath != "" || c.RequireKubeconfig != nil {
+		t.Errorf("expected no kubeconfig for a standalone kubelet, got %q / %v", c.KubeconfigPath, c.RequireKubeconfig)
+	}
+	if fi.BoolValue(c.RegisterNode) {
+		t.Errorf("expected a standalone kubelet not to register the node")
+	}
+
+	service := b.buildSystemdService()
+	if fi.BoolValue(service.Running) {
+		t.Errorf("expected kubelet to be started by protokube, not by nodeup")
+	}
+}
+
+func Test_APIServerKubeletTainted(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubernetesVersion = "1.10.0"
+	cluster.Spec.Kubelet = &kops.KubeletConfigSpec{
+		KubeconfigPath: "/var/lib/kubelet/kubeconfig",
+	}
+
+	ig := &kops.InstanceGroup{Spec: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleAPIServer}}
+
+	b := &KubeletBuilder{
+		&NodeupModelContext{
+			Cluster:       cluster,
+			InstanceGroup: ig,
+		},
+	}
+	if err := b.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if !b.IsAPIServer || b.IsMaster {
+		t.Fatalf("expected IsAPIServer and not IsMaster, got IsAPIServer=%v IsMaster=%v", b.IsAPIServer, b.IsMaster)
+	}
+
+	c, err := b.buildKubeletConfigSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.KubeconfigPath != "/var/lib/kubelet/kubeconfig" {
+		t.Errorf("expected the node kubelet config to be used, got KubeconfigPath %q", c.KubeconfigPath)
+	}
+	if _, found := c.NodeLabels[RoleLabelAPIServer16]; !found || c.NodeLabels[RoleLabelName15] != "api-server" {
+		t.Errorf("expected the api-server role labels, got %v", c.NodeLabels)
+	}
+	if len(c.Taints) != 1 || c.Taints[0] != "node-role.kubernetes.io/api-server=:NoSchedule" {
+		t.Errorf("expected the api-server taint, got %v", c.Taints)
+	}
+}
+
+func Test_NodeLocalDNSClusterDNS(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubernetesVersion = "1.10.0"
+	cluster.Spec.Kubelet = &kops.KubeletConfigSpec{ClusterDNS: "100.64.0.10"}
+	cluster.Spec.KubeDNS = &kops.KubeDNSConfig{
+		ServerIP: "100.64.0.10",
+		NodeLocalDNS: &kops.NodeLocalDNSConfig{
+			Enabled: fi.Bool(true),
+			LocalIP: "169.254.20.10",
+		},
+	}
+
+	ig := &kops.InstanceGroup{Spec: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode}}
+
+	b := &KubeletBuilder{
+		&NodeupModelContext{
+			Cluster:       cluster,
+			InstanceGroup: ig,
+		},
+	}
+	if err := b.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := b.buildKubeletConfigSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.ClusterDNS != "169.254.20.10" {
+		t.Errorf("expected the kubelet to use the node-local-dns address, got %q", c.ClusterDNS)
+	}
+
+	cluster.Spec.KubeDNS.NodeLocalDNS.Enabled = fi.Bool(false)
+	c, err = b.buildKubeletConfigSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.ClusterDNS != "100.64.0.10" {
+		t.Errorf("expected the kubelet to use the dns service address, got %q", c.ClusterDNS)
+	}
+}
+
 func TestTaintsAppliedAfter160(t *testing.T) {
 	tests := []struct {
 		version           string
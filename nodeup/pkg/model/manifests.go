@@ -35,8 +35,8 @@ var _ fi.ModelBuilder = &ManifestsBuilder{}
 
 // Build creates tasks for copying the manifests
 func (b *ManifestsBuilder) Build(c *fi.ModelBuilderContext) error {
-	// Write etcd manifests (currently etcd <=> master)
-	if b.IsMaster {
+	// Write etcd manifests (on the masters, or on dedicated etcd instances)
+	if b.IsMaster || b.IsEtcd {
 		for _, manifest := range b.NodeupConfig.EtcdManifests {
 			p, err := vfs.Context.BuildVfsPath(manifest)
 			if err != nil {
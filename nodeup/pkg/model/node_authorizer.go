@@ -39,6 +39,11 @@ var _ fi.ModelBuilder = &NodeAuthorizationBuilder{}
 
 // Build is responsible for handling the node authorization client
 func (b *NodeAuthorizationBuilder) Build(c *fi.ModelBuilderContext) error {
+	// @check if we are a dedicated etcd instance, which never registers as a node
+	if b.IsEtcd {
+		return nil
+	}
+
 	// @check if we are a master and download the certificates for the node-authozier
 	if b.UseBootstrapTokens() && b.IsMaster {
 		name := "node-authorizer"
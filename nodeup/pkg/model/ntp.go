@@ -0,0 +1,109 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// chronyConfigPath is the path of the chrony configuration written by kops; it is kept apart from the
+	// configuration shipped with the package, so package installs and upgrades never conflict with it
+	chronyConfigPath = "/etc/chrony-kops.conf"
+)
+
+// NTPBuilder installs and configures chrony to keep the clock of the instance in sync
+type NTPBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &NTPBuilder{}
+
+// Build is responsible for configuring time synchronization
+func (b *NTPBuilder) Build(c *fi.ModelBuilderContext) error {
+	if !b.Cluster.Spec.NTPManaged() {
+		glog.Infof("time synchronization is not managed by kops")
+		return nil
+	}
+
+	var serviceName string
+	switch {
+	case b.Distribution == distros.DistributionCoreOS || b.Distribution == distros.DistributionContainerOS:
+		glog.Infof("Detected %s; won't install chrony, systemd-timesyncd keeps the clock in sync", b.Distribution)
+		return nil
+	case b.Distribution.IsDebianFamily():
+		serviceName = "chrony.service"
+	case b.Distribution.IsRHELFamily():
+		serviceName = "chronyd.service"
+	default:
+		glog.Warningf("unknown distribution %q, won't install chrony", b.Distribution)
+		return nil
+	}
+
+	c.AddTask(&nodetasks.Package{Name: "chrony"})
+
+	c.AddTask(&nodetasks.File{
+		Path:     chronyConfigPath,
+		Contents: fi.NewStringResource(buildChronyConfig(b.Cluster.Spec.NTP.Servers)),
+		Type:     nodetasks.FileType_File,
+		OnChangeExecute: [][]string{
+			{"systemctl", "try-restart", serviceName},
+		},
+	})
+
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "chrony, an NTP client (configured by kops)")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	manifest.Set("Unit", "After", "network.target")
+	manifest.Set("Unit", "Conflicts", "ntp.service ntpd.service systemd-timesyncd.service")
+	manifest.Set("Service", "ExecStart", "/usr/sbin/chronyd -d -f "+chronyConfigPath)
+	manifest.Set("Service", "Restart", "always")
+	manifest.Set("Service", "RestartSec", "10s")
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	service := &nodetasks.Service{
+		Name:       serviceName,
+		Definition: s(manifest.Render()),
+	}
+	service.InitDefaults()
+	c.AddTask(service)
+
+	return nil
+}
+
+// buildChronyConfig returns the chrony configuration syncing with the servers
+func buildChronyConfig(servers []string) string {
+	lines := []string{"# Built by kops - do not edit"}
+	for _, server := range servers {
+		lines = append(lines, "server "+server+" iburst")
+	}
+	lines = append(lines,
+		"driftfile /var/lib/chrony/chrony.drift",
+		// Step the clock if it is badly off during the first updates, rather than slewing it for hours
+		"makestep 1.0 3",
+		"rtcsync",
+	)
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+)
+
+func TestBuildChronyConfig(t *testing.T) {
+	expected := `# Built by kops - do not edit
+server 169.254.169.123 iburst
+server metadata.google.internal iburst
+driftfile /var/lib/chrony/chrony.drift
+makestep 1.0 3
+rtcsync
+`
+	actual := buildChronyConfig([]string{"169.254.169.123", "metadata.google.internal"})
+	if actual != expected {
+		t.Errorf("unexpected chrony config, got:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
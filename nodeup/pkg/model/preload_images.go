@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// PreloadImagesBuilder pulls the container images listed in the instance group spec, before the kubelet is started
+type PreloadImagesBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &PreloadImagesBuilder{}
+
+// Build is responsible for adding a task to pull each image to preload
+func (b *PreloadImagesBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.InstanceGroup == nil || len(b.InstanceGroup.Spec.PreloadImages) == 0 {
+		return nil
+	}
+
+	images, err := b.buildPreloadImages()
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		c.AddTask(&nodetasks.PullImageTask{Image: image})
+	}
+
+	return nil
+}
+
+// buildPreloadImages returns the images to preload, remapped to the assets container registry if one is set
+func (b *PreloadImagesBuilder) buildPreloadImages() ([]string, error) {
+	assetBuilder := assets.NewAssetBuilder(b.Cluster, "")
+
+	var images []string
+	seen := make(map[string]bool)
+	for _, image := range b.InstanceGroup.Spec.PreloadImages {
+		remapped, err := assetBuilder.RemapImage(image)
+		if err != nil {
+			return nil, fmt.Errorf("unable to remap container %q: %v", image, err)
+		}
+		if seen[remapped] {
+			continue
+		}
+		seen[remapped] = true
+		images = append(images, remapped)
+	}
+
+	return images, nil
+}
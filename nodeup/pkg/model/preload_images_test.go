@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestBuildPreloadImages(t *testing.T) {
+	proxy := "proxy.example.com/"
+	b := &PreloadImagesBuilder{
+		NodeupModelContext: &NodeupModelContext{
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: "1.11.0",
+					Assets:            &kops.Assets{ContainerProxy: &proxy},
+				},
+			},
+			InstanceGroup: &kops.InstanceGroup{
+				Spec: kops.InstanceGroupSpec{
+					PreloadImages: []string{
+						"k8s.gcr.io/pause-amd64:3.0",
+						"weaveworks/weave-kube:2.3.0",
+						"docker.io/weaveworks/weave-kube:2.3.0",
+					},
+				},
+			},
+		},
+	}
+
+	actual, err := b.buildPreloadImages()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"proxy.example.com/pause-amd64:3.0",
+		"proxy.example.com/weaveworks/weave-kube:2.3.0",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected images, got %v, expected %v", actual, expected)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	kopsbase "k8s.io/kops"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 
 	"github.com/blang/semver"
@@ -49,7 +51,7 @@ func (t *ProtokubeBuilder) Build(c *fi.ModelBuilderContext) error {
 	useGossip := dns.IsGossipHostname(t.Cluster.Spec.MasterInternalName)
 
 	// check is not a master and we are not using gossip (https://github.com/kubernetes/kops/pull/3091)
-	if !t.IsMaster && !useGossip {
+	if !t.IsMaster && !t.IsEtcd && !useGossip {
 		glog.V(2).Infof("skipping the provisioning of protokube on the nodes")
 		return nil
 	}
@@ -66,7 +68,9 @@ func (t *ProtokubeBuilder) Build(c *fi.ModelBuilderContext) error {
 			Type:     nodetasks.FileType_File,
 			Mode:     s("0400"),
 		})
+	}
 
+	if t.IsMaster || t.IsEtcd {
 		// retrieve the etcd peer certificates and private keys from the keystore
 		if t.UseEtcdTLS() {
 			for _, x := range []string{"etcd", "etcd-client"} {
@@ -198,6 +202,7 @@ type ProtokubeFlags struct {
 	// ClusterID flag is required only for vSphere cloud type, to pass cluster id information to protokube. AWS and GCE workflows ignore this flag.
 	ClusterID                 *string  `json:"cluster-id,omitempty" flag:"cluster-id"`
 	Containerized             *bool    `json:"containerized,omitempty" flag:"containerized"`
+	DNSConfig                 []string `json:"dnsConfig,omitempty" flag:"dns-config,repeat"`
 	DNSInternalSuffix         *string  `json:"dnsInternalSuffix,omitempty" flag:"dns-internal-suffix"`
 	DNSProvider               *string  `json:"dnsProvider,omitempty" flag:"dns"`
 	DNSServer                 *string  `json:"dns-server,omitempty" flag:"dns-server"`
@@ -206,10 +211,18 @@ type ProtokubeFlags struct {
 	EtcdImage                 *string  `json:"etcd-image,omitempty" flag:"etcd-image"`
 	EtcdLeaderElectionTimeout *string  `json:"etcd-election-timeout,omitempty" flag:"etcd-election-timeout"`
 	EtcdHearbeatInterval      *string  `json:"etcd-heartbeat-interval,omitempty" flag:"etcd-heartbeat-interval"`
+	Etcd                      *bool    `json:"etcd,omitempty" flag:"etcd"`
+	GossipListen              *string  `json:"gossip-listen,omitempty" flag:"gossip-listen"`
+	GossipProtocol            *string  `json:"gossip-protocol,omitempty" flag:"gossip-protocol"`
+	GossipSecret              *string  `json:"gossip-secret,omitempty" flag:"gossip-secret"`
+	GossipListenSecondary     *string  `json:"gossip-listen-secondary,omitempty" flag:"gossip-listen-secondary"`
+	GossipProtocolSecondary   *string  `json:"gossip-protocol-secondary,omitempty" flag:"gossip-protocol-secondary"`
+	GossipSecretSecondary     *string  `json:"gossip-secret-secondary,omitempty" flag:"gossip-secret-secondary"`
 	InitializeRBAC            *bool    `json:"initializeRBAC,omitempty" flag:"initialize-rbac"`
 	LogLevel                  *int32   `json:"logLevel,omitempty" flag:"v"`
 	Master                    *bool    `json:"master,omitempty" flag:"master"`
 	PeerTLSCaFile             *string  `json:"peer-ca,omitempty" flag:"peer-ca"`
+	PreferPrivateZones        *bool    `json:"prefer-private-zones,omitempty" flag:"prefer-private-zones"`
 	PeerTLSCertFile           *string  `json:"peer-cert,omitempty" flag:"peer-cert"`
 	PeerTLSKeyFile            *string  `json:"peer-key,omitempty" flag:"peer-key"`
 	TLSAuth                   *bool    `json:"tls-auth,omitempty" flag:"tls-auth"`
@@ -248,6 +261,10 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 		Master:                    b(t.IsMaster),
 	}
 
+	if t.IsEtcd {
+		f.Etcd = fi.Bool(true)
+	}
+
 	f.ManageEtcd = false
 	if len(t.NodeupConfig.EtcdManifests) == 0 {
 		glog.V(4).Infof("no EtcdManifests; protokube will manage etcd")
@@ -316,6 +333,10 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 			// match by id
 			f.Zone = append(f.Zone, "*/"+zone)
 		}
+
+		if t.Cluster.Spec.DNSZoneOptions != nil && t.Cluster.Spec.DNSZoneOptions.Private {
+			f.PreferPrivateZones = fi.Bool(true)
+		}
 	} else {
 		glog.Warningf("DNSZone not specified; protokube won't be able to update DNS")
 		// @TODO: Should we permit wildcard updates if zone is not specified?
@@ -330,6 +351,31 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 		internalSuffix := t.Cluster.Spec.MasterInternalName
 		internalSuffix = strings.TrimPrefix(internalSuffix, "api.")
 		f.DNSInternalSuffix = fi.String(internalSuffix)
+
+		if t.Cluster.Spec.GossipConfig != nil {
+			f.GossipProtocol = t.Cluster.Spec.GossipConfig.Protocol
+			f.GossipListen = t.Cluster.Spec.GossipConfig.Listen
+			f.GossipSecret = t.Cluster.Spec.GossipConfig.Secret
+
+			if t.Cluster.Spec.GossipConfig.Secondary != nil {
+				f.GossipProtocolSecondary = t.Cluster.Spec.GossipConfig.Secondary.Protocol
+				f.GossipListenSecondary = t.Cluster.Spec.GossipConfig.Secondary.Listen
+				f.GossipSecretSecondary = t.Cluster.Spec.GossipConfig.Secondary.Secret
+			}
+		}
+
+		// Gossip seeds on DigitalOcean, Hetzner Cloud and Scaleway are found by the cluster tag or label, so protokube needs the cluster name
+		switch kops.CloudProviderID(t.Cluster.Spec.CloudProvider) {
+		case kops.CloudProviderDO, kops.CloudProviderHetzner, kops.CloudProviderScaleway:
+			f.ClusterID = fi.String(t.Cluster.ObjectMeta.Name)
+		}
+	}
+
+	if f.DNSProvider == nil {
+		if providerID := dns.ExternalProviderID(t.Cluster); providerID != "" {
+			f.DNSProvider = fi.String(providerID)
+			f.DNSConfig = dns.ExternalProviderConfig(t.Cluster)
+		}
 	}
 
 	if t.Cluster.Spec.CloudProvider != "" {
@@ -339,6 +385,7 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 			switch kops.CloudProviderID(t.Cluster.Spec.CloudProvider) {
 			case kops.CloudProviderAWS:
 				f.DNSProvider = fi.String("aws-route53")
+				f.DNSConfig = dns.AWSRoute53ProviderConfig(t.Cluster)
 			case kops.CloudProviderDO:
 				f.DNSProvider = fi.String("digitalocean")
 				f.ClusterID = fi.String(t.Cluster.Name)
@@ -348,6 +395,10 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 				f.DNSProvider = fi.String("coredns")
 				f.ClusterID = fi.String(t.Cluster.ObjectMeta.Name)
 				f.DNSServer = fi.String(*t.Cluster.Spec.CloudConfig.VSphereCoreDNSServer)
+			case kops.CloudProviderHetzner:
+				// Hetzner Cloud has no DNS service; validation requires gossip, which is configured above
+			case kops.CloudProviderScaleway:
+				// Neither has Scaleway
 			default:
 				glog.Warningf("Unknown cloudprovider %q; won't set DNS provider", t.Cluster.Spec.CloudProvider)
 			}
@@ -389,6 +440,22 @@ func (t *ProtokubeBuilder) ProtokubeEnvironmentVariables() string {
 		buffer.WriteString(" ")
 	}
 
+	if kops.CloudProviderID(t.Cluster.Spec.CloudProvider) == kops.CloudProviderAWS {
+		endpoints := awsup.ServiceEndpointEnvVars()
+		var names []string
+		for name := range endpoints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			buffer.WriteString(" -e '")
+			buffer.WriteString(name)
+			buffer.WriteString("=")
+			buffer.WriteString(endpoints[name])
+			buffer.WriteString("' ")
+		}
+	}
+
 	if os.Getenv("S3_ENDPOINT") != "" {
 		buffer.WriteString(" ")
 		buffer.WriteString("-e S3_ENDPOINT=")
@@ -418,6 +485,41 @@ func (t *ProtokubeBuilder) ProtokubeEnvironmentVariables() string {
 		buffer.WriteString(" ")
 	}
 
+	if kops.CloudProviderID(t.Cluster.Spec.CloudProvider) == kops.CloudProviderHetzner && os.Getenv("HCLOUD_TOKEN") != "" {
+		buffer.WriteString(" ")
+		buffer.WriteString("-e 'HCLOUD_TOKEN=")
+		buffer.WriteString(os.Getenv("HCLOUD_TOKEN"))
+		buffer.WriteString("'")
+		buffer.WriteString(" ")
+	}
+
+	if kops.CloudProviderID(t.Cluster.Spec.CloudProvider) == kops.CloudProviderScaleway {
+		for _, name := range []string{"SCW_SECRET_KEY", "SCW_DEFAULT_PROJECT_ID"} {
+			if os.Getenv(name) == "" {
+				continue
+			}
+			buffer.WriteString(" -e '")
+			buffer.WriteString(name)
+			buffer.WriteString("=")
+			buffer.WriteString(os.Getenv(name))
+			buffer.WriteString("' ")
+		}
+	}
+
+	// Pass in the credentials for the external DNS provider
+	if dns.ExternalProviderID(t.Cluster) != "" {
+		for _, name := range []string{"CLOUDFLARE_API_TOKEN", "INFOBLOX_USERNAME", "INFOBLOX_PASSWORD"} {
+			if os.Getenv(name) == "" {
+				continue
+			}
+			buffer.WriteString(" ")
+			buffer.WriteString("-e '" + name + "=")
+			buffer.WriteString(os.Getenv(name))
+			buffer.WriteString("'")
+			buffer.WriteString(" ")
+		}
+	}
+
 	t.writeProxyEnvVars(&buffer)
 
 	return buffer.String()
@@ -17,10 +17,14 @@ limitations under the License.
 package model
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
 
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/tokens"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
@@ -50,10 +54,11 @@ func (b *SecretBuilder) Build(c *fi.ModelBuilderContext) error {
 	}
 
 	if b.SecretStore != nil {
-		key := "dockerconfig"
-		dockercfg, _ := b.SecretStore.Secret(key)
-		if dockercfg != nil {
-			contents := string(dockercfg.Data)
+		contents, err := b.buildDockerConfig()
+		if err != nil {
+			return err
+		}
+		if contents != "" {
 			c.AddTask(&nodetasks.File{
 				Path:     filepath.Join("root", ".docker", "config.json"),
 				Contents: fi.NewStringResource(contents),
@@ -63,8 +68,8 @@ func (b *SecretBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 	}
 
-	// if we are not a master we can stop here
-	if !b.IsMaster {
+	// if we are not running kube-apiserver we can stop here
+	if !b.IsMaster && !b.IsAPIServer {
 		return nil
 	}
 
@@ -166,3 +171,77 @@ func (b *SecretBuilder) allAuthTokens() (map[string]string, error) {
 	}
 	return tokens, nil
 }
+
+// buildDockerConfig returns the docker config.json for the instance, combining the dockerconfig secret
+// with the credentials of the container registry mirrors
+func (b *SecretBuilder) buildDockerConfig() (string, error) {
+	var contents []byte
+	dockercfg, _ := b.SecretStore.Secret("dockerconfig")
+	if dockercfg != nil {
+		contents = dockercfg.Data
+	}
+
+	credentials := make(map[string]string)
+	for _, mirror := range b.Cluster.Spec.ContainerRegistryMirrors {
+		if mirror.CredentialsSecret == "" {
+			continue
+		}
+		secret, err := b.SecretStore.FindSecret(mirror.CredentialsSecret)
+		if err != nil {
+			return "", fmt.Errorf("error reading registry mirror credentials %q: %v", mirror.CredentialsSecret, err)
+		}
+		if secret == nil {
+			return "", fmt.Errorf("registry mirror credentials %q not found", mirror.CredentialsSecret)
+		}
+		credentials[dockerAuthKey(mirror.Registry)] = string(secret.Data)
+	}
+
+	if len(credentials) == 0 {
+		return string(contents), nil
+	}
+
+	return mergeDockerConfigAuths(contents, credentials)
+}
+
+// dockerAuthKey returns the key docker looks up the credentials for a registry with; the daemon presents the
+// credentials of the upstream registry to its mirrors, so the mirror credentials are keyed by the upstream registry
+func dockerAuthKey(registry string) string {
+	if registry == "" || registry == kops.DockerHubRegistry {
+		return "https://index.docker.io/v1/"
+	}
+	return registry
+}
+
+// mergeDockerConfigAuths adds the username:password credentials to the auths of the docker config,
+// leaving any credentials already present in the config untouched
+func mergeDockerConfigAuths(contents []byte, credentials map[string]string) (string, error) {
+	config := make(map[string]interface{})
+	if len(contents) != 0 {
+		if err := json.Unmarshal(contents, &config); err != nil {
+			return "", fmt.Errorf("error parsing dockerconfig secret: %v", err)
+		}
+	}
+
+	auths, ok := config["auths"].(map[string]interface{})
+	if !ok {
+		auths = make(map[string]interface{})
+		config["auths"] = auths
+	}
+
+	for key, credential := range credentials {
+		if _, found := auths[key]; found {
+			glog.Warningf("dockerconfig secret already holds credentials for %q, ignoring the registry mirror credentials", key)
+			continue
+		}
+		auths[key] = map[string]interface{}{
+			"auth": base64.StdEncoding.EncodeToString([]byte(credential)),
+		}
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error building docker config: %v", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergeDockerConfigAuths(t *testing.T) {
+	credentials := map[string]string{
+		dockerAuthKey("docker.io"): "user:password",
+	}
+
+	grid := []struct {
+		Contents string
+		Expected map[string]string
+	}{
+		{
+			Contents: "",
+			Expected: map[string]string{
+				"https://index.docker.io/v1/": "dXNlcjpwYXNzd29yZA==",
+			},
+		},
+		{
+			Contents: `{"auths":{"registry.example.com":{"auth":"b3RoZXI6c2VjcmV0"}}}`,
+			Expected: map[string]string{
+				"https://index.docker.io/v1/": "dXNlcjpwYXNzd29yZA==",
+				"registry.example.com":        "b3RoZXI6c2VjcmV0",
+			},
+		},
+		{
+			// Credentials in the dockerconfig secret take precedence
+			Contents: `{"auths":{"https://index.docker.io/v1/":{"auth":"b3RoZXI6c2VjcmV0"}}}`,
+			Expected: map[string]string{
+				"https://index.docker.io/v1/": "b3RoZXI6c2VjcmV0",
+			},
+		},
+	}
+
+	for _, g := range grid {
+		merged, err := mergeDockerConfigAuths([]byte(g.Contents), credentials)
+		if err != nil {
+			t.Errorf("unexpected error merging %q: %v", g.Contents, err)
+			continue
+		}
+
+		var config struct {
+			Auths map[string]struct {
+				Auth string `json:"auth"`
+			} `json:"auths"`
+		}
+		if err := json.Unmarshal([]byte(merged), &config); err != nil {
+			t.Errorf("error parsing merged config %q: %v", merged, err)
+			continue
+		}
+
+		if len(config.Auths) != len(g.Expected) {
+			t.Errorf("unexpected auths in merged config: %s", merged)
+		}
+		for key, auth := range g.Expected {
+			if config.Auths[key].Auth != auth {
+				t.Errorf("unexpected auth for %q in merged config: %s", key, merged)
+			}
+		}
+	}
+
+	if _, err := mergeDockerConfigAuths([]byte("not json"), credentials); err == nil {
+		t.Errorf("expected error merging invalid dockerconfig")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// SSHAuthorizedKeysBuilder writes the authorized SSH keys of the admin user, when the cluster has SSH credentials
+// beyond the primary key that the cloud installs
+type SSHAuthorizedKeysBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &SSHAuthorizedKeysBuilder{}
+
+// Build is responsible for writing the authorized_keys file of the admin user
+func (b *SSHAuthorizedKeysBuilder) Build(c *fi.ModelBuilderContext) error {
+	if len(b.NodeupConfig.SSHAuthorizedKeys) == 0 {
+		return nil
+	}
+
+	user := ""
+	for _, u := range sshAdminUsers(b.Distribution) {
+		if _, err := os.Stat(filepath.Join("/home", u)); err == nil {
+			user = u
+			break
+		}
+	}
+	if user == "" {
+		glog.Warningf("unable to find the admin user on %s; not adding the additional SSH keys", b.Distribution)
+		return nil
+	}
+
+	var lines []string
+	for _, key := range b.NodeupConfig.SSHAuthorizedKeys {
+		lines = append(lines, strings.TrimSpace(key))
+	}
+
+	c.AddTask(&nodetasks.File{
+		Path:     filepath.Join("/home", user, ".ssh", "authorized_keys"),
+		Contents: fi.NewStringResource(strings.Join(lines, "\n") + "\n"),
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0600"),
+		Owner:    s(user),
+		Group:    s(user),
+	})
+
+	return nil
+}
+
+// sshAdminUsers returns the users to which images of the distribution install the cloud's SSH key, most likely first
+func sshAdminUsers(d distros.Distribution) []string {
+	switch d {
+	case distros.DistributionJessie, distros.DistributionDebian9:
+		return []string{"admin"}
+	case distros.DistributionXenial, distros.DistributionBionic:
+		return []string{"ubuntu"}
+	case distros.DistributionCentos7:
+		// Amazon Linux 2 is identified as centos7
+		return []string{"centos", "ec2-user"}
+	case distros.DistributionRhel7:
+		return []string{"ec2-user", "cloud-user"}
+	case distros.DistributionCoreOS:
+		return []string{"core"}
+	default:
+		// ContainerOS takes its keys from the GCE metadata
+		return nil
+	}
+}
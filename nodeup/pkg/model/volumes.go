@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// ephemeralRaidDevice is the device of the raid0 array of the instance store devices
+const ephemeralRaidDevice = "/dev/md/kops-ephemeral"
+
+// VolumesBuilder formats and mounts the additional volumes of the instance group
+type VolumesBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &VolumesBuilder{}
+
+// findInstanceStoreDevices returns the instance store NVMe devices of the instance, which the kernel names
+// by their model
+var findInstanceStoreDevices = func() ([]string, error) {
+	links, err := filepath.Glob("/dev/disk/by-id/nvme-Amazon_EC2_NVMe_Instance_Storage_*")
+	if err != nil {
+		return nil, fmt.Errorf("error listing instance store devices: %v", err)
+	}
+
+	devices := sets.NewString()
+	for _, link := range links {
+		if strings.Contains(link, "-part") {
+			continue
+		}
+		device, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving device symlink %q: %v", link, err)
+		}
+		devices.Insert(device)
+	}
+	return devices.List(), nil
+}
+
+// Build is responsible for mounting the volumes
+func (b *VolumesBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.InstanceGroup == nil {
+		return nil
+	}
+
+	for _, v := range b.InstanceGroup.Spec.Volumes {
+		if v.Path == "" {
+			// Attached, but left for the user to manage
+			continue
+		}
+
+		device := v.Device
+		if v.Ephemeral {
+			devices, err := findInstanceStoreDevices()
+			if err != nil {
+				return err
+			}
+			if len(devices) == 0 {
+				glog.Warningf("instance type has no instance store NVMe devices; not mounting %q", v.Path)
+				continue
+			}
+
+			device = devices[0]
+			if v.Raid0 && len(devices) > 1 {
+				if err := b.addPackage(c, "mdadm"); err != nil {
+					return err
+				}
+				c.AddTask(&nodetasks.RaidArray{
+					Name:    "ephemeral",
+					Device:  ephemeralRaidDevice,
+					Devices: devices,
+				})
+				device = ephemeralRaidDevice
+			}
+		}
+
+		if v.Filesystem == "xfs" {
+			if err := b.addPackage(c, "xfsprogs"); err != nil {
+				return err
+			}
+		}
+
+		c.AddTask(&nodetasks.MountDiskTask{
+			Name:         volumeTaskName(v),
+			Device:       device,
+			Mountpoint:   v.Path,
+			Filesystem:   v.Filesystem,
+			MountOptions: v.MountOptions,
+		})
+	}
+
+	return nil
+}
+
+// addPackage installs a package needed for the volumes; other distributions are expected to include it
+func (b *VolumesBuilder) addPackage(c *fi.ModelBuilderContext, name string) error {
+	if !b.Distribution.IsDebianFamily() && !b.Distribution.IsRHELFamily() {
+		glog.Infof("not installing %s on distro %q", name, b.Distribution)
+		return nil
+	}
+	return c.EnsureTask(&nodetasks.Package{Name: name})
+}
+
+// volumeTaskName returns the name of the task mounting the volume, derived from its path
+func volumeTaskName(v *kops.VolumeSpec) string {
+	return "volume" + strings.Replace(strings.TrimSuffix(v.Path, "/"), "/", "-", -1)
+}
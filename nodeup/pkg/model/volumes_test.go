@@ -0,0 +1,91 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+func TestVolumesBuilder(t *testing.T) {
+	findInstanceStoreDevicesOriginal := findInstanceStoreDevices
+	defer func() { findInstanceStoreDevices = findInstanceStoreDevicesOriginal }()
+	findInstanceStoreDevices = func() ([]string, error) {
+		return []string{"/dev/nvme1n1", "/dev/nvme2n1"}, nil
+	}
+
+	grid := []struct {
+		volumes  []*kops.VolumeSpec
+		expected []string
+		mounts   map[string]string
+	}{
+		{
+			volumes: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Path: "/data"},
+				{Device: "/dev/xvde"},
+			},
+			expected: []string{"MountDiskTask/volume-data"},
+			mounts:   map[string]string{"volume-data": "/dev/xvdd"},
+		},
+		{
+			volumes: []*kops.VolumeSpec{
+				{Ephemeral: true, Path: "/mnt/scratch/"},
+			},
+			expected: []string{"MountDiskTask/volume-mnt-scratch"},
+			mounts:   map[string]string{"volume-mnt-scratch": "/dev/nvme1n1"},
+		},
+		{
+			volumes: []*kops.VolumeSpec{
+				{Ephemeral: true, Raid0: true, Path: "/scratch", Filesystem: "xfs"},
+			},
+			expected: []string{"MountDiskTask/volume-scratch", "Package/mdadm", "Package/xfsprogs", "RaidArray/ephemeral"},
+			mounts:   map[string]string{"volume-scratch": ephemeralRaidDevice},
+		},
+	}
+
+	for _, g := range grid {
+		b := &VolumesBuilder{
+			NodeupModelContext: &NodeupModelContext{
+				Distribution: distros.DistributionXenial,
+				InstanceGroup: &kops.InstanceGroup{
+					Spec: kops.InstanceGroupSpec{Volumes: g.volumes},
+				},
+			},
+		}
+		c := &fi.ModelBuilderContext{Tasks: make(map[string]fi.Task)}
+		if err := b.Build(c); err != nil {
+			t.Errorf("unexpected error building %v: %v", g.volumes, err)
+			continue
+		}
+
+		var keys []string
+		for key, task := range c.Tasks {
+			keys = append(keys, key)
+			if mount, ok := task.(*nodetasks.MountDiskTask); ok && g.mounts[mount.Name] != mount.Device {
+				t.Errorf("expected %s to mount %q, got %q", mount.Name, g.mounts[mount.Name], mount.Device)
+			}
+		}
+		if !reflect.DeepEqual(sortedStrings(keys), g.expected) {
+			t.Errorf("expected tasks %v, got %v", g.expected, keys)
+		}
+	}
+}
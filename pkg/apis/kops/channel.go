@@ -254,7 +254,9 @@ const (
 	CloudProviderBareMetal CloudProviderID = "baremetal"
 	CloudProviderDO        CloudProviderID = "digitalocean"
 	CloudProviderGCE       CloudProviderID = "gce"
+	CloudProviderHetzner   CloudProviderID = "hetzner"
 	CloudProviderOpenstack CloudProviderID = "openstack"
+	CloudProviderScaleway  CloudProviderID = "scaleway"
 	CloudProviderVSphere   CloudProviderID = "vsphere"
 )
 
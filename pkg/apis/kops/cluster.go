@@ -93,6 +93,10 @@ type ClusterSpec struct {
 	// Note that DNSZone can either by the host name of the zone (containing dots),
 	// or can be an identifier for the zone.
 	DNSZone string `json:"dnsZone,omitempty"`
+	// DNSZoneOptions configures how kops manages the DNS zone, e.g. as a Route53 private hosted zone
+	DNSZoneOptions *DNSZoneOptions `json:"dnsZoneOptions,omitempty"`
+	// GossipConfig configures the gossip protocols used for DNS by gossip clusters (those with names ending in .k8s.local)
+	GossipConfig *GossipConfig `json:"gossipConfig,omitempty"`
 	// AdditionalSANs adds additional Subject Alternate Names to apiserver cert that kops generates
 	AdditionalSANs []string `json:"additionalSans,omitempty"`
 	// ClusterDNSDomain is the suffix we use for internal DNS names (normally cluster.local)
@@ -131,6 +135,13 @@ type ClusterSpec struct {
 	FileAssets []FileAssetSpec `json:"fileAssets,omitempty"`
 	// EtcdClusters stores the configuration for each cluster
 	EtcdClusters []*EtcdClusterSpec `json:"etcdClusters,omitempty"`
+	// NTP configures the time synchronization of the instances
+	NTP *NTPConfig `json:"ntp,omitempty"`
+	// ContainerRegistryMirrors directs container image pulls through mirrors of the upstream registries
+	ContainerRegistryMirrors []ContainerRegistryMirrorSpec `json:"containerRegistryMirrors,omitempty"`
+	// NodeBootReports publishes a report of how long each nodeup task took to the state store, when instances boot.
+	// Instances are granted write access to the bootreports path of the state store (the whole bucket on GCE)
+	NodeBootReports *bool `json:"nodeBootReports,omitempty"`
 	// Component configurations
 	Docker                         *DockerConfig                 `json:"docker,omitempty"`
 	KubeDNS                        *KubeDNSConfig                `json:"kubeDNS,omitempty"`
@@ -154,8 +165,13 @@ type ClusterSpec struct {
 	Authorization *AuthorizationSpec `json:"authorization,omitempty"`
 	// NodeAuthorization defined the custom node authorization configuration
 	NodeAuthorization *NodeAuthorizationSpec `json:"nodeAuthorization,omitempty"`
+	// KopsController runs an in-cluster controller which signs kubelet certificates for nodes that prove their
+	// instance identity, so that no kubelet credentials are placed in the instance userdata (AWS only)
+	KopsController *KopsControllerSpec `json:"kopsController,omitempty"`
 	// Tags for AWS instance groups
 	CloudLabels map[string]string `json:"cloudLabels,omitempty"`
+	// InstanceMetadata is the default instance metadata service configuration for instance groups (AWS only)
+	InstanceMetadata *InstanceMetadataOptions `json:"instanceMetadata,omitempty"`
 	// Hooks for custom actions e.g. on first installation
 	Hooks []HookSpec `json:"hooks,omitempty"`
 	// Assets is alternative locations for files and containers; the API under construction, will remove this comment once this API is fully functional.
@@ -164,8 +180,20 @@ type ClusterSpec struct {
 	IAM *IAMSpec `json:"iam,omitempty"`
 	// EncryptionConfig controls if encryption is enabled
 	EncryptionConfig *bool `json:"encryptionConfig,omitempty"`
+	// EncryptionProvider configures the encryption config that kops generates and stores in the secret store, when EncryptionConfig is enabled
+	EncryptionProvider *EncryptionProviderSpec `json:"encryptionProvider,omitempty"`
 	// Target allows for us to nest extra config for targets such as terraform
 	Target *TargetSpec `json:"target,omitempty"`
+	// SecurityGroupOverrideMode controls what kops does with security groups that replace the ones kops would
+	// create (see InstanceGroupSpec.SecurityGroupOverride): Unmanaged (the default) or Additive
+	SecurityGroupOverrideMode string `json:"securityGroupOverrideMode,omitempty"`
+	// RequireApproval requires a second operator to approve, with kops approve, a plan for kops update cluster --yes
+	// or kops delete cluster --yes before it is executed
+	RequireApproval *bool `json:"requireApproval,omitempty"`
+	// PodSecurity installs a preset pod security policy for the cluster's workloads
+	PodSecurity *PodSecuritySpec `json:"podSecurity,omitempty"`
+	// NetworkPolicy installs default network policies, with a networking provider that enforces them
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
 }
 
 // NodeAuthorizationSpec is used to node authorization
@@ -192,12 +220,65 @@ type NodeAuthorizerSpec struct {
 	TokenTTL *metav1.Duration `json:"tokenTTL,omitempty"`
 }
 
+// KopsControllerSpec configures the kops-controller
+type KopsControllerSpec struct {
+	// Enabled deploys the kops-controller and bootstraps kubelets through it
+	Enabled *bool `json:"enabled,omitempty"`
+	// Image is the location of the container
+	Image string `json:"image,omitempty"`
+	// Port is the port the controller listens on, on the masters
+	Port int `json:"port,omitempty"`
+	// CertificateTTL is the lifetime of the kubelet certificates the controller signs
+	CertificateTTL *metav1.Duration `json:"certificateTTL,omitempty"`
+}
+
 // AddonSpec defines an addon that we want to install in the cluster
 type AddonSpec struct {
 	// Manifest is a path to the manifest that defines the addon
 	Manifest string `json:"manifest,omitempty"`
 }
 
+// EncryptionProviderSpec configures how the kube-apiserver encrypts secrets at rest
+type EncryptionProviderSpec struct {
+	// Type is the encryption provider, either aescbc (the default) or kms
+	Type string `json:"type,omitempty"`
+	// KMS configures the envelope encryption plugin, when the type is kms
+	KMS *KMSEncryptionProviderSpec `json:"kms,omitempty"`
+}
+
+// KMSEncryptionProviderSpec configures a KMS envelope encryption plugin
+type KMSEncryptionProviderSpec struct {
+	// Name is the name of the KMS plugin
+	Name string `json:"name,omitempty"`
+	// Endpoint is the listen address of the KMS plugin, e.g. unix:///var/run/kmsplugin/socket.sock
+	Endpoint string `json:"endpoint,omitempty"`
+	// CacheSize is the number of data encryption keys to cache in memory
+	CacheSize *int32 `json:"cacheSize,omitempty"`
+}
+
+// PodSecuritySpec configures the pod security policies kops installs
+type PodSecuritySpec struct {
+	// Preset is the policy pods outside kube-system must satisfy: restricted, baseline or privileged
+	Preset string `json:"preset,omitempty"`
+}
+
+// NetworkPolicySpec configures the network policies kops installs
+type NetworkPolicySpec struct {
+	// DefaultDeny installs policies denying ingress to the pods in Namespaces from anywhere but kube-system
+	DefaultDeny bool `json:"defaultDeny,omitempty"`
+	// Namespaces are the namespaces the default-deny policies are installed in; defaults to the default namespace
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+const (
+	// PodSecurityPresetRestricted forbids privileged pods, host namespaces and host paths, requires pods to run as non-root and drop all capabilities
+	PodSecurityPresetRestricted = "restricted"
+	// PodSecurityPresetBaseline forbids privileged pods, host namespaces and host paths and added capabilities, but allows pods to run as root
+	PodSecurityPresetBaseline = "baseline"
+	// PodSecurityPresetPrivileged allows any pod
+	PodSecurityPresetPrivileged = "privileged"
+)
+
 // FileAssetSpec defines the structure for a file asset
 type FileAssetSpec struct {
 	// Name is a shortened reference to the asset
@@ -263,16 +344,75 @@ type ExecContainerAction struct {
 type AuthenticationSpec struct {
 	Kopeio *KopeioAuthenticationSpec `json:"kopeio,omitempty"`
 	Aws    *AwsAuthenticationSpec    `json:"aws,omitempty"`
+	// OIDC authenticates users with OpenID Connect tokens, from an external issuer or a managed dex addon
+	OIDC *OIDCAuthenticationSpec `json:"oidc,omitempty"`
 }
 
 func (s *AuthenticationSpec) IsEmpty() bool {
-	return s.Kopeio == nil && s.Aws == nil
+	return s.Kopeio == nil && s.Aws == nil && s.OIDC == nil
 }
 
 type KopeioAuthenticationSpec struct {
 }
 
+// AwsAuthenticationSpec runs aws-iam-authenticator on the masters, so users authenticate with IAM credentials
 type AwsAuthenticationSpec struct {
+	// Image is the aws-iam-authenticator image to run
+	Image string `json:"image,omitempty"`
+	// ClusterID is the identifier tokens must be issued for, to prevent replay against other clusters; defaults to the cluster name
+	ClusterID string `json:"clusterID,omitempty"`
+	// MapRoles maps IAM roles to kubernetes users and groups
+	MapRoles []AwsAuthenticationRoleMapping `json:"mapRoles,omitempty"`
+	// MapUsers maps IAM users to kubernetes users and groups
+	MapUsers []AwsAuthenticationUserMapping `json:"mapUsers,omitempty"`
+}
+
+// AwsAuthenticationRoleMapping maps an IAM role to a kubernetes user and groups
+type AwsAuthenticationRoleMapping struct {
+	// RoleARN is the ARN of the IAM role
+	RoleARN string `json:"roleARN,omitempty"`
+	// Username is the kubernetes user name, which may contain {{AccountID}} and {{SessionName}}
+	Username string `json:"username,omitempty"`
+	// Groups are the kubernetes groups of the user
+	Groups []string `json:"groups,omitempty"`
+}
+
+// AwsAuthenticationUserMapping maps an IAM user to a kubernetes user and groups
+type AwsAuthenticationUserMapping struct {
+	// UserARN is the ARN of the IAM user
+	UserARN string `json:"userARN,omitempty"`
+	// Username is the kubernetes user name
+	Username string `json:"username,omitempty"`
+	// Groups are the kubernetes groups of the user
+	Groups []string `json:"groups,omitempty"`
+}
+
+// OIDCAuthenticationSpec configures the apiserver to accept OpenID Connect tokens
+type OIDCAuthenticationSpec struct {
+	// IssuerURL is the HTTPS URL of the OpenID issuer; with dex, the URL at which dex is exposed
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// ClientID is the client ID for which tokens must be issued
+	ClientID string `json:"clientID,omitempty"`
+	// UsernameClaim is the claim used as the user name (the apiserver defaults to sub)
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// UsernamePrefix is prepended to user names, to prevent clashes with other authenticators
+	UsernamePrefix string `json:"usernamePrefix,omitempty"`
+	// GroupsClaim is the claim used as the user's groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+	// GroupsPrefix is prepended to group names, to prevent clashes with other authenticators
+	GroupsPrefix string `json:"groupsPrefix,omitempty"`
+	// Dex runs dex in the cluster as the issuer, federating the identity providers it is configured with
+	Dex *DexAuthenticationSpec `json:"dex,omitempty"`
+}
+
+// DexAuthenticationSpec configures the managed dex addon
+type DexAuthenticationSpec struct {
+	// Image is the dex image to run
+	Image string `json:"image,omitempty"`
+	// Connectors is the connectors section of the dex configuration, in YAML, naming the upstream identity providers
+	Connectors string `json:"connectors,omitempty"`
+	// RedirectURIs are the redirect URIs allowed for the client, which is public so command line tools can log in
+	RedirectURIs []string `json:"redirectURIs,omitempty"`
 }
 
 type AuthorizationSpec struct {
@@ -284,7 +424,24 @@ func (s *AuthorizationSpec) IsEmpty() bool {
 	return s.RBAC == nil && s.AlwaysAllow == nil
 }
 
+// RBACAuthorizationSpec enables RBAC, and optionally binds groups and a CI service account to the default cluster roles
 type RBACAuthorizationSpec struct {
+	// AdminGroups are bound to the cluster-admin role
+	AdminGroups []string `json:"adminGroups,omitempty"`
+	// ReadOnlyGroups are bound to the view role
+	ReadOnlyGroups []string `json:"readOnlyGroups,omitempty"`
+	// CIServiceAccount creates a service account for continuous integration, bound to a cluster role
+	CIServiceAccount *RBACServiceAccountSpec `json:"ciServiceAccount,omitempty"`
+}
+
+// RBACServiceAccountSpec is a service account bound to a cluster role
+type RBACServiceAccountSpec struct {
+	// Name is the name of the service account
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the service account, defaulting to kube-system
+	Namespace string `json:"namespace,omitempty"`
+	// ClusterRole is the cluster role bound to the service account, defaulting to edit
+	ClusterRole string `json:"clusterRole,omitempty"`
 }
 
 type AlwaysAllowAuthorizationSpec struct {
@@ -320,8 +477,18 @@ type LoadBalancerAccessSpec struct {
 	AdditionalSecurityGroups []string         `json:"additionalSecurityGroups,omitempty"`
 	UseForInternalApi        bool             `json:"useForInternalApi,omitempty"`
 	SSLCertificate           string           `json:"sslCertificate,omitempty"`
+	// SecurityGroupOverride is the ID of a pre-existing security group that the API load balancer uses
+	// instead of the one kops would create
+	SecurityGroupOverride *string `json:"securityGroupOverride,omitempty"`
 }
 
+const (
+	// SecurityGroupOverrideModeUnmanaged uses overridden security groups as they are, without adding any rules
+	SecurityGroupOverrideModeUnmanaged = "Unmanaged"
+	// SecurityGroupOverrideModeAdditive adds the rules kops requires to overridden security groups, but never removes rules
+	SecurityGroupOverrideModeAdditive = "Additive"
+)
+
 // KubeDNSConfig defines the kube dns configuration
 type KubeDNSConfig struct {
 	// CacheMaxSize is the maximum entries to keep in dnsmaq
@@ -332,7 +499,7 @@ type KubeDNSConfig struct {
 	Domain string `json:"domain,omitempty"`
 	// Image is the name of the docker image to run - @deprecated as this is now in the addon
 	Image string `json:"image,omitempty"`
-	// Replicas is the number of pod replicas - @deprecated as this is now in the addon and controlled by autoscaler
+	// Replicas is the number of pod replicas when the autoscaler is disabled
 	Replicas int `json:"replicas,omitempty"`
 	// Provider indicates whether CoreDNS or kube-dns will be the default service discovery.
 	Provider string `json:"provider,omitempty"`
@@ -342,6 +509,38 @@ type KubeDNSConfig struct {
 	StubDomains map[string][]string `json:"stubDomains,omitempty"`
 	// UpstreamNameservers sets the upstream nameservers for queries not on the cluster domain
 	UpstreamNameservers []string `json:"upstreamNameservers,omitempty"`
+	// Autoscaler configures the cluster-proportional-autoscaler which sizes the dns deployment
+	Autoscaler *DNSAutoscalerConfig `json:"autoscaler,omitempty"`
+	// NodeLocalDNS configures the node-local-dns cache which runs on every node
+	NodeLocalDNS *NodeLocalDNSConfig `json:"nodeLocalDNS,omitempty"`
+}
+
+// DNSAutoscalerConfig configures the cluster-proportional-autoscaler for the dns deployment
+type DNSAutoscalerConfig struct {
+	// Disabled turns off the autoscaler, running a fixed number of dns replicas instead
+	Disabled bool `json:"disabled,omitempty"`
+	// CoresPerReplica is the number of cluster cores served by each dns replica (defaults to 256)
+	CoresPerReplica int `json:"coresPerReplica,omitempty"`
+	// NodesPerReplica is the number of cluster nodes served by each dns replica (defaults to 16)
+	NodesPerReplica int `json:"nodesPerReplica,omitempty"`
+	// Min is the minimum number of dns replicas
+	Min int `json:"min,omitempty"`
+	// Max is the maximum number of dns replicas
+	Max int `json:"max,omitempty"`
+}
+
+// NodeLocalDNSConfig configures the node-local-dns cache
+type NodeLocalDNSConfig struct {
+	// Enabled deploys the node-local-dns cache and points the kubelet at it
+	Enabled *bool `json:"enabled,omitempty"`
+	// LocalIP is the link-local address the cache listens on (defaults to 169.254.20.10)
+	LocalIP string `json:"localIP,omitempty"`
+	// CPURequest, cpu request compute resource for the cache e.g. "25m"
+	CPURequest string `json:"cpuRequest,omitempty"`
+	// MemoryRequest, memory request compute resource for the cache e.g. "5Mi"
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	// MemoryLimit, memory limit compute resource for the cache e.g. "30Mi"
+	MemoryLimit string `json:"memoryLimit,omitempty"`
 }
 
 // ExternalDNSConfig are options of the dns-controller
@@ -352,6 +551,30 @@ type ExternalDNSConfig struct {
 	WatchIngress *bool `json:"watchIngress,omitempty"`
 	// WatchNamespace is namespace to watch, defaults to all (use to control whom can creates dns entries)
 	WatchNamespace string `json:"watchNamespace,omitempty"`
+	// Provider is the DNS provider plugin used to manage records (cloudflare or infoblox), instead of the cloud's own DNS service
+	Provider string `json:"provider,omitempty"`
+	// Infoblox configures the infoblox provider
+	Infoblox *InfobloxDNSConfig `json:"infoblox,omitempty"`
+}
+
+const (
+	// ExternalDNSProviderCloudFlare manages DNS records in CloudFlare
+	ExternalDNSProviderCloudFlare = "cloudflare"
+	// ExternalDNSProviderInfoblox manages DNS records in Infoblox
+	ExternalDNSProviderInfoblox = "infoblox"
+)
+
+// InfobloxDNSConfig configures the Infoblox DNS provider; the credentials are read from the
+// INFOBLOX_USERNAME and INFOBLOX_PASSWORD environment variables, and from the infoblox secret in the cluster
+type InfobloxDNSConfig struct {
+	// Host is the hostname of the Infoblox grid master
+	Host string `json:"host,omitempty"`
+	// WAPIVersion is the version of the Infoblox WAPI, defaults to 2.7
+	WAPIVersion string `json:"wapiVersion,omitempty"`
+	// View is the DNS view in which records are managed, defaults to default
+	View string `json:"view,omitempty"`
+	// InsecureSkipVerify disables verification of the grid master's TLS certificate
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 // EtcdClusterSpec is the etcd cluster specification
@@ -384,6 +607,8 @@ type EtcdBackupSpec struct {
 	BackupStore string `json:"backupStore,omitempty"`
 	// Image is the etcd backup manager image to use.  Setting this will create a sidecar container in the etcd pod with the specified image.
 	Image string `json:"image,omitempty"`
+	// Interval is how often etcd-manager takes a backup of the etcd data; only used with etcd-manager
+	Interval *metav1.Duration `json:"interval,omitempty"`
 }
 
 // EtcdManagerSpec describes how we configure the etcd manager
@@ -400,8 +625,10 @@ type EtcdMemberSpec struct {
 	InstanceGroup *string `json:"instanceGroup,omitempty"`
 	// VolumeType is the underlining cloud storage class
 	VolumeType *string `json:"volumeType,omitempty"`
-	// If volume type is io1, then we need to specify the number of Iops.
+	// VolumeIops is the number of provisioned IOPS, for io1, io2 and gp3 volumes
 	VolumeIops *int32 `json:"volumeIops,omitempty"`
+	// VolumeThroughput is the throughput of the volume in MiB/s, for gp3 volumes (AWS only)
+	VolumeThroughput *int32 `json:"volumeThroughput,omitempty"`
 	// VolumeSize is the underlining cloud volume size
 	VolumeSize *int32 `json:"volumeSize,omitempty"`
 	// KmsKeyId is a AWS KMS ID used to encrypt the volume
@@ -422,6 +649,15 @@ const (
 	SubnetTypeUtility SubnetType = "Utility"
 )
 
+const (
+	// EgressNatGateway creates a NAT gateway in every zone with private subnets; this is the default
+	EgressNatGateway = "NatGateway"
+	// EgressSharedNatGateway creates a single NAT gateway which is used by the private subnets in all zones
+	EgressSharedNatGateway = "SharedNatGateway"
+	// EgressNatInstance creates a NAT EC2 instance in every zone with private subnets
+	EgressNatInstance = "NatInstance"
+)
+
 // ClusterSubnetSpec defines a subnet
 type ClusterSubnetSpec struct {
 	// Name is the name of the subnet
@@ -434,7 +670,8 @@ type ClusterSubnetSpec struct {
 	Region string `json:"region,omitempty"`
 	// ProviderID is the cloud provider id for the objects associated with the zone (the subnet on AWS)
 	ProviderID string `json:"id,omitempty"`
-	// Egress defines the method of traffic egress for this subnet
+	// Egress defines the method of traffic egress for this subnet.
+	// It is either one of the Egress* strategies, or the ID of an existing NAT gateway (nat-...) or NAT instance (i-...)
 	Egress string `json:"egress,omitempty"`
 	// Type define which one if the internal types (public, utility, private) the network is
 	Type SubnetType `json:"type,omitempty"`
@@ -455,6 +692,50 @@ type HTTPProxy struct {
 	// Password string `json:"password,omitempty"`
 }
 
+// DNSZoneOptions configures the DNS zone for the cluster; currently only supported on AWS (Route53)
+type DNSZoneOptions struct {
+	// Private makes the cluster zone a private hosted zone, associated with the cluster VPC.
+	// kops creates the zone if it does not already exist.
+	Private bool `json:"private,omitempty"`
+	// AdditionalVPCs are other VPCs (e.g. peered or shared-services VPCs) to associate with the private hosted zone
+	AdditionalVPCs []DNSZoneVPC `json:"additionalVPCs,omitempty"`
+	// SplitHorizon also publishes the API record in the public hosted zone of the same name,
+	// so that the API can be reached from outside the VPC while all other records stay private
+	SplitHorizon bool `json:"splitHorizon,omitempty"`
+	// PublicZone is the ID of the public hosted zone used for SplitHorizon; defaults to the public zone with the same name
+	PublicZone string `json:"publicZone,omitempty"`
+}
+
+// DNSZoneVPC is a VPC associated with a private hosted zone
+type DNSZoneVPC struct {
+	// ID is the ID of the VPC
+	ID string `json:"id,omitempty"`
+	// Region is the region of the VPC; defaults to the cluster region
+	Region string `json:"region,omitempty"`
+}
+
+// GossipConfig configures the gossip protocols used for DNS by gossip clusters
+type GossipConfig struct {
+	// Protocol is the gossip protocol; currently only mesh is supported, which is the default
+	Protocol *string `json:"protocol,omitempty"`
+	// Listen is the address:port on which to listen for gossip; defaults to 0.0.0.0:3999
+	Listen *string `json:"listen,omitempty"`
+	// Secret is used to secure gossip
+	Secret *string `json:"secret,omitempty"`
+	// Secondary is an additional gossip protocol run alongside the primary, so that DNS keeps working if one protocol fails
+	Secondary *GossipConfigSecondary `json:"secondary,omitempty"`
+}
+
+// GossipConfigSecondary configures a secondary gossip protocol
+type GossipConfigSecondary struct {
+	// Protocol is the secondary gossip protocol; currently only http is supported
+	Protocol *string `json:"protocol,omitempty"`
+	// Listen is the address:port on which to listen for the secondary protocol; defaults to 0.0.0.0:3993
+	Listen *string `json:"listen,omitempty"`
+	// Secret is used to secure the secondary protocol
+	Secret *string `json:"secret,omitempty"`
+}
+
 // TargetSpec allows for specifying target config in an extensible way
 type TargetSpec struct {
 	Terraform *TerraformSpec `json:"terraform,omitempty"`
@@ -546,3 +827,23 @@ func (c *Cluster) FillDefaults() error {
 func (c *Cluster) SharedVPC() bool {
 	return c.Spec.NetworkID != ""
 }
+
+// NodeLocalDNSEnabled checks if the node-local-dns cache is enabled
+func (c *KubeDNSConfig) NodeLocalDNSEnabled() bool {
+	return c != nil && c.NodeLocalDNS != nil && c.NodeLocalDNS.Enabled != nil && *c.NodeLocalDNS.Enabled
+}
+
+// KopsControllerEnabled checks if kubelets are bootstrapped through the kops-controller
+func (c *ClusterSpec) KopsControllerEnabled() bool {
+	return c.KopsController != nil && c.KopsController.Enabled != nil && *c.KopsController.Enabled
+}
+
+// NTPManaged returns true if nodeup configures time synchronization on the instances
+func (c *ClusterSpec) NTPManaged() bool {
+	return c.NTP != nil && c.NTP.Managed != nil && *c.NTP.Managed
+}
+
+// NodeBootReportsEnabled returns true if nodeup publishes boot timing reports to the state store
+func (c *ClusterSpec) NodeBootReportsEnabled() bool {
+	return c.NodeBootReports != nil && *c.NodeBootReports
+}
@@ -22,5 +22,11 @@ const AnnotationNameManagement = "kops.kubernetes.io/management"
 // AnnotationValueManagementImported is the annotation value that indicates a cluster was imported, typically as part of an upgrade
 const AnnotationValueManagementImported = "imported"
 
+// AnnotationNamePausedSizes is the annotation that records the sizes of a paused instance group, so they can be restored when it is resumed
+const AnnotationNamePausedSizes = "kops.kubernetes.io/paused-sizes"
+
+// AnnotationNameStoppedInstanceGroups is the annotation that lists the instance groups paused when a cluster was stopped
+const AnnotationNameStoppedInstanceGroups = "kops.kubernetes.io/stopped-instancegroups"
+
 // UpdatePolicyExternal is a value for ClusterSpec.UpdatePolicy indicating that upgrades are done externally, and we should disable automatic upgrades
 const UpdatePolicyExternal = "external"
@@ -48,3 +48,20 @@ func FindZonesForInstanceGroup(c *kops.Cluster, ig *kops.InstanceGroup) ([]strin
 	}
 	return zones.List(), nil
 }
+
+// EtcdClusterRole returns the role of the instance groups that host the members of the etcd cluster.
+// This is InstanceGroupRoleEtcd if the members are placed on dedicated etcd instance groups, otherwise InstanceGroupRoleMaster.
+func EtcdClusterRole(etcdCluster *kops.EtcdClusterSpec, instanceGroups []*kops.InstanceGroup) kops.InstanceGroupRole {
+	for _, m := range etcdCluster.Members {
+		name := ""
+		if m.InstanceGroup != nil {
+			name = *m.InstanceGroup
+		}
+		for _, ig := range instanceGroups {
+			if ig.ObjectMeta.Name == name && ig.Spec.Role == kops.InstanceGroupRoleEtcd {
+				return kops.InstanceGroupRoleEtcd
+			}
+		}
+	}
+	return kops.InstanceGroupRoleMaster
+}
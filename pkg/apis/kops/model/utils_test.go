@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
 )
 
@@ -169,3 +170,47 @@ func Test_FindZonesForInstanceGroup(t *testing.T) {
 		}
 	}
 }
+
+// Test_EtcdClusterRole tests EtcdClusterRole
+func Test_EtcdClusterRole(t *testing.T) {
+	instanceGroups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-a"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleMaster},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "etcd-a"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleEtcd},
+		},
+	}
+
+	grid := []struct {
+		instanceGroup string
+		expected      kops.InstanceGroupRole
+	}{
+		{
+			instanceGroup: "master-a",
+			expected:      kops.InstanceGroupRoleMaster,
+		},
+		{
+			instanceGroup: "etcd-a",
+			expected:      kops.InstanceGroupRoleEtcd,
+		},
+		{
+			instanceGroup: "missing",
+			expected:      kops.InstanceGroupRoleMaster,
+		},
+	}
+	for _, g := range grid {
+		etcdCluster := &kops.EtcdClusterSpec{
+			Name: "main",
+			Members: []*kops.EtcdMemberSpec{
+				{Name: "a", InstanceGroup: &g.instanceGroup},
+			},
+		}
+		actual := EtcdClusterRole(etcdCluster, instanceGroups)
+		if actual != g.expected {
+			t.Errorf("unexpected role for instance group %q: %q vs %q", g.instanceGroup, actual, g.expected)
+		}
+	}
+}
@@ -37,6 +37,25 @@ type TopologySpec struct {
 
 	// DNS configures options relating to DNS, in particular whether we use a public or a private hosted zone
 	DNS *DNSSpec `json:"dns,omitempty"`
+
+	// NatInstance configures the EC2 instances created for private subnets with egress NatInstance
+	NatInstance *NatInstanceSpec `json:"natInstance,omitempty"`
+
+	// SessionManager provides access to the instances through AWS Systems Manager Session Manager, in place of a bastion
+	// and without opening SSH to spec.sshAccess
+	SessionManager *SessionManagerSpec `json:"sessionManager,omitempty"`
+}
+
+// SessionManagerSpec configures access to the instances through AWS Systems Manager Session Manager
+type SessionManagerSpec struct {
+}
+
+// NatInstanceSpec configures the NAT instances kops creates for private subnets
+type NatInstanceSpec struct {
+	// MachineType is the instance type for the NAT instances, defaults to t2.micro
+	MachineType string `json:"machineType,omitempty"`
+	// Image is the image for the NAT instances, defaults to the latest Amazon Linux VPC NAT AMI
+	Image string `json:"image,omitempty"`
 }
 
 type DNSSpec struct {
@@ -26,4 +26,8 @@ type BastionSpec struct {
 	PublicName  string `json:"name,omitempty"`
 	// IdleTimeout is the bastion's Loadbalancer idle timeout
 	IdleTimeout *int64 `json:"idleTimeout,omitempty"`
+	// LoadBalancerType is Public (the default) for an internet-facing bastion ELB, or Internal for one reachable only from the VPC
+	LoadBalancerType LoadBalancerType `json:"loadBalancerType,omitempty"`
+	// AllowedCIDRs are the CIDRs allowed to SSH to the bastion ELB, defaulting to spec.sshAccess
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
 }
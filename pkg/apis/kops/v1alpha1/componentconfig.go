@@ -204,6 +204,10 @@ type KubeProxyConfig struct {
 	Enabled *bool `json:"enabled,omitempty"`
 	// Which proxy mode to use: (userspace, iptables(default), ipvs)
 	ProxyMode string `json:"proxyMode,omitempty" flag:"proxy-mode"`
+	// IPVSScheduler is the ipvs scheduling algorithm used when proxyMode is ipvs e.g. rr, lc, sh
+	IPVSScheduler string `json:"ipvsScheduler,omitempty" flag:"ipvs-scheduler"`
+	// IPVSExcludeCIDRs is a list of CIDRs which the ipvs proxier should not touch when cleaning up ipvs rules
+	IPVSExcludeCIDRs []string `json:"ipvsExcludeCIDRs,omitempty" flag:"ipvs-exclude-cidrs"`
 	// FeatureGates is a series of key pairs used to switch on features for the proxy
 	FeatureGates map[string]string `json:"featureGates,omitempty" flag:"feature-gates"`
 	// Maximum number of NAT connections to track per CPU core (default: 131072)
@@ -212,12 +216,26 @@ type KubeProxyConfig struct {
 	ConntrackMin *int32 `json:"conntrackMin,omitempty" flag:"conntrack-min"`
 }
 
+// ControlPlaneResources are the compute resources of a control plane component's static pod
+type ControlPlaneResources struct {
+	// CPURequest is the cpu request of the component e.g. "150m"
+	CPURequest string `json:"cpuRequest,omitempty"`
+	// CPULimit is the cpu limit of the component e.g. "1"
+	CPULimit string `json:"cpuLimit,omitempty"`
+	// MemoryRequest is the memory request of the component e.g. "512Mi"
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	// MemoryLimit is the memory limit of the component e.g. "2Gi"
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+}
+
 // KubeAPIServerConfig defines the configuration for the kube api
 type KubeAPIServerConfig struct {
 	// Image is the docker container used
 	Image string `json:"image,omitempty"`
 	// LogLevel is the logging level of the api
 	LogLevel int32 `json:"logLevel,omitempty" flag:"v" flag-empty:"0"`
+	// Resources are the compute resources of the static pod, overriding the default cpu request
+	Resources *ControlPlaneResources `json:"resources,omitempty"`
 	// CloudProvider is the name of the cloudProvider we are using, aws, gce etcd
 	CloudProvider string `json:"cloudProvider,omitempty" flag:"cloud-provider"`
 	// SecurePort is the port the kube runs on
@@ -360,6 +378,8 @@ type KubeControllerManagerConfig struct {
 	Master string `json:"master,omitempty" flag:"master"`
 	// LogLevel is the defined logLevel
 	LogLevel int32 `json:"logLevel,omitempty" flag:"v" flag-empty:"0"`
+	// Resources are the compute resources of the static pod, overriding the default cpu request
+	Resources *ControlPlaneResources `json:"resources,omitempty"`
 	// ServiceAccountPrivateKeyFile the location for a certificate for service account signing
 	ServiceAccountPrivateKeyFile string `json:"serviceAccountPrivateKeyFile,omitempty" flag:"service-account-private-key-file"`
 	// Image is the docker image to use
@@ -450,6 +470,8 @@ type KubeSchedulerConfig struct {
 	Master string `json:"master,omitempty" flag:"master"`
 	// LogLevel is the logging level
 	LogLevel int32 `json:"logLevel,omitempty" flag:"v"`
+	// Resources are the compute resources of the static pod, overriding the default cpu request
+	Resources *ControlPlaneResources `json:"resources,omitempty"`
 	// Image is the docker image to use
 	Image string `json:"image,omitempty"`
 	// LeaderElection defines the configuration of leader election client.
@@ -478,6 +500,19 @@ type CloudConfiguration struct {
 	// AWS cloud-config options
 	DisableSecurityGroupIngress *bool   `json:"disableSecurityGroupIngress,omitempty"`
 	ElbSecurityGroup            *string `json:"elbSecurityGroup,omitempty"`
+	// AWSPartition is the AWS partition of the cluster, e.g. aws-cn or aws-us-gov; defaults to the partition of its region
+	AWSPartition *string `json:"awsPartition,omitempty"`
+	// AWSAssumeRole is the IAM role that kops assumes for its AWS API calls
+	AWSAssumeRole *AWSAssumeRoleSpec `json:"awsAssumeRole,omitempty"`
+	// AWSDNSAssumeRole is the IAM role that is assumed to manage the DNS records of the cluster, e.g. when its Route53
+	// hosted zone is in another account
+	AWSDNSAssumeRole *AWSAssumeRoleSpec `json:"awsDNSAssumeRole,omitempty"`
+	// AWSEBSCSIDriver configures the AWS EBS CSI driver
+	AWSEBSCSIDriver *CSIDriverSpec `json:"awsEBSCSIDriver,omitempty"`
+	// GCEPDCSIDriver configures the GCE PD CSI driver
+	GCEPDCSIDriver *CSIDriverSpec `json:"gcePDCSIDriver,omitempty"`
+	// DefaultStorageClass configures the default storage class, which kops creates instead of the fixed storage classes
+	DefaultStorageClass *DefaultStorageClassSpec `json:"defaultStorageClass,omitempty"`
 	// vSphere cloud-config specs
 	VSphereUsername      *string `json:"vSphereUsername,omitempty"`
 	VSpherePassword      *string `json:"vSpherePassword,omitempty"`
@@ -486,6 +521,45 @@ type CloudConfiguration struct {
 	VSphereResourcePool  *string `json:"vSphereResourcePool,omitempty"`
 	VSphereDatastore     *string `json:"vSphereDatastore,omitempty"`
 	VSphereCoreDNSServer *string `json:"vSphereCoreDNSServer,omitempty"`
+	// VSphereEtcdDatastore is the datastore of the etcd disks of the masters, typically a vSAN datastore;
+	// defaults to VSphereDatastore. The disks are kept when masters are replaced.
+	VSphereEtcdDatastore *string `json:"vSphereEtcdDatastore,omitempty"`
+}
+
+// AWSAssumeRoleSpec is an IAM role that kops assumes with STS for its AWS API calls, e.g. to manage a cluster in
+// another account from a central tooling account
+type AWSAssumeRoleSpec struct {
+	// RoleARN is the ARN of the role, e.g. arn:aws:iam::123456789012:role/KopsAdmin
+	RoleARN string `json:"roleARN,omitempty"`
+	// ExternalID is the external id that the trust policy of the role requires, if any
+	ExternalID string `json:"externalID,omitempty"`
+	// SessionName is the name of the role session, which is recorded in CloudTrail; defaults to kops
+	SessionName string `json:"sessionName,omitempty"`
+	// SessionTags are the tags of the role session, for attribute-based access control
+	SessionTags map[string]string `json:"sessionTags,omitempty"`
+}
+
+// CSIDriverSpec configures a CSI driver of the cloud, which kops deploys as an addon
+type CSIDriverSpec struct {
+	// Enabled deploys the CSI driver and makes its storage class the default
+	Enabled *bool `json:"enabled,omitempty"`
+	// Image is the image of the CSI driver; defaults to the version that kops was tested with
+	Image string `json:"image,omitempty"`
+	// Migration turns on the CSIMigration feature gates, so that the volumes of the in-tree volume plugin are managed
+	// by the CSI driver. It requires kubernetes 1.14 or later.
+	Migration *bool `json:"migration,omitempty"`
+}
+
+// DefaultStorageClassSpec configures the default storage class of the cluster
+type DefaultStorageClassSpec struct {
+	// Type is the type of the volumes, e.g. gp3 on AWS or pd-ssd on GCE; defaults to gp2 and pd-standard
+	Type string `json:"type,omitempty"`
+	// Encrypted encrypts the volumes with the default KMS key of the account; only supported on AWS
+	Encrypted *bool `json:"encrypted,omitempty"`
+	// ReclaimPolicy is what happens to a volume when its claim is deleted, Delete or Retain; defaults to Delete
+	ReclaimPolicy string `json:"reclaimPolicy,omitempty"`
+	// AllowVolumeExpansion allows claims to be resized
+	AllowVolumeExpansion *bool `json:"allowVolumeExpansion,omitempty"`
 }
 
 // HasAdmissionController checks if a specific admission controller is enabled
@@ -0,0 +1,28 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// ContainerRegistryMirrorSpec configures a mirror which container images are pulled through
+type ContainerRegistryMirrorSpec struct {
+	// Registry is the upstream registry being mirrored, defaulting to docker.io (the only registry docker can mirror)
+	Registry string `json:"registry,omitempty"`
+	// Endpoints are the URLs of the mirror, tried in order before falling back to the upstream registry
+	Endpoints []string `json:"endpoints,omitempty"`
+	// CredentialsSecret is the name of a kops secret holding the username and password for the mirror,
+	// created with 'kops create secret registrycredentials'
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
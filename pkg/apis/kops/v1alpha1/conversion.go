@@ -29,10 +29,14 @@ import (
 func Convert_v1alpha1_BastionSpec_To_kops_BastionSpec(in *BastionSpec, out *kops.BastionSpec, s conversion.Scope) error {
 	out.BastionPublicName = in.PublicName
 	out.IdleTimeoutSeconds = in.IdleTimeout
+	out.LoadBalancerType = kops.LoadBalancerType(in.LoadBalancerType)
+	out.AllowedCIDRs = in.AllowedCIDRs
 
 	if !in.Enable {
 		out.BastionPublicName = ""
 		out.IdleTimeoutSeconds = nil
+		out.LoadBalancerType = ""
+		out.AllowedCIDRs = nil
 	}
 
 	return nil
@@ -41,6 +45,8 @@ func Convert_v1alpha1_BastionSpec_To_kops_BastionSpec(in *BastionSpec, out *kops
 func Convert_kops_BastionSpec_To_v1alpha1_BastionSpec(in *kops.BastionSpec, out *BastionSpec, s conversion.Scope) error {
 	out.PublicName = in.BastionPublicName
 	out.IdleTimeout = in.IdleTimeoutSeconds
+	out.LoadBalancerType = LoadBalancerType(in.LoadBalancerType)
+	out.AllowedCIDRs = in.AllowedCIDRs
 
 	out.Enable = true
 	out.MachineType = ""
@@ -277,6 +283,14 @@ func Convert_v1alpha1_TopologySpec_To_kops_TopologySpec(in *TopologySpec, out *k
 	} else {
 		out.DNS = nil
 	}
+	if in.NatInstance != nil {
+		out.NatInstance = new(kops.NatInstanceSpec)
+		if err := Convert_v1alpha1_NatInstanceSpec_To_kops_NatInstanceSpec(in.NatInstance, out.NatInstance, s); err != nil {
+			return err
+		}
+	} else {
+		out.NatInstance = nil
+	}
 	return nil
 }
 
@@ -299,5 +313,13 @@ func Convert_kops_TopologySpec_To_v1alpha1_TopologySpec(in *kops.TopologySpec, o
 	} else {
 		out.DNS = nil
 	}
+	if in.NatInstance != nil {
+		out.NatInstance = new(NatInstanceSpec)
+		if err := Convert_kops_NatInstanceSpec_To_v1alpha1_NatInstanceSpec(in.NatInstance, out.NatInstance, s); err != nil {
+			return err
+		}
+	} else {
+		out.NatInstance = nil
+	}
 	return nil
 }
@@ -46,6 +46,20 @@ type InstanceGroupRole string
 const (
 	InstanceGroupRoleMaster InstanceGroupRole = "Master"
 	InstanceGroupRoleNode   InstanceGroupRole = "Node"
+	// InstanceGroupRoleEtcd runs the etcd members on dedicated instances, separate from the masters
+	InstanceGroupRoleEtcd InstanceGroupRole = "Etcd"
+	// InstanceGroupRoleAPIServer runs additional kube-apiserver instances, without etcd, behind the API load balancer
+	InstanceGroupRoleAPIServer InstanceGroupRole = "APIServer"
+)
+
+// InstanceManager describes what manages the instances in an InstanceGroup
+type InstanceManager string
+
+const (
+	// InstanceManagerCloudGroup manages instances with a cloud group (an autoscaling group on AWS)
+	InstanceManagerCloudGroup InstanceManager = "cloudgroup"
+	// InstanceManagerDirect has kops manage each instance directly, without a cloud group
+	InstanceManagerDirect InstanceManager = "direct"
 )
 
 // InstanceGroupSpec is the specification for a instanceGroup
@@ -64,8 +78,10 @@ type InstanceGroupSpec struct {
 	RootVolumeSize *int32 `json:"rootVolumeSize,omitempty"`
 	// RootVolumeType is the type of the EBS root volume to use (e.g. gp2)
 	RootVolumeType *string `json:"rootVolumeType,omitempty"`
-	// If volume type is io1, then we need to specify the number of Iops.
+	// RootVolumeIops is the number of provisioned IOPS, for io1, io2 and gp3 volumes
 	RootVolumeIops *int32 `json:"rootVolumeIops,omitempty"`
+	// RootVolumeThroughput is the throughput of the root volume in MiB/s, for gp3 volumes (AWS only, requires launch templates)
+	RootVolumeThroughput *int32 `json:"rootVolumeThroughput,omitempty"`
 	// RootVolumeOptimization enables EBS optimization for an instance
 	RootVolumeOptimization *bool `json:"rootVolumeOptimization,omitempty"`
 	// Hooks is a list of hooks for this instanceGroup, note: these can override the cluster wide ones if required
@@ -102,6 +118,135 @@ type InstanceGroupSpec struct {
 	DetailedInstanceMonitoring *bool `json:"detailedInstanceMonitoring,omitempty"`
 	// IAMProfileSpec defines the identity of the cloud group iam profile (AWS only).
 	IAM *IAMProfileSpec `json:"iam,omitempty"`
+	// Manager selects what manages the instances in this group: cloudgroup (the default) uses a cloud autoscaling group,
+	// direct has kops launch and replace the instances itself (AWS only)
+	Manager InstanceManager `json:"manager,omitempty"`
+	// CPUCredits is the credit option for CPU usage of burstable instance types, standard or unlimited (AWS only, requires launch templates)
+	CPUCredits *string `json:"cpuCredits,omitempty"`
+	// MixedInstancesPolicy configures the instance types the group can launch (AWS only, requires the direct manager)
+	MixedInstancesPolicy *MixedInstancesPolicySpec `json:"mixedInstancesPolicy,omitempty"`
+	// InstanceMetadata configures the instance metadata service of the instances, overriding the cluster default
+	// (AWS only, requires launch templates)
+	InstanceMetadata *InstanceMetadataOptions `json:"instanceMetadata,omitempty"`
+	// PlacementGroup places the instances in an AWS placement group, which kops creates for the instance group
+	PlacementGroup *PlacementGroupSpec `json:"placementGroup,omitempty"`
+	// Volumes are additional volumes of the instances: EBS volumes to attach, or the instance store NVMe devices
+	// of the instance type. Volumes with a path are formatted and mounted by nodeup. (AWS only)
+	Volumes []*VolumeSpec `json:"volumes,omitempty"`
+	// Regional creates a single regional managed instance group, spread evenly across the zones of the
+	// instance group, instead of one managed instance group per zone (GCE only, node instance groups)
+	Regional *bool `json:"regional,omitempty"`
+	// SecurityGroupOverride is the ID of a pre-existing security group that the instances use instead of
+	// the one kops creates for their role. All instance groups of a role must use the same value. (AWS only)
+	SecurityGroupOverride *string `json:"securityGroupOverride,omitempty"`
+	// ImageAlias is an image name pattern, e.g. 099720109477/ubuntu/images/hvm-ssd/ubuntu-xenial-16.04-amd64-server-*.
+	// kops-operator --refresh-images sets image to the newest image matching it, during the maintenance window. (AWS only)
+	ImageAlias string `json:"imageAlias,omitempty"`
+	// PreloadImages is a list of container images that nodeup pulls during bootstrap, before the kubelet
+	// is started, e.g. the pause, CNI and kube-proxy images and images of critical daemonsets
+	PreloadImages []string `json:"preloadImages,omitempty"`
+	// BareMetal is the inventory of physical hosts backing the instance group, and how they are imaged (baremetal only)
+	BareMetal *BareMetalSpec `json:"bareMetal,omitempty"`
+}
+
+// VolumeSpec defines an additional volume of an instance group
+type VolumeSpec struct {
+	// Device is the device name of an EBS volume, e.g. /dev/xvdd
+	Device string `json:"device,omitempty"`
+	// Ephemeral uses the instance store NVMe devices of the instance type instead of an EBS volume
+	Ephemeral bool `json:"ephemeral,omitempty"`
+	// Raid0 stripes all the instance store NVMe devices into a single raid0 array; otherwise only the
+	// first device is used (ephemeral volumes only)
+	Raid0 bool `json:"raid0,omitempty"`
+	// Size is the size of the EBS volume, in GB
+	Size *int32 `json:"size,omitempty"`
+	// Type is the type of the EBS volume (e.g. gp2), defaulting to gp2
+	Type *string `json:"type,omitempty"`
+	// Iops is the number of provisioned IOPS, for io1, io2 and gp3 volumes
+	Iops *int32 `json:"iops,omitempty"`
+	// Throughput is the throughput of the volume in MiB/s, for gp3 volumes (requires launch templates)
+	Throughput *int32 `json:"throughput,omitempty"`
+	// Encrypted enables encryption of the EBS volume
+	Encrypted *bool `json:"encrypted,omitempty"`
+	// DeleteOnTermination deletes the EBS volume when the instance is terminated, defaulting to true
+	DeleteOnTermination *bool `json:"deleteOnTermination,omitempty"`
+	// Filesystem is the filesystem the volume is formatted with, ext4 (the default) or xfs
+	Filesystem string `json:"filesystem,omitempty"`
+	// Path is where the volume is mounted; volumes without a path are attached but not formatted
+	Path string `json:"path,omitempty"`
+	// MountOptions are additional options used to mount the volume
+	MountOptions []string `json:"mountOptions,omitempty"`
+}
+
+// PlacementGroupSpec defines the AWS placement group of an instance group
+type PlacementGroupSpec struct {
+	// Strategy is the placement strategy: cluster packs the instances close together in a single zone,
+	// spread places each instance on distinct hardware, and partition spreads groups of instances across partitions
+	Strategy string `json:"strategy,omitempty"`
+	// PartitionCount is the number of partitions, between 1 and 7 (partition strategy only)
+	PartitionCount *int32 `json:"partitionCount,omitempty"`
+}
+
+// BareMetalSpec defines the physical hosts of an instance group on the baremetal cloud provider
+type BareMetalSpec struct {
+	// Hosts are the physical machines of the instance group; each one becomes a node
+	Hosts []BareMetalHostSpec `json:"hosts,omitempty"`
+	// PXE configures the network boot that installs the node image on the hosts
+	PXE *PXESpec `json:"pxe,omitempty"`
+	// SSHUser is the user kops connects as to run nodeup on an imaged host; when unset admin and then ubuntu are tried
+	SSHUser string `json:"sshUser,omitempty"`
+}
+
+// BareMetalHostSpec defines a physical machine
+type BareMetalHostSpec struct {
+	// Name is the hostname of the machine, which must be the name its node registers with
+	Name string `json:"name,omitempty"`
+	// Address is the IP address or DNS name that kops connects to over SSH
+	Address string `json:"address,omitempty"`
+	// MACAddress is the MAC address of the network interface the machine boots from
+	MACAddress string `json:"macAddress,omitempty"`
+	// IPMI is the baseboard management controller used to control the power and boot device of the machine
+	IPMI *IPMISpec `json:"ipmi,omitempty"`
+}
+
+// IPMISpec defines how to reach the baseboard management controller of a machine
+type IPMISpec struct {
+	// Address is the IP address or DNS name of the management controller
+	Address string `json:"address,omitempty"`
+	// Username is the IPMI user
+	Username string `json:"username,omitempty"`
+	// PasswordEnv is the environment variable holding the password of the IPMI user, defaulting to IPMI_PASSWORD
+	PasswordEnv string `json:"passwordEnv,omitempty"`
+}
+
+// PXESpec defines the network boot of the hosts of an instance group
+type PXESpec struct {
+	// ConfigBase is the pxelinux.cfg directory served by the PXE server, as a VFS path,
+	// e.g. file:///var/lib/tftpboot/pxelinux.cfg; kops writes a configuration file per MAC address to it
+	ConfigBase string `json:"configBase,omitempty"`
+	// Kernel is the path of the installer kernel, relative to the TFTP root
+	Kernel string `json:"kernel,omitempty"`
+	// Initrd is the path of the installer initrd, relative to the TFTP root
+	Initrd string `json:"initrd,omitempty"`
+	// Args are additional kernel arguments, e.g. the location of the preseed or kickstart file of the installer
+	Args string `json:"args,omitempty"`
+}
+
+// InstanceMetadataOptions configures the instance metadata service of AWS instances
+type InstanceMetadataOptions struct {
+	// HTTPTokens is the state of token usage for instance metadata requests: optional (the default) allows IMDSv1,
+	// required enforces IMDSv2
+	HTTPTokens *string `json:"httpTokens,omitempty"`
+	// HTTPPutResponseHopLimit is the hop limit of the token PUT response, between 1 and 64.
+	// A limit of 1 stops containers that are not on the host network from obtaining a token.
+	HTTPPutResponseHopLimit *int64 `json:"httpPutResponseHopLimit,omitempty"`
+}
+
+// MixedInstancesPolicySpec defines the instance types an instance group can launch
+type MixedInstancesPolicySpec struct {
+	// Instances is the list of instance types to launch, in order of preference; the next type is tried
+	// when there is no capacity for the previous one
+	Instances []string `json:"instances,omitempty"`
 }
 
 // IAMProfileSpec is the AWS IAM Profile to attach to instances in this instance
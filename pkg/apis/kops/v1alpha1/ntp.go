@@ -0,0 +1,25 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// NTPConfig is the configuration for time synchronization on the instances
+type NTPConfig struct {
+	// Managed configures chrony on the instances, defaulting to true; clock skew breaks etcd and TLS
+	Managed *bool `json:"managed,omitempty"`
+	// Servers are the NTP servers, defaulting to the time service of the cloud provider where one exists
+	Servers []string `json:"servers,omitempty"`
+}
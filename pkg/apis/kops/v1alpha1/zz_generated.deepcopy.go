@@ -25,6 +25,29 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSAssumeRoleSpec) DeepCopyInto(out *AWSAssumeRoleSpec) {
+	*out = *in
+	if in.SessionTags != nil {
+		in, out := &in.SessionTags, &out.SessionTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSAssumeRoleSpec.
+func (in *AWSAssumeRoleSpec) DeepCopy() *AWSAssumeRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSAssumeRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AccessSpec) DeepCopyInto(out *AccessSpec) {
 	*out = *in
@@ -168,7 +191,16 @@ func (in *AuthenticationSpec) DeepCopyInto(out *AuthenticationSpec) {
 			*out = nil
 		} else {
 			*out = new(AwsAuthenticationSpec)
-			**out = **in
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(OIDCAuthenticationSpec)
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
@@ -202,7 +234,7 @@ func (in *AuthorizationSpec) DeepCopyInto(out *AuthorizationSpec) {
 			*out = nil
 		} else {
 			*out = new(RBACAuthorizationSpec)
-			**out = **in
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
@@ -218,9 +250,44 @@ func (in *AuthorizationSpec) DeepCopy() *AuthorizationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsAuthenticationRoleMapping) DeepCopyInto(out *AwsAuthenticationRoleMapping) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsAuthenticationRoleMapping.
+func (in *AwsAuthenticationRoleMapping) DeepCopy() *AwsAuthenticationRoleMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsAuthenticationRoleMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AwsAuthenticationSpec) DeepCopyInto(out *AwsAuthenticationSpec) {
 	*out = *in
+	if in.MapRoles != nil {
+		in, out := &in.MapRoles, &out.MapRoles
+		*out = make([]AwsAuthenticationRoleMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MapUsers != nil {
+		in, out := &in.MapUsers, &out.MapUsers
+		*out = make([]AwsAuthenticationUserMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -234,6 +301,84 @@ func (in *AwsAuthenticationSpec) DeepCopy() *AwsAuthenticationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsAuthenticationUserMapping) DeepCopyInto(out *AwsAuthenticationUserMapping) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsAuthenticationUserMapping.
+func (in *AwsAuthenticationUserMapping) DeepCopy() *AwsAuthenticationUserMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsAuthenticationUserMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalHostSpec) DeepCopyInto(out *BareMetalHostSpec) {
+	*out = *in
+	if in.IPMI != nil {
+		in, out := &in.IPMI, &out.IPMI
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(IPMISpec)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostSpec.
+func (in *BareMetalHostSpec) DeepCopy() *BareMetalHostSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalHostSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalSpec) DeepCopyInto(out *BareMetalSpec) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]BareMetalHostSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PXE != nil {
+		in, out := &in.PXE, &out.PXE
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PXESpec)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalSpec.
+func (in *BareMetalSpec) DeepCopy() *BareMetalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BastionSpec) DeepCopyInto(out *BastionSpec) {
 	*out = *in
@@ -246,6 +391,11 @@ func (in *BastionSpec) DeepCopyInto(out *BastionSpec) {
 			**out = **in
 		}
 	}
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -302,6 +452,40 @@ func (in *CNINetworkingSpec) DeepCopy() *CNINetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIDriverSpec) DeepCopyInto(out *CSIDriverSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIDriverSpec.
+func (in *CSIDriverSpec) DeepCopy() *CSIDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CSIDriverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CalicoNetworkingSpec) DeepCopyInto(out *CalicoNetworkingSpec) {
 	*out = *in
@@ -462,6 +646,60 @@ func (in *CloudConfiguration) DeepCopyInto(out *CloudConfiguration) {
 			**out = **in
 		}
 	}
+	if in.AWSPartition != nil {
+		in, out := &in.AWSPartition, &out.AWSPartition
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.AWSAssumeRole != nil {
+		in, out := &in.AWSAssumeRole, &out.AWSAssumeRole
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AWSAssumeRoleSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AWSDNSAssumeRole != nil {
+		in, out := &in.AWSDNSAssumeRole, &out.AWSDNSAssumeRole
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AWSAssumeRoleSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AWSEBSCSIDriver != nil {
+		in, out := &in.AWSEBSCSIDriver, &out.AWSEBSCSIDriver
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CSIDriverSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GCEPDCSIDriver != nil {
+		in, out := &in.GCEPDCSIDriver, &out.GCEPDCSIDriver
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CSIDriverSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.DefaultStorageClass != nil {
+		in, out := &in.DefaultStorageClass, &out.DefaultStorageClass
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DefaultStorageClassSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.VSphereUsername != nil {
 		in, out := &in.VSphereUsername, &out.VSphereUsername
 		if *in == nil {
@@ -525,6 +763,15 @@ func (in *CloudConfiguration) DeepCopyInto(out *CloudConfiguration) {
 			**out = **in
 		}
 	}
+	if in.VSphereEtcdDatastore != nil {
+		in, out := &in.VSphereEtcdDatastore, &out.VSphereEtcdDatastore
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -693,6 +940,24 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.DNSZoneOptions != nil {
+		in, out := &in.DNSZoneOptions, &out.DNSZoneOptions
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DNSZoneOptions)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GossipConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.AdditionalSANs != nil {
 		in, out := &in.AdditionalSANs, &out.AdditionalSANs
 		*out = make([]string, len(*in))
@@ -773,6 +1038,31 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			}
 		}
 	}
+	if in.NTP != nil {
+		in, out := &in.NTP, &out.NTP
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NTPConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ContainerRegistryMirrors != nil {
+		in, out := &in.ContainerRegistryMirrors, &out.ContainerRegistryMirrors
+		*out = make([]ContainerRegistryMirrorSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeBootReports != nil {
+		in, out := &in.NodeBootReports, &out.NodeBootReports
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		if *in == nil {
@@ -917,6 +1207,15 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.KopsController != nil {
+		in, out := &in.KopsController, &out.KopsController
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(KopsControllerSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.CloudLabels != nil {
 		in, out := &in.CloudLabels, &out.CloudLabels
 		*out = make(map[string]string, len(*in))
@@ -924,6 +1223,15 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(InstanceMetadataOptions)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.Hooks != nil {
 		in, out := &in.Hooks, &out.Hooks
 		*out = make([]HookSpec, len(*in))
@@ -958,6 +1266,15 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			**out = **in
 		}
 	}
+	if in.EncryptionProvider != nil {
+		in, out := &in.EncryptionProvider, &out.EncryptionProvider
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(EncryptionProviderSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		if *in == nil {
@@ -967,6 +1284,33 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.RequireApproval != nil {
+		in, out := &in.RequireApproval, &out.RequireApproval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.PodSecurity != nil {
+		in, out := &in.PodSecurity, &out.PodSecurity
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PodSecuritySpec)
+			**out = **in
+		}
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetworkPolicySpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -997,23 +1341,76 @@ func (in *ClusterZoneSpec) DeepCopy() *ClusterZoneSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DNSAccessSpec) DeepCopyInto(out *DNSAccessSpec) {
+func (in *ContainerRegistryMirrorSpec) DeepCopyInto(out *ContainerRegistryMirrorSpec) {
 	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSAccessSpec.
-func (in *DNSAccessSpec) DeepCopy() *DNSAccessSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRegistryMirrorSpec.
+func (in *ContainerRegistryMirrorSpec) DeepCopy() *ContainerRegistryMirrorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DNSAccessSpec)
+	out := new(ContainerRegistryMirrorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DNSSpec) DeepCopyInto(out *DNSSpec) {
+func (in *ControlPlaneResources) DeepCopyInto(out *ControlPlaneResources) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneResources.
+func (in *ControlPlaneResources) DeepCopy() *ControlPlaneResources {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSAccessSpec) DeepCopyInto(out *DNSAccessSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSAccessSpec.
+func (in *DNSAccessSpec) DeepCopy() *DNSAccessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSAccessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSAutoscalerConfig) DeepCopyInto(out *DNSAutoscalerConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSAutoscalerConfig.
+func (in *DNSAutoscalerConfig) DeepCopy() *DNSAutoscalerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSAutoscalerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSSpec) DeepCopyInto(out *DNSSpec) {
 	*out = *in
 	return
 }
@@ -1028,6 +1425,98 @@ func (in *DNSSpec) DeepCopy() *DNSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSZoneOptions) DeepCopyInto(out *DNSZoneOptions) {
+	*out = *in
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]DNSZoneVPC, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZoneOptions.
+func (in *DNSZoneOptions) DeepCopy() *DNSZoneOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSZoneOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSZoneVPC) DeepCopyInto(out *DNSZoneVPC) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSZoneVPC.
+func (in *DNSZoneVPC) DeepCopy() *DNSZoneVPC {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSZoneVPC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultStorageClassSpec) DeepCopyInto(out *DefaultStorageClassSpec) {
+	*out = *in
+	if in.Encrypted != nil {
+		in, out := &in.Encrypted, &out.Encrypted
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.AllowVolumeExpansion != nil {
+		in, out := &in.AllowVolumeExpansion, &out.AllowVolumeExpansion
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultStorageClassSpec.
+func (in *DefaultStorageClassSpec) DeepCopy() *DefaultStorageClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultStorageClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexAuthenticationSpec) DeepCopyInto(out *DexAuthenticationSpec) {
+	*out = *in
+	if in.RedirectURIs != nil {
+		in, out := &in.RedirectURIs, &out.RedirectURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexAuthenticationSpec.
+func (in *DexAuthenticationSpec) DeepCopy() *DexAuthenticationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DexAuthenticationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DockerConfig) DeepCopyInto(out *DockerConfig) {
 	*out = *in
@@ -1208,9 +1697,43 @@ func (in *EgressProxySpec) DeepCopy() *EgressProxySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionProviderSpec) DeepCopyInto(out *EncryptionProviderSpec) {
+	*out = *in
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(KMSEncryptionProviderSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionProviderSpec.
+func (in *EncryptionProviderSpec) DeepCopy() *EncryptionProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdBackupSpec) DeepCopyInto(out *EtcdBackupSpec) {
 	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -1263,7 +1786,7 @@ func (in *EtcdClusterSpec) DeepCopyInto(out *EtcdClusterSpec) {
 			*out = nil
 		} else {
 			*out = new(EtcdBackupSpec)
-			**out = **in
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	if in.Manager != nil {
@@ -1334,6 +1857,15 @@ func (in *EtcdMemberSpec) DeepCopyInto(out *EtcdMemberSpec) {
 			**out = **in
 		}
 	}
+	if in.VolumeThroughput != nil {
+		in, out := &in.VolumeThroughput, &out.VolumeThroughput
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
 	if in.VolumeSize != nil {
 		in, out := &in.VolumeSize, &out.VolumeSize
 		if *in == nil {
@@ -1414,6 +1946,15 @@ func (in *ExternalDNSConfig) DeepCopyInto(out *ExternalDNSConfig) {
 			**out = **in
 		}
 	}
+	if in.Infoblox != nil {
+		in, out := &in.Infoblox, &out.Infoblox
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(InfobloxDNSConfig)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -1480,6 +2021,101 @@ func (in *FlannelNetworkingSpec) DeepCopy() *FlannelNetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GossipConfig) DeepCopyInto(out *GossipConfig) {
+	*out = *in
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.Listen != nil {
+		in, out := &in.Listen, &out.Listen
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GossipConfigSecondary)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GossipConfig.
+func (in *GossipConfig) DeepCopy() *GossipConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GossipConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GossipConfigSecondary) DeepCopyInto(out *GossipConfigSecondary) {
+	*out = *in
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.Listen != nil {
+		in, out := &in.Listen, &out.Listen
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GossipConfigSecondary.
+func (in *GossipConfigSecondary) DeepCopy() *GossipConfigSecondary {
+	if in == nil {
+		return nil
+	}
+	out := new(GossipConfigSecondary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPProxy) DeepCopyInto(out *HTTPProxy) {
 	*out = *in
@@ -1577,6 +2213,38 @@ func (in *IAMSpec) DeepCopy() *IAMSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPMISpec) DeepCopyInto(out *IPMISpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPMISpec.
+func (in *IPMISpec) DeepCopy() *IPMISpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPMISpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfobloxDNSConfig) DeepCopyInto(out *InfobloxDNSConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfobloxDNSConfig.
+func (in *InfobloxDNSConfig) DeepCopy() *InfobloxDNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InfobloxDNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceGroup) DeepCopyInto(out *InstanceGroup) {
 	*out = *in
@@ -1685,6 +2353,15 @@ func (in *InstanceGroupSpec) DeepCopyInto(out *InstanceGroupSpec) {
 			**out = **in
 		}
 	}
+	if in.RootVolumeThroughput != nil {
+		in, out := &in.RootVolumeThroughput, &out.RootVolumeThroughput
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
 	if in.RootVolumeOptimization != nil {
 		in, out := &in.RootVolumeOptimization, &out.RootVolumeOptimization
 		if *in == nil {
@@ -1799,11 +2476,91 @@ func (in *InstanceGroupSpec) DeepCopyInto(out *InstanceGroupSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceGroupSpec.
-func (in *InstanceGroupSpec) DeepCopy() *InstanceGroupSpec {
+	if in.CPUCredits != nil {
+		in, out := &in.CPUCredits, &out.CPUCredits
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.MixedInstancesPolicy != nil {
+		in, out := &in.MixedInstancesPolicy, &out.MixedInstancesPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(MixedInstancesPolicySpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(InstanceMetadataOptions)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PlacementGroup != nil {
+		in, out := &in.PlacementGroup, &out.PlacementGroup
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PlacementGroupSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]*VolumeSpec, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(VolumeSpec)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	if in.Regional != nil {
+		in, out := &in.Regional, &out.Regional
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.SecurityGroupOverride != nil {
+		in, out := &in.SecurityGroupOverride, &out.SecurityGroupOverride
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.PreloadImages != nil {
+		in, out := &in.PreloadImages, &out.PreloadImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BareMetal != nil {
+		in, out := &in.BareMetal, &out.BareMetal
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BareMetalSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceGroupSpec.
+func (in *InstanceGroupSpec) DeepCopy() *InstanceGroupSpec {
 	if in == nil {
 		return nil
 	}
@@ -1812,6 +2569,65 @@ func (in *InstanceGroupSpec) DeepCopy() *InstanceGroupSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceMetadataOptions) DeepCopyInto(out *InstanceMetadataOptions) {
+	*out = *in
+	if in.HTTPTokens != nil {
+		in, out := &in.HTTPTokens, &out.HTTPTokens
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.HTTPPutResponseHopLimit != nil {
+		in, out := &in.HTTPPutResponseHopLimit, &out.HTTPPutResponseHopLimit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceMetadataOptions.
+func (in *InstanceMetadataOptions) DeepCopy() *InstanceMetadataOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceMetadataOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KMSEncryptionProviderSpec) DeepCopyInto(out *KMSEncryptionProviderSpec) {
+	*out = *in
+	if in.CacheSize != nil {
+		in, out := &in.CacheSize, &out.CacheSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KMSEncryptionProviderSpec.
+func (in *KMSEncryptionProviderSpec) DeepCopy() *KMSEncryptionProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KMSEncryptionProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KopeioAuthenticationSpec) DeepCopyInto(out *KopeioAuthenticationSpec) {
 	*out = *in
@@ -1844,9 +2660,52 @@ func (in *KopeioNetworkingSpec) DeepCopy() *KopeioNetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KopsControllerSpec) DeepCopyInto(out *KopsControllerSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.CertificateTTL != nil {
+		in, out := &in.CertificateTTL, &out.CertificateTTL
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KopsControllerSpec.
+func (in *KopsControllerSpec) DeepCopy() *KopsControllerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KopsControllerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeAPIServerConfig) DeepCopyInto(out *KubeAPIServerConfig) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ControlPlaneResources)
+			**out = **in
+		}
+	}
 	if in.EnableBootstrapAuthToken != nil {
 		in, out := &in.EnableBootstrapAuthToken, &out.EnableBootstrapAuthToken
 		if *in == nil {
@@ -2170,6 +3029,15 @@ func (in *KubeAPIServerConfig) DeepCopy() *KubeAPIServerConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeControllerManagerConfig) DeepCopyInto(out *KubeControllerManagerConfig) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ControlPlaneResources)
+			**out = **in
+		}
+	}
 	if in.AllocateNodeCIDRs != nil {
 		in, out := &in.AllocateNodeCIDRs, &out.AllocateNodeCIDRs
 		if *in == nil {
@@ -2345,6 +3213,24 @@ func (in *KubeDNSConfig) DeepCopyInto(out *KubeDNSConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Autoscaler != nil {
+		in, out := &in.Autoscaler, &out.Autoscaler
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DNSAutoscalerConfig)
+			**out = **in
+		}
+	}
+	if in.NodeLocalDNS != nil {
+		in, out := &in.NodeLocalDNS, &out.NodeLocalDNS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NodeLocalDNSConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -2370,6 +3256,11 @@ func (in *KubeProxyConfig) DeepCopyInto(out *KubeProxyConfig) {
 			**out = **in
 		}
 	}
+	if in.IPVSExcludeCIDRs != nil {
+		in, out := &in.IPVSExcludeCIDRs, &out.IPVSExcludeCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.FeatureGates != nil {
 		in, out := &in.FeatureGates, &out.FeatureGates
 		*out = make(map[string]string, len(*in))
@@ -2411,6 +3302,15 @@ func (in *KubeProxyConfig) DeepCopy() *KubeProxyConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeSchedulerConfig) DeepCopyInto(out *KubeSchedulerConfig) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ControlPlaneResources)
+			**out = **in
+		}
+	}
 	if in.LeaderElection != nil {
 		in, out := &in.LeaderElection, &out.LeaderElection
 		if *in == nil {
@@ -2881,6 +3781,15 @@ func (in *LoadBalancerAccessSpec) DeepCopyInto(out *LoadBalancerAccessSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SecurityGroupOverride != nil {
+		in, out := &in.SecurityGroupOverride, &out.SecurityGroupOverride
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -2894,6 +3803,94 @@ func (in *LoadBalancerAccessSpec) DeepCopy() *LoadBalancerAccessSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MixedInstancesPolicySpec) DeepCopyInto(out *MixedInstancesPolicySpec) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MixedInstancesPolicySpec.
+func (in *MixedInstancesPolicySpec) DeepCopy() *MixedInstancesPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MixedInstancesPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NTPConfig) DeepCopyInto(out *NTPConfig) {
+	*out = *in
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NTPConfig.
+func (in *NTPConfig) DeepCopy() *NTPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NTPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatInstanceSpec) DeepCopyInto(out *NatInstanceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatInstanceSpec.
+func (in *NatInstanceSpec) DeepCopy() *NatInstanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatInstanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicySpec) DeepCopyInto(out *NetworkPolicySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicySpec.
+func (in *NetworkPolicySpec) DeepCopy() *NetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkingSpec) DeepCopyInto(out *NetworkingSpec) {
 	*out = *in
@@ -3099,9 +4096,135 @@ func (in *NodeAuthorizerSpec) DeepCopy() *NodeAuthorizerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLocalDNSConfig) DeepCopyInto(out *NodeLocalDNSConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLocalDNSConfig.
+func (in *NodeLocalDNSConfig) DeepCopy() *NodeLocalDNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLocalDNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCAuthenticationSpec) DeepCopyInto(out *OIDCAuthenticationSpec) {
+	*out = *in
+	if in.Dex != nil {
+		in, out := &in.Dex, &out.Dex
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DexAuthenticationSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCAuthenticationSpec.
+func (in *OIDCAuthenticationSpec) DeepCopy() *OIDCAuthenticationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCAuthenticationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PXESpec) DeepCopyInto(out *PXESpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PXESpec.
+func (in *PXESpec) DeepCopy() *PXESpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PXESpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementGroupSpec) DeepCopyInto(out *PlacementGroupSpec) {
+	*out = *in
+	if in.PartitionCount != nil {
+		in, out := &in.PartitionCount, &out.PartitionCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementGroupSpec.
+func (in *PlacementGroupSpec) DeepCopy() *PlacementGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSecuritySpec) DeepCopyInto(out *PodSecuritySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSecuritySpec.
+func (in *PodSecuritySpec) DeepCopy() *PodSecuritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSecuritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RBACAuthorizationSpec) DeepCopyInto(out *RBACAuthorizationSpec) {
 	*out = *in
+	if in.AdminGroups != nil {
+		in, out := &in.AdminGroups, &out.AdminGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadOnlyGroups != nil {
+		in, out := &in.ReadOnlyGroups, &out.ReadOnlyGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CIServiceAccount != nil {
+		in, out := &in.CIServiceAccount, &out.CIServiceAccount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RBACServiceAccountSpec)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -3115,6 +4238,22 @@ func (in *RBACAuthorizationSpec) DeepCopy() *RBACAuthorizationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACServiceAccountSpec) DeepCopyInto(out *RBACServiceAccountSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACServiceAccountSpec.
+func (in *RBACServiceAccountSpec) DeepCopy() *RBACServiceAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACServiceAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RomanaNetworkingSpec) DeepCopyInto(out *RomanaNetworkingSpec) {
 	*out = *in
@@ -3207,6 +4346,22 @@ func (in *SSHCredentialSpec) DeepCopy() *SSHCredentialSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionManagerSpec) DeepCopyInto(out *SessionManagerSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionManagerSpec.
+func (in *SessionManagerSpec) DeepCopy() *SessionManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetSpec) DeepCopyInto(out *TargetSpec) {
 	*out = *in
@@ -3284,6 +4439,24 @@ func (in *TopologySpec) DeepCopyInto(out *TopologySpec) {
 			**out = **in
 		}
 	}
+	if in.NatInstance != nil {
+		in, out := &in.NatInstance, &out.NatInstance
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NatInstanceSpec)
+			**out = **in
+		}
+	}
+	if in.SessionManager != nil {
+		in, out := &in.SessionManager, &out.SessionManager
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SessionManagerSpec)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -3313,6 +4486,81 @@ func (in *UserData) DeepCopy() *UserData {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.Iops != nil {
+		in, out := &in.Iops, &out.Iops
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Throughput != nil {
+		in, out := &in.Throughput, &out.Throughput
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Encrypted != nil {
+		in, out := &in.Encrypted, &out.Encrypted
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.DeleteOnTermination != nil {
+		in, out := &in.DeleteOnTermination, &out.DeleteOnTermination
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSpec.
+func (in *VolumeSpec) DeepCopy() *VolumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WeaveNetworkingSpec) DeepCopyInto(out *WeaveNetworkingSpec) {
 	*out = *in
@@ -20,4 +20,8 @@ type BastionSpec struct {
 	BastionPublicName string `json:"bastionPublicName,omitempty"`
 	// IdleTimeoutSeconds is the bastion's Loadbalancer idle timeout
 	IdleTimeoutSeconds *int64 `json:"idleTimeoutSeconds,omitempty"`
+	// LoadBalancerType is Public (the default) for an internet-facing bastion ELB, or Internal for one reachable only from the VPC
+	LoadBalancerType LoadBalancerType `json:"loadBalancerType,omitempty"`
+	// AllowedCIDRs are the CIDRs allowed to SSH to the bastion ELB, defaulting to spec.sshAccess
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
 }
@@ -34,6 +34,8 @@ func init() {
 // Public to allow building arbitrary schemes.
 func RegisterConversions(scheme *runtime.Scheme) error {
 	return scheme.AddGeneratedConversionFuncs(
+		Convert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec,
+		Convert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec,
 		Convert_v1alpha2_AccessSpec_To_kops_AccessSpec,
 		Convert_kops_AccessSpec_To_v1alpha2_AccessSpec,
 		Convert_v1alpha2_AddonSpec_To_kops_AddonSpec,
@@ -48,12 +50,22 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_AuthenticationSpec_To_v1alpha2_AuthenticationSpec,
 		Convert_v1alpha2_AuthorizationSpec_To_kops_AuthorizationSpec,
 		Convert_kops_AuthorizationSpec_To_v1alpha2_AuthorizationSpec,
+		Convert_v1alpha2_AwsAuthenticationRoleMapping_To_kops_AwsAuthenticationRoleMapping,
+		Convert_kops_AwsAuthenticationRoleMapping_To_v1alpha2_AwsAuthenticationRoleMapping,
 		Convert_v1alpha2_AwsAuthenticationSpec_To_kops_AwsAuthenticationSpec,
 		Convert_kops_AwsAuthenticationSpec_To_v1alpha2_AwsAuthenticationSpec,
+		Convert_v1alpha2_AwsAuthenticationUserMapping_To_kops_AwsAuthenticationUserMapping,
+		Convert_kops_AwsAuthenticationUserMapping_To_v1alpha2_AwsAuthenticationUserMapping,
+		Convert_v1alpha2_BareMetalHostSpec_To_kops_BareMetalHostSpec,
+		Convert_kops_BareMetalHostSpec_To_v1alpha2_BareMetalHostSpec,
+		Convert_v1alpha2_BareMetalSpec_To_kops_BareMetalSpec,
+		Convert_kops_BareMetalSpec_To_v1alpha2_BareMetalSpec,
 		Convert_v1alpha2_BastionSpec_To_kops_BastionSpec,
 		Convert_kops_BastionSpec_To_v1alpha2_BastionSpec,
 		Convert_v1alpha2_CNINetworkingSpec_To_kops_CNINetworkingSpec,
 		Convert_kops_CNINetworkingSpec_To_v1alpha2_CNINetworkingSpec,
+		Convert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec,
+		Convert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec,
 		Convert_v1alpha2_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec,
 		Convert_kops_CalicoNetworkingSpec_To_v1alpha2_CalicoNetworkingSpec,
 		Convert_v1alpha2_CanalNetworkingSpec_To_kops_CanalNetworkingSpec,
@@ -74,14 +86,30 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_ClusterSpec_To_v1alpha2_ClusterSpec,
 		Convert_v1alpha2_ClusterSubnetSpec_To_kops_ClusterSubnetSpec,
 		Convert_kops_ClusterSubnetSpec_To_v1alpha2_ClusterSubnetSpec,
+		Convert_v1alpha2_ContainerRegistryMirrorSpec_To_kops_ContainerRegistryMirrorSpec,
+		Convert_kops_ContainerRegistryMirrorSpec_To_v1alpha2_ContainerRegistryMirrorSpec,
+		Convert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources,
+		Convert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources,
 		Convert_v1alpha2_DNSAccessSpec_To_kops_DNSAccessSpec,
 		Convert_kops_DNSAccessSpec_To_v1alpha2_DNSAccessSpec,
+		Convert_v1alpha2_DNSAutoscalerConfig_To_kops_DNSAutoscalerConfig,
+		Convert_kops_DNSAutoscalerConfig_To_v1alpha2_DNSAutoscalerConfig,
 		Convert_v1alpha2_DNSSpec_To_kops_DNSSpec,
 		Convert_kops_DNSSpec_To_v1alpha2_DNSSpec,
+		Convert_v1alpha2_DNSZoneOptions_To_kops_DNSZoneOptions,
+		Convert_kops_DNSZoneOptions_To_v1alpha2_DNSZoneOptions,
+		Convert_v1alpha2_DNSZoneVPC_To_kops_DNSZoneVPC,
+		Convert_kops_DNSZoneVPC_To_v1alpha2_DNSZoneVPC,
+		Convert_v1alpha2_DefaultStorageClassSpec_To_kops_DefaultStorageClassSpec,
+		Convert_kops_DefaultStorageClassSpec_To_v1alpha2_DefaultStorageClassSpec,
+		Convert_v1alpha2_DexAuthenticationSpec_To_kops_DexAuthenticationSpec,
+		Convert_kops_DexAuthenticationSpec_To_v1alpha2_DexAuthenticationSpec,
 		Convert_v1alpha2_DockerConfig_To_kops_DockerConfig,
 		Convert_kops_DockerConfig_To_v1alpha2_DockerConfig,
 		Convert_v1alpha2_EgressProxySpec_To_kops_EgressProxySpec,
 		Convert_kops_EgressProxySpec_To_v1alpha2_EgressProxySpec,
+		Convert_v1alpha2_EncryptionProviderSpec_To_kops_EncryptionProviderSpec,
+		Convert_kops_EncryptionProviderSpec_To_v1alpha2_EncryptionProviderSpec,
 		Convert_v1alpha2_EtcdBackupSpec_To_kops_EtcdBackupSpec,
 		Convert_kops_EtcdBackupSpec_To_v1alpha2_EtcdBackupSpec,
 		Convert_v1alpha2_EtcdClusterSpec_To_kops_EtcdClusterSpec,
@@ -100,6 +128,10 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_FileAssetSpec_To_v1alpha2_FileAssetSpec,
 		Convert_v1alpha2_FlannelNetworkingSpec_To_kops_FlannelNetworkingSpec,
 		Convert_kops_FlannelNetworkingSpec_To_v1alpha2_FlannelNetworkingSpec,
+		Convert_v1alpha2_GossipConfig_To_kops_GossipConfig,
+		Convert_kops_GossipConfig_To_v1alpha2_GossipConfig,
+		Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary,
+		Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary,
 		Convert_v1alpha2_HTTPProxy_To_kops_HTTPProxy,
 		Convert_kops_HTTPProxy_To_v1alpha2_HTTPProxy,
 		Convert_v1alpha2_HookSpec_To_kops_HookSpec,
@@ -108,12 +140,20 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_IAMProfileSpec_To_v1alpha2_IAMProfileSpec,
 		Convert_v1alpha2_IAMSpec_To_kops_IAMSpec,
 		Convert_kops_IAMSpec_To_v1alpha2_IAMSpec,
+		Convert_v1alpha2_IPMISpec_To_kops_IPMISpec,
+		Convert_kops_IPMISpec_To_v1alpha2_IPMISpec,
+		Convert_v1alpha2_InfobloxDNSConfig_To_kops_InfobloxDNSConfig,
+		Convert_kops_InfobloxDNSConfig_To_v1alpha2_InfobloxDNSConfig,
 		Convert_v1alpha2_InstanceGroup_To_kops_InstanceGroup,
 		Convert_kops_InstanceGroup_To_v1alpha2_InstanceGroup,
 		Convert_v1alpha2_InstanceGroupList_To_kops_InstanceGroupList,
 		Convert_kops_InstanceGroupList_To_v1alpha2_InstanceGroupList,
 		Convert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec,
 		Convert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec,
+		Convert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions,
+		Convert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions,
+		Convert_v1alpha2_KMSEncryptionProviderSpec_To_kops_KMSEncryptionProviderSpec,
+		Convert_kops_KMSEncryptionProviderSpec_To_v1alpha2_KMSEncryptionProviderSpec,
 		Convert_v1alpha2_Keyset_To_kops_Keyset,
 		Convert_kops_Keyset_To_v1alpha2_Keyset,
 		Convert_v1alpha2_KeysetItem_To_kops_KeysetItem,
@@ -126,6 +166,8 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_KopeioAuthenticationSpec_To_v1alpha2_KopeioAuthenticationSpec,
 		Convert_v1alpha2_KopeioNetworkingSpec_To_kops_KopeioNetworkingSpec,
 		Convert_kops_KopeioNetworkingSpec_To_v1alpha2_KopeioNetworkingSpec,
+		Convert_v1alpha2_KopsControllerSpec_To_kops_KopsControllerSpec,
+		Convert_kops_KopsControllerSpec_To_v1alpha2_KopsControllerSpec,
 		Convert_v1alpha2_KubeAPIServerConfig_To_kops_KubeAPIServerConfig,
 		Convert_kops_KubeAPIServerConfig_To_v1alpha2_KubeAPIServerConfig,
 		Convert_v1alpha2_KubeControllerManagerConfig_To_kops_KubeControllerManagerConfig,
@@ -148,14 +190,34 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_LoadBalancer_To_v1alpha2_LoadBalancer,
 		Convert_v1alpha2_LoadBalancerAccessSpec_To_kops_LoadBalancerAccessSpec,
 		Convert_kops_LoadBalancerAccessSpec_To_v1alpha2_LoadBalancerAccessSpec,
+		Convert_v1alpha2_MixedInstancesPolicySpec_To_kops_MixedInstancesPolicySpec,
+		Convert_kops_MixedInstancesPolicySpec_To_v1alpha2_MixedInstancesPolicySpec,
+		Convert_v1alpha2_NTPConfig_To_kops_NTPConfig,
+		Convert_kops_NTPConfig_To_v1alpha2_NTPConfig,
+		Convert_v1alpha2_NatInstanceSpec_To_kops_NatInstanceSpec,
+		Convert_kops_NatInstanceSpec_To_v1alpha2_NatInstanceSpec,
+		Convert_v1alpha2_NetworkPolicySpec_To_kops_NetworkPolicySpec,
+		Convert_kops_NetworkPolicySpec_To_v1alpha2_NetworkPolicySpec,
 		Convert_v1alpha2_NetworkingSpec_To_kops_NetworkingSpec,
 		Convert_kops_NetworkingSpec_To_v1alpha2_NetworkingSpec,
 		Convert_v1alpha2_NodeAuthorizationSpec_To_kops_NodeAuthorizationSpec,
 		Convert_kops_NodeAuthorizationSpec_To_v1alpha2_NodeAuthorizationSpec,
 		Convert_v1alpha2_NodeAuthorizerSpec_To_kops_NodeAuthorizerSpec,
 		Convert_kops_NodeAuthorizerSpec_To_v1alpha2_NodeAuthorizerSpec,
+		Convert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig,
+		Convert_kops_NodeLocalDNSConfig_To_v1alpha2_NodeLocalDNSConfig,
+		Convert_v1alpha2_OIDCAuthenticationSpec_To_kops_OIDCAuthenticationSpec,
+		Convert_kops_OIDCAuthenticationSpec_To_v1alpha2_OIDCAuthenticationSpec,
+		Convert_v1alpha2_PXESpec_To_kops_PXESpec,
+		Convert_kops_PXESpec_To_v1alpha2_PXESpec,
+		Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec,
+		Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec,
+		Convert_v1alpha2_PodSecuritySpec_To_kops_PodSecuritySpec,
+		Convert_kops_PodSecuritySpec_To_v1alpha2_PodSecuritySpec,
 		Convert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec,
 		Convert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec,
+		Convert_v1alpha2_RBACServiceAccountSpec_To_kops_RBACServiceAccountSpec,
+		Convert_kops_RBACServiceAccountSpec_To_v1alpha2_RBACServiceAccountSpec,
 		Convert_v1alpha2_RomanaNetworkingSpec_To_kops_RomanaNetworkingSpec,
 		Convert_kops_RomanaNetworkingSpec_To_v1alpha2_RomanaNetworkingSpec,
 		Convert_v1alpha2_SSHCredential_To_kops_SSHCredential,
@@ -164,6 +226,8 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_SSHCredentialList_To_v1alpha2_SSHCredentialList,
 		Convert_v1alpha2_SSHCredentialSpec_To_kops_SSHCredentialSpec,
 		Convert_kops_SSHCredentialSpec_To_v1alpha2_SSHCredentialSpec,
+		Convert_v1alpha2_SessionManagerSpec_To_kops_SessionManagerSpec,
+		Convert_kops_SessionManagerSpec_To_v1alpha2_SessionManagerSpec,
 		Convert_v1alpha2_TargetSpec_To_kops_TargetSpec,
 		Convert_kops_TargetSpec_To_v1alpha2_TargetSpec,
 		Convert_v1alpha2_TerraformSpec_To_kops_TerraformSpec,
@@ -172,11 +236,39 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_TopologySpec_To_v1alpha2_TopologySpec,
 		Convert_v1alpha2_UserData_To_kops_UserData,
 		Convert_kops_UserData_To_v1alpha2_UserData,
+		Convert_v1alpha2_VolumeSpec_To_kops_VolumeSpec,
+		Convert_kops_VolumeSpec_To_v1alpha2_VolumeSpec,
 		Convert_v1alpha2_WeaveNetworkingSpec_To_kops_WeaveNetworkingSpec,
 		Convert_kops_WeaveNetworkingSpec_To_v1alpha2_WeaveNetworkingSpec,
 	)
 }
 
+func autoConvert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec(in *AWSAssumeRoleSpec, out *kops.AWSAssumeRoleSpec, s conversion.Scope) error {
+	out.RoleARN = in.RoleARN
+	out.ExternalID = in.ExternalID
+	out.SessionName = in.SessionName
+	out.SessionTags = in.SessionTags
+	return nil
+}
+
+// Convert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec is an autogenerated conversion function.
+func Convert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec(in *AWSAssumeRoleSpec, out *kops.AWSAssumeRoleSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec(in, out, s)
+}
+
+func autoConvert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec(in *kops.AWSAssumeRoleSpec, out *AWSAssumeRoleSpec, s conversion.Scope) error {
+	out.RoleARN = in.RoleARN
+	out.ExternalID = in.ExternalID
+	out.SessionName = in.SessionName
+	out.SessionTags = in.SessionTags
+	return nil
+}
+
+// Convert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec is an autogenerated conversion function.
+func Convert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec(in *kops.AWSAssumeRoleSpec, out *AWSAssumeRoleSpec, s conversion.Scope) error {
+	return autoConvert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_AccessSpec_To_kops_AccessSpec(in *AccessSpec, out *kops.AccessSpec, s conversion.Scope) error {
 	if in.DNS != nil {
 		in, out := &in.DNS, &out.DNS
@@ -332,6 +424,15 @@ func autoConvert_v1alpha2_AuthenticationSpec_To_kops_AuthenticationSpec(in *Auth
 	} else {
 		out.Aws = nil
 	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(kops.OIDCAuthenticationSpec)
+		if err := Convert_v1alpha2_OIDCAuthenticationSpec_To_kops_OIDCAuthenticationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.OIDC = nil
+	}
 	return nil
 }
 
@@ -359,6 +460,15 @@ func autoConvert_kops_AuthenticationSpec_To_v1alpha2_AuthenticationSpec(in *kops
 	} else {
 		out.Aws = nil
 	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCAuthenticationSpec)
+		if err := Convert_kops_OIDCAuthenticationSpec_To_v1alpha2_OIDCAuthenticationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.OIDC = nil
+	}
 	return nil
 }
 
@@ -421,7 +531,55 @@ func Convert_kops_AuthorizationSpec_To_v1alpha2_AuthorizationSpec(in *kops.Autho
 	return autoConvert_kops_AuthorizationSpec_To_v1alpha2_AuthorizationSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_AwsAuthenticationRoleMapping_To_kops_AwsAuthenticationRoleMapping(in *AwsAuthenticationRoleMapping, out *kops.AwsAuthenticationRoleMapping, s conversion.Scope) error {
+	out.RoleARN = in.RoleARN
+	out.Username = in.Username
+	out.Groups = in.Groups
+	return nil
+}
+
+// Convert_v1alpha2_AwsAuthenticationRoleMapping_To_kops_AwsAuthenticationRoleMapping is an autogenerated conversion function.
+func Convert_v1alpha2_AwsAuthenticationRoleMapping_To_kops_AwsAuthenticationRoleMapping(in *AwsAuthenticationRoleMapping, out *kops.AwsAuthenticationRoleMapping, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AwsAuthenticationRoleMapping_To_kops_AwsAuthenticationRoleMapping(in, out, s)
+}
+
+func autoConvert_kops_AwsAuthenticationRoleMapping_To_v1alpha2_AwsAuthenticationRoleMapping(in *kops.AwsAuthenticationRoleMapping, out *AwsAuthenticationRoleMapping, s conversion.Scope) error {
+	out.RoleARN = in.RoleARN
+	out.Username = in.Username
+	out.Groups = in.Groups
+	return nil
+}
+
+// Convert_kops_AwsAuthenticationRoleMapping_To_v1alpha2_AwsAuthenticationRoleMapping is an autogenerated conversion function.
+func Convert_kops_AwsAuthenticationRoleMapping_To_v1alpha2_AwsAuthenticationRoleMapping(in *kops.AwsAuthenticationRoleMapping, out *AwsAuthenticationRoleMapping, s conversion.Scope) error {
+	return autoConvert_kops_AwsAuthenticationRoleMapping_To_v1alpha2_AwsAuthenticationRoleMapping(in, out, s)
+}
+
 func autoConvert_v1alpha2_AwsAuthenticationSpec_To_kops_AwsAuthenticationSpec(in *AwsAuthenticationSpec, out *kops.AwsAuthenticationSpec, s conversion.Scope) error {
+	out.Image = in.Image
+	out.ClusterID = in.ClusterID
+	if in.MapRoles != nil {
+		in, out := &in.MapRoles, &out.MapRoles
+		*out = make([]kops.AwsAuthenticationRoleMapping, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_AwsAuthenticationRoleMapping_To_kops_AwsAuthenticationRoleMapping(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.MapRoles = nil
+	}
+	if in.MapUsers != nil {
+		in, out := &in.MapUsers, &out.MapUsers
+		*out = make([]kops.AwsAuthenticationUserMapping, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_AwsAuthenticationUserMapping_To_kops_AwsAuthenticationUserMapping(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.MapUsers = nil
+	}
 	return nil
 }
 
@@ -431,6 +589,30 @@ func Convert_v1alpha2_AwsAuthenticationSpec_To_kops_AwsAuthenticationSpec(in *Aw
 }
 
 func autoConvert_kops_AwsAuthenticationSpec_To_v1alpha2_AwsAuthenticationSpec(in *kops.AwsAuthenticationSpec, out *AwsAuthenticationSpec, s conversion.Scope) error {
+	out.Image = in.Image
+	out.ClusterID = in.ClusterID
+	if in.MapRoles != nil {
+		in, out := &in.MapRoles, &out.MapRoles
+		*out = make([]AwsAuthenticationRoleMapping, len(*in))
+		for i := range *in {
+			if err := Convert_kops_AwsAuthenticationRoleMapping_To_v1alpha2_AwsAuthenticationRoleMapping(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.MapRoles = nil
+	}
+	if in.MapUsers != nil {
+		in, out := &in.MapUsers, &out.MapUsers
+		*out = make([]AwsAuthenticationUserMapping, len(*in))
+		for i := range *in {
+			if err := Convert_kops_AwsAuthenticationUserMapping_To_v1alpha2_AwsAuthenticationUserMapping(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.MapUsers = nil
+	}
 	return nil
 }
 
@@ -439,9 +621,137 @@ func Convert_kops_AwsAuthenticationSpec_To_v1alpha2_AwsAuthenticationSpec(in *ko
 	return autoConvert_kops_AwsAuthenticationSpec_To_v1alpha2_AwsAuthenticationSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_AwsAuthenticationUserMapping_To_kops_AwsAuthenticationUserMapping(in *AwsAuthenticationUserMapping, out *kops.AwsAuthenticationUserMapping, s conversion.Scope) error {
+	out.UserARN = in.UserARN
+	out.Username = in.Username
+	out.Groups = in.Groups
+	return nil
+}
+
+// Convert_v1alpha2_AwsAuthenticationUserMapping_To_kops_AwsAuthenticationUserMapping is an autogenerated conversion function.
+func Convert_v1alpha2_AwsAuthenticationUserMapping_To_kops_AwsAuthenticationUserMapping(in *AwsAuthenticationUserMapping, out *kops.AwsAuthenticationUserMapping, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AwsAuthenticationUserMapping_To_kops_AwsAuthenticationUserMapping(in, out, s)
+}
+
+func autoConvert_kops_AwsAuthenticationUserMapping_To_v1alpha2_AwsAuthenticationUserMapping(in *kops.AwsAuthenticationUserMapping, out *AwsAuthenticationUserMapping, s conversion.Scope) error {
+	out.UserARN = in.UserARN
+	out.Username = in.Username
+	out.Groups = in.Groups
+	return nil
+}
+
+// Convert_kops_AwsAuthenticationUserMapping_To_v1alpha2_AwsAuthenticationUserMapping is an autogenerated conversion function.
+func Convert_kops_AwsAuthenticationUserMapping_To_v1alpha2_AwsAuthenticationUserMapping(in *kops.AwsAuthenticationUserMapping, out *AwsAuthenticationUserMapping, s conversion.Scope) error {
+	return autoConvert_kops_AwsAuthenticationUserMapping_To_v1alpha2_AwsAuthenticationUserMapping(in, out, s)
+}
+
+func autoConvert_v1alpha2_BareMetalHostSpec_To_kops_BareMetalHostSpec(in *BareMetalHostSpec, out *kops.BareMetalHostSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Address = in.Address
+	out.MACAddress = in.MACAddress
+	if in.IPMI != nil {
+		in, out := &in.IPMI, &out.IPMI
+		*out = new(kops.IPMISpec)
+		if err := Convert_v1alpha2_IPMISpec_To_kops_IPMISpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.IPMI = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_BareMetalHostSpec_To_kops_BareMetalHostSpec is an autogenerated conversion function.
+func Convert_v1alpha2_BareMetalHostSpec_To_kops_BareMetalHostSpec(in *BareMetalHostSpec, out *kops.BareMetalHostSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_BareMetalHostSpec_To_kops_BareMetalHostSpec(in, out, s)
+}
+
+func autoConvert_kops_BareMetalHostSpec_To_v1alpha2_BareMetalHostSpec(in *kops.BareMetalHostSpec, out *BareMetalHostSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Address = in.Address
+	out.MACAddress = in.MACAddress
+	if in.IPMI != nil {
+		in, out := &in.IPMI, &out.IPMI
+		*out = new(IPMISpec)
+		if err := Convert_kops_IPMISpec_To_v1alpha2_IPMISpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.IPMI = nil
+	}
+	return nil
+}
+
+// Convert_kops_BareMetalHostSpec_To_v1alpha2_BareMetalHostSpec is an autogenerated conversion function.
+func Convert_kops_BareMetalHostSpec_To_v1alpha2_BareMetalHostSpec(in *kops.BareMetalHostSpec, out *BareMetalHostSpec, s conversion.Scope) error {
+	return autoConvert_kops_BareMetalHostSpec_To_v1alpha2_BareMetalHostSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_BareMetalSpec_To_kops_BareMetalSpec(in *BareMetalSpec, out *kops.BareMetalSpec, s conversion.Scope) error {
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]kops.BareMetalHostSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_BareMetalHostSpec_To_kops_BareMetalHostSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Hosts = nil
+	}
+	if in.PXE != nil {
+		in, out := &in.PXE, &out.PXE
+		*out = new(kops.PXESpec)
+		if err := Convert_v1alpha2_PXESpec_To_kops_PXESpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PXE = nil
+	}
+	out.SSHUser = in.SSHUser
+	return nil
+}
+
+// Convert_v1alpha2_BareMetalSpec_To_kops_BareMetalSpec is an autogenerated conversion function.
+func Convert_v1alpha2_BareMetalSpec_To_kops_BareMetalSpec(in *BareMetalSpec, out *kops.BareMetalSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_BareMetalSpec_To_kops_BareMetalSpec(in, out, s)
+}
+
+func autoConvert_kops_BareMetalSpec_To_v1alpha2_BareMetalSpec(in *kops.BareMetalSpec, out *BareMetalSpec, s conversion.Scope) error {
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]BareMetalHostSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_BareMetalHostSpec_To_v1alpha2_BareMetalHostSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Hosts = nil
+	}
+	if in.PXE != nil {
+		in, out := &in.PXE, &out.PXE
+		*out = new(PXESpec)
+		if err := Convert_kops_PXESpec_To_v1alpha2_PXESpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PXE = nil
+	}
+	out.SSHUser = in.SSHUser
+	return nil
+}
+
+// Convert_kops_BareMetalSpec_To_v1alpha2_BareMetalSpec is an autogenerated conversion function.
+func Convert_kops_BareMetalSpec_To_v1alpha2_BareMetalSpec(in *kops.BareMetalSpec, out *BareMetalSpec, s conversion.Scope) error {
+	return autoConvert_kops_BareMetalSpec_To_v1alpha2_BareMetalSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_BastionSpec_To_kops_BastionSpec(in *BastionSpec, out *kops.BastionSpec, s conversion.Scope) error {
 	out.BastionPublicName = in.BastionPublicName
 	out.IdleTimeoutSeconds = in.IdleTimeoutSeconds
+	out.LoadBalancerType = kops.LoadBalancerType(in.LoadBalancerType)
+	out.AllowedCIDRs = in.AllowedCIDRs
 	return nil
 }
 
@@ -453,6 +763,8 @@ func Convert_v1alpha2_BastionSpec_To_kops_BastionSpec(in *BastionSpec, out *kops
 func autoConvert_kops_BastionSpec_To_v1alpha2_BastionSpec(in *kops.BastionSpec, out *BastionSpec, s conversion.Scope) error {
 	out.BastionPublicName = in.BastionPublicName
 	out.IdleTimeoutSeconds = in.IdleTimeoutSeconds
+	out.LoadBalancerType = LoadBalancerType(in.LoadBalancerType)
+	out.AllowedCIDRs = in.AllowedCIDRs
 	return nil
 }
 
@@ -481,6 +793,30 @@ func Convert_kops_CNINetworkingSpec_To_v1alpha2_CNINetworkingSpec(in *kops.CNINe
 	return autoConvert_kops_CNINetworkingSpec_To_v1alpha2_CNINetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec(in *CSIDriverSpec, out *kops.CSIDriverSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Image = in.Image
+	out.Migration = in.Migration
+	return nil
+}
+
+// Convert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec is an autogenerated conversion function.
+func Convert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec(in *CSIDriverSpec, out *kops.CSIDriverSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec(in, out, s)
+}
+
+func autoConvert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec(in *kops.CSIDriverSpec, out *CSIDriverSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Image = in.Image
+	out.Migration = in.Migration
+	return nil
+}
+
+// Convert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec is an autogenerated conversion function.
+func Convert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec(in *kops.CSIDriverSpec, out *CSIDriverSpec, s conversion.Scope) error {
+	return autoConvert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec(in *CalicoNetworkingSpec, out *kops.CalicoNetworkingSpec, s conversion.Scope) error {
 	out.CrossSubnet = in.CrossSubnet
 	out.LogSeverityScreen = in.LogSeverityScreen
@@ -685,6 +1021,52 @@ func autoConvert_v1alpha2_CloudConfiguration_To_kops_CloudConfiguration(in *Clou
 	out.NodeInstancePrefix = in.NodeInstancePrefix
 	out.DisableSecurityGroupIngress = in.DisableSecurityGroupIngress
 	out.ElbSecurityGroup = in.ElbSecurityGroup
+	out.AWSPartition = in.AWSPartition
+	if in.AWSAssumeRole != nil {
+		in, out := &in.AWSAssumeRole, &out.AWSAssumeRole
+		*out = new(kops.AWSAssumeRoleSpec)
+		if err := Convert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSAssumeRole = nil
+	}
+	if in.AWSDNSAssumeRole != nil {
+		in, out := &in.AWSDNSAssumeRole, &out.AWSDNSAssumeRole
+		*out = new(kops.AWSAssumeRoleSpec)
+		if err := Convert_v1alpha2_AWSAssumeRoleSpec_To_kops_AWSAssumeRoleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSDNSAssumeRole = nil
+	}
+	if in.AWSEBSCSIDriver != nil {
+		in, out := &in.AWSEBSCSIDriver, &out.AWSEBSCSIDriver
+		*out = new(kops.CSIDriverSpec)
+		if err := Convert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSEBSCSIDriver = nil
+	}
+	if in.GCEPDCSIDriver != nil {
+		in, out := &in.GCEPDCSIDriver, &out.GCEPDCSIDriver
+		*out = new(kops.CSIDriverSpec)
+		if err := Convert_v1alpha2_CSIDriverSpec_To_kops_CSIDriverSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GCEPDCSIDriver = nil
+	}
+	if in.DefaultStorageClass != nil {
+		in, out := &in.DefaultStorageClass, &out.DefaultStorageClass
+		*out = new(kops.DefaultStorageClassSpec)
+		if err := Convert_v1alpha2_DefaultStorageClassSpec_To_kops_DefaultStorageClassSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DefaultStorageClass = nil
+	}
 	out.VSphereUsername = in.VSphereUsername
 	out.VSpherePassword = in.VSpherePassword
 	out.VSphereServer = in.VSphereServer
@@ -692,6 +1074,7 @@ func autoConvert_v1alpha2_CloudConfiguration_To_kops_CloudConfiguration(in *Clou
 	out.VSphereResourcePool = in.VSphereResourcePool
 	out.VSphereDatastore = in.VSphereDatastore
 	out.VSphereCoreDNSServer = in.VSphereCoreDNSServer
+	out.VSphereEtcdDatastore = in.VSphereEtcdDatastore
 	return nil
 }
 
@@ -706,6 +1089,52 @@ func autoConvert_kops_CloudConfiguration_To_v1alpha2_CloudConfiguration(in *kops
 	out.NodeInstancePrefix = in.NodeInstancePrefix
 	out.DisableSecurityGroupIngress = in.DisableSecurityGroupIngress
 	out.ElbSecurityGroup = in.ElbSecurityGroup
+	out.AWSPartition = in.AWSPartition
+	if in.AWSAssumeRole != nil {
+		in, out := &in.AWSAssumeRole, &out.AWSAssumeRole
+		*out = new(AWSAssumeRoleSpec)
+		if err := Convert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSAssumeRole = nil
+	}
+	if in.AWSDNSAssumeRole != nil {
+		in, out := &in.AWSDNSAssumeRole, &out.AWSDNSAssumeRole
+		*out = new(AWSAssumeRoleSpec)
+		if err := Convert_kops_AWSAssumeRoleSpec_To_v1alpha2_AWSAssumeRoleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSDNSAssumeRole = nil
+	}
+	if in.AWSEBSCSIDriver != nil {
+		in, out := &in.AWSEBSCSIDriver, &out.AWSEBSCSIDriver
+		*out = new(CSIDriverSpec)
+		if err := Convert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AWSEBSCSIDriver = nil
+	}
+	if in.GCEPDCSIDriver != nil {
+		in, out := &in.GCEPDCSIDriver, &out.GCEPDCSIDriver
+		*out = new(CSIDriverSpec)
+		if err := Convert_kops_CSIDriverSpec_To_v1alpha2_CSIDriverSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GCEPDCSIDriver = nil
+	}
+	if in.DefaultStorageClass != nil {
+		in, out := &in.DefaultStorageClass, &out.DefaultStorageClass
+		*out = new(DefaultStorageClassSpec)
+		if err := Convert_kops_DefaultStorageClassSpec_To_v1alpha2_DefaultStorageClassSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DefaultStorageClass = nil
+	}
 	out.VSphereUsername = in.VSphereUsername
 	out.VSpherePassword = in.VSpherePassword
 	out.VSphereServer = in.VSphereServer
@@ -713,6 +1142,7 @@ func autoConvert_kops_CloudConfiguration_To_v1alpha2_CloudConfiguration(in *kops
 	out.VSphereResourcePool = in.VSphereResourcePool
 	out.VSphereDatastore = in.VSphereDatastore
 	out.VSphereCoreDNSServer = in.VSphereCoreDNSServer
+	out.VSphereEtcdDatastore = in.VSphereEtcdDatastore
 	return nil
 }
 
@@ -891,6 +1321,24 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	out.KeyStore = in.KeyStore
 	out.ConfigStore = in.ConfigStore
 	out.DNSZone = in.DNSZone
+	if in.DNSZoneOptions != nil {
+		in, out := &in.DNSZoneOptions, &out.DNSZoneOptions
+		*out = new(kops.DNSZoneOptions)
+		if err := Convert_v1alpha2_DNSZoneOptions_To_kops_DNSZoneOptions(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DNSZoneOptions = nil
+	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		*out = new(kops.GossipConfig)
+		if err := Convert_v1alpha2_GossipConfig_To_kops_GossipConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GossipConfig = nil
+	}
 	out.AdditionalSANs = in.AdditionalSANs
 	out.ClusterDNSDomain = in.ClusterDNSDomain
 	out.ServiceClusterIPRange = in.ServiceClusterIPRange
@@ -934,6 +1382,27 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.EtcdClusters = nil
 	}
+	if in.NTP != nil {
+		in, out := &in.NTP, &out.NTP
+		*out = new(kops.NTPConfig)
+		if err := Convert_v1alpha2_NTPConfig_To_kops_NTPConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NTP = nil
+	}
+	if in.ContainerRegistryMirrors != nil {
+		in, out := &in.ContainerRegistryMirrors, &out.ContainerRegistryMirrors
+		*out = make([]kops.ContainerRegistryMirrorSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_ContainerRegistryMirrorSpec_To_kops_ContainerRegistryMirrorSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ContainerRegistryMirrors = nil
+	}
+	out.NodeBootReports = in.NodeBootReports
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		*out = new(kops.DockerConfig)
@@ -1078,7 +1547,25 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.NodeAuthorization = nil
 	}
+	if in.KopsController != nil {
+		in, out := &in.KopsController, &out.KopsController
+		*out = new(kops.KopsControllerSpec)
+		if err := Convert_v1alpha2_KopsControllerSpec_To_kops_KopsControllerSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KopsController = nil
+	}
 	out.CloudLabels = in.CloudLabels
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		*out = new(kops.InstanceMetadataOptions)
+		if err := Convert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.InstanceMetadata = nil
+	}
 	if in.Hooks != nil {
 		in, out := &in.Hooks, &out.Hooks
 		*out = make([]kops.HookSpec, len(*in))
@@ -1109,6 +1596,15 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 		out.IAM = nil
 	}
 	out.EncryptionConfig = in.EncryptionConfig
+	if in.EncryptionProvider != nil {
+		in, out := &in.EncryptionProvider, &out.EncryptionProvider
+		*out = new(kops.EncryptionProviderSpec)
+		if err := Convert_v1alpha2_EncryptionProviderSpec_To_kops_EncryptionProviderSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EncryptionProvider = nil
+	}
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(kops.TargetSpec)
@@ -1118,6 +1614,26 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.Target = nil
 	}
+	out.SecurityGroupOverrideMode = in.SecurityGroupOverrideMode
+	out.RequireApproval = in.RequireApproval
+	if in.PodSecurity != nil {
+		in, out := &in.PodSecurity, &out.PodSecurity
+		*out = new(kops.PodSecuritySpec)
+		if err := Convert_v1alpha2_PodSecuritySpec_To_kops_PodSecuritySpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PodSecurity = nil
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(kops.NetworkPolicySpec)
+		if err := Convert_v1alpha2_NetworkPolicySpec_To_kops_NetworkPolicySpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NetworkPolicy = nil
+	}
 	return nil
 }
 
@@ -1172,6 +1688,24 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	out.KeyStore = in.KeyStore
 	out.ConfigStore = in.ConfigStore
 	out.DNSZone = in.DNSZone
+	if in.DNSZoneOptions != nil {
+		in, out := &in.DNSZoneOptions, &out.DNSZoneOptions
+		*out = new(DNSZoneOptions)
+		if err := Convert_kops_DNSZoneOptions_To_v1alpha2_DNSZoneOptions(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DNSZoneOptions = nil
+	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		*out = new(GossipConfig)
+		if err := Convert_kops_GossipConfig_To_v1alpha2_GossipConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GossipConfig = nil
+	}
 	out.AdditionalSANs = in.AdditionalSANs
 	out.ClusterDNSDomain = in.ClusterDNSDomain
 	out.ServiceClusterIPRange = in.ServiceClusterIPRange
@@ -1215,6 +1749,27 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.EtcdClusters = nil
 	}
+	if in.NTP != nil {
+		in, out := &in.NTP, &out.NTP
+		*out = new(NTPConfig)
+		if err := Convert_kops_NTPConfig_To_v1alpha2_NTPConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NTP = nil
+	}
+	if in.ContainerRegistryMirrors != nil {
+		in, out := &in.ContainerRegistryMirrors, &out.ContainerRegistryMirrors
+		*out = make([]ContainerRegistryMirrorSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_ContainerRegistryMirrorSpec_To_v1alpha2_ContainerRegistryMirrorSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ContainerRegistryMirrors = nil
+	}
+	out.NodeBootReports = in.NodeBootReports
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		*out = new(DockerConfig)
@@ -1359,7 +1914,25 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.NodeAuthorization = nil
 	}
+	if in.KopsController != nil {
+		in, out := &in.KopsController, &out.KopsController
+		*out = new(KopsControllerSpec)
+		if err := Convert_kops_KopsControllerSpec_To_v1alpha2_KopsControllerSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KopsController = nil
+	}
 	out.CloudLabels = in.CloudLabels
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		*out = new(InstanceMetadataOptions)
+		if err := Convert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.InstanceMetadata = nil
+	}
 	if in.Hooks != nil {
 		in, out := &in.Hooks, &out.Hooks
 		*out = make([]HookSpec, len(*in))
@@ -1390,6 +1963,15 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 		out.IAM = nil
 	}
 	out.EncryptionConfig = in.EncryptionConfig
+	if in.EncryptionProvider != nil {
+		in, out := &in.EncryptionProvider, &out.EncryptionProvider
+		*out = new(EncryptionProviderSpec)
+		if err := Convert_kops_EncryptionProviderSpec_To_v1alpha2_EncryptionProviderSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EncryptionProvider = nil
+	}
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(TargetSpec)
@@ -1399,6 +1981,26 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.Target = nil
 	}
+	out.SecurityGroupOverrideMode = in.SecurityGroupOverrideMode
+	out.RequireApproval = in.RequireApproval
+	if in.PodSecurity != nil {
+		in, out := &in.PodSecurity, &out.PodSecurity
+		*out = new(PodSecuritySpec)
+		if err := Convert_kops_PodSecuritySpec_To_v1alpha2_PodSecuritySpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PodSecurity = nil
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicySpec)
+		if err := Convert_kops_NetworkPolicySpec_To_v1alpha2_NetworkPolicySpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NetworkPolicy = nil
+	}
 	return nil
 }
 
@@ -1441,6 +2043,56 @@ func Convert_kops_ClusterSubnetSpec_To_v1alpha2_ClusterSubnetSpec(in *kops.Clust
 	return autoConvert_kops_ClusterSubnetSpec_To_v1alpha2_ClusterSubnetSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_ContainerRegistryMirrorSpec_To_kops_ContainerRegistryMirrorSpec(in *ContainerRegistryMirrorSpec, out *kops.ContainerRegistryMirrorSpec, s conversion.Scope) error {
+	out.Registry = in.Registry
+	out.Endpoints = in.Endpoints
+	out.CredentialsSecret = in.CredentialsSecret
+	return nil
+}
+
+// Convert_v1alpha2_ContainerRegistryMirrorSpec_To_kops_ContainerRegistryMirrorSpec is an autogenerated conversion function.
+func Convert_v1alpha2_ContainerRegistryMirrorSpec_To_kops_ContainerRegistryMirrorSpec(in *ContainerRegistryMirrorSpec, out *kops.ContainerRegistryMirrorSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ContainerRegistryMirrorSpec_To_kops_ContainerRegistryMirrorSpec(in, out, s)
+}
+
+func autoConvert_kops_ContainerRegistryMirrorSpec_To_v1alpha2_ContainerRegistryMirrorSpec(in *kops.ContainerRegistryMirrorSpec, out *ContainerRegistryMirrorSpec, s conversion.Scope) error {
+	out.Registry = in.Registry
+	out.Endpoints = in.Endpoints
+	out.CredentialsSecret = in.CredentialsSecret
+	return nil
+}
+
+// Convert_kops_ContainerRegistryMirrorSpec_To_v1alpha2_ContainerRegistryMirrorSpec is an autogenerated conversion function.
+func Convert_kops_ContainerRegistryMirrorSpec_To_v1alpha2_ContainerRegistryMirrorSpec(in *kops.ContainerRegistryMirrorSpec, out *ContainerRegistryMirrorSpec, s conversion.Scope) error {
+	return autoConvert_kops_ContainerRegistryMirrorSpec_To_v1alpha2_ContainerRegistryMirrorSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources(in *ControlPlaneResources, out *kops.ControlPlaneResources, s conversion.Scope) error {
+	out.CPURequest = in.CPURequest
+	out.CPULimit = in.CPULimit
+	out.MemoryRequest = in.MemoryRequest
+	out.MemoryLimit = in.MemoryLimit
+	return nil
+}
+
+// Convert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources is an autogenerated conversion function.
+func Convert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources(in *ControlPlaneResources, out *kops.ControlPlaneResources, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources(in, out, s)
+}
+
+func autoConvert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources(in *kops.ControlPlaneResources, out *ControlPlaneResources, s conversion.Scope) error {
+	out.CPURequest = in.CPURequest
+	out.CPULimit = in.CPULimit
+	out.MemoryRequest = in.MemoryRequest
+	out.MemoryLimit = in.MemoryLimit
+	return nil
+}
+
+// Convert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources is an autogenerated conversion function.
+func Convert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources(in *kops.ControlPlaneResources, out *ControlPlaneResources, s conversion.Scope) error {
+	return autoConvert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources(in, out, s)
+}
+
 func autoConvert_v1alpha2_DNSAccessSpec_To_kops_DNSAccessSpec(in *DNSAccessSpec, out *kops.DNSAccessSpec, s conversion.Scope) error {
 	return nil
 }
@@ -1459,6 +2111,34 @@ func Convert_kops_DNSAccessSpec_To_v1alpha2_DNSAccessSpec(in *kops.DNSAccessSpec
 	return autoConvert_kops_DNSAccessSpec_To_v1alpha2_DNSAccessSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_DNSAutoscalerConfig_To_kops_DNSAutoscalerConfig(in *DNSAutoscalerConfig, out *kops.DNSAutoscalerConfig, s conversion.Scope) error {
+	out.Disabled = in.Disabled
+	out.CoresPerReplica = in.CoresPerReplica
+	out.NodesPerReplica = in.NodesPerReplica
+	out.Min = in.Min
+	out.Max = in.Max
+	return nil
+}
+
+// Convert_v1alpha2_DNSAutoscalerConfig_To_kops_DNSAutoscalerConfig is an autogenerated conversion function.
+func Convert_v1alpha2_DNSAutoscalerConfig_To_kops_DNSAutoscalerConfig(in *DNSAutoscalerConfig, out *kops.DNSAutoscalerConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_DNSAutoscalerConfig_To_kops_DNSAutoscalerConfig(in, out, s)
+}
+
+func autoConvert_kops_DNSAutoscalerConfig_To_v1alpha2_DNSAutoscalerConfig(in *kops.DNSAutoscalerConfig, out *DNSAutoscalerConfig, s conversion.Scope) error {
+	out.Disabled = in.Disabled
+	out.CoresPerReplica = in.CoresPerReplica
+	out.NodesPerReplica = in.NodesPerReplica
+	out.Min = in.Min
+	out.Max = in.Max
+	return nil
+}
+
+// Convert_kops_DNSAutoscalerConfig_To_v1alpha2_DNSAutoscalerConfig is an autogenerated conversion function.
+func Convert_kops_DNSAutoscalerConfig_To_v1alpha2_DNSAutoscalerConfig(in *kops.DNSAutoscalerConfig, out *DNSAutoscalerConfig, s conversion.Scope) error {
+	return autoConvert_kops_DNSAutoscalerConfig_To_v1alpha2_DNSAutoscalerConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_DNSSpec_To_kops_DNSSpec(in *DNSSpec, out *kops.DNSSpec, s conversion.Scope) error {
 	out.Type = kops.DNSType(in.Type)
 	return nil
@@ -1479,6 +2159,124 @@ func Convert_kops_DNSSpec_To_v1alpha2_DNSSpec(in *kops.DNSSpec, out *DNSSpec, s
 	return autoConvert_kops_DNSSpec_To_v1alpha2_DNSSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_DNSZoneOptions_To_kops_DNSZoneOptions(in *DNSZoneOptions, out *kops.DNSZoneOptions, s conversion.Scope) error {
+	out.Private = in.Private
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]kops.DNSZoneVPC, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_DNSZoneVPC_To_kops_DNSZoneVPC(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalVPCs = nil
+	}
+	out.SplitHorizon = in.SplitHorizon
+	out.PublicZone = in.PublicZone
+	return nil
+}
+
+// Convert_v1alpha2_DNSZoneOptions_To_kops_DNSZoneOptions is an autogenerated conversion function.
+func Convert_v1alpha2_DNSZoneOptions_To_kops_DNSZoneOptions(in *DNSZoneOptions, out *kops.DNSZoneOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha2_DNSZoneOptions_To_kops_DNSZoneOptions(in, out, s)
+}
+
+func autoConvert_kops_DNSZoneOptions_To_v1alpha2_DNSZoneOptions(in *kops.DNSZoneOptions, out *DNSZoneOptions, s conversion.Scope) error {
+	out.Private = in.Private
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]DNSZoneVPC, len(*in))
+		for i := range *in {
+			if err := Convert_kops_DNSZoneVPC_To_v1alpha2_DNSZoneVPC(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalVPCs = nil
+	}
+	out.SplitHorizon = in.SplitHorizon
+	out.PublicZone = in.PublicZone
+	return nil
+}
+
+// Convert_kops_DNSZoneOptions_To_v1alpha2_DNSZoneOptions is an autogenerated conversion function.
+func Convert_kops_DNSZoneOptions_To_v1alpha2_DNSZoneOptions(in *kops.DNSZoneOptions, out *DNSZoneOptions, s conversion.Scope) error {
+	return autoConvert_kops_DNSZoneOptions_To_v1alpha2_DNSZoneOptions(in, out, s)
+}
+
+func autoConvert_v1alpha2_DNSZoneVPC_To_kops_DNSZoneVPC(in *DNSZoneVPC, out *kops.DNSZoneVPC, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_v1alpha2_DNSZoneVPC_To_kops_DNSZoneVPC is an autogenerated conversion function.
+func Convert_v1alpha2_DNSZoneVPC_To_kops_DNSZoneVPC(in *DNSZoneVPC, out *kops.DNSZoneVPC, s conversion.Scope) error {
+	return autoConvert_v1alpha2_DNSZoneVPC_To_kops_DNSZoneVPC(in, out, s)
+}
+
+func autoConvert_kops_DNSZoneVPC_To_v1alpha2_DNSZoneVPC(in *kops.DNSZoneVPC, out *DNSZoneVPC, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_kops_DNSZoneVPC_To_v1alpha2_DNSZoneVPC is an autogenerated conversion function.
+func Convert_kops_DNSZoneVPC_To_v1alpha2_DNSZoneVPC(in *kops.DNSZoneVPC, out *DNSZoneVPC, s conversion.Scope) error {
+	return autoConvert_kops_DNSZoneVPC_To_v1alpha2_DNSZoneVPC(in, out, s)
+}
+
+func autoConvert_v1alpha2_DefaultStorageClassSpec_To_kops_DefaultStorageClassSpec(in *DefaultStorageClassSpec, out *kops.DefaultStorageClassSpec, s conversion.Scope) error {
+	out.Type = in.Type
+	out.Encrypted = in.Encrypted
+	out.ReclaimPolicy = in.ReclaimPolicy
+	out.AllowVolumeExpansion = in.AllowVolumeExpansion
+	return nil
+}
+
+// Convert_v1alpha2_DefaultStorageClassSpec_To_kops_DefaultStorageClassSpec is an autogenerated conversion function.
+func Convert_v1alpha2_DefaultStorageClassSpec_To_kops_DefaultStorageClassSpec(in *DefaultStorageClassSpec, out *kops.DefaultStorageClassSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_DefaultStorageClassSpec_To_kops_DefaultStorageClassSpec(in, out, s)
+}
+
+func autoConvert_kops_DefaultStorageClassSpec_To_v1alpha2_DefaultStorageClassSpec(in *kops.DefaultStorageClassSpec, out *DefaultStorageClassSpec, s conversion.Scope) error {
+	out.Type = in.Type
+	out.Encrypted = in.Encrypted
+	out.ReclaimPolicy = in.ReclaimPolicy
+	out.AllowVolumeExpansion = in.AllowVolumeExpansion
+	return nil
+}
+
+// Convert_kops_DefaultStorageClassSpec_To_v1alpha2_DefaultStorageClassSpec is an autogenerated conversion function.
+func Convert_kops_DefaultStorageClassSpec_To_v1alpha2_DefaultStorageClassSpec(in *kops.DefaultStorageClassSpec, out *DefaultStorageClassSpec, s conversion.Scope) error {
+	return autoConvert_kops_DefaultStorageClassSpec_To_v1alpha2_DefaultStorageClassSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_DexAuthenticationSpec_To_kops_DexAuthenticationSpec(in *DexAuthenticationSpec, out *kops.DexAuthenticationSpec, s conversion.Scope) error {
+	out.Image = in.Image
+	out.Connectors = in.Connectors
+	out.RedirectURIs = in.RedirectURIs
+	return nil
+}
+
+// Convert_v1alpha2_DexAuthenticationSpec_To_kops_DexAuthenticationSpec is an autogenerated conversion function.
+func Convert_v1alpha2_DexAuthenticationSpec_To_kops_DexAuthenticationSpec(in *DexAuthenticationSpec, out *kops.DexAuthenticationSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_DexAuthenticationSpec_To_kops_DexAuthenticationSpec(in, out, s)
+}
+
+func autoConvert_kops_DexAuthenticationSpec_To_v1alpha2_DexAuthenticationSpec(in *kops.DexAuthenticationSpec, out *DexAuthenticationSpec, s conversion.Scope) error {
+	out.Image = in.Image
+	out.Connectors = in.Connectors
+	out.RedirectURIs = in.RedirectURIs
+	return nil
+}
+
+// Convert_kops_DexAuthenticationSpec_To_v1alpha2_DexAuthenticationSpec is an autogenerated conversion function.
+func Convert_kops_DexAuthenticationSpec_To_v1alpha2_DexAuthenticationSpec(in *kops.DexAuthenticationSpec, out *DexAuthenticationSpec, s conversion.Scope) error {
+	return autoConvert_kops_DexAuthenticationSpec_To_v1alpha2_DexAuthenticationSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_DockerConfig_To_kops_DockerConfig(in *DockerConfig, out *kops.DockerConfig, s conversion.Scope) error {
 	out.AuthorizationPlugins = in.AuthorizationPlugins
 	out.Bridge = in.Bridge
@@ -1563,9 +2361,48 @@ func Convert_kops_EgressProxySpec_To_v1alpha2_EgressProxySpec(in *kops.EgressPro
 	return autoConvert_kops_EgressProxySpec_To_v1alpha2_EgressProxySpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_EncryptionProviderSpec_To_kops_EncryptionProviderSpec(in *EncryptionProviderSpec, out *kops.EncryptionProviderSpec, s conversion.Scope) error {
+	out.Type = in.Type
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(kops.KMSEncryptionProviderSpec)
+		if err := Convert_v1alpha2_KMSEncryptionProviderSpec_To_kops_KMSEncryptionProviderSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KMS = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_EncryptionProviderSpec_To_kops_EncryptionProviderSpec is an autogenerated conversion function.
+func Convert_v1alpha2_EncryptionProviderSpec_To_kops_EncryptionProviderSpec(in *EncryptionProviderSpec, out *kops.EncryptionProviderSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_EncryptionProviderSpec_To_kops_EncryptionProviderSpec(in, out, s)
+}
+
+func autoConvert_kops_EncryptionProviderSpec_To_v1alpha2_EncryptionProviderSpec(in *kops.EncryptionProviderSpec, out *EncryptionProviderSpec, s conversion.Scope) error {
+	out.Type = in.Type
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(KMSEncryptionProviderSpec)
+		if err := Convert_kops_KMSEncryptionProviderSpec_To_v1alpha2_KMSEncryptionProviderSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.KMS = nil
+	}
+	return nil
+}
+
+// Convert_kops_EncryptionProviderSpec_To_v1alpha2_EncryptionProviderSpec is an autogenerated conversion function.
+func Convert_kops_EncryptionProviderSpec_To_v1alpha2_EncryptionProviderSpec(in *kops.EncryptionProviderSpec, out *EncryptionProviderSpec, s conversion.Scope) error {
+	return autoConvert_kops_EncryptionProviderSpec_To_v1alpha2_EncryptionProviderSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_EtcdBackupSpec_To_kops_EtcdBackupSpec(in *EtcdBackupSpec, out *kops.EtcdBackupSpec, s conversion.Scope) error {
 	out.BackupStore = in.BackupStore
 	out.Image = in.Image
+	out.Interval = in.Interval
 	return nil
 }
 
@@ -1577,6 +2414,7 @@ func Convert_v1alpha2_EtcdBackupSpec_To_kops_EtcdBackupSpec(in *EtcdBackupSpec,
 func autoConvert_kops_EtcdBackupSpec_To_v1alpha2_EtcdBackupSpec(in *kops.EtcdBackupSpec, out *EtcdBackupSpec, s conversion.Scope) error {
 	out.BackupStore = in.BackupStore
 	out.Image = in.Image
+	out.Interval = in.Interval
 	return nil
 }
 
@@ -1702,6 +2540,7 @@ func autoConvert_v1alpha2_EtcdMemberSpec_To_kops_EtcdMemberSpec(in *EtcdMemberSp
 	out.InstanceGroup = in.InstanceGroup
 	out.VolumeType = in.VolumeType
 	out.VolumeIops = in.VolumeIops
+	out.VolumeThroughput = in.VolumeThroughput
 	out.VolumeSize = in.VolumeSize
 	out.KmsKeyId = in.KmsKeyId
 	out.EncryptedVolume = in.EncryptedVolume
@@ -1718,6 +2557,7 @@ func autoConvert_kops_EtcdMemberSpec_To_v1alpha2_EtcdMemberSpec(in *kops.EtcdMem
 	out.InstanceGroup = in.InstanceGroup
 	out.VolumeType = in.VolumeType
 	out.VolumeIops = in.VolumeIops
+	out.VolumeThroughput = in.VolumeThroughput
 	out.VolumeSize = in.VolumeSize
 	out.KmsKeyId = in.KmsKeyId
 	out.EncryptedVolume = in.EncryptedVolume
@@ -1757,6 +2597,16 @@ func autoConvert_v1alpha2_ExternalDNSConfig_To_kops_ExternalDNSConfig(in *Extern
 	out.Disable = in.Disable
 	out.WatchIngress = in.WatchIngress
 	out.WatchNamespace = in.WatchNamespace
+	out.Provider = in.Provider
+	if in.Infoblox != nil {
+		in, out := &in.Infoblox, &out.Infoblox
+		*out = new(kops.InfobloxDNSConfig)
+		if err := Convert_v1alpha2_InfobloxDNSConfig_To_kops_InfobloxDNSConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Infoblox = nil
+	}
 	return nil
 }
 
@@ -1769,6 +2619,16 @@ func autoConvert_kops_ExternalDNSConfig_To_v1alpha2_ExternalDNSConfig(in *kops.E
 	out.Disable = in.Disable
 	out.WatchIngress = in.WatchIngress
 	out.WatchNamespace = in.WatchNamespace
+	out.Provider = in.Provider
+	if in.Infoblox != nil {
+		in, out := &in.Infoblox, &out.Infoblox
+		*out = new(InfobloxDNSConfig)
+		if err := Convert_kops_InfobloxDNSConfig_To_v1alpha2_InfobloxDNSConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Infoblox = nil
+	}
 	return nil
 }
 
@@ -1859,6 +2719,72 @@ func Convert_kops_FlannelNetworkingSpec_To_v1alpha2_FlannelNetworkingSpec(in *ko
 	return autoConvert_kops_FlannelNetworkingSpec_To_v1alpha2_FlannelNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	out.Secret = in.Secret
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(kops.GossipConfigSecondary)
+		if err := Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secondary = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_GossipConfig_To_kops_GossipConfig is an autogenerated conversion function.
+func Convert_v1alpha2_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_GossipConfig_To_kops_GossipConfig(in, out, s)
+}
+
+func autoConvert_kops_GossipConfig_To_v1alpha2_GossipConfig(in *kops.GossipConfig, out *GossipConfig, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	out.Secret = in.Secret
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(GossipConfigSecondary)
+		if err := Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secondary = nil
+	}
+	return nil
+}
+
+// Convert_kops_GossipConfig_To_v1alpha2_GossipConfig is an autogenerated conversion function.
+func Convert_kops_GossipConfig_To_v1alpha2_GossipConfig(in *kops.GossipConfig, out *GossipConfig, s conversion.Scope) error {
+	return autoConvert_kops_GossipConfig_To_v1alpha2_GossipConfig(in, out, s)
+}
+
+func autoConvert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(in *GossipConfigSecondary, out *kops.GossipConfigSecondary, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	out.Secret = in.Secret
+	return nil
+}
+
+// Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary is an autogenerated conversion function.
+func Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(in *GossipConfigSecondary, out *kops.GossipConfigSecondary, s conversion.Scope) error {
+	return autoConvert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(in, out, s)
+}
+
+func autoConvert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(in *kops.GossipConfigSecondary, out *GossipConfigSecondary, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	out.Secret = in.Secret
+	return nil
+}
+
+// Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary is an autogenerated conversion function.
+func Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(in *kops.GossipConfigSecondary, out *GossipConfigSecondary, s conversion.Scope) error {
+	return autoConvert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(in, out, s)
+}
+
 func autoConvert_v1alpha2_HTTPProxy_To_kops_HTTPProxy(in *HTTPProxy, out *kops.HTTPProxy, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Port = in.Port
@@ -1989,6 +2915,56 @@ func Convert_kops_IAMSpec_To_v1alpha2_IAMSpec(in *kops.IAMSpec, out *IAMSpec, s
 	return autoConvert_kops_IAMSpec_To_v1alpha2_IAMSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_IPMISpec_To_kops_IPMISpec(in *IPMISpec, out *kops.IPMISpec, s conversion.Scope) error {
+	out.Address = in.Address
+	out.Username = in.Username
+	out.PasswordEnv = in.PasswordEnv
+	return nil
+}
+
+// Convert_v1alpha2_IPMISpec_To_kops_IPMISpec is an autogenerated conversion function.
+func Convert_v1alpha2_IPMISpec_To_kops_IPMISpec(in *IPMISpec, out *kops.IPMISpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_IPMISpec_To_kops_IPMISpec(in, out, s)
+}
+
+func autoConvert_kops_IPMISpec_To_v1alpha2_IPMISpec(in *kops.IPMISpec, out *IPMISpec, s conversion.Scope) error {
+	out.Address = in.Address
+	out.Username = in.Username
+	out.PasswordEnv = in.PasswordEnv
+	return nil
+}
+
+// Convert_kops_IPMISpec_To_v1alpha2_IPMISpec is an autogenerated conversion function.
+func Convert_kops_IPMISpec_To_v1alpha2_IPMISpec(in *kops.IPMISpec, out *IPMISpec, s conversion.Scope) error {
+	return autoConvert_kops_IPMISpec_To_v1alpha2_IPMISpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_InfobloxDNSConfig_To_kops_InfobloxDNSConfig(in *InfobloxDNSConfig, out *kops.InfobloxDNSConfig, s conversion.Scope) error {
+	out.Host = in.Host
+	out.WAPIVersion = in.WAPIVersion
+	out.View = in.View
+	out.InsecureSkipVerify = in.InsecureSkipVerify
+	return nil
+}
+
+// Convert_v1alpha2_InfobloxDNSConfig_To_kops_InfobloxDNSConfig is an autogenerated conversion function.
+func Convert_v1alpha2_InfobloxDNSConfig_To_kops_InfobloxDNSConfig(in *InfobloxDNSConfig, out *kops.InfobloxDNSConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_InfobloxDNSConfig_To_kops_InfobloxDNSConfig(in, out, s)
+}
+
+func autoConvert_kops_InfobloxDNSConfig_To_v1alpha2_InfobloxDNSConfig(in *kops.InfobloxDNSConfig, out *InfobloxDNSConfig, s conversion.Scope) error {
+	out.Host = in.Host
+	out.WAPIVersion = in.WAPIVersion
+	out.View = in.View
+	out.InsecureSkipVerify = in.InsecureSkipVerify
+	return nil
+}
+
+// Convert_kops_InfobloxDNSConfig_To_v1alpha2_InfobloxDNSConfig is an autogenerated conversion function.
+func Convert_kops_InfobloxDNSConfig_To_v1alpha2_InfobloxDNSConfig(in *kops.InfobloxDNSConfig, out *InfobloxDNSConfig, s conversion.Scope) error {
+	return autoConvert_kops_InfobloxDNSConfig_To_v1alpha2_InfobloxDNSConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_InstanceGroup_To_kops_InstanceGroup(in *InstanceGroup, out *kops.InstanceGroup, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -2066,6 +3042,7 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	out.RootVolumeSize = in.RootVolumeSize
 	out.RootVolumeType = in.RootVolumeType
 	out.RootVolumeIops = in.RootVolumeIops
+	out.RootVolumeThroughput = in.RootVolumeThroughput
 	out.RootVolumeOptimization = in.RootVolumeOptimization
 	out.Subnets = in.Subnets
 	out.Zones = in.Zones
@@ -2140,6 +3117,60 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	} else {
 		out.IAM = nil
 	}
+	out.Manager = kops.InstanceManager(in.Manager)
+	out.CPUCredits = in.CPUCredits
+	if in.MixedInstancesPolicy != nil {
+		in, out := &in.MixedInstancesPolicy, &out.MixedInstancesPolicy
+		*out = new(kops.MixedInstancesPolicySpec)
+		if err := Convert_v1alpha2_MixedInstancesPolicySpec_To_kops_MixedInstancesPolicySpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.MixedInstancesPolicy = nil
+	}
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		*out = new(kops.InstanceMetadataOptions)
+		if err := Convert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.InstanceMetadata = nil
+	}
+	if in.PlacementGroup != nil {
+		in, out := &in.PlacementGroup, &out.PlacementGroup
+		*out = new(kops.PlacementGroupSpec)
+		if err := Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PlacementGroup = nil
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]*kops.VolumeSpec, len(*in))
+		for i := range *in {
+			// TODO: Inefficient conversion - can we improve it?
+			if err := s.Convert(&(*in)[i], &(*out)[i], 0); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Volumes = nil
+	}
+	out.Regional = in.Regional
+	out.SecurityGroupOverride = in.SecurityGroupOverride
+	out.ImageAlias = in.ImageAlias
+	out.PreloadImages = in.PreloadImages
+	if in.BareMetal != nil {
+		in, out := &in.BareMetal, &out.BareMetal
+		*out = new(kops.BareMetalSpec)
+		if err := Convert_v1alpha2_BareMetalSpec_To_kops_BareMetalSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.BareMetal = nil
+	}
 	return nil
 }
 
@@ -2157,6 +3188,7 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	out.RootVolumeSize = in.RootVolumeSize
 	out.RootVolumeType = in.RootVolumeType
 	out.RootVolumeIops = in.RootVolumeIops
+	out.RootVolumeThroughput = in.RootVolumeThroughput
 	out.RootVolumeOptimization = in.RootVolumeOptimization
 	out.Subnets = in.Subnets
 	out.Zones = in.Zones
@@ -2229,7 +3261,61 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 			return err
 		}
 	} else {
-		out.IAM = nil
+		out.IAM = nil
+	}
+	out.Manager = InstanceManager(in.Manager)
+	out.CPUCredits = in.CPUCredits
+	if in.MixedInstancesPolicy != nil {
+		in, out := &in.MixedInstancesPolicy, &out.MixedInstancesPolicy
+		*out = new(MixedInstancesPolicySpec)
+		if err := Convert_kops_MixedInstancesPolicySpec_To_v1alpha2_MixedInstancesPolicySpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.MixedInstancesPolicy = nil
+	}
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		*out = new(InstanceMetadataOptions)
+		if err := Convert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.InstanceMetadata = nil
+	}
+	if in.PlacementGroup != nil {
+		in, out := &in.PlacementGroup, &out.PlacementGroup
+		*out = new(PlacementGroupSpec)
+		if err := Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PlacementGroup = nil
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]*VolumeSpec, len(*in))
+		for i := range *in {
+			// TODO: Inefficient conversion - can we improve it?
+			if err := s.Convert(&(*in)[i], &(*out)[i], 0); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Volumes = nil
+	}
+	out.Regional = in.Regional
+	out.SecurityGroupOverride = in.SecurityGroupOverride
+	out.ImageAlias = in.ImageAlias
+	out.PreloadImages = in.PreloadImages
+	if in.BareMetal != nil {
+		in, out := &in.BareMetal, &out.BareMetal
+		*out = new(BareMetalSpec)
+		if err := Convert_kops_BareMetalSpec_To_v1alpha2_BareMetalSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.BareMetal = nil
 	}
 	return nil
 }
@@ -2239,6 +3325,52 @@ func Convert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.Insta
 	return autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions(in *InstanceMetadataOptions, out *kops.InstanceMetadataOptions, s conversion.Scope) error {
+	out.HTTPTokens = in.HTTPTokens
+	out.HTTPPutResponseHopLimit = in.HTTPPutResponseHopLimit
+	return nil
+}
+
+// Convert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions is an autogenerated conversion function.
+func Convert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions(in *InstanceMetadataOptions, out *kops.InstanceMetadataOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha2_InstanceMetadataOptions_To_kops_InstanceMetadataOptions(in, out, s)
+}
+
+func autoConvert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(in *kops.InstanceMetadataOptions, out *InstanceMetadataOptions, s conversion.Scope) error {
+	out.HTTPTokens = in.HTTPTokens
+	out.HTTPPutResponseHopLimit = in.HTTPPutResponseHopLimit
+	return nil
+}
+
+// Convert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions is an autogenerated conversion function.
+func Convert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(in *kops.InstanceMetadataOptions, out *InstanceMetadataOptions, s conversion.Scope) error {
+	return autoConvert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(in, out, s)
+}
+
+func autoConvert_v1alpha2_KMSEncryptionProviderSpec_To_kops_KMSEncryptionProviderSpec(in *KMSEncryptionProviderSpec, out *kops.KMSEncryptionProviderSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Endpoint = in.Endpoint
+	out.CacheSize = in.CacheSize
+	return nil
+}
+
+// Convert_v1alpha2_KMSEncryptionProviderSpec_To_kops_KMSEncryptionProviderSpec is an autogenerated conversion function.
+func Convert_v1alpha2_KMSEncryptionProviderSpec_To_kops_KMSEncryptionProviderSpec(in *KMSEncryptionProviderSpec, out *kops.KMSEncryptionProviderSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KMSEncryptionProviderSpec_To_kops_KMSEncryptionProviderSpec(in, out, s)
+}
+
+func autoConvert_kops_KMSEncryptionProviderSpec_To_v1alpha2_KMSEncryptionProviderSpec(in *kops.KMSEncryptionProviderSpec, out *KMSEncryptionProviderSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Endpoint = in.Endpoint
+	out.CacheSize = in.CacheSize
+	return nil
+}
+
+// Convert_kops_KMSEncryptionProviderSpec_To_v1alpha2_KMSEncryptionProviderSpec is an autogenerated conversion function.
+func Convert_kops_KMSEncryptionProviderSpec_To_v1alpha2_KMSEncryptionProviderSpec(in *kops.KMSEncryptionProviderSpec, out *KMSEncryptionProviderSpec, s conversion.Scope) error {
+	return autoConvert_kops_KMSEncryptionProviderSpec_To_v1alpha2_KMSEncryptionProviderSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_Keyset_To_kops_Keyset(in *Keyset, out *kops.Keyset, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_KeysetSpec_To_kops_KeysetSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -2417,9 +3549,44 @@ func Convert_kops_KopeioNetworkingSpec_To_v1alpha2_KopeioNetworkingSpec(in *kops
 	return autoConvert_kops_KopeioNetworkingSpec_To_v1alpha2_KopeioNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_KopsControllerSpec_To_kops_KopsControllerSpec(in *KopsControllerSpec, out *kops.KopsControllerSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Image = in.Image
+	out.Port = in.Port
+	out.CertificateTTL = in.CertificateTTL
+	return nil
+}
+
+// Convert_v1alpha2_KopsControllerSpec_To_kops_KopsControllerSpec is an autogenerated conversion function.
+func Convert_v1alpha2_KopsControllerSpec_To_kops_KopsControllerSpec(in *KopsControllerSpec, out *kops.KopsControllerSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KopsControllerSpec_To_kops_KopsControllerSpec(in, out, s)
+}
+
+func autoConvert_kops_KopsControllerSpec_To_v1alpha2_KopsControllerSpec(in *kops.KopsControllerSpec, out *KopsControllerSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Image = in.Image
+	out.Port = in.Port
+	out.CertificateTTL = in.CertificateTTL
+	return nil
+}
+
+// Convert_kops_KopsControllerSpec_To_v1alpha2_KopsControllerSpec is an autogenerated conversion function.
+func Convert_kops_KopsControllerSpec_To_v1alpha2_KopsControllerSpec(in *kops.KopsControllerSpec, out *KopsControllerSpec, s conversion.Scope) error {
+	return autoConvert_kops_KopsControllerSpec_To_v1alpha2_KopsControllerSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(in *KubeAPIServerConfig, out *kops.KubeAPIServerConfig, s conversion.Scope) error {
 	out.Image = in.Image
 	out.LogLevel = in.LogLevel
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(kops.ControlPlaneResources)
+		if err := Convert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Resources = nil
+	}
 	out.CloudProvider = in.CloudProvider
 	out.SecurePort = in.SecurePort
 	out.InsecurePort = in.InsecurePort
@@ -2491,6 +3658,15 @@ func Convert_v1alpha2_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(in *KubeAP
 func autoConvert_kops_KubeAPIServerConfig_To_v1alpha2_KubeAPIServerConfig(in *kops.KubeAPIServerConfig, out *KubeAPIServerConfig, s conversion.Scope) error {
 	out.Image = in.Image
 	out.LogLevel = in.LogLevel
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ControlPlaneResources)
+		if err := Convert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Resources = nil
+	}
 	out.CloudProvider = in.CloudProvider
 	out.SecurePort = in.SecurePort
 	out.InsecurePort = in.InsecurePort
@@ -2562,6 +3738,15 @@ func Convert_kops_KubeAPIServerConfig_To_v1alpha2_KubeAPIServerConfig(in *kops.K
 func autoConvert_v1alpha2_KubeControllerManagerConfig_To_kops_KubeControllerManagerConfig(in *KubeControllerManagerConfig, out *kops.KubeControllerManagerConfig, s conversion.Scope) error {
 	out.Master = in.Master
 	out.LogLevel = in.LogLevel
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(kops.ControlPlaneResources)
+		if err := Convert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Resources = nil
+	}
 	out.ServiceAccountPrivateKeyFile = in.ServiceAccountPrivateKeyFile
 	out.Image = in.Image
 	out.CloudProvider = in.CloudProvider
@@ -2603,6 +3788,15 @@ func Convert_v1alpha2_KubeControllerManagerConfig_To_kops_KubeControllerManagerC
 func autoConvert_kops_KubeControllerManagerConfig_To_v1alpha2_KubeControllerManagerConfig(in *kops.KubeControllerManagerConfig, out *KubeControllerManagerConfig, s conversion.Scope) error {
 	out.Master = in.Master
 	out.LogLevel = in.LogLevel
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ControlPlaneResources)
+		if err := Convert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Resources = nil
+	}
 	out.ServiceAccountPrivateKeyFile = in.ServiceAccountPrivateKeyFile
 	out.Image = in.Image
 	out.CloudProvider = in.CloudProvider
@@ -2651,6 +3845,24 @@ func autoConvert_v1alpha2_KubeDNSConfig_To_kops_KubeDNSConfig(in *KubeDNSConfig,
 	out.ServerIP = in.ServerIP
 	out.StubDomains = in.StubDomains
 	out.UpstreamNameservers = in.UpstreamNameservers
+	if in.Autoscaler != nil {
+		in, out := &in.Autoscaler, &out.Autoscaler
+		*out = new(kops.DNSAutoscalerConfig)
+		if err := Convert_v1alpha2_DNSAutoscalerConfig_To_kops_DNSAutoscalerConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Autoscaler = nil
+	}
+	if in.NodeLocalDNS != nil {
+		in, out := &in.NodeLocalDNS, &out.NodeLocalDNS
+		*out = new(kops.NodeLocalDNSConfig)
+		if err := Convert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeLocalDNS = nil
+	}
 	return nil
 }
 
@@ -2669,6 +3881,24 @@ func autoConvert_kops_KubeDNSConfig_To_v1alpha2_KubeDNSConfig(in *kops.KubeDNSCo
 	out.ServerIP = in.ServerIP
 	out.StubDomains = in.StubDomains
 	out.UpstreamNameservers = in.UpstreamNameservers
+	if in.Autoscaler != nil {
+		in, out := &in.Autoscaler, &out.Autoscaler
+		*out = new(DNSAutoscalerConfig)
+		if err := Convert_kops_DNSAutoscalerConfig_To_v1alpha2_DNSAutoscalerConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Autoscaler = nil
+	}
+	if in.NodeLocalDNS != nil {
+		in, out := &in.NodeLocalDNS, &out.NodeLocalDNS
+		*out = new(NodeLocalDNSConfig)
+		if err := Convert_kops_NodeLocalDNSConfig_To_v1alpha2_NodeLocalDNSConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeLocalDNS = nil
+	}
 	return nil
 }
 
@@ -2690,6 +3920,8 @@ func autoConvert_v1alpha2_KubeProxyConfig_To_kops_KubeProxyConfig(in *KubeProxyC
 	out.Master = in.Master
 	out.Enabled = in.Enabled
 	out.ProxyMode = in.ProxyMode
+	out.IPVSScheduler = in.IPVSScheduler
+	out.IPVSExcludeCIDRs = in.IPVSExcludeCIDRs
 	out.FeatureGates = in.FeatureGates
 	out.ConntrackMaxPerCore = in.ConntrackMaxPerCore
 	out.ConntrackMin = in.ConntrackMin
@@ -2714,6 +3946,8 @@ func autoConvert_kops_KubeProxyConfig_To_v1alpha2_KubeProxyConfig(in *kops.KubeP
 	out.Master = in.Master
 	out.Enabled = in.Enabled
 	out.ProxyMode = in.ProxyMode
+	out.IPVSScheduler = in.IPVSScheduler
+	out.IPVSExcludeCIDRs = in.IPVSExcludeCIDRs
 	out.FeatureGates = in.FeatureGates
 	out.ConntrackMaxPerCore = in.ConntrackMaxPerCore
 	out.ConntrackMin = in.ConntrackMin
@@ -2728,6 +3962,15 @@ func Convert_kops_KubeProxyConfig_To_v1alpha2_KubeProxyConfig(in *kops.KubeProxy
 func autoConvert_v1alpha2_KubeSchedulerConfig_To_kops_KubeSchedulerConfig(in *KubeSchedulerConfig, out *kops.KubeSchedulerConfig, s conversion.Scope) error {
 	out.Master = in.Master
 	out.LogLevel = in.LogLevel
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(kops.ControlPlaneResources)
+		if err := Convert_v1alpha2_ControlPlaneResources_To_kops_ControlPlaneResources(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Resources = nil
+	}
 	out.Image = in.Image
 	if in.LeaderElection != nil {
 		in, out := &in.LeaderElection, &out.LeaderElection
@@ -2751,6 +3994,15 @@ func Convert_v1alpha2_KubeSchedulerConfig_To_kops_KubeSchedulerConfig(in *KubeSc
 func autoConvert_kops_KubeSchedulerConfig_To_v1alpha2_KubeSchedulerConfig(in *kops.KubeSchedulerConfig, out *KubeSchedulerConfig, s conversion.Scope) error {
 	out.Master = in.Master
 	out.LogLevel = in.LogLevel
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ControlPlaneResources)
+		if err := Convert_kops_ControlPlaneResources_To_v1alpha2_ControlPlaneResources(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Resources = nil
+	}
 	out.Image = in.Image
 	if in.LeaderElection != nil {
 		in, out := &in.LeaderElection, &out.LeaderElection
@@ -3007,6 +4259,7 @@ func autoConvert_v1alpha2_LoadBalancerAccessSpec_To_kops_LoadBalancerAccessSpec(
 	out.AdditionalSecurityGroups = in.AdditionalSecurityGroups
 	out.UseForInternalApi = in.UseForInternalApi
 	out.SSLCertificate = in.SSLCertificate
+	out.SecurityGroupOverride = in.SecurityGroupOverride
 	return nil
 }
 
@@ -3021,6 +4274,7 @@ func autoConvert_kops_LoadBalancerAccessSpec_To_v1alpha2_LoadBalancerAccessSpec(
 	out.AdditionalSecurityGroups = in.AdditionalSecurityGroups
 	out.UseForInternalApi = in.UseForInternalApi
 	out.SSLCertificate = in.SSLCertificate
+	out.SecurityGroupOverride = in.SecurityGroupOverride
 	return nil
 }
 
@@ -3029,6 +4283,92 @@ func Convert_kops_LoadBalancerAccessSpec_To_v1alpha2_LoadBalancerAccessSpec(in *
 	return autoConvert_kops_LoadBalancerAccessSpec_To_v1alpha2_LoadBalancerAccessSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_MixedInstancesPolicySpec_To_kops_MixedInstancesPolicySpec(in *MixedInstancesPolicySpec, out *kops.MixedInstancesPolicySpec, s conversion.Scope) error {
+	out.Instances = in.Instances
+	return nil
+}
+
+// Convert_v1alpha2_MixedInstancesPolicySpec_To_kops_MixedInstancesPolicySpec is an autogenerated conversion function.
+func Convert_v1alpha2_MixedInstancesPolicySpec_To_kops_MixedInstancesPolicySpec(in *MixedInstancesPolicySpec, out *kops.MixedInstancesPolicySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_MixedInstancesPolicySpec_To_kops_MixedInstancesPolicySpec(in, out, s)
+}
+
+func autoConvert_kops_MixedInstancesPolicySpec_To_v1alpha2_MixedInstancesPolicySpec(in *kops.MixedInstancesPolicySpec, out *MixedInstancesPolicySpec, s conversion.Scope) error {
+	out.Instances = in.Instances
+	return nil
+}
+
+// Convert_kops_MixedInstancesPolicySpec_To_v1alpha2_MixedInstancesPolicySpec is an autogenerated conversion function.
+func Convert_kops_MixedInstancesPolicySpec_To_v1alpha2_MixedInstancesPolicySpec(in *kops.MixedInstancesPolicySpec, out *MixedInstancesPolicySpec, s conversion.Scope) error {
+	return autoConvert_kops_MixedInstancesPolicySpec_To_v1alpha2_MixedInstancesPolicySpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_NTPConfig_To_kops_NTPConfig(in *NTPConfig, out *kops.NTPConfig, s conversion.Scope) error {
+	out.Managed = in.Managed
+	out.Servers = in.Servers
+	return nil
+}
+
+// Convert_v1alpha2_NTPConfig_To_kops_NTPConfig is an autogenerated conversion function.
+func Convert_v1alpha2_NTPConfig_To_kops_NTPConfig(in *NTPConfig, out *kops.NTPConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NTPConfig_To_kops_NTPConfig(in, out, s)
+}
+
+func autoConvert_kops_NTPConfig_To_v1alpha2_NTPConfig(in *kops.NTPConfig, out *NTPConfig, s conversion.Scope) error {
+	out.Managed = in.Managed
+	out.Servers = in.Servers
+	return nil
+}
+
+// Convert_kops_NTPConfig_To_v1alpha2_NTPConfig is an autogenerated conversion function.
+func Convert_kops_NTPConfig_To_v1alpha2_NTPConfig(in *kops.NTPConfig, out *NTPConfig, s conversion.Scope) error {
+	return autoConvert_kops_NTPConfig_To_v1alpha2_NTPConfig(in, out, s)
+}
+
+func autoConvert_v1alpha2_NatInstanceSpec_To_kops_NatInstanceSpec(in *NatInstanceSpec, out *kops.NatInstanceSpec, s conversion.Scope) error {
+	out.MachineType = in.MachineType
+	out.Image = in.Image
+	return nil
+}
+
+// Convert_v1alpha2_NatInstanceSpec_To_kops_NatInstanceSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NatInstanceSpec_To_kops_NatInstanceSpec(in *NatInstanceSpec, out *kops.NatInstanceSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NatInstanceSpec_To_kops_NatInstanceSpec(in, out, s)
+}
+
+func autoConvert_kops_NatInstanceSpec_To_v1alpha2_NatInstanceSpec(in *kops.NatInstanceSpec, out *NatInstanceSpec, s conversion.Scope) error {
+	out.MachineType = in.MachineType
+	out.Image = in.Image
+	return nil
+}
+
+// Convert_kops_NatInstanceSpec_To_v1alpha2_NatInstanceSpec is an autogenerated conversion function.
+func Convert_kops_NatInstanceSpec_To_v1alpha2_NatInstanceSpec(in *kops.NatInstanceSpec, out *NatInstanceSpec, s conversion.Scope) error {
+	return autoConvert_kops_NatInstanceSpec_To_v1alpha2_NatInstanceSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_NetworkPolicySpec_To_kops_NetworkPolicySpec(in *NetworkPolicySpec, out *kops.NetworkPolicySpec, s conversion.Scope) error {
+	out.DefaultDeny = in.DefaultDeny
+	out.Namespaces = in.Namespaces
+	return nil
+}
+
+// Convert_v1alpha2_NetworkPolicySpec_To_kops_NetworkPolicySpec is an autogenerated conversion function.
+func Convert_v1alpha2_NetworkPolicySpec_To_kops_NetworkPolicySpec(in *NetworkPolicySpec, out *kops.NetworkPolicySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NetworkPolicySpec_To_kops_NetworkPolicySpec(in, out, s)
+}
+
+func autoConvert_kops_NetworkPolicySpec_To_v1alpha2_NetworkPolicySpec(in *kops.NetworkPolicySpec, out *NetworkPolicySpec, s conversion.Scope) error {
+	out.DefaultDeny = in.DefaultDeny
+	out.Namespaces = in.Namespaces
+	return nil
+}
+
+// Convert_kops_NetworkPolicySpec_To_v1alpha2_NetworkPolicySpec is an autogenerated conversion function.
+func Convert_kops_NetworkPolicySpec_To_v1alpha2_NetworkPolicySpec(in *kops.NetworkPolicySpec, out *NetworkPolicySpec, s conversion.Scope) error {
+	return autoConvert_kops_NetworkPolicySpec_To_v1alpha2_NetworkPolicySpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_NetworkingSpec_To_kops_NetworkingSpec(in *NetworkingSpec, out *kops.NetworkingSpec, s conversion.Scope) error {
 	if in.Classic != nil {
 		in, out := &in.Classic, &out.Classic
@@ -3349,7 +4689,162 @@ func Convert_kops_NodeAuthorizerSpec_To_v1alpha2_NodeAuthorizerSpec(in *kops.Nod
 	return autoConvert_kops_NodeAuthorizerSpec_To_v1alpha2_NodeAuthorizerSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig(in *NodeLocalDNSConfig, out *kops.NodeLocalDNSConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.LocalIP = in.LocalIP
+	out.CPURequest = in.CPURequest
+	out.MemoryRequest = in.MemoryRequest
+	out.MemoryLimit = in.MemoryLimit
+	return nil
+}
+
+// Convert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig is an autogenerated conversion function.
+func Convert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig(in *NodeLocalDNSConfig, out *kops.NodeLocalDNSConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeLocalDNSConfig_To_kops_NodeLocalDNSConfig(in, out, s)
+}
+
+func autoConvert_kops_NodeLocalDNSConfig_To_v1alpha2_NodeLocalDNSConfig(in *kops.NodeLocalDNSConfig, out *NodeLocalDNSConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.LocalIP = in.LocalIP
+	out.CPURequest = in.CPURequest
+	out.MemoryRequest = in.MemoryRequest
+	out.MemoryLimit = in.MemoryLimit
+	return nil
+}
+
+// Convert_kops_NodeLocalDNSConfig_To_v1alpha2_NodeLocalDNSConfig is an autogenerated conversion function.
+func Convert_kops_NodeLocalDNSConfig_To_v1alpha2_NodeLocalDNSConfig(in *kops.NodeLocalDNSConfig, out *NodeLocalDNSConfig, s conversion.Scope) error {
+	return autoConvert_kops_NodeLocalDNSConfig_To_v1alpha2_NodeLocalDNSConfig(in, out, s)
+}
+
+func autoConvert_v1alpha2_OIDCAuthenticationSpec_To_kops_OIDCAuthenticationSpec(in *OIDCAuthenticationSpec, out *kops.OIDCAuthenticationSpec, s conversion.Scope) error {
+	out.IssuerURL = in.IssuerURL
+	out.ClientID = in.ClientID
+	out.UsernameClaim = in.UsernameClaim
+	out.UsernamePrefix = in.UsernamePrefix
+	out.GroupsClaim = in.GroupsClaim
+	out.GroupsPrefix = in.GroupsPrefix
+	if in.Dex != nil {
+		in, out := &in.Dex, &out.Dex
+		*out = new(kops.DexAuthenticationSpec)
+		if err := Convert_v1alpha2_DexAuthenticationSpec_To_kops_DexAuthenticationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Dex = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OIDCAuthenticationSpec_To_kops_OIDCAuthenticationSpec is an autogenerated conversion function.
+func Convert_v1alpha2_OIDCAuthenticationSpec_To_kops_OIDCAuthenticationSpec(in *OIDCAuthenticationSpec, out *kops.OIDCAuthenticationSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OIDCAuthenticationSpec_To_kops_OIDCAuthenticationSpec(in, out, s)
+}
+
+func autoConvert_kops_OIDCAuthenticationSpec_To_v1alpha2_OIDCAuthenticationSpec(in *kops.OIDCAuthenticationSpec, out *OIDCAuthenticationSpec, s conversion.Scope) error {
+	out.IssuerURL = in.IssuerURL
+	out.ClientID = in.ClientID
+	out.UsernameClaim = in.UsernameClaim
+	out.UsernamePrefix = in.UsernamePrefix
+	out.GroupsClaim = in.GroupsClaim
+	out.GroupsPrefix = in.GroupsPrefix
+	if in.Dex != nil {
+		in, out := &in.Dex, &out.Dex
+		*out = new(DexAuthenticationSpec)
+		if err := Convert_kops_DexAuthenticationSpec_To_v1alpha2_DexAuthenticationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Dex = nil
+	}
+	return nil
+}
+
+// Convert_kops_OIDCAuthenticationSpec_To_v1alpha2_OIDCAuthenticationSpec is an autogenerated conversion function.
+func Convert_kops_OIDCAuthenticationSpec_To_v1alpha2_OIDCAuthenticationSpec(in *kops.OIDCAuthenticationSpec, out *OIDCAuthenticationSpec, s conversion.Scope) error {
+	return autoConvert_kops_OIDCAuthenticationSpec_To_v1alpha2_OIDCAuthenticationSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_PXESpec_To_kops_PXESpec(in *PXESpec, out *kops.PXESpec, s conversion.Scope) error {
+	out.ConfigBase = in.ConfigBase
+	out.Kernel = in.Kernel
+	out.Initrd = in.Initrd
+	out.Args = in.Args
+	return nil
+}
+
+// Convert_v1alpha2_PXESpec_To_kops_PXESpec is an autogenerated conversion function.
+func Convert_v1alpha2_PXESpec_To_kops_PXESpec(in *PXESpec, out *kops.PXESpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PXESpec_To_kops_PXESpec(in, out, s)
+}
+
+func autoConvert_kops_PXESpec_To_v1alpha2_PXESpec(in *kops.PXESpec, out *PXESpec, s conversion.Scope) error {
+	out.ConfigBase = in.ConfigBase
+	out.Kernel = in.Kernel
+	out.Initrd = in.Initrd
+	out.Args = in.Args
+	return nil
+}
+
+// Convert_kops_PXESpec_To_v1alpha2_PXESpec is an autogenerated conversion function.
+func Convert_kops_PXESpec_To_v1alpha2_PXESpec(in *kops.PXESpec, out *PXESpec, s conversion.Scope) error {
+	return autoConvert_kops_PXESpec_To_v1alpha2_PXESpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(in *PlacementGroupSpec, out *kops.PlacementGroupSpec, s conversion.Scope) error {
+	out.Strategy = in.Strategy
+	out.PartitionCount = in.PartitionCount
+	return nil
+}
+
+// Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec is an autogenerated conversion function.
+func Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(in *PlacementGroupSpec, out *kops.PlacementGroupSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(in, out, s)
+}
+
+func autoConvert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(in *kops.PlacementGroupSpec, out *PlacementGroupSpec, s conversion.Scope) error {
+	out.Strategy = in.Strategy
+	out.PartitionCount = in.PartitionCount
+	return nil
+}
+
+// Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec is an autogenerated conversion function.
+func Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(in *kops.PlacementGroupSpec, out *PlacementGroupSpec, s conversion.Scope) error {
+	return autoConvert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_PodSecuritySpec_To_kops_PodSecuritySpec(in *PodSecuritySpec, out *kops.PodSecuritySpec, s conversion.Scope) error {
+	out.Preset = in.Preset
+	return nil
+}
+
+// Convert_v1alpha2_PodSecuritySpec_To_kops_PodSecuritySpec is an autogenerated conversion function.
+func Convert_v1alpha2_PodSecuritySpec_To_kops_PodSecuritySpec(in *PodSecuritySpec, out *kops.PodSecuritySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PodSecuritySpec_To_kops_PodSecuritySpec(in, out, s)
+}
+
+func autoConvert_kops_PodSecuritySpec_To_v1alpha2_PodSecuritySpec(in *kops.PodSecuritySpec, out *PodSecuritySpec, s conversion.Scope) error {
+	out.Preset = in.Preset
+	return nil
+}
+
+// Convert_kops_PodSecuritySpec_To_v1alpha2_PodSecuritySpec is an autogenerated conversion function.
+func Convert_kops_PodSecuritySpec_To_v1alpha2_PodSecuritySpec(in *kops.PodSecuritySpec, out *PodSecuritySpec, s conversion.Scope) error {
+	return autoConvert_kops_PodSecuritySpec_To_v1alpha2_PodSecuritySpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RBACAuthorizationSpec, out *kops.RBACAuthorizationSpec, s conversion.Scope) error {
+	out.AdminGroups = in.AdminGroups
+	out.ReadOnlyGroups = in.ReadOnlyGroups
+	if in.CIServiceAccount != nil {
+		in, out := &in.CIServiceAccount, &out.CIServiceAccount
+		*out = new(kops.RBACServiceAccountSpec)
+		if err := Convert_v1alpha2_RBACServiceAccountSpec_To_kops_RBACServiceAccountSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CIServiceAccount = nil
+	}
 	return nil
 }
 
@@ -3359,6 +4854,17 @@ func Convert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RB
 }
 
 func autoConvert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec(in *kops.RBACAuthorizationSpec, out *RBACAuthorizationSpec, s conversion.Scope) error {
+	out.AdminGroups = in.AdminGroups
+	out.ReadOnlyGroups = in.ReadOnlyGroups
+	if in.CIServiceAccount != nil {
+		in, out := &in.CIServiceAccount, &out.CIServiceAccount
+		*out = new(RBACServiceAccountSpec)
+		if err := Convert_kops_RBACServiceAccountSpec_To_v1alpha2_RBACServiceAccountSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CIServiceAccount = nil
+	}
 	return nil
 }
 
@@ -3367,6 +4873,30 @@ func Convert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec(in *ko
 	return autoConvert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_RBACServiceAccountSpec_To_kops_RBACServiceAccountSpec(in *RBACServiceAccountSpec, out *kops.RBACServiceAccountSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	out.ClusterRole = in.ClusterRole
+	return nil
+}
+
+// Convert_v1alpha2_RBACServiceAccountSpec_To_kops_RBACServiceAccountSpec is an autogenerated conversion function.
+func Convert_v1alpha2_RBACServiceAccountSpec_To_kops_RBACServiceAccountSpec(in *RBACServiceAccountSpec, out *kops.RBACServiceAccountSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_RBACServiceAccountSpec_To_kops_RBACServiceAccountSpec(in, out, s)
+}
+
+func autoConvert_kops_RBACServiceAccountSpec_To_v1alpha2_RBACServiceAccountSpec(in *kops.RBACServiceAccountSpec, out *RBACServiceAccountSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	out.ClusterRole = in.ClusterRole
+	return nil
+}
+
+// Convert_kops_RBACServiceAccountSpec_To_v1alpha2_RBACServiceAccountSpec is an autogenerated conversion function.
+func Convert_kops_RBACServiceAccountSpec_To_v1alpha2_RBACServiceAccountSpec(in *kops.RBACServiceAccountSpec, out *RBACServiceAccountSpec, s conversion.Scope) error {
+	return autoConvert_kops_RBACServiceAccountSpec_To_v1alpha2_RBACServiceAccountSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_RomanaNetworkingSpec_To_kops_RomanaNetworkingSpec(in *RomanaNetworkingSpec, out *kops.RomanaNetworkingSpec, s conversion.Scope) error {
 	out.DaemonServiceIP = in.DaemonServiceIP
 	out.EtcdServiceIP = in.EtcdServiceIP
@@ -3477,6 +5007,24 @@ func Convert_kops_SSHCredentialSpec_To_v1alpha2_SSHCredentialSpec(in *kops.SSHCr
 	return autoConvert_kops_SSHCredentialSpec_To_v1alpha2_SSHCredentialSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_SessionManagerSpec_To_kops_SessionManagerSpec(in *SessionManagerSpec, out *kops.SessionManagerSpec, s conversion.Scope) error {
+	return nil
+}
+
+// Convert_v1alpha2_SessionManagerSpec_To_kops_SessionManagerSpec is an autogenerated conversion function.
+func Convert_v1alpha2_SessionManagerSpec_To_kops_SessionManagerSpec(in *SessionManagerSpec, out *kops.SessionManagerSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SessionManagerSpec_To_kops_SessionManagerSpec(in, out, s)
+}
+
+func autoConvert_kops_SessionManagerSpec_To_v1alpha2_SessionManagerSpec(in *kops.SessionManagerSpec, out *SessionManagerSpec, s conversion.Scope) error {
+	return nil
+}
+
+// Convert_kops_SessionManagerSpec_To_v1alpha2_SessionManagerSpec is an autogenerated conversion function.
+func Convert_kops_SessionManagerSpec_To_v1alpha2_SessionManagerSpec(in *kops.SessionManagerSpec, out *SessionManagerSpec, s conversion.Scope) error {
+	return autoConvert_kops_SessionManagerSpec_To_v1alpha2_SessionManagerSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_TargetSpec_To_kops_TargetSpec(in *TargetSpec, out *kops.TargetSpec, s conversion.Scope) error {
 	if in.Terraform != nil {
 		in, out := &in.Terraform, &out.Terraform
@@ -3554,6 +5102,24 @@ func autoConvert_v1alpha2_TopologySpec_To_kops_TopologySpec(in *TopologySpec, ou
 	} else {
 		out.DNS = nil
 	}
+	if in.NatInstance != nil {
+		in, out := &in.NatInstance, &out.NatInstance
+		*out = new(kops.NatInstanceSpec)
+		if err := Convert_v1alpha2_NatInstanceSpec_To_kops_NatInstanceSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NatInstance = nil
+	}
+	if in.SessionManager != nil {
+		in, out := &in.SessionManager, &out.SessionManager
+		*out = new(kops.SessionManagerSpec)
+		if err := Convert_v1alpha2_SessionManagerSpec_To_kops_SessionManagerSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.SessionManager = nil
+	}
 	return nil
 }
 
@@ -3583,6 +5149,24 @@ func autoConvert_kops_TopologySpec_To_v1alpha2_TopologySpec(in *kops.TopologySpe
 	} else {
 		out.DNS = nil
 	}
+	if in.NatInstance != nil {
+		in, out := &in.NatInstance, &out.NatInstance
+		*out = new(NatInstanceSpec)
+		if err := Convert_kops_NatInstanceSpec_To_v1alpha2_NatInstanceSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NatInstance = nil
+	}
+	if in.SessionManager != nil {
+		in, out := &in.SessionManager, &out.SessionManager
+		*out = new(SessionManagerSpec)
+		if err := Convert_kops_SessionManagerSpec_To_v1alpha2_SessionManagerSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.SessionManager = nil
+	}
 	return nil
 }
 
@@ -3615,6 +5199,48 @@ func Convert_kops_UserData_To_v1alpha2_UserData(in *kops.UserData, out *UserData
 	return autoConvert_kops_UserData_To_v1alpha2_UserData(in, out, s)
 }
 
+func autoConvert_v1alpha2_VolumeSpec_To_kops_VolumeSpec(in *VolumeSpec, out *kops.VolumeSpec, s conversion.Scope) error {
+	out.Device = in.Device
+	out.Ephemeral = in.Ephemeral
+	out.Raid0 = in.Raid0
+	out.Size = in.Size
+	out.Type = in.Type
+	out.Iops = in.Iops
+	out.Throughput = in.Throughput
+	out.Encrypted = in.Encrypted
+	out.DeleteOnTermination = in.DeleteOnTermination
+	out.Filesystem = in.Filesystem
+	out.Path = in.Path
+	out.MountOptions = in.MountOptions
+	return nil
+}
+
+// Convert_v1alpha2_VolumeSpec_To_kops_VolumeSpec is an autogenerated conversion function.
+func Convert_v1alpha2_VolumeSpec_To_kops_VolumeSpec(in *VolumeSpec, out *kops.VolumeSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VolumeSpec_To_kops_VolumeSpec(in, out, s)
+}
+
+func autoConvert_kops_VolumeSpec_To_v1alpha2_VolumeSpec(in *kops.VolumeSpec, out *VolumeSpec, s conversion.Scope) error {
+	out.Device = in.Device
+	out.Ephemeral = in.Ephemeral
+	out.Raid0 = in.Raid0
+	out.Size = in.Size
+	out.Type = in.Type
+	out.Iops = in.Iops
+	out.Throughput = in.Throughput
+	out.Encrypted = in.Encrypted
+	out.DeleteOnTermination = in.DeleteOnTermination
+	out.Filesystem = in.Filesystem
+	out.Path = in.Path
+	out.MountOptions = in.MountOptions
+	return nil
+}
+
+// Convert_kops_VolumeSpec_To_v1alpha2_VolumeSpec is an autogenerated conversion function.
+func Convert_kops_VolumeSpec_To_v1alpha2_VolumeSpec(in *kops.VolumeSpec, out *VolumeSpec, s conversion.Scope) error {
+	return autoConvert_kops_VolumeSpec_To_v1alpha2_VolumeSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_WeaveNetworkingSpec_To_kops_WeaveNetworkingSpec(in *WeaveNetworkingSpec, out *kops.WeaveNetworkingSpec, s conversion.Scope) error {
 	out.MTU = in.MTU
 	out.ConnLimit = in.ConnLimit
@@ -17,6 +17,7 @@ limitations under the License.
 package validation
 
 import (
+	"regexp"
 	"strings"
 
 	"fmt"
@@ -24,6 +25,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
 
@@ -33,9 +36,148 @@ func awsValidateCluster(c *kops.Cluster) field.ErrorList {
 	if c.Spec.API != nil {
 		if c.Spec.API.LoadBalancer != nil {
 			allErrs = append(allErrs, awsValidateAdditionalSecurityGroups(field.NewPath("spec", "api", "loadBalancer", "additionalSecurityGroups"), c.Spec.API.LoadBalancer.AdditionalSecurityGroups)...)
+			allErrs = append(allErrs, awsValidateSecurityGroupOverride(field.NewPath("spec", "api", "loadBalancer", "securityGroupOverride"), c.Spec.API.LoadBalancer.SecurityGroupOverride)...)
 		}
 	}
 
+	switch c.Spec.SecurityGroupOverrideMode {
+	case "", kops.SecurityGroupOverrideModeUnmanaged, kops.SecurityGroupOverrideModeAdditive:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "securityGroupOverrideMode"), c.Spec.SecurityGroupOverrideMode, []string{kops.SecurityGroupOverrideModeUnmanaged, kops.SecurityGroupOverrideModeAdditive}))
+	}
+
+	allErrs = append(allErrs, awsValidatePartition(c)...)
+
+	if c.Spec.CloudConfig != nil && c.Spec.CloudConfig.AWSAssumeRole != nil {
+		allErrs = append(allErrs, awsValidateAssumeRole(field.NewPath("spec", "cloudConfig", "awsAssumeRole"), c.Spec.CloudConfig.AWSAssumeRole)...)
+	}
+
+	if c.Spec.CloudConfig != nil && c.Spec.CloudConfig.AWSDNSAssumeRole != nil {
+		fieldPath := field.NewPath("spec", "cloudConfig", "awsDNSAssumeRole")
+		allErrs = append(allErrs, awsValidateAssumeRole(fieldPath, c.Spec.CloudConfig.AWSDNSAssumeRole)...)
+		if dns.IsGossipHostname(c.ObjectMeta.Name) || dns.ExternalProviderID(c) != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath, "the DNS role is only used for Route53, not for gossip clusters or external DNS providers"))
+		}
+	}
+
+	if c.Spec.DNSZoneOptions != nil {
+		allErrs = append(allErrs, awsValidateDNSZoneOptions(c, field.NewPath("spec", "dnsZoneOptions"))...)
+	}
+
+	for i, etcd := range c.Spec.EtcdClusters {
+		for j, m := range etcd.Members {
+			fieldPath := field.NewPath("spec", "etcdClusters").Index(i).Child("etcdMembers").Index(j)
+			allErrs = append(allErrs, awsValidateVolume(fieldPath, "volume", m.VolumeType, m.VolumeSize, m.VolumeIops, m.VolumeThroughput)...)
+		}
+	}
+
+	return allErrs
+}
+
+// validAWSPartition matches partition ids such as aws, aws-cn and aws-us-gov
+var validAWSPartition = regexp.MustCompile(`^aws(-[a-z]+)*$`)
+
+func awsValidatePartition(c *kops.Cluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.Spec.CloudConfig != nil && c.Spec.CloudConfig.AWSPartition != nil {
+		if !validAWSPartition.MatchString(*c.Spec.CloudConfig.AWSPartition) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "cloudConfig", "awsPartition"), *c.Spec.CloudConfig.AWSPartition, "must be an AWS partition, such as aws, aws-cn or aws-us-gov"))
+		}
+	}
+
+	// An invalid region is reported elsewhere
+	region, err := awsup.FindRegion(c)
+	if err != nil || region == "" {
+		return allErrs
+	}
+
+	partition := awsup.PartitionForCluster(c, region)
+	if !awsup.PartitionHasService(partition, "route53") && !dns.IsGossipHostname(c.ObjectMeta.Name) && dns.ExternalProviderID(c) == "" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), c.ObjectMeta.Name, fmt.Sprintf("Route53 is not available in the %s partition; use a gossip cluster name ending in .k8s.local, or an external DNS provider", partition)))
+	}
+
+	return allErrs
+}
+
+// validAssumeRoleSessionName matches the role session names STS accepts
+var validAssumeRoleSessionName = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+func awsValidateAssumeRole(fieldPath *field.Path, spec *kops.AWSAssumeRoleSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.RoleARN == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("roleARN"), "the ARN of the role to assume is required"))
+	} else if !validIAMRoleARN.MatchString(spec.RoleARN) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("roleARN"), spec.RoleARN, "must be the ARN of an IAM role, such as arn:aws:iam::123456789012:role/KopsAdmin"))
+	}
+
+	if spec.ExternalID != "" && (len(spec.ExternalID) < 2 || len(spec.ExternalID) > 1224) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("externalID"), spec.ExternalID, "must be between 2 and 1224 characters"))
+	}
+
+	if spec.SessionName != "" && !validAssumeRoleSessionName.MatchString(spec.SessionName) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("sessionName"), spec.SessionName, "must be 2 to 64 letters, digits or any of +=,.@_-"))
+	}
+
+	if len(spec.SessionTags) > 50 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("sessionTags"), len(spec.SessionTags), "at most 50 session tags are allowed"))
+	}
+	for k, v := range spec.SessionTags {
+		if len(k) == 0 || len(k) > 128 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("sessionTags"), k, "tag keys must be between 1 and 128 characters"))
+		}
+		if len(v) > 256 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("sessionTags").Key(k), v, "tag values must be at most 256 characters"))
+		}
+	}
+
+	return allErrs
+}
+
+func awsValidateDNSZoneOptions(c *kops.Cluster, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	options := c.Spec.DNSZoneOptions
+
+	if dns.IsGossipHostname(c.ObjectMeta.Name) {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "DNS zone options cannot be used with gossip DNS"))
+		return allErrs
+	}
+
+	if options.Private && c.Spec.DNSZone == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "dnsZone"), "the DNS zone must be specified for a private hosted zone, so that it can be created"))
+	}
+
+	if !options.Private {
+		if len(options.AdditionalVPCs) != 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("additionalVPCs"), "additional VPCs can only be associated with a private hosted zone"))
+		}
+		if options.SplitHorizon {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("splitHorizon"), "split-horizon DNS requires a private hosted zone"))
+		}
+	}
+
+	for i, vpc := range options.AdditionalVPCs {
+		if !strings.HasPrefix(vpc.ID, "vpc-") {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("additionalVPCs").Index(i).Child("id"), vpc.ID, "VPC ID does not match the expected AWS format"))
+		}
+	}
+
+	if options.SplitHorizon {
+		// The public record can only point to the API load balancer, and only if it is reachable from outside the VPC
+		if c.Spec.API == nil || c.Spec.API.LoadBalancer == nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("splitHorizon"), "split-horizon DNS requires a load balancer for the API"))
+		} else if c.Spec.API.LoadBalancer.Type != kops.LoadBalancerTypePublic {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("splitHorizon"), "split-horizon DNS requires a Public API load balancer"))
+		}
+
+		if options.PublicZone == "" && c.Spec.DNSZone != "" && !strings.Contains(c.Spec.DNSZone, ".") {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("publicZone"), "the public zone must be specified when the DNS zone is specified by ID"))
+		}
+	} else if options.PublicZone != "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("publicZone"), "the public zone can only be specified with split-horizon DNS"))
+	}
+
 	return allErrs
 }
 
@@ -44,10 +186,51 @@ func awsValidateInstanceGroup(ig *kops.InstanceGroup) field.ErrorList {
 
 	allErrs = append(allErrs, awsValidateAdditionalSecurityGroups(field.NewPath("spec", "additionalSecurityGroups"), ig.Spec.AdditionalSecurityGroups)...)
 
+	allErrs = append(allErrs, awsValidateSecurityGroupOverride(field.NewPath("spec", "securityGroupOverride"), ig.Spec.SecurityGroupOverride)...)
+
 	allErrs = append(allErrs, awsValidateMachineType(field.NewPath(ig.GetName(), "spec", "machineType"), ig.Spec.MachineType)...)
 
 	allErrs = append(allErrs, awsValidateAMIforNVMe(field.NewPath(ig.GetName(), "spec", "machineType"), ig)...)
 
+	allErrs = append(allErrs, awsValidateVolume(field.NewPath("spec"), "rootVolume", ig.Spec.RootVolumeType, ig.Spec.RootVolumeSize, ig.Spec.RootVolumeIops, ig.Spec.RootVolumeThroughput)...)
+
+	for i, v := range ig.Spec.Volumes {
+		if !v.Ephemeral {
+			allErrs = append(allErrs, awsValidateVolume(field.NewPath("spec", "volumes").Index(i), "", v.Type, v.Size, v.Iops, v.Throughput)...)
+		}
+	}
+
+	return allErrs
+}
+
+func awsValidateSecurityGroupOverride(fieldPath *field.Path, override *string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if override != nil && !strings.HasPrefix(*override, "sg-") {
+		allErrs = append(allErrs, field.Invalid(fieldPath, *override, "security group does not match the expected AWS format"))
+	}
+
+	return allErrs
+}
+
+// awsValidateSecurityGroupOverrides checks that all the instance groups of a role use the same security group,
+// as the security group replaces the one kops would create for the role
+func awsValidateSecurityGroupOverrides(groups []*kops.InstanceGroup) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	byRole := make(map[kops.InstanceGroupRole]*kops.InstanceGroup)
+	for _, g := range groups {
+		first := byRole[g.Spec.Role]
+		if first == nil {
+			byRole[g.Spec.Role] = g
+			continue
+		}
+		if fi.StringValue(first.Spec.SecurityGroupOverride) != fi.StringValue(g.Spec.SecurityGroupOverride) {
+			fieldPath := field.NewPath(g.ObjectMeta.Name, "spec", "securityGroupOverride")
+			allErrs = append(allErrs, field.Invalid(fieldPath, fi.StringValue(g.Spec.SecurityGroupOverride), fmt.Sprintf("all %s instance groups must use the same securityGroupOverride as %q", g.Spec.Role, first.ObjectMeta.Name)))
+		}
+	}
+
 	return allErrs
 }
 
@@ -104,3 +287,81 @@ func awsValidateAMIforNVMe(fieldPath *field.Path, ig *kops.InstanceGroup) field.
 	}
 	return allErrs
 }
+
+// awsEBSVolumeLimits are the size (GiB), IOPS and throughput (MiB/s) limits of the EBS volume types
+var awsEBSVolumeLimits = map[string]struct {
+	MinSize, MaxSize int32
+	// MinIops and MaxIops are zero for volume types without provisioned IOPS
+	MinIops, MaxIops int32
+	// MaxIopsPerGiB limits the IOPS relative to the size of the volume
+	MaxIopsPerGiB int32
+	// MinThroughput and MaxThroughput are zero for volume types without provisioned throughput
+	MinThroughput, MaxThroughput int32
+}{
+	"standard": {MinSize: 1, MaxSize: 1024},
+	"gp2":      {MinSize: 1, MaxSize: 16384},
+	"gp3":      {MinSize: 1, MaxSize: 16384, MinIops: 3000, MaxIops: 16000, MaxIopsPerGiB: 500, MinThroughput: 125, MaxThroughput: 1000},
+	"io1":      {MinSize: 4, MaxSize: 16384, MinIops: 100, MaxIops: 64000, MaxIopsPerGiB: 50},
+	"io2":      {MinSize: 4, MaxSize: 16384, MinIops: 100, MaxIops: 64000, MaxIopsPerGiB: 500},
+	"st1":      {MinSize: 125, MaxSize: 16384},
+	"sc1":      {MinSize: 125, MaxSize: 16384},
+}
+
+// awsValidateVolume checks an EBS volume against the constraints of its volume type.
+// The fields are named <prefix>Type, <prefix>Size, <prefix>Iops and <prefix>Throughput, or type, size, iops and
+// throughput without a prefix.
+func awsValidateVolume(fieldPath *field.Path, prefix string, volumeType *string, size *int32, iops *int32, throughput *int32) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	child := func(name string) *field.Path {
+		if prefix == "" {
+			return fieldPath.Child(strings.ToLower(name))
+		}
+		return fieldPath.Child(prefix + name)
+	}
+
+	t := fi.StringValue(volumeType)
+	if t == "" {
+		t = "gp2"
+	}
+	limits, found := awsEBSVolumeLimits[t]
+	if !found {
+		allErrs = append(allErrs, field.NotSupported(child("Type"), t, sets.StringKeySet(awsEBSVolumeLimits).List()))
+		return allErrs
+	}
+
+	if size != nil && (*size < limits.MinSize || *size > limits.MaxSize) {
+		allErrs = append(allErrs, field.Invalid(child("Size"), *size, fmt.Sprintf("%s volumes must be between %d and %d GiB", t, limits.MinSize, limits.MaxSize)))
+	}
+
+	if iops != nil {
+		fldPath := child("Iops")
+		if limits.MaxIops == 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("IOPS cannot be provisioned for %s volumes", t)))
+		} else if *iops < limits.MinIops || *iops > limits.MaxIops {
+			allErrs = append(allErrs, field.Invalid(fldPath, *iops, fmt.Sprintf("%s volumes support between %d and %d IOPS", t, limits.MinIops, limits.MaxIops)))
+		} else if size != nil && *iops > *size*limits.MaxIopsPerGiB {
+			allErrs = append(allErrs, field.Invalid(fldPath, *iops, fmt.Sprintf("%s volumes support at most %d IOPS per GiB", t, limits.MaxIopsPerGiB)))
+		}
+	}
+
+	if throughput != nil {
+		fldPath := child("Throughput")
+		if limits.MaxThroughput == 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("throughput cannot be provisioned for %s volumes", t)))
+		} else if *throughput < limits.MinThroughput || *throughput > limits.MaxThroughput {
+			allErrs = append(allErrs, field.Invalid(fldPath, *throughput, fmt.Sprintf("%s volumes support between %d and %d MiB/s", t, limits.MinThroughput, limits.MaxThroughput)))
+		} else {
+			// Throughput is limited to a quarter of the IOPS
+			effectiveIops := limits.MinIops
+			if iops != nil {
+				effectiveIops = *iops
+			}
+			if *throughput > effectiveIops/4 {
+				allErrs = append(allErrs, field.Invalid(fldPath, *throughput, fmt.Sprintf("throughput cannot exceed IOPS / 4 (%d MiB/s)", effectiveIops/4)))
+			}
+		}
+	}
+
+	return allErrs
+}
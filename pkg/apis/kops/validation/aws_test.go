@@ -17,10 +17,13 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
 )
 
 func TestValidateInstanceGroupSpec(t *testing.T) {
@@ -115,3 +118,306 @@ func TestValidateInstanceGroupSpec(t *testing.T) {
 		testErrors(t, g.Input, errs, g.ExpectedErrors)
 	}
 }
+
+func TestValidateDNSZoneOptions(t *testing.T) {
+	publicAPI := &kops.AccessSpec{LoadBalancer: &kops.LoadBalancerAccessSpec{Type: kops.LoadBalancerTypePublic}}
+	internalAPI := &kops.AccessSpec{LoadBalancer: &kops.LoadBalancerAccessSpec{Type: kops.LoadBalancerTypeInternal}}
+
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				DNSZone: "example.com",
+				DNSZoneOptions: &kops.DNSZoneOptions{
+					Private:        true,
+					AdditionalVPCs: []kops.DNSZoneVPC{{ID: "vpc-12345678", Region: "us-west-2"}},
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				DNSZoneOptions: &kops.DNSZoneOptions{Private: true},
+			},
+			ExpectedErrors: []string{"Required value::spec.dnsZone"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				DNSZone: "example.com",
+				DNSZoneOptions: &kops.DNSZoneOptions{
+					Private:        true,
+					AdditionalVPCs: []kops.DNSZoneVPC{{ID: "12345678"}},
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.dnsZoneOptions.additionalVPCs[0].id"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				DNSZone:        "example.com",
+				DNSZoneOptions: &kops.DNSZoneOptions{SplitHorizon: true},
+				API:            publicAPI,
+			},
+			ExpectedErrors: []string{"Forbidden::spec.dnsZoneOptions.splitHorizon"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				DNSZone:        "example.com",
+				DNSZoneOptions: &kops.DNSZoneOptions{Private: true, SplitHorizon: true, PublicZone: "Z1234"},
+				API:            publicAPI,
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				DNSZone:        "example.com",
+				DNSZoneOptions: &kops.DNSZoneOptions{Private: true, SplitHorizon: true},
+				API:            internalAPI,
+			},
+			ExpectedErrors: []string{"Forbidden::spec.dnsZoneOptions.splitHorizon"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				DNSZone:        "example.com",
+				DNSZoneOptions: &kops.DNSZoneOptions{Private: true, PublicZone: "Z1234"},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.dnsZoneOptions.publicZone"},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "test.example.com",
+			},
+			Spec: g.Input,
+		}
+		errs := awsValidateCluster(cluster)
+
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateAWSPartition(t *testing.T) {
+	grid := []struct {
+		Name           string
+		Zone           string
+		Partition      *string
+		ExpectedErrors []string
+	}{
+		{
+			Name: "test.example.com",
+			Zone: "us-east-1a",
+		},
+		{
+			Name:           "test.example.com",
+			Zone:           "cn-north-1a",
+			ExpectedErrors: []string{"Invalid value::metadata.name"},
+		},
+		{
+			Name: "test.k8s.local",
+			Zone: "cn-north-1a",
+		},
+		{
+			Name:           "test.example.com",
+			Zone:           "us-gov-west-1a",
+			ExpectedErrors: []string{"Invalid value::metadata.name"},
+		},
+		{
+			Name:           "test.example.com",
+			Zone:           "us-east-1a",
+			Partition:      fi.String("aws-us-gov"),
+			ExpectedErrors: []string{"Invalid value::metadata.name"},
+		},
+		{
+			Name:           "test.k8s.local",
+			Zone:           "us-east-1a",
+			Partition:      fi.String("amazon"),
+			ExpectedErrors: []string{"Invalid value::spec.cloudConfig.awsPartition"},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name: g.Name,
+			},
+			Spec: kops.ClusterSpec{
+				Subnets:     []kops.ClusterSubnetSpec{{Name: "subnet", Zone: g.Zone}},
+				CloudConfig: &kops.CloudConfiguration{AWSPartition: g.Partition},
+			},
+		}
+		errs := awsValidateCluster(cluster)
+
+		testErrors(t, g.Name+" in "+g.Zone, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateAWSAssumeRole(t *testing.T) {
+	grid := []struct {
+		Input          kops.AWSAssumeRoleSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.AWSAssumeRoleSpec{
+				RoleARN:     "arn:aws:iam::123456789012:role/KopsAdmin",
+				ExternalID:  "tooling",
+				SessionName: "kops-ci",
+				SessionTags: map[string]string{"team": "platform"},
+			},
+		},
+		{
+			Input: kops.AWSAssumeRoleSpec{
+				RoleARN: "arn:aws-cn:iam::123456789012:role/path/KopsAdmin",
+			},
+		},
+		{
+			Input:          kops.AWSAssumeRoleSpec{},
+			ExpectedErrors: []string{"Required value::spec.cloudConfig.awsAssumeRole.roleARN"},
+		},
+		{
+			Input: kops.AWSAssumeRoleSpec{
+				RoleARN: "arn:aws:iam::123456789012:user/kops",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.cloudConfig.awsAssumeRole.roleARN"},
+		},
+		{
+			Input: kops.AWSAssumeRoleSpec{
+				RoleARN:     "arn:aws:iam::123456789012:role/KopsAdmin",
+				SessionName: "kops ci",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.cloudConfig.awsAssumeRole.sessionName"},
+		},
+		{
+			Input: kops.AWSAssumeRoleSpec{
+				RoleARN:    "arn:aws:iam::123456789012:role/KopsAdmin",
+				ExternalID: "x",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.cloudConfig.awsAssumeRole.externalID"},
+		},
+	}
+	for _, g := range grid {
+		errs := awsValidateAssumeRole(field.NewPath("spec", "cloudConfig", "awsAssumeRole"), &g.Input)
+
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateAWSDNSAssumeRole(t *testing.T) {
+	grid := []struct {
+		Name           string
+		ExpectedErrors []string
+	}{
+		{
+			Name: "test.example.com",
+		},
+		{
+			Name:           "test.k8s.local",
+			ExpectedErrors: []string{"Forbidden::spec.cloudConfig.awsDNSAssumeRole"},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name: g.Name,
+			},
+			Spec: kops.ClusterSpec{
+				Subnets: []kops.ClusterSubnetSpec{{Name: "subnet", Zone: "us-east-1a"}},
+				CloudConfig: &kops.CloudConfiguration{
+					AWSDNSAssumeRole: &kops.AWSAssumeRoleSpec{RoleARN: "arn:aws:iam::210987654321:role/dns"},
+				},
+			},
+		}
+		errs := awsValidateCluster(cluster)
+
+		testErrors(t, g.Name, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateAWSVolume(t *testing.T) {
+	grid := []struct {
+		Type           string
+		Size           *int32
+		Iops           *int32
+		Throughput     *int32
+		ExpectedErrors []string
+	}{
+		{Type: "", Size: fi.Int32(64)},
+		{Type: "gp3", Size: fi.Int32(64), Iops: fi.Int32(4000), Throughput: fi.Int32(250)},
+		{Type: "gp3", Size: fi.Int32(64), Throughput: fi.Int32(1000), ExpectedErrors: []string{"Invalid value::spec.rootVolumeThroughput"}},
+		{Type: "gp3", Size: fi.Int32(64), Iops: fi.Int32(2000), ExpectedErrors: []string{"Invalid value::spec.rootVolumeIops"}},
+		{Type: "gp2", Size: fi.Int32(64), Iops: fi.Int32(200), Throughput: fi.Int32(200), ExpectedErrors: []string{"Forbidden::spec.rootVolumeIops", "Forbidden::spec.rootVolumeThroughput"}},
+		{Type: "io1", Size: fi.Int32(20), Iops: fi.Int32(2000), ExpectedErrors: []string{"Invalid value::spec.rootVolumeIops"}},
+		{Type: "io2", Size: fi.Int32(20), Iops: fi.Int32(2000)},
+		{Type: "st1", Size: fi.Int32(64), ExpectedErrors: []string{"Invalid value::spec.rootVolumeSize"}},
+		{Type: "magnetic", ExpectedErrors: []string{"Unsupported value::spec.rootVolumeType"}},
+	}
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "test-nodes",
+			},
+			Spec: kops.InstanceGroupSpec{
+				RootVolumeSize:       g.Size,
+				RootVolumeIops:       g.Iops,
+				RootVolumeThroughput: g.Throughput,
+			},
+		}
+		if g.Type != "" {
+			ig.Spec.RootVolumeType = fi.String(g.Type)
+		}
+		errs := awsValidateInstanceGroup(ig)
+
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateAWSAdditionalVolumes(t *testing.T) {
+	ig := &kops.InstanceGroup{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test-nodes",
+		},
+		Spec: kops.InstanceGroupSpec{
+			Volumes: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Type: fi.String("gp3"), Size: fi.Int32(10), Iops: fi.Int32(6000)},
+				{Ephemeral: true, Path: "/scratch"},
+			},
+		},
+	}
+	errs := awsValidateInstanceGroup(ig)
+
+	testErrors(t, ig.Spec.Volumes, errs, []string{"Invalid value::spec.volumes[0].iops"})
+}
+
+func TestValidateAWSSecurityGroupOverrides(t *testing.T) {
+	grid := []struct {
+		Masters        *string
+		Nodes          []*string
+		ExpectedErrors []string
+	}{
+		{Nodes: []*string{nil, nil}},
+		{Masters: fi.String("sg-1234abcd"), Nodes: []*string{fi.String("sg-5678abcd"), fi.String("sg-5678abcd")}},
+		{Nodes: []*string{fi.String("sg-5678abcd"), nil}, ExpectedErrors: []string{"Invalid value::nodes-1.spec.securityGroupOverride"}},
+		{Nodes: []*string{fi.String("sg-5678abcd"), fi.String("sg-1234abcd")}, ExpectedErrors: []string{"Invalid value::nodes-1.spec.securityGroupOverride"}},
+	}
+	for _, g := range grid {
+		groups := []*kops.InstanceGroup{
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "master"},
+				Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleMaster, SecurityGroupOverride: g.Masters},
+			},
+		}
+		for i, override := range g.Nodes {
+			groups = append(groups, &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("nodes-%d", i)},
+				Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, SecurityGroupOverride: override},
+			})
+		}
+		errs := awsValidateSecurityGroupOverrides(groups)
+
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+
+	ig := &kops.InstanceGroup{
+		ObjectMeta: v1.ObjectMeta{Name: "nodes"},
+		Spec:       kops.InstanceGroupSpec{SecurityGroupOverride: fi.String("nodes.example.com")},
+	}
+	testErrors(t, ig, awsValidateInstanceGroup(ig), []string{"Invalid value::spec.securityGroupOverride"})
+}
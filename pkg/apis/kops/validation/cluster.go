@@ -17,8 +17,13 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -55,6 +60,41 @@ func ValidateClusterUpdate(obj *kops.Cluster, status *kops.ClusterStatus, old *k
 		}
 	}
 
+	if old.Spec.CloudConfig != nil && old.Spec.CloudConfig.DefaultStorageClass != nil {
+		fp := field.NewPath("Spec", "CloudConfig", "DefaultStorageClass")
+		if obj.Spec.CloudConfig == nil || obj.Spec.CloudConfig.DefaultStorageClass == nil {
+			allErrs = append(allErrs, field.Forbidden(fp, "DefaultStorageClass cannot be removed, as it would leave two default storage classes"))
+		} else {
+			allErrs = append(allErrs, validateDefaultStorageClassUpdate(fp, kops.CloudProviderID(obj.Spec.CloudProvider), obj.Spec.CloudConfig.DefaultStorageClass, old.Spec.CloudConfig.DefaultStorageClass)...)
+		}
+	}
+
+	return allErrs
+}
+
+// validateDefaultStorageClassUpdate checks that only the options of the default storage class which kubernetes can
+// update are changed; the provisioner parameters and reclaim policy of a storage class are immutable
+func validateDefaultStorageClassUpdate(fp *field.Path, cloudProvider kops.CloudProviderID, obj *kops.DefaultStorageClassSpec, old *kops.DefaultStorageClassSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	withDefault := func(s, defaultValue string) string {
+		if s == "" {
+			return defaultValue
+		}
+		return s
+	}
+
+	defaultType := components.DefaultStorageClassType(cloudProvider)
+	if withDefault(obj.Type, defaultType) != withDefault(old.Type, defaultType) {
+		allErrs = append(allErrs, field.Forbidden(fp.Child("Type"), "Type cannot be changed"))
+	}
+	if fi.BoolValue(obj.Encrypted) != fi.BoolValue(old.Encrypted) {
+		allErrs = append(allErrs, field.Forbidden(fp.Child("Encrypted"), "Encrypted cannot be changed"))
+	}
+	if withDefault(obj.ReclaimPolicy, components.DefaultStorageClassReclaimPolicy) != withDefault(old.ReclaimPolicy, components.DefaultStorageClassReclaimPolicy) {
+		allErrs = append(allErrs, field.Forbidden(fp.Child("ReclaimPolicy"), "ReclaimPolicy cannot be changed"))
+	}
+
 	return allErrs
 }
 
@@ -65,6 +105,8 @@ func validateEtcdClusterUpdate(fp *field.Path, obj *kops.EtcdClusterSpec, status
 		allErrs = append(allErrs, field.Forbidden(fp.Child("Name"), "Name cannot be changed"))
 	}
 
+	allErrs = append(allErrs, validateEtcdVersionUpdate(fp.Child("Version"), obj, old)...)
+
 	var etcdClusterStatus *kops.EtcdClusterStatus
 	if status != nil {
 		for i := range status.EtcdClusters {
@@ -108,6 +150,59 @@ func validateEtcdClusterUpdate(fp *field.Path, obj *kops.EtcdClusterSpec, status
 	return allErrs
 }
 
+// validateEtcdVersionUpdate checks that a change to the etcd version is one that etcd-manager can perform safely:
+// upgrades only, one minor version at a time, with etcd2 -> etcd3 being the only supported major version change
+func validateEtcdVersionUpdate(fp *field.Path, obj *kops.EtcdClusterSpec, old *kops.EtcdClusterSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	newVersion := obj.Version
+	if newVersion == "" {
+		newVersion = components.DefaultEtcdVersion
+	}
+	oldVersion := old.Version
+	if oldVersion == "" {
+		oldVersion = components.DefaultEtcdVersion
+	}
+
+	if newVersion == oldVersion {
+		return allErrs
+	}
+
+	if obj.Manager == nil {
+		allErrs = append(allErrs, field.Forbidden(fp, "etcd version can only be changed when using etcd-manager"))
+		return allErrs
+	}
+
+	newSem, err := semver.Parse(strings.TrimPrefix(newVersion, "v"))
+	if err != nil {
+		// We report the invalid version as part of the cluster validation
+		return allErrs
+	}
+	oldSem, err := semver.Parse(strings.TrimPrefix(oldVersion, "v"))
+	if err != nil {
+		// We can't reason about the upgrade path from an unparseable version; allow it to be corrected
+		return allErrs
+	}
+
+	if newSem.LT(oldSem) {
+		allErrs = append(allErrs, field.Forbidden(fp, fmt.Sprintf("etcd version cannot be downgraded from %s to %s", oldVersion, newVersion)))
+		return allErrs
+	}
+
+	if newSem.Major != oldSem.Major {
+		if oldSem.Major != 2 || newSem.Major != 3 {
+			allErrs = append(allErrs, field.Forbidden(fp, fmt.Sprintf("unsupported etcd upgrade from %s to %s", oldVersion, newVersion)))
+		}
+		return allErrs
+	}
+
+	if newSem.Minor > oldSem.Minor+1 {
+		allErrs = append(allErrs, field.Forbidden(fp, fmt.Sprintf("etcd must be upgraded one minor version at a time (from %s, the next version is %d.%d)", oldVersion, oldSem.Major, oldSem.Minor+1)))
+	}
+
+	return allErrs
+}
+
 func validateEtcdMemberUpdate(fp *field.Path, obj *kops.EtcdMemberSpec, status *kops.EtcdClusterStatus, old *kops.EtcdMemberSpec) field.ErrorList {
 	allErrs := field.ErrorList{}
 
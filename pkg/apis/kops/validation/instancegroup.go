@@ -18,11 +18,16 @@ package validation
 
 import (
 	"fmt"
+	"net"
+	"path/filepath"
 	"regexp"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -38,6 +43,8 @@ func ValidateInstanceGroup(g *kops.InstanceGroup) error {
 	case kops.InstanceGroupRoleMaster:
 	case kops.InstanceGroupRoleNode:
 	case kops.InstanceGroupRoleBastion:
+	case kops.InstanceGroupRoleEtcd:
+	case kops.InstanceGroupRoleAPIServer:
 	default:
 		return field.Invalid(field.NewPath("Role"), g.Spec.Role, "Unknown role")
 	}
@@ -91,6 +98,50 @@ func ValidateInstanceGroup(g *kops.InstanceGroup) error {
 		return err
 	}
 
+	if errs := validateInstanceManager(g, field.NewPath("manager")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	if errs := validateLaunchTemplateOptions(g); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	if g.Spec.PlacementGroup != nil {
+		if errs := validatePlacementGroup(g, field.NewPath("placementGroup")); len(errs) > 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if g.Spec.Regional != nil && *g.Spec.Regional {
+		if errs := validateRegional(g, field.NewPath("regional")); len(errs) > 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if len(g.Spec.Volumes) != 0 {
+		if errs := validateVolumes(g, field.NewPath("volumes")); len(errs) > 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if len(g.Spec.ExternalLoadBalancers) != 0 {
+		if errs := validateExternalLoadBalancers(g.Spec.ExternalLoadBalancers, field.NewPath("externalLoadBalancers")); len(errs) > 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if g.Spec.ImageAlias != "" {
+		if errs := validateImageAlias(g.Spec.ImageAlias, field.NewPath("imageAlias")); len(errs) > 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if len(g.Spec.PreloadImages) != 0 {
+		if errs := validatePreloadImages(g.Spec.PreloadImages, field.NewPath("preloadImages")); len(errs) > 0 {
+			return errs.ToAggregate()
+		}
+	}
+
 	return nil
 }
 
@@ -136,6 +187,57 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, st
 		}
 	}
 
+	if g.IsDirectlyManaged() && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "Manager"), "the direct instance manager is only supported on AWS"))
+	}
+
+	if g.IsEtcd() && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "Role"), "dedicated etcd instance groups are only supported on AWS"))
+	}
+
+	if g.IsAPIServer() {
+		allErrs = append(allErrs, validateAPIServerInstanceGroup(g, cluster, fieldPath.Child("Spec", "Role"))...)
+	}
+
+	if g.IsBastion() && cluster.Spec.Topology != nil && cluster.Spec.Topology.SessionManager != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "Role"), "bastions are not used when the cluster is accessed through session manager"))
+	}
+
+	if g.Spec.PlacementGroup != nil && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "PlacementGroup"), "placement groups are only supported on AWS"))
+	}
+
+	if g.Spec.Regional != nil && *g.Spec.Regional && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderGCE {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "Regional"), "regional instance groups are only supported on GCE"))
+	}
+
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderBareMetal {
+		allErrs = append(allErrs, validateBareMetal(g.Spec.BareMetal, field.NewPath("bareMetal"))...)
+	} else if g.Spec.BareMetal != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("bareMetal"), "bare metal hosts are only supported on the baremetal cloud provider"))
+	}
+
+	if g.Spec.SecurityGroupOverride != nil && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "SecurityGroupOverride"), "security group overrides are only supported on AWS"))
+	}
+
+	if len(g.Spec.Volumes) != 0 && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "Volumes"), "additional volumes are only supported on AWS"))
+	}
+
+	if len(g.Spec.ExternalLoadBalancers) != 0 && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("Spec", "ExternalLoadBalancers"), "external load balancers are only supported on AWS"))
+	}
+
+	if cluster.Spec.InstanceMetadata != nil && g.Spec.InstanceMetadata == nil {
+		if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("Cluster", "Spec", "InstanceMetadata"), "instance metadata options are only supported on AWS"))
+		} else if !usesLaunchTemplates(g) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("Cluster", "Spec", "InstanceMetadata"),
+				fmt.Sprintf("instance group %q does not use launch templates, which are required to apply instance metadata options", g.ObjectMeta.Name)))
+		}
+	}
+
 	if len(allErrs) != 0 {
 		return allErrs[0]
 	}
@@ -143,6 +245,353 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, st
 	return nil
 }
 
+// validateAPIServerInstanceGroup checks that an apiserver-only instance group can be registered behind the API load balancer
+func validateAPIServerInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "apiserver instance groups are only supported on AWS"))
+	}
+	if cluster.Spec.API == nil || cluster.Spec.API.LoadBalancer == nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, fmt.Sprintf("instance group %q has role APIServer, which requires an API load balancer", g.ObjectMeta.Name)))
+	}
+
+	return allErrs
+}
+
+// validateBareMetal checks that the hosts of a bare metal instance group can be imaged and enrolled
+func validateBareMetal(spec *kops.BareMetalSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec == nil || len(spec.Hosts) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("hosts"), "instance groups of bare metal clusters must list their hosts"))
+		return allErrs
+	}
+
+	if spec.PXE == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("pxe"), "the PXE configuration of the hosts is required"))
+	} else {
+		if spec.PXE.ConfigBase == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("pxe", "configBase"), ""))
+		}
+		if spec.PXE.Kernel == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("pxe", "kernel"), ""))
+		}
+	}
+
+	names := sets.NewString()
+	for i, host := range spec.Hosts {
+		hostPath := fldPath.Child("hosts").Index(i)
+		if host.Name == "" {
+			allErrs = append(allErrs, field.Required(hostPath.Child("name"), ""))
+		} else if names.Has(host.Name) {
+			allErrs = append(allErrs, field.Duplicate(hostPath.Child("name"), host.Name))
+		}
+		names.Insert(host.Name)
+
+		if host.Address == "" {
+			allErrs = append(allErrs, field.Required(hostPath.Child("address"), "the address kops connects to over SSH is required"))
+		}
+		if _, err := net.ParseMAC(host.MACAddress); err != nil {
+			allErrs = append(allErrs, field.Invalid(hostPath.Child("macAddress"), host.MACAddress, "must be the MAC address of the interface the host boots from"))
+		}
+		if host.IPMI == nil || host.IPMI.Address == "" {
+			allErrs = append(allErrs, field.Required(hostPath.Child("ipmi", "address"), "the address of the management controller is required to power cycle the host"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateEtcdInstanceGroups checks that the members of each etcd cluster are placed either all on masters or all on
+// dedicated etcd instance groups, and that every etcd instance group hosts etcd members
+func validateEtcdInstanceGroups(c *kops.Cluster, groups []*kops.InstanceGroup) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	groupsByName := make(map[string]*kops.InstanceGroup)
+	for _, g := range groups {
+		groupsByName[g.ObjectMeta.Name] = g
+	}
+
+	hostsMembers := make(map[string]bool)
+	for i, etcd := range c.Spec.EtcdClusters {
+		var role kops.InstanceGroupRole
+		for j, m := range etcd.Members {
+			fieldPath := field.NewPath("spec", "etcdClusters").Index(i).Child("etcdMembers").Index(j).Child("instanceGroup")
+
+			g := groupsByName[fi.StringValue(m.InstanceGroup)]
+			if g == nil {
+				continue
+			}
+			hostsMembers[g.ObjectMeta.Name] = true
+
+			if g.Spec.Role != kops.InstanceGroupRoleMaster && g.Spec.Role != kops.InstanceGroupRoleEtcd {
+				allErrs = append(allErrs, field.Forbidden(fieldPath, fmt.Sprintf("etcd members must be placed on a Master or Etcd instance group, %q has role %s", g.ObjectMeta.Name, g.Spec.Role)))
+				continue
+			}
+			if role == "" {
+				role = g.Spec.Role
+			} else if role != g.Spec.Role {
+				allErrs = append(allErrs, field.Forbidden(fieldPath, "all members of an etcd cluster must be placed on instance groups with the same role"))
+			}
+		}
+	}
+
+	for _, g := range groups {
+		if g.IsEtcd() && !hostsMembers[g.ObjectMeta.Name] {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "role"), fmt.Sprintf("instance group %q has role Etcd, but no etcd members are placed on it", g.ObjectMeta.Name)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateInstanceManager checks the manager of the instance group, and the fields that only apply to cloud groups
+func validateInstanceManager(g *kops.InstanceGroup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch g.Spec.Manager {
+	case "", kops.InstanceManagerCloudGroup:
+		return allErrs
+	case kops.InstanceManagerDirect:
+	default:
+		supported := []string{string(kops.InstanceManagerCloudGroup), string(kops.InstanceManagerDirect)}
+		return append(allErrs, field.NotSupported(fldPath, g.Spec.Manager, supported))
+	}
+
+	if g.Spec.Role != kops.InstanceGroupRoleNode {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the direct instance manager is only supported for Node instance groups"))
+	}
+	if g.Spec.MinSize != nil && g.Spec.MaxSize != nil && *g.Spec.MinSize != *g.Spec.MaxSize {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("maxSize"), "directly managed instance groups have a fixed size; maxSize must equal minSize"))
+	}
+	if fi.StringValue(g.Spec.MaxPrice) != "" {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("maxPrice"), "spot instances are not supported with the direct instance manager"))
+	}
+	if len(g.Spec.SuspendProcesses) != 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("suspendProcesses"), "suspendProcesses only applies to autoscaling groups"))
+	}
+	if len(g.Spec.ExternalLoadBalancers) != 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("externalLoadBalancers"), "externalLoadBalancers are not supported with the direct instance manager"))
+	}
+
+	return allErrs
+}
+
+// validateLaunchTemplateOptions checks the instance group options that can only be applied through launch templates
+func validateLaunchTemplateOptions(g *kops.InstanceGroup) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	launchTemplates := g.IsDirectlyManaged() || featureflag.EnableLaunchTemplates.Enabled()
+
+	if g.Spec.CPUCredits != nil {
+		fldPath := field.NewPath("cpuCredits")
+		allErrs = append(allErrs, IsValidValue(fldPath, g.Spec.CPUCredits, []string{"standard", "unlimited"})...)
+		if !launchTemplates {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "cpuCredits requires launch templates (the direct manager, or the EnableLaunchTemplates feature flag)"))
+		}
+		if fi.StringValue(g.Spec.MaxPrice) != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "cpuCredits cannot be used with maxPrice, as spot instance groups use launch configurations"))
+		}
+	}
+
+	if g.Spec.InstanceMetadata != nil {
+		fldPath := field.NewPath("instanceMetadata")
+		allErrs = append(allErrs, validateInstanceMetadataOptions(g.Spec.InstanceMetadata, fldPath)...)
+		if !usesLaunchTemplates(g) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "instanceMetadata requires launch templates (the direct manager, or the EnableLaunchTemplates feature flag without maxPrice)"))
+		}
+	}
+
+	if g.Spec.RootVolumeThroughput != nil && !usesLaunchTemplates(g) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("rootVolumeThroughput"), "rootVolumeThroughput requires launch templates (the direct manager, or the EnableLaunchTemplates feature flag without maxPrice)"))
+	}
+
+	if g.Spec.MixedInstancesPolicy != nil {
+		fldPath := field.NewPath("mixedInstancesPolicy")
+		if !g.IsDirectlyManaged() {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "mixedInstancesPolicy is only supported with the direct instance manager"))
+		}
+		if len(g.Spec.MixedInstancesPolicy.Instances) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("instances"), "at least one instance type must be listed"))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePlacementGroup checks the placement group of the instance group
+func validatePlacementGroup(g *kops.InstanceGroup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	pg := g.Spec.PlacementGroup
+
+	switch pg.Strategy {
+	case "":
+		allErrs = append(allErrs, field.Required(fldPath.Child("strategy"), "strategy must be set"))
+	case "cluster":
+		// A cluster placement group cannot span availability zones
+		if len(g.Spec.Subnets) > 1 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("strategy"), "the cluster strategy requires the instance group to be in a single subnet"))
+		}
+	case "spread":
+		// A spread placement group holds at most 7 running instances per availability zone
+		if g.Spec.MaxSize != nil && len(g.Spec.Subnets) != 0 && int(*g.Spec.MaxSize) > 7*len(g.Spec.Subnets) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("maxSize"), *g.Spec.MaxSize, "the spread strategy supports at most 7 instances per zone"))
+		}
+	case "partition":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("strategy"), pg.Strategy, []string{"cluster", "spread", "partition"}))
+	}
+
+	if pg.PartitionCount != nil {
+		if pg.Strategy != "partition" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("partitionCount"), "partitionCount can only be set with the partition strategy"))
+		} else if *pg.PartitionCount < 1 || *pg.PartitionCount > 7 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("partitionCount"), *pg.PartitionCount, "must be between 1 and 7"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRegional checks that a regional instance group can be built as a regional managed instance group
+func validateRegional(g *kops.InstanceGroup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// Masters are pinned to a zone each, for etcd; bastions are a single instance
+	if g.Spec.Role != kops.InstanceGroupRoleNode {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only node instance groups can be regional"))
+	}
+
+	return allErrs
+}
+
+// validateVolumes checks the additional volumes of the instance group
+func validateVolumes(g *kops.InstanceGroup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	devices := sets.NewString()
+	paths := sets.NewString()
+	ephemeral := 0
+
+	for i, v := range g.Spec.Volumes {
+		volumePath := fldPath.Index(i)
+
+		if v.Ephemeral {
+			ephemeral++
+			if ephemeral > 1 {
+				allErrs = append(allErrs, field.Forbidden(volumePath.Child("ephemeral"), "only one volume can use the instance store devices"))
+			}
+			if v.Device != "" {
+				allErrs = append(allErrs, field.Forbidden(volumePath.Child("device"), "the devices of ephemeral volumes are discovered on the instance"))
+			}
+			if v.Size != nil || v.Type != nil || v.Iops != nil || v.Throughput != nil || v.Encrypted != nil || v.DeleteOnTermination != nil {
+				allErrs = append(allErrs, field.Forbidden(volumePath, "size, type, iops, throughput, encrypted and deleteOnTermination only apply to EBS volumes"))
+			}
+			if v.Path == "" {
+				allErrs = append(allErrs, field.Required(volumePath.Child("path"), "ephemeral volumes must be mounted"))
+			}
+		} else {
+			if v.Device == "" {
+				allErrs = append(allErrs, field.Required(volumePath.Child("device"), "the device name of the EBS volume must be set"))
+			} else if !strings.HasPrefix(v.Device, "/dev/") {
+				allErrs = append(allErrs, field.Invalid(volumePath.Child("device"), v.Device, "must be a device name, e.g. /dev/xvdd"))
+			} else if devices.Has(v.Device) {
+				allErrs = append(allErrs, field.Duplicate(volumePath.Child("device"), v.Device))
+			}
+			devices.Insert(v.Device)
+
+			if v.Raid0 {
+				allErrs = append(allErrs, field.Forbidden(volumePath.Child("raid0"), "raid0 only applies to ephemeral volumes"))
+			}
+			if v.Throughput != nil && !usesLaunchTemplates(g) {
+				allErrs = append(allErrs, field.Forbidden(volumePath.Child("throughput"), "throughput requires launch templates (the direct manager, or the EnableLaunchTemplates feature flag without maxPrice)"))
+			}
+		}
+
+		if v.Path == "" {
+			if v.Filesystem != "" || len(v.MountOptions) != 0 {
+				allErrs = append(allErrs, field.Forbidden(volumePath, "filesystem and mountOptions only apply to volumes with a path"))
+			}
+			continue
+		}
+		if !filepath.IsAbs(v.Path) {
+			allErrs = append(allErrs, field.Invalid(volumePath.Child("path"), v.Path, "must be an absolute path"))
+		} else if paths.Has(v.Path) {
+			allErrs = append(allErrs, field.Duplicate(volumePath.Child("path"), v.Path))
+		}
+		paths.Insert(v.Path)
+
+		if v.Filesystem != "" {
+			allErrs = append(allErrs, IsValidValue(volumePath.Child("filesystem"), &v.Filesystem, []string{"ext4", "xfs"})...)
+		}
+	}
+
+	return allErrs
+}
+
+// validateInstanceMetadataOptions checks the instance metadata service options of an instance group or cluster
+// validateExternalLoadBalancers checks that each external load balancer is either a classic ELB or a target group, and is listed only once
+func validateExternalLoadBalancers(lbs []kops.LoadBalancer, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	seen := make(map[string]bool)
+	for i, lb := range lbs {
+		fp := fldPath.Index(i)
+
+		name := fi.StringValue(lb.LoadBalancerName)
+		arn := fi.StringValue(lb.TargetGroupARN)
+		if name == "" && arn == "" {
+			allErrs = append(allErrs, field.Required(fp, "one of loadBalancerName or targetGroupArn must be set"))
+			continue
+		}
+		if name != "" && arn != "" {
+			allErrs = append(allErrs, field.Forbidden(fp, "only one of loadBalancerName or targetGroupArn can be set"))
+			continue
+		}
+
+		if arn != "" {
+			if !strings.HasPrefix(arn, "arn:") || !strings.Contains(arn, ":targetgroup/") {
+				allErrs = append(allErrs, field.Invalid(fp.Child("targetGroupArn"), arn, "must be the ARN of a target group"))
+			}
+		}
+
+		key := name + arn
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(fp, key))
+		}
+		seen[key] = true
+	}
+
+	return allErrs
+}
+
+func validateInstanceMetadataOptions(opts *kops.InstanceMetadataOptions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if opts.HTTPTokens != nil {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("httpTokens"), opts.HTTPTokens, []string{"optional", "required"})...)
+	}
+
+	if opts.HTTPPutResponseHopLimit != nil {
+		hopLimit := *opts.HTTPPutResponseHopLimit
+		if hopLimit < 1 || hopLimit > 64 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("httpPutResponseHopLimit"), hopLimit, "must be between 1 and 64"))
+		}
+	}
+
+	return allErrs
+}
+
+// usesLaunchTemplates returns true if the instances of the group are launched from a launch template
+func usesLaunchTemplates(g *kops.InstanceGroup) bool {
+	if g.IsDirectlyManaged() {
+		return true
+	}
+	return featureflag.EnableLaunchTemplates.Enabled() && fi.StringValue(g.Spec.MaxPrice) == ""
+}
+
 func validateExtraUserData(userData *kops.UserData) error {
 	fieldPath := field.NewPath("AdditionalUserData")
 
@@ -171,8 +620,8 @@ func validateExtraUserData(userData *kops.UserData) error {
 	return nil
 }
 
-// format is arn:aws:iam::123456789012:instance-profile/S3Access
-var validARN = regexp.MustCompile(`^arn:aws:iam::\d+:instance-profile\/\S+$`)
+// format is arn:aws:iam::123456789012:instance-profile/S3Access, or arn:aws-cn:iam::... in another partition
+var validARN = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d+:instance-profile\/\S+$`)
 
 // validateInstanceProfile checks the String values for the AuthProfile
 func validateInstanceProfile(v *kops.IAMProfileSpec, fldPath *field.Path) *field.Error {
@@ -185,3 +634,40 @@ func validateInstanceProfile(v *kops.IAMProfileSpec, fldPath *field.Path) *field
 	}
 	return nil
 }
+
+// validateImageAlias checks that an image alias is a name pattern that can be resolved to an image
+func validateImageAlias(alias string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if strings.HasPrefix(alias, "ami-") {
+		allErrs = append(allErrs, field.Invalid(fldPath, alias, "imageAlias must be an image name pattern, not an image ID"))
+	}
+	if strings.HasSuffix(alias, "/") {
+		allErrs = append(allErrs, field.Invalid(fldPath, alias, "imageAlias must include an image name pattern after the owner"))
+	}
+
+	return allErrs
+}
+
+// validatePreloadImages checks that each image to preload is a single, non-empty image reference
+func validatePreloadImages(images []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	seen := make(map[string]bool)
+	for i, image := range images {
+		if image == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i), "image to preload must be set"))
+			continue
+		}
+		if strings.ContainsAny(image, " \t\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), image, "image to preload must not contain whitespace"))
+			continue
+		}
+		if seen[image] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), image))
+		}
+		seen[image] = true
+	}
+
+	return allErrs
+}
@@ -127,3 +127,566 @@ func TestValidateInstanceProfile(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateInstanceManager(t *testing.T) {
+	grid := []struct {
+		Input          kops.InstanceGroupSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleMaster},
+		},
+		{
+			Input: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Manager: kops.InstanceManagerDirect},
+		},
+		{
+			Input:          kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Manager: "spotfleet"},
+			ExpectedErrors: []string{"Unsupported value::manager"},
+		},
+		{
+			Input:          kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleMaster, Manager: kops.InstanceManagerDirect},
+			ExpectedErrors: []string{"Forbidden::manager"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:     kops.InstanceGroupRoleNode,
+				Manager:  kops.InstanceManagerDirect,
+				MinSize:  fi.Int32(2),
+				MaxSize:  fi.Int32(4),
+				MaxPrice: s("0.1"),
+			},
+			ExpectedErrors: []string{"Forbidden::maxSize", "Forbidden::maxPrice"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:                  kops.InstanceGroupRoleNode,
+				Manager:               kops.InstanceManagerDirect,
+				SuspendProcesses:      []string{"AZRebalance"},
+				ExternalLoadBalancers: []kops.LoadBalancer{{LoadBalancerName: s("lb")}},
+			},
+			ExpectedErrors: []string{"Forbidden::suspendProcesses", "Forbidden::externalLoadBalancers"},
+		},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       g.Input,
+		}
+		errs := validateInstanceManager(ig, field.NewPath("manager"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateLaunchTemplateOptions(t *testing.T) {
+	grid := []struct {
+		Input          kops.InstanceGroupSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Manager: kops.InstanceManagerDirect, CPUCredits: s("unlimited")},
+		},
+		{
+			Input:          kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Manager: kops.InstanceManagerDirect, CPUCredits: s("boosted")},
+			ExpectedErrors: []string{"Unsupported value::cpuCredits"},
+		},
+		{
+			Input:          kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, CPUCredits: s("standard")},
+			ExpectedErrors: []string{"Forbidden::cpuCredits"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:                 kops.InstanceGroupRoleNode,
+				Manager:              kops.InstanceManagerDirect,
+				MixedInstancesPolicy: &kops.MixedInstancesPolicySpec{Instances: []string{"m5.large", "m4.large"}},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:                 kops.InstanceGroupRoleNode,
+				MixedInstancesPolicy: &kops.MixedInstancesPolicySpec{},
+			},
+			ExpectedErrors: []string{"Forbidden::mixedInstancesPolicy", "Required value::mixedInstancesPolicy.instances"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:             kops.InstanceGroupRoleNode,
+				Manager:          kops.InstanceManagerDirect,
+				InstanceMetadata: &kops.InstanceMetadataOptions{HTTPTokens: s("required"), HTTPPutResponseHopLimit: fi.Int64(1)},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:             kops.InstanceGroupRoleNode,
+				Manager:          kops.InstanceManagerDirect,
+				InstanceMetadata: &kops.InstanceMetadataOptions{HTTPTokens: s("always"), HTTPPutResponseHopLimit: fi.Int64(65)},
+			},
+			ExpectedErrors: []string{"Unsupported value::instanceMetadata.httpTokens", "Invalid value::instanceMetadata.httpPutResponseHopLimit"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:             kops.InstanceGroupRoleNode,
+				InstanceMetadata: &kops.InstanceMetadataOptions{HTTPTokens: s("required")},
+			},
+			ExpectedErrors: []string{"Forbidden::instanceMetadata"},
+		},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       g.Input,
+		}
+		errs := validateLaunchTemplateOptions(ig)
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidatePlacementGroup(t *testing.T) {
+	grid := []struct {
+		Input          kops.InstanceGroupSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:        []string{"us-test-1a"},
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "cluster"},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:        []string{"us-test-1a", "us-test-1b"},
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "cluster"},
+			},
+			ExpectedErrors: []string{"Forbidden::placementGroup.strategy"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:        []string{"us-test-1a", "us-test-1b"},
+				MaxSize:        fi.Int32(15),
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "spread"},
+			},
+			ExpectedErrors: []string{"Invalid value::maxSize"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "partition", PartitionCount: fi.Int32(3)},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "partition", PartitionCount: fi.Int32(8)},
+			},
+			ExpectedErrors: []string{"Invalid value::placementGroup.partitionCount"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "spread", PartitionCount: fi.Int32(2)},
+			},
+			ExpectedErrors: []string{"Forbidden::placementGroup.partitionCount"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				PlacementGroup: &kops.PlacementGroupSpec{},
+			},
+			ExpectedErrors: []string{"Required value::placementGroup.strategy"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "random"},
+			},
+			ExpectedErrors: []string{"Unsupported value::placementGroup.strategy"},
+		},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       g.Input,
+		}
+		errs := validatePlacementGroup(ig, field.NewPath("placementGroup"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateRegional(t *testing.T) {
+	grid := []struct {
+		Input          kops.InstanceGroupSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:     kops.InstanceGroupRoleNode,
+				Regional: fi.Bool(true),
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:     kops.InstanceGroupRoleMaster,
+				Regional: fi.Bool(true),
+			},
+			ExpectedErrors: []string{"Forbidden::regional"},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Role:     kops.InstanceGroupRoleBastion,
+				Regional: fi.Bool(true),
+			},
+			ExpectedErrors: []string{"Forbidden::regional"},
+		},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       g.Input,
+		}
+		errs := validateRegional(ig, field.NewPath("regional"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateVolumes(t *testing.T) {
+	grid := []struct {
+		Input          []*kops.VolumeSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Size: fi.Int32(100), Path: "/data", Filesystem: "xfs"},
+				{Ephemeral: true, Raid0: true, Path: "/scratch"},
+			},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd"},
+			},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Path: "/data"},
+			},
+			ExpectedErrors: []string{"Required value::volumes[0].device"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "xvdd"},
+			},
+			ExpectedErrors: []string{"Invalid value::volumes[0].device"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Path: "/data"},
+				{Device: "/dev/xvdd", Path: "/data"},
+			},
+			ExpectedErrors: []string{"Duplicate value::volumes[1].device", "Duplicate value::volumes[1].path"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Raid0: true},
+			},
+			ExpectedErrors: []string{"Forbidden::volumes[0].raid0"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Throughput: fi.Int32(250)},
+			},
+			ExpectedErrors: []string{"Forbidden::volumes[0].throughput"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Ephemeral: true},
+			},
+			ExpectedErrors: []string{"Required value::volumes[0].path"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Ephemeral: true, Size: fi.Int32(100), Path: "/scratch"},
+			},
+			ExpectedErrors: []string{"Forbidden::volumes[0]"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Ephemeral: true, Path: "/scratch"},
+				{Ephemeral: true, Path: "/scratch2"},
+			},
+			ExpectedErrors: []string{"Forbidden::volumes[1].ephemeral"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Filesystem: "ext4"},
+			},
+			ExpectedErrors: []string{"Forbidden::volumes[0]"},
+		},
+		{
+			Input: []*kops.VolumeSpec{
+				{Device: "/dev/xvdd", Path: "data", Filesystem: "btrfs"},
+			},
+			ExpectedErrors: []string{"Invalid value::volumes[0].path", "Unsupported value::volumes[0].filesystem"},
+		},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       kops.InstanceGroupSpec{Volumes: g.Input},
+		}
+		errs := validateVolumes(ig, field.NewPath("volumes"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateEtcdInstanceGroups(t *testing.T) {
+	groups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-a"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleMaster},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "etcd-a"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleEtcd},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "etcd-b"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleEtcd},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode},
+		},
+	}
+
+	grid := []struct {
+		Members        []string
+		ExpectedErrors []string
+	}{
+		{
+			Members: []string{"etcd-a", "etcd-b"},
+		},
+		{
+			Members:        []string{"master-a"},
+			ExpectedErrors: []string{"Forbidden::spec.role"},
+		},
+		{
+			Members:        []string{"etcd-a", "master-a"},
+			ExpectedErrors: []string{"Forbidden::spec.etcdClusters[0].etcdMembers[1].instanceGroup", "Forbidden::spec.role"},
+		},
+		{
+			Members:        []string{"etcd-a", "etcd-b", "nodes"},
+			ExpectedErrors: []string{"Forbidden::spec.etcdClusters[0].etcdMembers[2].instanceGroup"},
+		},
+	}
+
+	for _, g := range grid {
+		etcd := &kops.EtcdClusterSpec{Name: "main"}
+		for _, name := range g.Members {
+			etcd.Members = append(etcd.Members, &kops.EtcdMemberSpec{Name: name, InstanceGroup: fi.String(name)})
+		}
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				EtcdClusters: []*kops.EtcdClusterSpec{etcd},
+			},
+		}
+		errs := validateEtcdInstanceGroups(cluster, groups)
+		testErrors(t, g.Members, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateAPIServerInstanceGroup(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				API:           &kops.AccessSpec{LoadBalancer: &kops.LoadBalancerAccessSpec{Type: kops.LoadBalancerTypeInternal}},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				API:           &kops.AccessSpec{DNS: &kops.DNSAccessSpec{}},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.role"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "gce",
+				API:           &kops.AccessSpec{LoadBalancer: &kops.LoadBalancerAccessSpec{Type: kops.LoadBalancerTypePublic}},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.role"},
+		},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "apiservers"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleAPIServer},
+		}
+		cluster := &kops.Cluster{Spec: g.Input}
+		errs := validateAPIServerInstanceGroup(ig, cluster, field.NewPath("spec", "role"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateExternalLoadBalancers(t *testing.T) {
+	tgARN := "arn:aws:elasticloadbalancing:eu-west-1:123456789012:targetgroup/my-ingress-target-group/0123456789abcdef"
+
+	grid := []struct {
+		Input          []kops.LoadBalancer
+		ExpectedErrors []string
+	}{
+		{
+			Input: []kops.LoadBalancer{
+				{LoadBalancerName: fi.String("my-elb")},
+				{TargetGroupARN: fi.String(tgARN)},
+			},
+		},
+		{
+			Input: []kops.LoadBalancer{
+				{},
+			},
+			ExpectedErrors: []string{"Required value::externalLoadBalancers[0]"},
+		},
+		{
+			Input: []kops.LoadBalancer{
+				{LoadBalancerName: fi.String("my-elb"), TargetGroupARN: fi.String(tgARN)},
+			},
+			ExpectedErrors: []string{"Forbidden::externalLoadBalancers[0]"},
+		},
+		{
+			Input: []kops.LoadBalancer{
+				{TargetGroupARN: fi.String("my-ingress-target-group")},
+			},
+			ExpectedErrors: []string{"Invalid value::externalLoadBalancers[0].targetGroupArn"},
+		},
+		{
+			Input: []kops.LoadBalancer{
+				{LoadBalancerName: fi.String("my-elb")},
+				{LoadBalancerName: fi.String("my-elb")},
+			},
+			ExpectedErrors: []string{"Duplicate value::externalLoadBalancers[1]"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateExternalLoadBalancers(g.Input, field.NewPath("externalLoadBalancers"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateImageAlias(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Input: "099720109477/ubuntu/images/hvm-ssd/ubuntu-xenial-16.04-amd64-server-*",
+		},
+		{
+			Input: "kope.io/k8s-1.9-debian-jessie-amd64-hvm-ebs-*",
+		},
+		{
+			Input:          "ami-12345678",
+			ExpectedErrors: []string{"Invalid value::imageAlias"},
+		},
+		{
+			Input:          "kope.io/",
+			ExpectedErrors: []string{"Invalid value::imageAlias"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateImageAlias(g.Input, field.NewPath("imageAlias"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidatePreloadImages(t *testing.T) {
+	grid := []struct {
+		Input          []string
+		ExpectedErrors []string
+	}{
+		{
+			Input: []string{"k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/kube-proxy:v1.11.0"},
+		},
+		{
+			Input:          []string{""},
+			ExpectedErrors: []string{"Required value::preloadImages[0]"},
+		},
+		{
+			Input:          []string{"k8s.gcr.io/pause-amd64:3.0 k8s.gcr.io/kube-proxy:v1.11.0"},
+			ExpectedErrors: []string{"Invalid value::preloadImages[0]"},
+		},
+		{
+			Input:          []string{"k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause-amd64:3.0"},
+			ExpectedErrors: []string{"Duplicate value::preloadImages[1]"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validatePreloadImages(g.Input, field.NewPath("preloadImages"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestValidateBareMetal(t *testing.T) {
+	pxe := &kops.PXESpec{ConfigBase: "file:///var/lib/tftpboot/pxelinux.cfg", Kernel: "ubuntu/linux"}
+	host := func(name string, mac string) kops.BareMetalHostSpec {
+		return kops.BareMetalHostSpec{
+			Name:       name,
+			Address:    "10.0.0.10",
+			MACAddress: mac,
+			IPMI:       &kops.IPMISpec{Address: "10.1.0.10"},
+		}
+	}
+
+	grid := []struct {
+		Input          *kops.BareMetalSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: &kops.BareMetalSpec{
+				Hosts: []kops.BareMetalHostSpec{host("node-1", "52:54:00:12:34:56"), host("node-2", "52:54:00:12:34:57")},
+				PXE:   pxe,
+			},
+		},
+		{
+			Input:          nil,
+			ExpectedErrors: []string{"Required value::bareMetal.hosts"},
+		},
+		{
+			Input: &kops.BareMetalSpec{
+				Hosts: []kops.BareMetalHostSpec{host("node-1", "52:54:00:12:34:56")},
+			},
+			ExpectedErrors: []string{"Required value::bareMetal.pxe"},
+		},
+		{
+			Input: &kops.BareMetalSpec{
+				Hosts: []kops.BareMetalHostSpec{host("node-1", "52:54:00:12:34:56"), host("node-1", "52:54:00:12:34:57")},
+				PXE:   pxe,
+			},
+			ExpectedErrors: []string{"Duplicate value::bareMetal.hosts[1].name"},
+		},
+		{
+			Input: &kops.BareMetalSpec{
+				Hosts: []kops.BareMetalHostSpec{host("node-1", "52:54:00")},
+				PXE:   pxe,
+			},
+			ExpectedErrors: []string{"Invalid value::bareMetal.hosts[0].macAddress"},
+		},
+		{
+			Input: &kops.BareMetalSpec{
+				Hosts: []kops.BareMetalHostSpec{{Name: "node-1", Address: "10.0.0.10", MACAddress: "52:54:00:12:34:56"}},
+				PXE:   pxe,
+			},
+			ExpectedErrors: []string{"Required value::bareMetal.hosts[0].ipmi.address"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateBareMetal(g.Input, field.NewPath("bareMetal"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
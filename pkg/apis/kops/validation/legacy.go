@@ -21,6 +21,7 @@ import (
 	"net"
 	"strings"
 
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 
 	"github.com/blang/semver"
 )
@@ -72,6 +74,11 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 		}
 	}
 
+	// Labels are used to select clusters, e.g. kops get clusters -l team=payments
+	if errs := metavalidation.ValidateLabels(c.ObjectMeta.Labels, field.NewPath("Labels")); len(errs) != 0 {
+		return errs[0]
+	}
+
 	if c.Spec.Assets != nil && c.Spec.Assets.ContainerProxy != nil && c.Spec.Assets.ContainerRegistry != nil {
 		return field.Forbidden(fieldSpec.Child("Assets", "ContainerProxy"), "ContainerProxy cannot be used in conjunction with ContainerRegistry as represent mutually exclusive concepts. Please consult the documentation for details.")
 	}
@@ -107,13 +114,23 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 			return field.Invalid(fieldSpec.Child("NetworkCIDR"), c.Spec.NetworkCIDR, "NetworkCIDR should not be set on DigitalOcean")
 		}
 	case kops.CloudProviderAWS:
+	case kops.CloudProviderHetzner:
+	case kops.CloudProviderScaleway:
 	case kops.CloudProviderVSphere:
 	case kops.CloudProviderOpenstack:
 		requiresNetworkCIDR = false
 		requiresSubnetCIDR = false
 
 	default:
-		return field.Invalid(fieldSpec.Child("CloudProvider"), c.Spec.CloudProvider, "CloudProvider not recognized")
+		provider := cloudplugin.Get(kops.CloudProviderID(c.Spec.CloudProvider))
+		if provider == nil {
+			return field.Invalid(fieldSpec.Child("CloudProvider"), c.Spec.CloudProvider, "CloudProvider not recognized")
+		}
+		if errs := provider.ValidateCluster(c); len(errs) != 0 {
+			return errs[0]
+		}
+		requiresNetworkCIDR = false
+		requiresSubnetCIDR = false
 	}
 
 	if requiresSubnets && len(c.Spec.Subnets) == 0 {
@@ -130,8 +147,16 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 	if strict && c.Spec.KubeControllerManager == nil {
 		return field.Required(fieldSpec.Child("KubeControllerManager"), "KubeControllerManager not configured")
 	}
-	if kubernetesRelease.LT(semver.MustParse("1.7.0")) && c.Spec.ExternalCloudControllerManager != nil {
-		return field.Invalid(fieldSpec.Child("ExternalCloudControllerManager"), c.Spec.ExternalCloudControllerManager, "ExternalCloudControllerManager is not supported in version 1.6.0 or lower")
+	if kubernetesRelease.LT(semver.MustParse("1.9.0")) && c.Spec.ExternalCloudControllerManager != nil {
+		return field.Invalid(fieldSpec.Child("ExternalCloudControllerManager"), c.Spec.ExternalCloudControllerManager, "ExternalCloudControllerManager is not supported in version 1.8 or lower")
+	}
+	if kubernetesRelease.LT(semver.MustParse("1.14.0")) && c.Spec.CloudConfig != nil {
+		if c.Spec.CloudConfig.AWSEBSCSIDriver.IsEnabled() {
+			return field.Invalid(fieldSpec.Child("CloudConfig", "AWSEBSCSIDriver"), c.Spec.CloudConfig.AWSEBSCSIDriver, "the AWS EBS CSI driver is not supported in version 1.13 or lower")
+		}
+		if c.Spec.CloudConfig.GCEPDCSIDriver.IsEnabled() {
+			return field.Invalid(fieldSpec.Child("CloudConfig", "GCEPDCSIDriver"), c.Spec.CloudConfig.GCEPDCSIDriver, "the GCE PD CSI driver is not supported in version 1.13 or lower")
+		}
 	}
 	if strict && c.Spec.KubeDNS == nil {
 		return field.Required(fieldSpec.Child("KubeDNS"), "KubeDNS not configured")
@@ -330,10 +355,17 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 			k8sCloudProvider = "vsphere"
 		case kops.CloudProviderBareMetal:
 			k8sCloudProvider = ""
+		case kops.CloudProviderHetzner:
+			k8sCloudProvider = ""
+		case kops.CloudProviderScaleway:
+			k8sCloudProvider = ""
 		case kops.CloudProviderOpenstack:
 			k8sCloudProvider = "openstack"
 		default:
-			return field.Invalid(fieldSpec.Child("CloudProvider"), c.Spec.CloudProvider, "unknown cloudprovider")
+			if cloudplugin.Get(kops.CloudProviderID(c.Spec.CloudProvider)) == nil {
+				return field.Invalid(fieldSpec.Child("CloudProvider"), c.Spec.CloudProvider, "unknown cloudprovider")
+			}
+			k8sCloudProvider = "external"
 		}
 
 		if c.Spec.Kubelet != nil && (strict || c.Spec.Kubelet.CloudProvider != "") {
@@ -416,6 +448,31 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 		}
 	}
 
+	// PodSecurity
+	if c.Spec.PodSecurity != nil {
+		if kubernetesRelease.LT(semver.MustParse("1.9.0")) {
+			return field.Invalid(fieldSpec.Child("podSecurity"), c.Spec.PodSecurity, "pod security presets require kubernetes 1.9 or later")
+		}
+		if strict && !c.Spec.KubeAPIServer.HasAdmissionController("PodSecurityPolicy") {
+			return field.Invalid(fieldSpec.Child("kubeAPIServer", "enableAdmissionPlugins"), c.Spec.KubeAPIServer.EnableAdmissionPlugins, "the PodSecurityPolicy admission controller must be enabled to enforce podSecurity")
+		}
+	}
+
+	// NetworkPolicy
+	if c.Spec.NetworkPolicy != nil && c.Spec.NetworkPolicy.DefaultDeny && kubernetesRelease.LT(semver.MustParse("1.8.0")) {
+		return field.Invalid(fieldSpec.Child("networkPolicy", "defaultDeny"), c.Spec.NetworkPolicy.DefaultDeny, "default-deny network policies require kubernetes 1.8 or later")
+	}
+
+	// NodeLocalDNS
+	if c.Spec.KubeDNS.NodeLocalDNSEnabled() && kubernetesRelease.LT(semver.MustParse("1.10.0")) {
+		return field.Invalid(fieldSpec.Child("kubeDNS", "nodeLocalDNS", "enabled"), true, "node-local-dns requires kubernetes 1.10 or later")
+	}
+
+	// KopsController
+	if c.Spec.KopsControllerEnabled() && kubernetesRelease.LT(semver.MustParse("1.10.0")) {
+		return field.Invalid(fieldSpec.Child("kopsController", "enabled"), true, "the kops-controller requires kubernetes 1.10 or later")
+	}
+
 	// UpdatePolicy
 	if c.Spec.UpdatePolicy != nil {
 		switch *c.Spec.UpdatePolicy {
@@ -439,6 +496,14 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 		if master != "" && !isValidAPIServersURL(master) {
 			return field.Invalid(kubeProxyPath.Child("Master"), master, "Not a valid APIServer URL")
 		}
+
+		if c.Spec.KubeProxy.ProxyMode == "ipvs" && kubernetesRelease.LT(semver.MustParse("1.9.0")) {
+			return field.Invalid(kubeProxyPath.Child("ProxyMode"), c.Spec.KubeProxy.ProxyMode, "ipvs proxy mode requires kubernetes 1.9 or later")
+		}
+
+		if len(c.Spec.KubeProxy.IPVSExcludeCIDRs) > 0 && kubernetesRelease.LT(semver.MustParse("1.11.0")) {
+			return field.Invalid(kubeProxyPath.Child("IPVSExcludeCIDRs"), strings.Join(c.Spec.KubeProxy.IPVSExcludeCIDRs, ","), "ipvsExcludeCIDRs requires kubernetes 1.11 or later")
+		}
 	}
 
 	// KubeAPIServer
@@ -556,8 +621,8 @@ func ValidateCluster(c *kops.Cluster, strict bool) *field.Error {
 	{
 		for i, s := range c.Spec.Subnets {
 			fieldSubnet := fieldSpec.Child("Subnets").Index(i)
-			if s.Egress != "" && !strings.HasPrefix(s.Egress, "nat-") && !strings.HasPrefix(s.Egress, "i-") {
-				return field.Invalid(fieldSubnet.Child("Egress"), s.Egress, "egress must be of type NAT Gateway or NAT EC2 Instance")
+			if !isValidEgress(s.Egress) {
+				return field.Invalid(fieldSubnet.Child("Egress"), s.Egress, "egress must be NatGateway, SharedNatGateway, NatInstance or the ID of an existing NAT Gateway or NAT EC2 Instance")
 			}
 			if s.Egress != "" && !(s.Type == "Private") {
 				return field.Invalid(fieldSubnet.Child("Egress"), s.Egress, "egress can only be specified for Private subnets")
@@ -763,7 +828,7 @@ func DeepValidate(c *kops.Cluster, groups []*kops.InstanceGroup, strict bool) er
 	for _, g := range groups {
 		if g.IsMaster() {
 			masterGroupCount++
-		} else {
+		} else if !g.IsEtcd() && !g.IsAPIServer() {
 			nodeGroupCount++
 		}
 	}
@@ -793,5 +858,25 @@ func DeepValidate(c *kops.Cluster, groups []*kops.InstanceGroup, strict bool) er
 		}
 	}
 
+	if errs := validateEtcdInstanceGroups(c, groups); len(errs) != 0 {
+		return errs[0]
+	}
+
+	if kops.CloudProviderID(c.Spec.CloudProvider) == kops.CloudProviderAWS {
+		if errs := awsValidateSecurityGroupOverrides(groups); len(errs) != 0 {
+			return errs[0]
+		}
+	}
+
 	return nil
 }
+
+// isValidEgress returns true if egress is one of the egress strategies kops implements,
+// or references an existing NAT Gateway or NAT EC2 Instance
+func isValidEgress(egress string) bool {
+	switch egress {
+	case "", kops.EgressNatGateway, kops.EgressSharedNatGateway, kops.EgressNatInstance:
+		return true
+	}
+	return strings.HasPrefix(egress, "nat-") || strings.HasPrefix(egress, "i-")
+}
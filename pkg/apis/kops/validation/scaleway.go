@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+func scalewayValidateCluster(c *kops.Cluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	fieldSpec := field.NewPath("spec")
+
+	// kops can't manage Scaleway DNS zones, so there is nowhere to publish the API records to
+	if !dns.IsGossipHostname(c.ObjectMeta.Name) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), c.ObjectMeta.Name, "Scaleway clusters must use gossip DNS, with a name ending in .k8s.local"))
+	}
+
+	// Without a cloud provider nothing configures routes for kubenet, so pods can only reach each other over a CNI overlay
+	if c.Spec.Networking != nil && c.Spec.Networking.Kubenet != nil {
+		allErrs = append(allErrs, field.Invalid(fieldSpec.Child("networking"), "kubenet", "kubenet is not supported on Scaleway, use a CNI network such as weave, calico or flannel"))
+	}
+
+	zones := sets.NewString(scaleway.Zones...)
+	for i, subnet := range c.Spec.Subnets {
+		f := fieldSpec.Child("subnets").Index(i)
+		if !zones.Has(subnet.Zone) {
+			allErrs = append(allErrs, field.NotSupported(f.Child("zone"), subnet.Zone, scaleway.Zones))
+		}
+		// Private networks are zonal, so the servers of the cluster must all be in one zone
+		if i != 0 && subnet.Zone != c.Spec.Subnets[0].Zone {
+			allErrs = append(allErrs, field.Invalid(f.Child("zone"), subnet.Zone, "all subnets of a Scaleway cluster must be in the same zone"))
+		}
+		if subnet.Type != kops.SubnetTypePublic {
+			allErrs = append(allErrs, field.Invalid(f.Child("type"), subnet.Type, "only public subnets are supported on Scaleway"))
+		}
+	}
+
+	return allErrs
+}
@@ -19,13 +19,20 @@ package validation
 import (
 	"fmt"
 	"net"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/model/iam"
 )
 
@@ -47,6 +54,14 @@ func newValidateCluster(cluster *kops.Cluster) field.ErrorList {
 		allErrs = append(allErrs, awsValidateCluster(cluster)...)
 	case kops.CloudProviderGCE:
 		allErrs = append(allErrs, gceValidateCluster(cluster)...)
+	case kops.CloudProviderHetzner:
+		allErrs = append(allErrs, hetznerValidateCluster(cluster)...)
+	case kops.CloudProviderScaleway:
+		allErrs = append(allErrs, scalewayValidateCluster(cluster)...)
+	}
+
+	if cluster.Spec.DNSZoneOptions != nil && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "dnsZoneOptions"), "DNS zone options are only supported on AWS"))
 	}
 
 	return allErrs
@@ -62,6 +77,20 @@ func validateClusterSpec(spec *kops.ClusterSpec, fieldPath *field.Path) field.Er
 		allErrs = append(allErrs, validateCIDR(cidr, fieldPath.Child("sshAccess").Index(i))...)
 	}
 
+	if spec.Topology != nil && spec.Topology.Bastion != nil {
+		allErrs = append(allErrs, validateBastion(spec.Topology.Bastion, fieldPath.Child("topology", "bastion"))...)
+	}
+
+	if spec.Topology != nil && spec.Topology.SessionManager != nil {
+		fp := fieldPath.Child("topology", "sessionManager")
+		if kops.CloudProviderID(spec.CloudProvider) != kops.CloudProviderAWS {
+			allErrs = append(allErrs, field.Forbidden(fp, "session manager is only supported on AWS"))
+		}
+		if spec.Topology.Bastion != nil {
+			allErrs = append(allErrs, field.Forbidden(fp, "session manager replaces the bastion, so cannot be used with topology.bastion"))
+		}
+	}
+
 	// KubernetesAPIAccess
 	for i, cidr := range spec.KubernetesAPIAccess {
 		allErrs = append(allErrs, validateCIDR(cidr, fieldPath.Child("kubernetesAPIAccess").Index(i))...)
@@ -96,6 +125,81 @@ func validateClusterSpec(spec *kops.ClusterSpec, fieldPath *field.Path) field.Er
 		allErrs = append(allErrs, validateNetworking(spec.Networking, fieldPath.Child("networking"))...)
 	}
 
+	if spec.GossipConfig != nil {
+		allErrs = append(allErrs, validateGossipConfig(spec.GossipConfig, fieldPath.Child("gossipConfig"))...)
+	}
+
+	if spec.ExternalDNS != nil {
+		allErrs = append(allErrs, validateExternalDNS(spec, fieldPath.Child("externalDns"))...)
+	}
+
+	if spec.InstanceMetadata != nil {
+		allErrs = append(allErrs, validateInstanceMetadataOptions(spec.InstanceMetadata, fieldPath.Child("instanceMetadata"))...)
+	}
+
+	if spec.EncryptionProvider != nil {
+		allErrs = append(allErrs, validateEncryptionProvider(spec, fieldPath.Child("encryptionProvider"))...)
+	}
+
+	if spec.PodSecurity != nil {
+		allErrs = append(allErrs, validatePodSecurity(spec, fieldPath.Child("podSecurity"))...)
+	}
+
+	if spec.NetworkPolicy != nil {
+		allErrs = append(allErrs, validateNetworkPolicy(spec, fieldPath.Child("networkPolicy"))...)
+	}
+
+	if spec.KopsController != nil {
+		allErrs = append(allErrs, validateKopsController(spec, fieldPath.Child("kopsController"))...)
+	}
+
+	if spec.CloudConfig != nil {
+		allErrs = append(allErrs, validateCSIDriver(spec, spec.CloudConfig.AWSEBSCSIDriver, kops.CloudProviderAWS, fieldPath.Child("cloudConfig", "awsEBSCSIDriver"))...)
+		allErrs = append(allErrs, validateCSIDriver(spec, spec.CloudConfig.GCEPDCSIDriver, kops.CloudProviderGCE, fieldPath.Child("cloudConfig", "gcePDCSIDriver"))...)
+	}
+
+	if spec.CloudConfig != nil && spec.CloudConfig.DefaultStorageClass != nil {
+		allErrs = append(allErrs, validateDefaultStorageClass(spec, fieldPath.Child("cloudConfig", "defaultStorageClass"))...)
+	}
+
+	if spec.NTP != nil {
+		allErrs = append(allErrs, validateNTP(spec.NTP, fieldPath.Child("ntp"))...)
+	}
+
+	if spec.Authentication != nil && spec.Authentication.OIDC != nil {
+		allErrs = append(allErrs, validateOIDCAuthentication(spec.Authentication.OIDC, fieldPath.Child("authentication", "oidc"))...)
+	}
+
+	if spec.Authorization != nil && spec.Authorization.RBAC != nil {
+		allErrs = append(allErrs, validateRBACAuthorization(spec.Authorization.RBAC, fieldPath.Child("authorization", "rbac"))...)
+	}
+
+	if spec.Authentication != nil && spec.Authentication.Aws != nil {
+		allErrs = append(allErrs, validateAwsAuthentication(spec.Authentication.Aws, fieldPath.Child("authentication", "aws"))...)
+	}
+
+	for i := range spec.ContainerRegistryMirrors {
+		allErrs = append(allErrs, validateContainerRegistryMirror(&spec.ContainerRegistryMirrors[i], fieldPath.Child("containerRegistryMirrors").Index(i))...)
+	}
+
+	if spec.KubeDNS != nil {
+		allErrs = append(allErrs, validateKubeDNS(spec.KubeDNS, fieldPath.Child("kubeDNS"))...)
+	}
+
+	if spec.KubeProxy != nil {
+		allErrs = append(allErrs, validateKubeProxy(spec.KubeProxy, fieldPath.Child("kubeProxy"))...)
+	}
+
+	if spec.KubeAPIServer != nil && spec.KubeAPIServer.Resources != nil {
+		allErrs = append(allErrs, validateControlPlaneResources(spec.KubeAPIServer.Resources, fieldPath.Child("kubeAPIServer", "resources"))...)
+	}
+	if spec.KubeControllerManager != nil && spec.KubeControllerManager.Resources != nil {
+		allErrs = append(allErrs, validateControlPlaneResources(spec.KubeControllerManager.Resources, fieldPath.Child("kubeControllerManager", "resources"))...)
+	}
+	if spec.KubeScheduler != nil && spec.KubeScheduler.Resources != nil {
+		allErrs = append(allErrs, validateControlPlaneResources(spec.KubeScheduler.Resources, fieldPath.Child("kubeScheduler", "resources"))...)
+	}
+
 	// IAM additionalPolicies
 	if spec.AdditionalPolicies != nil {
 		for k, v := range *spec.AdditionalPolicies {
@@ -106,6 +210,19 @@ func validateClusterSpec(spec *kops.ClusterSpec, fieldPath *field.Path) field.Er
 	return allErrs
 }
 
+func validateBastion(v *kops.BastionSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.LoadBalancerType != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("loadBalancerType"), (*string)(&v.LoadBalancerType), []string{string(kops.LoadBalancerTypePublic), string(kops.LoadBalancerTypeInternal)})...)
+	}
+	for i, cidr := range v.AllowedCIDRs {
+		allErrs = append(allErrs, validateCIDR(cidr, fldPath.Child("allowedCIDRs").Index(i))...)
+	}
+
+	return allErrs
+}
+
 func validateCIDR(cidr string, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -280,6 +397,480 @@ func validateNetworkingFlannel(v *kops.FlannelNetworkingSpec, fldPath *field.Pat
 	return allErrs
 }
 
+func validateGossipConfig(v *kops.GossipConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("protocol"), v.Protocol, []string{"mesh"})...)
+	allErrs = append(allErrs, validateListenAddress(v.Listen, fldPath.Child("listen"))...)
+
+	if v.Secondary != nil {
+		secondaryPath := fldPath.Child("secondary")
+		if v.Secondary.Protocol == nil {
+			allErrs = append(allErrs, field.Required(secondaryPath.Child("protocol"), "secondary gossip protocol must be specified"))
+		}
+		allErrs = append(allErrs, IsValidValue(secondaryPath.Child("protocol"), v.Secondary.Protocol, []string{"http"})...)
+		allErrs = append(allErrs, validateListenAddress(v.Secondary.Listen, secondaryPath.Child("listen"))...)
+
+		if v.Listen != nil && v.Secondary.Listen != nil && *v.Listen == *v.Secondary.Listen {
+			allErrs = append(allErrs, field.Invalid(secondaryPath.Child("listen"), *v.Secondary.Listen, "secondary gossip protocol must listen on a different address from the primary"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateExternalDNS(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	v := spec.ExternalDNS
+
+	if v.Infoblox != nil && v.Provider != kops.ExternalDNSProviderInfoblox {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("infoblox"), "infoblox configuration is only allowed with the infoblox provider"))
+	}
+
+	if v.Provider == "" {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("provider"), &v.Provider, []string{kops.ExternalDNSProviderCloudFlare, kops.ExternalDNSProviderInfoblox})...)
+
+	if v.Provider == kops.ExternalDNSProviderInfoblox {
+		if v.Infoblox == nil || v.Infoblox.Host == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("infoblox", "host"), "the infoblox provider requires the host of the grid master"))
+		}
+	}
+
+	if v.Disable {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider requires dns-controller"))
+	}
+	if dns.IsGossipHostname(spec.MasterInternalName) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider cannot be used with gossip DNS"))
+	}
+
+	// These create records directly in the cloud's DNS service, which we are not using
+	if spec.API != nil && spec.API.LoadBalancer != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider cannot be used with an API load balancer"))
+	}
+	if spec.Topology != nil && spec.Topology.Bastion != nil && spec.Topology.Bastion.BastionPublicName != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider cannot be used with a bastion DNS name"))
+	}
+	if spec.Topology != nil && spec.Topology.DNS != nil && spec.Topology.DNS.Type == kops.DNSTypePrivate {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider cannot be used with private DNS"))
+	}
+	if spec.DNSZoneOptions != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider cannot be used with dnsZoneOptions"))
+	}
+
+	return allErrs
+}
+
+func validateEncryptionProvider(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	v := spec.EncryptionProvider
+
+	if spec.EncryptionConfig == nil || !*spec.EncryptionConfig {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "encryptionConfig must be enabled to use an encryptionProvider"))
+	}
+
+	if v.Type != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("type"), &v.Type, []string{"aescbc", "kms"})...)
+	}
+
+	if v.Type == "kms" {
+		if v.KMS == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("kms"), "kms must be configured for the kms encryption provider"))
+		} else {
+			if v.KMS.Name == "" {
+				allErrs = append(allErrs, field.Required(fldPath.Child("kms", "name"), "name of the KMS plugin must be set"))
+			}
+			if v.KMS.Endpoint == "" {
+				allErrs = append(allErrs, field.Required(fldPath.Child("kms", "endpoint"), "endpoint of the KMS plugin must be set"))
+			}
+			if v.KMS.CacheSize != nil && *v.KMS.CacheSize < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("kms", "cacheSize"), *v.KMS.CacheSize, "cacheSize cannot be negative"))
+			}
+		}
+	} else if v.KMS != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("kms"), "kms can only be configured for the kms encryption provider"))
+	}
+
+	return allErrs
+}
+
+func validatePodSecurity(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("preset"), &spec.PodSecurity.Preset, []string{kops.PodSecurityPresetRestricted, kops.PodSecurityPresetBaseline, kops.PodSecurityPresetPrivileged})...)
+
+	if spec.Authorization == nil || spec.Authorization.RBAC == nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "pod security policies require RBAC authorization"))
+	}
+
+	return allErrs
+}
+
+func validateNetworkPolicy(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	v := spec.NetworkPolicy
+
+	for i, namespace := range v.Namespaces {
+		for _, msg := range validation.ValidateNamespaceName(namespace, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace, msg))
+		}
+		if namespace == "kube-system" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespaces").Index(i), "default-deny policies cannot be installed in kube-system"))
+		}
+	}
+
+	if v.DefaultDeny {
+		n := spec.Networking
+		if n == nil || (n.Calico == nil && n.Canal == nil && n.Cilium == nil && n.Weave == nil && n.Kuberouter == nil && n.Romana == nil) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("defaultDeny"), "network policies are only enforced by the calico, canal, cilium, kube-router, romana and weave networking providers"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateRBACAuthorization(v *kops.RBACAuthorizationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, group := range v.AdminGroups {
+		if strings.TrimSpace(group) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("adminGroups").Index(i), "group name cannot be empty"))
+		}
+	}
+	for i, group := range v.ReadOnlyGroups {
+		if strings.TrimSpace(group) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("readOnlyGroups").Index(i), "group name cannot be empty"))
+		}
+	}
+
+	if sa := v.CIServiceAccount; sa != nil {
+		fp := fldPath.Child("ciServiceAccount")
+		if sa.Name == "" {
+			allErrs = append(allErrs, field.Required(fp.Child("name"), "a service account name is required"))
+		} else {
+			for _, msg := range validation.ValidateServiceAccountName(sa.Name, false) {
+				allErrs = append(allErrs, field.Invalid(fp.Child("name"), sa.Name, msg))
+			}
+		}
+		if sa.Namespace != "" {
+			for _, msg := range validation.ValidateNamespaceName(sa.Namespace, false) {
+				allErrs = append(allErrs, field.Invalid(fp.Child("namespace"), sa.Namespace, msg))
+			}
+		}
+		if sa.ClusterRole != "" {
+			for _, msg := range validation.NameIsDNSSubdomain(sa.ClusterRole, false) {
+				allErrs = append(allErrs, field.Invalid(fp.Child("clusterRole"), sa.ClusterRole, msg))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateKopsController(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	v := spec.KopsController
+
+	if v.Port != 0 && (v.Port < 1 || v.Port > 65535) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), v.Port, "must be a valid port number"))
+	}
+	if v.CertificateTTL != nil && v.CertificateTTL.Duration < time.Hour {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("certificateTTL"), v.CertificateTTL.Duration.String(), "must be at least 1h"))
+	}
+
+	if spec.KopsControllerEnabled() {
+		if kops.CloudProviderID(spec.CloudProvider) != kops.CloudProviderAWS {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "the kops-controller verifies the identity of nodes on AWS only"))
+		}
+		if spec.NodeAuthorization != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "the kops-controller cannot be combined with nodeAuthorization"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateCSIDriver(spec *kops.ClusterSpec, driver *kops.CSIDriverSpec, cloudProvider kops.CloudProviderID, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if driver == nil {
+		return allErrs
+	}
+
+	if driver.IsEnabled() && kops.CloudProviderID(spec.CloudProvider) != cloudProvider {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), fmt.Sprintf("the CSI driver is only supported with cloud provider %q", cloudProvider)))
+	}
+	if driver.Migration != nil && *driver.Migration && !driver.IsEnabled() {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("migration"), "volumes can only be migrated to an enabled CSI driver"))
+	}
+
+	return allErrs
+}
+
+func validateDefaultStorageClass(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	v := spec.CloudConfig.DefaultStorageClass
+
+	switch kops.CloudProviderID(spec.CloudProvider) {
+	case kops.CloudProviderAWS:
+		if _, found := awsEBSVolumeLimits[v.Type]; v.Type != "" && !found {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), v.Type, sets.StringKeySet(awsEBSVolumeLimits).List()))
+		}
+		if v.Type == "gp3" && !spec.CloudConfig.AWSEBSCSIDriver.IsEnabled() {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("type"), "gp3 volumes can only be provisioned by the AWS EBS CSI driver"))
+		}
+	case kops.CloudProviderGCE:
+		diskTypes := []string{"pd-balanced", "pd-ssd", "pd-standard"}
+		if v.Type != "" && !sets.NewString(diskTypes...).Has(v.Type) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), v.Type, diskTypes))
+		}
+		if v.Encrypted != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("encrypted"), "GCE always encrypts disks"))
+		}
+	default:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the default storage class can only be managed on AWS and GCE"))
+	}
+
+	if v.ReclaimPolicy != "" && v.ReclaimPolicy != "Delete" && v.ReclaimPolicy != "Retain" {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("reclaimPolicy"), v.ReclaimPolicy, []string{"Delete", "Retain"}))
+	}
+
+	return allErrs
+}
+
+func validateOIDCAuthentication(v *kops.OIDCAuthenticationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.IssuerURL == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("issuerURL"), "the OIDC issuer URL is required"))
+	} else if u, err := url.Parse(v.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("issuerURL"), v.IssuerURL, "must be an https URL"))
+	}
+	if v.ClientID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientID"), "the OIDC client ID is required"))
+	}
+
+	if v.Dex != nil {
+		if strings.TrimSpace(v.Dex.Connectors) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("dex", "connectors"), "dex needs at least one connector to an identity provider"))
+		} else {
+			var connectors []map[string]interface{}
+			if err := yaml.Unmarshal([]byte(v.Dex.Connectors), &connectors); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("dex", "connectors"), v.Dex.Connectors, fmt.Sprintf("must be a YAML list of dex connectors: %v", err)))
+			}
+		}
+		for i, uri := range v.Dex.RedirectURIs {
+			if _, err := url.Parse(uri); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("dex", "redirectURIs").Index(i), uri, "must be a URL"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// format is arn:aws:iam::123456789012:role/KubernetesAdmin, where the role or user name may include a path
+var (
+	validIAMRoleARN = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role\/\S+$`)
+	validIAMUserARN = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:user\/\S+$`)
+)
+
+func validateAwsAuthentication(v *kops.AwsAuthenticationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, m := range v.MapRoles {
+		fp := fldPath.Child("mapRoles").Index(i)
+		if !validIAMRoleARN.MatchString(m.RoleARN) {
+			allErrs = append(allErrs, field.Invalid(fp.Child("roleARN"), m.RoleARN, "must be the ARN of an IAM role"))
+		}
+		if m.Username == "" {
+			allErrs = append(allErrs, field.Required(fp.Child("username"), "a kubernetes user name is required"))
+		}
+	}
+	for i, m := range v.MapUsers {
+		fp := fldPath.Child("mapUsers").Index(i)
+		if !validIAMUserARN.MatchString(m.UserARN) {
+			allErrs = append(allErrs, field.Invalid(fp.Child("userARN"), m.UserARN, "must be the ARN of an IAM user"))
+		}
+		if m.Username == "" {
+			allErrs = append(allErrs, field.Required(fp.Child("username"), "a kubernetes user name is required"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateNTP(v *kops.NTPConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, server := range v.Servers {
+		if net.ParseIP(server) != nil {
+			continue
+		}
+		for _, msg := range validation.NameIsDNSSubdomain(server, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("servers").Index(i), server, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// reservedSecretNames are the secrets used by the cluster itself, which must not be exposed to the nodes as mirror credentials
+var reservedSecretNames = sets.NewString("admin", "kube", "dockerconfig", "encryptionconfig")
+
+func validateContainerRegistryMirror(v *kops.ContainerRegistryMirrorSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.Registry != "" && v.Registry != kops.DockerHubRegistry {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("registry"), v.Registry, []string{kops.DockerHubRegistry}))
+	}
+
+	if len(v.Endpoints) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("endpoints"), "at least one mirror endpoint must be specified"))
+	}
+	for i, endpoint := range v.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("endpoints").Index(i), endpoint, "must be an http or https URL"))
+		}
+	}
+
+	if v.CredentialsSecret != "" {
+		for _, msg := range validation.NameIsDNSSubdomain(v.CredentialsSecret, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("credentialsSecret"), v.CredentialsSecret, msg))
+		}
+		if reservedSecretNames.Has(v.CredentialsSecret) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("credentialsSecret"), fmt.Sprintf("secret %q is reserved for use by the cluster", v.CredentialsSecret)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateKubeDNS(v *kops.KubeDNSConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.Provider != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("provider"), &v.Provider, []string{"CoreDNS", "KubeDNS"})...)
+	}
+
+	if v.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), v.Replicas, "must not be negative"))
+	}
+
+	if a := v.Autoscaler; a != nil {
+		fldPath := fldPath.Child("autoscaler")
+		for name, value := range map[string]int{"coresPerReplica": a.CoresPerReplica, "nodesPerReplica": a.NodesPerReplica, "min": a.Min, "max": a.Max} {
+			if value < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value, "must not be negative"))
+			}
+		}
+		if a.Max != 0 && a.Max < a.Min {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("max"), a.Max, "must not be less than min"))
+		}
+	}
+
+	if n := v.NodeLocalDNS; n != nil {
+		fldPath := fldPath.Child("nodeLocalDNS")
+		if n.LocalIP != "" {
+			if ip := net.ParseIP(n.LocalIP); ip == nil || !ip.IsLinkLocalUnicast() {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("localIP"), n.LocalIP, "must be a link-local address, such as 169.254.20.10"))
+			}
+		}
+
+		quantities := make(map[string]resource.Quantity)
+		for name, value := range map[string]string{"cpuRequest": n.CPURequest, "memoryRequest": n.MemoryRequest, "memoryLimit": n.MemoryLimit} {
+			if value == "" {
+				continue
+			}
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value, fmt.Sprintf("must be a resource quantity: %v", err)))
+				continue
+			}
+			quantities[name] = q
+		}
+		request, hasRequest := quantities["memoryRequest"]
+		limit, hasLimit := quantities["memoryLimit"]
+		if hasRequest && hasLimit && limit.Cmp(request) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryLimit"), n.MemoryLimit, "must not be less than memoryRequest"))
+		}
+	}
+
+	return allErrs
+}
+
+var validIPVSSchedulers = []string{"rr", "wrr", "lc", "wlc", "lblc", "lblcr", "sh", "dh", "sed", "nq"}
+
+func validateKubeProxy(v *kops.KubeProxyConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.ProxyMode != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("proxyMode"), &v.ProxyMode, []string{"userspace", "iptables", "ipvs"})...)
+	}
+
+	if v.IPVSScheduler != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("ipvsScheduler"), &v.IPVSScheduler, validIPVSSchedulers)...)
+		if v.ProxyMode != "ipvs" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipvsScheduler"), "ipvsScheduler requires proxyMode ipvs"))
+		}
+	}
+
+	for i, cidr := range v.IPVSExcludeCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ipvsExcludeCIDRs").Index(i), cidr, "must be a valid CIDR"))
+		}
+	}
+	if len(v.IPVSExcludeCIDRs) > 0 && v.ProxyMode != "ipvs" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipvsExcludeCIDRs"), "ipvsExcludeCIDRs requires proxyMode ipvs"))
+	}
+
+	return allErrs
+}
+
+func validateControlPlaneResources(v *kops.ControlPlaneResources, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	quantities := make(map[string]resource.Quantity)
+	for name, value := range map[string]string{"cpuRequest": v.CPURequest, "cpuLimit": v.CPULimit, "memoryRequest": v.MemoryRequest, "memoryLimit": v.MemoryLimit} {
+		if value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value, fmt.Sprintf("must be a resource quantity: %v", err)))
+			continue
+		}
+		quantities[name] = q
+	}
+
+	for _, r := range []string{"cpu", "memory"} {
+		request, hasRequest := quantities[r+"Request"]
+		limit, hasLimit := quantities[r+"Limit"]
+		if hasRequest && hasLimit && limit.Cmp(request) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(r+"Limit"), limit.String(), fmt.Sprintf("must not be less than %sRequest", r)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateListenAddress(v *string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if v == nil {
+		return allErrs
+	}
+
+	_, port, err := net.SplitHostPort(*v)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, *v, "must be of the form address:port"))
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath, *v, "port must be between 1 and 65535"))
+	}
+	return allErrs
+}
+
 func validateAdditionalPolicy(role string, policy string, fldPath *field.Path) field.ErrorList {
 	errs := field.ErrorList{}
 
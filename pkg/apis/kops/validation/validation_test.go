@@ -18,11 +18,14 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
 )
 
 func Test_Validate_DNS(t *testing.T) {
@@ -242,6 +245,124 @@ func Test_Validate_Networking_Flannel(t *testing.T) {
 	}
 }
 
+func Test_Validate_GossipConfig(t *testing.T) {
+	grid := []struct {
+		Input          kops.GossipConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.GossipConfig{},
+		},
+		{
+			Input: kops.GossipConfig{
+				Protocol: s("mesh"),
+				Listen:   s("0.0.0.0:3999"),
+				Secondary: &kops.GossipConfigSecondary{
+					Protocol: s("http"),
+					Listen:   s("0.0.0.0:3993"),
+				},
+			},
+		},
+		{
+			Input: kops.GossipConfig{
+				Protocol: s("memberlist"),
+			},
+			ExpectedErrors: []string{"Unsupported value::GossipConfig.protocol"},
+		},
+		{
+			Input: kops.GossipConfig{
+				Listen: s("3999"),
+			},
+			ExpectedErrors: []string{"Invalid value::GossipConfig.listen"},
+		},
+		{
+			Input: kops.GossipConfig{
+				Secondary: &kops.GossipConfigSecondary{},
+			},
+			ExpectedErrors: []string{"Required value::GossipConfig.secondary.protocol"},
+		},
+		{
+			Input: kops.GossipConfig{
+				Listen: s("0.0.0.0:3999"),
+				Secondary: &kops.GossipConfigSecondary{
+					Protocol: s("http"),
+					Listen:   s("0.0.0.0:3999"),
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::GossipConfig.secondary.listen"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateGossipConfig(&g.Input, field.NewPath("GossipConfig"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ExternalDNS(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				ExternalDNS: &kops.ExternalDNSConfig{WatchNamespace: "default"},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				ExternalDNS: &kops.ExternalDNSConfig{Provider: "cloudflare"},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				ExternalDNS: &kops.ExternalDNSConfig{
+					Provider: "infoblox",
+					Infoblox: &kops.InfobloxDNSConfig{Host: "gm.example.com"},
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				ExternalDNS: &kops.ExternalDNSConfig{Provider: "powerdns"},
+			},
+			ExpectedErrors: []string{"Unsupported value::ExternalDNS.provider"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				ExternalDNS: &kops.ExternalDNSConfig{Provider: "infoblox"},
+			},
+			ExpectedErrors: []string{"Required value::ExternalDNS.infoblox.host"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				ExternalDNS: &kops.ExternalDNSConfig{
+					Provider: "cloudflare",
+					Infoblox: &kops.InfobloxDNSConfig{Host: "gm.example.com"},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::ExternalDNS.infoblox"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				MasterInternalName: "api.internal.example.k8s.local",
+				ExternalDNS:        &kops.ExternalDNSConfig{Provider: "cloudflare"},
+			},
+			ExpectedErrors: []string{"Forbidden::ExternalDNS.provider"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				API:         &kops.AccessSpec{LoadBalancer: &kops.LoadBalancerAccessSpec{Type: kops.LoadBalancerTypePublic}},
+				ExternalDNS: &kops.ExternalDNSConfig{Provider: "cloudflare"},
+			},
+			ExpectedErrors: []string{"Forbidden::ExternalDNS.provider"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateExternalDNS(&g.Input, field.NewPath("ExternalDNS"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 func Test_Validate_AdditionalPolicies(t *testing.T) {
 	grid := []struct {
 		Input          map[string]string
@@ -291,3 +412,734 @@ func Test_Validate_AdditionalPolicies(t *testing.T) {
 		testErrors(t, g.Input, errs, g.ExpectedErrors)
 	}
 }
+
+func TestValidateEtcdVersionUpdate(t *testing.T) {
+	grid := []struct {
+		Old            string
+		New            string
+		Manager        bool
+		ExpectedErrors []string
+	}{
+		{
+			Old:     "2.2.1",
+			New:     "2.2.1",
+			Manager: false,
+		},
+		{
+			Old:            "2.2.1",
+			New:            "3.2.18",
+			Manager:        false,
+			ExpectedErrors: []string{"Forbidden::Version"},
+		},
+		{
+			Old:     "",
+			New:     "3.2.18",
+			Manager: true,
+		},
+		{
+			Old:     "3.1.12",
+			New:     "3.2.18",
+			Manager: true,
+		},
+		{
+			Old:     "3.2.18",
+			New:     "3.2.24",
+			Manager: true,
+		},
+		{
+			Old:            "3.0.17",
+			New:            "3.2.18",
+			Manager:        true,
+			ExpectedErrors: []string{"Forbidden::Version"},
+		},
+		{
+			Old:            "3.2.18",
+			New:            "3.1.12",
+			Manager:        true,
+			ExpectedErrors: []string{"Forbidden::Version"},
+		},
+		{
+			Old:            "3.2.18",
+			New:            "2.2.1",
+			Manager:        true,
+			ExpectedErrors: []string{"Forbidden::Version"},
+		},
+	}
+	for _, g := range grid {
+		old := &kops.EtcdClusterSpec{Name: "main", Version: g.Old}
+		obj := &kops.EtcdClusterSpec{Name: "main", Version: g.New}
+		if g.Manager {
+			obj.Manager = &kops.EtcdManagerSpec{}
+		}
+		errs := validateEtcdVersionUpdate(field.NewPath("Version"), obj, old)
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_EncryptionProvider(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				EncryptionConfig:   fi.Bool(true),
+				EncryptionProvider: &kops.EncryptionProviderSpec{},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				EncryptionProvider: &kops.EncryptionProviderSpec{Type: "aescbc"},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.encryptionProvider"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				EncryptionConfig:   fi.Bool(true),
+				EncryptionProvider: &kops.EncryptionProviderSpec{Type: "secretbox"},
+			},
+			ExpectedErrors: []string{"Unsupported value::spec.encryptionProvider.type"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				EncryptionConfig:   fi.Bool(true),
+				EncryptionProvider: &kops.EncryptionProviderSpec{Type: "kms"},
+			},
+			ExpectedErrors: []string{"Required value::spec.encryptionProvider.kms"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				EncryptionConfig: fi.Bool(true),
+				EncryptionProvider: &kops.EncryptionProviderSpec{
+					Type: "kms",
+					KMS:  &kops.KMSEncryptionProviderSpec{Name: "aws-encryption-provider"},
+				},
+			},
+			ExpectedErrors: []string{"Required value::spec.encryptionProvider.kms.endpoint"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				EncryptionConfig: fi.Bool(true),
+				EncryptionProvider: &kops.EncryptionProviderSpec{
+					Type: "kms",
+					KMS: &kops.KMSEncryptionProviderSpec{
+						Name:     "aws-encryption-provider",
+						Endpoint: "unix:///var/run/kmsplugin/socket.sock",
+					},
+				},
+			},
+		},
+	}
+	for _, g := range grid {
+		errs := validateEncryptionProvider(&g.Input, field.NewPath("spec", "encryptionProvider"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_PodSecurity(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				Authorization: &kops.AuthorizationSpec{RBAC: &kops.RBACAuthorizationSpec{}},
+				PodSecurity:   &kops.PodSecuritySpec{Preset: "restricted"},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				Authorization: &kops.AuthorizationSpec{RBAC: &kops.RBACAuthorizationSpec{}},
+				PodSecurity:   &kops.PodSecuritySpec{Preset: "strict"},
+			},
+			ExpectedErrors: []string{"Unsupported value::spec.podSecurity.preset"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				Authorization: &kops.AuthorizationSpec{AlwaysAllow: &kops.AlwaysAllowAuthorizationSpec{}},
+				PodSecurity:   &kops.PodSecuritySpec{Preset: "baseline"},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.podSecurity"},
+		},
+	}
+	for _, g := range grid {
+		errs := validatePodSecurity(&g.Input, field.NewPath("spec", "podSecurity"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_NetworkPolicy(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				Networking:    &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{}},
+				NetworkPolicy: &kops.NetworkPolicySpec{DefaultDeny: true, Namespaces: []string{"default", "team-a"}},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				Networking:    &kops.NetworkingSpec{Kubenet: &kops.KubenetNetworkingSpec{}},
+				NetworkPolicy: &kops.NetworkPolicySpec{DefaultDeny: true},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.networkPolicy.defaultDeny"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				Networking:    &kops.NetworkingSpec{Cilium: &kops.CiliumNetworkingSpec{}},
+				NetworkPolicy: &kops.NetworkPolicySpec{DefaultDeny: true, Namespaces: []string{"kube-system"}},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.networkPolicy.namespaces[0]"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				Networking:    &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+				NetworkPolicy: &kops.NetworkPolicySpec{DefaultDeny: true, Namespaces: []string{"Team_A"}},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.networkPolicy.namespaces[0]"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateNetworkPolicy(&g.Input, field.NewPath("spec", "networkPolicy"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_KopsController(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider:  "aws",
+				KopsController: &kops.KopsControllerSpec{Enabled: fi.Bool(true), Port: 3988},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider:  "gce",
+				KopsController: &kops.KopsControllerSpec{Enabled: fi.Bool(true)},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsController.enabled"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider:     "aws",
+				KopsController:    &kops.KopsControllerSpec{Enabled: fi.Bool(true)},
+				NodeAuthorization: &kops.NodeAuthorizationSpec{NodeAuthorizer: &kops.NodeAuthorizerSpec{}},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsController.enabled"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider:  "gce",
+				KopsController: &kops.KopsControllerSpec{Enabled: fi.Bool(false), Port: 70000},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kopsController.port"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider:  "aws",
+				KopsController: &kops.KopsControllerSpec{Enabled: fi.Bool(true), CertificateTTL: &metav1.Duration{Duration: time.Minute}},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kopsController.certificateTTL"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateKopsController(&g.Input, field.NewPath("spec", "kopsController"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_CSIDriver(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				CloudConfig: &kops.CloudConfiguration{
+					AWSEBSCSIDriver: &kops.CSIDriverSpec{Enabled: fi.Bool(true), Migration: fi.Bool(true)},
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "gce",
+				CloudConfig: &kops.CloudConfiguration{
+					AWSEBSCSIDriver: &kops.CSIDriverSpec{Enabled: fi.Bool(true)},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.cloudConfig.awsEBSCSIDriver.enabled"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "gce",
+				CloudConfig: &kops.CloudConfiguration{
+					GCEPDCSIDriver: &kops.CSIDriverSpec{Enabled: fi.Bool(false), Migration: fi.Bool(true)},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.cloudConfig.gcePDCSIDriver.migration"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateCSIDriver(&g.Input, g.Input.CloudConfig.AWSEBSCSIDriver, kops.CloudProviderAWS, field.NewPath("spec", "cloudConfig", "awsEBSCSIDriver"))
+		errs = append(errs, validateCSIDriver(&g.Input, g.Input.CloudConfig.GCEPDCSIDriver, kops.CloudProviderGCE, field.NewPath("spec", "cloudConfig", "gcePDCSIDriver"))...)
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_DefaultStorageClass(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				CloudConfig: &kops.CloudConfiguration{
+					DefaultStorageClass: &kops.DefaultStorageClassSpec{Type: "io1", Encrypted: fi.Bool(true), ReclaimPolicy: "Retain"},
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				CloudConfig: &kops.CloudConfiguration{
+					DefaultStorageClass: &kops.DefaultStorageClassSpec{Type: "gp3"},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.cloudConfig.defaultStorageClass.type"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				CloudConfig: &kops.CloudConfiguration{
+					AWSEBSCSIDriver:     &kops.CSIDriverSpec{Enabled: fi.Bool(true)},
+					DefaultStorageClass: &kops.DefaultStorageClassSpec{Type: "gp3", AllowVolumeExpansion: fi.Bool(true)},
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "gce",
+				CloudConfig: &kops.CloudConfiguration{
+					DefaultStorageClass: &kops.DefaultStorageClassSpec{Type: "gp2", Encrypted: fi.Bool(true), ReclaimPolicy: "Recycle"},
+				},
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::spec.cloudConfig.defaultStorageClass.type",
+				"Forbidden::spec.cloudConfig.defaultStorageClass.encrypted",
+				"Unsupported value::spec.cloudConfig.defaultStorageClass.reclaimPolicy",
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "digitalocean",
+				CloudConfig: &kops.CloudConfiguration{
+					DefaultStorageClass: &kops.DefaultStorageClassSpec{},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.cloudConfig.defaultStorageClass"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateDefaultStorageClass(&g.Input, field.NewPath("spec", "cloudConfig", "defaultStorageClass"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_DefaultStorageClassUpdate(t *testing.T) {
+	grid := []struct {
+		Old            kops.DefaultStorageClassSpec
+		New            kops.DefaultStorageClassSpec
+		ExpectedErrors []string
+	}{
+		{
+			Old: kops.DefaultStorageClassSpec{},
+			New: kops.DefaultStorageClassSpec{Type: "gp2", ReclaimPolicy: "Delete", AllowVolumeExpansion: fi.Bool(true)},
+		},
+		{
+			Old:            kops.DefaultStorageClassSpec{Type: "gp2"},
+			New:            kops.DefaultStorageClassSpec{Type: "io1", Encrypted: fi.Bool(true)},
+			ExpectedErrors: []string{"Forbidden::DefaultStorageClass.Type", "Forbidden::DefaultStorageClass.Encrypted"},
+		},
+		{
+			Old:            kops.DefaultStorageClassSpec{},
+			New:            kops.DefaultStorageClassSpec{ReclaimPolicy: "Retain"},
+			ExpectedErrors: []string{"Forbidden::DefaultStorageClass.ReclaimPolicy"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateDefaultStorageClassUpdate(field.NewPath("DefaultStorageClass"), kops.CloudProviderAWS, &g.New, &g.Old)
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_NTP(t *testing.T) {
+	grid := []struct {
+		Input          kops.NTPConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.NTPConfig{Managed: fi.Bool(false)},
+		},
+		{
+			Input: kops.NTPConfig{Servers: []string{"169.254.169.123", "metadata.google.internal", "fd00:ec2::123"}},
+		},
+		{
+			Input:          kops.NTPConfig{Servers: []string{"0.pool.ntp.org", ""}},
+			ExpectedErrors: []string{"Invalid value::spec.ntp.servers[1]"},
+		},
+		{
+			Input:          kops.NTPConfig{Servers: []string{"ntp://time.example.com"}},
+			ExpectedErrors: []string{"Invalid value::spec.ntp.servers[0]"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateNTP(&g.Input, field.NewPath("spec", "ntp"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ContainerRegistryMirror(t *testing.T) {
+	grid := []struct {
+		Input          kops.ContainerRegistryMirrorSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ContainerRegistryMirrorSpec{
+				Endpoints:         []string{"https://mirror.example.com"},
+				CredentialsSecret: "mirror-credentials",
+			},
+		},
+		{
+			Input: kops.ContainerRegistryMirrorSpec{
+				Registry:  "docker.io",
+				Endpoints: []string{"https://mirror.example.com", "http://10.0.0.10:5000"},
+			},
+		},
+		{
+			Input: kops.ContainerRegistryMirrorSpec{
+				Registry:  "quay.io",
+				Endpoints: []string{"https://mirror.example.com"},
+			},
+			ExpectedErrors: []string{"Unsupported value::spec.containerRegistryMirrors[0].registry"},
+		},
+		{
+			Input:          kops.ContainerRegistryMirrorSpec{},
+			ExpectedErrors: []string{"Required value::spec.containerRegistryMirrors[0].endpoints"},
+		},
+		{
+			Input: kops.ContainerRegistryMirrorSpec{
+				Endpoints: []string{"mirror.example.com"},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.containerRegistryMirrors[0].endpoints[0]"},
+		},
+		{
+			Input: kops.ContainerRegistryMirrorSpec{
+				Endpoints:         []string{"https://mirror.example.com"},
+				CredentialsSecret: "mirror/credentials",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.containerRegistryMirrors[0].credentialsSecret"},
+		},
+		{
+			Input: kops.ContainerRegistryMirrorSpec{
+				Endpoints:         []string{"https://mirror.example.com"},
+				CredentialsSecret: "kube",
+			},
+			ExpectedErrors: []string{"Forbidden::spec.containerRegistryMirrors[0].credentialsSecret"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateContainerRegistryMirror(&g.Input, field.NewPath("spec", "containerRegistryMirrors").Index(0))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_KubeDNS(t *testing.T) {
+	grid := []struct {
+		Input          kops.KubeDNSConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.KubeDNSConfig{
+				Provider:   "CoreDNS",
+				Autoscaler: &kops.DNSAutoscalerConfig{CoresPerReplica: 128, Min: 2, Max: 10},
+			},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				Provider:   "KubeDNS",
+				Replicas:   3,
+				Autoscaler: &kops.DNSAutoscalerConfig{Disabled: true},
+			},
+		},
+		{
+			Input:          kops.KubeDNSConfig{Provider: "SkyDNS"},
+			ExpectedErrors: []string{"Unsupported value::spec.kubeDNS.provider"},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				Autoscaler: &kops.DNSAutoscalerConfig{NodesPerReplica: -1},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeDNS.autoscaler.nodesPerReplica"},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				Autoscaler: &kops.DNSAutoscalerConfig{Min: 5, Max: 3},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeDNS.autoscaler.max"},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				NodeLocalDNS: &kops.NodeLocalDNSConfig{Enabled: fi.Bool(true), LocalIP: "169.254.20.10", MemoryRequest: "5Mi", MemoryLimit: "30Mi"},
+			},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				NodeLocalDNS: &kops.NodeLocalDNSConfig{Enabled: fi.Bool(true), LocalIP: "10.0.0.10"},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeDNS.nodeLocalDNS.localIP"},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				NodeLocalDNS: &kops.NodeLocalDNSConfig{Enabled: fi.Bool(true), CPURequest: "lots"},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeDNS.nodeLocalDNS.cpuRequest"},
+		},
+		{
+			Input: kops.KubeDNSConfig{
+				NodeLocalDNS: &kops.NodeLocalDNSConfig{Enabled: fi.Bool(true), MemoryRequest: "64Mi", MemoryLimit: "32Mi"},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeDNS.nodeLocalDNS.memoryLimit"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateKubeDNS(&g.Input, field.NewPath("spec", "kubeDNS"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_KubeProxy(t *testing.T) {
+	grid := []struct {
+		Input          kops.KubeProxyConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.KubeProxyConfig{ProxyMode: "iptables"},
+		},
+		{
+			Input: kops.KubeProxyConfig{ProxyMode: "ipvs", IPVSScheduler: "lc", IPVSExcludeCIDRs: []string{"10.0.0.0/8"}},
+		},
+		{
+			Input:          kops.KubeProxyConfig{ProxyMode: "nftables"},
+			ExpectedErrors: []string{"Unsupported value::spec.kubeProxy.proxyMode"},
+		},
+		{
+			Input:          kops.KubeProxyConfig{ProxyMode: "ipvs", IPVSScheduler: "random"},
+			ExpectedErrors: []string{"Unsupported value::spec.kubeProxy.ipvsScheduler"},
+		},
+		{
+			Input:          kops.KubeProxyConfig{ProxyMode: "ipvs", IPVSExcludeCIDRs: []string{"10.0.0.0"}},
+			ExpectedErrors: []string{"Invalid value::spec.kubeProxy.ipvsExcludeCIDRs[0]"},
+		},
+		{
+			Input:          kops.KubeProxyConfig{IPVSScheduler: "rr"},
+			ExpectedErrors: []string{"Forbidden::spec.kubeProxy.ipvsScheduler"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateKubeProxy(&g.Input, field.NewPath("spec", "kubeProxy"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_OIDCAuthentication(t *testing.T) {
+	connectors := `
+- type: github
+  id: github
+  name: GitHub
+  config:
+    clientID: abc
+    clientSecret: def
+    redirectURI: https://dex.example.com/callback
+`
+	grid := []struct {
+		Input          kops.OIDCAuthenticationSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.OIDCAuthenticationSpec{IssuerURL: "https://accounts.google.com", ClientID: "kubernetes"},
+		},
+		{
+			Input: kops.OIDCAuthenticationSpec{IssuerURL: "https://dex.example.com", ClientID: "kubernetes", Dex: &kops.DexAuthenticationSpec{Connectors: connectors}},
+		},
+		{
+			Input:          kops.OIDCAuthenticationSpec{IssuerURL: "http://dex.example.com", ClientID: "kubernetes"},
+			ExpectedErrors: []string{"Invalid value::spec.authentication.oidc.issuerURL"},
+		},
+		{
+			Input:          kops.OIDCAuthenticationSpec{},
+			ExpectedErrors: []string{"Required value::spec.authentication.oidc.issuerURL", "Required value::spec.authentication.oidc.clientID"},
+		},
+		{
+			Input:          kops.OIDCAuthenticationSpec{IssuerURL: "https://dex.example.com", ClientID: "kubernetes", Dex: &kops.DexAuthenticationSpec{}},
+			ExpectedErrors: []string{"Required value::spec.authentication.oidc.dex.connectors"},
+		},
+		{
+			Input:          kops.OIDCAuthenticationSpec{IssuerURL: "https://dex.example.com", ClientID: "kubernetes", Dex: &kops.DexAuthenticationSpec{Connectors: "type: github"}},
+			ExpectedErrors: []string{"Invalid value::spec.authentication.oidc.dex.connectors"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateOIDCAuthentication(&g.Input, field.NewPath("spec", "authentication", "oidc"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_Bastion(t *testing.T) {
+	grid := []struct {
+		Input          kops.BastionSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.BastionSpec{},
+		},
+		{
+			Input: kops.BastionSpec{LoadBalancerType: kops.LoadBalancerTypeInternal, AllowedCIDRs: []string{"10.0.0.0/8"}},
+		},
+		{
+			Input:          kops.BastionSpec{LoadBalancerType: "Private"},
+			ExpectedErrors: []string{"Unsupported value::spec.topology.bastion.loadBalancerType"},
+		},
+		{
+			Input:          kops.BastionSpec{AllowedCIDRs: []string{"10.0.0.0"}},
+			ExpectedErrors: []string{"Invalid value::spec.topology.bastion.allowedCIDRs[0]"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateBastion(&g.Input, field.NewPath("spec", "topology", "bastion"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_RBACAuthorization(t *testing.T) {
+	grid := []struct {
+		Input          kops.RBACAuthorizationSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.RBACAuthorizationSpec{},
+		},
+		{
+			Input: kops.RBACAuthorizationSpec{
+				AdminGroups:      []string{"system:masters", "admins"},
+				ReadOnlyGroups:   []string{"developers"},
+				CIServiceAccount: &kops.RBACServiceAccountSpec{Name: "ci", Namespace: "ci", ClusterRole: "edit"},
+			},
+		},
+		{
+			Input:          kops.RBACAuthorizationSpec{AdminGroups: []string{""}, ReadOnlyGroups: []string{" "}},
+			ExpectedErrors: []string{"Required value::spec.authorization.rbac.adminGroups[0]", "Required value::spec.authorization.rbac.readOnlyGroups[0]"},
+		},
+		{
+			Input:          kops.RBACAuthorizationSpec{CIServiceAccount: &kops.RBACServiceAccountSpec{}},
+			ExpectedErrors: []string{"Required value::spec.authorization.rbac.ciServiceAccount.name"},
+		},
+		{
+			Input:          kops.RBACAuthorizationSpec{CIServiceAccount: &kops.RBACServiceAccountSpec{Name: "CI", Namespace: "Builds"}},
+			ExpectedErrors: []string{"Invalid value::spec.authorization.rbac.ciServiceAccount.name", "Invalid value::spec.authorization.rbac.ciServiceAccount.namespace"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateRBACAuthorization(&g.Input, field.NewPath("spec", "authorization", "rbac"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_AwsAuthentication(t *testing.T) {
+	grid := []struct {
+		Input          kops.AwsAuthenticationSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.AwsAuthenticationSpec{},
+		},
+		{
+			Input: kops.AwsAuthenticationSpec{
+				MapRoles: []kops.AwsAuthenticationRoleMapping{{RoleARN: "arn:aws:iam::000000000000:role/KubernetesAdmin", Username: "kubernetes-admin", Groups: []string{"system:masters"}}},
+				MapUsers: []kops.AwsAuthenticationUserMapping{{UserARN: "arn:aws:iam::000000000000:user/Alice", Username: "alice"}},
+			},
+		},
+		{
+			Input: kops.AwsAuthenticationSpec{
+				MapRoles: []kops.AwsAuthenticationRoleMapping{{RoleARN: "arn:aws:iam::000000000000:user/Alice", Username: "alice"}},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.authentication.aws.mapRoles[0].roleARN"},
+		},
+		{
+			Input: kops.AwsAuthenticationSpec{
+				MapUsers: []kops.AwsAuthenticationUserMapping{{UserARN: "arn:aws:iam::000000000000:user/Alice"}},
+			},
+			ExpectedErrors: []string{"Required value::spec.authentication.aws.mapUsers[0].username"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateAwsAuthentication(&g.Input, field.NewPath("spec", "authentication", "aws"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ControlPlaneResources(t *testing.T) {
+	grid := []struct {
+		Input          kops.ControlPlaneResources
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ControlPlaneResources{CPURequest: "500m", CPULimit: "2", MemoryRequest: "1Gi", MemoryLimit: "4Gi"},
+		},
+		{
+			Input:          kops.ControlPlaneResources{CPURequest: "half"},
+			ExpectedErrors: []string{"Invalid value::spec.kubeAPIServer.resources.cpuRequest"},
+		},
+		{
+			Input:          kops.ControlPlaneResources{CPURequest: "1", CPULimit: "500m"},
+			ExpectedErrors: []string{"Invalid value::spec.kubeAPIServer.resources.cpuLimit"},
+		},
+		{
+			Input:          kops.ControlPlaneResources{MemoryRequest: "2Gi", MemoryLimit: "1Gi"},
+			ExpectedErrors: []string{"Invalid value::spec.kubeAPIServer.resources.memoryLimit"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateControlPlaneResources(&g.Input, field.NewPath("spec", "kubeAPIServer", "resources"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_Egress(t *testing.T) {
+	grid := []struct {
+		Egress   string
+		Expected bool
+	}{
+		{Egress: "", Expected: true},
+		{Egress: kops.EgressNatGateway, Expected: true},
+		{Egress: kops.EgressSharedNatGateway, Expected: true},
+		{Egress: kops.EgressNatInstance, Expected: true},
+		{Egress: "nat-0123456789abcdef0", Expected: true},
+		{Egress: "i-0123456789abcdef0", Expected: true},
+		{Egress: "igw-0123456789abcdef0", Expected: false},
+		{Egress: "natgateway", Expected: false},
+	}
+	for _, g := range grid {
+		actual := isValidEgress(g.Egress)
+		if actual != g.Expected {
+			t.Errorf("isValidEgress(%q) = %v, expected %v", g.Egress, actual, g.Expected)
+		}
+	}
+}
@@ -40,6 +40,10 @@ type Config struct {
 
 	// Manifests for running etcd
 	EtcdManifests []string `json:"etcdManifests,omitempty"`
+
+	// SSHAuthorizedKeys are the SSH public keys authorized for the admin user, set when the cluster has
+	// SSH credentials beyond the primary key that the cloud installs
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
 }
 
 // Image is a docker image we should pre-load
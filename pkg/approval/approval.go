@@ -0,0 +1,350 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// Operations that can require approval
+const (
+	OperationUpdateCluster = "update-cluster"
+	OperationDeleteCluster = "delete-cluster"
+)
+
+// StateStoreDir is the directory of the state store holding plans, one subdirectory per cluster; the state store
+// does not list it as a cluster
+const StateStoreDir = "approvals"
+
+// Plan is a request to perform an operation, which must be approved by a second operator before it is executed
+type Plan struct {
+	// ID identifies the plan
+	ID string `json:"id"`
+	// Cluster is the name of the cluster the operation applies to
+	Cluster string `json:"cluster"`
+	// Operation is one of the Operation* values
+	Operation string `json:"operation"`
+	// Digest identifies the cluster and instance group specs the plan was made against
+	Digest string `json:"digest"`
+	// Summary describes what the operation will do, for the approver to review
+	Summary string `json:"summary,omitempty"`
+	// Command is the command line that requested the plan
+	Command string `json:"command,omitempty"`
+
+	// RequestedBy is the user who requested the plan
+	RequestedBy string `json:"requestedBy"`
+	// RequestedAt is the time the plan was requested
+	RequestedAt time.Time `json:"requestedAt"`
+	// ApprovedBy is the user who approved the plan, if it has been approved
+	ApprovedBy string `json:"approvedBy,omitempty"`
+	// ApprovedAt is the time the plan was approved
+	ApprovedAt *time.Time `json:"approvedAt,omitempty"`
+	// ApprovalProof is the evidence of who approved the plan, verified before the plan is executed
+	ApprovalProof string `json:"approvalProof,omitempty"`
+	// ExecutedAt is the time the plan was executed; a plan can only be executed once
+	ExecutedAt *time.Time `json:"executedAt,omitempty"`
+
+	// Signature is the HMAC of the rest of the plan, keyed with the cluster's approval secret
+	Signature string `json:"signature"`
+}
+
+// IsApproved is true if the plan has been approved and not yet executed
+func (p *Plan) IsApproved() bool {
+	return p.ApprovedBy != "" && p.ExecutedAt == nil
+}
+
+// Store holds the plans of a cluster in the state store.
+// Plans are signed, so that a plan edited outside of kops is rejected; but as the key is in the state store, only
+// the identity establishes who approved a plan.
+type Store struct {
+	cluster  string
+	dir      vfs.Path
+	key      []byte
+	identity Identity
+}
+
+// NewStore returns the plan store for the cluster in the state store at base, signing plans with key and
+// identifying users with identity
+func NewStore(base vfs.Path, cluster string, key []byte, identity Identity) *Store {
+	return &Store{
+		cluster:  cluster,
+		dir:      base.Join(StateStoreDir, cluster),
+		key:      key,
+		identity: identity,
+	}
+}
+
+// Digest identifies the specs of a cluster and its instance groups, so an approval only applies to the specs it was given for
+func Digest(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) (string, error) {
+	h := sha256.New()
+
+	y, err := kopscodecs.ToVersionedYaml(cluster)
+	if err != nil {
+		return "", fmt.Errorf("error serializing cluster: %v", err)
+	}
+	h.Write(y)
+
+	sorted := make([]*kops.InstanceGroup, len(instanceGroups))
+	copy(sorted, instanceGroups)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ObjectMeta.Name < sorted[j].ObjectMeta.Name
+	})
+	for _, ig := range sorted {
+		y, err := kopscodecs.ToVersionedYaml(ig)
+		if err != nil {
+			return "", fmt.Errorf("error serializing instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+		h.Write([]byte("\n---\n"))
+		h.Write(y)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Request creates a new plan, which must then be approved by a user other than the caller
+func (s *Store) Request(operation string, digest string, summary string) (*Plan, error) {
+	user, err := s.identity.Caller()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{
+		ID:          id,
+		Cluster:     s.cluster,
+		Operation:   operation,
+		Digest:      digest,
+		Summary:     summary,
+		Command:     strings.Join(os.Args, " "),
+		RequestedBy: user,
+		RequestedAt: time.Now().UTC(),
+	}
+
+	data, err := s.sign(p)
+	if err != nil {
+		return nil, err
+	}
+	path := s.dir.Join(p.ID + ".json")
+	if err := path.CreateFile(bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("error writing plan %s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Get returns the plan with the given id, verifying its signature
+func (s *Store) Get(id string) (*Plan, error) {
+	if id == "" || strings.ContainsAny(id, "/.") {
+		return nil, fmt.Errorf("invalid plan id %q", id)
+	}
+	return s.read(s.dir.Join(id + ".json"))
+}
+
+// Approve records that the caller approved the plan with the given id, with the proof of their identity.
+// The user who requested a plan cannot approve it.
+func (s *Store) Approve(id string) (*Plan, error) {
+	p, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.ExecutedAt != nil {
+		return nil, fmt.Errorf("plan %s has already been executed", id)
+	}
+	if p.ApprovedBy != "" {
+		return nil, fmt.Errorf("plan %s has already been approved by %s", id, p.ApprovedBy)
+	}
+	user, err := s.identity.Caller()
+	if err != nil {
+		return nil, err
+	}
+	if user == p.RequestedBy {
+		return nil, fmt.Errorf("plan %s was requested by %s, and must be approved by a different user", id, p.RequestedBy)
+	}
+
+	now := time.Now().UTC()
+	p.ApprovedBy = user
+	p.ApprovedAt = &now
+	p.ApprovalProof, err = s.identity.Prove(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.write(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindApproved returns the most recently approved plan for the operation against the specs identified by digest,
+// or nil if there is none.  Plans with an invalid signature, whose approval cannot be verified, or which were
+// approved by the caller are ignored.
+func (s *Store) FindApproved(operation string, digest string) (*Plan, error) {
+	paths, err := s.dir.ReadDir()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing plans in %s: %v", s.dir, err)
+	}
+
+	var found *Plan
+	for _, path := range paths {
+		if !strings.HasSuffix(path.Base(), ".json") {
+			continue
+		}
+		p, err := s.read(path)
+		if err != nil {
+			glog.Warningf("ignoring plan %s: %v", path, err)
+			continue
+		}
+		if p.Operation != operation || p.Digest != digest || !p.IsApproved() {
+			continue
+		}
+		if found != nil && !p.ApprovedAt.After(*found.ApprovedAt) {
+			continue
+		}
+		if err := s.verifyApproval(p); err != nil {
+			glog.Warningf("ignoring plan %s: %v", path, err)
+			continue
+		}
+		found = p
+	}
+	return found, nil
+}
+
+// verifyApproval checks the plan was approved by the user it names, who is not the caller
+func (s *Store) verifyApproval(p *Plan) error {
+	approver, err := s.identity.Verify(p)
+	if err != nil {
+		return err
+	}
+	if approver != p.ApprovedBy {
+		return fmt.Errorf("plan claims to be approved by %s, but was approved by %s", p.ApprovedBy, approver)
+	}
+	user, err := s.identity.Caller()
+	if err != nil {
+		return err
+	}
+	if approver == user {
+		return fmt.Errorf("plan was approved by %s, who cannot also execute it", approver)
+	}
+	return nil
+}
+
+// MarkExecuted records that the plan has been executed, so it cannot be executed again
+func (s *Store) MarkExecuted(p *Plan) error {
+	now := time.Now().UTC()
+	p.ExecutedAt = &now
+	return s.write(p)
+}
+
+func (s *Store) read(path vfs.Path) (*Plan, error) {
+	data, err := path.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("plan %q not found", strings.TrimSuffix(path.Base(), ".json"))
+		}
+		return nil, fmt.Errorf("error reading plan %s: %v", path, err)
+	}
+
+	p := &Plan{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("error parsing plan %s: %v", path, err)
+	}
+
+	expected, err := s.signature(p)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := hex.DecodeString(p.Signature)
+	if err != nil || !hmac.Equal(actual, expected) {
+		return nil, fmt.Errorf("plan %s has an invalid signature", path)
+	}
+	if p.Cluster != s.cluster {
+		return nil, fmt.Errorf("plan %s is for cluster %q, not %q", path, p.Cluster, s.cluster)
+	}
+	return p, nil
+}
+
+func (s *Store) write(p *Plan) error {
+	data, err := s.sign(p)
+	if err != nil {
+		return err
+	}
+	path := s.dir.Join(p.ID + ".json")
+	if err := path.WriteFile(bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("error writing plan %s: %v", path, err)
+	}
+	return nil
+}
+
+// sign sets the signature of the plan, returning its serialized form
+func (s *Store) sign(p *Plan) ([]byte, error) {
+	signature, err := s.signature(p)
+	if err != nil {
+		return nil, err
+	}
+	p.Signature = hex.EncodeToString(signature)
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error serializing plan: %v", err)
+	}
+	return data, nil
+}
+
+// signature computes the HMAC of the plan, excluding its signature
+func (s *Store) signature(p *Plan) ([]byte, error) {
+	if len(s.key) == 0 {
+		return nil, fmt.Errorf("no key to sign plans")
+	}
+	unsigned := *p
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing plan: %v", err)
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// newID returns a plan id that sorts by creation time
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating plan id: %v", err)
+	}
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b), nil
+}
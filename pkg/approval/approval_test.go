@@ -0,0 +1,177 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// fakeIdentity identifies the caller as user, proving approvals by naming the approver
+type fakeIdentity struct {
+	user string
+}
+
+func (f *fakeIdentity) Caller() (string, error) {
+	return f.user, nil
+}
+
+func (f *fakeIdentity) Prove(p *Plan) (string, error) {
+	return "approved-by:" + f.user, nil
+}
+
+func (f *fakeIdentity) Verify(p *Plan) (string, error) {
+	if !strings.HasPrefix(p.ApprovalProof, "approved-by:") {
+		return "", fmt.Errorf("invalid proof %q", p.ApprovalProof)
+	}
+	return strings.TrimPrefix(p.ApprovalProof, "approved-by:"), nil
+}
+
+func TestApprovalWorkflow(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	identity := &fakeIdentity{user: "alice"}
+	store := NewStore(base, "example.com", []byte("secret"), identity)
+
+	found, err := store.FindApproved(OperationUpdateCluster, "digest1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found != nil {
+		t.Fatalf("expected no approved plan before any plan was requested")
+	}
+
+	plan, err := store.Request(OperationUpdateCluster, "digest1", "changes")
+	if err != nil {
+		t.Fatalf("error requesting plan: %v", err)
+	}
+
+	if found, _ := store.FindApproved(OperationUpdateCluster, "digest1"); found != nil {
+		t.Fatalf("expected unapproved plan not to be found")
+	}
+
+	if _, err := store.Approve(plan.ID); err == nil {
+		t.Fatalf("expected the requester not to be able to approve their own plan")
+	}
+
+	identity.user = "bob"
+	if _, err := store.Approve(plan.ID); err != nil {
+		t.Fatalf("error approving plan: %v", err)
+	}
+	if found, _ := store.FindApproved(OperationUpdateCluster, "digest1"); found != nil {
+		t.Errorf("expected the approver not to be able to execute the plan")
+	}
+
+	identity.user = "carol"
+	if _, err := store.Approve(plan.ID); err == nil {
+		t.Fatalf("expected a plan not to be approved twice")
+	}
+
+	identity.user = "alice"
+
+	if found, _ := store.FindApproved(OperationDeleteCluster, "digest1"); found != nil {
+		t.Errorf("expected approval not to apply to another operation")
+	}
+	if found, _ := store.FindApproved(OperationUpdateCluster, "digest2"); found != nil {
+		t.Errorf("expected approval not to apply to other specs")
+	}
+
+	found, err = store.FindApproved(OperationUpdateCluster, "digest1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found == nil || found.ID != plan.ID || found.ApprovedBy != "bob" {
+		t.Fatalf("expected plan %s approved by bob, got %+v", plan.ID, found)
+	}
+
+	if err := store.MarkExecuted(found); err != nil {
+		t.Fatalf("error marking plan executed: %v", err)
+	}
+	if found, _ := store.FindApproved(OperationUpdateCluster, "digest1"); found != nil {
+		t.Errorf("expected an executed plan not to be found again")
+	}
+}
+
+func TestTamperedPlanRejected(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	identity := &fakeIdentity{user: "alice"}
+	store := NewStore(base, "example.com", []byte("secret"), identity)
+
+	plan, err := store.Request(OperationDeleteCluster, "digest1", "")
+	if err != nil {
+		t.Fatalf("error requesting plan: %v", err)
+	}
+
+	path := base.Join(StateStoreDir, "example.com", plan.ID+".json")
+	data, err := path.ReadFile()
+	if err != nil {
+		t.Fatalf("error reading plan: %v", err)
+	}
+	tampered := strings.Replace(string(data), `"digest1"`, `"digest2"`, 1)
+	if err := path.WriteFile(bytes.NewReader([]byte(tampered)), nil); err != nil {
+		t.Fatalf("error writing plan: %v", err)
+	}
+
+	if _, err := store.Get(plan.ID); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("expected invalid signature error, got %v", err)
+	}
+	identity.user = "bob"
+	if _, err := store.Approve(plan.ID); err == nil {
+		t.Fatalf("expected a tampered plan not to be approved")
+	}
+
+	other := NewStore(base, "example.com", []byte("other"), identity)
+	if _, err := other.Get(plan.ID); err == nil {
+		t.Fatalf("expected a plan signed with another key to be rejected")
+	}
+}
+
+func TestForgedApprovalRejected(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	identity := &fakeIdentity{user: "alice"}
+	store := NewStore(base, "example.com", []byte("secret"), identity)
+
+	plan, err := store.Request(OperationUpdateCluster, "digest1", "")
+	if err != nil {
+		t.Fatalf("error requesting plan: %v", err)
+	}
+
+	// the requester records an approval in another name, re-signing the plan with the key from the state store
+	now := time.Now().UTC()
+	plan.ApprovedBy = "bob"
+	plan.ApprovedAt = &now
+	plan.ApprovalProof = "approved-by:alice"
+	if err := store.write(plan); err != nil {
+		t.Fatalf("error writing plan: %v", err)
+	}
+
+	if found, err := store.FindApproved(OperationUpdateCluster, "digest1"); err != nil || found != nil {
+		t.Errorf("expected a forged approval to be ignored, got %v, %v", found, err)
+	}
+
+	plan.ApprovalProof = ""
+	if err := store.write(plan); err != nil {
+		t.Fatalf("error writing plan: %v", err)
+	}
+	if found, err := store.FindApproved(OperationUpdateCluster, "digest1"); err != nil || found != nil {
+		t.Errorf("expected an approval without proof to be ignored, got %v, %v", found, err)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"k8s.io/kops/pkg/retrypolicy"
+)
+
+const (
+	// ApprovalLifetime is how long an approval on AWS can be used for; it also ends when the credentials the
+	// approver used expire
+	ApprovalLifetime = 24 * time.Hour
+
+	// awsApprovalHeader binds the presigned request proving an approval to the plan it approves
+	awsApprovalHeader = "X-Kops-Approval"
+)
+
+// stsHostPattern matches the hosts of the STS endpoints; an approval is only accepted from AWS itself
+var stsHostPattern = regexp.MustCompile(`^sts(\.[a-z0-9-]+)?\.amazonaws\.com(\.cn)?$`)
+
+// AWSIdentity identifies users by the IAM principal of their AWS credentials, as reported by sts:GetCallerIdentity.
+// An approval is proven by a sts:GetCallerIdentity request presigned by the approver and bound to the plan, which
+// only the approver's credentials can produce; it is replayed to STS before the plan is executed, to learn who
+// signed it.  The users sharing an IAM role are the same principal, whatever the names of their sessions.
+type AWSIdentity struct {
+	client     *sts.STS
+	httpClient *http.Client
+}
+
+var _ Identity = &AWSIdentity{}
+
+// NewAWSIdentity returns an AWSIdentity using the credentials of the environment, in the region.  These are the
+// operator's own credentials, rather than any role kops assumes for the cluster, which every operator shares.
+func NewAWSIdentity(region string) (*AWSIdentity, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, fmt.Errorf("error starting a new AWS session: %v", err)
+	}
+
+	return &AWSIdentity{
+		client: sts.New(sess),
+		httpClient: &http.Client{
+			Transport: retrypolicy.WrapTransport(http.DefaultTransport),
+			Timeout:   retrypolicy.Current().Timeout,
+		},
+	}, nil
+}
+
+// Caller returns the IAM principal of the credentials of the environment
+func (a *AWSIdentity) Caller() (string, error) {
+	response, err := a.client.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("error getting caller identity: %v", err)
+	}
+	return awsPrincipal(aws.StringValue(response.Arn)), nil
+}
+
+// Prove presigns a sts:GetCallerIdentity request bound to the plan
+func (a *AWSIdentity) Prove(p *Plan) (string, error) {
+	request, _ := a.client.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	request.HTTPRequest.Header.Set(awsApprovalHeader, awsApprovalBinding(p))
+	proof, err := request.Presign(ApprovalLifetime)
+	if err != nil {
+		return "", fmt.Errorf("error signing approval: %v", err)
+	}
+	return proof, nil
+}
+
+// Verify replays the presigned request recorded with the approval to STS, returning the principal which signed it
+func (a *AWSIdentity) Verify(p *Plan) (string, error) {
+	if err := checkAWSApprovalProof(p.ApprovalProof); err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequest(http.MethodGet, p.ApprovalProof, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid approval proof: %v", err)
+	}
+	request.Header.Set(awsApprovalHeader, awsApprovalBinding(p))
+
+	response, err := a.httpClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("error verifying approval: %v", err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("error verifying approval: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("STS rejected the approval, which may have expired: %s", strings.TrimSpace(string(body)))
+	}
+
+	result := &struct {
+		Arn string `xml:"GetCallerIdentityResult>Arn"`
+	}{}
+	if err := xml.Unmarshal(body, result); err != nil || result.Arn == "" {
+		return "", fmt.Errorf("unexpected response verifying approval: %q", string(body))
+	}
+	return awsPrincipal(result.Arn), nil
+}
+
+// checkAWSApprovalProof checks the proof is a request to STS itself, for the caller identity, bound to the plan; a
+// request to any other endpoint could return any identity
+func checkAWSApprovalProof(proof string) error {
+	if proof == "" {
+		return fmt.Errorf("the approval has no proof of the approver's identity")
+	}
+	u, err := url.Parse(proof)
+	if err != nil {
+		return fmt.Errorf("invalid approval proof: %v", err)
+	}
+	if u.Scheme != "https" || !stsHostPattern.MatchString(u.Host) {
+		return fmt.Errorf("approval proof %q is not a request to STS", u.Host)
+	}
+	query := u.Query()
+	if query.Get("Action") != "GetCallerIdentity" {
+		return fmt.Errorf("approval proof is not a GetCallerIdentity request")
+	}
+	signed := strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	for _, header := range signed {
+		if header == strings.ToLower(awsApprovalHeader) {
+			return nil
+		}
+	}
+	return fmt.Errorf("approval proof is not bound to the plan")
+}
+
+// awsApprovalBinding identifies what an approval applies to
+func awsApprovalBinding(p *Plan) string {
+	return p.Cluster + "/" + p.ID + "/" + p.Operation + "/" + p.Digest
+}
+
+// awsPrincipal returns the principal of an ARN returned by sts:GetCallerIdentity: the session name of an assumed
+// role is chosen by whoever assumes it, so an assumed role session is mapped to its role
+func awsPrincipal(arn string) string {
+	tokens := strings.SplitN(arn, ":", 6)
+	if len(tokens) != 6 || tokens[2] != "sts" || !strings.HasPrefix(tokens[5], "assumed-role/") {
+		return arn
+	}
+	role := strings.Split(strings.TrimPrefix(tokens[5], "assumed-role/"), "/")[0]
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", tokens[1], tokens[4], role)
+}
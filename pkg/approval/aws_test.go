@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+func TestAWSPrincipal(t *testing.T) {
+	grid := map[string]string{
+		"arn:aws:iam::123456789012:user/alice":                      "arn:aws:iam::123456789012:user/alice",
+		"arn:aws:sts::123456789012:assumed-role/admin/alice":        "arn:aws:iam::123456789012:role/admin",
+		"arn:aws:sts::123456789012:assumed-role/admin/someone-else": "arn:aws:iam::123456789012:role/admin",
+		"arn:aws-cn:sts::123456789012:assumed-role/admin/alice":     "arn:aws-cn:iam::123456789012:role/admin",
+		"arn:aws:iam::123456789012:root":                            "arn:aws:iam::123456789012:root",
+	}
+	for arn, expected := range grid {
+		if actual := awsPrincipal(arn); actual != expected {
+			t.Errorf("%s: expected %q, got %q", arn, expected, actual)
+		}
+	}
+}
+
+func TestAWSIdentityProve(t *testing.T) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	})
+	if err != nil {
+		t.Fatalf("error creating session: %v", err)
+	}
+	a := &AWSIdentity{client: sts.New(sess)}
+
+	plan := &Plan{ID: "20180601-120000-1a2b3c4d", Cluster: "example.com", Operation: OperationUpdateCluster, Digest: "digest1"}
+	proof, err := a.Prove(plan)
+	if err != nil {
+		t.Fatalf("error proving approval: %v", err)
+	}
+	if err := checkAWSApprovalProof(proof); err != nil {
+		t.Errorf("expected proof %q to be accepted: %v", proof, err)
+	}
+	if !strings.Contains(proof, "X-Amz-Credential=AKID") {
+		t.Errorf("expected proof to be signed with the caller's credentials, got %q", proof)
+	}
+}
+
+func TestCheckAWSApprovalProof(t *testing.T) {
+	grid := []struct {
+		Proof string
+		Error string
+	}{
+		{Proof: "", Error: "no proof"},
+		{Proof: "https://attacker.example.com/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host%3Bx-kops-approval", Error: "not a request to STS"},
+		{Proof: "https://sts.amazonaws.com.example.com/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host%3Bx-kops-approval", Error: "not a request to STS"},
+		{Proof: "http://sts.amazonaws.com/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host%3Bx-kops-approval", Error: "not a request to STS"},
+		{Proof: "https://sts.amazonaws.com/?Action=AssumeRole&X-Amz-SignedHeaders=host%3Bx-kops-approval", Error: "not a GetCallerIdentity request"},
+		{Proof: "https://sts.amazonaws.com/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host", Error: "not bound to the plan"},
+		{Proof: "https://sts.amazonaws.com/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host%3Bx-kops-approval"},
+		{Proof: "https://sts.eu-west-1.amazonaws.com/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host%3Bx-kops-approval"},
+		{Proof: "https://sts.cn-north-1.amazonaws.com.cn/?Action=GetCallerIdentity&X-Amz-SignedHeaders=host%3Bx-kops-approval"},
+	}
+	for _, g := range grid {
+		err := checkAWSApprovalProof(g.Proof)
+		if g.Error == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", g.Proof, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), g.Error) {
+			t.Errorf("%q: expected error containing %q, got %v", g.Proof, g.Error, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"fmt"
+)
+
+// Identity establishes who requests, approves and executes plans
+type Identity interface {
+	// Caller returns the identity of the user running kops
+	Caller() (string, error)
+	// Prove returns evidence that the caller approved the plan, recorded with the approval
+	Prove(p *Plan) (string, error)
+	// Verify checks the evidence recorded with the approval of the plan, returning the identity of the approver
+	Verify(p *Plan) (string, error)
+}
+
+// UserIdentity identifies users by a name which is not verified, such as the name of the local user.
+// Approvals are not proven, so anyone able to write the state store can record an approval in any name.
+type UserIdentity struct {
+	// Name is the name of the user running kops
+	Name string
+}
+
+var _ Identity = &UserIdentity{}
+
+// Caller returns the name of the user
+func (u *UserIdentity) Caller() (string, error) {
+	if u.Name == "" {
+		return "", fmt.Errorf("unable to determine the current user")
+	}
+	return u.Name, nil
+}
+
+// Prove returns no evidence, as there is none for a name
+func (u *UserIdentity) Prove(p *Plan) (string, error) {
+	return "", nil
+}
+
+// Verify accepts the approver recorded in the plan
+func (u *UserIdentity) Verify(p *Plan) (string, error) {
+	return p.ApprovedBy, nil
+}
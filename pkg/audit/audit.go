@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// Operations recorded in the audit log
+const (
+	OperationCreate        = "create"
+	OperationEdit          = "edit"
+	OperationReplace       = "replace"
+	OperationUpdate        = "update"
+	OperationRollingUpdate = "rolling-update"
+	OperationDelete        = "delete"
+	OperationPatchNodes    = "patch-nodes"
+	OperationRollback      = "rollback"
+	OperationEnroll        = "enroll"
+	OperationSet           = "set"
+	OperationUpgrade       = "upgrade"
+	OperationScale         = "scale"
+	OperationPause         = "pause"
+	OperationResume        = "resume"
+	OperationStop          = "stop"
+	OperationStart         = "start"
+	OperationRotate        = "rotate"
+)
+
+// StateStoreDir is the directory of the state store holding the audit log, one subdirectory per cluster.
+// It lives outside the cluster directories so that the log survives the deletion of a cluster; the
+// state store does not list it as a cluster.
+const StateStoreDir = "audit"
+
+// timestampFormat sorts lexically in chronological order
+const timestampFormat = "20060102T150405.000000000Z"
+
+// Entry records a single mutating operation
+type Entry struct {
+	// Timestamp is the time the operation was recorded
+	Timestamp time.Time `json:"timestamp"`
+	// User is the local user who ran the operation
+	User string `json:"user,omitempty"`
+	// Host is the machine the operation was run from
+	Host string `json:"host,omitempty"`
+	// Cluster is the name of the cluster the operation applied to
+	Cluster string `json:"cluster"`
+	// Operation is one of the Operation* values
+	Operation string `json:"operation"`
+	// Object describes what the operation applied to, e.g. cluster or instancegroup/nodes
+	Object string `json:"object,omitempty"`
+	// Command is the full command line
+	Command string `json:"command,omitempty"`
+	// Diff is the change made to the spec, when known
+	Diff string `json:"diff,omitempty"`
+	// Previous is the YAML of the object before the operation, when known, so that the change can be reverted
+	Previous string `json:"previous,omitempty"`
+}
+
+// Log is the append-only audit log of a state store
+type Log struct {
+	base vfs.Path
+}
+
+// NewLog returns the audit log of the state store at base
+func NewLog(base vfs.Path) *Log {
+	return &Log{base: base}
+}
+
+// Record appends e to the log, filling in the timestamp, user, host and command if they are not set.
+// Each entry is written to a new file, so that concurrent writers never overwrite each other.
+func (l *Log) Record(e *Entry) error {
+	if e.Cluster == "" {
+		return fmt.Errorf("cluster is required for audit log entries")
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	e.Timestamp = e.Timestamp.UTC()
+	if e.User == "" {
+		e.User = CurrentUser()
+	}
+	if e.Host == "" {
+		e.Host, _ = os.Hostname()
+	}
+	if e.Command == "" {
+		e.Command = strings.Join(os.Args, " ")
+	}
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing audit log entry: %v", err)
+	}
+
+	name := e.Timestamp.Format(timestampFormat) + "-" + e.Operation + ".json"
+	p := l.base.Join(StateStoreDir, e.Cluster, name)
+	if err := p.CreateFile(bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("error writing audit log entry %s: %v", p, err)
+	}
+	return nil
+}
+
+// List returns the entries recorded for the cluster, or for all clusters if cluster is empty, oldest first
+func (l *Log) List(cluster string) ([]*Entry, error) {
+	dir := l.base.Join(StateStoreDir)
+	if cluster != "" {
+		dir = dir.Join(cluster)
+	}
+
+	paths, err := dir.ReadTree()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing audit log in %s: %v", dir, err)
+	}
+
+	var entries []*Entry
+	for _, p := range paths {
+		if !strings.HasSuffix(p.Base(), ".json") {
+			continue
+		}
+		data, err := p.ReadFile()
+		if err != nil {
+			return nil, fmt.Errorf("error reading audit log entry %s: %v", p, err)
+		}
+		e := &Entry{}
+		if err := json.Unmarshal(data, e); err != nil {
+			return nil, fmt.Errorf("error parsing audit log entry %s: %v", p, err)
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// currentUserOverride is reported by CurrentUser instead of the local user, when set
+var currentUserOverride string
+
+// SetCurrentUser makes CurrentUser report name instead of the local user, or the local user again if name is empty.
+// kops server sets it to the authenticated caller of each request.
+func SetCurrentUser(name string) {
+	currentUserOverride = name
+}
+
+// CurrentUser returns the name of the user running kops
+func CurrentUser() string {
+	if currentUserOverride != "" {
+		return currentUserOverride
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
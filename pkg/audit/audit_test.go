@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestRecordAndList(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	log := NewLog(base)
+
+	start := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	records := []*Entry{
+		{Timestamp: start.Add(2 * time.Minute), Cluster: "a.example.com", Operation: OperationEdit, Object: "cluster", Diff: "-  foo\n+  bar\n"},
+		{Timestamp: start, Cluster: "a.example.com", Operation: OperationCreate, Object: "cluster"},
+		{Timestamp: start.Add(time.Minute), Cluster: "b.example.com", Operation: OperationDelete, Object: "cluster", User: "alice"},
+	}
+	for _, e := range records {
+		if err := log.Record(e); err != nil {
+			t.Fatalf("error recording entry: %v", err)
+		}
+	}
+
+	all, err := log.List("")
+	if err != nil {
+		t.Fatalf("error listing entries: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+	expected := []string{OperationCreate, OperationDelete, OperationEdit}
+	for i, e := range all {
+		if e.Operation != expected[i] {
+			t.Errorf("entry %d: expected operation %q, got %q", i, expected[i], e.Operation)
+		}
+		if e.Command == "" {
+			t.Errorf("entry %d: expected the command to be recorded", i)
+		}
+	}
+	if all[1].User != "alice" {
+		t.Errorf("expected the user to be kept, got %q", all[1].User)
+	}
+
+	entries, err := log.List("a.example.com")
+	if err != nil {
+		t.Fatalf("error listing entries: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Diff != "-  foo\n+  bar\n" {
+		t.Errorf("unexpected entries for a.example.com: %v", entries)
+	}
+
+	entries, err = log.List("c.example.com")
+	if err != nil {
+		t.Fatalf("error listing entries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries for c.example.com, got %d", len(entries))
+	}
+
+	if err := log.Record(&Entry{Operation: OperationUpdate}); err == nil {
+		t.Errorf("expected an error recording an entry without a cluster")
+	}
+}
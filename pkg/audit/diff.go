@@ -0,0 +1,64 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/pkg/diff"
+	"k8s.io/kops/pkg/kopscodecs"
+)
+
+// Diff returns the difference between the YAML of two versions of an object, for an Entry; either may be nil
+func Diff(before runtime.Object, after runtime.Object) (string, error) {
+	beforeYaml, err := diffYaml(before)
+	if err != nil {
+		return "", err
+	}
+	afterYaml, err := diffYaml(after)
+	if err != nil {
+		return "", err
+	}
+
+	if beforeYaml == afterYaml {
+		return "", nil
+	}
+	return diff.FormatDiff(beforeYaml, afterYaml), nil
+}
+
+// Snapshot returns the YAML of o to record as the Previous version in an Entry, or "" if o is nil
+func Snapshot(o runtime.Object) (string, error) {
+	return diffYaml(o)
+}
+
+// diffYaml serializes o without its resourceVersion, which changes on every write
+func diffYaml(o runtime.Object) (string, error) {
+	if o == nil {
+		return "", nil
+	}
+
+	o = o.DeepCopyObject()
+	if objectMeta, err := meta.Accessor(o); err == nil {
+		objectMeta.SetResourceVersion("")
+	}
+
+	y, err := kopscodecs.ToVersionedYaml(o)
+	if err != nil {
+		return "", err
+	}
+	return string(y), nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// reportsDir is the directory of the cluster config base holding the boot reports, one subdirectory per instance group
+const reportsDir = "bootreports"
+
+// Report records how long nodeup took to bootstrap an instance
+type Report struct {
+	// Hostname is the hostname of the instance
+	Hostname string `json:"hostname"`
+	// InstanceGroup is the name of the instance group of the instance
+	InstanceGroup string `json:"instanceGroup"`
+	// BootTime is when the instance booted, if known
+	BootTime *metav1.Time `json:"bootTime,omitempty"`
+	// StartTime is when nodeup started
+	StartTime metav1.Time `json:"startTime"`
+	// EndTime is when nodeup finished running its tasks
+	EndTime metav1.Time `json:"endTime"`
+	// Failed is true if nodeup did not complete all of its tasks
+	Failed bool `json:"failed,omitempty"`
+	// Tasks are the timings of each task, in the order they were first attempted
+	Tasks []*TaskTiming `json:"tasks,omitempty"`
+}
+
+// TaskTiming records how long a task took
+type TaskTiming struct {
+	// Key identifies the task, e.g. Package/docker-ce
+	Key string `json:"key"`
+	// StartTime is when the task was first attempted
+	StartTime metav1.Time `json:"startTime"`
+	// Duration is the total time spent running the task, across all attempts
+	Duration metav1.Duration `json:"duration"`
+	// Attempts is the number of times the task was run
+	Attempts int `json:"attempts"`
+	// Failures is the number of attempts that returned an error
+	Failures int `json:"failures,omitempty"`
+}
+
+// Duration returns how long nodeup ran for
+func (r *Report) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime.Time)
+}
+
+// StartDelay returns the time between the instance booting and nodeup starting, or 0 if the boot time is not known
+func (r *Report) StartDelay() time.Duration {
+	if r.BootTime == nil {
+		return 0
+	}
+	return r.StartTime.Sub(r.BootTime.Time)
+}
+
+// Recorder collects task timings while nodeup runs; it implements fi.TaskTimingRecorder
+type Recorder struct {
+	mutex sync.Mutex
+	tasks map[string]*TaskTiming
+}
+
+// NewRecorder returns an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{tasks: make(map[string]*TaskTiming)}
+}
+
+// RecordTask records an attempt to run the task with the given key
+func (r *Recorder) RecordTask(key string, start time.Time, duration time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t := r.tasks[key]
+	if t == nil {
+		t = &TaskTiming{Key: key, StartTime: metav1.NewTime(start)}
+		r.tasks[key] = t
+	}
+	t.Attempts++
+	t.Duration.Duration += duration
+	if err != nil {
+		t.Failures++
+	}
+}
+
+// Tasks returns the recorded task timings, in the order they were first attempted
+func (r *Recorder) Tasks() []*TaskTiming {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var tasks []*TaskTiming
+	for _, t := range r.tasks {
+		c := *t
+		tasks = append(tasks, &c)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].StartTime.Equal(&tasks[j].StartTime) {
+			return tasks[i].StartTime.Before(&tasks[j].StartTime)
+		}
+		return tasks[i].Key < tasks[j].Key
+	})
+	return tasks
+}
+
+// Slowest returns the n tasks that took the longest, slowest first
+func Slowest(tasks []*TaskTiming, n int) []*TaskTiming {
+	sorted := append([]*TaskTiming(nil), tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration.Duration > sorted[j].Duration.Duration
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// BootTime returns when the machine booted, from /proc/uptime
+func BootTime(now time.Time) (time.Time, error) {
+	data, err := ioutil.ReadFile("/proc/uptime")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error reading /proc/uptime: %v", err)
+	}
+	return parseUptime(string(data), now)
+}
+
+// parseUptime returns the boot time given the contents of /proc/uptime, e.g. "350735.47 234388.90"
+func parseUptime(uptime string, now time.Time) (time.Time, error) {
+	fields := strings.Fields(uptime)
+	if len(fields) == 0 {
+		return time.Time{}, fmt.Errorf("unexpected contents of /proc/uptime: %q", uptime)
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected contents of /proc/uptime: %q", uptime)
+	}
+	return now.Add(-time.Duration(seconds * float64(time.Second))), nil
+}
+
+// Path returns the directory of the cluster config base holding the boot reports
+func Path(configBase vfs.Path) vfs.Path {
+	return configBase.Join(reportsDir)
+}
+
+// Write stores the report in the cluster config base, replacing any earlier report of the same instance
+func Write(configBase vfs.Path, r *Report) error {
+	if r.InstanceGroup == "" || r.Hostname == "" {
+		return fmt.Errorf("instance group and hostname are required for boot reports")
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing boot report: %v", err)
+	}
+
+	p := Path(configBase).Join(r.InstanceGroup, r.Hostname+".json")
+	if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("error writing boot report %s: %v", p, err)
+	}
+	return nil
+}
+
+// List returns the boot reports stored in the cluster config base, oldest first
+func List(configBase vfs.Path) ([]*Report, error) {
+	dir := Path(configBase)
+
+	paths, err := dir.ReadTree()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing boot reports in %s: %v", dir, err)
+	}
+
+	var reports []*Report
+	for _, p := range paths {
+		if !strings.HasSuffix(p.Base(), ".json") {
+			continue
+		}
+		data, err := p.ReadFile()
+		if err != nil {
+			return nil, fmt.Errorf("error reading boot report %s: %v", p, err)
+		}
+		r := &Report{}
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, fmt.Errorf("error parsing boot report %s: %v", p, err)
+		}
+		reports = append(reports, r)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].StartTime.Before(&reports[j].StartTime)
+	})
+	return reports, nil
+}
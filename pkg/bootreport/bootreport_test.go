@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootreport
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+
+	start := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	r.RecordTask("Service/kubelet.service", start.Add(time.Minute), 2*time.Second, nil)
+	r.RecordTask("Package/docker-ce", start, 30*time.Second, fmt.Errorf("dpkg lock held"))
+	r.RecordTask("Package/docker-ce", start.Add(40*time.Second), 20*time.Second, nil)
+
+	tasks := r.Tasks()
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	docker := tasks[0]
+	if docker.Key != "Package/docker-ce" {
+		t.Fatalf("expected tasks in the order they were first attempted, got %q first", docker.Key)
+	}
+	if docker.Attempts != 2 || docker.Failures != 1 || docker.Duration.Duration != 50*time.Second {
+		t.Errorf("unexpected timing for docker: %+v", docker)
+	}
+
+	slowest := Slowest(tasks, 1)
+	if len(slowest) != 1 || slowest[0].Key != "Package/docker-ce" {
+		t.Errorf("unexpected slowest tasks: %v", slowest)
+	}
+}
+
+func TestParseUptime(t *testing.T) {
+	now := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	boot, err := parseUptime("90.50 170.25\n", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := now.Add(-90500 * time.Millisecond); !boot.Equal(expected) {
+		t.Errorf("expected boot time %v, got %v", expected, boot)
+	}
+
+	if _, err := parseUptime("", now); err == nil {
+		t.Errorf("expected error parsing empty uptime")
+	}
+}
+
+func TestWriteListAndSummarize(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state/cluster.example.com")
+
+	start := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	report := func(ig string, host string, offset time.Duration, duration time.Duration, docker time.Duration) *Report {
+		boot := metav1.NewTime(start.Add(offset))
+		return &Report{
+			Hostname:      host,
+			InstanceGroup: ig,
+			BootTime:      &boot,
+			StartTime:     metav1.NewTime(start.Add(offset + 30*time.Second)),
+			EndTime:       metav1.NewTime(start.Add(offset + 30*time.Second + duration)),
+			Tasks: []*TaskTiming{
+				{Key: "Package/docker-ce", Duration: metav1.Duration{Duration: docker}, Attempts: 1},
+				{Key: "File//etc/sysctl.d/99-k8s-general.conf", Duration: metav1.Duration{Duration: time.Second}, Attempts: 1},
+			},
+		}
+	}
+
+	for _, r := range []*Report{
+		report("nodes", "ip-172-20-33-1", 2*time.Minute, 90*time.Second, 40*time.Second),
+		report("nodes", "ip-172-20-33-2", 0, 60*time.Second, 20*time.Second),
+		report("nodes", "ip-172-20-33-3", time.Minute, 120*time.Second, 60*time.Second),
+		report("master-us-east-1a", "ip-172-20-40-1", 0, 180*time.Second, 30*time.Second),
+	} {
+		if err := Write(base, r); err != nil {
+			t.Fatalf("error writing report: %v", err)
+		}
+	}
+
+	reports, err := List(base)
+	if err != nil {
+		t.Fatalf("error listing reports: %v", err)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("expected 4 reports, got %d", len(reports))
+	}
+
+	summary := Summarize(reports)
+	if len(summary.InstanceGroups) != 2 {
+		t.Fatalf("expected 2 instance groups, got %d", len(summary.InstanceGroups))
+	}
+	nodes := summary.InstanceGroups[1]
+	if nodes.Name != "nodes" || nodes.Instances != 3 {
+		t.Fatalf("unexpected instance group summary: %+v", nodes)
+	}
+	if nodes.MedianDuration != 90*time.Second || nodes.MaxDuration != 120*time.Second {
+		t.Errorf("unexpected durations for nodes: %+v", nodes)
+	}
+	if nodes.MedianStartDelay != 30*time.Second {
+		t.Errorf("unexpected start delay for nodes: %v", nodes.MedianStartDelay)
+	}
+
+	if len(summary.Tasks) != 2 || summary.Tasks[0].Key != "Package/docker-ce" {
+		t.Fatalf("expected docker to be the slowest task, got %+v", summary.Tasks)
+	}
+	docker := summary.Tasks[0]
+	if docker.Instances != 4 || docker.MedianDuration != 35*time.Second || docker.MaxDuration != 60*time.Second {
+		t.Errorf("unexpected summary for docker: %+v", docker)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootreport
+
+import (
+	"sort"
+	"time"
+)
+
+// InstanceGroupSummary aggregates the boot reports of the instances of an instance group
+type InstanceGroupSummary struct {
+	// Name is the name of the instance group
+	Name string `json:"name"`
+	// Instances is the number of boot reports
+	Instances int `json:"instances"`
+	// Failed is the number of boots in which nodeup did not complete
+	Failed int `json:"failed,omitempty"`
+	// MedianStartDelay is the median time between the instance booting and nodeup starting
+	MedianStartDelay time.Duration `json:"medianStartDelay"`
+	// MedianDuration is the median time nodeup ran for
+	MedianDuration time.Duration `json:"medianDuration"`
+	// MaxDuration is the longest time nodeup ran for
+	MaxDuration time.Duration `json:"maxDuration"`
+}
+
+// TaskSummary aggregates the timings of a task across boot reports
+type TaskSummary struct {
+	// Key identifies the task
+	Key string `json:"key"`
+	// Instances is the number of boot reports that include the task
+	Instances int `json:"instances"`
+	// Failures is the total number of failed attempts to run the task
+	Failures int `json:"failures,omitempty"`
+	// MedianDuration is the median time spent running the task
+	MedianDuration time.Duration `json:"medianDuration"`
+	// MaxDuration is the longest time spent running the task
+	MaxDuration time.Duration `json:"maxDuration"`
+}
+
+// Summary aggregates boot reports, to find the steps of the bootstrap that are slow
+type Summary struct {
+	// InstanceGroups summarizes each instance group, ordered by name
+	InstanceGroups []*InstanceGroupSummary `json:"instanceGroups"`
+	// Tasks summarizes each task, slowest (by median) first
+	Tasks []*TaskSummary `json:"tasks"`
+}
+
+// Summarize aggregates the boot reports
+func Summarize(reports []*Report) *Summary {
+	igDurations := make(map[string][]time.Duration)
+	igStartDelays := make(map[string][]time.Duration)
+	igs := make(map[string]*InstanceGroupSummary)
+
+	taskDurations := make(map[string][]time.Duration)
+	tasks := make(map[string]*TaskSummary)
+
+	for _, r := range reports {
+		ig := igs[r.InstanceGroup]
+		if ig == nil {
+			ig = &InstanceGroupSummary{Name: r.InstanceGroup}
+			igs[r.InstanceGroup] = ig
+		}
+		ig.Instances++
+		if r.Failed {
+			ig.Failed++
+		}
+		igDurations[r.InstanceGroup] = append(igDurations[r.InstanceGroup], r.Duration())
+		if r.BootTime != nil {
+			igStartDelays[r.InstanceGroup] = append(igStartDelays[r.InstanceGroup], r.StartDelay())
+		}
+
+		for _, t := range r.Tasks {
+			s := tasks[t.Key]
+			if s == nil {
+				s = &TaskSummary{Key: t.Key}
+				tasks[t.Key] = s
+			}
+			s.Instances++
+			s.Failures += t.Failures
+			taskDurations[t.Key] = append(taskDurations[t.Key], t.Duration.Duration)
+		}
+	}
+
+	summary := &Summary{}
+	for name, ig := range igs {
+		ig.MedianDuration, ig.MaxDuration = medianAndMax(igDurations[name])
+		ig.MedianStartDelay, _ = medianAndMax(igStartDelays[name])
+		summary.InstanceGroups = append(summary.InstanceGroups, ig)
+	}
+	sort.Slice(summary.InstanceGroups, func(i, j int) bool {
+		return summary.InstanceGroups[i].Name < summary.InstanceGroups[j].Name
+	})
+
+	for key, s := range tasks {
+		s.MedianDuration, s.MaxDuration = medianAndMax(taskDurations[key])
+		summary.Tasks = append(summary.Tasks, s)
+	}
+	sort.Slice(summary.Tasks, func(i, j int) bool {
+		a, b := summary.Tasks[i], summary.Tasks[j]
+		if a.MedianDuration != b.MedianDuration {
+			return a.MedianDuration > b.MedianDuration
+		}
+		return a.Key < b.Key
+	})
+
+	return summary
+}
+
+// medianAndMax returns the median and maximum of the durations, or zero if there are none
+func medianAndMax(durations []time.Duration) (time.Duration, time.Duration) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return median, sorted[n-1]
+}
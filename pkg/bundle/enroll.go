@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/glog"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/upup/pkg/fi/cloudup/baremetal"
+	"k8s.io/kops/upup/pkg/kutil"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// SSHEnroller enrolls bare metal hosts by copying the bundle of their instance group to them over SSH, and running its
+// bootstrap script
+type SSHEnroller struct {
+	Clientset simple.Clientset
+	Cluster   *kops.Cluster
+
+	// SSHIdentity is the private key used to connect to the hosts
+	SSHIdentity string
+}
+
+var _ baremetal.Enroller = &SSHEnroller{}
+
+// Enroll implements baremetal.Enroller
+func (e *SSHEnroller) Enroll(ig *kops.InstanceGroup, host *kops.BareMetalHostSpec) error {
+	builder := Builder{
+		Clientset: e.Clientset,
+	}
+	data, err := builder.Build(e.Cluster, ig)
+	if err != nil {
+		return fmt.Errorf("error building bundle: %v", err)
+	}
+
+	nodeSSH := &kutil.NodeSSH{
+		Hostname: host.Address,
+	}
+	// The host keys of an imaged host are new, so there is nothing to check them against
+	nodeSSH.SSHConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	if ig.Spec.BareMetal != nil {
+		nodeSSH.SSHConfig.User = ig.Spec.BareMetal.SSHUser
+	}
+	if err := kutil.AddSSHIdentity(&nodeSSH.SSHConfig, e.SSHIdentity); err != nil {
+		return err
+	}
+
+	return Install(nodeSSH, data)
+}
+
+// Install copies the files of the bundle to /etc/kubernetes/bootstrap on the machine, and runs the bootstrap script
+func Install(nodeSSH *kutil.NodeSSH, data *Data) error {
+	sshClient, err := nodeSSH.GetSSHClient()
+	if err != nil {
+		return fmt.Errorf("error getting SSH client: %v", err)
+	}
+
+	if err := runSSHCommand(sshClient, "sudo mkdir -p /etc/kubernetes/bootstrap"); err != nil {
+		return err
+	}
+
+	root, err := nodeSSH.Root()
+	if err != nil {
+		return fmt.Errorf("error connecting to nodeSSH: %v", err)
+	}
+	for _, file := range data.Files {
+		sshAcl := &vfs.SSHAcl{
+			Mode: file.Header.FileInfo().Mode(),
+		}
+		p := root.Join("etc", "kubernetes", "bootstrap", file.Header.Name)
+		glog.Infof("writing %s", p)
+		if err := p.WriteFile(bytes.NewReader(file.Data), sshAcl); err != nil {
+			return fmt.Errorf("error writing file %q: %v", file.Header.Name, err)
+		}
+	}
+
+	return runSSHCommand(sshClient, "sudo /etc/kubernetes/bootstrap/bootstrap.sh")
+}
+
+func runSSHCommand(sshClient *ssh.Client, cmd string) error {
+	s, err := sshClient.NewSession()
+	if err != nil {
+		return fmt.Errorf("error creating ssh session: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	s.Stdout = io.MultiWriter(&stdout, os.Stdout)
+	s.Stderr = io.MultiWriter(&stderr, os.Stderr)
+
+	glog.Infof("running %s", cmd)
+	if err := s.Run(cmd); err != nil {
+		return fmt.Errorf("error running %s: %v\nstdout: %s\nstderr: %s", cmd, err, stdout.String(), stderr.String())
+	}
+
+	glog.Infof("stdout: %s", stdout.String())
+	glog.Infof("stderr: %s", stderr.String())
+	return nil
+}
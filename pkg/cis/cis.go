@@ -0,0 +1,550 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/flagbuilder"
+	"k8s.io/kops/util/pkg/reflectutils"
+)
+
+// Results of a benchmark control
+const (
+	StatusPass = "PASS"
+	StatusFail = "FAIL"
+)
+
+// Components checked by the benchmark
+const (
+	ComponentAPIServer         = "kube-apiserver"
+	ComponentControllerManager = "kube-controller-manager"
+	ComponentEtcd              = "etcd"
+	ComponentKubelet           = "kubelet"
+)
+
+// pathSrvKubernetes is where nodeup writes the certificates and keys the master components are configured with
+const pathSrvKubernetes = "/srv/kubernetes"
+
+// Result is the outcome of a single CIS Kubernetes Benchmark control
+type Result struct {
+	// ID is the number of the control in the benchmark, e.g. 1.1.1
+	ID string `json:"id"`
+	// Component is the component the control applies to
+	Component string `json:"component"`
+	// Target is the instance group (for the kubelet) or etcd cluster the control was checked against, if any
+	Target string `json:"target,omitempty"`
+	// Description is the requirement of the control
+	Description string `json:"description"`
+	// Status is StatusPass or StatusFail
+	Status string `json:"status"`
+	// Remediation is how to change the cluster spec to pass the control, if it failed
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Failed returns the results that did not pass
+func Failed(results []*Result) []*Result {
+	var failed []*Result
+	for _, r := range results {
+		if r.Status != StatusPass {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// control is a benchmark control that can be checked from the command line flags of a component
+type control struct {
+	id          string
+	description string
+	remediation string
+	check       func(flags componentFlags) bool
+}
+
+// componentFlags are the command line flags of a component, by name
+type componentFlags map[string]string
+
+func parseFlags(flags []string) componentFlags {
+	f := make(componentFlags)
+	for _, flag := range flags {
+		flag = strings.TrimPrefix(flag, "--")
+		tokens := strings.SplitN(flag, "=", 2)
+		if len(tokens) == 2 {
+			f[tokens[0]] = tokens[1]
+		} else {
+			f[tokens[0]] = "true"
+		}
+	}
+	return f
+}
+
+// value returns the value of the flag, or defaultValue (the default of the component) if it is not set
+func (f componentFlags) value(name string, defaultValue string) string {
+	if v, found := f[name]; found {
+		return v
+	}
+	return defaultValue
+}
+
+func (f componentFlags) isSet(name string) bool {
+	return f[name] != ""
+}
+
+// contains is true if the comma separated list value of any of the named flags contains item
+func (f componentFlags) contains(item string, names ...string) bool {
+	for _, name := range names {
+		for _, v := range strings.Split(f[name], ",") {
+			if v == item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// atLeast is true if the flag is set to an integer of at least min
+func (f componentFlags) atLeast(name string, min int) bool {
+	n, err := strconv.Atoi(f[name])
+	return err == nil && n >= min
+}
+
+func (f componentFlags) hasAdmissionPlugin(name string) bool {
+	return f.contains(name, "admission-control", "enable-admission-plugins")
+}
+
+func admissionControl(id string, plugin string) control {
+	return control{
+		id:          id,
+		description: fmt.Sprintf("Ensure that the admission control plugin %s is set", plugin),
+		remediation: fmt.Sprintf("add %s to spec.kubeAPIServer.enableAdmissionPlugins (or admissionControl)", plugin),
+		check: func(f componentFlags) bool {
+			return f.hasAdmissionPlugin(plugin)
+		},
+	}
+}
+
+var apiServerControls = []control{
+	{
+		id:          "1.1.1",
+		description: "Ensure that the --anonymous-auth argument is set to false",
+		remediation: "set spec.kubeAPIServer.anonymousAuth to false",
+		check: func(f componentFlags) bool {
+			return f.value("anonymous-auth", "true") == "false"
+		},
+	},
+	{
+		id:          "1.1.2",
+		description: "Ensure that the --basic-auth-file argument is not set",
+		remediation: "kops always configures basic authentication for the admin user",
+		check: func(f componentFlags) bool {
+			return !f.isSet("basic-auth-file")
+		},
+	},
+	{
+		id:          "1.1.5",
+		description: "Ensure that the --insecure-bind-address argument is not set",
+		remediation: "remove spec.kubeAPIServer.insecureBindAddress, or set it to 127.0.0.1",
+		check: func(f componentFlags) bool {
+			return f.value("insecure-bind-address", "127.0.0.1") == "127.0.0.1"
+		},
+	},
+	{
+		id:          "1.1.6",
+		description: "Ensure that the --insecure-port argument is set to 0",
+		remediation: "set spec.kubeAPIServer.insecurePort to 0",
+		check: func(f componentFlags) bool {
+			return f.value("insecure-port", "8080") == "0"
+		},
+	},
+	{
+		id:          "1.1.7",
+		description: "Ensure that the --secure-port argument is not set to 0",
+		remediation: "set spec.kubeAPIServer.securePort to 443",
+		check: func(f componentFlags) bool {
+			return f.value("secure-port", "6443") != "0"
+		},
+	},
+	{
+		id:          "1.1.10",
+		description: "Ensure that the admission control plugin AlwaysAdmit is not set",
+		remediation: "remove AlwaysAdmit from spec.kubeAPIServer.enableAdmissionPlugins (or admissionControl)",
+		check: func(f componentFlags) bool {
+			return !f.hasAdmissionPlugin("AlwaysAdmit")
+		},
+	},
+	admissionControl("1.1.11", "AlwaysPullImages"),
+	admissionControl("1.1.12", "DenyEscalatingExec"),
+	{
+		id:          "1.1.13",
+		description: "Ensure that the admission control plugin SecurityContextDeny is set (if PodSecurityPolicy is not used)",
+		remediation: "add SecurityContextDeny or PodSecurityPolicy to spec.kubeAPIServer.enableAdmissionPlugins (or admissionControl)",
+		check: func(f componentFlags) bool {
+			return f.hasAdmissionPlugin("SecurityContextDeny") || f.hasAdmissionPlugin("PodSecurityPolicy")
+		},
+	},
+	admissionControl("1.1.14", "NamespaceLifecycle"),
+	{
+		id:          "1.1.15",
+		description: "Ensure that the --audit-log-path argument is set",
+		remediation: "set spec.kubeAPIServer.auditLogPath",
+		check: func(f componentFlags) bool {
+			return f.isSet("audit-log-path")
+		},
+	},
+	{
+		id:          "1.1.16",
+		description: "Ensure that the --audit-log-maxage argument is set to 30 or as appropriate",
+		remediation: "set spec.kubeAPIServer.auditLogMaxAge to 30",
+		check: func(f componentFlags) bool {
+			return f.atLeast("audit-log-maxage", 30)
+		},
+	},
+	{
+		id:          "1.1.17",
+		description: "Ensure that the --audit-log-maxbackup argument is set to 10 or as appropriate",
+		remediation: "set spec.kubeAPIServer.auditLogMaxBackups to 10",
+		check: func(f componentFlags) bool {
+			return f.atLeast("audit-log-maxbackup", 10)
+		},
+	},
+	{
+		id:          "1.1.18",
+		description: "Ensure that the --audit-log-maxsize argument is set to 100 or as appropriate",
+		remediation: "set spec.kubeAPIServer.auditLogMaxSize to 100",
+		check: func(f componentFlags) bool {
+			return f.atLeast("audit-log-maxsize", 100)
+		},
+	},
+	{
+		id:          "1.1.19",
+		description: "Ensure that the --authorization-mode argument is not set to AlwaysAllow",
+		remediation: "set spec.authorization.rbac, or spec.kubeAPIServer.authorizationMode to Node,RBAC",
+		check: func(f componentFlags) bool {
+			return !f.contains("AlwaysAllow", "authorization-mode") && f.isSet("authorization-mode")
+		},
+	},
+	{
+		id:          "1.1.20",
+		description: "Ensure that the --token-auth-file parameter is not set",
+		remediation: "kops always configures static tokens for its system users",
+		check: func(f componentFlags) bool {
+			return !f.isSet("token-auth-file")
+		},
+	},
+	{
+		id:          "1.1.22",
+		description: "Ensure that the --kubelet-client-certificate and --kubelet-client-key arguments are set as appropriate",
+		remediation: "set spec.kubelet.anonymousAuth to false",
+		check: func(f componentFlags) bool {
+			return f.isSet("kubelet-client-certificate") && f.isSet("kubelet-client-key")
+		},
+	},
+	admissionControl("1.1.24", "PodSecurityPolicy"),
+	{
+		id:          "1.1.26",
+		description: "Ensure that the --etcd-certfile and --etcd-keyfile arguments are set as appropriate",
+		remediation: "set enableEtcdTLS on each of spec.etcdClusters",
+		check: func(f componentFlags) bool {
+			return f.isSet("etcd-certfile") && f.isSet("etcd-keyfile")
+		},
+	},
+	admissionControl("1.1.27", "ServiceAccount"),
+	{
+		id:          "1.1.28",
+		description: "Ensure that the --tls-cert-file and --tls-private-key-file arguments are set as appropriate",
+		check: func(f componentFlags) bool {
+			return f.isSet("tls-cert-file") && f.isSet("tls-private-key-file")
+		},
+	},
+	{
+		id:          "1.1.29",
+		description: "Ensure that the --client-ca-file argument is set as appropriate",
+		check: func(f componentFlags) bool {
+			return f.isSet("client-ca-file")
+		},
+	},
+	{
+		id:          "1.1.31",
+		description: "Ensure that the --etcd-cafile argument is set as appropriate",
+		remediation: "set enableEtcdTLS on each of spec.etcdClusters",
+		check: func(f componentFlags) bool {
+			return f.isSet("etcd-cafile")
+		},
+	},
+	{
+		id:          "1.1.32",
+		description: "Ensure that the --authorization-mode argument is set to Node",
+		remediation: "set spec.kubeAPIServer.authorizationMode to Node,RBAC",
+		check: func(f componentFlags) bool {
+			return f.contains("Node", "authorization-mode")
+		},
+	},
+	admissionControl("1.1.33", "NodeRestriction"),
+	{
+		id:          "1.1.34",
+		description: "Ensure that the --experimental-encryption-provider-config argument is set as appropriate",
+		remediation: "set spec.encryptionConfig to true and create the encryptionconfig secret",
+		check: func(f componentFlags) bool {
+			return f.isSet("experimental-encryption-provider-config")
+		},
+	},
+	admissionControl("1.1.36", "EventRateLimit"),
+}
+
+var controllerManagerControls = []control{
+	{
+		id:          "1.3.1",
+		description: "Ensure that the --terminated-pod-gc-threshold argument is set as appropriate",
+		remediation: "set spec.kubeControllerManager.terminatedPodGCThreshold",
+		check: func(f componentFlags) bool {
+			return f.isSet("terminated-pod-gc-threshold")
+		},
+	},
+	{
+		id:          "1.3.3",
+		description: "Ensure that the --use-service-account-credentials argument is set to true",
+		remediation: "set spec.kubeControllerManager.useServiceAccountCredentials to true",
+		check: func(f componentFlags) bool {
+			return f.value("use-service-account-credentials", "false") == "true"
+		},
+	},
+	{
+		id:          "1.3.4",
+		description: "Ensure that the --service-account-private-key-file argument is set as appropriate",
+		check: func(f componentFlags) bool {
+			return f.isSet("service-account-private-key-file")
+		},
+	},
+	{
+		id:          "1.3.5",
+		description: "Ensure that the --root-ca-file argument is set as appropriate",
+		check: func(f componentFlags) bool {
+			return f.isSet("root-ca-file")
+		},
+	},
+}
+
+var kubeletControls = []control{
+	{
+		id:          "2.1.1",
+		description: "Ensure that the --allow-privileged argument is set to false",
+		remediation: "set allowPrivileged to false in spec.kubelet, spec.masterKubelet or the instance group's kubelet",
+		check: func(f componentFlags) bool {
+			return f.value("allow-privileged", "false") == "false"
+		},
+	},
+	{
+		id:          "2.1.2",
+		description: "Ensure that the --anonymous-auth argument is set to false",
+		remediation: "set anonymousAuth to false in spec.kubelet, spec.masterKubelet or the instance group's kubelet",
+		check: func(f componentFlags) bool {
+			return f.value("anonymous-auth", "true") == "false"
+		},
+	},
+	{
+		id:          "2.1.3",
+		description: "Ensure that the --authorization-mode argument is not set to AlwaysAllow",
+		remediation: "set authorizationMode to Webhook in spec.kubelet, spec.masterKubelet or the instance group's kubelet",
+		check: func(f componentFlags) bool {
+			return f.value("authorization-mode", "AlwaysAllow") != "AlwaysAllow"
+		},
+	},
+	{
+		id:          "2.1.4",
+		description: "Ensure that the --client-ca-file argument is set as appropriate",
+		remediation: "set anonymousAuth to false in spec.kubelet, spec.masterKubelet or the instance group's kubelet",
+		check: func(f componentFlags) bool {
+			return f.isSet("client-ca-file")
+		},
+	},
+	{
+		id:          "2.1.5",
+		description: "Ensure that the --read-only-port argument is set to 0",
+		remediation: "set readOnlyPort to 0 in spec.kubelet, spec.masterKubelet or the instance group's kubelet",
+		check: func(f componentFlags) bool {
+			return f.value("read-only-port", "10255") == "0"
+		},
+	},
+	{
+		id:          "2.1.6",
+		description: "Ensure that the --streaming-connection-idle-timeout argument is not set to 0",
+		remediation: "remove streamingConnectionIdleTimeout from spec.kubelet, spec.masterKubelet and the instance group's kubelet",
+		check: func(f componentFlags) bool {
+			v := f.value("streaming-connection-idle-timeout", "4h0m0s")
+			return v != "0" && v != "0s"
+		},
+	},
+}
+
+// CheckCluster checks the CIS Kubernetes Benchmark controls that can be determined from the configuration kops
+// renders for the cluster's components.  The cluster must be the fully populated spec, as applied by update cluster.
+func CheckCluster(cluster *api.Cluster, instanceGroups []*api.InstanceGroup) ([]*Result, error) {
+	if cluster.Spec.KubeAPIServer == nil || cluster.Spec.KubeControllerManager == nil {
+		return nil, fmt.Errorf("cluster spec has not been populated")
+	}
+
+	var results []*Result
+
+	apiServerFlags, err := flagbuilder.BuildFlagsList(renderAPIServer(cluster))
+	if err != nil {
+		return nil, fmt.Errorf("error building kube-apiserver flags: %v", err)
+	}
+	results = append(results, check(ComponentAPIServer, "", apiServerControls, apiServerFlags)...)
+
+	controllerManagerFlags, err := flagbuilder.BuildFlagsList(renderControllerManager(cluster))
+	if err != nil {
+		return nil, fmt.Errorf("error building kube-controller-manager flags: %v", err)
+	}
+	results = append(results, check(ComponentControllerManager, "", controllerManagerControls, controllerManagerFlags)...)
+
+	results = append(results, checkEtcd(cluster)...)
+
+	for _, ig := range instanceGroups {
+		if ig.Spec.Role == api.InstanceGroupRoleBastion {
+			continue
+		}
+		kubeletFlags, err := flagbuilder.BuildFlagsList(renderKubelet(cluster, ig))
+		if err != nil {
+			return nil, fmt.Errorf("error building kubelet flags for instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+		results = append(results, check(ComponentKubelet, ig.ObjectMeta.Name, kubeletControls, kubeletFlags)...)
+	}
+
+	return results, nil
+}
+
+func check(component string, target string, controls []control, flags []string) []*Result {
+	f := parseFlags(flags)
+
+	var results []*Result
+	for _, c := range controls {
+		r := &Result{
+			ID:          c.id,
+			Component:   component,
+			Target:      target,
+			Description: c.description,
+			Status:      StatusPass,
+		}
+		if !c.check(f) {
+			r.Status = StatusFail
+			r.Remediation = c.remediation
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func checkEtcd(cluster *api.Cluster) []*Result {
+	var results []*Result
+	for _, etcd := range cluster.Spec.EtcdClusters {
+		add := func(id string, description string, remediation string, pass bool) {
+			r := &Result{
+				ID:          id,
+				Component:   ComponentEtcd,
+				Target:      etcd.Name,
+				Description: description,
+				Status:      StatusPass,
+			}
+			if !pass {
+				r.Status = StatusFail
+				r.Remediation = remediation
+			}
+			results = append(results, r)
+		}
+
+		tlsRemediation := fmt.Sprintf("set enableEtcdTLS on etcd cluster %q", etcd.Name)
+		authRemediation := fmt.Sprintf("set enableEtcdTLS and enableTLSAuth on etcd cluster %q", etcd.Name)
+		add("1.5.1", "Ensure that the --cert-file and --key-file arguments are set as appropriate", tlsRemediation, etcd.EnableEtcdTLS)
+		add("1.5.2", "Ensure that the --client-cert-auth argument is set to true", authRemediation, etcd.EnableEtcdTLS && etcd.EnableTLSAuth)
+		add("1.5.4", "Ensure that the --peer-cert-file and --peer-key-file arguments are set as appropriate", tlsRemediation, etcd.EnableEtcdTLS)
+		add("1.5.5", "Ensure that the --peer-client-cert-auth argument is set to true", authRemediation, etcd.EnableEtcdTLS && etcd.EnableTLSAuth)
+	}
+	return results
+}
+
+// renderAPIServer applies the settings nodeup adds to the kube-apiserver configuration when it writes the manifest
+func renderAPIServer(cluster *api.Cluster) *api.KubeAPIServerConfig {
+	c := cluster.Spec.KubeAPIServer.DeepCopy()
+	c.ClientCAFile = filepath.Join(pathSrvKubernetes, "ca.crt")
+	c.TLSCertFile = filepath.Join(pathSrvKubernetes, "server.cert")
+	c.TLSPrivateKeyFile = filepath.Join(pathSrvKubernetes, "server.key")
+	c.BasicAuthFile = filepath.Join(pathSrvKubernetes, "basic_auth.csv")
+	c.TokenAuthFile = filepath.Join(pathSrvKubernetes, "known_tokens.csv")
+
+	if useEtcdTLS(cluster) {
+		c.EtcdCAFile = filepath.Join(pathSrvKubernetes, "ca.crt")
+		c.EtcdCertFile = filepath.Join(pathSrvKubernetes, "etcd-client.pem")
+		c.EtcdKeyFile = filepath.Join(pathSrvKubernetes, "etcd-client-key.pem")
+	}
+
+	if useSecureKubelet(cluster.Spec.MasterKubelet) || useSecureKubelet(cluster.Spec.Kubelet) {
+		c.KubeletClientCertificate = filepath.Join(pathSrvKubernetes, "kubelet-api.pem")
+		c.KubeletClientKey = filepath.Join(pathSrvKubernetes, "kubelet-api-key.pem")
+	}
+
+	return c
+}
+
+// renderControllerManager applies the settings nodeup adds to the kube-controller-manager configuration
+func renderControllerManager(cluster *api.Cluster) *api.KubeControllerManagerConfig {
+	c := cluster.Spec.KubeControllerManager.DeepCopy()
+	c.RootCAFile = filepath.Join(pathSrvKubernetes, "ca.crt")
+	c.ServiceAccountPrivateKeyFile = filepath.Join(pathSrvKubernetes, "server.key")
+	return c
+}
+
+// renderKubelet merges the kubelet configuration of an instance group as nodeup does
+func renderKubelet(cluster *api.Cluster, ig *api.InstanceGroup) *api.KubeletConfigSpec {
+	isMaster := ig.Spec.Role == api.InstanceGroupRoleMaster || ig.Spec.Role == api.InstanceGroupRoleEtcd
+
+	c := &api.KubeletConfigSpec{}
+	if isMaster {
+		reflectutils.JsonMergeStruct(c, cluster.Spec.MasterKubelet)
+	} else {
+		reflectutils.JsonMergeStruct(c, cluster.Spec.Kubelet)
+	}
+
+	secure := useSecureKubelet(ig.Spec.Kubelet) || useSecureKubelet(cluster.Spec.Kubelet)
+	if isMaster {
+		secure = secure || useSecureKubelet(cluster.Spec.MasterKubelet)
+	}
+	if secure {
+		c.ClientCAFile = filepath.Join(pathSrvKubernetes, "ca.crt")
+	}
+
+	if ig.Spec.Kubelet != nil {
+		reflectutils.JsonMergeStruct(c, ig.Spec.Kubelet)
+	}
+	return c
+}
+
+func useSecureKubelet(c *api.KubeletConfigSpec) bool {
+	return c != nil && c.AnonymousAuth != nil && !*c.AnonymousAuth
+}
+
+func useEtcdTLS(cluster *api.Cluster) bool {
+	for _, etcd := range cluster.Spec.EtcdClusters {
+		if etcd.EnableEtcdTLS {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cis
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func findResult(results []*Result, id string, target string) *Result {
+	for _, r := range results {
+		if r.ID == id && r.Target == target {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestCheckCluster(t *testing.T) {
+	cluster := &api.Cluster{
+		Spec: api.ClusterSpec{
+			KubeAPIServer: &api.KubeAPIServerConfig{
+				SecurePort:             443,
+				InsecurePort:           8080,
+				AnonymousAuth:          fi.Bool(false),
+				AuthorizationMode:      fi.String("RBAC"),
+				EnableAdmissionPlugins: []string{"NamespaceLifecycle", "ServiceAccount", "NodeRestriction"},
+				AuditLogMaxAge:         fi.Int32(30),
+			},
+			KubeControllerManager: &api.KubeControllerManagerConfig{
+				UseServiceAccountCredentials: fi.Bool(true),
+			},
+			Kubelet: &api.KubeletConfigSpec{
+				AnonymousAuth: fi.Bool(false),
+			},
+			MasterKubelet: &api.KubeletConfigSpec{},
+			EtcdClusters: []*api.EtcdClusterSpec{
+				{Name: "main", EnableEtcdTLS: true, EnableTLSAuth: true},
+				{Name: "events", EnableEtcdTLS: true},
+			},
+		},
+	}
+	instanceGroups := []*api.InstanceGroup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "master-us-test-1a"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleMaster}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nodes"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleNode}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "insecure"},
+			Spec: api.InstanceGroupSpec{
+				Role:    api.InstanceGroupRoleNode,
+				Kubelet: &api.KubeletConfigSpec{ReadOnlyPort: fi.Int32(0), AnonymousAuth: fi.Bool(true)},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bastions"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleBastion}},
+	}
+
+	results, err := CheckCluster(cluster, instanceGroups)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	grid := []struct {
+		ID     string
+		Target string
+		Status string
+	}{
+		{"1.1.1", "", StatusPass},
+		{"1.1.2", "", StatusFail},
+		{"1.1.6", "", StatusFail},
+		{"1.1.7", "", StatusPass},
+		{"1.1.14", "", StatusPass},
+		{"1.1.16", "", StatusPass},
+		{"1.1.17", "", StatusFail},
+		{"1.1.19", "", StatusPass},
+		{"1.1.22", "", StatusPass},
+		{"1.1.26", "", StatusPass},
+		{"1.1.29", "", StatusPass},
+		{"1.1.32", "", StatusFail},
+		{"1.1.33", "", StatusPass},
+		{"1.3.1", "", StatusFail},
+		{"1.3.3", "", StatusPass},
+		{"1.3.5", "", StatusPass},
+		{"1.5.2", "main", StatusPass},
+		{"1.5.2", "events", StatusFail},
+		{"1.5.4", "events", StatusPass},
+		{"2.1.2", "master-us-test-1a", StatusFail},
+		{"2.1.4", "master-us-test-1a", StatusPass},
+		{"2.1.2", "nodes", StatusPass},
+		{"2.1.4", "nodes", StatusPass},
+		{"2.1.5", "nodes", StatusFail},
+		{"2.1.2", "insecure", StatusFail},
+		{"2.1.5", "insecure", StatusPass},
+	}
+	for _, g := range grid {
+		r := findResult(results, g.ID, g.Target)
+		if r == nil {
+			t.Errorf("control %s (%q) was not checked", g.ID, g.Target)
+			continue
+		}
+		if r.Status != g.Status {
+			t.Errorf("control %s (%q): expected %s, got %s", g.ID, g.Target, g.Status, r.Status)
+		}
+		if r.Status == StatusPass && r.Remediation != "" {
+			t.Errorf("control %s (%q): passing control should not have a remediation", g.ID, g.Target)
+		}
+	}
+
+	if r := findResult(results, "2.1.2", "bastions"); r != nil {
+		t.Errorf("bastion instance groups do not run a kubelet and should not be checked")
+	}
+
+	if cluster.Spec.KubeAPIServer.ClientCAFile != "" {
+		t.Errorf("CheckCluster should not modify the cluster spec")
+	}
+}
+
+func TestCheckClusterNotPopulated(t *testing.T) {
+	if _, err := CheckCluster(&api.Cluster{}, nil); err == nil {
+		t.Errorf("expected an error for a cluster spec that has not been populated")
+	}
+}
@@ -117,7 +117,7 @@ func DeleteAllClusterState(basePath vfs.Path) error {
 			continue
 		}
 
-		if relativePath == "config" || relativePath == "cluster.spec" {
+		if relativePath == "config" || relativePath == "cluster.spec" || relativePath == "lock" {
 			continue
 		}
 		if strings.HasPrefix(relativePath, "addons/") {
@@ -139,6 +139,10 @@ func DeleteAllClusterState(basePath vfs.Path) error {
 		if strings.HasPrefix(relativePath, "backups/") {
 			continue
 		}
+		// State recorded by clouds without launch configurations, e.g. the launch specs of vSphere instance groups
+		if strings.HasPrefix(relativePath, "baremetal/") || strings.HasPrefix(relativePath, "hetzner/") || strings.HasPrefix(relativePath, "scaleway/") || strings.HasPrefix(relativePath, "vsphere/") {
+			continue
+		}
 
 		return fmt.Errorf("refusing to delete: unknown file found: %s", path)
 	}
@@ -25,6 +25,7 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -33,9 +34,17 @@ import (
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/kops/v1alpha1"
 	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/approval"
+	"k8s.io/kops/pkg/audit"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
+// reservedNames are the directories of the state store that hold data of other kinds rather than clusters
+var reservedNames = map[string]bool{
+	approval.StateStoreDir: true,
+	audit.StateStoreDir:    true,
+}
+
 type ClusterVFS struct {
 	commonVFS
 }
@@ -72,6 +81,15 @@ func (c *ClusterVFS) configBase(clusterName string) (vfs.Path, error) {
 }
 
 func (c *ClusterVFS) List(options metav1.ListOptions) (*api.ClusterList, error) {
+	selector := labels.Everything()
+	if options.LabelSelector != "" {
+		var err error
+		selector, err = labels.Parse(options.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %v", options.LabelSelector, err)
+		}
+	}
+
 	names, err := c.listNames()
 	if err != nil {
 		return nil, err
@@ -91,6 +109,10 @@ func (c *ClusterVFS) List(options metav1.ListOptions) (*api.ClusterList, error)
 			continue
 		}
 
+		if !selector.Matches(labels.Set(cluster.ObjectMeta.Labels)) {
+			continue
+		}
+
 		items = append(items, *cluster)
 	}
 
@@ -110,6 +132,9 @@ func (r *ClusterVFS) Create(c *api.Cluster) (*api.Cluster, error) {
 	if clusterName == "" {
 		return nil, fmt.Errorf("clusterName is required")
 	}
+	if reservedNames[clusterName] {
+		return nil, fmt.Errorf("cluster name %q is reserved", clusterName)
+	}
 
 	if err := r.writeConfig(c, r.basePath.Join(clusterName, registry.PathCluster), c, vfs.WriteOptionCreate); err != nil {
 		if os.IsExist(err) {
@@ -141,7 +166,7 @@ func (r *ClusterVFS) Update(c *api.Cluster, status *api.ClusterStatus) (*api.Clu
 	}
 
 	if err := r.writeConfig(c, r.basePath.Join(clusterName, registry.PathCluster), c, vfs.WriteOptionOnlyIfExists); err != nil {
-		if os.IsNotExist(err) {
+		if os.IsNotExist(err) || errors.IsConflict(err) {
 			return nil, err
 		}
 		return nil, fmt.Errorf("error writing Cluster: %v", err)
@@ -151,7 +176,7 @@ func (r *ClusterVFS) Update(c *api.Cluster, status *api.ClusterStatus) (*api.Clu
 }
 
 // List returns a slice containing all the cluster names
-// It skips directories that don't look like clusters
+// It skips directories that don't look like clusters, and the reserved directories
 func (r *ClusterVFS) listNames() ([]string, error) {
 	paths, err := r.basePath.ReadTree()
 	if err != nil {
@@ -164,6 +189,9 @@ func (r *ClusterVFS) listNames() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
+		if reservedNames[strings.SplitN(relativePath, "/", 2)[0]] {
+			continue
+		}
 		if !strings.HasSuffix(relativePath, "/config") {
 			continue
 		}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/approval"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestListNamesSkipsReservedNames(t *testing.T) {
+	basePath := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	for _, p := range []string{
+		"example.com/config",
+		"example.com/instancegroup/nodes",
+		"other.example.com/config",
+		// a directory holding other data must not be listed, even if it looks like a cluster
+		audit.StateStoreDir + "/config",
+		approval.StateStoreDir + "/config",
+	} {
+		if err := basePath.Join(p).CreateFile(bytes.NewReader([]byte("{}")), nil); err != nil {
+			t.Fatalf("error writing %s: %v", p, err)
+		}
+	}
+	if err := audit.NewLog(basePath).Record(&audit.Entry{Cluster: "example.com", Operation: audit.OperationCreate}); err != nil {
+		t.Fatalf("error recording audit entry: %v", err)
+	}
+	if _, err := approval.NewStore(basePath, "example.com", []byte("key")).Request(approval.OperationUpdateCluster, "digest", "summary", "user"); err != nil {
+		t.Fatalf("error requesting approval: %v", err)
+	}
+
+	names, err := newClusterVFS(basePath).listNames()
+	if err != nil {
+		t.Fatalf("error listing clusters: %v", err)
+	}
+	expected := []string{"example.com", "other.example.com"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected clusters %v, got %v", expected, names)
+	}
+}
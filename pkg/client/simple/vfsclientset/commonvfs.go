@@ -18,6 +18,8 @@ package vfsclientset
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"reflect"
@@ -25,6 +27,7 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -130,11 +133,34 @@ func (c *commonVFS) readConfig(configPath vfs.Path) (runtime.Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing %s: %v", configPath, err)
 	}
+
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta.SetResourceVersion(resourceVersion(data))
+
 	return object, nil
 }
 
+// resourceVersion identifies the stored contents of an object.
+// An update of an object with a resourceVersion fails if the stored object has changed since it was read.
+func resourceVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
 func (c *commonVFS) writeConfig(cluster *kops.Cluster, configPath vfs.Path, o runtime.Object, writeOptions ...vfs.WriteOption) error {
+	objectMeta, err := meta.Accessor(o)
+	if err != nil {
+		return err
+	}
+
+	// The resourceVersion is derived from the stored contents, so is never itself stored
+	expectedVersion := objectMeta.GetResourceVersion()
+	objectMeta.SetResourceVersion("")
 	data, err := c.serialize(o)
+	objectMeta.SetResourceVersion(expectedVersion)
 	if err != nil {
 		return fmt.Errorf("error marshalling object: %v", err)
 	}
@@ -145,13 +171,18 @@ func (c *commonVFS) writeConfig(cluster *kops.Cluster, configPath vfs.Path, o ru
 		case vfs.WriteOptionCreate:
 			create = true
 		case vfs.WriteOptionOnlyIfExists:
-			_, err = configPath.ReadFile()
+			existing, err := configPath.ReadFile()
 			if err != nil {
 				if os.IsNotExist(err) {
 					return fmt.Errorf("cannot update configuration file %s: does not exist", configPath)
 				}
 				return fmt.Errorf("error checking if configuration file %s exists already: %v", configPath, err)
 			}
+			// The state store has no transactions, so this narrows rather than closes the window for conflicting writes
+			if expectedVersion != "" && expectedVersion != resourceVersion(existing) {
+				return errors.NewConflict(schema.GroupResource{Group: kops.GroupName, Resource: c.kind}, objectMeta.GetName(),
+					fmt.Errorf("the object has been modified since it was read; read it again and reapply your changes, or use --force to overwrite them"))
+			}
 		default:
 			return fmt.Errorf("unknown write option: %q", writeOption)
 		}
@@ -175,6 +206,8 @@ func (c *commonVFS) writeConfig(cluster *kops.Cluster, configPath vfs.Path, o ru
 		}
 		return fmt.Errorf("error writing configuration file %s: %v", configPath, err)
 	}
+
+	objectMeta.SetResourceVersion(resourceVersion(data))
 	return nil
 }
 
@@ -198,6 +231,9 @@ func (c *commonVFS) update(cluster *kops.Cluster, i runtime.Object) error {
 
 	err = c.writeConfig(cluster, c.basePath.Join(objectMeta.GetName()), i, vfs.WriteOptionOnlyIfExists)
 	if err != nil {
+		if errors.IsConflict(err) {
+			return err
+		}
 		return fmt.Errorf("error writing %s: %v", c.kind, err)
 	}
 
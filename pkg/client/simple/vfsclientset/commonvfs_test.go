@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfsclientset
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestUpdateConflict(t *testing.T) {
+	basePath := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	clientset := NewVFSClientset(basePath, true)
+
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = "example.com"
+	client := clientset.InstanceGroupsFor(cluster)
+
+	ig := &kops.InstanceGroup{}
+	ig.ObjectMeta.Name = "nodes"
+	ig.Spec.Role = kops.InstanceGroupRoleNode
+	ig.Spec.MachineType = "t2.medium"
+	if _, err := client.Create(ig); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	first, err := client.Get("nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error reading instance group: %v", err)
+	}
+	second, err := client.Get("nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error reading instance group: %v", err)
+	}
+	if first.ObjectMeta.ResourceVersion == "" {
+		t.Fatalf("expected the resourceVersion to be set when reading")
+	}
+
+	first.Spec.MachineType = "m4.large"
+	if _, err := client.Update(first); err != nil {
+		t.Fatalf("error updating instance group: %v", err)
+	}
+	if first.ObjectMeta.ResourceVersion == second.ObjectMeta.ResourceVersion {
+		t.Errorf("expected the resourceVersion to change on update")
+	}
+
+	// The second copy was read before the first update, so must not overwrite it
+	second.Spec.MachineType = "c4.large"
+	_, err = client.Update(second)
+	if !errors.IsConflict(err) {
+		t.Fatalf("expected conflict updating a stale instance group, got %v", err)
+	}
+
+	// Updating the latest copy again succeeds
+	first.Spec.MachineType = "m4.xlarge"
+	if _, err := client.Update(first); err != nil {
+		t.Fatalf("error updating instance group again: %v", err)
+	}
+
+	// Without a resourceVersion the update is unconditional
+	second.ObjectMeta.ResourceVersion = ""
+	if _, err := client.Update(second); err != nil {
+		t.Fatalf("error forcing update of instance group: %v", err)
+	}
+
+	stored, err := client.Get("nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error reading instance group: %v", err)
+	}
+	if stored.Spec.MachineType != "c4.large" {
+		t.Errorf("expected forced update to be stored, got machine type %q", stored.Spec.MachineType)
+	}
+	if stored.ObjectMeta.ResourceVersion != second.ObjectMeta.ResourceVersion {
+		t.Errorf("expected resourceVersion %q after update, read %q", second.ObjectMeta.ResourceVersion, stored.ObjectMeta.ResourceVersion)
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kops/pkg/apis/kops"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
@@ -49,10 +50,25 @@ type CloudInstanceGroupMember struct {
 	Node *v1.Node
 	// CloudInstanceGroup is the managing CloudInstanceGroup
 	CloudInstanceGroup *CloudInstanceGroup
+	// UpdateReasons describes why the instance needs to be updated, e.g. image or userData; empty if it is ready
+	UpdateReasons []string
 }
 
-// NewCloudInstanceGroupMember creates a new CloudInstanceGroupMember
+// ReasonConfigurationChanged is the update reason when the cloud can't tell which part of the configuration changed
+const ReasonConfigurationChanged = "configuration"
+
+// NewCloudInstanceGroupMember creates a new CloudInstanceGroupMember, which needs updating if it was created from
+// a configuration (e.g. a launch configuration) other than the current configuration of the group
 func (c *CloudInstanceGroup) NewCloudInstanceGroupMember(instanceId string, newGroupName string, currentGroupName string, nodeMap map[string]*v1.Node) error {
+	var reasons []string
+	if newGroupName != currentGroupName {
+		reasons = append(reasons, ReasonConfigurationChanged)
+	}
+	return c.NewCloudInstanceGroupMemberWithReasons(instanceId, reasons, nodeMap)
+}
+
+// NewCloudInstanceGroupMemberWithReasons creates a new CloudInstanceGroupMember, which needs updating for the given reasons, if any
+func (c *CloudInstanceGroup) NewCloudInstanceGroupMemberWithReasons(instanceId string, reasons []string, nodeMap map[string]*v1.Node) error {
 	if instanceId == "" {
 		return fmt.Errorf("instance id for cloud instance member cannot be empty")
 	}
@@ -67,15 +83,25 @@ func (c *CloudInstanceGroup) NewCloudInstanceGroupMember(instanceId string, newG
 		glog.V(8).Infof("unable to find node for instance: %s", instanceId)
 	}
 
-	if newGroupName == currentGroupName {
+	if len(reasons) == 0 {
 		c.Ready = append(c.Ready, cm)
 	} else {
+		cm.UpdateReasons = reasons
 		c.NeedUpdate = append(c.NeedUpdate, cm)
 	}
 
 	return nil
 }
 
+// UpdateReasons returns the distinct reasons the instances of the group need updating, sorted
+func (c *CloudInstanceGroup) UpdateReasons() []string {
+	reasons := sets.NewString()
+	for _, m := range c.NeedUpdate {
+		reasons.Insert(m.UpdateReasons...)
+	}
+	return reasons.List()
+}
+
 // Status returns a human-readable Status indicating whether an update is needed
 func (c *CloudInstanceGroup) Status() string {
 	if len(c.NeedUpdate) == 0 {
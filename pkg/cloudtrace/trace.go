@@ -0,0 +1,233 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudtrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/kops/util/pkg/tables"
+)
+
+// Call records a single (logical) cloud API call, including any retries
+type Call struct {
+	// Cloud is the cloud provider, e.g. aws or gce
+	Cloud string `json:"cloud"`
+	// Service is the API service, e.g. ec2 or compute
+	Service string `json:"service"`
+	// Operation is the name of the API operation, e.g. DescribeInstances
+	Operation string `json:"operation"`
+
+	// Start is the time the call was started
+	Start time.Time `json:"start"`
+	// Duration is the total time taken by the call, including retries
+	Duration time.Duration `json:"duration"`
+
+	// Retries is the number of times the call was retried
+	Retries int `json:"retries,omitempty"`
+	// Throttled is the number of attempts that were rejected by rate limiting
+	Throttled int `json:"throttled,omitempty"`
+	// Cached is true if the call was served from the discovery cache
+	Cached bool `json:"cached,omitempty"`
+	// Error is the final error from the call, if it failed
+	Error string `json:"error,omitempty"`
+}
+
+// Recorder accumulates the calls made during a kops invocation
+type Recorder struct {
+	mutex sync.Mutex
+	start time.Time
+	calls []*Call
+}
+
+var (
+	activeMutex sync.Mutex
+	active      *Recorder
+)
+
+// Enable starts recording cloud API calls for the remainder of the process
+func Enable() *Recorder {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	if active == nil {
+		active = &Recorder{start: time.Now()}
+	}
+	return active
+}
+
+// Active returns the process-wide recorder, or nil if tracing is not enabled
+func Active() *Recorder {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	return active
+}
+
+// Record adds the call to the process-wide recorder, if tracing is enabled
+func Record(call *Call) {
+	r := Active()
+	if r == nil {
+		return
+	}
+	r.Record(call)
+}
+
+// Record adds the call to the recorder
+func (r *Recorder) Record(call *Call) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+// Calls returns a copy of the recorded calls
+func (r *Recorder) Calls() []*Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	calls := make([]*Call, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// OperationSummary aggregates the calls to a single operation
+type OperationSummary struct {
+	Cloud     string
+	Service   string
+	Operation string
+
+	Calls     int
+	Cached    int
+	Errors    int
+	Retries   int
+	Throttled int
+
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// Summarize aggregates the recorded calls by operation
+func (r *Recorder) Summarize() []*OperationSummary {
+	byOperation := make(map[string]*OperationSummary)
+	for _, call := range r.Calls() {
+		key := call.Cloud + "/" + call.Service + "/" + call.Operation
+		s := byOperation[key]
+		if s == nil {
+			s = &OperationSummary{
+				Cloud:     call.Cloud,
+				Service:   call.Service,
+				Operation: call.Operation,
+			}
+			byOperation[key] = s
+		}
+
+		s.Calls++
+		if call.Cached {
+			s.Cached++
+		}
+		if call.Error != "" {
+			s.Errors++
+		}
+		s.Retries += call.Retries
+		s.Throttled += call.Throttled
+		s.TotalDuration += call.Duration
+		if call.Duration > s.MaxDuration {
+			s.MaxDuration = call.Duration
+		}
+	}
+
+	var summaries []*OperationSummary
+	for _, s := range byOperation {
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Service != summaries[j].Service {
+			return summaries[i].Service < summaries[j].Service
+		}
+		return summaries[i].Operation < summaries[j].Operation
+	})
+	return summaries
+}
+
+// WriteSummary prints a per-operation summary of the recorded calls, along with the overall request rate
+func (r *Recorder) WriteSummary(out io.Writer) error {
+	summaries := r.Summarize()
+
+	calls, cached, throttled, retries := 0, 0, 0, 0
+	for _, s := range summaries {
+		calls += s.Calls
+		cached += s.Cached
+		throttled += s.Throttled
+		retries += s.Retries
+	}
+
+	elapsed := time.Since(r.start)
+	fmt.Fprintf(out, "\nCloud API calls: %d (%d served from cache) in %v\n", calls, cached, elapsed.Round(time.Millisecond))
+	if calls == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "Retries: %d, of which throttled: %d; average rate %.1f calls/second\n\n", retries, throttled, float64(calls-cached)/elapsed.Seconds())
+
+	t := &tables.Table{}
+	t.AddColumn("SERVICE", func(s *OperationSummary) string {
+		return s.Cloud + "/" + s.Service
+	})
+	t.AddColumn("OPERATION", func(s *OperationSummary) string {
+		return s.Operation
+	})
+	t.AddColumn("CALLS", func(s *OperationSummary) string {
+		return strconv.Itoa(s.Calls)
+	})
+	t.AddColumn("CACHED", func(s *OperationSummary) string {
+		return strconv.Itoa(s.Cached)
+	})
+	t.AddColumn("ERRORS", func(s *OperationSummary) string {
+		return strconv.Itoa(s.Errors)
+	})
+	t.AddColumn("RETRIES", func(s *OperationSummary) string {
+		return strconv.Itoa(s.Retries)
+	})
+	t.AddColumn("THROTTLED", func(s *OperationSummary) string {
+		return strconv.Itoa(s.Throttled)
+	})
+	t.AddColumn("TOTAL", func(s *OperationSummary) string {
+		return s.TotalDuration.Round(time.Millisecond).String()
+	})
+	t.AddColumn("MAX", func(s *OperationSummary) string {
+		return s.MaxDuration.Round(time.Millisecond).String()
+	})
+	return t.Render(summaries, out, "SERVICE", "OPERATION", "CALLS", "CACHED", "ERRORS", "RETRIES", "THROTTLED", "TOTAL", "MAX")
+}
+
+// WriteTrace writes every recorded call to the file, as JSON
+func (r *Recorder) WriteTrace(p string) error {
+	data, err := json.MarshalIndent(r.Calls(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing cloud trace: %v", err)
+	}
+	if err := ioutil.WriteFile(p, data, 0644); err != nil {
+		return fmt.Errorf("error writing cloud trace to %q: %v", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudtrace
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	r := &Recorder{start: time.Now()}
+	r.Record(&Call{Cloud: "aws", Service: "ec2", Operation: "DescribeInstances", Duration: time.Second, Retries: 2, Throttled: 1})
+	r.Record(&Call{Cloud: "aws", Service: "ec2", Operation: "DescribeInstances", Duration: 3 * time.Second, Cached: true})
+	r.Record(&Call{Cloud: "aws", Service: "autoscaling", Operation: "DescribeAutoScalingGroups", Duration: time.Second, Error: "boom"})
+
+	summaries := r.Summarize()
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(summaries))
+	}
+
+	s := summaries[1]
+	if s.Operation != "DescribeInstances" || s.Calls != 2 || s.Cached != 1 || s.Retries != 2 || s.Throttled != 1 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s.TotalDuration != 4*time.Second || s.MaxDuration != 3*time.Second {
+		t.Errorf("unexpected durations in summary %+v", s)
+	}
+	if summaries[0].Errors != 1 {
+		t.Errorf("expected error to be counted in summary %+v", summaries[0])
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteSummary(&b); err != nil {
+		t.Fatalf("error writing summary: %v", err)
+	}
+	if !strings.Contains(b.String(), "Cloud API calls: 3 (1 served from cache)") {
+		t.Errorf("unexpected summary output: %s", b.String())
+	}
+}
+
+func TestDescribeRESTCall(t *testing.T) {
+	grid := []struct {
+		Method    string
+		URL       string
+		Service   string
+		Operation string
+	}{
+		{
+			Method:    http.MethodGet,
+			URL:       "https://www.googleapis.com/compute/beta/projects/p/zones/us-central1-a/instances",
+			Service:   "compute",
+			Operation: "GET projects.zones.instances",
+		},
+		{
+			Method:    http.MethodPost,
+			URL:       "https://www.googleapis.com/compute/beta/projects/p/zones/us-central1-a/instances/i/setMetadata",
+			Service:   "compute",
+			Operation: "POST projects.zones.instances.setMetadata",
+		},
+		{
+			Method:    http.MethodGet,
+			URL:       "https://iam.googleapis.com/v1/projects/p/serviceAccounts",
+			Service:   "iam",
+			Operation: "GET projects.serviceAccounts",
+		},
+	}
+	for _, g := range grid {
+		req, err := http.NewRequest(g.Method, g.URL, nil)
+		if err != nil {
+			t.Fatalf("error building request: %v", err)
+		}
+		service, operation := describeRESTCall(req)
+		if service != g.Service || operation != g.Operation {
+			t.Errorf("describeRESTCall(%s) = %q %q, expected %q %q", g.URL, service, operation, g.Service, g.Operation)
+		}
+	}
+}
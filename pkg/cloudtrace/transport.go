@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudtrace
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/kops/pkg/discoverycache"
+)
+
+// WrapTransport returns a RoundTripper that records calls to REST-style cloud APIs (e.g. GCE).
+// The transport is returned unchanged if tracing is not enabled.
+func WrapTransport(cloud string, rt http.RoundTripper) http.RoundTripper {
+	if Active() == nil {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{cloud: cloud, next: rt}
+}
+
+type transport struct {
+	cloud string
+	next  http.RoundTripper
+}
+
+var _ http.RoundTripper = &transport{}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, operation := describeRESTCall(req)
+	call := &Call{
+		Cloud:     t.cloud,
+		Service:   service,
+		Operation: operation,
+		Start:     time.Now(),
+	}
+
+	response, err := t.next.RoundTrip(req)
+	call.Duration = time.Since(call.Start)
+	if err != nil {
+		call.Error = err.Error()
+	} else {
+		if response.StatusCode == http.StatusTooManyRequests {
+			call.Throttled = 1
+		}
+		if response.StatusCode >= 400 {
+			call.Error = response.Status
+		}
+		call.Cached = response.Header.Get(discoverycache.HitHeader) != ""
+	}
+	Record(call)
+
+	return response, err
+}
+
+// describeRESTCall infers the service and operation from a google-style REST URL,
+// e.g. GET /compute/beta/projects/p/zones/z/instances => compute, GET projects.zones.instances
+func describeRESTCall(req *http.Request) (string, string) {
+	var segments []string
+	for _, s := range strings.Split(req.URL.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	service := strings.Split(req.URL.Host, ".")[0]
+	if service == "www" && len(segments) != 0 {
+		// e.g. www.googleapis.com/compute/...
+		service = segments[0]
+		segments = segments[1:]
+	}
+	if len(segments) != 0 {
+		// Skip the API version
+		segments = segments[1:]
+	}
+
+	// Paths alternate between collection and name; a trailing odd segment is a custom verb
+	var collections []string
+	for i := 0; i < len(segments); i += 2 {
+		collections = append(collections, segments[i])
+	}
+
+	return service, fmt.Sprintf("%s %s", req.Method, strings.Join(collections, "."))
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterlock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// lockFile is the name of the lock record, within the directory of the cluster in the state store
+const lockFile = "lock"
+
+// Lock records that a user has locked a cluster
+type Lock struct {
+	// Holder is the user holding the lock
+	Holder string `json:"holder"`
+	// Host is the machine the lock was taken from
+	Host string `json:"host,omitempty"`
+	// Reason is why the cluster was locked
+	Reason string `json:"reason,omitempty"`
+	// LockedAt is the time the cluster was locked
+	LockedAt time.Time `json:"lockedAt"`
+}
+
+// LockedError is returned when a cluster is locked by another user
+type LockedError struct {
+	Cluster string
+	Lock    *Lock
+}
+
+func (e *LockedError) Error() string {
+	msg := fmt.Sprintf("cluster %q is locked by %s since %s", e.Cluster, e.Lock.Holder, e.Lock.LockedAt.Format(time.RFC3339))
+	if e.Lock.Reason != "" {
+		msg += ": " + e.Lock.Reason
+	}
+	return msg
+}
+
+// Locker reads and writes the lock records of the clusters in a state store
+type Locker struct {
+	base vfs.Path
+}
+
+// NewLocker returns the Locker for the state store at base
+func NewLocker(base vfs.Path) *Locker {
+	return &Locker{base: base}
+}
+
+func (l *Locker) path(cluster string) vfs.Path {
+	return l.base.Join(cluster, lockFile)
+}
+
+// Get returns the lock on the cluster, or nil if it is not locked
+func (l *Locker) Get(cluster string) (*Lock, error) {
+	p := l.path(cluster)
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading lock %s: %v", p, err)
+	}
+
+	lock := &Lock{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("error parsing lock %s: %v", p, err)
+	}
+	return lock, nil
+}
+
+// Lock locks the cluster for user.  If user already holds the lock, the reason is updated.
+func (l *Locker) Lock(cluster string, user string, reason string) (*Lock, error) {
+	if user == "" {
+		return nil, fmt.Errorf("unable to determine the user locking the cluster")
+	}
+
+	existing, err := l.Get(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Holder != user {
+		return nil, &LockedError{Cluster: cluster, Lock: existing}
+	}
+
+	lock := &Lock{
+		Holder:   user,
+		Reason:   reason,
+		LockedAt: time.Now().UTC(),
+	}
+	lock.Host, _ = os.Hostname()
+	if existing != nil {
+		lock.LockedAt = existing.LockedAt
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error serializing lock: %v", err)
+	}
+
+	p := l.path(cluster)
+	if existing == nil {
+		// CreateFile fails if another user took the lock since we checked
+		err = p.CreateFile(bytes.NewReader(data), nil)
+	} else {
+		err = p.WriteFile(bytes.NewReader(data), nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error writing lock %s: %v", p, err)
+	}
+	return lock, nil
+}
+
+// Unlock removes the lock on the cluster.  Only the holder can unlock the cluster, unless force is set.
+func (l *Locker) Unlock(cluster string, user string, force bool) error {
+	existing, err := l.Get(cluster)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("cluster %q is not locked", cluster)
+	}
+	if existing.Holder != user && !force {
+		return fmt.Errorf("%v; only %s can unlock it, unless --force is used", &LockedError{Cluster: cluster, Lock: existing}, existing.Holder)
+	}
+
+	p := l.path(cluster)
+	if err := p.Remove(); err != nil {
+		return fmt.Errorf("error removing lock %s: %v", p, err)
+	}
+	return nil
+}
+
+// Check returns a LockedError if the cluster is locked by a user other than user
+func (l *Locker) Check(cluster string, user string) error {
+	lock, err := l.Get(cluster)
+	if err != nil {
+		return err
+	}
+	if lock != nil && lock.Holder != user {
+		return &LockedError{Cluster: cluster, Lock: lock}
+	}
+	return nil
+}
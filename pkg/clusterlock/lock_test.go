@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterlock
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestLockUnlock(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	locker := NewLocker(base)
+
+	if err := locker.Check("example.com", "alice"); err != nil {
+		t.Fatalf("expected unlocked cluster to pass check, got %v", err)
+	}
+
+	if _, err := locker.Lock("example.com", "alice", "upgrading etcd"); err != nil {
+		t.Fatalf("error locking cluster: %v", err)
+	}
+
+	if err := locker.Check("example.com", "alice"); err != nil {
+		t.Errorf("expected the lock holder to pass check, got %v", err)
+	}
+	err := locker.Check("example.com", "bob")
+	if _, ok := err.(*LockedError); !ok {
+		t.Fatalf("expected LockedError for another user, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "alice") || !strings.Contains(err.Error(), "upgrading etcd") {
+		t.Errorf("expected error to name the holder and reason, got %q", err)
+	}
+	if err := locker.Check("other.example.com", "bob"); err != nil {
+		t.Errorf("expected lock to apply only to its cluster, got %v", err)
+	}
+
+	if _, err := locker.Lock("example.com", "bob", ""); err == nil {
+		t.Errorf("expected locking a cluster locked by another user to fail")
+	}
+	lock, err := locker.Lock("example.com", "alice", "upgrading etcd, then kubernetes")
+	if err != nil {
+		t.Fatalf("error re-locking cluster: %v", err)
+	}
+	if lock.Reason != "upgrading etcd, then kubernetes" {
+		t.Errorf("expected reason to be updated, got %q", lock.Reason)
+	}
+
+	if err := locker.Unlock("example.com", "bob", false); err == nil {
+		t.Errorf("expected unlock by another user to fail")
+	}
+	if err := locker.Unlock("example.com", "alice", false); err != nil {
+		t.Fatalf("error unlocking cluster: %v", err)
+	}
+	if err := locker.Check("example.com", "bob"); err != nil {
+		t.Errorf("expected unlocked cluster to pass check, got %v", err)
+	}
+	if err := locker.Unlock("example.com", "alice", false); err == nil {
+		t.Errorf("expected unlocking an unlocked cluster to fail")
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	locker := NewLocker(base)
+
+	if _, err := locker.Lock("example.com", "alice", ""); err != nil {
+		t.Fatalf("error locking cluster: %v", err)
+	}
+	if err := locker.Unlock("example.com", "bob", true); err != nil {
+		t.Fatalf("error force unlocking cluster: %v", err)
+	}
+	if lock, _ := locker.Get("example.com"); lock != nil {
+		t.Errorf("expected cluster to be unlocked, got %+v", lock)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+)
+
+// RecordAudit appends an entry to the audit log of the state store.
+// The operation has already been applied, so failing to record it only logs a warning.
+func RecordAudit(f *util.Factory, clusterName string, operation string, object string, specDiff string) {
+	recordAuditEntry(f, &audit.Entry{
+		Cluster:   clusterName,
+		Operation: operation,
+		Object:    object,
+		Diff:      specDiff,
+	})
+}
+
+// RecordAuditChange records a change from before to after, keeping the previous version so that it can be reverted.
+// Either object may be nil, for a creation or a deletion.
+func RecordAuditChange(f *util.Factory, clusterName string, operation string, object string, before runtime.Object, after runtime.Object) {
+	previous, err := audit.Snapshot(before)
+	if err != nil {
+		glog.Warningf("unable to serialize object for the audit log: %v", err)
+	}
+	recordAuditEntry(f, &audit.Entry{
+		Cluster:   clusterName,
+		Operation: operation,
+		Object:    object,
+		Diff:      auditDiff(before, after),
+		Previous:  previous,
+	})
+}
+
+// recordAuditEntry appends e to the audit log, logging a warning on failure
+func recordAuditEntry(f *util.Factory, e *audit.Entry) {
+	operation, object := e.Operation, e.Object
+	log, err := f.AuditLog()
+	if err != nil {
+		glog.Warningf("unable to record %s of %s in the audit log: %v", operation, object, err)
+		return
+	}
+	if log == nil {
+		return
+	}
+
+	if err := log.Record(e); err != nil {
+		glog.Warningf("unable to record %s of %s in the audit log: %v", operation, object, err)
+	}
+}
+
+// auditDiff returns the difference between the YAML of two versions of an object; either may be nil
+func auditDiff(before runtime.Object, after runtime.Object) string {
+	d, err := audit.Diff(before, after)
+	if err != nil {
+		glog.Warningf("unable to serialize object for the audit log: %v", err)
+		return ""
+	}
+	return d
+}
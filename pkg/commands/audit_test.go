@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestRecordAuditChange(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+	f := util.NewFactory(&util.FactoryOptions{RegistryPath: "memfs://tests"})
+
+	before := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, MinSize: fi.Int32(2), MaxSize: fi.Int32(2)},
+	}
+	after := before.DeepCopy()
+	after.Spec.MaxSize = fi.Int32(5)
+
+	RecordAuditChange(f, "example.com", audit.OperationScale, "instancegroup/nodes", before, after)
+	RecordAudit(f, "example.com", audit.OperationRotate, "secret/encryptionconfig", "")
+
+	log, err := f.AuditLog()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := log.List("example.com")
+	if err != nil {
+		t.Fatalf("error listing audit log: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(entries))
+	}
+
+	change := entries[0]
+	if change.Operation != audit.OperationScale || change.Object != "instancegroup/nodes" {
+		t.Errorf("unexpected entry %s of %s", change.Operation, change.Object)
+	}
+	if !strings.Contains(change.Diff, "maxSize: 5") {
+		t.Errorf("expected the diff to contain the change, got %q", change.Diff)
+	}
+	if !strings.Contains(change.Previous, "maxSize: 2") {
+		t.Errorf("expected the previous version to be recorded, got %q", change.Previous)
+	}
+
+	if secret := entries[1]; secret.Diff != "" || secret.Previous != "" {
+		t.Errorf("expected no snapshot of a secret, got %+v", secret)
+	}
+}
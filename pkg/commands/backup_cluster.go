@@ -0,0 +1,566 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	kopsbase "k8s.io/kops"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/pkg/urls"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// ClusterBackupVersion is the version of the layout of cluster backup bundles
+	ClusterBackupVersion = "v1"
+
+	clusterBackupManifestFile = "backup.json"
+	clusterBackupClusterFile  = "cluster.yaml"
+	clusterBackupIGDir        = "instancegroup/"
+	// clusterBackupStateDir holds the keystore and secret store, by their path relative to the config base
+	clusterBackupStateDir = "state/"
+	// clusterBackupEtcdDir holds a backup of each etcd cluster, by its path relative to the backup store
+	clusterBackupEtcdDir = "etcd/"
+
+	etcdBackupMetaFile = "_etcd_backup.meta"
+	etcdControlDir     = "control"
+)
+
+// clusterStateStores returns the paths of the keystore and secret store that the clientset uses for the cluster, by
+// their directory in a backup; the cluster and instance group specs are captured separately, and everything else is
+// rebuilt by kops update cluster
+func clusterStateStores(clientset simple.Clientset, cluster *kops.Cluster) (map[string]vfs.Path, error) {
+	keyStore, err := clientset.KeyStore(cluster)
+	if err != nil {
+		return nil, err
+	}
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	stores := make(map[string]vfs.Path)
+	for dir, store := range map[string]interface{}{"pki": keyStore, "secrets": secretStore} {
+		hasVFSPath, ok := store.(fi.HasVFSPath)
+		if !ok {
+			return nil, fmt.Errorf("the %s of cluster %q are not kept in a VFS path, so cannot be backed up or restored", dir, cluster.ObjectMeta.Name)
+		}
+		stores[dir] = hasVFSPath.VFSPath()
+	}
+	return stores, nil
+}
+
+// ClusterBackupManifest describes a cluster backup bundle
+type ClusterBackupManifest struct {
+	// Version is the version of the layout of the bundle
+	Version string `json:"version"`
+	// ClusterName is the name of the cluster
+	ClusterName string `json:"clusterName"`
+	// ConfigBase is the state store path of the cluster when it was backed up
+	ConfigBase string `json:"configBase"`
+	// Timestamp is when the backup was taken
+	Timestamp time.Time `json:"timestamp"`
+	// KopsVersion is the version of kops that took the backup
+	KopsVersion string `json:"kopsVersion"`
+	// EtcdBackups is the name of the etcd-manager backup in the bundle, by etcd cluster
+	EtcdBackups map[string]string `json:"etcdBackups,omitempty"`
+}
+
+// ClusterBackup is a cluster backup bundle
+type ClusterBackup struct {
+	Manifest ClusterBackupManifest
+	// Files are the contents of the bundle, other than the manifest, by path
+	Files map[string][]byte
+}
+
+// BuildClusterBackup captures the cluster and instance group specs, the keystore and secret store, and the latest
+// etcd-manager backup of each etcd cluster
+func BuildClusterBackup(clientset simple.Clientset, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, timestamp time.Time) (*ClusterBackup, error) {
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return nil, err
+	}
+	stores, err := clusterStateStores(clientset, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &ClusterBackup{
+		Manifest: ClusterBackupManifest{
+			Version:     ClusterBackupVersion,
+			ClusterName: cluster.ObjectMeta.Name,
+			ConfigBase:  configBase.Path(),
+			Timestamp:   timestamp.UTC(),
+			KopsVersion: kopsbase.Version,
+			EtcdBackups: make(map[string]string),
+		},
+		Files: make(map[string][]byte),
+	}
+
+	data, err := kopscodecs.ToVersionedYaml(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing cluster: %v", err)
+	}
+	backup.Files[clusterBackupClusterFile] = data
+
+	for _, ig := range instanceGroups {
+		data, err := kopscodecs.ToVersionedYaml(ig)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+		backup.Files[clusterBackupIGDir+ig.ObjectMeta.Name+".yaml"] = data
+	}
+
+	for dir, store := range stores {
+		files, err := readTreeFiles(store)
+		if err != nil {
+			return nil, err
+		}
+		for relativePath, data := range files {
+			backup.Files[clusterBackupStateDir+dir+"/"+relativePath] = data
+		}
+	}
+
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		if etcdCluster.Manager == nil {
+			glog.Warningf("etcd cluster %q is not managed by etcd-manager, so its data cannot be backed up", etcdCluster.Name)
+			continue
+		}
+
+		backupStore, err := vfs.Context.BuildVfsPath(etcdBackupStore(cluster, configBase.Path(), etcdCluster))
+		if err != nil {
+			return nil, fmt.Errorf("error building backup store path for etcd cluster %q: %v", etcdCluster.Name, err)
+		}
+		files, err := readTreeFiles(backupStore)
+		if err != nil {
+			return nil, err
+		}
+
+		// etcd-manager names its backups by their timestamp, so the latest sorts last
+		var names []string
+		for relativePath := range files {
+			if path.Base(relativePath) == etcdBackupMetaFile && !strings.HasPrefix(relativePath, etcdControlDir+"/") {
+				names = append(names, path.Dir(relativePath))
+			}
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no backups of etcd cluster %q found in %s; etcd-manager takes backups periodically once the cluster is running", etcdCluster.Name, backupStore)
+		}
+		sort.Strings(names)
+		latest := names[len(names)-1]
+
+		for relativePath, data := range files {
+			if strings.HasPrefix(relativePath, latest+"/") {
+				backup.Files[clusterBackupEtcdDir+etcdCluster.Name+"/"+relativePath] = data
+			}
+		}
+		backup.Manifest.EtcdBackups[etcdCluster.Name] = latest
+	}
+
+	return backup, nil
+}
+
+// Write writes the bundle as a gzipped tar archive
+func (b *ClusterBackup) Write(w io.Writer) error {
+	manifest, err := json.MarshalIndent(&b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing backup manifest: %v", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	writeFile := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: b.Manifest.Timestamp,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing %s to backup: %v", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("error writing %s to backup: %v", name, err)
+		}
+		return nil
+	}
+
+	// The manifest comes first, so the bundle can be identified without reading all of it
+	if err := writeFile(clusterBackupManifestFile, manifest); err != nil {
+		return err
+	}
+	var names []string
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeFile(name, b.Files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error writing backup: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error writing backup: %v", err)
+	}
+	return nil
+}
+
+// ReadClusterBackup reads a bundle written by ClusterBackup.Write
+func ReadClusterBackup(data []byte) (*ClusterBackup, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	backup := &ClusterBackup{Files: make(map[string][]byte)}
+	foundManifest := false
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %v", err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from backup: %v", header.Name, err)
+		}
+		if header.Name == clusterBackupManifestFile {
+			if err := json.Unmarshal(data, &backup.Manifest); err != nil {
+				return nil, fmt.Errorf("error parsing backup manifest: %v", err)
+			}
+			foundManifest = true
+			continue
+		}
+		backup.Files[header.Name] = data
+	}
+
+	if !foundManifest {
+		return nil, fmt.Errorf("backup has no %s; is it a cluster backup?", clusterBackupManifestFile)
+	}
+	if backup.Manifest.Version != ClusterBackupVersion {
+		return nil, fmt.Errorf("backup has version %q, but this version of kops only supports %q", backup.Manifest.Version, ClusterBackupVersion)
+	}
+	return backup, nil
+}
+
+// RestoreClusterOptions controls how a cluster is rebuilt from a backup
+type RestoreClusterOptions struct {
+	// Region moves the cluster to another region; the zones of the cluster are moved to the zones with the same
+	// suffix in the new region
+	Region string
+}
+
+// RestoreClusterSpec returns the cluster and instance group specs of the backup, for a new state store: paths in
+// the state store of the backed up cluster are cleared, so they are defaulted from the new state store
+func RestoreClusterSpec(backup *ClusterBackup, options *RestoreClusterOptions) (*kops.Cluster, []*kops.InstanceGroup, error) {
+	data, found := backup.Files[clusterBackupClusterFile]
+	if !found {
+		return nil, nil, fmt.Errorf("backup has no %s", clusterBackupClusterFile)
+	}
+	obj, _, err := kopscodecs.ParseVersionedYaml(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing cluster from backup: %v", err)
+	}
+	cluster, ok := obj.(*kops.Cluster)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s in backup is not a cluster, but %T", clusterBackupClusterFile, obj)
+	}
+
+	var instanceGroups []*kops.InstanceGroup
+	var names []string
+	for name := range backup.Files {
+		if strings.HasPrefix(name, clusterBackupIGDir) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		obj, _, err := kopscodecs.ParseVersionedYaml(backup.Files[name])
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing %s from backup: %v", name, err)
+		}
+		ig, ok := obj.(*kops.InstanceGroup)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s in backup is not an instance group, but %T", name, obj)
+		}
+		instanceGroups = append(instanceGroups, ig)
+	}
+
+	// The cluster is created anew
+	cluster.ObjectMeta.CreationTimestamp.Reset()
+	for _, ig := range instanceGroups {
+		ig.ObjectMeta.CreationTimestamp.Reset()
+	}
+
+	inOldStateStore := func(p string) bool {
+		return p == backup.Manifest.ConfigBase || strings.HasPrefix(p, backup.Manifest.ConfigBase+"/")
+	}
+	cluster.Spec.ConfigBase = ""
+	if inOldStateStore(cluster.Spec.ConfigStore) {
+		cluster.Spec.ConfigStore = ""
+	}
+	// The keystore and secret store are restored to the new state store, so they are always mirrored from there
+	if cluster.Spec.KeyStore != "" && !inOldStateStore(cluster.Spec.KeyStore) {
+		glog.Warningf("cluster mirrored its keystore to %q; the restored cluster mirrors it to its own state store instead", cluster.Spec.KeyStore)
+	}
+	cluster.Spec.KeyStore = ""
+	if cluster.Spec.SecretStore != "" && !inOldStateStore(cluster.Spec.SecretStore) {
+		glog.Warningf("cluster mirrored its secrets to %q; the restored cluster mirrors them to its own state store instead", cluster.Spec.SecretStore)
+	}
+	cluster.Spec.SecretStore = ""
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		if etcdCluster.Backups != nil && inOldStateStore(etcdCluster.Backups.BackupStore) {
+			etcdCluster.Backups.BackupStore = ""
+		}
+	}
+
+	if cluster.Spec.NetworkID != "" {
+		glog.Warningf("cluster uses the existing network %q, which must exist where the cluster is restored", cluster.Spec.NetworkID)
+	}
+
+	if options.Region != "" {
+		if _, err := moveRegion(cluster, instanceGroups, options.Region); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cluster, instanceGroups, nil
+}
+
+// RestoreClusterState writes the keystore and secret store of the backup to the stores of the restored cluster, and
+// the etcd backups to its backup stores, with the commands that make etcd-manager restore them
+func RestoreClusterState(clientset simple.Clientset, backup *ClusterBackup, cluster *kops.Cluster, timestamp time.Time) error {
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil {
+		return err
+	}
+	stores, err := clusterStateStores(clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	for name, data := range backup.Files {
+		if !strings.HasPrefix(name, clusterBackupStateDir) {
+			continue
+		}
+		tokens := strings.SplitN(strings.TrimPrefix(name, clusterBackupStateDir), "/", 2)
+		store := stores[tokens[0]]
+		if store == nil || len(tokens) != 2 {
+			return fmt.Errorf("unexpected file %s in backup", name)
+		}
+		p := store.Join(tokens[1])
+		if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+			return fmt.Errorf("error writing %s: %v", p, err)
+		}
+	}
+
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		backupName := backup.Manifest.EtcdBackups[etcdCluster.Name]
+		if backupName == "" {
+			glog.Warningf("backup has no data for etcd cluster %q, so it will start empty", etcdCluster.Name)
+			continue
+		}
+		if etcdCluster.Manager == nil {
+			return fmt.Errorf("etcd cluster %q must be managed by etcd-manager to restore its backup", etcdCluster.Name)
+		}
+
+		backupStore, err := vfs.Context.BuildVfsPath(etcdBackupStore(cluster, configBase.Path(), etcdCluster))
+		if err != nil {
+			return fmt.Errorf("error building backup store path for etcd cluster %q: %v", etcdCluster.Name, err)
+		}
+
+		prefix := clusterBackupEtcdDir + etcdCluster.Name + "/"
+		for name, data := range backup.Files {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			p := backupStore.Join(strings.TrimPrefix(name, prefix))
+			if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+				return fmt.Errorf("error writing %s: %v", p, err)
+			}
+		}
+
+		etcdVersion := etcdCluster.Version
+		command := &etcdRestoreCommand{
+			Timestamp: strconv.FormatInt(timestamp.UnixNano(), 10),
+			RestoreBackup: &etcdRestoreBackupCommand{
+				ClusterSpec: &etcdCommandClusterSpec{
+					MemberCount: int32(len(etcdCluster.Members)),
+					EtcdVersion: etcdVersion,
+				},
+				Backup: backupName,
+			},
+		}
+		data, err := json.Marshal(command)
+		if err != nil {
+			return fmt.Errorf("error serializing restore command: %v", err)
+		}
+		p := backupStore.Join(etcdControlDir, command.Timestamp, "_command.json")
+		if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+			return fmt.Errorf("error writing %s: %v", p, err)
+		}
+	}
+
+	return nil
+}
+
+// etcdRestoreCommand is the command that etcd-manager-ctl restore-backup adds to the backup store; etcd-manager
+// restores the backup when it finds the command, before it starts the etcd cluster
+type etcdRestoreCommand struct {
+	Timestamp     string                    `json:"timestamp"`
+	RestoreBackup *etcdRestoreBackupCommand `json:"restoreBackup"`
+}
+
+type etcdRestoreBackupCommand struct {
+	ClusterSpec *etcdCommandClusterSpec `json:"clusterSpec"`
+	Backup      string                  `json:"backup"`
+}
+
+type etcdCommandClusterSpec struct {
+	MemberCount int32  `json:"memberCount"`
+	EtcdVersion string `json:"etcdVersion"`
+}
+
+// etcdBackupStore returns the backup store of the etcd cluster, which defaults to a path in the config base
+func etcdBackupStore(cluster *kops.Cluster, configBase string, etcdCluster *kops.EtcdClusterSpec) string {
+	if etcdCluster.Backups != nil && etcdCluster.Backups.BackupStore != "" {
+		return etcdCluster.Backups.BackupStore
+	}
+	return urls.Join(configBase, "backups", "etcd", etcdCluster.Name)
+}
+
+// readTreeFiles reads the files below the path, by their path relative to it
+func readTreeFiles(base vfs.Path) (map[string][]byte, error) {
+	paths, err := base.ReadTree()
+	if err != nil {
+		return nil, fmt.Errorf("error listing files in %s: %v", base, err)
+	}
+
+	files := make(map[string][]byte)
+	for _, p := range paths {
+		relativePath, err := vfs.RelativePath(base, p)
+		if err != nil {
+			return nil, err
+		}
+		if relativePath == "" {
+			continue
+		}
+		data, err := p.ReadFile()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", p, err)
+		}
+		files[relativePath] = data
+	}
+	return files, nil
+}
+
+// moveRegion moves the zones of the cluster and its instance groups to the zones with the same suffix in region,
+// returning the new name of each zone
+func moveRegion(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, region string) (map[string]string, error) {
+	oldRegion := ""
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Region != "" {
+			oldRegion = subnet.Region
+			break
+		}
+		if subnet.Zone != "" {
+			oldRegion = zoneRegion(kops.CloudProviderID(cluster.Spec.CloudProvider), subnet.Zone)
+			break
+		}
+	}
+	if oldRegion == "" {
+		return nil, fmt.Errorf("cannot determine the region of cluster %q from its subnets", cluster.ObjectMeta.Name)
+	}
+
+	zones := make(map[string]string)
+	moveZone := func(zone string) (string, error) {
+		if !strings.HasPrefix(zone, oldRegion) {
+			return "", fmt.Errorf("zone %q is not in region %q", zone, oldRegion)
+		}
+		moved := region + strings.TrimPrefix(zone, oldRegion)
+		zones[zone] = moved
+		return moved, nil
+	}
+
+	for i := range cluster.Spec.Subnets {
+		subnet := &cluster.Spec.Subnets[i]
+		if subnet.ProviderID != "" {
+			return nil, fmt.Errorf("subnet %q is an existing subnet (%s); cannot move it to region %q", subnet.Name, subnet.ProviderID, region)
+		}
+		if subnet.Region != "" {
+			subnet.Region = region
+		}
+		if subnet.Zone != "" {
+			zone, err := moveZone(subnet.Zone)
+			if err != nil {
+				return nil, err
+			}
+			subnet.Zone = zone
+		}
+	}
+	if cluster.Spec.NetworkID != "" {
+		return nil, fmt.Errorf("cluster uses the existing network %q; cannot move it to region %q", cluster.Spec.NetworkID, region)
+	}
+
+	for _, ig := range instanceGroups {
+		for i, zone := range ig.Spec.Zones {
+			moved, err := moveZone(zone)
+			if err != nil {
+				return nil, fmt.Errorf("instance group %q: %v", ig.ObjectMeta.Name, err)
+			}
+			ig.Spec.Zones[i] = moved
+		}
+	}
+
+	return zones, nil
+}
+
+// zoneRegion returns the region of a zone, e.g. us-east-1 for the AWS zone us-east-1a, or us-central1 for the GCE
+// zone us-central1-a
+func zoneRegion(cloudProvider kops.CloudProviderID, zone string) string {
+	if cloudProvider == kops.CloudProviderAWS {
+		return zone[:len(zone)-1]
+	}
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return ""
+}
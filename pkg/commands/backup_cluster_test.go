@@ -0,0 +1,217 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func writeTestFile(t *testing.T, p string, data string) {
+	vfsPath, err := vfs.Context.BuildVfsPath(p)
+	if err != nil {
+		t.Fatalf("error building path %q: %v", p, err)
+	}
+	if err := vfsPath.WriteFile(bytes.NewReader([]byte(data)), nil); err != nil {
+		t.Fatalf("error writing %s: %v", p, err)
+	}
+}
+
+func readTestFile(t *testing.T, p string) string {
+	vfsPath, err := vfs.Context.BuildVfsPath(p)
+	if err != nil {
+		t.Fatalf("error building path %q: %v", p, err)
+	}
+	data, err := vfsPath.ReadFile()
+	if err != nil {
+		t.Fatalf("error reading %s: %v", p, err)
+	}
+	return string(data)
+}
+
+func newTestClientset(t *testing.T, stateStore string) simple.Clientset {
+	basePath, err := vfs.Context.BuildVfsPath(stateStore)
+	if err != nil {
+		t.Fatalf("error building path %q: %v", stateStore, err)
+	}
+	return vfsclientset.NewVFSClientset(basePath, true)
+}
+
+func TestClusterBackupRoundTrip(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod.example.com", CreationTimestamp: metav1.Now()},
+		Spec: kops.ClusterSpec{
+			CloudProvider:    "aws",
+			ConfigBase:       "memfs://old/prod.example.com",
+			KeyStore:         "memfs://old/prod.example.com/pki",
+			SecretStore:      "memfs://shared/secrets",
+			NetworkCIDR:      "172.20.0.0/16",
+			MasterPublicName: "api.prod.example.com",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-east-1a", Zone: "us-east-1a", CIDR: "172.20.32.0/19"},
+			},
+			EtcdClusters: []*kops.EtcdClusterSpec{
+				{
+					Name:    "main",
+					Version: "3.2.24",
+					Manager: &kops.EtcdManagerSpec{},
+					Members: []*kops.EtcdMemberSpec{{Name: "a"}},
+				},
+				{Name: "legacy"},
+			},
+		},
+	}
+	groups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Zones: []string{"us-east-1a"}},
+		},
+	}
+
+	writeTestFile(t, "memfs://old/prod.example.com/pki/private/ca/keyset.yaml", "ca-key")
+	writeTestFile(t, "memfs://old/prod.example.com/secrets/admin", "admin-secret")
+	writeTestFile(t, "memfs://old/prod.example.com/instancegroup/nodes", "ignored")
+	etcdBackups := "memfs://old/prod.example.com/backups/etcd/main/"
+	writeTestFile(t, etcdBackups+"2018-06-01T10:00:00Z-000001/etcd.backup.gz", "old-data")
+	writeTestFile(t, etcdBackups+"2018-06-01T10:00:00Z-000001/_etcd_backup.meta", "old-meta")
+	writeTestFile(t, etcdBackups+"2018-06-01T11:00:00Z-000002/etcd.backup.gz", "new-data")
+	writeTestFile(t, etcdBackups+"2018-06-01T11:00:00Z-000002/_etcd_backup.meta", "new-meta")
+	writeTestFile(t, etcdBackups+"2018-06-01T12:00:00Z-000003/etcd.backup.gz", "incomplete")
+	writeTestFile(t, etcdBackups+"control/etcd-cluster-spec", "spec")
+
+	// The secret store is mirrored outside the state store, but is read from the state store itself
+	writeTestFile(t, "memfs://shared/secrets/admin", "mirrored-secret")
+
+	clientset := newTestClientset(t, "memfs://old")
+	backup, err := BuildClusterBackup(clientset, cluster, groups, time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error building backup: %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := backup.Write(&b); err != nil {
+		t.Fatalf("unexpected error writing backup: %v", err)
+	}
+	backup, err = ReadClusterBackup(b.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error reading backup: %v", err)
+	}
+
+	if backup.Manifest.ClusterName != "prod.example.com" || backup.Manifest.ConfigBase != "memfs://old/prod.example.com" {
+		t.Errorf("unexpected manifest %+v", backup.Manifest)
+	}
+	if backup.Manifest.EtcdBackups["main"] != "2018-06-01T11:00:00Z-000002" {
+		t.Errorf("expected the latest complete etcd backup, got %v", backup.Manifest.EtcdBackups)
+	}
+	var names []string
+	for name := range backup.Files {
+		names = append(names, name)
+	}
+	expectedFiles := []string{
+		"cluster.yaml",
+		"instancegroup/nodes.yaml",
+		"state/pki/private/ca/keyset.yaml",
+		"state/secrets/admin",
+		"etcd/main/2018-06-01T11:00:00Z-000002/etcd.backup.gz",
+		"etcd/main/2018-06-01T11:00:00Z-000002/_etcd_backup.meta",
+	}
+	if len(backup.Files) != len(expectedFiles) {
+		t.Errorf("expected files %v, got %v", expectedFiles, names)
+	}
+	for _, name := range expectedFiles {
+		if _, found := backup.Files[name]; !found {
+			t.Errorf("expected %s in backup, got %v", name, names)
+		}
+	}
+
+	restored, restoredGroups, err := RestoreClusterSpec(backup, &RestoreClusterOptions{Region: "eu-west-1"})
+	if err != nil {
+		t.Fatalf("unexpected error restoring spec: %v", err)
+	}
+	if restored.ObjectMeta.Name != "prod.example.com" || !restored.ObjectMeta.CreationTimestamp.IsZero() {
+		t.Errorf("unexpected metadata %+v", restored.ObjectMeta)
+	}
+	if restored.Spec.ConfigBase != "" || restored.Spec.KeyStore != "" {
+		t.Errorf("expected paths in the old state store to be cleared, got %q and %q", restored.Spec.ConfigBase, restored.Spec.KeyStore)
+	}
+	if restored.Spec.SecretStore != "" {
+		t.Errorf("expected the secret store to be mirrored from the new state store, got %q", restored.Spec.SecretStore)
+	}
+	if restored.Spec.Subnets[0].Zone != "eu-west-1a" {
+		t.Errorf("expected subnet to move to eu-west-1a, got %q", restored.Spec.Subnets[0].Zone)
+	}
+	if len(restoredGroups) != 1 || restoredGroups[0].Spec.Zones[0] != "eu-west-1a" {
+		t.Errorf("expected instance group to move to eu-west-1a, got %+v", restoredGroups)
+	}
+
+	// The state store sets the config base when the restored cluster is read back
+	restored.Spec.ConfigBase = "memfs://new/prod.example.com"
+	if err := RestoreClusterState(newTestClientset(t, "memfs://new"), backup, restored, time.Unix(0, 1000)); err != nil {
+		t.Fatalf("unexpected error restoring state: %v", err)
+	}
+
+	if data := readTestFile(t, "memfs://new/prod.example.com/pki/private/ca/keyset.yaml"); data != "ca-key" {
+		t.Errorf("unexpected restored key %q", data)
+	}
+	if data := readTestFile(t, "memfs://new/prod.example.com/secrets/admin"); data != "admin-secret" {
+		t.Errorf("unexpected restored secret %q", data)
+	}
+	newEtcdBackups := "memfs://new/prod.example.com/backups/etcd/main/"
+	if data := readTestFile(t, newEtcdBackups+"2018-06-01T11:00:00Z-000002/etcd.backup.gz"); data != "new-data" {
+		t.Errorf("unexpected restored etcd backup %q", data)
+	}
+
+	command := &etcdRestoreCommand{}
+	if err := json.Unmarshal([]byte(readTestFile(t, newEtcdBackups+"control/1000/_command.json")), command); err != nil {
+		t.Fatalf("error parsing restore command: %v", err)
+	}
+	if command.RestoreBackup == nil || command.RestoreBackup.Backup != "2018-06-01T11:00:00Z-000002" {
+		t.Errorf("unexpected restore command %+v", command)
+	} else if command.RestoreBackup.ClusterSpec.MemberCount != 1 || command.RestoreBackup.ClusterSpec.EtcdVersion != "3.2.24" {
+		t.Errorf("unexpected restore cluster spec %+v", command.RestoreBackup.ClusterSpec)
+	}
+}
+
+func TestBuildClusterBackupWithoutEtcdBackup(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod.example.com"},
+		Spec: kops.ClusterSpec{
+			EtcdClusters: []*kops.EtcdClusterSpec{
+				{Name: "main", Manager: &kops.EtcdManagerSpec{}},
+			},
+		},
+	}
+	cluster.Spec.ConfigBase = "memfs://old/prod.example.com"
+
+	_, err := BuildClusterBackup(newTestClientset(t, "memfs://old"), cluster, nil, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "no backups of etcd cluster \"main\"") {
+		t.Errorf("expected missing etcd backup error, got %v", err)
+	}
+}
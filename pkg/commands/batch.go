@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/tables"
+)
+
+// ClusterSelector chooses the clusters in the state store that a batch operation applies to
+type ClusterSelector struct {
+	// All selects every cluster
+	All bool
+	// NameGlob selects clusters whose name matches the glob, e.g. *.prod.example.com
+	NameGlob string
+	// LabelSelector selects clusters whose labels match the selector, e.g. env=prod,team!=payments
+	LabelSelector string
+	// Group selects the clusters in the group of per-region clusters
+	Group string
+}
+
+// IsSet returns true if any selection was requested, i.e. this is a batch operation
+func (s *ClusterSelector) IsSet() bool {
+	return s.All || s.NameGlob != "" || s.LabelSelector != "" || s.Group != ""
+}
+
+// Select returns the names of the matching clusters, sorted by name.
+// Name, label and group conditions must all match, if specified.
+func (s *ClusterSelector) Select(clusters []kops.Cluster) ([]string, error) {
+	if s.All && (s.NameGlob != "" || s.LabelSelector != "" || s.Group != "") {
+		return nil, fmt.Errorf("cannot combine selecting all clusters with a name, label or group selector")
+	}
+
+	if s.NameGlob != "" {
+		if _, err := path.Match(s.NameGlob, ""); err != nil {
+			return nil, fmt.Errorf("invalid cluster name glob %q: %v", s.NameGlob, err)
+		}
+	}
+
+	selector := labels.Everything()
+	if s.LabelSelector != "" {
+		parsed, err := labels.Parse(s.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster label selector %q: %v", s.LabelSelector, err)
+		}
+		selector = parsed
+	}
+
+	var names []string
+	for i := range clusters {
+		cluster := &clusters[i]
+		if s.NameGlob != "" {
+			// Error already checked above
+			if match, _ := path.Match(s.NameGlob, cluster.ObjectMeta.Name); !match {
+				continue
+			}
+		}
+		if !selector.Matches(labels.Set(cluster.ObjectMeta.Labels)) {
+			continue
+		}
+		if s.Group != "" && ClusterGroup(cluster) != s.Group {
+			continue
+		}
+		names = append(names, cluster.ObjectMeta.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// BatchResult is the outcome of a batch operation on a single cluster
+type BatchResult struct {
+	Cluster  string
+	Duration time.Duration
+	Error    error
+}
+
+// RunBatch runs fn against each of the clusters, at most parallelism at a time.
+// When running in parallel, the output for each cluster is buffered and written when it completes,
+// so that the output of different clusters is not interleaved.
+// Results are returned in the same order as clusterNames.
+func RunBatch(out io.Writer, clusterNames []string, parallelism int, fn func(clusterName string, out io.Writer) error) []*BatchResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make([]*BatchResult, len(clusterNames))
+
+	var outMutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, parallelism)
+
+	for i, clusterName := range clusterNames {
+		i, clusterName := i, clusterName
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			var clusterOut io.Writer = out
+			var buffer bytes.Buffer
+			if parallelism > 1 {
+				clusterOut = &buffer
+			} else {
+				fmt.Fprintf(out, "\n=== %s\n\n", clusterName)
+			}
+
+			start := time.Now()
+			err := fn(clusterName, clusterOut)
+			result := &BatchResult{
+				Cluster:  clusterName,
+				Duration: time.Since(start),
+				Error:    err,
+			}
+			results[i] = result
+
+			if parallelism > 1 {
+				outMutex.Lock()
+				defer outMutex.Unlock()
+				fmt.Fprintf(out, "\n=== %s\n\n", clusterName)
+				out.Write(buffer.Bytes())
+			}
+			if err != nil {
+				fmt.Fprintf(out, "\nerror: %v\n", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// WriteBatchReport writes a summary table of the batch results
+func WriteBatchReport(out io.Writer, results []*BatchResult) error {
+	t := &tables.Table{}
+	t.AddColumn("CLUSTER", func(r *BatchResult) string {
+		return r.Cluster
+	})
+	t.AddColumn("RESULT", func(r *BatchResult) string {
+		if r.Error != nil {
+			return "Failed"
+		}
+		return "Succeeded"
+	})
+	t.AddColumn("DURATION", func(r *BatchResult) string {
+		return r.Duration.Round(time.Second).String()
+	})
+	t.AddColumn("ERROR", func(r *BatchResult) string {
+		if r.Error != nil {
+			return r.Error.Error()
+		}
+		return ""
+	})
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != nil {
+			failed++
+		}
+	}
+
+	fmt.Fprintf(out, "\nSUMMARY\n")
+	if err := t.Render(results, out, "CLUSTER", "RESULT", "DURATION", "ERROR"); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%d clusters, %d succeeded, %d failed\n", len(results), len(results)-failed, failed)
+	return nil
+}
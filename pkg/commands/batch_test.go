@@ -0,0 +1,148 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestClusterSelector_Select(t *testing.T) {
+	clusters := []kops.Cluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b.prod.example.com", Labels: map[string]string{"env": "prod", "team": "payments", kops.LabelClusterGroup: "payments"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a.prod.example.com", Labels: map[string]string{"env": "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a.staging.example.com", Labels: map[string]string{"env": "staging"}}},
+	}
+
+	grid := []struct {
+		Selector ClusterSelector
+		Expected []string
+	}{
+		{
+			Selector: ClusterSelector{All: true},
+			Expected: []string{"a.prod.example.com", "a.staging.example.com", "b.prod.example.com"},
+		},
+		{
+			Selector: ClusterSelector{NameGlob: "*.prod.example.com"},
+			Expected: []string{"a.prod.example.com", "b.prod.example.com"},
+		},
+		{
+			Selector: ClusterSelector{LabelSelector: "env=prod,team!=payments"},
+			Expected: []string{"a.prod.example.com"},
+		},
+		{
+			Selector: ClusterSelector{NameGlob: "a.*", LabelSelector: "env=staging"},
+			Expected: []string{"a.staging.example.com"},
+		},
+		{
+			Selector: ClusterSelector{Group: "payments"},
+			Expected: []string{"b.prod.example.com"},
+		},
+		{
+			Selector: ClusterSelector{NameGlob: "a.*", Group: "payments"},
+		},
+		{
+			Selector: ClusterSelector{NameGlob: "*.dev.example.com"},
+		},
+	}
+	for _, g := range grid {
+		actual, err := g.Selector.Select(clusters)
+		if err != nil {
+			t.Errorf("unexpected error for %+v: %v", g.Selector, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("selector %+v: expected %v, got %v", g.Selector, g.Expected, actual)
+		}
+	}
+
+	for _, s := range []ClusterSelector{
+		{All: true, NameGlob: "*"},
+		{All: true, Group: "payments"},
+		{NameGlob: "["},
+		{LabelSelector: "env in (prod"},
+	} {
+		if _, err := s.Select(clusters); err == nil {
+			t.Errorf("expected error for selector %+v", s)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	clusterNames := []string{"a", "b", "c", "d"}
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	fn := func(clusterName string, out io.Writer) error {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		fmt.Fprintf(out, "updating %s\n", clusterName)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+
+		if clusterName == "c" {
+			return fmt.Errorf("validation failed")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	results := RunBatch(&out, clusterNames, 2, fn)
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent operations, got %d", maxRunning)
+	}
+	if len(results) != len(clusterNames) {
+		t.Fatalf("expected %d results, got %d", len(clusterNames), len(results))
+	}
+	for i, r := range results {
+		if r.Cluster != clusterNames[i] {
+			t.Errorf("expected results in cluster order, got %q at %d", r.Cluster, i)
+		}
+		if (r.Error != nil) != (r.Cluster == "c") {
+			t.Errorf("unexpected error for %q: %v", r.Cluster, r.Error)
+		}
+	}
+	for _, clusterName := range clusterNames {
+		if !strings.Contains(out.String(), "=== "+clusterName+"\n\nupdating "+clusterName+"\n") {
+			t.Errorf("expected output for %q to be grouped, got:\n%s", clusterName, out.String())
+		}
+	}
+
+	var report bytes.Buffer
+	if err := WriteBatchReport(&report, results); err != nil {
+		t.Fatalf("error writing report: %v", err)
+	}
+	if !strings.Contains(report.String(), "4 clusters, 3 succeeded, 1 failed") {
+		t.Errorf("unexpected report:\n%s", report.String())
+	}
+}
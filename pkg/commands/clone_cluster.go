@@ -0,0 +1,198 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+)
+
+// CloneClusterOptions controls how a cluster spec is rewritten when it is cloned
+type CloneClusterOptions struct {
+	// To is the name of the new cluster
+	To string
+
+	// DNSZone overrides the DNS zone of the new cluster; by default the zone of the source cluster is kept
+	DNSZone string
+
+	// NetworkCIDR moves the new cluster to a different network range; subnet CIDRs are moved to the same offsets within the new range
+	NetworkCIDR string
+}
+
+// CloneClusterSpec builds the spec for a copy of the cluster and its instance groups, under a new name.
+// References to the source cluster name (DNS names, state store paths) are rewritten to the new name.
+// Nothing is copied from the keystore or secret store, so the new cluster will generate its own secrets.
+func CloneClusterSpec(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, options *CloneClusterOptions) (*kops.Cluster, []*kops.InstanceGroup, error) {
+	from := cluster.ObjectMeta.Name
+	to := options.To
+	if to == "" {
+		return nil, nil, fmt.Errorf("name of new cluster is required")
+	}
+	if to == from {
+		return nil, nil, fmt.Errorf("cannot clone cluster %q to itself", from)
+	}
+
+	clone := &kops.Cluster{}
+	clone.ObjectMeta = metav1.ObjectMeta{
+		Name:        to,
+		Labels:      copyStringMap(cluster.ObjectMeta.Labels),
+		Annotations: copyStringMap(cluster.ObjectMeta.Annotations),
+	}
+	cluster.Spec.DeepCopyInto(&clone.Spec)
+
+	rename := func(s string) string {
+		return renameClusterReferences(s, from, to)
+	}
+
+	clone.Spec.ConfigBase = rename(clone.Spec.ConfigBase)
+	clone.Spec.ConfigStore = rename(clone.Spec.ConfigStore)
+	clone.Spec.KeyStore = rename(clone.Spec.KeyStore)
+	clone.Spec.SecretStore = rename(clone.Spec.SecretStore)
+	clone.Spec.MasterPublicName = rename(clone.Spec.MasterPublicName)
+	clone.Spec.MasterInternalName = rename(clone.Spec.MasterInternalName)
+	for _, etcdCluster := range clone.Spec.EtcdClusters {
+		if etcdCluster.Backups != nil {
+			etcdCluster.Backups.BackupStore = rename(etcdCluster.Backups.BackupStore)
+		}
+	}
+
+	if options.DNSZone != "" {
+		clone.Spec.DNSZone = options.DNSZone
+	} else if dns.IsGossipHostname(to) {
+		clone.Spec.DNSZone = ""
+	} else if clone.Spec.DNSZone != "" && !strings.Contains(clone.Spec.DNSZone, ".") {
+		// A zone ID (rather than a name); we can't check it, so assume the user knows best
+	} else if clone.Spec.DNSZone != "" && !strings.HasSuffix(to, "."+strings.TrimSuffix(clone.Spec.DNSZone, ".")) {
+		return nil, nil, fmt.Errorf("cluster name %q is not in DNS zone %q; specify the zone for the new cluster", to, clone.Spec.DNSZone)
+	}
+
+	if options.NetworkCIDR != "" {
+		if err := moveNetwork(clone, options.NetworkCIDR); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var cloneGroups []*kops.InstanceGroup
+	for _, ig := range instanceGroups {
+		cloneGroup := &kops.InstanceGroup{}
+		cloneGroup.ObjectMeta = metav1.ObjectMeta{
+			Name:        ig.ObjectMeta.Name,
+			Labels:      copyStringMap(ig.ObjectMeta.Labels),
+			Annotations: copyStringMap(ig.ObjectMeta.Annotations),
+		}
+		if cloneGroup.ObjectMeta.Labels == nil {
+			cloneGroup.ObjectMeta.Labels = make(map[string]string)
+		}
+		cloneGroup.ObjectMeta.Labels[kops.LabelClusterName] = to
+		ig.Spec.DeepCopyInto(&cloneGroup.Spec)
+		cloneGroups = append(cloneGroups, cloneGroup)
+	}
+
+	return clone, cloneGroups, nil
+}
+
+// renameClusterReferences rewrites a DNS name or VFS path that refers to the cluster named from, so that it refers to to instead
+func renameClusterReferences(s string, from string, to string) string {
+	if s == from {
+		return to
+	}
+	if strings.HasSuffix(s, "."+from) {
+		return strings.TrimSuffix(s, from) + to
+	}
+	// State store paths, e.g. s3://bucket/<cluster>/backups/etcd/main
+	if strings.HasSuffix(s, "/"+from) {
+		return strings.TrimSuffix(s, from) + to
+	}
+	return strings.Replace(s, "/"+from+"/", "/"+to+"/", -1)
+}
+
+// moveNetwork changes the cluster network CIDR, moving each subnet to the same offset in the new network
+func moveNetwork(cluster *kops.Cluster, networkCIDR string) error {
+	_, newNetwork, err := net.ParseCIDR(networkCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid network CIDR %q: %v", networkCIDR, err)
+	}
+	if newNetwork.IP.To4() == nil {
+		return fmt.Errorf("network CIDR %q is not an IPv4 CIDR", networkCIDR)
+	}
+
+	if cluster.Spec.NetworkCIDR == "" {
+		// e.g. GCE, where subnets are allocated by the cloud
+		cluster.Spec.NetworkCIDR = networkCIDR
+		return nil
+	}
+
+	_, oldNetwork, err := net.ParseCIDR(cluster.Spec.NetworkCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid network CIDR %q in source cluster: %v", cluster.Spec.NetworkCIDR, err)
+	}
+
+	oldBase := binary.BigEndian.Uint32(oldNetwork.IP.To4())
+	newBase := binary.BigEndian.Uint32(newNetwork.IP.To4())
+
+	for i := range cluster.Spec.Subnets {
+		subnet := &cluster.Spec.Subnets[i]
+		if subnet.CIDR == "" {
+			continue
+		}
+		if subnet.ProviderID != "" {
+			return fmt.Errorf("subnet %q is an existing subnet (%s); cannot move it to network %q", subnet.Name, subnet.ProviderID, networkCIDR)
+		}
+
+		ip, subnetNet, err := net.ParseCIDR(subnet.CIDR)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %q for subnet %q: %v", subnet.CIDR, subnet.Name, err)
+		}
+		if !oldNetwork.Contains(ip) {
+			return fmt.Errorf("subnet %q CIDR %q is not within network %q; cannot move it to %q", subnet.Name, subnet.CIDR, cluster.Spec.NetworkCIDR, networkCIDR)
+		}
+
+		offset := binary.BigEndian.Uint32(subnetNet.IP.To4()) - oldBase
+		moved := make(net.IP, 4)
+		binary.BigEndian.PutUint32(moved, newBase+offset)
+		movedNet := &net.IPNet{IP: moved, Mask: subnetNet.Mask}
+
+		last := make(net.IP, 4)
+		ones, bits := subnetNet.Mask.Size()
+		binary.BigEndian.PutUint32(last, newBase+offset+(uint32(1)<<uint(bits-ones))-1)
+		if !newNetwork.Contains(moved) || !newNetwork.Contains(last) {
+			return fmt.Errorf("subnet %q CIDR %q does not fit at the same offset in network %q", subnet.Name, subnet.CIDR, networkCIDR)
+		}
+
+		subnet.CIDR = movedNet.String()
+	}
+
+	cluster.Spec.NetworkCIDR = newNetwork.String()
+	return nil
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func buildCloneSource() (*kops.Cluster, []*kops.InstanceGroup) {
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "prod.example.com",
+			CreationTimestamp: metav1.Now(),
+			Labels:            map[string]string{"env": "prod"},
+		},
+		Spec: kops.ClusterSpec{
+			ConfigBase:         "s3://state/prod.example.com",
+			MasterPublicName:   "api.prod.example.com",
+			MasterInternalName: "api.internal.prod.example.com",
+			DNSZone:            "example.com",
+			NetworkCIDR:        "172.20.0.0/16",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", CIDR: "172.20.32.0/19"},
+				{Name: "utility-us-test-1a", CIDR: "172.20.4.0/22"},
+			},
+			EtcdClusters: []*kops.EtcdClusterSpec{
+				{Name: "main", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://state/prod.example.com/backups/etcd/main"}},
+			},
+		},
+	}
+	groups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "nodes",
+				Labels: map[string]string{kops.LabelClusterName: "prod.example.com"},
+			},
+			Spec: kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Subnets: []string{"us-test-1a"}},
+		},
+	}
+	return cluster, groups
+}
+
+func TestCloneClusterSpec(t *testing.T) {
+	cluster, groups := buildCloneSource()
+
+	clone, cloneGroups, err := CloneClusterSpec(cluster, groups, &CloneClusterOptions{
+		To:          "staging.example.com",
+		NetworkCIDR: "10.1.0.0/16",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if clone.ObjectMeta.Name != "staging.example.com" || !clone.ObjectMeta.CreationTimestamp.IsZero() {
+		t.Errorf("unexpected metadata %+v", clone.ObjectMeta)
+	}
+	if clone.ObjectMeta.Labels["env"] != "prod" {
+		t.Errorf("expected labels to be copied, got %v", clone.ObjectMeta.Labels)
+	}
+
+	expected := map[string]string{
+		"ConfigBase":         "s3://state/staging.example.com",
+		"MasterPublicName":   "api.staging.example.com",
+		"MasterInternalName": "api.internal.staging.example.com",
+		"DNSZone":            "example.com",
+		"NetworkCIDR":        "10.1.0.0/16",
+		"Subnet":             "10.1.32.0/19",
+		"UtilitySubnet":      "10.1.4.0/22",
+		"BackupStore":        "s3://state/staging.example.com/backups/etcd/main",
+		"ClusterLabel":       "staging.example.com",
+	}
+	actual := map[string]string{
+		"ConfigBase":         clone.Spec.ConfigBase,
+		"MasterPublicName":   clone.Spec.MasterPublicName,
+		"MasterInternalName": clone.Spec.MasterInternalName,
+		"DNSZone":            clone.Spec.DNSZone,
+		"NetworkCIDR":        clone.Spec.NetworkCIDR,
+		"Subnet":             clone.Spec.Subnets[0].CIDR,
+		"UtilitySubnet":      clone.Spec.Subnets[1].CIDR,
+		"BackupStore":        clone.Spec.EtcdClusters[0].Backups.BackupStore,
+		"ClusterLabel":       cloneGroups[0].ObjectMeta.Labels[kops.LabelClusterName],
+	}
+	for k, v := range expected {
+		if actual[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, actual[k])
+		}
+	}
+
+	// The source must not be modified
+	if cluster.Spec.Subnets[0].CIDR != "172.20.32.0/19" || groups[0].ObjectMeta.Labels[kops.LabelClusterName] != "prod.example.com" {
+		t.Errorf("source cluster was modified by clone")
+	}
+}
+
+func TestCloneClusterSpec_Errors(t *testing.T) {
+	grid := []*CloneClusterOptions{
+		{To: "prod.example.com"},
+		{To: "staging.example.net"},
+		{To: "staging.example.com", NetworkCIDR: "10.1.0.0/20"},
+		{To: "staging.example.com", NetworkCIDR: "not-a-cidr"},
+	}
+	for _, options := range grid {
+		cluster, groups := buildCloneSource()
+		if _, _, err := CloneClusterSpec(cluster, groups, options); err == nil {
+			t.Errorf("expected error cloning with %+v", options)
+		}
+	}
+
+	// Changing zone, or moving to gossip DNS, is fine
+	for _, options := range []*CloneClusterOptions{
+		{To: "staging.example.net", DNSZone: "example.net"},
+		{To: "staging.k8s.local"},
+	} {
+		cluster, groups := buildCloneSource()
+		if _, _, err := CloneClusterSpec(cluster, groups, options); err != nil {
+			t.Errorf("unexpected error cloning with %+v: %v", options, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// ClusterGroup returns the group of per-region clusters that the cluster belongs to, or "" if it is not in a group
+func ClusterGroup(cluster *kops.Cluster) string {
+	return cluster.ObjectMeta.Labels[kops.LabelClusterGroup]
+}
+
+// ClusterRegion returns the region of the cluster, from its subnets, or "" if it cannot be determined
+func ClusterRegion(cluster *kops.Cluster) string {
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Region != "" {
+			return subnet.Region
+		}
+		if subnet.Zone != "" {
+			return zoneRegion(kops.CloudProviderID(cluster.Spec.CloudProvider), subnet.Zone)
+		}
+	}
+	return ""
+}
+
+// ClusterGroupMembers returns the clusters in the group, sorted by name
+func ClusterGroupMembers(clusters []kops.Cluster, group string) []*kops.Cluster {
+	var members []*kops.Cluster
+	for i := range clusters {
+		if ClusterGroup(&clusters[i]) == group {
+			members = append(members, &clusters[i])
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ObjectMeta.Name < members[j].ObjectMeta.Name
+	})
+	return members
+}
+
+// JoinClusterGroup adds the cluster to the group.  A group has at most one cluster in each region, and the
+// labels shared by all the existing members of the group are copied to the cluster, unless it sets them itself.
+func JoinClusterGroup(cluster *kops.Cluster, group string, clusters []kops.Cluster) error {
+	if errs := validation.IsValidLabelValue(group); len(errs) != 0 {
+		return fmt.Errorf("invalid cluster group %q: %s", group, strings.Join(errs, "; "))
+	}
+
+	var members []*kops.Cluster
+	for _, member := range ClusterGroupMembers(clusters, group) {
+		if member.ObjectMeta.Name != cluster.ObjectMeta.Name {
+			members = append(members, member)
+		}
+	}
+
+	region := ClusterRegion(cluster)
+	for _, member := range members {
+		if region != "" && ClusterRegion(member) == region {
+			return fmt.Errorf("group %q already has cluster %q in region %q", group, member.ObjectMeta.Name, region)
+		}
+	}
+
+	if cluster.ObjectMeta.Labels == nil {
+		cluster.ObjectMeta.Labels = make(map[string]string)
+	}
+	if len(members) != 0 {
+		for k, v := range members[0].ObjectMeta.Labels {
+			shared := true
+			for _, member := range members[1:] {
+				if value, found := member.ObjectMeta.Labels[k]; !found || value != v {
+					shared = false
+					break
+				}
+			}
+			if _, found := cluster.ObjectMeta.Labels[k]; shared && !found {
+				cluster.ObjectMeta.Labels[k] = v
+			}
+		}
+	}
+	cluster.ObjectMeta.Labels[kops.LabelClusterGroup] = group
+
+	return nil
+}
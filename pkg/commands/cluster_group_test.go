@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func buildGroupCluster(name string, zone string, labels map[string]string) kops.Cluster {
+	return kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: kops.ClusterSpec{
+			CloudProvider: "aws",
+			Subnets:       []kops.ClusterSubnetSpec{{Name: zone, Zone: zone}},
+		},
+	}
+}
+
+func TestJoinClusterGroup(t *testing.T) {
+	clusters := []kops.Cluster{
+		buildGroupCluster("payments-us.example.com", "us-east-1a", map[string]string{kops.LabelClusterGroup: "payments", "team": "payments", "env": "prod", "region": "us"}),
+		buildGroupCluster("payments-ap.example.com", "ap-southeast-1a", map[string]string{kops.LabelClusterGroup: "payments", "team": "payments", "env": "prod", "region": "ap"}),
+		buildGroupCluster("search.example.com", "eu-west-1a", map[string]string{"team": "search", "env": "prod"}),
+	}
+
+	cluster := buildGroupCluster("payments-eu.example.com", "eu-west-1a", map[string]string{"env": "canary"})
+	if err := JoinClusterGroup(&cluster, "payments", clusters); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{kops.LabelClusterGroup: "payments", "team": "payments", "env": "canary"}
+	if !reflect.DeepEqual(cluster.ObjectMeta.Labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, cluster.ObjectMeta.Labels)
+	}
+
+	var names []string
+	for _, member := range ClusterGroupMembers(append(clusters, cluster), "payments") {
+		names = append(names, member.ObjectMeta.Name)
+	}
+	if expected := []string{"payments-ap.example.com", "payments-eu.example.com", "payments-us.example.com"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected members %v, got %v", expected, names)
+	}
+
+	sameRegion := buildGroupCluster("payments-us2.example.com", "us-east-1b", nil)
+	if err := JoinClusterGroup(&sameRegion, "payments", clusters); err == nil {
+		t.Errorf("expected error adding a second cluster in us-east-1 to the group")
+	}
+
+	invalid := buildGroupCluster("other.example.com", "us-west-2a", nil)
+	if err := JoinClusterGroup(&invalid, "not a label", clusters); err == nil {
+		t.Errorf("expected error for invalid group name")
+	}
+}
+
+func TestClusterRegion(t *testing.T) {
+	grid := []struct {
+		Cluster  kops.Cluster
+		Expected string
+	}{
+		{Cluster: buildGroupCluster("a", "us-east-1a", nil), Expected: "us-east-1"},
+		{
+			Cluster: kops.Cluster{Spec: kops.ClusterSpec{
+				CloudProvider: "gce",
+				Subnets:       []kops.ClusterSubnetSpec{{Name: "us-central1", Region: "us-central1"}},
+			}},
+			Expected: "us-central1",
+		},
+		{
+			Cluster: kops.Cluster{Spec: kops.ClusterSpec{
+				CloudProvider: "gce",
+				Subnets:       []kops.ClusterSubnetSpec{{Name: "europe-west1-b", Zone: "europe-west1-b"}},
+			}},
+			Expected: "europe-west1",
+		},
+		{Cluster: kops.Cluster{}, Expected: ""},
+	}
+	for _, g := range grid {
+		if actual := ClusterRegion(&g.Cluster); actual != g.Expected {
+			t.Errorf("expected region %q for %+v, got %q", g.Expected, g.Cluster.Spec.Subnets, actual)
+		}
+	}
+}
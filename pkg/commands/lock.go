@@ -0,0 +1,35 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/audit"
+)
+
+// CheckClusterLock returns an error if the cluster is locked by another user.
+// It must be called by every command that changes a cluster, before it changes anything.
+func CheckClusterLock(f *util.Factory, clusterName string) error {
+	locker, err := f.ClusterLocker()
+	if err != nil {
+		return err
+	}
+	if locker == nil {
+		return nil
+	}
+	return locker.Check(clusterName, audit.CurrentUser())
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// MigrateRegionOptions controls how a cluster is cloned into another region
+type MigrateRegionOptions struct {
+	CloneClusterOptions
+
+	// Region is the region to move the cluster to
+	Region string
+	// Image is the image for all instance groups in the new region; if empty, images which are specific to the
+	// old region are cleared, so the image in the channel is used
+	Image string
+}
+
+// RegionMigration is the plan for moving a cluster to another region
+type RegionMigration struct {
+	Cluster        *kops.Cluster
+	InstanceGroups []*kops.InstanceGroup
+
+	// Checklist is the steps that must be taken to cut over to the new cluster, in order
+	Checklist []string
+}
+
+// PlanRegionMigration clones a cluster into another region: zones, and the subnets, instance groups and etcd
+// members named after them, are moved to the zones with the same suffix in the new region, and references to
+// existing resources of the old region are removed
+func PlanRegionMigration(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, options *MigrateRegionOptions) (*RegionMigration, error) {
+	if options.Region == "" {
+		return nil, fmt.Errorf("region is required")
+	}
+
+	clone, cloneGroups, err := CloneClusterSpec(cluster, instanceGroups, &options.CloneClusterOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	migration := &RegionMigration{
+		Cluster:        clone,
+		InstanceGroups: cloneGroups,
+	}
+	var notes []string
+
+	// Existing networks can't be moved between regions, so kops creates new ones
+	if clone.Spec.NetworkID != "" {
+		notes = append(notes, fmt.Sprintf("The cluster used the existing network %q, but kops will create a new network in %s; recreate any peering, VPN or routes it relied on", clone.Spec.NetworkID, options.Region))
+		clone.Spec.NetworkID = ""
+	}
+	for i := range clone.Spec.Subnets {
+		subnet := &clone.Spec.Subnets[i]
+		if subnet.ProviderID != "" || subnet.Egress != "" {
+			notes = append(notes, fmt.Sprintf("Subnet %q used existing resources (id %q, egress %q); kops will create them in %s", subnet.Name, subnet.ProviderID, subnet.Egress, options.Region))
+			subnet.ProviderID = ""
+			subnet.Egress = ""
+		}
+	}
+
+	zones, err := moveRegion(clone, cloneGroups, options.Region)
+	if err != nil {
+		return nil, err
+	}
+	renameZones := func(s string) string {
+		for from, to := range zones {
+			s = strings.Replace(s, from, to, -1)
+		}
+		return s
+	}
+
+	for i := range clone.Spec.Subnets {
+		clone.Spec.Subnets[i].Name = renameZones(clone.Spec.Subnets[i].Name)
+	}
+	for _, etcdCluster := range clone.Spec.EtcdClusters {
+		for _, member := range etcdCluster.Members {
+			member.Name = renameZones(member.Name)
+			if member.InstanceGroup != nil {
+				name := renameZones(*member.InstanceGroup)
+				member.InstanceGroup = &name
+			}
+		}
+	}
+
+	for _, ig := range cloneGroups {
+		ig.ObjectMeta.Name = renameZones(ig.ObjectMeta.Name)
+		for i := range ig.Spec.Subnets {
+			ig.Spec.Subnets[i] = renameZones(ig.Spec.Subnets[i])
+		}
+
+		if options.Image != "" {
+			ig.Spec.Image = options.Image
+		} else if isRegionalImage(kops.CloudProviderID(clone.Spec.CloudProvider), ig.Spec.Image) {
+			notes = append(notes, fmt.Sprintf("Instance group %q used the image %q, which is specific to the old region; it will use the image in the channel unless one is set", ig.ObjectMeta.Name, ig.Spec.Image))
+			ig.Spec.Image = ""
+		}
+
+		if len(ig.Spec.AdditionalSecurityGroups) != 0 {
+			notes = append(notes, fmt.Sprintf("Instance group %q has additional security groups %v, which must be recreated in %s and updated with kops edit ig", ig.ObjectMeta.Name, ig.Spec.AdditionalSecurityGroups, options.Region))
+		}
+		if len(ig.Spec.ExternalLoadBalancers) != 0 {
+			notes = append(notes, fmt.Sprintf("Instance group %q is attached to external load balancers, which must be recreated in %s and updated with kops edit ig", ig.ObjectMeta.Name, options.Region))
+		}
+	}
+
+	if clone.Spec.API != nil && clone.Spec.API.LoadBalancer != nil {
+		lb := clone.Spec.API.LoadBalancer
+		if lb.SSLCertificate != "" {
+			notes = append(notes, fmt.Sprintf("The API load balancer uses the certificate %q, which must be imported into %s and updated with kops edit cluster", lb.SSLCertificate, options.Region))
+		}
+		if len(lb.AdditionalSecurityGroups) != 0 || lb.SecurityGroupOverride != nil {
+			notes = append(notes, fmt.Sprintf("The API load balancer uses existing security groups, which must be recreated in %s and updated with kops edit cluster", options.Region))
+		}
+	}
+
+	from := cluster.ObjectMeta.Name
+	to := clone.ObjectMeta.Name
+	migration.Checklist = append(migration.Checklist, notes...)
+	migration.Checklist = append(migration.Checklist,
+		fmt.Sprintf("Review the configuration of the new cluster with: kops edit cluster %s", to),
+		fmt.Sprintf("Create the new cluster with: kops update cluster %s --yes; etcd-manager restores the etcd backup when the control plane starts", to),
+		fmt.Sprintf("Wait for the new cluster to be ready with: kops validate cluster %s", to),
+		"Changes made to the old cluster after the etcd backup was taken are not in the new cluster; re-apply them, or stop deploying to the old cluster before migrating",
+		fmt.Sprintf("Persistent volumes are not moved; copy their data to %s and recreate the PersistentVolumes that refer to the old volumes", options.Region),
+		"Services of type LoadBalancer get new load balancers; move application DNS records to them, lowering their TTLs first",
+		fmt.Sprintf("Point clients at the new cluster with: kops export kubecfg %s", to),
+		fmt.Sprintf("Once traffic has moved, delete the old cluster with: kops delete cluster %s --yes", from),
+	)
+
+	return migration, nil
+}
+
+// isRegionalImage returns true if the image is an id that is only valid in one region; AWS images named by
+// owner/name, and images of other clouds, are found in every region
+func isRegionalImage(cloudProvider kops.CloudProviderID, image string) bool {
+	return cloudProvider == kops.CloudProviderAWS && strings.HasPrefix(image, "ami-")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestPlanRegionMigration(t *testing.T) {
+	master := "master-us-east-1a"
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod.example.com"},
+		Spec: kops.ClusterSpec{
+			CloudProvider:      "aws",
+			ConfigBase:         "s3://state/prod.example.com",
+			MasterPublicName:   "api.prod.example.com",
+			MasterInternalName: "api.internal.prod.example.com",
+			DNSZone:            "example.com",
+			NetworkID:          "vpc-12345678",
+			NetworkCIDR:        "172.20.0.0/16",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-east-1a", Zone: "us-east-1a", CIDR: "172.20.32.0/19", ProviderID: "subnet-12345678", Egress: "nat-12345678"},
+				{Name: "utility-us-east-1a", Zone: "us-east-1a", CIDR: "172.20.4.0/22"},
+			},
+			EtcdClusters: []*kops.EtcdClusterSpec{
+				{Name: "main", Members: []*kops.EtcdMemberSpec{{Name: "us-east-1a", InstanceGroup: &master}}},
+			},
+		},
+	}
+	groups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: master},
+			Spec: kops.InstanceGroupSpec{
+				Role:    kops.InstanceGroupRoleMaster,
+				Image:   "ami-12345678",
+				Subnets: []string{"us-east-1a"},
+				Zones:   []string{"us-east-1a"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec: kops.InstanceGroupSpec{
+				Role:    kops.InstanceGroupRoleNode,
+				Image:   "kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-05-27",
+				Subnets: []string{"us-east-1a"},
+			},
+		},
+	}
+
+	migration, err := PlanRegionMigration(cluster, groups, &MigrateRegionOptions{
+		CloneClusterOptions: CloneClusterOptions{To: "eu.example.com"},
+		Region:              "eu-west-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clone := migration.Cluster
+	if clone.ObjectMeta.Name != "eu.example.com" || clone.Spec.MasterPublicName != "api.eu.example.com" {
+		t.Errorf("expected cluster to be cloned as eu.example.com, got %q with %q", clone.ObjectMeta.Name, clone.Spec.MasterPublicName)
+	}
+	if clone.Spec.NetworkID != "" || clone.Spec.Subnets[0].ProviderID != "" || clone.Spec.Subnets[0].Egress != "" {
+		t.Errorf("expected existing network resources to be removed, got %q and %+v", clone.Spec.NetworkID, clone.Spec.Subnets[0])
+	}
+	for i, expected := range []string{"eu-west-1a", "utility-eu-west-1a"} {
+		subnet := clone.Spec.Subnets[i]
+		if subnet.Name != expected || subnet.Zone != "eu-west-1a" {
+			t.Errorf("expected subnet %q in eu-west-1a, got %q in %q", expected, subnet.Name, subnet.Zone)
+		}
+	}
+	member := clone.Spec.EtcdClusters[0].Members[0]
+	if member.Name != "eu-west-1a" || *member.InstanceGroup != "master-eu-west-1a" {
+		t.Errorf("expected etcd member to be renamed, got %q in %q", member.Name, *member.InstanceGroup)
+	}
+
+	masterGroup := migration.InstanceGroups[0]
+	if masterGroup.ObjectMeta.Name != "master-eu-west-1a" || masterGroup.Spec.Zones[0] != "eu-west-1a" || masterGroup.Spec.Subnets[0] != "eu-west-1a" {
+		t.Errorf("expected master instance group to be moved, got %q with %+v", masterGroup.ObjectMeta.Name, masterGroup.Spec)
+	}
+	if masterGroup.Spec.Image != "" {
+		t.Errorf("expected regional image to be cleared, got %q", masterGroup.Spec.Image)
+	}
+	if image := migration.InstanceGroups[1].Spec.Image; image != "kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-05-27" {
+		t.Errorf("expected named image to be kept, got %q", image)
+	}
+
+	// The source is not changed
+	if cluster.Spec.NetworkID != "vpc-12345678" || groups[0].ObjectMeta.Name != master || groups[0].Spec.Zones[0] != "us-east-1a" {
+		t.Errorf("source cluster was modified")
+	}
+
+	checklist := strings.Join(migration.Checklist, "\n")
+	for _, expected := range []string{"vpc-12345678", "subnet-12345678", "ami-12345678", "kops update cluster eu.example.com --yes", "kops delete cluster prod.example.com --yes"} {
+		if !strings.Contains(checklist, expected) {
+			t.Errorf("expected checklist to mention %q, got:\n%s", expected, checklist)
+		}
+	}
+}
+
+func TestPlanRegionMigrationImage(t *testing.T) {
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod.example.com"},
+		Spec: kops.ClusterSpec{
+			CloudProvider: "gce",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-central1", Region: "us-central1"},
+			},
+		},
+	}
+	groups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, Image: "cos-cloud/cos-stable", Zones: []string{"us-central1-a"}},
+		},
+	}
+
+	migration, err := PlanRegionMigration(cluster, groups, &MigrateRegionOptions{
+		CloneClusterOptions: CloneClusterOptions{To: "eu.example.com"},
+		Region:              "europe-west1",
+		Image:               "ubuntu-os-cloud/ubuntu-1804",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if subnet := migration.Cluster.Spec.Subnets[0]; subnet.Name != "us-central1" || subnet.Region != "europe-west1" {
+		t.Errorf("expected regional subnet to move to europe-west1, got %+v", subnet)
+	}
+	ig := migration.InstanceGroups[0]
+	if ig.Spec.Zones[0] != "europe-west1-a" || ig.Spec.Image != "ubuntu-os-cloud/ubuntu-1804" {
+		t.Errorf("unexpected instance group spec %+v", ig.Spec)
+	}
+
+	if _, err := PlanRegionMigration(cluster, groups, &MigrateRegionOptions{CloneClusterOptions: CloneClusterOptions{To: "eu.example.com"}}); err == nil {
+		t.Errorf("expected error without a region")
+	}
+}
@@ -26,7 +26,8 @@ import (
 
 	"k8s.io/kops/cmd/kops/util"
 	api "k8s.io/kops/pkg/apis/kops"
-	"k8s.io/kops/pkg/featureflag"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/util/pkg/reflectutils"
 )
 
 type SetClusterOptions struct {
@@ -36,10 +37,6 @@ type SetClusterOptions struct {
 
 // RunSetCluster implements the set cluster command logic
 func RunSetCluster(f *util.Factory, cmd *cobra.Command, out io.Writer, options *SetClusterOptions) error {
-	if !featureflag.SpecOverrideFlag.Enabled() {
-		return fmt.Errorf("set cluster command is current feature gated; set `export KOPS_FEATURE_FLAGS=SpecOverrideFlag`")
-	}
-
 	if options.ClusterName == "" {
 		return field.Required(field.NewPath("ClusterName"), "Cluster name is required")
 	}
@@ -54,11 +51,16 @@ func RunSetCluster(f *util.Factory, cmd *cobra.Command, out io.Writer, options *
 		return err
 	}
 
+	if err := CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	instanceGroups, err := ReadAllInstanceGroups(clientset, cluster)
 	if err != nil {
 		return err
 	}
 
+	oldCluster := cluster.DeepCopy()
 	if err := SetClusterFields(options.Fields, cluster, instanceGroups); err != nil {
 		return err
 	}
@@ -66,6 +68,7 @@ func RunSetCluster(f *util.Factory, cmd *cobra.Command, out io.Writer, options *
 	if err := UpdateCluster(clientset, cluster, instanceGroups); err != nil {
 		return err
 	}
+	RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationSet, "cluster", oldCluster, cluster)
 
 	return nil
 }
@@ -78,25 +81,11 @@ func SetClusterFields(fields []string, cluster *api.Cluster, instanceGroups []*a
 			return fmt.Errorf("unhandled field: %q", field)
 		}
 
-		// For now we have hard-code the values we want to support; we'll get test coverage and then do this properly...
-		switch kv[0] {
-		case "cluster.spec.nodePortAccess":
-			cluster.Spec.NodePortAccess = append(cluster.Spec.NodePortAccess, kv[1])
-		case "spec.kubernetesVersion":
-			cluster.Spec.KubernetesVersion = kv[1]
-		case "cluster.spec.etcdClusters[*].version":
-			for _, c := range cluster.Spec.EtcdClusters {
-				c.Version = kv[1]
-			}
-		case "cluster.spec.etcdClusters[*].manager.image":
-			for _, etcd := range cluster.Spec.EtcdClusters {
-				if etcd.Manager == nil {
-					etcd.Manager = &api.EtcdManagerSpec{}
-				}
-				etcd.Manager.Image = kv[1]
-			}
-		default:
-			return fmt.Errorf("unhandled field: %q", field)
+		// Paths were historically prefixed with "cluster."; we accept both forms
+		key := strings.TrimPrefix(kv[0], "cluster.")
+
+		if err := reflectutils.SetString(cluster, key, kv[1]); err != nil {
+			return fmt.Errorf("failed to set %s=%s: %v", kv[0], kv[1], err)
 		}
 	}
 	return nil
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
 )
 
 func TestSetClusterFields(t *testing.T) {
@@ -37,6 +38,53 @@ func TestSetClusterFields(t *testing.T) {
 				Spec: kops.ClusterSpec{KubernetesVersion: "1.8.2"},
 			},
 		},
+		{
+			Fields: []string{
+				"spec.kubelet.authorizationMode=Webhook",
+				"spec.kubelet.authenticationTokenWebhook=true",
+			},
+			Output: kops.Cluster{
+				Spec: kops.ClusterSpec{
+					Kubelet: &kops.KubeletConfigSpec{
+						AuthorizationMode:          "Webhook",
+						AuthenticationTokenWebhook: fi.Bool(true),
+					},
+				},
+			},
+		},
+		{
+			Fields: []string{
+				"cluster.spec.nodePortAccess=10.0.0.0/8",
+			},
+			Input: kops.Cluster{
+				Spec: kops.ClusterSpec{NodePortAccess: []string{"192.168.0.0/16"}},
+			},
+			Output: kops.Cluster{
+				Spec: kops.ClusterSpec{NodePortAccess: []string{"192.168.0.0/16", "10.0.0.0/8"}},
+			},
+		},
+		{
+			Fields: []string{
+				"cluster.spec.etcdClusters[*].version=3.2.18",
+				"spec.etcdClusters[*].manager.image=etcd-manager:latest",
+			},
+			Input: kops.Cluster{
+				Spec: kops.ClusterSpec{
+					EtcdClusters: []*kops.EtcdClusterSpec{
+						{Name: "main"},
+						{Name: "events"},
+					},
+				},
+			},
+			Output: kops.Cluster{
+				Spec: kops.ClusterSpec{
+					EtcdClusters: []*kops.EtcdClusterSpec{
+						{Name: "main", Version: "3.2.18", Manager: &kops.EtcdManagerSpec{Image: "etcd-manager:latest"}},
+						{Name: "events", Version: "3.2.18", Manager: &kops.EtcdManagerSpec{Image: "etcd-manager:latest"}},
+					},
+				},
+			},
+		},
 	}
 
 	for _, g := range grid {
@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/reflectutils"
+)
+
+type SetInstanceGroupOptions struct {
+	Fields            []string
+	ClusterName       string
+	InstanceGroupName string
+}
+
+// RunSetInstancegroup implements the set instancegroup command logic
+func RunSetInstancegroup(f *util.Factory, cmd *cobra.Command, out io.Writer, options *SetInstanceGroupOptions) error {
+	if options.ClusterName == "" {
+		return field.Required(field.NewPath("ClusterName"), "Cluster name is required")
+	}
+	if options.InstanceGroupName == "" {
+		return field.Required(field.NewPath("InstanceGroupName"), "Instance Group name is required")
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clientset.GetCluster(options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckClusterLock(f, cluster.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	instanceGroup, err := clientset.InstanceGroupsFor(cluster).Get(options.InstanceGroupName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading InstanceGroup %q: %v", options.InstanceGroupName, err)
+	}
+	if instanceGroup == nil {
+		return fmt.Errorf("InstanceGroup %q not found", options.InstanceGroupName)
+	}
+	oldGroup := instanceGroup.DeepCopy()
+
+	if err := SetInstancegroupFields(options.Fields, instanceGroup); err != nil {
+		return err
+	}
+
+	if err := validation.ValidateInstanceGroup(instanceGroup); err != nil {
+		return err
+	}
+
+	channel, err := cloudup.ChannelForCluster(cluster)
+	if err != nil {
+		return err
+	}
+
+	fullGroup, err := cloudup.PopulateInstanceGroupSpec(cluster, instanceGroup, channel)
+	if err != nil {
+		return err
+	}
+
+	// We need the full cluster spec to perform deep validation
+	// Note that we don't write it back though
+	err = cloudup.PerformAssignments(cluster)
+	if err != nil {
+		return fmt.Errorf("error populating configuration: %v", err)
+	}
+
+	assetBuilder := assets.NewAssetBuilder(cluster, "")
+	fullCluster, err := cloudup.PopulateClusterSpec(clientset, cluster, assetBuilder)
+	if err != nil {
+		return err
+	}
+
+	if err := validation.CrossValidateInstanceGroup(fullGroup, fullCluster, true); err != nil {
+		return err
+	}
+
+	// Note we perform as much validation as we can, before writing a bad config
+	if _, err := clientset.InstanceGroupsFor(cluster).Update(fullGroup); err != nil {
+		return err
+	}
+	RecordAuditChange(f, cluster.ObjectMeta.Name, audit.OperationSet, "instancegroup/"+fullGroup.ObjectMeta.Name, oldGroup, fullGroup)
+
+	return nil
+}
+
+// SetInstancegroupFields sets field values in the instance group
+func SetInstancegroupFields(fields []string, instanceGroup *api.InstanceGroup) error {
+	for _, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("unhandled field: %q", field)
+		}
+
+		if err := reflectutils.SetString(instanceGroup, kv[0], kv[1]); err != nil {
+			return fmt.Errorf("failed to set %s=%s: %v", kv[0], kv[1], err)
+		}
+	}
+	return nil
+}
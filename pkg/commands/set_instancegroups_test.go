@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestSetInstancegroupFields(t *testing.T) {
+	grid := []struct {
+		Fields []string
+		Input  kops.InstanceGroup
+		Output kops.InstanceGroup
+	}{
+		{
+			Fields: []string{
+				"spec.maxSize=20",
+				"spec.minSize=5",
+			},
+			Output: kops.InstanceGroup{
+				Spec: kops.InstanceGroupSpec{MaxSize: fi.Int32(20), MinSize: fi.Int32(5)},
+			},
+		},
+		{
+			Fields: []string{
+				"spec.machineType=m4.large",
+				"spec.nodeLabels.team=infra",
+			},
+			Input: kops.InstanceGroup{
+				Spec: kops.InstanceGroupSpec{MachineType: "t2.medium", NodeLabels: map[string]string{"env": "prod"}},
+			},
+			Output: kops.InstanceGroup{
+				Spec: kops.InstanceGroupSpec{MachineType: "m4.large", NodeLabels: map[string]string{"env": "prod", "team": "infra"}},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		ig := g.Input
+
+		err := SetInstancegroupFields(g.Fields, &ig)
+		if err != nil {
+			t.Errorf("unexpected error from SetInstancegroupFields %v: %v", g.Fields, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(ig, g.Output) {
+			t.Errorf("unexpected output from SetInstancegroupFields %v.  expected=%v, actual=%v", g.Fields, g.Output, ig)
+			continue
+		}
+	}
+}
+
+func TestSetInstancegroupFieldsErrors(t *testing.T) {
+	for _, fields := range [][]string{
+		{"spec.maxSize"},
+		{"spec.maxSize=lots"},
+		{"spec.noSuchField=1"},
+	} {
+		ig := kops.InstanceGroup{}
+		if err := SetInstancegroupFields(fields, &ig); err == nil {
+			t.Errorf("expected error from SetInstancegroupFields %v", fields)
+		}
+	}
+}
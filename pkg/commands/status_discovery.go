@@ -26,6 +26,8 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
 )
 
 // CloudDiscoveryStatusStore implements status.Store by inspecting cloud objects.
@@ -45,6 +47,14 @@ func (s *CloudDiscoveryStatusStore) GetApiIngressStatus(cluster *kops.Cluster) (
 		return gceCloud.GetApiIngressStatus(cluster)
 	}
 
+	if hetznerCloud, ok := cloud.(hetzner.HetznerCloud); ok {
+		return hetznerCloud.GetApiIngressStatus(cluster)
+	}
+
+	if scalewayCloud, ok := cloud.(scaleway.ScalewayCloud); ok {
+		return scalewayCloud.GetApiIngressStatus(cluster)
+	}
+
 	if awsCloud, ok := cloud.(awsup.AWSCloud); ok {
 		name := "api." + cluster.Name
 		lb, err := awstasks.FindLoadBalancerByNameTag(awsCloud, name)
@@ -89,5 +99,12 @@ func (s *CloudDiscoveryStatusStore) FindClusterStatus(cluster *kops.Cluster) (*k
 	if aliCloud, ok := cloud.(aliup.ALICloud); ok {
 		return aliCloud.FindClusterStatus(cluster)
 	}
+
+	if hetznerCloud, ok := cloud.(hetzner.HetznerCloud); ok {
+		return hetznerCloud.FindClusterStatus(cluster)
+	}
+	if scalewayCloud, ok := cloud.(scaleway.ScalewayCloud); ok {
+		return scalewayCloud.FindClusterStatus(cluster)
+	}
 	return nil, fmt.Errorf("Etcd Status not implemented for %T", cloud)
 }
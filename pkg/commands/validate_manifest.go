@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/v1alpha1"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/kopscodecs"
+)
+
+// ManifestObject is an object decoded from a manifest document
+type ManifestObject struct {
+	// Source identifies the file and document the object was read from
+	Source string
+	// Object is the decoded object, converted to the internal API version
+	Object interface{}
+}
+
+// ManifestProblem is a problem found when validating a manifest
+type ManifestProblem struct {
+	// Source identifies the file and document containing the problem
+	Source string
+	// Kind is the kind of the object with the problem, if it could be decoded
+	Kind string
+	// Name is the name of the object with the problem, if it could be decoded
+	Name string
+	// Message describes the problem
+	Message string
+}
+
+// ManifestValidation is the result of validating one or more manifest files
+type ManifestValidation struct {
+	Objects  []*ManifestObject
+	Problems []*ManifestProblem
+}
+
+func (m *ManifestValidation) addProblem(source string, kind string, name string, format string, args ...interface{}) {
+	m.Problems = append(m.Problems, &ManifestProblem{
+		Source:  source,
+		Kind:    kind,
+		Name:    name,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// DecodeManifest decodes the documents in the manifest, converting them to the internal API version.
+// Documents that cannot be decoded are recorded as problems.
+func (m *ManifestValidation) DecodeManifest(source string, contents []byte) {
+	codec := kopscodecs.Codecs.UniversalDecoder(kops.SchemeGroupVersion)
+
+	// TODO: this does not support a JSON array
+	sections := bytes.Split(bytes.Replace(contents, []byte("\r\n"), []byte("\n"), -1), []byte("\n---\n"))
+	for i, section := range sections {
+		if len(bytes.TrimSpace(section)) == 0 {
+			continue
+		}
+
+		sectionSource := source
+		if len(sections) > 1 {
+			sectionSource = fmt.Sprintf("%s#%d", source, i+1)
+		}
+
+		defaults := &schema.GroupVersionKind{
+			Group:   v1alpha1.SchemeGroupVersion.Group,
+			Version: v1alpha1.SchemeGroupVersion.Version,
+		}
+		o, gvk, err := codec.Decode(section, defaults, nil)
+		if err != nil {
+			m.addProblem(sectionSource, "", "", "error parsing document: %v", err)
+			continue
+		}
+
+		switch o.(type) {
+		case *kops.Cluster, *kops.InstanceGroup:
+			m.Objects = append(m.Objects, &ManifestObject{Source: sectionSource, Object: o})
+		default:
+			m.addProblem(sectionSource, gvk.Kind, "", "unhandled kind %q", gvk)
+		}
+	}
+}
+
+// Validate checks the decoded objects, individually and against each other (e.g. that instance groups
+// reference subnets defined in their cluster).  It does not contact the cloud or the state store.
+func (m *ManifestValidation) Validate() {
+	// Instance groups may precede their cluster in the manifest
+	definedClusters := make(map[string]bool)
+	for _, obj := range m.Objects {
+		if cluster, ok := obj.Object.(*kops.Cluster); ok {
+			definedClusters[cluster.ObjectMeta.Name] = true
+		}
+	}
+
+	var clusters []*kops.Cluster
+	invalidClusters := make(map[string]bool)
+	instanceGroups := make(map[string][]*kops.InstanceGroup)
+	// sources records where each object was defined, and detects duplicates
+	sources := make(map[string]string)
+
+	for _, obj := range m.Objects {
+		switch v := obj.Object.(type) {
+		case *kops.Cluster:
+			name := v.ObjectMeta.Name
+			key := "Cluster/" + name
+			if previous := sources[key]; previous != "" {
+				m.addProblem(obj.Source, "Cluster", name, "duplicate cluster (also defined in %s)", previous)
+				continue
+			}
+			sources[key] = obj.Source
+
+			if err := validation.ValidateCluster(v, false); err != nil {
+				m.addProblem(obj.Source, "Cluster", name, "%v", err)
+				invalidClusters[name] = true
+			}
+			clusters = append(clusters, v)
+
+		case *kops.InstanceGroup:
+			name := v.ObjectMeta.Name
+			clusterName := v.ObjectMeta.Labels[kops.LabelClusterName]
+			if clusterName == "" {
+				m.addProblem(obj.Source, "InstanceGroup", name, "must specify %q label with cluster name", kops.LabelClusterName)
+			}
+
+			key := "InstanceGroup/" + clusterName + "/" + name
+			if previous := sources[key]; previous != "" {
+				m.addProblem(obj.Source, "InstanceGroup", name, "duplicate instance group (also defined in %s)", previous)
+				continue
+			}
+			sources[key] = obj.Source
+
+			if err := validation.ValidateInstanceGroup(v); err != nil {
+				m.addProblem(obj.Source, "InstanceGroup", name, "%v", err)
+				continue
+			}
+			if clusterName == "" {
+				continue
+			}
+
+			if !definedClusters[clusterName] {
+				// The cluster may already exist in the state store, but we are validating offline;
+				// we can only cross-check instance groups whose cluster is in the manifest.
+				if len(definedClusters) != 0 {
+					m.addProblem(obj.Source, "InstanceGroup", name, "cluster %q is not defined in the manifest", clusterName)
+				}
+				continue
+			}
+			instanceGroups[clusterName] = append(instanceGroups[clusterName], v)
+		}
+	}
+
+	for _, cluster := range clusters {
+		clusterName := cluster.ObjectMeta.Name
+		problemCount := len(m.Problems)
+
+		groups := instanceGroups[clusterName]
+		for _, g := range groups {
+			if err := validation.CrossValidateInstanceGroup(g, cluster, false); err != nil {
+				m.addProblem(sources["InstanceGroup/"+clusterName+"/"+g.ObjectMeta.Name], "InstanceGroup", g.ObjectMeta.Name, "%v", err)
+			}
+		}
+
+		// Instance groups may be managed separately from the cluster
+		if len(groups) == 0 {
+			continue
+		}
+
+		// DeepValidate repeats the checks above, so only run it to catch the remaining (whole-cluster) problems
+		if len(m.Problems) == problemCount && !invalidClusters[clusterName] {
+			if err := validation.DeepValidate(cluster, groups, false); err != nil {
+				m.addProblem(sources["Cluster/"+clusterName], "Cluster", clusterName, "%v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const testManifestInstanceGroup = `
+apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: extra
+  labels:
+    kops.k8s.io/cluster: minimal.example.com
+spec:
+  machineType: t2.medium
+  maxSize: 2
+  minSize: 2
+  role: Node
+  subnets:
+  - us-test-1b
+`
+
+func TestValidateManifest(t *testing.T) {
+	contents, err := ioutil.ReadFile("../../tests/integration/update_cluster/minimal/in-v1alpha2.yaml")
+	if err != nil {
+		t.Fatalf("error reading manifest: %v", err)
+	}
+
+	{
+		m := &ManifestValidation{}
+		m.DecodeManifest("minimal.yaml", contents)
+		m.Validate()
+		if len(m.Objects) != 3 {
+			t.Errorf("expected 3 objects, got %d", len(m.Objects))
+		}
+		for _, p := range m.Problems {
+			t.Errorf("unexpected problem in valid manifest: %+v", p)
+		}
+	}
+
+	{
+		m := &ManifestValidation{}
+		m.DecodeManifest("minimal.yaml", contents)
+		m.DecodeManifest("extra.yaml", []byte(testManifestInstanceGroup))
+		m.Validate()
+		if len(m.Problems) != 1 {
+			t.Fatalf("expected 1 problem, got %d: %+v", len(m.Problems), m.Problems)
+		}
+		p := m.Problems[0]
+		if p.Source != "extra.yaml" || p.Kind != "InstanceGroup" || p.Name != "extra" || !strings.Contains(p.Message, "us-test-1b") {
+			t.Errorf("unexpected problem for instance group in unknown subnet: %+v", p)
+		}
+	}
+
+	{
+		// Without the cluster, we can't cross-validate
+		m := &ManifestValidation{}
+		m.DecodeManifest("extra.yaml", []byte(testManifestInstanceGroup))
+		m.Validate()
+		if len(m.Problems) != 0 {
+			t.Errorf("unexpected problems validating standalone instance group: %+v", m.Problems)
+		}
+	}
+
+	{
+		// Cluster labels must be valid kubernetes labels
+		labelled := strings.Replace(string(contents), "  name: minimal.example.com\nspec:", "  name: minimal.example.com\n  labels:\n    team: payments\n    env: \"not valid\"\nspec:", 1)
+		m := &ManifestValidation{}
+		m.DecodeManifest("minimal.yaml", []byte(labelled))
+		m.Validate()
+		if len(m.Problems) != 1 || m.Problems[0].Kind != "Cluster" || !strings.Contains(m.Problems[0].Message, "Labels") {
+			t.Errorf("expected a problem with the cluster labels, got %+v", m.Problems)
+		}
+	}
+
+	{
+		m := &ManifestValidation{}
+		m.DecodeManifest("bad.yaml", []byte("apiVersion: kops/v1alpha2\nkind: Unknown\n"))
+		m.Validate()
+		if len(m.Problems) != 1 {
+			t.Errorf("expected a problem decoding an unknown kind, got %+v", m.Problems)
+		}
+	}
+}
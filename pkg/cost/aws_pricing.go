@@ -0,0 +1,202 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/golang/glog"
+)
+
+// awsPricingRegion is the region of the AWS price list API endpoint
+const awsPricingRegion = "us-east-1"
+
+// awsRegionLocations maps regions to the location names used by the AWS price list
+var awsRegionLocations = map[string]string{
+	"us-east-1":      "US East (N. Virginia)",
+	"us-east-2":      "US East (Ohio)",
+	"us-west-1":      "US West (N. California)",
+	"us-west-2":      "US West (Oregon)",
+	"ca-central-1":   "Canada (Central)",
+	"eu-central-1":   "EU (Frankfurt)",
+	"eu-west-1":      "EU (Ireland)",
+	"eu-west-2":      "EU (London)",
+	"eu-west-3":      "EU (Paris)",
+	"ap-northeast-1": "Asia Pacific (Tokyo)",
+	"ap-northeast-2": "Asia Pacific (Seoul)",
+	"ap-south-1":     "Asia Pacific (Mumbai)",
+	"ap-southeast-1": "Asia Pacific (Singapore)",
+	"ap-southeast-2": "Asia Pacific (Sydney)",
+	"sa-east-1":      "South America (Sao Paulo)",
+}
+
+// FetchAWSPrices returns the on-demand prices of the machine types in the region, from the AWS price list API.
+// Volumes, NAT gateways and load balancers keep the prices in base, as they vary little between regions.
+func FetchAWSPrices(region string, machineTypes []string, base *Prices) (*Prices, error) {
+	location := awsRegionLocations[region]
+	if location == "" {
+		return nil, fmt.Errorf("region %q is not known to the AWS price list", region)
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("error creating AWS session: %v", err)
+	}
+	svc := pricing.New(sess, aws.NewConfig().WithRegion(awsPricingRegion))
+
+	prices := base.Copy()
+	prices.Region = region
+	prices.Date = time.Now().UTC().Format("2006-01-02")
+	prices.Instances = make(map[string]float64)
+
+	typeTerm := pricing.FilterTypeTermMatch
+	for _, machineType := range machineTypes {
+		filter := func(field, value string) *pricing.Filter {
+			return &pricing.Filter{Field: aws.String(field), Type: &typeTerm, Value: aws.String(value)}
+		}
+		request := &pricing.GetProductsInput{
+			Filters: []*pricing.Filter{
+				filter("location", location),
+				filter("instanceType", machineType),
+				filter("operatingSystem", "Linux"),
+				filter("tenancy", "Shared"),
+				filter("preInstalledSw", "NA"),
+				filter("capacitystatus", "Used"),
+			},
+			FormatVersion: aws.String("aws_v1"),
+			ServiceCode:   aws.String("AmazonEC2"),
+		}
+
+		glog.V(2).Infof("querying AWS price list for %s in %s", machineType, region)
+		var price float64
+		found := false
+		err := svc.GetProductsPages(request, func(p *pricing.GetProductsOutput, lastPage bool) bool {
+			for _, item := range p.PriceList {
+				v, err := parseAWSOnDemandPrice(item)
+				if err != nil {
+					glog.Warningf("ignoring price list item for %s: %v", machineType, err)
+					continue
+				}
+				price = v
+				found = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error querying AWS price list for %q: %v", machineType, err)
+		}
+		if !found {
+			glog.Warningf("AWS price list has no price for %s in %s", machineType, region)
+			continue
+		}
+		prices.Instances[machineType] = price
+	}
+
+	return prices, nil
+}
+
+// parseAWSOnDemandPrice returns the hourly on-demand price in USD from an item of the AWS price list
+func parseAWSOnDemandPrice(item aws.JSONValue) (float64, error) {
+	terms, ok := item["terms"].(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("no terms")
+	}
+	onDemand, ok := terms["OnDemand"].(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("no on-demand terms")
+	}
+	for _, offer := range onDemand {
+		offer, ok := offer.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dimensions, ok := offer["priceDimensions"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, dimension := range dimensions {
+			dimension, ok := dimension.(map[string]interface{})
+			if !ok || dimension["unit"] != "Hrs" {
+				continue
+			}
+			pricePerUnit, ok := dimension["pricePerUnit"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usd, ok := pricePerUnit["USD"].(string)
+			if !ok {
+				continue
+			}
+			price, err := strconv.ParseFloat(usd, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid price %q: %v", usd, err)
+			}
+			return price, nil
+		}
+	}
+	return 0, fmt.Errorf("no hourly price")
+}
+
+// FetchAWSSpotPrices returns the current spot price of each of the machine types, averaged over the zones
+func FetchAWSSpotPrices(client ec2iface.EC2API, machineTypes []string) (map[string]float64, error) {
+	request := &ec2.DescribeSpotPriceHistoryInput{
+		StartTime:           aws.Time(time.Now()),
+		ProductDescriptions: []*string{aws.String("Linux/UNIX")},
+		InstanceTypes:       aws.StringSlice(machineTypes),
+	}
+
+	// The history returns the price in effect at the start time, for each zone
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	seen := make(map[string]bool)
+	err := client.DescribeSpotPriceHistoryPages(request, func(p *ec2.DescribeSpotPriceHistoryOutput, lastPage bool) bool {
+		for _, h := range p.SpotPriceHistory {
+			machineType := aws.StringValue(h.InstanceType)
+			key := machineType + "/" + aws.StringValue(h.AvailabilityZone)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			price, err := strconv.ParseFloat(aws.StringValue(h.SpotPrice), 64)
+			if err != nil {
+				glog.Warningf("ignoring invalid spot price %q for %s", aws.StringValue(h.SpotPrice), key)
+				continue
+			}
+			totals[machineType] += price
+			counts[machineType]++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing spot price history: %v", err)
+	}
+
+	prices := make(map[string]float64)
+	for machineType, total := range totals {
+		prices[machineType] = total / float64(counts[machineType])
+	}
+	return prices, nil
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/pkg/model/defaults"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+const (
+	// KindInstances is a line item for the instances of an instance group
+	KindInstances = "instances"
+	// KindVolumes is a line item for EBS or persistent disk volumes
+	KindVolumes = "volumes"
+	// KindNatGateway is a line item for NAT gateways
+	KindNatGateway = "nat-gateway"
+	// KindLoadBalancer is a line item for load balancers
+	KindLoadBalancer = "load-balancer"
+)
+
+// LineItem is the estimated monthly cost of one kind of resource of the cluster
+type LineItem struct {
+	// Name identifies the item, e.g. instancegroup/nodes
+	Name string `json:"name"`
+	// Kind is the kind of resource, e.g. instances
+	Kind string `json:"kind"`
+	// Type is the machine type, volume type etc.
+	Type string `json:"type,omitempty"`
+	// MinCount and MaxCount are the number of resources (or of GB, for volumes), at the minimum and maximum size
+	// of the instance groups
+	MinCount int64 `json:"minCount"`
+	MaxCount int64 `json:"maxCount"`
+	// UnitPrice is the price of one resource per hour, or of one GB per month for volumes
+	UnitPrice float64 `json:"unitPrice"`
+	// MinMonthly and MaxMonthly are the estimated monthly costs
+	MinMonthly float64 `json:"minMonthly"`
+	MaxMonthly float64 `json:"maxMonthly"`
+	// Note explains how the price was determined, when it isn't from the price list
+	Note string `json:"note,omitempty"`
+}
+
+// Estimate is the estimated monthly cost of a cluster
+type Estimate struct {
+	Cloud    string `json:"cloud"`
+	Region   string `json:"region"`
+	Currency string `json:"currency"`
+	// PriceDate is when the prices were collected
+	PriceDate string `json:"priceDate,omitempty"`
+
+	Items []*LineItem `json:"items"`
+
+	MinMonthly float64 `json:"minMonthly"`
+	MaxMonthly float64 `json:"maxMonthly"`
+
+	// Warnings explain where the estimate is known to be inaccurate
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// estimator accumulates the line items of an Estimate
+type estimator struct {
+	prices   *Prices
+	estimate *Estimate
+}
+
+// EstimateCluster estimates the monthly cost of the cluster, from its spec and the prices.
+// Data transfer, NAT gateway data processing and storage in the state store are not included.
+func EstimateCluster(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, region string, prices *Prices) (*Estimate, error) {
+	cloud := cluster.Spec.CloudProvider
+	if cloud != string(kops.CloudProviderAWS) && cloud != string(kops.CloudProviderGCE) {
+		return nil, fmt.Errorf("cost estimation is not supported on cloud provider %q", cloud)
+	}
+	if prices == nil || prices.Cloud != cloud {
+		return nil, fmt.Errorf("no prices for cloud provider %q", cloud)
+	}
+
+	e := &estimator{
+		prices: prices,
+		estimate: &Estimate{
+			Cloud:     cloud,
+			Region:    region,
+			Currency:  prices.Currency,
+			PriceDate: prices.Date,
+		},
+	}
+	if prices.Region != region {
+		e.warnf("prices are for region %s, not %s", prices.Region, region)
+	}
+
+	igs := make([]*kops.InstanceGroup, len(instanceGroups))
+	copy(igs, instanceGroups)
+	sort.Slice(igs, func(i, j int) bool {
+		return igs[i].ObjectMeta.Name < igs[j].ObjectMeta.Name
+	})
+
+	hasBastion := false
+	for _, ig := range igs {
+		if err := e.addInstanceGroup(cluster, ig); err != nil {
+			return nil, err
+		}
+		if ig.Spec.Role == kops.InstanceGroupRoleBastion {
+			hasBastion = true
+		}
+	}
+
+	e.addEtcdVolumes(cluster)
+
+	if cloud == string(kops.CloudProviderAWS) {
+		e.addNatGateways(cluster)
+		if hasBastion {
+			e.addLoadBalancer("bastion")
+		}
+	}
+	if cluster.Spec.API != nil && cluster.Spec.API.LoadBalancer != nil {
+		e.addLoadBalancer("api")
+	}
+
+	for _, item := range e.estimate.Items {
+		e.estimate.MinMonthly += item.MinMonthly
+		e.estimate.MaxMonthly += item.MaxMonthly
+	}
+
+	return e.estimate, nil
+}
+
+func (e *estimator) warnf(format string, args ...interface{}) {
+	e.estimate.Warnings = append(e.estimate.Warnings, fmt.Sprintf(format, args...))
+}
+
+// addHourly adds a line item for resources priced by the hour
+func (e *estimator) addHourly(item *LineItem, unitPrice float64) {
+	item.UnitPrice = unitPrice
+	item.MinMonthly = float64(item.MinCount) * unitPrice * HoursPerMonth
+	item.MaxMonthly = float64(item.MaxCount) * unitPrice * HoursPerMonth
+	e.estimate.Items = append(e.estimate.Items, item)
+}
+
+// addVolumes adds a line item for volumes, with the counts in GB
+func (e *estimator) addVolumes(item *LineItem) {
+	unitPrice, found := e.prices.Volumes[item.Type]
+	if !found {
+		item.Note = "no price for volume type"
+		e.warnf("no price for volume type %q of %s", item.Type, item.Name)
+	}
+	item.Kind = KindVolumes
+	item.UnitPrice = unitPrice
+	item.MinMonthly = float64(item.MinCount) * unitPrice
+	item.MaxMonthly = float64(item.MaxCount) * unitPrice
+	e.estimate.Items = append(e.estimate.Items, item)
+}
+
+func (e *estimator) addInstanceGroup(cluster *kops.Cluster, ig *kops.InstanceGroup) error {
+	name := "instancegroup/" + ig.ObjectMeta.Name
+
+	// These match the defaults kops uses when creating the groups
+	minSize := int64(1)
+	maxSize := int64(1)
+	if ig.Spec.MinSize != nil {
+		minSize = int64(fi.Int32Value(ig.Spec.MinSize))
+	} else if ig.Spec.Role == kops.InstanceGroupRoleNode {
+		minSize = 2
+	}
+	if ig.Spec.MaxSize != nil {
+		maxSize = int64(fi.Int32Value(ig.Spec.MaxSize))
+	} else if ig.Spec.Role == kops.InstanceGroupRoleNode {
+		maxSize = 2
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+
+	instances := &LineItem{
+		Name:     name,
+		Kind:     KindInstances,
+		Type:     ig.Spec.MachineType,
+		MinCount: minSize,
+		MaxCount: maxSize,
+	}
+	onDemand, onDemandFound := e.prices.Instances[ig.Spec.MachineType]
+	if !onDemandFound {
+		instances.Note = "no price for machine type"
+		e.warnf("no price for machine type %q of %s", ig.Spec.MachineType, name)
+	}
+	unitPrice := onDemand
+	if maxPrice := fi.StringValue(ig.Spec.MaxPrice); maxPrice != "" {
+		bid, err := strconv.ParseFloat(maxPrice, 64)
+		if err != nil {
+			return fmt.Errorf("invalid maxPrice %q of %s: %v", maxPrice, name, err)
+		}
+		if spot, found := e.prices.SpotInstances[ig.Spec.MachineType]; found {
+			unitPrice = spot
+			instances.Note = "current spot price"
+		} else {
+			unitPrice = bid
+			instances.Note = "spot, at most the max price"
+		}
+		if unitPrice > bid {
+			unitPrice = bid
+		}
+		if onDemandFound && unitPrice > onDemand {
+			unitPrice = onDemand
+		}
+	}
+	e.addHourly(instances, unitPrice)
+
+	rootVolumeSize := int64(fi.Int32Value(ig.Spec.RootVolumeSize))
+	if rootVolumeSize == 0 {
+		size, err := defaults.DefaultInstanceGroupVolumeSize(ig.Spec.Role)
+		if err != nil {
+			return err
+		}
+		rootVolumeSize = int64(size)
+	}
+	e.addVolumes(&LineItem{
+		Name:     name + "/root",
+		Type:     e.volumeType(fi.StringValue(ig.Spec.RootVolumeType), "gp2", "pd-standard"),
+		MinCount: minSize * rootVolumeSize,
+		MaxCount: maxSize * rootVolumeSize,
+	})
+
+	if cluster.Spec.CloudProvider == string(kops.CloudProviderAWS) {
+		for _, v := range ig.Spec.Volumes {
+			if v.Ephemeral {
+				// Included in the price of the instance
+				continue
+			}
+			size := int64(fi.Int32Value(v.Size))
+			e.addVolumes(&LineItem{
+				Name:     name + "/" + v.Device,
+				Type:     e.volumeType(fi.StringValue(v.Type), "gp2", ""),
+				MinCount: minSize * size,
+				MaxCount: maxSize * size,
+			})
+		}
+	}
+
+	return nil
+}
+
+// volumeType returns the volume type, or the default for the cloud if it is not set
+func (e *estimator) volumeType(volumeType string, awsDefault string, gceDefault string) string {
+	if volumeType != "" {
+		return volumeType
+	}
+	if e.estimate.Cloud == string(kops.CloudProviderGCE) {
+		return gceDefault
+	}
+	return awsDefault
+}
+
+func (e *estimator) addEtcdVolumes(cluster *kops.Cluster) {
+	for _, etcd := range cluster.Spec.EtcdClusters {
+		byType := make(map[string]int64)
+		var types []string
+		for _, m := range etcd.Members {
+			size := int64(fi.Int32Value(m.VolumeSize))
+			if size == 0 {
+				size = model.DefaultEtcdVolumeSize
+			}
+			volumeType := e.volumeType(fi.StringValue(m.VolumeType), "gp2", "pd-ssd")
+			if _, found := byType[volumeType]; !found {
+				types = append(types, volumeType)
+			}
+			byType[volumeType] += size
+		}
+		sort.Strings(types)
+		for _, volumeType := range types {
+			e.addVolumes(&LineItem{
+				Name:     "etcd/" + etcd.Name,
+				Type:     volumeType,
+				MinCount: byType[volumeType],
+				MaxCount: byType[volumeType],
+			})
+		}
+	}
+}
+
+// addNatGateways adds the NAT gateways or NAT instances kops creates for the private subnets
+func (e *estimator) addNatGateways(cluster *kops.Cluster) {
+	egressByZone := make(map[string]string)
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Type != kops.SubnetTypePrivate {
+			continue
+		}
+		egressByZone[subnet.Zone] = subnet.Egress
+	}
+
+	natGateways := int64(0)
+	natInstances := int64(0)
+	sharedNatGateway := false
+	for _, egress := range egressByZone {
+		switch egress {
+		case "", kops.EgressNatGateway:
+			natGateways++
+		case kops.EgressSharedNatGateway:
+			sharedNatGateway = true
+		case kops.EgressNatInstance:
+			natInstances++
+		default:
+			// An existing NAT gateway or instance, which kops doesn't manage
+		}
+	}
+	if sharedNatGateway {
+		natGateways++
+	}
+
+	if natGateways != 0 {
+		e.addHourly(&LineItem{
+			Name:     "nat-gateways",
+			Kind:     KindNatGateway,
+			MinCount: natGateways,
+			MaxCount: natGateways,
+			Note:     "excludes data processing",
+		}, e.prices.NatGateway)
+	}
+	if natInstances != 0 {
+		machineType := model.DefaultNatInstanceMachineType
+		if cluster.Spec.Topology != nil && cluster.Spec.Topology.NatInstance != nil && cluster.Spec.Topology.NatInstance.MachineType != "" {
+			machineType = cluster.Spec.Topology.NatInstance.MachineType
+		}
+		item := &LineItem{
+			Name:     "nat-instances",
+			Kind:     KindInstances,
+			Type:     machineType,
+			MinCount: natInstances,
+			MaxCount: natInstances,
+		}
+		unitPrice, found := e.prices.Instances[machineType]
+		if !found {
+			item.Note = "no price for machine type"
+			e.warnf("no price for machine type %q of the NAT instances", machineType)
+		}
+		e.addHourly(item, unitPrice)
+	}
+}
+
+func (e *estimator) addLoadBalancer(name string) {
+	e.addHourly(&LineItem{
+		Name:     "loadbalancer/" + name,
+		Kind:     KindLoadBalancer,
+		MinCount: 1,
+		MaxCount: 1,
+		Note:     "excludes data processed",
+	}, e.prices.LoadBalancer)
+}
+
+// ItemDelta is the change in the estimated monthly cost of a line item
+type ItemDelta struct {
+	Name string `json:"name"`
+	// Old and New are the line items before and after the change; nil if the item was added or removed
+	Old *LineItem `json:"old,omitempty"`
+	New *LineItem `json:"new,omitempty"`
+	// MinMonthly and MaxMonthly are the changes in the estimated monthly cost
+	MinMonthly float64 `json:"minMonthly"`
+	MaxMonthly float64 `json:"maxMonthly"`
+}
+
+// Delta returns the line items whose cost differs between two estimates, in the order of the new estimate
+// followed by the removed items
+func Delta(old *Estimate, new *Estimate) []*ItemDelta {
+	key := func(i *LineItem) string {
+		return i.Name + "/" + i.Type
+	}
+
+	oldItems := make(map[string]*LineItem)
+	for _, i := range old.Items {
+		oldItems[key(i)] = i
+	}
+
+	var deltas []*ItemDelta
+	seen := make(map[string]bool)
+	for _, n := range new.Items {
+		k := key(n)
+		seen[k] = true
+		d := &ItemDelta{Name: n.Name, New: n, MinMonthly: n.MinMonthly, MaxMonthly: n.MaxMonthly}
+		if o := oldItems[k]; o != nil {
+			d.Old = o
+			d.MinMonthly -= o.MinMonthly
+			d.MaxMonthly -= o.MaxMonthly
+			if d.MinMonthly == 0 && d.MaxMonthly == 0 && o.MinCount == n.MinCount && o.MaxCount == n.MaxCount {
+				continue
+			}
+		}
+		deltas = append(deltas, d)
+	}
+	for _, o := range old.Items {
+		if seen[key(o)] {
+			continue
+		}
+		deltas = append(deltas, &ItemDelta{Name: o.Name, Old: o, MinMonthly: -o.MinMonthly, MaxMonthly: -o.MaxMonthly})
+	}
+	return deltas
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+var testPrices = &Prices{
+	Cloud:     "aws",
+	Region:    "us-east-1",
+	Currency:  "USD",
+	Instances: map[string]float64{"m4.large": 0.1, "t2.micro": 0.01},
+	Volumes:   map[string]float64{"gp2": 0.1, "io1": 0.125},
+
+	NatGateway:   0.05,
+	LoadBalancer: 0.02,
+}
+
+func buildCluster() (*kops.Cluster, []*kops.InstanceGroup) {
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = "test.example.com"
+	cluster.Spec.CloudProvider = "aws"
+	cluster.Spec.Subnets = []kops.ClusterSubnetSpec{
+		{Name: "us-east-1a", Zone: "us-east-1a", Type: kops.SubnetTypePrivate},
+		{Name: "us-east-1b", Zone: "us-east-1b", Type: kops.SubnetTypePrivate},
+		{Name: "utility-us-east-1a", Zone: "us-east-1a", Type: kops.SubnetTypeUtility},
+	}
+	cluster.Spec.API = &kops.AccessSpec{LoadBalancer: &kops.LoadBalancerAccessSpec{}}
+	cluster.Spec.EtcdClusters = []*kops.EtcdClusterSpec{
+		{Name: "main", Members: []*kops.EtcdMemberSpec{{Name: "a"}}},
+	}
+
+	master := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-us-east-1a"},
+		Spec: kops.InstanceGroupSpec{
+			Role:        kops.InstanceGroupRoleMaster,
+			MachineType: "m4.large",
+		},
+	}
+	nodes := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: kops.InstanceGroupSpec{
+			Role:           kops.InstanceGroupRoleNode,
+			MachineType:    "t2.micro",
+			MinSize:        fi.Int32(2),
+			MaxSize:        fi.Int32(4),
+			RootVolumeSize: fi.Int32(10),
+		},
+	}
+	return cluster, []*kops.InstanceGroup{nodes, master}
+}
+
+func findItem(t *testing.T, e *Estimate, name string) *LineItem {
+	for _, i := range e.Items {
+		if i.Name == name {
+			return i
+		}
+	}
+	t.Fatalf("line item %q not found", name)
+	return nil
+}
+
+func expectMonthly(t *testing.T, name string, actual float64, expected float64) {
+	if math.Abs(actual-expected) > 0.001 {
+		t.Errorf("unexpected monthly cost of %s: %v, expected %v", name, actual, expected)
+	}
+}
+
+func TestEstimateCluster(t *testing.T) {
+	cluster, igs := buildCluster()
+
+	e, err := EstimateCluster(cluster, igs, "us-east-1", testPrices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(e.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", e.Warnings)
+	}
+
+	var names []string
+	for _, i := range e.Items {
+		names = append(names, i.Name)
+	}
+	expectedNames := `["instancegroup/master-us-east-1a","instancegroup/master-us-east-1a/root","instancegroup/nodes","instancegroup/nodes/root","etcd/main","nat-gateways","loadbalancer/api"]`
+	if b, _ := json.Marshal(names); string(b) != expectedNames {
+		t.Errorf("unexpected line items %s, expected %s", b, expectedNames)
+	}
+
+	nodes := findItem(t, e, "instancegroup/nodes")
+	expectMonthly(t, nodes.Name, nodes.MinMonthly, 2*0.01*HoursPerMonth)
+	expectMonthly(t, nodes.Name, nodes.MaxMonthly, 4*0.01*HoursPerMonth)
+
+	root := findItem(t, e, "instancegroup/nodes/root")
+	expectMonthly(t, root.Name, root.MaxMonthly, 4*10*0.1)
+
+	masterRoot := findItem(t, e, "instancegroup/master-us-east-1a/root")
+	expectMonthly(t, masterRoot.Name, masterRoot.MinMonthly, 64*0.1)
+
+	etcd := findItem(t, e, "etcd/main")
+	expectMonthly(t, etcd.Name, etcd.MinMonthly, 20*0.1)
+
+	nat := findItem(t, e, "nat-gateways")
+	if nat.MinCount != 2 {
+		t.Errorf("expected a NAT gateway per zone, got %d", nat.MinCount)
+	}
+
+	var total float64
+	for _, i := range e.Items {
+		total += i.MaxMonthly
+	}
+	expectMonthly(t, "cluster", e.MaxMonthly, total)
+}
+
+func TestEstimateClusterSpot(t *testing.T) {
+	cluster, igs := buildCluster()
+	igs[0].Spec.MaxPrice = fi.String("0.005")
+
+	e, err := EstimateCluster(cluster, igs, "us-east-1", testPrices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nodes := findItem(t, e, "instancegroup/nodes")
+	if nodes.UnitPrice != 0.005 {
+		t.Errorf("expected the max price to bound the spot price, got %v", nodes.UnitPrice)
+	}
+
+	prices := testPrices.Copy()
+	prices.SpotInstances = map[string]float64{"t2.micro": 0.003}
+	e, err = EstimateCluster(cluster, igs, "us-east-1", prices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nodes = findItem(t, e, "instancegroup/nodes")
+	if nodes.UnitPrice != 0.003 {
+		t.Errorf("expected the current spot price, got %v", nodes.UnitPrice)
+	}
+}
+
+func TestEstimateClusterWarnings(t *testing.T) {
+	cluster, igs := buildCluster()
+	igs[0].Spec.MachineType = "x1.32xlarge"
+
+	e, err := EstimateCluster(cluster, igs, "eu-west-1", testPrices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `["prices are for region us-east-1, not eu-west-1","no price for machine type \"x1.32xlarge\" of instancegroup/nodes"]`
+	if b, _ := json.Marshal(e.Warnings); string(b) != expected {
+		t.Errorf("unexpected warnings %s, expected %s", b, expected)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	cluster, igs := buildCluster()
+	old, err := EstimateCluster(cluster, igs, "us-east-1", testPrices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	igs[0].Spec.MaxSize = fi.Int32(6)
+	cluster.Spec.API = nil
+	new, err := EstimateCluster(cluster, igs, "us-east-1", testPrices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deltas := Delta(old, new)
+	var names []string
+	for _, d := range deltas {
+		names = append(names, d.Name)
+	}
+	expected := `["instancegroup/nodes","instancegroup/nodes/root","loadbalancer/api"]`
+	if b, _ := json.Marshal(names); string(b) != expected {
+		t.Fatalf("unexpected deltas %s, expected %s", b, expected)
+	}
+	expectMonthly(t, "nodes", deltas[0].MinMonthly, 0)
+	expectMonthly(t, "nodes", deltas[0].MaxMonthly, 2*0.01*HoursPerMonth)
+	if deltas[2].New != nil {
+		t.Errorf("expected the api load balancer to be removed")
+	}
+	expectMonthly(t, "api", deltas[2].MaxMonthly, -0.02*HoursPerMonth)
+}
+
+func TestParsePrices(t *testing.T) {
+	prices, err := ParsePrices([]byte("cloud: aws\nregion: eu-west-1\ncurrency: USD\ninstances:\n  m4.large: 0.111\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prices.Region != "eu-west-1" || prices.Instances["m4.large"] != 0.111 {
+		t.Errorf("unexpected prices %v", prices)
+	}
+
+	if _, err := ParsePrices([]byte("region: eu-west-1\n")); err == nil {
+		t.Errorf("expected an error for a price list without a cloud")
+	}
+}
+
+func TestParseAWSOnDemandPrice(t *testing.T) {
+	item := aws.JSONValue{}
+	data := `{"product": {"attributes": {"instanceType": "m4.large"}},
+		"terms": {"OnDemand": {"ABC.JRTCKXETXF": {"priceDimensions": {"ABC.JRTCKXETXF.6YS6EN2CT7": {
+			"unit": "Hrs", "pricePerUnit": {"USD": "0.1110000000"}}}}}}}`
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatalf("error parsing test data: %v", err)
+	}
+
+	price, err := parseAWSOnDemandPrice(item)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if price != 0.111 {
+		t.Errorf("unexpected price %v", price)
+	}
+
+	if _, err := parseAWSOnDemandPrice(aws.JSONValue{}); err == nil {
+		t.Errorf("expected an error for an item without terms")
+	}
+}
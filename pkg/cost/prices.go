@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// HoursPerMonth is the number of hours in an average month, as used by the cloud providers' pricing
+const HoursPerMonth = 730
+
+// Prices is a price list for a region of a cloud
+type Prices struct {
+	// Cloud is the cloud provider the prices are for, e.g. aws
+	Cloud string `json:"cloud"`
+	// Region is the region the prices are for
+	Region string `json:"region"`
+	// Date is when the prices were collected
+	Date string `json:"date,omitempty"`
+	// Currency is the currency of the prices, e.g. USD
+	Currency string `json:"currency"`
+	// Instances are the on-demand prices per hour, by machine type
+	Instances map[string]float64 `json:"instances"`
+	// SpotInstances are the current spot prices per hour, by machine type
+	SpotInstances map[string]float64 `json:"spotInstances,omitempty"`
+	// Volumes are the prices per GB-month, by volume type
+	Volumes map[string]float64 `json:"volumes"`
+	// NatGateway is the price of a NAT gateway per hour
+	NatGateway float64 `json:"natGateway,omitempty"`
+	// LoadBalancer is the price of a load balancer per hour
+	LoadBalancer float64 `json:"loadBalancer,omitempty"`
+}
+
+// ParsePrices parses a price list, in YAML or JSON
+func ParsePrices(data []byte) (*Prices, error) {
+	prices := &Prices{}
+	if err := yaml.Unmarshal(data, prices); err != nil {
+		return nil, fmt.Errorf("error parsing price list: %v", err)
+	}
+	if prices.Cloud == "" {
+		return nil, fmt.Errorf("price list does not specify the cloud")
+	}
+	return prices, nil
+}
+
+// Copy returns a deep copy of the price list
+func (p *Prices) Copy() *Prices {
+	data, err := json.Marshal(p)
+	if err != nil {
+		panic(fmt.Sprintf("error copying price list: %v", err))
+	}
+	c := &Prices{}
+	if err := json.Unmarshal(data, c); err != nil {
+		panic(fmt.Sprintf("error copying price list: %v", err))
+	}
+	return c
+}
+
+// SnapshotPrices returns the offline price snapshot for the cloud, or nil if there is none
+func SnapshotPrices(cloud string) *Prices {
+	switch cloud {
+	case "aws":
+		return awsSnapshot.Copy()
+	case "gce":
+		return gceSnapshot.Copy()
+	default:
+		return nil
+	}
+}
+
+// awsSnapshot holds the on-demand prices of Linux instances in us-east-1
+var awsSnapshot = &Prices{
+	Cloud:    "aws",
+	Region:   "us-east-1",
+	Date:     "2018-07-01",
+	Currency: "USD",
+	Instances: map[string]float64{
+		"t2.nano":     0.0058,
+		"t2.micro":    0.0116,
+		"t2.small":    0.023,
+		"t2.medium":   0.0464,
+		"t2.large":    0.0928,
+		"t2.xlarge":   0.1856,
+		"t2.2xlarge":  0.3712,
+		"t3.nano":     0.0052,
+		"t3.micro":    0.0104,
+		"t3.small":    0.0208,
+		"t3.medium":   0.0416,
+		"t3.large":    0.0832,
+		"t3.xlarge":   0.1664,
+		"t3.2xlarge":  0.3328,
+		"m3.medium":   0.067,
+		"m3.large":    0.133,
+		"m3.xlarge":   0.266,
+		"m3.2xlarge":  0.532,
+		"m4.large":    0.1,
+		"m4.xlarge":   0.2,
+		"m4.2xlarge":  0.4,
+		"m4.4xlarge":  0.8,
+		"m4.10xlarge": 2.0,
+		"m4.16xlarge": 3.2,
+		"m5.large":    0.096,
+		"m5.xlarge":   0.192,
+		"m5.2xlarge":  0.384,
+		"m5.4xlarge":  0.768,
+		"m5.12xlarge": 2.304,
+		"m5.24xlarge": 4.608,
+		"c4.large":    0.1,
+		"c4.xlarge":   0.199,
+		"c4.2xlarge":  0.398,
+		"c4.4xlarge":  0.796,
+		"c4.8xlarge":  1.591,
+		"c5.large":    0.085,
+		"c5.xlarge":   0.17,
+		"c5.2xlarge":  0.34,
+		"c5.4xlarge":  0.68,
+		"c5.9xlarge":  1.53,
+		"c5.18xlarge": 3.06,
+		"r4.large":    0.133,
+		"r4.xlarge":   0.266,
+		"r4.2xlarge":  0.532,
+		"r4.4xlarge":  1.064,
+		"r4.8xlarge":  2.128,
+		"r4.16xlarge": 4.256,
+		"r5.large":    0.126,
+		"r5.xlarge":   0.252,
+		"r5.2xlarge":  0.504,
+		"r5.4xlarge":  1.008,
+		"i3.large":    0.156,
+		"i3.xlarge":   0.312,
+		"i3.2xlarge":  0.624,
+		"i3.4xlarge":  1.248,
+		"p2.xlarge":   0.9,
+		"p3.2xlarge":  3.06,
+	},
+	Volumes: map[string]float64{
+		"gp2":      0.1,
+		"gp3":      0.08,
+		"io1":      0.125,
+		"st1":      0.045,
+		"sc1":      0.025,
+		"standard": 0.05,
+	},
+	NatGateway:   0.045,
+	LoadBalancer: 0.025,
+}
+
+// gceSnapshot holds the on-demand prices of instances in us-central1
+var gceSnapshot = &Prices{
+	Cloud:    "gce",
+	Region:   "us-central1",
+	Date:     "2018-07-01",
+	Currency: "USD",
+	Instances: map[string]float64{
+		"f1-micro":       0.0076,
+		"g1-small":       0.0257,
+		"n1-standard-1":  0.0475,
+		"n1-standard-2":  0.095,
+		"n1-standard-4":  0.19,
+		"n1-standard-8":  0.38,
+		"n1-standard-16": 0.76,
+		"n1-standard-32": 1.52,
+		"n1-highmem-2":   0.1184,
+		"n1-highmem-4":   0.2368,
+		"n1-highmem-8":   0.4736,
+		"n1-highmem-16":  0.9472,
+		"n1-highcpu-2":   0.0709,
+		"n1-highcpu-4":   0.1418,
+		"n1-highcpu-8":   0.2836,
+		"n1-highcpu-16":  0.5672,
+	},
+	Volumes: map[string]float64{
+		"pd-standard": 0.04,
+		"pd-ssd":      0.17,
+	},
+	LoadBalancer: 0.025,
+}
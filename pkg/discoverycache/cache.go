@@ -0,0 +1,349 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discoverycache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// HitHeader is set on responses that were served from the cache, so that callers (e.g. tracing) can tell them apart
+const HitHeader = "X-Kops-Discovery-Cache"
+
+// Options configures the discovery cache
+type Options struct {
+	// Dir is the directory in which cached responses are stored
+	Dir string
+
+	// TTL is the duration for which a cached response is considered fresh.  A zero TTL disables the cache.
+	TTL time.Duration
+
+	// Refresh ignores any existing cached responses, but still records new ones
+	Refresh bool
+}
+
+// Cache is an on-disk cache of read-only cloud API responses.
+// Any mutating request invalidates the entire cache and disables it for the remainder of the process,
+// so that we never act on (or poll) state we know to be stale.
+type Cache struct {
+	options Options
+
+	mutex sync.Mutex
+	// refreshed tracks the keys we have fetched during this process when Refresh is set
+	refreshed map[string]bool
+	// disabled is set once we have seen a mutating request
+	disabled bool
+}
+
+// cachedResponse is the on-disk representation of a cached response
+type cachedResponse struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+var (
+	activeMutex sync.Mutex
+	active      *Cache
+)
+
+// Configure sets the process-wide discovery cache, used by WrapTransport
+func Configure(options Options) {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	if options.TTL <= 0 || options.Dir == "" {
+		active = nil
+		return
+	}
+	active = New(options)
+}
+
+// New builds a Cache with the specified options
+func New(options Options) *Cache {
+	return &Cache{
+		options:   options,
+		refreshed: make(map[string]bool),
+	}
+}
+
+// DefaultDir returns the default cache directory for the named cluster
+func DefaultDir(clusterName string) (string, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return "", fmt.Errorf("cannot determine home directory for discovery cache; HOME is not set")
+	}
+	return filepath.Join(home, ".kops", "cache", "discovery", clusterName), nil
+}
+
+// WrapTransport returns a RoundTripper that serves read-only requests from the process-wide cache.
+// If the cache has not been configured, the transport is returned unchanged.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	activeMutex.Lock()
+	c := active
+	activeMutex.Unlock()
+
+	if c == nil {
+		return rt
+	}
+	return c.WrapTransport(rt)
+}
+
+// WrapTransport returns a RoundTripper that serves read-only requests from this cache
+func (c *Cache) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{cache: c, next: rt}
+}
+
+type transport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+var _ http.RoundTripper = &transport{}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %v", err)
+		}
+		req.Body.Close()
+		body = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	// STS calls return credentials or identities, never cloud state
+	if isSTS(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	if !isReadOnly(req, body) {
+		t.cache.Invalidate()
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req, body)
+	if cached := t.cache.get(key); cached != nil {
+		glog.V(4).Infof("serving %s %s from discovery cache", req.Method, req.URL.Host)
+		header := http.Header{}
+		for k, v := range cached.Header {
+			header[k] = v
+		}
+		header.Set(HitHeader, "hit")
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", cached.StatusCode, http.StatusText(cached.StatusCode)),
+			StatusCode:    cached.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	response, err := t.next.RoundTrip(req)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %v", err)
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	t.cache.put(key, &cachedResponse{
+		Timestamp:  time.Now(),
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       responseBody,
+	})
+
+	return response, nil
+}
+
+// Invalidate removes all cached responses, and stops further caching by this process
+func (c *Cache) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.disabled {
+		return
+	}
+	c.disabled = true
+
+	glog.V(2).Infof("cloud state is changing; invalidating discovery cache %q", c.options.Dir)
+	if err := os.RemoveAll(c.options.Dir); err != nil {
+		glog.Warningf("error removing discovery cache %q: %v", c.options.Dir, err)
+	}
+}
+
+func (c *Cache) get(key string) *cachedResponse {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.disabled {
+		return nil
+	}
+	if c.options.Refresh && !c.refreshed[key] {
+		return nil
+	}
+
+	p := filepath.Join(c.options.Dir, key+".json")
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			glog.Warningf("error reading discovery cache entry %q: %v", p, err)
+		}
+		return nil
+	}
+
+	cached := &cachedResponse{}
+	if err := json.Unmarshal(data, cached); err != nil {
+		glog.Warningf("ignoring invalid discovery cache entry %q: %v", p, err)
+		return nil
+	}
+
+	if time.Since(cached.Timestamp) > c.options.TTL {
+		return nil
+	}
+	return cached
+}
+
+func (c *Cache) put(key string, cached *cachedResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.disabled {
+		return
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		glog.Warningf("error serializing discovery cache entry: %v", err)
+		return
+	}
+
+	if err := os.MkdirAll(c.options.Dir, 0700); err != nil {
+		glog.Warningf("error creating discovery cache directory %q: %v", c.options.Dir, err)
+		return
+	}
+
+	p := filepath.Join(c.options.Dir, key+".json")
+	if err := ioutil.WriteFile(p, data, 0600); err != nil {
+		glog.Warningf("error writing discovery cache entry %q: %v", p, err)
+		return
+	}
+	c.refreshed[key] = true
+}
+
+// isReadOnly returns true if the request is known not to mutate cloud state.
+// REST APIs (GCE, Route53) use GET for reads; the AWS query APIs POST an Action.
+func isReadOnly(req *http.Request, body []byte) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return false
+		}
+		action := values.Get("Action")
+		for _, prefix := range []string{"Describe", "List", "Get"} {
+			if strings.HasPrefix(action, prefix) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// isSTS returns true if the request is a call to AWS STS
+func isSTS(req *http.Request) bool {
+	if strings.HasPrefix(req.URL.Host, "sts.") {
+		return true
+	}
+	credential := awsCredential(req)
+	return len(credential) == 5 && credential[3] == "sts"
+}
+
+// awsCredential returns the fields of the credential of an AWS signature version 4 Authorization header:
+// the access key ID, date, region, service and "aws4_request"
+func awsCredential(req *http.Request) []string {
+	auth := req.Header.Get("Authorization")
+	i := strings.Index(auth, "Credential=")
+	if i == -1 {
+		return nil
+	}
+	credential := auth[i+len("Credential="):]
+	if j := strings.IndexAny(credential, ", "); j != -1 {
+		credential = credential[:j]
+	}
+	return strings.Split(credential, "/")
+}
+
+// credentialIdentity identifies the credentials the request is made with, so that a response is never served to another
+// account or principal: the access key ID of an AWS signature, or else a hash of the Authorization header
+func credentialIdentity(req *http.Request) string {
+	if credential := awsCredential(req); len(credential) != 0 {
+		return credential[0]
+	}
+	auth := req.Header.Get("Authorization")
+	if auth == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(auth))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheKey computes a key for the request.  Apart from the identity of the credentials, headers are excluded because
+// they carry signatures and timestamps.
+func cacheKey(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(credentialIdentity(req)))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.String()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discoverycache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestServer(calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "response %d", atomic.AddInt32(calls, 1))
+	}))
+}
+
+func doRequest(t *testing.T, client *http.Client, method string, url string, body string) string {
+	return doAuthorizedRequest(t, client, method, url, body, "")
+}
+
+func doAuthorizedRequest(t *testing.T, client *http.Client, method string, url string, body string, authorization string) string {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("error building request: %v", err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	response, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("error doing request: %v", err)
+	}
+	defer response.Body.Close()
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading response: %v", err)
+	}
+	return string(b)
+}
+
+func TestCache_ServesReadsFromCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discoverycache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var calls int32
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	options := Options{Dir: dir, TTL: time.Hour}
+	describe := "Action=DescribeInstances&Version=2016-11-15"
+
+	client := &http.Client{Transport: New(options).WrapTransport(nil)}
+	if actual := doRequest(t, client, http.MethodPost, server.URL, describe); actual != "response 1" {
+		t.Fatalf("unexpected response %q", actual)
+	}
+
+	// A second process should be served from disk
+	client = &http.Client{Transport: New(options).WrapTransport(nil)}
+	if actual := doRequest(t, client, http.MethodPost, server.URL, describe); actual != "response 1" {
+		t.Fatalf("expected cached response, got %q", actual)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected 1 call to the server, got %d", n)
+	}
+
+	// Unless we ask for a refresh
+	refresh := options
+	refresh.Refresh = true
+	client = &http.Client{Transport: New(refresh).WrapTransport(nil)}
+	if actual := doRequest(t, client, http.MethodPost, server.URL, describe); actual != "response 2" {
+		t.Fatalf("expected refreshed response, got %q", actual)
+	}
+	if actual := doRequest(t, client, http.MethodPost, server.URL, describe); actual != "response 2" {
+		t.Fatalf("expected response cached during refresh, got %q", actual)
+	}
+}
+
+func TestCache_MutationInvalidates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discoverycache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var calls int32
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	options := Options{Dir: dir, TTL: time.Hour}
+	client := &http.Client{Transport: New(options).WrapTransport(nil)}
+
+	doRequest(t, client, http.MethodGet, server.URL+"/instances", "")
+	doRequest(t, client, http.MethodPost, server.URL, "Action=TerminateInstances&InstanceId.1=i-1")
+
+	if actual := doRequest(t, client, http.MethodGet, server.URL+"/instances", ""); actual != "response 3" {
+		t.Fatalf("expected live response after mutation, got %q", actual)
+	}
+	if actual := doRequest(t, client, http.MethodGet, server.URL+"/instances", ""); actual != "response 4" {
+		t.Fatalf("expected cache to stay disabled after mutation, got %q", actual)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("error reading cache dir: %v", err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected cache to be empty after mutation, found %d entries", len(files))
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discoverycache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var calls int32
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	options := Options{Dir: dir, TTL: time.Nanosecond}
+	client := &http.Client{Transport: New(options).WrapTransport(nil)}
+
+	doRequest(t, client, http.MethodGet, server.URL, "")
+	time.Sleep(time.Millisecond)
+	if actual := doRequest(t, client, http.MethodGet, server.URL, ""); actual != "response 2" {
+		t.Fatalf("expected expired entry to be refetched, got %q", actual)
+	}
+}
+
+func TestCache_KeyedByCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discoverycache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var calls int32
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	options := Options{Dir: dir, TTL: time.Hour}
+	describe := "Action=DescribeInstances&Version=2016-11-15"
+	signature := func(accessKeyID string, date string) string {
+		return "AWS4-HMAC-SHA256 Credential=" + accessKeyID + "/" + date + "/us-east-1/ec2/aws4_request, SignedHeaders=host, Signature=abc"
+	}
+
+	client := &http.Client{Transport: New(options).WrapTransport(nil)}
+	if actual := doAuthorizedRequest(t, client, http.MethodPost, server.URL, describe, signature("AKIDA", "20180101")); actual != "response 1" {
+		t.Fatalf("unexpected response %q", actual)
+	}
+	// The same credentials are served from the cache, even though the signature changes
+	if actual := doAuthorizedRequest(t, client, http.MethodPost, server.URL, describe, signature("AKIDA", "20180102")); actual != "response 1" {
+		t.Fatalf("expected cached response, got %q", actual)
+	}
+	// Other credentials, which may belong to another account, are not
+	if actual := doAuthorizedRequest(t, client, http.MethodPost, server.URL, describe, signature("AKIDB", "20180101")); actual != "response 2" {
+		t.Fatalf("expected live response for other credentials, got %q", actual)
+	}
+	if actual := doAuthorizedRequest(t, client, http.MethodGet, server.URL+"/instances", "", "Bearer token-a"); actual != "response 3" {
+		t.Fatalf("unexpected response %q", actual)
+	}
+	if actual := doAuthorizedRequest(t, client, http.MethodGet, server.URL+"/instances", "", "Bearer token-b"); actual != "response 4" {
+		t.Fatalf("expected live response for another bearer token, got %q", actual)
+	}
+}
+
+func TestCache_NeverCachesSTS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discoverycache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var calls int32
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	options := Options{Dir: dir, TTL: time.Hour}
+	identity := "Action=GetCallerIdentity&Version=2011-06-15"
+	authorization := "AWS4-HMAC-SHA256 Credential=AKIDA/20180101/us-east-1/sts/aws4_request, SignedHeaders=host, Signature=abc"
+
+	client := &http.Client{Transport: New(options).WrapTransport(nil)}
+	doAuthorizedRequest(t, client, http.MethodGet, server.URL+"/instances", "", "")
+	doAuthorizedRequest(t, client, http.MethodPost, server.URL, identity, authorization)
+	if actual := doAuthorizedRequest(t, client, http.MethodPost, server.URL, identity, authorization); actual != "response 3" {
+		t.Fatalf("expected live response for STS, got %q", actual)
+	}
+
+	// STS calls do not change cloud state, so they leave the cache in place
+	if actual := doRequest(t, client, http.MethodGet, server.URL+"/instances", ""); actual != "response 1" {
+		t.Fatalf("expected cached response, got %q", actual)
+	}
+}
+
+func TestIsSTS(t *testing.T) {
+	grid := []struct {
+		URL           string
+		Authorization string
+		Expected      bool
+	}{
+		{URL: "https://sts.amazonaws.com/", Expected: true},
+		{URL: "https://sts.us-east-1.amazonaws.com/", Expected: true},
+		{URL: "https://vpce-1.sts.us-east-1.vpce.amazonaws.com/", Authorization: "AWS4-HMAC-SHA256 Credential=AKIDA/20180101/us-east-1/sts/aws4_request, SignedHeaders=host, Signature=abc", Expected: true},
+		{URL: "https://ec2.us-east-1.amazonaws.com/", Authorization: "AWS4-HMAC-SHA256 Credential=AKIDA/20180101/us-east-1/ec2/aws4_request, SignedHeaders=host, Signature=abc", Expected: false},
+		{URL: "https://www.googleapis.com/compute/v1/projects/p/zones", Authorization: "Bearer token", Expected: false},
+	}
+	for _, g := range grid {
+		req, err := http.NewRequest(http.MethodPost, g.URL, nil)
+		if err != nil {
+			t.Fatalf("error building request: %v", err)
+		}
+		if g.Authorization != "" {
+			req.Header.Set("Authorization", g.Authorization)
+		}
+		if actual := isSTS(req); actual != g.Expected {
+			t.Errorf("isSTS(%s) = %v, expected %v", g.URL, actual, g.Expected)
+		}
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	grid := []struct {
+		Method   string
+		Body     string
+		Expected bool
+	}{
+		{Method: http.MethodGet, Expected: true},
+		{Method: http.MethodDelete, Expected: false},
+		{Method: http.MethodPost, Body: "Action=DescribeAutoScalingGroups&Version=2011-01-01", Expected: true},
+		{Method: http.MethodPost, Body: "Action=ListRoles&Version=2010-05-08", Expected: true},
+		{Method: http.MethodPost, Body: "Action=GetRole&RoleName=masters", Expected: true},
+		{Method: http.MethodPost, Body: "Action=CreateTags&Version=2016-11-15", Expected: false},
+		{Method: http.MethodPost, Body: `{"instances": []}`, Expected: false},
+	}
+	for _, g := range grid {
+		req, err := http.NewRequest(g.Method, "https://ec2.us-east-1.amazonaws.com/", nil)
+		if err != nil {
+			t.Fatalf("error building request: %v", err)
+		}
+		actual := isReadOnly(req, []byte(g.Body))
+		if actual != g.Expected {
+			t.Errorf("isReadOnly(%s %q) = %v, expected %v", g.Method, g.Body, actual, g.Expected)
+		}
+	}
+}
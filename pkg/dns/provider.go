@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"sort"
+	"strconv"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// ExternalProviderID returns the id of the external DNS provider plugin configured for the cluster, or "" if the
+// cluster uses the DNS service of its cloud
+func ExternalProviderID(cluster *kops.Cluster) string {
+	if cluster.Spec.ExternalDNS == nil {
+		return ""
+	}
+	return cluster.Spec.ExternalDNS.Provider
+}
+
+// ExternalProviderConfig returns the configuration for the cluster's external DNS provider plugin, as key=value pairs.
+// Credentials are not included; the plugins read them from their environment.
+func ExternalProviderConfig(cluster *kops.Cluster) []string {
+	var config []string
+	switch ExternalProviderID(cluster) {
+	case kops.ExternalDNSProviderInfoblox:
+		infoblox := cluster.Spec.ExternalDNS.Infoblox
+		if infoblox == nil {
+			break
+		}
+		config = append(config, "host="+infoblox.Host)
+		if infoblox.WAPIVersion != "" {
+			config = append(config, "wapi-version="+infoblox.WAPIVersion)
+		}
+		if infoblox.View != "" {
+			config = append(config, "view="+infoblox.View)
+		}
+		if infoblox.InsecureSkipVerify {
+			config = append(config, "insecure-skip-verify="+strconv.FormatBool(infoblox.InsecureSkipVerify))
+		}
+	}
+	return config
+}
+
+// AWSRoute53ProviderConfig returns the configuration of the aws-route53 DNS provider for the cluster, as key=value
+// pairs. It is only needed when the records are managed with another role, e.g. because the hosted zone is in
+// another account; the role is assumed with the credentials of the instance.
+func AWSRoute53ProviderConfig(cluster *kops.Cluster) []string {
+	if cluster.Spec.CloudConfig == nil || cluster.Spec.CloudConfig.AWSDNSAssumeRole == nil {
+		return nil
+	}
+	role := cluster.Spec.CloudConfig.AWSDNSAssumeRole
+	if role.RoleARN == "" {
+		return nil
+	}
+
+	config := []string{"assume-role-arn=" + role.RoleARN}
+	if role.ExternalID != "" {
+		config = append(config, "assume-role-external-id="+role.ExternalID)
+	}
+	if role.SessionName != "" {
+		config = append(config, "assume-role-session-name="+role.SessionName)
+	}
+
+	var keys []string
+	for k := range role.SessionTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		config = append(config, "assume-role-session-tag="+k+"="+role.SessionTags[k])
+	}
+	return config
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	crypto_rand "crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// SecretName is the name of the secret in the secret store holding the encryption config
+const SecretName = "encryptionconfig"
+
+const (
+	// ProviderAESCBC is the aescbc provider, with keys stored in the encryption config
+	ProviderAESCBC = "aescbc"
+	// ProviderKMS is the kms envelope encryption provider
+	ProviderKMS = "kms"
+)
+
+// EncryptionConfig is the configuration file read by the kube-apiserver --experimental-encryption-provider-config flag
+type EncryptionConfig struct {
+	Kind       string           `json:"kind"`
+	APIVersion string           `json:"apiVersion"`
+	Resources  []ResourceConfig `json:"resources"`
+}
+
+// ResourceConfig is the set of providers used to encrypt a list of resources
+type ResourceConfig struct {
+	Resources []string         `json:"resources"`
+	Providers []ProviderConfig `json:"providers"`
+}
+
+// ProviderConfig is a single encryption provider; only one of the fields should be set
+type ProviderConfig struct {
+	AESCBC   *KeysConfig     `json:"aescbc,omitempty"`
+	KMS      *KMSConfig      `json:"kms,omitempty"`
+	Identity *IdentityConfig `json:"identity,omitempty"`
+}
+
+// KeysConfig holds the keys for the aescbc provider; the first key is used for encryption, all keys for decryption
+type KeysConfig struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key is a named encryption key
+type Key struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+// KMSConfig configures the kms provider
+type KMSConfig struct {
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint"`
+	CacheSize int32  `json:"cachesize,omitempty"`
+}
+
+// IdentityConfig is the (non-)encryption provider, allowing unencrypted data to be read
+type IdentityConfig struct{}
+
+// Build generates a new encryption config for the provider, including new key material
+func Build(spec *kops.EncryptionProviderSpec) (*EncryptionConfig, error) {
+	providerType := ProviderAESCBC
+	if spec != nil && spec.Type != "" {
+		providerType = spec.Type
+	}
+
+	var provider ProviderConfig
+	switch providerType {
+	case ProviderAESCBC:
+		key, err := newKey(1)
+		if err != nil {
+			return nil, err
+		}
+		provider.AESCBC = &KeysConfig{Keys: []Key{*key}}
+
+	case ProviderKMS:
+		if spec.KMS == nil {
+			return nil, fmt.Errorf("kms must be configured for the kms encryption provider")
+		}
+		provider.KMS = &KMSConfig{
+			Name:     spec.KMS.Name,
+			Endpoint: spec.KMS.Endpoint,
+		}
+		if spec.KMS.CacheSize != nil {
+			provider.KMS.CacheSize = *spec.KMS.CacheSize
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown encryption provider %q", providerType)
+	}
+
+	config := &EncryptionConfig{
+		Kind:       "EncryptionConfig",
+		APIVersion: "v1",
+		Resources: []ResourceConfig{
+			{
+				Resources: []string{"secrets"},
+				// We keep the identity provider so that secrets written before encryption was enabled can still be read
+				Providers: []ProviderConfig{provider, {Identity: &IdentityConfig{}}},
+			},
+		},
+	}
+	return config, nil
+}
+
+// Parse parses an encryption config, as stored in the secret store
+func Parse(data []byte) (*EncryptionConfig, error) {
+	config := &EncryptionConfig{}
+	if err := kops.ParseRawYaml(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing encryption config: %v", err)
+	}
+	return config, nil
+}
+
+// ToYAML serializes the encryption config
+func (c *EncryptionConfig) ToYAML() ([]byte, error) {
+	return kops.ToRawYaml(c)
+}
+
+// aescbcKeys returns the aescbc keys for the secrets resource, which is the only config that supports key rotation
+func (c *EncryptionConfig) aescbcKeys() (*KeysConfig, error) {
+	for i := range c.Resources {
+		for j := range c.Resources[i].Providers {
+			if c.Resources[i].Providers[j].AESCBC != nil {
+				return c.Resources[i].Providers[j].AESCBC, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("key rotation is only supported for the %s encryption provider", ProviderAESCBC)
+}
+
+// SupportsKeyRotation returns true if the config has aescbc keys that kops can rotate
+func (c *EncryptionConfig) SupportsKeyRotation() bool {
+	_, err := c.aescbcKeys()
+	return err == nil
+}
+
+// AddKey adds a new aescbc key to the config, after the existing keys, so it can be used for decryption but is not yet used for encryption
+func (c *EncryptionConfig) AddKey() (*Key, error) {
+	keys, err := c.aescbcKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	next := 1
+	for _, k := range keys.Keys {
+		n, err := strconv.Atoi(strings.TrimPrefix(k.Name, "key"))
+		if err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	key, err := newKey(next)
+	if err != nil {
+		return nil, err
+	}
+	keys.Keys = append(keys.Keys, *key)
+	return key, nil
+}
+
+// PromoteNewestKey makes the most recently added aescbc key the key used for encryption
+func (c *EncryptionConfig) PromoteNewestKey() (*Key, error) {
+	keys, err := c.aescbcKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys.Keys) < 2 {
+		return nil, fmt.Errorf("no new key to promote; add a key first")
+	}
+
+	newest := keys.Keys[len(keys.Keys)-1]
+	promoted := []Key{newest}
+	promoted = append(promoted, keys.Keys[:len(keys.Keys)-1]...)
+	keys.Keys = promoted
+	return &newest, nil
+}
+
+// PruneKeys removes all the aescbc keys other than the key used for encryption, returning the removed keys
+func (c *EncryptionConfig) PruneKeys() ([]Key, error) {
+	keys, err := c.aescbcKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys.Keys) < 2 {
+		return nil, nil
+	}
+
+	pruned := keys.Keys[1:]
+	keys.Keys = keys.Keys[:1]
+	return pruned, nil
+}
+
+// newKey generates a new random 32 byte aescbc key
+func newKey(n int) (*Key, error) {
+	data := make([]byte, 32)
+	if _, err := crypto_rand.Read(data); err != nil {
+		return nil, fmt.Errorf("error reading crypto_rand: %v", err)
+	}
+
+	return &Key{
+		Name:   "key" + strconv.Itoa(n),
+		Secret: base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestBuildAESCBC(t *testing.T) {
+	config, err := Build(&kops.EncryptionProviderSpec{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := config.ToYAML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	providers := parsed.Resources[0].Providers
+	if len(providers) != 2 || providers[0].AESCBC == nil || providers[1].Identity == nil {
+		t.Fatalf("expected aescbc then identity providers, got %s", string(data))
+	}
+	keys := providers[0].AESCBC.Keys
+	if len(keys) != 1 || keys[0].Name != "key1" || keys[0].Secret == "" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+}
+
+func TestBuildKMS(t *testing.T) {
+	spec := &kops.EncryptionProviderSpec{
+		Type: ProviderKMS,
+		KMS: &kops.KMSEncryptionProviderSpec{
+			Name:     "aws-encryption-provider",
+			Endpoint: "unix:///var/run/kmsplugin/socket.sock",
+		},
+	}
+	config, err := Build(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := config.ToYAML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), "endpoint: unix:///var/run/kmsplugin/socket.sock") {
+		t.Fatalf("kms endpoint not found in %s", string(data))
+	}
+
+	if _, err := config.AddKey(); err == nil {
+		t.Fatalf("expected error rotating kms keys")
+	}
+
+	if _, err := Build(&kops.EncryptionProviderSpec{Type: ProviderKMS}); err == nil {
+		t.Fatalf("expected error building kms config without kms settings")
+	}
+}
+
+func TestRotateKeys(t *testing.T) {
+	config, err := Build(&kops.EncryptionProviderSpec{Type: ProviderAESCBC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := config.Resources[0].Providers[0].AESCBC
+
+	if _, err := config.PromoteNewestKey(); err == nil {
+		t.Fatalf("expected error promoting without a new key")
+	}
+
+	added, err := config.AddKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added.Name != "key2" {
+		t.Fatalf("expected key2, got %q", added.Name)
+	}
+	if keys.Keys[0].Name != "key1" {
+		t.Fatalf("new key should not be used for encryption until promoted")
+	}
+
+	if _, err := config.PromoteNewestKey(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys.Keys[0].Name != "key2" || keys.Keys[1].Name != "key1" {
+		t.Fatalf("unexpected key order after promotion: %v", keys.Keys)
+	}
+
+	pruned, err := config.PruneKeys()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].Name != "key1" {
+		t.Fatalf("unexpected pruned keys: %v", pruned)
+	}
+	if len(keys.Keys) != 1 || keys.Keys[0].Name != "key2" {
+		t.Fatalf("unexpected keys after pruning: %v", keys.Keys)
+	}
+}
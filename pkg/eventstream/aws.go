@@ -0,0 +1,216 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventstream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/golang/glog"
+)
+
+// AutoscalingSource reports the scaling activities of autoscaling groups, i.e. instance launches and terminations
+type AutoscalingSource struct {
+	client     autoscalingiface.AutoScalingAPI
+	groupNames []string
+	since      time.Time
+
+	// statuses holds the last reported status of each activity, by activity id
+	statuses map[string]string
+}
+
+var _ Source = &AutoscalingSource{}
+
+// NewAutoscalingSource builds an AutoscalingSource for the activities of the named groups that start after since
+func NewAutoscalingSource(client autoscalingiface.AutoScalingAPI, groupNames []string, since time.Time) *AutoscalingSource {
+	return &AutoscalingSource{
+		client:     client,
+		groupNames: groupNames,
+		since:      since,
+		statuses:   make(map[string]string),
+	}
+}
+
+func (s *AutoscalingSource) Name() string {
+	return "autoscaling"
+}
+
+func (s *AutoscalingSource) Poll() ([]*Event, error) {
+	var events []*Event
+	for _, groupName := range s.groupNames {
+		request := &autoscaling.DescribeScalingActivitiesInput{
+			AutoScalingGroupName: aws.String(groupName),
+		}
+		response, err := s.client.DescribeScalingActivities(request)
+		if err != nil {
+			// The group might not have been created yet
+			glog.V(4).Infof("error listing scaling activities for %q: %v", groupName, err)
+			continue
+		}
+
+		for _, activity := range response.Activities {
+			startTime := aws.TimeValue(activity.StartTime)
+			if startTime.Before(s.since) {
+				continue
+			}
+
+			id := aws.StringValue(activity.ActivityId)
+			status := aws.StringValue(activity.StatusCode)
+			if s.statuses[id] == status {
+				continue
+			}
+			s.statuses[id] = status
+
+			t := startTime
+			if activity.EndTime != nil {
+				t = aws.TimeValue(activity.EndTime)
+			}
+
+			message := fmt.Sprintf("%s (%s)", aws.StringValue(activity.Description), status)
+			if aws.StringValue(activity.StatusMessage) != "" {
+				message += ": " + aws.StringValue(activity.StatusMessage)
+			}
+
+			events = append(events, &Event{
+				Time:    t,
+				Source:  s.Name(),
+				Object:  groupName,
+				Message: message,
+			})
+		}
+	}
+	return events, nil
+}
+
+// LoadBalancerSource reports changes in the health of the instances registered with classic load balancers
+type LoadBalancerSource struct {
+	client            elbiface.ELBAPI
+	loadBalancerNames func() ([]string, error)
+
+	// states holds the last seen state of each instance, by load balancer then instance id
+	states map[string]map[string]string
+}
+
+var _ Source = &LoadBalancerSource{}
+
+// NewLoadBalancerSource builds a LoadBalancerSource; the load balancers are listed on every poll, as they may be created during the operation
+func NewLoadBalancerSource(client elbiface.ELBAPI, loadBalancerNames func() ([]string, error)) *LoadBalancerSource {
+	return &LoadBalancerSource{
+		client:            client,
+		loadBalancerNames: loadBalancerNames,
+		states:            make(map[string]map[string]string),
+	}
+}
+
+func (s *LoadBalancerSource) Name() string {
+	return "elb"
+}
+
+func (s *LoadBalancerSource) Poll() ([]*Event, error) {
+	names, err := s.loadBalancerNames()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var events []*Event
+	for _, name := range names {
+		request := &elb.DescribeInstanceHealthInput{
+			LoadBalancerName: aws.String(name),
+		}
+		response, err := s.client.DescribeInstanceHealth(request)
+		if err != nil {
+			glog.V(4).Infof("error describing instance health for load balancer %q: %v", name, err)
+			continue
+		}
+
+		states, found := s.states[name]
+		if !found {
+			states = make(map[string]string)
+			s.states[name] = states
+		}
+
+		for _, instance := range response.InstanceStates {
+			id := aws.StringValue(instance.InstanceId)
+			state := aws.StringValue(instance.State)
+			previous, seen := states[id]
+			states[id] = state
+
+			// The first poll of a load balancer only records the existing states
+			if !found || previous == state {
+				continue
+			}
+
+			message := fmt.Sprintf("instance %s is %s", id, state)
+			if seen {
+				message = fmt.Sprintf("instance %s changed from %s to %s", id, previous, state)
+			}
+			if aws.StringValue(instance.Description) != "" && aws.StringValue(instance.Description) != "N/A" {
+				message += ": " + aws.StringValue(instance.Description)
+			}
+
+			events = append(events, &Event{
+				Time:    now,
+				Source:  s.Name(),
+				Object:  name,
+				Message: message,
+			})
+		}
+	}
+	return events, nil
+}
+
+// AutoscalingLoadBalancerNames returns a function listing the classic load balancers attached to the named autoscaling groups
+func AutoscalingLoadBalancerNames(client autoscalingiface.AutoScalingAPI, groupNames []string) func() ([]string, error) {
+	return func() ([]string, error) {
+		var names []string
+		seen := make(map[string]bool)
+
+		// DescribeAutoScalingGroups accepts at most 50 names
+		for i := 0; i < len(groupNames); i += 50 {
+			end := i + 50
+			if end > len(groupNames) {
+				end = len(groupNames)
+			}
+
+			request := &autoscaling.DescribeAutoScalingGroupsInput{
+				AutoScalingGroupNames: aws.StringSlice(groupNames[i:end]),
+			}
+			err := client.DescribeAutoScalingGroupsPages(request, func(p *autoscaling.DescribeAutoScalingGroupsOutput, lastPage bool) bool {
+				for _, g := range p.AutoScalingGroups {
+					for _, name := range g.LoadBalancerNames {
+						if !seen[aws.StringValue(name)] {
+							seen[aws.StringValue(name)] = true
+							names = append(names, aws.StringValue(name))
+						}
+					}
+				}
+				return true
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error listing autoscaling groups: %v", err)
+			}
+		}
+		return names, nil
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventstream
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+)
+
+type fakeAutoscaling struct {
+	autoscalingiface.AutoScalingAPI
+
+	activities map[string][]*autoscaling.Activity
+}
+
+func (f *fakeAutoscaling) DescribeScalingActivities(request *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	return &autoscaling.DescribeScalingActivitiesOutput{
+		Activities: f.activities[aws.StringValue(request.AutoScalingGroupName)],
+	}, nil
+}
+
+type fakeELB struct {
+	elbiface.ELBAPI
+
+	states map[string][]*elb.InstanceState
+}
+
+func (f *fakeELB) DescribeInstanceHealth(request *elb.DescribeInstanceHealthInput) (*elb.DescribeInstanceHealthOutput, error) {
+	return &elb.DescribeInstanceHealthOutput{
+		InstanceStates: f.states[aws.StringValue(request.LoadBalancerName)],
+	}, nil
+}
+
+func messages(events []*Event) []string {
+	var l []string
+	for _, e := range events {
+		l = append(l, e.Object+": "+e.Message)
+	}
+	return l
+}
+
+func TestAutoscalingSource(t *testing.T) {
+	since := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	old := &autoscaling.Activity{
+		ActivityId:  aws.String("old"),
+		Description: aws.String("Launching a new EC2 instance: i-0"),
+		StartTime:   aws.Time(since.Add(-time.Minute)),
+		StatusCode:  aws.String("Successful"),
+	}
+	launch := &autoscaling.Activity{
+		ActivityId:  aws.String("launch"),
+		Description: aws.String("Launching a new EC2 instance: i-1"),
+		StartTime:   aws.Time(since.Add(time.Minute)),
+		StatusCode:  aws.String("InProgress"),
+	}
+
+	client := &fakeAutoscaling{activities: map[string][]*autoscaling.Activity{
+		"nodes.example.com": {launch, old},
+	}}
+	s := NewAutoscalingSource(client, []string{"nodes.example.com"}, since)
+
+	events, _ := s.Poll()
+	expected := []string{"nodes.example.com: Launching a new EC2 instance: i-1 (InProgress)"}
+	if !reflect.DeepEqual(messages(events), expected) {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+
+	// Unchanged activities are not reported again
+	events, _ = s.Poll()
+	if len(events) != 0 {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+
+	launch.StatusCode = aws.String("Failed")
+	launch.StatusMessage = aws.String("Instance limit exceeded")
+	launch.EndTime = aws.Time(since.Add(2 * time.Minute))
+	events, _ = s.Poll()
+	expected = []string{"nodes.example.com: Launching a new EC2 instance: i-1 (Failed): Instance limit exceeded"}
+	if !reflect.DeepEqual(messages(events), expected) {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+	if !events[0].Time.Equal(since.Add(2 * time.Minute)) {
+		t.Fatalf("expected the time of a finished activity to be its end time, got %v", events[0].Time)
+	}
+}
+
+func TestLoadBalancerSource(t *testing.T) {
+	client := &fakeELB{states: map[string][]*elb.InstanceState{
+		"api-example": {
+			{InstanceId: aws.String("i-1"), State: aws.String("InService"), Description: aws.String("N/A")},
+		},
+	}}
+	s := NewLoadBalancerSource(client, func() ([]string, error) {
+		return []string{"api-example"}, nil
+	})
+
+	// The first poll records the existing states
+	events, _ := s.Poll()
+	if len(events) != 0 {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+
+	client.states["api-example"] = []*elb.InstanceState{
+		{InstanceId: aws.String("i-1"), State: aws.String("OutOfService"), Description: aws.String("Instance has failed at least the UnhealthyThreshold number of health checks consecutively.")},
+		{InstanceId: aws.String("i-2"), State: aws.String("OutOfService"), Description: aws.String("Instance registration is still in progress.")},
+	}
+	events, _ = s.Poll()
+	expected := []string{
+		"api-example: instance i-1 changed from InService to OutOfService: Instance has failed at least the UnhealthyThreshold number of health checks consecutively.",
+		"api-example: instance i-2 is OutOfService: Instance registration is still in progress.",
+	}
+	if !reflect.DeepEqual(messages(events), expected) {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventstream
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// relevantKubernetesReasons are the reasons of normal events that are reported, in addition to warnings and node events
+var relevantKubernetesReasons = map[string]bool{
+	"Evicted":    true,
+	"Killing":    true,
+	"Preempting": true,
+}
+
+// KubernetesSource reports kubernetes events about nodes, warnings and pod evictions
+type KubernetesSource struct {
+	client kubernetes.Interface
+	since  time.Time
+
+	// counts holds the last reported count of each event, as repeated events are aggregated
+	counts map[types.UID]int32
+}
+
+var _ Source = &KubernetesSource{}
+
+// NewKubernetesSource builds a KubernetesSource for the events that happen after since
+func NewKubernetesSource(client kubernetes.Interface, since time.Time) *KubernetesSource {
+	return &KubernetesSource{
+		client: client,
+		since:  since,
+		counts: make(map[types.UID]int32),
+	}
+}
+
+func (s *KubernetesSource) Name() string {
+	return "kubernetes"
+}
+
+func (s *KubernetesSource) Poll() ([]*Event, error) {
+	list, err := s.client.CoreV1().Events("").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing events: %v", err)
+	}
+
+	var events []*Event
+	for i := range list.Items {
+		e := &list.Items[i]
+
+		t := e.LastTimestamp.Time
+		if t.IsZero() {
+			t = e.FirstTimestamp.Time
+		}
+		if t.Before(s.since) {
+			continue
+		}
+
+		if !isRelevantKubernetesEvent(e) {
+			continue
+		}
+
+		if count, found := s.counts[e.UID]; found && count == e.Count {
+			continue
+		}
+		s.counts[e.UID] = e.Count
+
+		message := e.Reason + ": " + strings.TrimSpace(e.Message)
+		if e.Count > 1 {
+			message += fmt.Sprintf(" (x%d)", e.Count)
+		}
+
+		events = append(events, &Event{
+			Time:    t,
+			Source:  s.Name(),
+			Object:  kubernetesObjectName(&e.InvolvedObject),
+			Message: message,
+		})
+	}
+	return events, nil
+}
+
+// isRelevantKubernetesEvent is true for the events that help diagnose an update: node events, warnings and pod evictions
+func isRelevantKubernetesEvent(e *v1.Event) bool {
+	if e.InvolvedObject.Kind == "Node" {
+		return true
+	}
+	if e.Type == v1.EventTypeWarning {
+		return true
+	}
+	return relevantKubernetesReasons[e.Reason]
+}
+
+// kubernetesObjectName renders the object as kind/name, or kind/namespace/name for namespaced objects
+func kubernetesObjectName(o *v1.ObjectReference) string {
+	kind := strings.ToLower(o.Kind)
+	if o.Namespace == "" {
+		return kind + "/" + o.Name
+	}
+	return kind + "/" + o.Namespace + "/" + o.Name
+}
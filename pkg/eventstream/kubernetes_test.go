@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventstream
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKubernetesSource(t *testing.T) {
+	since := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	event := func(name string, t time.Time, object v1.ObjectReference, eventType string, reason string, message string) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+			InvolvedObject: object,
+			Type:           eventType,
+			Reason:         reason,
+			Message:        message,
+			Count:          1,
+			FirstTimestamp: metav1.NewTime(t),
+			LastTimestamp:  metav1.NewTime(t),
+		}
+	}
+
+	node := v1.ObjectReference{Kind: "Node", Name: "node1"}
+	pod := v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-1"}
+
+	client := fake.NewSimpleClientset(
+		event("old", since.Add(-time.Minute), node, v1.EventTypeNormal, "RegisteredNode", "old"),
+		event("registered", since.Add(time.Minute), node, v1.EventTypeNormal, "RegisteredNode", "Node node1 event: Registered Node node1"),
+		event("scheduled", since.Add(time.Minute), pod, v1.EventTypeNormal, "Scheduled", "Successfully assigned web-1 to node1"),
+		event("evicted", since.Add(time.Minute), pod, v1.EventTypeNormal, "Evicted", "The node was low on resource: memory."),
+		event("backoff", since.Add(time.Minute), pod, v1.EventTypeWarning, "BackOff", "Back-off restarting failed container"),
+	)
+
+	s := NewKubernetesSource(client, since)
+
+	events, err := s.Poll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]bool{
+		"node/node1: RegisteredNode: Node node1 event: Registered Node node1": true,
+		"pod/default/web-1: Evicted: The node was low on resource: memory.":   true,
+		"pod/default/web-1: BackOff: Back-off restarting failed container":    true,
+	}
+	actual := make(map[string]bool)
+	for _, m := range messages(events) {
+		actual[m] = true
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+
+	// Only repeats of an event are reported again
+	e := event("backoff", since.Add(2*time.Minute), pod, v1.EventTypeWarning, "BackOff", "Back-off restarting failed container")
+	e.Count = 3
+	if _, err := client.CoreV1().Events("default").Update(e); err != nil {
+		t.Fatalf("error updating event: %v", err)
+	}
+	events, err = s.Poll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(messages(events), []string{"pod/default/web-1: BackOff: Back-off restarting failed container (x3)"}) {
+		t.Fatalf("unexpected events: %v", messages(events))
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventstream
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// DefaultInterval is how often sources are polled for new events
+const DefaultInterval = 10 * time.Second
+
+// Event is something that happened to the cluster's cloud or kubernetes resources
+type Event struct {
+	// Time is when the event happened
+	Time time.Time
+	// Source is where the event came from, e.g. autoscaling, elb or kubernetes
+	Source string
+	// Object names the resource the event is about, e.g. the autoscaling group or node
+	Object string
+	// Message describes the event
+	Message string
+}
+
+// String renders the event as a single line of output
+func (e *Event) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", e.Time.Local().Format("15:04:05"), e.Source, e.Object, e.Message)
+}
+
+// Source is polled for the events that happened since it was last polled
+type Source interface {
+	// Name identifies the source in log messages
+	Name() string
+	// Poll returns the events not returned by a previous call
+	Poll() ([]*Event, error)
+}
+
+// Stream polls its sources in the background, writing their events to Out
+type Stream struct {
+	Sources  []Source
+	Out      io.Writer
+	Interval time.Duration
+
+	mutex sync.Mutex
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// Start begins polling the sources, until Stop is called
+func (s *Stream) Start() {
+	interval := s.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.Poll()
+			}
+		}
+	}()
+}
+
+// Stop stops polling, after writing any events that happened since the last poll
+func (s *Stream) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+
+	s.Poll()
+}
+
+// Poll polls each source once, writing the new events in time order
+func (s *Stream) Poll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var events []*Event
+	for _, source := range s.Sources {
+		l, err := source.Poll()
+		if err != nil {
+			// Events are only diagnostic; don't interrupt the operation
+			glog.V(2).Infof("error polling %s events: %v", source.Name(), err)
+			continue
+		}
+		events = append(events, l...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	for _, e := range events {
+		fmt.Fprintf(s.Out, "%s\n", e.String())
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventstream
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	events [][]*Event
+}
+
+func (s *fakeSource) Name() string {
+	return "fake"
+}
+
+func (s *fakeSource) Poll() ([]*Event, error) {
+	if len(s.events) == 0 {
+		return nil, nil
+	}
+	events := s.events[0]
+	s.events = s.events[1:]
+	return events, nil
+}
+
+func TestStreamPollOrdersEvents(t *testing.T) {
+	t0 := time.Date(2018, 6, 1, 12, 0, 0, 0, time.Local)
+
+	a := &fakeSource{events: [][]*Event{{
+		{Time: t0.Add(2 * time.Second), Source: "autoscaling", Object: "nodes.example.com", Message: "second"},
+	}}}
+	b := &fakeSource{events: [][]*Event{{
+		{Time: t0.Add(1 * time.Second), Source: "kubernetes", Object: "node/node1", Message: "first"},
+		{Time: t0.Add(3 * time.Second), Source: "kubernetes", Object: "node/node2", Message: "third"},
+	}}}
+
+	var out bytes.Buffer
+	s := &Stream{Sources: []Source{a, b}, Out: &out}
+	s.Poll()
+	s.Poll()
+
+	expected := strings.Join([]string{
+		"12:00:01 [kubernetes] node/node1: first",
+		"12:00:02 [autoscaling] nodes.example.com: second",
+		"12:00:03 [kubernetes] node/node2: third",
+		"",
+	}, "\n")
+	if out.String() != expected {
+		t.Fatalf("unexpected output; expected:\n%s\nactual:\n%s", expected, out.String())
+	}
+}
+
+func TestStreamStopPollsOnce(t *testing.T) {
+	source := &fakeSource{events: [][]*Event{{
+		{Time: time.Now(), Source: "fake", Object: "o", Message: "m"},
+	}}}
+
+	var out bytes.Buffer
+	s := &Stream{Sources: []Source{source}, Out: &out, Interval: time.Hour}
+	s.Start()
+	s.Stop()
+
+	if !strings.Contains(out.String(), "[fake] o: m") {
+		t.Fatalf("expected the final poll to write the pending event, got %q", out.String())
+	}
+
+	// Stopping again is a no-op
+	s.Stop()
+}
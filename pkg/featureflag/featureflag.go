@@ -44,6 +44,9 @@ var ExperimentalClusterDNS = New("ExperimentalClusterDNS", Bool(false))
 // KeepLaunchConfigurations can be set to prevent garbage collection of old launch configurations
 var KeepLaunchConfigurations = New("KeepLaunchConfigurations", Bool(false))
 
+// EnableLaunchTemplates uses EC2 launch templates rather than launch configurations for AWS autoscaling groups
+var EnableLaunchTemplates = New("EnableLaunchTemplates", Bool(false))
+
 // DNSPreCreate controls whether we pre-create DNS records.
 var DNSPreCreate = New("DNSPreCreate", Bool(true))
 
@@ -60,9 +63,6 @@ var VSphereCloudProvider = New("VSphereCloudProvider", Bool(false))
 
 var EnableExternalDNS = New("EnableExternalDNS", Bool(false))
 
-//EnableExternalCloudController toggles the use of cloud-controller-manager introduced in v1.7
-var EnableExternalCloudController = New("EnableExternalCloudController", Bool(false))
-
 // EnableSeparateConfigBase allows a config-base that is different from the state store
 var EnableSeparateConfigBase = New("EnableSeparateConfigBase", Bool(false))
 
@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebuilder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+const (
+	// DefaultAWSInstanceType is the instance type AMIs are built on, unless the spec sets one
+	DefaultAWSInstanceType = "t2.medium"
+)
+
+// AWSBuilder builds AMIs: it launches an instance of the base image with the build script as its user data,
+// waits for the script to stop the instance, and then creates an AMI of the stopped instance
+type AWSBuilder struct {
+	Cloud awsup.AWSCloud
+
+	// SubnetID is the subnet the build instance is launched in
+	SubnetID string
+	// PublicIP assigns the build instance a public IP, so that it reaches the internet from a public subnet
+	PublicIP bool
+
+	// Timeout is how long to wait for the build script to complete, and for the AMI to become available
+	Timeout time.Duration
+	// PollInterval is how often the state of the instance and the AMI is checked
+	PollInterval time.Duration
+}
+
+var _ Builder = &AWSBuilder{}
+
+// BuildImage implements Builder::BuildImage
+func (b *AWSBuilder) BuildImage(spec *ImageSpec, imageName string, script string) (string, error) {
+	base, err := b.Cloud.ResolveImage(spec.BaseImage)
+	if err != nil {
+		return "", fmt.Errorf("error resolving base image %q: %v", spec.BaseImage, err)
+	}
+	if base == nil {
+		return "", fmt.Errorf("base image %q not found", spec.BaseImage)
+	}
+
+	instanceType := spec.InstanceType
+	if instanceType == "" {
+		instanceType = DefaultAWSInstanceType
+	}
+
+	request := &ec2.RunInstancesInput{
+		ImageId:      base.ImageId,
+		InstanceType: aws.String(instanceType),
+		MinCount:     aws.Int64(1),
+		MaxCount:     aws.Int64(1),
+		// The build script powers off the instance once it completes
+		InstanceInitiatedShutdownBehavior: aws.String(ec2.ShutdownBehaviorStop),
+		UserData:                          aws.String(base64.StdEncoding.EncodeToString([]byte(script))),
+		NetworkInterfaces: []*ec2.InstanceNetworkInterfaceSpecification{
+			{
+				DeviceIndex:              aws.Int64(0),
+				SubnetId:                 aws.String(b.SubnetID),
+				AssociatePublicIpAddress: aws.Bool(b.PublicIP),
+				DeleteOnTermination:      aws.Bool(true),
+			},
+		},
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeInstance),
+				Tags: []*ec2.Tag{
+					{Key: aws.String("Name"), Value: aws.String("build-" + imageName)},
+				},
+			},
+		},
+	}
+
+	response, err := b.Cloud.EC2().RunInstances(request)
+	if err != nil {
+		return "", fmt.Errorf("error launching build instance: %v", err)
+	}
+	if len(response.Instances) == 0 {
+		return "", fmt.Errorf("no build instance was launched")
+	}
+	instanceID := aws.StringValue(response.Instances[0].InstanceId)
+	glog.Infof("Launched build instance %s from %s", instanceID, aws.StringValue(base.ImageId))
+	defer b.terminateInstance(instanceID)
+
+	err = wait.PollImmediate(b.PollInterval, b.Timeout, func() (bool, error) {
+		instance, err := b.findInstance(instanceID)
+		if err != nil || instance == nil {
+			// Newly launched instances may not be found for a short while
+			glog.V(2).Infof("unable to find build instance %s: %v", instanceID, err)
+			return false, nil
+		}
+
+		state := aws.StringValue(instance.State.Name)
+		switch state {
+		case ec2.InstanceStateNameStopped:
+			return true, nil
+		case ec2.InstanceStateNameShuttingDown, ec2.InstanceStateNameTerminated:
+			return false, fmt.Errorf("build instance %s was terminated", instanceID)
+		default:
+			glog.V(2).Infof("build instance %s is %s", instanceID, state)
+			return false, nil
+		}
+	})
+	if err == wait.ErrWaitTimeout {
+		return "", fmt.Errorf("build instance %s did not stop within %v; the build script has probably failed, see the console output of the instance", instanceID, b.Timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	created, err := b.Cloud.EC2().CreateImage(&ec2.CreateImageInput{
+		InstanceId:  aws.String(instanceID),
+		Name:        aws.String(imageName),
+		Description: aws.String("Built by kops from " + spec.BaseImage),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating image of build instance %s: %v", instanceID, err)
+	}
+	imageID := aws.StringValue(created.ImageId)
+	glog.Infof("Creating image %s", imageID)
+
+	if err := b.Cloud.CreateTags(imageID, map[string]string{"Name": imageName, "kops.k8s.io/base-image": spec.BaseImage}); err != nil {
+		glog.Warningf("unable to tag image %s: %v", imageID, err)
+	}
+
+	err = wait.PollImmediate(b.PollInterval, b.Timeout, func() (bool, error) {
+		response, err := b.Cloud.EC2().DescribeImages(&ec2.DescribeImagesInput{ImageIds: []*string{aws.String(imageID)}})
+		if err != nil || len(response.Images) == 0 {
+			glog.V(2).Infof("unable to find image %s: %v", imageID, err)
+			return false, nil
+		}
+
+		switch state := aws.StringValue(response.Images[0].State); state {
+		case ec2.ImageStateAvailable:
+			return true, nil
+		case ec2.ImageStateFailed, ec2.ImageStateError:
+			return false, fmt.Errorf("creating image %s failed: %s", imageID, state)
+		default:
+			return false, nil
+		}
+	})
+	if err == wait.ErrWaitTimeout {
+		return "", fmt.Errorf("image %s did not become available within %v", imageID, b.Timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return imageID, nil
+}
+
+func (b *AWSBuilder) findInstance(instanceID string) (*ec2.Instance, error) {
+	response, err := b.Cloud.EC2().DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(instanceID)}})
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range response.Reservations {
+		for _, instance := range reservation.Instances {
+			return instance, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *AWSBuilder) terminateInstance(instanceID string) {
+	glog.Infof("Terminating build instance %s", instanceID)
+	_, err := b.Cloud.EC2().TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: []*string{aws.String(instanceID)}})
+	if err != nil {
+		glog.Warningf("unable to terminate build instance %s, it must be terminated manually: %v", instanceID, err)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebuilder
+
+import (
+	"strings"
+)
+
+// Builder builds images on a cloud
+type Builder interface {
+	// BuildImage runs the script on an instance of the base image and registers an image of the instance with the name,
+	// returning the image in the format used by instance groups
+	BuildImage(spec *ImageSpec, imageName string, script string) (string, error)
+}
+
+// BuildScript returns the script run on the build instance as it boots.  The script powers the instance off once it
+// completes, which is the signal for the image to be registered; if it fails the instance keeps running and the
+// build times out.
+func BuildScript(spec *ImageSpec) string {
+	lines := []string{
+		"#!/bin/bash",
+		"# Built by kops toolbox build-image",
+		"set -o errexit",
+		"set -o nounset",
+		"set -o pipefail",
+		"",
+	}
+
+	if len(spec.Packages) != 0 {
+		packages := strings.Join(spec.Packages, " ")
+		lines = append(lines,
+			"if command -v apt-get >/dev/null; then",
+			"  export DEBIAN_FRONTEND=noninteractive",
+			"  apt-get update",
+			"  apt-get install --yes "+packages,
+			"elif command -v yum >/dev/null; then",
+			"  yum install -y "+packages,
+			"else",
+			"  echo \"cannot install packages: neither apt-get nor yum was found\"",
+			"  exit 1",
+			"fi",
+			"",
+		)
+	}
+
+	if len(spec.ContainerImages) != 0 {
+		lines = append(lines, "systemctl start docker")
+		for _, image := range spec.ContainerImages {
+			lines = append(lines, "docker pull "+shellQuote(image))
+		}
+		lines = append(lines, "")
+	}
+
+	if len(spec.Commands) != 0 {
+		lines = append(lines, spec.Commands...)
+		lines = append(lines, "")
+	}
+
+	lines = append(lines,
+		"# Clean up, so that instances launched from the image start afresh",
+		"if command -v apt-get >/dev/null; then apt-get clean; fi",
+		"if command -v yum >/dev/null; then yum clean all; fi",
+		"rm -rf /tmp/* /var/tmp/* /root/.bash_history",
+		"",
+		"shutdown -h now",
+	)
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// shellQuote quotes the value as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebuilder
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	compute "google.golang.org/api/compute/v0.beta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+)
+
+const (
+	// DefaultGCEMachineType is the machine type GCE images are built on, unless the spec sets one
+	DefaultGCEMachineType = "n1-standard-2"
+)
+
+// GCEBuilder builds GCE images: it creates an instance of the base image with the build script as its startup
+// script, waits for the script to power off the instance, and then creates an image of the boot disk
+type GCEBuilder struct {
+	Cloud gce.GCECloud
+
+	// Zone is the zone the build instance is created in
+	Zone string
+	// Network is the name of the network the build instance is attached to
+	Network string
+
+	// Timeout is how long to wait for the build script to complete
+	Timeout time.Duration
+	// PollInterval is how often the status of the instance is checked
+	PollInterval time.Duration
+}
+
+var _ Builder = &GCEBuilder{}
+
+// BuildImage implements Builder::BuildImage
+func (b *GCEBuilder) BuildImage(spec *ImageSpec, imageName string, script string) (string, error) {
+	project := b.Cloud.Project()
+	c := b.Cloud.Compute()
+
+	machineType := spec.InstanceType
+	if machineType == "" {
+		machineType = DefaultGCEMachineType
+	}
+
+	instanceName := "build-" + imageName
+	instance := &compute.Instance{
+		Name:        instanceName,
+		MachineType: gcetasks.BuildMachineTypeURL(project, b.Zone, machineType),
+		Disks: []*compute.AttachedDisk{
+			{
+				Boot:       true,
+				AutoDelete: true,
+				InitializeParams: &compute.AttachedDiskInitializeParams{
+					SourceImage: gcetasks.BuildImageURL(project, spec.BaseImage),
+				},
+			},
+		},
+		NetworkInterfaces: []*compute.NetworkInterface{
+			{
+				Network: fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/networks/%s", project, b.Network),
+				AccessConfigs: []*compute.AccessConfig{
+					{Name: "external-nat", Type: "ONE_TO_ONE_NAT"},
+				},
+			},
+		},
+		Metadata: &compute.Metadata{
+			Items: []*compute.MetadataItems{
+				{Key: "startup-script", Value: &script},
+			},
+		},
+	}
+
+	op, err := c.Instances.Insert(project, b.Zone, instance).Do()
+	if err != nil {
+		return "", fmt.Errorf("error creating build instance: %v", err)
+	}
+	if err := b.Cloud.WaitForOp(op); err != nil {
+		return "", fmt.Errorf("error creating build instance: %v", err)
+	}
+	glog.Infof("Created build instance %s from %s", instanceName, spec.BaseImage)
+	defer b.deleteInstance(instanceName)
+
+	var sourceDisk string
+	err = wait.PollImmediate(b.PollInterval, b.Timeout, func() (bool, error) {
+		i, err := c.Instances.Get(project, b.Zone, instanceName).Do()
+		if err != nil {
+			glog.V(2).Infof("unable to get build instance %s: %v", instanceName, err)
+			return false, nil
+		}
+		if i.Status != "TERMINATED" {
+			glog.V(2).Infof("build instance %s is %s", instanceName, i.Status)
+			return false, nil
+		}
+		if len(i.Disks) == 0 {
+			return false, fmt.Errorf("build instance %s has no boot disk", instanceName)
+		}
+		sourceDisk = i.Disks[0].Source
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return "", fmt.Errorf("build instance %s did not power off within %v; the build script has probably failed, see the serial port output of the instance", instanceName, b.Timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	image := &compute.Image{
+		Name:        imageName,
+		SourceDisk:  sourceDisk,
+		Description: "Built by kops from " + spec.BaseImage,
+	}
+	op, err = c.Images.Insert(project, image).Do()
+	if err != nil {
+		return "", fmt.Errorf("error creating image: %v", err)
+	}
+	if err := b.Cloud.WaitForOp(op); err != nil {
+		return "", fmt.Errorf("error creating image: %v", err)
+	}
+
+	return project + "/" + imageName, nil
+}
+
+func (b *GCEBuilder) deleteInstance(instanceName string) {
+	glog.Infof("Deleting build instance %s", instanceName)
+	op, err := b.Cloud.Compute().Instances.Delete(b.Cloud.Project(), b.Zone, instanceName).Do()
+	if err == nil {
+		err = b.Cloud.WaitForOp(op)
+	}
+	if err != nil {
+		glog.Warningf("unable to delete build instance %s, it must be deleted manually: %v", instanceName, err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebuilder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseImageSpec(t *testing.T) {
+	grid := []struct {
+		Spec          string
+		ExpectedError bool
+	}{
+		{
+			Spec: `
+name: hardened-nodes
+baseImage: kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-08-17
+packages:
+- auditd
+containerImages:
+- k8s.gcr.io/pause-amd64:3.1
+`,
+		},
+		{
+			Spec:          `baseImage: kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-08-17`,
+			ExpectedError: true,
+		},
+		{
+			Spec:          `name: Hardened_Nodes`,
+			ExpectedError: true,
+		},
+		{
+			Spec:          `name: hardened-nodes`,
+			ExpectedError: true,
+		},
+		{
+			Spec: `
+name: hardened-nodes
+baseImage: kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-08-17
+packages:
+- "auditd; rm -rf /"
+`,
+			ExpectedError: true,
+		},
+	}
+
+	for _, g := range grid {
+		spec, err := ParseImageSpec([]byte(g.Spec))
+		if g.ExpectedError {
+			if err == nil {
+				t.Errorf("expected error parsing %q", g.Spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", g.Spec, err)
+			continue
+		}
+		if spec.Name != "hardened-nodes" || len(spec.Packages) != 1 || len(spec.ContainerImages) != 1 {
+			t.Errorf("unexpected spec parsed from %q: %+v", g.Spec, spec)
+		}
+	}
+}
+
+func TestImageName(t *testing.T) {
+	spec := &ImageSpec{Name: "hardened-nodes"}
+	now := time.Date(2018, 10, 18, 15, 30, 0, 0, time.UTC)
+	if name := spec.ImageName(now); name != "hardened-nodes-20181018-153000" {
+		t.Errorf("unexpected image name %q", name)
+	}
+}
+
+func TestBuildScript(t *testing.T) {
+	spec := &ImageSpec{
+		Name:            "hardened-nodes",
+		BaseImage:       "kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-08-17",
+		Packages:        []string{"auditd", "sysstat"},
+		ContainerImages: []string{"k8s.gcr.io/pause-amd64:3.1"},
+		Commands:        []string{"echo 'hardened' > /etc/motd"},
+	}
+
+	expected := `#!/bin/bash
+# Built by kops toolbox build-image
+set -o errexit
+set -o nounset
+set -o pipefail
+
+if command -v apt-get >/dev/null; then
+  export DEBIAN_FRONTEND=noninteractive
+  apt-get update
+  apt-get install --yes auditd sysstat
+elif command -v yum >/dev/null; then
+  yum install -y auditd sysstat
+else
+  echo "cannot install packages: neither apt-get nor yum was found"
+  exit 1
+fi
+
+systemctl start docker
+docker pull 'k8s.gcr.io/pause-amd64:3.1'
+
+echo 'hardened' > /etc/motd
+
+# Clean up, so that instances launched from the image start afresh
+if command -v apt-get >/dev/null; then apt-get clean; fi
+if command -v yum >/dev/null; then yum clean all; fi
+rm -rf /tmp/* /var/tmp/* /root/.bash_history
+
+shutdown -h now
+`
+	if actual := BuildScript(spec); actual != expected {
+		t.Errorf("unexpected build script, got:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for s, expected := range map[string]string{
+		"k8s.gcr.io/pause-amd64:3.1": `'k8s.gcr.io/pause-amd64:3.1'`,
+		"it's":                       `'it'\''s'`,
+	} {
+		if actual := shellQuote(s); actual != expected {
+			t.Errorf("shellQuote(%q) = %q, expected %q", s, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebuilder
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"k8s.io/kops/upup/pkg/fi/utils"
+)
+
+// ImageSpec is the declarative specification of a node image, built from a base image
+type ImageSpec struct {
+	// Name is the prefix of the name of the built image; the time of the build is appended to it
+	Name string `json:"name"`
+	// BaseImage is the image the build starts from, in the format used by instance groups
+	BaseImage string `json:"baseImage"`
+	// InstanceType is the instance (or machine) type the image is built on
+	InstanceType string `json:"instanceType,omitempty"`
+	// Packages are the OS packages installed into the image
+	Packages []string `json:"packages,omitempty"`
+	// ContainerImages are pulled into the image with docker, so that pods using them start without pulling them
+	ContainerImages []string `json:"containerImages,omitempty"`
+	// Commands are shell commands run after the packages are installed and the container images pulled
+	Commands []string `json:"commands,omitempty"`
+}
+
+const (
+	// maxNameLength leaves room for the timestamp and the prefix of the build instance, within the 63 characters GCE allows
+	maxNameLength = 40
+)
+
+var (
+	validName    = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+	validPackage = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.+:=_-]*$`)
+)
+
+// ParseImageSpec parses and validates the YAML (or JSON) image specification
+func ParseImageSpec(data []byte) (*ImageSpec, error) {
+	spec := &ImageSpec{}
+	if err := utils.YamlUnmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("error parsing image spec: %v", err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// Validate checks that the image can be built from the specification
+func (s *ImageSpec) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(s.Name) > maxNameLength || !validName.MatchString(s.Name) {
+		return fmt.Errorf("invalid name %q: must be at most %d lowercase letters, digits and dashes, starting with a letter", s.Name, maxNameLength)
+	}
+
+	if s.BaseImage == "" {
+		return fmt.Errorf("baseImage is required")
+	}
+
+	for _, p := range s.Packages {
+		if !validPackage.MatchString(p) {
+			return fmt.Errorf("invalid package name %q", p)
+		}
+	}
+
+	for _, image := range s.ContainerImages {
+		if image == "" {
+			return fmt.Errorf("containerImages must not contain empty names")
+		}
+	}
+
+	return nil
+}
+
+// ImageName returns the name of the image built from the specification at the specified time
+func (s *ImageSpec) ImageName(now time.Time) string {
+	return s.Name + "-" + now.UTC().Format("20060102-150405")
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/kubernetes"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+// DefaultAPIProbeInterval is the interval between apiserver probes, if APIProbeInterval is not set
+const DefaultAPIProbeInterval = 5 * time.Second
+
+// apiServerHealthz probes the apiserver's /healthz endpoint, through the same endpoint (typically the load balancer)
+// as the rest of the rolling update; tests replace it
+var apiServerHealthz = func(client kubernetes.Interface) error {
+	data, err := client.Discovery().RESTClient().Get().AbsPath("/healthz").DoRaw()
+	if err != nil {
+		return err
+	}
+	if body := strings.TrimSpace(string(data)); body != "ok" {
+		return fmt.Errorf("apiserver reported %q", body)
+	}
+	return nil
+}
+
+// servesAPI returns true if the instances of the group run the apiserver
+func servesAPI(ig *api.InstanceGroup) bool {
+	return ig.Spec.Role == api.InstanceGroupRoleMaster || ig.Spec.Role == api.InstanceGroupRoleAPIServer
+}
+
+// probeAPIServer waits, after replacing an instance running the apiserver, until the apiserver has answered
+// APIProbeSuccesses consecutive probes, or returns an error if that doesn't happen within timeout
+func (r *RollingUpdateInstanceGroup) probeAPIServer(rollingUpdateData *RollingUpdateCluster, timeout time.Duration) error {
+	if rollingUpdateData.APIProbeSuccesses <= 0 || !servesAPI(r.CloudGroup.InstanceGroup) {
+		return nil
+	}
+
+	interval := rollingUpdateData.APIProbeInterval
+	if interval <= 0 {
+		interval = DefaultAPIProbeInterval
+	}
+	return waitForAPIServerStreak(rollingUpdateData.K8sClient, rollingUpdateData.APIProbeSuccesses, interval, timeout)
+}
+
+// waitForAPIServerStreak probes the apiserver every interval until it succeeds successes times in a row
+func waitForAPIServerStreak(client kubernetes.Interface, successes int, interval time.Duration, timeout time.Duration) error {
+	glog.Infof("Waiting for the apiserver to answer %d consecutive probes", successes)
+
+	deadline := time.Now().Add(timeout)
+	streak := 0
+	failures := 0
+	for {
+		if err := apiServerHealthz(client); err != nil {
+			if streak != 0 {
+				glog.Warningf("apiserver probe failed after %d successful probes: %v", streak, err)
+			} else {
+				glog.V(2).Infof("apiserver probe failed: %v", err)
+			}
+			streak = 0
+			failures++
+		} else {
+			streak++
+			if streak >= successes {
+				glog.Infof("apiserver answered %d consecutive probes (%d failed probes)", streak, failures)
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("apiserver did not answer %d consecutive probes within %s (%d probes failed)", successes, timeout, failures)
+		}
+		time.Sleep(interval)
+	}
+}
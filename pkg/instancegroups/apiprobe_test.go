@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWaitForAPIServerStreak(t *testing.T) {
+	defer func(f func(kubernetes.Interface) error) { apiServerHealthz = f }(apiServerHealthz)
+
+	grid := []struct {
+		name      string
+		results   []bool
+		successes int
+		expectErr bool
+		probes    int
+	}{
+		{
+			name:      "healthy",
+			results:   []bool{true, true, true},
+			successes: 3,
+			probes:    3,
+		},
+		{
+			name:      "streak restarts after a failure",
+			results:   []bool{true, true, false, true, true, true},
+			successes: 3,
+			probes:    6,
+		},
+		{
+			name:      "never reaches the streak",
+			results:   []bool{false},
+			successes: 2,
+			expectErr: true,
+		},
+	}
+
+	for _, g := range grid {
+		probes := 0
+		apiServerHealthz = func(client kubernetes.Interface) error {
+			i := probes
+			probes++
+			if i >= len(g.results) {
+				i = len(g.results) - 1
+			}
+			if g.results[i] {
+				return nil
+			}
+			return fmt.Errorf("connection refused")
+		}
+
+		err := waitForAPIServerStreak(fake.NewSimpleClientset(), g.successes, time.Millisecond, 50*time.Millisecond)
+		if g.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", g.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", g.name, err)
+		}
+		if probes != g.probes {
+			t.Errorf("%s: expected %d probes, got %d", g.name, g.probes, probes)
+		}
+	}
+}
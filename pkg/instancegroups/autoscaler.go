@@ -0,0 +1,162 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+const (
+	// ScaleDownDisabledAnnotation stops cluster-autoscaler from removing a node
+	ScaleDownDisabledAnnotation = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+
+	clusterAutoscalerName = "cluster-autoscaler"
+)
+
+// GroupMinSizeSetter is implemented by clouds that can change the minimum size of a cloud group
+type GroupMinSizeSetter interface {
+	SetGroupMinSize(group *cloudinstances.CloudInstanceGroup, minSize int) error
+}
+
+// detectClusterAutoscaler returns true if cluster-autoscaler is deployed in the kube-system namespace
+func detectClusterAutoscaler(k8sClient kubernetes.Interface) (bool, error) {
+	deployments, err := k8sClient.AppsV1().Deployments(metav1.NamespaceSystem).List(metav1.ListOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error listing deployments: %v", err)
+	}
+
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if d.Name == clusterAutoscalerName || d.Labels["app"] == clusterAutoscalerName || d.Labels["k8s-app"] == clusterAutoscalerName {
+			glog.V(2).Infof("found cluster-autoscaler deployment %q", d.Name)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// protectNodes marks the nodes of the instance group that are not going to be replaced, so that
+// cluster-autoscaler does not scale them down while the pods from the replaced nodes move onto them.
+func (c *RollingUpdateCluster) protectNodes(group *cloudinstances.CloudInstanceGroup, update []*cloudinstances.CloudInstanceGroupMember) error {
+	replacing := make(map[string]bool)
+	for _, u := range update {
+		if u.Node != nil {
+			replacing[u.Node.Name] = true
+		}
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{api.NodeLabelInstanceGroup: group.InstanceGroup.ObjectMeta.Name})
+	nodes, err := c.K8sClient.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	c.protectedNodesMutex.Lock()
+	defer c.protectedNodesMutex.Unlock()
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if replacing[node.Name] {
+			continue
+		}
+		if _, found := node.Annotations[ScaleDownDisabledAnnotation]; found {
+			// Either we already set it, or someone else did and will remove it
+			continue
+		}
+
+		glog.V(2).Infof("disabling cluster-autoscaler scale-down of node %q", node.Name)
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[ScaleDownDisabledAnnotation] = "true"
+		if _, err := c.K8sClient.CoreV1().Nodes().Update(node); err != nil {
+			return fmt.Errorf("error annotating node %q: %v", node.Name, err)
+		}
+		c.protectedNodes = append(c.protectedNodes, node.Name)
+	}
+
+	return nil
+}
+
+// unprotectNodes removes the annotations set by protectNodes
+func (c *RollingUpdateCluster) unprotectNodes() {
+	c.protectedNodesMutex.Lock()
+	defer c.protectedNodesMutex.Unlock()
+
+	for _, name := range c.protectedNodes {
+		node, err := c.K8sClient.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				glog.Warningf("error reading node %q to re-enable cluster-autoscaler scale-down: %v", name, err)
+			}
+			continue
+		}
+		if _, found := node.Annotations[ScaleDownDisabledAnnotation]; !found {
+			continue
+		}
+
+		glog.V(2).Infof("re-enabling cluster-autoscaler scale-down of node %q", name)
+		delete(node.Annotations, ScaleDownDisabledAnnotation)
+		if _, err := c.K8sClient.CoreV1().Nodes().Update(node); err != nil {
+			glog.Warningf("error re-enabling cluster-autoscaler scale-down of node %q: %v", name, err)
+		}
+	}
+	c.protectedNodes = nil
+}
+
+// raiseMinSize raises the minimum size of the cloud group to its current size, so that cluster-autoscaler
+// cannot shrink the group while we are replacing its instances.  The returned function restores the original minimum size.
+func (r *RollingUpdateInstanceGroup) raiseMinSize() (func(), error) {
+	noop := func() {}
+
+	if r.CloudGroup.InstanceGroup.IsDirectlyManaged() {
+		// Not an autoscaling group, so cluster-autoscaler does not manage it
+		return noop, nil
+	}
+
+	setter, ok := r.Cloud.(GroupMinSizeSetter)
+	if !ok {
+		glog.V(2).Infof("cloud does not support changing group sizes, not adjusting the minimum size of %q", r.CloudGroup.HumanName)
+		return noop, nil
+	}
+
+	originalMinSize := r.CloudGroup.MinSize
+	currentSize := len(r.CloudGroup.Ready) + len(r.CloudGroup.NeedUpdate)
+	if currentSize <= originalMinSize {
+		return noop, nil
+	}
+
+	glog.Infof("Raising the minimum size of %q from %d to %d during the rolling update", r.CloudGroup.HumanName, originalMinSize, currentSize)
+	if err := setter.SetGroupMinSize(r.CloudGroup, currentSize); err != nil {
+		return noop, fmt.Errorf("error raising the minimum size of %q: %v", r.CloudGroup.HumanName, err)
+	}
+
+	return func() {
+		glog.Infof("Restoring the minimum size of %q to %d", r.CloudGroup.HumanName, originalMinSize)
+		if err := setter.SetGroupMinSize(r.CloudGroup, originalMinSize); err != nil {
+			glog.Warningf("error restoring the minimum size of %q to %d: %v", r.CloudGroup.HumanName, originalMinSize, err)
+		}
+	}, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+// cloudControllerManagerName is the name of the DaemonSet of the external cloud-controller-manager addon
+const cloudControllerManagerName = "cloud-controller-manager"
+
+// cloudControllerManagerTickDuration is the interval between checks of the cloud-controller-manager
+var cloudControllerManagerTickDuration = 10 * time.Second
+
+// cloudControllerManagerReady returns "" if the external cloud-controller-manager is up to date and ready on every
+// master, otherwise the reason it is not
+func cloudControllerManagerReady(k8sClient kubernetes.Interface) (string, error) {
+	ds, err := k8sClient.AppsV1().DaemonSets(metav1.NamespaceSystem).Get(cloudControllerManagerName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "the cloud-controller-manager addon has not been applied yet", nil
+		}
+		return "", fmt.Errorf("error getting DaemonSet %q: %v", cloudControllerManagerName, err)
+	}
+
+	status := ds.Status
+	if status.ObservedGeneration < ds.Generation {
+		return "the DaemonSet has not been observed yet", nil
+	}
+	if status.DesiredNumberScheduled == 0 {
+		return "no pods are scheduled", nil
+	}
+	if status.UpdatedNumberScheduled < status.DesiredNumberScheduled || status.NumberReady < status.DesiredNumberScheduled {
+		return fmt.Sprintf("%d of %d pods are up to date, %d are ready", status.UpdatedNumberScheduled, status.DesiredNumberScheduled, status.NumberReady), nil
+	}
+	return "", nil
+}
+
+// waitForCloudControllerManager waits, once the masters have been updated, until the external cloud-controller-manager
+// runs on them. Nodes replaced after moving the cluster to an external cloud-controller-manager run kubelet with
+// --cloud-provider=external, and stay tainted as uninitialized until the cloud-controller-manager initializes them.
+func (c *RollingUpdateCluster) waitForCloudControllerManager(cluster *api.Cluster, timeout time.Duration) error {
+	if cluster.Spec.ExternalCloudControllerManager == nil || c.CloudOnly || c.K8sClient == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		reason, err := cloudControllerManagerReady(c.K8sClient)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cloud-controller-manager was not ready within %s: %s", timeout, reason)
+		}
+
+		glog.Infof("Waiting for the cloud-controller-manager before updating nodes: %s", reason)
+		time.Sleep(cloudControllerManagerTickDuration)
+	}
+}
+
+// nodesNeedUpdate is true if the rolling update is going to replace instances of the node groups
+func (c *RollingUpdateCluster) nodesNeedUpdate(nodeGroups map[string]*cloudinstances.CloudInstanceGroup) bool {
+	for _, group := range nodeGroups {
+		if len(group.NeedUpdate) != 0 || (c.Force && len(group.Ready) != 0) {
+			return true
+		}
+	}
+	return false
+}
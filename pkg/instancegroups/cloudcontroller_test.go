@@ -0,0 +1,124 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+func testCloudControllerManagerDaemonSet(desired, updated, ready int32) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: cloudControllerManagerName, Namespace: metav1.NamespaceSystem, Generation: 2},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     2,
+			DesiredNumberScheduled: desired,
+			UpdatedNumberScheduled: updated,
+			NumberReady:            ready,
+		},
+	}
+}
+
+func TestCloudControllerManagerReady(t *testing.T) {
+	grid := []struct {
+		DaemonSet *appsv1.DaemonSet
+		Ready     bool
+	}{
+		{
+			DaemonSet: nil,
+			Ready:     false,
+		},
+		{
+			DaemonSet: testCloudControllerManagerDaemonSet(0, 0, 0),
+			Ready:     false,
+		},
+		{
+			DaemonSet: testCloudControllerManagerDaemonSet(3, 2, 3),
+			Ready:     false,
+		},
+		{
+			DaemonSet: testCloudControllerManagerDaemonSet(3, 3, 2),
+			Ready:     false,
+		},
+		{
+			DaemonSet: testCloudControllerManagerDaemonSet(3, 3, 3),
+			Ready:     true,
+		},
+	}
+	for i, g := range grid {
+		k8sClient := fake.NewSimpleClientset()
+		if g.DaemonSet != nil {
+			k8sClient = fake.NewSimpleClientset(g.DaemonSet)
+		}
+
+		reason, err := cloudControllerManagerReady(k8sClient)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if (reason == "") != g.Ready {
+			t.Errorf("case %d: expected ready=%v, got reason %q", i, g.Ready, reason)
+		}
+	}
+}
+
+func TestWaitForCloudControllerManager(t *testing.T) {
+	cloudControllerManagerTickDuration = time.Millisecond
+
+	cluster := &api.Cluster{}
+	c := &RollingUpdateCluster{K8sClient: fake.NewSimpleClientset()}
+
+	// Without an external cloud-controller-manager there is nothing to wait for
+	if err := c.waitForCloudControllerManager(cluster, time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cluster.Spec.ExternalCloudControllerManager = &api.CloudControllerManagerConfig{}
+	if err := c.waitForCloudControllerManager(cluster, 5*time.Millisecond); err == nil {
+		t.Fatalf("expected an error when the cloud-controller-manager is not deployed")
+	}
+
+	c.K8sClient = fake.NewSimpleClientset(testCloudControllerManagerDaemonSet(1, 1, 1))
+	if err := c.waitForCloudControllerManager(cluster, 5*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNodesNeedUpdate(t *testing.T) {
+	member := &cloudinstances.CloudInstanceGroupMember{ID: "i-1"}
+	upToDate := map[string]*cloudinstances.CloudInstanceGroup{"nodes": {Ready: []*cloudinstances.CloudInstanceGroupMember{member}}}
+	stale := map[string]*cloudinstances.CloudInstanceGroup{"nodes": {NeedUpdate: []*cloudinstances.CloudInstanceGroupMember{member}}}
+
+	c := &RollingUpdateCluster{}
+	if c.nodesNeedUpdate(upToDate) {
+		t.Errorf("expected up to date nodes not to need an update")
+	}
+	if !c.nodesNeedUpdate(stale) {
+		t.Errorf("expected stale nodes to need an update")
+	}
+
+	c.Force = true
+	if !c.nodesNeedUpdate(upToDate) {
+		t.Errorf("expected nodes to be updated with force")
+	}
+}
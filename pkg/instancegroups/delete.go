@@ -18,10 +18,16 @@ package instancegroups
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/cloudinstances"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -30,12 +36,36 @@ type DeleteInstanceGroup struct {
 	Cluster   *api.Cluster
 	Cloud     fi.Cloud
 	Clientset simple.Clientset
+
+	// K8sClient and ClientConfig are used to drain the nodes of the group; they are not needed when CloudOnly is set
+	K8sClient    kubernetes.Interface
+	ClientConfig clientcmd.ClientConfig
+
+	// CloudOnly deletes the cloud resources without draining the nodes first
+	CloudOnly bool
+
+	// DrainTimeout is the maximum time to wait for each node to drain; zero means no limit
+	DrainTimeout time.Duration
+
+	// RescheduleTimeout is the maximum time to wait for evicted pods to be scheduled elsewhere; zero does not wait
+	RescheduleTimeout time.Duration
 }
 
 // DeleteInstanceGroup deletes a cloud instance group
 func (d *DeleteInstanceGroup) DeleteInstanceGroup(group *api.InstanceGroup) error {
+	var nodes []v1.Node
+	if !d.CloudOnly {
+		if d.K8sClient == nil {
+			return fmt.Errorf("K8sClient is required to drain nodes")
+		}
+		nodeList, err := d.K8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing nodes in cluster: %v", err)
+		}
+		nodes = nodeList.Items
+	}
 
-	groups, err := d.Cloud.GetCloudGroups(d.Cluster, []*api.InstanceGroup{group}, false, nil)
+	groups, err := d.Cloud.GetCloudGroups(d.Cluster, []*api.InstanceGroup{group}, false, nodes)
 	if err != nil {
 		return fmt.Errorf("error finding CloudInstanceGroups: %v", err)
 	}
@@ -46,7 +76,56 @@ func (d *DeleteInstanceGroup) DeleteInstanceGroup(group *api.InstanceGroup) erro
 		}
 	}
 
-	// TODO should we drain nodes and validate the cluster?
+	if d.CloudOnly {
+		glog.Warningf("Not draining nodes of InstanceGroup %q as 'cloudonly' flag is set.", group.ObjectMeta.Name)
+	} else {
+		var nodeNames []string
+		for _, g := range groups {
+			var members []*cloudinstances.CloudInstanceGroupMember
+			members = append(members, g.Ready...)
+			members = append(members, g.NeedUpdate...)
+
+			for _, member := range members {
+				if member.Node == nil {
+					glog.Warningf("Skipping drain of instance %q, because it is not registered in kubernetes", member.ID)
+					continue
+				}
+				nodeNames = append(nodeNames, member.Node.Name)
+			}
+		}
+
+		// Cordon every node first, so that pods evicted from one node are not scheduled onto another node of the group
+		for _, nodeName := range nodeNames {
+			glog.Infof("Cordoning node %q", nodeName)
+			if err := cordonNode(d.K8sClient, nodeName); err != nil {
+				return err
+			}
+		}
+
+		var reschedule *rescheduleCheck
+		if len(nodeNames) != 0 && d.RescheduleTimeout > 0 {
+			var err error
+			reschedule, err = newNodesRescheduleCheck(d.K8sClient, nodeNames, fmt.Sprintf("instance group %q", group.ObjectMeta.Name), false)
+			if err != nil {
+				return err
+			}
+			reschedule.scheduledOnly = true
+		}
+
+		for _, nodeName := range nodeNames {
+			glog.Infof("Draining node %q", nodeName)
+			if err := drainNode(d.ClientConfig, nodeName, drainSettings{Timeout: d.DrainTimeout}); err != nil {
+				return fmt.Errorf("failed to drain node %q: %v", nodeName, err)
+			}
+		}
+
+		if reschedule != nil {
+			if err := reschedule.wait(d.K8sClient, d.RescheduleTimeout); err != nil {
+				return err
+			}
+		}
+	}
+
 	for _, g := range groups {
 		glog.Infof("Deleting %q", group.ObjectMeta.Name)
 
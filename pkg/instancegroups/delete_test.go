@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeleteRescheduleCheck(t *testing.T) {
+	rescheduleTickDuration = time.Millisecond
+
+	k8sClient := fake.NewSimpleClientset(
+		testReschedulePod("web-0", "node1", "ReplicaSet", "rs-web", true),
+		testReschedulePod("web-1", "node2", "ReplicaSet", "rs-web", true),
+		testReschedulePod("api-0", "node3", "ReplicaSet", "rs-api", true),
+		testReschedulePod("batch-0", "", "Job", "job-batch", false),
+	)
+
+	c, err := newNodesRescheduleCheck(k8sClient, []string{"node1", "node2"}, `instance group "nodes"`, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.scheduledOnly = true
+	if !reflect.DeepEqual(c.expected, map[types.UID]int{"rs-web": 2}) {
+		t.Fatalf("unexpected expected scheduled pods: %v", c.expected)
+	}
+
+	// The drain evicts both web pods; one replacement is still pending
+	for _, name := range []string{"web-0", "web-1"} {
+		if err := k8sClient.CoreV1().Pods("default").Delete(name, nil); err != nil {
+			t.Fatalf("error deleting pod: %v", err)
+		}
+	}
+	for _, pod := range []string{"web-2", "web-3"} {
+		nodeName := "node3"
+		if pod == "web-3" {
+			nodeName = ""
+		}
+		if _, err := k8sClient.CoreV1().Pods("default").Create(testReschedulePod(pod, nodeName, "ReplicaSet", "rs-web", false)); err != nil {
+			t.Fatalf("error creating pod: %v", err)
+		}
+	}
+
+	err = c.wait(k8sClient, 5*time.Millisecond)
+	if err == nil {
+		t.Fatalf("expected error waiting for a pending replacement")
+	}
+	expected := `pods evicted from instance group "nodes" were not rescheduled within 5ms: replicaset/default/web (1/2 scheduled)`
+	if err.Error() != expected {
+		t.Fatalf("unexpected error %q, expected %q", err.Error(), expected)
+	}
+
+	// Pods which were not evicted from the group do not hold up the deletion, even if they are pending
+	web3 := testReschedulePod("web-3", "node4", "ReplicaSet", "rs-web", false)
+	if _, err := k8sClient.CoreV1().Pods("default").Update(web3); err != nil {
+		t.Fatalf("error updating pod: %v", err)
+	}
+	if err := c.wait(k8sClient, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// mirrorPodAnnotation marks the API representation of a static pod, which can't be evicted
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// drainSettings controls how a node is drained
+type drainSettings struct {
+	// Timeout is the maximum time to wait for the node to drain; zero means no limit
+	Timeout time.Duration
+	// GracePeriod overrides the termination grace period of evicted pods; zero uses each pod's own grace period
+	GracePeriod time.Duration
+	// SkipPodsWithLocalStorage leaves pods with emptyDir volumes running, instead of evicting them and losing their data
+	SkipPodsWithLocalStorage bool
+	// Out is where the progress of the drain is written; stdout and stderr if nil
+	Out io.Writer
+}
+
+// gracePeriodSeconds returns the grace period in the form expected by kubectl drain, where -1 uses each pod's own grace period
+func (s drainSettings) gracePeriodSeconds() int {
+	if s.GracePeriod <= 0 {
+		return -1
+	}
+	seconds := int(s.GracePeriod / time.Second)
+	if seconds < 1 {
+		// A grace period of zero deletes the pod immediately
+		seconds = 1
+	}
+	return seconds
+}
+
+// podsToEvict splits the pods of a node into those that should be evicted and those with local storage that should
+// be left running; mirror pods, daemonset pods and finished pods are neither.
+func podsToEvict(pods []corev1.Pod, skipPodsWithLocalStorage bool) (evict []corev1.Pod, skipped []corev1.Pod) {
+	for _, pod := range pods {
+		if _, found := pod.Annotations[mirrorPodAnnotation]; found {
+			continue
+		}
+		if controller := metav1.GetControllerOf(&pod); controller != nil && controller.Kind == "DaemonSet" {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if skipPodsWithLocalStorage && hasLocalStorage(&pod) {
+			skipped = append(skipped, pod)
+			continue
+		}
+		evict = append(evict, pod)
+	}
+	return evict, skipped
+}
+
+// hasLocalStorage is true if the pod has an emptyDir volume, whose data is lost when the pod is evicted
+func hasLocalStorage(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// evictPodsSkippingLocalStorage evicts the pods of a cordoned node, except those with local storage, and waits for
+// them to terminate.  kubectl drain can only delete pods with local storage or refuse to drain the node.
+// If evict is nil, pods are evicted through the eviction API.
+func evictPodsSkippingLocalStorage(client kubernetes.Interface, nodeName string, settings drainSettings, evict func(eviction *policy.Eviction) error, pollInterval time.Duration) error {
+	if evict == nil {
+		evict = func(eviction *policy.Eviction) error {
+			return client.PolicyV1beta1().Evictions(eviction.Namespace).Evict(eviction)
+		}
+	}
+
+	var deadline time.Time
+	if settings.Timeout != 0 {
+		deadline = time.Now().Add(settings.Timeout)
+	}
+
+	options := metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": nodeName}).String(),
+	}
+	podList, err := client.CoreV1().Pods("").List(options)
+	if err != nil {
+		return fmt.Errorf("error listing pods on node %q: %v", nodeName, err)
+	}
+
+	pods, skipped := podsToEvict(podList.Items, true)
+	for _, pod := range skipped {
+		glog.Infof("Not evicting pod %s/%s, as it has local storage", pod.Namespace, pod.Name)
+	}
+
+	var deleteOptions *metav1.DeleteOptions
+	if settings.GracePeriod > 0 {
+		gracePeriodSeconds := int64(settings.gracePeriodSeconds())
+		deleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds}
+	}
+
+	for _, pod := range pods {
+		eviction := &policy.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+			},
+			DeleteOptions: deleteOptions,
+		}
+
+		for {
+			err := evict(eviction)
+			if err == nil || apierrors.IsNotFound(err) {
+				break
+			}
+			if !apierrors.IsTooManyRequests(err) {
+				return fmt.Errorf("error evicting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+
+			// The eviction would violate a PodDisruptionBudget; wait for other pods to become ready
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return fmt.Errorf("timed out evicting pod %s/%s after %s: %v", pod.Namespace, pod.Name, settings.Timeout, err)
+			}
+			glog.V(2).Infof("waiting to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			time.Sleep(pollInterval)
+		}
+		glog.Infof("Evicted pod %s/%s", pod.Namespace, pod.Name)
+	}
+
+	for _, pod := range pods {
+		for {
+			current, err := client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("error getting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return fmt.Errorf("timed out waiting for pod %s/%s to terminate after %s", pod.Namespace, pod.Name, settings.Timeout)
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+
+	return nil
+}
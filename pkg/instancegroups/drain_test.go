@@ -0,0 +1,142 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testDrainPod(name string, mutate func(pod *corev1.Pod)) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if mutate != nil {
+		mutate(pod)
+	}
+	return pod
+}
+
+func withEmptyDir(pod *corev1.Pod) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name:         "scratch",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+}
+
+func podNames(pods []corev1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPodsToEvict(t *testing.T) {
+	isController := true
+	pods := []corev1.Pod{
+		*testDrainPod("web", nil),
+		*testDrainPod("cache", withEmptyDir),
+		*testDrainPod("mirror", func(pod *corev1.Pod) {
+			pod.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+		}),
+		*testDrainPod("daemon", func(pod *corev1.Pod) {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "fluentd", Controller: &isController}}
+		}),
+		*testDrainPod("job", func(pod *corev1.Pod) {
+			pod.Status.Phase = corev1.PodSucceeded
+		}),
+	}
+
+	evict, skipped := podsToEvict(pods, false)
+	if !reflect.DeepEqual(podNames(evict), []string{"cache", "web"}) || len(skipped) != 0 {
+		t.Errorf("unexpected pods without skipping local storage: evict %v, skipped %v", podNames(evict), podNames(skipped))
+	}
+
+	evict, skipped = podsToEvict(pods, true)
+	if !reflect.DeepEqual(podNames(evict), []string{"web"}) || !reflect.DeepEqual(podNames(skipped), []string{"cache"}) {
+		t.Errorf("unexpected pods skipping local storage: evict %v, skipped %v", podNames(evict), podNames(skipped))
+	}
+}
+
+func TestDrainSettingsGracePeriodSeconds(t *testing.T) {
+	grid := map[time.Duration]int{
+		0:                      -1,
+		500 * time.Millisecond: 1,
+		30 * time.Second:       30,
+		10 * time.Minute:       600,
+	}
+	for gracePeriod, expected := range grid {
+		actual := drainSettings{GracePeriod: gracePeriod}.gracePeriodSeconds()
+		if actual != expected {
+			t.Errorf("unexpected grace period seconds for %v: %d, expected %d", gracePeriod, actual, expected)
+		}
+	}
+}
+
+func TestEvictPodsSkippingLocalStorage(t *testing.T) {
+	client := fake.NewSimpleClientset(testDrainPod("web", nil), testDrainPod("cache", withEmptyDir))
+
+	var evicted []string
+	var gracePeriods []int64
+	evict := func(eviction *policy.Eviction) error {
+		evicted = append(evicted, eviction.Name)
+		if eviction.DeleteOptions != nil && eviction.DeleteOptions.GracePeriodSeconds != nil {
+			gracePeriods = append(gracePeriods, *eviction.DeleteOptions.GracePeriodSeconds)
+		}
+		return nil
+	}
+	// Evicted pods are gone
+	client.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.GetAction).GetName()
+		for _, e := range evicted {
+			if e == name {
+				return true, nil, apierrors.NewNotFound(corev1.Resource("pods"), name)
+			}
+		}
+		return false, nil, nil
+	})
+
+	settings := drainSettings{Timeout: time.Minute, GracePeriod: 45 * time.Second, SkipPodsWithLocalStorage: true}
+	if err := evictPodsSkippingLocalStorage(client, "node1", settings, evict, time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(evicted, []string{"web"}) {
+		t.Errorf("unexpected evicted pods: %v", evicted)
+	}
+	if !reflect.DeepEqual(gracePeriods, []int64{45}) {
+		t.Errorf("unexpected grace periods: %v", gracePeriods)
+	}
+	if _, err := client.CoreV1().Pods("default").Get("cache", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the pod with local storage to be left running: %v", err)
+	}
+}
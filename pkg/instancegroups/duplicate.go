@@ -0,0 +1,250 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/featureflag"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// duplicateTickDuration is the interval at which we check whether the nodes of a duplicate group are ready
+var duplicateTickDuration = 10 * time.Second
+
+// duplicateGroupName returns the name of the group which replaces the named group with the duplicate strategy.
+// Groups alternate between a -blue and a -green suffix, so that repeated rolling updates don't grow the name.
+func duplicateGroupName(name string) string {
+	switch {
+	case strings.HasSuffix(name, "-green"):
+		return strings.TrimSuffix(name, "-green") + "-blue"
+	case strings.HasSuffix(name, "-blue"):
+		return strings.TrimSuffix(name, "-blue") + "-green"
+	default:
+		return name + "-green"
+	}
+}
+
+// duplicateRollingUpdate replaces a node group with a new group of the same spec: the new group is created and its
+// nodes must be ready before the nodes of the old group are cordoned and drained, and the old group is then deleted.
+// The instances of the old group are never changed in place.
+func (r *RollingUpdateInstanceGroup) duplicateRollingUpdate(rollingUpdateData *RollingUpdateCluster, cluster *api.Cluster, instanceGroupList *api.InstanceGroupList, validationTimeout time.Duration) error {
+	if rollingUpdateData.Clientset == nil || rollingUpdateData.ApplyCluster == nil {
+		return fmt.Errorf("the %q rolling-update strategy requires a clientset and ApplyCluster", RollingUpdateStrategyDuplicate)
+	}
+	if rollingUpdateData.K8sClient == nil {
+		return fmt.Errorf("the %q rolling-update strategy requires a k8s client", RollingUpdateStrategyDuplicate)
+	}
+
+	// The group may point into instanceGroupList, which we change below
+	old := r.CloudGroup.InstanceGroup.DeepCopy()
+	name := duplicateGroupName(old.ObjectMeta.Name)
+	for i := range instanceGroupList.Items {
+		if instanceGroupList.Items[i].ObjectMeta.Name == name {
+			return fmt.Errorf("cannot duplicate instance group %q: instance group %q already exists; delete it, or finish the previous rolling update", old.ObjectMeta.Name, name)
+		}
+	}
+
+	ig := &api.InstanceGroup{}
+	ig.ObjectMeta.Name = name
+	for k, v := range old.ObjectMeta.Labels {
+		if ig.ObjectMeta.Labels == nil {
+			ig.ObjectMeta.Labels = make(map[string]string)
+		}
+		ig.ObjectMeta.Labels[k] = v
+	}
+	old.Spec.DeepCopyInto(&ig.Spec)
+	ig.AddInstanceGroupNodeLabel()
+
+	glog.Infof("Creating instance group %q to replace %q", name, old.ObjectMeta.Name)
+	created, err := rollingUpdateData.Clientset.InstanceGroupsFor(cluster).Create(ig)
+	if err != nil {
+		return fmt.Errorf("error creating instance group %q: %v", name, err)
+	}
+	rollingUpdateData.record(cluster.ObjectMeta.Name, audit.OperationCreate, "instancegroup/"+name, nil, created)
+
+	instanceGroupList.Items = append(instanceGroupList.Items, *created)
+	var instanceGroups []*api.InstanceGroup
+	for i := range instanceGroupList.Items {
+		instanceGroups = append(instanceGroups, &instanceGroupList.Items[i])
+	}
+	if err := rollingUpdateData.ApplyCluster(cluster, instanceGroups); err != nil {
+		return fmt.Errorf("error creating cloud resources for instance group %q: %v", name, err)
+	}
+
+	target := r.CloudGroup.MinSize
+	if created.Spec.MinSize != nil {
+		target = int(fi.Int32Value(created.Spec.MinSize))
+	}
+	if current := len(r.CloudGroup.Ready) + len(r.CloudGroup.NeedUpdate); current > target {
+		glog.Warningf("Instance group %q has %d instances, but %q starts with %d; the remaining pods rely on the cluster being able to scale up", old.ObjectMeta.Name, current, name, target)
+	}
+	if err := waitForGroupNodesReady(rollingUpdateData, name, target, validationTimeout); err != nil {
+		return fmt.Errorf("%v; instance group %q has not been changed, and %q can be deleted with kops delete instancegroup", err, old.ObjectMeta.Name, name)
+	}
+
+	var members []*cloudinstances.CloudInstanceGroupMember
+	members = append(members, r.CloudGroup.Ready...)
+	members = append(members, r.CloudGroup.NeedUpdate...)
+
+	if featureflag.DrainAndValidateRollingUpdate.Enabled() {
+		// Cordon every node first, so that pods evicted from one old node don't land on another
+		for _, u := range members {
+			if u.Node == nil {
+				continue
+			}
+			if err := cordonNode(rollingUpdateData.K8sClient, u.Node.Name); err != nil {
+				return err
+			}
+		}
+
+		for _, u := range members {
+			if u.Node == nil {
+				glog.Warningf("Skipping drain of instance %q, because it is not registered in kubernetes", u.ID)
+				continue
+			}
+			glog.Infof("Draining the node: %q.", u.Node.Name)
+			if err := r.DrainNode(u, rollingUpdateData); err != nil {
+				if rollingUpdateData.FailOnDrainError {
+					return fmt.Errorf("failed to drain node %q: %v", u.Node.Name, err)
+				}
+				glog.Infof("Ignoring error draining node %q: %v", u.Node.Name, err)
+			}
+		}
+
+		glog.Infof("Validating the cluster.")
+		if err := r.ValidateClusterWithDuration(rollingUpdateData, cluster, instanceGroupList, validationTimeout); err != nil {
+			if rollingUpdateData.FailOnValidate || rollingUpdateData.RollbackOnFailure {
+				glog.Errorf("Cluster did not validate within %s", validationTimeout)
+				rollingUpdateData.recordValidationFailure(name)
+				return fmt.Errorf("error validating cluster after draining instance group %q, which has not been deleted: %v", old.ObjectMeta.Name, err)
+			}
+			glog.Warningf("Cluster validation failed after draining instance group, proceeding since fail-on-validate is set to false: %v", err)
+		}
+	}
+
+	for _, u := range members {
+		if u.Node == nil {
+			continue
+		}
+		if err := r.deleteNode(u.Node, rollingUpdateData); err != nil {
+			return fmt.Errorf("error deleting node %q: %v", u.Node.Name, err)
+		}
+	}
+
+	glog.Infof("Deleting instance group %q", old.ObjectMeta.Name)
+	if err := r.Cloud.DeleteGroup(r.CloudGroup); err != nil {
+		return fmt.Errorf("error deleting cloud resources for instance group %q: %v", old.ObjectMeta.Name, err)
+	}
+	if err := rollingUpdateData.Clientset.InstanceGroupsFor(cluster).Delete(old.ObjectMeta.Name, nil); err != nil {
+		return fmt.Errorf("error deleting instance group %q: %v", old.ObjectMeta.Name, err)
+	}
+	rollingUpdateData.record(cluster.ObjectMeta.Name, audit.OperationDelete, "instancegroup/"+old.ObjectMeta.Name, old, nil)
+
+	var remaining []api.InstanceGroup
+	for _, item := range instanceGroupList.Items {
+		if item.ObjectMeta.Name != old.ObjectMeta.Name {
+			remaining = append(remaining, item)
+		}
+	}
+	instanceGroupList.Items = remaining
+
+	glog.Infof("Replaced instance group %q with %q", old.ObjectMeta.Name, name)
+	return nil
+}
+
+// waitForGroupNodesReady waits until at least count nodes of the named instance group are ready
+func waitForGroupNodesReady(rollingUpdateData *RollingUpdateCluster, groupName string, count int, timeout time.Duration) error {
+	selector := labels.SelectorFromSet(labels.Set{api.NodeLabelInstanceGroup: groupName})
+	deadline := time.Now().Add(timeout)
+	for {
+		nodes, err := rollingUpdateData.K8sClient.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return fmt.Errorf("error listing nodes: %v", err)
+		}
+		ready := 0
+		for i := range nodes.Items {
+			if isNodeReady(&nodes.Items[i]) {
+				ready++
+			}
+		}
+		if ready >= count {
+			glog.Infof("%d node(s) of instance group %q are ready", ready, groupName)
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("only %d of %d node(s) of instance group %q were ready within %s", ready, count, groupName, timeout)
+		}
+
+		glog.Infof("Waiting for nodes of instance group %q: %d of %d ready", groupName, ready, count)
+		time.Sleep(duplicateTickDuration)
+	}
+}
+
+// cordonNode marks the node unschedulable
+func cordonNode(k8sClient kubernetes.Interface, nodeName string) error {
+	node, err := k8sClient.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading node %q: %v", nodeName, err)
+	}
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = true
+	if _, err := k8sClient.CoreV1().Nodes().Update(node); err != nil {
+		return fmt.Errorf("error cordoning node %q: %v", nodeName, err)
+	}
+	return nil
+}
+
+// record appends an entry to the audit log, if we have one.
+// The change has already been made, so failing to record it only logs a warning.
+func (c *RollingUpdateCluster) record(clusterName string, operation string, object string, before runtime.Object, after runtime.Object) {
+	if c.AuditLog == nil {
+		return
+	}
+
+	d, err := audit.Diff(before, after)
+	if err != nil {
+		glog.Warningf("unable to serialize %s for the audit log: %v", object, err)
+	}
+	previous, err := audit.Snapshot(before)
+	if err != nil {
+		glog.Warningf("unable to serialize %s for the audit log: %v", object, err)
+	}
+	e := &audit.Entry{
+		Cluster:   clusterName,
+		Operation: operation,
+		Object:    object,
+		Diff:      d,
+		Previous:  previous,
+	}
+	if err := c.AuditLog.Record(e); err != nil {
+		glog.Warningf("unable to record %s of %s in the audit log: %v", operation, object, err)
+	}
+}
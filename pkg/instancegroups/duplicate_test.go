@@ -0,0 +1,234 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestDuplicateGroupName(t *testing.T) {
+	grid := map[string]string{
+		"nodes":       "nodes-green",
+		"nodes-green": "nodes-blue",
+		"nodes-blue":  "nodes-green",
+		"blue":        "blue-green",
+	}
+	for name, expected := range grid {
+		if actual := duplicateGroupName(name); actual != expected {
+			t.Errorf("duplicateGroupName(%q): expected %q, got %q", name, expected, actual)
+		}
+	}
+}
+
+func testDuplicateNode(name string, group string, ready bool) *v1.Node {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{api.NodeLabelInstanceGroup: group},
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestDuplicateRollingUpdate(t *testing.T) {
+	defer func(d time.Duration) { duplicateTickDuration = d }(duplicateTickDuration)
+	duplicateTickDuration = time.Millisecond
+
+	cluster := &api.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "duplicate.k8s.local"}}
+
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	clientset := vfsclientset.NewVFSClientset(base, true)
+
+	ig := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: api.InstanceGroupSpec{
+			Role:        api.InstanceGroupRoleNode,
+			MachineType: "m4.large",
+			MinSize:     fi.Int32(2),
+			MaxSize:     fi.Int32(2),
+		},
+	}
+	ig.AddInstanceGroupNodeLabel()
+	ig, err := clientset.InstanceGroupsFor(cluster).Create(ig)
+	if err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockASG := &mockautoscaling.MockAutoscaling{}
+	mockcloud.MockAutoscaling = mockASG
+	mockcloud.Autoscaling().CreateLaunchConfiguration(&autoscaling.CreateLaunchConfigurationInput{
+		LaunchConfigurationName: aws.String("nodes-1"),
+	})
+	mockcloud.Autoscaling().CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName:    aws.String("nodes"),
+		LaunchConfigurationName: aws.String("nodes-1"),
+		MinSize:                 aws.Int64(2),
+		MaxSize:                 aws.Int64(2),
+	})
+	old1 := testDuplicateNode("old-1", "nodes", true)
+	old2 := testDuplicateNode("old-2", "nodes", true)
+	k8sClient := fake.NewSimpleClientset(old1, old2)
+
+	var applied []string
+	c := &RollingUpdateCluster{
+		Cloud:        mockcloud,
+		K8sClient:    k8sClient,
+		ClusterName:  cluster.ObjectMeta.Name,
+		Strategy:     RollingUpdateStrategyDuplicate,
+		Clientset:    clientset,
+		AuditLog:     audit.NewLog(base),
+		NodeInterval: time.Millisecond,
+		ApplyCluster: func(cluster *api.Cluster, instanceGroups []*api.InstanceGroup) error {
+			applied = nil
+			for _, ig := range instanceGroups {
+				applied = append(applied, ig.ObjectMeta.Name)
+			}
+			// The new group's nodes join the cluster
+			k8sClient.CoreV1().Nodes().Create(testDuplicateNode("new-1", "nodes-green", true))
+			k8sClient.CoreV1().Nodes().Create(testDuplicateNode("new-2", "nodes-green", true))
+			return nil
+		},
+	}
+
+	groups := map[string]*cloudinstances.CloudInstanceGroup{
+		"nodes": {
+			InstanceGroup: ig,
+			MinSize:       2,
+			MaxSize:       2,
+			NeedUpdate: []*cloudinstances.CloudInstanceGroupMember{
+				{ID: "i-1", Node: old1},
+				{ID: "i-2", Node: old2},
+			},
+			Raw: mockASG.Groups["nodes"],
+		},
+	}
+	list := &api.InstanceGroupList{Items: []api.InstanceGroup{*ig}}
+
+	if err := c.RollingUpdate(groups, cluster, list); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(applied) != 2 || applied[0] != "nodes" || applied[1] != "nodes-green" {
+		t.Errorf("unexpected instance groups applied: %v", applied)
+	}
+
+	if old, err := clientset.InstanceGroupsFor(cluster).Get("nodes", metav1.GetOptions{}); err == nil && old != nil {
+		t.Errorf("expected nodes to be deleted from the state store")
+	}
+	stored, err := clientset.InstanceGroupsFor(cluster).Get("nodes-green", metav1.GetOptions{})
+	if err != nil || stored == nil {
+		t.Fatalf("error reading nodes-green: %v", err)
+	}
+	if stored.Spec.MachineType != "m4.large" || stored.Spec.NodeLabels[api.NodeLabelInstanceGroup] != "nodes-green" {
+		t.Errorf("unexpected spec for duplicate group: %v", stored.Spec)
+	}
+
+	if len(mockASG.Groups) != 0 || len(mockASG.LaunchConfigurations) != 0 {
+		t.Errorf("expected the old autoscaling group and launch configuration to be deleted")
+	}
+
+	nodes, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing nodes: %v", err)
+	}
+	if len(nodes.Items) != 2 {
+		t.Errorf("expected only the new nodes to remain, got %d nodes", len(nodes.Items))
+	}
+
+	if len(list.Items) != 1 || list.Items[0].ObjectMeta.Name != "nodes-green" {
+		t.Errorf("unexpected instance group list after update: %v", list.Items)
+	}
+
+	entries, err := c.AuditLog.List(cluster.ObjectMeta.Name)
+	if err != nil {
+		t.Fatalf("error listing audit log: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Object != "instancegroup/nodes-green" || entries[1].Object != "instancegroup/nodes" {
+		t.Errorf("unexpected audit log entries: %v", entries)
+	}
+}
+
+func TestDuplicateRollingUpdateNodesNotReady(t *testing.T) {
+	defer func(d time.Duration) { duplicateTickDuration = d }(duplicateTickDuration)
+	duplicateTickDuration = time.Millisecond
+
+	cluster := &api.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "duplicate.k8s.local"}}
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	clientset := vfsclientset.NewVFSClientset(base, true)
+
+	ig := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: api.InstanceGroupSpec{
+			Role:    api.InstanceGroupRoleNode,
+			MinSize: fi.Int32(1),
+			MaxSize: fi.Int32(1),
+		},
+	}
+	k8sClient := fake.NewSimpleClientset()
+
+	c := &RollingUpdateCluster{
+		Cloud:             awsup.BuildMockAWSCloud("us-east-1", "abc"),
+		K8sClient:         k8sClient,
+		Strategy:          RollingUpdateStrategyDuplicate,
+		Clientset:         clientset,
+		ValidationTimeout: 10 * time.Millisecond,
+		ApplyCluster: func(cluster *api.Cluster, instanceGroups []*api.InstanceGroup) error {
+			k8sClient.CoreV1().Nodes().Create(testDuplicateNode("new-1", "nodes-green", false))
+			return nil
+		},
+	}
+
+	group := &cloudinstances.CloudInstanceGroup{
+		InstanceGroup: ig,
+		NeedUpdate:    []*cloudinstances.CloudInstanceGroupMember{{ID: "i-1"}},
+	}
+	r, err := NewRollingUpdateInstanceGroup(c.Cloud, group)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list := &api.InstanceGroupList{Items: []api.InstanceGroup{*ig}}
+	if err := r.duplicateRollingUpdate(c, cluster, list, c.ValidationTimeout); err == nil {
+		t.Fatalf("expected an error when the new nodes are not ready")
+	}
+
+	// The old group is left untouched, and the new group is kept for inspection
+	if ig, err := clientset.InstanceGroupsFor(cluster).Get("nodes-green", metav1.GetOptions{}); err != nil || ig == nil {
+		t.Errorf("expected nodes-green to be kept: %v", err)
+	}
+}
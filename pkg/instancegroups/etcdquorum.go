@@ -0,0 +1,220 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+// etcdClusterLabels are the k8s-app labels of the etcd pods kops runs on the masters, by etcd cluster
+var etcdClusterLabels = map[string]string{
+	"etcd-server":        "main",
+	"etcd-server-events": "events",
+}
+
+// etcdMember is an etcd member, running as a pod on a master
+type etcdMember struct {
+	// Cluster is the etcd cluster of the member, main or events
+	Cluster string
+	// Pod is the name of the etcd pod
+	Pod string
+	// Node is the name of the node running the member
+	Node string
+	// Healthy is true if the member reports itself healthy, or if its pod is ready when we can't reach it
+	Healthy bool
+	// State is the raft state of the member, StateLeader or StateFollower, or "" if it is not known
+	State string
+}
+
+// etcdProxyGet reads path from the named pod through the apiserver proxy; tests replace it
+var etcdProxyGet = func(client kubernetes.Interface, scheme string, podName string, port int, path string) ([]byte, error) {
+	name := fmt.Sprintf("%s:%s:%d", scheme, podName, port)
+	return client.CoreV1().RESTClient().Get().Namespace("kube-system").Resource("pods").Name(name).SubResource("proxy").Suffix(path).DoRaw()
+}
+
+// listEtcdMembers returns the etcd members of the cluster, with their health and raft state
+func listEtcdMembers(client kubernetes.Interface) ([]*etcdMember, error) {
+	pods, err := client.CoreV1().Pods("kube-system").List(metav1.ListOptions{LabelSelector: "k8s-app in (etcd-server, etcd-server-events)"})
+	if err != nil {
+		return nil, fmt.Errorf("error listing etcd pods: %v", err)
+	}
+
+	var members []*etcdMember
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		cluster := etcdClusterLabels[pod.Labels["k8s-app"]]
+		if cluster == "" {
+			continue
+		}
+
+		m := &etcdMember{
+			Cluster: cluster,
+			Pod:     pod.Name,
+			Node:    pod.Spec.NodeName,
+			Healthy: isPodReady(pod),
+		}
+
+		scheme, port := etcdClientEndpoint(pod, cluster)
+		if data, err := etcdProxyGet(client, scheme, pod.Name, port, "/health"); err != nil {
+			glog.V(2).Infof("unable to query health of etcd member %q, using the pod status: %v", pod.Name, err)
+		} else {
+			health := struct {
+				Health string `json:"health"`
+			}{}
+			if err := json.Unmarshal(data, &health); err != nil {
+				glog.V(2).Infof("unable to parse health of etcd member %q, using the pod status: %v", pod.Name, err)
+			} else {
+				m.Healthy = health.Health == "true"
+			}
+		}
+
+		if data, err := etcdProxyGet(client, scheme, pod.Name, port, "/v2/stats/self"); err != nil {
+			glog.V(2).Infof("unable to query raft state of etcd member %q: %v", pod.Name, err)
+		} else {
+			stats := struct {
+				State string `json:"state"`
+			}{}
+			if err := json.Unmarshal(data, &stats); err != nil {
+				glog.V(2).Infof("unable to parse raft state of etcd member %q: %v", pod.Name, err)
+			} else {
+				m.State = stats.State
+			}
+		}
+
+		members = append(members, m)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Cluster != members[j].Cluster {
+			return members[i].Cluster < members[j].Cluster
+		}
+		return members[i].Pod < members[j].Pod
+	})
+	return members, nil
+}
+
+// etcdClientEndpoint returns the scheme and client port of the etcd pod
+func etcdClientEndpoint(pod *v1.Pod, cluster string) (string, int) {
+	scheme := "http"
+	port := 4001
+	if cluster == "events" {
+		port = 4002
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, env := range c.Env {
+			if env.Name != "ETCD_LISTEN_CLIENT_URLS" {
+				continue
+			}
+			if strings.HasPrefix(env.Value, "https://") {
+				scheme = "https"
+			}
+			if i := strings.LastIndex(env.Value, ":"); i != -1 {
+				if p, err := strconv.Atoi(env.Value[i+1:]); err == nil {
+					port = p
+				}
+			}
+		}
+	}
+	return scheme, port
+}
+
+// checkEtcdQuorum returns an error if terminating the named node would leave an etcd cluster without quorum,
+// or if a member on another node is already unhealthy or a cluster has no leader.
+// A cluster with a single member is always unavailable while its master is replaced, so it is only warned about.
+func checkEtcdQuorum(members []*etcdMember, nodeName string) error {
+	byCluster := make(map[string][]*etcdMember)
+	var clusters []string
+	for _, m := range members {
+		if byCluster[m.Cluster] == nil {
+			clusters = append(clusters, m.Cluster)
+		}
+		byCluster[m.Cluster] = append(byCluster[m.Cluster], m)
+	}
+	sort.Strings(clusters)
+
+	for _, cluster := range clusters {
+		members := byCluster[cluster]
+		if len(members) == 1 {
+			glog.Warningf("etcd cluster %q has a single member; it will be unavailable until its master is replaced", cluster)
+			continue
+		}
+
+		remaining := 0
+		leaderKnown := false
+		hasLeader := false
+		for _, m := range members {
+			if m.State != "" {
+				leaderKnown = true
+			}
+			if m.State == "StateLeader" {
+				hasLeader = true
+				if m.Node == nodeName {
+					glog.Infof("etcd member %q is the leader of etcd cluster %q; a new leader will be elected", m.Pod, cluster)
+				}
+			}
+			if m.Node == nodeName {
+				continue
+			}
+			if !m.Healthy {
+				return fmt.Errorf("etcd member %q of etcd cluster %q, on node %q, is unhealthy", m.Pod, cluster, m.Node)
+			}
+			remaining++
+		}
+
+		if leaderKnown && !hasLeader {
+			return fmt.Errorf("etcd cluster %q has no leader", cluster)
+		}
+
+		quorum := len(members)/2 + 1
+		if remaining < quorum {
+			return fmt.Errorf("terminating node %q would leave etcd cluster %q with %d of %d members, fewer than the %d needed for quorum", nodeName, cluster, remaining, len(members), quorum)
+		}
+	}
+
+	return nil
+}
+
+// runsEtcd returns true if the instances of the group run etcd members
+func runsEtcd(ig *api.InstanceGroup) bool {
+	return ig.Spec.Role == api.InstanceGroupRoleMaster || ig.Spec.Role == api.InstanceGroupRoleEtcd
+}
+
+// ensureEtcdQuorum checks that the named master can be terminated without etcd losing quorum
+func ensureEtcdQuorum(client kubernetes.Interface, nodeName string) error {
+	members, err := listEtcdMembers(client)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		glog.Warningf("No etcd pods found; not checking etcd quorum")
+		return nil
+	}
+	if err := checkEtcdQuorum(members, nodeName); err != nil {
+		return fmt.Errorf("not terminating master %q: %v (use --etcd-quorum-check=false to override)", nodeName, err)
+	}
+	return nil
+}
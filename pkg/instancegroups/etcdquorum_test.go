@@ -0,0 +1,186 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckEtcdQuorum(t *testing.T) {
+	member := func(cluster string, node string, healthy bool, state string) *etcdMember {
+		return &etcdMember{Cluster: cluster, Pod: "etcd-server-" + node, Node: node, Healthy: healthy, State: state}
+	}
+
+	grid := []struct {
+		name     string
+		members  []*etcdMember
+		node     string
+		expected string
+	}{
+		{
+			name: "healthy three member cluster",
+			members: []*etcdMember{
+				member("main", "master-a", true, "StateLeader"),
+				member("main", "master-b", true, "StateFollower"),
+				member("main", "master-c", true, "StateFollower"),
+			},
+			node: "master-a",
+		},
+		{
+			name: "other member unhealthy",
+			members: []*etcdMember{
+				member("main", "master-a", true, "StateLeader"),
+				member("main", "master-b", false, ""),
+				member("main", "master-c", true, "StateFollower"),
+			},
+			node:     "master-a",
+			expected: "is unhealthy",
+		},
+		{
+			name: "replacing the unhealthy member",
+			members: []*etcdMember{
+				member("main", "master-a", false, ""),
+				member("main", "master-b", true, "StateLeader"),
+				member("main", "master-c", true, "StateFollower"),
+			},
+			node: "master-a",
+		},
+		{
+			name: "two member cluster loses quorum",
+			members: []*etcdMember{
+				member("main", "master-a", true, "StateLeader"),
+				member("main", "master-b", true, "StateFollower"),
+			},
+			node:     "master-b",
+			expected: "fewer than the 2 needed for quorum",
+		},
+		{
+			name: "no leader",
+			members: []*etcdMember{
+				member("events", "master-a", true, "StateFollower"),
+				member("events", "master-b", true, "StateFollower"),
+				member("events", "master-c", true, "StateFollower"),
+			},
+			node:     "master-a",
+			expected: "has no leader",
+		},
+		{
+			name: "raft state unknown",
+			members: []*etcdMember{
+				member("main", "master-a", true, ""),
+				member("main", "master-b", true, ""),
+				member("main", "master-c", true, ""),
+			},
+			node: "master-c",
+		},
+		{
+			name: "single member",
+			members: []*etcdMember{
+				member("main", "master-a", true, "StateLeader"),
+			},
+			node: "master-a",
+		},
+	}
+
+	for _, g := range grid {
+		err := checkEtcdQuorum(g.members, g.node)
+		if g.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", g.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), g.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", g.name, g.expected, err)
+		}
+	}
+}
+
+func testEtcdPod(name string, app string, node string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+			Labels:    map[string]string{"k8s-app": app},
+		},
+		Spec: v1.PodSpec{
+			NodeName: node,
+			Containers: []v1.Container{{
+				Env: []v1.EnvVar{{Name: "ETCD_LISTEN_CLIENT_URLS", Value: "https://0.0.0.0:4002"}},
+			}},
+		},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestListEtcdMembers(t *testing.T) {
+	defer func(f func(kubernetes.Interface, string, string, int, string) ([]byte, error)) { etcdProxyGet = f }(etcdProxyGet)
+
+	var requests []string
+	etcdProxyGet = func(client kubernetes.Interface, scheme string, podName string, port int, path string) ([]byte, error) {
+		requests = append(requests, fmt.Sprintf("%s:%s:%d%s", scheme, podName, port, path))
+		switch podName {
+		case "etcd-server-events-master-a":
+			if path == "/health" {
+				return []byte(`{"health": "false"}`), nil
+			}
+			return []byte(`{"state": "StateLeader"}`), nil
+		default:
+			return nil, fmt.Errorf("proxy error")
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		testEtcdPod("etcd-server-events-master-a", "etcd-server-events", "master-a", true),
+		testEtcdPod("etcd-server-master-b", "etcd-server", "master-b", true),
+		testEtcdPod("kube-proxy-master-a", "kube-proxy", "master-a", true),
+	)
+
+	members, err := listEtcdMembers(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("expected 2 etcd members, got %d", len(members))
+	}
+
+	// The health reported by etcd wins over the pod status
+	if m := members[0]; m.Cluster != "events" || m.Node != "master-a" || m.Healthy || m.State != "StateLeader" {
+		t.Errorf("unexpected events member: %+v", m)
+	}
+	// We fall back to the pod status when etcd can't be reached
+	if m := members[1]; m.Cluster != "main" || m.Node != "master-b" || !m.Healthy || m.State != "" {
+		t.Errorf("unexpected main member: %+v", m)
+	}
+
+	if len(requests) == 0 || requests[0] != "https:etcd-server-events-master-a:4002/health" {
+		t.Errorf("unexpected proxy requests: %v", requests)
+	}
+}
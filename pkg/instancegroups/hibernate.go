@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+// IsStopped returns true if the cluster has been stopped
+func IsStopped(cluster *api.Cluster) bool {
+	_, found := cluster.ObjectMeta.Annotations[api.AnnotationNameStoppedInstanceGroups]
+	return found
+}
+
+// StopCluster pauses every instance group of the cluster, including the masters.  Groups that are already paused are
+// left alone.  The changed groups are returned in the order they should be scaled down: nodes first, masters last.
+// The cluster is not changed: once the groups have been scaled down, MarkClusterStopped records which groups were
+// paused, so that StartCluster only resumes those.
+func StopCluster(cluster *api.Cluster, groups []*api.InstanceGroup) ([]*api.InstanceGroup, error) {
+	if IsStopped(cluster) {
+		return nil, fmt.Errorf("cluster %q is already stopped", cluster.ObjectMeta.Name)
+	}
+
+	var stopped []*api.InstanceGroup
+	for _, ig := range groups {
+		if IsPaused(ig) {
+			glog.Infof("InstanceGroup %q is already paused", ig.ObjectMeta.Name)
+			continue
+		}
+		if err := pauseInstanceGroup(ig); err != nil {
+			return nil, err
+		}
+		stopped = append(stopped, ig)
+	}
+
+	sortByStartOrder(stopped)
+	reversed := make([]*api.InstanceGroup, 0, len(stopped))
+	for i := len(stopped) - 1; i >= 0; i-- {
+		reversed = append(reversed, stopped[i])
+	}
+	return reversed, nil
+}
+
+// MarkClusterStopped records on the cluster the instance groups that were paused when it was stopped
+func MarkClusterStopped(cluster *api.Cluster, stopped []*api.InstanceGroup) {
+	var names []string
+	for _, ig := range stopped {
+		names = append(names, ig.ObjectMeta.Name)
+	}
+	sort.Strings(names)
+
+	if cluster.ObjectMeta.Annotations == nil {
+		cluster.ObjectMeta.Annotations = make(map[string]string)
+	}
+	cluster.ObjectMeta.Annotations[api.AnnotationNameStoppedInstanceGroups] = strings.Join(names, ",")
+}
+
+// StartCluster resumes the instance groups paused by StopCluster.
+// The changed groups are returned in the order they should be scaled up: masters first, nodes last.
+// The cluster is not changed: once the groups have been scaled up, MarkClusterStarted records that it is no longer stopped.
+func StartCluster(cluster *api.Cluster, groups []*api.InstanceGroup) ([]*api.InstanceGroup, error) {
+	value, found := cluster.ObjectMeta.Annotations[api.AnnotationNameStoppedInstanceGroups]
+	if !found {
+		return nil, fmt.Errorf("cluster %q is not stopped", cluster.ObjectMeta.Name)
+	}
+
+	names := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		if name != "" {
+			names[name] = true
+		}
+	}
+
+	var started []*api.InstanceGroup
+	for _, ig := range groups {
+		if !names[ig.ObjectMeta.Name] {
+			continue
+		}
+		if !IsPaused(ig) {
+			glog.Warningf("InstanceGroup %q was stopped with the cluster, but is not paused", ig.ObjectMeta.Name)
+			continue
+		}
+		if err := ResumeInstanceGroup(ig); err != nil {
+			return nil, err
+		}
+		started = append(started, ig)
+	}
+
+	sortByStartOrder(started)
+	return started, nil
+}
+
+// MarkClusterStarted records on the cluster that it is no longer stopped
+func MarkClusterStarted(cluster *api.Cluster) {
+	delete(cluster.ObjectMeta.Annotations, api.AnnotationNameStoppedInstanceGroups)
+}
+
+// startOrder is the order in which instance groups are started: the control plane before the nodes that depend on it
+var startOrder = map[api.InstanceGroupRole]int{
+	api.InstanceGroupRoleEtcd:      0,
+	api.InstanceGroupRoleMaster:    1,
+	api.InstanceGroupRoleAPIServer: 2,
+	api.InstanceGroupRoleBastion:   3,
+	api.InstanceGroupRoleNode:      4,
+}
+
+func sortByStartOrder(groups []*api.InstanceGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		return startOrder[groups[i].Spec.Role] < startOrder[groups[j].Spec.Role]
+	})
+}
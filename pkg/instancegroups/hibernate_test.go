@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func buildHibernateGroup(name string, role api.InstanceGroupRole, size int32) *api.InstanceGroup {
+	return &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: api.InstanceGroupSpec{
+			Role:    role,
+			MinSize: fi.Int32(size),
+			MaxSize: fi.Int32(size),
+		},
+	}
+}
+
+func groupNames(groups []*api.InstanceGroup) []string {
+	var names []string
+	for _, ig := range groups {
+		names = append(names, ig.ObjectMeta.Name)
+	}
+	return names
+}
+
+func TestStopStartCluster(t *testing.T) {
+	cluster := &api.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test.k8s.local"}}
+
+	gpu := buildHibernateGroup("gpu-nodes", api.InstanceGroupRoleNode, 1)
+	if err := PauseInstanceGroup(gpu); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups := []*api.InstanceGroup{
+		buildHibernateGroup("nodes", api.InstanceGroupRoleNode, 3),
+		buildHibernateGroup("master-us-east-1a", api.InstanceGroupRoleMaster, 1),
+		buildHibernateGroup("bastions", api.InstanceGroupRoleBastion, 1),
+		gpu,
+	}
+
+	stopped, err := StopCluster(cluster, groups)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if IsStopped(cluster) {
+		t.Fatalf("cluster should not be marked stopped before its groups are scaled down")
+	}
+	MarkClusterStopped(cluster, stopped)
+	if !IsStopped(cluster) {
+		t.Fatalf("cluster should be stopped")
+	}
+	if names := groupNames(stopped); len(names) != 3 || names[0] != "nodes" || names[1] != "bastions" || names[2] != "master-us-east-1a" {
+		t.Fatalf("unexpected stop order %v", names)
+	}
+	for _, ig := range groups {
+		if fi.Int32Value(ig.Spec.MaxSize) != 0 {
+			t.Errorf("expected %q to be scaled to zero", ig.ObjectMeta.Name)
+		}
+	}
+	if _, err := StopCluster(cluster, groups); err == nil {
+		t.Fatalf("expected error stopping a stopped cluster")
+	}
+
+	started, err := StartCluster(cluster, groups)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsStopped(cluster) {
+		t.Fatalf("cluster should remain marked stopped until its groups are scaled up")
+	}
+	MarkClusterStarted(cluster)
+	if IsStopped(cluster) {
+		t.Fatalf("cluster should not be stopped after start")
+	}
+	if names := groupNames(started); len(names) != 3 || names[0] != "master-us-east-1a" || names[1] != "bastions" || names[2] != "nodes" {
+		t.Fatalf("unexpected start order %v", names)
+	}
+	if fi.Int32Value(groups[0].Spec.MinSize) != 3 {
+		t.Errorf("expected nodes to be restored to 3, got %d", fi.Int32Value(groups[0].Spec.MinSize))
+	}
+	if !IsPaused(gpu) {
+		t.Errorf("expected gpu-nodes, which was paused before the cluster was stopped, to remain paused")
+	}
+	if _, err := StartCluster(cluster, groups); err == nil {
+		t.Fatalf("expected error starting a cluster which is not stopped")
+	}
+}
@@ -19,6 +19,7 @@ package instancegroups
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -27,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
 	"k8s.io/kops/pkg/featureflag"
@@ -129,6 +131,19 @@ func (r *RollingUpdateInstanceGroup) RollingUpdate(rollingUpdateData *RollingUpd
 		return nil
 	}
 
+	coordinateWithAutoscaler := rollingUpdateData.clusterAutoscaler && r.CloudGroup.InstanceGroup.Spec.Role == api.InstanceGroupRoleNode
+	if coordinateWithAutoscaler {
+		restoreMinSize, err := r.raiseMinSize()
+		if err != nil {
+			return err
+		}
+		defer restoreMinSize()
+
+		if err := rollingUpdateData.protectNodes(r.CloudGroup, update); err != nil {
+			glog.Warningf("Unable to protect nodes from cluster-autoscaler scale-down: %v", err)
+		}
+	}
+
 	if isBastion {
 		glog.V(3).Info("Not validating the cluster as instance is a bastion.")
 	} else if rollingUpdateData.CloudOnly {
@@ -144,6 +159,17 @@ func (r *RollingUpdateInstanceGroup) RollingUpdate(rollingUpdateData *RollingUpd
 		}
 	}
 
+	if rollingUpdateData.Strategy == RollingUpdateStrategyNative {
+		return r.nativeRollingUpdate(rollingUpdateData, cluster, instanceGroupList, update, isBastion, validationTimeout)
+	}
+
+	if rollingUpdateData.Strategy == RollingUpdateStrategyDuplicate {
+		if r.CloudGroup.InstanceGroup.Spec.Role == api.InstanceGroupRoleNode {
+			return r.duplicateRollingUpdate(rollingUpdateData, cluster, instanceGroupList, validationTimeout)
+		}
+		glog.Infof("Instance group %q is not a node group, so its instances are replaced one at a time", r.CloudGroup.InstanceGroup.ObjectMeta.Name)
+	}
+
 	for _, u := range update {
 		instanceId := u.ID
 
@@ -160,6 +186,12 @@ func (r *RollingUpdateInstanceGroup) RollingUpdate(rollingUpdateData *RollingUpd
 
 		} else if featureflag.DrainAndValidateRollingUpdate.Enabled() {
 
+			if rollingUpdateData.EtcdQuorumCheck && runsEtcd(r.CloudGroup.InstanceGroup) {
+				if err = ensureEtcdQuorum(rollingUpdateData.K8sClient, nodeName); err != nil {
+					return err
+				}
+			}
+
 			if u.Node != nil {
 				glog.Infof("Draining the node: %q.", nodeName)
 
@@ -205,12 +237,21 @@ func (r *RollingUpdateInstanceGroup) RollingUpdate(rollingUpdateData *RollingUpd
 			glog.Warningf("Not validating cluster as cloudonly flag is set.")
 
 		} else if featureflag.DrainAndValidateRollingUpdate.Enabled() {
+			if err = r.probeAPIServer(rollingUpdateData, validationTimeout); err != nil {
+				if rollingUpdateData.FailOnValidate || rollingUpdateData.RollbackOnFailure {
+					rollingUpdateData.recordValidationFailure(r.CloudGroup.InstanceGroup.ObjectMeta.Name)
+					return fmt.Errorf("error probing apiserver after removing a node: %v", err)
+				}
+				glog.Warningf("apiserver was not consistently available after removing instance, proceeding since fail-on-validate is set to false: %v", err)
+			}
+
 			glog.Infof("Validating the cluster.")
 
 			if err = r.ValidateClusterWithDuration(rollingUpdateData, cluster, instanceGroupList, validationTimeout); err != nil {
 
-				if rollingUpdateData.FailOnValidate {
+				if rollingUpdateData.FailOnValidate || rollingUpdateData.RollbackOnFailure {
 					glog.Errorf("Cluster did not validate within %s", validationTimeout)
+					rollingUpdateData.recordValidationFailure(r.CloudGroup.InstanceGroup.ObjectMeta.Name)
 					return fmt.Errorf("error validating cluster after removing a node: %v", err)
 				}
 
@@ -218,6 +259,13 @@ func (r *RollingUpdateInstanceGroup) RollingUpdate(rollingUpdateData *RollingUpd
 			}
 		}
 
+		if coordinateWithAutoscaler {
+			// Protect the replacement node(s) that have joined since we started
+			if err := rollingUpdateData.protectNodes(r.CloudGroup, update); err != nil {
+				glog.Warningf("Unable to protect nodes from cluster-autoscaler scale-down: %v", err)
+			}
+		}
+
 		if rollingUpdateData.Interactive {
 			stopPrompting, err := promptInteractive(u.ID, nodeName)
 			if err != nil {
@@ -243,6 +291,16 @@ func (r *RollingUpdateInstanceGroup) ValidateClusterWithDuration(rollingUpdateDa
 		return nil
 	}
 
+	// With rollback on failure, we stop waiting once the cluster has failed validation enough times in a row
+	failures := 1
+	threshold := 0
+	if rollingUpdateData.RollbackOnFailure {
+		threshold = rollingUpdateData.ValidationFailureThreshold
+	}
+	if threshold > 0 && failures >= threshold {
+		return fmt.Errorf("cluster failed validation %d consecutive times", failures)
+	}
+
 	timeout := time.After(duration)
 	tick := time.Tick(tickDuration)
 	// Keep trying until we're timed out or got a result or got an error
@@ -256,6 +314,10 @@ func (r *RollingUpdateInstanceGroup) ValidateClusterWithDuration(rollingUpdateDa
 			if r.tryValidateCluster(rollingUpdateData, cluster, instanceGroupList, duration, tickDuration) {
 				return nil
 			}
+			failures++
+			if threshold > 0 && failures >= threshold {
+				return fmt.Errorf("cluster failed validation %d consecutive times", failures)
+			}
 			// ValidateCluster didn't work yet, so let's try again
 			// this will exit up to the for loop
 		}
@@ -263,7 +325,7 @@ func (r *RollingUpdateInstanceGroup) ValidateClusterWithDuration(rollingUpdateDa
 }
 
 func (r *RollingUpdateInstanceGroup) tryValidateCluster(rollingUpdateData *RollingUpdateCluster, cluster *api.Cluster, instanceGroupList *api.InstanceGroupList, duration time.Duration, tickDuration time.Duration) bool {
-	result, err := validation.ValidateCluster(cluster, instanceGroupList, rollingUpdateData.K8sClient)
+	result, err := validation.ValidateClusterWithOptions(cluster, instanceGroupList, rollingUpdateData.K8sClient, rollingUpdateData.validationOptions())
 
 	if err != nil {
 		glog.Infof("Cluster did not validate, will try again in %q until duration %q expires: %v.", tickDuration, duration, err)
@@ -279,7 +341,7 @@ func (r *RollingUpdateInstanceGroup) tryValidateCluster(rollingUpdateData *Rolli
 
 // ValidateCluster runs our validation methods on the K8s Cluster.
 func (r *RollingUpdateInstanceGroup) ValidateCluster(rollingUpdateData *RollingUpdateCluster, cluster *api.Cluster, instanceGroupList *api.InstanceGroupList) error {
-	if _, err := validation.ValidateCluster(cluster, instanceGroupList, rollingUpdateData.K8sClient); err != nil {
+	if _, err := validation.ValidateClusterWithOptions(cluster, instanceGroupList, rollingUpdateData.K8sClient, rollingUpdateData.validationOptions()); err != nil {
 		return fmt.Errorf("cluster %q did not pass validation: %v", cluster.Name, err)
 	}
 
@@ -314,18 +376,56 @@ func (r *RollingUpdateInstanceGroup) DeleteInstance(u *cloudinstances.CloudInsta
 
 // DrainNode drains a K8s node.
 func (r *RollingUpdateInstanceGroup) DrainNode(u *cloudinstances.CloudInstanceGroupMember, rollingUpdateData *RollingUpdateCluster) error {
-	if rollingUpdateData.ClientConfig == nil {
-		return fmt.Errorf("clientConfig not set")
-	}
-
 	if u.Node.Name == "" {
 		return fmt.Errorf("node name not set")
 	}
-	f := cmdutil.NewFactory(rollingUpdateData.ClientConfig)
 
-	// TODO: Send out somewhere else, also DrainOptions has errout
-	out := os.Stdout
-	errOut := os.Stderr
+	settings := drainSettings{
+		Timeout:                  rollingUpdateData.DrainTimeout,
+		GracePeriod:              rollingUpdateData.PodEvictionGracePeriod,
+		SkipPodsWithLocalStorage: rollingUpdateData.SkipPodsWithLocalStorage,
+		Out:                      rollingUpdateData.Out,
+	}
+
+	var reschedule *rescheduleCheck
+	if rollingUpdateData.WaitForReschedule {
+		var err error
+		reschedule, err = newRescheduleCheck(rollingUpdateData.K8sClient, u.Node.Name, settings.SkipPodsWithLocalStorage)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := drainNode(rollingUpdateData.ClientConfig, u.Node.Name, settings); err != nil {
+		return err
+	}
+
+	if reschedule != nil {
+		if err := reschedule.wait(rollingUpdateData.K8sClient, rollingUpdateData.RescheduleTimeout); err != nil {
+			return err
+		}
+	}
+
+	if rollingUpdateData.PostDrainDelay > 0 {
+		glog.Infof("Waiting for %s for pods to stabilize after draining.", rollingUpdateData.PostDrainDelay)
+		time.Sleep(rollingUpdateData.PostDrainDelay)
+	}
+
+	return nil
+}
+
+// drainNode cordons and drains the named node, evicting pods so that PodDisruptionBudgets are respected
+func drainNode(clientConfig clientcmd.ClientConfig, nodeName string, settings drainSettings) error {
+	if clientConfig == nil {
+		return fmt.Errorf("clientConfig not set")
+	}
+
+	f := cmdutil.NewFactory(clientConfig)
+
+	var out, errOut io.Writer = os.Stdout, os.Stderr
+	if settings.Out != nil {
+		out, errOut = settings.Out, settings.Out
+	}
 
 	options := &cmd.DrainOptions{
 		Factory:            f,
@@ -334,11 +434,12 @@ func (r *RollingUpdateInstanceGroup) DrainNode(u *cloudinstances.CloudInstanceGr
 		Force:              true,
 		DeleteLocalData:    true,
 		ErrOut:             errOut,
-		GracePeriodSeconds: -1,
+		GracePeriodSeconds: settings.gracePeriodSeconds(),
+		Timeout:            settings.Timeout,
 	}
 
 	cmd := cmd.NewCmdDrain(f, out, errOut)
-	args := []string{u.Node.Name}
+	args := []string{nodeName}
 	err := options.SetupDrain(cmd, args)
 	if err != nil {
 		return fmt.Errorf("error setting up drain: %v", err)
@@ -349,16 +450,22 @@ func (r *RollingUpdateInstanceGroup) DrainNode(u *cloudinstances.CloudInstanceGr
 		return fmt.Errorf("error cordoning node node: %v", err)
 	}
 
+	if settings.SkipPodsWithLocalStorage {
+		client, err := f.KubernetesClientSet()
+		if err != nil {
+			return fmt.Errorf("error building kube client: %v", err)
+		}
+		if err := evictPodsSkippingLocalStorage(client, nodeName, settings, nil, 5*time.Second); err != nil {
+			return fmt.Errorf("error draining node: %v", err)
+		}
+		return nil
+	}
+
 	err = options.RunDrain()
 	if err != nil {
 		return fmt.Errorf("error draining node: %v", err)
 	}
 
-	if rollingUpdateData.PostDrainDelay > 0 {
-		glog.Infof("Waiting for %s for pods to stabilize after draining.", rollingUpdateData.PostDrainDelay)
-		time.Sleep(rollingUpdateData.PostDrainDelay)
-	}
-
 	return nil
 }
 
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/featureflag"
+)
+
+const (
+	// RollingUpdateStrategyReplace drains and deletes instances one at a time, letting the cloud group replace them
+	RollingUpdateStrategyReplace = "replace"
+	// RollingUpdateStrategyNative hands the replacement of a whole group to the cloud's own rolling update
+	RollingUpdateStrategyNative = "native"
+	// RollingUpdateStrategyDuplicate replaces each node group with a new group, created alongside it, and then deletes it
+	RollingUpdateStrategyDuplicate = "duplicate"
+)
+
+// NativeRollingUpdater is implemented by clouds whose instance groups can replace their own out-of-date instances,
+// such as GCE managed instance groups
+type NativeRollingUpdater interface {
+	// RollingReplaceGroup replaces the out-of-date instances in the group, returning once the replacement has completed
+	RollingReplaceGroup(g *cloudinstances.CloudInstanceGroup, maxSurge int, maxUnavailable int) error
+}
+
+// nativeRollingUpdate replaces the instances in the group using the cloud's rolling update, then validates the cluster
+func (r *RollingUpdateInstanceGroup) nativeRollingUpdate(rollingUpdateData *RollingUpdateCluster, cluster *api.Cluster, instanceGroupList *api.InstanceGroupList, update []*cloudinstances.CloudInstanceGroupMember, isBastion bool, validationTimeout time.Duration) error {
+	updater, ok := r.Cloud.(NativeRollingUpdater)
+	if !ok {
+		return fmt.Errorf("the %q rolling-update strategy is not supported by cloud provider %q", RollingUpdateStrategyNative, r.Cloud.ProviderID())
+	}
+
+	if rollingUpdateData.Force && len(update) != len(r.CloudGroup.NeedUpdate) {
+		return fmt.Errorf("the %q rolling-update strategy only replaces instances which need updating, and cannot be forced", RollingUpdateStrategyNative)
+	}
+
+	groupName := r.CloudGroup.InstanceGroup.ObjectMeta.Name
+	glog.Infof("Replacing %d instance(s) in instance group %q using the cloud's rolling update (max surge %d, max unavailable %d)", len(update), groupName, rollingUpdateData.MaxSurge, rollingUpdateData.MaxUnavailable)
+	if !isBastion && !rollingUpdateData.CloudOnly {
+		glog.Warningf("Nodes in instance group %q are not drained before the cloud replaces them", groupName)
+	}
+
+	if err := updater.RollingReplaceGroup(r.CloudGroup, rollingUpdateData.MaxSurge, rollingUpdateData.MaxUnavailable); err != nil {
+		return fmt.Errorf("error replacing instances in instance group %q: %v", groupName, err)
+	}
+
+	if isBastion {
+		glog.Infof("Replaced bastion instance group %q, and continuing with rolling-update.", groupName)
+		return nil
+	} else if rollingUpdateData.CloudOnly {
+		glog.Warningf("Not validating cluster as cloudonly flag is set.")
+		return nil
+	} else if !featureflag.DrainAndValidateRollingUpdate.Enabled() {
+		return nil
+	}
+
+	if err := r.probeAPIServer(rollingUpdateData, validationTimeout); err != nil {
+		if rollingUpdateData.FailOnValidate || rollingUpdateData.RollbackOnFailure {
+			rollingUpdateData.recordValidationFailure(groupName)
+			return fmt.Errorf("error probing apiserver after replacing instance group %q: %v", groupName, err)
+		}
+		glog.Warningf("apiserver was not consistently available after replacing instance group, proceeding since fail-on-validate is set to false: %v", err)
+	}
+
+	glog.Infof("Validating the cluster.")
+	if err := r.ValidateClusterWithDuration(rollingUpdateData, cluster, instanceGroupList, validationTimeout); err != nil {
+		if rollingUpdateData.FailOnValidate || rollingUpdateData.RollbackOnFailure {
+			glog.Errorf("Cluster did not validate within %s", validationTimeout)
+			rollingUpdateData.recordValidationFailure(groupName)
+			return fmt.Errorf("error validating cluster after replacing instance group %q: %v", groupName, err)
+		}
+
+		glog.Warningf("Cluster validation failed after replacing instance group, proceeding since fail-on-validate is set to false: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+// nativeMockCloud records the groups replaced through the cloud's rolling update
+type nativeMockCloud struct {
+	*awsup.MockAWSCloud
+
+	replaced []string
+}
+
+func (c *nativeMockCloud) RollingReplaceGroup(g *cloudinstances.CloudInstanceGroup, maxSurge int, maxUnavailable int) error {
+	c.replaced = append(c.replaced, g.InstanceGroup.ObjectMeta.Name)
+	return nil
+}
+
+func buildNativeGroups() map[string]*cloudinstances.CloudInstanceGroup {
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	for _, name := range []string{"node-1", "node-2"} {
+		groups[name] = &cloudinstances.CloudInstanceGroup{
+			InstanceGroup: &kopsapi.InstanceGroup{
+				ObjectMeta: v1meta.ObjectMeta{
+					Name: name,
+				},
+				Spec: kopsapi.InstanceGroupSpec{
+					Role: kopsapi.InstanceGroupRoleNode,
+				},
+			},
+		}
+	}
+	groups["node-1"].NeedUpdate = []*cloudinstances.CloudInstanceGroupMember{
+		{
+			ID:   "node-1a",
+			Node: &v1.Node{},
+		},
+	}
+	groups["node-2"].Ready = []*cloudinstances.CloudInstanceGroupMember{
+		{
+			ID:   "node-2a",
+			Node: &v1.Node{},
+		},
+	}
+	return groups
+}
+
+func TestRollingUpdateNativeStrategy(t *testing.T) {
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+	cloud := &nativeMockCloud{MockAWSCloud: mockcloud}
+
+	cluster := &kopsapi.Cluster{}
+	cluster.Name = "test.k8s.local"
+
+	c := &RollingUpdateCluster{
+		Cloud:          cloud,
+		NodeInterval:   1 * time.Millisecond,
+		K8sClient:      fake.NewSimpleClientset(),
+		Strategy:       RollingUpdateStrategyNative,
+		MaxSurge:       1,
+		MaxUnavailable: 0,
+		CloudOnly:      true,
+	}
+
+	err := c.RollingUpdate(buildNativeGroups(), cluster, &kopsapi.InstanceGroupList{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cloud.replaced) != 1 || cloud.replaced[0] != "node-1" {
+		t.Errorf("expected only node-1 to be replaced, got %v", cloud.replaced)
+	}
+}
+
+func TestRollingUpdateNativeStrategyUnsupported(t *testing.T) {
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	cluster := &kopsapi.Cluster{}
+	cluster.Name = "test.k8s.local"
+
+	c := &RollingUpdateCluster{
+		Cloud:        mockcloud,
+		NodeInterval: 1 * time.Millisecond,
+		K8sClient:    fake.NewSimpleClientset(),
+		Strategy:     RollingUpdateStrategyNative,
+		MaxSurge:     1,
+		CloudOnly:    true,
+	}
+
+	err := c.RollingUpdate(buildNativeGroups(), cluster, &kopsapi.InstanceGroupList{})
+	if err == nil {
+		t.Fatalf("expected error when the cloud does not support the native strategy")
+	}
+}
+
+func TestRollingUpdateNativeStrategyForce(t *testing.T) {
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+	cloud := &nativeMockCloud{MockAWSCloud: mockcloud}
+
+	cluster := &kopsapi.Cluster{}
+	cluster.Name = "test.k8s.local"
+
+	c := &RollingUpdateCluster{
+		Cloud:        cloud,
+		NodeInterval: 1 * time.Millisecond,
+		K8sClient:    fake.NewSimpleClientset(),
+		Strategy:     RollingUpdateStrategyNative,
+		MaxSurge:     1,
+		Force:        true,
+		CloudOnly:    true,
+	}
+
+	err := c.RollingUpdate(buildNativeGroups(), cluster, &kopsapi.InstanceGroupList{})
+	if err == nil {
+		t.Fatalf("expected error when forcing an update of up-to-date instances with the native strategy")
+	}
+}
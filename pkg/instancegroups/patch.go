@@ -0,0 +1,280 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/validation"
+)
+
+// PatchScript installs the pending OS package updates of a node, with unattended-upgrades or apt-get on Debian and
+// Ubuntu, or yum on CentOS, RHEL and Amazon Linux.  It creates /var/run/reboot-required when a reboot is needed.
+const PatchScript = `set -e
+if command -v unattended-upgrade >/dev/null 2>&1; then
+  apt-get update -q
+  unattended-upgrade -v
+elif command -v apt-get >/dev/null 2>&1; then
+  apt-get update -q
+  DEBIAN_FRONTEND=noninteractive apt-get upgrade -y -q -o Dpkg::Options::=--force-confold
+elif command -v yum >/dev/null 2>&1; then
+  yum update -y -q
+  if command -v needs-restarting >/dev/null 2>&1 && ! needs-restarting -r >/dev/null 2>&1; then
+    touch /var/run/reboot-required
+  fi
+else
+  echo "no supported package manager found" >&2
+  exit 1
+fi
+`
+
+// rebootRequiredCommand prints "reboot" if the node must be rebooted to finish patching
+const rebootRequiredCommand = "if [ -f /var/run/reboot-required ]; then echo reboot; fi"
+
+// rebootCommand reboots a node; the SSH connection is dropped, so its result is ignored
+const rebootCommand = "sudo systemctl reboot"
+
+// NodePatcher installs OS package updates on the nodes of a cluster in place, one node at a time:
+// it cordons and drains the node, respecting PodDisruptionBudgets, installs the updates, reboots the node if needed,
+// then uncordons it and waits for the cluster to validate before moving on.
+type NodePatcher struct {
+	K8sClient    kubernetes.Interface
+	ClientConfig clientcmd.ClientConfig
+
+	// Exec runs a shell command on a node, returning its output
+	Exec func(node *v1.Node, command string) (string, error)
+
+	// Interval is the minimum time to wait after patching a node, before patching the next
+	Interval time.Duration
+
+	// PostDrainDelay is how long to wait after draining a node, for pods to stabilize
+	PostDrainDelay time.Duration
+
+	// RebootTimeout is how long to wait for a node to become ready after it is rebooted
+	RebootTimeout time.Duration
+
+	// ValidationTimeout is how long to wait for the cluster to validate after a node is patched
+	ValidationTimeout time.Duration
+
+	// FailOnValidate stops patching when the cluster does not validate
+	FailOnValidate bool
+
+	// ValidateConditions are the node conditions, other than Ready, that fail validation when they are true
+	ValidateConditions []string
+
+	// drain, validate and sleep are replaced in tests
+	drain    func(nodeName string) error
+	validate func(cluster *api.Cluster, instanceGroups *api.InstanceGroupList, timeout time.Duration) error
+	sleep    func(d time.Duration)
+}
+
+// PatchNodes patches the nodes of the listed instance groups, masters first
+func (p *NodePatcher) PatchNodes(cluster *api.Cluster, instanceGroups *api.InstanceGroupList, groups []*api.InstanceGroup) error {
+	if p.Exec == nil {
+		return fmt.Errorf("Exec not set")
+	}
+	p.setDefaults()
+
+	nodeList, err := p.K8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes in cluster: %v", err)
+	}
+
+	nodes := nodesToPatch(nodeList.Items, groups)
+	if len(nodes) == 0 {
+		glog.Infof("No nodes to patch.")
+		return nil
+	}
+
+	for i, node := range nodes {
+		if err := p.patchNode(node); err != nil {
+			return fmt.Errorf("error patching node %q: %v", node.Name, err)
+		}
+
+		if err := p.validate(cluster, instanceGroups, p.ValidationTimeout); err != nil {
+			if p.FailOnValidate {
+				return fmt.Errorf("cluster did not validate after patching node %q: %v", node.Name, err)
+			}
+			glog.Warningf("cluster did not validate after patching node %q, continuing: %v", node.Name, err)
+		}
+
+		if i != len(nodes)-1 && p.Interval > 0 {
+			p.sleep(p.Interval)
+		}
+	}
+
+	return nil
+}
+
+func (p *NodePatcher) setDefaults() {
+	if p.drain == nil {
+		p.drain = func(nodeName string) error {
+			return drainNode(p.ClientConfig, nodeName, drainSettings{})
+		}
+	}
+	if p.validate == nil {
+		p.validate = p.validateCluster
+	}
+	if p.sleep == nil {
+		p.sleep = time.Sleep
+	}
+}
+
+// nodesToPatch returns the nodes belonging to the instance groups, masters first, then sorted by name
+func nodesToPatch(nodes []v1.Node, groups []*api.InstanceGroup) []*v1.Node {
+	roles := make(map[string]api.InstanceGroupRole)
+	for _, ig := range groups {
+		roles[ig.ObjectMeta.Name] = ig.Spec.Role
+	}
+
+	var selected []*v1.Node
+	for i := range nodes {
+		node := &nodes[i]
+		if _, found := roles[node.Labels[api.NodeLabelInstanceGroup]]; found {
+			selected = append(selected, node)
+		}
+	}
+
+	isMaster := func(node *v1.Node) bool {
+		return roles[node.Labels[api.NodeLabelInstanceGroup]] == api.InstanceGroupRoleMaster
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if isMaster(selected[i]) != isMaster(selected[j]) {
+			return isMaster(selected[i])
+		}
+		return selected[i].Name < selected[j].Name
+	})
+	return selected
+}
+
+func (p *NodePatcher) patchNode(node *v1.Node) error {
+	glog.Infof("Draining node %q.", node.Name)
+	if err := p.drain(node.Name); err != nil {
+		return err
+	}
+	if p.PostDrainDelay > 0 {
+		glog.Infof("Waiting for %s for pods to stabilize after draining.", p.PostDrainDelay)
+		p.sleep(p.PostDrainDelay)
+	}
+
+	glog.Infof("Installing updates on node %q.", node.Name)
+	if out, err := p.Exec(node, "sudo sh -c '"+strings.Replace(PatchScript, "'", `'\''`, -1)+"'"); err != nil {
+		return fmt.Errorf("error installing updates: %v\n%s", err, out)
+	}
+
+	out, err := p.Exec(node, rebootRequiredCommand)
+	if err != nil {
+		return fmt.Errorf("error checking whether a reboot is required: %v", err)
+	}
+	if strings.TrimSpace(out) == "reboot" {
+		if err := p.reboot(node); err != nil {
+			return err
+		}
+	} else {
+		glog.Infof("Node %q does not need a reboot.", node.Name)
+	}
+
+	glog.Infof("Uncordoning node %q.", node.Name)
+	return p.uncordon(node.Name)
+}
+
+// reboot reboots the node and waits for it to come back ready, with a new boot ID
+func (p *NodePatcher) reboot(node *v1.Node) error {
+	bootID := node.Status.NodeInfo.BootID
+
+	glog.Infof("Rebooting node %q.", node.Name)
+	if _, err := p.Exec(node, rebootCommand); err != nil {
+		glog.V(2).Infof("reboot command on node %q returned %v; the connection is expected to drop", node.Name, err)
+	}
+
+	deadline := time.Now().Add(p.RebootTimeout)
+	for {
+		current, err := p.K8sClient.CoreV1().Nodes().Get(node.Name, metav1.GetOptions{})
+		if err != nil {
+			glog.V(2).Infof("error reading node %q: %v", node.Name, err)
+		} else if current.Status.NodeInfo.BootID != bootID && isNodeReady(current) {
+			glog.Infof("Node %q is ready after reboot.", node.Name)
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("node did not become ready within %s of rebooting", p.RebootTimeout)
+		}
+		p.sleep(10 * time.Second)
+	}
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (p *NodePatcher) uncordon(nodeName string) error {
+	node, err := p.K8sClient.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading node: %v", err)
+	}
+	if !node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = false
+	if _, err := p.K8sClient.CoreV1().Nodes().Update(node); err != nil {
+		return fmt.Errorf("error uncordoning node: %v", err)
+	}
+	return nil
+}
+
+// validateCluster waits for the cluster to validate, for up to timeout
+func (p *NodePatcher) validateCluster(cluster *api.Cluster, instanceGroups *api.InstanceGroupList, timeout time.Duration) error {
+	conditions := p.ValidateConditions
+	if conditions == nil {
+		conditions = validation.DefaultNodeConditions
+	}
+	options := &validation.ValidationOptions{
+		NodeConditions: conditions,
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err := validation.ValidateClusterWithOptions(cluster, instanceGroups, p.K8sClient, options)
+		if err == nil && len(result.Failures) == 0 {
+			glog.Infof("Cluster validated.")
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s", result.Failures[0].Message)
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		glog.Infof("Cluster did not pass validation, will try again: %v", err)
+		p.sleep(30 * time.Second)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	api "k8s.io/kops/pkg/apis/kops"
+)
+
+func makePatchTestNode(name string, ig string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{api.NodeLabelInstanceGroup: ig},
+		},
+		Status: v1.NodeStatus{
+			NodeInfo:   v1.NodeSystemInfo{BootID: "boot-1"},
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	}
+}
+
+func TestPatchNodes(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(
+		makePatchTestNode("node-b", "nodes"),
+		makePatchTestNode("node-a", "nodes"),
+		makePatchTestNode("master-1", "master-us-test-1a"),
+		makePatchTestNode("other-1", "other"),
+	)
+
+	groups := []*api.InstanceGroup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nodes"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleNode}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "master-us-test-1a"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleMaster}},
+	}
+
+	var drained []string
+	var rebooted []string
+	validations := 0
+	p := &NodePatcher{
+		K8sClient:     k8sClient,
+		RebootTimeout: time.Minute,
+		Exec: func(node *v1.Node, command string) (string, error) {
+			switch command {
+			case rebootRequiredCommand:
+				if node.Name == "node-a" {
+					return "reboot\n", nil
+				}
+				return "", nil
+			case rebootCommand:
+				rebooted = append(rebooted, node.Name)
+				n, err := k8sClient.CoreV1().Nodes().Get(node.Name, metav1.GetOptions{})
+				if err != nil {
+					return "", err
+				}
+				n.Status.NodeInfo.BootID = "boot-2"
+				_, err = k8sClient.CoreV1().Nodes().Update(n)
+				return "", fmt.Errorf("connection lost")
+			default:
+				if !strings.Contains(command, "unattended-upgrade") {
+					t.Errorf("unexpected command %q", command)
+				}
+				return "", nil
+			}
+		},
+		FailOnValidate: true,
+	}
+	p.drain = func(nodeName string) error {
+		drained = append(drained, nodeName)
+		n, err := k8sClient.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		n.Spec.Unschedulable = true
+		_, err = k8sClient.CoreV1().Nodes().Update(n)
+		return err
+	}
+	p.validate = func(cluster *api.Cluster, instanceGroups *api.InstanceGroupList, timeout time.Duration) error {
+		validations++
+		return nil
+	}
+	p.sleep = func(d time.Duration) {}
+
+	if err := p.PatchNodes(&api.Cluster{}, &api.InstanceGroupList{}, groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"master-1", "node-a", "node-b"}; !reflect.DeepEqual(drained, expected) {
+		t.Errorf("expected nodes to be patched in order %v, got %v", expected, drained)
+	}
+	if expected := []string{"node-a"}; !reflect.DeepEqual(rebooted, expected) {
+		t.Errorf("expected only %v to be rebooted, got %v", expected, rebooted)
+	}
+	if validations != 3 {
+		t.Errorf("expected the cluster to be validated after each node, got %d validations", validations)
+	}
+	for _, name := range drained {
+		n, err := k8sClient.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error reading node: %v", err)
+		}
+		if n.Spec.Unschedulable {
+			t.Errorf("expected node %q to be uncordoned", name)
+		}
+	}
+}
+
+func TestPatchNodesStopsOnValidationFailure(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(
+		makePatchTestNode("node-a", "nodes"),
+		makePatchTestNode("node-b", "nodes"),
+	)
+	groups := []*api.InstanceGroup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nodes"}, Spec: api.InstanceGroupSpec{Role: api.InstanceGroupRoleNode}},
+	}
+
+	var drained []string
+	p := &NodePatcher{
+		K8sClient: k8sClient,
+		Exec: func(node *v1.Node, command string) (string, error) {
+			return "", nil
+		},
+		FailOnValidate: true,
+	}
+	p.drain = func(nodeName string) error {
+		drained = append(drained, nodeName)
+		return nil
+	}
+	p.validate = func(cluster *api.Cluster, instanceGroups *api.InstanceGroupList, timeout time.Duration) error {
+		return fmt.Errorf("node-a is not ready")
+	}
+	p.sleep = func(d time.Duration) {}
+
+	if err := p.PatchNodes(&api.Cluster{}, &api.InstanceGroupList{}, groups); err == nil {
+		t.Fatalf("expected error when the cluster does not validate")
+	}
+	if len(drained) != 1 {
+		t.Errorf("expected patching to stop after the first node, drained %v", drained)
+	}
+}
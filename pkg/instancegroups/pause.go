@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"encoding/json"
+	"fmt"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// pausedSizes is the value of the AnnotationNamePausedSizes annotation
+type pausedSizes struct {
+	MinSize *int32 `json:"minSize,omitempty"`
+	MaxSize *int32 `json:"maxSize,omitempty"`
+}
+
+// IsPaused returns true if the instance group has been paused
+func IsPaused(ig *api.InstanceGroup) bool {
+	_, found := ig.ObjectMeta.Annotations[api.AnnotationNamePausedSizes]
+	return found
+}
+
+// PauseInstanceGroup scales the instance group to zero, recording its sizes so that they can be restored by ResumeInstanceGroup
+func PauseInstanceGroup(ig *api.InstanceGroup) error {
+	if IsPaused(ig) {
+		return fmt.Errorf("InstanceGroup %q is already paused", ig.ObjectMeta.Name)
+	}
+	if ig.Spec.Role == api.InstanceGroupRoleMaster {
+		return fmt.Errorf("InstanceGroup %q has role %s and cannot be paused; use \"kops stop cluster\" instead", ig.ObjectMeta.Name, ig.Spec.Role)
+	}
+
+	return pauseInstanceGroup(ig)
+}
+
+// pauseInstanceGroup scales the instance group to zero, whatever its role
+func pauseInstanceGroup(ig *api.InstanceGroup) error {
+	data, err := json.Marshal(&pausedSizes{MinSize: ig.Spec.MinSize, MaxSize: ig.Spec.MaxSize})
+	if err != nil {
+		return fmt.Errorf("error recording sizes of InstanceGroup %q: %v", ig.ObjectMeta.Name, err)
+	}
+
+	if ig.ObjectMeta.Annotations == nil {
+		ig.ObjectMeta.Annotations = make(map[string]string)
+	}
+	ig.ObjectMeta.Annotations[api.AnnotationNamePausedSizes] = string(data)
+	ig.Spec.MinSize = fi.Int32(0)
+	ig.Spec.MaxSize = fi.Int32(0)
+
+	return nil
+}
+
+// ResumeInstanceGroup restores the sizes of an instance group paused by PauseInstanceGroup
+func ResumeInstanceGroup(ig *api.InstanceGroup) error {
+	value, found := ig.ObjectMeta.Annotations[api.AnnotationNamePausedSizes]
+	if !found {
+		return fmt.Errorf("InstanceGroup %q is not paused", ig.ObjectMeta.Name)
+	}
+
+	sizes := &pausedSizes{}
+	if err := json.Unmarshal([]byte(value), sizes); err != nil {
+		return fmt.Errorf("error parsing annotation %s on InstanceGroup %q: %v", api.AnnotationNamePausedSizes, ig.ObjectMeta.Name, err)
+	}
+
+	ig.Spec.MinSize = sizes.MinSize
+	ig.Spec.MaxSize = sizes.MaxSize
+	delete(ig.ObjectMeta.Annotations, api.AnnotationNamePausedSizes)
+
+	return nil
+}
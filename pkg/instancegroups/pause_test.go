@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestPauseResumeInstanceGroup(t *testing.T) {
+	ig := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-nodes"},
+		Spec: api.InstanceGroupSpec{
+			Role:    api.InstanceGroupRoleNode,
+			MinSize: fi.Int32(2),
+			MaxSize: fi.Int32(5),
+		},
+	}
+
+	if IsPaused(ig) {
+		t.Fatalf("instance group should not be paused")
+	}
+	if err := ResumeInstanceGroup(ig); err == nil {
+		t.Fatalf("expected error resuming an instance group which is not paused")
+	}
+
+	if err := PauseInstanceGroup(ig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsPaused(ig) {
+		t.Fatalf("instance group should be paused")
+	}
+	if fi.Int32Value(ig.Spec.MinSize) != 0 || fi.Int32Value(ig.Spec.MaxSize) != 0 {
+		t.Fatalf("expected paused sizes to be 0, got min=%v max=%v", ig.Spec.MinSize, ig.Spec.MaxSize)
+	}
+	if err := PauseInstanceGroup(ig); err == nil {
+		t.Fatalf("expected error pausing an instance group twice")
+	}
+
+	if err := ResumeInstanceGroup(ig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if IsPaused(ig) {
+		t.Fatalf("instance group should not be paused after resume")
+	}
+	if fi.Int32Value(ig.Spec.MinSize) != 2 || fi.Int32Value(ig.Spec.MaxSize) != 5 {
+		t.Fatalf("expected sizes to be restored, got min=%v max=%v", ig.Spec.MinSize, ig.Spec.MaxSize)
+	}
+}
+
+func TestPauseInstanceGroupDefaultSizes(t *testing.T) {
+	ig := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec:       api.InstanceGroupSpec{Role: api.InstanceGroupRoleNode},
+	}
+
+	if err := PauseInstanceGroup(ig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ResumeInstanceGroup(ig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ig.Spec.MinSize != nil || ig.Spec.MaxSize != nil {
+		t.Fatalf("expected default sizes to be restored, got min=%v max=%v", ig.Spec.MinSize, ig.Spec.MaxSize)
+	}
+}
+
+func TestPauseMasterInstanceGroup(t *testing.T) {
+	ig := &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-us-east-1a"},
+		Spec:       api.InstanceGroupSpec{Role: api.InstanceGroupRoleMaster},
+	}
+
+	if err := PauseInstanceGroup(ig); err == nil {
+		t.Fatalf("expected error pausing a master instance group")
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rescheduleTickDuration is the interval at which we check whether the evicted pods have been replaced
+var rescheduleTickDuration = 10 * time.Second
+
+// rescheduleCheck verifies that the pods evicted from some nodes have been replaced by ready pods on other nodes
+type rescheduleCheck struct {
+	// nodes are the drained nodes, and description names them for messages
+	nodes       map[string]bool
+	description string
+
+	// scheduledOnly counts a replacement pod as soon as it is bound to another node, rather than once it is ready
+	scheduledOnly bool
+
+	// expected is the number of ready pods each controller must have on other nodes, by controller UID
+	expected map[types.UID]int
+	// controllers describes each controller, for messages
+	controllers map[types.UID]string
+}
+
+// newRescheduleCheck records the pods that draining the node will evict; it must be called before the drain
+func newRescheduleCheck(k8sClient kubernetes.Interface, nodeName string, skipPodsWithLocalStorage bool) (*rescheduleCheck, error) {
+	return newNodesRescheduleCheck(k8sClient, []string{nodeName}, fmt.Sprintf("node %q", nodeName), skipPodsWithLocalStorage)
+}
+
+// newNodesRescheduleCheck records the pods that draining the nodes will evict; it must be called before the nodes are drained
+func newNodesRescheduleCheck(k8sClient kubernetes.Interface, nodeNames []string, description string, skipPodsWithLocalStorage bool) (*rescheduleCheck, error) {
+	pods, err := k8sClient.CoreV1().Pods("").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Pods: %v", err)
+	}
+
+	c := &rescheduleCheck{
+		nodes:       make(map[string]bool),
+		description: description,
+		expected:    make(map[types.UID]int),
+		controllers: make(map[types.UID]string),
+	}
+	for _, nodeName := range nodeNames {
+		c.nodes[nodeName] = true
+	}
+
+	var onNode []corev1.Pod
+	for _, pod := range pods.Items {
+		if c.nodes[pod.Spec.NodeName] {
+			onNode = append(onNode, pod)
+		}
+	}
+
+	evict, _ := podsToEvict(onNode, skipPodsWithLocalStorage)
+	for i := range evict {
+		pod := &evict[i]
+		controller := metav1.GetControllerOf(pod)
+		if controller == nil {
+			glog.Warningf("Pod %s/%s has no controller, so it will not be rescheduled", pod.Namespace, pod.Name)
+			continue
+		}
+		c.expected[controller.UID]++
+		c.controllers[controller.UID] = strings.ToLower(controller.Kind) + "/" + pod.Namespace + "/" + controller.Name
+	}
+
+	// The replacements are in addition to the pods already ready elsewhere
+	for uid, ready := range c.replacedElsewhere(pods.Items) {
+		c.expected[uid] += ready
+	}
+
+	return c, nil
+}
+
+// replacedElsewhere counts the ready (or, with scheduledOnly, scheduled) pods of each tracked controller on nodes
+// other than the drained nodes
+func (c *rescheduleCheck) replacedElsewhere(pods []corev1.Pod) map[types.UID]int {
+	counts := make(map[types.UID]int)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || c.nodes[pod.Spec.NodeName] || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if !c.scheduledOnly && !isPodReady(pod) {
+			continue
+		}
+		controller := metav1.GetControllerOf(pod)
+		if controller == nil {
+			continue
+		}
+		if _, found := c.expected[controller.UID]; found {
+			counts[controller.UID]++
+		}
+	}
+	return counts
+}
+
+// missing describes the controllers whose evicted pods have not yet been replaced by ready pods on other nodes
+func (c *rescheduleCheck) missing(k8sClient kubernetes.Interface) ([]string, error) {
+	if len(c.expected) == 0 {
+		return nil, nil
+	}
+
+	pods, err := k8sClient.CoreV1().Pods("").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Pods: %v", err)
+	}
+
+	replaced := c.replacedElsewhere(pods.Items)
+
+	state := "ready"
+	if c.scheduledOnly {
+		state = "scheduled"
+	}
+
+	var missing []string
+	for uid, expected := range c.expected {
+		if replaced[uid] < expected {
+			missing = append(missing, fmt.Sprintf("%s (%d/%d %s)", c.controllers[uid], replaced[uid], expected, state))
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// wait waits until the evicted pods have been replaced by ready pods on other nodes, or until the timeout expires
+func (c *rescheduleCheck) wait(k8sClient kubernetes.Interface, timeout time.Duration) error {
+	state := "rescheduled and ready"
+	if c.scheduledOnly {
+		state = "rescheduled"
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		missing, err := c.missing(k8sClient)
+		if err != nil {
+			return err
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("pods evicted from %s were not %s within %s: %s", c.description, state, timeout, strings.Join(missing, ", "))
+		}
+
+		glog.Infof("Waiting for the pods evicted from %s to be %s: %s", c.description, state, strings.Join(missing, ", "))
+		time.Sleep(rescheduleTickDuration)
+	}
+}
+
+// isPodReady is true if the pod has the Ready condition
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testReschedulePod(name string, nodeName string, controllerKind string, controllerUID string, ready bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if controllerKind != "" {
+		isController := true
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			Kind:       controllerKind,
+			Name:       strings.Split(name, "-")[0],
+			UID:        types.UID(controllerUID),
+			Controller: &isController,
+		}}
+	}
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}
+	return pod
+}
+
+func TestRescheduleCheck(t *testing.T) {
+	rescheduleTickDuration = time.Millisecond
+
+	k8sClient := fake.NewSimpleClientset(
+		testReschedulePod("web-0", "node2", "ReplicaSet", "rs-web", true),
+		testReschedulePod("web-1", "node1", "ReplicaSet", "rs-web", true),
+		testReschedulePod("fluentd-1", "node1", "DaemonSet", "ds-fluentd", true),
+		testReschedulePod("bare", "node1", "", "", true),
+	)
+
+	c, err := newRescheduleCheck(k8sClient, "node1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(c.expected, map[types.UID]int{"rs-web": 2}) {
+		t.Fatalf("unexpected expected ready pods: %v", c.expected)
+	}
+
+	// The drain evicts web-1; its replacement is not ready yet
+	if err := k8sClient.CoreV1().Pods("default").Delete("web-1", nil); err != nil {
+		t.Fatalf("error deleting pod: %v", err)
+	}
+	if _, err := k8sClient.CoreV1().Pods("default").Create(testReschedulePod("web-2", "node2", "ReplicaSet", "rs-web", false)); err != nil {
+		t.Fatalf("error creating pod: %v", err)
+	}
+
+	err = c.wait(k8sClient, 5*time.Millisecond)
+	if err == nil {
+		t.Fatalf("expected error waiting for an unready replacement")
+	}
+	expected := `pods evicted from node "node1" were not rescheduled and ready within 5ms: replicaset/default/web (1/2 ready)`
+	if err.Error() != expected {
+		t.Fatalf("unexpected error %q, expected %q", err.Error(), expected)
+	}
+
+	if _, err := k8sClient.CoreV1().Pods("default").Update(testReschedulePod("web-2", "node2", "ReplicaSet", "rs-web", true)); err != nil {
+		t.Fatalf("error updating pod: %v", err)
+	}
+	if err := c.wait(k8sClient, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
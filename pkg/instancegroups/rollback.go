@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/kopscodecs"
+)
+
+// RollbackResult describes the outcome of rolling back an instance group after a failed rolling update
+type RollbackResult struct {
+	// InstanceGroup is the name of the instance group that failed validation
+	InstanceGroup string
+	// Reverted is true if the instance group spec in the state store was reverted
+	Reverted bool
+	// Before and After are the instance group before and after it was reverted, for the audit log
+	Before *api.InstanceGroup
+	After  *api.InstanceGroup
+	// Reason explains what was, or was not, reverted
+	Reason string
+	// ManualActions are the commands the user must still run to complete the rollback
+	ManualActions []string
+}
+
+// RollbackInstanceGroup reverts the spec of the named instance group in the state store to its version at the
+// last successful rolling update, using the previous versions recorded in the audit log.
+// We only revert configuration-only changes, i.e. when the instance group was changed since the last rolling
+// update but the cluster spec was not; anything else is left for the user, and reported in the ManualActions.
+func RollbackInstanceGroup(clientset simple.Clientset, auditLog *audit.Log, cluster *api.Cluster, groupName string) (*RollbackResult, error) {
+	clusterName := cluster.ObjectMeta.Name
+	result := &RollbackResult{InstanceGroup: groupName}
+
+	updateCommands := []string{
+		fmt.Sprintf("kops update cluster %s --yes", clusterName),
+		fmt.Sprintf("kops rolling-update cluster %s --instance-group %s --yes", clusterName, groupName),
+	}
+
+	if auditLog == nil {
+		result.Reason = "the state store has no audit log, so the previous version of the instance group is not known"
+		result.ManualActions = append([]string{fmt.Sprintf("kops edit instancegroup %s --name %s", groupName, clusterName)}, updateCommands...)
+		return result, nil
+	}
+
+	entries, err := auditLog.List(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only consider the changes made since the last completed rolling update which covered the instance group.
+	// Rolling updates recorded against the whole cluster predate recording the groups they covered; we take them to
+	// have covered every group, so that we never revert a change which may already have been rolled out.
+	object := "instancegroup/" + groupName
+	since := 0
+	for i, e := range entries {
+		if e.Operation == audit.OperationRollingUpdate && (e.Object == object || e.Object == "cluster") {
+			since = i + 1
+		}
+	}
+
+	var clusterChanged bool
+	var groupChange *audit.Entry
+	for _, e := range entries[since:] {
+		if e.Diff == "" {
+			continue
+		}
+		switch e.Object {
+		case "cluster":
+			clusterChanged = true
+		case object:
+			if groupChange == nil {
+				groupChange = e
+			}
+		}
+	}
+
+	if clusterChanged {
+		result.Reason = "the cluster spec was changed since the last rolling update, so the change is not limited to the instance group"
+		result.ManualActions = append([]string{
+			fmt.Sprintf("kops get audit --name %s -o yaml", clusterName),
+			fmt.Sprintf("kops edit cluster %s", clusterName),
+		}, updateCommands...)
+		return result, nil
+	}
+
+	if groupChange == nil {
+		result.Reason = fmt.Sprintf("instance group %q was not changed since the last rolling update, so the failure has another cause", groupName)
+		return result, nil
+	}
+
+	if groupChange.Operation == audit.OperationCreate || groupChange.Previous == "" {
+		result.Reason = fmt.Sprintf("the version of instance group %q before %s was not recorded", groupName, groupChange.Timestamp.Format("2006-01-02 15:04:05"))
+		result.ManualActions = append([]string{fmt.Sprintf("kops edit instancegroup %s --name %s", groupName, clusterName)}, updateCommands...)
+		return result, nil
+	}
+
+	obj, _, err := kopscodecs.ParseVersionedYaml([]byte(groupChange.Previous))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing previous version of instance group %q: %v", groupName, err)
+	}
+	previous, ok := obj.(*api.InstanceGroup)
+	if !ok {
+		return nil, fmt.Errorf("previous version of instance group %q was unexpected type %T", groupName, obj)
+	}
+
+	current, err := clientset.InstanceGroupsFor(cluster).Get(groupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error reading instance group %q: %v", groupName, err)
+	}
+	if current == nil {
+		return nil, fmt.Errorf("instance group %q not found", groupName)
+	}
+
+	previous.ObjectMeta.ResourceVersion = current.ObjectMeta.ResourceVersion
+	updated, err := clientset.InstanceGroupsFor(cluster).Update(previous)
+	if err != nil {
+		return nil, fmt.Errorf("error reverting instance group %q: %v", groupName, err)
+	}
+
+	result.Reverted = true
+	result.Before = current
+	result.After = updated
+	result.Reason = fmt.Sprintf("instance group %q was reverted to its version before the %s at %s", groupName, groupChange.Operation, groupChange.Timestamp.Format("2006-01-02 15:04:05"))
+	result.ManualActions = updateCommands
+	return result, nil
+}
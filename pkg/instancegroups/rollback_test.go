@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func testRollbackInstanceGroup(machineType string) *api.InstanceGroup {
+	return &api.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: api.InstanceGroupSpec{
+			Role:        api.InstanceGroupRoleNode,
+			MachineType: machineType,
+			MinSize:     fi.Int32(2),
+			MaxSize:     fi.Int32(2),
+		},
+	}
+}
+
+func TestRollbackInstanceGroup(t *testing.T) {
+	cluster := &api.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "rollback.example.com"}}
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	grid := []struct {
+		name     string
+		entries  func(before, after *api.InstanceGroup) []*audit.Entry
+		reverted bool
+	}{
+		{
+			name: "instance group edited since rolling update",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationRollingUpdate, Object: "cluster"},
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+				}
+			},
+			reverted: true,
+		},
+		{
+			name: "instance group edited before rolling update",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+					{Operation: audit.OperationRollingUpdate, Object: "cluster"},
+				}
+			},
+		},
+		{
+			name: "instance group edited, then rolled",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+					{Operation: audit.OperationRollingUpdate, Object: "instancegroup/nodes"},
+				}
+			},
+		},
+		{
+			name: "another instance group rolled since the edit",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationRollingUpdate, Object: "instancegroup/nodes"},
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+					{Operation: audit.OperationRollingUpdate, Object: "instancegroup/other"},
+				}
+			},
+			reverted: true,
+		},
+		{
+			name: "cluster edited before the instance group was last rolled",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationEdit, Object: "cluster", Diff: "-a\n+b\n"},
+					{Operation: audit.OperationRollingUpdate, Object: "instancegroup/nodes"},
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+				}
+			},
+			reverted: true,
+		},
+		{
+			name: "cluster edited since only another instance group was rolled",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationEdit, Object: "cluster", Diff: "-a\n+b\n"},
+					{Operation: audit.OperationRollingUpdate, Object: "instancegroup/other"},
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+				}
+			},
+		},
+		{
+			name: "cluster edited as well",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationEdit, Object: "cluster", Diff: "-a\n+b\n"},
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after), Previous: auditTestSnapshot(t, before)},
+				}
+			},
+		},
+		{
+			name: "previous version not recorded",
+			entries: func(before, after *api.InstanceGroup) []*audit.Entry {
+				return []*audit.Entry{
+					{Operation: audit.OperationEdit, Object: "instancegroup/nodes", Diff: auditTestDiff(t, before, after)},
+				}
+			},
+		},
+	}
+
+	for _, g := range grid {
+		base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+		clientset := vfsclientset.NewVFSClientset(base, true)
+		log := audit.NewLog(base)
+
+		before := testRollbackInstanceGroup("m4.large")
+		after := testRollbackInstanceGroup("t2.nano")
+		if _, err := clientset.InstanceGroupsFor(cluster).Create(after); err != nil {
+			t.Fatalf("%s: error creating instance group: %v", g.name, err)
+		}
+
+		for i, e := range g.entries(before, after) {
+			e.Cluster = cluster.ObjectMeta.Name
+			e.Timestamp = start.Add(time.Duration(i) * time.Minute)
+			if err := log.Record(e); err != nil {
+				t.Fatalf("%s: error recording audit entry: %v", g.name, err)
+			}
+		}
+
+		result, err := RollbackInstanceGroup(clientset, log, cluster, "nodes")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", g.name, err)
+			continue
+		}
+		if result.Reverted != g.reverted {
+			t.Errorf("%s: expected reverted=%v, got %v (%s)", g.name, g.reverted, result.Reverted, result.Reason)
+		}
+
+		ig, err := clientset.InstanceGroupsFor(cluster).Get("nodes", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: error reading instance group: %v", g.name, err)
+		}
+		expected := "t2.nano"
+		if g.reverted {
+			expected = "m4.large"
+			if len(result.ManualActions) != 2 {
+				t.Errorf("%s: expected update and rolling-update actions, got %v", g.name, result.ManualActions)
+			}
+		}
+		if ig.Spec.MachineType != expected {
+			t.Errorf("%s: expected machine type %q, got %q", g.name, expected, ig.Spec.MachineType)
+		}
+	}
+}
+
+func auditTestDiff(t *testing.T, before, after *api.InstanceGroup) string {
+	d, err := audit.Diff(before, after)
+	if err != nil {
+		t.Fatalf("error building diff: %v", err)
+	}
+	return d
+}
+
+func auditTestSnapshot(t *testing.T, o *api.InstanceGroup) string {
+	s, err := audit.Snapshot(o)
+	if err != nil {
+		t.Fatalf("error serializing: %v", err)
+	}
+	return s
+}
@@ -18,6 +18,7 @@ package instancegroups
 
 import (
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -25,7 +26,10 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/validation"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -54,8 +58,123 @@ type RollingUpdateCluster struct {
 	// PostDrainDelay is the duration we wait after draining each node
 	PostDrainDelay time.Duration
 
+	// DrainTimeout is the maximum time to wait for each node to drain; zero means no limit
+	DrainTimeout time.Duration
+
+	// PodEvictionGracePeriod overrides the termination grace period of the pods evicted by a drain; zero uses each pod's own grace period
+	PodEvictionGracePeriod time.Duration
+
+	// SkipPodsWithLocalStorage leaves pods with emptyDir volumes running when draining, instead of evicting them
+	SkipPodsWithLocalStorage bool
+
+	// WaitForReschedule waits, after draining a node and before deleting it, until the evicted pods have been
+	// replaced by ready pods on other nodes
+	WaitForReschedule bool
+
+	// RescheduleTimeout is the maximum time to wait for the evicted pods to be ready elsewhere, with WaitForReschedule
+	RescheduleTimeout time.Duration
+
+	// RollbackOnFailure stops the rolling update when the cluster fails validation after replacing instances,
+	// so that the caller can roll back the instance group returned by FailedValidationGroup
+	RollbackOnFailure bool
+
+	// ValidationFailureThreshold is the number of consecutive failed validations after which, with RollbackOnFailure,
+	// we give up on the cluster validating without waiting for ValidationTimeout; zero waits for the full timeout
+	ValidationFailureThreshold int
+
+	// EtcdQuorumCheck refuses to terminate a master or etcd instance if an etcd member is unhealthy, or if etcd
+	// would lose quorum without it
+	EtcdQuorumCheck bool
+
+	// APIProbeSuccesses is the number of consecutive successful apiserver probes required after replacing a master
+	// or apiserver instance, before moving on; zero disables the probe
+	APIProbeSuccesses int
+
+	// APIProbeInterval is the interval between apiserver probes; DefaultAPIProbeInterval if zero
+	APIProbeInterval time.Duration
+
 	// ValidationTimeout is the maximum time to wait for the cluster to validate, once we start validation
 	ValidationTimeout time.Duration
+
+	// ValidateConditions are the node conditions, other than Ready, that fail validation when they are true
+	ValidateConditions []string
+
+	// ValidatePodsNamespace is the namespace of the pods selected by ValidatePodsSelectors; all namespaces if empty
+	ValidatePodsNamespace string
+
+	// ValidatePodsSelectors are label selectors for additional pods which must be ready for validation to pass
+	ValidatePodsSelectors []string
+
+	// Strategy is how instances are replaced; see RollingUpdateStrategyReplace, RollingUpdateStrategyNative
+	// and RollingUpdateStrategyDuplicate
+	Strategy string
+
+	// Clientset is used to create and delete instance groups, with the duplicate strategy
+	Clientset simple.Clientset
+
+	// ApplyCluster creates the cloud resources for the instance groups, as "kops update cluster --yes" does;
+	// it is required by the duplicate strategy
+	ApplyCluster func(cluster *api.Cluster, instanceGroups []*api.InstanceGroup) error
+
+	// AuditLog records the instance groups created and deleted by the duplicate strategy, if set
+	AuditLog *audit.Log
+
+	// MaxSurge is the number of instances the cloud may create above the group's target size, with the native strategy
+	MaxSurge int
+
+	// MaxUnavailable is the number of instances the cloud may take down at once, with the native strategy
+	MaxUnavailable int
+
+	// Out is where the output of draining nodes is written; stdout and stderr if nil
+	Out io.Writer
+
+	// DetectClusterAutoscaler coordinates with cluster-autoscaler, if it is deployed in the cluster:
+	// the minimum size of each node group is raised for the duration of its update, and nodes that are not
+	// being replaced are protected from scale-down until the rolling update completes.
+	DetectClusterAutoscaler bool
+
+	// clusterAutoscaler is set if we detected cluster-autoscaler
+	clusterAutoscaler bool
+
+	protectedNodesMutex sync.Mutex
+	// protectedNodes are the nodes on which we have disabled cluster-autoscaler scale-down
+	protectedNodes []string
+
+	failedValidationMutex sync.Mutex
+	// failedValidationGroup is the first instance group after whose replacement the cluster failed validation
+	failedValidationGroup string
+}
+
+// recordValidationFailure records that the cluster failed validation after replacing instances in the named group
+func (c *RollingUpdateCluster) recordValidationFailure(groupName string) {
+	c.failedValidationMutex.Lock()
+	defer c.failedValidationMutex.Unlock()
+
+	if c.failedValidationGroup == "" {
+		c.failedValidationGroup = groupName
+	}
+}
+
+// FailedValidationGroup returns the name of the instance group after whose replacement the cluster failed validation,
+// or "" if validation did not fail
+func (c *RollingUpdateCluster) FailedValidationGroup() string {
+	c.failedValidationMutex.Lock()
+	defer c.failedValidationMutex.Unlock()
+
+	return c.failedValidationGroup
+}
+
+// validationOptions returns the options for validating the cluster between instance replacements
+func (c *RollingUpdateCluster) validationOptions() *validation.ValidationOptions {
+	conditions := c.ValidateConditions
+	if conditions == nil {
+		conditions = validation.DefaultNodeConditions
+	}
+	return &validation.ValidationOptions{
+		NodeConditions: conditions,
+		PodNamespace:   c.ValidatePodsNamespace,
+		PodSelectors:   c.ValidatePodsSelectors,
+	}
 }
 
 // RollingUpdate performs a rolling update on a K8s Cluster.
@@ -65,10 +184,23 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 		return nil
 	}
 
+	if c.DetectClusterAutoscaler && !c.CloudOnly && c.K8sClient != nil {
+		found, err := detectClusterAutoscaler(c.K8sClient)
+		if err != nil {
+			glog.Warningf("Unable to detect cluster-autoscaler, not coordinating with it: %v", err)
+		} else if found {
+			glog.Infof("Detected cluster-autoscaler; node groups will not be scaled down during the rolling update")
+			c.clusterAutoscaler = true
+			defer c.unprotectNodes()
+		}
+	}
+
 	var resultsMutex sync.Mutex
 	results := make(map[string]error)
 
 	masterGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	etcdGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	apiServerGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
 	nodeGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
 	bastionGroups := make(map[string]*cloudinstances.CloudInstanceGroup)
 	for k, group := range groups {
@@ -79,6 +211,10 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 			masterGroups[k] = group
 		case api.InstanceGroupRoleBastion:
 			bastionGroups[k] = group
+		case api.InstanceGroupRoleEtcd:
+			etcdGroups[k] = group
+		case api.InstanceGroupRoleAPIServer:
+			apiServerGroups[k] = group
 		default:
 			return fmt.Errorf("unknown group type for group %q", group.InstanceGroup.ObjectMeta.Name)
 		}
@@ -118,6 +254,25 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 		}
 	}
 
+	// Upgrade dedicated etcd members before the masters
+	{
+		// As with masters, we replace etcd members strictly one at a time across all etcd
+		// instance groups, validating in between, so that we never take down more than one
+		// member and lose quorum.
+
+		for _, group := range etcdGroups {
+			g, err := NewRollingUpdateInstanceGroup(c.Cloud, group)
+			if err == nil {
+				err = g.RollingUpdate(c, cluster, instanceGroups, false, c.MasterInterval, c.ValidationTimeout)
+			}
+
+			// Do not continue update if an etcd member failed, etcd may be close to losing quorum
+			if err != nil {
+				return fmt.Errorf("etcd not healthy after update, stopping rolling-update: %q", err)
+			}
+		}
+	}
+
 	// Upgrade masters next
 	{
 		// We run master nodes in series, even if they are in separate instance groups
@@ -137,6 +292,30 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 		}
 	}
 
+	// Upgrade the apiserver-only instances after the masters, so they never run a newer apiserver than the masters
+	{
+		// These sit behind the API load balancer alongside the masters, so we also replace them in series
+		// to keep the remaining apiservers able to absorb the load.
+
+		for _, group := range apiServerGroups {
+			g, err := NewRollingUpdateInstanceGroup(c.Cloud, group)
+			if err == nil {
+				err = g.RollingUpdate(c, cluster, instanceGroups, false, c.MasterInterval, c.ValidationTimeout)
+			}
+
+			if err != nil {
+				return fmt.Errorf("apiserver not healthy after update, stopping rolling-update: %q", err)
+			}
+		}
+	}
+
+	// Nodes replaced with an external cloud-controller-manager need it to be running on the updated masters
+	if c.nodesNeedUpdate(nodeGroups) {
+		if err := c.waitForCloudControllerManager(cluster, c.ValidationTimeout); err != nil {
+			return fmt.Errorf("not updating nodes: %v", err)
+		}
+	}
+
 	// Upgrade nodes, with greater parallelism
 	{
 		var wg sync.WaitGroup
@@ -168,7 +347,11 @@ func (c *RollingUpdateCluster) RollingUpdate(groups map[string]*cloudinstances.C
 				results[k] = err
 				resultsMutex.Unlock()
 
-				// TODO: Bail on error?
+				// When rolling back, stop before replacing any more nodes
+				// TODO: Bail on error otherwise?
+				if err != nil && c.RollbackOnFailure {
+					break
+				}
 			}
 		}()
 
@@ -23,9 +23,11 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
 	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/kops/cloudmock/aws/mockautoscaling"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
@@ -679,3 +681,286 @@ func TestRollingUpdateUnknownRole(t *testing.T) {
 		}
 	}
 }
+
+func TestRollingUpdateEtcdGroup(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset()
+
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	cluster := &kopsapi.Cluster{}
+	cluster.Name = "test.k8s.local"
+
+	c := &RollingUpdateCluster{
+		Cloud:           mockcloud,
+		MasterInterval:  1 * time.Millisecond,
+		NodeInterval:    1 * time.Millisecond,
+		BastionInterval: 1 * time.Millisecond,
+		Force:           false,
+		K8sClient:       k8sClient,
+	}
+
+	cloud := c.Cloud.(awsup.AWSCloud)
+	setUpCloud(c)
+
+	cloud.Autoscaling().CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String("etcd-1"),
+		MinSize:              aws.Int64(1),
+		MaxSize:              aws.Int64(5),
+	})
+
+	cloud.Autoscaling().AttachInstances(&autoscaling.AttachInstancesInput{
+		AutoScalingGroupName: aws.String("etcd-1"),
+		InstanceIds:          []*string{aws.String("etcd-1a"), aws.String("etcd-1b")},
+	})
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+
+	// etcd members do not register as kubernetes nodes
+	groups["etcd-1"] = &cloudinstances.CloudInstanceGroup{
+		InstanceGroup: &kopsapi.InstanceGroup{
+			ObjectMeta: v1meta.ObjectMeta{
+				Name: "etcd-1",
+			},
+			Spec: kopsapi.InstanceGroupSpec{
+				Role: kopsapi.InstanceGroupRoleEtcd,
+			},
+		},
+		Ready: []*cloudinstances.CloudInstanceGroupMember{
+			{ID: "etcd-1a"},
+			{ID: "etcd-1b"},
+		},
+		NeedUpdate: []*cloudinstances.CloudInstanceGroupMember{
+			{ID: "etcd-1a"},
+			{ID: "etcd-1b"},
+		},
+	}
+
+	err := c.RollingUpdate(groups, cluster, &kopsapi.InstanceGroupList{})
+	if err != nil {
+		t.Errorf("Error on rolling update: %v", err)
+	}
+
+	asgGroups, _ := cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String("etcd-1")},
+	})
+	for _, group := range asgGroups.AutoScalingGroups {
+		if len(group.Instances) != 0 {
+			t.Errorf("Expected all etcd instances to be terminated, got: %v", len(group.Instances))
+		}
+	}
+}
+
+func TestRollingUpdateAPIServerGroup(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset()
+
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	cluster := &kopsapi.Cluster{}
+	cluster.Name = "test.k8s.local"
+
+	c := &RollingUpdateCluster{
+		Cloud:           mockcloud,
+		MasterInterval:  1 * time.Millisecond,
+		NodeInterval:    1 * time.Millisecond,
+		BastionInterval: 1 * time.Millisecond,
+		Force:           false,
+		K8sClient:       k8sClient,
+	}
+
+	cloud := c.Cloud.(awsup.AWSCloud)
+	setUpCloud(c)
+
+	cloud.Autoscaling().CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String("apiserver-1"),
+		MinSize:              aws.Int64(1),
+		MaxSize:              aws.Int64(5),
+	})
+
+	cloud.Autoscaling().AttachInstances(&autoscaling.AttachInstancesInput{
+		AutoScalingGroupName: aws.String("apiserver-1"),
+		InstanceIds:          []*string{aws.String("apiserver-1a"), aws.String("apiserver-1b")},
+	})
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+
+	// apiserver instances that have not registered are terminated without draining
+	groups["apiserver-1"] = &cloudinstances.CloudInstanceGroup{
+		InstanceGroup: &kopsapi.InstanceGroup{
+			ObjectMeta: v1meta.ObjectMeta{
+				Name: "apiserver-1",
+			},
+			Spec: kopsapi.InstanceGroupSpec{
+				Role: kopsapi.InstanceGroupRoleAPIServer,
+			},
+		},
+		Ready: []*cloudinstances.CloudInstanceGroupMember{
+			{ID: "apiserver-1a"},
+			{ID: "apiserver-1b"},
+		},
+		NeedUpdate: []*cloudinstances.CloudInstanceGroupMember{
+			{ID: "apiserver-1a"},
+			{ID: "apiserver-1b"},
+		},
+	}
+
+	err := c.RollingUpdate(groups, cluster, &kopsapi.InstanceGroupList{})
+	if err != nil {
+		t.Errorf("Error on rolling update: %v", err)
+	}
+
+	asgGroups, _ := cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String("apiserver-1")},
+	})
+	for _, group := range asgGroups.AutoScalingGroups {
+		if len(group.Instances) != 0 {
+			t.Errorf("Expected all apiserver instances to be terminated, got: %v", len(group.Instances))
+		}
+	}
+}
+
+func TestRollingUpdateWithClusterAutoscaler(t *testing.T) {
+	nodeLabels := map[string]string{kopsapi.NodeLabelInstanceGroup: "node-1"}
+	k8sClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: v1meta.ObjectMeta{Name: "cluster-autoscaler", Namespace: "kube-system"}},
+		&v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node-1a", Labels: nodeLabels}},
+		&v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node-1b", Labels: nodeLabels}},
+		&v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node-1c", Labels: nodeLabels}},
+	)
+
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	c := &RollingUpdateCluster{
+		Cloud:                   mockcloud,
+		MasterInterval:          1 * time.Millisecond,
+		NodeInterval:            1 * time.Millisecond,
+		BastionInterval:         1 * time.Millisecond,
+		K8sClient:               k8sClient,
+		DetectClusterAutoscaler: true,
+	}
+	cloud := c.Cloud.(awsup.AWSCloud)
+	setUpCloud(c)
+
+	asgGroups, _ := cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String("node-1")},
+	})
+
+	group := &cloudinstances.CloudInstanceGroup{
+		HumanName: "node-1",
+		InstanceGroup: &kopsapi.InstanceGroup{
+			ObjectMeta: v1meta.ObjectMeta{
+				Name: "node-1",
+			},
+			Spec: kopsapi.InstanceGroupSpec{
+				Role: kopsapi.InstanceGroupRoleNode,
+			},
+		},
+		NeedUpdate: []*cloudinstances.CloudInstanceGroupMember{
+			{
+				ID:   "node-1a",
+				Node: &v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node-1a"}},
+			},
+			{
+				ID:   "node-1b",
+				Node: &v1.Node{ObjectMeta: v1meta.ObjectMeta{Name: "node-1b"}},
+			},
+		},
+		MinSize: 1,
+		MaxSize: 5,
+		Raw:     asgGroups.AutoScalingGroups[0],
+	}
+
+	cluster := &kopsapi.Cluster{}
+	cluster.Name = "test.k8s.local"
+
+	err := c.RollingUpdate(map[string]*cloudinstances.CloudInstanceGroup{"node-1": group}, cluster, &kopsapi.InstanceGroupList{})
+	if err != nil {
+		t.Errorf("Error on rolling update: %v", err)
+	}
+
+	// The minimum size is raised during the update (see TestRaiseMinSize), and must be restored
+	asgGroups, _ = cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String("node-1")},
+	})
+	if minSize := aws.Int64Value(asgGroups.AutoScalingGroups[0].MinSize); minSize != 1 {
+		t.Errorf("Expected minimum size to be restored to 1, got %d", minSize)
+	}
+
+	// The node that was not replaced must have been protected during the update, and released afterwards
+	protected := false
+	for _, action := range k8sClient.Actions() {
+		update, ok := action.(k8stesting.UpdateAction)
+		if !ok {
+			continue
+		}
+		node, ok := update.GetObject().(*v1.Node)
+		if !ok {
+			continue
+		}
+		if node.Name != "node-1c" && node.Annotations[ScaleDownDisabledAnnotation] != "" {
+			t.Errorf("Unexpected protection of replaced node %q", node.Name)
+		}
+		if node.Name == "node-1c" && node.Annotations[ScaleDownDisabledAnnotation] == "true" {
+			protected = true
+		}
+	}
+	if !protected {
+		t.Errorf("Expected node-1c to be protected from scale-down")
+	}
+
+	node, err := k8sClient.CoreV1().Nodes().Get("node-1c", v1meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting node: %v", err)
+	}
+	if _, found := node.Annotations[ScaleDownDisabledAnnotation]; found {
+		t.Errorf("Expected scale-down protection to be removed from node-1c")
+	}
+}
+
+func TestRaiseMinSize(t *testing.T) {
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	c := &RollingUpdateCluster{Cloud: mockcloud}
+	setUpCloud(c)
+
+	minSize := func() int64 {
+		asgGroups, _ := mockcloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: []*string{aws.String("node-2")},
+		})
+		return aws.Int64Value(asgGroups.AutoScalingGroups[0].MinSize)
+	}
+
+	asgGroups, _ := mockcloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String("node-2")},
+	})
+	group := &cloudinstances.CloudInstanceGroup{
+		HumanName:     "node-2",
+		InstanceGroup: &kopsapi.InstanceGroup{},
+		Ready:         []*cloudinstances.CloudInstanceGroupMember{{ID: "node-2a"}},
+		NeedUpdate:    []*cloudinstances.CloudInstanceGroupMember{{ID: "node-2b"}},
+		MinSize:       1,
+		MaxSize:       5,
+		Raw:           asgGroups.AutoScalingGroups[0],
+	}
+
+	r, err := NewRollingUpdateInstanceGroup(mockcloud, group)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restore, err := r.raiseMinSize()
+	if err != nil {
+		t.Fatalf("unexpected error raising minimum size: %v", err)
+	}
+	if minSize() != 2 {
+		t.Errorf("Expected minimum size to be raised to 2, got %d", minSize())
+	}
+
+	restore()
+	if minSize() != 1 {
+		t.Errorf("Expected minimum size to be restored to 1, got %d", minSize())
+	}
+}
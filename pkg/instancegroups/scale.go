@@ -0,0 +1,49 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// GroupSizeSetter is implemented by clouds that can resize a cloud group directly
+type GroupSizeSetter interface {
+	SetGroupSize(group *cloudinstances.CloudInstanceGroup, minSize int, maxSize int, desiredCapacity *int) error
+}
+
+// ScaleCloudGroup resizes a cloud group to the specified sizes, without a full cluster update.
+// The desired capacity is left to the cloud if desiredCapacity is nil.
+func ScaleCloudGroup(cloud fi.Cloud, group *cloudinstances.CloudInstanceGroup, minSize int, maxSize int, desiredCapacity *int) error {
+	if minSize > maxSize {
+		return fmt.Errorf("minimum size %d is greater than maximum size %d", minSize, maxSize)
+	}
+	if desiredCapacity != nil && (*desiredCapacity < minSize || *desiredCapacity > maxSize) {
+		return fmt.Errorf("desired capacity %d must be between %d and %d", *desiredCapacity, minSize, maxSize)
+	}
+
+	setter, ok := cloud.(GroupSizeSetter)
+	if !ok {
+		return fmt.Errorf("cloud provider %q does not support resizing groups directly, use \"kops update cluster\" instead", cloud.ProviderID())
+	}
+
+	glog.Infof("Resizing %q to min=%d max=%d", group.HumanName, minSize, maxSize)
+	return setter.SetGroupSize(group, minSize, maxSize, desiredCapacity)
+}
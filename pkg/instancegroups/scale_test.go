@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func TestScaleCloudGroup(t *testing.T) {
+	mockcloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	mockcloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	c := &RollingUpdateCluster{Cloud: mockcloud}
+	setUpCloud(c)
+
+	describe := func() *autoscaling.Group {
+		asgGroups, _ := mockcloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: []*string{aws.String("node-1")},
+		})
+		return asgGroups.AutoScalingGroups[0]
+	}
+
+	group := &cloudinstances.CloudInstanceGroup{
+		HumanName:     "node-1",
+		InstanceGroup: &kopsapi.InstanceGroup{},
+		MinSize:       1,
+		MaxSize:       5,
+		Raw:           describe(),
+	}
+
+	if err := ScaleCloudGroup(mockcloud, group, 5, 3, nil); err == nil {
+		t.Errorf("expected error when minimum size is greater than maximum size")
+	}
+	if err := ScaleCloudGroup(mockcloud, group, 2, 3, fi.Int(4)); err == nil {
+		t.Errorf("expected error when desired capacity is greater than maximum size")
+	}
+
+	if err := ScaleCloudGroup(mockcloud, group, 3, 10, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	asg := describe()
+	if aws.Int64Value(asg.MinSize) != 3 || aws.Int64Value(asg.MaxSize) != 10 {
+		t.Errorf("expected min=3 max=10, got min=%d max=%d", aws.Int64Value(asg.MinSize), aws.Int64Value(asg.MaxSize))
+	}
+	if asg.DesiredCapacity != nil {
+		t.Errorf("expected desired capacity to be unchanged, got %d", aws.Int64Value(asg.DesiredCapacity))
+	}
+	if group.MinSize != 3 || group.MaxSize != 10 {
+		t.Errorf("expected group sizes to be updated, got min=%d max=%d", group.MinSize, group.MaxSize)
+	}
+
+	if err := ScaleCloudGroup(mockcloud, group, 3, 10, fi.Int(6)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if aws.Int64Value(describe().DesiredCapacity) != 6 {
+		t.Errorf("expected desired capacity 6, got %d", aws.Int64Value(describe().DesiredCapacity))
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"fmt"
+	"strings"
+
+	nodeauthorizer "k8s.io/kops/node-authorizer/pkg/authorizers/aws"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	// awsTagClusterName is the tag kops puts on every autoscaling group of the cluster
+	awsTagClusterName = "KubernetesCluster"
+	// awsTagNodeRole is the tag kops puts on the autoscaling groups of node instance groups
+	awsTagNodeRole = "k8s.io/role/node"
+	// awsTagAutoscalingGroupName is set by AWS on the instances an autoscaling group launches, and cannot be set by users
+	awsTagAutoscalingGroupName = "aws:autoscaling:groupName"
+	// awsTagNodeLabelPrefix prefixes the tags carrying the node labels of the instance group
+	awsTagNodeLabelPrefix = "k8s.io/cluster-autoscaler/node-template/label/"
+	// awsTagLaunchTemplateID and awsTagLaunchTemplateVersion are set by EC2 on the instances launched from a launch
+	// template, and cannot be set by users
+	awsTagLaunchTemplateID      = "aws:ec2launchtemplate:id"
+	awsTagLaunchTemplateVersion = "aws:ec2launchtemplate:version"
+	// awsTagDirectInstanceGroup is set by kops on the instances of directly managed instance groups
+	awsTagDirectInstanceGroup = "kops.k8s.io/direct-instancegroup"
+)
+
+// AWSIdentifier verifies the identity of nodes and looks up their labels from the EC2 API
+type AWSIdentifier struct {
+	client      ec2iface.EC2API
+	autoscaling autoscalingiface.AutoScalingAPI
+	clusterName string
+	accountID   string
+	vpcID       string
+}
+
+var _ Verifier = &AWSIdentifier{}
+var _ LabelSource = &AWSIdentifier{}
+
+// NewAWSIdentifier creates an AWSIdentifier for the cluster, using the account and VPC of the instance we are running on
+func NewAWSIdentifier(clusterName string) (*AWSIdentifier, error) {
+	if err := nodeauthorizer.GetPublicCertificates(); err != nil {
+		return nil, fmt.Errorf("error loading AWS public certificates: %v", err)
+	}
+
+	document, err := ec2metadata.New(session.New()).GetInstanceIdentityDocument()
+	if err != nil {
+		return nil, fmt.Errorf("error getting instance identity document: %v", err)
+	}
+
+	config := &aws.Config{Region: aws.String(document.Region)}
+	client := ec2.New(session.New(config))
+
+	instance, err := describeInstance(client, document.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AWSIdentifier{
+		client:      client,
+		autoscaling: autoscaling.New(session.New(config)),
+		clusterName: clusterName,
+		accountID:   document.AccountID,
+		vpcID:       aws.StringValue(instance.VpcId),
+	}, nil
+}
+
+// VerifyIdentity checks the signed instance identity document belongs to a running node of the cluster, calling from its own address
+func (a *AWSIdentifier) VerifyIdentity(identityDocument []byte, remoteAddr string) (string, error) {
+	document := &ec2metadata.EC2InstanceIdentityDocument{}
+	verified, err := nodeauthorizer.VerifyIdentityDocument(identityDocument, document)
+	if err != nil {
+		return "", fmt.Errorf("error verifying identity document: %v", err)
+	}
+	if !verified {
+		return "", fmt.Errorf("identity document signature is invalid")
+	}
+
+	return a.verifyInstance(document, remoteAddr)
+}
+
+// verifyInstance checks the instance of a verified identity document is a node of the cluster, returning the node name
+func (a *AWSIdentifier) verifyInstance(document *ec2metadata.EC2InstanceIdentityDocument, remoteAddr string) (string, error) {
+	if document.AccountID != a.accountID {
+		return "", fmt.Errorf("instance %q is running in account %q", document.InstanceID, document.AccountID)
+	}
+
+	instance, err := describeInstance(a.client, document.InstanceID)
+	if err != nil {
+		return "", err
+	}
+	if instance.State == nil || aws.StringValue(instance.State.Name) != ec2.InstanceStateNameRunning {
+		return "", fmt.Errorf("instance %q is not running", document.InstanceID)
+	}
+	if aws.StringValue(instance.VpcId) != a.vpcID {
+		return "", fmt.Errorf("instance %q is not running in our VPC", document.InstanceID)
+	}
+	if aws.StringValue(instance.PrivateIpAddress) != remoteAddr {
+		return "", fmt.Errorf("instance %q has address %q, but the request came from %q", document.InstanceID, aws.StringValue(instance.PrivateIpAddress), remoteAddr)
+	}
+
+	// @note: we trust the autoscaling group, or for directly managed instance groups the launch template, rather
+	// than the tags of the instance, which anyone permitted to tag instances could change
+	tags, err := a.instanceGroupTags(instance)
+	if err != nil {
+		return "", err
+	}
+	if groupName := tagValue(instance.Tags, awsTagAutoscalingGroupName); groupName != "" {
+		if tags[awsTagNodeRole] != "1" {
+			return "", fmt.Errorf("autoscaling group %q is not a node instance group", groupName)
+		}
+	} else if tags[awsTagDirectInstanceGroup] == "" || tags[awsTagNodeRole] != "1" {
+		return "", fmt.Errorf("launch template %q does not launch the nodes of a directly managed instance group", tagValue(instance.Tags, awsTagLaunchTemplateID))
+	}
+
+	nodeName := aws.StringValue(instance.PrivateDnsName)
+	if nodeName == "" {
+		return "", fmt.Errorf("instance %q has no private dns name", document.InstanceID)
+	}
+
+	return nodeName, nil
+}
+
+// instanceGroupTags returns the tags of the instance group which launched the instance: those of its autoscaling
+// group, or of the launch template version it was launched from for directly managed instance groups.  Unlike the
+// tags of the instance itself, these cannot be changed by anyone permitted only to tag instances.
+func (a *AWSIdentifier) instanceGroupTags(instance *ec2.Instance) (map[string]string, error) {
+	instanceID := aws.StringValue(instance.InstanceId)
+	if groupName := tagValue(instance.Tags, awsTagAutoscalingGroupName); groupName != "" {
+		return a.autoscalingGroupTags(groupName, instanceID)
+	}
+	if templateID := tagValue(instance.Tags, awsTagLaunchTemplateID); templateID != "" {
+		return a.launchTemplateTags(templateID, tagValue(instance.Tags, awsTagLaunchTemplateVersion))
+	}
+
+	return nil, fmt.Errorf("instance %q was not launched by an autoscaling group or launch template", instanceID)
+}
+
+// autoscalingGroupTags checks the autoscaling group is part of the cluster and contains the instance, returning its tags
+func (a *AWSIdentifier) autoscalingGroupTags(groupName string, instanceID string) (map[string]string, error) {
+	resp, err := a.autoscaling.DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String(groupName)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing autoscaling group %q: %v", groupName, err)
+	}
+	if len(resp.AutoScalingGroups) != 1 {
+		return nil, fmt.Errorf("autoscaling group %q not found", groupName)
+	}
+	group := resp.AutoScalingGroups[0]
+
+	tags := make(map[string]string)
+	for _, tag := range group.Tags {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	if tags[awsTagClusterName] != a.clusterName {
+		return nil, fmt.Errorf("autoscaling group %q is not part of cluster %q", groupName, a.clusterName)
+	}
+
+	for _, instance := range group.Instances {
+		if aws.StringValue(instance.InstanceId) == instanceID {
+			return tags, nil
+		}
+	}
+
+	return nil, fmt.Errorf("instance %q is not a member of autoscaling group %q", instanceID, groupName)
+}
+
+// launchTemplateTags checks the launch template belongs to the cluster, returning the tags which the version the
+// instance was launched from puts on instances
+func (a *AWSIdentifier) launchTemplateTags(templateID string, version string) (map[string]string, error) {
+	templates, err := a.client.DescribeLaunchTemplates(&ec2.DescribeLaunchTemplatesInput{
+		LaunchTemplateIds: []*string{aws.String(templateID)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing launch template %q: %v", templateID, err)
+	}
+	if len(templates.LaunchTemplates) != 1 {
+		return nil, fmt.Errorf("launch template %q not found", templateID)
+	}
+	if tagValue(templates.LaunchTemplates[0].Tags, awsTagClusterName) != a.clusterName {
+		return nil, fmt.Errorf("launch template %q is not part of cluster %q", templateID, a.clusterName)
+	}
+
+	if version == "" {
+		return nil, fmt.Errorf("instance was launched from an unknown version of launch template %q", templateID)
+	}
+	versions, err := a.client.DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String(templateID),
+		Versions:         []*string{aws.String(version)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing launch template %q version %s: %v", templateID, version, err)
+	}
+	if len(versions.LaunchTemplateVersions) != 1 || versions.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return nil, fmt.Errorf("launch template %q version %s not found", templateID, version)
+	}
+
+	tags := make(map[string]string)
+	for _, spec := range versions.LaunchTemplateVersions[0].LaunchTemplateData.TagSpecifications {
+		if aws.StringValue(spec.ResourceType) == ec2.ResourceTypeInstance {
+			for _, tag := range spec.Tags {
+				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+	}
+
+	return tags, nil
+}
+
+// NodeLabels returns the labels of the instance group the node was launched by
+func (a *AWSIdentifier) NodeLabels(node *v1.Node) (map[string]string, error) {
+	// the provider id is of the form aws:///<zone>/<instance-id>
+	tokens := strings.Split(node.Spec.ProviderID, "/")
+	instanceID := tokens[len(tokens)-1]
+	if !strings.HasPrefix(node.Spec.ProviderID, "aws://") || instanceID == "" {
+		return nil, nil
+	}
+
+	instance, err := describeInstance(a.client, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tags of the instance itself are not trusted, or anyone permitted to tag it could choose the node's labels
+	tags, err := a.instanceGroupTags(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]string)
+	for key, value := range tags {
+		if strings.HasPrefix(key, awsTagNodeLabelPrefix) {
+			labels[strings.TrimPrefix(key, awsTagNodeLabelPrefix)] = value
+		}
+	}
+
+	return labels, nil
+}
+
+// describeInstance returns the instance with the given id
+func describeInstance(client ec2iface.EC2API, instanceID string) (*ec2.Instance, error) {
+	resp, err := client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(instanceID)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing instance %q: %v", instanceID, err)
+	}
+	if len(resp.Reservations) != 1 || len(resp.Reservations[0].Instances) != 1 {
+		return nil, fmt.Errorf("instance %q not found", instanceID)
+	}
+
+	return resp.Reservations[0].Instances[0], nil
+}
+
+// tagValue returns the value of the tag, or an empty string
+func tagValue(tags []*ec2.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key {
+			return aws.StringValue(tag.Value)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"k8s.io/api/core/v1"
+)
+
+// fakeEC2 returns the instances and launch templates it holds
+type fakeEC2 struct {
+	ec2iface.EC2API
+	instances map[string]*ec2.Instance
+	templates map[string]*ec2.LaunchTemplate
+	// versions holds the data of the launch template versions, by template id and version
+	versions map[string]*ec2.ResponseLaunchTemplateData
+}
+
+func (f *fakeEC2) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	instance := f.instances[aws.StringValue(input.InstanceIds[0])]
+	if instance == nil {
+		return nil, fmt.Errorf("InvalidInstanceID.NotFound")
+	}
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: []*ec2.Instance{instance}}},
+	}, nil
+}
+
+func (f *fakeEC2) DescribeLaunchTemplates(input *ec2.DescribeLaunchTemplatesInput) (*ec2.DescribeLaunchTemplatesOutput, error) {
+	output := &ec2.DescribeLaunchTemplatesOutput{}
+	if template := f.templates[aws.StringValue(input.LaunchTemplateIds[0])]; template != nil {
+		output.LaunchTemplates = append(output.LaunchTemplates, template)
+	}
+	return output, nil
+}
+
+func (f *fakeEC2) DescribeLaunchTemplateVersions(input *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	output := &ec2.DescribeLaunchTemplateVersionsOutput{}
+	if data := f.versions[aws.StringValue(input.LaunchTemplateId)+":"+aws.StringValue(input.Versions[0])]; data != nil {
+		output.LaunchTemplateVersions = append(output.LaunchTemplateVersions, &ec2.LaunchTemplateVersion{LaunchTemplateData: data})
+	}
+	return output, nil
+}
+
+func newTestInstance(id, ip, group string) *ec2.Instance {
+	return &ec2.Instance{
+		InstanceId:       aws.String(id),
+		PrivateDnsName:   aws.String("ip-" + strings.Replace(ip, ".", "-", -1) + ".ec2.internal"),
+		PrivateIpAddress: aws.String(ip),
+		State:            &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+		VpcId:            aws.String("vpc-1"),
+		Tags:             []*ec2.Tag{{Key: aws.String(awsTagAutoscalingGroupName), Value: aws.String(group)}},
+	}
+}
+
+func TestAWSIdentifierVerifyInstance(t *testing.T) {
+	asg := &mockautoscaling.MockAutoscaling{}
+	for _, g := range []struct {
+		name string
+		tags map[string]string
+	}{
+		{name: "nodes.example.com", tags: map[string]string{awsTagClusterName: "example.com", awsTagNodeRole: "1"}},
+		{name: "master-us-test-1a.masters.example.com", tags: map[string]string{awsTagClusterName: "example.com", "k8s.io/role/master": "1"}},
+		{name: "nodes.other.com", tags: map[string]string{awsTagClusterName: "other.com", awsTagNodeRole: "1"}},
+	} {
+		input := &autoscaling.CreateAutoScalingGroupInput{AutoScalingGroupName: aws.String(g.name)}
+		for k, v := range g.tags {
+			input.Tags = append(input.Tags, &autoscaling.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		if _, err := asg.CreateAutoScalingGroup(input); err != nil {
+			t.Fatalf("error creating autoscaling group: %v", err)
+		}
+	}
+	for id, group := range map[string]string{"i-node": "nodes.example.com", "i-master": "master-us-test-1a.masters.example.com", "i-other": "nodes.other.com"} {
+		if _, err := asg.AttachInstances(&autoscaling.AttachInstancesInput{AutoScalingGroupName: aws.String(group), InstanceIds: []*string{aws.String(id)}}); err != nil {
+			t.Fatalf("error attaching instance: %v", err)
+		}
+	}
+
+	stopped := newTestInstance("i-stopped", "10.0.1.14", "nodes.example.com")
+	stopped.State.Name = aws.String(ec2.InstanceStateNameStopped)
+	untagged := newTestInstance("i-untagged", "10.0.1.15", "")
+	untagged.Tags = nil
+
+	a := &AWSIdentifier{
+		client: &fakeEC2{instances: map[string]*ec2.Instance{
+			"i-node":     newTestInstance("i-node", "10.0.1.10", "nodes.example.com"),
+			"i-master":   newTestInstance("i-master", "10.0.1.11", "master-us-test-1a.masters.example.com"),
+			"i-other":    newTestInstance("i-other", "10.0.1.12", "nodes.other.com"),
+			"i-detached": newTestInstance("i-detached", "10.0.1.13", "nodes.example.com"),
+			"i-stopped":  stopped,
+			"i-untagged": untagged,
+		}},
+		autoscaling: asg,
+		clusterName: "example.com",
+		accountID:   "123456789012",
+		vpcID:       "vpc-1",
+	}
+
+	grid := []struct {
+		Instance   string
+		Account    string
+		RemoteAddr string
+		Expected   string
+		Error      string
+	}{
+		{Instance: "i-node", RemoteAddr: "10.0.1.10", Expected: "ip-10-0-1-10.ec2.internal"},
+		{Instance: "i-node", RemoteAddr: "10.0.1.99", Error: "but the request came from"},
+		{Instance: "i-node", Account: "999999999999", RemoteAddr: "10.0.1.10", Error: "running in account"},
+		{Instance: "i-master", RemoteAddr: "10.0.1.11", Error: "is not a node instance group"},
+		{Instance: "i-other", RemoteAddr: "10.0.1.12", Error: "is not part of cluster"},
+		{Instance: "i-detached", RemoteAddr: "10.0.1.13", Error: "is not a member of autoscaling group"},
+		{Instance: "i-stopped", RemoteAddr: "10.0.1.14", Error: "is not running"},
+		{Instance: "i-untagged", RemoteAddr: "10.0.1.15", Error: "not launched by an autoscaling group or launch template"},
+		{Instance: "i-missing", RemoteAddr: "10.0.1.16", Error: "error describing instance"},
+	}
+	for _, g := range grid {
+		account := g.Account
+		if account == "" {
+			account = "123456789012"
+		}
+		document := &ec2metadata.EC2InstanceIdentityDocument{InstanceID: g.Instance, AccountID: account}
+
+		nodeName, err := a.verifyInstance(document, g.RemoteAddr)
+		if g.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), g.Error) {
+				t.Errorf("%s: expected error containing %q, got %v", g.Instance, g.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", g.Instance, err)
+			continue
+		}
+		if nodeName != g.Expected {
+			t.Errorf("%s: expected node name %q, got %q", g.Instance, g.Expected, nodeName)
+		}
+	}
+}
+
+// newTestDirectInstance returns an instance launched from a launch template, as directly managed instance groups are
+func newTestDirectInstance(id, ip, templateID, version string) *ec2.Instance {
+	instance := newTestInstance(id, ip, "")
+	instance.Tags = []*ec2.Tag{
+		{Key: aws.String(awsTagLaunchTemplateID), Value: aws.String(templateID)},
+		{Key: aws.String(awsTagLaunchTemplateVersion), Value: aws.String(version)},
+	}
+	return instance
+}
+
+func newTestLaunchTemplateData(tags map[string]string) *ec2.ResponseLaunchTemplateData {
+	spec := &ec2.LaunchTemplateTagSpecification{ResourceType: aws.String(ec2.ResourceTypeInstance)}
+	for k, v := range tags {
+		spec.Tags = append(spec.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	return &ec2.ResponseLaunchTemplateData{TagSpecifications: []*ec2.LaunchTemplateTagSpecification{spec}}
+}
+
+func TestAWSIdentifierVerifyDirectInstance(t *testing.T) {
+	clusterTags := []*ec2.Tag{{Key: aws.String(awsTagClusterName), Value: aws.String("example.com")}}
+	otherTags := []*ec2.Tag{{Key: aws.String(awsTagClusterName), Value: aws.String("other.com")}}
+	directTags := map[string]string{awsTagDirectInstanceGroup: "direct", awsTagNodeRole: "1"}
+
+	a := &AWSIdentifier{
+		client: &fakeEC2{
+			instances: map[string]*ec2.Instance{
+				"i-direct":    newTestDirectInstance("i-direct", "10.0.1.10", "lt-direct", "2"),
+				"i-master":    newTestDirectInstance("i-master", "10.0.1.11", "lt-master", "1"),
+				"i-other":     newTestDirectInstance("i-other", "10.0.1.12", "lt-other", "1"),
+				"i-old":       newTestDirectInstance("i-old", "10.0.1.13", "lt-direct", "1"),
+				"i-missing":   newTestDirectInstance("i-missing", "10.0.1.14", "lt-missing", "1"),
+				"i-noversion": newTestDirectInstance("i-noversion", "10.0.1.15", "lt-direct", ""),
+			},
+			templates: map[string]*ec2.LaunchTemplate{
+				"lt-direct": {LaunchTemplateId: aws.String("lt-direct"), Tags: clusterTags},
+				"lt-master": {LaunchTemplateId: aws.String("lt-master"), Tags: clusterTags},
+				"lt-other":  {LaunchTemplateId: aws.String("lt-other"), Tags: otherTags},
+			},
+			versions: map[string]*ec2.ResponseLaunchTemplateData{
+				"lt-direct:1": newTestLaunchTemplateData(map[string]string{awsTagNodeRole: "1"}),
+				"lt-direct:2": newTestLaunchTemplateData(directTags),
+				"lt-master:1": newTestLaunchTemplateData(map[string]string{awsTagDirectInstanceGroup: "master", "k8s.io/role/master": "1"}),
+				"lt-other:1":  newTestLaunchTemplateData(directTags),
+			},
+		},
+		autoscaling: &mockautoscaling.MockAutoscaling{},
+		clusterName: "example.com",
+		accountID:   "123456789012",
+		vpcID:       "vpc-1",
+	}
+
+	grid := []struct {
+		Instance   string
+		RemoteAddr string
+		Expected   string
+		Error      string
+	}{
+		{Instance: "i-direct", RemoteAddr: "10.0.1.10", Expected: "ip-10-0-1-10.ec2.internal"},
+		{Instance: "i-master", RemoteAddr: "10.0.1.11", Error: "does not launch the nodes of a directly managed instance group"},
+		{Instance: "i-other", RemoteAddr: "10.0.1.12", Error: "is not part of cluster"},
+		{Instance: "i-old", RemoteAddr: "10.0.1.13", Error: "does not launch the nodes of a directly managed instance group"},
+		{Instance: "i-missing", RemoteAddr: "10.0.1.14", Error: "launch template \"lt-missing\" not found"},
+		{Instance: "i-noversion", RemoteAddr: "10.0.1.15", Error: "unknown version"},
+	}
+	for _, g := range grid {
+		document := &ec2metadata.EC2InstanceIdentityDocument{InstanceID: g.Instance, AccountID: "123456789012"}
+
+		nodeName, err := a.verifyInstance(document, g.RemoteAddr)
+		if g.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), g.Error) {
+				t.Errorf("%s: expected error containing %q, got %v", g.Instance, g.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", g.Instance, err)
+			continue
+		}
+		if nodeName != g.Expected {
+			t.Errorf("%s: expected node name %q, got %q", g.Instance, g.Expected, nodeName)
+		}
+	}
+}
+
+func TestAWSIdentifierNodeLabels(t *testing.T) {
+	asg := &mockautoscaling.MockAutoscaling{}
+	if _, err := asg.CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String("nodes.example.com"),
+		Tags: []*autoscaling.Tag{
+			{Key: aws.String(awsTagClusterName), Value: aws.String("example.com")},
+			{Key: aws.String(awsTagNodeRole), Value: aws.String("1")},
+			{Key: aws.String(awsTagNodeLabelPrefix + "kops.k8s.io/instancegroup"), Value: aws.String("nodes")},
+		},
+	}); err != nil {
+		t.Fatalf("error creating autoscaling group: %v", err)
+	}
+	if _, err := asg.AttachInstances(&autoscaling.AttachInstancesInput{AutoScalingGroupName: aws.String("nodes.example.com"), InstanceIds: []*string{aws.String("i-node")}}); err != nil {
+		t.Fatalf("error attaching instance: %v", err)
+	}
+
+	// labels tagged onto the instances themselves must be ignored
+	forged := &ec2.Tag{Key: aws.String(awsTagNodeLabelPrefix + "node-role.kubernetes.io/master"), Value: aws.String("")}
+	node := newTestInstance("i-node", "10.0.1.10", "nodes.example.com")
+	node.Tags = append(node.Tags, forged)
+	direct := newTestDirectInstance("i-direct", "10.0.1.11", "lt-direct", "1")
+	direct.Tags = append(direct.Tags, forged)
+	untagged := newTestInstance("i-untagged", "10.0.1.12", "")
+	untagged.Tags = []*ec2.Tag{forged}
+
+	a := &AWSIdentifier{
+		client: &fakeEC2{
+			instances: map[string]*ec2.Instance{"i-node": node, "i-direct": direct, "i-untagged": untagged},
+			templates: map[string]*ec2.LaunchTemplate{
+				"lt-direct": {LaunchTemplateId: aws.String("lt-direct"), Tags: []*ec2.Tag{{Key: aws.String(awsTagClusterName), Value: aws.String("example.com")}}},
+			},
+			versions: map[string]*ec2.ResponseLaunchTemplateData{
+				"lt-direct:1": newTestLaunchTemplateData(map[string]string{
+					awsTagDirectInstanceGroup: "direct",
+					awsTagNodeRole:            "1",
+					awsTagNodeLabelPrefix + "kops.k8s.io/instancegroup": "direct",
+				}),
+			},
+		},
+		autoscaling: asg,
+		clusterName: "example.com",
+	}
+
+	grid := []struct {
+		ProviderID string
+		Expected   map[string]string
+		Error      string
+	}{
+		{ProviderID: "aws:///us-test-1a/i-node", Expected: map[string]string{"kops.k8s.io/instancegroup": "nodes"}},
+		{ProviderID: "aws:///us-test-1a/i-direct", Expected: map[string]string{"kops.k8s.io/instancegroup": "direct"}},
+		{ProviderID: "aws:///us-test-1a/i-untagged", Error: "not launched by an autoscaling group or launch template"},
+		{ProviderID: "gce://project/zone/node"},
+	}
+	for _, g := range grid {
+		labels, err := a.NodeLabels(&v1.Node{Spec: v1.NodeSpec{ProviderID: g.ProviderID}})
+		if g.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), g.Error) {
+				t.Errorf("%s: expected error containing %q, got %v", g.ProviderID, g.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", g.ProviderID, err)
+			continue
+		}
+		if !reflect.DeepEqual(labels, g.Expected) {
+			t.Errorf("%s: expected labels %v, got %v", g.ProviderID, g.Expected, labels)
+		}
+	}
+}
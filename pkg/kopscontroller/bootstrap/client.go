@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// IdentityFunc returns the signed identity document of the instance we are running on
+type IdentityFunc func() ([]byte, error)
+
+// Client requests kubelet client certificates from the kops-controller
+type Client struct {
+	// Server is the base url of the kops-controller, e.g. https://kops-controller.internal.example.com:3988
+	Server string
+	// CA is the PEM encoded certificate authority which signed the kops-controller's serving certificate
+	CA []byte
+	// Identity returns the document the kops-controller verifies the node with
+	Identity IdentityFunc
+}
+
+// Bootstrap proves our identity to the kops-controller and returns a certificate issued for the public key
+func (c *Client) Bootstrap(publicKey crypto.PublicKey) (*Response, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(c.CA) {
+		return nil, fmt.Errorf("no certificates found in the certificate authority")
+	}
+	hc := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+
+	document, err := c.Identity()
+	if err != nil {
+		return nil, fmt.Errorf("error getting instance identity document: %v", err)
+	}
+
+	keyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding public key: %v", err)
+	}
+
+	request := &Request{
+		IdentityDocument: document,
+		PublicKey:        string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: keyBytes})),
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding bootstrap request: %v", err)
+	}
+
+	url := strings.TrimSuffix(c.Server, "/") + Path
+	resp, err := hc.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error calling kops-controller %q: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from kops-controller: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kops-controller refused bootstrap request (%s): %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	response := &Response{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("error decoding response from kops-controller: %v", err)
+	}
+
+	return response, nil
+}
+
+// AWSIdentityDocument returns the pkcs7 signature of the instance identity document from the metadata service
+func AWSIdentityDocument() ([]byte, error) {
+	client := ec2metadata.New(session.New())
+
+	signature, err := client.GetDynamicData("/instance-identity/pkcs7")
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(signature), nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+const (
+	// Path is the path the kops-controller serves bootstrap requests on
+	Path = "/bootstrap"
+)
+
+// Request is sent by a node to the kops-controller to obtain a kubelet client certificate
+type Request struct {
+	// IdentityDocument is the signed proof of the node's identity, e.g. the pkcs7 signature of the AWS instance identity document
+	IdentityDocument []byte `json:"identityDocument"`
+	// PublicKey is the PEM encoded public key the certificate is issued for; the private key never leaves the node
+	PublicKey string `json:"publicKey"`
+}
+
+// Response is returned by the kops-controller once it has verified the identity of a node
+type Response struct {
+	// NodeName is the name the certificate was issued for, i.e. the node authenticates as system:node:<NodeName>
+	NodeName string `json:"nodeName"`
+	// Certificate is the PEM encoded kubelet client certificate
+	Certificate string `json:"certificate"`
+}
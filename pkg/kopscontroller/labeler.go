@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LabelSource looks up the labels a node should carry
+type LabelSource interface {
+	// NodeLabels returns the labels for the node, or nil if it is not known to the source
+	NodeLabels(node *v1.Node) (map[string]string, error)
+}
+
+// NodeLabeler applies the labels of their instance group to nodes, rather than trusting the kubelets to set them
+type NodeLabeler struct {
+	// Client is the kubernetes client
+	Client kubernetes.Interface
+	// Source looks up the labels of the nodes
+	Source LabelSource
+	// Interval is the time between reconciliations
+	Interval time.Duration
+
+	// labelled is the set of nodes we have already labelled; the labels of an instance never change
+	labelled map[types.UID]bool
+}
+
+// Run reconciles the node labels until the stop channel is closed
+func (l *NodeLabeler) Run(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := l.reconcile(); err != nil {
+			glog.Warningf("error labelling nodes: %v", err)
+		}
+	}, l.Interval, stopCh)
+}
+
+// reconcile adds any missing labels to the nodes we have not yet labelled
+func (l *NodeLabeler) reconcile() error {
+	if l.labelled == nil {
+		l.labelled = make(map[types.UID]bool)
+	}
+
+	nodes, err := l.Client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	seen := make(map[types.UID]bool)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		seen[node.UID] = true
+		if l.labelled[node.UID] {
+			continue
+		}
+
+		labels, err := l.Source.NodeLabels(node)
+		if err != nil {
+			glog.Warningf("error getting labels for node %q: %v", node.Name, err)
+			continue
+		}
+
+		changed := false
+		for k, v := range labels {
+			if node.Labels[k] != v {
+				if node.Labels == nil {
+					node.Labels = make(map[string]string)
+				}
+				node.Labels[k] = v
+				changed = true
+			}
+		}
+
+		if changed {
+			glog.Infof("labelling node %q", node.Name)
+			if _, err := l.Client.CoreV1().Nodes().Update(node); err != nil {
+				glog.Warningf("error labelling node %q: %v", node.Name, err)
+				continue
+			}
+		}
+		l.labelled[node.UID] = true
+	}
+
+	// forget the nodes which have been deleted
+	for uid := range l.labelled {
+		if !seen[uid] {
+			delete(l.labelled, uid)
+		}
+	}
+
+	return nil
+}
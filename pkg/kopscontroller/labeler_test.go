@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeLabelSource struct {
+	labels map[string]map[string]string
+	calls  int
+}
+
+func (f *fakeLabelSource) NodeLabels(node *v1.Node) (map[string]string, error) {
+	f.calls++
+	return f.labels[node.Name], nil
+}
+
+func TestNodeLabelerAddsMissingLabels(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", UID: "a", Labels: map[string]string{"existing": "true"}}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", UID: "b"}},
+	)
+	source := &fakeLabelSource{
+		labels: map[string]map[string]string{
+			"node-a": {"kops.k8s.io/instancegroup": "nodes"},
+		},
+	}
+	l := &NodeLabeler{Client: client, Source: source}
+
+	if err := l.reconcile(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	node, err := client.CoreV1().Nodes().Get("node-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if node.Labels["kops.k8s.io/instancegroup"] != "nodes" || node.Labels["existing"] != "true" {
+		t.Errorf("unexpected labels %v", node.Labels)
+	}
+
+	if err := l.reconcile(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.calls != 2 {
+		t.Errorf("expected labelled nodes not to be looked up again, got %d lookups", source.calls)
+	}
+}
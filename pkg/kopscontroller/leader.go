@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// leaderAnnotation is the annotation on the lock ConfigMap which records the current leader
+	leaderAnnotation = "kops.k8s.io/leader"
+)
+
+// leaderRecord is the lease stored in the lock ConfigMap
+type leaderRecord struct {
+	HolderIdentity string    `json:"holderIdentity"`
+	RenewTime      time.Time `json:"renewTime"`
+}
+
+// LeaderElector holds a lease on a ConfigMap, so that only one of the kops-controllers runs the cluster wide loops
+type LeaderElector struct {
+	// Client is the kubernetes client
+	Client kubernetes.Interface
+	// Namespace and Name identify the lock ConfigMap
+	Namespace string
+	Name      string
+	// Identity is the unique name of this kops-controller
+	Identity string
+	// LeaseDuration is how long a lease is valid for without being renewed
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader keeps trying to renew its lease before it stops leading.  It must be shorter
+	// than LeaseDuration, so that we have stopped before another controller can take over the expired lease; if it is
+	// not, two thirds of LeaseDuration is used.
+	RenewDeadline time.Duration
+	// RetryPeriod is the time between attempts to acquire or renew the lease
+	RetryPeriod time.Duration
+
+	// now returns the current time, and is replaced in tests
+	now func() time.Time
+}
+
+// Run waits until we are the leader and then calls run, closing its stop channel if we lose the lease.
+// Like the client-go leader election, we stop leading once we have failed to renew the lease for RenewDeadline,
+// before the lease expires and another controller can become the leader.
+func (l *LeaderElector) Run(stopCh <-chan struct{}, run func(stopCh <-chan struct{})) {
+	renewDeadline := l.renewDeadline()
+
+	for {
+		// The lease runs from the time recorded in it, which is taken before the request is made
+		lastRenew := l.clock()
+		for !l.tryAcquireOrRenew() {
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(l.RetryPeriod):
+			}
+			lastRenew = l.clock()
+		}
+
+		glog.Infof("%s became the leader", l.Identity)
+		leadingCh := make(chan struct{})
+		doneCh := make(chan struct{})
+		go func() {
+			defer close(doneCh)
+			run(leadingCh)
+		}()
+
+		for leading := true; leading; {
+			select {
+			case <-stopCh:
+				close(leadingCh)
+				<-doneCh
+				return
+			case <-time.After(l.RetryPeriod):
+			}
+			attempt := l.clock()
+			if l.tryAcquireOrRenew() {
+				lastRenew = attempt
+			} else if l.clock().Sub(lastRenew) >= renewDeadline {
+				leading = false
+			}
+		}
+
+		glog.Warningf("%s lost the leader lease", l.Identity)
+		close(leadingCh)
+		<-doneCh
+	}
+}
+
+// renewDeadline returns RenewDeadline, or two thirds of LeaseDuration if RenewDeadline is not shorter than LeaseDuration
+func (l *LeaderElector) renewDeadline() time.Duration {
+	if l.RenewDeadline > 0 && l.RenewDeadline < l.LeaseDuration {
+		return l.RenewDeadline
+	}
+	d := l.LeaseDuration * 2 / 3
+	if l.RenewDeadline != 0 {
+		glog.Warningf("leader renew deadline %s is not shorter than the lease duration %s; using %s", l.RenewDeadline, l.LeaseDuration, d)
+	}
+	return d
+}
+
+// tryAcquireOrRenew takes or renews the lease, returning true if we hold it
+func (l *LeaderElector) tryAcquireOrRenew() bool {
+	acquired, err := l.acquireOrRenew()
+	if err != nil {
+		glog.Warningf("error acquiring leader lease %s/%s: %v", l.Namespace, l.Name, err)
+		return false
+	}
+	return acquired
+}
+
+func (l *LeaderElector) acquireOrRenew() (bool, error) {
+	now := l.clock()
+	record, err := json.Marshal(&leaderRecord{HolderIdentity: l.Identity, RenewTime: now})
+	if err != nil {
+		return false, err
+	}
+
+	configMaps := l.Client.CoreV1().ConfigMaps(l.Namespace)
+	lock, err := configMaps.Get(l.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		lock = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   l.Namespace,
+				Name:        l.Name,
+				Annotations: map[string]string{leaderAnnotation: string(record)},
+			},
+		}
+		if _, err := configMaps.Create(lock); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	if value := lock.Annotations[leaderAnnotation]; value != "" {
+		current := &leaderRecord{}
+		if err := json.Unmarshal([]byte(value), current); err != nil {
+			return false, fmt.Errorf("error parsing leader record %q: %v", value, err)
+		}
+		if current.HolderIdentity != l.Identity && now.Before(current.RenewTime.Add(l.LeaseDuration)) {
+			return false, nil
+		}
+	}
+
+	if lock.Annotations == nil {
+		lock.Annotations = make(map[string]string)
+	}
+	lock.Annotations[leaderAnnotation] = string(record)
+
+	// the update fails with a conflict if another controller changed the lock since we read it
+	if _, err := configMaps.Update(lock); err != nil {
+		if errors.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *LeaderElector) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestLeaderElectorLease(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	newElector := func(identity string) *LeaderElector {
+		return &LeaderElector{
+			Client:        client,
+			Namespace:     "kube-system",
+			Name:          "kops-controller-leader",
+			Identity:      identity,
+			LeaseDuration: 15 * time.Second,
+			now:           clock,
+		}
+	}
+	a := newElector("a")
+	b := newElector("b")
+
+	if !a.tryAcquireOrRenew() {
+		t.Fatalf("expected a to acquire the free lease")
+	}
+	if b.tryAcquireOrRenew() {
+		t.Fatalf("expected b not to acquire the lease held by a")
+	}
+
+	now = now.Add(10 * time.Second)
+	if !a.tryAcquireOrRenew() {
+		t.Fatalf("expected a to renew its lease")
+	}
+
+	now = now.Add(10 * time.Second)
+	if b.tryAcquireOrRenew() {
+		t.Fatalf("expected b not to acquire the renewed lease")
+	}
+
+	now = now.Add(10 * time.Second)
+	if !b.tryAcquireOrRenew() {
+		t.Fatalf("expected b to acquire the expired lease")
+	}
+	if a.tryAcquireOrRenew() {
+		t.Fatalf("expected a not to take back the lease held by b")
+	}
+}
+
+func TestLeaderElectorRenewDeadline(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	l := &LeaderElector{
+		Client:        client,
+		Namespace:     "kube-system",
+		Name:          "kops-controller-leader",
+		Identity:      "a",
+		LeaseDuration: time.Hour,
+		RenewDeadline: 50 * time.Millisecond,
+		RetryPeriod:   5 * time.Millisecond,
+	}
+
+	// Every renewal fails once we have taken the lease
+	client.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("apiserver unavailable")
+	})
+
+	stopCh := make(chan struct{})
+	leading := make(chan time.Time)
+	stopped := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		l.Run(stopCh, func(leadingCh <-chan struct{}) {
+			leading <- time.Now()
+			<-leadingCh
+			stopped <- time.Now()
+		})
+		close(done)
+	}()
+
+	var start time.Time
+	select {
+	case start = <-leading:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting to become the leader")
+	}
+
+	select {
+	case end := <-stopped:
+		if d := end.Sub(start); d < l.RenewDeadline {
+			t.Errorf("stopped leading after %s, before the renew deadline %s", d, l.RenewDeadline)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("still leading long after the renew deadline, though well within the lease")
+	}
+
+	close(stopCh)
+	<-done
+}
+
+func TestLeaderElectorRenewDeadlineDefault(t *testing.T) {
+	grid := []struct {
+		RenewDeadline time.Duration
+		Expected      time.Duration
+	}{
+		{RenewDeadline: 0, Expected: 20 * time.Second},
+		{RenewDeadline: 10 * time.Second, Expected: 10 * time.Second},
+		{RenewDeadline: 30 * time.Second, Expected: 20 * time.Second},
+		{RenewDeadline: time.Minute, Expected: 20 * time.Second},
+	}
+	for _, g := range grid {
+		l := &LeaderElector{LeaseDuration: 30 * time.Second, RenewDeadline: g.RenewDeadline}
+		if actual := l.renewDeadline(); actual != g.Expected {
+			t.Errorf("renew deadline %s: expected %s, got %s", g.RenewDeadline, g.Expected, actual)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"k8s.io/kops/pkg/kopscontroller/bootstrap"
+	"k8s.io/kops/pkg/pki"
+
+	"github.com/golang/glog"
+)
+
+// maxRequestSize bounds the bootstrap requests we are willing to read
+const maxRequestSize = 64 * 1024
+
+// Verifier checks the identity documents of nodes asking for kubelet certificates
+type Verifier interface {
+	// VerifyIdentity checks the document proves the caller at remoteAddr is a node of this cluster, returning the node name
+	VerifyIdentity(identityDocument []byte, remoteAddr string) (string, error)
+}
+
+// BootstrapServer issues kubelet client certificates to nodes which prove their identity
+type BootstrapServer struct {
+	// Verifier checks the identity of the nodes
+	Verifier Verifier
+	// CA is the cluster certificate authority the kubelet certificates are signed by
+	CA *pki.Certificate
+	// CAKey is the private key of the certificate authority
+	CAKey *pki.PrivateKey
+	// CertificateTTL is the lifetime of the issued certificates
+	CertificateTTL time.Duration
+}
+
+var _ http.Handler = &BootstrapServer{}
+
+// ServeHTTP handles a bootstrap request
+func (s *BootstrapServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != bootstrap.Path {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "error reading request", http.StatusBadRequest)
+		return
+	}
+	request := &bootstrap.Request{}
+	if err := json.Unmarshal(body, request); err != nil {
+		http.Error(w, "error decoding request", http.StatusBadRequest)
+		return
+	}
+	if len(request.IdentityDocument) == 0 {
+		http.Error(w, "identity document is required", http.StatusBadRequest)
+		return
+	}
+
+	remoteAddr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteAddr = r.RemoteAddr
+	}
+
+	// we deliberately do not tell the caller why it was refused
+	nodeName, err := s.Verifier.VerifyIdentity(request.IdentityDocument, remoteAddr)
+	if err != nil {
+		glog.Warningf("refusing bootstrap request from %s: %v", remoteAddr, err)
+		http.Error(w, "identity could not be verified", http.StatusForbidden)
+		return
+	}
+
+	certificate, err := s.issueCertificate(nodeName, request.PublicKey)
+	if err != nil {
+		glog.Warningf("error issuing certificate for node %q: %v", nodeName, err)
+		http.Error(w, "error issuing certificate", http.StatusBadRequest)
+		return
+	}
+
+	glog.Infof("issued kubelet certificate for node %q (%s)", nodeName, remoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&bootstrap.Response{NodeName: nodeName, Certificate: certificate}); err != nil {
+		glog.Warningf("error writing bootstrap response: %v", err)
+	}
+}
+
+// issueCertificate signs a kubelet client certificate for the node with the given public key
+func (s *BootstrapServer) issueCertificate(nodeName string, publicKeyPEM string) (string, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return "", fmt.Errorf("public key is not PEM encoded")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("error parsing public key: %v", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   "system:node:" + nodeName,
+			Organization: []string{"system:nodes"},
+		},
+		PublicKey:             publicKey,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(s.CertificateTTL),
+		SerialNumber:          pki.BuildPKISerial(now.UnixNano()),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	certificate, err := pki.SignNewCertificate(nil, template, s.CA.Certificate, s.CAKey)
+	if err != nil {
+		return "", err
+	}
+
+	return certificate.AsString()
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kopscontroller
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/kopscontroller/bootstrap"
+	"k8s.io/kops/pkg/pki"
+)
+
+// fakeVerifier accepts a single identity document, from a single address
+type fakeVerifier struct {
+	document   string
+	remoteAddr string
+	nodeName   string
+}
+
+func (f *fakeVerifier) VerifyIdentity(identityDocument []byte, remoteAddr string) (string, error) {
+	if string(identityDocument) != f.document || remoteAddr != f.remoteAddr {
+		return "", fmt.Errorf("unknown identity")
+	}
+	return f.nodeName, nil
+}
+
+func newTestBootstrapServer(t *testing.T) *BootstrapServer {
+	key, err := pki.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("error generating private key: %v", err)
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+	ca, err := pki.SignNewCertificate(key, template, nil, nil)
+	if err != nil {
+		t.Fatalf("error signing certificate authority: %v", err)
+	}
+
+	return &BootstrapServer{
+		Verifier:       &fakeVerifier{document: "valid", remoteAddr: "10.0.1.10", nodeName: "ip-10-0-1-10.ec2.internal"},
+		CA:             ca,
+		CAKey:          key,
+		CertificateTTL: time.Hour,
+	}
+}
+
+func newBootstrapRequest(t *testing.T, document string) ([]byte, *pki.PrivateKey) {
+	key, err := pki.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("error generating private key: %v", err)
+	}
+	keyBytes, err := x509.MarshalPKIXPublicKey(key.Key.(*rsa.PrivateKey).Public())
+	if err != nil {
+		t.Fatalf("error encoding public key: %v", err)
+	}
+	body, err := json.Marshal(&bootstrap.Request{
+		IdentityDocument: []byte(document),
+		PublicKey:        string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: keyBytes})),
+	})
+	if err != nil {
+		t.Fatalf("error encoding request: %v", err)
+	}
+	return body, key
+}
+
+func TestBootstrapServerIssuesCertificate(t *testing.T) {
+	s := newTestBootstrapServer(t)
+	body, key := newBootstrapRequest(t, "valid")
+
+	r := httptest.NewRequest(http.MethodPost, bootstrap.Path, bytes.NewReader(body))
+	r.RemoteAddr = "10.0.1.10:43210"
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	response := &bootstrap.Response{}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if response.NodeName != "ip-10-0-1-10.ec2.internal" {
+		t.Errorf("unexpected node name %q", response.NodeName)
+	}
+
+	certificate, err := pki.ParsePEMCertificate([]byte(response.Certificate))
+	if err != nil {
+		t.Fatalf("error parsing certificate: %v", err)
+	}
+	cert := certificate.Certificate
+	if cert.Subject.CommonName != "system:node:ip-10-0-1-10.ec2.internal" {
+		t.Errorf("unexpected common name %q", cert.Subject.CommonName)
+	}
+	if len(cert.Subject.Organization) != 1 || cert.Subject.Organization[0] != "system:nodes" {
+		t.Errorf("unexpected organization %v", cert.Subject.Organization)
+	}
+	if err := cert.CheckSignatureFrom(s.CA.Certificate); err != nil {
+		t.Errorf("certificate not signed by the CA: %v", err)
+	}
+	if cert.PublicKey.(*rsa.PublicKey).N.Cmp(key.Key.(*rsa.PrivateKey).N) != 0 {
+		t.Errorf("certificate not issued for the requested public key")
+	}
+	if cert.NotAfter.After(time.Now().Add(s.CertificateTTL)) {
+		t.Errorf("certificate expires after the ttl: %v", cert.NotAfter)
+	}
+}
+
+func TestBootstrapServerRefusesRequests(t *testing.T) {
+	s := newTestBootstrapServer(t)
+	valid, _ := newBootstrapRequest(t, "valid")
+	forged, _ := newBootstrapRequest(t, "forged")
+
+	grid := []struct {
+		Name       string
+		Method     string
+		Path       string
+		Body       []byte
+		RemoteAddr string
+		Expected   int
+	}{
+		{Name: "unverified identity", Method: http.MethodPost, Path: bootstrap.Path, Body: forged, RemoteAddr: "10.0.1.10:1", Expected: http.StatusForbidden},
+		{Name: "different address", Method: http.MethodPost, Path: bootstrap.Path, Body: valid, RemoteAddr: "10.0.1.11:1", Expected: http.StatusForbidden},
+		{Name: "bad request", Method: http.MethodPost, Path: bootstrap.Path, Body: []byte("{"), RemoteAddr: "10.0.1.10:1", Expected: http.StatusBadRequest},
+		{Name: "missing document", Method: http.MethodPost, Path: bootstrap.Path, Body: []byte("{}"), RemoteAddr: "10.0.1.10:1", Expected: http.StatusBadRequest},
+		{Name: "get", Method: http.MethodGet, Path: bootstrap.Path, RemoteAddr: "10.0.1.10:1", Expected: http.StatusMethodNotAllowed},
+		{Name: "unknown path", Method: http.MethodPost, Path: "/other", Body: valid, RemoteAddr: "10.0.1.10:1", Expected: http.StatusNotFound},
+	}
+	for _, g := range grid {
+		r := httptest.NewRequest(g.Method, g.Path, bytes.NewReader(g.Body))
+		r.RemoteAddr = g.RemoteAddr
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != g.Expected {
+			t.Errorf("%s: expected status %d, got %d", g.Name, g.Expected, w.Code)
+		}
+	}
+}
@@ -53,6 +53,8 @@ type KubectlUserWithName struct {
 type KubectlUser struct {
 	ClientCertificateData []byte `json:"client-certificate-data,omitempty"`
 	ClientKeyData         []byte `json:"client-key-data,omitempty"`
+	ClientCertificate     string `json:"client-certificate,omitempty"`
+	ClientKey             string `json:"client-key,omitempty"`
 	Password              string `json:"password,omitempty"`
 	Username              string `json:"username,omitempty"`
 	Token                 string `json:"token,omitempty"`
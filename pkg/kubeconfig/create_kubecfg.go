@@ -49,8 +49,13 @@ func BuildKubecfg(cluster *kops.Cluster, keyStore fi.Keystore, secretStore fi.Se
 	// We differentiate using the heuristic that if we have an internal ELB
 	// we are likely connected directly to the VPC.
 	privateDNS := cluster.Spec.Topology != nil && cluster.Spec.Topology.DNS.Type == kops.DNSTypePrivate
+	if cluster.Spec.DNSZoneOptions != nil && cluster.Spec.DNSZoneOptions.Private {
+		privateDNS = true
+	}
 	internalELB := cluster.Spec.API != nil && cluster.Spec.API.LoadBalancer != nil && cluster.Spec.API.LoadBalancer.Type == kops.LoadBalancerTypeInternal
-	if privateDNS && !internalELB {
+	// With split-horizon DNS, the API name is also published in the public zone
+	splitHorizon := cluster.Spec.DNSZoneOptions != nil && cluster.Spec.DNSZoneOptions.SplitHorizon
+	if privateDNS && !internalELB && !splitHorizon {
 		useELBName = true
 	}
 
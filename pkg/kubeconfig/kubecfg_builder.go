@@ -18,6 +18,7 @@ package kubeconfig
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/golang/glog"
 	"k8s.io/client-go/rest"
@@ -99,8 +100,8 @@ func (c *KubeconfigBuilder) BuildRestConfig() (*rest.Config, error) {
 	return restConfig, nil
 }
 
-// Write out a new kubeconfig
-func (b *KubeconfigBuilder) WriteKubecfg() error {
+// Write out a new kubeconfig, reporting the context that was set to out
+func (b *KubeconfigBuilder) WriteKubecfg(out io.Writer) error {
 	config, err := b.configAccess.GetStartingConfig()
 	if err != nil {
 		return fmt.Errorf("error reading kubeconfig: %v", err)
@@ -204,6 +205,6 @@ func (b *KubeconfigBuilder) WriteKubecfg() error {
 		return err
 	}
 
-	fmt.Printf("kops has set your kubectl context to %s\n", b.Context)
+	fmt.Fprintf(out, "kops has set your kubectl context to %s\n", b.Context)
 	return nil
 }
@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
 	"k8s.io/kops/upup/pkg/fi/fitasks"
@@ -158,6 +159,7 @@ func (b *APILoadBalancerBuilder) Build(c *fi.ModelBuilderContext) error {
 			RemoveExtraRules: []string{"port=443"},
 		}
 		t.Tags = b.CloudTags(*t.Name, false)
+		model.UseSecurityGroupOverride(t, lbSpec.SecurityGroupOverride)
 		c.AddTask(t)
 	}
 
@@ -235,7 +237,9 @@ func (b *APILoadBalancerBuilder) Build(c *fi.ModelBuilderContext) error {
 		masterKeypair.AlternateNameTasks = append(masterKeypair.AlternateNameTasks, elb)
 	}
 
-	for _, ig := range b.MasterInstanceGroups() {
+	// apiserver-only instances serve the API alongside the masters
+	igs := append(b.MasterInstanceGroups(), b.APIServerInstanceGroups()...)
+	for _, ig := range igs {
 		t := &awstasks.LoadBalancerAttachment{
 			Name:      s("api-" + ig.ObjectMeta.Name),
 			Lifecycle: b.Lifecycle,
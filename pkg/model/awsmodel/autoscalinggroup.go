@@ -18,14 +18,17 @@ package awsmodel
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/golang/glog"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/pkg/model/defaults"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
 
 const (
@@ -46,134 +49,45 @@ type AutoscalingGroupModelBuilder struct {
 var _ fi.ModelBuilder = &AutoscalingGroupModelBuilder{}
 
 func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
-	var err error
 	for _, ig := range b.InstanceGroups {
 		name := b.AutoscalingGroupName(ig)
 
-		// LaunchConfiguration
-		var launchConfiguration *awstasks.LaunchConfiguration
-		{
-			volumeSize := fi.Int32Value(ig.Spec.RootVolumeSize)
-			if volumeSize == 0 {
-				volumeSize, err = defaults.DefaultInstanceGroupVolumeSize(ig.Spec.Role)
-				if err != nil {
-					return err
-				}
-			}
-
-			volumeType := fi.StringValue(ig.Spec.RootVolumeType)
-			if volumeType == "" {
-				volumeType = DefaultVolumeType
-			}
+		placementGroup := b.buildPlacementGroup(c, name, ig)
 
-			volumeIops := fi.Int32Value(ig.Spec.RootVolumeIops)
-			if volumeIops <= 0 {
-				volumeIops = DefaultVolumeIops
+		if ig.IsDirectlyManaged() {
+			if err := b.buildDirectInstanceGroup(c, name, ig, placementGroup); err != nil {
+				return err
 			}
+			continue
+		}
 
-			link, err := b.LinkToIAMInstanceProfile(ig)
+		// LaunchConfiguration or LaunchTemplate
+		// Spot instance groups keep using launch configurations, as the autoscaling group cannot request spot
+		// instances through a launch template
+		var launchConfiguration *awstasks.LaunchConfiguration
+		var launchTemplate *awstasks.LaunchTemplate
+		if featureflag.EnableLaunchTemplates.Enabled() && fi.StringValue(ig.Spec.MaxPrice) == "" {
+			// The autoscaling group propagates its tags to the instances, but not to their volumes
+			instanceTags, err := b.CloudTagsForInstanceGroup(ig)
 			if err != nil {
-				return fmt.Errorf("unable to find iam profile link for instance group %q: %v", ig.ObjectMeta.Name, err)
-			}
-
-			t := &awstasks.LaunchConfiguration{
-				Name:      s(name),
-				Lifecycle: b.Lifecycle,
-
-				SecurityGroups: []*awstasks.SecurityGroup{
-					b.LinkToSecurityGroup(ig.Spec.Role),
-				},
-				IAMInstanceProfile: link,
-				ImageID:            s(ig.Spec.Image),
-				InstanceType:       s(ig.Spec.MachineType),
-				InstanceMonitoring: ig.Spec.DetailedInstanceMonitoring,
-
-				RootVolumeSize:         i64(int64(volumeSize)),
-				RootVolumeType:         s(volumeType),
-				RootVolumeOptimization: ig.Spec.RootVolumeOptimization,
+				return fmt.Errorf("error building cloud tags: %v", err)
 			}
-
-			if volumeType == "io1" {
-				t.RootVolumeIops = i64(int64(volumeIops))
+			for k, v := range b.CloudTags(name, false) {
+				instanceTags[k] = v
 			}
 
-			if ig.Spec.Tenancy != "" {
-				t.Tenancy = s(ig.Spec.Tenancy)
-			}
-
-			for _, id := range ig.Spec.AdditionalSecurityGroups {
-				sgTask := &awstasks.SecurityGroup{
-					Name:   fi.String(id),
-					ID:     fi.String(id),
-					Shared: fi.Bool(true),
-
-					Lifecycle: b.SecurityLifecycle,
-				}
-				if err := c.EnsureTask(sgTask); err != nil {
-					return err
-				}
-				t.SecurityGroups = append(t.SecurityGroups, sgTask)
-			}
-
-			if t.SSHKey, err = b.LinkToSSHKey(); err != nil {
+			launchTemplate, err = b.buildLaunchTemplate(c, name, ig, instanceTags)
+			if err != nil {
 				return err
 			}
-
-			if t.UserData, err = b.BootstrapScript.ResourceNodeUp(ig, b.Cluster); err != nil {
+			c.AddTask(launchTemplate)
+		} else {
+			var err error
+			launchConfiguration, err = b.buildLaunchConfiguration(c, name, ig)
+			if err != nil {
 				return err
 			}
-
-			if fi.StringValue(ig.Spec.MaxPrice) != "" {
-				spotPrice := fi.StringValue(ig.Spec.MaxPrice)
-				t.SpotPrice = spotPrice
-			}
-
-			{
-				// TODO: Wrapper / helper class to analyze clusters
-				subnetMap := make(map[string]*kops.ClusterSubnetSpec)
-				for i := range b.Cluster.Spec.Subnets {
-					subnet := &b.Cluster.Spec.Subnets[i]
-					subnetMap[subnet.Name] = subnet
-				}
-
-				var subnetType kops.SubnetType
-				for _, subnetName := range ig.Spec.Subnets {
-					subnet := subnetMap[subnetName]
-					if subnet == nil {
-						return fmt.Errorf("InstanceGroup %q uses subnet %q that does not exist", ig.ObjectMeta.Name, subnetName)
-					}
-					if subnetType != "" && subnetType != subnet.Type {
-						return fmt.Errorf("InstanceGroup %q cannot be in subnets of different Type", ig.ObjectMeta.Name)
-					}
-					subnetType = subnet.Type
-				}
-
-				associatePublicIP := true
-				switch subnetType {
-				case kops.SubnetTypePublic, kops.SubnetTypeUtility:
-					associatePublicIP = true
-					if ig.Spec.AssociatePublicIP != nil {
-						associatePublicIP = *ig.Spec.AssociatePublicIP
-					}
-
-				case kops.SubnetTypePrivate:
-					associatePublicIP = false
-					if ig.Spec.AssociatePublicIP != nil {
-						// This isn't meaningful - private subnets can't have public ip
-						//associatePublicIP = *ig.Spec.AssociatePublicIP
-						if *ig.Spec.AssociatePublicIP {
-							glog.Warningf("Ignoring AssociatePublicIP=true for private InstanceGroup %q", ig.ObjectMeta.Name)
-						}
-					}
-
-				default:
-					return fmt.Errorf("unknown subnet type %q", subnetType)
-				}
-				t.AssociatePublicIP = &associatePublicIP
-			}
-			c.AddTask(t)
-
-			launchConfiguration = t
+			c.AddTask(launchConfiguration)
 		}
 
 		// AutoscalingGroup
@@ -195,6 +109,8 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 				},
 
 				LaunchConfiguration: launchConfiguration,
+				LaunchTemplate:      launchTemplate,
+				PlacementGroup:      placementGroup,
 			}
 
 			minSize := int32(1)
@@ -230,6 +146,38 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			}
 			t.Tags = tags
 
+			// Record the configuration the instances are launched with, so that rolling-update can tell which
+			// instances need replacing, and why
+			if launchConfiguration != nil {
+				hash, err := instanceConfigHash(launchConfiguration.ImageID, launchConfiguration.InstanceType, launchConfiguration.UserData, launchConfiguration.SecurityGroups, tags)
+				if err != nil {
+					return err
+				}
+				t.Tags[awsup.TagNameInstanceConfigHash] = hash
+			} else {
+				hash, err := instanceConfigHash(launchTemplate.ImageID, launchTemplate.InstanceType, launchTemplate.UserData, launchTemplate.SecurityGroups, launchTemplate.InstanceTags)
+				if err != nil {
+					return err
+				}
+				t.Tags[awsup.TagNameInstanceConfigHash] = hash
+			}
+
+			// Instances launched from a launch configuration only get the tags propagated by the autoscaling group,
+			// so we record them to find the instances that need replacing when the tags change
+			if launchConfiguration != nil {
+				t.Tags[awsup.TagNameInstanceTagsHash] = awsup.InstanceTagsHash(tags)
+			}
+
+			// Record the external load balancers we attach, so that we can detach them when they are removed from the spec
+			for _, lb := range ig.Spec.ExternalLoadBalancers {
+				if lb.LoadBalancerName != nil {
+					t.Tags[awsup.TagNameExternalLoadBalancerPrefix+*lb.LoadBalancerName] = *lb.LoadBalancerName
+				}
+				if lb.TargetGroupARN != nil {
+					t.Tags[awsup.TagNameExternalTargetGroupPrefix+targetGroupNameFromARN(*lb.TargetGroupARN)] = *lb.TargetGroupARN
+				}
+			}
+
 			processes := []string{}
 			for _, p := range ig.Spec.SuspendProcesses {
 				processes = append(processes, p)
@@ -269,3 +217,340 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 
 	return nil
 }
+
+// instanceConfigHash returns the hash of the configuration the instances of an autoscaling group are launched with
+func instanceConfigHash(imageID *string, instanceType *string, userData *fi.ResourceHolder, securityGroups []*awstasks.SecurityGroup, tags map[string]string) (string, error) {
+	config := &awsup.InstanceConfig{
+		Image:        fi.StringValue(imageID),
+		InstanceType: fi.StringValue(instanceType),
+		Tags:         tags,
+	}
+
+	if userData != nil {
+		d, err := userData.AsString()
+		if err != nil {
+			return "", fmt.Errorf("error rendering user data: %v", err)
+		}
+		config.UserData = d
+	}
+
+	for _, sg := range securityGroups {
+		// Security groups created by kops don't have an ID until they are created, so we use their name
+		if sg.ID != nil {
+			config.SecurityGroups = append(config.SecurityGroups, fi.StringValue(sg.ID))
+		} else {
+			config.SecurityGroups = append(config.SecurityGroups, fi.StringValue(sg.Name))
+		}
+	}
+
+	return config.Hash(), nil
+}
+
+// buildPlacementGroup adds the PlacementGroup task for the instance group, if it has a placement group
+func (b *AutoscalingGroupModelBuilder) buildPlacementGroup(c *fi.ModelBuilderContext, name string, ig *kops.InstanceGroup) *awstasks.PlacementGroup {
+	if ig.Spec.PlacementGroup == nil {
+		return nil
+	}
+
+	t := &awstasks.PlacementGroup{
+		Name:      s(name),
+		Lifecycle: b.Lifecycle,
+		Strategy:  s(ig.Spec.PlacementGroup.Strategy),
+	}
+	if ig.Spec.PlacementGroup.PartitionCount != nil {
+		t.PartitionCount = i64(int64(*ig.Spec.PlacementGroup.PartitionCount))
+	}
+	c.AddTask(t)
+
+	return t
+}
+
+// buildLaunchConfiguration builds the LaunchConfiguration task for the instance group
+func (b *AutoscalingGroupModelBuilder) buildLaunchConfiguration(c *fi.ModelBuilderContext, name string, ig *kops.InstanceGroup) (*awstasks.LaunchConfiguration, error) {
+	var err error
+
+	volumeSize := fi.Int32Value(ig.Spec.RootVolumeSize)
+	if volumeSize == 0 {
+		volumeSize, err = defaults.DefaultInstanceGroupVolumeSize(ig.Spec.Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	volumeType := fi.StringValue(ig.Spec.RootVolumeType)
+	if volumeType == "" {
+		volumeType = DefaultVolumeType
+	}
+
+	volumeIops := fi.Int32Value(ig.Spec.RootVolumeIops)
+	if volumeIops <= 0 && (volumeType == "io1" || volumeType == "io2") {
+		volumeIops = DefaultVolumeIops
+	}
+
+	link, err := b.LinkToIAMInstanceProfile(ig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find iam profile link for instance group %q: %v", ig.ObjectMeta.Name, err)
+	}
+
+	t := &awstasks.LaunchConfiguration{
+		Name:      s(name),
+		Lifecycle: b.Lifecycle,
+
+		SecurityGroups: []*awstasks.SecurityGroup{
+			b.LinkToSecurityGroup(ig.Spec.Role),
+		},
+		IAMInstanceProfile: link,
+		ImageID:            s(ig.Spec.Image),
+		InstanceType:       s(ig.Spec.MachineType),
+		InstanceMonitoring: ig.Spec.DetailedInstanceMonitoring,
+
+		RootVolumeSize:         i64(int64(volumeSize)),
+		RootVolumeType:         s(volumeType),
+		RootVolumeOptimization: ig.Spec.RootVolumeOptimization,
+	}
+
+	if volumeIops > 0 && (volumeType == "io1" || volumeType == "io2" || volumeType == "gp3") {
+		t.RootVolumeIops = i64(int64(volumeIops))
+	}
+
+	if ig.Spec.Tenancy != "" {
+		t.Tenancy = s(ig.Spec.Tenancy)
+	}
+
+	t.BlockDeviceMappings = buildAdditionalVolumes(ig)
+
+	for _, id := range ig.Spec.AdditionalSecurityGroups {
+		sgTask := &awstasks.SecurityGroup{
+			Name:   fi.String(id),
+			ID:     fi.String(id),
+			Shared: fi.Bool(true),
+
+			Lifecycle: b.SecurityLifecycle,
+		}
+		if err := c.EnsureTask(sgTask); err != nil {
+			return nil, err
+		}
+		t.SecurityGroups = append(t.SecurityGroups, sgTask)
+	}
+
+	if t.SSHKey, err = b.LinkToSSHKey(); err != nil {
+		return nil, err
+	}
+
+	if t.UserData, err = b.BootstrapScript.ResourceNodeUp(ig, b.Cluster); err != nil {
+		return nil, err
+	}
+
+	if fi.StringValue(ig.Spec.MaxPrice) != "" {
+		spotPrice := fi.StringValue(ig.Spec.MaxPrice)
+		t.SpotPrice = spotPrice
+	}
+
+	{
+		// TODO: Wrapper / helper class to analyze clusters
+		subnetMap := make(map[string]*kops.ClusterSubnetSpec)
+		for i := range b.Cluster.Spec.Subnets {
+			subnet := &b.Cluster.Spec.Subnets[i]
+			subnetMap[subnet.Name] = subnet
+		}
+
+		var subnetType kops.SubnetType
+		for _, subnetName := range ig.Spec.Subnets {
+			subnet := subnetMap[subnetName]
+			if subnet == nil {
+				return nil, fmt.Errorf("InstanceGroup %q uses subnet %q that does not exist", ig.ObjectMeta.Name, subnetName)
+			}
+			if subnetType != "" && subnetType != subnet.Type {
+				return nil, fmt.Errorf("InstanceGroup %q cannot be in subnets of different Type", ig.ObjectMeta.Name)
+			}
+			subnetType = subnet.Type
+		}
+
+		associatePublicIP := true
+		switch subnetType {
+		case kops.SubnetTypePublic, kops.SubnetTypeUtility:
+			associatePublicIP = true
+			if ig.Spec.AssociatePublicIP != nil {
+				associatePublicIP = *ig.Spec.AssociatePublicIP
+			}
+
+		case kops.SubnetTypePrivate:
+			associatePublicIP = false
+			if ig.Spec.AssociatePublicIP != nil {
+				// This isn't meaningful - private subnets can't have public ip
+				//associatePublicIP = *ig.Spec.AssociatePublicIP
+				if *ig.Spec.AssociatePublicIP {
+					glog.Warningf("Ignoring AssociatePublicIP=true for private InstanceGroup %q", ig.ObjectMeta.Name)
+				}
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown subnet type %q", subnetType)
+		}
+		t.AssociatePublicIP = &associatePublicIP
+	}
+
+	return t, nil
+}
+
+// buildLaunchTemplate builds the LaunchTemplate task for the instance group, with the same launch parameters as the
+// LaunchConfiguration; instanceTags are applied to the instances and volumes launched from the template
+func (b *AutoscalingGroupModelBuilder) buildLaunchTemplate(c *fi.ModelBuilderContext, name string, ig *kops.InstanceGroup, instanceTags map[string]string) (*awstasks.LaunchTemplate, error) {
+	lc, err := b.buildLaunchConfiguration(c, name, ig)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &awstasks.LaunchTemplate{
+		Name:      s(name),
+		Lifecycle: b.Lifecycle,
+
+		UserData:               lc.UserData,
+		ImageID:                lc.ImageID,
+		InstanceType:           lc.InstanceType,
+		SSHKey:                 lc.SSHKey,
+		SecurityGroups:         lc.SecurityGroups,
+		AssociatePublicIP:      lc.AssociatePublicIP,
+		IAMInstanceProfile:     lc.IAMInstanceProfile,
+		InstanceMonitoring:     lc.InstanceMonitoring,
+		RootVolumeSize:         lc.RootVolumeSize,
+		RootVolumeType:         lc.RootVolumeType,
+		RootVolumeIops:         lc.RootVolumeIops,
+		RootVolumeOptimization: lc.RootVolumeOptimization,
+		BlockDeviceMappings:    lc.BlockDeviceMappings,
+		Tenancy:                lc.Tenancy,
+		CPUCredits:             ig.Spec.CPUCredits,
+
+		Tags:         b.CloudTags(name, false),
+		InstanceTags: instanceTags,
+	}
+
+	if fi.StringValue(lc.RootVolumeType) == "gp3" && ig.Spec.RootVolumeThroughput != nil {
+		t.RootVolumeThroughput = i64(int64(*ig.Spec.RootVolumeThroughput))
+	}
+
+	// Instance metadata options of the instance group replace the cluster defaults
+	instanceMetadata := b.Cluster.Spec.InstanceMetadata
+	if ig.Spec.InstanceMetadata != nil {
+		instanceMetadata = ig.Spec.InstanceMetadata
+	}
+	if instanceMetadata != nil {
+		t.HTTPTokens = instanceMetadata.HTTPTokens
+		t.HTTPPutResponseHopLimit = instanceMetadata.HTTPPutResponseHopLimit
+	}
+
+	return t, nil
+}
+
+// buildAdditionalVolumes returns the block device mappings of the additional EBS volumes of the instance group;
+// ephemeral volumes use the instance store devices, which are attached without a mapping
+func buildAdditionalVolumes(ig *kops.InstanceGroup) map[string]*awstasks.BlockDeviceMapping {
+	var mappings map[string]*awstasks.BlockDeviceMapping
+
+	for _, v := range ig.Spec.Volumes {
+		if v.Ephemeral {
+			continue
+		}
+
+		volumeType := fi.StringValue(v.Type)
+		if volumeType == "" {
+			volumeType = DefaultVolumeType
+		}
+
+		bdm := &awstasks.BlockDeviceMapping{
+			EbsVolumeType:          s(volumeType),
+			EbsEncrypted:           v.Encrypted,
+			EbsDeleteOnTermination: fi.Bool(true),
+		}
+		if v.DeleteOnTermination != nil {
+			bdm.EbsDeleteOnTermination = v.DeleteOnTermination
+		}
+		if v.Size != nil {
+			bdm.EbsVolumeSize = i64(int64(*v.Size))
+		}
+
+		volumeIops := fi.Int32Value(v.Iops)
+		if volumeIops <= 0 && (volumeType == "io1" || volumeType == "io2") {
+			volumeIops = DefaultVolumeIops
+		}
+		if volumeIops > 0 && (volumeType == "io1" || volumeType == "io2" || volumeType == "gp3") {
+			bdm.EbsVolumeIops = i64(int64(volumeIops))
+		}
+		if volumeType == "gp3" && v.Throughput != nil {
+			bdm.EbsVolumeThroughput = i64(int64(*v.Throughput))
+		}
+
+		if mappings == nil {
+			mappings = make(map[string]*awstasks.BlockDeviceMapping)
+		}
+		mappings[v.Device] = bdm
+	}
+
+	return mappings
+}
+
+// buildDirectInstanceGroup builds the tasks for an instance group whose instances kops manages itself:
+// a LaunchTemplate and a DirectInstanceGroup
+func (b *AutoscalingGroupModelBuilder) buildDirectInstanceGroup(c *fi.ModelBuilderContext, name string, ig *kops.InstanceGroup, placementGroup *awstasks.PlacementGroup) error {
+	instanceTags, err := b.CloudTagsForInstanceGroup(ig)
+	if err != nil {
+		return fmt.Errorf("error building cloud tags: %v", err)
+	}
+	// Unlike an ASG, the cloud does not add the cluster tags to the instances for us
+	for k, v := range b.CloudTags(name, false) {
+		instanceTags[k] = v
+	}
+	instanceTags[awsup.TagNameDirectInstanceGroup] = ig.ObjectMeta.Name
+
+	launchTemplate, err := b.buildLaunchTemplate(c, name, ig, instanceTags)
+	if err != nil {
+		return err
+	}
+	launchTemplate.PlacementGroup = placementGroup
+	c.AddTask(launchTemplate)
+
+	size := int32(2)
+	if ig.Spec.MinSize != nil {
+		size = fi.Int32Value(ig.Spec.MinSize)
+	}
+
+	t := &awstasks.DirectInstanceGroup{
+		Name:      s(name),
+		Lifecycle: b.Lifecycle,
+
+		GroupName:      s(ig.ObjectMeta.Name),
+		LaunchTemplate: launchTemplate,
+		Size:           i64(int64(size)),
+	}
+	if ig.Spec.MixedInstancesPolicy != nil {
+		t.InstanceTypes = ig.Spec.MixedInstancesPolicy.Instances
+	}
+
+	subnets, err := b.GatherSubnets(ig)
+	if err != nil {
+		return err
+	}
+	if len(subnets) == 0 {
+		return fmt.Errorf("could not determine any subnets for InstanceGroup %q; subnets was %s", ig.ObjectMeta.Name, ig.Spec.Subnets)
+	}
+	for _, subnet := range subnets {
+		t.Subnets = append(t.Subnets, b.LinkToSubnet(subnet))
+	}
+
+	c.AddTask(t)
+
+	return nil
+}
+
+// targetGroupNameFromARN returns the name of a target group from its ARN, e.g. arn:aws:elasticloadbalancing:...:targetgroup/<name>/<id>
+func targetGroupNameFromARN(arn string) string {
+	i := strings.Index(arn, ":targetgroup/")
+	if i == -1 {
+		return arn
+	}
+	name := arn[i+len(":targetgroup/"):]
+	if j := strings.Index(name, "/"); j != -1 {
+		name = name[:j]
+	}
+	return name
+}
@@ -20,9 +20,12 @@ import (
 	"testing"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
 
 func buildMinimalCluster() *kops.Cluster {
@@ -47,6 +50,9 @@ func buildMinimalCluster() *kops.Cluster {
 
 	c.Spec.ConfigBase = "s3://unittest-bucket/"
 
+	// Rendered into the user data of the instances
+	c.Spec.KubeAPIServer = &kops.KubeAPIServerConfig{}
+
 	// Required to stop a call to cloud provider
 	// TODO: Mock cloudprovider
 	c.Spec.DNSZone = "test.com"
@@ -63,6 +69,16 @@ func buildNodeInstanceGroup(subnets ...string) *kops.InstanceGroup {
 	return g
 }
 
+func buildBootstrapScript() *model.BootstrapScript {
+	return &model.BootstrapScript{
+		NodeUpSource:     "NUSource",
+		NodeUpSourceHash: "NUSHash",
+		NodeUpConfigBuilder: func(ig *kops.InstanceGroup) (*nodeup.Config, error) {
+			return &nodeup.Config{}, nil
+		},
+	}
+}
+
 // Tests that RootVolumeOptimization flag gets added to the awstasks
 func TestRootVolumeOptimizationFlag(t *testing.T) {
 	cluster := buildMinimalCluster()
@@ -76,6 +92,7 @@ func TestRootVolumeOptimizationFlag(t *testing.T) {
 	igs = append(igs, ig)
 
 	b := AutoscalingGroupModelBuilder{
+		BootstrapScript: buildBootstrapScript(),
 		AWSModelContext: &AWSModelContext{
 			KopsModelContext: &model.KopsModelContext{
 				SSHPublicKeys:  k,
@@ -97,3 +114,192 @@ func TestRootVolumeOptimizationFlag(t *testing.T) {
 		t.Fatalf("RootVolumeOptimization was expected to be true, but was false")
 	}
 }
+
+// Tests that a directly managed instance group gets a LaunchTemplate and DirectInstanceGroup, rather than an ASG
+func TestDirectInstanceGroup(t *testing.T) {
+	cluster := buildMinimalCluster()
+	ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+	ig.Spec.Manager = kops.InstanceManagerDirect
+	ig.Spec.MinSize = fi.Int32(3)
+
+	b := AutoscalingGroupModelBuilder{
+		BootstrapScript: buildBootstrapScript(),
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				SSHPublicKeys:  [][]byte{[]byte("ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test")},
+				Cluster:        cluster,
+				InstanceGroups: []*kops.InstanceGroup{ig},
+			},
+		},
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	if err := b.Build(c); err != nil {
+		t.Fatalf("unexpected error building model: %v", err)
+	}
+
+	for _, key := range []string{"AutoscalingGroup/nodes.testcluster.test.com", "LaunchConfiguration/nodes.testcluster.test.com"} {
+		if c.Tasks[key] != nil {
+			t.Errorf("unexpected task %q for directly managed instance group", key)
+		}
+	}
+
+	lt, ok := c.Tasks["LaunchTemplate/nodes.testcluster.test.com"].(*awstasks.LaunchTemplate)
+	if !ok {
+		t.Fatalf("LaunchTemplate task not found")
+	}
+	if lt.InstanceTags[awsup.TagNameDirectInstanceGroup] != "nodes" {
+		t.Errorf("expected instance tag %s=nodes, got %v", awsup.TagNameDirectInstanceGroup, lt.InstanceTags)
+	}
+
+	g, ok := c.Tasks["DirectInstanceGroup/nodes.testcluster.test.com"].(*awstasks.DirectInstanceGroup)
+	if !ok {
+		t.Fatalf("DirectInstanceGroup task not found")
+	}
+	if fi.Int64Value(g.Size) != 3 {
+		t.Errorf("expected size 3, got %d", fi.Int64Value(g.Size))
+	}
+	if g.LaunchTemplate != lt {
+		t.Errorf("DirectInstanceGroup was not linked to the LaunchTemplate")
+	}
+}
+
+func TestLaunchTemplatesFeatureFlag(t *testing.T) {
+	featureflag.ParseFlags("+EnableLaunchTemplates")
+	defer featureflag.ParseFlags("-EnableLaunchTemplates")
+
+	cluster := buildMinimalCluster()
+	ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+	ig.Spec.CPUCredits = fi.String("unlimited")
+
+	b := AutoscalingGroupModelBuilder{
+		BootstrapScript: buildBootstrapScript(),
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				SSHPublicKeys:  [][]byte{[]byte("ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test")},
+				Cluster:        cluster,
+				InstanceGroups: []*kops.InstanceGroup{ig},
+			},
+		},
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	if err := b.Build(c); err != nil {
+		t.Fatalf("unexpected error building model: %v", err)
+	}
+
+	if c.Tasks["LaunchConfiguration/nodes.testcluster.test.com"] != nil {
+		t.Errorf("unexpected LaunchConfiguration task with launch templates enabled")
+	}
+
+	lt, ok := c.Tasks["LaunchTemplate/nodes.testcluster.test.com"].(*awstasks.LaunchTemplate)
+	if !ok {
+		t.Fatalf("LaunchTemplate task not found")
+	}
+	if fi.StringValue(lt.CPUCredits) != "unlimited" {
+		t.Errorf("expected cpuCredits unlimited, got %q", fi.StringValue(lt.CPUCredits))
+	}
+
+	asg, ok := c.Tasks["AutoscalingGroup/nodes.testcluster.test.com"].(*awstasks.AutoscalingGroup)
+	if !ok {
+		t.Fatalf("AutoscalingGroup task not found")
+	}
+	if asg.LaunchTemplate != lt || asg.LaunchConfiguration != nil {
+		t.Errorf("AutoscalingGroup was not linked to the LaunchTemplate")
+	}
+}
+
+func TestPlacementGroup(t *testing.T) {
+	cluster := buildMinimalCluster()
+	ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+	ig.Spec.PlacementGroup = &kops.PlacementGroupSpec{Strategy: "partition", PartitionCount: fi.Int32(3)}
+
+	b := AutoscalingGroupModelBuilder{
+		BootstrapScript: buildBootstrapScript(),
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				SSHPublicKeys:  [][]byte{[]byte("ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test")},
+				Cluster:        cluster,
+				InstanceGroups: []*kops.InstanceGroup{ig},
+			},
+		},
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	if err := b.Build(c); err != nil {
+		t.Fatalf("unexpected error building model: %v", err)
+	}
+
+	pg, ok := c.Tasks["PlacementGroup/nodes.testcluster.test.com"].(*awstasks.PlacementGroup)
+	if !ok {
+		t.Fatalf("PlacementGroup task not found")
+	}
+	if fi.StringValue(pg.Strategy) != "partition" || fi.Int64Value(pg.PartitionCount) != 3 {
+		t.Errorf("unexpected placement group %v", pg)
+	}
+
+	asg, ok := c.Tasks["AutoscalingGroup/nodes.testcluster.test.com"].(*awstasks.AutoscalingGroup)
+	if !ok {
+		t.Fatalf("AutoscalingGroup task not found")
+	}
+	if asg.PlacementGroup != pg {
+		t.Errorf("AutoscalingGroup was not linked to the PlacementGroup")
+	}
+}
+
+// Tests that the autoscaling group records the hash of the instance configuration, which changes with the image
+func TestInstanceConfigHashTag(t *testing.T) {
+	buildHash := func(image string) string {
+		cluster := buildMinimalCluster()
+		ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+		ig.Spec.Image = image
+
+		b := AutoscalingGroupModelBuilder{
+			BootstrapScript: buildBootstrapScript(),
+			AWSModelContext: &AWSModelContext{
+				KopsModelContext: &model.KopsModelContext{
+					SSHPublicKeys:  [][]byte{[]byte("ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test")},
+					Cluster:        cluster,
+					InstanceGroups: []*kops.InstanceGroup{ig},
+				},
+			},
+		}
+
+		c := &fi.ModelBuilderContext{
+			Tasks: make(map[string]fi.Task),
+		}
+		if err := b.Build(c); err != nil {
+			t.Fatalf("unexpected error building model: %v", err)
+		}
+
+		asg, ok := c.Tasks["AutoscalingGroup/nodes.testcluster.test.com"].(*awstasks.AutoscalingGroup)
+		if !ok {
+			t.Fatalf("AutoscalingGroup task not found")
+		}
+		hash := asg.Tags[awsup.TagNameInstanceConfigHash]
+		if hash == "" {
+			t.Fatalf("expected tag %s on the autoscaling group, got %v", awsup.TagNameInstanceConfigHash, asg.Tags)
+		}
+		return hash
+	}
+
+	a := buildHash("kope.io/k8s-1.9-debian-jessie-amd64-hvm-ebs-2018-03-11")
+	b := buildHash("kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-05-27")
+
+	changes, err := awsup.InstanceConfigChanges(b, a)
+	if err != nil {
+		t.Fatalf("unexpected error comparing hashes: %v", err)
+	}
+	if len(changes) != 1 || changes[0] != awsup.InstanceConfigImage {
+		t.Errorf("expected only the image to change, got %v", changes)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetalmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/baremetal"
+	"k8s.io/kops/upup/pkg/fi/cloudup/baremetaltasks"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// HostModelBuilder publishes the PXE and node configuration of the hosts of bare metal instance groups
+type HostModelBuilder struct {
+	*BareMetalModelContext
+
+	BootstrapScript *model.BootstrapScript
+	Lifecycle       *fi.Lifecycle
+}
+
+var _ fi.ModelBuilder = &HostModelBuilder{}
+
+func (b *HostModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	for _, ig := range b.InstanceGroups {
+		spec := ig.Spec.BareMetal
+		if spec == nil || spec.PXE == nil {
+			continue
+		}
+
+		pxeConfigBase, err := vfs.Context.BuildVfsPath(spec.PXE.ConfigBase)
+		if err != nil {
+			return fmt.Errorf("error parsing PXE config base %q of instance group %q: %v", spec.PXE.ConfigBase, ig.ObjectMeta.Name, err)
+		}
+
+		userData, err := b.BootstrapScript.ResourceNodeUp(ig, b.Cluster)
+		if err != nil {
+			return err
+		}
+		script, err := userData.AsString()
+		if err != nil {
+			return fmt.Errorf("error rendering bootstrap script of instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+
+		for i := range spec.Hosts {
+			host := &spec.Hosts[i]
+
+			file, err := baremetal.PXEConfigFile(host.MACAddress)
+			if err != nil {
+				return fmt.Errorf("host %q: %v", host.Name, err)
+			}
+			pxeConfig := baremetal.BuildPXEConfig(spec.PXE, host)
+
+			c.AddTask(&baremetaltasks.Host{
+				Name:      fi.String(host.Name),
+				Lifecycle: b.Lifecycle,
+
+				PXEConfigPath: fi.String(pxeConfigBase.Join(file).Path()),
+				PXEConfig:     fi.String(pxeConfig),
+				ConfigHash:    fi.String(configHash(pxeConfig, script)),
+			})
+		}
+	}
+
+	return nil
+}
+
+// configHash returns the hash of the configuration a host is imaged with: how it network boots, and how nodeup is run
+func configHash(pxeConfig string, bootstrapScript string) string {
+	h := sha256.New()
+	h.Write([]byte(pxeConfig))
+	h.Write([]byte{0})
+	h.Write([]byte(bootstrapScript))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -31,7 +31,7 @@ const BastionELBDefaultIdleTimeout = 5 * time.Minute
 // BastionModelBuilder adds model objects to support bastions
 //
 // Bastion instances live in the utility subnets created in the private topology.
-// All traffic goes through an ELB, and the ELB has port 22 open to SSHAccess.
+// All traffic goes through an ELB, and the ELB has port 22 open to SSHAccess (or the bastion AllowedCIDRs).
 // Bastion instances have access to all internal master and node instances.
 
 type BastionModelBuilder struct {
@@ -65,6 +65,7 @@ func (b *BastionModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			RemoveExtraRules: []string{"port=22"},
 		}
 		t.Tags = b.CloudTags(*t.Name, false)
+		UseSecurityGroupOverride(t, b.SecurityGroupOverride(kops.InstanceGroupRoleBastion))
 		c.AddTask(t)
 	}
 
@@ -127,6 +128,21 @@ func (b *BastionModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		c.AddTask(t)
 	}
 
+	// Allow bastion nodes to SSH to dedicated etcd instances
+	if len(b.EtcdInstanceGroups()) != 0 {
+		t := &awstasks.SecurityGroupRule{
+			Name:      s("bastion-to-etcd-ssh"),
+			Lifecycle: b.SecurityLifecycle,
+
+			SecurityGroup: b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+			SourceGroup:   b.LinkToSecurityGroup(kops.InstanceGroupRoleBastion),
+			Protocol:      s("tcp"),
+			FromPort:      i64(22),
+			ToPort:        i64(22),
+		}
+		c.AddTask(t)
+	}
+
 	// Create security group for bastion ELB
 	{
 		t := &awstasks.SecurityGroup{
@@ -156,7 +172,7 @@ func (b *BastionModelBuilder) Build(c *fi.ModelBuilderContext) error {
 	}
 
 	// Allow external access to ELB
-	for _, sshAccess := range b.Cluster.Spec.SSHAccess {
+	for _, sshAccess := range b.bastionAllowedCIDRs() {
 		t := &awstasks.SecurityGroupRule{
 			Name:      s("ssh-external-to-bastion-elb-" + sshAccess),
 			Lifecycle: b.SecurityLifecycle,
@@ -228,6 +244,10 @@ func (b *BastionModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			},
 		}
 
+		if b.Cluster.Spec.Topology != nil && b.Cluster.Spec.Topology.Bastion != nil && b.Cluster.Spec.Topology.Bastion.LoadBalancerType == kops.LoadBalancerTypeInternal {
+			elb.Scheme = s("internal")
+		}
+
 		c.AddTask(elb)
 	}
 
@@ -265,3 +285,11 @@ func (b *BastionModelBuilder) Build(c *fi.ModelBuilderContext) error {
 	}
 	return nil
 }
+
+// bastionAllowedCIDRs returns the CIDRs allowed to SSH to the bastion ELB
+func (b *BastionModelBuilder) bastionAllowedCIDRs() []string {
+	if b.Cluster.Spec.Topology != nil && b.Cluster.Spec.Topology.Bastion != nil && len(b.Cluster.Spec.Topology.Bastion.AllowedCIDRs) != 0 {
+		return b.Cluster.Spec.Topology.Bastion.AllowedCIDRs
+	}
+	return b.Cluster.Spec.SSHAccess
+}
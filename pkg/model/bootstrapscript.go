@@ -41,6 +41,9 @@ type BootstrapScript struct {
 	NodeUpSource        string
 	NodeUpSourceHash    string
 	NodeUpConfigBuilder func(ig *kops.InstanceGroup) (*nodeup.Config, error)
+	// SSHAuthorizedKeys are the SSH public keys authorized for the admin user; bastions do not run nodeup, so
+	// they receive these through cloud-init instead
+	SSHAuthorizedKeys []string
 }
 
 // KubeEnv returns the nodeup config for the instance group
@@ -79,6 +82,20 @@ func (b *BootstrapScript) buildEnvironmentVariables(cluster *kops.Cluster) (map[
 		}
 	}
 
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderHetzner {
+		if token := os.Getenv("HCLOUD_TOKEN"); token != "" {
+			env["HCLOUD_TOKEN"] = token
+		}
+	}
+
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderScaleway {
+		for _, name := range []string{"SCW_SECRET_KEY", "SCW_DEFAULT_PROJECT_ID"} {
+			if value := os.Getenv(name); value != "" {
+				env[name] = value
+			}
+		}
+	}
+
 	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderAWS {
 		region, err := awsup.FindRegion(cluster)
 		if err != nil {
@@ -89,6 +106,11 @@ func (b *BootstrapScript) buildEnvironmentVariables(cluster *kops.Cluster) (map[
 		} else {
 			env["AWS_REGION"] = region
 		}
+
+		// Endpoint overrides, e.g. for VPC endpoints or partitions the AWS SDK doesn't know, are needed on the nodes too
+		for name, value := range awsup.ServiceEndpointEnvVars() {
+			env[name] = value
+		}
 	}
 
 	return env, nil
@@ -97,9 +119,20 @@ func (b *BootstrapScript) buildEnvironmentVariables(cluster *kops.Cluster) (map[
 // ResourceNodeUp generates and returns a nodeup (bootstrap) script from a
 // template file, substituting in specific env vars & cluster spec configuration
 func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cluster *kops.Cluster) (*fi.ResourceHolder, error) {
-	// Bastions can have AdditionalUserData, but if there isn't any skip this part
-	if ig.IsBastion() && len(ig.Spec.AdditionalUserData) == 0 {
-		return nil, nil
+	if ig.IsBastion() {
+		if len(b.SSHAuthorizedKeys) != 0 {
+			userData, err := bastionSSHAuthorizedKeysUserData(b.SSHAuthorizedKeys)
+			if err != nil {
+				return nil, err
+			}
+			ig = ig.DeepCopy()
+			ig.Spec.AdditionalUserData = append(ig.Spec.AdditionalUserData, userData)
+		}
+
+		// Bastions can have AdditionalUserData, but if there isn't any skip this part
+		if len(ig.Spec.AdditionalUserData) == 0 {
+			return nil, nil
+		}
 	}
 
 	functions := template.FuncMap{
@@ -149,10 +182,20 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cluster *kops.C
 
 			if ig.IsMaster() {
 				spec["encryptionConfig"] = cs.EncryptionConfig
-				spec["etcdClusters"] = make(map[string]kops.EtcdClusterSpec, 0)
 				spec["kubeAPIServer"] = cs.KubeAPIServer
 				spec["kubeControllerManager"] = cs.KubeControllerManager
 				spec["kubeScheduler"] = cs.KubeScheduler
+			}
+
+			// apiserver-only instances run kube-apiserver against the remote etcd clusters
+			if ig.IsAPIServer() {
+				spec["encryptionConfig"] = cs.EncryptionConfig
+				spec["kubeAPIServer"] = cs.KubeAPIServer
+			}
+
+			// Dedicated etcd instances run etcd with the master kubelet, but none of the control plane
+			if ig.IsMaster() || ig.IsEtcd() {
+				spec["etcdClusters"] = make(map[string]kops.EtcdClusterSpec, 0)
 				spec["masterKubelet"] = cs.MasterKubelet
 
 				for _, etcdCluster := range cs.EtcdClusters {
@@ -229,6 +272,19 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cluster *kops.C
 	return fi.WrapResource(templateResource), nil
 }
 
+// bastionSSHAuthorizedKeysUserData builds a cloud-config part that authorizes the SSH keys for the default user
+func bastionSSHAuthorizedKeysUserData(keys []string) (kops.UserData, error) {
+	content, err := yaml.Marshal(map[string][]string{"ssh_authorized_keys": keys})
+	if err != nil {
+		return kops.UserData{}, fmt.Errorf("error building bastion ssh authorized keys: %v", err)
+	}
+	return kops.UserData{
+		Name:    "ssh-authorized-keys.cfg",
+		Type:    "text/cloud-config",
+		Content: "#cloud-config\n" + string(content),
+	}, nil
+}
+
 // getRelevantHooks returns a list of hooks to be applied to the instance group,
 // with the Manifest and ExecContainer Commands fingerprinted to reduce size
 func (b *BootstrapScript) getRelevantHooks(allHooks []kops.HookSpec, role kops.InstanceGroupRole) ([]kops.HookSpec, error) {
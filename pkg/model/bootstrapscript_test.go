@@ -17,6 +17,7 @@ limitations under the License.
 package model
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -305,3 +306,42 @@ func makeTestInstanceGroup(role kops.InstanceGroupRole, hookSpecRoles []kops.Ins
 		},
 	}
 }
+
+func TestBootstrapUserDataBastion(t *testing.T) {
+	cluster := makeTestCluster(nil, nil)
+	group := makeTestInstanceGroup(kops.InstanceGroupRoleBastion, nil, nil)
+
+	bs := &BootstrapScript{
+		NodeUpConfigBuilder: func(ig *kops.InstanceGroup) (*nodeup.Config, error) {
+			return nil, fmt.Errorf("bastions do not run nodeup")
+		},
+	}
+
+	res, err := bs.ResourceNodeUp(group, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != nil {
+		t.Fatalf("expected no user data for a bastion without additional user data or ssh keys")
+	}
+
+	bs.SSHAuthorizedKeys = []string{"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQ admin", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAI alice"}
+	res, err = bs.ResourceNodeUp(group, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual, err := res.AsString()
+	if err != nil {
+		t.Fatalf("error rendering user data: %v", err)
+	}
+	if strings.Contains(actual, "nodeup") {
+		t.Errorf("expected bastion user data not to run nodeup")
+	}
+	expected := "Content-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\nssh_authorized_keys:\n- ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQ admin\n- ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAI alice\n"
+	if !strings.Contains(actual, expected) {
+		t.Errorf("expected bastion user data to authorize the ssh keys, got %q", actual)
+	}
+	if len(group.Spec.AdditionalUserData) != 0 {
+		t.Errorf("expected the instance group not to be modified")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	"k8s.io/kops/upup/pkg/fi/loader"
 
 	"github.com/blang/semver"
@@ -82,6 +83,19 @@ func (b *KubeAPIServerOptionsBuilder) BuildOptions(o interface{}) error {
 		if clusterSpec.Authentication.Kopeio != nil {
 			c.AuthenticationTokenWebhookConfigFile = fi.String("/etc/kubernetes/authn.config")
 		}
+		if clusterSpec.Authentication.Aws != nil {
+			// nodeup writes the kubeconfig for the aws-iam-authenticator webhook to the same path
+			c.AuthenticationTokenWebhookConfigFile = fi.String("/etc/kubernetes/authn.config")
+		}
+		if oidc := clusterSpec.Authentication.OIDC; oidc != nil {
+			// Flags set directly on the apiserver take precedence
+			setStringIfUnset(&c.OIDCIssuerURL, oidc.IssuerURL)
+			setStringIfUnset(&c.OIDCClientID, oidc.ClientID)
+			setStringIfUnset(&c.OIDCUsernameClaim, oidc.UsernameClaim)
+			setStringIfUnset(&c.OIDCUsernamePrefix, oidc.UsernamePrefix)
+			setStringIfUnset(&c.OIDCGroupsClaim, oidc.GroupsClaim)
+			setStringIfUnset(&c.OIDCGroupsPrefix, oidc.GroupsPrefix)
+		}
 	}
 
 	if clusterSpec.Authorization == nil || clusterSpec.Authorization.IsEmpty() {
@@ -159,10 +173,17 @@ func (b *KubeAPIServerOptionsBuilder) BuildOptions(o interface{}) error {
 		c.CloudProvider = "vsphere"
 	case kops.CloudProviderBareMetal:
 		// for baremetal, we don't specify a cloudprovider to apiserver
+	case kops.CloudProviderHetzner:
+		// Hetzner Cloud has no in-tree cloudprovider
 	case kops.CloudProviderOpenstack:
 		c.CloudProvider = "openstack"
+	case kops.CloudProviderScaleway:
+		// Scaleway has no in-tree cloudprovider
 	default:
-		return fmt.Errorf("unknown cloudprovider %q", clusterSpec.CloudProvider)
+		if cloudplugin.Get(kops.CloudProviderID(clusterSpec.CloudProvider)) == nil {
+			return fmt.Errorf("unknown cloudprovider %q", clusterSpec.CloudProvider)
+		}
+		c.CloudProvider = "external"
 	}
 
 	if clusterSpec.ExternalCloudControllerManager != nil {
@@ -271,6 +292,15 @@ func (b *KubeAPIServerOptionsBuilder) BuildOptions(o interface{}) error {
 		}
 	}
 
+	// spec.podSecurity is enforced by the PodSecurityPolicy admission controller
+	if clusterSpec.PodSecurity != nil {
+		if b.IsKubernetesGTE("1.10") {
+			c.EnableAdmissionPlugins = appendAdmissionPlugin(c.EnableAdmissionPlugins, "PodSecurityPolicy")
+		} else {
+			c.AdmissionControl = appendAdmissionPlugin(c.AdmissionControl, "PodSecurityPolicy")
+		}
+	}
+
 	// We make sure to disable AnonymousAuth from when it was introduced
 	if b.IsKubernetesGTE("1.5") {
 		c.AnonymousAuth = fi.Bool(false)
@@ -282,6 +312,20 @@ func (b *KubeAPIServerOptionsBuilder) BuildOptions(o interface{}) error {
 	return nil
 }
 
+// appendAdmissionPlugin adds an admission plugin to the list, keeping ResourceQuota last as it must run after all
+// the plugins that can reject a request
+func appendAdmissionPlugin(plugins []string, name string) []string {
+	for _, p := range plugins {
+		if p == name {
+			return plugins
+		}
+	}
+	if n := len(plugins); n != 0 && plugins[n-1] == "ResourceQuota" {
+		return append(plugins[:n-1:n-1], name, "ResourceQuota")
+	}
+	return append(plugins, name)
+}
+
 // buildAPIServerCount calculates the count of the api servers, essentuially the number of node marked as Master role
 func (b *KubeAPIServerOptionsBuilder) buildAPIServerCount(clusterSpec *kops.ClusterSpec) int {
 	// The --apiserver-count flag is (generally agreed) to be something we need to get rid of in k8s
@@ -328,3 +372,10 @@ func (b *KubeAPIServerOptionsBuilder) configureAggregation(clusterSpec *kops.Clu
 
 	return nil
 }
+
+// setStringIfUnset sets the flag to the value, if the flag is not already set and the value is not empty
+func setStringIfUnset(flag **string, value string) {
+	if *flag == nil && value != "" {
+		*flag = fi.String(value)
+	}
+}
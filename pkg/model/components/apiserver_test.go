@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func Test_Build_KubeAPIServer_OIDC(t *testing.T) {
+	c := buildCluster()
+	c.Spec.KubernetesVersion = "v1.10.0"
+	c.Spec.KubeAPIServer = &api.KubeAPIServerConfig{
+		APIServerCount:  fi.Int32(1),
+		StorageBackend:  fi.String("etcd3"),
+		OIDCGroupsClaim: fi.String("roles"),
+	}
+	c.Spec.Authentication = &api.AuthenticationSpec{
+		OIDC: &api.OIDCAuthenticationSpec{
+			IssuerURL:     "https://dex.example.com",
+			ClientID:      "kubernetes",
+			UsernameClaim: "email",
+			GroupsClaim:   "groups",
+		},
+	}
+
+	b := &KubeAPIServerOptionsBuilder{
+		OptionsContext: &OptionsContext{
+			AssetBuilder:      assets.NewAssetBuilder(c, ""),
+			KubernetesVersion: semver.MustParse("1.10.0"),
+		},
+	}
+	if err := b.BuildOptions(&c.Spec); err != nil {
+		t.Fatalf("unexpected error from BuildOptions: %v", err)
+	}
+
+	apiserver := c.Spec.KubeAPIServer
+	if fi.StringValue(apiserver.OIDCIssuerURL) != "https://dex.example.com" {
+		t.Errorf("unexpected oidc-issuer-url %q", fi.StringValue(apiserver.OIDCIssuerURL))
+	}
+	if fi.StringValue(apiserver.OIDCClientID) != "kubernetes" {
+		t.Errorf("unexpected oidc-client-id %q", fi.StringValue(apiserver.OIDCClientID))
+	}
+	if fi.StringValue(apiserver.OIDCUsernameClaim) != "email" {
+		t.Errorf("unexpected oidc-username-claim %q", fi.StringValue(apiserver.OIDCUsernameClaim))
+	}
+	if fi.StringValue(apiserver.OIDCGroupsClaim) != "roles" {
+		t.Errorf("expected the oidc-groups-claim set on the apiserver to take precedence, got %q", fi.StringValue(apiserver.OIDCGroupsClaim))
+	}
+	if apiserver.OIDCUsernamePrefix != nil {
+		t.Errorf("expected no oidc-username-prefix, got %q", fi.StringValue(apiserver.OIDCUsernamePrefix))
+	}
+}
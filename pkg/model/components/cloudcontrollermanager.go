@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/loader"
+)
+
+// CloudControllerManagerOptionsBuilder adds options for the external cloud-controller-manager to the model
+type CloudControllerManagerOptionsBuilder struct {
+	Context *OptionsContext
+}
+
+var _ loader.OptionsBuilder = &CloudControllerManagerOptionsBuilder{}
+
+// BuildOptions defaults the options of the external cloud-controller-manager, if the cluster runs one
+func (b *CloudControllerManagerOptionsBuilder) BuildOptions(o interface{}) error {
+	clusterSpec := o.(*kops.ClusterSpec)
+	ccm := clusterSpec.ExternalCloudControllerManager
+	if ccm == nil {
+		return nil
+	}
+
+	// The cloud-controller-manager takes over the cloud controllers of kube-controller-manager, so it mirrors its options
+	kcm := clusterSpec.KubeControllerManager
+	if kcm == nil {
+		kcm = &kops.KubeControllerManagerConfig{}
+	}
+
+	if ccm.CloudProvider == "" {
+		ccm.CloudProvider = inTreeCloudProvider(kops.CloudProviderID(clusterSpec.CloudProvider))
+	}
+
+	if ccm.Image == "" {
+		if inTreeCloudProvider(kops.CloudProviderID(clusterSpec.CloudProvider)) == "" {
+			return fmt.Errorf("cloudControllerManager.image must be set for cloud provider %q, which has no in-tree cloud provider", clusterSpec.CloudProvider)
+		}
+		image, err := Image("cloud-controller-manager", clusterSpec, b.Context.AssetBuilder)
+		if err != nil {
+			return err
+		}
+		ccm.Image = image
+	}
+
+	if ccm.ClusterName == "" {
+		ccm.ClusterName = kcm.ClusterName
+	}
+	if ccm.ClusterCIDR == "" {
+		ccm.ClusterCIDR = kcm.ClusterCIDR
+	}
+	if ccm.AllocateNodeCIDRs == nil {
+		ccm.AllocateNodeCIDRs = kcm.AllocateNodeCIDRs
+	}
+	if ccm.ConfigureCloudRoutes == nil {
+		ccm.ConfigureCloudRoutes = kcm.ConfigureCloudRoutes
+	}
+	if ccm.CIDRAllocatorType == nil {
+		ccm.CIDRAllocatorType = kcm.CIDRAllocatorType
+	}
+
+	if ccm.LogLevel == 0 {
+		ccm.LogLevel = 2
+	}
+
+	if ccm.LeaderElection == nil {
+		// The cloud-controller-manager runs on every master
+		ccm.LeaderElection = &kops.LeaderElectionConfiguration{LeaderElect: fi.Bool(true)}
+	}
+
+	if ccm.UseServiceAccountCredentials == nil {
+		ccm.UseServiceAccountCredentials = fi.Bool(true)
+	}
+
+	return nil
+}
+
+// inTreeCloudProvider returns the name of the in-tree cloud provider for the cloud, which the cloud-controller-manager
+// image of kubernetes can run, or "" if there is none
+func inTreeCloudProvider(cloudProvider kops.CloudProviderID) string {
+	switch cloudProvider {
+	case kops.CloudProviderAWS:
+		return "aws"
+	case kops.CloudProviderGCE:
+		return "gce"
+	case kops.CloudProviderOpenstack:
+		return "openstack"
+	case kops.CloudProviderVSphere:
+		return "vsphere"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func buildCloudControllerManagerOptionsBuilder(t *testing.T, c *api.Cluster) *CloudControllerManagerOptionsBuilder {
+	version, err := util.ParseKubernetesVersion(c.Spec.KubernetesVersion)
+	if err != nil {
+		t.Fatalf("unexpected error from ParseKubernetesVersion %s: %v", c.Spec.KubernetesVersion, err)
+	}
+	return &CloudControllerManagerOptionsBuilder{
+		Context: &OptionsContext{
+			AssetBuilder:      assets.NewAssetBuilder(c, ""),
+			KubernetesVersion: *version,
+		},
+	}
+}
+
+func Test_Build_CloudControllerManager_Defaults(t *testing.T) {
+	c := buildCluster()
+	c.Spec.KubernetesVersion = "v1.11.0"
+	c.Spec.KubeControllerManager = &api.KubeControllerManagerConfig{
+		ClusterName:          "minimal.example.com",
+		ClusterCIDR:          "100.96.0.0/11",
+		AllocateNodeCIDRs:    fi.Bool(true),
+		ConfigureCloudRoutes: fi.Bool(true),
+	}
+	c.Spec.ExternalCloudControllerManager = &api.CloudControllerManagerConfig{}
+
+	if err := buildCloudControllerManagerOptionsBuilder(t, c).BuildOptions(&c.Spec); err != nil {
+		t.Fatalf("unexpected error from BuildOptions: %v", err)
+	}
+
+	ccm := c.Spec.ExternalCloudControllerManager
+	if ccm.CloudProvider != "aws" {
+		t.Errorf("expected cloud provider aws, got %q", ccm.CloudProvider)
+	}
+	if ccm.Image != "k8s.gcr.io/cloud-controller-manager:v1.11.0" {
+		t.Errorf("unexpected image %q", ccm.Image)
+	}
+	if ccm.ClusterName != "minimal.example.com" || ccm.ClusterCIDR != "100.96.0.0/11" {
+		t.Errorf("expected the cluster name and CIDR of kube-controller-manager, got %q and %q", ccm.ClusterName, ccm.ClusterCIDR)
+	}
+	if !fi.BoolValue(ccm.ConfigureCloudRoutes) || !fi.BoolValue(ccm.AllocateNodeCIDRs) {
+		t.Errorf("expected cloud routes to be configured")
+	}
+	if ccm.LeaderElection == nil || !fi.BoolValue(ccm.LeaderElection.LeaderElect) {
+		t.Errorf("expected leader election")
+	}
+}
+
+func Test_Build_CloudControllerManager_OutOfTree(t *testing.T) {
+	c := buildCluster()
+	c.Spec.CloudProvider = "hetzner"
+	c.Spec.KubernetesVersion = "v1.11.0"
+	c.Spec.ExternalCloudControllerManager = &api.CloudControllerManagerConfig{}
+
+	if err := buildCloudControllerManagerOptionsBuilder(t, c).BuildOptions(&c.Spec); err == nil {
+		t.Fatalf("expected an error without an image for a cloud without an in-tree provider")
+	}
+
+	c.Spec.ExternalCloudControllerManager = &api.CloudControllerManagerConfig{
+		CloudProvider: "hcloud",
+		Image:         "hetznercloud/hcloud-cloud-controller-manager:v1.2.0",
+	}
+	if err := buildCloudControllerManagerOptionsBuilder(t, c).BuildOptions(&c.Spec); err != nil {
+		t.Fatalf("unexpected error from BuildOptions: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi/loader"
+)
+
+const (
+	// AWSEBSCSIDriverImage is the default image of the AWS EBS CSI driver
+	AWSEBSCSIDriverImage = "amazon/aws-ebs-csi-driver:v0.4.0"
+	// GCEPDCSIDriverImage is the default image of the GCE PD CSI driver
+	GCEPDCSIDriverImage = "gcr.io/gke-release/gcp-compute-persistent-disk-csi-driver:v0.4.0-gke.0"
+)
+
+// CSIDriverOptionsBuilder adds the options for the CSI driver of the cloud to the model
+type CSIDriverOptionsBuilder struct {
+	Context *OptionsContext
+}
+
+var _ loader.OptionsBuilder = &CSIDriverOptionsBuilder{}
+
+// BuildOptions defaults the image of an enabled CSI driver and, if its volumes are migrated from the in-tree volume
+// plugin, turns on the CSIMigration feature gates of the kubelets, kube-controller-manager and kube-scheduler
+func (b *CSIDriverOptionsBuilder) BuildOptions(o interface{}) error {
+	clusterSpec := o.(*kops.ClusterSpec)
+	if clusterSpec.CloudConfig == nil {
+		return nil
+	}
+
+	var driver *kops.CSIDriverSpec
+	var defaultImage, migrationFeatureGate string
+	switch kops.CloudProviderID(clusterSpec.CloudProvider) {
+	case kops.CloudProviderAWS:
+		driver = clusterSpec.CloudConfig.AWSEBSCSIDriver
+		defaultImage = AWSEBSCSIDriverImage
+		migrationFeatureGate = "CSIMigrationAWS"
+	case kops.CloudProviderGCE:
+		driver = clusterSpec.CloudConfig.GCEPDCSIDriver
+		defaultImage = GCEPDCSIDriverImage
+		migrationFeatureGate = "CSIMigrationGCE"
+	default:
+		return nil
+	}
+	if !driver.IsEnabled() {
+		return nil
+	}
+
+	if driver.Image == "" {
+		image, err := b.Context.AssetBuilder.RemapImage(defaultImage)
+		if err != nil {
+			return err
+		}
+		driver.Image = image
+	}
+
+	if !driver.IsMigrationEnabled() {
+		return nil
+	}
+
+	// Every component which manages volumes must agree on whether they are migrated
+	if clusterSpec.Kubelet == nil {
+		clusterSpec.Kubelet = &kops.KubeletConfigSpec{}
+	}
+	if clusterSpec.KubeControllerManager == nil {
+		clusterSpec.KubeControllerManager = &kops.KubeControllerManagerConfig{}
+	}
+	if clusterSpec.KubeScheduler == nil {
+		clusterSpec.KubeScheduler = &kops.KubeSchedulerConfig{}
+	}
+	clusterSpec.Kubelet.FeatureGates = withCSIMigrationFeatureGates(clusterSpec.Kubelet.FeatureGates, migrationFeatureGate)
+	clusterSpec.KubeControllerManager.FeatureGates = withCSIMigrationFeatureGates(clusterSpec.KubeControllerManager.FeatureGates, migrationFeatureGate)
+	clusterSpec.KubeScheduler.FeatureGates = withCSIMigrationFeatureGates(clusterSpec.KubeScheduler.FeatureGates, migrationFeatureGate)
+
+	return nil
+}
+
+// withCSIMigrationFeatureGates turns on the CSIMigration feature gate and that of the cloud, unless they are
+// already set
+func withCSIMigrationFeatureGates(featureGates map[string]string, migrationFeatureGate string) map[string]string {
+	if featureGates == nil {
+		featureGates = make(map[string]string)
+	}
+	for _, name := range []string{"CSIMigration", migrationFeatureGate} {
+		if _, found := featureGates[name]; !found {
+			featureGates[name] = "true"
+		}
+	}
+	return featureGates
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func buildCSIDriverOptionsBuilder(t *testing.T, c *api.Cluster) *CSIDriverOptionsBuilder {
+	version, err := util.ParseKubernetesVersion(c.Spec.KubernetesVersion)
+	if err != nil {
+		t.Fatalf("unexpected error from ParseKubernetesVersion %s: %v", c.Spec.KubernetesVersion, err)
+	}
+	return &CSIDriverOptionsBuilder{
+		Context: &OptionsContext{
+			AssetBuilder:      assets.NewAssetBuilder(c, ""),
+			KubernetesVersion: *version,
+		},
+	}
+}
+
+func Test_Build_CSIDriver_Migration(t *testing.T) {
+	c := buildCluster()
+	c.Spec.KubernetesVersion = "v1.14.0"
+	c.Spec.CloudConfig = &api.CloudConfiguration{
+		AWSEBSCSIDriver: &api.CSIDriverSpec{Enabled: fi.Bool(true), Migration: fi.Bool(true)},
+	}
+	c.Spec.KubeControllerManager = &api.KubeControllerManagerConfig{
+		FeatureGates: map[string]string{"CSIMigration": "false"},
+	}
+
+	if err := buildCSIDriverOptionsBuilder(t, c).BuildOptions(&c.Spec); err != nil {
+		t.Fatalf("unexpected error from BuildOptions: %v", err)
+	}
+
+	if c.Spec.CloudConfig.AWSEBSCSIDriver.Image != AWSEBSCSIDriverImage {
+		t.Errorf("unexpected image %q", c.Spec.CloudConfig.AWSEBSCSIDriver.Image)
+	}
+	for name, featureGates := range map[string]map[string]string{
+		"kubelet":        c.Spec.Kubelet.FeatureGates,
+		"kube-scheduler": c.Spec.KubeScheduler.FeatureGates,
+	} {
+		if featureGates["CSIMigration"] != "true" || featureGates["CSIMigrationAWS"] != "true" {
+			t.Errorf("expected the CSIMigration feature gates of %s to be on, got %v", name, featureGates)
+		}
+	}
+	if gates := c.Spec.KubeControllerManager.FeatureGates; gates["CSIMigration"] != "false" || gates["CSIMigrationAWS"] != "true" {
+		t.Errorf("expected the feature gates of kube-controller-manager to be kept, got %v", gates)
+	}
+}
+
+func Test_Build_CSIDriver_NoMigration(t *testing.T) {
+	c := buildCluster()
+	c.Spec.KubernetesVersion = "v1.14.0"
+	c.Spec.CloudConfig = &api.CloudConfiguration{
+		AWSEBSCSIDriver: &api.CSIDriverSpec{Enabled: fi.Bool(true), Image: "example.com/aws-ebs-csi-driver:dev"},
+		// The driver of another cloud is ignored
+		GCEPDCSIDriver: &api.CSIDriverSpec{Enabled: fi.Bool(true), Migration: fi.Bool(true)},
+	}
+
+	if err := buildCSIDriverOptionsBuilder(t, c).BuildOptions(&c.Spec); err != nil {
+		t.Fatalf("unexpected error from BuildOptions: %v", err)
+	}
+
+	if c.Spec.CloudConfig.AWSEBSCSIDriver.Image != "example.com/aws-ebs-csi-driver:dev" {
+		t.Errorf("unexpected image %q", c.Spec.CloudConfig.AWSEBSCSIDriver.Image)
+	}
+	if c.Spec.CloudConfig.GCEPDCSIDriver.Image != "" {
+		t.Errorf("expected the GCE PD CSI driver to be ignored")
+	}
+	if c.Spec.Kubelet != nil && len(c.Spec.Kubelet.FeatureGates) != 0 {
+		t.Errorf("expected no feature gates, got %v", c.Spec.Kubelet.FeatureGates)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/loader"
@@ -105,5 +106,21 @@ func (b *DockerOptionsBuilder) BuildOptions(o interface{}) error {
 		docker.Bridge = fi.String("cbr0")
 	}
 
+	// Docker can only mirror docker.io, so the endpoints of those mirrors are passed as registry mirrors to the daemon
+	for i := range clusterSpec.ContainerRegistryMirrors {
+		mirror := &clusterSpec.ContainerRegistryMirrors[i]
+		if mirror.Registry == "" {
+			mirror.Registry = kops.DockerHubRegistry
+		}
+		if mirror.Registry != kops.DockerHubRegistry {
+			continue
+		}
+		for _, endpoint := range mirror.Endpoints {
+			if !sets.NewString(docker.RegistryMirrors...).Has(endpoint) {
+				docker.RegistryMirrors = append(docker.RegistryMirrors, endpoint)
+			}
+		}
+	}
+
 	return nil
 }
@@ -25,6 +25,9 @@ import (
 
 const DefaultBackupImage = "kopeio/etcd-backup:1.0.20180220"
 
+// DefaultEtcdManagerImage is the etcd-manager image used when the cluster spec does not specify one
+const DefaultEtcdManagerImage = "kopeio/etcd-manager:1.0.20180729"
+
 // EtcdOptionsBuilder adds options for etcd to the model
 type EtcdOptionsBuilder struct {
 	Context *OptionsContext
@@ -91,8 +94,7 @@ func (b *EtcdOptionsBuilder) BuildOptions(o interface{}) error {
 			image := c.Manager.Image
 			if image == "" {
 				// We can make this easier later - maybe put it into the channel?  Or an addon?
-				//image = fmt.Sprintf(DefaultManagerImage)
-				return fmt.Errorf("EtcdManager.Image must be specified (currently)")
+				image = DefaultEtcdManagerImage
 			}
 
 			if image != "" {
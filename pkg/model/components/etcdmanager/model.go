@@ -198,6 +198,10 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster *kops.EtcdClusterSpec) (*v1.Po
 
 	config.LogVerbosity = 8
 
+	if etcdCluster.Backups != nil && etcdCluster.Backups.Interval != nil {
+		config.BackupInterval = etcdCluster.Backups.Interval.Duration.String()
+	}
+
 	var envs []v1.EnvVar
 
 	{
@@ -235,7 +239,7 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster *kops.EtcdClusterSpec) (*v1.Po
 			config.VolumeTag = []string{
 				fmt.Sprintf("kubernetes.io/cluster/%s=owned", b.Cluster.Name),
 				awsup.TagNameEtcdClusterPrefix + etcdCluster.Name,
-				awsup.TagNameRolePrefix + "master=1",
+				awsup.TagNameRolePrefix + strings.ToLower(string(b.EtcdClusterRole(etcdCluster))) + "=1",
 			}
 			config.VolumeNameTag = awsup.TagNameEtcdClusterPrefix + etcdCluster.Name
 
@@ -349,6 +353,7 @@ type EtcdManagerConfig struct {
 	QuarantineClientUrls string   `flag:"quarantine-client-urls"`
 	ClusterName          string   `flag:"cluster-name"`
 	BackupStore          string   `flag:"backup-store"`
+	BackupInterval       string   `flag:"backup-interval"`
 	DataDir              string   `flag:"data-dir"`
 	VolumeProvider       string   `flag:"volume-provider"`
 	VolumeTag            []string `flag:"volume-tag,repeat"`
@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"os"
+	"time"
+
+	kopsbase "k8s.io/kops"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi/loader"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KopsControllerDefaultPort is the port the kops-controller listens on by default
+	KopsControllerDefaultPort = 3988
+)
+
+// KopsControllerOptionsBuilder adds the default options for the kops-controller to the model
+type KopsControllerOptionsBuilder struct {
+	Context *OptionsContext
+}
+
+var _ loader.OptionsBuilder = &KopsControllerOptionsBuilder{}
+
+// BuildOptions fills in the image, port and certificate lifetime of an enabled kops-controller
+func (b *KopsControllerOptionsBuilder) BuildOptions(o interface{}) error {
+	clusterSpec := o.(*kops.ClusterSpec)
+	if !clusterSpec.KopsControllerEnabled() {
+		return nil
+	}
+	config := clusterSpec.KopsController
+
+	if config.Image == "" {
+		image, err := b.Context.AssetBuilder.RemapImage(KopsControllerImage())
+		if err != nil {
+			return err
+		}
+		config.Image = image
+	}
+
+	if config.Port == 0 {
+		config.Port = KopsControllerDefaultPort
+	}
+
+	if config.CertificateTTL == nil {
+		// kubelets rotate their certificates before they expire, so we can keep this reasonably short
+		config.CertificateTTL = &metav1.Duration{Duration: 365 * 24 * time.Hour}
+	}
+
+	return nil
+}
+
+// KopsControllerImage returns the image to use for the kops-controller
+func KopsControllerImage() string {
+	if v := os.Getenv("KOPS_CONTROLLER_IMAGE"); v != "" {
+		return v
+	}
+
+	return "kope/kops-controller:" + kopsbase.Version
+}
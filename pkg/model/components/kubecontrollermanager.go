@@ -25,6 +25,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 	"k8s.io/kops/upup/pkg/fi/loader"
 )
@@ -113,11 +114,20 @@ func (b *KubeControllerManagerOptionsBuilder) BuildOptions(o interface{}) error
 	case kops.CloudProviderBareMetal:
 		// No cloudprovider
 
+	case kops.CloudProviderHetzner:
+		// No in-tree cloudprovider
+
 	case kops.CloudProviderOpenstack:
 		kcm.CloudProvider = "openstack"
 
+	case kops.CloudProviderScaleway:
+		// No in-tree cloudprovider
+
 	default:
-		return fmt.Errorf("unknown cloudprovider %q", clusterSpec.CloudProvider)
+		if cloudplugin.Get(kops.CloudProviderID(clusterSpec.CloudProvider)) == nil {
+			return fmt.Errorf("unknown cloudprovider %q", clusterSpec.CloudProvider)
+		}
+		kcm.CloudProvider = "external"
 	}
 
 	if clusterSpec.ExternalCloudControllerManager != nil {
@@ -36,7 +36,9 @@ func (b *KubeDnsOptionsBuilder) BuildOptions(o interface{}) error {
 		clusterSpec.KubeDNS = &kops.KubeDNSConfig{}
 	}
 
-	clusterSpec.KubeDNS.Replicas = 2
+	if clusterSpec.KubeDNS.Replicas == 0 {
+		clusterSpec.KubeDNS.Replicas = 2
+	}
 
 	if clusterSpec.KubeDNS.CacheMaxSize == 0 {
 		clusterSpec.KubeDNS.CacheMaxSize = 1000
@@ -58,5 +60,21 @@ func (b *KubeDnsOptionsBuilder) BuildOptions(o interface{}) error {
 		clusterSpec.KubeDNS.Domain = clusterSpec.ClusterDNSDomain
 	}
 
+	if clusterSpec.KubeDNS.NodeLocalDNSEnabled() {
+		nodeLocalDNS := clusterSpec.KubeDNS.NodeLocalDNS
+		if nodeLocalDNS.LocalIP == "" {
+			nodeLocalDNS.LocalIP = "169.254.20.10"
+		}
+		if nodeLocalDNS.CPURequest == "" {
+			nodeLocalDNS.CPURequest = "25m"
+		}
+		if nodeLocalDNS.MemoryRequest == "" {
+			nodeLocalDNS.MemoryRequest = "5Mi"
+		}
+		if nodeLocalDNS.MemoryLimit == "" {
+			nodeLocalDNS.MemoryLimit = "30Mi"
+		}
+	}
+
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	"k8s.io/kops/upup/pkg/fi/loader"
 )
 
@@ -196,6 +197,10 @@ func (b *KubeletOptionsBuilder) BuildOptions(o interface{}) error {
 		clusterSpec.Kubelet.CloudProvider = "openstack"
 	}
 
+	if cloudplugin.Get(cloudProvider) != nil {
+		clusterSpec.Kubelet.CloudProvider = "external"
+	}
+
 	if clusterSpec.ExternalCloudControllerManager != nil {
 		clusterSpec.Kubelet.CloudProvider = "external"
 	}
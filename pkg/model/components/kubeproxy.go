@@ -72,6 +72,16 @@ func (b *KubeProxyOptionsBuilder) BuildOptions(o interface{}) error {
 		}
 	}
 
+	// ipvs mode is beta before 1.11, and has to be enabled with a feature gate
+	if config.ProxyMode == "ipvs" && b.Context.IsKubernetesLT("1.11") {
+		if config.FeatureGates == nil {
+			config.FeatureGates = make(map[string]string)
+		}
+		if _, found := config.FeatureGates["SupportIPVSProxyMode"]; !found {
+			config.FeatureGates["SupportIPVSProxyMode"] = "true"
+		}
+	}
+
 	// Set the kube-proxy hostname-override (actually the NodeName), to avoid #2915 et al
 	cloudProvider := kops.CloudProviderID(clusterSpec.CloudProvider)
 	if cloudProvider == kops.CloudProviderAWS {
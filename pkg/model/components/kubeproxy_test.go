@@ -0,0 +1,64 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/assets"
+)
+
+func Test_Build_KubeProxy_IPVSFeatureGate(t *testing.T) {
+	grid := []struct {
+		version     string
+		featureGate string
+	}{
+		{version: "v1.9.8", featureGate: "true"},
+		{version: "v1.10.3", featureGate: "true"},
+		{version: "v1.11.0", featureGate: ""},
+	}
+
+	for _, g := range grid {
+		c := buildCluster()
+		c.Spec.KubernetesVersion = g.version
+		c.Spec.Networking = &api.NetworkingSpec{}
+		c.Spec.KubeProxy = &api.KubeProxyConfig{ProxyMode: "ipvs"}
+		b := assets.NewAssetBuilder(c, "")
+
+		version, err := util.ParseKubernetesVersion(g.version)
+		if err != nil {
+			t.Fatalf("unexpected error from ParseKubernetesVersion %s: %v", g.version, err)
+		}
+
+		kp := &KubeProxyOptionsBuilder{
+			Context: &OptionsContext{
+				AssetBuilder:      b,
+				KubernetesVersion: *version,
+			},
+		}
+
+		if err := kp.BuildOptions(&c.Spec); err != nil {
+			t.Fatalf("unexpected error from BuildOptions: %v", err)
+		}
+
+		if actual := c.Spec.KubeProxy.FeatureGates["SupportIPVSProxyMode"]; actual != g.featureGate {
+			t.Errorf("expected SupportIPVSProxyMode feature gate %q for %s, got %q", g.featureGate, g.version, actual)
+		}
+	}
+}
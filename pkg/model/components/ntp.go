@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/loader"
+)
+
+// NTPOptionsBuilder adds the default time synchronization options to the model
+type NTPOptionsBuilder struct {
+	Context *OptionsContext
+}
+
+var _ loader.OptionsBuilder = &NTPOptionsBuilder{}
+
+// BuildOptions enables time synchronization by default, using the time service of the cloud provider
+func (b *NTPOptionsBuilder) BuildOptions(o interface{}) error {
+	clusterSpec := o.(*kops.ClusterSpec)
+	if clusterSpec.NTP == nil {
+		clusterSpec.NTP = &kops.NTPConfig{}
+	}
+	ntp := clusterSpec.NTP
+
+	if ntp.Managed == nil {
+		ntp.Managed = fi.Bool(true)
+	}
+	if !fi.BoolValue(ntp.Managed) {
+		glog.Warningf("time synchronization is not managed by kops; clock skew breaks etcd and TLS, so ensure the instances are kept in sync")
+		return nil
+	}
+
+	if len(ntp.Servers) == 0 {
+		ntp.Servers = DefaultNTPServers(kops.CloudProviderID(clusterSpec.CloudProvider))
+	}
+
+	return nil
+}
+
+// DefaultNTPServers returns the NTP servers for instances running on the cloud provider
+func DefaultNTPServers(cloud kops.CloudProviderID) []string {
+	switch cloud {
+	case kops.CloudProviderAWS:
+		// The Amazon Time Sync Service, available on every instance
+		return []string{"169.254.169.123"}
+	case kops.CloudProviderGCE:
+		return []string{"metadata.google.internal"}
+	default:
+		return []string{"0.pool.ntp.org", "1.pool.ntp.org", "2.pool.ntp.org", "3.pool.ntp.org"}
+	}
+}
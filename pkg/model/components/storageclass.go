@@ -0,0 +1,63 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi/loader"
+)
+
+// DefaultStorageClassReclaimPolicy is the reclaim policy of the default storage class, if none is set
+const DefaultStorageClassReclaimPolicy = "Delete"
+
+// DefaultStorageClassOptionsBuilder adds the options for the default storage class to the model
+type DefaultStorageClassOptionsBuilder struct {
+	Context *OptionsContext
+}
+
+var _ loader.OptionsBuilder = &DefaultStorageClassOptionsBuilder{}
+
+// BuildOptions defaults the volume type and reclaim policy of the default storage class, if kops manages it
+func (b *DefaultStorageClassOptionsBuilder) BuildOptions(o interface{}) error {
+	clusterSpec := o.(*kops.ClusterSpec)
+	if clusterSpec.CloudConfig == nil || clusterSpec.CloudConfig.DefaultStorageClass == nil {
+		return nil
+	}
+	storageClass := clusterSpec.CloudConfig.DefaultStorageClass
+
+	if storageClass.Type == "" {
+		storageClass.Type = DefaultStorageClassType(kops.CloudProviderID(clusterSpec.CloudProvider))
+	}
+
+	if storageClass.ReclaimPolicy == "" {
+		storageClass.ReclaimPolicy = DefaultStorageClassReclaimPolicy
+	}
+
+	return nil
+}
+
+// DefaultStorageClassType returns the volume type of the default storage class on the cloud, if none is set
+func DefaultStorageClassType(cloudProvider kops.CloudProviderID) string {
+	switch cloudProvider {
+	case kops.CloudProviderAWS:
+		return "gp2"
+	case kops.CloudProviderGCE:
+		return "pd-standard"
+	default:
+		return ""
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/upup/pkg/fi"
@@ -154,6 +155,35 @@ func (m *KopsModelContext) MasterInstanceGroups() []*kops.InstanceGroup {
 	return groups
 }
 
+// EtcdInstanceGroups returns InstanceGroups with the etcd role
+func (m *KopsModelContext) EtcdInstanceGroups() []*kops.InstanceGroup {
+	var groups []*kops.InstanceGroup
+	for _, ig := range m.InstanceGroups {
+		if ig.Spec.Role != kops.InstanceGroupRoleEtcd {
+			continue
+		}
+		groups = append(groups, ig)
+	}
+	return groups
+}
+
+// APIServerInstanceGroups returns InstanceGroups with the apiserver role
+func (m *KopsModelContext) APIServerInstanceGroups() []*kops.InstanceGroup {
+	var groups []*kops.InstanceGroup
+	for _, ig := range m.InstanceGroups {
+		if !ig.IsAPIServer() {
+			continue
+		}
+		groups = append(groups, ig)
+	}
+	return groups
+}
+
+// EtcdClusterRole returns the role of the instance groups that host the members of the etcd cluster
+func (m *KopsModelContext) EtcdClusterRole(etcdCluster *kops.EtcdClusterSpec) kops.InstanceGroupRole {
+	return model.EtcdClusterRole(etcdCluster, m.InstanceGroups)
+}
+
 // NodeInstanceGroups returns InstanceGroups with the node role
 func (m *KopsModelContext) NodeInstanceGroups() []*kops.InstanceGroup {
 	var groups []*kops.InstanceGroup
@@ -207,6 +237,14 @@ func (m *KopsModelContext) CloudTagsForInstanceGroup(ig *kops.InstanceGroup) (ma
 		labels[awstasks.CloudTagInstanceGroupRolePrefix+strings.ToLower(string(kops.InstanceGroupRoleBastion))] = "1"
 	}
 
+	if ig.Spec.Role == kops.InstanceGroupRoleEtcd {
+		labels[awstasks.CloudTagInstanceGroupRolePrefix+strings.ToLower(string(kops.InstanceGroupRoleEtcd))] = "1"
+	}
+
+	if ig.Spec.Role == kops.InstanceGroupRoleAPIServer {
+		labels[awstasks.CloudTagInstanceGroupRolePrefix+strings.ToLower(string(kops.InstanceGroupRoleAPIServer))] = "1"
+	}
+
 	return labels, nil
 }
 
@@ -277,6 +315,11 @@ func (m *KopsModelContext) UsesSSHBastion() bool {
 	return false
 }
 
+// UsesSessionManager checks if the instances are accessed through AWS Session Manager rather than SSH
+func (m *KopsModelContext) UsesSessionManager() bool {
+	return m.Cluster.Spec.Topology != nil && m.Cluster.Spec.Topology.SessionManager != nil
+}
+
 // UseLoadBalancerForAPI checks if we are using a load balancer for the kubeapi
 func (m *KopsModelContext) UseLoadBalancerForAPI() bool {
 	if m.Cluster.Spec.API == nil {
@@ -295,6 +338,10 @@ func (m *KopsModelContext) UseLoadBalancerForInternalAPI() bool {
 
 // UsePrivateDNS checks if we are using private DNS
 func (m *KopsModelContext) UsePrivateDNS() bool {
+	if m.Cluster.Spec.DNSZoneOptions != nil && m.Cluster.Spec.DNSZoneOptions.Private {
+		return true
+	}
+
 	topology := m.Cluster.Spec.Topology
 	if topology != nil && topology.DNS != nil {
 		switch topology.DNS.Type {
@@ -312,6 +359,18 @@ func (m *KopsModelContext) UsePrivateDNS() bool {
 	return false
 }
 
+// UseSplitHorizonDNS checks if the API record should also be published in the public zone
+func (m *KopsModelContext) UseSplitHorizonDNS() bool {
+	options := m.Cluster.Spec.DNSZoneOptions
+	return options != nil && options.Private && options.SplitHorizon
+}
+
+// UseExternalDNSProvider returns true if the DNS records are managed by a DNS provider plugin (e.g. CloudFlare),
+// rather than by the DNS service of the cloud
+func (m *KopsModelContext) UseExternalDNSProvider() bool {
+	return dns.ExternalProviderID(m.Cluster) != ""
+}
+
 // UseEtcdTLS checks to see if etcd tls is enabled
 func (c *KopsModelContext) UseEtcdTLS() bool {
 	for _, x := range c.Cluster.Spec.EtcdClusters {
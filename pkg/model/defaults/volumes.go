@@ -31,7 +31,7 @@ const (
 // DefaultInstanceGroupVolumeSize returns the default volume size for nodes in an InstanceGroup with the specified role
 func DefaultInstanceGroupVolumeSize(role kops.InstanceGroupRole) (int32, error) {
 	switch role {
-	case kops.InstanceGroupRoleMaster:
+	case kops.InstanceGroupRoleMaster, kops.InstanceGroupRoleEtcd, kops.InstanceGroupRoleAPIServer:
 		return DefaultVolumeSizeMaster, nil
 	case kops.InstanceGroupRoleNode:
 		return DefaultVolumeSizeNode, nil
@@ -60,6 +60,19 @@ func (b *DNSModelBuilder) ensureDNSZone(c *fi.ModelBuilderContext) error {
 		}
 	}
 
+	if options := b.Cluster.Spec.DNSZoneOptions; options != nil && options.Private {
+		dnsZone.Private = fi.Bool(true)
+		dnsZone.PrivateVPC = b.LinkToVPC()
+
+		for _, vpc := range options.AdditionalVPCs {
+			region := vpc.Region
+			if region == "" {
+				region = b.Region
+			}
+			dnsZone.AdditionalVPCs = append(dnsZone.AdditionalVPCs, awstasks.DNSZoneVPC{ID: vpc.ID, Region: region})
+		}
+	}
+
 	if !strings.Contains(b.Cluster.Spec.DNSZone, ".") {
 		// Looks like a hosted zone ID
 		dnsZone.ZoneID = s(b.Cluster.Spec.DNSZone)
@@ -71,7 +84,36 @@ func (b *DNSModelBuilder) ensureDNSZone(c *fi.ModelBuilderContext) error {
 	return c.EnsureTask(dnsZone)
 }
 
+// ensurePublicDNSZone adds the public zone used for split-horizon DNS; unlike the private zone, kops never creates it,
+// because it must be delegated to
+func (b *DNSModelBuilder) ensurePublicDNSZone(c *fi.ModelBuilderContext) error {
+	lifecycle := b.Lifecycle
+	if lifecycle != nil && *lifecycle == fi.LifecycleSync {
+		existsAndValidates := fi.LifecycleExistsAndValidates
+		lifecycle = &existsAndValidates
+	}
+
+	dnsZone := &awstasks.DNSZone{
+		Name:      s(b.NameForPublicDNSZone()),
+		Lifecycle: lifecycle,
+		Private:   fi.Bool(false),
+	}
+
+	if publicZone := b.Cluster.Spec.DNSZoneOptions.PublicZone; publicZone != "" {
+		dnsZone.ZoneID = s(publicZone)
+	} else {
+		dnsZone.DNSName = s(b.Cluster.Spec.DNSZone)
+	}
+
+	return c.EnsureTask(dnsZone)
+}
+
 func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.UseExternalDNSProvider() {
+		// The zone is not in Route53; dns-controller and protokube manage the records through the provider plugin
+		return nil
+	}
+
 	// Add a HostedZone if we are going to publish a dns record that depends on it
 	if b.UsePrivateDNS() {
 		// Check to see if we are using a bastion DNS record that points to the hosted zone
@@ -108,6 +150,23 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 				TargetLoadBalancer: b.LinkToELB("api"),
 			}
 			c.AddTask(apiDnsName)
+
+			if b.UseSplitHorizonDNS() {
+				// Publish (only) the API record in the public zone, so that it can be reached from outside the VPC
+				if err := b.ensurePublicDNSZone(c); err != nil {
+					return err
+				}
+
+				publicApiDnsName := &awstasks.DNSName{
+					Name:               s("public-" + b.Cluster.Spec.MasterPublicName),
+					RecordName:         s(b.Cluster.Spec.MasterPublicName),
+					Lifecycle:          b.Lifecycle,
+					Zone:               b.LinkToPublicDNSZone(),
+					ResourceType:       s("A"),
+					TargetLoadBalancer: b.LinkToELB("api"),
+				}
+				c.AddTask(publicApiDnsName)
+			}
 		}
 	}
 
@@ -47,6 +47,9 @@ func (b *ExternalAccessModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		// This is admittedly a little odd... adding a bastion shuts down direct access to the masters/nodes
 		// But I think we can always add more permissions in this case later, but we can't easily take them away
 		glog.V(2).Infof("bastion is in use; won't configure SSH access to master / node instances")
+	} else if b.UsesSessionManager() {
+		// Session Manager connects out from the instances, so no inbound rules are needed
+		glog.V(2).Infof("session manager is in use; won't configure SSH access to master / node instances")
 	} else {
 		for _, sshAccess := range b.Cluster.Spec.SSHAccess {
 			c.AddTask(&awstasks.SecurityGroupRule{
@@ -68,6 +71,18 @@ func (b *ExternalAccessModelBuilder) Build(c *fi.ModelBuilderContext) error {
 				ToPort:        i64(22),
 				CIDR:          s(sshAccess),
 			})
+
+			if len(b.EtcdInstanceGroups()) != 0 {
+				c.AddTask(&awstasks.SecurityGroupRule{
+					Name:          s("ssh-external-to-etcd-" + sshAccess),
+					Lifecycle:     b.Lifecycle,
+					SecurityGroup: b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+					Protocol:      s("tcp"),
+					FromPort:      i64(22),
+					ToPort:        i64(22),
+					CIDR:          s(sshAccess),
+				})
+			}
 		}
 	}
 
@@ -48,6 +48,9 @@ func (b *FirewallModelBuilder) Build(c *fi.ModelBuilderContext) error {
 	if err := b.buildMasterRules(c); err != nil {
 		return err
 	}
+	if err := b.buildEtcdRules(c); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -61,6 +64,7 @@ func (b *FirewallModelBuilder) buildNodeRules(c *fi.ModelBuilderContext) error {
 			RemoveExtraRules: []string{"port=22"},
 		}
 		t.Tags = b.CloudTags(*t.Name, false)
+		UseSecurityGroupOverride(t, b.SecurityGroupOverride(kops.InstanceGroupRoleNode))
 		c.AddTask(t)
 	}
 
@@ -348,6 +352,7 @@ func (b *FirewallModelBuilder) buildMasterRules(c *fi.ModelBuilderContext) error
 			},
 		}
 		t.Tags = b.CloudTags(*t.Name, false)
+		UseSecurityGroupOverride(t, b.SecurityGroupOverride(kops.InstanceGroupRoleMaster))
 		c.AddTask(t)
 	}
 
@@ -387,3 +392,132 @@ func (b *FirewallModelBuilder) buildMasterRules(c *fi.ModelBuilderContext) error
 
 	return nil
 }
+
+// buildEtcdRules configures the security group for dedicated etcd instances, if there are any
+func (b *FirewallModelBuilder) buildEtcdRules(c *fi.ModelBuilderContext) error {
+	if len(b.EtcdInstanceGroups()) == 0 {
+		return nil
+	}
+
+	{
+		t := &awstasks.SecurityGroup{
+			Name:        s(b.SecurityGroupName(kops.InstanceGroupRoleEtcd)),
+			Lifecycle:   b.Lifecycle,
+			VPC:         b.LinkToVPC(),
+			Description: s("Security group for etcd"),
+			RemoveExtraRules: []string{
+				"port=22",   // SSH
+				"port=2380", // etcd main peer
+				"port=2381", // etcd events peer
+				"port=4001", // etcd main
+				"port=4002", // etcd events
+			},
+		}
+		t.Tags = b.CloudTags(*t.Name, false)
+		UseSecurityGroupOverride(t, b.SecurityGroupOverride(kops.InstanceGroupRoleEtcd))
+		c.AddTask(t)
+	}
+
+	// Allow full egress
+	{
+		t := &awstasks.SecurityGroupRule{
+			Name:          s("etcd-egress"),
+			Lifecycle:     b.Lifecycle,
+			SecurityGroup: b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+			Egress:        fi.Bool(true),
+			CIDR:          s("0.0.0.0/0"),
+		}
+		c.AddTask(t)
+	}
+
+	// etcd members can talk to each other (peer traffic, and etcd-manager coordination)
+	{
+		t := &awstasks.SecurityGroupRule{
+			Name:          s("all-etcd-to-etcd"),
+			Lifecycle:     b.Lifecycle,
+			SecurityGroup: b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+			SourceGroup:   b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+		}
+		c.AddTask(t)
+	}
+
+	// The apiservers on the masters are the etcd clients
+	for _, port := range []int64{4001, 4002} {
+		t := &awstasks.SecurityGroupRule{
+			Name:          s(fmt.Sprintf("master-to-etcd-tcp-%d", port)),
+			Lifecycle:     b.Lifecycle,
+			SecurityGroup: b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+			SourceGroup:   b.LinkToSecurityGroup(kops.InstanceGroupRoleMaster),
+			Protocol:      s("tcp"),
+			FromPort:      i64(port),
+			ToPort:        i64(port),
+		}
+		c.AddTask(t)
+	}
+
+	// Calico and Cilium store their state in the main etcd cluster, so nodes need to reach it
+	if b.Cluster.Spec.Networking != nil && (b.Cluster.Spec.Networking.Calico != nil || b.Cluster.Spec.Networking.Cilium != nil) {
+		t := &awstasks.SecurityGroupRule{
+			Name:          s("node-to-etcd-tcp-4001"),
+			Lifecycle:     b.Lifecycle,
+			SecurityGroup: b.LinkToSecurityGroup(kops.InstanceGroupRoleEtcd),
+			SourceGroup:   b.LinkToSecurityGroup(kops.InstanceGroupRoleNode),
+			Protocol:      s("tcp"),
+			FromPort:      i64(4001),
+			ToPort:        i64(4001),
+		}
+		c.AddTask(t)
+	}
+
+	return nil
+}
+
+// UseSecurityGroupOverride makes t refer to the pre-existing security group with the given ID, if any.
+// kops never creates, tags or removes rules from an overridden security group.
+func UseSecurityGroupOverride(t *awstasks.SecurityGroup, id *string) {
+	if id == nil {
+		return
+	}
+	t.ID = id
+	t.Shared = fi.Bool(true)
+	t.Description = nil
+	t.Tags = nil
+	t.RemoveExtraRules = nil
+}
+
+// SecurityGroupOverrideModelBuilder removes the rules other builders add to overridden security groups,
+// unless the cluster uses the Additive SecurityGroupOverrideMode
+type SecurityGroupOverrideModelBuilder struct {
+	*KopsModelContext
+}
+
+var _ fi.ModelBuilder = &SecurityGroupOverrideModelBuilder{}
+
+func (b *SecurityGroupOverrideModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.Cluster.Spec.SecurityGroupOverrideMode == kops.SecurityGroupOverrideModeAdditive {
+		return nil
+	}
+
+	unmanaged := make(map[string]bool)
+	for _, task := range c.Tasks {
+		if sg, ok := task.(*awstasks.SecurityGroup); ok && fi.BoolValue(sg.Shared) {
+			unmanaged[fi.StringValue(sg.Name)] = true
+		}
+	}
+	if len(unmanaged) == 0 {
+		return nil
+	}
+
+	for key, task := range c.Tasks {
+		rule, ok := task.(*awstasks.SecurityGroupRule)
+		if !ok || rule.SecurityGroup == nil {
+			continue
+		}
+		if unmanaged[fi.StringValue(rule.SecurityGroup.Name)] {
+			glog.V(4).Infof("not adding rule %q to unmanaged security group %q", fi.StringValue(rule.Name), fi.StringValue(rule.SecurityGroup.Name))
+			delete(c.Tasks, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+)
+
+func TestSecurityGroupOverride(t *testing.T) {
+	for _, mode := range []string{"", kops.SecurityGroupOverrideModeAdditive} {
+		cluster := &kops.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test.k8s.local",
+			},
+		}
+		cluster.Spec.Networking = &kops.NetworkingSpec{Kubenet: &kops.KubenetNetworkingSpec{}}
+		cluster.Spec.SecurityGroupOverrideMode = mode
+
+		lifecycle := fi.LifecycleSync
+		modelContext := &KopsModelContext{
+			Cluster: cluster,
+			InstanceGroups: []*kops.InstanceGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "master"},
+					Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleMaster},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+					Spec:       kops.InstanceGroupSpec{Role: kops.InstanceGroupRoleNode, SecurityGroupOverride: fi.String("sg-12345678")},
+				},
+			},
+		}
+
+		c := &fi.ModelBuilderContext{
+			Tasks: make(map[string]fi.Task),
+		}
+		builders := []fi.ModelBuilder{
+			&FirewallModelBuilder{KopsModelContext: modelContext, Lifecycle: &lifecycle},
+			&SecurityGroupOverrideModelBuilder{KopsModelContext: modelContext},
+		}
+		for _, builder := range builders {
+			if err := builder.Build(c); err != nil {
+				t.Fatalf("error building model: %v", err)
+			}
+		}
+
+		nodeGroupName := modelContext.SecurityGroupName(kops.InstanceGroupRoleNode)
+		nodeRules := 0
+		masterRules := 0
+		for _, task := range c.Tasks {
+			switch task := task.(type) {
+			case *awstasks.SecurityGroup:
+				if fi.StringValue(task.Name) != nodeGroupName {
+					continue
+				}
+				if !fi.BoolValue(task.Shared) || fi.StringValue(task.ID) != "sg-12345678" {
+					t.Errorf("mode %q: expected the node security group to be overridden, got %v", mode, task)
+				}
+				if len(task.RemoveExtraRules) != 0 {
+					t.Errorf("mode %q: kops must not remove rules from an overridden security group", mode)
+				}
+			case *awstasks.SecurityGroupRule:
+				if fi.StringValue(task.SecurityGroup.Name) == nodeGroupName {
+					nodeRules++
+				} else {
+					masterRules++
+				}
+			}
+		}
+
+		if mode == kops.SecurityGroupOverrideModeAdditive && nodeRules == 0 {
+			t.Errorf("mode %q: expected rules to be added to the overridden security group", mode)
+		}
+		if mode != kops.SecurityGroupOverrideModeAdditive && nodeRules != 0 {
+			t.Errorf("mode %q: expected no rules on the overridden security group, found %d", mode, nodeRules)
+		}
+		if masterRules == 0 {
+			t.Errorf("mode %q: expected rules on the kops-managed master security group", mode)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package gcemodel
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/golang/glog"
@@ -105,7 +106,7 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			if len(storagePaths) == 0 {
 				t.Scopes = append(t.Scopes, "storage-ro")
 			} else {
-				glog.Warningf("enabling storage-rw for etcd backups")
+				glog.Warningf("enabling storage-rw for etcd backups and boot reports")
 				t.Scopes = append(t.Scopes, "storage-rw")
 			}
 
@@ -153,11 +154,24 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			minSize = 2
 		}
 
-		// We have to assign instances to the various zones
-		// TODO: Switch to regional managed instance group
-		// But we can't yet use RegionInstanceGroups:
-		// 1) no support in terraform
-		// 2) we can't steer to specific zones AFAICT, only to all zones in the region
+		// A regional managed instance group spreads its instances evenly across the zones itself
+		if fi.BoolValue(ig.Spec.Regional) {
+			t := &gcetasks.InstanceGroupManager{
+				Name:              s(gce.NameForRegionInstanceGroupManager(b.Cluster, ig)),
+				Lifecycle:         b.Lifecycle,
+				Region:            s(b.Region),
+				DistributionZones: append([]string(nil), zones...),
+				TargetSize:        fi.Int64(int64(minSize)),
+				BaseInstanceName:  s(ig.ObjectMeta.Name),
+				InstanceTemplate:  instanceTemplate,
+			}
+			sort.Strings(t.DistributionZones)
+
+			c.AddTask(t)
+			continue
+		}
+
+		// Otherwise we have to assign instances to the various zones
 
 		targetSizes := make([]int, len(zones), len(zones))
 		totalSize := 0
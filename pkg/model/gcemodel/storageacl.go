@@ -83,7 +83,7 @@ func (b *StorageAclBuilder) Build(c *fi.ModelBuilderContext) error {
 				continue
 			}
 
-			glog.Warningf("adding bucket level write ACL to gs://%s to support etcd backup", bucket)
+			glog.Warningf("adding bucket level write ACL to gs://%s to support etcd backups and boot reports", bucket)
 
 			c.AddTask(&gcetasks.StorageBucketAcl{
 				Name:      s("serviceaccount-backup-readwrite-" + bucket),
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznermodel
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetznertasks"
+	"k8s.io/kops/upup/pkg/fi/fitasks"
+)
+
+// DefaultLoadBalancerType is the smallest Hetzner Cloud load balancer, which is plenty for the API
+const DefaultLoadBalancerType = "lb11"
+
+// APILoadBalancerBuilder builds a load balancer in front of the masters
+type APILoadBalancerBuilder struct {
+	*HetznerModelContext
+	Lifecycle *fi.Lifecycle
+}
+
+var _ fi.ModelBuilder = &APILoadBalancerBuilder{}
+
+func (b *APILoadBalancerBuilder) Build(c *fi.ModelBuilderContext) error {
+	if !b.UseLoadBalancerForAPI() {
+		return nil
+	}
+
+	lbSpec := b.Cluster.Spec.API.LoadBalancer
+	switch lbSpec.Type {
+	case kops.LoadBalancerTypePublic, "":
+		// OK
+	case kops.LoadBalancerTypeInternal:
+		return errors.New("internal load balancers are not yet supported by kops on Hetzner Cloud")
+	default:
+		return fmt.Errorf("unhandled LoadBalancer type %q", lbSpec.Type)
+	}
+
+	if len(b.Cluster.Spec.Subnets) == 0 {
+		return fmt.Errorf("cluster has no subnets")
+	}
+
+	lb := &hetznertasks.LoadBalancer{
+		Name:           fi.String("api." + b.ClusterName()),
+		Lifecycle:      b.Lifecycle,
+		Location:       fi.String(b.Cluster.Spec.Subnets[0].Zone),
+		Type:           fi.String(DefaultLoadBalancerType),
+		Network:        b.LinkToNetwork(),
+		Port:           fi.Int(443),
+		TargetSelector: fi.String(b.MasterSelector()),
+	}
+	c.AddTask(lb)
+
+	if dns.IsGossipHostname(b.Cluster.Name) {
+		// Ensure the load balancer address is included in the TLS certificate, as it is how the API is reached
+		masterKeypairTask, found := c.Tasks["Keypair/master"]
+		if !found {
+			return errors.New("keypair/master task not found")
+		}
+		masterKeypair := masterKeypairTask.(*fitasks.Keypair)
+		masterKeypair.AlternateNameTasks = append(masterKeypair.AlternateNameTasks, lb)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznermodel
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
+	kopsmodel "k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetznertasks"
+)
+
+// HetznerModelContext is the model context for Hetzner Cloud
+type HetznerModelContext struct {
+	*kopsmodel.KopsModelContext
+}
+
+// LinkToNetwork returns the private network of the cluster
+func (c *HetznerModelContext) LinkToNetwork() *hetznertasks.Network {
+	return &hetznertasks.Network{Name: fi.String(c.ClusterName())}
+}
+
+// LinkToSSHKey returns the SSH key of the cluster
+func (c *HetznerModelContext) LinkToSSHKey() (*hetznertasks.SSHKey, error) {
+	if len(c.SSHPublicKeys) == 0 {
+		return nil, fmt.Errorf("SSH public key must be specified when running with Hetzner Cloud (create with `kops create secret --name %s sshpublickey admin -i ~/.ssh/id_rsa.pub`)", c.ClusterName())
+	}
+	name, err := c.SSHKeyName()
+	if err != nil {
+		return nil, err
+	}
+	return &hetznertasks.SSHKey{Name: fi.String(name)}, nil
+}
+
+// Location returns the location the servers of the instance group are created in
+func (c *HetznerModelContext) Location(ig *kops.InstanceGroup) (string, error) {
+	zones, err := model.FindZonesForInstanceGroup(c.Cluster, ig)
+	if err != nil {
+		return "", err
+	}
+	if len(zones) == 0 {
+		return "", fmt.Errorf("must specify a subnet for instancegroup %q", ig.ObjectMeta.Name)
+	}
+	return zones[0], nil
+}
+
+// RoleLabel returns the value of the role label of the servers of the instance group
+func RoleLabel(ig *kops.InstanceGroup) string {
+	return strings.ToLower(string(ig.Spec.Role))
+}
+
+// MasterSelector returns the label selector matching the masters of the cluster
+func (c *HetznerModelContext) MasterSelector() string {
+	return hetzner.LabelSelector(map[string]string{
+		hetzner.LabelCluster:      c.ClusterName(),
+		hetzner.LabelInstanceRole: strings.ToLower(string(kops.InstanceGroupRoleMaster)),
+	})
+}
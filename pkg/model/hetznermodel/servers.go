@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznermodel
+
+import (
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetznertasks"
+)
+
+// ServerGroupModelBuilder configures the servers of each instance group
+type ServerGroupModelBuilder struct {
+	*HetznerModelContext
+
+	BootstrapScript *model.BootstrapScript
+	Lifecycle       *fi.Lifecycle
+}
+
+var _ fi.ModelBuilder = &ServerGroupModelBuilder{}
+
+func (b *ServerGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	sshKey, err := b.LinkToSSHKey()
+	if err != nil {
+		return err
+	}
+
+	for _, ig := range b.InstanceGroups {
+		name := b.AutoscalingGroupName(ig)
+
+		location, err := b.Location(ig)
+		if err != nil {
+			return err
+		}
+
+		userData, err := b.BootstrapScript.ResourceNodeUp(ig, b.Cluster)
+		if err != nil {
+			return err
+		}
+
+		// Hetzner Cloud has no autoscaling, so the group is kept at its minimum size
+		count := int(fi.Int32Value(ig.Spec.MinSize))
+
+		group := &hetznertasks.ServerGroup{
+			Name:          fi.String(name),
+			Lifecycle:     b.Lifecycle,
+			InstanceGroup: fi.String(ig.ObjectMeta.Name),
+			Role:          fi.String(RoleLabel(ig)),
+			Count:         fi.Int(count),
+			ServerType:    fi.String(ig.Spec.MachineType),
+			Image:         fi.String(ig.Spec.Image),
+			Location:      fi.String(location),
+			SSHKey:        sshKey,
+			Network:       b.LinkToNetwork(),
+			UserData:      userData,
+		}
+
+		// Spread the servers of the group across hosts, as far as a placement group allows
+		if count > 1 && count <= hetzner.MaxSpreadPlacementGroupSize {
+			placementGroup := &hetznertasks.PlacementGroup{
+				Name:      fi.String(name),
+				Lifecycle: b.Lifecycle,
+			}
+			c.AddTask(placementGroup)
+			group.PlacementGroup = placementGroup
+		}
+
+		c.AddTask(group)
+	}
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
 
 // IAMModelBuilder configures IAM objects
@@ -55,18 +56,24 @@ func (b *IAMModelBuilder) Build(c *fi.ModelBuilderContext) error {
 	// Collect Instance Profile ARNs and their associated Instance Group roles
 	sharedProfileARNsToIGRole := make(map[string]kops.InstanceGroupRole)
 	for _, ig := range b.InstanceGroups {
+		role := ig.Spec.Role
+		if role == kops.InstanceGroupRoleAPIServer {
+			// apiserver-only instances share the masters IAM role
+			role = kops.InstanceGroupRoleMaster
+		}
+
 		if ig.Spec.IAM != nil && ig.Spec.IAM.Profile != nil {
 			specProfile := fi.StringValue(ig.Spec.IAM.Profile)
 			if matchingRole, ok := sharedProfileARNsToIGRole[specProfile]; ok {
-				if matchingRole != ig.Spec.Role {
+				if matchingRole != role {
 					return fmt.Errorf("Found IAM instance profile assigned to multiple Instance Group roles %v and %v: %v",
-						ig.Spec.Role, sharedProfileARNsToIGRole[specProfile], specProfile)
+						role, sharedProfileARNsToIGRole[specProfile], specProfile)
 				}
 			} else {
-				sharedProfileARNsToIGRole[specProfile] = ig.Spec.Role
+				sharedProfileARNsToIGRole[specProfile] = role
 			}
 		} else {
-			managedRoles[ig.Spec.Role] = true
+			managedRoles[role] = true
 		}
 	}
 
@@ -212,16 +219,9 @@ func (b *IAMModelBuilder) buildIAMTasks(igRole kops.InstanceGroupRole, iamName s
 func (b *IAMModelBuilder) buildAWSIAMRolePolicy() (fi.Resource, error) {
 	functions := template.FuncMap{
 		"IAMServiceEC2": func() string {
-			// IAMServiceEC2 returns the name of the IAM service for EC2 in the current region
-			// it is ec2.amazonaws.com everywhere but in cn-north, where it is ec2.amazonaws.com.cn
-			switch b.Region {
-			case "cn-north-1":
-				return "ec2.amazonaws.com.cn"
-			case "cn-northwest-1":
-				return "ec2.amazonaws.com.cn"
-			default:
-				return "ec2.amazonaws.com"
-			}
+			// IAMServiceEC2 returns the name of the IAM service for EC2 in the current partition
+			// it is ec2.amazonaws.com everywhere but in the China partition, where it is ec2.amazonaws.com.cn
+			return awsup.ServicePrincipal(awsup.PartitionForCluster(b.Cluster, b.Region), "ec2")
 		},
 	}
 
@@ -37,9 +37,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/bootreport"
 	"k8s.io/kops/pkg/util/stringorslice"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -139,10 +141,19 @@ func (b *PolicyBuilder) BuildAWSPolicy() (*Policy, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate AWS IAM Policy for Master Instance Group: %v", err)
 		}
+	case kops.InstanceGroupRoleEtcd:
+		p, err = b.BuildAWSPolicyEtcd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate AWS IAM Policy for Etcd Instance Group: %v", err)
+		}
 	default:
 		return nil, fmt.Errorf("unrecognised instance group type: %s", b.Role)
 	}
 
+	if b.Cluster.Spec.Topology != nil && b.Cluster.Spec.Topology.SessionManager != nil && b.Role != kops.InstanceGroupRoleBastion {
+		addSessionManagerPermissions(p)
+	}
+
 	return p, nil
 }
 
@@ -159,6 +170,10 @@ func (b *PolicyBuilder) BuildAWSPolicyMaster() (*Policy, error) {
 	addMasterELBPolicies(p, resource, b.Cluster.Spec.IAM.Legacy)
 	addCertIAMPolicies(p, resource)
 
+	if b.Cluster.Spec.KopsControllerEnabled() {
+		addKopsControllerPermissions(p, resource)
+	}
+
 	var err error
 	if p, err = b.AddS3Permissions(p); err != nil {
 		return nil, fmt.Errorf("failed to generate AWS IAM S3 access statements: %v", err)
@@ -168,11 +183,9 @@ func (b *PolicyBuilder) BuildAWSPolicyMaster() (*Policy, error) {
 		addKMSIAMPolicies(p, stringorslice.Slice(b.KMSKeys), b.Cluster.Spec.IAM.Legacy)
 	}
 
-	if b.HostedZoneID != "" {
-		addRoute53Permissions(p, b.HostedZoneID)
-	}
+	b.addHostedZonePermissions(p)
 
-	if b.Cluster.Spec.IAM.Legacy {
+	if b.Cluster.Spec.IAM.Legacy && b.hasRoute53() {
 		addRoute53ListHostedZonesPermission(p)
 	}
 
@@ -206,10 +219,8 @@ func (b *PolicyBuilder) BuildAWSPolicyNode() (*Policy, error) {
 		return nil, fmt.Errorf("failed to generate AWS IAM S3 access statements: %v", err)
 	}
 
-	if b.Cluster.Spec.IAM.Legacy {
-		if b.HostedZoneID != "" {
-			addRoute53Permissions(p, b.HostedZoneID)
-		}
+	if b.Cluster.Spec.IAM.Legacy && b.hasRoute53() {
+		b.addHostedZonePermissions(p)
 		addRoute53ListHostedZonesPermission(p)
 	}
 
@@ -224,6 +235,39 @@ func (b *PolicyBuilder) BuildAWSPolicyNode() (*Policy, error) {
 	return p, nil
 }
 
+// BuildAWSPolicyEtcd generates a custom policy for a dedicated etcd instance.
+// This is the subset of the master policy needed to mount the etcd volumes, publish the etcd DNS names and read the state store.
+func (b *PolicyBuilder) BuildAWSPolicyEtcd() (*Policy, error) {
+	resource := createResource(b)
+
+	p := &Policy{
+		Version: PolicyDefaultVersion,
+	}
+
+	addEtcdEC2Policies(p, resource, b.Cluster.Spec.IAM.Legacy, b.Cluster.GetName())
+
+	var err error
+	if p, err = b.AddS3Permissions(p); err != nil {
+		return nil, fmt.Errorf("failed to generate AWS IAM S3 access statements: %v", err)
+	}
+
+	if b.KMSKeys != nil && len(b.KMSKeys) != 0 {
+		addKMSIAMPolicies(p, stringorslice.Slice(b.KMSKeys), b.Cluster.Spec.IAM.Legacy)
+	}
+
+	b.addHostedZonePermissions(p)
+
+	if b.Cluster.Spec.IAM.Legacy && b.hasRoute53() {
+		addRoute53ListHostedZonesPermission(p)
+	}
+
+	if b.Cluster.Spec.IAM.Legacy || b.Cluster.Spec.IAM.AllowContainerRegistry {
+		addECRPermissions(p)
+	}
+
+	return p, nil
+}
+
 // BuildAWSPolicyBastion generates a custom policy for a bastion host.
 func (b *PolicyBuilder) BuildAWSPolicyBastion() (*Policy, error) {
 	resource := createResource(b)
@@ -243,18 +287,36 @@ func (b *PolicyBuilder) BuildAWSPolicyBastion() (*Policy, error) {
 	return p, nil
 }
 
-// IAMPrefix returns the prefix for AWS ARNs in the current region, for use with IAM
-// it is arn:aws everywhere but in cn-north and us-gov-west-1
+// IAMPrefix returns the prefix for AWS ARNs in the current partition, for use with IAM
+// it is arn:aws everywhere but in the China and GovCloud partitions
 func (b *PolicyBuilder) IAMPrefix() string {
-	switch b.Region {
-	case "cn-north-1":
-		return "arn:aws-cn"
-	case "cn-northwest-1":
-		return "arn:aws-cn"
-	case "us-gov-west-1":
-		return "arn:aws-us-gov"
-	default:
-		return "arn:aws"
+	return awsup.ARNPrefix(b.partition())
+}
+
+// partition returns the AWS partition of the cluster, which may be overridden in the cluster spec
+func (b *PolicyBuilder) partition() string {
+	return awsup.PartitionForCluster(b.Cluster, b.Region)
+}
+
+// hasRoute53 returns false in partitions without Route53, where clusters use gossip or an external DNS provider
+func (b *PolicyBuilder) hasRoute53() bool {
+	return awsup.PartitionHasService(b.partition(), "route53")
+}
+
+// addHostedZonePermissions grants access to the records of the cluster's hosted zone. When the records are managed
+// with another role, e.g. because the zone is in another account, only that role may be assumed.
+func (b *PolicyBuilder) addHostedZonePermissions(p *Policy) {
+	if b.Cluster.Spec.CloudConfig != nil && b.Cluster.Spec.CloudConfig.AWSDNSAssumeRole != nil && b.Cluster.Spec.CloudConfig.AWSDNSAssumeRole.RoleARN != "" {
+		p.Statement = append(p.Statement, &Statement{
+			Effect:   StatementEffectAllow,
+			Action:   stringorslice.Of("sts:AssumeRole", "sts:TagSession"),
+			Resource: stringorslice.Slice([]string{b.Cluster.Spec.CloudConfig.AWSDNSAssumeRole.RoleARN}),
+		})
+		return
+	}
+
+	if b.HostedZoneID != "" {
+		addRoute53Permissions(p, b.IAMPrefix(), b.HostedZoneID)
 	}
 }
 
@@ -329,7 +391,7 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 					),
 				})
 			} else {
-				if b.Role == kops.InstanceGroupRoleMaster {
+				if b.Role == kops.InstanceGroupRoleMaster || b.Role == kops.InstanceGroupRoleEtcd {
 					p.Statement = append(p.Statement, &Statement{
 						Effect: StatementEffectAllow,
 						Action: stringorslice.Slice([]string{"s3:Get*"}),
@@ -352,10 +414,18 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 					// @check if bootstrap tokens are enabled and if so enable access to client certificate
 					if b.UseBootstrapTokens() {
 						resources = append(resources, strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/pki/private/node-authorizer-client/*"}, ""))
-					} else {
+					} else if !b.Cluster.Spec.KopsControllerEnabled() {
+						// @note: the kops-controller issues the kubelet certificates, so the nodes never read the kubelet key
 						resources = append(resources, strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/pki/private/kubelet/*"}, ""))
 					}
 
+					// @check if any of the container registry mirrors require credentials, which nodeup writes into the docker config
+					for _, mirror := range b.Cluster.Spec.ContainerRegistryMirrors {
+						if mirror.CredentialsSecret != "" {
+							resources = append(resources, strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/secrets/", mirror.CredentialsSecret}, ""))
+						}
+					}
+
 					sort.Strings(resources)
 
 					p.Statement = append(p.Statement, &Statement{
@@ -427,8 +497,8 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 func WriteableVFSPaths(cluster *kops.Cluster, role kops.InstanceGroupRole) ([]vfs.Path, error) {
 	var paths []vfs.Path
 
-	// On the master, grant IAM permissions to the backup store, if it is configured
-	if role == kops.InstanceGroupRoleMaster {
+	// On the master (or dedicated etcd instances), grant IAM permissions to the backup store, if it is configured
+	if role == kops.InstanceGroupRoleMaster || role == kops.InstanceGroupRoleEtcd {
 		backupStores := sets.NewString()
 		for _, c := range cluster.Spec.EtcdClusters {
 			if c.Backups == nil || c.Backups.BackupStore == "" || backupStores.Has(c.Backups.BackupStore) {
@@ -446,6 +516,16 @@ func WriteableVFSPaths(cluster *kops.Cluster, role kops.InstanceGroupRole) ([]vf
 			backupStores.Insert(backupStore)
 		}
 	}
+
+	// nodeup publishes its boot timing report, on every instance that runs it
+	if cluster.Spec.NodeBootReportsEnabled() && role != kops.InstanceGroupRoleBastion {
+		configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse VFS path %q: %v", cluster.Spec.ConfigBase, err)
+		}
+		paths = append(paths, bootreport.Path(configBase))
+	}
+
 	return paths, nil
 }
 
@@ -526,10 +606,7 @@ func addECRPermissions(p *Policy) {
 	})
 }
 
-func addRoute53Permissions(p *Policy, hostedZoneID string) {
-
-	// TODO: Route53 currently not supported in China, need to check and fail/return
-
+func addRoute53Permissions(p *Policy, iamPrefix string, hostedZoneID string) {
 	// Remove /hostedzone/ prefix (if present)
 	hostedZoneID = strings.TrimPrefix(hostedZoneID, "/")
 	hostedZoneID = strings.TrimPrefix(hostedZoneID, "hostedzone/")
@@ -539,13 +616,13 @@ func addRoute53Permissions(p *Policy, hostedZoneID string) {
 		Action: stringorslice.Of("route53:ChangeResourceRecordSets",
 			"route53:ListResourceRecordSets",
 			"route53:GetHostedZone"),
-		Resource: stringorslice.Slice([]string{"arn:aws:route53:::hostedzone/" + hostedZoneID}),
+		Resource: stringorslice.Slice([]string{iamPrefix + ":route53:::hostedzone/" + hostedZoneID}),
 	})
 
 	p.Statement = append(p.Statement, &Statement{
 		Effect:   StatementEffectAllow,
 		Action:   stringorslice.Slice([]string{"route53:GetChange"}),
-		Resource: stringorslice.Slice([]string{"arn:aws:route53:::change/*"}),
+		Resource: stringorslice.Slice([]string{iamPrefix + ":route53:::change/*"}),
 	})
 
 	wildcard := stringorslice.Slice([]string{"*"})
@@ -663,6 +740,38 @@ func addMasterEC2Policies(p *Policy, resource stringorslice.StringOrSlice, legac
 	}
 }
 
+func addEtcdEC2Policies(p *Policy, resource stringorslice.StringOrSlice, legacyIAM bool, clusterName string) {
+	// Protokube and etcd-manager find and attach the etcd volumes
+	p.Statement = append(p.Statement, &Statement{
+		Effect: StatementEffectAllow,
+		Action: stringorslice.Slice([]string{
+			"ec2:DescribeInstances",
+			"ec2:DescribeRegions",
+			"ec2:DescribeVolumes",
+		}),
+		Resource: resource,
+	})
+
+	if legacyIAM {
+		p.Statement = append(p.Statement, &Statement{
+			Effect:   StatementEffectAllow,
+			Action:   stringorslice.Of("ec2:AttachVolume", "ec2:DetachVolume"),
+			Resource: resource,
+		})
+	} else {
+		p.Statement = append(p.Statement, &Statement{
+			Effect:   StatementEffectAllow,
+			Action:   stringorslice.Of("ec2:AttachVolume", "ec2:DetachVolume"),
+			Resource: resource,
+			Condition: Condition{
+				"StringEquals": map[string]string{
+					"ec2:ResourceTag/KubernetesCluster": clusterName,
+				},
+			},
+		})
+	}
+}
+
 func addMasterELBPolicies(p *Policy, resource stringorslice.StringOrSlice, legacyIAM bool) {
 	if legacyIAM {
 		p.Statement = append(p.Statement, &Statement{
@@ -845,6 +954,44 @@ func addAmazonVPCCNIPermissions(p *Policy, resource stringorslice.StringOrSlice,
 	)
 }
 
+// addKopsControllerPermissions lets kops-controller verify the nodes of directly managed instance groups against the
+// launch template they were launched from
+func addKopsControllerPermissions(p *Policy, resource stringorslice.StringOrSlice) {
+	p.Statement = append(p.Statement,
+		&Statement{
+			Effect: StatementEffectAllow,
+			Action: stringorslice.Slice([]string{
+				"ec2:DescribeLaunchTemplateVersions",
+				"ec2:DescribeLaunchTemplates",
+			}),
+			Resource: resource,
+		},
+	)
+}
+
+// addSessionManagerPermissions lets the SSM agent register the instance and carry sessions
+func addSessionManagerPermissions(p *Policy) {
+	p.Statement = append(p.Statement,
+		&Statement{
+			Effect: StatementEffectAllow,
+			Action: stringorslice.Slice([]string{
+				"ssm:UpdateInstanceInformation",
+				"ssmmessages:CreateControlChannel",
+				"ssmmessages:CreateDataChannel",
+				"ssmmessages:OpenControlChannel",
+				"ssmmessages:OpenDataChannel",
+				"ec2messages:AcknowledgeMessage",
+				"ec2messages:DeleteMessage",
+				"ec2messages:FailMessage",
+				"ec2messages:GetEndpoint",
+				"ec2messages:GetMessages",
+				"ec2messages:SendReply",
+			}),
+			Resource: stringorslice.Slice([]string{"*"}),
+		},
+	)
+}
+
 func createResource(b *PolicyBuilder) stringorslice.StringOrSlice {
 	var resource stringorslice.StringOrSlice
 	if b.ResourceARN != nil {
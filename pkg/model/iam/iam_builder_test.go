@@ -27,6 +27,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/diff"
 	"k8s.io/kops/pkg/util/stringorslice"
+	"k8s.io/kops/upup/pkg/fi"
 )
 
 func TestRoundTrip(t *testing.T) {
@@ -79,6 +80,11 @@ func TestPolicyGeneration(t *testing.T) {
 		Role                   kops.InstanceGroupRole
 		LegacyIAM              bool
 		AllowContainerRegistry bool
+		KopsController         bool
+		MirrorCredentials      string
+		BootReports            bool
+		SessionManager         bool
+		DNSAssumeRole          string
 		Policy                 string
 	}{
 		{
@@ -99,6 +105,20 @@ func TestPolicyGeneration(t *testing.T) {
 			AllowContainerRegistry: true,
 			Policy:                 "tests/iam_builder_master_strict_ecr.json",
 		},
+		{
+			Role:                   "Master",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			DNSAssumeRole:          "arn:aws:iam::210987654321:role/dns",
+			Policy:                 "tests/iam_builder_master_strict_dnsrole.json",
+		},
+		{
+			Role:                   "Master",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			KopsController:         true,
+			Policy:                 "tests/iam_builder_master_strict_kopscontroller.json",
+		},
 		{
 			Role:                   "Node",
 			LegacyIAM:              true,
@@ -117,6 +137,46 @@ func TestPolicyGeneration(t *testing.T) {
 			AllowContainerRegistry: true,
 			Policy:                 "tests/iam_builder_node_strict_ecr.json",
 		},
+		{
+			Role:                   "Node",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			KopsController:         true,
+			Policy:                 "tests/iam_builder_node_strict_kopscontroller.json",
+		},
+		{
+			Role:                   "Node",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			MirrorCredentials:      "mirror-credentials",
+			Policy:                 "tests/iam_builder_node_strict_mirror.json",
+		},
+		{
+			Role:                   "Node",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			BootReports:            true,
+			Policy:                 "tests/iam_builder_node_strict_bootreports.json",
+		},
+		{
+			Role:                   "Node",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			SessionManager:         true,
+			Policy:                 "tests/iam_builder_node_strict_sessionmanager.json",
+		},
+		{
+			Role:                   "Etcd",
+			LegacyIAM:              true,
+			AllowContainerRegistry: false,
+			Policy:                 "tests/iam_builder_etcd_legacy.json",
+		},
+		{
+			Role:                   "Etcd",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			Policy:                 "tests/iam_builder_etcd_strict.json",
+		},
 		{
 			Role:                   "Bastion",
 			LegacyIAM:              true,
@@ -141,11 +201,22 @@ func TestPolicyGeneration(t *testing.T) {
 		b := &PolicyBuilder{
 			Cluster: &kops.Cluster{
 				Spec: kops.ClusterSpec{
+					ConfigBase:  "s3://kops-tests/iam-builder-test.k8s.local",
 					ConfigStore: "s3://kops-tests/iam-builder-test.k8s.local",
 					IAM: &kops.IAMSpec{
 						Legacy:                 x.LegacyIAM,
 						AllowContainerRegistry: x.AllowContainerRegistry,
 					},
+					KopsController: &kops.KopsControllerSpec{
+						Enabled: fi.Bool(x.KopsController),
+					},
+					NodeBootReports: fi.Bool(x.BootReports),
+					ContainerRegistryMirrors: []kops.ContainerRegistryMirrorSpec{
+						{
+							Endpoints:         []string{"https://mirror.example.com"},
+							CredentialsSecret: x.MirrorCredentials,
+						},
+					},
 					EtcdClusters: []*kops.EtcdClusterSpec{
 						{
 							Members: []*kops.EtcdMemberSpec{
@@ -173,6 +244,12 @@ func TestPolicyGeneration(t *testing.T) {
 			Role: x.Role,
 		}
 		b.Cluster.SetName("iam-builder-test.k8s.local")
+		if x.SessionManager {
+			b.Cluster.Spec.Topology = &kops.TopologySpec{SessionManager: &kops.SessionManagerSpec{}}
+		}
+		if x.DNSAssumeRole != "" {
+			b.Cluster.Spec.CloudConfig = &kops.CloudConfiguration{AWSDNSAssumeRole: &kops.AWSAssumeRoleSpec{RoleARN: x.DNSAssumeRole}}
+		}
 
 		p, err := b.BuildAWSPolicy()
 		if err != nil {
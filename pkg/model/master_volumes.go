@@ -32,8 +32,12 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/dotasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetznertasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstacktasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scalewaytasks"
 )
 
 const (
@@ -97,6 +101,10 @@ func (b *MasterVolumeBuilder) Build(c *fi.ModelBuilderContext) error {
 				b.addDOVolume(c, name, volumeSize, zone, etcd, m, allMembers)
 			case kops.CloudProviderGCE:
 				b.addGCEVolume(c, name, volumeSize, zone, etcd, m, allMembers)
+			case kops.CloudProviderHetzner:
+				b.addHetznerVolume(c, name, volumeSize, zone, etcd, m, allMembers)
+			case kops.CloudProviderScaleway:
+				b.addScalewayVolume(c, name, volumeSize, zone, etcd, m, allMembers)
 			case kops.CloudProviderVSphere:
 				b.addVSphereVolume(c, name, volumeSize, zone, etcd, m, allMembers)
 			case kops.CloudProviderBareMetal:
@@ -120,12 +128,15 @@ func (b *MasterVolumeBuilder) addAWSVolume(c *fi.ModelBuilderContext, name strin
 	volumeType := fi.StringValue(m.VolumeType)
 	volumeIops := fi.Int32Value(m.VolumeIops)
 	switch volumeType {
-	case "io1":
+	case "io1", "io2":
 		if volumeIops <= 0 {
 			volumeIops = DefaultAWSEtcdVolumeIops
 		}
+	case "gp3":
+		// gp3 volumes have a baseline performance, which is only overridden when requested
 	default:
 		volumeType = DefaultAWSEtcdVolumeType
+		volumeIops = 0
 	}
 
 	// The tags are how protokube knows to mount the volume and use it for etcd
@@ -139,8 +150,8 @@ func (b *MasterVolumeBuilder) addAWSVolume(c *fi.ModelBuilderContext, name strin
 	//tags[awsup.TagClusterName] = b.C.cluster.Name
 	// This is the configuration of the etcd cluster
 	tags[awsup.TagNameEtcdClusterPrefix+etcd.Name] = m.Name + "/" + strings.Join(allMembers, ",")
-	// This says "only mount on a master"
-	tags[awsup.TagNameRolePrefix+"master"] = "1"
+	// This says "only mount on a master" (or on a dedicated etcd instance, if the members are placed there)
+	tags[awsup.TagNameRolePrefix+strings.ToLower(string(b.EtcdClusterRole(etcd)))] = "1"
 
 	// We always add an owned tags (these can't be shared)
 	tags["kubernetes.io/cluster/"+b.Cluster.ObjectMeta.Name] = "owned"
@@ -158,9 +169,12 @@ func (b *MasterVolumeBuilder) addAWSVolume(c *fi.ModelBuilderContext, name strin
 		Encrypted:        fi.Bool(encrypted),
 		Tags:             tags,
 	}
-	if volumeType == "io1" {
+	if volumeIops > 0 {
 		t.VolumeIops = i64(int64(volumeIops))
 	}
+	if volumeType == "gp3" && m.VolumeThroughput != nil {
+		t.VolumeThroughput = i64(int64(*m.VolumeThroughput))
+	}
 
 	c.AddTask(t)
 }
@@ -184,6 +198,50 @@ func (b *MasterVolumeBuilder) addDOVolume(c *fi.ModelBuilderContext, name string
 	c.AddTask(t)
 }
 
+func (b *MasterVolumeBuilder) addHetznerVolume(c *fi.ModelBuilderContext, name string, volumeSize int32, zone string, etcd *kops.EtcdClusterSpec, m *kops.EtcdMemberSpec, allMembers []string) {
+	// Hetzner volume names are limited to letters, numbers and hyphens, and 64 characters
+	name = "kops-" + strings.Replace(name, ".", "-", -1)
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	// Label values can't hold the list of members, so protokube finds the other members from their volumes
+	t := &hetznertasks.Volume{
+		Name:      s(name),
+		Lifecycle: b.Lifecycle,
+		SizeGB:    fi.Int(int(volumeSize)),
+		Location:  s(zone),
+		Labels: map[string]string{
+			hetzner.LabelCluster:      b.ClusterName(),
+			hetzner.LabelInstanceRole: strings.ToLower(string(kops.InstanceGroupRoleMaster)),
+			hetzner.LabelEtcdCluster:  etcd.Name,
+			hetzner.LabelEtcdMember:   m.Name,
+		},
+	}
+
+	c.AddTask(t)
+}
+
+func (b *MasterVolumeBuilder) addScalewayVolume(c *fi.ModelBuilderContext, name string, volumeSize int32, zone string, etcd *kops.EtcdClusterSpec, m *kops.EtcdMemberSpec, allMembers []string) {
+	name = "kops-" + strings.Replace(name, ".", "-", -1)
+
+	// As on Hetzner Cloud, protokube finds the other members of the etcd cluster from their volumes
+	t := &scalewaytasks.Volume{
+		Name:      s(name),
+		Lifecycle: b.Lifecycle,
+		SizeGB:    fi.Int(int(volumeSize)),
+		Zone:      s(zone),
+		Tags: []string{
+			scaleway.Tag(scaleway.TagCluster, b.ClusterName()),
+			scaleway.Tag(scaleway.TagInstanceRole, strings.ToLower(string(kops.InstanceGroupRoleMaster))),
+			scaleway.Tag(scaleway.TagEtcdCluster, etcd.Name),
+			scaleway.Tag(scaleway.TagEtcdMember, m.Name),
+		},
+	}
+
+	c.AddTask(t)
+}
+
 func (b *MasterVolumeBuilder) addGCEVolume(c *fi.ModelBuilderContext, name string, volumeSize int32, zone string, etcd *kops.EtcdClusterSpec, m *kops.EtcdMemberSpec, allMembers []string) {
 	volumeType := fi.StringValue(m.VolumeType)
 	if volumeType == "" {
@@ -232,7 +290,7 @@ func (b *MasterVolumeBuilder) addGCEVolume(c *fi.ModelBuilderContext, name strin
 }
 
 func (b *MasterVolumeBuilder) addVSphereVolume(c *fi.ModelBuilderContext, name string, volumeSize int32, zone string, etcd *kops.EtcdClusterSpec, m *kops.EtcdMemberSpec, allMembers []string) {
-	fmt.Print("addVSphereVolume to be implemented")
+	// The etcd disks are created along with the master VMs, as they are attached when the VM is cloned; see vspheremodel
 }
 
 func (b *MasterVolumeBuilder) addOpenstackVolume(c *fi.ModelBuilderContext, name string, volumeSize int32, zone string, etcd *kops.EtcdClusterSpec, m *kops.EtcdMemberSpec, allMembers []string) error {
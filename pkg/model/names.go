@@ -36,6 +36,11 @@ func (b *KopsModelContext) SecurityGroupName(role kops.InstanceGroupRole) string
 		return "nodes." + b.ClusterName()
 	case kops.InstanceGroupRoleMaster:
 		return "masters." + b.ClusterName()
+	case kops.InstanceGroupRoleEtcd:
+		return "etcd." + b.ClusterName()
+	case kops.InstanceGroupRoleAPIServer:
+		// apiserver-only instances share the masters security group, so they are reachable exactly as the masters are
+		return "masters." + b.ClusterName()
 	default:
 		glog.Fatalf("unknown role: %v", role)
 		return ""
@@ -47,6 +52,17 @@ func (b *KopsModelContext) LinkToSecurityGroup(role kops.InstanceGroupRole) *aws
 	return &awstasks.SecurityGroup{Name: &name}
 }
 
+// SecurityGroupOverride returns the ID of the pre-existing security group that the instance groups
+// of the role use instead of the one kops creates, or nil if kops manages the security group
+func (b *KopsModelContext) SecurityGroupOverride(role kops.InstanceGroupRole) *string {
+	for _, ig := range b.InstanceGroups {
+		if ig.Spec.Role == role && ig.Spec.SecurityGroupOverride != nil {
+			return ig.Spec.SecurityGroupOverride
+		}
+	}
+	return nil
+}
+
 func (b *KopsModelContext) AutoscalingGroupName(ig *kops.InstanceGroup) string {
 	switch ig.Spec.Role {
 	case kops.InstanceGroupRoleMaster:
@@ -54,6 +70,10 @@ func (b *KopsModelContext) AutoscalingGroupName(ig *kops.InstanceGroup) string {
 		// though the IG name suffices for uniqueness, and with sensible naming masters
 		// should be redundant...
 		return ig.ObjectMeta.Name + ".masters." + b.ClusterName()
+	case kops.InstanceGroupRoleEtcd:
+		return ig.ObjectMeta.Name + ".etcd." + b.ClusterName()
+	case kops.InstanceGroupRoleAPIServer:
+		return ig.ObjectMeta.Name + ".apiservers." + b.ClusterName()
 	case kops.InstanceGroupRoleNode, kops.InstanceGroupRoleBastion:
 		return ig.ObjectMeta.Name + "." + b.ClusterName()
 
@@ -101,6 +121,16 @@ func (b *KopsModelContext) NameForDNSZone() string {
 	return name
 }
 
+// LinkToPublicDNSZone returns the public zone used for split-horizon DNS
+func (b *KopsModelContext) LinkToPublicDNSZone() *awstasks.DNSZone {
+	name := b.NameForPublicDNSZone()
+	return &awstasks.DNSZone{Name: &name}
+}
+
+func (b *KopsModelContext) NameForPublicDNSZone() string {
+	return "public-" + b.Cluster.Spec.DNSZone
+}
+
 // IAMName determines the name of the IAM Role and Instance Profile to use for the InstanceGroup
 func (b *KopsModelContext) IAMName(role kops.InstanceGroupRole) string {
 	switch role {
@@ -110,6 +140,11 @@ func (b *KopsModelContext) IAMName(role kops.InstanceGroupRole) string {
 		return "bastions." + b.ClusterName()
 	case kops.InstanceGroupRoleNode:
 		return "nodes." + b.ClusterName()
+	case kops.InstanceGroupRoleEtcd:
+		return "etcd." + b.ClusterName()
+	case kops.InstanceGroupRoleAPIServer:
+		// apiserver-only instances share the masters IAM role
+		return "masters." + b.ClusterName()
 
 	default:
 		glog.Fatalf("unknown InstanceGroup Role: %q", role)
@@ -36,6 +36,13 @@ type NetworkModelBuilder struct {
 
 var _ fi.ModelBuilder = &NetworkModelBuilder{}
 
+const (
+	// DefaultNatInstanceMachineType is the instance type used for NAT instances if not otherwise specified
+	DefaultNatInstanceMachineType = "t2.micro"
+	// DefaultNatInstanceImage is the image used for NAT instances if not otherwise specified
+	DefaultNatInstanceImage = "amazon/amzn-ami-vpc-nat-hvm-*-x86_64-ebs"
+)
+
 type zoneInfo struct {
 	PrivateSubnets []*kops.ClusterSubnetSpec
 }
@@ -252,6 +259,37 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 	}
 
+	// A SharedNatGateway is hosted in the first zone (alphabetically) that uses it
+	sharedNatGatewayZone := ""
+	natInstanceZones := 0
+	for zone, info := range infoByZone {
+		if len(info.PrivateSubnets) == 0 {
+			continue
+		}
+		switch info.PrivateSubnets[0].Egress {
+		case kops.EgressSharedNatGateway:
+			if sharedNatGatewayZone == "" || zone < sharedNatGatewayZone {
+				sharedNatGatewayZone = zone
+			}
+		case kops.EgressNatInstance:
+			natInstanceZones++
+		}
+	}
+
+	var sharedNatGateway *awstasks.NatGateway
+	if sharedNatGatewayZone != "" {
+		utilitySubnet, err := b.LinkToUtilitySubnetInZone(sharedNatGatewayZone)
+		if err != nil {
+			return err
+		}
+		sharedNatGateway = b.buildNatGateway(c, sharedNatGatewayZone, utilitySubnet, infoByZone[sharedNatGatewayZone].PrivateSubnets[0].PublicIP)
+	}
+
+	var natInstanceSecurityGroup *awstasks.SecurityGroup
+	if natInstanceZones != 0 {
+		natInstanceSecurityGroup = b.buildNatInstanceSecurityGroup(c)
+	}
+
 	// Set up private route tables & egress
 	for zone, info := range infoByZone {
 		if len(info.PrivateSubnets) == 0 {
@@ -278,7 +316,46 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 
 		var ngw *awstasks.NatGateway
 		var in *awstasks.Instance
-		if egress != "" {
+		switch egress {
+		case "", kops.EgressNatGateway:
+			ngw = b.buildNatGateway(c, zone, utilitySubnet, publicIP)
+
+		case kops.EgressSharedNatGateway:
+			ngw = sharedNatGateway
+
+		case kops.EgressNatInstance:
+			sshKey, err := b.LinkToSSHKey()
+			if err != nil {
+				return err
+			}
+
+			machineType := DefaultNatInstanceMachineType
+			image := DefaultNatInstanceImage
+			if b.Cluster.Spec.Topology != nil && b.Cluster.Spec.Topology.NatInstance != nil {
+				if b.Cluster.Spec.Topology.NatInstance.MachineType != "" {
+					machineType = b.Cluster.Spec.Topology.NatInstance.MachineType
+				}
+				if b.Cluster.Spec.Topology.NatInstance.Image != "" {
+					image = b.Cluster.Spec.Topology.NatInstance.Image
+				}
+			}
+
+			name := "nat-" + zone + "." + b.ClusterName()
+			in = &awstasks.Instance{
+				Name:              s(name),
+				Lifecycle:         b.Lifecycle,
+				Subnet:            utilitySubnet,
+				ImageID:           s(image),
+				InstanceType:      s(machineType),
+				SSHKey:            sshKey,
+				SecurityGroups:    []*awstasks.SecurityGroup{natInstanceSecurityGroup},
+				AssociatePublicIP: fi.Bool(true),
+				SourceDestCheck:   fi.Bool(false),
+				Tags:              b.CloudTags(name, false),
+			}
+			c.AddTask(in)
+
+		default:
 			if strings.HasPrefix(egress, "nat-") {
 
 				ngw = &awstasks.NatGateway{
@@ -310,43 +387,6 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 				return fmt.Errorf("kops currently only supports re-use of either NAT EC2 Instances or NAT Gateways. We will support more eventually! Please see https://github.com/kubernetes/kops/issues/1530")
 			}
 
-		} else {
-
-			// Every NGW needs a public (Elastic) IP address, every private
-			// subnet needs a NGW, lets create it. We tie it to a subnet
-			// so we can track it in AWS
-			eip := &awstasks.ElasticIP{
-				Name:                           s(zone + "." + b.ClusterName()),
-				Lifecycle:                      b.Lifecycle,
-				AssociatedNatGatewayRouteTable: b.LinkToPrivateRouteTableInZone(zone),
-			}
-
-			if publicIP != "" {
-				eip.PublicIP = s(publicIP)
-				eip.Tags = b.CloudTags(*eip.Name, true)
-			} else {
-				eip.Tags = b.CloudTags(*eip.Name, false)
-			}
-
-			c.AddTask(eip)
-			// NAT Gateway
-			//
-			// All private subnets will need a NGW, one per zone
-			//
-			// The instances in the private subnet can access the Internet by
-			// using a network address translation (NAT) gateway that resides
-			// in the public subnet.
-
-			//var ngw = &awstasks.NatGateway{}
-			ngw = &awstasks.NatGateway{
-				Name:                 s(zone + "." + b.ClusterName()),
-				Lifecycle:            b.Lifecycle,
-				Subnet:               utilitySubnet,
-				ElasticIP:            eip,
-				AssociatedRouteTable: b.LinkToPrivateRouteTableInZone(zone),
-				Tags:                 b.CloudTags(zone+"."+b.ClusterName(), false),
-			}
-			c.AddTask(ngw)
 		}
 
 		// Private Route Table
@@ -398,3 +438,73 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 
 	return nil
 }
+
+// buildNatGateway creates the NAT Gateway (and its Elastic IP) hosted in the utility subnet of the zone
+func (b *NetworkModelBuilder) buildNatGateway(c *fi.ModelBuilderContext, zone string, utilitySubnet *awstasks.Subnet, publicIP string) *awstasks.NatGateway {
+	// Every NGW needs a public (Elastic) IP address, every private
+	// subnet needs a NGW, lets create it. We tie it to a subnet
+	// so we can track it in AWS
+	eip := &awstasks.ElasticIP{
+		Name:                           s(zone + "." + b.ClusterName()),
+		Lifecycle:                      b.Lifecycle,
+		AssociatedNatGatewayRouteTable: b.LinkToPrivateRouteTableInZone(zone),
+	}
+
+	if publicIP != "" {
+		eip.PublicIP = s(publicIP)
+		eip.Tags = b.CloudTags(*eip.Name, true)
+	} else {
+		eip.Tags = b.CloudTags(*eip.Name, false)
+	}
+
+	c.AddTask(eip)
+	// NAT Gateway
+	//
+	// The instances in the private subnet can access the Internet by
+	// using a network address translation (NAT) gateway that resides
+	// in the public subnet.
+	ngw := &awstasks.NatGateway{
+		Name:                 s(zone + "." + b.ClusterName()),
+		Lifecycle:            b.Lifecycle,
+		Subnet:               utilitySubnet,
+		ElasticIP:            eip,
+		AssociatedRouteTable: b.LinkToPrivateRouteTableInZone(zone),
+		Tags:                 b.CloudTags(zone+"."+b.ClusterName(), false),
+	}
+	c.AddTask(ngw)
+
+	return ngw
+}
+
+// buildNatInstanceSecurityGroup creates the security group for NAT instances,
+// which accepts any traffic from within the VPC and can egress freely
+func (b *NetworkModelBuilder) buildNatInstanceSecurityGroup(c *fi.ModelBuilderContext) *awstasks.SecurityGroup {
+	sg := &awstasks.SecurityGroup{
+		Name:        s("nat." + b.ClusterName()),
+		Lifecycle:   b.Lifecycle,
+		VPC:         b.LinkToVPC(),
+		Description: s("Security group for NAT instances"),
+	}
+	sg.Tags = b.CloudTags(*sg.Name, false)
+	c.AddTask(sg)
+
+	c.AddTask(&awstasks.SecurityGroupRule{
+		Name:          s("nat-egress"),
+		Lifecycle:     b.Lifecycle,
+		SecurityGroup: sg,
+		Egress:        fi.Bool(true),
+		CIDR:          s("0.0.0.0/0"),
+	})
+
+	cidrs := append([]string{b.Cluster.Spec.NetworkCIDR}, b.Cluster.Spec.AdditionalNetworkCIDRs...)
+	for _, cidr := range cidrs {
+		c.AddTask(&awstasks.SecurityGroupRule{
+			Name:          s("nat-ingress-" + cidr),
+			Lifecycle:     b.Lifecycle,
+			SecurityGroup: sg,
+			CIDR:          s(cidr),
+		})
+	}
+
+	return sg
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+)
+
+func buildEgressTestCluster(egress string) *kops.Cluster {
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test.k8s.local",
+		},
+	}
+	cluster.Spec.NetworkCIDR = "172.20.0.0/16"
+	cluster.Spec.SSHKeyName = "test-key"
+	cluster.Spec.Topology = &kops.TopologySpec{
+		Masters: kops.TopologyPrivate,
+		Nodes:   kops.TopologyPrivate,
+	}
+	for i, zone := range []string{"us-test-1c", "us-test-1a", "us-test-1b"} {
+		cluster.Spec.Subnets = append(cluster.Spec.Subnets,
+			kops.ClusterSubnetSpec{
+				Name:   zone,
+				Zone:   zone,
+				CIDR:   fmt.Sprintf("172.20.%d2.0/24", i+1),
+				Type:   kops.SubnetTypePrivate,
+				Egress: egress,
+			},
+			kops.ClusterSubnetSpec{
+				Name: "utility-" + zone,
+				Zone: zone,
+				CIDR: fmt.Sprintf("172.20.%d3.0/24", i+1),
+				Type: kops.SubnetTypeUtility,
+			})
+	}
+	return cluster
+}
+
+func buildEgressTestTasks(t *testing.T, cluster *kops.Cluster) map[string]fi.Task {
+	lifecycle := fi.LifecycleSync
+	b := &NetworkModelBuilder{
+		KopsModelContext: &KopsModelContext{
+			Cluster: cluster,
+		},
+		Lifecycle: &lifecycle,
+	}
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	if err := b.Build(c); err != nil {
+		t.Fatalf("error building network model: %v", err)
+	}
+	return c.Tasks
+}
+
+func TestNetworkModelBuilder_SharedNatGateway(t *testing.T) {
+	tasks := buildEgressTestTasks(t, buildEgressTestCluster(kops.EgressSharedNatGateway))
+
+	var ngws []*awstasks.NatGateway
+	var routes []*awstasks.Route
+	for _, task := range tasks {
+		switch task := task.(type) {
+		case *awstasks.NatGateway:
+			ngws = append(ngws, task)
+		case *awstasks.Route:
+			if task.NatGateway != nil {
+				routes = append(routes, task)
+			}
+		}
+	}
+
+	if len(ngws) != 1 {
+		t.Fatalf("expected a single NatGateway, got %d", len(ngws))
+	}
+	if fi.StringValue(ngws[0].Name) != "us-test-1a.test.k8s.local" {
+		t.Errorf("expected NatGateway in first zone, got %q", fi.StringValue(ngws[0].Name))
+	}
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 private routes, got %d", len(routes))
+	}
+	for _, r := range routes {
+		if r.NatGateway != ngws[0] {
+			t.Errorf("route %q does not use the shared NatGateway", fi.StringValue(r.Name))
+		}
+	}
+}
+
+func TestNetworkModelBuilder_NatInstance(t *testing.T) {
+	cluster := buildEgressTestCluster(kops.EgressNatInstance)
+	cluster.Spec.Topology.NatInstance = &kops.NatInstanceSpec{MachineType: "t3.nano"}
+	tasks := buildEgressTestTasks(t, cluster)
+
+	var instances []*awstasks.Instance
+	for _, task := range tasks {
+		switch task := task.(type) {
+		case *awstasks.NatGateway:
+			t.Errorf("unexpected NatGateway %q", fi.StringValue(task.Name))
+		case *awstasks.Instance:
+			instances = append(instances, task)
+		case *awstasks.Route:
+			if task.NatGateway != nil {
+				t.Errorf("route %q should use a NAT instance", fi.StringValue(task.Name))
+			}
+		}
+	}
+
+	if len(instances) != 3 {
+		t.Fatalf("expected a NAT instance per zone, got %d", len(instances))
+	}
+	for _, in := range instances {
+		if fi.StringValue(in.InstanceType) != "t3.nano" {
+			t.Errorf("unexpected InstanceType %q", fi.StringValue(in.InstanceType))
+		}
+		if fi.StringValue(in.ImageID) != DefaultNatInstanceImage {
+			t.Errorf("unexpected ImageID %q", fi.StringValue(in.ImageID))
+		}
+		if in.SourceDestCheck == nil || *in.SourceDestCheck {
+			t.Errorf("SourceDestCheck must be disabled on NAT instances")
+		}
+	}
+}
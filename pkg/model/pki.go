@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	"k8s.io/kops/pkg/encryptionconfig"
 	"k8s.io/kops/pkg/tokens"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/fitasks"
@@ -316,11 +317,32 @@ func (b *PKIModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		})
 	}
 
+	// @note: the serving certificate of the kops-controller, which nodes call to obtain their kubelet certificates
+	if b.Cluster.Spec.KopsControllerEnabled() {
+		c.AddTask(&fitasks.Keypair{
+			Name:           fi.String("kops-controller"),
+			Subject:        "cn=kops-controller",
+			Type:           "server",
+			AlternateNames: []string{"kops-controller.internal." + b.ClusterName()},
+			Signer:         defaultCA,
+			Format:         format,
+		})
+	}
+
 	// Create auth tokens (though this is deprecated)
 	for _, x := range tokens.GetKubernetesAuthTokens_Deprecated() {
 		c.AddTask(&fitasks.Secret{Name: fi.String(x), Lifecycle: b.Lifecycle})
 	}
 
+	// Generate the encryption config, unless the user has provided their own with kops create secret encryptionconfig
+	if fi.BoolValue(b.Cluster.Spec.EncryptionConfig) && b.Cluster.Spec.EncryptionProvider != nil {
+		c.AddTask(&fitasks.EncryptionConfig{
+			Name:      fi.String(encryptionconfig.SecretName),
+			Lifecycle: b.Lifecycle,
+			Provider:  b.Cluster.Spec.EncryptionProvider,
+		})
+	}
+
 	{
 		mirrorPath, err := vfs.Context.BuildVfsPath(b.Cluster.Spec.SecretStore)
 		if err != nil {
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaymodel
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	kopsmodel "k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scalewaytasks"
+)
+
+// ScalewayModelContext is the model context for Scaleway
+type ScalewayModelContext struct {
+	*kopsmodel.KopsModelContext
+}
+
+// Zone returns the zone of the cluster; private networks are zonal, so every subnet is in the same zone
+func (c *ScalewayModelContext) Zone() (string, error) {
+	if len(c.Cluster.Spec.Subnets) == 0 {
+		return "", fmt.Errorf("cluster has no subnets")
+	}
+	return c.Cluster.Spec.Subnets[0].Zone, nil
+}
+
+// LinkToPrivateNetwork returns the private network of the cluster
+func (c *ScalewayModelContext) LinkToPrivateNetwork() *scalewaytasks.PrivateNetwork {
+	return &scalewaytasks.PrivateNetwork{Name: fi.String(c.ClusterName())}
+}
+
+// LinkToSSHKey returns the SSH key of the cluster
+func (c *ScalewayModelContext) LinkToSSHKey() (*scalewaytasks.SSHKey, error) {
+	if len(c.SSHPublicKeys) == 0 {
+		return nil, fmt.Errorf("SSH public key must be specified when running with Scaleway (create with `kops create secret --name %s sshpublickey admin -i ~/.ssh/id_rsa.pub`)", c.ClusterName())
+	}
+	name, err := c.SSHKeyName()
+	if err != nil {
+		return nil, err
+	}
+	return &scalewaytasks.SSHKey{Name: fi.String(name)}, nil
+}
+
+// RoleTag returns the value of the role tag of the servers of the instance group
+func RoleTag(ig *kops.InstanceGroup) string {
+	return strings.ToLower(string(ig.Spec.Role))
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaymodel
+
+import (
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scalewaytasks"
+)
+
+// InstancePoolModelBuilder configures the instance pool of each instance group
+type InstancePoolModelBuilder struct {
+	*ScalewayModelContext
+
+	BootstrapScript *model.BootstrapScript
+	Lifecycle       *fi.Lifecycle
+}
+
+var _ fi.ModelBuilder = &InstancePoolModelBuilder{}
+
+func (b *InstancePoolModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	zone, err := b.Zone()
+	if err != nil {
+		return err
+	}
+
+	sshKey, err := b.LinkToSSHKey()
+	if err != nil {
+		return err
+	}
+
+	for _, ig := range b.InstanceGroups {
+		userData, err := b.BootstrapScript.ResourceNodeUp(ig, b.Cluster)
+		if err != nil {
+			return err
+		}
+
+		// Scaleway has no autoscaling, so the pool is kept at its minimum size
+		pool := &scalewaytasks.InstancePool{
+			Name:           fi.String(b.AutoscalingGroupName(ig)),
+			Lifecycle:      b.Lifecycle,
+			InstanceGroup:  fi.String(ig.ObjectMeta.Name),
+			Role:           fi.String(RoleTag(ig)),
+			Count:          fi.Int(int(fi.Int32Value(ig.Spec.MinSize))),
+			CommercialType: fi.String(ig.Spec.MachineType),
+			Image:          fi.String(ig.Spec.Image),
+			Zone:           fi.String(zone),
+			PrivateNetwork: b.LinkToPrivateNetwork(),
+			UserData:       userData,
+			SSHKey:         sshKey,
+		}
+		c.AddTask(pool)
+	}
+	return nil
+}
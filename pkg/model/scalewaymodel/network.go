@@ -0,0 +1,59 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaymodel
+
+import (
+	"fmt"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// NetworkModelBuilder configures the private network and the SSH key of the cluster
+type NetworkModelBuilder struct {
+	*ScalewayModelContext
+	Lifecycle *fi.Lifecycle
+}
+
+var _ fi.ModelBuilder = &NetworkModelBuilder{}
+
+func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	zone, err := b.Zone()
+	if err != nil {
+		return err
+	}
+
+	network := b.LinkToPrivateNetwork()
+	network.Lifecycle = b.Lifecycle
+	network.Zone = fi.String(zone)
+	for _, subnet := range b.Cluster.Spec.Subnets {
+		if subnet.CIDR == "" {
+			return fmt.Errorf("subnet %q has no CIDR", subnet.Name)
+		}
+		network.Subnets = append(network.Subnets, subnet.CIDR)
+	}
+	c.AddTask(network)
+
+	sshKey, err := b.LinkToSSHKey()
+	if err != nil {
+		return err
+	}
+	sshKey.Lifecycle = b.Lifecycle
+	sshKey.PublicKey = fi.String(string(b.SSHPublicKeys[0]))
+	c.AddTask(sshKey)
+
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
 	"k8s.io/kops/upup/pkg/fi/cloudup/vspheretasks"
 )
 
@@ -42,7 +43,18 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 	// Note that we are creating a VM per instance group. Instance group represents a group of VMs.
 	// The following logic should considerably change once we add support for multiple master/worker nodes,
 	// cloud-init etc.
+	var masterVMs []*vspheretasks.VirtualMachine
 	for _, ig := range b.InstanceGroups {
+		launchSpecTask := &vspheretasks.LaunchSpec{
+			Name:            fi.String(ig.ObjectMeta.Name),
+			VMTemplateName:  fi.String(ig.Spec.Image),
+			EtcdDisks:       b.etcdDisks(ig),
+			IG:              ig,
+			BootstrapScript: b.BootstrapScript,
+			Cluster:         b.Cluster,
+		}
+		c.AddTask(launchSpecTask)
+
 		instanceCount := int(fi.Int32Value(ig.Spec.MinSize))
 		if ig.Spec.Role == kops.InstanceGroupRoleMaster {
 			instanceCount = 1
@@ -52,17 +64,18 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			createVmTask := &vspheretasks.VirtualMachine{
 				Name:           &name,
 				VMTemplateName: fi.String(ig.Spec.Image),
+				LaunchSpec:     launchSpecTask,
 			}
 
 			c.AddTask(createVmTask)
+			if ig.Spec.Role == kops.InstanceGroupRoleMaster {
+				masterVMs = append(masterVMs, createVmTask)
+			}
 
 			attachISOTaskName := "AttachISO-" + name
 			attachISOTask := &vspheretasks.AttachISO{
-				Name:            &attachISOTaskName,
-				VM:              createVmTask,
-				IG:              ig,
-				BootstrapScript: b.BootstrapScript,
-				Cluster:         b.Cluster,
+				Name: &attachISOTaskName,
+				VM:   createVmTask,
 			}
 
 			c.AddTask(attachISOTask)
@@ -75,5 +88,45 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			c.AddTask(powerOnTask)
 		}
 	}
+
+	// DRS keeps the masters on separate hosts, so that losing a host doesn't lose the etcd quorum
+	if len(masterVMs) >= 2 {
+		c.AddTask(&vspheretasks.AntiAffinityRule{
+			Name: fi.String(vsphere.MasterAntiAffinityRuleName(b.ClusterName())),
+			VMs:  masterVMs,
+		})
+	}
 	return nil
 }
+
+// etcdDisks returns the disks of the etcd members of a master instance group, on the etcd datastore
+func (b *AutoscalingGroupModelBuilder) etcdDisks(ig *kops.InstanceGroup) []vsphere.EtcdDisk {
+	if ig.Spec.Role != kops.InstanceGroupRoleMaster {
+		return nil
+	}
+
+	datastore := fi.StringValue(b.Cluster.Spec.CloudConfig.VSphereEtcdDatastore)
+	if datastore == "" {
+		datastore = fi.StringValue(b.Cluster.Spec.CloudConfig.VSphereDatastore)
+	}
+
+	var disks []vsphere.EtcdDisk
+	for i, etcd := range b.Cluster.Spec.EtcdClusters {
+		for _, m := range etcd.Members {
+			if fi.StringValue(m.InstanceGroup) != ig.ObjectMeta.Name {
+				continue
+			}
+			size := fi.Int32Value(m.VolumeSize)
+			if size == 0 {
+				size = model.DefaultEtcdVolumeSize
+			}
+			disks = append(disks, vsphere.EtcdDisk{
+				VolumeId:  vsphere.GetVolumeId(i + 1),
+				Datastore: datastore,
+				Path:      vsphere.EtcdDiskPath(b.ClusterName(), etcd.Name, m.Name),
+				SizeGB:    size,
+			})
+		}
+	}
+	return disks
+}
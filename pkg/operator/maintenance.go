@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a recurring period, in UTC, in which the operator may refresh images and replace instances
+type MaintenanceWindow struct {
+	// Days are the days of the week the window starts on; if empty the window starts every day
+	Days []time.Weekday
+
+	// Start is the time of day the window starts
+	Start time.Duration
+
+	// Duration is how long the window lasts
+	Duration time.Duration
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseMaintenanceWindow parses a window such as "Sat,Sun 02:00-06:00" or "22:00-02:00", in UTC.
+// The days are optional, and a window that ends before it starts continues into the next day.
+func ParseMaintenanceWindow(s string) (*MaintenanceWindow, error) {
+	w := &MaintenanceWindow{}
+
+	fields := strings.Fields(s)
+	var times string
+	switch len(fields) {
+	case 1:
+		times = fields[0]
+	case 2:
+		for _, day := range strings.Split(fields[0], ",") {
+			d, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return nil, fmt.Errorf("unknown day %q in maintenance window %q", day, s)
+			}
+			w.Days = append(w.Days, d)
+		}
+		times = fields[1]
+	default:
+		return nil, fmt.Errorf("maintenance window %q must be of the form \"Sat,Sun 02:00-06:00\"", s)
+	}
+
+	tokens := strings.Split(times, "-")
+	if len(tokens) != 2 {
+		return nil, fmt.Errorf("maintenance window %q must be of the form \"Sat,Sun 02:00-06:00\"", s)
+	}
+	start, err := parseTimeOfDay(tokens[0])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing maintenance window %q: %v", s, err)
+	}
+	end, err := parseTimeOfDay(tokens[1])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing maintenance window %q: %v", s, err)
+	}
+	if end == start {
+		return nil, fmt.Errorf("maintenance window %q is empty", s)
+	}
+	if end < start {
+		end += 24 * time.Hour
+	}
+
+	w.Start = start
+	w.Duration = end - start
+	return w, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time %q must be of the form HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains returns true if t falls inside the window
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	// A window that crosses midnight may have started the day before
+	for _, start := range []time.Time{midnight.Add(w.Start), midnight.AddDate(0, 0, -1).Add(w.Start)} {
+		if !w.startsOn(start.Weekday()) {
+			continue
+		}
+		if !t.Before(start) && t.Before(start.Add(w.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *MaintenanceWindow) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
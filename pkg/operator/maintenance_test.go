@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	grid := []struct {
+		Window   string
+		Time     string
+		Contains bool
+	}{
+		{Window: "Sat,Sun 02:00-06:00", Time: "2018-06-02T03:00:00Z", Contains: true},
+		{Window: "Sat,Sun 02:00-06:00", Time: "2018-06-02T06:00:00Z", Contains: false},
+		{Window: "Sat,Sun 02:00-06:00", Time: "2018-06-02T01:59:00Z", Contains: false},
+		{Window: "Sat,Sun 02:00-06:00", Time: "2018-06-06T03:00:00Z", Contains: false},
+		{Window: "sat 22:00-02:00", Time: "2018-06-02T23:00:00Z", Contains: true},
+		{Window: "sat 22:00-02:00", Time: "2018-06-03T01:00:00Z", Contains: true},
+		{Window: "sat 22:00-02:00", Time: "2018-06-02T01:00:00Z", Contains: false},
+		{Window: "04:00-05:00", Time: "2018-06-06T04:30:00+02:00", Contains: false},
+		{Window: "04:00-05:00", Time: "2018-06-06T06:30:00+02:00", Contains: true},
+	}
+
+	for _, g := range grid {
+		w, err := ParseMaintenanceWindow(g.Window)
+		if err != nil {
+			t.Errorf("error parsing %q: %v", g.Window, err)
+			continue
+		}
+		tm, err := time.Parse(time.RFC3339, g.Time)
+		if err != nil {
+			t.Fatalf("error parsing time %q: %v", g.Time, err)
+		}
+		if got := w.Contains(tm); got != g.Contains {
+			t.Errorf("window %q contains %s: expected %v, got %v", g.Window, g.Time, g.Contains, got)
+		}
+	}
+
+	for _, s := range []string{"", "Sat", "Caturday 02:00-06:00", "Sat 02:00", "Sat 2am-6am", "Sat 02:00-02:00", "Sat Sun 02:00-06:00"} {
+		if _, err := ParseMaintenanceWindow(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/clusterlock"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/kutil"
+)
+
+// Operator reconciles the clusters described by a Source: it copies their spec to the state store,
+// and applies it to the cloud as "kops update cluster --yes" would.
+type Operator struct {
+	// Clientset is the state store
+	Clientset simple.Clientset
+
+	// Source provides the desired clusters and instance groups
+	Source Source
+
+	// AuditLog records the changes the operator makes; it may be nil
+	AuditLog *audit.Log
+
+	// Locker holds the cluster locks; clusters locked by another user are not reconciled.  It may be nil.
+	Locker *clusterlock.Locker
+
+	// User is the user the operator acts as, in the audit log and when checking locks
+	User string
+
+	// RollingUpdate enables rolling updates of instances that are out of date after an update
+	RollingUpdate bool
+
+	// RefreshImages sets the image of instance groups with an imageAlias to the newest image matching it,
+	// then replaces the out of date instances of those instance groups
+	RefreshImages bool
+
+	// MaintenanceWindow limits when images are refreshed; if nil, they are refreshed on every reconcile
+	MaintenanceWindow *MaintenanceWindow
+
+	// apply, rollingUpdate and resolveImage use the cloud, and now the clock; tests replace them
+	apply         func(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) error
+	rollingUpdate func(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) (bool, error)
+	resolveImage  func(cluster *kops.Cluster, alias string) (string, error)
+	now           func() time.Time
+}
+
+// NewOperator builds an Operator reconciling the clusters from source into the state store
+func NewOperator(clientset simple.Clientset, source Source) *Operator {
+	o := &Operator{
+		Clientset: clientset,
+		Source:    source,
+		User:      "kops-operator",
+	}
+	o.apply = o.applyCluster
+	o.rollingUpdate = o.rollingUpdateCluster
+	o.resolveImage = o.resolveImageAlias
+	o.now = time.Now
+	return o
+}
+
+// Run reconciles all clusters whenever changes signals, and every resyncPeriod, until stop is closed
+func (o *Operator) Run(resyncPeriod time.Duration, changes <-chan struct{}, stop <-chan struct{}) {
+	for {
+		if err := o.ReconcileAll(); err != nil {
+			glog.Warningf("error reconciling clusters: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-changes:
+		case <-time.After(resyncPeriod):
+		}
+	}
+}
+
+// ReconcileAll reconciles every cluster from the Source; a failure to reconcile one cluster does not stop the others
+func (o *Operator) ReconcileAll() error {
+	clusters, err := o.Source.Clusters()
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, cluster := range clusters {
+		if err := o.Reconcile(cluster); err != nil {
+			glog.Warningf("error reconciling cluster %q: %v", cluster.ObjectMeta.Name, err)
+			failed = append(failed, cluster.ObjectMeta.Name)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to reconcile clusters %v", failed)
+	}
+	return nil
+}
+
+// Reconcile makes the state store and the cloud match the desired cluster, and its instance groups from the Source
+func (o *Operator) Reconcile(desired *kops.Cluster) error {
+	clusterName := desired.ObjectMeta.Name
+	if clusterName == "" {
+		return fmt.Errorf("cluster name is required")
+	}
+
+	if o.Locker != nil {
+		if err := o.Locker.Check(clusterName, o.User); err != nil {
+			return err
+		}
+	}
+
+	cluster, err := o.syncCluster(desired)
+	if err != nil {
+		return err
+	}
+
+	desiredInstanceGroups, err := o.Source.InstanceGroups(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, ig := range desiredInstanceGroups {
+		if err := o.syncInstanceGroup(cluster, ig); err != nil {
+			return err
+		}
+	}
+
+	if fi.BoolValue(cluster.Spec.RequireApproval) {
+		glog.Warningf("cluster %q requires approval for updates; not applying it", clusterName)
+		return nil
+	}
+
+	// Instance groups that only exist in the state store are still part of the cluster
+	list, err := o.Clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var instanceGroups []*kops.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	var refreshed []*kops.InstanceGroup
+	if o.RefreshImages && (o.MaintenanceWindow == nil || o.MaintenanceWindow.Contains(o.now())) {
+		refreshed, err = o.refreshImages(cluster, instanceGroups)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := o.apply(cluster, instanceGroups); err != nil {
+		return fmt.Errorf("error updating cluster: %v", err)
+	}
+
+	// Image refreshes only replace the instances of the refreshed instance groups
+	rollingUpdateGroups := refreshed
+	if o.RollingUpdate {
+		rollingUpdateGroups = instanceGroups
+	}
+	if len(rollingUpdateGroups) != 0 {
+		updated, err := o.rollingUpdate(cluster, rollingUpdateGroups)
+		if err != nil {
+			return fmt.Errorf("error doing rolling-update of cluster: %v", err)
+		}
+		if updated {
+			o.record(clusterName, audit.OperationRollingUpdate, "cluster", nil, nil)
+		}
+	}
+
+	return nil
+}
+
+// refreshImages sets the image of each instance group with an imageAlias to the newest image matching it.
+// It returns the instance groups with an imageAlias, whether or not their image changed,
+// so that instances left out of date by an interrupted rolling update are still replaced.
+func (o *Operator) refreshImages(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) ([]*kops.InstanceGroup, error) {
+	var aliased []*kops.InstanceGroup
+	for _, ig := range instanceGroups {
+		if ig.Spec.ImageAlias == "" {
+			continue
+		}
+		aliased = append(aliased, ig)
+
+		image, err := o.resolveImage(cluster, ig.Spec.ImageAlias)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving imageAlias %q of instanceGroup %q: %v", ig.Spec.ImageAlias, ig.ObjectMeta.Name, err)
+		}
+		if image == ig.Spec.Image {
+			continue
+		}
+
+		glog.Infof("refreshing image of instanceGroup %q in cluster %q to %q", ig.ObjectMeta.Name, cluster.ObjectMeta.Name, image)
+		before := ig.DeepCopy()
+		ig.Spec.Image = image
+		updated, err := o.Clientset.InstanceGroupsFor(cluster).Update(ig)
+		if err != nil {
+			return nil, fmt.Errorf("error updating image of instanceGroup %q: %v", ig.ObjectMeta.Name, err)
+		}
+		*ig = *updated
+		o.record(cluster.ObjectMeta.Name, audit.OperationReplace, "instancegroup/"+ig.ObjectMeta.Name, before, updated)
+	}
+	return aliased, nil
+}
+
+// resolveImageAlias returns the newest image matching alias, named in the same form as the alias
+func (o *Operator) resolveImageAlias(cluster *kops.Cluster, alias string) (string, error) {
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return "", err
+	}
+	awsCloud, ok := cloud.(awsup.AWSCloud)
+	if !ok {
+		return "", fmt.Errorf("imageAlias is only supported on AWS")
+	}
+
+	image, err := awsCloud.ResolveImage(alias)
+	if err != nil {
+		return "", err
+	}
+	name := aws.StringValue(image.Name)
+	if i := strings.Index(alias, "/"); i != -1 {
+		return alias[:i+1] + name, nil
+	}
+	return name, nil
+}
+
+// syncCluster writes the desired cluster to the state store, if it differs from the stored cluster
+func (o *Operator) syncCluster(desired *kops.Cluster) (*kops.Cluster, error) {
+	clusterName := desired.ObjectMeta.Name
+
+	desired = desired.DeepCopy()
+	if err := cloudup.PerformAssignments(desired); err != nil {
+		return nil, fmt.Errorf("error populating configuration: %v", err)
+	}
+
+	existing, err := o.Clientset.GetCluster(clusterName)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("error fetching cluster %q: %v", clusterName, err)
+	}
+
+	if existing == nil || errors.IsNotFound(err) {
+		glog.Infof("creating cluster %q", clusterName)
+		created, err := o.Clientset.CreateCluster(desired)
+		if err != nil {
+			return nil, fmt.Errorf("error creating cluster: %v", err)
+		}
+		o.record(clusterName, audit.OperationCreate, "cluster", nil, created)
+		return created, nil
+	}
+
+	if reflect.DeepEqual(existing.Spec, desired.Spec) && reflect.DeepEqual(existing.ObjectMeta.Labels, desired.ObjectMeta.Labels) {
+		return existing, nil
+	}
+
+	glog.Infof("updating spec of cluster %q", clusterName)
+	desired.ObjectMeta.ResourceVersion = existing.ObjectMeta.ResourceVersion
+	statusDiscovery := &commands.CloudDiscoveryStatusStore{}
+	status, err := statusDiscovery.FindClusterStatus(desired)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := o.Clientset.UpdateCluster(desired, status)
+	if err != nil {
+		return nil, fmt.Errorf("error replacing cluster: %v", err)
+	}
+	o.record(clusterName, audit.OperationReplace, "cluster", existing, updated)
+	return updated, nil
+}
+
+// syncInstanceGroup writes a desired instance group to the state store, if it differs from the stored instance group
+func (o *Operator) syncInstanceGroup(cluster *kops.Cluster, desired *kops.InstanceGroup) error {
+	clusterName := cluster.ObjectMeta.Name
+	igName := desired.ObjectMeta.Name
+
+	desired = desired.DeepCopy()
+	if desired.ObjectMeta.Labels == nil {
+		desired.ObjectMeta.Labels = make(map[string]string)
+	}
+	desired.ObjectMeta.Labels[kops.LabelClusterName] = clusterName
+
+	existing, err := o.Clientset.InstanceGroupsFor(cluster).Get(igName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error fetching instanceGroup %q: %v", igName, err)
+	}
+
+	if existing == nil || errors.IsNotFound(err) {
+		glog.Infof("creating instanceGroup %q in cluster %q", igName, clusterName)
+		created, err := o.Clientset.InstanceGroupsFor(cluster).Create(desired)
+		if err != nil {
+			return fmt.Errorf("error creating instanceGroup: %v", err)
+		}
+		o.record(clusterName, audit.OperationCreate, "instancegroup/"+igName, nil, created)
+		return nil
+	}
+
+	// When refreshing images, the image of an instance group with an imageAlias is managed by the operator
+	if o.RefreshImages && desired.Spec.ImageAlias != "" && desired.Spec.ImageAlias == existing.Spec.ImageAlias {
+		desired.Spec.Image = existing.Spec.Image
+	}
+
+	if reflect.DeepEqual(existing.Spec, desired.Spec) && reflect.DeepEqual(existing.ObjectMeta.Labels, desired.ObjectMeta.Labels) {
+		return nil
+	}
+
+	glog.Infof("updating spec of instanceGroup %q in cluster %q", igName, clusterName)
+	desired.ObjectMeta.ResourceVersion = existing.ObjectMeta.ResourceVersion
+	updated, err := o.Clientset.InstanceGroupsFor(cluster).Update(desired)
+	if err != nil {
+		return fmt.Errorf("error replacing instanceGroup: %v", err)
+	}
+	o.record(clusterName, audit.OperationReplace, "instancegroup/"+igName, existing, updated)
+	return nil
+}
+
+// applyCluster applies the cluster to the cloud, as "kops update cluster --yes" does
+func (o *Operator) applyCluster(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) error {
+	applyCmd := &cloudup.ApplyClusterCmd{
+		Clientset:      o.Clientset,
+		Cluster:        cluster,
+		InstanceGroups: instanceGroups,
+		Models:         cloudup.CloudupModels,
+		TargetName:     cloudup.TargetDirect,
+	}
+	return applyCmd.Run()
+}
+
+// rollingUpdateCluster replaces the instances that are out of date, as "kops rolling-update cluster --yes" does.
+// It returns true if any instances needed to be replaced.
+func (o *Operator) rollingUpdateCluster(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) (bool, error) {
+	keyStore, err := o.Clientset.KeyStore(cluster)
+	if err != nil {
+		return false, err
+	}
+	secretStore, err := o.Clientset.SecretStore(cluster)
+	if err != nil {
+		return false, err
+	}
+	conf, err := kubeconfig.BuildKubecfg(cluster, keyStore, secretStore, &commands.CloudDiscoveryStatusStore{})
+	if err != nil {
+		return false, err
+	}
+	config, err := conf.BuildRestConfig()
+	if err != nil {
+		return false, err
+	}
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return false, fmt.Errorf("cannot build kube client for %q: %v", cluster.ObjectMeta.Name, err)
+	}
+
+	var nodes []v1.Node
+	nodeList, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error listing nodes in cluster: %v", err)
+	}
+	if nodeList != nil {
+		nodes = nodeList.Items
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return false, err
+	}
+	groups, err := cloud.GetCloudGroups(cluster, instanceGroups, false, nodes)
+	if err != nil {
+		return false, err
+	}
+
+	needUpdate := false
+	for _, group := range groups {
+		if len(group.NeedUpdate) != 0 {
+			needUpdate = true
+		}
+	}
+	if !needUpdate {
+		return false, nil
+	}
+
+	list := &kops.InstanceGroupList{}
+	for _, ig := range instanceGroups {
+		list.Items = append(list.Items, *ig)
+	}
+
+	glog.Infof("starting rolling-update of cluster %q", cluster.ObjectMeta.Name)
+	d := &instancegroups.RollingUpdateCluster{
+		MasterInterval:    5 * time.Minute,
+		NodeInterval:      4 * time.Minute,
+		BastionInterval:   5 * time.Minute,
+		Cloud:             cloud,
+		K8sClient:         k8sClient,
+		ClientConfig:      kutil.NewClientConfig(config, "kube-system"),
+		FailOnValidate:    true,
+		ClusterName:       cluster.ObjectMeta.Name,
+		PostDrainDelay:    90 * time.Second,
+		ValidationTimeout: 5 * time.Minute,
+
+		DetectClusterAutoscaler: true,
+		EtcdQuorumCheck:         true,
+		ValidateConditions:      validation.DefaultNodeConditions,
+
+		Strategy: instancegroups.RollingUpdateStrategyReplace,
+		MaxSurge: 1,
+	}
+	if err := d.RollingUpdate(groups, cluster, list); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// record appends an entry to the audit log.
+// The change has already been made, so failing to record it only logs a warning.
+func (o *Operator) record(clusterName string, operation string, object string, before runtime.Object, after runtime.Object) {
+	if o.AuditLog == nil {
+		return
+	}
+
+	d, err := audit.Diff(before, after)
+	if err != nil {
+		glog.Warningf("unable to serialize %s for the audit log: %v", object, err)
+	}
+	previous, err := audit.Snapshot(before)
+	if err != nil {
+		glog.Warningf("unable to serialize %s for the audit log: %v", object, err)
+	}
+	e := &audit.Entry{
+		User:      o.User,
+		Cluster:   clusterName,
+		Operation: operation,
+		Object:    object,
+		Diff:      d,
+		Previous:  previous,
+	}
+	if err := o.AuditLog.Record(e); err != nil {
+		glog.Warningf("unable to record %s of %s in the audit log: %v", operation, object, err)
+	}
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/audit"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/clusterlock"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const testClusterYAML = `
+apiVersion: kops/v1alpha2
+kind: Cluster
+metadata:
+  name: minimal.example.com
+spec:
+  kubernetesApiAccess:
+  - 0.0.0.0/0
+  cloudProvider: aws
+  configBase: memfs://tests/minimal.example.com
+  etcdClusters:
+  - etcdMembers:
+    - instanceGroup: master-us-test-1a
+      name: us-test-1a
+    name: main
+  - etcdMembers:
+    - instanceGroup: master-us-test-1a
+      name: us-test-1a
+    name: events
+  kubernetesVersion: v1.8.0
+  masterInternalName: api.internal.minimal.example.com
+  masterPublicName: api.minimal.example.com
+  networkCIDR: 172.20.0.0/16
+  networking:
+    kubenet: {}
+  nonMasqueradeCIDR: 100.64.0.0/10
+  sshAccess:
+  - 0.0.0.0/0
+  topology:
+    masters: public
+    nodes: public
+  subnets:
+  - cidr: 172.20.32.0/19
+    name: us-test-1a
+    type: Public
+    zone: us-test-1a
+`
+
+const testInstanceGroupYAML = `
+apiVersion: kops/v1alpha2
+kind: InstanceGroup
+metadata:
+  name: nodes
+  labels:
+    kops.k8s.io/cluster: minimal.example.com
+spec:
+  image: kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-2016-10-21
+  machineType: t2.medium
+  maxSize: 2
+  minSize: 2
+  role: Node
+  subnets:
+  - us-test-1a
+`
+
+type fakeSource struct {
+	clusters       []*kops.Cluster
+	instanceGroups []*kops.InstanceGroup
+}
+
+func (s *fakeSource) Clusters() ([]*kops.Cluster, error) {
+	return s.clusters, nil
+}
+
+func (s *fakeSource) InstanceGroups(clusterName string) ([]*kops.InstanceGroup, error) {
+	var instanceGroups []*kops.InstanceGroup
+	for _, ig := range s.instanceGroups {
+		if ig.ObjectMeta.Labels[kops.LabelClusterName] == clusterName {
+			instanceGroups = append(instanceGroups, ig)
+		}
+	}
+	return instanceGroups, nil
+}
+
+func parseTestObject(t *testing.T, y string) runtime.Object {
+	o, _, err := kopscodecs.ParseVersionedYaml([]byte(y))
+	if err != nil {
+		t.Fatalf("error parsing test object: %v", err)
+	}
+	return o
+}
+
+func TestReconcile(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+	h.SetupMockAWS()
+
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	clientset := vfsclientset.NewVFSClientset(base, true)
+
+	source := &fakeSource{
+		clusters:       []*kops.Cluster{parseTestObject(t, testClusterYAML).(*kops.Cluster)},
+		instanceGroups: []*kops.InstanceGroup{parseTestObject(t, testInstanceGroupYAML).(*kops.InstanceGroup)},
+	}
+
+	o := NewOperator(clientset, source)
+	o.AuditLog = audit.NewLog(base)
+	o.Locker = clusterlock.NewLocker(base)
+
+	applied := 0
+	o.apply = func(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) error {
+		applied++
+		if len(instanceGroups) != 1 || instanceGroups[0].ObjectMeta.Name != "nodes" {
+			t.Errorf("unexpected instance groups applied: %v", instanceGroups)
+		}
+		return nil
+	}
+	o.rollingUpdate = func(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) (bool, error) {
+		t.Errorf("rolling update was not enabled")
+		return false, nil
+	}
+
+	operations := func() []string {
+		entries, err := o.AuditLog.List("minimal.example.com")
+		if err != nil {
+			t.Fatalf("error listing audit log: %v", err)
+		}
+		var l []string
+		for _, e := range entries {
+			if e.User != "kops-operator" {
+				t.Errorf("expected audit entries to be recorded as kops-operator, got %q", e.User)
+			}
+			l = append(l, e.Operation+" "+e.Object)
+		}
+		return l
+	}
+
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("expected the cluster to be applied once, was applied %d times", applied)
+	}
+	if got := strings.Join(operations(), ","); got != "create cluster,create instancegroup/nodes" {
+		t.Errorf("unexpected audit log after first reconcile: %s", got)
+	}
+
+	// Reconciling an unchanged cluster applies it again, but doesn't rewrite the state store
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("expected the cluster to be applied again, was applied %d times", applied)
+	}
+	if got := len(operations()); got != 2 {
+		t.Errorf("expected no changes to the state store for an unchanged cluster, got %d audit entries", got)
+	}
+
+	source.instanceGroups[0].Spec.MachineType = "m4.large"
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	cluster, err := clientset.GetCluster("minimal.example.com")
+	if err != nil {
+		t.Fatalf("error reading cluster: %v", err)
+	}
+	ig, err := clientset.InstanceGroupsFor(cluster).Get("nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error reading instance group: %v", err)
+	}
+	if ig.Spec.MachineType != "m4.large" {
+		t.Errorf("expected the instance group change to be written to the state store, got %q", ig.Spec.MachineType)
+	}
+	if got := operations(); len(got) != 3 || got[2] != "replace instancegroup/nodes" {
+		t.Errorf("unexpected audit log after changing the instance group: %v", got)
+	}
+
+	// A cluster locked by someone else is left alone
+	if _, err := o.Locker.Lock("minimal.example.com", "alice", "upgrading"); err != nil {
+		t.Fatalf("error locking cluster: %v", err)
+	}
+	if err := o.ReconcileAll(); err == nil {
+		t.Errorf("expected reconciling a locked cluster to fail")
+	}
+	if applied != 3 {
+		t.Errorf("expected a locked cluster not to be applied, was applied %d times", applied)
+	}
+	if err := o.Locker.Unlock("minimal.example.com", "alice", false); err != nil {
+		t.Fatalf("error unlocking cluster: %v", err)
+	}
+
+	// Clusters requiring approval are synced to the state store, but not applied
+	requireApproval := true
+	source.clusters[0].Spec.RequireApproval = &requireApproval
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	if applied != 3 {
+		t.Errorf("expected a cluster requiring approval not to be applied, was applied %d times", applied)
+	}
+	if got := operations(); len(got) != 4 || got[3] != "replace cluster" {
+		t.Errorf("unexpected audit log after requiring approval: %v", got)
+	}
+}
+
+func TestDecodeCustomResource(t *testing.T) {
+	data := []byte(`{
+  "apiVersion": "kops.k8s.io/v1alpha2",
+  "kind": "InstanceGroup",
+  "metadata": {
+    "name": "nodes",
+    "namespace": "kops",
+    "uid": "0b5f7c4e-6d5e-11e8-9bd6-02f3b4a1c3d2",
+    "resourceVersion": "12345",
+    "labels": {"kops.k8s.io/cluster": "minimal.example.com"}
+  },
+  "spec": {"role": "Node", "machineType": "t2.medium", "minSize": 2}
+}`)
+
+	o, err := decodeCustomResource("InstanceGroup", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ig, ok := o.(*kops.InstanceGroup)
+	if !ok {
+		t.Fatalf("expected an InstanceGroup, got %T", o)
+	}
+	if ig.ObjectMeta.Name != "nodes" || ig.ObjectMeta.Labels[kops.LabelClusterName] != "minimal.example.com" {
+		t.Errorf("unexpected metadata: %v", ig.ObjectMeta)
+	}
+	if ig.ObjectMeta.Namespace != "" || ig.ObjectMeta.ResourceVersion != "" || ig.ObjectMeta.UID != "" {
+		t.Errorf("expected custom resource metadata to be dropped, got %v", ig.ObjectMeta)
+	}
+	if ig.Spec.Role != kops.InstanceGroupRoleNode || ig.Spec.MachineType != "t2.medium" {
+		t.Errorf("unexpected spec: %v", ig.Spec)
+	}
+
+	if _, err := decodeCustomResource("InstanceGroup", []byte("not json")); err == nil {
+		t.Errorf("expected error for invalid custom resource")
+	}
+}
+
+func TestRefreshImages(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+	h.SetupMockAWS()
+
+	base := vfs.NewMemFSPath(vfs.NewMemFSContext(), "state")
+	clientset := vfsclientset.NewVFSClientset(base, true)
+
+	ig := parseTestObject(t, testInstanceGroupYAML).(*kops.InstanceGroup)
+	ig.Spec.ImageAlias = "kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-*"
+	source := &fakeSource{
+		clusters:       []*kops.Cluster{parseTestObject(t, testClusterYAML).(*kops.Cluster)},
+		instanceGroups: []*kops.InstanceGroup{ig},
+	}
+
+	window, err := ParseMaintenanceWindow("Sat 02:00-06:00")
+	if err != nil {
+		t.Fatalf("error parsing maintenance window: %v", err)
+	}
+
+	o := NewOperator(clientset, source)
+	o.RefreshImages = true
+	o.MaintenanceWindow = window
+	o.apply = func(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) error {
+		return nil
+	}
+	var rolled []string
+	o.rollingUpdate = func(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) (bool, error) {
+		for _, ig := range instanceGroups {
+			rolled = append(rolled, ig.ObjectMeta.Name+"="+ig.Spec.Image)
+		}
+		return true, nil
+	}
+	o.resolveImage = func(cluster *kops.Cluster, alias string) (string, error) {
+		if alias != ig.Spec.ImageAlias {
+			t.Errorf("unexpected alias %q", alias)
+		}
+		return "kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-2018-06-01", nil
+	}
+
+	storedImage := func() string {
+		cluster, err := clientset.GetCluster("minimal.example.com")
+		if err != nil {
+			t.Fatalf("error reading cluster: %v", err)
+		}
+		stored, err := clientset.InstanceGroupsFor(cluster).Get("nodes", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error reading instance group: %v", err)
+		}
+		return stored.Spec.Image
+	}
+
+	// Outside the maintenance window, images are left alone
+	o.now = func() time.Time { return time.Date(2018, 6, 6, 3, 0, 0, 0, time.UTC) }
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	if got := storedImage(); got != ig.Spec.Image {
+		t.Errorf("expected image not to be refreshed outside the maintenance window, got %q", got)
+	}
+	if len(rolled) != 0 {
+		t.Errorf("expected no rolling update outside the maintenance window, got %v", rolled)
+	}
+
+	// Inside it, the image is refreshed and only the refreshed instance group is replaced
+	o.now = func() time.Time { return time.Date(2018, 6, 2, 3, 0, 0, 0, time.UTC) }
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	if got := storedImage(); got != "kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-2018-06-01" {
+		t.Errorf("expected image to be refreshed in the maintenance window, got %q", got)
+	}
+	if len(rolled) != 1 || rolled[0] != "nodes=kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-2018-06-01" {
+		t.Errorf("unexpected rolling update: %v", rolled)
+	}
+
+	// The refreshed image is kept, even though the source still has the old one
+	o.now = func() time.Time { return time.Date(2018, 6, 6, 3, 0, 0, 0, time.UTC) }
+	if err := o.ReconcileAll(); err != nil {
+		t.Fatalf("unexpected error reconciling: %v", err)
+	}
+	if got := storedImage(); got != "kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-2018-06-01" {
+		t.Errorf("expected the refreshed image to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/kopscodecs"
+)
+
+const (
+	// CustomResourceGroup is the API group of the kops custom resources.
+	// Custom resource groups must be domain names, so it differs from the group of the kops API.
+	CustomResourceGroup = "kops.k8s.io"
+
+	// CustomResourceVersion is the version of the kops API the custom resources hold
+	CustomResourceVersion = "v1alpha2"
+)
+
+// watchRetryInterval is how long we wait before restarting a watch that failed
+const watchRetryInterval = 10 * time.Second
+
+// Source provides the desired state of the clusters the operator manages
+type Source interface {
+	// Clusters returns the desired clusters
+	Clusters() ([]*kops.Cluster, error)
+
+	// InstanceGroups returns the desired instance groups of a cluster
+	InstanceGroups(clusterName string) ([]*kops.InstanceGroup, error)
+}
+
+// CustomResourceSource reads the desired state from kops custom resources in a Kubernetes cluster.
+// The custom resources hold the same objects as the kops state store, in the CustomResourceGroup API group.
+type CustomResourceSource struct {
+	// Client is a REST client for the cluster holding the custom resources
+	Client rest.Interface
+
+	// Namespace is the namespace holding the custom resources
+	Namespace string
+}
+
+var _ Source = &CustomResourceSource{}
+
+// Clusters implements Source::Clusters
+func (s *CustomResourceSource) Clusters() ([]*kops.Cluster, error) {
+	objects, err := s.list("clusters", "Cluster")
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []*kops.Cluster
+	for _, o := range objects {
+		cluster, ok := o.(*kops.Cluster)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type in clusters: %T", o)
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+// InstanceGroups implements Source::InstanceGroups
+func (s *CustomResourceSource) InstanceGroups(clusterName string) ([]*kops.InstanceGroup, error) {
+	objects, err := s.list("instancegroups", "InstanceGroup")
+	if err != nil {
+		return nil, err
+	}
+
+	var instanceGroups []*kops.InstanceGroup
+	for _, o := range objects {
+		ig, ok := o.(*kops.InstanceGroup)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type in instancegroups: %T", o)
+		}
+		if ig.ObjectMeta.Labels[kops.LabelClusterName] != clusterName {
+			continue
+		}
+		instanceGroups = append(instanceGroups, ig)
+	}
+	return instanceGroups, nil
+}
+
+// Watch signals changes on changes whenever a kops custom resource changes, until stop is closed
+func (s *CustomResourceSource) Watch(changes chan<- struct{}, stop <-chan struct{}) {
+	for _, resource := range []string{"clusters", "instancegroups"} {
+		go s.watch(resource, changes, stop)
+	}
+}
+
+func (s *CustomResourceSource) path(resource string) string {
+	return fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", CustomResourceGroup, CustomResourceVersion, s.Namespace, resource)
+}
+
+func (s *CustomResourceSource) list(resource string, kind string) ([]runtime.Object, error) {
+	data, err := s.Client.Get().AbsPath(s.path(resource)).DoRaw()
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %v", resource, err)
+	}
+
+	list := &struct {
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", resource, err)
+	}
+
+	var objects []runtime.Object
+	for _, item := range list.Items {
+		o, err := decodeCustomResource(kind, item)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", resource, err)
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+func (s *CustomResourceSource) watch(resource string, changes chan<- struct{}, stop <-chan struct{}) {
+	for {
+		if err := s.watchOnce(resource, changes, stop); err != nil {
+			glog.Warningf("error watching %s: %v", resource, err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// watchOnce reads watch events for a resource until the watch ends
+func (s *CustomResourceSource) watchOnce(resource string, changes chan<- struct{}, stop <-chan struct{}) error {
+	stream, err := s.Client.Get().AbsPath(s.path(resource)).Param("watch", "true").Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	go func() {
+		<-stop
+		stream.Close()
+	}()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		event := &struct {
+			Type string `json:"type"`
+		}{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			return fmt.Errorf("error parsing watch event: %v", err)
+		}
+		glog.V(2).Infof("watch event %s on %s", event.Type, resource)
+
+		// changes is buffered; if a change is already pending we don't need to queue another
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	return scanner.Err()
+}
+
+// decodeCustomResource converts a kops custom resource to the matching kops API object
+func decodeCustomResource(kind string, data []byte) (runtime.Object, error) {
+	u := make(map[string]interface{})
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, err
+	}
+
+	// The custom resource has the same schema as the kops API, but in a different group;
+	// objects in lists may not have their apiVersion and kind set.
+	u["apiVersion"] = kops.GroupName + "/" + CustomResourceVersion
+	u["kind"] = kind
+
+	// Keep only the metadata that kops uses; the rest describes the custom resource itself
+	if metadata, ok := u["metadata"].(map[string]interface{}); ok {
+		for k := range metadata {
+			switch k {
+			case "name", "labels", "annotations":
+			default:
+				delete(metadata, k)
+			}
+		}
+	}
+
+	converted, err := json.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	o, _, err := kopscodecs.ParseVersionedYaml(converted)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/kopscodecs"
+)
+
+// Operation identifies the kops operation being checked
+type Operation string
+
+const (
+	OperationUpdateCluster        Operation = "update-cluster"
+	OperationRollingUpdateCluster Operation = "rolling-update-cluster"
+)
+
+// Request is the input to a policy check.
+// Objects are serialized in the versioned (external) API, as they would be by kops get -o json.
+type Request struct {
+	// Operation is the operation about to be performed
+	Operation Operation `json:"operation"`
+	// Cluster is the cluster the operation will be performed against
+	Cluster json.RawMessage `json:"cluster"`
+	// InstanceGroups are the instance groups affected by the operation
+	InstanceGroups []json.RawMessage `json:"instanceGroups"`
+}
+
+// Denial is a reason an operation was denied
+type Denial struct {
+	// Policy identifies the policy that denied the operation
+	Policy string `json:"policy,omitempty"`
+	// Message describes why the operation was denied
+	Message string `json:"message"`
+}
+
+// Response is the result of a policy check
+type Response struct {
+	// Allowed is true if the operation may proceed
+	Allowed bool `json:"allowed"`
+	// Denials lists the reasons the operation was denied
+	Denials []Denial `json:"denials,omitempty"`
+}
+
+// Checker evaluates a policy against a request
+type Checker interface {
+	// Check evaluates the policy; an error means the policy could not be evaluated
+	Check(request *Request) (*Response, error)
+}
+
+// BuildRequest builds the policy input for the operation
+func BuildRequest(operation Operation, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) (*Request, error) {
+	request := &Request{
+		Operation: operation,
+	}
+
+	clusterJSON, err := kopscodecs.ToVersionedJSON(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing cluster: %v", err)
+	}
+	request.Cluster = json.RawMessage(clusterJSON)
+
+	for _, ig := range instanceGroups {
+		igJSON, err := kopscodecs.ToVersionedJSON(ig)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+		request.InstanceGroups = append(request.InstanceGroups, json.RawMessage(igJSON))
+	}
+
+	return request, nil
+}
+
+// BuildCheckers builds a checker for each policy source.
+// http:// and https:// sources are webhooks; other sources are rego files, evaluated with opa.
+func BuildCheckers(sources []string) ([]Checker, error) {
+	var checkers []Checker
+	var regoFiles []string
+	for _, source := range sources {
+		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+			checkers = append(checkers, &WebhookChecker{URL: source})
+		} else if strings.HasSuffix(source, ".rego") {
+			regoFiles = append(regoFiles, source)
+		} else {
+			return nil, fmt.Errorf("unknown policy %q: expected a http(s) webhook URL or a .rego file", source)
+		}
+	}
+	if len(regoFiles) != 0 {
+		// Evaluate all rego files together, so they can share helper rules
+		checkers = append(checkers, &RegoChecker{Files: regoFiles})
+	}
+	return checkers, nil
+}
+
+// Enforce evaluates all the policies, returning an error describing every denial if the operation is not allowed
+func Enforce(sources []string, operation Operation, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	checkers, err := BuildCheckers(sources)
+	if err != nil {
+		return err
+	}
+
+	request, err := BuildRequest(operation, cluster, instanceGroups)
+	if err != nil {
+		return err
+	}
+
+	var denials []Denial
+	for _, checker := range checkers {
+		response, err := checker.Check(request)
+		if err != nil {
+			// Fail closed: if we can't evaluate a policy, we don't know that the operation is allowed
+			return fmt.Errorf("error evaluating policy: %v", err)
+		}
+		if !response.Allowed {
+			denials = append(denials, response.Denials...)
+			if len(response.Denials) == 0 {
+				denials = append(denials, Denial{Message: "denied without a reason"})
+			}
+		}
+	}
+
+	if len(denials) == 0 {
+		glog.V(2).Infof("%s of cluster %q allowed by %d policies", operation, cluster.ObjectMeta.Name, len(checkers))
+		return nil
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s of cluster %q denied by policy:\n", operation, cluster.ObjectMeta.Name)
+	for _, d := range denials {
+		if d.Policy != "" {
+			fmt.Fprintf(&b, "  * [%s] %s\n", d.Policy, d.Message)
+		} else {
+			fmt.Fprintf(&b, "  * %s\n", d.Message)
+		}
+	}
+	return fmt.Errorf("%s", b.String())
+}
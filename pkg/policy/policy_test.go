@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func testCluster() *kops.Cluster {
+	return &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "minimal.example.com"},
+		Spec: kops.ClusterSpec{
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Type: kops.SubnetTypePublic},
+			},
+		},
+	}
+}
+
+func TestEnforce_Webhook(t *testing.T) {
+	var received Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("error decoding policy request: %v", err)
+		}
+		response := &Response{Allowed: true}
+		if strings.Contains(string(received.Cluster), `"type":"Public"`) {
+			response = &Response{
+				Allowed: false,
+				Denials: []Denial{{Policy: "no-public-subnets", Message: "subnet us-test-1a is public"}},
+			}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	err := Enforce([]string{server.URL}, OperationUpdateCluster, testCluster(), nil)
+	if err == nil {
+		t.Fatalf("expected operation to be denied")
+	}
+	if !strings.Contains(err.Error(), "[no-public-subnets] subnet us-test-1a is public") {
+		t.Fatalf("expected denial in error, got %q", err)
+	}
+	if received.Operation != OperationUpdateCluster {
+		t.Fatalf("unexpected operation %q", received.Operation)
+	}
+
+	cluster := testCluster()
+	cluster.Spec.Subnets[0].Type = kops.SubnetTypePrivate
+	if err := Enforce([]string{server.URL}, OperationUpdateCluster, cluster, nil); err != nil {
+		t.Fatalf("expected operation to be allowed, got %v", err)
+	}
+}
+
+func TestEnforce_WebhookFailureDenies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := Enforce([]string{server.URL}, OperationRollingUpdateCluster, testCluster(), nil); err == nil {
+		t.Fatalf("expected error when policy webhook fails")
+	}
+}
+
+func TestBuildCheckers(t *testing.T) {
+	checkers, err := BuildCheckers([]string{"https://policy.example.com/check", "a.rego", "b.rego"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checkers) != 2 {
+		t.Fatalf("expected webhook and a single rego checker, got %d checkers", len(checkers))
+	}
+	if rego, ok := checkers[1].(*RegoChecker); !ok || len(rego.Files) != 2 {
+		t.Fatalf("expected rego files to be evaluated together, got %v", checkers[1])
+	}
+
+	if _, err := BuildCheckers([]string{"policy.txt"}); err == nil {
+		t.Fatalf("expected error for unknown policy type")
+	}
+}
+
+func TestParseOPAOutput(t *testing.T) {
+	grid := []struct {
+		Output  string
+		Allowed bool
+		Denials int
+	}{
+		{Output: `{}`, Allowed: true},
+		{Output: `{"result":[{"expressions":[{"value":[],"text":"data.kops.deny"}]}]}`, Allowed: true},
+		{Output: `{"result":[{"expressions":[{"value":["subnet a is public","instance type x1.32xlarge is not allowed"],"text":"data.kops.deny"}]}]}`, Allowed: false, Denials: 2},
+	}
+	for _, g := range grid {
+		response, err := parseOPAOutput("test.rego", []byte(g.Output))
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", g.Output, err)
+			continue
+		}
+		if response.Allowed != g.Allowed || len(response.Denials) != g.Denials {
+			t.Errorf("parseOPAOutput(%q) = %v, expected allowed=%v with %d denials", g.Output, response, g.Allowed, g.Denials)
+		}
+	}
+
+	if _, err := parseOPAOutput("test.rego", []byte(`{"result":[{"expressions":[{"value":true}]}]}`)); err == nil {
+		t.Errorf("expected error when deny is not a set")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RegoQuery is the query we evaluate; policies should define deny[msg] rules in the kops package
+const RegoQuery = "data.kops.deny"
+
+// RegoChecker evaluates rego policies using the opa binary, which must be on the PATH
+type RegoChecker struct {
+	Files []string
+}
+
+var _ Checker = &RegoChecker{}
+
+// Check implements Checker::Check
+func (c *RegoChecker) Check(request *Request) (*Response, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing policy request: %v", err)
+	}
+
+	args := []string{"eval", "--format=json", "--stdin-input"}
+	for _, f := range c.Files {
+		args = append(args, "--data", f)
+	}
+	args = append(args, RegoQuery)
+
+	cmd := exec.Command("opa", args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running opa on %s: %v: %s", strings.Join(c.Files, ","), err, stderr.String())
+	}
+
+	return parseOPAOutput(strings.Join(c.Files, ","), stdout.Bytes())
+}
+
+// opaOutput is the (subset of the) output of opa eval --format=json
+type opaOutput struct {
+	Result []struct {
+		Expressions []struct {
+			Value interface{} `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// parseOPAOutput converts the result of evaluating the deny rules to a Response.
+// An undefined result (no deny rules) means the operation is allowed.
+func parseOPAOutput(policy string, data []byte) (*Response, error) {
+	output := &opaOutput{}
+	if err := json.Unmarshal(data, output); err != nil {
+		return nil, fmt.Errorf("error parsing opa output: %v", err)
+	}
+
+	response := &Response{Allowed: true}
+	for _, result := range output.Result {
+		for _, expression := range result.Expressions {
+			messages, ok := expression.Value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected %s to be a set of messages, was %T", RegoQuery, expression.Value)
+			}
+			for _, message := range messages {
+				response.Allowed = false
+				response.Denials = append(response.Denials, Denial{
+					Policy:  policy,
+					Message: fmt.Sprintf("%v", message),
+				})
+			}
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// WebhookChecker evaluates policy by POSTing the Request as JSON to an external service,
+// which must reply with a JSON Response
+type WebhookChecker struct {
+	URL string
+}
+
+var _ Checker = &WebhookChecker{}
+
+// webhookTimeout bounds how long we wait for a policy webhook
+const webhookTimeout = 30 * time.Second
+
+// Check implements Checker::Check
+func (c *WebhookChecker) Check(request *Request) (*Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing policy request: %v", err)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	httpResponse, err := client.Post(c.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error calling policy webhook %q: %v", c.URL, err)
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from policy webhook %q: %v", c.URL, err)
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from policy webhook %q: %s", c.URL, httpResponse.Status)
+	}
+
+	response := &Response{}
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return nil, fmt.Errorf("error parsing response from policy webhook %q: %v", c.URL, err)
+	}
+	for i := range response.Denials {
+		if response.Denials[i].Policy == "" {
+			response.Denials[i].Policy = c.URL
+		}
+	}
+	return response, nil
+}
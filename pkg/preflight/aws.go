@@ -0,0 +1,300 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+// plannedSize is the number of instances the group may run: its maximum size, or its minimum size if the
+// maximum is not set, or one
+func plannedSize(ig *kops.InstanceGroup) int {
+	if ig.Spec.MaxSize != nil {
+		return int(fi.Int32Value(ig.Spec.MaxSize))
+	}
+	if ig.Spec.MinSize != nil {
+		return int(fi.Int32Value(ig.Spec.MinSize))
+	}
+	return 1
+}
+
+// DefaultAWSVPCLimit is the default limit on VPCs in a region; EC2 does not report the limit of the account
+var DefaultAWSVPCLimit = 5
+
+// AWSQuotas returns the EC2 quotas of the region, with how much more of each the cluster needs.
+// The account's instance limit applies to each instance type, and counts the NAT instances of private subnets;
+// addresses are counted for NAT gateways, and a VPC if the cluster does not use an existing one.
+func AWSQuotas(client ec2iface.EC2API, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, surge []*kops.InstanceGroup) ([]*Quota, error) {
+	clusterName := cluster.ObjectMeta.Name
+
+	attributes, err := client.DescribeAccountAttributes(&ec2.DescribeAccountAttributesInput{
+		AttributeNames: aws.StringSlice([]string{"max-instances", "vpc-max-elastic-ips"}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading account attributes: %v", err)
+	}
+	limits := make(map[string]int)
+	for _, attribute := range attributes.AccountAttributes {
+		for _, value := range attribute.AttributeValues {
+			if n, err := strconv.Atoi(aws.StringValue(value.AttributeValue)); err == nil {
+				limits[aws.StringValue(attribute.AttributeName)] = n
+			}
+		}
+	}
+
+	var quotas []*Quota
+
+	if limit, found := limits["max-instances"]; found {
+		used := make(map[string]int)
+		current := make(map[string]int)
+		request := &ec2.DescribeInstancesInput{
+			Filters: []*ec2.Filter{{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running"}),
+			}},
+		}
+		err = client.DescribeInstancesPages(request, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					instanceType := aws.StringValue(instance.InstanceType)
+					used[instanceType]++
+					if hasClusterTag(instance.Tags, clusterName) {
+						current[instanceType]++
+					}
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing instances: %v", err)
+		}
+
+		planned := plannedByMachineType(append(natInstances(cluster), instanceGroups...))
+		surged := surgeByMachineType(surge)
+		for _, instanceType := range sortedKeys(planned, surged) {
+			quotas = append(quotas, &Quota{
+				Resource: instanceType + " instances",
+				Limit:    limit,
+				Used:     used[instanceType],
+				Needed:   additional(planned[instanceType], current[instanceType]),
+				Surge:    surged[instanceType],
+			})
+		}
+	} else {
+		glog.Warningf("account did not report an instance limit; not checking instance quotas")
+	}
+
+	if limit, found := limits["vpc-max-elastic-ips"]; found {
+		addresses, err := client.DescribeAddresses(&ec2.DescribeAddressesInput{
+			Filters: []*ec2.Filter{{
+				Name:   aws.String("domain"),
+				Values: aws.StringSlice([]string{"vpc"}),
+			}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing addresses: %v", err)
+		}
+		current := 0
+		for _, address := range addresses.Addresses {
+			if hasClusterTag(address.Tags, clusterName) {
+				current++
+			}
+		}
+		quotas = append(quotas, &Quota{
+			Resource: "elastic IP addresses",
+			Limit:    limit,
+			Used:     len(addresses.Addresses),
+			Needed:   additional(plannedNatAddresses(cluster), current),
+		})
+	}
+
+	vpcs, err := client.DescribeVpcs(&ec2.DescribeVpcsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing VPCs: %v", err)
+	}
+	planned := 0
+	if cluster.Spec.NetworkID == "" {
+		planned = 1
+	}
+	current := 0
+	for _, vpc := range vpcs.Vpcs {
+		if hasClusterTag(vpc.Tags, clusterName) {
+			current++
+		}
+	}
+	quotas = append(quotas, &Quota{
+		Resource: "VPCs",
+		Limit:    DefaultAWSVPCLimit,
+		Used:     len(vpcs.Vpcs),
+		Needed:   additional(planned, current),
+	})
+
+	return quotas, nil
+}
+
+// hasClusterTag returns true if the tags mark the resource as belonging to the cluster
+func hasClusterTag(tags []*ec2.Tag, clusterName string) bool {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == awsup.TagClusterName && aws.StringValue(tag.Value) == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedNatAddresses returns the number of elastic IP addresses kops allocates for the NAT gateways of the
+// private subnets: one per zone, or one for a shared NAT gateway, except where an existing address is used
+func plannedNatAddresses(cluster *kops.Cluster) int {
+	addresses := make(map[string]bool)
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Type != kops.SubnetTypePrivate || subnet.PublicIP != "" {
+			continue
+		}
+		switch subnet.Egress {
+		case "", kops.EgressNatGateway:
+			addresses[subnet.Zone] = true
+		case kops.EgressSharedNatGateway:
+			addresses[kops.EgressSharedNatGateway] = true
+		}
+	}
+	return len(addresses)
+}
+
+// natInstances returns the NAT instances kops runs for the private subnets, one per zone, as instance groups
+// so they are counted against the instance quotas
+func natInstances(cluster *kops.Cluster) []*kops.InstanceGroup {
+	machineType := model.DefaultNatInstanceMachineType
+	if cluster.Spec.Topology != nil && cluster.Spec.Topology.NatInstance != nil && cluster.Spec.Topology.NatInstance.MachineType != "" {
+		machineType = cluster.Spec.Topology.NatInstance.MachineType
+	}
+
+	zones := make(map[string]bool)
+	var instances []*kops.InstanceGroup
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Type != kops.SubnetTypePrivate || subnet.Egress != kops.EgressNatInstance || zones[subnet.Zone] {
+			continue
+		}
+		zones[subnet.Zone] = true
+		instances = append(instances, &kops.InstanceGroup{
+			Spec: kops.InstanceGroupSpec{MachineType: machineType, MinSize: fi.Int32(1), MaxSize: fi.Int32(1)},
+		})
+	}
+	return instances
+}
+
+// RequiredAWSActions returns a sample of the IAM actions kops uses to create, update and roll the cluster,
+// covering each of the services it uses
+func RequiredAWSActions(cluster *kops.Cluster) []string {
+	actions := []string{
+		"autoscaling:CreateAutoScalingGroup",
+		"autoscaling:CreateLaunchConfiguration",
+		"autoscaling:DescribeAutoScalingGroups",
+		"autoscaling:TerminateInstanceInAutoScalingGroup",
+		"autoscaling:UpdateAutoScalingGroup",
+		"ec2:AuthorizeSecurityGroupIngress",
+		"ec2:CreateSecurityGroup",
+		"ec2:CreateTags",
+		"ec2:CreateVolume",
+		"ec2:DescribeInstances",
+		"ec2:DescribeSubnets",
+		"ec2:DescribeVpcs",
+		"ec2:RunInstances",
+		"elasticloadbalancing:CreateLoadBalancer",
+		"elasticloadbalancing:DescribeLoadBalancers",
+		"iam:AddRoleToInstanceProfile",
+		"iam:CreateInstanceProfile",
+		"iam:CreateRole",
+		"iam:PassRole",
+		"iam:PutRolePolicy",
+		"s3:GetObject",
+		"s3:ListBucket",
+		"s3:PutObject",
+	}
+	if !dns.IsGossipHostname(cluster.ObjectMeta.Name) {
+		actions = append(actions, "route53:ChangeResourceRecordSets", "route53:ListHostedZones", "route53:ListResourceRecordSets")
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// iamPolicySourceARN returns the ARN of the IAM user or role to simulate the policies of, for the ARN
+// returned by sts:GetCallerIdentity: the ARN of an assumed role session is mapped to the ARN of its role.
+// Roles with a path can't be recovered from the session ARN, so the simulation fails for them.
+func iamPolicySourceARN(callerARN string) string {
+	tokens := strings.SplitN(callerARN, ":", 6)
+	if len(tokens) != 6 || tokens[2] != "sts" || !strings.HasPrefix(tokens[5], "assumed-role/") {
+		return callerARN
+	}
+	role := strings.Split(strings.TrimPrefix(tokens[5], "assumed-role/"), "/")[0]
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", tokens[1], tokens[4], role)
+}
+
+// CheckAWSPermissions simulates the IAM policies of the caller, and fails if any of the actions are denied
+func CheckAWSPermissions(report *Report, client iamiface.IAMAPI, callerARN string, actions []string) {
+	const check = "permissions"
+
+	if strings.HasSuffix(callerARN, ":root") {
+		report.Add(check, StatusPass, "running as the root user of the account, which is allowed every action")
+		return
+	}
+
+	source := iamPolicySourceARN(callerARN)
+	request := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(source),
+		ActionNames:     aws.StringSlice(actions),
+	}
+
+	var denied []string
+	for {
+		response, err := client.SimulatePrincipalPolicy(request)
+		if err != nil {
+			report.Add(check, StatusWarn, "unable to simulate the IAM policies of %s: %v", source, err)
+			return
+		}
+		for _, result := range response.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, aws.StringValue(result.EvalActionName))
+			}
+		}
+		if !aws.BoolValue(response.IsTruncated) {
+			break
+		}
+		request.Marker = response.Marker
+	}
+
+	if len(denied) != 0 {
+		sort.Strings(denied)
+		report.Add(check, StatusFail, "%s is not allowed %s", source, strings.Join(denied, ", "))
+		return
+	}
+	report.Add(check, StatusPass, "%s is allowed the %d actions checked", source, len(actions))
+}
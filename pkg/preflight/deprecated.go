@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// deprecatedClusterField is a field of the cluster spec that is deprecated
+type deprecatedClusterField struct {
+	// Path is the path of the field in the spec
+	Path string
+	// Replacement says what to use instead
+	Replacement string
+	// IsSet returns true if the field is set in the spec
+	IsSet func(spec *kops.ClusterSpec) bool
+}
+
+var deprecatedClusterFields = []deprecatedClusterField{
+	{
+		Path:        "spec.kubeAPIServer.address",
+		Replacement: "use spec.kubeAPIServer.bindAddress and spec.kubeAPIServer.insecureBindAddress",
+		IsSet: func(spec *kops.ClusterSpec) bool {
+			return spec.KubeAPIServer != nil && spec.KubeAPIServer.Address != ""
+		},
+	},
+	{
+		Path:        "spec.kubeAPIServer.admissionControl",
+		Replacement: "use spec.kubeAPIServer.enableAdmissionPlugins",
+		IsSet: func(spec *kops.ClusterSpec) bool {
+			return spec.KubeAPIServer != nil && len(spec.KubeAPIServer.AdmissionControl) != 0
+		},
+	},
+	{
+		Path:        "spec.kubeDNS.image",
+		Replacement: "the image is set by the kube-dns addon",
+		IsSet: func(spec *kops.ClusterSpec) bool {
+			return spec.KubeDNS != nil && spec.KubeDNS.Image != ""
+		},
+	},
+}
+
+// CheckDeprecatedFields warns about deprecated fields set in the stored cluster and instance group specs
+func CheckDeprecatedFields(report *Report, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) {
+	const check = "deprecation"
+
+	found := false
+	for _, field := range deprecatedClusterFields {
+		if field.IsSet(&cluster.Spec) {
+			report.Add(check, StatusWarn, "cluster %s is deprecated; %s", field.Path, field.Replacement)
+			found = true
+		}
+	}
+
+	for _, ig := range instanceGroups {
+		for _, taint := range ig.Spec.Taints {
+			if taint == kops.TaintNoScheduleMaster15 {
+				report.Add(check, StatusWarn, "instancegroup/%s spec.taints %q is deprecated; masters are tainted with node-role.kubernetes.io/master", ig.ObjectMeta.Name, taint)
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		report.Add(check, StatusPass, "no deprecated fields are set")
+	}
+}
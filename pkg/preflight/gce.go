@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+
+	compute "google.golang.org/api/compute/v0.beta"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// GCEQuotas returns the CPU and instance quotas of the region, with how much more of each the cluster needs
+func GCEQuotas(cloud gce.GCECloud, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, surge []*kops.InstanceGroup) ([]*Quota, error) {
+	project := cloud.Project()
+
+	region, err := cloud.Compute().Regions.Get(project, cloud.Region()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("error reading quotas of region %q: %v", cloud.Region(), err)
+	}
+	zones, err := cloud.Zones()
+	if err != nil {
+		return nil, err
+	}
+
+	cpusByMachineType := make(map[string]int)
+	cpus := func(machineType string) (int, error) {
+		if n, found := cpusByMachineType[machineType]; found {
+			return n, nil
+		}
+		m, err := cloud.Compute().MachineTypes.Get(project, zones[0], machineType).Do()
+		if err != nil {
+			return 0, fmt.Errorf("error reading machine type %q: %v", machineType, err)
+		}
+		cpusByMachineType[machineType] = int(m.GuestCpus)
+		return cpusByMachineType[machineType], nil
+	}
+
+	// The instances the cluster runs now, from the sizes and templates of its managed instance groups
+	groups, err := cloud.GetCloudGroups(cluster, instanceGroups, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	currentInstances := 0
+	currentCPUs := 0
+	for _, group := range groups {
+		mig, ok := group.Raw.(*compute.InstanceGroupManager)
+		if !ok {
+			continue
+		}
+		template, err := cloud.Compute().InstanceTemplates.Get(project, gce.LastComponent(mig.InstanceTemplate)).Do()
+		if err != nil {
+			return nil, fmt.Errorf("error reading instance template %q: %v", mig.InstanceTemplate, err)
+		}
+		n, err := cpus(gce.LastComponent(template.Properties.MachineType))
+		if err != nil {
+			return nil, err
+		}
+		currentInstances += int(mig.TargetSize)
+		currentCPUs += int(mig.TargetSize) * n
+	}
+
+	plannedInstances := 0
+	plannedCPUs := 0
+	for machineType, count := range plannedByMachineType(instanceGroups) {
+		n, err := cpus(machineType)
+		if err != nil {
+			return nil, err
+		}
+		plannedInstances += count
+		plannedCPUs += count * n
+	}
+
+	// Surge groups are added one at a time, so the largest is needed
+	surgeInstances := 0
+	surgeCPUs := 0
+	for _, ig := range surge {
+		n, err := cpus(ig.Spec.MachineType)
+		if err != nil {
+			return nil, err
+		}
+		size := plannedSize(ig)
+		if size > surgeInstances {
+			surgeInstances = size
+		}
+		if size*n > surgeCPUs {
+			surgeCPUs = size * n
+		}
+	}
+
+	var quotas []*Quota
+	if q := gceQuota(region.Quotas, "CPUS", "CPUs", plannedCPUs, currentCPUs, surgeCPUs); q != nil {
+		quotas = append(quotas, q)
+	}
+	if q := gceQuota(region.Quotas, "INSTANCES", "instances", plannedInstances, currentInstances, surgeInstances); q != nil {
+		quotas = append(quotas, q)
+	}
+	return quotas, nil
+}
+
+// gceQuota returns the quota of the region for the metric, or nil if the region has no such quota
+func gceQuota(regionQuotas []*compute.Quota, metric string, resource string, planned, current, surge int) *Quota {
+	for _, q := range regionQuotas {
+		if q.Metric != metric {
+			continue
+		}
+		return &Quota{
+			Resource: resource,
+			Limit:    int(q.Limit),
+			Used:     int(q.Usage),
+			Needed:   additional(planned, current),
+			Surge:    surge,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight checks a cluster for problems before it is changed: an unsupported combination of kops and
+// kubernetes versions, deprecated fields in the spec, too little cloud quota and missing permissions.
+package preflight
+
+import (
+	"fmt"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	// StatusPass means the check found no problem
+	StatusPass Status = "pass"
+	// StatusWarn means the check found something worth looking at, or could not be run
+	StatusWarn Status = "warn"
+	// StatusFail means the check found a problem that should be fixed before changing the cluster
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	// Check is the name of the check, e.g. version or quota
+	Check string `json:"check"`
+	// Status is pass, warn or fail
+	Status Status `json:"status"`
+	// Message explains the status
+	Message string `json:"message"`
+}
+
+// Report is the outcome of all the checks
+type Report struct {
+	Results []*Result `json:"results"`
+}
+
+// Add appends a result to the report
+func (r *Report) Add(check string, status Status, format string, args ...interface{}) {
+	r.Results = append(r.Results, &Result{
+		Check:   check,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Count returns the number of results with the status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns true if any check failed
+func (r *Report) Failed() bool {
+	return r.Count(StatusFail) != 0
+}
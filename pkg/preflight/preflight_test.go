@@ -0,0 +1,143 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func statuses(report *Report) string {
+	var s []string
+	for _, r := range report.Results {
+		s = append(s, r.Check+"="+string(r.Status))
+	}
+	return strings.Join(s, ",")
+}
+
+func TestCheckVersions(t *testing.T) {
+	channel := &kops.Channel{
+		Spec: kops.ChannelSpec{
+			KopsVersions: []kops.KopsVersionSpec{
+				{Range: ">=1.10.0", RecommendedVersion: "1.10.1"},
+				{Range: "<1.10.0", RecommendedVersion: "1.10.1", RequiredVersion: "1.10.0"},
+			},
+			KubernetesVersions: []kops.KubernetesVersionSpec{
+				{Range: ">=1.10.0", RecommendedVersion: "1.10.5"},
+			},
+		},
+	}
+
+	grid := []struct {
+		kops       string
+		kubernetes string
+		channel    *kops.Channel
+		expected   string
+	}{
+		{kops: "1.10.1", kubernetes: "1.10.5", channel: channel, expected: "version=pass"},
+		{kops: "1.10.0", kubernetes: "1.10.5", channel: channel, expected: "version=pass,version=warn"},
+		{kops: "1.10.1", kubernetes: "1.11.0", channel: channel, expected: "version=fail"},
+		{kops: "1.9.0", kubernetes: "1.9.3", channel: channel, expected: "version=pass,version=fail"},
+		{kops: "1.10.1", kubernetes: "1.10.5", expected: "version=pass,version=warn"},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{Spec: kops.ClusterSpec{KubernetesVersion: g.kubernetes}}
+		report := &Report{}
+		CheckVersions(report, g.kops, cluster, g.channel)
+		if actual := statuses(report); actual != g.expected {
+			t.Errorf("kops %s, kubernetes %s: expected %s, got %s", g.kops, g.kubernetes, g.expected, actual)
+		}
+	}
+}
+
+func TestCheckDeprecatedFields(t *testing.T) {
+	cluster := &kops.Cluster{}
+	ig := &kops.InstanceGroup{ObjectMeta: metav1.ObjectMeta{Name: "master-us-east-1a"}}
+
+	report := &Report{}
+	CheckDeprecatedFields(report, cluster, []*kops.InstanceGroup{ig})
+	if actual := statuses(report); actual != "deprecation=pass" {
+		t.Errorf("expected no deprecations, got %s", actual)
+	}
+
+	cluster.Spec.KubeAPIServer = &kops.KubeAPIServerConfig{AdmissionControl: []string{"NamespaceLifecycle"}}
+	ig.Spec.Taints = []string{kops.TaintNoScheduleMaster15}
+	report = &Report{}
+	CheckDeprecatedFields(report, cluster, []*kops.InstanceGroup{ig})
+	if actual := statuses(report); actual != "deprecation=warn,deprecation=warn" {
+		t.Errorf("expected two deprecations, got %s", actual)
+	}
+	if !strings.Contains(report.Results[0].Message, "spec.kubeAPIServer.admissionControl") {
+		t.Errorf("unexpected message: %s", report.Results[0].Message)
+	}
+}
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	source string
+	denied map[string]bool
+}
+
+func (f *fakeIAM) SimulatePrincipalPolicy(request *iam.SimulatePrincipalPolicyInput) (*iam.SimulatePolicyResponse, error) {
+	f.source = aws.StringValue(request.PolicySourceArn)
+	response := &iam.SimulatePolicyResponse{}
+	for _, action := range request.ActionNames {
+		decision := iam.PolicyEvaluationDecisionTypeAllowed
+		if f.denied[aws.StringValue(action)] {
+			decision = iam.PolicyEvaluationDecisionTypeImplicitDeny
+		}
+		response.EvaluationResults = append(response.EvaluationResults, &iam.EvaluationResult{
+			EvalActionName: action,
+			EvalDecision:   aws.String(decision),
+		})
+	}
+	return response, nil
+}
+
+func TestCheckAWSPermissions(t *testing.T) {
+	cluster := &kops.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c.k8s.local"}}
+	actions := RequiredAWSActions(cluster)
+	for _, action := range actions {
+		if strings.HasPrefix(action, "route53:") {
+			t.Errorf("gossip clusters should not need %s", action)
+		}
+	}
+
+	client := &fakeIAM{denied: map[string]bool{"iam:PassRole": true}}
+	report := &Report{}
+	CheckAWSPermissions(report, client, "arn:aws:sts::123456789012:assumed-role/admin/session", actions)
+	if client.source != "arn:aws:iam::123456789012:role/admin" {
+		t.Errorf("unexpected policy source: %s", client.source)
+	}
+	if actual := statuses(report); actual != "permissions=fail" || !strings.Contains(report.Results[0].Message, "iam:PassRole") {
+		t.Errorf("expected iam:PassRole to be denied, got %s: %s", actual, report.Results[0].Message)
+	}
+
+	client = &fakeIAM{}
+	report = &Report{}
+	CheckAWSPermissions(report, client, "arn:aws:iam::123456789012:user/kops", actions)
+	if client.source != "arn:aws:iam::123456789012:user/kops" || statuses(report) != "permissions=pass" {
+		t.Errorf("expected all actions to be allowed for %s, got %s", client.source, statuses(report))
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// Quota is a cloud provider's limit on a resource, with how much of it is used and how much more a change needs
+type Quota struct {
+	// Resource names what is limited, e.g. "m4.large instances" or "CPUs"
+	Resource string `json:"resource"`
+	// Limit is the most of the resource the account or project may use
+	Limit int `json:"limit"`
+	// Used is how much of the resource is in use, by this cluster and anything else
+	Used int `json:"used"`
+	// Needed is how much more of the resource the cluster needs once the change is complete
+	Needed int `json:"needed"`
+	// Surge is how much more of the resource is needed temporarily while the change is made, e.g. for a rolling update
+	Surge int `json:"surge,omitempty"`
+}
+
+// Exceeded returns true if the change needs more of the resource than the limit leaves.
+// A quota that is already over its limit does not stop a change that needs no more of the resource.
+func (q *Quota) Exceeded() bool {
+	return q.Needed+q.Surge > 0 && q.Used+q.Needed+q.Surge > q.Limit
+}
+
+func (q *Quota) String() string {
+	s := fmt.Sprintf("%s: %d of %d used, %d more needed", q.Resource, q.Used, q.Limit, q.Needed)
+	if q.Surge != 0 {
+		s += fmt.Sprintf(" and %d more during the update", q.Surge)
+	}
+	return s
+}
+
+// CheckQuotas reports whether each quota leaves room for the change; it warns if there is only room once the
+// change is complete, and not for the instances that are added while it is made
+func CheckQuotas(report *Report, quotas []*Quota) {
+	const check = "quota"
+
+	for _, q := range quotas {
+		switch {
+		case q.Used+q.Needed > q.Limit && q.Needed > 0:
+			report.Add(check, StatusFail, "%s", q)
+		case q.Exceeded():
+			report.Add(check, StatusWarn, "%s", q)
+		default:
+			report.Add(check, StatusPass, "%s", q)
+		}
+	}
+}
+
+// VerifyQuotas returns an error naming each quota that does not leave room for the change
+func VerifyQuotas(quotas []*Quota) error {
+	var exceeded []string
+	for _, q := range quotas {
+		if q.Exceeded() {
+			exceeded = append(exceeded, q.String())
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return fmt.Errorf("insufficient cloud quota, request a limit increase before continuing:\n  %s", strings.Join(exceeded, "\n  "))
+}
+
+// CloudQuotas returns the quotas that limit the cluster, with how much more of each the instance groups need.
+// surge are the instance groups that are added temporarily while the change is made, one at a time, such as the
+// duplicate groups of a blue/green rolling update.  Clouds other than AWS and GCE are not checked.
+func CloudQuotas(cloud fi.Cloud, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, surge []*kops.InstanceGroup) ([]*Quota, error) {
+	switch c := cloud.(type) {
+	case awsup.AWSCloud:
+		return AWSQuotas(c.EC2(), cluster, instanceGroups, surge)
+	case gce.GCECloud:
+		return GCEQuotas(c, cluster, instanceGroups, surge)
+	default:
+		glog.V(2).Infof("not checking quotas on cloud %q", cloud.ProviderID())
+		return nil, nil
+	}
+}
+
+// plannedByMachineType returns the number of instances the groups may run, by machine type
+func plannedByMachineType(instanceGroups []*kops.InstanceGroup) map[string]int {
+	planned := make(map[string]int)
+	for _, ig := range instanceGroups {
+		planned[ig.Spec.MachineType] += plannedSize(ig)
+	}
+	return planned
+}
+
+// surgeByMachineType returns the largest number of instances of each machine type that is added at once,
+// as the surge groups are added one at a time
+func surgeByMachineType(surge []*kops.InstanceGroup) map[string]int {
+	largest := make(map[string]int)
+	for _, ig := range surge {
+		if n := plannedSize(ig); n > largest[ig.Spec.MachineType] {
+			largest[ig.Spec.MachineType] = n
+		}
+	}
+	return largest
+}
+
+// sortedKeys returns the keys of the maps, sorted
+func sortedKeys(maps ...map[string]int) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// additional returns how many more of a resource are needed to go from current to planned
+func additional(planned, current int) int {
+	if planned > current {
+		return planned - current
+	}
+	return 0
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	compute "google.golang.org/api/compute/v0.beta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestQuotaExceeded(t *testing.T) {
+	grid := []struct {
+		quota    Quota
+		exceeded bool
+	}{
+		{quota: Quota{Limit: 20, Used: 10, Needed: 10}, exceeded: false},
+		{quota: Quota{Limit: 20, Used: 10, Needed: 11}, exceeded: true},
+		{quota: Quota{Limit: 20, Used: 10, Needed: 5, Surge: 6}, exceeded: true},
+		// Already over the limit, but nothing more is needed
+		{quota: Quota{Limit: 5, Used: 6}, exceeded: false},
+	}
+	for _, g := range grid {
+		if actual := g.quota.Exceeded(); actual != g.exceeded {
+			t.Errorf("%s: expected exceeded %v, got %v", &g.quota, g.exceeded, actual)
+		}
+	}
+}
+
+func TestCheckAndVerifyQuotas(t *testing.T) {
+	quotas := []*Quota{
+		{Resource: "m4.large instances", Limit: 20, Used: 18, Needed: 3},
+		{Resource: "t2.micro instances", Limit: 20, Used: 18, Needed: 1, Surge: 2},
+		{Resource: "VPCs", Limit: 5, Used: 1, Needed: 1},
+	}
+
+	report := &Report{}
+	CheckQuotas(report, quotas)
+	if actual := statuses(report); actual != "quota=fail,quota=warn,quota=pass" {
+		t.Errorf("unexpected statuses %s", actual)
+	}
+
+	err := VerifyQuotas(quotas)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "m4.large instances: 18 of 20 used, 3 more needed") || !strings.Contains(err.Error(), "t2.micro instances") || strings.Contains(err.Error(), "VPCs") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := VerifyQuotas(quotas[2:]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type fakeEC2 struct {
+	ec2iface.EC2API
+	instances []*ec2.Instance
+	addresses []*ec2.Address
+	vpcs      []*ec2.Vpc
+}
+
+func (f *fakeEC2) DescribeAccountAttributes(*ec2.DescribeAccountAttributesInput) (*ec2.DescribeAccountAttributesOutput, error) {
+	attribute := func(name, value string) *ec2.AccountAttribute {
+		return &ec2.AccountAttribute{
+			AttributeName:   aws.String(name),
+			AttributeValues: []*ec2.AccountAttributeValue{{AttributeValue: aws.String(value)}},
+		}
+	}
+	return &ec2.DescribeAccountAttributesOutput{
+		AccountAttributes: []*ec2.AccountAttribute{attribute("max-instances", "20"), attribute("vpc-max-elastic-ips", "5")},
+	}, nil
+}
+
+func (f *fakeEC2) DescribeInstancesPages(request *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
+	fn(&ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: f.instances}}}, true)
+	return nil
+}
+
+func (f *fakeEC2) DescribeAddresses(*ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+	return &ec2.DescribeAddressesOutput{Addresses: f.addresses}, nil
+}
+
+func (f *fakeEC2) DescribeVpcs(*ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{Vpcs: f.vpcs}, nil
+}
+
+func clusterTags(clusterName string) []*ec2.Tag {
+	return []*ec2.Tag{{Key: aws.String("KubernetesCluster"), Value: aws.String(clusterName)}}
+}
+
+func TestAWSQuotas(t *testing.T) {
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c.example.com"},
+		Spec: kops.ClusterSpec{
+			NetworkID: "vpc-1",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-east-1a", Zone: "us-east-1a", Type: kops.SubnetTypePrivate},
+				{Name: "us-east-1b", Zone: "us-east-1b", Type: kops.SubnetTypePrivate, Egress: kops.EgressNatInstance},
+				{Name: "utility-us-east-1a", Zone: "us-east-1a", Type: kops.SubnetTypeUtility},
+			},
+		},
+	}
+	nodes := &kops.InstanceGroup{Spec: kops.InstanceGroupSpec{MachineType: "m4.large", MinSize: fi.Int32(2), MaxSize: fi.Int32(6)}}
+	instanceGroups := []*kops.InstanceGroup{
+		{Spec: kops.InstanceGroupSpec{MachineType: "m4.large", MinSize: fi.Int32(1), MaxSize: fi.Int32(1)}},
+		nodes,
+	}
+
+	client := &fakeEC2{
+		addresses: []*ec2.Address{{}, {}},
+		vpcs:      []*ec2.Vpc{{Tags: clusterTags("other.example.com")}},
+	}
+	for i := 0; i < 3; i++ {
+		client.instances = append(client.instances, &ec2.Instance{InstanceType: aws.String("m4.large"), Tags: clusterTags("c.example.com")})
+	}
+	for i := 0; i < 12; i++ {
+		client.instances = append(client.instances, &ec2.Instance{InstanceType: aws.String("m4.large")})
+	}
+
+	quotas, err := AWSQuotas(client, cluster, instanceGroups, []*kops.InstanceGroup{nodes})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var actual []string
+	for _, q := range quotas {
+		actual = append(actual, q.String())
+	}
+	expected := []string{
+		// 15 running, 3 of them in the cluster, which may grow to 7, and duplicate the node group of 6
+		"m4.large instances: 15 of 20 used, 4 more needed and 6 more during the update",
+		// The NAT instance of us-east-1b
+		"t2.micro instances: 0 of 20 used, 1 more needed",
+		// The NAT gateway of us-east-1a
+		"elastic IP addresses: 2 of 5 used, 1 more needed",
+		// The cluster uses an existing VPC
+		"VPCs: 1 of 5 used, 0 more needed",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected quotas:\n%s\nexpected:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+	if err := VerifyQuotas(quotas); err == nil || !strings.Contains(err.Error(), "m4.large") {
+		t.Errorf("expected the m4.large quota to be exceeded, got %v", err)
+	}
+}
+
+func TestPlannedNatAddresses(t *testing.T) {
+	grid := []struct {
+		subnets  []kops.ClusterSubnetSpec
+		expected int
+	}{
+		{
+			subnets:  []kops.ClusterSubnetSpec{{Zone: "a", Type: kops.SubnetTypePublic}},
+			expected: 0,
+		},
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Zone: "a", Type: kops.SubnetTypePrivate},
+				{Zone: "b", Type: kops.SubnetTypePrivate, Egress: kops.EgressNatGateway},
+				{Zone: "c", Type: kops.SubnetTypePrivate, PublicIP: "203.0.113.1"},
+				{Zone: "d", Type: kops.SubnetTypePrivate, Egress: "nat-123"},
+			},
+			expected: 2,
+		},
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Zone: "a", Type: kops.SubnetTypePrivate, Egress: kops.EgressSharedNatGateway},
+				{Zone: "b", Type: kops.SubnetTypePrivate, Egress: kops.EgressSharedNatGateway},
+			},
+			expected: 1,
+		},
+	}
+	for i, g := range grid {
+		cluster := &kops.Cluster{Spec: kops.ClusterSpec{Subnets: g.subnets}}
+		if actual := plannedNatAddresses(cluster); actual != g.expected {
+			t.Errorf("case %d: expected %d addresses, got %d", i, g.expected, actual)
+		}
+	}
+}
+
+func TestGCEQuota(t *testing.T) {
+	regionQuotas := []*compute.Quota{
+		{Metric: "CPUS", Limit: 24, Usage: 16},
+		{Metric: "INSTANCES", Limit: 100, Usage: 8},
+	}
+
+	q := gceQuota(regionQuotas, "CPUS", "CPUs", 24, 16, 8)
+	if q == nil || q.String() != "CPUs: 16 of 24 used, 8 more needed and 8 more during the update" || !q.Exceeded() {
+		t.Errorf("unexpected CPU quota: %v", q)
+	}
+	if q := gceQuota(regionQuotas, "SSD_TOTAL_GB", "SSD", 1, 0, 0); q != nil {
+		t.Errorf("expected no quota, got %v", q)
+	}
+}
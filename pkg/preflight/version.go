@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"github.com/blang/semver"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/util"
+)
+
+// CheckVersions checks that this version of kops supports the kubernetes version of the cluster, and that neither
+// version is required or recommended to be upgraded by the channel, which may be nil if it could not be loaded
+func CheckVersions(report *Report, kopsVersionString string, cluster *kops.Cluster, channel *kops.Channel) {
+	const check = "version"
+
+	kopsVersion, err := semver.ParseTolerant(kopsVersionString)
+	if err != nil {
+		report.Add(check, StatusWarn, "unable to parse kops version %q", kopsVersionString)
+		return
+	}
+	kubernetesVersion, err := util.ParseKubernetesVersion(cluster.Spec.KubernetesVersion)
+	if err != nil {
+		report.Add(check, StatusFail, "unable to parse kubernetes version %q", cluster.Spec.KubernetesVersion)
+		return
+	}
+
+	// Each minor release of kops supports kubernetes up to the same minor release
+	if kubernetesVersion.Major > kopsVersion.Major || (kubernetesVersion.Major == kopsVersion.Major && kubernetesVersion.Minor > kopsVersion.Minor) {
+		report.Add(check, StatusFail, "kops %s does not support kubernetes %s; upgrade kops to %d.%d or later", kopsVersion, kubernetesVersion, kubernetesVersion.Major, kubernetesVersion.Minor)
+	} else {
+		report.Add(check, StatusPass, "kops %s supports kubernetes %s", kopsVersion, kubernetesVersion)
+	}
+
+	if channel == nil {
+		report.Add(check, StatusWarn, "channel not loaded; not checking for required or recommended upgrades")
+		return
+	}
+
+	if spec := kops.FindKopsVersionSpec(channel.Spec.KopsVersions, kopsVersion); spec != nil {
+		checkUpgrade(report, "kops", kopsVersion, spec.IsUpgradeRequired, spec.FindRecommendedUpgrade)
+	}
+	if spec := kops.FindKubernetesVersionSpec(channel.Spec.KubernetesVersions, *kubernetesVersion); spec != nil {
+		checkUpgrade(report, "kubernetes", *kubernetesVersion, spec.IsUpgradeRequired, spec.FindRecommendedUpgrade)
+	}
+}
+
+// checkUpgrade reports whether the channel requires or recommends an upgrade of the named component
+func checkUpgrade(report *Report, component string, version semver.Version, isRequired func(semver.Version) (bool, error), findRecommended func(semver.Version) (*semver.Version, error)) {
+	const check = "version"
+
+	required, err := isRequired(version)
+	if err != nil {
+		report.Add(check, StatusWarn, "unable to parse the channel's required version of %s", component)
+	}
+	recommended, err := findRecommended(version)
+	if err != nil {
+		report.Add(check, StatusWarn, "unable to parse the channel's recommended version of %s", component)
+	}
+
+	switch {
+	case required && recommended != nil:
+		report.Add(check, StatusFail, "%s %s is no longer supported by the channel; upgrade to %s", component, version, recommended)
+	case required:
+		report.Add(check, StatusFail, "%s %s is no longer supported by the channel", component, version)
+	case recommended != nil:
+		report.Add(check, StatusWarn, "%s %s is available, and recommended by the channel over %s", component, recommended, version)
+	}
+}
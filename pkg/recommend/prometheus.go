@@ -0,0 +1,185 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// DefaultPrometheusCPUQuery is the CPU usage of each node in cores, from the cAdvisor metrics of the kubelet
+	DefaultPrometheusCPUQuery = `sum by (node) (rate(container_cpu_usage_seconds_total{id="/"}[5m]))`
+	// DefaultPrometheusMemoryQuery is the memory usage of each node in bytes, from the cAdvisor metrics of the kubelet
+	DefaultPrometheusMemoryQuery = `sum by (node) (container_memory_working_set_bytes{id="/"})`
+)
+
+// maxPrometheusPoints bounds the number of points we request for each series
+const maxPrometheusPoints = 1000
+
+// PrometheusClient queries the usage of nodes from the Prometheus HTTP API
+type PrometheusClient struct {
+	// URL is the base URL of Prometheus, e.g. http://prometheus.monitoring:9090
+	URL string
+
+	HTTPClient *http.Client
+}
+
+// prometheusResponse is the response of the query_range API
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][]interface{}   `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NodeUsage returns the CPU usage in millicores and memory usage in bytes of each node, at the percentile
+// of their values over the window; queries must return a series per node, labelled with node or instance
+func (c *PrometheusClient) NodeUsage(cpuQuery string, memoryQuery string, window time.Duration, percentile float64, now time.Time) (map[string]int64, map[string]int64, error) {
+	step := window / maxPrometheusPoints
+	if step < time.Minute {
+		step = time.Minute
+	}
+
+	cpu, err := c.queryRange(cpuQuery, now.Add(-window), now, step)
+	if err != nil {
+		return nil, nil, err
+	}
+	memory, err := c.queryRange(memoryQuery, now.Add(-window), now, step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cpuUsage := make(map[string]int64)
+	for node, values := range cpu {
+		cpuUsage[node] = int64(Percentile(values, percentile) * 1000)
+	}
+	memoryUsage := make(map[string]int64)
+	for node, values := range memory {
+		memoryUsage[node] = int64(Percentile(values, percentile))
+	}
+	return cpuUsage, memoryUsage, nil
+}
+
+// queryRange evaluates the query over the time range, returning the values of each series keyed by node name
+func (c *PrometheusClient) queryRange(query string, start time.Time, end time.Time, step time.Duration) (map[string][]float64, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing prometheus URL %q: %v", c.URL, err)
+	}
+	u.Path = u.Path + "/api/v1/query_range"
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatInt(int64(step/time.Second), 10))
+	u.RawQuery = params.Encode()
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	glog.V(2).Infof("querying prometheus: %s", u)
+	response, err := httpClient.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("error querying prometheus: %v", err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading prometheus response: %v", err)
+	}
+
+	result := &prometheusResponse{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("error parsing prometheus response (status %d): %v", response.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("error from prometheus evaluating %q: %s: %s", query, result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unexpected prometheus result type %q for %q", result.Data.ResultType, query)
+	}
+
+	series := make(map[string][]float64)
+	for _, r := range result.Data.Result {
+		node := r.Metric["node"]
+		if node == "" {
+			// The instance label is the address of the scrape target, e.g. the kubelet
+			node = r.Metric["instance"]
+			if host, _, err := net.SplitHostPort(node); err == nil {
+				node = host
+			}
+		}
+		if node == "" {
+			return nil, fmt.Errorf("result of %q has no node or instance label", query)
+		}
+
+		for _, v := range r.Values {
+			if len(v) != 2 {
+				continue
+			}
+			s, ok := v[1].(string)
+			if !ok {
+				continue
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil || math.IsNaN(f) {
+				continue
+			}
+			series[node] = append(series[node], f)
+		}
+	}
+	return series, nil
+}
+
+// Percentile returns the p-th percentile (0-100) of the values, using the nearest rank
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
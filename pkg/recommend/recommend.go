@@ -0,0 +1,277 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// NodeUsage is the allocatable capacity of a node and how much of it is used; CPU is in millicores and memory in bytes
+type NodeUsage struct {
+	Name          string
+	InstanceGroup string
+
+	CPUAllocatable    int64
+	MemoryAllocatable int64
+
+	// CPUUsage and MemoryUsage are the measured usage of the node
+	CPUUsage    int64
+	MemoryUsage int64
+
+	// CPURequests and MemoryRequests are the sum of the requests of the pods scheduled on the node
+	CPURequests    int64
+	MemoryRequests int64
+}
+
+// MachineType is the size of a machine type; CPU is in millicores and memory in bytes
+type MachineType struct {
+	Name   string
+	CPU    int64
+	Memory int64
+}
+
+// Options are the utilization thresholds that recommendations are made at
+type Options struct {
+	// Target is the utilization recommendations aim for
+	Target float64
+	// Low is the utilization below which fewer or smaller instances are recommended
+	Low float64
+	// High is the utilization above which more instances are recommended
+	High float64
+}
+
+// InitDefaults sets the default thresholds
+func (o *Options) InitDefaults() {
+	o.Target = 0.6
+	o.Low = 0.3
+	o.High = 0.8
+}
+
+// Recommendation is the recommended size of an instance group
+type Recommendation struct {
+	InstanceGroup string `json:"instanceGroup"`
+	MachineType   string `json:"machineType"`
+	Nodes         int    `json:"nodes"`
+	MinSize       int32  `json:"minSize"`
+	MaxSize       int32  `json:"maxSize"`
+
+	// CPUUtilization and MemoryUtilization are the demand, the greater of usage and requests, as a fraction of the allocatable capacity
+	CPUUtilization    float64 `json:"cpuUtilization"`
+	MemoryUtilization float64 `json:"memoryUtilization"`
+
+	// NewMachineType, NewMinSize and NewMaxSize are the recommended values, when they differ from the current values
+	NewMachineType string `json:"newMachineType,omitempty"`
+	NewMinSize     *int32 `json:"newMinSize,omitempty"`
+	NewMaxSize     *int32 `json:"newMaxSize,omitempty"`
+
+	// Reason explains the recommendation
+	Reason string `json:"reason"`
+}
+
+// HasChanges is true if the recommendation changes the instance group
+func (r *Recommendation) HasChanges() bool {
+	return r.NewMachineType != "" || r.NewMinSize != nil || r.NewMaxSize != nil
+}
+
+// Apply returns a copy of the instance group with the recommendation applied
+func (r *Recommendation) Apply(ig *kops.InstanceGroup) *kops.InstanceGroup {
+	ig = ig.DeepCopy()
+	if r.NewMachineType != "" {
+		ig.Spec.MachineType = r.NewMachineType
+	}
+	if r.NewMinSize != nil {
+		ig.Spec.MinSize = fi.Int32(*r.NewMinSize)
+	}
+	if r.NewMaxSize != nil {
+		ig.Spec.MaxSize = fi.Int32(*r.NewMaxSize)
+	}
+	return ig
+}
+
+// Recommend recommends the size of an instance group from the usage of its nodes.
+// machineTypes are the machine types that can be recommended instead of the current one; nil if unknown.
+func Recommend(ig *kops.InstanceGroup, nodes []*NodeUsage, machineTypes []*MachineType, options *Options) *Recommendation {
+	r := &Recommendation{
+		InstanceGroup: ig.ObjectMeta.Name,
+		MachineType:   ig.Spec.MachineType,
+		Nodes:         len(nodes),
+		MinSize:       1,
+		MaxSize:       1,
+	}
+	// These match the defaults kops uses when creating the groups
+	if ig.Spec.MinSize != nil {
+		r.MinSize = fi.Int32Value(ig.Spec.MinSize)
+	} else if ig.Spec.Role == kops.InstanceGroupRoleNode {
+		r.MinSize = 2
+	}
+	if ig.Spec.MaxSize != nil {
+		r.MaxSize = fi.Int32Value(ig.Spec.MaxSize)
+	} else if ig.Spec.Role == kops.InstanceGroupRoleNode {
+		r.MaxSize = 2
+	}
+
+	if len(nodes) == 0 {
+		r.Reason = "no nodes found"
+		return r
+	}
+
+	var cpuAllocatable, memoryAllocatable, cpuDemand, memoryDemand int64
+	for _, n := range nodes {
+		cpuAllocatable += n.CPUAllocatable
+		memoryAllocatable += n.MemoryAllocatable
+		cpuDemand += max64(n.CPUUsage, n.CPURequests)
+		memoryDemand += max64(n.MemoryUsage, n.MemoryRequests)
+	}
+	if cpuAllocatable == 0 || memoryAllocatable == 0 {
+		r.Reason = "allocatable capacity unknown"
+		return r
+	}
+	r.CPUUtilization = float64(cpuDemand) / float64(cpuAllocatable)
+	r.MemoryUtilization = float64(memoryDemand) / float64(memoryAllocatable)
+	utilization := math.Max(r.CPUUtilization, r.MemoryUtilization)
+
+	if ig.Spec.Role != kops.InstanceGroupRoleNode {
+		r.Reason = fmt.Sprintf("%s instance groups are not resized", strings.ToLower(string(ig.Spec.Role)))
+		return r
+	}
+
+	// The number of nodes needed to run the demand at the target utilization
+	cpuPerNode := float64(cpuAllocatable) / float64(len(nodes))
+	memoryPerNode := float64(memoryAllocatable) / float64(len(nodes))
+	needed := int32(math.Ceil(math.Max(float64(cpuDemand)/(cpuPerNode*options.Target), float64(memoryDemand)/(memoryPerNode*options.Target))))
+	if needed < 1 {
+		needed = 1
+	}
+
+	switch {
+	case utilization > options.High:
+		if needed > r.MinSize {
+			r.NewMinSize = fi.Int32(needed)
+		}
+		if needed > r.MaxSize {
+			r.NewMaxSize = fi.Int32(needed)
+		}
+		if r.HasChanges() {
+			r.Reason = fmt.Sprintf("utilization %.0f%% is above %.0f%%; %d nodes are needed for %.0f%%", utilization*100, options.High*100, needed, options.Target*100)
+		} else {
+			r.Reason = fmt.Sprintf("utilization %.0f%% is above %.0f%%, but the instance group can already scale to %d nodes", utilization*100, options.High*100, r.MaxSize)
+		}
+
+	case utilization < options.Low:
+		if needed < r.MinSize {
+			r.NewMinSize = fi.Int32(needed)
+			if r.MaxSize > r.MinSize {
+				// Keep the headroom the instance group had
+				r.NewMaxSize = fi.Int32(needed + r.MaxSize - r.MinSize)
+			} else {
+				r.NewMaxSize = fi.Int32(needed)
+			}
+			r.Reason = fmt.Sprintf("utilization %.0f%% is below %.0f%%; %d nodes are enough for %.0f%%", utilization*100, options.Low*100, needed, options.Target*100)
+			return r
+		}
+
+		// We can't remove nodes, but smaller nodes might do
+		if smaller := findSmallerMachineType(ig.Spec.MachineType, machineTypes, nodes, cpuDemand, memoryDemand, options.Target); smaller != nil {
+			r.NewMachineType = smaller.Name
+			r.Reason = fmt.Sprintf("utilization %.0f%% is below %.0f%%; %s is large enough for %.0f%%", utilization*100, options.Low*100, smaller.Name, options.Target*100)
+			return r
+		}
+		r.Reason = fmt.Sprintf("utilization %.0f%% is below %.0f%%, but no smaller size was found", utilization*100, options.Low*100)
+
+	default:
+		r.Reason = fmt.Sprintf("utilization %.0f%% is within %.0f%%-%.0f%%", utilization*100, options.Low*100, options.High*100)
+	}
+
+	return r
+}
+
+// findSmallerMachineType returns the smallest machine type of the same family that runs the demand on the same
+// number of nodes at the target utilization, or nil if there is none smaller than the current machine type
+func findSmallerMachineType(current string, machineTypes []*MachineType, nodes []*NodeUsage, cpuDemand int64, memoryDemand int64, target float64) *MachineType {
+	var currentType *MachineType
+	for _, m := range machineTypes {
+		if m.Name == current {
+			currentType = m
+		}
+	}
+	if currentType == nil || currentType.CPU == 0 || currentType.Memory == 0 {
+		return nil
+	}
+
+	// The system reserves part of each node, so we scale the machine types by the allocatable fraction of the current nodes
+	var cpuAllocatable, memoryAllocatable int64
+	for _, n := range nodes {
+		cpuAllocatable += n.CPUAllocatable
+		memoryAllocatable += n.MemoryAllocatable
+	}
+	cpuFraction := float64(cpuAllocatable) / float64(len(nodes)) / float64(currentType.CPU)
+	memoryFraction := float64(memoryAllocatable) / float64(len(nodes)) / float64(currentType.Memory)
+
+	var candidates []*MachineType
+	for _, m := range machineTypes {
+		if machineTypeFamily(m.Name) != machineTypeFamily(current) {
+			continue
+		}
+		if m.CPU > currentType.CPU || m.Memory > currentType.Memory || (m.CPU == currentType.CPU && m.Memory == currentType.Memory) {
+			continue
+		}
+		cpu := float64(m.CPU) * cpuFraction * float64(len(nodes)) * target
+		memory := float64(m.Memory) * memoryFraction * float64(len(nodes)) * target
+		if float64(cpuDemand) > cpu || float64(memoryDemand) > memory {
+			continue
+		}
+		candidates = append(candidates, m)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Memory != candidates[j].Memory {
+			return candidates[i].Memory < candidates[j].Memory
+		}
+		if candidates[i].CPU != candidates[j].CPU {
+			return candidates[i].CPU < candidates[j].CPU
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0]
+}
+
+// machineTypeFamily returns the family of a machine type, e.g. m4 for m4.large, or n1-standard for n1-standard-4
+func machineTypeFamily(name string) string {
+	if i := strings.Index(name, "."); i != -1 {
+		return name[:i]
+	}
+	if i := strings.LastIndex(name, "-"); i != -1 {
+		return name[:i]
+	}
+	return name
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
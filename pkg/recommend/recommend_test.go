@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+const gb = 1024 * 1024 * 1024
+
+var testMachineTypes = []*MachineType{
+	{Name: "m4.large", CPU: 2000, Memory: 8 * gb},
+	{Name: "m4.xlarge", CPU: 4000, Memory: 16 * gb},
+	{Name: "m4.2xlarge", CPU: 8000, Memory: 32 * gb},
+	{Name: "c4.large", CPU: 2000, Memory: 4 * gb},
+}
+
+func buildNodes(count int, cpuUsage int64, memoryUsage int64) []*NodeUsage {
+	var nodes []*NodeUsage
+	for i := 0; i < count; i++ {
+		nodes = append(nodes, &NodeUsage{
+			Name:              fmt.Sprintf("node-%d", i),
+			CPUAllocatable:    3800,
+			MemoryAllocatable: 15 * gb,
+			CPUUsage:          cpuUsage,
+			MemoryUsage:       memoryUsage,
+		})
+	}
+	return nodes
+}
+
+func TestRecommend(t *testing.T) {
+	options := &Options{}
+	options.InitDefaults()
+
+	grid := []struct {
+		Name           string
+		Role           kops.InstanceGroupRole
+		MinSize        int32
+		MaxSize        int32
+		Nodes          []*NodeUsage
+		NewMachineType string
+		NewMinSize     *int32
+		NewMaxSize     *int32
+	}{
+		{
+			Name:    "within thresholds",
+			MinSize: 3, MaxSize: 3,
+			Nodes: buildNodes(3, 2000, 7*gb),
+		},
+		{
+			Name:    "overloaded",
+			MinSize: 3, MaxSize: 3,
+			Nodes:      buildNodes(3, 3500, 7*gb),
+			NewMinSize: fi.Int32(5), NewMaxSize: fi.Int32(5),
+		},
+		{
+			Name:    "overloaded but can scale",
+			MinSize: 3, MaxSize: 10,
+			Nodes:      buildNodes(3, 3500, 7*gb),
+			NewMinSize: fi.Int32(5),
+		},
+		{
+			Name:    "underused",
+			MinSize: 6, MaxSize: 8,
+			Nodes:      buildNodes(6, 500, 2*gb),
+			NewMinSize: fi.Int32(2), NewMaxSize: fi.Int32(4),
+		},
+		{
+			Name:    "underused at minimum uses a smaller machine type",
+			MinSize: 1, MaxSize: 1,
+			Nodes:          buildNodes(1, 500, 2*gb),
+			NewMachineType: "m4.large",
+		},
+		{
+			Name:    "requests count as demand",
+			MinSize: 1, MaxSize: 1,
+			Nodes: []*NodeUsage{{CPUAllocatable: 3800, MemoryAllocatable: 15 * gb, CPUUsage: 100, CPURequests: 2000, MemoryUsage: gb}},
+		},
+		{
+			Name:    "masters are not resized",
+			Role:    kops.InstanceGroupRoleMaster,
+			MinSize: 1, MaxSize: 1,
+			Nodes: buildNodes(1, 3500, 14*gb),
+		},
+	}
+
+	for _, g := range grid {
+		role := g.Role
+		if role == "" {
+			role = kops.InstanceGroupRoleNode
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec: kops.InstanceGroupSpec{
+				Role:        role,
+				MachineType: "m4.xlarge",
+				MinSize:     fi.Int32(g.MinSize),
+				MaxSize:     fi.Int32(g.MaxSize),
+			},
+		}
+
+		r := Recommend(ig, g.Nodes, testMachineTypes, options)
+		if r.NewMachineType != g.NewMachineType {
+			t.Errorf("%s: unexpected machine type %q, expected %q (%s)", g.Name, r.NewMachineType, g.NewMachineType, r.Reason)
+		}
+		if fi.Int32Value(r.NewMinSize) != fi.Int32Value(g.NewMinSize) || (r.NewMinSize == nil) != (g.NewMinSize == nil) {
+			t.Errorf("%s: unexpected minSize %v, expected %v (%s)", g.Name, fi.Int32Value(r.NewMinSize), fi.Int32Value(g.NewMinSize), r.Reason)
+		}
+		if fi.Int32Value(r.NewMaxSize) != fi.Int32Value(g.NewMaxSize) || (r.NewMaxSize == nil) != (g.NewMaxSize == nil) {
+			t.Errorf("%s: unexpected maxSize %v, expected %v (%s)", g.Name, fi.Int32Value(r.NewMaxSize), fi.Int32Value(g.NewMaxSize), r.Reason)
+		}
+
+		applied := r.Apply(ig)
+		if applied == ig {
+			t.Errorf("%s: Apply should not modify the instance group", g.Name)
+		}
+		if g.NewMachineType != "" && applied.Spec.MachineType != g.NewMachineType {
+			t.Errorf("%s: recommendation not applied", g.Name)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+	grid := map[float64]float64{0: 1, 50: 5, 95: 10, 90: 9, 100: 10}
+	for p, expected := range grid {
+		if actual := Percentile(values, p); actual != expected {
+			t.Errorf("Percentile(%v) = %v, expected %v", p, actual, expected)
+		}
+	}
+	if Percentile(nil, 95) != 0 {
+		t.Errorf("expected 0 for no values")
+	}
+}
+
+func TestPrometheusNodeUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prom/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("query") {
+		case "cpu":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"node":"node-a"},"values":[[1,"0.5"],[2,"1.5"],[3,"NaN"]]},
+				{"metric":{"instance":"node-b:10250"},"values":[[1,"0.25"]]}]}}`)
+		case "memory":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"node":"node-a"},"values":[[1,"1024"],[2,"2048"]]}]}}`)
+		default:
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+		}
+	}))
+	defer server.Close()
+
+	c := &PrometheusClient{URL: server.URL + "/prom"}
+	cpu, memory, err := c.NodeUsage("cpu", "memory", 24*time.Hour, 95, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cpu["node-a"] != 1500 || cpu["node-b"] != 250 || len(cpu) != 2 {
+		t.Errorf("unexpected cpu usage %v", cpu)
+	}
+	if memory["node-a"] != 2048 || len(memory) != 1 {
+		t.Errorf("unexpected memory usage %v", memory)
+	}
+
+	if _, _, err := c.NodeUsage("bad", "memory", time.Hour, 95, time.Now()); err == nil {
+		t.Errorf("expected an error for a failed query")
+	}
+}
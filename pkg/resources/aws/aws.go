@@ -41,6 +41,8 @@ import (
 
 const (
 	TypeAutoscalingLaunchConfig = "autoscaling-config"
+	TypeLaunchTemplate          = "launch-template"
+	TypePlacementGroup          = "placement-group"
 	TypeNatGateway              = "nat-gateway"
 	TypeElasticIp               = "elastic-ip"
 	TypeLoadBalancer            = "load-balancer"
@@ -76,6 +78,8 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 		ListTargetGroups,
 		// ASG
 		ListAutoScalingGroups,
+		ListLaunchTemplates,
+		ListPlacementGroups,
 
 		// Route 53
 		ListRoute53Records,
@@ -1150,7 +1154,14 @@ func ListAutoScalingGroups(cloud fi.Cloud, clusterName string) ([]*resources.Res
 			}
 			blocks = append(blocks, "subnet:"+subnet)
 		}
-		blocks = append(blocks, TypeAutoscalingLaunchConfig+":"+aws.StringValue(asg.LaunchConfigurationName))
+		if aws.StringValue(asg.PlacementGroup) != "" {
+			blocks = append(blocks, TypePlacementGroup+":"+aws.StringValue(asg.PlacementGroup))
+		}
+		if asg.LaunchTemplate != nil {
+			blocks = append(blocks, TypeLaunchTemplate+":"+aws.StringValue(asg.LaunchTemplate.LaunchTemplateId))
+		} else {
+			blocks = append(blocks, TypeAutoscalingLaunchConfig+":"+aws.StringValue(asg.LaunchConfigurationName))
+		}
 
 		resourceTracker.Blocks = blocks
 
@@ -1160,6 +1171,98 @@ func ListAutoScalingGroups(cloud fi.Cloud, clusterName string) ([]*resources.Res
 	return resourceTrackers, nil
 }
 
+// ListLaunchTemplates finds the EC2 launch templates tagged for the cluster
+func ListLaunchTemplates(cloud fi.Cloud, clusterName string) ([]*resources.Resource, error) {
+	c := cloud.(awsup.AWSCloud)
+
+	glog.V(2).Infof("Listing EC2 LaunchTemplates")
+
+	var resourceTrackers []*resources.Resource
+
+	request := &ec2.DescribeLaunchTemplatesInput{
+		Filters: BuildEC2Filters(cloud),
+	}
+	for {
+		response, err := c.EC2().DescribeLaunchTemplates(request)
+		if err != nil {
+			return nil, fmt.Errorf("error listing launch templates: %v", err)
+		}
+		for _, t := range response.LaunchTemplates {
+			resourceTrackers = append(resourceTrackers, &resources.Resource{
+				Name:    aws.StringValue(t.LaunchTemplateName),
+				ID:      aws.StringValue(t.LaunchTemplateId),
+				Type:    TypeLaunchTemplate,
+				Deleter: DeleteLaunchTemplate,
+			})
+		}
+		if aws.StringValue(response.NextToken) == "" {
+			break
+		}
+		request.NextToken = response.NextToken
+	}
+
+	return resourceTrackers, nil
+}
+
+func DeleteLaunchTemplate(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(awsup.AWSCloud)
+
+	id := r.ID
+	glog.V(2).Infof("Deleting EC2 LaunchTemplate %q", id)
+	request := &ec2.DeleteLaunchTemplateInput{
+		LaunchTemplateId: &id,
+	}
+	_, err := c.EC2().DeleteLaunchTemplate(request)
+	if err != nil {
+		return fmt.Errorf("error deleting EC2 LaunchTemplate %q: %v", id, err)
+	}
+	return nil
+}
+
+// ListPlacementGroups finds the EC2 placement groups of the cluster.
+// Placement groups cannot be tagged, so we match the names kops gives them: <instancegroup>.<cluster>
+func ListPlacementGroups(cloud fi.Cloud, clusterName string) ([]*resources.Resource, error) {
+	c := cloud.(awsup.AWSCloud)
+
+	glog.V(2).Infof("Listing EC2 PlacementGroups")
+
+	response, err := c.EC2().DescribePlacementGroups(&ec2.DescribePlacementGroupsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing placement groups: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, pg := range response.PlacementGroups {
+		name := aws.StringValue(pg.GroupName)
+		if !strings.HasSuffix(name, "."+clusterName) {
+			continue
+		}
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    name,
+			ID:      name,
+			Type:    TypePlacementGroup,
+			Deleter: DeletePlacementGroup,
+		})
+	}
+
+	return resourceTrackers, nil
+}
+
+func DeletePlacementGroup(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(awsup.AWSCloud)
+
+	name := r.ID
+	glog.V(2).Infof("Deleting EC2 PlacementGroup %q", name)
+	request := &ec2.DeletePlacementGroupInput{
+		GroupName: &name,
+	}
+	_, err := c.EC2().DeletePlacementGroup(request)
+	if err != nil {
+		return fmt.Errorf("error deleting EC2 PlacementGroup %q: %v", name, err)
+	}
+	return nil
+}
+
 func FindAutoScalingLaunchConfigurations(cloud fi.Cloud, securityGroups sets.String) ([]*resources.Resource, error) {
 	c := cloud.(awsup.AWSCloud)
 
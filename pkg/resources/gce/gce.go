@@ -58,6 +58,7 @@ func ListResourcesGCE(gceCloud gce.GCECloud, clusterName string, region string)
 		cloud:       gceCloud,
 		gceCloud:    gceCloud,
 		clusterName: clusterName,
+		region:      region,
 	}
 
 	{
@@ -130,6 +131,7 @@ type clusterDiscoveryGCE struct {
 	clusterName string
 
 	instanceTemplates []*compute.InstanceTemplate
+	region            string
 	zones             []string
 }
 
@@ -192,34 +194,40 @@ func (d *clusterDiscoveryGCE) listInstanceGroupManagersAndInstances() ([]*resour
 
 	ctx := context.Background()
 
-	for _, zoneName := range d.zones {
-		err := c.Compute().InstanceGroupManagers.List(project, zoneName).Pages(ctx, func(page *compute.InstanceGroupManagerList) error {
-			for i := range page.Items {
-				mig := page.Items[i] // avoid closure-in-loop go-tcha
-				instanceTemplate := instanceTemplates[mig.InstanceTemplate]
-				if instanceTemplate == nil {
-					glog.V(2).Infof("Ignoring MIG with unmanaged InstanceTemplate: %s", mig.InstanceTemplate)
-					continue
-				}
+	addMIG := func(location string, mig *compute.InstanceGroupManager) error {
+		instanceTemplate := instanceTemplates[mig.InstanceTemplate]
+		if instanceTemplate == nil {
+			glog.V(2).Infof("Ignoring MIG with unmanaged InstanceTemplate: %s", mig.InstanceTemplate)
+			return nil
+		}
 
-				resourceTracker := &resources.Resource{
-					Name:    mig.Name,
-					ID:      zoneName + "/" + mig.Name,
-					Type:    typeInstanceGroupManager,
-					Deleter: func(cloud fi.Cloud, r *resources.Resource) error { return gce.DeleteInstanceGroupManager(c, mig) },
-					Obj:     mig,
-				}
+		resourceTracker := &resources.Resource{
+			Name:    mig.Name,
+			ID:      location + "/" + mig.Name,
+			Type:    typeInstanceGroupManager,
+			Deleter: func(cloud fi.Cloud, r *resources.Resource) error { return gce.DeleteInstanceGroupManager(c, mig) },
+			Obj:     mig,
+		}
 
-				resourceTracker.Blocks = append(resourceTracker.Blocks, typeInstanceTemplate+":"+instanceTemplate.Name)
+		resourceTracker.Blocks = append(resourceTracker.Blocks, typeInstanceTemplate+":"+instanceTemplate.Name)
 
-				glog.V(4).Infof("Found resource: %s", mig.SelfLink)
-				resourceTrackers = append(resourceTrackers, resourceTracker)
+		glog.V(4).Infof("Found resource: %s", mig.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
 
-				instanceTrackers, err := d.listManagedInstances(mig)
-				if err != nil {
-					return fmt.Errorf("error listing instances in InstanceGroupManager: %v", err)
+		instanceTrackers, err := d.listManagedInstances(mig)
+		if err != nil {
+			return fmt.Errorf("error listing instances in InstanceGroupManager: %v", err)
+		}
+		resourceTrackers = append(resourceTrackers, instanceTrackers...)
+		return nil
+	}
+
+	for _, zoneName := range d.zones {
+		err := c.Compute().InstanceGroupManagers.List(project, zoneName).Pages(ctx, func(page *compute.InstanceGroupManagerList) error {
+			for i := range page.Items {
+				if err := addMIG(zoneName, page.Items[i]); err != nil {
+					return err
 				}
-				resourceTrackers = append(resourceTrackers, instanceTrackers...)
 			}
 			return nil
 		})
@@ -229,6 +237,18 @@ func (d *clusterDiscoveryGCE) listInstanceGroupManagersAndInstances() ([]*resour
 		}
 	}
 
+	err := c.Compute().RegionInstanceGroupManagers.List(project, d.region).Pages(ctx, func(page *compute.RegionInstanceGroupManagerList) error {
+		for i := range page.Items {
+			if err := addMIG(d.region, page.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing RegionInstanceGroupManagers: %v", err)
+	}
+
 	return resourceTrackers, nil
 }
 
@@ -248,9 +268,15 @@ func (d *clusterDiscoveryGCE) listManagedInstances(igm *compute.InstanceGroupMan
 		url := i.Instance // avoid closure-in-loop go-tcha
 		name := gce.LastComponent(url)
 
+		// Instances of a regional MIG are in any zone of the region
+		instanceZone := zoneName
+		if u, err := gce.ParseGoogleCloudURL(url); err == nil && u.Zone != "" {
+			instanceZone = u.Zone
+		}
+
 		resourceTracker := &resources.Resource{
 			Name: name,
-			ID:   zoneName + "/" + name,
+			ID:   instanceZone + "/" + name,
 			Type: typeInstance,
 			Deleter: func(cloud fi.Cloud, tracker *resources.Resource) error {
 				return gce.DeleteInstance(c, url)
@@ -0,0 +1,245 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+const (
+	resourceTypeServer         = "server"
+	resourceTypeVolume         = "volume"
+	resourceTypeLoadBalancer   = "load-balancer"
+	resourceTypePlacementGroup = "placement-group"
+	resourceTypeNetwork        = "network"
+	resourceTypeSSHKey         = "ssh-key"
+)
+
+type listFn func(hetzner.HetznerCloud, string) ([]*resources.Resource, error)
+
+// ListResources lists the resources labelled with the cluster. Servers and load balancers block the deletion of
+// the network they are attached to, and servers block the deletion of their volumes and placement group.
+func ListResources(cloud hetzner.HetznerCloud, clusterName string) (map[string]*resources.Resource, error) {
+	resourceTrackers := make(map[string]*resources.Resource)
+
+	listFunctions := []listFn{
+		listServers,
+		listVolumes,
+		listLoadBalancers,
+		listPlacementGroups,
+		listNetworks,
+		listSSHKeys,
+	}
+
+	for _, fn := range listFunctions {
+		rt, err := fn(cloud, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range rt {
+			resourceTrackers[t.Type+":"+t.ID] = t
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+func clusterSelector(clusterName string) string {
+	return hetzner.LabelSelector(map[string]string{hetzner.LabelCluster: clusterName})
+}
+
+func listServers(cloud hetzner.HetznerCloud, clusterName string) ([]*resources.Resource, error) {
+	servers, err := cloud.Client().ListServers(clusterSelector(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, server := range servers {
+		t := &resources.Resource{
+			Name:    server.Name,
+			ID:      strconv.Itoa(server.ID),
+			Type:    resourceTypeServer,
+			Deleter: deleteServer,
+			Obj:     server,
+		}
+		for _, privateNet := range server.PrivateNet {
+			t.Blocks = append(t.Blocks, resourceTypeNetwork+":"+strconv.Itoa(privateNet.Network))
+		}
+		if server.PlacementGroup != nil {
+			t.Blocks = append(t.Blocks, resourceTypePlacementGroup+":"+strconv.Itoa(server.PlacementGroup.ID))
+		}
+		resourceTrackers = append(resourceTrackers, t)
+	}
+	return resourceTrackers, nil
+}
+
+func deleteServer(cloud fi.Cloud, t *resources.Resource) error {
+	id, _ := strconv.Atoi(t.ID)
+	if err := cloud.(hetzner.HetznerCloud).Client().DeleteServer(id); err != nil && !hetzner.IsNotFound(err) {
+		return fmt.Errorf("error deleting server %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listVolumes(cloud hetzner.HetznerCloud, clusterName string) ([]*resources.Resource, error) {
+	volumes, err := cloud.Client().ListVolumes(clusterSelector(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, volume := range volumes {
+		t := &resources.Resource{
+			Name:    volume.Name,
+			ID:      strconv.Itoa(volume.ID),
+			Type:    resourceTypeVolume,
+			Deleter: deleteVolume,
+			Obj:     volume,
+		}
+		if volume.Server != nil {
+			t.Blocked = append(t.Blocked, resourceTypeServer+":"+strconv.Itoa(*volume.Server))
+		}
+		resourceTrackers = append(resourceTrackers, t)
+	}
+	return resourceTrackers, nil
+}
+
+func deleteVolume(cloud fi.Cloud, t *resources.Resource) error {
+	id, _ := strconv.Atoi(t.ID)
+	if err := cloud.(hetzner.HetznerCloud).Client().DeleteVolume(id); err != nil && !hetzner.IsNotFound(err) {
+		return fmt.Errorf("error deleting volume %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listLoadBalancers(cloud hetzner.HetznerCloud, clusterName string) ([]*resources.Resource, error) {
+	loadBalancers, err := cloud.Client().ListLoadBalancers(clusterSelector(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancers: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, lb := range loadBalancers {
+		t := &resources.Resource{
+			Name:    lb.Name,
+			ID:      strconv.Itoa(lb.ID),
+			Type:    resourceTypeLoadBalancer,
+			Deleter: deleteLoadBalancer,
+			Obj:     lb,
+		}
+		for _, privateNet := range lb.PrivateNet {
+			t.Blocks = append(t.Blocks, resourceTypeNetwork+":"+strconv.Itoa(privateNet.Network))
+		}
+		resourceTrackers = append(resourceTrackers, t)
+	}
+	return resourceTrackers, nil
+}
+
+func deleteLoadBalancer(cloud fi.Cloud, t *resources.Resource) error {
+	id, _ := strconv.Atoi(t.ID)
+	if err := cloud.(hetzner.HetznerCloud).Client().DeleteLoadBalancer(id); err != nil && !hetzner.IsNotFound(err) {
+		return fmt.Errorf("error deleting load balancer %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listPlacementGroups(cloud hetzner.HetznerCloud, clusterName string) ([]*resources.Resource, error) {
+	placementGroups, err := cloud.Client().ListPlacementGroups(clusterSelector(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing placement groups: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, pg := range placementGroups {
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    pg.Name,
+			ID:      strconv.Itoa(pg.ID),
+			Type:    resourceTypePlacementGroup,
+			Deleter: deletePlacementGroup,
+			Obj:     pg,
+		})
+	}
+	return resourceTrackers, nil
+}
+
+func deletePlacementGroup(cloud fi.Cloud, t *resources.Resource) error {
+	id, _ := strconv.Atoi(t.ID)
+	if err := cloud.(hetzner.HetznerCloud).Client().DeletePlacementGroup(id); err != nil && !hetzner.IsNotFound(err) {
+		return fmt.Errorf("error deleting placement group %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listNetworks(cloud hetzner.HetznerCloud, clusterName string) ([]*resources.Resource, error) {
+	networks, err := cloud.Client().ListNetworks(clusterSelector(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing networks: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, network := range networks {
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    network.Name,
+			ID:      strconv.Itoa(network.ID),
+			Type:    resourceTypeNetwork,
+			Deleter: deleteNetwork,
+			Obj:     network,
+		})
+	}
+	return resourceTrackers, nil
+}
+
+func deleteNetwork(cloud fi.Cloud, t *resources.Resource) error {
+	id, _ := strconv.Atoi(t.ID)
+	if err := cloud.(hetzner.HetznerCloud).Client().DeleteNetwork(id); err != nil && !hetzner.IsNotFound(err) {
+		return fmt.Errorf("error deleting network %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listSSHKeys(cloud hetzner.HetznerCloud, clusterName string) ([]*resources.Resource, error) {
+	keys, err := cloud.Client().ListSSHKeys(clusterSelector(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing ssh keys: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, key := range keys {
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    key.Name,
+			ID:      strconv.Itoa(key.ID),
+			Type:    resourceTypeSSHKey,
+			Deleter: deleteSSHKey,
+			Obj:     key,
+		})
+	}
+	return resourceTrackers, nil
+}
+
+func deleteSSHKey(cloud fi.Cloud, t *resources.Resource) error {
+	id, _ := strconv.Atoi(t.ID)
+	if err := cloud.(hetzner.HetznerCloud).Client().DeleteSSHKey(id); err != nil && !hetzner.IsNotFound(err) {
+		return fmt.Errorf("error deleting ssh key %q: %v", t.Name, err)
+	}
+	return nil
+}
@@ -24,11 +24,16 @@ import (
 	"k8s.io/kops/pkg/resources/aws"
 	"k8s.io/kops/pkg/resources/digitalocean"
 	"k8s.io/kops/pkg/resources/gce"
+	"k8s.io/kops/pkg/resources/hetzner"
 	"k8s.io/kops/pkg/resources/openstack"
+	"k8s.io/kops/pkg/resources/scaleway"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	cloudgce "k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	cloudhetzner "k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
 	cloudopenstack "k8s.io/kops/upup/pkg/fi/cloudup/openstack"
+	cloudscaleway "k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
 	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
 )
 
@@ -41,11 +46,18 @@ func ListResources(cloud fi.Cloud, clusterName string, region string) (map[strin
 		return digitalocean.ListResources(cloud.(*digitalocean.Cloud), clusterName)
 	case kops.CloudProviderGCE:
 		return gce.ListResourcesGCE(cloud.(cloudgce.GCECloud), clusterName, region)
+	case kops.CloudProviderHetzner:
+		return hetzner.ListResources(cloud.(cloudhetzner.HetznerCloud), clusterName)
 	case kops.CloudProviderOpenstack:
 		return openstack.ListResources(cloud.(cloudopenstack.OpenstackCloud), clusterName)
+	case kops.CloudProviderScaleway:
+		return scaleway.ListResources(cloud.(cloudscaleway.ScalewayCloud), clusterName)
 	case kops.CloudProviderVSphere:
 		return resources.ListResourcesVSphere(cloud.(*vsphere.VSphereCloud), clusterName)
 	default:
+		if provider := cloudplugin.Get(cloud.ProviderID()); provider != nil {
+			return provider.ListResources(cloud, clusterName)
+		}
 		return nil, fmt.Errorf("delete on clusters on %q not (yet) supported", cloud.ProviderID())
 	}
 }
@@ -0,0 +1,211 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+const (
+	resourceTypeServer         = "server"
+	resourceTypeVolume         = "volume"
+	resourceTypeLoadBalancer   = "load-balancer"
+	resourceTypePrivateNetwork = "private-network"
+	resourceTypeSSHKey         = "ssh-key"
+)
+
+type listFn func(scaleway.ScalewayCloud, string) ([]*resources.Resource, error)
+
+// ListResources lists the resources tagged with the cluster, and the SSH key kops added to the project for it.
+// Servers block the deletion of the private network they are attached to and of their etcd volumes, and load
+// balancers block the deletion of the private network.
+func ListResources(cloud scaleway.ScalewayCloud, clusterName string) (map[string]*resources.Resource, error) {
+	resourceTrackers := make(map[string]*resources.Resource)
+
+	listFunctions := []listFn{
+		listServers,
+		listVolumes,
+		listLoadBalancers,
+		listPrivateNetworks,
+		listSSHKeys,
+	}
+
+	for _, fn := range listFunctions {
+		rt, err := fn(cloud, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range rt {
+			resourceTrackers[t.Type+":"+t.ID] = t
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+func clusterTags(clusterName string) []string {
+	return []string{scaleway.Tag(scaleway.TagCluster, clusterName)}
+}
+
+func listServers(cloud scaleway.ScalewayCloud, clusterName string) ([]*resources.Resource, error) {
+	servers, err := cloud.Client().ListServers(cloud.Zone(), clusterTags(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, server := range servers {
+		t := &resources.Resource{
+			Name:    server.Name,
+			ID:      server.ID,
+			Type:    resourceTypeServer,
+			Deleter: deleteServer,
+			Obj:     server,
+		}
+		for _, nic := range server.PrivateNICs {
+			t.Blocks = append(t.Blocks, resourceTypePrivateNetwork+":"+nic.PrivateNetworkID)
+		}
+		resourceTrackers = append(resourceTrackers, t)
+	}
+	return resourceTrackers, nil
+}
+
+func deleteServer(cloud fi.Cloud, t *resources.Resource) error {
+	return cloud.(scaleway.ScalewayCloud).DeleteServer(t.Obj.(*scaleway.Server))
+}
+
+func listVolumes(cloud scaleway.ScalewayCloud, clusterName string) ([]*resources.Resource, error) {
+	volumes, err := cloud.Client().ListVolumes(cloud.Zone(), clusterTags(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, volume := range volumes {
+		t := &resources.Resource{
+			Name:    volume.Name,
+			ID:      volume.ID,
+			Type:    resourceTypeVolume,
+			Deleter: deleteVolume,
+			Obj:     volume,
+		}
+		if volume.Server != nil {
+			t.Blocked = append(t.Blocked, resourceTypeServer+":"+volume.Server.ID)
+		}
+		resourceTrackers = append(resourceTrackers, t)
+	}
+	return resourceTrackers, nil
+}
+
+func deleteVolume(cloud fi.Cloud, t *resources.Resource) error {
+	c := cloud.(scaleway.ScalewayCloud)
+	if err := c.Client().DeleteVolume(c.Zone(), t.ID); err != nil && !scaleway.IsNotFound(err) {
+		return fmt.Errorf("error deleting volume %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listLoadBalancers(cloud scaleway.ScalewayCloud, clusterName string) ([]*resources.Resource, error) {
+	loadBalancers, err := cloud.Client().ListLoadBalancers(cloud.Zone(), clusterTags(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancers: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, lb := range loadBalancers {
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    lb.Name,
+			ID:      lb.ID,
+			Type:    resourceTypeLoadBalancer,
+			Deleter: deleteLoadBalancer,
+			Obj:     lb,
+		})
+	}
+	return resourceTrackers, nil
+}
+
+func deleteLoadBalancer(cloud fi.Cloud, t *resources.Resource) error {
+	c := cloud.(scaleway.ScalewayCloud)
+	if err := c.Client().DeleteLoadBalancer(c.Zone(), t.ID); err != nil && !scaleway.IsNotFound(err) {
+		return fmt.Errorf("error deleting load balancer %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+func listPrivateNetworks(cloud scaleway.ScalewayCloud, clusterName string) ([]*resources.Resource, error) {
+	networks, err := cloud.Client().ListPrivateNetworks(cloud.Zone(), clusterTags(clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing private networks: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, network := range networks {
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    network.Name,
+			ID:      network.ID,
+			Type:    resourceTypePrivateNetwork,
+			Deleter: deletePrivateNetwork,
+			Obj:     network,
+		})
+	}
+	return resourceTrackers, nil
+}
+
+func deletePrivateNetwork(cloud fi.Cloud, t *resources.Resource) error {
+	c := cloud.(scaleway.ScalewayCloud)
+	if err := c.Client().DeletePrivateNetwork(c.Zone(), t.ID); err != nil && !scaleway.IsNotFound(err) {
+		return fmt.Errorf("error deleting private network %q: %v", t.Name, err)
+	}
+	return nil
+}
+
+// listSSHKeys finds the SSH keys kops named after the cluster; SSH keys are not tagged, and a key the cluster
+// references by name with sshKeyName is left alone
+func listSSHKeys(cloud scaleway.ScalewayCloud, clusterName string) ([]*resources.Resource, error) {
+	keys, err := cloud.Client().ListSSHKeys("")
+	if err != nil {
+		return nil, fmt.Errorf("error listing ssh keys: %v", err)
+	}
+
+	prefix := "kubernetes." + clusterName + "-"
+	var resourceTrackers []*resources.Resource
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Name, prefix) {
+			continue
+		}
+		resourceTrackers = append(resourceTrackers, &resources.Resource{
+			Name:    key.Name,
+			ID:      key.ID,
+			Type:    resourceTypeSSHKey,
+			Deleter: deleteSSHKey,
+			Obj:     key,
+		})
+	}
+	return resourceTrackers, nil
+}
+
+func deleteSSHKey(cloud fi.Cloud, t *resources.Resource) error {
+	if err := cloud.(scaleway.ScalewayCloud).Client().DeleteSSHKey(t.ID); err != nil && !scaleway.IsNotFound(err) {
+		return fmt.Errorf("error deleting ssh key %q: %v", t.Name, err)
+	}
+	return nil
+}
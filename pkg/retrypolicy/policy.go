@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retrypolicy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBackoff is the delay before the first retry, for clients that don't have their own retry rules
+	DefaultBackoff = 1 * time.Second
+	// DefaultMaxBackoff caps the delay between retries, for clients that don't have their own retry rules
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Policy bounds how long cloud and kubernetes API calls may take, and how failed calls are retried.
+// Zero values leave the behaviour of each client unchanged.
+type Policy struct {
+	// Timeout is the maximum duration of an API call, including its retries
+	Timeout time.Duration
+	// MaxAttempts is the maximum number of attempts of an API call, including the first
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles with each further retry
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries
+	MaxBackoff time.Duration
+}
+
+var (
+	// mutex guards current
+	mutex sync.Mutex
+	// current is the policy of this process
+	current Policy
+)
+
+// Set configures the policy applied to the API clients built from now on
+func Set(p Policy) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	current = p
+}
+
+// Current returns the configured policy
+func Current() Policy {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return current
+}
+
+// Validate checks that the policy is consistent
+func (p Policy) Validate() error {
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, was %v", p.Timeout)
+	}
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("maximum attempts must not be negative, was %d", p.MaxAttempts)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("retry backoff must not be negative, was %v", p.Backoff)
+	}
+	if p.MaxBackoff < 0 {
+		return fmt.Errorf("maximum retry backoff must not be negative, was %v", p.MaxBackoff)
+	}
+	if p.MaxBackoff != 0 && p.MaxBackoff < p.Backoff {
+		return fmt.Errorf("maximum retry backoff (%v) must not be less than the retry backoff (%v)", p.MaxBackoff, p.Backoff)
+	}
+	return nil
+}
+
+// Retries returns the number of retries allowed after the first attempt, or defaultRetries if MaxAttempts is not set
+func (p Policy) Retries(defaultRetries int) int {
+	if p.MaxAttempts == 0 {
+		return defaultRetries
+	}
+	return p.MaxAttempts - 1
+}
+
+// HasBackoff is true if the policy sets the delay between retries
+func (p Policy) HasBackoff() bool {
+	return p.Backoff != 0 || p.MaxBackoff != 0
+}
+
+// Delay returns the delay before the given retry, counting from zero, using the defaults for unset values
+func (p Policy) Delay(retry int) time.Duration {
+	delay := p.Backoff
+	if delay == 0 {
+		delay = DefaultBackoff
+	}
+	max := p.MaxBackoff
+	if max == 0 {
+		max = DefaultMaxBackoff
+	}
+	if max < delay {
+		max = delay
+	}
+
+	for i := 0; i < retry && delay < max; i++ {
+		delay = delay * 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
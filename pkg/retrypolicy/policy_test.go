@@ -0,0 +1,147 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retrypolicy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	grid := []struct {
+		Policy   Policy
+		Expected string
+	}{
+		{Policy: Policy{}},
+		{Policy: Policy{Timeout: time.Minute, MaxAttempts: 3, Backoff: time.Second, MaxBackoff: 10 * time.Second}},
+		{Policy: Policy{Timeout: -time.Second}, Expected: "timeout must not be negative"},
+		{Policy: Policy{MaxAttempts: -1}, Expected: "maximum attempts must not be negative"},
+		{Policy: Policy{Backoff: 10 * time.Second, MaxBackoff: time.Second}, Expected: "must not be less than the retry backoff"},
+	}
+	for _, g := range grid {
+		err := g.Policy.Validate()
+		if g.Expected == "" {
+			if err != nil {
+				t.Errorf("unexpected error for %+v: %v", g.Policy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), g.Expected) {
+			t.Errorf("expected error containing %q for %+v, got %v", g.Expected, g.Policy, err)
+		}
+	}
+}
+
+func TestDelay(t *testing.T) {
+	p := Policy{Backoff: 2 * time.Second, MaxBackoff: 10 * time.Second}
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, e := range expected {
+		if actual := p.Delay(i); actual != e {
+			t.Errorf("unexpected delay for retry %d: %v, expected %v", i, actual, e)
+		}
+	}
+
+	if actual := (Policy{}).Delay(10); actual != DefaultMaxBackoff {
+		t.Errorf("unexpected default delay: %v", actual)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	if actual := (Policy{}).Retries(13); actual != 13 {
+		t.Errorf("expected the default retries, got %d", actual)
+	}
+	if actual := (Policy{MaxAttempts: 1}).Retries(13); actual != 0 {
+		t.Errorf("expected no retries, got %d", actual)
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	rt := &retryTransport{
+		policy: Policy{MaxAttempts: 3, Backoff: time.Millisecond},
+		inner:  http.DefaultTransport,
+	}
+	client := &http.Client{Transport: rt}
+
+	response, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status: %d", response.StatusCode)
+	}
+	if len(requests) != 3 {
+		t.Errorf("expected 3 attempts of a GET, got %v", requests)
+	}
+
+	// Requests with a body are not replayed
+	requests = nil
+	response, err = client.Post(server.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	if len(requests) != 1 {
+		t.Errorf("expected a single attempt of a POST, got %v", requests)
+	}
+}
+
+func TestRetryTransportTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	rt := &retryTransport{
+		policy: Policy{MaxAttempts: 10, Backoff: time.Hour},
+		inner:  http.DefaultTransport,
+	}
+	client := &http.Client{Transport: rt, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	_, err := client.Get(server.URL)
+	if err == nil {
+		t.Fatalf("expected the timeout to interrupt the retries")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("timeout did not interrupt the backoff, took %v", elapsed)
+	}
+}
+
+func TestWrapTransportWithoutRetries(t *testing.T) {
+	Set(Policy{})
+	defer Set(Policy{})
+
+	if rt := WrapTransport(http.DefaultTransport); rt != http.DefaultTransport {
+		t.Errorf("expected the transport to be unchanged when retries are not configured")
+	}
+
+	Set(Policy{MaxAttempts: 2})
+	if _, ok := WrapTransport(http.DefaultTransport).(*retryTransport); !ok {
+		t.Errorf("expected the transport to be wrapped when retries are configured")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retrypolicy
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/rest"
+)
+
+// retryableStatusCodes are the responses that are retried, as the server may succeed later
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// WrapTransport retries idempotent requests that fail with a connection error or a retryable status, as allowed by
+// the current policy.  If the policy does not allow retries, the transport is returned unchanged.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	p := Current()
+	if p.Retries(0) <= 0 {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &retryTransport{policy: p, inner: rt}
+}
+
+// ConfigureKubernetes applies the current policy to a kubernetes client configuration; the client's timeout
+// includes the retries of the wrapped transport
+func ConfigureKubernetes(config *rest.Config) {
+	p := Current()
+	if p.Timeout != 0 {
+		config.Timeout = p.Timeout
+	}
+
+	if p.Retries(0) > 0 {
+		wrap := config.WrapTransport
+		config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			if wrap != nil {
+				rt = wrap(rt)
+			}
+			return WrapTransport(rt)
+		}
+	}
+}
+
+// retryTransport is a RoundTripper retrying idempotent requests
+type retryTransport struct {
+	policy Policy
+	inner  http.RoundTripper
+}
+
+var _ http.RoundTripper = &retryTransport{}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only requests without a body can be safely replayed
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || (req.Body != nil && req.Body != http.NoBody) {
+		return t.inner.RoundTrip(req)
+	}
+
+	retries := t.policy.Retries(0)
+	for retry := 0; ; retry++ {
+		response, err := t.inner.RoundTrip(req)
+
+		retryable := err != nil || retryableStatusCodes[response.StatusCode]
+		if !retryable || retry >= retries || req.Context().Err() != nil {
+			return response, err
+		}
+
+		description := ""
+		if err != nil {
+			description = err.Error()
+		} else {
+			description = response.Status
+			response.Body.Close()
+		}
+
+		delay := t.policy.Delay(retry)
+		glog.Infof("Retryable error (%s) from %s %s - will retry after delay of %v", description, req.Method, req.URL.Host, delay)
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subnets
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// ByZone implements sort.Interface for []*ClusterSubnetSpec based on
+// the Zone field.
+type ByZone []*kops.ClusterSubnetSpec
+
+func (a ByZone) Len() int {
+	return len(a)
+}
+func (a ByZone) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+func (a ByZone) Less(i, j int) bool {
+	return a[i].Zone < a[j].Zone
+}
+
+// Layout returns the subnets of a cluster with the given topology across the zones: a subnet per zone,
+// named after the zone, and for the private topology an additional utility subnet per zone
+func Layout(zones []string, topology string) ([]kops.ClusterSubnetSpec, error) {
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("at least one zone is required")
+	}
+
+	subnetType := kops.SubnetTypePublic
+	switch topology {
+	case kops.TopologyPublic:
+	case kops.TopologyPrivate:
+		subnetType = kops.SubnetTypePrivate
+	default:
+		return nil, fmt.Errorf("unknown topology %q; must be %q or %q", topology, kops.TopologyPublic, kops.TopologyPrivate)
+	}
+
+	var subnets []kops.ClusterSubnetSpec
+	seen := make(map[string]bool)
+	for _, zone := range zones {
+		if seen[zone] {
+			return nil, fmt.Errorf("zone %q is specified more than once", zone)
+		}
+		seen[zone] = true
+
+		subnets = append(subnets, kops.ClusterSubnetSpec{
+			Name: zone,
+			Zone: zone,
+			Type: subnetType,
+		})
+	}
+
+	if topology == kops.TopologyPrivate {
+		for _, zone := range zones {
+			subnets = append(subnets, kops.ClusterSubnetSpec{
+				Name: "utility-" + zone,
+				Zone: zone,
+				Type: kops.SubnetTypeUtility,
+			})
+		}
+	}
+
+	return subnets, nil
+}
+
+// Plan lays out the subnets of a cluster with the given topology across the zones, and assigns each a CIDR
+// within networkCIDR that does not overlap any of the existing CIDRs
+func Plan(networkCIDR string, zones []string, topology string, existing []*net.IPNet) ([]kops.ClusterSubnetSpec, error) {
+	subnets, err := Layout(zones, topology)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []*kops.ClusterSubnetSpec
+	for i := range subnets {
+		specs = append(specs, &subnets[i])
+	}
+	if err := Assign(networkCIDR, specs, existing); err != nil {
+		return nil, err
+	}
+
+	if err := Validate(networkCIDR, subnets, existing); err != nil {
+		return nil, err
+	}
+
+	return subnets, nil
+}
+
+// Assign assigns a CIDR within networkCIDR to each subnet that does not already have one.
+// The assigned CIDRs do not overlap the CIDRs of the other subnets, nor any of the reserved CIDRs.
+func Assign(networkCIDR string, subnets []*kops.ClusterSubnetSpec, reserved []*net.IPNet) error {
+	_, cidr, err := net.ParseCIDR(networkCIDR)
+	if err != nil {
+		return fmt.Errorf("Invalid NetworkCIDR: %q", networkCIDR)
+	}
+
+	// We split the network range into 8 subnets
+	// But we then reserve the lowest one for the private block
+	// (and we split _that_ into 8 further subnets, leaving the first one unused/for future use)
+	// Note that this limits us to 7 zones
+	// TODO: Does this make sense on GCE?
+	// TODO: Should we limit this to say 1000 IPs per subnet? (any reason to?)
+
+	bigCIDRs, err := SplitInto8(cidr)
+	if err != nil {
+		return err
+	}
+
+	var bigSubnets []*kops.ClusterSubnetSpec
+	var littleSubnets []*kops.ClusterSubnetSpec
+
+	reserved = append([]*net.IPNet(nil), reserved...)
+	for _, subnet := range subnets {
+		switch subnet.Type {
+		case kops.SubnetTypePublic, kops.SubnetTypePrivate:
+			bigSubnets = append(bigSubnets, subnet)
+
+		case kops.SubnetTypeUtility:
+			littleSubnets = append(littleSubnets, subnet)
+
+		default:
+			return fmt.Errorf("subnet %q has unknown type %q", subnet.Name, subnet.Type)
+		}
+
+		if subnet.CIDR != "" {
+			_, subnetCIDR, err := net.ParseCIDR(subnet.CIDR)
+			if err != nil {
+				return fmt.Errorf("subnet %q has unexpected CIDR %q", subnet.Name, subnet.CIDR)
+			}
+
+			reserved = append(reserved, subnetCIDR)
+		}
+	}
+
+	// Remove any CIDRs marked as overlapping
+	bigCIDRs = removeOverlapping(bigCIDRs, reserved)
+
+	if len(bigCIDRs) == 0 {
+		return fmt.Errorf("could not find any non-overlapping CIDRs in parent NetworkCIDR; cannot automatically assign CIDR to subnet")
+	}
+
+	littleCIDRs, err := SplitInto8(bigCIDRs[0])
+	if err != nil {
+		return err
+	}
+	littleCIDRs = removeOverlapping(littleCIDRs, reserved)
+	bigCIDRs = bigCIDRs[1:]
+
+	// Assign a consistent order
+	sort.Sort(ByZone(bigSubnets))
+	sort.Sort(ByZone(littleSubnets))
+
+	for _, subnet := range bigSubnets {
+		if subnet.CIDR != "" {
+			continue
+		}
+
+		if len(bigCIDRs) == 0 {
+			return fmt.Errorf("insufficient (big) CIDRs remaining for automatic CIDR allocation to subnet %q", subnet.Name)
+		}
+		subnet.CIDR = bigCIDRs[0].String()
+		glog.Infof("Assigned CIDR %s to subnet %s", subnet.CIDR, subnet.Name)
+
+		bigCIDRs = bigCIDRs[1:]
+	}
+
+	for _, subnet := range littleSubnets {
+		if subnet.CIDR != "" {
+			continue
+		}
+
+		if len(littleCIDRs) == 0 {
+			return fmt.Errorf("insufficient (little) CIDRs remaining for automatic CIDR allocation to subnet %q", subnet.Name)
+		}
+		subnet.CIDR = littleCIDRs[0].String()
+		glog.Infof("Assigned CIDR %s to subnet %s", subnet.CIDR, subnet.Name)
+
+		littleCIDRs = littleCIDRs[1:]
+	}
+
+	return nil
+}
+
+// Validate checks that the CIDR of each subnet is within networkCIDR, and does not overlap
+// the CIDR of another subnet or any of the existing CIDRs
+func Validate(networkCIDR string, subnets []kops.ClusterSubnetSpec, existing []*net.IPNet) error {
+	_, network, err := net.ParseCIDR(networkCIDR)
+	if err != nil {
+		return fmt.Errorf("Invalid NetworkCIDR: %q", networkCIDR)
+	}
+
+	cidrs := make([]*net.IPNet, len(subnets))
+	for i, subnet := range subnets {
+		_, cidr, err := net.ParseCIDR(subnet.CIDR)
+		if err != nil {
+			return fmt.Errorf("subnet %q has invalid CIDR %q", subnet.Name, subnet.CIDR)
+		}
+		if !network.Contains(cidr.IP) || !network.Contains(lastIP(cidr)) {
+			return fmt.Errorf("subnet %q CIDR %q is not within the network CIDR %q", subnet.Name, subnet.CIDR, networkCIDR)
+		}
+		for j := 0; j < i; j++ {
+			if Overlap(cidrs[j], cidr) {
+				return fmt.Errorf("subnet %q CIDR %q overlaps subnet %q CIDR %q", subnet.Name, subnet.CIDR, subnets[j].Name, subnets[j].CIDR)
+			}
+		}
+		for _, e := range existing {
+			if Overlap(e, cidr) {
+				return fmt.Errorf("subnet %q CIDR %q overlaps existing subnet CIDR %q", subnet.Name, subnet.CIDR, e)
+			}
+		}
+		cidrs[i] = cidr
+	}
+
+	return nil
+}
+
+// ParseCIDRs parses a list of CIDRs
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var parsed []*net.IPNet
+	for _, s := range cidrs {
+		_, cidr, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %v", s, err)
+		}
+		parsed = append(parsed, cidr)
+	}
+	return parsed, nil
+}
+
+// SplitInto8 splits the parent IPNet into 8 subnets
+func SplitInto8(parent *net.IPNet) ([]*net.IPNet, error) {
+	networkLength, _ := parent.Mask.Size()
+	networkLength += 3
+
+	var subnets []*net.IPNet
+	for i := 0; i < 8; i++ {
+		ip4 := parent.IP.To4()
+		if ip4 != nil {
+			n := binary.BigEndian.Uint32(ip4)
+			n += uint32(i) << uint(32-networkLength)
+			subnetIP := make(net.IP, len(ip4))
+			binary.BigEndian.PutUint32(subnetIP, n)
+
+			subnets = append(subnets, &net.IPNet{
+				IP:   subnetIP,
+				Mask: net.CIDRMask(networkLength, 32),
+			})
+		} else {
+			return nil, fmt.Errorf("Unexpected IP address type: %s", parent)
+		}
+	}
+
+	return subnets, nil
+}
+
+// Overlap returns true iff the two CIDRs are non-disjoint
+func Overlap(l, r *net.IPNet) bool {
+	return l.Contains(r.IP) || r.Contains(l.IP)
+}
+
+// removeOverlapping returns the CIDRs that do not overlap any of the reserved CIDRs
+func removeOverlapping(cidrs []*net.IPNet, reserved []*net.IPNet) []*net.IPNet {
+	var nonOverlapping []*net.IPNet
+	for _, c := range cidrs {
+		overlapped := false
+		for _, r := range reserved {
+			if Overlap(r, c) {
+				overlapped = true
+			}
+		}
+		if !overlapped {
+			nonOverlapping = append(nonOverlapping, c)
+		}
+	}
+	return nonOverlapping
+}
+
+// lastIP returns the last address of the CIDR
+func lastIP(cidr *net.IPNet) net.IP {
+	ip := make(net.IP, len(cidr.IP))
+	for i := range cidr.IP {
+		ip[i] = cidr.IP[i] | ^cidr.Mask[i]
+	}
+	return ip
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subnets
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func Test_Split_Subnet(t *testing.T) {
+	tests := []struct {
+		parent   string
+		expected []string
+	}{
+		{
+			parent:   "1.2.3.0/24",
+			expected: []string{"1.2.3.0/27", "1.2.3.32/27", "1.2.3.64/27", "1.2.3.96/27", "1.2.3.128/27", "1.2.3.160/27", "1.2.3.192/27", "1.2.3.224/27"},
+		},
+		{
+			parent:   "1.2.3.0/27",
+			expected: []string{"1.2.3.0/30", "1.2.3.4/30", "1.2.3.8/30", "1.2.3.12/30", "1.2.3.16/30", "1.2.3.20/30", "1.2.3.24/30", "1.2.3.28/30"},
+		},
+	}
+	for _, test := range tests {
+		_, parent, err := net.ParseCIDR(test.parent)
+		if err != nil {
+			t.Fatalf("error parsing parent cidr %q: %v", test.parent, err)
+		}
+
+		subnets, err := SplitInto8(parent)
+		if err != nil {
+			t.Fatalf("error splitting parent cidr %q: %v", parent, err)
+		}
+
+		var actual []string
+		for _, subnet := range subnets {
+			actual = append(actual, subnet.String())
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Fatalf("unexpected result of split: actual=%v, expected=%v", actual, test.expected)
+		}
+	}
+}
+
+func Test_Plan(t *testing.T) {
+	tests := []struct {
+		topology string
+		zones    []string
+		existing []string
+		expected []string
+	}{
+		{
+			topology: kops.TopologyPublic,
+			zones:    []string{"us-test-1a", "us-test-1b"},
+			expected: []string{"us-test-1a=172.20.32.0/19", "us-test-1b=172.20.64.0/19"},
+		},
+		{
+			topology: kops.TopologyPrivate,
+			zones:    []string{"us-test-1b", "us-test-1a"},
+			expected: []string{
+				"us-test-1b=172.20.64.0/19", "us-test-1a=172.20.32.0/19",
+				"utility-us-test-1b=172.20.4.0/22", "utility-us-test-1a=172.20.0.0/22",
+			},
+		},
+		{
+			topology: kops.TopologyPrivate,
+			zones:    []string{"us-test-1a", "us-test-1b"},
+			existing: []string{"172.20.32.0/24", "172.20.0.0/22"},
+			expected: []string{
+				"us-test-1a=172.20.96.0/19", "us-test-1b=172.20.128.0/19",
+				"utility-us-test-1a=172.20.64.0/22", "utility-us-test-1b=172.20.68.0/22",
+			},
+		},
+	}
+	for i, test := range tests {
+		existing, err := ParseCIDRs(test.existing)
+		if err != nil {
+			t.Fatalf("unexpected error on test %d: %v", i+1, err)
+		}
+
+		subnets, err := Plan("172.20.0.0/16", test.zones, test.topology, existing)
+		if err != nil {
+			t.Fatalf("unexpected error on test %d: %v", i+1, err)
+		}
+
+		var actual []string
+		for _, subnet := range subnets {
+			actual = append(actual, subnet.Name+"="+subnet.CIDR)
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Fatalf("unexpected plan (#%d): actual=%v, expected=%v", i+1, actual, test.expected)
+		}
+	}
+}
+
+func Test_PlanErrors(t *testing.T) {
+	if _, err := Plan("172.20.0.0/16", []string{"us-test-1a"}, "hybrid", nil); err == nil {
+		t.Errorf("expected error for unknown topology")
+	}
+	if _, err := Plan("172.20.0.0/16", []string{"us-test-1a", "us-test-1a"}, kops.TopologyPublic, nil); err == nil {
+		t.Errorf("expected error for duplicate zone")
+	}
+
+	zones := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	if _, err := Plan("172.20.0.0/16", zones, kops.TopologyPublic, nil); err == nil {
+		t.Errorf("expected error when there are more zones than available CIDRs")
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		subnets  []kops.ClusterSubnetSpec
+		existing []string
+		valid    bool
+	}{
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Name: "a", CIDR: "172.20.32.0/19"},
+				{Name: "b", CIDR: "172.20.64.0/19"},
+			},
+			valid: true,
+		},
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Name: "a", CIDR: "172.20.32.0/19"},
+				{Name: "b", CIDR: "172.20.48.0/20"},
+			},
+		},
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Name: "a", CIDR: "172.21.0.0/19"},
+			},
+		},
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Name: "a", CIDR: "172.20.0.0/15"},
+			},
+		},
+		{
+			subnets: []kops.ClusterSubnetSpec{
+				{Name: "a", CIDR: "172.20.32.0/19"},
+			},
+			existing: []string{"172.20.33.0/24"},
+		},
+	}
+	for i, test := range tests {
+		existing, err := ParseCIDRs(test.existing)
+		if err != nil {
+			t.Fatalf("unexpected error on test %d: %v", i+1, err)
+		}
+
+		err = Validate("172.20.0.0/16", test.subnets, existing)
+		if test.valid && err != nil {
+			t.Errorf("unexpected error on test %d: %v", i+1, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("expected error on test %d", i+1)
+		}
+	}
+}
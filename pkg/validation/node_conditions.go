@@ -21,6 +21,10 @@ import (
 	"k8s.io/api/core/v1"
 )
 
+// DefaultNodeConditions are the node conditions, other than Ready and NetworkUnavailable, which fail validation
+// when they are true; node-problem-detector conditions such as KernelDeadlock can be added to these.
+var DefaultNodeConditions = []string{string(v1.NodeMemoryPressure), string(v1.NodeDiskPressure)}
+
 func getNodeReadyStatus(node *v1.Node) v1.ConditionStatus {
 	cond := findNodeCondition(node, v1.NodeReady)
 	if cond != nil {
@@ -66,3 +70,15 @@ func isNodeReady(node *v1.Node) bool {
 
 	return true
 }
+
+// findFailedNodeConditions returns the conditions of the specified types that are true on the node
+func findFailedNodeConditions(node *v1.Node, conditionTypes []string) []*v1.NodeCondition {
+	var failed []*v1.NodeCondition
+	for _, conditionType := range conditionTypes {
+		cond := findNodeCondition(node, v1.NodeConditionType(conditionType))
+		if cond != nil && cond.Status == v1.ConditionTrue {
+			failed = append(failed, cond)
+		}
+	}
+	return failed
+}
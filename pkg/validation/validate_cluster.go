@@ -85,8 +85,25 @@ func hasPlaceHolderIP(clusterName string) (bool, error) {
 	return false, nil
 }
 
-// ValidateCluster validates a k8s cluster with a provided instance group list
+// ValidationOptions configures cluster validation
+type ValidationOptions struct {
+	// NodeConditions are the node conditions, other than Ready, which fail validation when they are true
+	NodeConditions []string
+
+	// PodNamespace is the namespace of the pods selected by PodSelectors; all namespaces if empty
+	PodNamespace string
+
+	// PodSelectors are label selectors for additional pods, outside kube-system, which must be ready for validation to pass
+	PodSelectors []string
+}
+
+// ValidateCluster validates a k8s cluster with a provided instance group list, checking the DefaultNodeConditions
 func ValidateCluster(cluster *kops.Cluster, instanceGroupList *kops.InstanceGroupList, k8sClient kubernetes.Interface) (*ValidationCluster, error) {
+	return ValidateClusterWithOptions(cluster, instanceGroupList, k8sClient, &ValidationOptions{NodeConditions: DefaultNodeConditions})
+}
+
+// ValidateClusterWithOptions validates a k8s cluster with a provided instance group list
+func ValidateClusterWithOptions(cluster *kops.Cluster, instanceGroupList *kops.InstanceGroupList, k8sClient kubernetes.Interface, options *ValidationOptions) (*ValidationCluster, error) {
 	clusterName := cluster.Name
 
 	v := &ValidationCluster{}
@@ -142,7 +159,7 @@ func ValidateCluster(cluster *kops.Cluster, instanceGroupList *kops.InstanceGrou
 	if err != nil {
 		return nil, err
 	}
-	v.validateNodes(cloudGroups)
+	v.validateNodes(cloudGroups, options.NodeConditions)
 
 	if err := v.collectComponentFailures(k8sClient); err != nil {
 		return nil, fmt.Errorf("cannot get component status for %q: %v", clusterName, err)
@@ -152,6 +169,12 @@ func ValidateCluster(cluster *kops.Cluster, instanceGroupList *kops.InstanceGrou
 		return nil, fmt.Errorf("cannot get pod health for %q: %v", clusterName, err)
 	}
 
+	for _, selector := range options.PodSelectors {
+		if err = v.collectSelectedPodFailures(k8sClient, options.PodNamespace, selector); err != nil {
+			return nil, fmt.Errorf("cannot get pod health for %q: %v", clusterName, err)
+		}
+	}
+
 	return v, nil
 }
 
@@ -198,7 +221,51 @@ func (v *ValidationCluster) collectPodFailures(client kubernetes.Interface) erro
 	return nil
 }
 
-func (v *ValidationCluster) validateNodes(cloudGroups map[string]*cloudinstances.CloudInstanceGroup) {
+// collectSelectedPodFailures adds a failure for each pod matching the label selector which is not ready
+func (v *ValidationCluster) collectSelectedPodFailures(client kubernetes.Interface, namespace string, selector string) error {
+	pods, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("error listing Pods matching %q: %v", selector, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded {
+			continue
+		}
+
+		name := pod.Namespace + "/" + pod.Name
+		if v.hasFailure("Pod", name) {
+			continue
+		}
+
+		ready := pod.Status.Phase != v1.PodPending
+		for _, status := range pod.Status.ContainerStatuses {
+			if !status.Ready {
+				ready = false
+			}
+		}
+		if !ready {
+			v.addError(&ValidationError{
+				Kind:    "Pod",
+				Name:    name,
+				Message: fmt.Sprintf("pod %q matching %q is not healthy", name, selector),
+			})
+		}
+	}
+	return nil
+}
+
+// hasFailure returns true if a failure has already been recorded for the named object
+func (v *ValidationCluster) hasFailure(kind string, name string) bool {
+	for _, failure := range v.Failures {
+		if failure.Kind == kind && failure.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *ValidationCluster) validateNodes(cloudGroups map[string]*cloudinstances.CloudInstanceGroup, nodeConditions []string) {
 	for _, cloudGroup := range cloudGroups {
 		var allMembers []*cloudinstances.CloudInstanceGroupMember
 		allMembers = append(allMembers, cloudGroup.Ready...)
@@ -223,6 +290,10 @@ func (v *ValidationCluster) validateNodes(cloudGroups map[string]*cloudinstances
 					// bastion nodes don't join the cluster
 					nodeExpectedToJoin = false
 				}
+				if cloudGroup.InstanceGroup.Spec.Role == kops.InstanceGroupRoleEtcd {
+					// dedicated etcd members run a standalone kubelet and don't join the cluster
+					nodeExpectedToJoin = false
+				}
 
 				if nodeExpectedToJoin {
 					v.addError(&ValidationError{
@@ -249,6 +320,18 @@ func (v *ValidationCluster) validateNodes(cloudGroups map[string]*cloudinstances
 
 			ready := isNodeReady(node)
 
+			for _, cond := range findFailedNodeConditions(node, nodeConditions) {
+				message := fmt.Sprintf("%s %q has condition %s", n.Role, node.Name, cond.Type)
+				if cond.Message != "" {
+					message += ": " + cond.Message
+				}
+				v.addError(&ValidationError{
+					Kind:    "Node",
+					Name:    node.Name,
+					Message: message,
+				})
+			}
+
 			// TODO: Use instance group role instead...
 			if n.Role == "master" {
 				if !ready {
@@ -260,12 +343,12 @@ func (v *ValidationCluster) validateNodes(cloudGroups map[string]*cloudinstances
 				}
 
 				v.Nodes = append(v.Nodes, n)
-			} else if n.Role == "node" {
+			} else if n.Role == "node" || n.Role == "api-server" {
 				if !ready {
 					v.addError(&ValidationError{
 						Kind:    "Node",
 						Name:    node.Name,
-						Message: fmt.Sprintf("node %q is not ready", node.Name),
+						Message: fmt.Sprintf("%s %q is not ready", n.Role, node.Name),
 					})
 				}
 
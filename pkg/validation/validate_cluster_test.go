@@ -70,7 +70,7 @@ func Test_ValidateNodesNotEnough(t *testing.T) {
 	{
 		v := &ValidationCluster{}
 		groups["node-1"].MinSize = 3
-		v.validateNodes(groups)
+		v.validateNodes(groups, DefaultNodeConditions)
 		if len(v.Failures) != 2 {
 			printDebug(t, v)
 			t.Fatal("Too few nodes not caught")
@@ -80,7 +80,7 @@ func Test_ValidateNodesNotEnough(t *testing.T) {
 	{
 		groups["node-1"].MinSize = 2
 		v := &ValidationCluster{}
-		v.validateNodes(groups)
+		v.validateNodes(groups, DefaultNodeConditions)
 		if len(v.Failures) != 1 {
 			printDebug(t, v)
 			t.Fatal("Not ready node not caught")
@@ -90,7 +90,7 @@ func Test_ValidateNodesNotEnough(t *testing.T) {
 	{
 		groups["node-1"].NeedUpdate[0].Node.Status.Conditions[0].Status = v1.ConditionTrue
 		v := &ValidationCluster{}
-		v.validateNodes(groups)
+		v.validateNodes(groups, DefaultNodeConditions)
 		if len(v.Failures) != 0 {
 			printDebug(t, v)
 			t.Fatal("unexpected errors")
@@ -147,6 +147,61 @@ func Test_ValidatePodFailure(t *testing.T) {
 	}
 }
 
+func Test_ValidateSelectedPodFailures(t *testing.T) {
+	v := &ValidationCluster{}
+	client := dummyPodClient(
+		[]map[string]string{
+			{
+				"name":      "ingress-1",
+				"namespace": "infra",
+				"app":       "ingress",
+				"ready":     "false",
+				"phase":     string(v1.PodRunning),
+			},
+			{
+				"name":      "ingress-2",
+				"namespace": "infra",
+				"app":       "ingress",
+				"ready":     "true",
+				"phase":     string(v1.PodRunning),
+			},
+			{
+				"name":      "other",
+				"namespace": "infra",
+				"app":       "other",
+				"ready":     "false",
+				"phase":     string(v1.PodRunning),
+			},
+			{
+				"name":      "ingress-3",
+				"namespace": "default",
+				"app":       "ingress",
+				"ready":     "false",
+				"phase":     string(v1.PodRunning),
+			},
+		},
+	)
+
+	if err := v.collectSelectedPodFailures(client, "infra", "app=ingress"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(v.Failures) != 1 || v.Failures[0].Name != "infra/ingress-1" {
+		printDebug(t, v)
+		t.Fatal("infra/ingress-1 failure expected")
+	}
+
+	// pods which have already failed are only reported once
+	if err := v.collectSelectedPodFailures(client, "", "app=ingress"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(v.Failures) != 2 || v.Failures[1].Name != "default/ingress-3" {
+		printDebug(t, v)
+		t.Fatal("default/ingress-3 failure expected")
+	}
+}
+
 func printDebug(t *testing.T, v *ValidationCluster) {
 	t.Logf("cluster - %d failures", len(v.Failures))
 	for _, fail := range v.Failures {
@@ -159,10 +214,19 @@ func dummyPodClient(pods []map[string]string) kubernetes.Interface {
 }
 
 func dummyPod(podMap map[string]string) v1.Pod {
+	namespace := podMap["namespace"]
+	if namespace == "" {
+		namespace = "kube-system"
+	}
+	var labels map[string]string
+	if podMap["app"] != "" {
+		labels = map[string]string{"app": podMap["app"]}
+	}
 	return v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podMap["name"],
-			Namespace: "kube-system",
+			Namespace: namespace,
+			Labels:    labels,
 		},
 		Spec: v1.PodSpec{},
 		Status: v1.PodStatus{
@@ -208,7 +272,7 @@ func Test_ValidateBastionNodes(t *testing.T) {
 	{
 		v := &ValidationCluster{}
 		groups["ig1"].InstanceGroup.Spec.Role = kopsapi.InstanceGroupRoleNode
-		v.validateNodes(groups)
+		v.validateNodes(groups, DefaultNodeConditions)
 		if len(v.Failures) != 1 {
 			printDebug(t, v)
 			t.Fatal("Nodes are expected to join cluster")
@@ -222,7 +286,7 @@ func Test_ValidateBastionNodes(t *testing.T) {
 	{
 		v := &ValidationCluster{}
 		groups["ig1"].InstanceGroup.Spec.Role = kopsapi.InstanceGroupRoleBastion
-		v.validateNodes(groups)
+		v.validateNodes(groups, DefaultNodeConditions)
 		if len(v.Failures) != 0 {
 			printDebug(t, v)
 			t.Fatal("Bastion nodes are not expected to join cluster")
@@ -230,3 +294,62 @@ func Test_ValidateBastionNodes(t *testing.T) {
 	}
 
 }
+
+func Test_ValidateNodeConditions(t *testing.T) {
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	groups["node-1"] = &cloudinstances.CloudInstanceGroup{
+		InstanceGroup: &kopsapi.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Spec: kopsapi.InstanceGroupSpec{
+				Role: kopsapi.InstanceGroupRoleNode,
+			},
+		},
+		Ready: []*cloudinstances.CloudInstanceGroupMember{
+			{
+				ID: "i-00001",
+				Node: &v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1a"},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: "Ready", Status: v1.ConditionTrue},
+							{Type: "MemoryPressure", Status: v1.ConditionFalse},
+							{Type: "DiskPressure", Status: v1.ConditionTrue, Message: "kubelet has disk pressure"},
+							{Type: "KernelDeadlock", Status: v1.ConditionTrue},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	{
+		v := &ValidationCluster{}
+		v.validateNodes(groups, DefaultNodeConditions)
+		if len(v.Failures) != 1 {
+			printDebug(t, v)
+			t.Fatal("DiskPressure condition not caught")
+		} else if v.Failures[0].Message != "node \"node-1a\" has condition DiskPressure: kubelet has disk pressure" {
+			t.Fatalf("unexpected validation failure: %+v", v.Failures[0])
+		}
+	}
+
+	{
+		v := &ValidationCluster{}
+		v.validateNodes(groups, append(DefaultNodeConditions, "KernelDeadlock"))
+		if len(v.Failures) != 2 {
+			printDebug(t, v)
+			t.Fatal("KernelDeadlock condition not caught")
+		}
+	}
+
+	{
+		v := &ValidationCluster{}
+		v.validateNodes(groups, nil)
+		if len(v.Failures) != 0 {
+			printDebug(t, v)
+			t.Fatal("unexpected errors when no conditions are checked")
+		}
+	}
+}
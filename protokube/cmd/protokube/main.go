@@ -30,15 +30,19 @@ import (
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/protokube/pkg/gossip"
 	gossipdns "k8s.io/kops/protokube/pkg/gossip/dns"
+	"k8s.io/kops/protokube/pkg/gossip/httpsync"
 	"k8s.io/kops/protokube/pkg/gossip/mesh"
 	"k8s.io/kops/protokube/pkg/protokube"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 
 	// Load DNS plugins
 	"github.com/golang/glog"
 	"github.com/spf13/pflag"
 	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/cloudflare"
 	k8scoredns "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/coredns"
 	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/google/clouddns"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/infoblox"
 )
 
 var (
@@ -59,9 +63,10 @@ func main() {
 
 // run is responsible for running the protokube service controller
 func run() error {
-	var zones []string
-	var applyTaints, initializeRBAC, containerized, master, tlsAuth bool
+	var zones, dnsConfig []string
+	var applyTaints, initializeRBAC, containerized, master, etcd, tlsAuth, preferPrivateZones bool
 	var cloud, clusterID, dnsServer, dnsProviderID, dnsInternalSuffix, gossipSecret, gossipListen string
+	var gossipProtocol, gossipProtocolSecondary, gossipListenSecondary, gossipSecretSecondary, gossipStatusListen string
 	var flagChannels, tlsCert, tlsKey, tlsCA, peerCert, peerKey, peerCA string
 	var etcdBackupImage, etcdBackupStore, etcdImageSource, etcdElectionTimeout, etcdHeartbeatInterval string
 	var dnsUpdateInterval int
@@ -70,7 +75,8 @@ func run() error {
 	flag.BoolVar(&containerized, "containerized", containerized, "Set if we are running containerized.")
 	flag.BoolVar(&initializeRBAC, "initialize-rbac", initializeRBAC, "Set if we should initialize RBAC")
 	flag.BoolVar(&master, "master", master, "Whether or not this node is a master")
-	flag.StringVar(&cloud, "cloud", "aws", "CloudProvider we are using (aws,digitalocean,gce)")
+	flag.BoolVar(&etcd, "etcd", etcd, "Whether or not this node is a dedicated etcd instance")
+	flag.StringVar(&cloud, "cloud", "aws", "CloudProvider we are using (aws,digitalocean,gce,hetzner,scaleway)")
 	flag.StringVar(&clusterID, "cluster-id", clusterID, "Cluster ID")
 	flag.StringVar(&dnsInternalSuffix, "dns-internal-suffix", dnsInternalSuffix, "DNS suffix for internal domain names")
 	flag.StringVar(&dnsServer, "dns-server", dnsServer, "DNS Server")
@@ -85,13 +91,20 @@ func run() error {
 	flag.StringVar(&tlsCert, "tls-cert", tlsCert, "Path to a file containing the certificate for etcd server")
 	flag.StringVar(&tlsKey, "tls-key", tlsKey, "Path to a file containing the private key for etcd server")
 	flags.StringSliceVarP(&zones, "zone", "z", []string{}, "Configure permitted zones and their mappings")
-	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, coredns, digitalocean)")
+	flags.BoolVar(&preferPrivateZones, "prefer-private-zones", preferPrivateZones, "If a public and a private zone have the same name, manage the private zone")
+	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, coredns, digitalocean, cloudflare, infoblox)")
+	flags.StringSliceVar(&dnsConfig, "dns-config", dnsConfig, "Configuration for the DNS provider, as key=value pairs")
 	flags.StringVar(&etcdBackupImage, "etcd-backup-image", "", "Set to override the image for (experimental) etcd backups")
 	flags.StringVar(&etcdBackupStore, "etcd-backup-store", "", "Set to enable (experimental) etcd backups")
 	flags.StringVar(&etcdImageSource, "etcd-image", "k8s.gcr.io/etcd:2.2.1", "Etcd Source Container Registry")
 	flags.StringVar(&etcdElectionTimeout, "etcd-election-timeout", etcdElectionTimeout, "time in ms for an election to timeout")
 	flags.StringVar(&etcdHeartbeatInterval, "etcd-heartbeat-interval", etcdHeartbeatInterval, "time in ms of a heartbeat interval")
 	flags.StringVar(&gossipSecret, "gossip-secret", gossipSecret, "Secret to use to secure gossip")
+	flags.StringVar(&gossipProtocol, "gossip-protocol", "mesh", "Gossip protocol to use (mesh)")
+	flags.StringVar(&gossipProtocolSecondary, "gossip-protocol-secondary", gossipProtocolSecondary, "Secondary gossip protocol to run alongside the primary (http)")
+	flags.StringVar(&gossipListenSecondary, "gossip-listen-secondary", "0.0.0.0:3993", "address:port on which to bind for the secondary gossip protocol")
+	flags.StringVar(&gossipSecretSecondary, "gossip-secret-secondary", gossipSecretSecondary, "Secret to use to secure the secondary gossip protocol")
+	flags.StringVar(&gossipStatusListen, "gossip-status-listen", gossip.DefaultStatusListen, "address:port on which to serve the gossip status (empty to disable)")
 
 	manageEtcd := false
 	flag.BoolVar(&manageEtcd, "manage-etcd", manageEtcd, "Set to manage etcd (deprecated in favor of etcd-manager)")
@@ -112,6 +125,9 @@ func run() error {
 			glog.Errorf("Error initializing AWS: %q", err)
 			os.Exit(1)
 		}
+		if etcd {
+			awsVolumes.SetRole(awsup.TagRoleEtcd)
+		}
 		volumes = awsVolumes
 
 		if clusterID == "" {
@@ -142,6 +158,50 @@ func run() error {
 			}
 		}
 
+	} else if cloud == "hetzner" {
+		if clusterID == "" {
+			glog.Error("hetzner requires --cluster-id")
+			os.Exit(1)
+		}
+
+		hetznerVolumes, err := protokube.NewHetznerVolumes(clusterID)
+		if err != nil {
+			glog.Errorf("Error initializing Hetzner: %q", err)
+			os.Exit(1)
+		}
+
+		volumes = hetznerVolumes
+
+		if internalIP == nil {
+			internalIP, err = protokube.GetHetznerInternalIP()
+			if err != nil {
+				glog.Errorf("Error getting server internal IP: %s", err)
+				os.Exit(1)
+			}
+		}
+
+	} else if cloud == "scaleway" {
+		if clusterID == "" {
+			glog.Error("scaleway requires --cluster-id")
+			os.Exit(1)
+		}
+
+		scalewayVolumes, err := protokube.NewScalewayVolumes(clusterID)
+		if err != nil {
+			glog.Errorf("Error initializing Scaleway: %q", err)
+			os.Exit(1)
+		}
+
+		volumes = scalewayVolumes
+
+		if internalIP == nil {
+			internalIP, err = protokube.GetScalewayInternalIP()
+			if err != nil {
+				glog.Errorf("Error getting server internal IP: %s", err)
+				os.Exit(1)
+			}
+		}
+
 	} else if cloud == "gce" {
 		gceVolumes, err := protokube.NewGCEVolumes()
 		if err != nil {
@@ -235,6 +295,24 @@ func run() error {
 				return err
 			}
 			gossipName = volumes.(*protokube.GCEVolumes).InstanceName()
+		} else if cloud == "digitalocean" {
+			gossipSeeds, err = volumes.(*protokube.DOVolumes).GossipSeeds()
+			if err != nil {
+				return err
+			}
+			gossipName = volumes.(*protokube.DOVolumes).InstanceName()
+		} else if cloud == "hetzner" {
+			gossipSeeds, err = volumes.(*protokube.HetznerVolumes).GossipSeeds()
+			if err != nil {
+				return err
+			}
+			gossipName = volumes.(*protokube.HetznerVolumes).InstanceName()
+		} else if cloud == "scaleway" {
+			gossipSeeds, err = volumes.(*protokube.ScalewayVolumes).GossipSeeds()
+			if err != nil {
+				return err
+			}
+			gossipName = volumes.(*protokube.ScalewayVolumes).InstanceName()
 		} else {
 			glog.Fatalf("seed provider for %q not yet implemented", cloud)
 		}
@@ -245,20 +323,30 @@ func run() error {
 		}
 
 		channelName := "dns"
-		gossipState, err := mesh.NewMeshGossiper(gossipListen, channelName, gossipName, []byte(gossipSecret), gossipSeeds)
+		var gossipState gossip.GossipState
+
+		gossipState, err = getGossiper(gossipProtocol, gossipListen, channelName, gossipName, []byte(gossipSecret), gossipSeeds)
 		if err != nil {
 			glog.Errorf("Error initializing gossip: %v", err)
 			os.Exit(1)
 		}
 
-		go func() {
-			err := gossipState.Start()
+		if gossipProtocolSecondary != "" {
+			secondaryGossipState, err := getGossiper(gossipProtocolSecondary, gossipListenSecondary, channelName, gossipName, []byte(gossipSecretSecondary), gossipSeeds)
 			if err != nil {
-				glog.Fatalf("gossip exited unexpectedly: %v", err)
-			} else {
-				glog.Fatalf("gossip exited unexpectedly, but without error")
+				glog.Errorf("Error initializing secondary gossip: %v", err)
+				os.Exit(1)
 			}
-		}()
+
+			gossipState = gossip.NewMultiGossiper(gossipState, secondaryGossipState)
+		}
+
+		if gossipStatusListen != "" {
+			go func() {
+				err := gossip.ServeStatus(gossipStatusListen, gossipName, gossipState)
+				glog.Errorf("error serving gossip status: %v", err)
+			}()
+		}
 
 		dnsView := gossipdns.NewDNSView(gossipState)
 		zoneInfo := gossipdns.DNSZoneInfo{
@@ -279,7 +367,13 @@ func run() error {
 		var dnsController *dns.DNSController
 		{
 			var file io.Reader
-			if dnsProviderID == k8scoredns.ProviderName {
+			if len(dnsConfig) != 0 {
+				f, err := dns.BuildProviderConfig(dnsConfig)
+				if err != nil {
+					return fmt.Errorf("error parsing DNS provider config: %v", err)
+				}
+				file = f
+			} else if dnsProviderID == k8scoredns.ProviderName {
 				var lines []string
 				lines = append(lines, "etcd-endpoints = "+dnsServer)
 				lines = append(lines, "zones = "+zones[0])
@@ -299,6 +393,7 @@ func run() error {
 			if err != nil {
 				return fmt.Errorf("unexpected zone flags: %q", err)
 			}
+			zoneRules.PreferPrivate = preferPrivateZones
 
 			dnsController, err = dns.NewDNSController([]dnsprovider.Interface{dnsProvider}, zoneRules, dnsUpdateInterval)
 			if err != nil {
@@ -341,6 +436,7 @@ func run() error {
 		InternalIP:            internalIP,
 		Kubernetes:            protokube.NewKubernetesContext(),
 		Master:                master,
+		Etcd:                  etcd,
 		ModelDir:              modelDir,
 		PeerCA:                peerCA,
 		PeerCert:              peerCert,
@@ -362,6 +458,43 @@ func run() error {
 	return fmt.Errorf("Unexpected exit")
 }
 
+// getGossiper builds and starts the named gossip protocol
+func getGossiper(protocol string, listen string, channelName string, nodeName string, secret []byte, seeds gossip.SeedProvider) (gossip.GossipState, error) {
+	type gossiper interface {
+		gossip.GossipState
+		Start() error
+	}
+
+	var g gossiper
+	switch protocol {
+	case "mesh":
+		meshGossiper, err := mesh.NewMeshGossiper(listen, channelName, nodeName, secret, seeds)
+		if err != nil {
+			return nil, err
+		}
+		g = meshGossiper
+	case httpsync.ProtocolName:
+		httpGossiper, err := httpsync.NewHTTPGossiper(listen, secret, seeds)
+		if err != nil {
+			return nil, err
+		}
+		g = httpGossiper
+	default:
+		return nil, fmt.Errorf("unknown gossip protocol %q", protocol)
+	}
+
+	go func() {
+		err := g.Start()
+		if err != nil {
+			glog.Fatalf("%s gossip exited unexpectedly: %v", protocol, err)
+		} else {
+			glog.Fatalf("%s gossip exited unexpectedly, but without error", protocol)
+		}
+	}()
+
+	return g, nil
+}
+
 // findInternalIP attempts to discover the internal IP address by inspecting the network interfaces
 func findInternalIP() (net.IP, error) {
 	var ips []net.IP
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package do
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/digitalocean/godo"
+	"github.com/golang/glog"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+type SeedProvider struct {
+	droplets godo.DropletsService
+	tag      string
+}
+
+var _ gossip.SeedProvider = &SeedProvider{}
+
+func (p *SeedProvider) GetSeeds() ([]string, error) {
+	var seeds []string
+
+	opt := &godo.ListOptions{}
+	for {
+		droplets, resp, err := p.droplets.ListByTag(context.TODO(), p.tag, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error querying for droplets with tag %q: %v", p.tag, err)
+		}
+
+		for i := range droplets {
+			ip, err := droplets[i].PrivateIPv4()
+			if err != nil {
+				// The droplet may still be starting
+				glog.Warningf("ignoring droplet %q: error getting private IP: %v", droplets[i].Name, err)
+				continue
+			}
+			if ip != "" {
+				seeds = append(seeds, ip)
+			}
+		}
+
+		if resp.Links == nil || resp.Links.IsLastPage() {
+			break
+		}
+
+		page, err := resp.Links.CurrentPage()
+		if err != nil {
+			return nil, err
+		}
+		opt.Page = page + 1
+	}
+
+	return seeds, nil
+}
+
+func NewSeedProvider(droplets godo.DropletsService, tag string) (*SeedProvider, error) {
+	return &SeedProvider{
+		droplets: droplets,
+		tag:      tag,
+	}, nil
+}
@@ -29,6 +29,8 @@ type SeedProvider struct {
 	compute   *compute.Service
 	projectID string
 	region    string
+	// tagPrefix restricts seeds to instances with a network tag starting with this prefix, identifying the cluster
+	tagPrefix string
 }
 
 var _ gossip.SeedProvider = &SeedProvider{}
@@ -77,6 +79,10 @@ func (p *SeedProvider) GetSeeds() ([]string, error) {
 			}
 			pageToken = res.NextPageToken
 			for _, i := range res.Items {
+				if p.tagPrefix != "" && !hasTagWithPrefix(i, p.tagPrefix) {
+					continue
+				}
+
 				// TODO: Expose multiple IPs topologies?
 
 				for _, ni := range i.NetworkInterfaces {
@@ -96,14 +102,28 @@ func (p *SeedProvider) GetSeeds() ([]string, error) {
 	return seeds, nil
 }
 
-func NewSeedProvider(compute *compute.Service, region string, projectID string) (*SeedProvider, error) {
+func NewSeedProvider(compute *compute.Service, region string, projectID string, tagPrefix string) (*SeedProvider, error) {
 	return &SeedProvider{
 		compute:   compute,
 		region:    region,
 		projectID: projectID,
+		tagPrefix: tagPrefix,
 	}, nil
 }
 
+// hasTagWithPrefix returns true if the instance has a network tag starting with the prefix
+func hasTagWithPrefix(i *compute.Instance, prefix string) bool {
+	if i.Tags == nil {
+		return false
+	}
+	for _, tag := range i.Tags.Items {
+		if strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Returns the last component of a URL, i.e. anything after the last slash
 // If there is no slash, returns the whole string
 func lastComponent(s string) string {
@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+// SeedProvider finds the private addresses of the servers of the cluster
+type SeedProvider struct {
+	client      *hetzner.Client
+	clusterName string
+}
+
+var _ gossip.SeedProvider = &SeedProvider{}
+
+func (p *SeedProvider) GetSeeds() ([]string, error) {
+	selector := hetzner.LabelSelector(map[string]string{hetzner.LabelCluster: p.clusterName})
+	servers, err := p.client.ListServers(selector)
+	if err != nil {
+		return nil, fmt.Errorf("error querying for servers with label %q: %v", selector, err)
+	}
+
+	var seeds []string
+	for _, server := range servers {
+		if len(server.PrivateNet) == 0 {
+			// The server may still be starting
+			glog.Warningf("ignoring server %q: not attached to a private network", server.Name)
+			continue
+		}
+		seeds = append(seeds, server.PrivateNet[0].IP)
+	}
+	return seeds, nil
+}
+
+func NewSeedProvider(client *hetzner.Client, clusterName string) (*SeedProvider, error) {
+	return &SeedProvider{
+		client:      client,
+		clusterName: clusterName,
+	}, nil
+}
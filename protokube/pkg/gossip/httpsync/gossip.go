@@ -0,0 +1,337 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpsync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+const (
+	// ProtocolName is the name of this gossip protocol
+	ProtocolName = "http"
+
+	// statePath is the HTTP path at which we serve our state to peers
+	statePath = "/gossip/state"
+
+	// signatureHeader carries the HMAC-SHA256 of the response body, keyed by the gossip secret
+	signatureHeader = "X-Gossip-Signature"
+
+	// syncInterval is how often we pull state from peers
+	syncInterval = 10 * time.Second
+	// seedInterval is how often we refresh the list of seeds from the cloud
+	seedInterval = 5 * time.Minute
+	// fanout is the number of peers we pull from on each sync
+	fanout = 3
+	// tombstoneTTL is how long we remember removed keys, so that they are not resurrected by peers
+	tombstoneTTL = time.Hour
+)
+
+// record is a single value, versioned so that peers converge on the most recent write
+type record struct {
+	Data      string `json:"data,omitempty"`
+	Version   int64  `json:"version"`
+	Tombstone bool   `json:"tombstone,omitempty"`
+}
+
+// HTTPGossiper is a simple anti-entropy gossip protocol: each node periodically pulls the complete state
+// from a few random peers over HTTP, keeping the most recent version of each value.  It is less efficient
+// than mesh, but has no persistent connections, so it is useful as a secondary protocol.
+type HTTPGossiper struct {
+	listen string
+	port   string
+	secret []byte
+	seeds  gossip.SeedProvider
+	client *http.Client
+
+	mutex   sync.Mutex
+	records map[string]*record
+	version uint64
+	// lastSync records when we last successfully pulled from each peer
+	lastSync map[string]time.Time
+}
+
+var _ gossip.GossipState = &HTTPGossiper{}
+var _ gossip.StatusReporter = &HTTPGossiper{}
+var _ http.Handler = &HTTPGossiper{}
+
+// NewHTTPGossiper builds an HTTPGossiper, which will listen on the listen address (address:port).
+// Peers are assumed to listen on the same port.
+func NewHTTPGossiper(listen string, secret []byte, seeds gossip.SeedProvider) (*HTTPGossiper, error) {
+	_, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse listen address %q: %v", listen, err)
+	}
+
+	return &HTTPGossiper{
+		listen:   listen,
+		port:     port,
+		secret:   secret,
+		seeds:    seeds,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		records:  make(map[string]*record),
+		lastSync: make(map[string]time.Time),
+	}, nil
+}
+
+// Start serves our state to peers and synchronizes with them; it only returns on error
+func (g *HTTPGossiper) Start() error {
+	mux := http.NewServeMux()
+	mux.Handle(statePath, g)
+
+	errors := make(chan error, 1)
+	go func() {
+		glog.Infof("http gossip listening on %s", g.listen)
+		errors <- http.ListenAndServe(g.listen, mux)
+	}()
+
+	var seeds []string
+	var seedsFetched time.Time
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-errors:
+			return fmt.Errorf("error serving http gossip: %v", err)
+		case <-ticker.C:
+		}
+
+		if len(seeds) == 0 || time.Since(seedsFetched) > seedInterval {
+			glog.V(2).Infof("Querying for seeds")
+			s, err := g.seeds.GetSeeds()
+			if err != nil {
+				glog.Warningf("error getting seeds: %v", err)
+			} else {
+				seeds = s
+				seedsFetched = time.Now()
+			}
+		}
+
+		for _, i := range rand.Perm(len(seeds)) {
+			if i >= fanout {
+				continue
+			}
+			address := peerAddress(seeds[i], g.port)
+			if err := g.pull(address); err != nil {
+				glog.V(2).Infof("error synchronizing with %s: %v", address, err)
+			}
+		}
+
+		g.expireTombstones()
+	}
+}
+
+// peerAddress adds our port to a seed, unless it already includes one
+func peerAddress(seed string, port string) string {
+	if _, _, err := net.SplitHostPort(seed); err == nil {
+		return seed
+	}
+	return net.JoinHostPort(seed, port)
+}
+
+// ServeHTTP serves our complete state to a peer
+func (g *HTTPGossiper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	g.mutex.Lock()
+	data, err := json.Marshal(g.records)
+	g.mutex.Unlock()
+	if err != nil {
+		glog.Warningf("error serializing gossip state: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(signatureHeader, g.sign(data))
+	w.Write(data)
+}
+
+func (g *HTTPGossiper) sign(data []byte) string {
+	return hex.EncodeToString(g.mac(data))
+}
+
+func (g *HTTPGossiper) mac(data []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// pull fetches the state from the peer at address (host:port), and merges it into our state
+func (g *HTTPGossiper) pull(address string) error {
+	response, err := g.client.Get("http://" + address + statePath)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %q", response.Status)
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %v", err)
+	}
+
+	signature, err := hex.DecodeString(response.Header.Get(signatureHeader))
+	if err != nil || !hmac.Equal(signature, g.mac(data)) {
+		return fmt.Errorf("response signature did not match; is the gossip secret the same on all nodes?")
+	}
+
+	remote := make(map[string]*record)
+	if err := json.Unmarshal(data, &remote); err != nil {
+		return fmt.Errorf("error parsing state: %v", err)
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.lastSync[address] = time.Now()
+	if g.merge(remote) {
+		g.version++
+	}
+	return nil
+}
+
+// merge keeps the most recent version of each record; it returns true if our state changed.  Must be called with the mutex held.
+func (g *HTTPGossiper) merge(remote map[string]*record) bool {
+	changed := false
+	for k, r := range remote {
+		if r == nil {
+			continue
+		}
+		if local := g.records[k]; local != nil && !supersedes(r, local) {
+			continue
+		}
+		g.records[k] = &record{Data: r.Data, Version: r.Version, Tombstone: r.Tombstone}
+		changed = true
+	}
+	return changed
+}
+
+// supersedes returns true if record a should replace record b.
+// The most recent version wins; ties are broken deterministically so that all nodes converge.
+func supersedes(a *record, b *record) bool {
+	if a.Version != b.Version {
+		return a.Version > b.Version
+	}
+	if a.Tombstone != b.Tombstone {
+		return a.Tombstone
+	}
+	return a.Data > b.Data
+}
+
+// nextVersion returns a version newer than both the clock and the existing record.  Must be called with the mutex held.
+func (g *HTTPGossiper) nextVersion(existing *record) int64 {
+	v := time.Now().UnixNano()
+	if existing != nil && v <= existing.Version {
+		v = existing.Version + 1
+	}
+	return v
+}
+
+// expireTombstones forgets removed keys once every peer should have seen the removal
+func (g *HTTPGossiper) expireTombstones() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	cutoff := time.Now().Add(-tombstoneTTL).UnixNano()
+	for k, r := range g.records {
+		if r.Tombstone && r.Version < cutoff {
+			delete(g.records, k)
+		}
+	}
+}
+
+func (g *HTTPGossiper) Snapshot() *gossip.GossipStateSnapshot {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	values := make(map[string]string)
+	for k, r := range g.records {
+		if !r.Tombstone {
+			values[k] = r.Data
+		}
+	}
+	return &gossip.GossipStateSnapshot{
+		Values:  values,
+		Version: g.version,
+	}
+}
+
+func (g *HTTPGossiper) UpdateValues(removeKeys []string, putEntries map[string]string) error {
+	glog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putEntries)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	changed := false
+	for _, k := range removeKeys {
+		existing := g.records[k]
+		if existing == nil || existing.Tombstone {
+			continue
+		}
+		g.records[k] = &record{Version: g.nextVersion(existing), Tombstone: true}
+		changed = true
+	}
+	for k, v := range putEntries {
+		existing := g.records[k]
+		if existing != nil && !existing.Tombstone && existing.Data == v {
+			continue
+		}
+		g.records[k] = &record{Data: v, Version: g.nextVersion(existing)}
+		changed = true
+	}
+
+	if changed {
+		g.version++
+	}
+	return nil
+}
+
+// Status reports the peers we have synchronized with recently
+func (g *HTTPGossiper) Status() []gossip.ProtocolStatus {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	status := gossip.ProtocolStatus{
+		Protocol: ProtocolName,
+		Listen:   g.listen,
+	}
+	for address, t := range g.lastSync {
+		if time.Since(t) < 3*syncInterval {
+			status.Peers = append(status.Peers, address)
+		}
+	}
+	return []gossip.ProtocolStatus{status}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpsync
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+func newTestGossiper(t *testing.T, secret string) (*HTTPGossiper, *httptest.Server) {
+	g, err := NewHTTPGossiper("127.0.0.1:0", []byte(secret), gossip.NewStaticSeedProvider(nil))
+	if err != nil {
+		t.Fatalf("error building gossiper: %v", err)
+	}
+	return g, httptest.NewServer(g)
+}
+
+func serverAddress(s *httptest.Server) string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func TestHTTPGossiper_Sync(t *testing.T) {
+	a, serverA := newTestGossiper(t, "secret")
+	defer serverA.Close()
+	b, serverB := newTestGossiper(t, "secret")
+	defer serverB.Close()
+
+	if err := a.UpdateValues(nil, map[string]string{"api.internal": "10.0.0.1", "etcd-a": "10.0.0.2"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	if err := b.pull(serverAddress(serverA)); err != nil {
+		t.Fatalf("error pulling state: %v", err)
+	}
+	expected := map[string]string{"api.internal": "10.0.0.1", "etcd-a": "10.0.0.2"}
+	if actual := b.Snapshot().Values; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected values after sync: %v", actual)
+	}
+
+	// A newer write on b wins over the older value from a, and removals propagate
+	version := b.Snapshot().Version
+	if err := b.UpdateValues([]string{"etcd-a"}, map[string]string{"api.internal": "10.0.0.3"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	if b.Snapshot().Version == version {
+		t.Fatalf("expected version to change after update")
+	}
+	if err := b.pull(serverAddress(serverA)); err != nil {
+		t.Fatalf("error pulling state: %v", err)
+	}
+	if err := a.pull(serverAddress(serverB)); err != nil {
+		t.Fatalf("error pulling state: %v", err)
+	}
+	expected = map[string]string{"api.internal": "10.0.0.3"}
+	for _, g := range []*HTTPGossiper{a, b} {
+		if actual := g.Snapshot().Values; !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("unexpected values after removal: %v", actual)
+		}
+	}
+	if gossip.HashValues(a.Snapshot().Values) != gossip.HashValues(b.Snapshot().Values) {
+		t.Fatalf("expected gossipers to have converged")
+	}
+
+	if peers := a.Status()[0].Peers; len(peers) != 1 || peers[0] != serverAddress(serverB) {
+		t.Fatalf("unexpected peers %v", peers)
+	}
+}
+
+func TestHTTPGossiper_RejectsWrongSecret(t *testing.T) {
+	a, serverA := newTestGossiper(t, "secret")
+	defer serverA.Close()
+	b, serverB := newTestGossiper(t, "other")
+	defer serverB.Close()
+
+	a.UpdateValues(nil, map[string]string{"api.internal": "10.0.0.1"})
+	if err := b.pull(serverAddress(serverA)); err == nil {
+		t.Fatalf("expected error pulling state with the wrong secret")
+	}
+	if values := b.Snapshot().Values; len(values) != 0 {
+		t.Fatalf("expected no values to be merged, got %v", values)
+	}
+}
+
+func TestPeerAddress(t *testing.T) {
+	grid := map[string]string{
+		"10.0.0.1":      "10.0.0.1:3993",
+		"10.0.0.1:4000": "10.0.0.1:4000",
+		"fd00::1":       "[fd00::1]:3993",
+	}
+	for seed, expected := range grid {
+		if actual := peerAddress(seed, "3993"); actual != expected {
+			t.Errorf("peerAddress(%q) = %q, expected %q", seed, actual, expected)
+		}
+	}
+}
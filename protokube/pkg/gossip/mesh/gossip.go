@@ -29,7 +29,8 @@ import (
 )
 
 type MeshGossiper struct {
-	seeds gossip.SeedProvider
+	seeds  gossip.SeedProvider
+	listen string
 
 	router *mesh.Router
 	peer   *peer
@@ -91,6 +92,7 @@ func NewMeshGossiper(listen string, channelName string, nodeName string, passwor
 
 	gossiper := &MeshGossiper{
 		seeds:  seeds,
+		listen: listen,
 		router: router,
 		peer:   peer,
 	}
@@ -153,3 +155,19 @@ func (g *MeshGossiper) UpdateValues(removeKeys []string, putEntries map[string]s
 	glog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putEntries)
 	return g.peer.updateValues(removeKeys, putEntries)
 }
+
+var _ gossip.StatusReporter = &MeshGossiper{}
+
+// Status reports the peers to which we have established mesh connections
+func (g *MeshGossiper) Status() []gossip.ProtocolStatus {
+	status := gossip.ProtocolStatus{
+		Protocol: "mesh",
+		Listen:   g.listen,
+	}
+	for _, c := range mesh.NewStatus(g.router).Connections {
+		if c.State == "established" {
+			status.Peers = append(status.Peers, c.Address)
+		}
+	}
+	return []gossip.ProtocolStatus{status}
+}
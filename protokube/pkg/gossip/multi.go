@@ -0,0 +1,87 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+)
+
+// MultiGossiper runs a secondary gossip protocol alongside the primary, so that DNS keeps working if one of them fails.
+// Values are written to both protocols; reads merge the two, preferring the primary.
+type MultiGossiper struct {
+	primary   GossipState
+	secondary GossipState
+}
+
+var _ GossipState = &MultiGossiper{}
+
+// NewMultiGossiper builds a MultiGossiper over the two protocols
+func NewMultiGossiper(primary GossipState, secondary GossipState) *MultiGossiper {
+	return &MultiGossiper{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+func (m *MultiGossiper) Snapshot() *GossipStateSnapshot {
+	primary := m.primary.Snapshot()
+	secondary := m.secondary.Snapshot()
+
+	values := make(map[string]string)
+	for k, v := range secondary.Values {
+		values[k] = v
+	}
+	for k, v := range primary.Values {
+		values[k] = v
+	}
+
+	return &GossipStateSnapshot{
+		Values: values,
+		// The version is used to detect changes, so it must change when either protocol changes
+		Version: primary.Version + secondary.Version,
+	}
+}
+
+func (m *MultiGossiper) UpdateValues(removeKeys []string, putEntries map[string]string) error {
+	primaryErr := m.primary.UpdateValues(removeKeys, putEntries)
+	if primaryErr != nil {
+		glog.Warningf("error updating values in primary gossip protocol: %v", primaryErr)
+	}
+	secondaryErr := m.secondary.UpdateValues(removeKeys, putEntries)
+	if secondaryErr != nil {
+		glog.Warningf("error updating values in secondary gossip protocol: %v", secondaryErr)
+	}
+
+	// We only fail if both protocols failed; otherwise the values will still propagate
+	if primaryErr != nil && secondaryErr != nil {
+		return fmt.Errorf("error updating gossip values: %v", primaryErr)
+	}
+	return nil
+}
+
+// Status reports the status of both protocols
+func (m *MultiGossiper) Status() []ProtocolStatus {
+	var statuses []ProtocolStatus
+	for _, g := range []GossipState{m.primary, m.secondary} {
+		if reporter, ok := g.(StatusReporter); ok {
+			statuses = append(statuses, reporter.Status()...)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeGossipState struct {
+	values  map[string]string
+	version uint64
+}
+
+func (f *fakeGossipState) Snapshot() *GossipStateSnapshot {
+	return &GossipStateSnapshot{Values: f.values, Version: f.version}
+}
+
+func (f *fakeGossipState) UpdateValues(removeKeys []string, putKeys map[string]string) error {
+	for _, k := range removeKeys {
+		delete(f.values, k)
+	}
+	for k, v := range putKeys {
+		f.values[k] = v
+	}
+	f.version++
+	return nil
+}
+
+func TestMultiGossiper(t *testing.T) {
+	primary := &fakeGossipState{values: map[string]string{"a": "1", "b": "2"}}
+	secondary := &fakeGossipState{values: map[string]string{"b": "stale", "c": "3"}}
+	m := NewMultiGossiper(primary, secondary)
+
+	snapshot := m.Snapshot()
+	expected := map[string]string{"a": "1", "b": "2", "c": "3"}
+	if !reflect.DeepEqual(snapshot.Values, expected) {
+		t.Fatalf("unexpected merged values %v", snapshot.Values)
+	}
+
+	if err := m.UpdateValues([]string{"c"}, map[string]string{"d": "4"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	if primary.values["d"] != "4" || secondary.values["d"] != "4" {
+		t.Fatalf("expected update to be written to both protocols")
+	}
+	if _, found := secondary.values["c"]; found {
+		t.Fatalf("expected removal to be written to both protocols")
+	}
+	if m.Snapshot().Version == snapshot.Version {
+		t.Fatalf("expected version to change after update")
+	}
+}
+
+func TestHashValues(t *testing.T) {
+	a := HashValues(map[string]string{"a": "1", "b": "2"})
+	b := HashValues(map[string]string{"b": "2", "a": "1"})
+	if a != b {
+		t.Fatalf("expected hash to be independent of ordering")
+	}
+	if a == HashValues(map[string]string{"a": "1", "b": "3"}) {
+		t.Fatalf("expected hash to change when values change")
+	}
+}
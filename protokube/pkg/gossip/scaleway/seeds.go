@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"fmt"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+// SeedProvider finds the addresses IPAM assigned to the servers on the cluster's private network
+type SeedProvider struct {
+	client      *scaleway.Client
+	zone        string
+	clusterName string
+}
+
+var _ gossip.SeedProvider = &SeedProvider{}
+
+func (p *SeedProvider) GetSeeds() ([]string, error) {
+	tags := []string{scaleway.Tag(scaleway.TagCluster, p.clusterName)}
+	networks, err := p.client.ListPrivateNetworks(p.zone, tags)
+	if err != nil {
+		return nil, fmt.Errorf("error querying for private networks with tags %q: %v", tags, err)
+	}
+
+	var seeds []string
+	for _, network := range networks {
+		ips, err := p.client.ListPrivateNetworkIPs(scaleway.RegionForZone(p.zone), network.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error listing addresses in private network %q: %v", network.Name, err)
+		}
+		for _, ip := range ips {
+			seeds = append(seeds, ip.Address)
+		}
+	}
+	return seeds, nil
+}
+
+func NewSeedProvider(client *scaleway.Client, zone string, clusterName string) (*SeedProvider, error) {
+	return &SeedProvider{
+		client:      client,
+		zone:        zone,
+		clusterName: clusterName,
+	}, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/golang/glog"
+)
+
+// DefaultStatusListen is the default address on which protokube serves the gossip status
+const DefaultStatusListen = "0.0.0.0:3992"
+
+// StatusPath is the HTTP path at which the gossip status is served
+const StatusPath = "/gossip/status"
+
+// ProtocolStatus is the status of a single gossip protocol on a node
+type ProtocolStatus struct {
+	// Protocol is the name of the gossip protocol, e.g. mesh
+	Protocol string `json:"protocol"`
+	// Listen is the address on which the protocol is listening
+	Listen string `json:"listen"`
+	// Peers are the peers to which we are connected (or have recently synchronized with)
+	Peers []string `json:"peers,omitempty"`
+}
+
+// NodeStatus is the gossip status of a node, as served by ServeStatus
+type NodeStatus struct {
+	// Name is the name of the node in gossip
+	Name string `json:"name"`
+	// Protocols is the status of each gossip protocol
+	Protocols []ProtocolStatus `json:"protocols"`
+	// Version is the version of the gossip state
+	Version uint64 `json:"version"`
+	// Records is the number of values in the gossip state
+	Records int `json:"records"`
+	// RecordsHash is a hash of the values in the gossip state; nodes that have converged have the same hash
+	RecordsHash string `json:"recordsHash"`
+}
+
+// StatusReporter is implemented by gossip protocols that can report their status
+type StatusReporter interface {
+	Status() []ProtocolStatus
+}
+
+// BuildNodeStatus computes the status of the gossip state
+func BuildNodeStatus(name string, state GossipState) *NodeStatus {
+	snapshot := state.Snapshot()
+
+	status := &NodeStatus{
+		Name:        name,
+		Version:     snapshot.Version,
+		Records:     len(snapshot.Values),
+		RecordsHash: HashValues(snapshot.Values),
+	}
+	if reporter, ok := state.(StatusReporter); ok {
+		status.Protocols = reporter.Status()
+	}
+	return status
+}
+
+// HashValues computes a hash of the values that is independent of ordering
+func HashValues(values map[string]string) string {
+	var keys []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(values[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ServeStatus serves the gossip status as JSON on the listen address; it only returns on error
+func ServeStatus(listen string, name string, state GossipState) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(BuildNodeStatus(name, state))
+		if err != nil {
+			glog.Warningf("error serializing gossip status: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+
+	glog.Infof("serving gossip status on %s", listen)
+	return http.ListenAndServe(listen, mux)
+}
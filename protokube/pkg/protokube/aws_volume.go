@@ -49,6 +49,7 @@ type AWSVolumes struct {
 	instanceId string
 	internalIP net.IP
 	metadata   *ec2metadata.EC2Metadata
+	role       string
 	zone       string
 }
 
@@ -58,10 +59,12 @@ var _ Volumes = &AWSVolumes{}
 func NewAWSVolumes() (*AWSVolumes, error) {
 	a := &AWSVolumes{
 		deviceMap: make(map[string]string),
+		role:      awsup.TagRoleMaster,
 	}
 
 	config := aws.NewConfig()
 	config = config.WithCredentialsChainVerboseErrors(true)
+	config = config.WithEndpointResolver(awsup.EndpointResolver())
 
 	s, err := session.NewSession(config)
 	if err != nil {
@@ -273,13 +276,18 @@ func (a *AWSVolumes) FindVolumes() ([]*Volume, error) {
 	request := &ec2.DescribeVolumesInput{}
 	request.Filters = []*ec2.Filter{
 		newEc2Filter("tag:"+awsup.TagClusterName, a.clusterTag),
-		newEc2Filter("tag-key", awsup.TagNameRolePrefix+awsup.TagRoleMaster),
+		newEc2Filter("tag-key", awsup.TagNameRolePrefix+a.role),
 		newEc2Filter("availability-zone", a.zone),
 	}
 
 	return a.findVolumes(request)
 }
 
+// SetRole sets the role tag of the volumes we mount: master, or etcd on dedicated etcd instances
+func (a *AWSVolumes) SetRole(role string) {
+	a.role = role
+}
+
 // FindMountedVolume implements Volumes::FindMountedVolume
 func (v *AWSVolumes) FindMountedVolume(volume *Volume) (string, error) {
 	device := volume.LocalDevice
@@ -31,6 +31,8 @@ import (
 
 	"k8s.io/kops/pkg/resources/digitalocean"
 	"k8s.io/kops/protokube/pkg/etcd"
+	"k8s.io/kops/protokube/pkg/gossip"
+	gossipdo "k8s.io/kops/protokube/pkg/gossip/do"
 )
 
 const (
@@ -248,6 +250,15 @@ func GetDropletInternalIP() (net.IP, error) {
 	return net.ParseIP(addr), nil
 }
 
+func (d *DOVolumes) GossipSeeds() (gossip.SeedProvider, error) {
+	clusterTag := "KubernetesCluster:" + strings.Replace(d.ClusterID, ".", "-", -1)
+	return gossipdo.NewSeedProvider(d.Cloud.Droplets(), clusterTag)
+}
+
+func (d *DOVolumes) InstanceName() string {
+	return d.dropletName
+}
+
 func getMetadataRegion() (string, error) {
 	return getMetadata(dropletRegionMetadataURL)
 }
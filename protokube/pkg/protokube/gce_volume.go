@@ -365,7 +365,9 @@ func (v *GCEVolumes) AttachVolume(volume *Volume) error {
 }
 
 func (g *GCEVolumes) GossipSeeds() (gossip.SeedProvider, error) {
-	return gossipgce.NewSeedProvider(g.compute, g.region, g.project)
+	// Instances are tagged with <cluster>-k8s-io-role-<role>; match any role so that nodes are seeds too
+	tagPrefix := gce.SafeClusterName(g.clusterName) + "-" + gce.GceLabelNameRolePrefix
+	return gossipgce.NewSeedProvider(g.compute, g.region, g.project, tagPrefix)
 }
 
 func (g *GCEVolumes) InstanceName() string {
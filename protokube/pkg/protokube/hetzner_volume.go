@@ -0,0 +1,214 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protokube
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/protokube/pkg/etcd"
+	"k8s.io/kops/protokube/pkg/gossip"
+	gossiphetzner "k8s.io/kops/protokube/pkg/gossip/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+const (
+	hetznerServerIDMetadataURL        = "http://169.254.169.254/hetzner/v1/metadata/instance-id"
+	hetznerServerNameMetadataURL      = "http://169.254.169.254/hetzner/v1/metadata/hostname"
+	hetznerPrivateNetworksMetadataURL = "http://169.254.169.254/hetzner/v1/metadata/private-networks"
+)
+
+// HetznerVolumes implements Volumes for servers in Hetzner Cloud
+type HetznerVolumes struct {
+	ClusterID string
+	Client    *hetzner.Client
+
+	serverID   int
+	serverName string
+}
+
+var _ Volumes = &HetznerVolumes{}
+
+func NewHetznerVolumes(clusterID string) (*HetznerVolumes, error) {
+	serverID, err := getMetadata(hetznerServerIDMetadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server id: %v", err)
+	}
+
+	serverIDInt, err := strconv.Atoi(strings.TrimSpace(serverID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert server id %q to int: %v", serverID, err)
+	}
+
+	serverName, err := getMetadata(hetznerServerNameMetadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server name: %v", err)
+	}
+
+	client, err := hetzner.NewClientFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hetzner client: %v", err)
+	}
+
+	return &HetznerVolumes{
+		ClusterID:  clusterID,
+		Client:     client,
+		serverID:   serverIDInt,
+		serverName: strings.TrimSpace(serverName),
+	}, nil
+}
+
+func (h *HetznerVolumes) AttachVolume(volume *Volume) error {
+	volumeID, err := strconv.Atoi(volume.ID)
+	if err != nil {
+		return fmt.Errorf("invalid hetzner volume id %q: %v", volume.ID, err)
+	}
+
+	if err := h.Client.AttachVolume(volumeID, h.serverID); err != nil {
+		return fmt.Errorf("error attaching volume %q: %v", volume.ID, err)
+	}
+
+	for {
+		v, err := h.Client.GetVolume(volumeID)
+		if err != nil {
+			return fmt.Errorf("error getting volume status: %v", err)
+		}
+
+		if v.Server != nil {
+			if *v.Server != h.serverID {
+				return fmt.Errorf("hetzner volume %q is attached to another server", volume.ID)
+			}
+
+			volume.AttachedTo = strconv.Itoa(h.serverID)
+			volume.LocalDevice = v.LinuxDevice
+			return nil
+		}
+
+		glog.V(2).Infof("waiting for volume %q to be attached", volume.ID)
+		time.Sleep(10 * time.Second)
+	}
+}
+
+func (h *HetznerVolumes) FindVolumes() ([]*Volume, error) {
+	selector := hetzner.LabelSelector(map[string]string{
+		hetzner.LabelCluster:      h.ClusterID,
+		hetzner.LabelInstanceRole: "master",
+	})
+	hetznerVolumes, err := h.Client.ListVolumes(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %v", err)
+	}
+
+	// The members of an etcd cluster are the volumes sharing its label
+	members := make(map[string][]string)
+	for _, v := range hetznerVolumes {
+		etcdCluster := v.Labels[hetzner.LabelEtcdCluster]
+		etcdMember := v.Labels[hetzner.LabelEtcdMember]
+		if etcdCluster == "" || etcdMember == "" {
+			continue
+		}
+		members[etcdCluster] = append(members[etcdCluster], etcdMember)
+	}
+
+	var volumes []*Volume
+	for _, v := range hetznerVolumes {
+		etcdCluster := v.Labels[hetzner.LabelEtcdCluster]
+		etcdMember := v.Labels[hetzner.LabelEtcdMember]
+		if etcdCluster == "" || etcdMember == "" {
+			glog.Warningf("ignoring volume %q: missing etcd labels", v.Name)
+			continue
+		}
+
+		vol := &Volume{
+			ID: strconv.Itoa(v.ID),
+			Info: VolumeInfo{
+				Description: v.Name,
+			},
+		}
+
+		if v.Server != nil {
+			vol.AttachedTo = strconv.Itoa(*v.Server)
+			if *v.Server == h.serverID {
+				vol.LocalDevice = v.LinuxDevice
+			}
+		}
+
+		vol.Info.EtcdClusters = append(vol.Info.EtcdClusters, &etcd.EtcdClusterSpec{
+			ClusterKey: etcdCluster,
+			NodeName:   etcdMember,
+			NodeNames:  members[etcdCluster],
+		})
+		volumes = append(volumes, vol)
+	}
+
+	return volumes, nil
+}
+
+func (h *HetznerVolumes) FindMountedVolume(volume *Volume) (string, error) {
+	device := volume.LocalDevice
+	if device == "" {
+		return "", nil
+	}
+
+	_, err := os.Stat(pathFor(device))
+	if err == nil {
+		return device, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("error checking for device %q: %v", device, err)
+	}
+
+	return "", nil
+}
+
+func (h *HetznerVolumes) GossipSeeds() (gossip.SeedProvider, error) {
+	return gossiphetzner.NewSeedProvider(h.Client, h.ClusterID)
+}
+
+func (h *HetznerVolumes) InstanceName() string {
+	return h.serverName
+}
+
+// GetHetznerInternalIP gets the private network IP of the server running this program
+func GetHetznerInternalIP() (net.IP, error) {
+	networks, err := getMetadata(hetznerPrivateNetworksMetadataURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The metadata is a YAML list of networks; we only need the first address
+	for _, line := range strings.Split(networks, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-"))
+		if !strings.HasPrefix(line, "ip:") {
+			continue
+		}
+		ip := net.ParseIP(strings.TrimSpace(strings.TrimPrefix(line, "ip:")))
+		if ip == nil {
+			return nil, fmt.Errorf("unable to parse private network address from %q", line)
+		}
+		return ip, nil
+	}
+
+	return nil, fmt.Errorf("server is not attached to a private network")
+}
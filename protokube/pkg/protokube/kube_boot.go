@@ -53,6 +53,8 @@ type KubeBoot struct {
 	Kubernetes *KubernetesContext
 	// Master indicates we are a master node
 	Master bool
+	// Etcd indicates we are a dedicated etcd node, running etcd but none of the control plane
+	Etcd bool
 
 	// ManageEtcd is true if we should manage etcd.
 	// Deprecated in favor of etcd-manager.
@@ -104,7 +106,7 @@ func (k *KubeBoot) RunSyncLoop() {
 }
 
 func (k *KubeBoot) syncOnce() error {
-	if k.Master && k.ManageEtcd {
+	if (k.Master || k.Etcd) && k.ManageEtcd {
 		// attempt to mount the volumes
 		volumes, err := k.volumeMounter.mountMasterVolumes()
 		if err != nil {
@@ -128,7 +130,7 @@ func (k *KubeBoot) syncOnce() error {
 			}
 		}
 	} else if k.ManageEtcd {
-		glog.V(4).Infof("Not in role master; won't scan for volumes")
+		glog.V(4).Infof("Not in role master or etcd; won't scan for volumes")
 	} else {
 		glog.V(4).Infof("protokube management of etcd not enabled; won't scan for volumes")
 	}
@@ -0,0 +1,239 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protokube
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/protokube/pkg/etcd"
+	"k8s.io/kops/protokube/pkg/gossip"
+	gossipscaleway "k8s.io/kops/protokube/pkg/gossip/scaleway"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+const scalewayMetadataURL = "http://169.254.42.42/conf?format=json"
+
+// scalewayMetadata is the part of the instance metadata protokube uses
+type scalewayMetadata struct {
+	ID       string `json:"id"`
+	Hostname string `json:"hostname"`
+	Location struct {
+		ZoneID string `json:"zone_id"`
+	} `json:"location"`
+	PrivateNICs []struct {
+		PrivateNetworkID string `json:"private_network_id"`
+		MACAddress       string `json:"mac_address"`
+	} `json:"private_nics"`
+}
+
+func getScalewayMetadata() (*scalewayMetadata, error) {
+	body, err := getMetadata(scalewayMetadataURL)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &scalewayMetadata{}
+	if err := json.Unmarshal([]byte(body), metadata); err != nil {
+		return nil, fmt.Errorf("error parsing instance metadata: %v", err)
+	}
+	return metadata, nil
+}
+
+// ScalewayVolumes implements Volumes for servers in Scaleway
+type ScalewayVolumes struct {
+	ClusterID string
+	Client    *scaleway.Client
+
+	serverID   string
+	serverName string
+	zone       string
+}
+
+var _ Volumes = &ScalewayVolumes{}
+
+func NewScalewayVolumes(clusterID string) (*ScalewayVolumes, error) {
+	metadata, err := getScalewayMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance metadata: %v", err)
+	}
+
+	client, err := scaleway.NewClientFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize scaleway client: %v", err)
+	}
+
+	return &ScalewayVolumes{
+		ClusterID:  clusterID,
+		Client:     client,
+		serverID:   metadata.ID,
+		serverName: metadata.Hostname,
+		zone:       metadata.Location.ZoneID,
+	}, nil
+}
+
+// scalewayLocalDevice is the udev link for an attached block volume
+func scalewayLocalDevice(v *scaleway.Volume) string {
+	return "/dev/disk/by-id/scsi-0SCW_" + v.VolumeType + "_volume-" + v.ID
+}
+
+func (s *ScalewayVolumes) AttachVolume(volume *Volume) error {
+	if err := s.Client.AttachVolume(s.zone, s.serverID, volume.ID); err != nil {
+		return fmt.Errorf("error attaching volume %q: %v", volume.ID, err)
+	}
+
+	for {
+		v, err := s.Client.GetVolume(s.zone, volume.ID)
+		if err != nil {
+			return fmt.Errorf("error getting volume status: %v", err)
+		}
+
+		if v.Server != nil {
+			if v.Server.ID != s.serverID {
+				return fmt.Errorf("scaleway volume %q is attached to another server", volume.ID)
+			}
+
+			volume.AttachedTo = s.serverID
+			volume.LocalDevice = scalewayLocalDevice(v)
+			return nil
+		}
+
+		glog.V(2).Infof("waiting for volume %q to be attached", volume.ID)
+		time.Sleep(10 * time.Second)
+	}
+}
+
+func (s *ScalewayVolumes) FindVolumes() ([]*Volume, error) {
+	scalewayVolumes, err := s.Client.ListVolumes(s.zone, []string{
+		scaleway.Tag(scaleway.TagCluster, s.ClusterID),
+		scaleway.Tag(scaleway.TagInstanceRole, "master"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %v", err)
+	}
+
+	// The members of an etcd cluster are the volumes sharing its tag
+	members := make(map[string][]string)
+	for _, v := range scalewayVolumes {
+		etcdCluster := scaleway.TagValue(v.Tags, scaleway.TagEtcdCluster)
+		etcdMember := scaleway.TagValue(v.Tags, scaleway.TagEtcdMember)
+		if etcdCluster == "" || etcdMember == "" {
+			continue
+		}
+		members[etcdCluster] = append(members[etcdCluster], etcdMember)
+	}
+
+	var volumes []*Volume
+	for _, v := range scalewayVolumes {
+		etcdCluster := scaleway.TagValue(v.Tags, scaleway.TagEtcdCluster)
+		etcdMember := scaleway.TagValue(v.Tags, scaleway.TagEtcdMember)
+		if etcdCluster == "" || etcdMember == "" {
+			glog.Warningf("ignoring volume %q: missing etcd tags", v.Name)
+			continue
+		}
+
+		vol := &Volume{
+			ID: v.ID,
+			Info: VolumeInfo{
+				Description: v.Name,
+			},
+		}
+
+		if v.Server != nil {
+			vol.AttachedTo = v.Server.ID
+			if v.Server.ID == s.serverID {
+				vol.LocalDevice = scalewayLocalDevice(v)
+			}
+		}
+
+		vol.Info.EtcdClusters = append(vol.Info.EtcdClusters, &etcd.EtcdClusterSpec{
+			ClusterKey: etcdCluster,
+			NodeName:   etcdMember,
+			NodeNames:  members[etcdCluster],
+		})
+		volumes = append(volumes, vol)
+	}
+
+	return volumes, nil
+}
+
+func (s *ScalewayVolumes) FindMountedVolume(volume *Volume) (string, error) {
+	device := volume.LocalDevice
+	if device == "" {
+		return "", nil
+	}
+
+	_, err := os.Stat(pathFor(device))
+	if err == nil {
+		return device, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("error checking for device %q: %v", device, err)
+	}
+
+	return "", nil
+}
+
+func (s *ScalewayVolumes) GossipSeeds() (gossip.SeedProvider, error) {
+	return gossipscaleway.NewSeedProvider(s.Client, s.zone, s.ClusterID)
+}
+
+func (s *ScalewayVolumes) InstanceName() string {
+	return s.serverName
+}
+
+// GetScalewayInternalIP gets the private network IP of the server running this program
+func GetScalewayInternalIP() (net.IP, error) {
+	metadata, err := getScalewayMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata.PrivateNICs) == 0 {
+		return nil, fmt.Errorf("server is not attached to a private network")
+	}
+
+	// The metadata doesn't include the address IPAM assigned, so look for the interface with the NIC's MAC address
+	mac := strings.ToLower(metadata.PrivateNICs[0].MACAddress)
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("error listing network interfaces: %v", err)
+	}
+	for _, iface := range interfaces {
+		if strings.ToLower(iface.HardwareAddr.String()) != mac {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("error listing addresses of %q: %v", iface.Name, err)
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return ipNet.IP, nil
+			}
+		}
+		return nil, fmt.Errorf("private network interface %q has no IPv4 address", iface.Name)
+	}
+
+	return nil, fmt.Errorf("no interface found with MAC address %q", mac)
+}
@@ -245,6 +245,15 @@ var zonesToCloud = map[string]kops.CloudProviderID{
 
 	"blr1": kops.CloudProviderDO,
 
+	"fsn1": kops.CloudProviderHetzner,
+	"nbg1": kops.CloudProviderHetzner,
+	"hel1": kops.CloudProviderHetzner,
+
+	"fr-par-1": kops.CloudProviderScaleway,
+	"fr-par-2": kops.CloudProviderScaleway,
+	"nl-ams-1": kops.CloudProviderScaleway,
+	"pl-waw-1": kops.CloudProviderScaleway,
+
 	"cn-qingdao-b": kops.CloudProviderALI,
 	"cn-qingdao-c": kops.CloudProviderALI,
 
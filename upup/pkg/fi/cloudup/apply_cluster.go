@@ -18,6 +18,7 @@ package cloudup
 
 import (
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kopsbase "k8s.io/kops"
 	"k8s.io/kops/pkg/apis/kops"
+	apimodel "k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/kops/validation"
@@ -40,11 +42,14 @@ import (
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/pkg/model/alimodel"
 	"k8s.io/kops/pkg/model/awsmodel"
+	"k8s.io/kops/pkg/model/baremetalmodel"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/pkg/model/components/etcdmanager"
 	"k8s.io/kops/pkg/model/domodel"
 	"k8s.io/kops/pkg/model/gcemodel"
+	"k8s.io/kops/pkg/model/hetznermodel"
 	"k8s.io/kops/pkg/model/openstackmodel"
+	"k8s.io/kops/pkg/model/scalewaymodel"
 	"k8s.io/kops/pkg/model/vspheremodel"
 	"k8s.io/kops/pkg/resources/digitalocean"
 	"k8s.io/kops/pkg/templates"
@@ -55,13 +60,19 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/cloudup/baremetal"
+	"k8s.io/kops/upup/pkg/fi/cloudup/baremetaltasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	"k8s.io/kops/upup/pkg/fi/cloudup/do"
 	"k8s.io/kops/upup/pkg/fi/cloudup/dotasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetznertasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstacktasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scalewaytasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
 	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
 	"k8s.io/kops/upup/pkg/fi/cloudup/vspheretasks"
@@ -80,6 +91,10 @@ var (
 	AlphaAllowDO = featureflag.New("AlphaAllowDO", featureflag.Bool(false))
 	// AlphaAllowGCE is a feature flag that gates GCE support while it is alpha
 	AlphaAllowGCE = featureflag.New("AlphaAllowGCE", featureflag.Bool(false))
+	// AlphaAllowHetzner is a feature flag that gates Hetzner Cloud support while it is alpha
+	AlphaAllowHetzner = featureflag.New("AlphaAllowHetzner", featureflag.Bool(false))
+	// AlphaAllowScaleway is a feature flag that gates Scaleway support while it is alpha
+	AlphaAllowScaleway = featureflag.New("AlphaAllowScaleway", featureflag.Bool(false))
 	// AlphaAllowVsphere is a feature flag that gates vsphere support while it is alpha
 	AlphaAllowVsphere = featureflag.New("AlphaAllowVsphere", featureflag.Bool(false))
 	// AlphaAllowALI is a feature flag that gates aliyun support while it is alpha
@@ -108,6 +123,12 @@ type ApplyClusterCmd struct {
 	// Target is the fi.Target we will operate against
 	Target fi.Target
 
+	// DryRunOut is where the report of a dry-run is printed; defaults to Out
+	DryRunOut io.Writer
+
+	// Out is where messages for the user, such as recommended upgrades, are printed; defaults to stdout
+	Out io.Writer
+
 	// OutDir is a local directory in which we place output, can cache files etc
 	OutDir string
 
@@ -138,6 +159,9 @@ type ApplyClusterCmd struct {
 
 	// TaskMap is the map of tasks that we built (output)
 	TaskMap map[string]fi.Task
+
+	// sshAuthorizedKeys are the SSH public keys nodeup should authorize for the admin user
+	sshAuthorizedKeys []string
 }
 
 func (c *ApplyClusterCmd) Run() error {
@@ -317,6 +341,12 @@ func (c *ApplyClusterCmd) Run() error {
 		}
 	}
 
+	// additionalSSHPublicKeys are the keys of the other SSH credentials, authorized alongside the primary key
+	additionalSSHPublicKeys, err := findAdditionalSSHPublicKeys(sshCredentialStore)
+	if err != nil {
+		return err
+	}
+
 	modelContext := &model.KopsModelContext{
 		Cluster:        cluster,
 		InstanceGroups: c.InstanceGroups,
@@ -333,7 +363,8 @@ func (c *ApplyClusterCmd) Run() error {
 				return fmt.Errorf("GCE support is currently alpha, and is feature-gated.  export KOPS_FEATURE_FLAGS=AlphaAllowGCE")
 			}
 
-			modelContext.SSHPublicKeys = sshPublicKeys
+			// GCE metadata holds any number of keys, so the additional keys are installed with the primary key
+			modelContext.SSHPublicKeys = append(sshPublicKeys, additionalSSHPublicKeys...)
 
 			l.AddTypes(map[string]interface{}{
 				"Disk":                 &gcetasks.Disk{},
@@ -359,6 +390,47 @@ func (c *ApplyClusterCmd) Run() error {
 				"droplet": &dotasks.Droplet{},
 			})
 		}
+	case kops.CloudProviderHetzner:
+		{
+			if !AlphaAllowHetzner.Enabled() {
+				return fmt.Errorf("Hetzner Cloud support is currently alpha and is feature-gated. export KOPS_FEATURE_FLAGS=AlphaAllowHetzner to enable it")
+			}
+
+			if len(sshPublicKeys) == 0 {
+				return fmt.Errorf("SSH public key must be specified when running with Hetzner Cloud (create with `kops create secret --name %s sshpublickey admin -i ~/.ssh/id_rsa.pub`)", cluster.ObjectMeta.Name)
+			}
+
+			modelContext.SSHPublicKeys = sshPublicKeys
+
+			l.AddTypes(map[string]interface{}{
+				"network":        &hetznertasks.Network{},
+				"sshKey":         &hetznertasks.SSHKey{},
+				"placementGroup": &hetznertasks.PlacementGroup{},
+				"loadBalancer":   &hetznertasks.LoadBalancer{},
+				"volume":         &hetznertasks.Volume{},
+				"serverGroup":    &hetznertasks.ServerGroup{},
+			})
+		}
+	case kops.CloudProviderScaleway:
+		{
+			if !AlphaAllowScaleway.Enabled() {
+				return fmt.Errorf("Scaleway support is currently alpha and is feature-gated. export KOPS_FEATURE_FLAGS=AlphaAllowScaleway to enable it")
+			}
+
+			if len(sshPublicKeys) == 0 {
+				return fmt.Errorf("SSH public key must be specified when running with Scaleway (create with `kops create secret --name %s sshpublickey admin -i ~/.ssh/id_rsa.pub`)", cluster.ObjectMeta.Name)
+			}
+
+			modelContext.SSHPublicKeys = sshPublicKeys
+
+			l.AddTypes(map[string]interface{}{
+				"privateNetwork": &scalewaytasks.PrivateNetwork{},
+				"sshKey":         &scalewaytasks.SSHKey{},
+				"loadBalancer":   &scalewaytasks.LoadBalancer{},
+				"volume":         &scalewaytasks.Volume{},
+				"instancePool":   &scalewaytasks.InstancePool{},
+			})
+		}
 	case kops.CloudProviderAWS:
 		{
 			awsCloud := cloud.(awsup.AWSCloud)
@@ -380,16 +452,16 @@ func (c *ApplyClusterCmd) Run() error {
 				"iamRolePolicy":          &awstasks.IAMRolePolicy{},
 
 				// VPC / Networking
-				"dhcpOptions":           &awstasks.DHCPOptions{},
-				"internetGateway":       &awstasks.InternetGateway{},
-				"route":                 &awstasks.Route{},
-				"routeTable":            &awstasks.RouteTable{},
-				"routeTableAssociation": &awstasks.RouteTableAssociation{},
-				"securityGroup":         &awstasks.SecurityGroup{},
-				"securityGroupRule":     &awstasks.SecurityGroupRule{},
-				"subnet":                &awstasks.Subnet{},
-				"vpc":                   &awstasks.VPC{},
-				"ngw":                   &awstasks.NatGateway{},
+				"dhcpOptions":                &awstasks.DHCPOptions{},
+				"internetGateway":            &awstasks.InternetGateway{},
+				"route":                      &awstasks.Route{},
+				"routeTable":                 &awstasks.RouteTable{},
+				"routeTableAssociation":      &awstasks.RouteTableAssociation{},
+				"securityGroup":              &awstasks.SecurityGroup{},
+				"securityGroupRule":          &awstasks.SecurityGroupRule{},
+				"subnet":                     &awstasks.Subnet{},
+				"vpc":                        &awstasks.VPC{},
+				"ngw":                        &awstasks.NatGateway{},
 				"vpcDHDCPOptionsAssociation": &awstasks.VPCDHCPOptionsAssociation{},
 
 				// ELB
@@ -470,7 +542,9 @@ func (c *ApplyClusterCmd) Run() error {
 				return fmt.Errorf("BareMetal support is currently (very) alpha and is feature-gated. export KOPS_FEATURE_FLAGS=AlphaAllowBareMetal to enable it")
 			}
 
-			// No additional tasks (yet)
+			l.AddTypes(map[string]interface{}{
+				"host": &baremetaltasks.Host{},
+			})
 		}
 
 	case kops.CloudProviderOpenstack:
@@ -496,11 +570,24 @@ func (c *ApplyClusterCmd) Run() error {
 			}
 		}
 	default:
-		return fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
+		provider := cloudplugin.Get(kops.CloudProviderID(cluster.Spec.CloudProvider))
+		if provider == nil {
+			return fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
+		}
+		region = provider.Region(cloud)
+
+		l.AddTypes(provider.TaskTypes())
+
+		modelContext.SSHPublicKeys = sshPublicKeys
 	}
 
 	modelContext.Region = region
 
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderGCE {
+		// Other clouds install a single key, so nodeup authorizes the additional keys
+		c.sshAuthorizedKeys = buildSSHAuthorizedKeys(sshPublicKeys, additionalSSHPublicKeys)
+	}
+
 	if dns.IsGossipHostname(cluster.ObjectMeta.Name) {
 		glog.Infof("Gossip DNS: skipping DNS validation")
 	} else {
@@ -576,6 +663,7 @@ func (c *ApplyClusterCmd) Run() error {
 					&model.ExternalAccessModelBuilder{KopsModelContext: modelContext, Lifecycle: &securityLifecycle},
 					&model.FirewallModelBuilder{KopsModelContext: modelContext, Lifecycle: &securityLifecycle},
 					&model.SSHKeyModelBuilder{KopsModelContext: modelContext, Lifecycle: &securityLifecycle},
+					&model.SecurityGroupOverrideModelBuilder{KopsModelContext: modelContext},
 				)
 
 				l.Builders = append(l.Builders,
@@ -590,6 +678,28 @@ func (c *ApplyClusterCmd) Run() error {
 					&model.MasterVolumeBuilder{KopsModelContext: modelContext, Lifecycle: &clusterLifecycle},
 				)
 
+			case kops.CloudProviderHetzner:
+				hetznerModelContext := &hetznermodel.HetznerModelContext{
+					KopsModelContext: modelContext,
+				}
+
+				l.Builders = append(l.Builders,
+					&model.MasterVolumeBuilder{KopsModelContext: modelContext, Lifecycle: &clusterLifecycle},
+					&hetznermodel.APILoadBalancerBuilder{HetznerModelContext: hetznerModelContext, Lifecycle: &clusterLifecycle},
+					&hetznermodel.NetworkModelBuilder{HetznerModelContext: hetznerModelContext, Lifecycle: &networkLifecycle},
+				)
+
+			case kops.CloudProviderScaleway:
+				scalewayModelContext := &scalewaymodel.ScalewayModelContext{
+					KopsModelContext: modelContext,
+				}
+
+				l.Builders = append(l.Builders,
+					&model.MasterVolumeBuilder{KopsModelContext: modelContext, Lifecycle: &clusterLifecycle},
+					&scalewaymodel.APILoadBalancerBuilder{ScalewayModelContext: scalewayModelContext, Lifecycle: &clusterLifecycle},
+					&scalewaymodel.NetworkModelBuilder{ScalewayModelContext: scalewayModelContext, Lifecycle: &networkLifecycle},
+				)
+
 			case kops.CloudProviderGCE:
 				gceModelContext := &gcemodel.GCEModelContext{
 					KopsModelContext: modelContext,
@@ -645,7 +755,10 @@ func (c *ApplyClusterCmd) Run() error {
 				)
 
 			default:
-				return fmt.Errorf("unknown cloudprovider %q", cluster.Spec.CloudProvider)
+				// The builders of cloud provider plugins are added with those of the instance groups
+				if cloudplugin.Get(kops.CloudProviderID(cluster.Spec.CloudProvider)) == nil {
+					return fmt.Errorf("unknown cloudprovider %q", cluster.Spec.CloudProvider)
+				}
 			}
 
 			fileModels = append(fileModels, m)
@@ -666,6 +779,7 @@ func (c *ApplyClusterCmd) Run() error {
 		NodeUpConfigBuilder: func(ig *kops.InstanceGroup) (*nodeup.Config, error) { return c.BuildNodeUpConfig(assetBuilder, ig) },
 		NodeUpSource:        c.NodeUpSource,
 		NodeUpSourceHash:    c.NodeUpHash,
+		SSHAuthorizedKeys:   c.sshAuthorizedKeys,
 	}
 	switch kops.CloudProviderID(cluster.Spec.CloudProvider) {
 	case kops.CloudProviderAWS:
@@ -703,6 +817,32 @@ func (c *ApplyClusterCmd) Run() error {
 			})
 		}
 
+	case kops.CloudProviderHetzner:
+		{
+			hetznerModelContext := &hetznermodel.HetznerModelContext{
+				KopsModelContext: modelContext,
+			}
+
+			l.Builders = append(l.Builders, &hetznermodel.ServerGroupModelBuilder{
+				HetznerModelContext: hetznerModelContext,
+				BootstrapScript:     bootstrapScriptBuilder,
+				Lifecycle:           &clusterLifecycle,
+			})
+		}
+
+	case kops.CloudProviderScaleway:
+		{
+			scalewayModelContext := &scalewaymodel.ScalewayModelContext{
+				KopsModelContext: modelContext,
+			}
+
+			l.Builders = append(l.Builders, &scalewaymodel.InstancePoolModelBuilder{
+				ScalewayModelContext: scalewayModelContext,
+				BootstrapScript:      bootstrapScriptBuilder,
+				Lifecycle:            &clusterLifecycle,
+			})
+		}
+
 	case kops.CloudProviderALI:
 		{
 			aliModelContext := &alimodel.ALIModelContext{
@@ -730,12 +870,42 @@ func (c *ApplyClusterCmd) Run() error {
 		}
 
 	case kops.CloudProviderBareMetal:
-		// BareMetal tasks will go here
+		{
+			baremetalModelContext := &baremetalmodel.BareMetalModelContext{
+				KopsModelContext: modelContext,
+			}
+
+			l.Builders = append(l.Builders, &baremetalmodel.HostModelBuilder{
+				BareMetalModelContext: baremetalModelContext,
+				BootstrapScript:       bootstrapScriptBuilder,
+				Lifecycle:             &clusterLifecycle,
+			})
+		}
 
 	case kops.CloudProviderOpenstack:
 
 	default:
-		return fmt.Errorf("unknown cloudprovider %q", cluster.Spec.CloudProvider)
+		provider := cloudplugin.Get(kops.CloudProviderID(cluster.Spec.CloudProvider))
+		if provider == nil {
+			return fmt.Errorf("unknown cloudprovider %q", cluster.Spec.CloudProvider)
+		}
+
+		builders, err := provider.ModelBuilders(&cloudplugin.ModelContext{
+			Cluster:        cluster,
+			InstanceGroups: c.InstanceGroups,
+			Region:         region,
+			SSHPublicKeys:  modelContext.SSHPublicKeys,
+			UserData: func(ig *kops.InstanceGroup) (*fi.ResourceHolder, error) {
+				return bootstrapScriptBuilder.ResourceNodeUp(ig, cluster)
+			},
+			ClusterLifecycle:  &clusterLifecycle,
+			NetworkLifecycle:  &networkLifecycle,
+			SecurityLifecycle: &securityLifecycle,
+		})
+		if err != nil {
+			return fmt.Errorf("error building the model of cloud provider %q: %v", cluster.Spec.CloudProvider, err)
+		}
+		l.Builders = append(l.Builders, builders...)
 	}
 
 	l.TemplateFunctions["Masters"] = tf.modelContext.MasterInstanceGroups
@@ -765,6 +935,10 @@ func (c *ApplyClusterCmd) Run() error {
 			target = awsup.NewAWSAPITarget(cloud.(awsup.AWSCloud))
 		case kops.CloudProviderDO:
 			target = do.NewDOAPITarget(cloud.(*digitalocean.Cloud))
+		case kops.CloudProviderHetzner:
+			target = hetzner.NewHetznerAPITarget(cloud.(hetzner.HetznerCloud))
+		case kops.CloudProviderScaleway:
+			target = scaleway.NewScalewayAPITarget(cloud.(scaleway.ScalewayCloud))
 		case kops.CloudProviderVSphere:
 			target = vsphere.NewVSphereAPITarget(cloud.(*vsphere.VSphereCloud))
 		case kops.CloudProviderBareMetal:
@@ -774,7 +948,14 @@ func (c *ApplyClusterCmd) Run() error {
 		case kops.CloudProviderALI:
 			target = aliup.NewALIAPITarget(cloud.(aliup.ALICloud))
 		default:
-			return fmt.Errorf("direct configuration not supported with CloudProvider:%q", cluster.Spec.CloudProvider)
+			provider := cloudplugin.Get(kops.CloudProviderID(cluster.Spec.CloudProvider))
+			if provider == nil {
+				return fmt.Errorf("direct configuration not supported with CloudProvider:%q", cluster.Spec.CloudProvider)
+			}
+			target, err = provider.NewTarget(cloud)
+			if err != nil {
+				return err
+			}
 		}
 
 	case TargetTerraform:
@@ -811,7 +992,11 @@ func (c *ApplyClusterCmd) Run() error {
 		shouldPrecreateDNS = false
 
 	case TargetDryRun:
-		target = fi.NewDryRunTarget(assetBuilder, os.Stdout)
+		dryRunOut := c.DryRunOut
+		if dryRunOut == nil {
+			dryRunOut = c.out()
+		}
+		target = fi.NewDryRunTarget(assetBuilder, dryRunOut)
 		dryRun = true
 
 		// Avoid making changes on a dry-run
@@ -900,7 +1085,17 @@ func (c *ApplyClusterCmd) upgradeSpecs(assetBuilder *assets.AssetBuilder) error
 }
 
 // validateKopsVersion ensures that kops meet the version requirements / recommendations in the channel
+// out returns the writer for messages for the user
+func (c *ApplyClusterCmd) out() io.Writer {
+	if c.Out != nil {
+		return c.Out
+	}
+	return os.Stdout
+}
+
 func (c *ApplyClusterCmd) validateKopsVersion() error {
+	out := c.out()
+
 	kopsVersion, err := semver.ParseTolerant(kopsbase.Version)
 	if err != nil {
 		glog.Warningf("unable to parse kops version %q", kopsbase.Version)
@@ -926,31 +1121,31 @@ func (c *ApplyClusterCmd) validateKopsVersion() error {
 	}
 
 	if recommended != nil && !required {
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
-		fmt.Printf("A new kops version is available: %s\n", recommended)
-		fmt.Printf("\n")
-		fmt.Printf("Upgrading is recommended\n")
-		fmt.Printf("More information: %s\n", buildPermalink("upgrade_kops", recommended.String()))
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "A new kops version is available: %s\n", recommended)
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "Upgrading is recommended\n")
+		fmt.Fprintf(out, "More information: %s\n", buildPermalink("upgrade_kops", recommended.String()))
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
 	} else if required {
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
 		if recommended != nil {
-			fmt.Printf("A new kops version is available: %s\n", recommended)
+			fmt.Fprintf(out, "A new kops version is available: %s\n", recommended)
 		}
-		fmt.Printf("\n")
-		fmt.Printf("This version of kops (%s) is no longer supported; upgrading is required\n", kopsbase.Version)
-		fmt.Printf("(you can bypass this check by exporting KOPS_RUN_OBSOLETE_VERSION)\n")
-		fmt.Printf("\n")
-		fmt.Printf("More information: %s\n", buildPermalink("upgrade_kops", recommended.String()))
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "This version of kops (%s) is no longer supported; upgrading is required\n", kopsbase.Version)
+		fmt.Fprintf(out, "(you can bypass this check by exporting KOPS_RUN_OBSOLETE_VERSION)\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "More information: %s\n", buildPermalink("upgrade_kops", recommended.String()))
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
 	}
 
 	if required {
@@ -964,6 +1159,8 @@ func (c *ApplyClusterCmd) validateKopsVersion() error {
 
 // validateKubernetesVersion ensures that kubernetes meet the version requirements / recommendations in the channel
 func (c *ApplyClusterCmd) validateKubernetesVersion() error {
+	out := c.out()
+
 	parsed, err := util.ParseKubernetesVersion(c.Cluster.Spec.KubernetesVersion)
 	if err != nil {
 		glog.Warningf("unable to parse kubernetes version %q", c.Cluster.Spec.KubernetesVersion)
@@ -992,31 +1189,31 @@ func (c *ApplyClusterCmd) validateKubernetesVersion() error {
 	}
 
 	if recommended != nil && !required {
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
-		fmt.Printf("A new kubernetes version is available: %s\n", recommended)
-		fmt.Printf("Upgrading is recommended (try kops upgrade cluster)\n")
-		fmt.Printf("\n")
-		fmt.Printf("More information: %s\n", buildPermalink("upgrade_k8s", recommended.String()))
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "A new kubernetes version is available: %s\n", recommended)
+		fmt.Fprintf(out, "Upgrading is recommended (try kops upgrade cluster)\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "More information: %s\n", buildPermalink("upgrade_k8s", recommended.String()))
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
 	} else if required {
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
 		if recommended != nil {
-			fmt.Printf("A new kubernetes version is available: %s\n", recommended)
+			fmt.Fprintf(out, "A new kubernetes version is available: %s\n", recommended)
 		}
-		fmt.Printf("\n")
-		fmt.Printf("This version of kubernetes is no longer supported; upgrading is required\n")
-		fmt.Printf("(you can bypass this check by exporting KOPS_RUN_OBSOLETE_VERSION)\n")
-		fmt.Printf("\n")
-		fmt.Printf("More information: %s\n", buildPermalink("upgrade_k8s", recommended.String()))
-		fmt.Printf("\n")
-		fmt.Printf(starline)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "This version of kubernetes is no longer supported; upgrading is required\n")
+		fmt.Fprintf(out, "(you can bypass this check by exporting KOPS_RUN_OBSOLETE_VERSION)\n")
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "More information: %s\n", buildPermalink("upgrade_k8s", recommended.String()))
+		fmt.Fprintf(out, "\n")
+		fmt.Fprint(out, starline)
+		fmt.Fprintf(out, "\n")
 	}
 
 	if required {
@@ -1194,6 +1391,9 @@ func (c *ApplyClusterCmd) BuildNodeUpConfig(assetBuilder *assets.AssetBuilder, i
 		if role == kops.InstanceGroupRoleMaster {
 			components = append(components, "kube-apiserver", "kube-controller-manager", "kube-scheduler")
 		}
+		if role == kops.InstanceGroupRoleAPIServer {
+			components = append(components, "kube-apiserver")
+		}
 
 		for _, component := range components {
 			baseURL, err := url.Parse(c.Cluster.Spec.KubernetesVersion)
@@ -1229,8 +1429,12 @@ func (c *ApplyClusterCmd) BuildNodeUpConfig(assetBuilder *assets.AssetBuilder, i
 		}
 	}
 
-	if role == kops.InstanceGroupRoleMaster {
+	if role == kops.InstanceGroupRoleMaster || role == kops.InstanceGroupRoleEtcd {
 		for _, etcdCluster := range cluster.Spec.EtcdClusters {
+			// The etcd members run either on the masters or on dedicated etcd instances, but never both
+			if apimodel.EtcdClusterRole(etcdCluster, c.InstanceGroups) != role {
+				continue
+			}
 			if etcdCluster.Manager != nil {
 				p := configBase.Join("manifests/etcd/" + etcdCluster.Name + ".yaml").Path()
 				config.EtcdManifests = append(config.EtcdManifests, p)
@@ -1240,6 +1444,7 @@ func (c *ApplyClusterCmd) BuildNodeUpConfig(assetBuilder *assets.AssetBuilder, i
 
 	config.Images = images
 	config.Channels = channels
+	config.SSHAuthorizedKeys = c.sshAuthorizedKeys
 
 	return config, nil
 }
@@ -48,6 +48,11 @@ type AutoscalingGroup struct {
 	Metrics     []string
 
 	LaunchConfiguration *LaunchConfiguration
+	// LaunchTemplate is used instead of LaunchConfiguration when set; the group always uses the default version
+	LaunchTemplate *LaunchTemplate
+
+	// PlacementGroup is the placement group the instances are launched into
+	PlacementGroup *PlacementGroup
 
 	SuspendProcesses *[]string
 }
@@ -58,6 +63,14 @@ func (e *AutoscalingGroup) CompareWithID() *string {
 	return e.Name
 }
 
+// launchTemplateSpecification returns the reference to the default version of the group's launch template
+func (e *AutoscalingGroup) launchTemplateSpecification() *autoscaling.LaunchTemplateSpecification {
+	return &autoscaling.LaunchTemplateSpecification{
+		LaunchTemplateId: e.LaunchTemplate.ID,
+		Version:          aws.String("$Default"),
+	}
+}
+
 func findAutoscalingGroup(cloud awsup.AWSCloud, name string) (*autoscaling.Group, error) {
 	request := &autoscaling.DescribeAutoScalingGroupsInput{
 		AutoScalingGroupNames: []*string{&name},
@@ -136,12 +149,18 @@ func (e *AutoscalingGroup) Find(c *fi.Context) (*AutoscalingGroup, error) {
 		}
 	}
 
-	if fi.StringValue(g.LaunchConfigurationName) == "" {
+	if g.LaunchTemplate != nil && fi.StringValue(g.LaunchTemplate.LaunchTemplateId) != "" {
+		actual.LaunchTemplate = &LaunchTemplate{ID: g.LaunchTemplate.LaunchTemplateId}
+	} else if fi.StringValue(g.LaunchConfigurationName) == "" {
 		glog.Warningf("autoscaling Group %q had no LaunchConfiguration", fi.StringValue(g.AutoScalingGroupName))
 	} else {
 		actual.LaunchConfiguration = &LaunchConfiguration{ID: g.LaunchConfigurationName}
 	}
 
+	if aws.StringValue(g.PlacementGroup) != "" {
+		actual.PlacementGroup = &PlacementGroup{Name: g.PlacementGroup}
+	}
+
 	if subnetSlicesEqualIgnoreOrder(actual.Subnets, e.Subnets) {
 		actual.Subnets = e.Subnets
 	}
@@ -208,9 +227,16 @@ func (_ *AutoscalingGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Autos
 
 		request := &autoscaling.CreateAutoScalingGroupInput{}
 		request.AutoScalingGroupName = e.Name
-		request.LaunchConfigurationName = e.LaunchConfiguration.ID
+		if e.LaunchTemplate != nil {
+			request.LaunchTemplate = e.launchTemplateSpecification()
+		} else {
+			request.LaunchConfigurationName = e.LaunchConfiguration.ID
+		}
 		request.MinSize = e.MinSize
 		request.MaxSize = e.MaxSize
+		if e.PlacementGroup != nil {
+			request.PlacementGroup = e.PlacementGroup.Name
+		}
 
 		var subnetIDs []string
 		for _, s := range e.Subnets {
@@ -255,9 +281,19 @@ func (_ *AutoscalingGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Autos
 		}
 
 		if changes.LaunchConfiguration != nil {
-			request.LaunchConfigurationName = e.LaunchConfiguration.ID
+			if e.LaunchConfiguration != nil {
+				request.LaunchConfigurationName = e.LaunchConfiguration.ID
+			}
 			changes.LaunchConfiguration = nil
 		}
+		if changes.LaunchTemplate != nil {
+			request.LaunchTemplate = e.launchTemplateSpecification()
+			changes.LaunchTemplate = nil
+		}
+		if changes.PlacementGroup != nil {
+			request.PlacementGroup = e.PlacementGroup.Name
+			changes.PlacementGroup = nil
+		}
 		if changes.MinSize != nil {
 			request.MinSize = e.MinSize
 			changes.MinSize = nil
@@ -379,6 +415,102 @@ func processCompare(a *[]string, b *[]string) []*string {
 	return notInB
 }
 
+var _ fi.ProducesDeletions = &AutoscalingGroup{}
+
+// FindDeletions detaches the external load balancers that kops attached to the group, but which are no longer in the spec
+func (e *AutoscalingGroup) FindDeletions(c *fi.Context) ([]fi.Deletion, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	g, err := findAutoscalingGroup(cloud, fi.StringValue(e.Name))
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, nil
+	}
+
+	attachedLoadBalancers := make(map[string]bool)
+	for _, name := range g.LoadBalancerNames {
+		attachedLoadBalancers[aws.StringValue(name)] = true
+	}
+	attachedTargetGroups := make(map[string]bool)
+	for _, arn := range g.TargetGroupARNs {
+		attachedTargetGroups[aws.StringValue(arn)] = true
+	}
+
+	var removals []fi.Deletion
+	for _, tag := range g.Tags {
+		key := aws.StringValue(tag.Key)
+		if _, found := e.Tags[key]; found {
+			continue
+		}
+
+		value := aws.StringValue(tag.Value)
+		if strings.HasPrefix(key, awsup.TagNameExternalLoadBalancerPrefix) && attachedLoadBalancers[value] {
+			removals = append(removals, &detachExternalLoadBalancer{autoscalingGroup: g.AutoScalingGroupName, loadBalancerName: value})
+		}
+		if strings.HasPrefix(key, awsup.TagNameExternalTargetGroupPrefix) && attachedTargetGroups[value] {
+			removals = append(removals, &detachExternalLoadBalancer{autoscalingGroup: g.AutoScalingGroupName, targetGroupARN: value})
+		}
+	}
+
+	return removals, nil
+}
+
+// detachExternalLoadBalancer tracks an external load balancer or target group that we're going to detach from an AutoscalingGroup
+// It implements fi.Deletion
+type detachExternalLoadBalancer struct {
+	autoscalingGroup *string
+	loadBalancerName string
+	targetGroupARN   string
+}
+
+var _ fi.Deletion = &detachExternalLoadBalancer{}
+
+func (d *detachExternalLoadBalancer) TaskName() string {
+	return "ExternalLoadBalancerAttachment"
+}
+
+func (d *detachExternalLoadBalancer) Item() string {
+	if d.targetGroupARN != "" {
+		return aws.StringValue(d.autoscalingGroup) + ":" + d.targetGroupARN
+	}
+	return aws.StringValue(d.autoscalingGroup) + ":" + d.loadBalancerName
+}
+
+func (d *detachExternalLoadBalancer) Delete(t fi.Target) error {
+	awsTarget, ok := t.(*awsup.AWSAPITarget)
+	if !ok {
+		return fmt.Errorf("unexpected target type for deletion: %T", t)
+	}
+
+	if d.targetGroupARN != "" {
+		glog.V(2).Infof("Detaching target group %q from autoscaling group %q", d.targetGroupARN, aws.StringValue(d.autoscalingGroup))
+		request := &autoscaling.DetachLoadBalancerTargetGroupsInput{
+			AutoScalingGroupName: d.autoscalingGroup,
+			TargetGroupARNs:      aws.StringSlice([]string{d.targetGroupARN}),
+		}
+		if _, err := awsTarget.Cloud.Autoscaling().DetachLoadBalancerTargetGroups(request); err != nil {
+			return fmt.Errorf("error detaching target group %q from autoscaling group: %v", d.targetGroupARN, err)
+		}
+		return nil
+	}
+
+	glog.V(2).Infof("Detaching ELB %q from autoscaling group %q", d.loadBalancerName, aws.StringValue(d.autoscalingGroup))
+	request := &autoscaling.DetachLoadBalancersInput{
+		AutoScalingGroupName: d.autoscalingGroup,
+		LoadBalancerNames:    aws.StringSlice([]string{d.loadBalancerName}),
+	}
+	if _, err := awsTarget.Cloud.Autoscaling().DetachLoadBalancers(request); err != nil {
+		return fmt.Errorf("error detaching ELB %q from autoscaling group: %v", d.loadBalancerName, err)
+	}
+	return nil
+}
+
+func (d *detachExternalLoadBalancer) String() string {
+	return d.TaskName() + "-" + d.Item()
+}
+
 // getASGTagsToDelete loops through the currently set tags and builds a list of
 // tags to be deleted from the Autoscaling Group
 func (e *AutoscalingGroup) getASGTagsToDelete(currentTags map[string]string) []*autoscaling.Tag {
@@ -402,27 +534,49 @@ type terraformASGTag struct {
 	Value             *string `json:"value"`
 	PropagateAtLaunch *bool   `json:"propagate_at_launch"`
 }
+type terraformAutoscalingLaunchTemplate struct {
+	ID      *terraform.Literal `json:"id,omitempty"`
+	Version *terraform.Literal `json:"version,omitempty"`
+}
+
 type terraformAutoscalingGroup struct {
-	Name                    *string              `json:"name,omitempty"`
-	LaunchConfigurationName *terraform.Literal   `json:"launch_configuration,omitempty"`
-	MaxSize                 *int64               `json:"max_size,omitempty"`
-	MinSize                 *int64               `json:"min_size,omitempty"`
-	VPCZoneIdentifier       []*terraform.Literal `json:"vpc_zone_identifier,omitempty"`
-	Tags                    []*terraformASGTag   `json:"tag,omitempty"`
-	MetricsGranularity      *string              `json:"metrics_granularity,omitempty"`
-	EnabledMetrics          []*string            `json:"enabled_metrics,omitempty"`
-	SuspendedProcesses      []*string            `json:"suspended_processes,omitempty"`
+	Name                    *string                             `json:"name,omitempty"`
+	LaunchConfigurationName *terraform.Literal                  `json:"launch_configuration,omitempty"`
+	LaunchTemplate          *terraformAutoscalingLaunchTemplate `json:"launch_template,omitempty"`
+	PlacementGroup          *terraform.Literal                  `json:"placement_group,omitempty"`
+	MaxSize                 *int64                              `json:"max_size,omitempty"`
+	MinSize                 *int64                              `json:"min_size,omitempty"`
+	VPCZoneIdentifier       []*terraform.Literal                `json:"vpc_zone_identifier,omitempty"`
+	Tags                    []*terraformASGTag                  `json:"tag,omitempty"`
+	MetricsGranularity      *string                             `json:"metrics_granularity,omitempty"`
+	EnabledMetrics          []*string                           `json:"enabled_metrics,omitempty"`
+	SuspendedProcesses      []*string                           `json:"suspended_processes,omitempty"`
 }
 
 func (_ *AutoscalingGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *AutoscalingGroup) error {
 
 	tf := &terraformAutoscalingGroup{
-		Name:                    e.Name,
-		MinSize:                 e.MinSize,
-		MaxSize:                 e.MaxSize,
-		LaunchConfigurationName: e.LaunchConfiguration.TerraformLink(),
-		MetricsGranularity:      e.Granularity,
-		EnabledMetrics:          aws.StringSlice(e.Metrics),
+		Name:               e.Name,
+		MinSize:            e.MinSize,
+		MaxSize:            e.MaxSize,
+		MetricsGranularity: e.Granularity,
+		EnabledMetrics:     aws.StringSlice(e.Metrics),
+	}
+
+	if e.PlacementGroup != nil {
+		tf.PlacementGroup = e.PlacementGroup.TerraformLink()
+	}
+
+	var securityGroups []*SecurityGroup
+	if e.LaunchTemplate != nil {
+		tf.LaunchTemplate = &terraformAutoscalingLaunchTemplate{
+			ID:      e.LaunchTemplate.TerraformLink(),
+			Version: e.LaunchTemplate.TerraformVersionLink(),
+		}
+		securityGroups = e.LaunchTemplate.SecurityGroups
+	} else if e.LaunchConfiguration != nil {
+		tf.LaunchConfigurationName = e.LaunchConfiguration.TerraformLink()
+		securityGroups = e.LaunchConfiguration.SecurityGroups
 	}
 
 	for _, s := range e.Subnets {
@@ -445,9 +599,9 @@ func (_ *AutoscalingGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, c
 		})
 	}
 
-	if e.LaunchConfiguration != nil {
+	if e.LaunchConfiguration != nil || e.LaunchTemplate != nil {
 		// Create TF output variable with security group ids
-		// This is in the launch configuration, but the ASG has the information about the instance group type
+		// This is in the launch configuration (or template), but the ASG has the information about the instance group type
 
 		role := ""
 		for k := range e.Tags {
@@ -461,7 +615,7 @@ func (_ *AutoscalingGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, c
 		}
 
 		if role != "" {
-			for _, sg := range e.LaunchConfiguration.SecurityGroups {
+			for _, sg := range securityGroups {
 				if err := t.AddOutputVariableArray(role+"_security_group_ids", sg.TerraformLink()); err != nil {
 					return err
 				}
@@ -491,6 +645,15 @@ func (_ *AutoscalingGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, c
 	return t.RenderResource("aws_autoscaling_group", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *AutoscalingGroup) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*AutoscalingGroup)
+	if !ok || actual == nil || actual.Name == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_autoscaling_group", *e.Name, *actual.Name)
+}
+
 func (e *AutoscalingGroup) TerraformLink() *terraform.Literal {
 	return terraform.LiteralProperty("aws_autoscaling_group", *e.Name, "id")
 }
@@ -505,14 +668,21 @@ type cloudformationASGMetricsCollection struct {
 	Granularity *string   `json:"Granularity"`
 	Metrics     []*string `json:"Metrics"`
 }
+type cloudformationAutoscalingLaunchTemplate struct {
+	LaunchTemplateID *cloudformation.Literal `json:"LaunchTemplateId,omitempty"`
+	Version          *cloudformation.Literal `json:"Version,omitempty"`
+}
+
 type cloudformationAutoscalingGroup struct {
-	Name                    *string                               `json:"AutoScalingGroupName,omitempty"`
-	LaunchConfigurationName *cloudformation.Literal               `json:"LaunchConfigurationName,omitempty"`
-	MaxSize                 *int64                                `json:"MaxSize,omitempty"`
-	MinSize                 *int64                                `json:"MinSize,omitempty"`
-	VPCZoneIdentifier       []*cloudformation.Literal             `json:"VPCZoneIdentifier,omitempty"`
-	Tags                    []*cloudformationASGTag               `json:"Tags,omitempty"`
-	MetricsCollection       []*cloudformationASGMetricsCollection `json:"MetricsCollection,omitempty"`
+	Name                    *string                                  `json:"AutoScalingGroupName,omitempty"`
+	LaunchConfigurationName *cloudformation.Literal                  `json:"LaunchConfigurationName,omitempty"`
+	LaunchTemplate          *cloudformationAutoscalingLaunchTemplate `json:"LaunchTemplate,omitempty"`
+	PlacementGroup          *cloudformation.Literal                  `json:"PlacementGroup,omitempty"`
+	MaxSize                 *int64                                   `json:"MaxSize,omitempty"`
+	MinSize                 *int64                                   `json:"MinSize,omitempty"`
+	VPCZoneIdentifier       []*cloudformation.Literal                `json:"VPCZoneIdentifier,omitempty"`
+	Tags                    []*cloudformationASGTag                  `json:"Tags,omitempty"`
+	MetricsCollection       []*cloudformationASGMetricsCollection    `json:"MetricsCollection,omitempty"`
 
 	LoadBalancerNames []*cloudformation.Literal `json:"LoadBalancerNames,omitempty"`
 	TargetGroupARNs   []*cloudformation.Literal `json:"TargetGroupARNs,omitempty"`
@@ -529,7 +699,19 @@ func (_ *AutoscalingGroup) RenderCloudformation(t *cloudformation.Cloudformation
 				Metrics:     aws.StringSlice(e.Metrics),
 			},
 		},
-		LaunchConfigurationName: e.LaunchConfiguration.CloudformationLink(),
+	}
+
+	if e.LaunchTemplate != nil {
+		tf.LaunchTemplate = &cloudformationAutoscalingLaunchTemplate{
+			LaunchTemplateID: e.LaunchTemplate.CloudformationLink(),
+			Version:          e.LaunchTemplate.CloudformationVersionLink(),
+		}
+	} else {
+		tf.LaunchConfigurationName = e.LaunchConfiguration.CloudformationLink()
+	}
+
+	if e.PlacementGroup != nil {
+		tf.PlacementGroup = e.PlacementGroup.CloudformationLink()
 	}
 
 	for _, s := range e.Subnets {
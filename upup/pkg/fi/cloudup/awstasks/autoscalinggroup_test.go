@@ -20,7 +20,10 @@ import (
 	"sort"
 	"testing"
 
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
 	"k8s.io/kops/pkg/diff"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
@@ -196,3 +199,59 @@ func TestProcessCompare(t *testing.T) {
 		}
 	}
 }
+
+func TestFindDeletionsDetachesRemovedExternalLoadBalancers(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockautoscaling.MockAutoscaling{}
+	cloud.MockAutoscaling = c
+
+	tgARN := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/ingress/0123456789abcdef"
+	c.Groups = map[string]*autoscaling.Group{
+		"nodes.example.com": {
+			AutoScalingGroupName: aws.String("nodes.example.com"),
+			LoadBalancerNames:    aws.StringSlice([]string{"kept-elb", "removed-elb", "unmanaged-elb"}),
+			TargetGroupARNs:      aws.StringSlice([]string{tgARN}),
+			Tags: []*autoscaling.TagDescription{
+				{Key: aws.String(awsup.TagNameExternalLoadBalancerPrefix + "kept-elb"), Value: aws.String("kept-elb")},
+				{Key: aws.String(awsup.TagNameExternalLoadBalancerPrefix + "removed-elb"), Value: aws.String("removed-elb")},
+				{Key: aws.String(awsup.TagNameExternalTargetGroupPrefix + "ingress"), Value: aws.String(tgARN)},
+			},
+		},
+	}
+
+	e := &AutoscalingGroup{
+		Name: aws.String("nodes.example.com"),
+		Tags: map[string]string{
+			awsup.TagNameExternalLoadBalancerPrefix + "kept-elb": "kept-elb",
+		},
+	}
+
+	context, err := fi.NewContext(&awsup.AWSAPITarget{Cloud: cloud}, nil, cloud, nil, nil, nil, true, nil)
+	if err != nil {
+		t.Fatalf("error building context: %v", err)
+	}
+
+	deletions, err := e.FindDeletions(context)
+	if err != nil {
+		t.Fatalf("unexpected error from FindDeletions: %v", err)
+	}
+	if len(deletions) != 2 {
+		t.Fatalf("expected 2 deletions, got %v", deletions)
+	}
+
+	for _, d := range deletions {
+		if err := d.Delete(context.Target); err != nil {
+			t.Fatalf("unexpected error from Delete: %v", err)
+		}
+	}
+
+	g := c.Groups["nodes.example.com"]
+	lbs := aws.StringValueSlice(g.LoadBalancerNames)
+	sort.Strings(lbs)
+	if len(lbs) != 2 || lbs[0] != "kept-elb" || lbs[1] != "unmanaged-elb" {
+		t.Errorf("unexpected load balancers after detaching: %v", lbs)
+	}
+	if len(g.TargetGroupARNs) != 0 {
+		t.Errorf("unexpected target groups after detaching: %v", aws.StringValueSlice(g.TargetGroupARNs))
+	}
+}
@@ -30,6 +30,9 @@ type BlockDeviceMapping struct {
 	EbsVolumeSize          *int64
 	EbsVolumeType          *string
 	EbsVolumeIops          *int64
+	EbsEncrypted           *bool
+	// EbsVolumeThroughput is the throughput of gp3 volumes; only launch templates support it
+	EbsVolumeThroughput *int64
 }
 
 func BlockDeviceMappingFromEC2(i *ec2.BlockDeviceMapping) (string, *BlockDeviceMapping) {
@@ -39,6 +42,8 @@ func BlockDeviceMappingFromEC2(i *ec2.BlockDeviceMapping) (string, *BlockDeviceM
 		o.EbsDeleteOnTermination = i.Ebs.DeleteOnTermination
 		o.EbsVolumeSize = i.Ebs.VolumeSize
 		o.EbsVolumeType = i.Ebs.VolumeType
+		o.EbsVolumeIops = i.Ebs.Iops
+		o.EbsEncrypted = i.Ebs.Encrypted
 	}
 	return aws.StringValue(i.DeviceName), o
 }
@@ -52,6 +57,8 @@ func (i *BlockDeviceMapping) ToEC2(deviceName string) *ec2.BlockDeviceMapping {
 		o.Ebs.DeleteOnTermination = i.EbsDeleteOnTermination
 		o.Ebs.VolumeSize = i.EbsVolumeSize
 		o.Ebs.VolumeType = i.EbsVolumeType
+		o.Ebs.Iops = i.EbsVolumeIops
+		o.Ebs.Encrypted = i.EbsEncrypted
 	}
 	return o
 }
@@ -63,6 +70,8 @@ func BlockDeviceMappingFromAutoscaling(i *autoscaling.BlockDeviceMapping) (strin
 		o.EbsDeleteOnTermination = i.Ebs.DeleteOnTermination
 		o.EbsVolumeSize = i.Ebs.VolumeSize
 		o.EbsVolumeType = i.Ebs.VolumeType
+		o.EbsVolumeIops = i.Ebs.Iops
+		o.EbsEncrypted = i.Ebs.Encrypted
 	}
 	return aws.StringValue(i.DeviceName), o
 }
@@ -78,6 +87,37 @@ func (i *BlockDeviceMapping) ToAutoscaling(deviceName string) *autoscaling.Block
 		o.Ebs.VolumeSize = i.EbsVolumeSize
 		o.Ebs.VolumeType = i.EbsVolumeType
 		o.Ebs.Iops = i.EbsVolumeIops
+		o.Ebs.Encrypted = i.EbsEncrypted
+	}
+
+	return o
+}
+
+func BlockDeviceMappingFromLaunchTemplate(i *ec2.LaunchTemplateBlockDeviceMapping) (string, *BlockDeviceMapping) {
+	o := &BlockDeviceMapping{}
+	o.VirtualName = i.VirtualName
+	if i.Ebs != nil {
+		o.EbsDeleteOnTermination = i.Ebs.DeleteOnTermination
+		o.EbsVolumeSize = i.Ebs.VolumeSize
+		o.EbsVolumeType = i.Ebs.VolumeType
+		o.EbsVolumeIops = i.Ebs.Iops
+		o.EbsEncrypted = i.Ebs.Encrypted
+	}
+	return aws.StringValue(i.DeviceName), o
+}
+
+func (i *BlockDeviceMapping) ToLaunchTemplate(deviceName string) *ec2.LaunchTemplateBlockDeviceMappingRequest {
+	o := &ec2.LaunchTemplateBlockDeviceMappingRequest{}
+	o.DeviceName = aws.String(deviceName)
+	o.VirtualName = i.VirtualName
+
+	if i.EbsDeleteOnTermination != nil || i.EbsVolumeSize != nil || i.EbsVolumeType != nil {
+		o.Ebs = &ec2.LaunchTemplateEbsBlockDeviceRequest{}
+		o.Ebs.DeleteOnTermination = i.EbsDeleteOnTermination
+		o.Ebs.VolumeSize = i.EbsVolumeSize
+		o.Ebs.VolumeType = i.EbsVolumeType
+		o.Ebs.Iops = i.EbsVolumeIops
+		o.Ebs.Encrypted = i.EbsEncrypted
 	}
 
 	return o
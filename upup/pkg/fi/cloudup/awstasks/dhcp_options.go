@@ -187,6 +187,15 @@ func (_ *DHCPOptions) RenderTerraform(t *terraform.TerraformTarget, a, e, change
 	return t.RenderResource("aws_vpc_dhcp_options", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *DHCPOptions) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*DHCPOptions)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_vpc_dhcp_options", *e.Name, *actual.ID)
+}
+
 func (e *DHCPOptions) TerraformLink() *terraform.Literal {
 	return terraform.LiteralProperty("aws_vpc_dhcp_options", *e.Name, "id")
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+)
+
+// DirectInstanceGroup is a group of instances that kops launches and terminates itself, without an autoscaling group.
+// Instances are launched from the LaunchTemplate and spread across the Subnets.
+//
+//go:generate fitask -type=DirectInstanceGroup
+type DirectInstanceGroup struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	// GroupName is the name of the kops instance group, which the instances are tagged with
+	GroupName *string
+
+	LaunchTemplate *LaunchTemplate
+	Subnets        []*Subnet
+
+	// Size is the number of instances that should be running
+	Size *int64
+
+	// InstanceTypes are tried in order when launching instances; if empty the instance type of the LaunchTemplate is used
+	InstanceTypes []string
+
+	instances []*ec2.Instance
+}
+
+func (e *DirectInstanceGroup) Find(c *fi.Context) (*DirectInstanceGroup, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	instances, err := awsup.FindDirectInstances(cloud, fi.StringValue(e.GroupName))
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, nil
+	}
+
+	actual := &DirectInstanceGroup{
+		Name:      e.Name,
+		GroupName: e.GroupName,
+		Size:      fi.Int64(int64(len(instances))),
+		instances: instances,
+
+		// Launch template changes are rolled out by rolling-update
+		LaunchTemplate: e.LaunchTemplate,
+		InstanceTypes:  e.InstanceTypes,
+		// Instances are not moved between subnets, we only use the subnets for new instances
+		Subnets: e.Subnets,
+
+		// Avoid spurious changes
+		Lifecycle: e.Lifecycle,
+	}
+
+	return actual, nil
+}
+
+func (e *DirectInstanceGroup) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (s *DirectInstanceGroup) CheckChanges(a, e, changes *DirectInstanceGroup) error {
+	if e.GroupName == nil {
+		return fi.RequiredField("GroupName")
+	}
+	if e.LaunchTemplate == nil {
+		return fi.RequiredField("LaunchTemplate")
+	}
+	if len(e.Subnets) == 0 {
+		return fi.RequiredField("Subnets")
+	}
+	return nil
+}
+
+func (_ *DirectInstanceGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DirectInstanceGroup) error {
+	var instances []*ec2.Instance
+	if a != nil {
+		instances = a.instances
+	}
+
+	size := int(fi.Int64Value(e.Size))
+	if len(instances) == size {
+		return nil
+	}
+
+	template, err := awsup.FindLaunchTemplate(t.Cloud, fi.StringValue(e.LaunchTemplate.Name))
+	if err != nil {
+		return err
+	}
+
+	var subnetIDs []string
+	for _, subnet := range e.Subnets {
+		subnetIDs = append(subnetIDs, fi.StringValue(subnet.ID))
+	}
+
+	return awsup.ResizeDirectInstances(t.Cloud, fi.StringValue(e.GroupName), template, instances, subnetIDs, e.InstanceTypes, size)
+}
+
+func (_ *DirectInstanceGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *DirectInstanceGroup) error {
+	return fmt.Errorf("directly managed instance groups are not supported with terraform")
+}
+
+func (_ *DirectInstanceGroup) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *DirectInstanceGroup) error {
+	return fmt.Errorf("directly managed instance groups are not supported with cloudformation")
+}
@@ -44,6 +44,15 @@ type DNSZone struct {
 
 	Private    *bool
 	PrivateVPC *VPC
+
+	// AdditionalVPCs are other VPCs to associate with a private zone
+	AdditionalVPCs []DNSZoneVPC
+}
+
+// DNSZoneVPC identifies a VPC, which may be in another region, to associate with a private zone
+type DNSZoneVPC struct {
+	ID     string
+	Region string
 }
 
 var _ fi.CompareWithID = &DNSZone{}
@@ -88,6 +97,15 @@ func (e *DNSZone) Find(c *fi.Context) (*DNSZone, error) {
 		}
 	}
 
+	for _, v := range e.AdditionalVPCs {
+		for _, vpc := range z.VPCs {
+			if v.ID == aws.StringValue(vpc.VPCId) && v.Region == aws.StringValue(vpc.VPCRegion) {
+				actual.AdditionalVPCs = append(actual.AdditionalVPCs, v)
+				break
+			}
+		}
+	}
+
 	if e.ZoneID == nil {
 		e.ZoneID = actual.ZoneID
 	}
@@ -195,6 +213,10 @@ func (_ *DNSZone) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSZone) error
 		}
 
 		e.ZoneID = response.HostedZone.Id
+
+		if err := e.associateVPCs(t.Cloud, e.AdditionalVPCs); err != nil {
+			return err
+		}
 	} else {
 		if changes.PrivateVPC != nil {
 			request := &route53.AssociateVPCWithHostedZoneInput{
@@ -215,6 +237,20 @@ func (_ *DNSZone) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSZone) error
 			}
 		}
 
+		if changes.AdditionalVPCs != nil {
+			var missing []DNSZoneVPC
+			for _, v := range e.AdditionalVPCs {
+				if !hasDNSZoneVPC(a.AdditionalVPCs, v) {
+					missing = append(missing, v)
+				}
+			}
+			changes.AdditionalVPCs = nil
+
+			if err := a.associateVPCs(t.Cloud, missing); err != nil {
+				return err
+			}
+		}
+
 		empty := &DNSZone{}
 		if !reflect.DeepEqual(empty, changes) {
 			glog.Warningf("cannot apply changes to DNSZone %q: %v", name, changes)
@@ -225,9 +261,39 @@ func (_ *DNSZone) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSZone) error
 	return nil
 }
 
+// associateVPCs associates the VPCs with the (existing) zone
+func (e *DNSZone) associateVPCs(cloud awsup.AWSCloud, vpcs []DNSZoneVPC) error {
+	for _, v := range vpcs {
+		request := &route53.AssociateVPCWithHostedZoneInput{
+			HostedZoneId: e.ZoneID,
+			VPC: &route53.VPC{
+				VPCId:     aws.String(v.ID),
+				VPCRegion: aws.String(v.Region),
+			},
+		}
+
+		glog.V(2).Infof("Associating VPC %q with DNSZone %q", v.ID, aws.StringValue(e.DNSName))
+
+		if _, err := cloud.Route53().AssociateVPCWithHostedZone(request); err != nil {
+			return fmt.Errorf("error associating VPC %q with hosted zone %q: %v", v.ID, aws.StringValue(e.DNSName), err)
+		}
+	}
+	return nil
+}
+
+func hasDNSZoneVPC(vpcs []DNSZoneVPC, v DNSZoneVPC) bool {
+	for _, vpc := range vpcs {
+		if vpc == v {
+			return true
+		}
+	}
+	return false
+}
+
 type terraformRoute53ZoneAssociation struct {
 	ZoneID    *terraform.Literal   `json:"zone_id"`
 	VPCID     *terraform.Literal   `json:"vpc_id"`
+	VPCRegion *string              `json:"vpc_region,omitempty"`
 	Lifecycle *terraform.Lifecycle `json:"lifecycle,omitempty"`
 }
 
@@ -274,7 +340,31 @@ func (_ *DNSZone) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *D
 					ZoneID: terraform.LiteralFromStringValue(*e.ZoneID),
 					VPCID:  e.PrivateVPC.TerraformLink(),
 				}
-				return t.RenderResource("aws_route53_zone_association", *e.Name, tf)
+				if err := t.RenderResource("aws_route53_zone_association", *e.Name, tf); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, v := range e.AdditionalVPCs {
+			associated := false
+			for _, vpc := range z.VPCs {
+				if v.ID == aws.StringValue(vpc.VPCId) && v.Region == aws.StringValue(vpc.VPCRegion) {
+					associated = true
+				}
+			}
+			if associated {
+				continue
+			}
+
+			glog.Infof("No association between VPC %q and zone %q; adding", v.ID, aws.StringValue(z.HostedZone.Name))
+			tf := &terraformRoute53ZoneAssociation{
+				ZoneID:    terraform.LiteralFromStringValue(*e.ZoneID),
+				VPCID:     terraform.LiteralFromStringValue(v.ID),
+				VPCRegion: aws.String(v.Region),
+			}
+			if err := t.RenderResource("aws_route53_zone_association", *e.Name+"-"+v.ID, tf); err != nil {
+				return err
 			}
 		}
 
@@ -332,6 +422,10 @@ func (_ *DNSZone) RenderCloudformation(t *cloudformation.CloudformationTarget, a
 		return fmt.Errorf("Creation of public Route53 hosted zones is not supported for cloudformation")
 	}
 
+	if len(e.AdditionalVPCs) != 0 {
+		return fmt.Errorf("Association of additional VPCs with Route53 hosted zones is not supported for cloudformation")
+	}
+
 	// We will create private zones (and delete them)
 	tf := &cloudformationRoute53Zone{
 		Name: e.Name,
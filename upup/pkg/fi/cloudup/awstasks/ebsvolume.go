@@ -38,6 +38,7 @@ type EBSVolume struct {
 	VolumeType       *string
 	SizeGB           *int64
 	VolumeIops       *int64
+	VolumeThroughput *int64
 	KmsKeyId         *string
 	Encrypted        *bool
 	Tags             map[string]string
@@ -105,6 +106,9 @@ func (e *EBSVolume) find(cloud awsup.AWSCloud) (*EBSVolume, error) {
 		VolumeIops:       v.Iops,
 	}
 
+	// The vendored SDK does not return the throughput, and volumes are never modified
+	actual.VolumeThroughput = e.VolumeThroughput
+
 	actual.Tags = mapEC2TagsToMap(v.Tags)
 
 	// Avoid spurious changes
@@ -158,7 +162,7 @@ func (_ *EBSVolume) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *EBSVolume) e
 			}
 		}
 
-		response, err := t.Cloud.EC2().CreateVolume(request)
+		response, err := awsup.CreateVolume(t.Cloud, request, e.VolumeThroughput)
 		if err != nil {
 			return fmt.Errorf("error creating PersistentVolume: %v", err)
 		}
@@ -198,6 +202,7 @@ type terraformVolume struct {
 	Size             *int64            `json:"size,omitempty"`
 	Type             *string           `json:"type,omitempty"`
 	Iops             *int64            `json:"iops,omitempty"`
+	Throughput       *int64            `json:"throughput,omitempty"`
 	KmsKeyId         *string           `json:"kms_key_id,omitempty"`
 	Encrypted        *bool             `json:"encrypted,omitempty"`
 	Tags             map[string]string `json:"tags,omitempty"`
@@ -209,6 +214,7 @@ func (_ *EBSVolume) RenderTerraform(t *terraform.TerraformTarget, a, e, changes
 		Size:             e.SizeGB,
 		Type:             e.VolumeType,
 		Iops:             e.VolumeIops,
+		Throughput:       e.VolumeThroughput,
 		KmsKeyId:         e.KmsKeyId,
 		Encrypted:        e.Encrypted,
 		Tags:             e.Tags,
@@ -217,6 +223,15 @@ func (_ *EBSVolume) RenderTerraform(t *terraform.TerraformTarget, a, e, changes
 	return t.RenderResource("aws_ebs_volume", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *EBSVolume) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*EBSVolume)
+	if !ok || actual == nil || actual.ID == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_ebs_volume", *e.Name, *actual.ID)
+}
+
 func (e *EBSVolume) TerraformLink() *terraform.Literal {
 	return terraform.LiteralSelfLink("aws_ebs_volume", *e.Name)
 }
@@ -226,6 +241,7 @@ type cloudformationVolume struct {
 	Size             *int64              `json:"Size,omitempty"`
 	Type             *string             `json:"VolumeType,omitempty"`
 	Iops             *int64              `json:"Iops,omitempty"`
+	Throughput       *int64              `json:"Throughput,omitempty"`
 	KmsKeyId         *string             `json:"KmsKeyId,omitempty"`
 	Encrypted        *bool               `json:"Encrypted,omitempty"`
 	Tags             []cloudformationTag `json:"Tags,omitempty"`
@@ -237,6 +253,7 @@ func (_ *EBSVolume) RenderCloudformation(t *cloudformation.CloudformationTarget,
 		Size:             e.SizeGB,
 		Type:             e.VolumeType,
 		Iops:             e.VolumeIops,
+		Throughput:       e.VolumeThroughput,
 		KmsKeyId:         e.KmsKeyId,
 		Encrypted:        e.Encrypted,
 		Tags:             buildCloudformationTags(e.Tags),
@@ -292,6 +292,15 @@ func (_ *ElasticIP) RenderTerraform(t *terraform.TerraformTarget, a, e, changes
 	return t.RenderResource("aws_eip", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *ElasticIP) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*ElasticIP)
+	if !ok || actual == nil || actual.ID == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_eip", *e.Name, *actual.ID)
+}
+
 func (e *ElasticIP) TerraformLink() *terraform.Literal {
 	return terraform.LiteralProperty("aws_eip", *e.Name, "id")
 }
@@ -125,6 +125,15 @@ func (_ *IAMInstanceProfileRole) RenderTerraform(t *terraform.TerraformTarget, a
 	return t.RenderResource("aws_iam_instance_profile", *e.InstanceProfile.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *IAMInstanceProfileRole) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*IAMInstanceProfileRole)
+	if !ok || actual == nil || actual.InstanceProfile == nil || actual.InstanceProfile.Name == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_iam_instance_profile", *e.InstanceProfile.Name, *actual.InstanceProfile.Name)
+}
+
 type cloudformationIAMInstanceProfile struct {
 	//Path  *string              `json:"name"`
 	Roles []*cloudformation.Literal `json:"Roles"`
@@ -213,6 +213,15 @@ func (_ *IAMRole) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *I
 	return t.RenderResource("aws_iam_role", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *IAMRole) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*IAMRole)
+	if !ok || actual == nil || actual.Name == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_iam_role", *e.Name, *actual.Name)
+}
+
 func (e *IAMRole) TerraformLink() *terraform.Literal {
 	return terraform.LiteralProperty("aws_iam_role", *e.Name, "name")
 }
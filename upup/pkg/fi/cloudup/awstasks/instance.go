@@ -52,6 +52,9 @@ type Instance struct {
 	SecurityGroups     []*SecurityGroup
 	AssociatePublicIP  *bool
 	IAMInstanceProfile *IAMInstanceProfile
+
+	// SourceDestCheck must be disabled for instances that route traffic, such as NAT instances
+	SourceDestCheck *bool
 }
 
 var _ fi.CompareWithID = &Instance{}
@@ -113,6 +116,7 @@ func (e *Instance) Find(c *fi.Context) (*Instance, error) {
 		InstanceType:     i.InstanceType,
 		ImageID:          i.ImageId,
 		Name:             findNameTag(i.Tags),
+		SourceDestCheck:  i.SourceDestCheck,
 	}
 
 	// Fetch instance UserData
@@ -293,6 +297,17 @@ func (_ *Instance) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Instance) err
 		e.ID = response.Instances[0].InstanceId
 	}
 
+	if e.SourceDestCheck != nil && (a == nil || changes.SourceDestCheck != nil) {
+		request := &ec2.ModifyInstanceAttributeInput{
+			InstanceId:      e.ID,
+			SourceDestCheck: &ec2.AttributeBooleanValue{Value: e.SourceDestCheck},
+		}
+		glog.V(2).Infof("Setting SourceDestCheck=%v on Instance %q", *e.SourceDestCheck, *e.ID)
+		if _, err := t.Cloud.EC2().ModifyInstanceAttribute(request); err != nil {
+			return fmt.Errorf("error setting SourceDestCheck on Instance %q: %v", *e.ID, err)
+		}
+	}
+
 	return t.AddAWSTags(*e.ID, e.Tags)
 }
 
@@ -305,5 +320,78 @@ func (e *Instance) TerraformLink() *terraform.Literal {
 		return terraform.LiteralFromStringValue(*e.ID)
 	}
 
-	return terraform.LiteralSelfLink("aws_instance", *e.Name)
+	return terraform.LiteralProperty("aws_instance", *e.Name, "id")
+}
+
+type terraformInstance struct {
+	AMI                      *string              `json:"ami,omitempty"`
+	InstanceType             *string              `json:"instance_type,omitempty"`
+	SubnetID                 *terraform.Literal   `json:"subnet_id,omitempty"`
+	PrivateIP                *string              `json:"private_ip,omitempty"`
+	KeyName                  *terraform.Literal   `json:"key_name,omitempty"`
+	SecurityGroupIDs         []*terraform.Literal `json:"vpc_security_group_ids,omitempty"`
+	AssociatePublicIPAddress *bool                `json:"associate_public_ip_address,omitempty"`
+	SourceDestCheck          *bool                `json:"source_dest_check,omitempty"`
+	IAMInstanceProfile       *terraform.Literal   `json:"iam_instance_profile,omitempty"`
+	UserData                 *terraform.Literal   `json:"user_data,omitempty"`
+	Tags                     map[string]string    `json:"tags,omitempty"`
+}
+
+func (_ *Instance) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Instance) error {
+	if fi.BoolValue(e.Shared) {
+		if e.ID == nil {
+			return fmt.Errorf("ID must be set, if NAT Instance is shared: %s", e)
+		}
+
+		glog.V(4).Infof("reusing existing Instance with id %q", *e.ID)
+		return nil
+	}
+
+	if e.ImageID == nil {
+		return fi.RequiredField("ImageID")
+	}
+	image, err := t.Cloud.(awsup.AWSCloud).ResolveImage(*e.ImageID)
+	if err != nil {
+		return err
+	}
+
+	tf := &terraformInstance{
+		AMI:                      image.ImageId,
+		InstanceType:             e.InstanceType,
+		SubnetID:                 e.Subnet.TerraformLink(),
+		PrivateIP:                e.PrivateIPAddress,
+		AssociatePublicIPAddress: e.AssociatePublicIP,
+		SourceDestCheck:          e.SourceDestCheck,
+		Tags:                     e.Tags,
+	}
+
+	if e.SSHKey != nil {
+		tf.KeyName = e.SSHKey.TerraformLink()
+	}
+
+	for _, sg := range e.SecurityGroups {
+		tf.SecurityGroupIDs = append(tf.SecurityGroupIDs, sg.TerraformLink())
+	}
+
+	if e.IAMInstanceProfile != nil {
+		tf.IAMInstanceProfile = e.IAMInstanceProfile.TerraformLink()
+	}
+
+	if e.UserData != nil {
+		tf.UserData, err = t.AddFile("aws_instance", *e.Name, "user_data", e.UserData)
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.RenderResource("aws_instance", *e.Name, tf)
+}
+
+// TerraformImport implements terraform.Importable
+func (e *Instance) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*Instance)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_instance", *e.Name, *actual.ID)
 }
@@ -216,6 +216,15 @@ func (_ *InternetGateway) RenderTerraform(t *terraform.TerraformTarget, a, e, ch
 	return t.RenderResource("aws_internet_gateway", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *InternetGateway) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*InternetGateway)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_internet_gateway", *e.Name, *actual.ID)
+}
+
 func (e *InternetGateway) TerraformLink() *terraform.Literal {
 	shared := fi.BoolValue(e.Shared)
 	if shared {
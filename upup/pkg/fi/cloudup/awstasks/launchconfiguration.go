@@ -70,6 +70,8 @@ type LaunchConfiguration struct {
 	RootVolumeIops *int64
 	// RootVolumeOptimization enables EBS optimization for an instance
 	RootVolumeOptimization *bool
+	// BlockDeviceMappings are the additional EBS volumes, keyed by device name
+	BlockDeviceMappings map[string]*BlockDeviceMapping
 
 	// SpotPrice is set to the spot-price bid if this is a spot pricing request
 	SpotPrice string
@@ -171,8 +173,16 @@ func (e *LaunchConfiguration) Find(c *fi.Context) (*LaunchConfiguration, error)
 
 	actual.SecurityGroups = securityGroups
 
-	// Find the root volume
+	// Find the root volume, and the additional volumes
 	for _, b := range lc.BlockDeviceMappings {
+		if _, found := e.BlockDeviceMappings[aws.StringValue(b.DeviceName)]; found {
+			deviceName, bdm := BlockDeviceMappingFromAutoscaling(b)
+			if actual.BlockDeviceMappings == nil {
+				actual.BlockDeviceMappings = make(map[string]*BlockDeviceMapping)
+			}
+			actual.BlockDeviceMappings[deviceName] = bdm
+			continue
+		}
 		if b.Ebs == nil || b.Ebs.SnapshotId != nil {
 			// Not the root
 			continue
@@ -332,7 +342,7 @@ func (_ *LaunchConfiguration) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *La
 			return err
 		}
 
-		if len(rootDevices) != 0 || len(ephemeralDevices) != 0 {
+		if len(rootDevices) != 0 || len(ephemeralDevices) != 0 || len(e.BlockDeviceMappings) != 0 {
 			request.BlockDeviceMappings = []*autoscaling.BlockDeviceMapping{}
 			for device, bdm := range rootDevices {
 				request.BlockDeviceMappings = append(request.BlockDeviceMappings, bdm.ToAutoscaling(device))
@@ -340,6 +350,9 @@ func (_ *LaunchConfiguration) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *La
 			for device, bdm := range ephemeralDevices {
 				request.BlockDeviceMappings = append(request.BlockDeviceMappings, bdm.ToAutoscaling(device))
 			}
+			for device, bdm := range e.BlockDeviceMappings {
+				request.BlockDeviceMappings = append(request.BlockDeviceMappings, bdm.ToAutoscaling(device))
+			}
 		}
 	}
 
@@ -404,6 +417,7 @@ type terraformLaunchConfiguration struct {
 	RootBlockDevice          *terraformBlockDevice   `json:"root_block_device,omitempty"`
 	EBSOptimized             *bool                   `json:"ebs_optimized,omitempty"`
 	EphemeralBlockDevice     []*terraformBlockDevice `json:"ephemeral_block_device,omitempty"`
+	EBSBlockDevice           []*terraformBlockDevice `json:"ebs_block_device,omitempty"`
 	Lifecycle                *terraform.Lifecycle    `json:"lifecycle,omitempty"`
 	SpotPrice                *string                 `json:"spot_price,omitempty"`
 	PlacementTenancy         *string                 `json:"placement_tenancy,omitempty"`
@@ -415,9 +429,11 @@ type terraformBlockDevice struct {
 	DeviceName  *string `json:"device_name,omitempty"`
 	VirtualName *string `json:"virtual_name,omitempty"`
 
-	// For root
+	// For root and additional EBS volumes
 	VolumeType          *string `json:"volume_type,omitempty"`
 	VolumeSize          *int64  `json:"volume_size,omitempty"`
+	Iops                *int64  `json:"iops,omitempty"`
+	Encrypted           *bool   `json:"encrypted,omitempty"`
 	DeleteOnTermination *bool   `json:"delete_on_termination,omitempty"`
 }
 
@@ -493,6 +509,18 @@ func (_ *LaunchConfiguration) RenderTerraform(t *terraform.TerraformTarget, a, e
 				})
 			}
 		}
+
+		for _, deviceName := range sets.StringKeySet(e.BlockDeviceMappings).List() {
+			bdm := e.BlockDeviceMappings[deviceName]
+			tf.EBSBlockDevice = append(tf.EBSBlockDevice, &terraformBlockDevice{
+				DeviceName:          fi.String(deviceName),
+				VolumeType:          bdm.EbsVolumeType,
+				VolumeSize:          bdm.EbsVolumeSize,
+				Iops:                bdm.EbsVolumeIops,
+				Encrypted:           bdm.EbsEncrypted,
+				DeleteOnTermination: bdm.EbsDeleteOnTermination,
+			})
+		}
 	}
 
 	if e.UserData != nil {
@@ -515,6 +543,15 @@ func (_ *LaunchConfiguration) RenderTerraform(t *terraform.TerraformTarget, a, e
 	return t.RenderResource("aws_launch_configuration", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *LaunchConfiguration) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*LaunchConfiguration)
+	if !ok || actual == nil || actual.ID == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_launch_configuration", *e.Name, *actual.ID)
+}
+
 func (e *LaunchConfiguration) TerraformLink() *terraform.Literal {
 	return terraform.LiteralProperty("aws_launch_configuration", *e.Name, "id")
 }
@@ -549,6 +586,8 @@ type cloudformationBlockDevice struct {
 type cloudformationBlockDeviceEBS struct {
 	VolumeType          *string `json:"VolumeType,omitempty"`
 	VolumeSize          *int64  `json:"VolumeSize,omitempty"`
+	Iops                *int64  `json:"Iops,omitempty"`
+	Encrypted           *bool   `json:"Encrypted,omitempty"`
 	DeleteOnTermination *bool   `json:"DeleteOnTermination,omitempty"`
 }
 
@@ -628,6 +667,20 @@ func (_ *LaunchConfiguration) RenderCloudformation(t *cloudformation.Cloudformat
 				})
 			}
 		}
+
+		for _, deviceName := range sets.StringKeySet(e.BlockDeviceMappings).List() {
+			bdm := e.BlockDeviceMappings[deviceName]
+			cf.BlockDeviceMappings = append(cf.BlockDeviceMappings, &cloudformationBlockDevice{
+				DeviceName: fi.String(deviceName),
+				Ebs: &cloudformationBlockDeviceEBS{
+					VolumeType:          bdm.EbsVolumeType,
+					VolumeSize:          bdm.EbsVolumeSize,
+					Iops:                bdm.EbsVolumeIops,
+					Encrypted:           bdm.EbsEncrypted,
+					DeleteOnTermination: bdm.EbsDeleteOnTermination,
+				},
+			})
+		}
 	}
 
 	if e.UserData != nil {
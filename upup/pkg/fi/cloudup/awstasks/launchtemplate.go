@@ -0,0 +1,796 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+)
+
+// LaunchTemplate is an EC2 launch template; changes are applied by creating a new version and making it the default.
+// It is used by autoscaling groups when the EnableLaunchTemplates feature flag is set, and by directly managed instance groups.
+//
+//go:generate fitask -type=LaunchTemplate
+type LaunchTemplate struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	UserData *fi.ResourceHolder
+
+	ImageID            *string
+	InstanceType       *string
+	SSHKey             *SSHKey
+	SecurityGroups     []*SecurityGroup
+	AssociatePublicIP  *bool
+	IAMInstanceProfile *IAMInstanceProfile
+	InstanceMonitoring *bool
+
+	// RootVolumeSize is the size of the EBS root volume to use, in GB
+	RootVolumeSize *int64
+	// RootVolumeType is the type of the EBS root volume to use (e.g. gp2)
+	RootVolumeType *string
+	// RootVolumeIops is the number of provisioned IOPS of io1, io2 and gp3 root volumes
+	RootVolumeIops *int64
+	// RootVolumeThroughput is the throughput of gp3 root volumes, in MiB/s
+	RootVolumeThroughput *int64
+	// RootVolumeOptimization enables EBS optimization for an instance
+	RootVolumeOptimization *bool
+	// BlockDeviceMappings are the additional EBS volumes, keyed by device name
+	BlockDeviceMappings map[string]*BlockDeviceMapping
+
+	// Tenancy. Can be either default or dedicated.
+	Tenancy *string
+	// CPUCredits is the credit option for CPU usage of burstable instances (standard or unlimited)
+	CPUCredits *string
+	// PlacementGroup is the placement group instances are launched into; only used by directly managed instance groups,
+	// as autoscaling groups set the placement group themselves
+	PlacementGroup *PlacementGroup
+	// HTTPTokens is the state of token usage for instance metadata requests (optional or required)
+	HTTPTokens *string
+	// HTTPPutResponseHopLimit is the hop limit of instance metadata token responses
+	HTTPPutResponseHopLimit *int64
+
+	// Tags are the tags on the launch template itself
+	Tags map[string]string
+	// InstanceTags are applied to the instances launched from the template
+	InstanceTags map[string]string
+
+	ID *string
+}
+
+var _ fi.CompareWithID = &LaunchTemplate{}
+
+func (e *LaunchTemplate) CompareWithID() *string {
+	return e.ID
+}
+
+func (e *LaunchTemplate) Find(c *fi.Context) (*LaunchTemplate, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	lt, err := awsup.FindLaunchTemplate(cloud, fi.StringValue(e.Name))
+	if err != nil {
+		return nil, err
+	}
+	if lt == nil {
+		return nil, nil
+	}
+
+	response, params, err := awsup.DescribeLaunchTemplateVersions(cloud, &ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: lt.LaunchTemplateId,
+		Versions:         []*string{aws.String("$Default")},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing launch template %q: %v", fi.StringValue(e.Name), err)
+	}
+	if len(response.LaunchTemplateVersions) == 0 || response.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return nil, fmt.Errorf("launch template %q had no default version", fi.StringValue(e.Name))
+	}
+	data := response.LaunchTemplateVersions[0].LaunchTemplateData
+
+	glog.V(2).Infof("found existing LaunchTemplate: %q", fi.StringValue(lt.LaunchTemplateName))
+
+	actual := &LaunchTemplate{
+		Name:                   e.Name,
+		ID:                     lt.LaunchTemplateId,
+		ImageID:                data.ImageId,
+		InstanceType:           data.InstanceType,
+		RootVolumeOptimization: data.EbsOptimized,
+		Tags:                   mapEC2TagsToMap(lt.Tags),
+	}
+
+	if data.KeyName != nil {
+		actual.SSHKey = &SSHKey{Name: data.KeyName}
+	}
+	if data.IamInstanceProfile != nil {
+		actual.IAMInstanceProfile = &IAMInstanceProfile{Name: data.IamInstanceProfile.Name}
+	}
+	if data.Monitoring != nil {
+		actual.InstanceMonitoring = data.Monitoring.Enabled
+	}
+	if data.Placement != nil {
+		actual.Tenancy = data.Placement.Tenancy
+		if aws.StringValue(data.Placement.GroupName) != "" {
+			actual.PlacementGroup = &PlacementGroup{Name: data.Placement.GroupName}
+		}
+	}
+	if data.CreditSpecification != nil {
+		actual.CPUCredits = data.CreditSpecification.CpuCredits
+	}
+	if params[0].MetadataOptions != nil {
+		actual.HTTPTokens = params[0].MetadataOptions.HTTPTokens
+		actual.HTTPPutResponseHopLimit = params[0].MetadataOptions.HTTPPutResponseHopLimit
+	}
+
+	securityGroups := []*SecurityGroup{}
+	for _, ni := range data.NetworkInterfaces {
+		if aws.Int64Value(ni.DeviceIndex) != 0 {
+			continue
+		}
+		actual.AssociatePublicIP = ni.AssociatePublicIpAddress
+		for _, sgID := range ni.Groups {
+			securityGroups = append(securityGroups, &SecurityGroup{ID: sgID})
+		}
+	}
+	sort.Sort(OrderSecurityGroupsById(securityGroups))
+	actual.SecurityGroups = securityGroups
+
+	// Find the root volume, and the additional volumes
+	for _, b := range data.BlockDeviceMappings {
+		if _, found := e.BlockDeviceMappings[aws.StringValue(b.DeviceName)]; found {
+			deviceName, bdm := BlockDeviceMappingFromLaunchTemplate(b)
+			if throughput, found := params[0].VolumeThroughput[deviceName]; found {
+				bdm.EbsVolumeThroughput = fi.Int64(throughput)
+			}
+			if actual.BlockDeviceMappings == nil {
+				actual.BlockDeviceMappings = make(map[string]*BlockDeviceMapping)
+			}
+			actual.BlockDeviceMappings[deviceName] = bdm
+			continue
+		}
+		if b.Ebs == nil || b.Ebs.SnapshotId != nil {
+			// Not the root
+			continue
+		}
+		actual.RootVolumeSize = b.Ebs.VolumeSize
+		actual.RootVolumeType = b.Ebs.VolumeType
+		actual.RootVolumeIops = b.Ebs.Iops
+		if throughput, found := params[0].VolumeThroughput[aws.StringValue(b.DeviceName)]; found {
+			actual.RootVolumeThroughput = fi.Int64(throughput)
+		}
+	}
+
+	for _, ts := range data.TagSpecifications {
+		if aws.StringValue(ts.ResourceType) == ec2.ResourceTypeInstance {
+			actual.InstanceTags = mapEC2TagsToMap(ts.Tags)
+		}
+	}
+
+	if data.UserData != nil {
+		userData, err := base64.StdEncoding.DecodeString(aws.StringValue(data.UserData))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding UserData: %v", err)
+		}
+		actual.UserData = fi.WrapResource(fi.NewStringResource(string(userData)))
+	}
+
+	// Avoid spurious changes on ImageId
+	if e.ImageID != nil && actual.ImageID != nil && *actual.ImageID != *e.ImageID {
+		image, err := cloud.ResolveImage(*e.ImageID)
+		if err != nil {
+			glog.Warningf("unable to resolve image: %q: %v", *e.ImageID, err)
+		} else if image == nil {
+			glog.Warningf("unable to resolve image: %q: not found", *e.ImageID)
+		} else if aws.StringValue(image.ImageId) == *actual.ImageID {
+			glog.V(4).Infof("Returning matching ImageId as expected name: %q -> %q", *actual.ImageID, *e.ImageID)
+			actual.ImageID = e.ImageID
+		}
+	}
+
+	// Avoid spurious changes
+	actual.Lifecycle = e.Lifecycle
+
+	if e.ID == nil {
+		e.ID = actual.ID
+	}
+
+	return actual, nil
+}
+
+func (e *LaunchTemplate) Run(c *fi.Context) error {
+	e.Normalize()
+
+	c.Cloud.(awsup.AWSCloud).AddTags(e.Name, e.Tags)
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (e *LaunchTemplate) Normalize() {
+	// We need to sort our arrays consistently, so we don't get spurious changes
+	sort.Stable(OrderSecurityGroupsById(e.SecurityGroups))
+}
+
+func (s *LaunchTemplate) CheckChanges(a, e, changes *LaunchTemplate) error {
+	if e.ImageID == nil {
+		return fi.RequiredField("ImageID")
+	}
+	if e.InstanceType == nil {
+		return fi.RequiredField("InstanceType")
+	}
+	if e.Name == nil {
+		return fi.RequiredField("Name")
+	}
+	return nil
+}
+
+// buildData builds the launch template data for the expected state
+func (e *LaunchTemplate) buildData(cloud awsup.AWSCloud) (*ec2.RequestLaunchTemplateData, error) {
+	image, err := cloud.ResolveImage(fi.StringValue(e.ImageID))
+	if err != nil {
+		return nil, err
+	}
+
+	data := &ec2.RequestLaunchTemplateData{
+		ImageId:      image.ImageId,
+		InstanceType: e.InstanceType,
+		EbsOptimized: e.RootVolumeOptimization,
+		Monitoring:   &ec2.LaunchTemplatesMonitoringRequest{Enabled: fi.Bool(fi.BoolValue(e.InstanceMonitoring))},
+	}
+
+	if e.SSHKey != nil {
+		data.KeyName = e.SSHKey.Name
+	}
+	if e.Tenancy != nil || e.PlacementGroup != nil {
+		data.Placement = &ec2.LaunchTemplatePlacementRequest{Tenancy: e.Tenancy}
+		if e.PlacementGroup != nil {
+			data.Placement.GroupName = e.PlacementGroup.Name
+		}
+	}
+	if e.CPUCredits != nil {
+		data.CreditSpecification = &ec2.CreditSpecificationRequest{CpuCredits: e.CPUCredits}
+	}
+	if e.IAMInstanceProfile != nil {
+		data.IamInstanceProfile = &ec2.LaunchTemplateIamInstanceProfileSpecificationRequest{Name: e.IAMInstanceProfile.Name}
+	}
+
+	// The subnet is chosen when each instance is launched
+	ni := &ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+		DeviceIndex:              aws.Int64(0),
+		AssociatePublicIpAddress: e.AssociatePublicIP,
+		DeleteOnTermination:      aws.Bool(true),
+	}
+	for _, sg := range e.SecurityGroups {
+		ni.Groups = append(ni.Groups, sg.ID)
+	}
+	data.NetworkInterfaces = []*ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{ni}
+
+	// Build up the actual block device mappings
+	{
+		rootDevice := &BlockDeviceMapping{
+			EbsDeleteOnTermination: aws.Bool(true),
+			EbsVolumeSize:          e.RootVolumeSize,
+			EbsVolumeType:          e.RootVolumeType,
+			EbsVolumeIops:          e.RootVolumeIops,
+		}
+		data.BlockDeviceMappings = append(data.BlockDeviceMappings, rootDevice.ToLaunchTemplate(aws.StringValue(image.RootDeviceName)))
+
+		ephemeralDevices, err := buildEphemeralDevices(e.InstanceType)
+		if err != nil {
+			return nil, err
+		}
+		for device, bdm := range ephemeralDevices {
+			data.BlockDeviceMappings = append(data.BlockDeviceMappings, bdm.ToLaunchTemplate(device))
+		}
+		for device, bdm := range e.BlockDeviceMappings {
+			data.BlockDeviceMappings = append(data.BlockDeviceMappings, bdm.ToLaunchTemplate(device))
+		}
+	}
+
+	if len(e.InstanceTags) != 0 {
+		var tags []*ec2.Tag
+		for k, v := range e.InstanceTags {
+			tags = append(tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		for _, resourceType := range []string{ec2.ResourceTypeInstance, ec2.ResourceTypeVolume} {
+			data.TagSpecifications = append(data.TagSpecifications, &ec2.LaunchTemplateTagSpecificationRequest{
+				ResourceType: aws.String(resourceType),
+				Tags:         tags,
+			})
+		}
+	}
+
+	if e.UserData != nil {
+		d, err := e.UserData.AsBytes()
+		if err != nil {
+			return nil, fmt.Errorf("error rendering LaunchTemplate UserData: %v", err)
+		}
+		data.UserData = aws.String(base64.StdEncoding.EncodeToString(d))
+	}
+
+	return data, nil
+}
+
+// parameters returns the launch template parameters the SDK does not support; the root device is the first block device
+func (e *LaunchTemplate) parameters(data *ec2.RequestLaunchTemplateData) *awsup.LaunchTemplateParameters {
+	params := &awsup.LaunchTemplateParameters{
+		MetadataOptions: &awsup.InstanceMetadataOptions{
+			HTTPTokens:              e.HTTPTokens,
+			HTTPPutResponseHopLimit: e.HTTPPutResponseHopLimit,
+		},
+	}
+	throughput := make(map[string]int64)
+	if e.RootVolumeThroughput != nil && len(data.BlockDeviceMappings) != 0 {
+		throughput[aws.StringValue(data.BlockDeviceMappings[0].DeviceName)] = *e.RootVolumeThroughput
+	}
+	for device, bdm := range e.BlockDeviceMappings {
+		if bdm.EbsVolumeThroughput != nil {
+			throughput[device] = *bdm.EbsVolumeThroughput
+		}
+	}
+	if len(throughput) != 0 {
+		params.VolumeThroughput = throughput
+	}
+	return params
+}
+
+func (_ *LaunchTemplate) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *LaunchTemplate) error {
+	data, err := e.buildData(t.Cloud)
+	if err != nil {
+		return err
+	}
+
+	if a == nil {
+		glog.V(2).Infof("Creating LaunchTemplate with Name:%q", fi.StringValue(e.Name))
+		request := &ec2.CreateLaunchTemplateInput{
+			LaunchTemplateName: e.Name,
+			LaunchTemplateData: data,
+		}
+		response, err := awsup.CreateLaunchTemplate(t.Cloud, request, e.parameters(data))
+		if err != nil {
+			return fmt.Errorf("error creating LaunchTemplate: %v", err)
+		}
+		e.ID = response.LaunchTemplate.LaunchTemplateId
+	} else {
+		// Tags are applied to the template directly; anything else needs a new version
+		changes.Tags = nil
+
+		empty := &LaunchTemplate{}
+		if !reflect.DeepEqual(empty, changes) {
+			glog.V(2).Infof("Creating new version of LaunchTemplate %q", fi.StringValue(e.Name))
+			response, err := awsup.CreateLaunchTemplateVersion(t.Cloud, &ec2.CreateLaunchTemplateVersionInput{
+				LaunchTemplateId:   a.ID,
+				LaunchTemplateData: data,
+			}, e.parameters(data))
+			if err != nil {
+				return fmt.Errorf("error creating LaunchTemplate version: %v", err)
+			}
+
+			version := strconv.FormatInt(aws.Int64Value(response.LaunchTemplateVersion.VersionNumber), 10)
+			_, err = t.Cloud.EC2().ModifyLaunchTemplate(&ec2.ModifyLaunchTemplateInput{
+				LaunchTemplateId: a.ID,
+				DefaultVersion:   aws.String(version),
+			})
+			if err != nil {
+				return fmt.Errorf("error setting default version of LaunchTemplate to %s: %v", version, err)
+			}
+		}
+	}
+
+	return t.AddAWSTags(fi.StringValue(e.ID), e.Tags)
+}
+
+type terraformLaunchTemplateIAMInstanceProfile struct {
+	Name *terraform.Literal `json:"name,omitempty"`
+}
+
+type terraformLaunchTemplateNetworkInterface struct {
+	DeviceIndex              *int64               `json:"device_index"`
+	AssociatePublicIPAddress *bool                `json:"associate_public_ip_address,omitempty"`
+	DeleteOnTermination      *bool                `json:"delete_on_termination,omitempty"`
+	SecurityGroups           []*terraform.Literal `json:"security_groups,omitempty"`
+}
+
+type terraformLaunchTemplateBlockDeviceEBS struct {
+	VolumeType          *string `json:"volume_type,omitempty"`
+	VolumeSize          *int64  `json:"volume_size,omitempty"`
+	IOPS                *int64  `json:"iops,omitempty"`
+	Throughput          *int64  `json:"throughput,omitempty"`
+	Encrypted           *bool   `json:"encrypted,omitempty"`
+	DeleteOnTermination *bool   `json:"delete_on_termination,omitempty"`
+}
+
+type terraformLaunchTemplateBlockDevice struct {
+	DeviceName  *string                                `json:"device_name,omitempty"`
+	VirtualName *string                                `json:"virtual_name,omitempty"`
+	EBS         *terraformLaunchTemplateBlockDeviceEBS `json:"ebs,omitempty"`
+}
+
+type terraformLaunchTemplateMonitoring struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type terraformLaunchTemplatePlacement struct {
+	Tenancy   *string            `json:"tenancy,omitempty"`
+	GroupName *terraform.Literal `json:"group_name,omitempty"`
+}
+
+type terraformLaunchTemplateCreditSpecification struct {
+	CPUCredits *string `json:"cpu_credits,omitempty"`
+}
+
+type terraformLaunchTemplateMetadataOptions struct {
+	HTTPEndpoint            *string `json:"http_endpoint,omitempty"`
+	HTTPTokens              *string `json:"http_tokens,omitempty"`
+	HTTPPutResponseHopLimit *int64  `json:"http_put_response_hop_limit,omitempty"`
+}
+
+type terraformLaunchTemplateTagSpecification struct {
+	ResourceType *string           `json:"resource_type,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+type terraformLaunchTemplate struct {
+	Name                *string                                     `json:"name,omitempty"`
+	ImageID             *string                                     `json:"image_id,omitempty"`
+	InstanceType        *string                                     `json:"instance_type,omitempty"`
+	KeyName             *terraform.Literal                          `json:"key_name,omitempty"`
+	EBSOptimized        *bool                                       `json:"ebs_optimized,omitempty"`
+	IAMInstanceProfile  *terraformLaunchTemplateIAMInstanceProfile  `json:"iam_instance_profile,omitempty"`
+	NetworkInterfaces   []*terraformLaunchTemplateNetworkInterface  `json:"network_interfaces,omitempty"`
+	BlockDeviceMappings []*terraformLaunchTemplateBlockDevice       `json:"block_device_mappings,omitempty"`
+	Monitoring          *terraformLaunchTemplateMonitoring          `json:"monitoring,omitempty"`
+	Placement           *terraformLaunchTemplatePlacement           `json:"placement,omitempty"`
+	CreditSpecification *terraformLaunchTemplateCreditSpecification `json:"credit_specification,omitempty"`
+	MetadataOptions     *terraformLaunchTemplateMetadataOptions     `json:"metadata_options,omitempty"`
+	TagSpecifications   []*terraformLaunchTemplateTagSpecification  `json:"tag_specifications,omitempty"`
+	Tags                map[string]string                           `json:"tags,omitempty"`
+	UserData            *terraform.Literal                          `json:"user_data,omitempty"`
+	Lifecycle           *terraform.Lifecycle                        `json:"lifecycle,omitempty"`
+}
+
+func (_ *LaunchTemplate) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *LaunchTemplate) error {
+	cloud := t.Cloud.(awsup.AWSCloud)
+
+	image, err := cloud.ResolveImage(fi.StringValue(e.ImageID))
+	if err != nil {
+		return err
+	}
+
+	tf := &terraformLaunchTemplate{
+		Name:         e.Name,
+		ImageID:      image.ImageId,
+		InstanceType: e.InstanceType,
+		EBSOptimized: e.RootVolumeOptimization,
+		Monitoring:   &terraformLaunchTemplateMonitoring{Enabled: fi.Bool(fi.BoolValue(e.InstanceMonitoring))},
+		Tags:         e.Tags,
+	}
+
+	if e.SSHKey != nil {
+		tf.KeyName = e.SSHKey.TerraformLink()
+	}
+	if e.IAMInstanceProfile != nil {
+		tf.IAMInstanceProfile = &terraformLaunchTemplateIAMInstanceProfile{Name: e.IAMInstanceProfile.TerraformLink()}
+	}
+	if e.Tenancy != nil || e.PlacementGroup != nil {
+		tf.Placement = &terraformLaunchTemplatePlacement{Tenancy: e.Tenancy}
+		if e.PlacementGroup != nil {
+			tf.Placement.GroupName = e.PlacementGroup.TerraformLink()
+		}
+	}
+	if e.CPUCredits != nil {
+		tf.CreditSpecification = &terraformLaunchTemplateCreditSpecification{CPUCredits: e.CPUCredits}
+	}
+	if e.HTTPTokens != nil || e.HTTPPutResponseHopLimit != nil {
+		tf.MetadataOptions = &terraformLaunchTemplateMetadataOptions{
+			HTTPEndpoint:            fi.String("enabled"),
+			HTTPTokens:              e.HTTPTokens,
+			HTTPPutResponseHopLimit: e.HTTPPutResponseHopLimit,
+		}
+	}
+
+	ni := &terraformLaunchTemplateNetworkInterface{
+		DeviceIndex:              fi.Int64(0),
+		AssociatePublicIPAddress: e.AssociatePublicIP,
+		DeleteOnTermination:      fi.Bool(true),
+	}
+	for _, sg := range e.SecurityGroups {
+		ni.SecurityGroups = append(ni.SecurityGroups, sg.TerraformLink())
+	}
+	tf.NetworkInterfaces = []*terraformLaunchTemplateNetworkInterface{ni}
+
+	{
+		tf.BlockDeviceMappings = append(tf.BlockDeviceMappings, &terraformLaunchTemplateBlockDevice{
+			DeviceName: image.RootDeviceName,
+			EBS: &terraformLaunchTemplateBlockDeviceEBS{
+				VolumeType:          e.RootVolumeType,
+				VolumeSize:          e.RootVolumeSize,
+				IOPS:                e.RootVolumeIops,
+				Throughput:          e.RootVolumeThroughput,
+				DeleteOnTermination: fi.Bool(true),
+			},
+		})
+
+		ephemeralDevices, err := buildEphemeralDevices(e.InstanceType)
+		if err != nil {
+			return err
+		}
+		for _, deviceName := range sets.StringKeySet(ephemeralDevices).List() {
+			tf.BlockDeviceMappings = append(tf.BlockDeviceMappings, &terraformLaunchTemplateBlockDevice{
+				DeviceName:  fi.String(deviceName),
+				VirtualName: ephemeralDevices[deviceName].VirtualName,
+			})
+		}
+
+		for _, deviceName := range sets.StringKeySet(e.BlockDeviceMappings).List() {
+			bdm := e.BlockDeviceMappings[deviceName]
+			tf.BlockDeviceMappings = append(tf.BlockDeviceMappings, &terraformLaunchTemplateBlockDevice{
+				DeviceName: fi.String(deviceName),
+				EBS: &terraformLaunchTemplateBlockDeviceEBS{
+					VolumeType:          bdm.EbsVolumeType,
+					VolumeSize:          bdm.EbsVolumeSize,
+					IOPS:                bdm.EbsVolumeIops,
+					Throughput:          bdm.EbsVolumeThroughput,
+					Encrypted:           bdm.EbsEncrypted,
+					DeleteOnTermination: bdm.EbsDeleteOnTermination,
+				},
+			})
+		}
+	}
+
+	if len(e.InstanceTags) != 0 {
+		for _, resourceType := range []string{ec2.ResourceTypeInstance, ec2.ResourceTypeVolume} {
+			tf.TagSpecifications = append(tf.TagSpecifications, &terraformLaunchTemplateTagSpecification{
+				ResourceType: fi.String(resourceType),
+				Tags:         e.InstanceTags,
+			})
+		}
+	}
+
+	if e.UserData != nil {
+		tf.UserData, err = t.AddFileBase64("aws_launch_template", *e.Name, "user_data", e.UserData)
+		if err != nil {
+			return err
+		}
+	}
+
+	tf.Lifecycle = &terraform.Lifecycle{CreateBeforeDestroy: fi.Bool(true)}
+
+	return t.RenderResource("aws_launch_template", *e.Name, tf)
+}
+
+func (e *LaunchTemplate) TerraformLink() *terraform.Literal {
+	return terraform.LiteralProperty("aws_launch_template", *e.Name, "id")
+}
+
+// TerraformVersionLink returns a reference to the latest version of the launch template
+func (e *LaunchTemplate) TerraformVersionLink() *terraform.Literal {
+	return terraform.LiteralProperty("aws_launch_template", *e.Name, "latest_version")
+}
+
+type cloudformationLaunchTemplateIAMInstanceProfile struct {
+	Name *cloudformation.Literal `json:"Name,omitempty"`
+}
+
+type cloudformationLaunchTemplateNetworkInterface struct {
+	DeviceIndex              *int64                    `json:"DeviceIndex"`
+	AssociatePublicIPAddress *bool                     `json:"AssociatePublicIpAddress,omitempty"`
+	DeleteOnTermination      *bool                     `json:"DeleteOnTermination,omitempty"`
+	Groups                   []*cloudformation.Literal `json:"Groups,omitempty"`
+}
+
+type cloudformationLaunchTemplateBlockDeviceEBS struct {
+	VolumeType          *string `json:"VolumeType,omitempty"`
+	VolumeSize          *int64  `json:"VolumeSize,omitempty"`
+	IOPS                *int64  `json:"Iops,omitempty"`
+	Throughput          *int64  `json:"Throughput,omitempty"`
+	Encrypted           *bool   `json:"Encrypted,omitempty"`
+	DeleteOnTermination *bool   `json:"DeleteOnTermination,omitempty"`
+}
+
+type cloudformationLaunchTemplateBlockDevice struct {
+	DeviceName  *string                                     `json:"DeviceName,omitempty"`
+	VirtualName *string                                     `json:"VirtualName,omitempty"`
+	EBS         *cloudformationLaunchTemplateBlockDeviceEBS `json:"Ebs,omitempty"`
+}
+
+type cloudformationLaunchTemplateMonitoring struct {
+	Enabled *bool `json:"Enabled,omitempty"`
+}
+
+type cloudformationLaunchTemplatePlacement struct {
+	Tenancy   *string                 `json:"Tenancy,omitempty"`
+	GroupName *cloudformation.Literal `json:"GroupName,omitempty"`
+}
+
+type cloudformationLaunchTemplateCreditSpecification struct {
+	CPUCredits *string `json:"CpuCredits,omitempty"`
+}
+
+type cloudformationLaunchTemplateMetadataOptions struct {
+	HTTPEndpoint            *string `json:"HttpEndpoint,omitempty"`
+	HTTPTokens              *string `json:"HttpTokens,omitempty"`
+	HTTPPutResponseHopLimit *int64  `json:"HttpPutResponseHopLimit,omitempty"`
+}
+
+type cloudformationLaunchTemplateTag struct {
+	Key   *string `json:"Key"`
+	Value *string `json:"Value"`
+}
+
+type cloudformationLaunchTemplateTagSpecification struct {
+	ResourceType *string                            `json:"ResourceType,omitempty"`
+	Tags         []*cloudformationLaunchTemplateTag `json:"Tags,omitempty"`
+}
+
+type cloudformationLaunchTemplateData struct {
+	ImageID             *string                                          `json:"ImageId,omitempty"`
+	InstanceType        *string                                          `json:"InstanceType,omitempty"`
+	KeyName             *string                                          `json:"KeyName,omitempty"`
+	EBSOptimized        *bool                                            `json:"EbsOptimized,omitempty"`
+	IAMInstanceProfile  *cloudformationLaunchTemplateIAMInstanceProfile  `json:"IamInstanceProfile,omitempty"`
+	NetworkInterfaces   []*cloudformationLaunchTemplateNetworkInterface  `json:"NetworkInterfaces,omitempty"`
+	BlockDeviceMappings []*cloudformationLaunchTemplateBlockDevice       `json:"BlockDeviceMappings,omitempty"`
+	Monitoring          *cloudformationLaunchTemplateMonitoring          `json:"Monitoring,omitempty"`
+	Placement           *cloudformationLaunchTemplatePlacement           `json:"Placement,omitempty"`
+	CreditSpecification *cloudformationLaunchTemplateCreditSpecification `json:"CreditSpecification,omitempty"`
+	MetadataOptions     *cloudformationLaunchTemplateMetadataOptions     `json:"MetadataOptions,omitempty"`
+	TagSpecifications   []*cloudformationLaunchTemplateTagSpecification  `json:"TagSpecifications,omitempty"`
+	UserData            *string                                          `json:"UserData,omitempty"`
+}
+
+type cloudformationLaunchTemplate struct {
+	LaunchTemplateName *string                           `json:"LaunchTemplateName,omitempty"`
+	LaunchTemplateData *cloudformationLaunchTemplateData `json:"LaunchTemplateData,omitempty"`
+}
+
+func (_ *LaunchTemplate) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *LaunchTemplate) error {
+	cloud := t.Cloud.(awsup.AWSCloud)
+
+	image, err := cloud.ResolveImage(fi.StringValue(e.ImageID))
+	if err != nil {
+		return err
+	}
+
+	data := &cloudformationLaunchTemplateData{
+		ImageID:      image.ImageId,
+		InstanceType: e.InstanceType,
+		EBSOptimized: e.RootVolumeOptimization,
+		Monitoring:   &cloudformationLaunchTemplateMonitoring{Enabled: fi.Bool(fi.BoolValue(e.InstanceMonitoring))},
+	}
+
+	if e.SSHKey != nil {
+		if e.SSHKey.Name == nil {
+			return fmt.Errorf("SSHKey Name not set")
+		}
+		data.KeyName = e.SSHKey.Name
+	}
+	if e.IAMInstanceProfile != nil {
+		data.IAMInstanceProfile = &cloudformationLaunchTemplateIAMInstanceProfile{Name: e.IAMInstanceProfile.CloudformationLink()}
+	}
+	if e.Tenancy != nil || e.PlacementGroup != nil {
+		data.Placement = &cloudformationLaunchTemplatePlacement{Tenancy: e.Tenancy}
+		if e.PlacementGroup != nil {
+			data.Placement.GroupName = e.PlacementGroup.CloudformationLink()
+		}
+	}
+	if e.CPUCredits != nil {
+		data.CreditSpecification = &cloudformationLaunchTemplateCreditSpecification{CPUCredits: e.CPUCredits}
+	}
+	if e.HTTPTokens != nil || e.HTTPPutResponseHopLimit != nil {
+		data.MetadataOptions = &cloudformationLaunchTemplateMetadataOptions{
+			HTTPEndpoint:            fi.String("enabled"),
+			HTTPTokens:              e.HTTPTokens,
+			HTTPPutResponseHopLimit: e.HTTPPutResponseHopLimit,
+		}
+	}
+
+	ni := &cloudformationLaunchTemplateNetworkInterface{
+		DeviceIndex:              fi.Int64(0),
+		AssociatePublicIPAddress: e.AssociatePublicIP,
+		DeleteOnTermination:      fi.Bool(true),
+	}
+	for _, sg := range e.SecurityGroups {
+		ni.Groups = append(ni.Groups, sg.CloudformationLink())
+	}
+	data.NetworkInterfaces = []*cloudformationLaunchTemplateNetworkInterface{ni}
+
+	{
+		data.BlockDeviceMappings = append(data.BlockDeviceMappings, &cloudformationLaunchTemplateBlockDevice{
+			DeviceName: image.RootDeviceName,
+			EBS: &cloudformationLaunchTemplateBlockDeviceEBS{
+				VolumeType:          e.RootVolumeType,
+				VolumeSize:          e.RootVolumeSize,
+				IOPS:                e.RootVolumeIops,
+				Throughput:          e.RootVolumeThroughput,
+				DeleteOnTermination: fi.Bool(true),
+			},
+		})
+
+		ephemeralDevices, err := buildEphemeralDevices(e.InstanceType)
+		if err != nil {
+			return err
+		}
+		for _, deviceName := range sets.StringKeySet(ephemeralDevices).List() {
+			data.BlockDeviceMappings = append(data.BlockDeviceMappings, &cloudformationLaunchTemplateBlockDevice{
+				DeviceName:  fi.String(deviceName),
+				VirtualName: ephemeralDevices[deviceName].VirtualName,
+			})
+		}
+
+		for _, deviceName := range sets.StringKeySet(e.BlockDeviceMappings).List() {
+			bdm := e.BlockDeviceMappings[deviceName]
+			data.BlockDeviceMappings = append(data.BlockDeviceMappings, &cloudformationLaunchTemplateBlockDevice{
+				DeviceName: fi.String(deviceName),
+				EBS: &cloudformationLaunchTemplateBlockDeviceEBS{
+					VolumeType:          bdm.EbsVolumeType,
+					VolumeSize:          bdm.EbsVolumeSize,
+					IOPS:                bdm.EbsVolumeIops,
+					Throughput:          bdm.EbsVolumeThroughput,
+					Encrypted:           bdm.EbsEncrypted,
+					DeleteOnTermination: bdm.EbsDeleteOnTermination,
+				},
+			})
+		}
+	}
+
+	if len(e.InstanceTags) != 0 {
+		var tags []*cloudformationLaunchTemplateTag
+		for _, k := range sets.StringKeySet(e.InstanceTags).List() {
+			tags = append(tags, &cloudformationLaunchTemplateTag{Key: fi.String(k), Value: fi.String(e.InstanceTags[k])})
+		}
+		for _, resourceType := range []string{ec2.ResourceTypeInstance, ec2.ResourceTypeVolume} {
+			data.TagSpecifications = append(data.TagSpecifications, &cloudformationLaunchTemplateTagSpecification{
+				ResourceType: fi.String(resourceType),
+				Tags:         tags,
+			})
+		}
+	}
+
+	if e.UserData != nil {
+		d, err := e.UserData.AsBytes()
+		if err != nil {
+			return fmt.Errorf("error rendering LaunchTemplate UserData: %v", err)
+		}
+		data.UserData = aws.String(base64.StdEncoding.EncodeToString(d))
+	}
+
+	cf := &cloudformationLaunchTemplate{
+		LaunchTemplateName: e.Name,
+		LaunchTemplateData: data,
+	}
+
+	return t.RenderResource("AWS::EC2::LaunchTemplate", *e.Name, cf)
+}
+
+func (e *LaunchTemplate) CloudformationLink() *cloudformation.Literal {
+	return cloudformation.Ref("AWS::EC2::LaunchTemplate", *e.Name)
+}
+
+// CloudformationVersionLink returns a reference to the latest version of the launch template
+func (e *LaunchTemplate) CloudformationVersionLink() *cloudformation.Literal {
+	return cloudformation.GetAtt("AWS::EC2::LaunchTemplate", *e.Name, "LatestVersionNumber")
+}
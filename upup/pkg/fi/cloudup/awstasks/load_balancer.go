@@ -742,6 +742,15 @@ func (_ *LoadBalancer) RenderTerraform(t *terraform.TerraformTarget, a, e, chang
 	return t.RenderResource("aws_elb", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *LoadBalancer) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*LoadBalancer)
+	if !ok || actual == nil || actual.LoadBalancerName == nil {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_elb", *e.Name, *actual.LoadBalancerName)
+}
+
 func (e *LoadBalancer) TerraformLink(params ...string) *terraform.Literal {
 	prop := "id"
 	if len(params) > 0 {
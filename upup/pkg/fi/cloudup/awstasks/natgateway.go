@@ -384,6 +384,15 @@ func (_ *NatGateway) RenderTerraform(t *terraform.TerraformTarget, a, e, changes
 	return t.RenderResource("aws_nat_gateway", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *NatGateway) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*NatGateway)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_nat_gateway", *e.Name, *actual.ID)
+}
+
 func (e *NatGateway) TerraformLink() *terraform.Literal {
 	if fi.BoolValue(e.Shared) {
 		if e.ID == nil {
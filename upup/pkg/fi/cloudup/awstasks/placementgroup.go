@@ -0,0 +1,146 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+)
+
+// PlacementGroup is an EC2 placement group; placement groups cannot be modified once created
+//
+//go:generate fitask -type=PlacementGroup
+type PlacementGroup struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	// Strategy is cluster, spread or partition
+	Strategy *string
+	// PartitionCount is the number of partitions of a partition placement group
+	PartitionCount *int64
+}
+
+var _ fi.CompareWithID = &PlacementGroup{}
+
+func (e *PlacementGroup) CompareWithID() *string {
+	return e.Name
+}
+
+func (e *PlacementGroup) Find(c *fi.Context) (*PlacementGroup, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	pg, err := awsup.FindPlacementGroup(cloud, fi.StringValue(e.Name))
+	if err != nil {
+		return nil, err
+	}
+	if pg == nil {
+		return nil, nil
+	}
+
+	glog.V(2).Infof("found existing PlacementGroup: %q", aws.StringValue(pg.GroupName))
+
+	actual := &PlacementGroup{
+		Name:     pg.GroupName,
+		Strategy: pg.Strategy,
+
+		// The vendored aws-sdk-go cannot describe the partition count
+		PartitionCount: e.PartitionCount,
+
+		// Avoid spurious changes
+		Lifecycle: e.Lifecycle,
+	}
+
+	return actual, nil
+}
+
+func (e *PlacementGroup) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *PlacementGroup) CheckChanges(a, e, changes *PlacementGroup) error {
+	if e.Strategy == nil {
+		return fi.RequiredField("Strategy")
+	}
+	if a != nil && changes.Strategy != nil {
+		return fi.CannotChangeField("Strategy")
+	}
+	return nil
+}
+
+func (_ *PlacementGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *PlacementGroup) error {
+	if a == nil {
+		glog.V(2).Infof("Creating PlacementGroup with Name:%q", fi.StringValue(e.Name))
+		var partitionCount *int64
+		if fi.StringValue(e.Strategy) == awsup.PlacementStrategyPartition {
+			partitionCount = e.PartitionCount
+		}
+		if err := awsup.CreatePlacementGroup(t.Cloud, fi.StringValue(e.Name), fi.StringValue(e.Strategy), partitionCount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type terraformPlacementGroup struct {
+	Name           *string `json:"name,omitempty"`
+	Strategy       *string `json:"strategy,omitempty"`
+	PartitionCount *int64  `json:"partition_count,omitempty"`
+}
+
+func (_ *PlacementGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *PlacementGroup) error {
+	tf := &terraformPlacementGroup{
+		Name:     e.Name,
+		Strategy: e.Strategy,
+	}
+	if fi.StringValue(e.Strategy) == awsup.PlacementStrategyPartition {
+		tf.PartitionCount = e.PartitionCount
+	}
+
+	return t.RenderResource("aws_placement_group", *e.Name, tf)
+}
+
+func (e *PlacementGroup) TerraformLink() *terraform.Literal {
+	return terraform.LiteralProperty("aws_placement_group", *e.Name, "id")
+}
+
+type cloudformationPlacementGroup struct {
+	Strategy *string `json:"Strategy,omitempty"`
+}
+
+func (_ *PlacementGroup) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *PlacementGroup) error {
+	if fi.StringValue(e.Strategy) == awsup.PlacementStrategyPartition {
+		return fmt.Errorf("partition placement groups are not supported with cloudformation")
+	}
+
+	// CloudFormation names placement groups itself
+	cf := &cloudformationPlacementGroup{
+		Strategy: e.Strategy,
+	}
+
+	return t.RenderResource("AWS::EC2::PlacementGroup", *e.Name, cf)
+}
+
+func (e *PlacementGroup) CloudformationLink() *cloudformation.Literal {
+	return cloudformation.Ref("AWS::EC2::PlacementGroup", *e.Name)
+}
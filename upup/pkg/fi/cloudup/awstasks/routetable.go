@@ -211,6 +211,15 @@ func (_ *RouteTable) RenderTerraform(t *terraform.TerraformTarget, a, e, changes
 	return t.RenderResource("aws_route_table", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *RouteTable) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*RouteTable)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_route_table", *e.Name, *actual.ID)
+}
+
 func (e *RouteTable) TerraformLink() *terraform.Literal {
 	return terraform.LiteralProperty("aws_route_table", *e.Name, "id")
 }
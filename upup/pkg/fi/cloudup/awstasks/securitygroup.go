@@ -87,6 +87,12 @@ func (e *SecurityGroup) Find(c *fi.Context) (*SecurityGroup, error) {
 	// Prevent spurious comparison failures
 	actual.Shared = e.Shared
 	actual.Lifecycle = e.Lifecycle
+	if fi.BoolValue(e.Shared) {
+		// We refer to shared security groups by ID, and don't manage their name, description or tags
+		actual.Name = e.Name
+		actual.Description = e.Description
+		actual.Tags = e.Tags
+	}
 	if e.ID == nil {
 		e.ID = actual.ID
 	}
@@ -210,6 +216,15 @@ func (_ *SecurityGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, chan
 	return t.RenderResource("aws_security_group", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *SecurityGroup) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*SecurityGroup)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_security_group", *e.Name, *actual.ID)
+}
+
 func (e *SecurityGroup) TerraformLink() *terraform.Literal {
 	shared := fi.BoolValue(e.Shared)
 	if shared {
@@ -249,6 +249,15 @@ func (_ *Subnet) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Su
 	return t.RenderResource("aws_subnet", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *Subnet) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*Subnet)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_subnet", *e.Name, *actual.ID)
+}
+
 func (e *Subnet) TerraformLink() *terraform.Literal {
 	shared := fi.BoolValue(e.Shared)
 	if shared {
@@ -227,6 +227,15 @@ func (_ *VPC) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *VPC)
 	return t.RenderResource("aws_vpc", *e.Name, tf)
 }
 
+// TerraformImport implements terraform.Importable
+func (e *VPC) TerraformImport(a fi.Task) *terraform.ImportCommand {
+	actual, ok := a.(*VPC)
+	if !ok || actual == nil || actual.ID == nil || fi.BoolValue(e.Shared) {
+		return nil
+	}
+	return terraform.NewImportCommand("aws_vpc", *e.Name, *actual.ID)
+}
+
 func (e *VPC) TerraformLink() *terraform.Literal {
 	shared := fi.BoolValue(e.Shared)
 	if shared {
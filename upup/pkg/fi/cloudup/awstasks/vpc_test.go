@@ -128,3 +128,25 @@ func Test4758(t *testing.T) {
 		t.Errorf("unexpected changes: +%v", changes)
 	}
 }
+
+func TestVPCTerraformImport(t *testing.T) {
+	e := &VPC{Name: s("minimal.example.com")}
+
+	if c := e.TerraformImport(&VPC{Name: s("minimal.example.com")}); c != nil {
+		t.Errorf("expected no import command for a VPC without an ID, got %q", c)
+	}
+
+	c := e.TerraformImport(&VPC{Name: s("minimal.example.com"), ID: s("vpc-12345678")})
+	if c == nil {
+		t.Fatalf("expected import command")
+	}
+	expected := "terraform import aws_vpc.minimal-example-com vpc-12345678"
+	if c.String() != expected {
+		t.Errorf("unexpected import command %q, expected %q", c.String(), expected)
+	}
+
+	e.Shared = fi.Bool(true)
+	if c := e.TerraformImport(&VPC{Name: s("minimal.example.com"), ID: s("vpc-12345678")}); c != nil {
+		t.Errorf("expected no import command for a shared VPC, got %q", c)
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	// DefaultAssumeRoleSessionName is the name of the role sessions kops starts, unless another is configured
+	DefaultAssumeRoleSessionName = "kops"
+
+	// assumeRoleDuration is how long assumed role credentials are valid for; this is the maximum for role chaining
+	assumeRoleDuration = 1 * time.Hour
+	// assumeRoleExpiryWindow is how long before they expire that credentials are refreshed
+	assumeRoleExpiryWindow = 5 * time.Minute
+)
+
+// AssumeRole is an IAM role that kops assumes with STS for its AWS API calls
+type AssumeRole struct {
+	RoleARN     string
+	ExternalID  string
+	SessionName string
+	SessionTags map[string]string
+}
+
+// key identifies the role in the cache of clouds
+func (r *AssumeRole) key() string {
+	return r.RoleARN + "/" + r.ExternalID + "/" + r.SessionName + "/" + sessionTagParameters(r.SessionTags).Encode()
+}
+
+var (
+	// defaultAssumeRoleMutex guards defaultAssumeRole
+	defaultAssumeRoleMutex sync.Mutex
+	// defaultAssumeRole is the role set for this process, e.g. with --aws-assume-role-arn
+	defaultAssumeRole *AssumeRole
+)
+
+// SetDefaultAssumeRole sets the role assumed by the AWS clouds built from now on. It takes precedence over the role
+// in the cluster spec; nil assumes no role.
+func SetDefaultAssumeRole(role *AssumeRole) {
+	defaultAssumeRoleMutex.Lock()
+	defer defaultAssumeRoleMutex.Unlock()
+	defaultAssumeRole = role
+}
+
+// DefaultAssumeRole returns the role set with SetDefaultAssumeRole, or nil
+func DefaultAssumeRole() *AssumeRole {
+	defaultAssumeRoleMutex.Lock()
+	defer defaultAssumeRoleMutex.Unlock()
+	return defaultAssumeRole
+}
+
+// AssumeRoleForCluster returns the role to assume for the AWS API calls of a cluster: the default role if one is
+// set, otherwise cloudConfig.awsAssumeRole, or nil to use the credentials of the environment
+func AssumeRoleForCluster(cluster *kops.Cluster) *AssumeRole {
+	if role := DefaultAssumeRole(); role != nil {
+		return role
+	}
+	if cluster.Spec.CloudConfig == nil {
+		return nil
+	}
+	return assumeRoleFromSpec(cluster.Spec.CloudConfig.AWSAssumeRole)
+}
+
+// DNSAssumeRoleForCluster returns the role to assume for the Route53 API calls of a cluster, from
+// cloudConfig.awsDNSAssumeRole, or nil to use the same credentials as for the other AWS API calls
+func DNSAssumeRoleForCluster(cluster *kops.Cluster) *AssumeRole {
+	if cluster.Spec.CloudConfig == nil {
+		return nil
+	}
+	return assumeRoleFromSpec(cluster.Spec.CloudConfig.AWSDNSAssumeRole)
+}
+
+func assumeRoleFromSpec(spec *kops.AWSAssumeRoleSpec) *AssumeRole {
+	if spec == nil || spec.RoleARN == "" {
+		return nil
+	}
+	return &AssumeRole{
+		RoleARN:     spec.RoleARN,
+		ExternalID:  spec.ExternalID,
+		SessionName: spec.SessionName,
+		SessionTags: spec.SessionTags,
+	}
+}
+
+// assumeRoleProvider is a credentials provider that assumes a role with STS, refreshing the credentials before
+// they expire. Unlike the provider of the SDK, it supports session tags.
+type assumeRoleProvider struct {
+	credentials.Expiry
+
+	client *sts.STS
+	role   *AssumeRole
+}
+
+var _ credentials.Provider = &assumeRoleProvider{}
+
+// newAssumeRoleCredentials returns credentials for the role, assumed with the credentials of config
+func newAssumeRoleCredentials(config *aws.Config, role *AssumeRole) (*credentials.Credentials, error) {
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error starting a new AWS session: %v", err)
+	}
+	return credentials.NewCredentials(&assumeRoleProvider{
+		client: sts.New(sess, config),
+		role:   role,
+	}), nil
+}
+
+func (p *assumeRoleProvider) Retrieve() (credentials.Value, error) {
+	sessionName := p.role.SessionName
+	if sessionName == "" {
+		sessionName = DefaultAssumeRoleSessionName
+	}
+
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(p.role.RoleARN),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int64(int64(assumeRoleDuration / time.Second)),
+	}
+	if p.role.ExternalID != "" {
+		input.ExternalId = aws.String(p.role.ExternalID)
+	}
+
+	glog.V(2).Infof("assuming role %q", p.role.RoleARN)
+
+	// The vendored aws-sdk-go predates session tags, so they are added to the query parameters
+	req, out := p.client.AssumeRoleRequest(input)
+	addQueryParameters(req, sessionTagParameters(p.role.SessionTags))
+	if err := req.Send(); err != nil {
+		return credentials.Value{}, fmt.Errorf("error assuming role %q: %v", p.role.RoleARN, err)
+	}
+
+	if out.Credentials == nil {
+		return credentials.Value{}, fmt.Errorf("no credentials returned when assuming role %q", p.role.RoleARN)
+	}
+	p.SetExpiration(aws.TimeValue(out.Credentials.Expiration), assumeRoleExpiryWindow)
+
+	return credentials.Value{
+		AccessKeyID:     aws.StringValue(out.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(out.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(out.Credentials.SessionToken),
+		ProviderName:    "KopsAssumeRoleProvider",
+	}, nil
+}
+
+// sessionTagParameters returns the AssumeRole query parameters for the session tags, ordered by key
+func sessionTagParameters(tags map[string]string) url.Values {
+	values := url.Values{}
+
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		values.Set(fmt.Sprintf("Tags.member.%d.Key", i+1), k)
+		values.Set(fmt.Sprintf("Tags.member.%d.Value", i+1), tags[k])
+	}
+	return values
+}
+
+// ParseSessionTags parses session tags given as key=value strings, e.g. on the command line
+func ParseSessionTags(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	tags := make(map[string]string)
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("session tag %q must be of the form key=value", arg)
+		}
+		tags[kv[0]] = kv[1]
+	}
+	return tags, nil
+}
+
+// CallerARN returns the ARN of the identity that the API calls of the cloud are made as; when kops assumes a role,
+// this is the ARN of the assumed role session
+func CallerARN(c AWSCloud) (string, error) {
+	impl, ok := c.(*awsCloudImplementation)
+	if !ok {
+		return "", fmt.Errorf("caller identity is not supported for %T", c)
+	}
+
+	sess, err := session.NewSession(impl.config)
+	if err != nil {
+		return "", fmt.Errorf("error starting a new AWS session: %v", err)
+	}
+	response, err := sts.New(sess, impl.config).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("error getting caller identity: %v", err)
+	}
+	return aws.StringValue(response.Arn), nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestAssumeRoleProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("error reading request: %v", err)
+		}
+		params, err := url.ParseQuery(string(body))
+		if err != nil {
+			t.Fatalf("error parsing request: %v", err)
+		}
+
+		expected := map[string]string{
+			"Action":              "AssumeRole",
+			"RoleArn":             "arn:aws:iam::123456789012:role/KopsAdmin",
+			"RoleSessionName":     "kops",
+			"ExternalId":          "tooling",
+			"Tags.member.1.Key":   "cost-center",
+			"Tags.member.1.Value": "1234",
+			"Tags.member.2.Key":   "team",
+			"Tags.member.2.Value": "platform",
+		}
+		for k, v := range expected {
+			if params.Get(k) != v {
+				t.Errorf("unexpected %s: expected %q, got %q", k, v, params.Get(k))
+			}
+		}
+
+		fmt.Fprint(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIAEXAMPLE</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`)
+	}))
+	defer server.Close()
+
+	config := aws.NewConfig().
+		WithRegion("us-east-1").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", ""))
+	sess, err := session.NewSession(config)
+	if err != nil {
+		t.Fatalf("error starting session: %v", err)
+	}
+
+	provider := &assumeRoleProvider{
+		client: sts.New(sess, config),
+		role: &AssumeRole{
+			RoleARN:     "arn:aws:iam::123456789012:role/KopsAdmin",
+			ExternalID:  "tooling",
+			SessionTags: map[string]string{"team": "platform", "cost-center": "1234"},
+		},
+	}
+
+	value, err := provider.Retrieve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value.AccessKeyID != "ASIAEXAMPLE" || value.SecretAccessKey != "secret" || value.SessionToken != "token" {
+		t.Errorf("unexpected credentials %+v", value)
+	}
+	if provider.IsExpired() {
+		t.Errorf("expected credentials to be valid")
+	}
+}
+
+func TestAssumeRoleForCluster(t *testing.T) {
+	cluster := &kops.Cluster{}
+	if role := AssumeRoleForCluster(cluster); role != nil {
+		t.Errorf("expected no role, got %+v", role)
+	}
+
+	cluster.Spec.CloudConfig = &kops.CloudConfiguration{
+		AWSAssumeRole: &kops.AWSAssumeRoleSpec{RoleARN: "arn:aws:iam::123456789012:role/Cluster"},
+	}
+	if role := AssumeRoleForCluster(cluster); role == nil || role.RoleARN != "arn:aws:iam::123456789012:role/Cluster" {
+		t.Errorf("expected the role of the cluster, got %+v", role)
+	}
+
+	SetDefaultAssumeRole(&AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/Flag"})
+	defer SetDefaultAssumeRole(nil)
+	if role := AssumeRoleForCluster(cluster); role == nil || role.RoleARN != "arn:aws:iam::123456789012:role/Flag" {
+		t.Errorf("expected the default role, got %+v", role)
+	}
+
+	// The default role is not used for DNS
+	if role := DNSAssumeRoleForCluster(cluster); role != nil {
+		t.Errorf("expected no DNS role, got %+v", role)
+	}
+	cluster.Spec.CloudConfig.AWSDNSAssumeRole = &kops.AWSAssumeRoleSpec{RoleARN: "arn:aws:iam::210987654321:role/DNS"}
+	if role := DNSAssumeRoleForCluster(cluster); role == nil || role.RoleARN != "arn:aws:iam::210987654321:role/DNS" {
+		t.Errorf("expected the DNS role of the cluster, got %+v", role)
+	}
+}
+
+func TestParseSessionTags(t *testing.T) {
+	tags, err := ParseSessionTags([]string{"team=platform", "empty="})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags["team"] != "platform" || tags["empty"] != "" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	if _, err := ParseSessionTags([]string{"team"}); err == nil {
+		t.Errorf("expected an error for a tag without a value")
+	}
+}
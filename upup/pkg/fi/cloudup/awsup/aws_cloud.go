@@ -18,6 +18,8 @@ package awsup
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -48,7 +50,10 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/discoverycache"
+	"k8s.io/kops/pkg/retrypolicy"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/slice"
 	k8s_aws "k8s.io/kubernetes/pkg/cloudprovider/providers/aws"
 )
 
@@ -76,12 +81,21 @@ const TagNameEtcdClusterPrefix = "k8s.io/etcd/"
 
 const TagRoleMaster = "master"
 
+// TagRoleEtcd is the role tag for dedicated etcd instances, and for the etcd volumes they mount
+const TagRoleEtcd = "etcd"
+
 // TagNameKopsRole is the AWS tag used to identify the role an object plays for a cluster
 const TagNameKopsRole = "kubernetes.io/kops/role"
 
 // TagNameClusterOwnershipPrefix is the AWS tag used for ownership
 const TagNameClusterOwnershipPrefix = "kubernetes.io/cluster/"
 
+// TagNameExternalLoadBalancerPrefix marks a classic ELB that kops attached to an autoscaling group; the value is the ELB name
+const TagNameExternalLoadBalancerPrefix = "kops.k8s.io/external-load-balancer/"
+
+// TagNameExternalTargetGroupPrefix marks a target group that kops attached to an autoscaling group; the value is the target group ARN
+const TagNameExternalTargetGroupPrefix = "kops.k8s.io/external-target-group/"
+
 const (
 	WellKnownAccountKopeio             = "383156758163"
 	WellKnownAccountRedhat             = "309956199498"
@@ -158,6 +172,9 @@ type awsCloudImplementation struct {
 	autoscaling *autoscaling.AutoScaling
 	route53     *route53.Route53
 
+	// config is the configuration of the clients, including their credentials
+	config *aws.Config
+
 	region string
 
 	tags map[string]string
@@ -180,10 +197,33 @@ func (c *awsCloudImplementation) Region() string {
 	return c.region
 }
 
-var awsCloudInstances map[string]AWSCloud = make(map[string]AWSCloud)
+var (
+	// awsCloudInstancesMutex guards awsCloudInstances; clouds may be built concurrently in batch operations
+	awsCloudInstancesMutex sync.Mutex
+	awsCloudInstances      map[string]AWSCloud = make(map[string]AWSCloud)
+)
 
+// NewAWSCloud builds an AWSCloud for the region, assuming the default role if one is set
 func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
-	raw := awsCloudInstances[region]
+	return NewAWSCloudWithAssumeRole(region, tags, DefaultAssumeRole(), nil)
+}
+
+// NewAWSCloudWithAssumeRole builds an AWSCloud for the region whose API calls are made as the role, or with the
+// credentials of the environment if role is nil. Its Route53 API calls are made as dnsRole instead, if set; that
+// role is assumed with the credentials of the environment.
+func NewAWSCloudWithAssumeRole(region string, tags map[string]string, role *AssumeRole, dnsRole *AssumeRole) (AWSCloud, error) {
+	awsCloudInstancesMutex.Lock()
+	defer awsCloudInstancesMutex.Unlock()
+
+	key := region
+	if role != nil {
+		key = region + "/" + role.key()
+	}
+	if dnsRole != nil {
+		key += "/dns/" + dnsRole.key()
+	}
+
+	raw := awsCloudInstances[key]
 	if raw == nil {
 		c := &awsCloudImplementation{
 			region: region,
@@ -193,11 +233,13 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		}
 
 		config := aws.NewConfig().WithRegion(region)
+		config = config.WithEndpointResolver(EndpointResolver())
 
 		// This avoids a confusing error message when we fail to get credentials
 		// e.g. https://github.com/kubernetes/kops/issues/605
 		config = config.WithCredentialsChainVerboseErrors(true)
-		config = request.WithRetryer(config, newLoggingRetryer(ClientMaxRetries))
+		policy := retrypolicy.Current()
+		config = request.WithRetryer(config, newLoggingRetryer(ClientMaxRetries, policy))
 
 		// We have the updated aws sdk from 1.9, but don't have https://github.com/kubernetes/kubernetes/pull/55307
 		// Set the SleepDelay function to work around this
@@ -207,7 +249,30 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 			time.Sleep(d)
 		}
 
+		// Serve read-only requests from the discovery cache, if one has been configured
+		config = config.WithHTTPClient(&http.Client{Transport: discoverycache.WrapTransport(http.DefaultTransport)})
+
+		// Route53 uses the cloud's credentials, unless the hosted zone is managed with another role
+		dnsConfig := config
+		if dnsRole != nil {
+			creds, err := newAssumeRoleCredentials(config.Copy(), dnsRole)
+			if err != nil {
+				return c, err
+			}
+			dnsConfig = config.Copy().WithCredentials(creds)
+		}
+
+		if role != nil {
+			creds, err := newAssumeRoleCredentials(config.Copy(), role)
+			if err != nil {
+				return c, err
+			}
+			config = config.WithCredentials(creds)
+		}
+		c.config = config
+
 		requestLogger := newRequestLogger(2)
+		requestTracer := newRequestTracer()
 
 		sess, err := session.NewSession(config)
 		if err != nil {
@@ -216,6 +281,8 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		c.cf = cloudformation.New(sess, config)
 		c.cf.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.cf.Handlers)
+		requestTracer.addHandlers(&c.cf.Handlers)
+		addRequestTimeout(&c.cf.Handlers, policy.Timeout)
 
 		sess, err = session.NewSession(config)
 		if err != nil {
@@ -224,6 +291,8 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		c.ec2 = ec2.New(sess, config)
 		c.ec2.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.ec2.Handlers)
+		requestTracer.addHandlers(&c.ec2.Handlers)
+		addRequestTimeout(&c.ec2.Handlers, policy.Timeout)
 
 		sess, err = session.NewSession(config)
 		if err != nil {
@@ -232,6 +301,8 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		c.iam = iam.New(sess, config)
 		c.iam.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.iam.Handlers)
+		requestTracer.addHandlers(&c.iam.Handlers)
+		addRequestTimeout(&c.iam.Handlers, policy.Timeout)
 
 		sess, err = session.NewSession(config)
 		if err != nil {
@@ -240,6 +311,8 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		c.elb = elb.New(sess, config)
 		c.elb.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.elb.Handlers)
+		requestTracer.addHandlers(&c.elb.Handlers)
+		addRequestTimeout(&c.elb.Handlers, policy.Timeout)
 
 		sess, err = session.NewSession(config)
 		if err != nil {
@@ -248,6 +321,8 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		c.elbv2 = elbv2.New(sess, config)
 		c.elbv2.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.elbv2.Handlers)
+		requestTracer.addHandlers(&c.elbv2.Handlers)
+		addRequestTimeout(&c.elbv2.Handlers, policy.Timeout)
 
 		sess, err = session.NewSession(config)
 		if err != nil {
@@ -256,16 +331,20 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		c.autoscaling = autoscaling.New(sess, config)
 		c.autoscaling.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.autoscaling.Handlers)
+		requestTracer.addHandlers(&c.autoscaling.Handlers)
+		addRequestTimeout(&c.autoscaling.Handlers, policy.Timeout)
 
-		sess, err = session.NewSession(config)
+		sess, err = session.NewSession(dnsConfig)
 		if err != nil {
 			return c, err
 		}
-		c.route53 = route53.New(sess, config)
+		c.route53 = route53.New(sess, dnsConfig)
 		c.route53.Handlers.Send.PushFront(requestLogger)
 		c.addHandlers(region, &c.route53.Handlers)
+		requestTracer.addHandlers(&c.route53.Handlers)
+		addRequestTimeout(&c.route53.Handlers, policy.Timeout)
 
-		awsCloudInstances[region] = c
+		awsCloudInstances[key] = c
 		raw = c
 	}
 
@@ -329,6 +408,10 @@ func (c *awsCloudImplementation) DeleteGroup(g *cloudinstances.CloudInstanceGrou
 }
 
 func deleteGroup(c AWSCloud, g *cloudinstances.CloudInstanceGroup) error {
+	if direct, ok := g.Raw.(*DirectInstanceGroup); ok {
+		return deleteDirectGroup(c, direct)
+	}
+
 	asg := g.Raw.(*autoscaling.Group)
 
 	name := aws.StringValue(asg.AutoScalingGroupName)
@@ -347,8 +430,17 @@ func deleteGroup(c AWSCloud, g *cloudinstances.CloudInstanceGroup) error {
 		}
 	}
 
-	// Delete LaunchConfig
-	{
+	if asg.LaunchTemplate != nil {
+		id := aws.StringValue(asg.LaunchTemplate.LaunchTemplateId)
+		glog.V(2).Infof("Deleting launch template %q", id)
+		request := &ec2.DeleteLaunchTemplateInput{
+			LaunchTemplateId: asg.LaunchTemplate.LaunchTemplateId,
+		}
+		if _, err := c.EC2().DeleteLaunchTemplate(request); err != nil {
+			return fmt.Errorf("error deleting launch template %q: %v", id, err)
+		}
+	} else {
+		// Delete LaunchConfig
 		glog.V(2).Infof("Deleting autoscaling launch configuration %q", template)
 		request := &autoscaling.DeleteLaunchConfigurationInput{
 			LaunchConfigurationName: aws.String(template),
@@ -364,6 +456,64 @@ func deleteGroup(c AWSCloud, g *cloudinstances.CloudInstanceGroup) error {
 	return nil
 }
 
+// SetGroupMinSize changes the minimum size of an aws autoscaling group
+func (c *awsCloudImplementation) SetGroupMinSize(g *cloudinstances.CloudInstanceGroup, minSize int) error {
+	return setGroupMinSize(c, g, minSize)
+}
+
+func setGroupMinSize(c AWSCloud, g *cloudinstances.CloudInstanceGroup, minSize int) error {
+	asg, ok := g.Raw.(*autoscaling.Group)
+	if !ok {
+		return fmt.Errorf("group %q is not an autoscaling group", g.HumanName)
+	}
+
+	name := aws.StringValue(asg.AutoScalingGroupName)
+	request := &autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(name),
+		MinSize:              aws.Int64(int64(minSize)),
+	}
+	if _, err := c.Autoscaling().UpdateAutoScalingGroup(request); err != nil {
+		return fmt.Errorf("error setting minimum size of autoscaling group %q: %v", name, err)
+	}
+	g.MinSize = minSize
+
+	return nil
+}
+
+// SetGroupSize changes the minimum, maximum and optionally the desired size of an aws autoscaling group, or launches or
+// terminates the instances of a directly managed instance group
+func (c *awsCloudImplementation) SetGroupSize(g *cloudinstances.CloudInstanceGroup, minSize int, maxSize int, desiredCapacity *int) error {
+	return setGroupSize(c, g, minSize, maxSize, desiredCapacity)
+}
+
+func setGroupSize(c AWSCloud, g *cloudinstances.CloudInstanceGroup, minSize int, maxSize int, desiredCapacity *int) error {
+	if direct, ok := g.Raw.(*DirectInstanceGroup); ok {
+		return setDirectGroupSize(c, g, direct, minSize, desiredCapacity)
+	}
+
+	asg, ok := g.Raw.(*autoscaling.Group)
+	if !ok {
+		return fmt.Errorf("group %q is not an autoscaling group", g.HumanName)
+	}
+
+	name := aws.StringValue(asg.AutoScalingGroupName)
+	request := &autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(name),
+		MinSize:              aws.Int64(int64(minSize)),
+		MaxSize:              aws.Int64(int64(maxSize)),
+	}
+	if desiredCapacity != nil {
+		request.DesiredCapacity = aws.Int64(int64(*desiredCapacity))
+	}
+	if _, err := c.Autoscaling().UpdateAutoScalingGroup(request); err != nil {
+		return fmt.Errorf("error resizing autoscaling group %q: %v", name, err)
+	}
+	g.MinSize = minSize
+	g.MaxSize = maxSize
+
+	return nil
+}
+
 // DeleteInstance deletes an aws instance
 func (c *awsCloudImplementation) DeleteInstance(i *cloudinstances.CloudInstanceGroupMember) error {
 	return deleteInstance(c, i)
@@ -375,6 +525,12 @@ func deleteInstance(c AWSCloud, i *cloudinstances.CloudInstanceGroupMember) erro
 		return fmt.Errorf("id was not set on CloudInstanceGroupMember: %v", i)
 	}
 
+	if i.CloudInstanceGroup != nil {
+		if direct, ok := i.CloudInstanceGroup.Raw.(*DirectInstanceGroup); ok {
+			return deleteDirectInstance(c, direct, id)
+		}
+	}
+
 	request := &autoscaling.TerminateInstanceInAutoScalingGroupInput{
 		InstanceId:                     aws.String(id),
 		ShouldDecrementDesiredCapacity: aws.Bool(false),
@@ -418,6 +574,10 @@ func getCloudGroups(c AWSCloud, cluster *kops.Cluster, instancegroups []*kops.In
 			}
 			continue
 		}
+		if instancegroup.IsDirectlyManaged() {
+			glog.Warningf("Ignoring ASG %q, as instance group %q is directly managed", name, instancegroup.ObjectMeta.Name)
+			continue
+		}
 
 		groups[instancegroup.ObjectMeta.Name], err = awsBuildCloudInstanceGroup(c, instancegroup, asg, nodeMap)
 		if err != nil {
@@ -425,8 +585,15 @@ func getCloudGroups(c AWSCloud, cluster *kops.Cluster, instancegroups []*kops.In
 		}
 	}
 
-	return groups, nil
+	directGroups, err := getDirectCloudGroups(c, cluster, instancegroups, nodeMap)
+	if err != nil {
+		return nil, err
+	}
+	for name, g := range directGroups {
+		groups[name] = g
+	}
 
+	return groups, nil
 }
 
 // FindAutoscalingGroups finds autoscaling groups matching the specified tags
@@ -516,6 +683,33 @@ func matchesAsgTags(tags map[string]string, actual []*autoscaling.TagDescription
 func awsBuildCloudInstanceGroup(c AWSCloud, ig *kops.InstanceGroup, g *autoscaling.Group, nodeMap map[string]*v1.Node) (*cloudinstances.CloudInstanceGroup, error) {
 	newLaunchConfigName := aws.StringValue(g.LaunchConfigurationName)
 
+	// The tags of the instances record what they were launched with, so we only describe them when we need to
+	var instances map[string]*ec2.Instance
+	if g.LaunchTemplate != nil || findASGTag(g, TagNameInstanceTagsHash) != "" || findASGTag(g, TagNameInstanceConfigHash) != "" {
+		var err error
+		instances, err = describeAutoscalingGroupInstances(c, g)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// For groups using a launch template, instances are compared by the template version they were launched from
+	var currentLaunchTemplates map[string]string
+	// A launch configuration doesn't include the tags, so instances launched with older tags are found separately
+	var staleTags map[string]bool
+	if g.LaunchTemplate != nil {
+		var err error
+		newLaunchConfigName, currentLaunchTemplates, err = findLaunchTemplateVersions(c, g, instances)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		staleTags = findInstancesWithStaleTags(g, instances)
+	}
+
+	// Instances launched since kops recorded the hash of their configuration tell us which part of it changed
+	configChanges := findInstanceConfigChanges(g, instances)
+
 	cg := &cloudinstances.CloudInstanceGroup{
 		HumanName:     aws.StringValue(g.AutoScalingGroupName),
 		InstanceGroup: ig,
@@ -530,7 +724,22 @@ func awsBuildCloudInstanceGroup(c AWSCloud, ig *kops.InstanceGroup, g *autoscali
 			glog.Warningf("ignoring instance with no instance id: %s", i)
 			continue
 		}
-		err := cg.NewCloudInstanceGroupMember(instanceId, newLaunchConfigName, aws.StringValue(i.LaunchConfigurationName), nodeMap)
+		currentLaunchConfigName := aws.StringValue(i.LaunchConfigurationName)
+		if currentLaunchTemplates != nil {
+			currentLaunchConfigName = currentLaunchTemplates[instanceId]
+		}
+
+		reasons := configChanges[instanceId]
+		if staleTags[instanceId] && !slice.Contains(reasons, InstanceConfigTags) {
+			// Forces the instance to be replaced, so that it picks up the current tags
+			reasons = append(reasons, InstanceConfigTags)
+		}
+		if len(reasons) == 0 && currentLaunchConfigName != newLaunchConfigName {
+			// Something not covered by the hash changed, e.g. the volumes or the IAM instance profile
+			reasons = append(reasons, cloudinstances.ReasonConfigurationChanged)
+		}
+
+		err := cg.NewCloudInstanceGroupMemberWithReasons(instanceId, reasons, nodeMap)
 		if err != nil {
 			return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
 		}
@@ -539,6 +748,69 @@ func awsBuildCloudInstanceGroup(c AWSCloud, ig *kops.InstanceGroup, g *autoscali
 	return cg, nil
 }
 
+// findASGTag returns the value of the tag of the autoscaling group, or "" if it is not set
+func findASGTag(g *autoscaling.Group, key string) string {
+	for _, t := range g.Tags {
+		if aws.StringValue(t.Key) == key {
+			return aws.StringValue(t.Value)
+		}
+	}
+	return ""
+}
+
+// describeAutoscalingGroupInstances returns the instances of the autoscaling group, keyed by instance id
+func describeAutoscalingGroupInstances(c AWSCloud, g *autoscaling.Group) (map[string]*ec2.Instance, error) {
+	instances := make(map[string]*ec2.Instance)
+
+	var instanceIDs []*string
+	for _, i := range g.Instances {
+		if aws.StringValue(i.InstanceId) != "" {
+			instanceIDs = append(instanceIDs, i.InstanceId)
+		}
+	}
+	if len(instanceIDs) == 0 {
+		return instances, nil
+	}
+
+	request := &ec2.DescribeInstancesInput{InstanceIds: instanceIDs}
+	err := c.EC2().DescribeInstancesPages(request, func(p *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, r := range p.Reservations {
+			for _, i := range r.Instances {
+				instances[aws.StringValue(i.InstanceId)] = i
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing instances of autoscaling group %q: %v", aws.StringValue(g.AutoScalingGroupName), err)
+	}
+	return instances, nil
+}
+
+// findLaunchTemplateVersions returns the "<template>:<version>" identifier of the default version of the launch template
+// used by the autoscaling group, along with the identifier of the version each instance of the group was launched from
+func findLaunchTemplateVersions(c AWSCloud, g *autoscaling.Group, instances map[string]*ec2.Instance) (string, map[string]string, error) {
+	response, err := c.EC2().DescribeLaunchTemplates(&ec2.DescribeLaunchTemplatesInput{
+		LaunchTemplateIds: []*string{g.LaunchTemplate.LaunchTemplateId},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error describing launch template %q: %v", aws.StringValue(g.LaunchTemplate.LaunchTemplateId), err)
+	}
+	if len(response.LaunchTemplates) != 1 {
+		return "", nil, fmt.Errorf("launch template %q not found", aws.StringValue(g.LaunchTemplate.LaunchTemplateId))
+	}
+	template := response.LaunchTemplates[0]
+	templateName := aws.StringValue(template.LaunchTemplateName)
+	newVersion := templateName + ":" + strconv.FormatInt(aws.Int64Value(template.DefaultVersionNumber), 10)
+
+	current := make(map[string]string)
+	for id, i := range instances {
+		version, _ := FindEC2Tag(i.Tags, TagLaunchTemplateVersion)
+		current[id] = templateName + ":" + version
+	}
+	return newVersion, current, nil
+}
+
 func (c *awsCloudImplementation) Tags() map[string]string {
 	// Defensive copy
 	tags := make(map[string]string)
@@ -1105,11 +1377,8 @@ func ValidateZones(zones []string, cloud AWSCloud) error {
 }
 
 func (c *awsCloudImplementation) DNS() (dnsprovider.Interface, error) {
-	provider, err := dnsprovider.GetDnsProvider(dnsproviderroute53.ProviderName, nil)
-	if err != nil {
-		return nil, fmt.Errorf("Error building (k8s) DNS provider: %v", err)
-	}
-	return provider, nil
+	// Share the Route53 client, so that the DNS provider uses the same credentials
+	return dnsproviderroute53.New(c.route53), nil
 }
 
 func (c *awsCloudImplementation) CloudFormation() *cloudformation.CloudFormation {
@@ -1195,6 +1464,9 @@ func (c *awsCloudImplementation) DefaultInstanceType(cluster *kops.Cluster, ig *
 		// Also some accounts are no longer supporting m3 in us-east-1 zones
 		candidates = []string{"m3.medium", "c4.large"}
 
+	case kops.InstanceGroupRoleEtcd, kops.InstanceGroupRoleAPIServer:
+		candidates = []string{"m3.medium", "c4.large"}
+
 	case kops.InstanceGroupRoleNode:
 		candidates = []string{"t2.medium"}
 
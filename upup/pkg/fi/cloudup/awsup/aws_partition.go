@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// PartitionForRegion returns the AWS partition of a region, e.g. aws-cn for cn-north-1 or aws-us-gov for
+// us-gov-west-1. Regions the SDK doesn't know are matched by its region patterns, and default to aws.
+func PartitionForRegion(region string) string {
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return p.ID()
+	}
+	return endpoints.AwsPartitionID
+}
+
+// PartitionForCluster returns the AWS partition of a cluster in the region: cloudConfig.awsPartition if it is set,
+// otherwise the partition of the region
+func PartitionForCluster(cluster *kops.Cluster, region string) string {
+	if cluster != nil && cluster.Spec.CloudConfig != nil && cluster.Spec.CloudConfig.AWSPartition != nil && *cluster.Spec.CloudConfig.AWSPartition != "" {
+		return *cluster.Spec.CloudConfig.AWSPartition
+	}
+	return PartitionForRegion(region)
+}
+
+// ARNPrefix returns the prefix of the ARNs in a partition, e.g. arn:aws-cn
+func ARNPrefix(partition string) string {
+	return "arn:" + partition
+}
+
+// ServicePrincipal returns the principal of an AWS service for IAM trust policies, e.g. ec2.amazonaws.com. Services
+// in the China partition use the amazonaws.com.cn domain.
+func ServicePrincipal(partition string, service string) string {
+	if partition == endpoints.AwsCnPartitionID {
+		return service + ".amazonaws.com.cn"
+	}
+	return service + ".amazonaws.com"
+}
+
+// PartitionHasService returns true if the service is available in the partition. Route53, for example, is not
+// available in aws-cn or aws-us-gov. Partitions the SDK doesn't know are assumed to have every service.
+func PartitionHasService(partition string, service string) bool {
+	known := false
+	for _, p := range endpoints.DefaultPartitions() {
+		if p.ID() == partition {
+			known = true
+		}
+	}
+	if !known {
+		return true
+	}
+
+	_, ok := endpoints.RegionsForService(endpoints.DefaultPartitions(), partition, service)
+	return ok
+}
+
+// ServiceEndpointEnvVar returns the environment variable that overrides the endpoint of an AWS service, e.g.
+// AWS_EC2_ENDPOINT for ec2 or AWS_ELASTICLOADBALANCING_ENDPOINT for elasticloadbalancing
+func ServiceEndpointEnvVar(service string) string {
+	return "AWS_" + strings.ToUpper(strings.Replace(service, "-", "_", -1)) + "_ENDPOINT"
+}
+
+// ServiceEndpointEnvVars returns the endpoint overrides that are set, by environment variable name, so they can be
+// passed on to nodeup and protokube
+func ServiceEndpointEnvVars() map[string]string {
+	env := make(map[string]string)
+	for _, e := range os.Environ() {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			continue
+		}
+		if strings.HasPrefix(kv[0], "AWS_") && strings.HasSuffix(kv[0], "_ENDPOINT") {
+			env[kv[0]] = kv[1]
+		}
+	}
+	return env
+}
+
+// EndpointResolver resolves the endpoints of AWS services with the SDK's endpoint data, unless the endpoint of a
+// service is overridden with its environment variable, e.g. for a VPC endpoint or a partition the SDK doesn't know
+func EndpointResolver() endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		resolved, err := endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+
+		url := os.Getenv(ServiceEndpointEnvVar(service))
+		if url == "" {
+			if err != nil {
+				return resolved, fmt.Errorf("error resolving endpoint of AWS service %q in region %q (set %s to override it): %v", service, region, ServiceEndpointEnvVar(service), err)
+			}
+			return resolved, nil
+		}
+
+		glog.V(4).Infof("using endpoint %q for AWS service %q", url, service)
+		// Only the URL is overridden; the SDK knows how to sign for the services it knows, e.g. IAM signs for us-east-1
+		if err != nil {
+			resolved = endpoints.ResolvedEndpoint{
+				SigningRegion: region,
+				SigningName:   service,
+			}
+		}
+		resolved.URL = url
+		return resolved, nil
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	grid := map[string]string{
+		"us-east-1":      "aws",
+		"eu-west-3":      "aws",
+		"cn-north-1":     "aws-cn",
+		"cn-northwest-1": "aws-cn",
+		"us-gov-west-1":  "aws-us-gov",
+		// Not in the SDK, but matched by the partition's region pattern
+		"us-gov-east-1": "aws-us-gov",
+		"us-test-1":     "aws",
+	}
+	for region, expected := range grid {
+		if actual := PartitionForRegion(region); actual != expected {
+			t.Errorf("unexpected partition for %q: expected %q, got %q", region, expected, actual)
+		}
+	}
+}
+
+func TestServicePrincipal(t *testing.T) {
+	grid := map[string]string{
+		"aws":        "ec2.amazonaws.com",
+		"aws-cn":     "ec2.amazonaws.com.cn",
+		"aws-us-gov": "ec2.amazonaws.com",
+	}
+	for partition, expected := range grid {
+		if actual := ServicePrincipal(partition, "ec2"); actual != expected {
+			t.Errorf("unexpected principal in %q: expected %q, got %q", partition, expected, actual)
+		}
+	}
+}
+
+func TestPartitionHasService(t *testing.T) {
+	if !PartitionHasService("aws", "route53") {
+		t.Errorf("expected route53 in aws")
+	}
+	if PartitionHasService("aws-cn", "route53") {
+		t.Errorf("expected no route53 in aws-cn")
+	}
+	if !PartitionHasService("aws-cn", "ec2") {
+		t.Errorf("expected ec2 in aws-cn")
+	}
+	if !PartitionHasService("aws-iso", "route53") {
+		t.Errorf("expected unknown partitions to have every service")
+	}
+}
+
+func TestEndpointResolver(t *testing.T) {
+	endpoint, err := EndpointResolver().EndpointFor("ec2", "cn-north-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.URL != "https://ec2.cn-north-1.amazonaws.com.cn" {
+		t.Errorf("unexpected endpoint %q", endpoint.URL)
+	}
+
+	os.Setenv("AWS_EC2_ENDPOINT", "https://ec2.example.com")
+	defer os.Unsetenv("AWS_EC2_ENDPOINT")
+
+	endpoint, err = EndpointResolver().EndpointFor("ec2", "cn-north-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.URL != "https://ec2.example.com" || endpoint.SigningRegion != "cn-north-1" {
+		t.Errorf("unexpected endpoint %+v", endpoint)
+	}
+
+	if env := ServiceEndpointEnvVars(); env["AWS_EC2_ENDPOINT"] != "https://ec2.example.com" {
+		t.Errorf("unexpected endpoint env vars %v", env)
+	}
+}
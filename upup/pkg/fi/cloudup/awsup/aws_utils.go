@@ -69,6 +69,7 @@ func ValidateRegion(region string) error {
 			awsRegion = "us-east-1"
 		}
 		config := aws.NewConfig().WithRegion(awsRegion)
+		config = config.WithEndpointResolver(EndpointResolver())
 		config = config.WithCredentialsChainVerboseErrors(true)
 
 		sess, err := session.NewSession(config)
@@ -0,0 +1,444 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+const (
+	// TagNameDirectInstanceGroup is set on the instances of a directly managed instance group, to the name of the group
+	TagNameDirectInstanceGroup = "kops.k8s.io/direct-instancegroup"
+
+	// TagLaunchTemplateVersion is set by EC2 on instances launched from a launch template
+	TagLaunchTemplateVersion = "aws:ec2launchtemplate:version"
+)
+
+// DirectInstanceGroup is the Raw value of a CloudInstanceGroup that kops manages without an autoscaling group
+type DirectInstanceGroup struct {
+	// LaunchTemplate is the launch template from which instances are launched
+	LaunchTemplate *ec2.LaunchTemplate
+	// Instances are the pending and running instances of the group
+	Instances []*ec2.Instance
+	// InstanceTypes are the instance types to try, in order of preference, when launching instances
+	InstanceTypes []string
+	// Subnets are the subnets into which instances are launched, and ClusterName the cluster whose subnets they are
+	Subnets     []*kops.ClusterSubnetSpec
+	ClusterName string
+}
+
+// DirectInstanceGroupName returns the name of the launch template backing a directly managed instance group
+func DirectInstanceGroupName(ig *kops.InstanceGroup, clusterName string) string {
+	return ig.ObjectMeta.Name + "." + clusterName
+}
+
+// FindLaunchTemplate returns the launch template with the specified name, or nil if there is none
+func FindLaunchTemplate(c AWSCloud, name string) (*ec2.LaunchTemplate, error) {
+	request := &ec2.DescribeLaunchTemplatesInput{
+		LaunchTemplateNames: []*string{aws.String(name)},
+	}
+	response, err := c.EC2().DescribeLaunchTemplates(request)
+	if err != nil {
+		if AWSErrorCode(err) == "InvalidLaunchTemplateName.NotFoundException" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing launch templates: %v", err)
+	}
+	if len(response.LaunchTemplates) == 0 {
+		return nil, nil
+	}
+	if len(response.LaunchTemplates) != 1 {
+		return nil, fmt.Errorf("found multiple launch templates with name %q", name)
+	}
+	return response.LaunchTemplates[0], nil
+}
+
+// FindDirectInstances returns the pending and running instances of the directly managed instance group
+func FindDirectInstances(c AWSCloud, groupName string) ([]*ec2.Instance, error) {
+	request := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			NewEC2Filter("tag:"+TagNameDirectInstanceGroup, groupName),
+			NewEC2Filter("instance-state-name", "pending", "running"),
+		},
+	}
+	for k, v := range c.Tags() {
+		request.Filters = append(request.Filters, NewEC2Filter("tag:"+k, v))
+	}
+
+	var instances []*ec2.Instance
+	err := c.EC2().DescribeInstancesPages(request, func(p *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, r := range p.Reservations {
+			instances = append(instances, r.Instances...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing instances for instance group %q: %v", groupName, err)
+	}
+	return instances, nil
+}
+
+// RunDirectInstance launches a single instance from the default version of the launch template, in the specified subnet.
+// If instanceTypes is not empty, each instance type is tried in order until one has capacity; otherwise the instance
+// type of the launch template is used.
+func RunDirectInstance(c AWSCloud, template *ec2.LaunchTemplate, subnetID string, instanceTypes []string) (string, error) {
+	version := strconv.FormatInt(aws.Int64Value(template.DefaultVersionNumber), 10)
+
+	response, err := c.EC2().DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: template.LaunchTemplateId,
+		Versions:         []*string{aws.String(version)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error describing launch template %q: %v", aws.StringValue(template.LaunchTemplateName), err)
+	}
+	if len(response.LaunchTemplateVersions) == 0 || response.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return "", fmt.Errorf("launch template %q version %s not found", aws.StringValue(template.LaunchTemplateName), version)
+	}
+	data := response.LaunchTemplateVersions[0].LaunchTemplateData
+
+	request := &ec2.RunInstancesInput{
+		LaunchTemplate: &ec2.LaunchTemplateSpecification{
+			LaunchTemplateId: template.LaunchTemplateId,
+			Version:          aws.String(version),
+		},
+		MinCount: aws.Int64(1),
+		MaxCount: aws.Int64(1),
+	}
+
+	// Network interfaces in the request replace those of the template, so we carry over the template's settings
+	if len(data.NetworkInterfaces) != 0 {
+		ni := data.NetworkInterfaces[0]
+		request.NetworkInterfaces = []*ec2.InstanceNetworkInterfaceSpecification{
+			{
+				DeviceIndex:              aws.Int64(0),
+				SubnetId:                 aws.String(subnetID),
+				AssociatePublicIpAddress: ni.AssociatePublicIpAddress,
+				DeleteOnTermination:      aws.Bool(true),
+				Groups:                   ni.Groups,
+			},
+		}
+	} else {
+		request.SubnetId = aws.String(subnetID)
+	}
+
+	name := aws.StringValue(template.LaunchTemplateName)
+	glog.V(2).Infof("Launching instance from launch template %q (version %s) in subnet %q", name, version, subnetID)
+
+	if len(instanceTypes) == 0 {
+		id, err := runDirectInstance(c, request)
+		if err != nil {
+			return "", fmt.Errorf("error launching instance from launch template %q: %v", name, err)
+		}
+		return id, nil
+	}
+
+	var lastErr error
+	for _, instanceType := range instanceTypes {
+		request.InstanceType = aws.String(instanceType)
+		id, err := runDirectInstance(c, request)
+		if err == nil {
+			return id, nil
+		}
+		switch AWSErrorCode(err) {
+		case "InsufficientInstanceCapacity", "Unsupported":
+			glog.Warningf("unable to launch instance type %q in subnet %q, trying next instance type: %v", instanceType, subnetID, err)
+			lastErr = err
+		default:
+			return "", fmt.Errorf("error launching %q instance from launch template %q: %v", instanceType, name, err)
+		}
+	}
+	return "", fmt.Errorf("no capacity for any of the instance types %v in subnet %q: %v", instanceTypes, subnetID, lastErr)
+}
+
+func runDirectInstance(c AWSCloud, request *ec2.RunInstancesInput) (string, error) {
+	reservation, err := c.EC2().RunInstances(request)
+	if err != nil {
+		return "", err
+	}
+	if len(reservation.Instances) == 0 {
+		return "", fmt.Errorf("no instance was launched")
+	}
+	return aws.StringValue(reservation.Instances[0].InstanceId), nil
+}
+
+// ResizeDirectInstances launches or terminates instances of a directly managed instance group until it has size instances.
+// New instances are spread across the subnets, always launching into the subnet with the fewest instances; outdated
+// instances are terminated first, then the most recently launched.
+func ResizeDirectInstances(c AWSCloud, groupName string, template *ec2.LaunchTemplate, instances []*ec2.Instance, subnetIDs []string, instanceTypes []string, size int) error {
+	if len(instances) < size {
+		if template == nil {
+			return fmt.Errorf("launch template for instance group %q not found", groupName)
+		}
+		if len(subnetIDs) == 0 {
+			return fmt.Errorf("instance group %q has no subnets to launch instances into", groupName)
+		}
+
+		counts := make(map[string]int)
+		for _, i := range instances {
+			counts[aws.StringValue(i.SubnetId)]++
+		}
+		for n := len(instances); n < size; n++ {
+			var subnetID string
+			for _, id := range subnetIDs {
+				if subnetID == "" || counts[id] < counts[subnetID] {
+					subnetID = id
+				}
+			}
+
+			id, err := RunDirectInstance(c, template, subnetID, instanceTypes)
+			if err != nil {
+				return err
+			}
+			glog.V(2).Infof("launched instance %q for instance group %q", id, groupName)
+			counts[subnetID]++
+		}
+	}
+
+	if len(instances) > size {
+		ids := directInstancesToTerminate(template, instances, len(instances)-size)
+		glog.V(2).Infof("terminating instances %v of instance group %q", ids, groupName)
+		if err := TerminateDirectInstances(c, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// directInstancesToTerminate chooses count instances to terminate: outdated instances first, then the most recently launched
+func directInstancesToTerminate(template *ec2.LaunchTemplate, instances []*ec2.Instance, count int) []string {
+	surplus := make([]*ec2.Instance, len(instances))
+	copy(surplus, instances)
+	sort.SliceStable(surplus, func(i, j int) bool {
+		if template != nil {
+			oi := IsDirectInstanceOutdated(template, surplus[i])
+			oj := IsDirectInstanceOutdated(template, surplus[j])
+			if oi != oj {
+				return oi
+			}
+		}
+		return aws.TimeValue(surplus[i].LaunchTime).After(aws.TimeValue(surplus[j].LaunchTime))
+	})
+
+	var ids []string
+	for _, i := range surplus[:count] {
+		ids = append(ids, aws.StringValue(i.InstanceId))
+	}
+	return ids
+}
+
+// findDirectSubnetIDs returns the IDs of the subnets into which the instances of a directly managed group are launched
+func findDirectSubnetIDs(c AWSCloud, g *DirectInstanceGroup) ([]string, error) {
+	var ids []string
+	for _, subnet := range g.Subnets {
+		if subnet.ProviderID != "" {
+			ids = append(ids, subnet.ProviderID)
+			continue
+		}
+
+		name := subnet.Name + "." + g.ClusterName
+		request := &ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{NewEC2Filter("tag:Name", name)},
+		}
+		for k, v := range c.Tags() {
+			request.Filters = append(request.Filters, NewEC2Filter("tag:"+k, v))
+		}
+		response, err := c.EC2().DescribeSubnets(request)
+		if err != nil {
+			return nil, fmt.Errorf("error listing subnets: %v", err)
+		}
+		if len(response.Subnets) != 1 {
+			return nil, fmt.Errorf("found %d subnets with name %q, expected 1", len(response.Subnets), name)
+		}
+		ids = append(ids, aws.StringValue(response.Subnets[0].SubnetId))
+	}
+	return ids, nil
+}
+
+// setDirectGroupSize launches or terminates instances so that a directly managed group has the desired capacity, or
+// else its minimum size.  Nothing but kops scales the group, so it has no separate maximum size.
+func setDirectGroupSize(c AWSCloud, g *cloudinstances.CloudInstanceGroup, direct *DirectInstanceGroup, minSize int, desiredCapacity *int) error {
+	size := minSize
+	if desiredCapacity != nil {
+		size = *desiredCapacity
+	}
+
+	var subnetIDs []string
+	if size > len(direct.Instances) {
+		var err error
+		subnetIDs, err = findDirectSubnetIDs(c, direct)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ResizeDirectInstances(c, g.HumanName, direct.LaunchTemplate, direct.Instances, subnetIDs, direct.InstanceTypes, size); err != nil {
+		return err
+	}
+	g.MinSize = size
+	g.MaxSize = size
+
+	return nil
+}
+
+// TerminateDirectInstances terminates the specified instances
+func TerminateDirectInstances(c AWSCloud, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	request := &ec2.TerminateInstancesInput{
+		InstanceIds: aws.StringSlice(ids),
+	}
+	if _, err := c.EC2().TerminateInstances(request); err != nil {
+		return fmt.Errorf("error terminating instances %v: %v", ids, err)
+	}
+	return nil
+}
+
+// IsDirectInstanceOutdated returns true if the instance was not launched from the default version of the launch template
+func IsDirectInstanceOutdated(template *ec2.LaunchTemplate, i *ec2.Instance) bool {
+	version, _ := FindEC2Tag(i.Tags, TagLaunchTemplateVersion)
+	return version != strconv.FormatInt(aws.Int64Value(template.DefaultVersionNumber), 10)
+}
+
+func getDirectCloudGroups(c AWSCloud, cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, nodeMap map[string]*v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+
+	for _, ig := range instancegroups {
+		if !ig.IsDirectlyManaged() {
+			continue
+		}
+
+		name := DirectInstanceGroupName(ig, cluster.ObjectMeta.Name)
+		template, err := FindLaunchTemplate(c, name)
+		if err != nil {
+			return nil, err
+		}
+		if template == nil {
+			glog.V(2).Infof("no launch template found for instance group %q", ig.ObjectMeta.Name)
+			continue
+		}
+
+		instances, err := FindDirectInstances(c, ig.ObjectMeta.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		size := 2
+		if ig.Spec.MinSize != nil {
+			size = int(*ig.Spec.MinSize)
+		}
+		direct := &DirectInstanceGroup{
+			LaunchTemplate: template,
+			Instances:      instances,
+			ClusterName:    cluster.ObjectMeta.Name,
+		}
+		for _, subnetName := range ig.Spec.Subnets {
+			subnet := findClusterSubnet(cluster, subnetName)
+			if subnet == nil {
+				return nil, fmt.Errorf("cannot find subnet %q (declared in instance group %q, not found in cluster)", subnetName, ig.ObjectMeta.Name)
+			}
+			direct.Subnets = append(direct.Subnets, subnet)
+		}
+
+		cg := &cloudinstances.CloudInstanceGroup{
+			HumanName:     name,
+			InstanceGroup: ig,
+			MinSize:       size,
+			MaxSize:       size,
+			Raw:           direct,
+		}
+
+		newVersion := strconv.FormatInt(aws.Int64Value(template.DefaultVersionNumber), 10)
+		for _, i := range instances {
+			currentVersion, _ := FindEC2Tag(i.Tags, TagLaunchTemplateVersion)
+			if err := cg.NewCloudInstanceGroupMember(aws.StringValue(i.InstanceId), newVersion, currentVersion, nodeMap); err != nil {
+				return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
+			}
+		}
+
+		if ig.Spec.MixedInstancesPolicy != nil {
+			direct.InstanceTypes = ig.Spec.MixedInstancesPolicy.Instances
+		}
+
+		groups[ig.ObjectMeta.Name] = cg
+	}
+
+	return groups, nil
+}
+
+// findClusterSubnet returns the subnet of the cluster with the specified name, or nil if there is none
+func findClusterSubnet(cluster *kops.Cluster, name string) *kops.ClusterSubnetSpec {
+	for i := range cluster.Spec.Subnets {
+		if cluster.Spec.Subnets[i].Name == name {
+			return &cluster.Spec.Subnets[i]
+		}
+	}
+	return nil
+}
+
+// deleteDirectInstance replaces an instance of a directly managed group: the replacement is launched into the same
+// subnet before the instance is terminated, so the group never drops below its size.
+func deleteDirectInstance(c AWSCloud, g *DirectInstanceGroup, id string) error {
+	var subnetID string
+	for _, i := range g.Instances {
+		if aws.StringValue(i.InstanceId) == id {
+			subnetID = aws.StringValue(i.SubnetId)
+		}
+	}
+	if subnetID == "" {
+		return fmt.Errorf("instance %q not found in instance group %q", id, aws.StringValue(g.LaunchTemplate.LaunchTemplateName))
+	}
+
+	replacement, err := RunDirectInstance(c, g.LaunchTemplate, subnetID, g.InstanceTypes)
+	if err != nil {
+		return err
+	}
+	glog.V(2).Infof("launched instance %q to replace %q", replacement, id)
+
+	return TerminateDirectInstances(c, []string{id})
+}
+
+// deleteDirectGroup terminates all the instances of a directly managed group, and deletes its launch template
+func deleteDirectGroup(c AWSCloud, g *DirectInstanceGroup) error {
+	var ids []string
+	for _, i := range g.Instances {
+		ids = append(ids, aws.StringValue(i.InstanceId))
+	}
+	if err := TerminateDirectInstances(c, ids); err != nil {
+		return err
+	}
+
+	name := aws.StringValue(g.LaunchTemplate.LaunchTemplateName)
+	glog.V(2).Infof("Deleting launch template %q", name)
+	request := &ec2.DeleteLaunchTemplateInput{
+		LaunchTemplateId: g.LaunchTemplate.LaunchTemplateId,
+	}
+	if _, err := c.EC2().DeleteLaunchTemplate(request); err != nil {
+		return fmt.Errorf("error deleting launch template %q: %v", name, err)
+	}
+	return nil
+}
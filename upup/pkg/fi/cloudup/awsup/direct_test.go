@@ -0,0 +1,97 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestDirectInstancesToTerminate(t *testing.T) {
+	template := &ec2.LaunchTemplate{DefaultVersionNumber: aws.Int64(2)}
+	now := time.Now()
+
+	instance := func(id string, version string, launched time.Duration) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceId: aws.String(id),
+			LaunchTime: aws.Time(now.Add(-launched)),
+			Tags:       []*ec2.Tag{{Key: aws.String(TagLaunchTemplateVersion), Value: aws.String(version)}},
+		}
+	}
+	instances := []*ec2.Instance{
+		instance("i-old", "2", 3*time.Hour),
+		instance("i-outdated", "1", 2*time.Hour),
+		instance("i-new", "2", time.Hour),
+	}
+
+	if ids := directInstancesToTerminate(template, instances, 2); !reflect.DeepEqual(ids, []string{"i-outdated", "i-new"}) {
+		t.Errorf("unexpected instances to terminate: %v", ids)
+	}
+	if ids := directInstancesToTerminate(template, instances, 3); !reflect.DeepEqual(ids, []string{"i-outdated", "i-new", "i-old"}) {
+		t.Errorf("unexpected instances to terminate: %v", ids)
+	}
+}
+
+func TestFindDirectSubnetIDs(t *testing.T) {
+	mockEC2 := &mockec2.MockEC2{}
+	cloud := BuildMockAWSCloud("us-east-1", "a")
+	cloud.MockEC2 = mockEC2
+	cloud.tags = map[string]string{"KubernetesCluster": "test.k8s.local"}
+
+	subnets := []struct {
+		id      string
+		name    string
+		cluster string
+	}{
+		{id: "subnet-a", name: "us-east-1a.test.k8s.local", cluster: "test.k8s.local"},
+		{id: "subnet-other", name: "us-east-1a.other.k8s.local", cluster: "other.k8s.local"},
+	}
+	for _, subnet := range subnets {
+		if _, err := mockEC2.CreateSubnetWithId(&ec2.CreateSubnetInput{VpcId: aws.String("vpc-1")}, subnet.id); err != nil {
+			t.Fatalf("error creating subnet: %v", err)
+		}
+		if err := cloud.CreateTags(subnet.id, map[string]string{"Name": subnet.name, "KubernetesCluster": subnet.cluster}); err != nil {
+			t.Fatalf("error tagging subnet: %v", err)
+		}
+	}
+
+	g := &DirectInstanceGroup{
+		ClusterName: "test.k8s.local",
+		Subnets: []*kops.ClusterSubnetSpec{
+			{Name: "us-east-1a"},
+			{Name: "shared", ProviderID: "subnet-shared"},
+		},
+	}
+	ids, err := findDirectSubnetIDs(cloud, g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"subnet-a", "subnet-shared"}) {
+		t.Errorf("unexpected subnet IDs: %v", ids)
+	}
+
+	g.Subnets = []*kops.ClusterSubnetSpec{{Name: "us-east-1b"}}
+	if _, err := findDirectSubnetIDs(cloud, g); err == nil {
+		t.Errorf("expected error for a subnet that does not exist")
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+// TagNameAutoscalingGroup is set by AWS on the instances of an autoscaling group
+const TagNameAutoscalingGroup = "aws:autoscaling:groupName"
+
+// FindEnrollmentSpec returns the launch configuration or launch template version that the instances of the group are
+// launched from, and the tags that they are launched with, which an existing instance must share to join the group
+func FindEnrollmentSpec(c AWSCloud, cg *cloudinstances.CloudInstanceGroup) (*LaunchSpec, map[string]string, error) {
+	tags := make(map[string]string)
+
+	var spec *LaunchSpec
+	switch g := cg.Raw.(type) {
+	case *autoscaling.Group:
+		if g.LaunchTemplate != nil {
+			version := aws.StringValue(g.LaunchTemplate.Version)
+			if version == "" {
+				version = "$Default"
+			}
+			var err error
+			spec, err = findLaunchTemplateEnrollmentSpec(c, aws.StringValue(g.LaunchTemplate.LaunchTemplateId), version, tags)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			name := aws.StringValue(g.LaunchConfigurationName)
+			var err error
+			spec, err = findLaunchConfigurationSpec(c, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if spec == nil {
+				return nil, nil, fmt.Errorf("launch configuration %q of instance group %q not found", name, cg.HumanName)
+			}
+		}
+		// Instances get the tags of the group, as well as those of any template
+		for _, t := range g.Tags {
+			if aws.BoolValue(t.PropagateAtLaunch) {
+				tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+		}
+
+	case *DirectInstanceGroup:
+		if g.LaunchTemplate == nil {
+			return nil, nil, fmt.Errorf("instance group %q has no launch template", cg.HumanName)
+		}
+		version := strconv.FormatInt(aws.Int64Value(g.LaunchTemplate.DefaultVersionNumber), 10)
+		var err error
+		spec, err = findLaunchTemplateEnrollmentSpec(c, aws.StringValue(g.LaunchTemplate.LaunchTemplateId), version, tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Directly managed instances are found by these tags
+		for k, v := range c.Tags() {
+			tags[k] = v
+		}
+		tags[TagNameDirectInstanceGroup] = cg.InstanceGroup.ObjectMeta.Name
+
+	default:
+		return nil, nil, fmt.Errorf("unexpected type %T for instance group %q", cg.Raw, cg.HumanName)
+	}
+
+	return spec, tags, nil
+}
+
+// findLaunchTemplateEnrollmentSpec returns the LaunchSpec of the launch template version, adding the tags it gives
+// instances to tags
+func findLaunchTemplateEnrollmentSpec(c AWSCloud, templateID string, version string, tags map[string]string) (*LaunchSpec, error) {
+	spec, err := findLaunchTemplateVersionSpec(c, templateID, version)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("launch template %q version %s not found", templateID, version)
+	}
+
+	// The LaunchSpec doesn't include the tags, as they are compared by their hash
+	response, err := c.EC2().DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String(templateID),
+		Versions:         []*string{aws.String(version)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing launch template %q: %v", templateID, err)
+	}
+	for _, v := range response.LaunchTemplateVersions {
+		if v.LaunchTemplateData == nil {
+			continue
+		}
+		for _, ts := range v.LaunchTemplateData.TagSpecifications {
+			if aws.StringValue(ts.ResourceType) != ec2.ResourceTypeInstance {
+				continue
+			}
+			for _, t := range ts.Tags {
+				tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+		}
+	}
+	return spec, nil
+}
+
+// InstanceEnrollment is the set of changes that adopts an existing instance into an instance group
+type InstanceEnrollment struct {
+	InstanceID string
+	// Address is the address to connect to the instance on, to bootstrap it
+	Address string
+
+	// AssociateIAMInstanceProfile is the instance profile to associate with the instance, if it has none
+	AssociateIAMInstanceProfile string
+	// SecurityGroups are the security groups of the instance once it joins the group, if they must change
+	SecurityGroups []string
+	// Tags are the tags that must be added or changed
+	Tags map[string]string
+	// AutoscalingGroup is the autoscaling group to attach the instance to, for groups that are not directly managed
+	AutoscalingGroup string
+}
+
+// PlanInstanceEnrollment checks that the instance can join the instance group, and returns the changes to make
+func PlanInstanceEnrollment(cg *cloudinstances.CloudInstanceGroup, spec *LaunchSpec, tags map[string]string, i *ec2.Instance, internalIP bool) (*InstanceEnrollment, error) {
+	id := aws.StringValue(i.InstanceId)
+	if i.State == nil || aws.StringValue(i.State.Name) != ec2.InstanceStateNameRunning {
+		return nil, fmt.Errorf("instance %q is not running", id)
+	}
+	if group, found := FindEC2Tag(i.Tags, TagNameAutoscalingGroup); found {
+		return nil, fmt.Errorf("instance %q is already in autoscaling group %q", id, group)
+	}
+	if group, found := FindEC2Tag(i.Tags, TagNameDirectInstanceGroup); found {
+		return nil, fmt.Errorf("instance %q is already in instance group %q", id, group)
+	}
+
+	e := &InstanceEnrollment{
+		InstanceID: id,
+		Address:    aws.StringValue(i.PublicIpAddress),
+		Tags:       make(map[string]string),
+	}
+	if internalIP || e.Address == "" {
+		e.Address = aws.StringValue(i.PrivateIpAddress)
+	}
+
+	if g, ok := cg.Raw.(*autoscaling.Group); ok {
+		subnet := aws.StringValue(i.SubnetId)
+		found := false
+		for _, s := range strings.Split(aws.StringValue(g.VPCZoneIdentifier), ",") {
+			if strings.TrimSpace(s) == subnet {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("instance %q is in subnet %q, which is not a subnet of autoscaling group %q", id, subnet, aws.StringValue(g.AutoScalingGroupName))
+		}
+		e.AutoscalingGroup = aws.StringValue(g.AutoScalingGroupName)
+	}
+
+	// nodeup reads its configuration from the state store with the instance profile of the group
+	if i.IamInstanceProfile == nil {
+		e.AssociateIAMInstanceProfile = spec.IAMInstanceProfile
+	} else if spec.IAMInstanceProfile != "" && instanceProfileName(aws.StringValue(i.IamInstanceProfile.Arn)) != instanceProfileName(spec.IAMInstanceProfile) {
+		return nil, fmt.Errorf("instance %q has instance profile %q; it must have the instance profile of the instance group, %q", id, aws.StringValue(i.IamInstanceProfile.Arn), spec.IAMInstanceProfile)
+	}
+
+	// The security groups of the instance are kept, so existing access continues to work
+	groups := make(map[string]bool)
+	for _, g := range i.SecurityGroups {
+		groups[aws.StringValue(g.GroupId)] = true
+	}
+	missing := false
+	for _, g := range spec.SecurityGroups {
+		if !groups[g] {
+			groups[g] = true
+			missing = true
+		}
+	}
+	if missing {
+		for g := range groups {
+			e.SecurityGroups = append(e.SecurityGroups, g)
+		}
+		sort.Strings(e.SecurityGroups)
+	}
+
+	for k, v := range tags {
+		if actual, found := FindEC2Tag(i.Tags, k); !found || actual != v {
+			e.Tags[k] = v
+		}
+	}
+
+	return e, nil
+}
+
+// instanceProfileName returns the name of an instance profile, given its name or ARN
+func instanceProfileName(s string) string {
+	return s[strings.LastIndex(s, "/")+1:]
+}
+
+// ApplyInstanceEnrollment makes the instance look like an instance of the group, apart from attaching it to its
+// autoscaling group, which should be done once the instance has been bootstrapped
+func ApplyInstanceEnrollment(c AWSCloud, e *InstanceEnrollment) error {
+	if e.AssociateIAMInstanceProfile != "" {
+		profile := &ec2.IamInstanceProfileSpecification{}
+		if strings.HasPrefix(e.AssociateIAMInstanceProfile, "arn:") {
+			profile.Arn = aws.String(e.AssociateIAMInstanceProfile)
+		} else {
+			profile.Name = aws.String(e.AssociateIAMInstanceProfile)
+		}
+		glog.V(2).Infof("Associating instance profile %q with instance %q", e.AssociateIAMInstanceProfile, e.InstanceID)
+		if _, err := c.EC2().AssociateIamInstanceProfile(&ec2.AssociateIamInstanceProfileInput{
+			InstanceId:         aws.String(e.InstanceID),
+			IamInstanceProfile: profile,
+		}); err != nil {
+			return fmt.Errorf("error associating instance profile with instance %q: %v", e.InstanceID, err)
+		}
+	}
+
+	if len(e.SecurityGroups) != 0 {
+		glog.V(2).Infof("Setting security groups of instance %q to %v", e.InstanceID, e.SecurityGroups)
+		if _, err := c.EC2().ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+			InstanceId: aws.String(e.InstanceID),
+			Groups:     aws.StringSlice(e.SecurityGroups),
+		}); err != nil {
+			return fmt.Errorf("error setting security groups of instance %q: %v", e.InstanceID, err)
+		}
+	}
+
+	if len(e.Tags) != 0 {
+		if err := c.CreateTags(e.InstanceID, e.Tags); err != nil {
+			return fmt.Errorf("error tagging instance %q: %v", e.InstanceID, err)
+		}
+	}
+
+	return nil
+}
+
+// AttachEnrolledInstance attaches the instance to the autoscaling group of the enrollment, if it has one; the
+// desired capacity of the group grows by one
+func AttachEnrolledInstance(c AWSCloud, e *InstanceEnrollment) error {
+	if e.AutoscalingGroup == "" {
+		return nil
+	}
+	glog.V(2).Infof("Attaching instance %q to autoscaling group %q", e.InstanceID, e.AutoscalingGroup)
+	if _, err := c.Autoscaling().AttachInstances(&autoscaling.AttachInstancesInput{
+		AutoScalingGroupName: aws.String(e.AutoscalingGroup),
+		InstanceIds:          []*string{aws.String(e.InstanceID)},
+	}); err != nil {
+		return fmt.Errorf("error attaching instance %q to autoscaling group %q: %v", e.InstanceID, e.AutoscalingGroup, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+func buildEnrollInstance(id string, subnet string, tags map[string]string) *ec2.Instance {
+	i := &ec2.Instance{
+		InstanceId:       aws.String(id),
+		State:            &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+		SubnetId:         aws.String(subnet),
+		PrivateIpAddress: aws.String("172.20.32.10"),
+		PublicIpAddress:  aws.String("203.0.113.10"),
+		SecurityGroups:   []*ec2.GroupIdentifier{{GroupId: aws.String("sg-existing")}},
+	}
+	for k, v := range tags {
+		i.Tags = append(i.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	return i
+}
+
+func TestPlanInstanceEnrollment(t *testing.T) {
+	cg := &cloudinstances.CloudInstanceGroup{
+		HumanName: "nodes.test.k8s.local",
+		Raw: &autoscaling.Group{
+			AutoScalingGroupName: aws.String("nodes.test.k8s.local"),
+			VPCZoneIdentifier:    aws.String("subnet-a,subnet-b"),
+		},
+	}
+	spec := &LaunchSpec{
+		IAMInstanceProfile: "nodes.test.k8s.local",
+		SecurityGroups:     []string{"sg-nodes"},
+		UserData:           "#!/bin/bash\n",
+	}
+	tags := map[string]string{"KubernetesCluster": "test.k8s.local", "Name": "nodes.test.k8s.local"}
+
+	i := buildEnrollInstance("i-1", "subnet-b", map[string]string{"KubernetesCluster": "test.k8s.local", "Name": "legacy-node"})
+	e, err := PlanInstanceEnrollment(cg, spec, tags, i, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &InstanceEnrollment{
+		InstanceID:                  "i-1",
+		Address:                     "203.0.113.10",
+		AssociateIAMInstanceProfile: "nodes.test.k8s.local",
+		SecurityGroups:              []string{"sg-existing", "sg-nodes"},
+		Tags:                        map[string]string{"Name": "nodes.test.k8s.local"},
+		AutoscalingGroup:            "nodes.test.k8s.local",
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("expected %+v, got %+v", expected, e)
+	}
+
+	// An instance which already matches the group only needs attaching
+	i = buildEnrollInstance("i-2", "subnet-a", tags)
+	i.SecurityGroups = append(i.SecurityGroups, &ec2.GroupIdentifier{GroupId: aws.String("sg-nodes")})
+	i.IamInstanceProfile = &ec2.IamInstanceProfile{Arn: aws.String("arn:aws:iam::123456789012:instance-profile/nodes.test.k8s.local")}
+	e, err = PlanInstanceEnrollment(cg, spec, tags, i, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Address != "172.20.32.10" || e.AssociateIAMInstanceProfile != "" || len(e.SecurityGroups) != 0 || len(e.Tags) != 0 {
+		t.Errorf("expected no changes other than attaching, got %+v", e)
+	}
+
+	grid := []struct {
+		Description string
+		Instance    *ec2.Instance
+	}{
+		{
+			Description: "stopped instance",
+			Instance: func() *ec2.Instance {
+				i := buildEnrollInstance("i-3", "subnet-a", nil)
+				i.State.Name = aws.String(ec2.InstanceStateNameStopped)
+				return i
+			}(),
+		},
+		{
+			Description: "instance in another autoscaling group",
+			Instance:    buildEnrollInstance("i-4", "subnet-a", map[string]string{TagNameAutoscalingGroup: "other"}),
+		},
+		{
+			Description: "instance in another subnet",
+			Instance:    buildEnrollInstance("i-5", "subnet-c", nil),
+		},
+		{
+			Description: "instance with another instance profile",
+			Instance: func() *ec2.Instance {
+				i := buildEnrollInstance("i-6", "subnet-a", nil)
+				i.IamInstanceProfile = &ec2.IamInstanceProfile{Arn: aws.String("arn:aws:iam::123456789012:instance-profile/legacy")}
+				return i
+			}(),
+		},
+	}
+	for _, g := range grid {
+		if _, err := PlanInstanceEnrollment(cg, spec, tags, g.Instance, false); err == nil {
+			t.Errorf("expected error for %s", g.Description)
+		}
+	}
+}
+
+func TestPlanInstanceEnrollmentDirect(t *testing.T) {
+	cg := &cloudinstances.CloudInstanceGroup{
+		HumanName: "nodes.test.k8s.local",
+		Raw:       &DirectInstanceGroup{},
+	}
+	tags := map[string]string{TagNameDirectInstanceGroup: "nodes"}
+
+	e, err := PlanInstanceEnrollment(cg, &LaunchSpec{}, tags, buildEnrollInstance("i-1", "subnet-z", nil), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.AutoscalingGroup != "" || e.Tags[TagNameDirectInstanceGroup] != "nodes" {
+		t.Errorf("expected directly managed instance to be tagged, not attached, got %+v", e)
+	}
+
+	if _, err := PlanInstanceEnrollment(cg, &LaunchSpec{}, tags, buildEnrollInstance("i-2", "subnet-z", tags), false); err == nil {
+		t.Errorf("expected error for instance already in the group")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+)
+
+// TagNameInstanceConfigHash is set on autoscaling groups, and propagated to their instances, recording a hash of each
+// part of the configuration the instances are launched with, so that we can tell which instances need replacing and why
+const TagNameInstanceConfigHash = "kops.k8s.io/instance-config-hash"
+
+// The parts of the instance configuration that are hashed, which are also the reasons an instance needs updating
+const (
+	InstanceConfigImage          = "image"
+	InstanceConfigInstanceType   = "instanceType"
+	InstanceConfigUserData       = "userData"
+	InstanceConfigTags           = "tags"
+	InstanceConfigSecurityGroups = "securityGroups"
+)
+
+// instanceConfigHashLength is the length of the hash of each part; the tag value must fit in 256 characters
+const instanceConfigHashLength = 8
+
+// InstanceConfig is the effective configuration the instances of an instance group are launched with
+type InstanceConfig struct {
+	Image          string
+	InstanceType   string
+	UserData       string
+	Tags           map[string]string
+	SecurityGroups []string
+}
+
+// Hash returns a stable hash of each part of the configuration, e.g. image=1a2b3c4d,instanceType=...
+func (c *InstanceConfig) Hash() string {
+	var tags []string
+	for k, v := range c.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+
+	securityGroups := append([]string(nil), c.SecurityGroups...)
+	sort.Strings(securityGroups)
+
+	parts := []string{
+		InstanceConfigImage + "=" + hashInstanceConfigPart(c.Image),
+		InstanceConfigInstanceType + "=" + hashInstanceConfigPart(c.InstanceType),
+		InstanceConfigUserData + "=" + hashInstanceConfigPart(c.UserData),
+		InstanceConfigTags + "=" + hashInstanceConfigPart(strings.Join(tags, "\n")),
+		InstanceConfigSecurityGroups + "=" + hashInstanceConfigPart(strings.Join(securityGroups, "\n")),
+	}
+	return strings.Join(parts, ",")
+}
+
+func hashInstanceConfigPart(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])[:instanceConfigHashLength]
+}
+
+// parseInstanceConfigHash splits a hash returned by InstanceConfig.Hash into its parts
+func parseInstanceConfigHash(hash string) (map[string]string, error) {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(hash, ",") {
+		tokens := strings.SplitN(part, "=", 2)
+		if len(tokens) != 2 || tokens[0] == "" {
+			return nil, fmt.Errorf("unexpected instance config hash %q", hash)
+		}
+		parts[tokens[0]] = tokens[1]
+	}
+	return parts, nil
+}
+
+// InstanceConfigChanges returns the parts of the configuration that differ between the expected and actual hashes,
+// sorted. A part that is missing from either hash is reported as changed.
+func InstanceConfigChanges(expected string, actual string) ([]string, error) {
+	if expected == actual {
+		return nil, nil
+	}
+
+	e, err := parseInstanceConfigHash(expected)
+	if err != nil {
+		return nil, err
+	}
+	a, err := parseInstanceConfigHash(actual)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	for k, v := range e {
+		if a[k] != v {
+			changes = append(changes, k)
+		}
+	}
+	for k := range a {
+		if _, found := e[k]; !found {
+			changes = append(changes, k)
+		}
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// findInstanceConfigChanges returns the parts of the configuration that changed since each instance of the autoscaling
+// group was launched. Instances launched before kops recorded the hash are not included.
+func findInstanceConfigChanges(g *autoscaling.Group, instances map[string]*ec2.Instance) map[string][]string {
+	changes := make(map[string][]string)
+
+	expected := findASGTag(g, TagNameInstanceConfigHash)
+	if expected == "" {
+		return changes
+	}
+
+	for id, i := range instances {
+		actual, found := FindEC2Tag(i.Tags, TagNameInstanceConfigHash)
+		if !found {
+			continue
+		}
+		c, err := InstanceConfigChanges(expected, actual)
+		if err != nil {
+			glog.Warningf("ignoring instance config hash of %s: %v", id, err)
+			continue
+		}
+		if len(c) != 0 {
+			changes[id] = c
+		}
+	}
+	return changes
+}
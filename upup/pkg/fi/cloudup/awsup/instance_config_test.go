@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstanceConfigHash(t *testing.T) {
+	base := InstanceConfig{
+		Image:          "kope.io/k8s-1.9-debian-jessie-amd64-hvm-ebs-2018-03-11",
+		InstanceType:   "t2.medium",
+		UserData:       "#!/bin/bash\necho nodeup\n",
+		Tags:           map[string]string{"team": "payments", "KubernetesCluster": "test.k8s.local"},
+		SecurityGroups: []string{"nodes.test.k8s.local", "sg-12345678"},
+	}
+
+	reordered := base
+	reordered.SecurityGroups = []string{"sg-12345678", "nodes.test.k8s.local"}
+	if base.Hash() != reordered.Hash() {
+		t.Errorf("hash depends on the order of the security groups")
+	}
+	if len(base.Hash()) > 256 {
+		t.Errorf("hash %q does not fit in a tag value", base.Hash())
+	}
+
+	grid := []struct {
+		Change   func(c *InstanceConfig)
+		Expected []string
+	}{
+		{
+			Change:   func(c *InstanceConfig) {},
+			Expected: nil,
+		},
+		{
+			Change:   func(c *InstanceConfig) { c.Image = "kope.io/k8s-1.10-debian-jessie-amd64-hvm-ebs-2018-05-27" },
+			Expected: []string{InstanceConfigImage},
+		},
+		{
+			Change: func(c *InstanceConfig) {
+				c.InstanceType = "m5.large"
+				c.UserData = "#!/bin/bash\necho nodeup 1.10\n"
+			},
+			Expected: []string{InstanceConfigInstanceType, InstanceConfigUserData},
+		},
+		{
+			Change: func(c *InstanceConfig) {
+				c.Tags = map[string]string{"team": "billing", "KubernetesCluster": "test.k8s.local"}
+			},
+			Expected: []string{InstanceConfigTags},
+		},
+		{
+			Change:   func(c *InstanceConfig) { c.SecurityGroups = []string{"nodes.test.k8s.local"} },
+			Expected: []string{InstanceConfigSecurityGroups},
+		},
+	}
+
+	for i, g := range grid {
+		changed := base
+		g.Change(&changed)
+
+		actual, err := InstanceConfigChanges(changed.Hash(), base.Hash())
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("case %d: expected changes %v, got %v", i, g.Expected, actual)
+		}
+	}
+}
+
+func TestInstanceConfigChangesMissingParts(t *testing.T) {
+	c := &InstanceConfig{Image: "ami-12345678"}
+	expected := c.Hash()
+
+	// A hash recorded by a version of kops that hashed fewer parts
+	parts := strings.Split(expected, ",")
+	actual := strings.Join(parts[:len(parts)-1], ",")
+
+	changes, err := InstanceConfigChanges(expected, actual)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(changes, []string{InstanceConfigSecurityGroups}) {
+		t.Errorf("expected the missing part to be reported as changed, got %v", changes)
+	}
+
+	if _, err := InstanceConfigChanges(expected, "garbage"); err == nil {
+		t.Errorf("expected error parsing an invalid hash")
+	}
+}
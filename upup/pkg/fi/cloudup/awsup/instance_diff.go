@@ -0,0 +1,363 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"k8s.io/kops/pkg/cloudinstances"
+)
+
+// UserDataScript names the part of the user data outside of any embedded file
+const UserDataScript = "script"
+
+// LaunchSpec is the part of a launch configuration or launch template version that we compare
+type LaunchSpec struct {
+	Image              string
+	InstanceType       string
+	KeyName            string
+	IAMInstanceProfile string
+	SpotPrice          string
+	SecurityGroups     []string
+	Volumes            []string
+	UserData           string
+}
+
+// LaunchSpecDiff is a single difference between two launch specs
+type LaunchSpecDiff struct {
+	// Field is the name of the field which differs, e.g. image, or userData:kube_env.yaml for a section of the user data
+	Field string
+	Old   string
+	New   string
+}
+
+// InstanceLaunchDiff describes how the launch configuration that some instances of a group were launched from
+// differs from the current launch configuration of the group
+type InstanceLaunchDiff struct {
+	// From identifies the launch configuration, or launch template version, the instances were launched from
+	From string
+	// To identifies the current launch configuration, or launch template version, of the group
+	To string
+	// Instances are the ids of the instances launched from From
+	Instances []string
+	// Missing is true if From no longer exists, so can't be compared
+	Missing bool
+	// Diffs are the differences between From and To
+	Diffs []*LaunchSpecDiff
+}
+
+// DiffLaunchSpecs returns the differences between two launch specs; the user data is compared section by section
+func DiffLaunchSpecs(old *LaunchSpec, new *LaunchSpec) []*LaunchSpecDiff {
+	var diffs []*LaunchSpecDiff
+	add := func(field string, o, n string) {
+		if o != n {
+			diffs = append(diffs, &LaunchSpecDiff{Field: field, Old: o, New: n})
+		}
+	}
+
+	add(InstanceConfigImage, old.Image, new.Image)
+	add(InstanceConfigInstanceType, old.InstanceType, new.InstanceType)
+	add("keyName", old.KeyName, new.KeyName)
+	add("iamInstanceProfile", old.IAMInstanceProfile, new.IAMInstanceProfile)
+	add("spotPrice", old.SpotPrice, new.SpotPrice)
+	add(InstanceConfigSecurityGroups, strings.Join(old.SecurityGroups, ","), strings.Join(new.SecurityGroups, ","))
+	add("volumes", strings.Join(old.Volumes, "\n"), strings.Join(new.Volumes, "\n"))
+
+	if old.UserData != new.UserData {
+		oldSections := splitUserData(old.UserData)
+		newSections := splitUserData(new.UserData)
+
+		var names []string
+		for name := range oldSections {
+			names = append(names, name)
+		}
+		for name := range newSections {
+			if _, found := oldSections[name]; !found {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			add(InstanceConfigUserData+":"+name, oldSections[name], newSections[name])
+		}
+	}
+
+	return diffs
+}
+
+// heredocStart matches the start of a file embedded in a script, e.g. cat > kube_env.yaml << '__EOF_KUBE_ENV'
+var heredocStart = regexp.MustCompile(`^cat > (\S+) << '?([A-Za-z0-9_]+)'?\s*$`)
+
+// splitUserData splits the user data into the files it embeds, keyed by file name, with the rest of it under UserDataScript
+func splitUserData(userData string) map[string]string {
+	sections := make(map[string]string)
+
+	var script []string
+	name := ""
+	terminator := ""
+	var lines []string
+	for _, line := range strings.Split(userData, "\n") {
+		if terminator != "" {
+			if strings.TrimSpace(line) == terminator {
+				sections[name] = strings.Join(lines, "\n")
+				terminator = ""
+				lines = nil
+			} else {
+				lines = append(lines, line)
+			}
+			continue
+		}
+
+		if m := heredocStart.FindStringSubmatch(line); m != nil {
+			name = m[1]
+			terminator = m[2]
+			continue
+		}
+		script = append(script, line)
+	}
+	if terminator != "" {
+		// Unterminated, so we keep the lines with the script
+		script = append(script, lines...)
+	}
+
+	sections[UserDataScript] = strings.Join(script, "\n")
+	return sections
+}
+
+// DiffCloudInstanceGroup compares the launch configuration that each instance needing update was launched from
+// with the current launch configuration of the group; instances launched from the same configuration are grouped together
+func DiffCloudInstanceGroup(c AWSCloud, cg *cloudinstances.CloudInstanceGroup) ([]*InstanceLaunchDiff, error) {
+	if len(cg.NeedUpdate) == 0 {
+		return nil, nil
+	}
+
+	// from records the launch configuration (or "<template>:<version>") each instance was launched from
+	from := make(map[string]string)
+	var to string
+	var loadSpec func(id string) (*LaunchSpec, error)
+
+	switch g := cg.Raw.(type) {
+	case *autoscaling.Group:
+		if g.LaunchTemplate != nil {
+			instances, err := describeAutoscalingGroupInstances(c, g)
+			if err != nil {
+				return nil, err
+			}
+			to, from, err = findLaunchTemplateVersions(c, g, instances)
+			if err != nil {
+				return nil, err
+			}
+			loadSpec = func(id string) (*LaunchSpec, error) {
+				return findLaunchTemplateVersionSpec(c, aws.StringValue(g.LaunchTemplate.LaunchTemplateId), id)
+			}
+		} else {
+			to = aws.StringValue(g.LaunchConfigurationName)
+			for _, i := range g.Instances {
+				from[aws.StringValue(i.InstanceId)] = aws.StringValue(i.LaunchConfigurationName)
+			}
+			loadSpec = func(id string) (*LaunchSpec, error) {
+				return findLaunchConfigurationSpec(c, id)
+			}
+		}
+
+	case *DirectInstanceGroup:
+		if g.LaunchTemplate == nil {
+			return nil, fmt.Errorf("instance group %q has no launch template", cg.HumanName)
+		}
+		templateName := aws.StringValue(g.LaunchTemplate.LaunchTemplateName)
+		to = templateName + ":" + strconv.FormatInt(aws.Int64Value(g.LaunchTemplate.DefaultVersionNumber), 10)
+		for _, i := range g.Instances {
+			version, _ := FindEC2Tag(i.Tags, TagLaunchTemplateVersion)
+			from[aws.StringValue(i.InstanceId)] = templateName + ":" + version
+		}
+		loadSpec = func(id string) (*LaunchSpec, error) {
+			return findLaunchTemplateVersionSpec(c, aws.StringValue(g.LaunchTemplate.LaunchTemplateId), id)
+		}
+
+	default:
+		return nil, fmt.Errorf("unexpected type %T for instance group %q", cg.Raw, cg.HumanName)
+	}
+
+	byFrom := make(map[string]*InstanceLaunchDiff)
+	var diffs []*InstanceLaunchDiff
+	for _, member := range cg.NeedUpdate {
+		id := from[member.ID]
+		d := byFrom[id]
+		if d == nil {
+			d = &InstanceLaunchDiff{From: id, To: to}
+			byFrom[id] = d
+			diffs = append(diffs, d)
+		}
+		d.Instances = append(d.Instances, member.ID)
+	}
+
+	newSpec, err := loadSpec(to)
+	if err != nil {
+		return nil, err
+	}
+	if newSpec == nil {
+		return nil, fmt.Errorf("launch configuration %q of instance group %q not found", to, cg.HumanName)
+	}
+
+	for _, d := range diffs {
+		if d.From == d.To {
+			continue
+		}
+		oldSpec, err := loadSpec(d.From)
+		if err != nil {
+			return nil, err
+		}
+		if oldSpec == nil {
+			d.Missing = true
+			continue
+		}
+		d.Diffs = DiffLaunchSpecs(oldSpec, newSpec)
+	}
+
+	return diffs, nil
+}
+
+// findLaunchConfigurationSpec returns the LaunchSpec of the named launch configuration, or nil if it does not exist
+func findLaunchConfigurationSpec(c AWSCloud, name string) (*LaunchSpec, error) {
+	if name == "" {
+		return nil, nil
+	}
+	response, err := c.Autoscaling().DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+		LaunchConfigurationNames: []*string{aws.String(name)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing launch configuration %q: %v", name, err)
+	}
+	if len(response.LaunchConfigurations) == 0 {
+		return nil, nil
+	}
+	lc := response.LaunchConfigurations[0]
+
+	spec := &LaunchSpec{
+		Image:              aws.StringValue(lc.ImageId),
+		InstanceType:       aws.StringValue(lc.InstanceType),
+		KeyName:            aws.StringValue(lc.KeyName),
+		IAMInstanceProfile: aws.StringValue(lc.IamInstanceProfile),
+		SpotPrice:          aws.StringValue(lc.SpotPrice),
+	}
+	for _, sg := range lc.SecurityGroups {
+		spec.SecurityGroups = append(spec.SecurityGroups, aws.StringValue(sg))
+	}
+	sort.Strings(spec.SecurityGroups)
+	for _, b := range lc.BlockDeviceMappings {
+		if b.Ebs == nil {
+			continue
+		}
+		spec.Volumes = append(spec.Volumes, formatVolume(b.DeviceName, b.Ebs.VolumeType, b.Ebs.VolumeSize, b.Ebs.Iops))
+	}
+	sort.Strings(spec.Volumes)
+
+	if spec.UserData, err = decodeUserData(lc.UserData); err != nil {
+		return nil, fmt.Errorf("error decoding user data of launch configuration %q: %v", name, err)
+	}
+	return spec, nil
+}
+
+// findLaunchTemplateVersionSpec returns the LaunchSpec of a "<template>:<version>" version of the launch template,
+// or nil if the version does not exist
+func findLaunchTemplateVersionSpec(c AWSCloud, templateID string, id string) (*LaunchSpec, error) {
+	version := id[strings.LastIndex(id, ":")+1:]
+	if version == "" {
+		return nil, nil
+	}
+	response, err := c.EC2().DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String(templateID),
+		Versions:         []*string{aws.String(version)},
+	})
+	if err != nil {
+		if AWSErrorCode(err) == "InvalidLaunchTemplateId.VersionNotFound" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error describing launch template version %q: %v", id, err)
+	}
+	if len(response.LaunchTemplateVersions) == 0 || response.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return nil, nil
+	}
+	data := response.LaunchTemplateVersions[0].LaunchTemplateData
+
+	spec := &LaunchSpec{
+		Image:        aws.StringValue(data.ImageId),
+		InstanceType: aws.StringValue(data.InstanceType),
+		KeyName:      aws.StringValue(data.KeyName),
+	}
+	if data.IamInstanceProfile != nil {
+		spec.IAMInstanceProfile = aws.StringValue(data.IamInstanceProfile.Name)
+		if spec.IAMInstanceProfile == "" {
+			spec.IAMInstanceProfile = aws.StringValue(data.IamInstanceProfile.Arn)
+		}
+	}
+	if data.InstanceMarketOptions != nil && data.InstanceMarketOptions.SpotOptions != nil {
+		spec.SpotPrice = aws.StringValue(data.InstanceMarketOptions.SpotOptions.MaxPrice)
+	}
+	for _, sg := range data.SecurityGroupIds {
+		spec.SecurityGroups = append(spec.SecurityGroups, aws.StringValue(sg))
+	}
+	for _, ni := range data.NetworkInterfaces {
+		for _, sg := range ni.Groups {
+			spec.SecurityGroups = append(spec.SecurityGroups, aws.StringValue(sg))
+		}
+	}
+	sort.Strings(spec.SecurityGroups)
+	for _, b := range data.BlockDeviceMappings {
+		if b.Ebs == nil {
+			continue
+		}
+		spec.Volumes = append(spec.Volumes, formatVolume(b.DeviceName, b.Ebs.VolumeType, b.Ebs.VolumeSize, b.Ebs.Iops))
+	}
+	sort.Strings(spec.Volumes)
+
+	if spec.UserData, err = decodeUserData(data.UserData); err != nil {
+		return nil, fmt.Errorf("error decoding user data of launch template version %q: %v", id, err)
+	}
+	return spec, nil
+}
+
+// formatVolume returns a one line description of an EBS volume
+func formatVolume(deviceName *string, volumeType *string, size *int64, iops *int64) string {
+	s := fmt.Sprintf("%s %s %dGB", aws.StringValue(deviceName), aws.StringValue(volumeType), aws.Int64Value(size))
+	if iops != nil {
+		s += fmt.Sprintf(" %d iops", aws.Int64Value(iops))
+	}
+	return s
+}
+
+// decodeUserData decodes the base64 encoded user data of a launch configuration or launch template
+func decodeUserData(userData *string) (string, error) {
+	if userData == nil {
+		return "", nil
+	}
+	b, err := base64.StdEncoding.DecodeString(aws.StringValue(userData))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
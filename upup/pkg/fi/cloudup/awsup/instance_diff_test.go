@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testUserData = `#!/bin/bash
+NODEUP_HASH=abc
+
+cat > cluster_spec.yaml << '__EOF_CLUSTER_SPEC'
+cloudConfig: null
+docker:
+  version: 17.03.2
+__EOF_CLUSTER_SPEC
+
+cat > kube_env.yaml << '__EOF_KUBE_ENV'
+InstanceGroupName: nodes
+__EOF_KUBE_ENV
+
+download-release
+`
+
+func TestSplitUserData(t *testing.T) {
+	sections := splitUserData(testUserData)
+
+	expected := map[string]string{
+		"cluster_spec.yaml": "cloudConfig: null\ndocker:\n  version: 17.03.2",
+		"kube_env.yaml":     "InstanceGroupName: nodes",
+		UserDataScript:      "#!/bin/bash\nNODEUP_HASH=abc\n\n\n\ndownload-release\n",
+	}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("unexpected sections %q, expected %q", sections, expected)
+	}
+
+	unterminated := splitUserData("echo hello\ncat > a.yaml << 'EOF'\nfoo: bar\n")
+	if len(unterminated) != 1 || unterminated[UserDataScript] != "echo hello\nfoo: bar\n" {
+		t.Errorf("unexpected sections for unterminated file: %q", unterminated)
+	}
+}
+
+func TestDiffLaunchSpecs(t *testing.T) {
+	old := &LaunchSpec{
+		Image:          "ami-11111111",
+		InstanceType:   "t2.medium",
+		SecurityGroups: []string{"sg-1"},
+		Volumes:        []string{"/dev/xvda gp2 64GB"},
+		UserData:       testUserData,
+	}
+
+	same := *old
+	if diffs := DiffLaunchSpecs(old, &same); len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+
+	changed := *old
+	changed.Image = "ami-22222222"
+	changed.Volumes = []string{"/dev/xvda gp2 128GB"}
+	changed.UserData = strings.Replace(testUserData, "17.03.2", "18.06.1", 1)
+	changed.UserData = strings.Replace(changed.UserData, "download-release", "download-release --verbose", 1)
+
+	var fields []string
+	for _, d := range DiffLaunchSpecs(old, &changed) {
+		fields = append(fields, d.Field)
+		if d.Field == InstanceConfigImage && (d.Old != "ami-11111111" || d.New != "ami-22222222") {
+			t.Errorf("unexpected image difference %v", d)
+		}
+	}
+	expected := []string{"image", "volumes", "userData:cluster_spec.yaml", "userData:script"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("unexpected differences %v, expected %v", fields, expected)
+	}
+}
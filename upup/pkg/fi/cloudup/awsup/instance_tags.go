@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// TagNameInstanceTagsHash is set on autoscaling groups using a launch configuration, and propagated to their
+// instances, so that instances launched before a change of the instance group tags can be found
+const TagNameInstanceTagsHash = "kops.k8s.io/instance-tags-hash"
+
+// InstanceTagsHash returns a short, stable hash of the tags the instances of an instance group are launched with
+func InstanceTagsHash(tags map[string]string) string {
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, tags[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// findInstancesWithStaleTags returns the instances of an autoscaling group that were launched with tags
+// other than those the group currently propagates. Instances launched before kops recorded the hash are
+// assumed to be up to date.
+func findInstancesWithStaleTags(g *autoscaling.Group, instances map[string]*ec2.Instance) map[string]bool {
+	stale := make(map[string]bool)
+
+	expected := findASGTag(g, TagNameInstanceTagsHash)
+	if expected == "" {
+		return stale
+	}
+
+	for id, i := range instances {
+		actual, found := FindEC2Tag(i.Tags, TagNameInstanceTagsHash)
+		if found && actual != expected {
+			stale[id] = true
+		}
+	}
+	return stale
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"testing"
+)
+
+func TestInstanceTagsHash(t *testing.T) {
+	a := InstanceTagsHash(map[string]string{"team": "payments", "KubernetesCluster": "test.k8s.local"})
+	b := InstanceTagsHash(map[string]string{"KubernetesCluster": "test.k8s.local", "team": "payments"})
+	if a != b {
+		t.Errorf("hash depends on map order: %q != %q", a, b)
+	}
+	if len(a) != 16 {
+		t.Errorf("unexpected hash length %d", len(a))
+	}
+
+	c := InstanceTagsHash(map[string]string{"team": "billing", "KubernetesCluster": "test.k8s.local"})
+	if a == c {
+		t.Errorf("expected a different hash when a tag value changes")
+	}
+
+	d := InstanceTagsHash(map[string]string{"team": "payments", "KubernetesCluster": "test.k8s.local", "env": "prod"})
+	if a == d {
+		t.Errorf("expected a different hash when a tag is added")
+	}
+}
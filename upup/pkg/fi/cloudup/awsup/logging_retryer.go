@@ -23,24 +23,34 @@ import (
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/golang/glog"
+	"k8s.io/kops/pkg/retrypolicy"
 )
 
 // LoggingRetryer adds some logging when we are retrying, so we have some idea what is happening
 // Right now it is very basic - e.g. it only logs when we retry (so doesn't log when we fail due to too many retries)
 type LoggingRetryer struct {
 	client.DefaultRetryer
+
+	// policy overrides the number of retries and the delay between them, if set
+	policy retrypolicy.Policy
 }
 
 var _ request.Retryer = &LoggingRetryer{}
 
-func newLoggingRetryer(maxRetries int) *LoggingRetryer {
+func newLoggingRetryer(maxRetries int, policy retrypolicy.Policy) *LoggingRetryer {
 	return &LoggingRetryer{
-		client.DefaultRetryer{NumMaxRetries: maxRetries},
+		DefaultRetryer: client.DefaultRetryer{NumMaxRetries: policy.Retries(maxRetries)},
+		policy:         policy,
 	}
 }
 
 func (l LoggingRetryer) RetryRules(r *request.Request) time.Duration {
-	duration := l.DefaultRetryer.RetryRules(r)
+	var duration time.Duration
+	if l.policy.HasBackoff() {
+		duration = l.policy.Delay(r.RetryCount)
+	} else {
+		duration = l.DefaultRetryer.RetryRules(r)
+	}
 
 	service := r.ClientInfo.ServiceName
 	name := "?"
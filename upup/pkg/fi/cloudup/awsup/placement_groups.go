@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// PlacementStrategyPartition is the partition placement strategy, which the vendored aws-sdk-go predates
+const PlacementStrategyPartition = "partition"
+
+// FindPlacementGroup returns the placement group with the specified name, or nil if there is none
+func FindPlacementGroup(c AWSCloud, name string) (*ec2.PlacementGroup, error) {
+	request := &ec2.DescribePlacementGroupsInput{
+		GroupNames: []*string{aws.String(name)},
+	}
+	response, err := c.EC2().DescribePlacementGroups(request)
+	if err != nil {
+		if AWSErrorCode(err) == "InvalidPlacementGroup.Unknown" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing placement groups: %v", err)
+	}
+	if len(response.PlacementGroups) == 0 {
+		return nil, nil
+	}
+	if len(response.PlacementGroups) != 1 {
+		return nil, fmt.Errorf("found multiple placement groups with name %q", name)
+	}
+	return response.PlacementGroups[0], nil
+}
+
+// CreatePlacementGroup creates a placement group; partitionCount is only used with the partition strategy
+func CreatePlacementGroup(c AWSCloud, name string, strategy string, partitionCount *int64) error {
+	req, _ := c.EC2().CreatePlacementGroupRequest(&ec2.CreatePlacementGroupInput{
+		GroupName: aws.String(name),
+		Strategy:  aws.String(strategy),
+	})
+	if partitionCount != nil {
+		addQueryParameters(req, url.Values{"PartitionCount": []string{strconv.FormatInt(*partitionCount, 10)}})
+	}
+	if err := req.Send(); err != nil {
+		return fmt.Errorf("error creating placement group %q: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// addRequestTimeout bounds the duration of every request, including its retries, if timeout is not zero
+func addRequestTimeout(h *request.Handlers, timeout time.Duration) {
+	if timeout == 0 {
+		return
+	}
+
+	h.Validate.PushFrontNamed(request.NamedHandler{
+		Name: "kops/timeout",
+		Fn: func(r *request.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			r.SetContext(ctx)
+			r.Handlers.Complete.PushBack(func(*request.Request) {
+				cancel()
+			})
+		},
+	})
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/pkg/retrypolicy"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Second)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := aws.NewConfig().
+		WithRegion("us-east-1").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", ""))
+	config = request.WithRetryer(config, newLoggingRetryer(ClientMaxRetries, retrypolicy.Policy{Backoff: time.Millisecond}))
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		t.Fatalf("error building session: %v", err)
+	}
+	client := ec2.New(sess, config)
+	addRequestTimeout(&client.Handlers, 200*time.Millisecond)
+
+	start := time.Now()
+	_, err = client.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err == nil {
+		t.Fatalf("expected the request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("request was not interrupted by the timeout, took %v", elapsed)
+	}
+}
+
+func TestLoggingRetryerPolicy(t *testing.T) {
+	r := newLoggingRetryer(ClientMaxRetries, retrypolicy.Policy{})
+	if r.MaxRetries() != ClientMaxRetries {
+		t.Errorf("expected the default retries without a policy, got %d", r.MaxRetries())
+	}
+
+	r = newLoggingRetryer(ClientMaxRetries, retrypolicy.Policy{MaxAttempts: 3, Backoff: time.Second, MaxBackoff: 3 * time.Second})
+	if r.MaxRetries() != 2 {
+		t.Errorf("expected 2 retries for 3 attempts, got %d", r.MaxRetries())
+	}
+
+	req := &request.Request{
+		ClientInfo: metadata.ClientInfo{ServiceName: "ec2"},
+		Error:      fmt.Errorf("connection reset"),
+		RetryCount: 2,
+	}
+	if d := r.RetryRules(req); d != 3*time.Second {
+		t.Errorf("expected the policy backoff, got %v", d)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"k8s.io/kops/pkg/cloudtrace"
+	"k8s.io/kops/pkg/discoverycache"
+)
+
+// RequestTracer records every AWS request in the cloud trace, including retries and throttling
+type RequestTracer struct {
+	mutex sync.Mutex
+	// throttled counts the throttled attempts for in-flight requests
+	throttled map[*request.Request]int
+}
+
+func newRequestTracer() *RequestTracer {
+	return &RequestTracer{
+		throttled: make(map[*request.Request]int),
+	}
+}
+
+// addHandlers registers the tracing handlers, if tracing is enabled
+func (t *RequestTracer) addHandlers(h *request.Handlers) {
+	if cloudtrace.Active() == nil {
+		return
+	}
+
+	h.Retry.PushBackNamed(request.NamedHandler{
+		Name: "kops/trace-retry",
+		Fn:   t.retry,
+	})
+	h.Complete.PushBackNamed(request.NamedHandler{
+		Name: "kops/trace-complete",
+		Fn:   t.complete,
+	})
+}
+
+// retry is called after every failed attempt
+func (t *RequestTracer) retry(r *request.Request) {
+	if !r.IsErrorThrottle() {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.throttled[r]++
+}
+
+// complete is called once the request has finished, successfully or not
+func (t *RequestTracer) complete(r *request.Request) {
+	t.mutex.Lock()
+	throttled := t.throttled[r]
+	delete(t.throttled, r)
+	t.mutex.Unlock()
+
+	call := &cloudtrace.Call{
+		Cloud:     "aws",
+		Service:   r.ClientInfo.ServiceName,
+		Operation: "?",
+		Start:     r.Time,
+		Duration:  time.Since(r.Time),
+		Retries:   r.RetryCount,
+		Throttled: throttled,
+	}
+	if r.Operation != nil {
+		call.Operation = r.Operation.Name
+	}
+	if r.Error != nil {
+		call.Error = r.Error.Error()
+	}
+	if r.HTTPResponse != nil && r.HTTPResponse.Header.Get(discoverycache.HitHeader) != "" {
+		call.Cached = true
+	}
+	cloudtrace.Record(call)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// The vendored aws-sdk-go predates some newer EC2 parameters, such as the instance metadata options of launch
+// templates and the throughput of gp3 volumes, so until it is updated we add them to the query parameters of the
+// requests, and read them back from the raw responses.
+
+// InstanceMetadataOptions are the instance metadata service options of a launch template
+type InstanceMetadataOptions struct {
+	HTTPTokens              *string `xml:"httpTokens"`
+	HTTPPutResponseHopLimit *int64  `xml:"httpPutResponseHopLimit"`
+}
+
+// LaunchTemplateParameters are the launch template parameters the vendored aws-sdk-go does not support
+type LaunchTemplateParameters struct {
+	MetadataOptions *InstanceMetadataOptions
+	// VolumeThroughput is the throughput in MiB/s of the EBS volumes, by device name
+	VolumeThroughput map[string]int64
+}
+
+// CreateLaunchTemplate creates a launch template with the additional parameters
+func CreateLaunchTemplate(c AWSCloud, input *ec2.CreateLaunchTemplateInput, params *LaunchTemplateParameters) (*ec2.CreateLaunchTemplateOutput, error) {
+	req, out := c.EC2().CreateLaunchTemplateRequest(input)
+	addQueryParameters(req, params.queryParameters(input.LaunchTemplateData))
+	return out, req.Send()
+}
+
+// CreateLaunchTemplateVersion creates a launch template version with the additional parameters
+func CreateLaunchTemplateVersion(c AWSCloud, input *ec2.CreateLaunchTemplateVersionInput, params *LaunchTemplateParameters) (*ec2.CreateLaunchTemplateVersionOutput, error) {
+	req, out := c.EC2().CreateLaunchTemplateVersionRequest(input)
+	addQueryParameters(req, params.queryParameters(input.LaunchTemplateData))
+	return out, req.Send()
+}
+
+// DescribeLaunchTemplateVersions describes launch template versions, returning the additional parameters of each
+// version alongside the versions
+func DescribeLaunchTemplateVersions(c AWSCloud, input *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, []*LaunchTemplateParameters, error) {
+	return describeLaunchTemplateVersions(c.EC2(), input)
+}
+
+func describeLaunchTemplateVersions(svc ec2iface.EC2API, input *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, []*LaunchTemplateParameters, error) {
+	req, out := svc.DescribeLaunchTemplateVersionsRequest(input)
+	raw := captureResponseBody(req)
+
+	if err := req.Send(); err != nil {
+		return nil, nil, err
+	}
+
+	var parsed struct {
+		Versions []struct {
+			Data struct {
+				MetadataOptions     *InstanceMetadataOptions `xml:"metadataOptions"`
+				BlockDeviceMappings []struct {
+					DeviceName string `xml:"deviceName"`
+					Ebs        struct {
+						Throughput *int64 `xml:"throughput"`
+					} `xml:"ebs"`
+				} `xml:"blockDeviceMappingSet>item"`
+			} `xml:"launchTemplateData"`
+		} `xml:"launchTemplateVersionSet>item"`
+	}
+	if err := xml.Unmarshal(*raw, &parsed); err != nil {
+		return nil, nil, fmt.Errorf("error parsing launch template versions: %v", err)
+	}
+
+	params := make([]*LaunchTemplateParameters, len(out.LaunchTemplateVersions))
+	for i := range params {
+		params[i] = &LaunchTemplateParameters{}
+		if i >= len(parsed.Versions) {
+			continue
+		}
+		data := parsed.Versions[i].Data
+		params[i].MetadataOptions = data.MetadataOptions
+		for _, b := range data.BlockDeviceMappings {
+			if b.Ebs.Throughput != nil {
+				if params[i].VolumeThroughput == nil {
+					params[i].VolumeThroughput = make(map[string]int64)
+				}
+				params[i].VolumeThroughput[b.DeviceName] = *b.Ebs.Throughput
+			}
+		}
+	}
+	return out, params, nil
+}
+
+// queryParameters returns the query parameters for the launch template data
+func (p *LaunchTemplateParameters) queryParameters(data *ec2.RequestLaunchTemplateData) url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+
+	if p.MetadataOptions != nil {
+		if p.MetadataOptions.HTTPTokens != nil {
+			values.Set("LaunchTemplateData.MetadataOptions.HttpTokens", *p.MetadataOptions.HTTPTokens)
+		}
+		if p.MetadataOptions.HTTPPutResponseHopLimit != nil {
+			values.Set("LaunchTemplateData.MetadataOptions.HttpPutResponseHopLimit", strconv.FormatInt(*p.MetadataOptions.HTTPPutResponseHopLimit, 10))
+		}
+	}
+
+	if data != nil {
+		for i, b := range data.BlockDeviceMappings {
+			throughput, found := p.VolumeThroughput[aws.StringValue(b.DeviceName)]
+			if !found {
+				continue
+			}
+			values.Set(fmt.Sprintf("LaunchTemplateData.BlockDeviceMapping.%d.Ebs.Throughput", i+1), strconv.FormatInt(throughput, 10))
+		}
+	}
+
+	return values
+}
+
+// CreateVolume creates an EBS volume; throughput is only used for gp3 volumes
+func CreateVolume(c AWSCloud, input *ec2.CreateVolumeInput, throughput *int64) (*ec2.Volume, error) {
+	req, out := c.EC2().CreateVolumeRequest(input)
+	if throughput != nil {
+		addQueryParameters(req, url.Values{"Throughput": []string{strconv.FormatInt(*throughput, 10)}})
+	}
+	return out, req.Send()
+}
+
+// captureResponseBody keeps a copy of the raw response body of the request, for parsing the fields the SDK drops
+func captureResponseBody(req *request.Request) *[]byte {
+	raw := &[]byte{}
+	req.Handlers.Unmarshal.PushFront(func(r *request.Request) {
+		b, err := ioutil.ReadAll(r.HTTPResponse.Body)
+		r.HTTPResponse.Body.Close()
+		if err != nil {
+			r.Error = err
+			return
+		}
+		*raw = b
+		r.HTTPResponse.Body = ioutil.NopCloser(bytes.NewReader(b))
+	})
+	return raw
+}
+
+// addQueryParameters adds parameters the SDK does not know about to an EC2 query request, once the SDK has built
+// the request body
+func addQueryParameters(req *request.Request, params url.Values) {
+	if len(params) == 0 {
+		return
+	}
+
+	req.Handlers.Build.PushBack(func(r *request.Request) {
+		if r.Error != nil {
+			return
+		}
+		if _, err := r.Body.Seek(0, 0); err != nil {
+			r.Error = err
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			r.Error = err
+			return
+		}
+		for k, v := range params {
+			values[k] = v
+		}
+		r.SetBufferBody([]byte(values.Encode()))
+	})
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func newTestEC2(t *testing.T, handler http.HandlerFunc) (*ec2.EC2, func()) {
+	server := httptest.NewServer(handler)
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String("us-test-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if err != nil {
+		t.Fatalf("error building session: %v", err)
+	}
+	return ec2.New(sess), server.Close
+}
+
+func TestLaunchTemplateParameters(t *testing.T) {
+	var query url.Values
+	svc, done := newTestEC2(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		query, _ = url.ParseQuery(string(body))
+		w.Write([]byte(`<CreateLaunchTemplateResponse><launchTemplate><launchTemplateId>lt-1</launchTemplateId></launchTemplate></CreateLaunchTemplateResponse>`))
+	})
+	defer done()
+
+	input := &ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: aws.String("nodes"),
+		LaunchTemplateData: &ec2.RequestLaunchTemplateData{
+			InstanceType: aws.String("t2.medium"),
+			BlockDeviceMappings: []*ec2.LaunchTemplateBlockDeviceMappingRequest{
+				{DeviceName: aws.String("/dev/xvda"), Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{VolumeType: aws.String("gp3")}},
+				{DeviceName: aws.String("/dev/sdc"), VirtualName: aws.String("ephemeral0")},
+			},
+		},
+	}
+	params := &LaunchTemplateParameters{
+		MetadataOptions:  &InstanceMetadataOptions{HTTPTokens: aws.String("required"), HTTPPutResponseHopLimit: aws.Int64(1)},
+		VolumeThroughput: map[string]int64{"/dev/xvda": 250},
+	}
+	req, out := svc.CreateLaunchTemplateRequest(input)
+	addQueryParameters(req, params.queryParameters(input.LaunchTemplateData))
+	if err := req.Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if aws.StringValue(out.LaunchTemplate.LaunchTemplateId) != "lt-1" {
+		t.Errorf("unexpected response %v", out)
+	}
+	expected := map[string]string{
+		"Action":                          "CreateLaunchTemplate",
+		"LaunchTemplateName":              "nodes",
+		"LaunchTemplateData.InstanceType": "t2.medium",
+		"LaunchTemplateData.MetadataOptions.HttpTokens":              "required",
+		"LaunchTemplateData.MetadataOptions.HttpPutResponseHopLimit": "1",
+		"LaunchTemplateData.BlockDeviceMapping.1.DeviceName":         "/dev/xvda",
+		"LaunchTemplateData.BlockDeviceMapping.1.Ebs.Throughput":     "250",
+		"LaunchTemplateData.BlockDeviceMapping.2.Ebs.Throughput":     "",
+	}
+	for k, v := range expected {
+		if query.Get(k) != v {
+			t.Errorf("expected %s=%q, got %q", k, v, query.Get(k))
+		}
+	}
+}
+
+func TestDescribeLaunchTemplateVersionsParameters(t *testing.T) {
+	svc, done := newTestEC2(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<DescribeLaunchTemplateVersionsResponse>
+  <launchTemplateVersionSet>
+    <item>
+      <versionNumber>2</versionNumber>
+      <launchTemplateData>
+        <instanceType>t2.medium</instanceType>
+        <blockDeviceMappingSet>
+          <item>
+            <deviceName>/dev/xvda</deviceName>
+            <ebs>
+              <volumeType>gp3</volumeType>
+              <throughput>250</throughput>
+            </ebs>
+          </item>
+        </blockDeviceMappingSet>
+        <metadataOptions>
+          <httpTokens>required</httpTokens>
+          <httpPutResponseHopLimit>1</httpPutResponseHopLimit>
+        </metadataOptions>
+      </launchTemplateData>
+    </item>
+    <item>
+      <versionNumber>1</versionNumber>
+      <launchTemplateData>
+        <instanceType>t2.small</instanceType>
+      </launchTemplateData>
+    </item>
+  </launchTemplateVersionSet>
+</DescribeLaunchTemplateVersionsResponse>`))
+	})
+	defer done()
+
+	out, params, err := describeLaunchTemplateVersions(svc, &ec2.DescribeLaunchTemplateVersionsInput{LaunchTemplateId: aws.String("lt-1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.LaunchTemplateVersions) != 2 || len(params) != 2 {
+		t.Fatalf("expected 2 versions, got %d with %d parameters", len(out.LaunchTemplateVersions), len(params))
+	}
+	if aws.StringValue(out.LaunchTemplateVersions[0].LaunchTemplateData.InstanceType) != "t2.medium" {
+		t.Errorf("response was not unmarshalled: %v", out)
+	}
+	options := params[0].MetadataOptions
+	if options == nil || aws.StringValue(options.HTTPTokens) != "required" || aws.Int64Value(options.HTTPPutResponseHopLimit) != 1 {
+		t.Errorf("unexpected metadata options for version 2: %+v", options)
+	}
+	if params[0].VolumeThroughput["/dev/xvda"] != 250 {
+		t.Errorf("unexpected volume throughput for version 2: %v", params[0].VolumeThroughput)
+	}
+	if params[1].MetadataOptions != nil || params[1].VolumeThroughput != nil {
+		t.Errorf("expected no parameters for version 1, got %+v", params[1])
+	}
+}
@@ -18,6 +18,7 @@ package baremetal
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/golang/glog"
 
@@ -26,16 +27,30 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 type Cloud struct {
 	dns dnsprovider.Interface
+
+	// stateBase is where the configuration of each host is recorded, under the cluster's config base
+	stateBase vfs.Path
+
+	// Enroller runs nodeup on hosts once they have been imaged; hosts can't be imaged without one
+	Enroller Enroller
+
+	// ImageTimeout is how long to wait for an imaged host to become reachable over SSH
+	ImageTimeout time.Duration
 }
 
 var _ fi.Cloud = &Cloud{}
 
-func NewCloud(dns dnsprovider.Interface) (*Cloud, error) {
-	return &Cloud{dns: dns}, nil
+// DefaultImageTimeout is the default time allowed for a host to network boot, install the node image and restart
+const DefaultImageTimeout = 30 * time.Minute
+
+// NewCloud builds a bare metal cloud, recording the state of hosts under stateBase
+func NewCloud(dns dnsprovider.Interface, stateBase vfs.Path) (*Cloud, error) {
+	return &Cloud{dns: dns, stateBase: stateBase, ImageTimeout: DefaultImageTimeout}, nil
 }
 
 func (c *Cloud) ProviderID() kops.CloudProviderID {
@@ -50,23 +65,71 @@ func (c *Cloud) FindVPCInfo(id string) (*fi.VPCInfo, error) {
 	return nil, fmt.Errorf("baremetal FindVPCInfo not supported")
 }
 
-// GetCloudGroups is not implemented yet, that needs to return the instances and groups that back a kops cluster.
-// Baremetal may not support this.
+// GetCloudGroups returns a group for each instance group with bare metal hosts, with a member per host.
+// Hosts need updating when they have never been imaged, or when the configuration published by update cluster
+// has changed since they were last imaged.
 func (c *Cloud) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
-	glog.V(8).Infof("baremetal cloud GetCloudGroups not implemented yet")
-	return nil, fmt.Errorf("baremetal provider does not support getting cloud groups at this time")
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+
+	for _, ig := range instancegroups {
+		if ig.Spec.BareMetal == nil {
+			if warnUnmatched {
+				glog.Warningf("instance group %q has no bare metal hosts", ig.ObjectMeta.Name)
+			}
+			continue
+		}
+
+		hosts := ig.Spec.BareMetal.Hosts
+		group := &cloudinstances.CloudInstanceGroup{
+			HumanName:     ig.ObjectMeta.Name,
+			InstanceGroup: ig,
+			MinSize:       len(hosts),
+			MaxSize:       len(hosts),
+		}
+
+		nodeMap := hostNodeMap(hosts, nodes)
+		for i := range hosts {
+			reasons, err := c.updateReasons(hosts[i].Name)
+			if err != nil {
+				return nil, err
+			}
+			if err := group.NewCloudInstanceGroupMemberWithReasons(hosts[i].Name, reasons, nodeMap); err != nil {
+				return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
+			}
+		}
+
+		groups[ig.ObjectMeta.Name] = group
+	}
+
+	return groups, nil
 }
 
-// DeleteGroup is not implemented yet, is a func that needs to delete a DO instance group.
-// Baremetal may not support this.
+// DeleteGroup powers off the hosts of the group; they are imaged again if they are added back to a group
 func (c *Cloud) DeleteGroup(g *cloudinstances.CloudInstanceGroup) error {
-	glog.V(8).Infof("baremetal cloud provider DeleteGroup not implemented yet")
-	return fmt.Errorf("baremetal cloud provider does not support deleting cloud groups at this time")
+	spec := g.InstanceGroup.Spec.BareMetal
+	if spec == nil {
+		return nil
+	}
+
+	for i := range spec.Hosts {
+		host := &spec.Hosts[i]
+		glog.Infof("powering off host %q", host.Name)
+		if err := ipmiPowerOff(host); err != nil {
+			return err
+		}
+		if err := c.forgetHost(host.Name); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-//DeleteInstance is not implemented yet, is func needs to delete a DO instance.
-//Baremetal may not support this.
-func (c *Cloud) DeleteInstance(instance *cloudinstances.CloudInstanceGroupMember) error {
-	glog.V(8).Infof("baremetal cloud provider DeleteInstance not implemented yet")
-	return fmt.Errorf("baremetal cloud provider does not support deleting cloud instances at this time")
+// DeleteInstance re-images the host: it is network booted to install the node image, and then enrolled again
+func (c *Cloud) DeleteInstance(i *cloudinstances.CloudInstanceGroupMember) error {
+	ig := i.CloudInstanceGroup.InstanceGroup
+	host := FindHost(ig, i.ID)
+	if host == nil {
+		return fmt.Errorf("host %q not found in instance group %q", i.ID, ig.ObjectMeta.Name)
+	}
+	return c.Reimage(ig, host)
 }
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestPXEConfigFile(t *testing.T) {
+	file, err := PXEConfigFile("52:54:00:AB:CD:EF")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != "01-52-54-00-ab-cd-ef" {
+		t.Errorf("unexpected file %q", file)
+	}
+
+	if _, err := PXEConfigFile("52:54:00"); err == nil {
+		t.Errorf("expected an error for an invalid MAC address")
+	}
+}
+
+func TestBuildPXEConfig(t *testing.T) {
+	pxe := &kops.PXESpec{Kernel: "ubuntu/linux", Initrd: "ubuntu/initrd.gz", Args: "auto=true url=http://10.0.0.1/preseed.cfg"}
+	host := &kops.BareMetalHostSpec{Name: "node-1"}
+
+	expected := `# Managed by kops, for host node-1
+DEFAULT kops
+LABEL kops
+  KERNEL ubuntu/linux
+  APPEND initrd=ubuntu/initrd.gz auto=true url=http://10.0.0.1/preseed.cfg
+`
+	if actual := BuildPXEConfig(pxe, host); actual != expected {
+		t.Errorf("unexpected PXE config:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestGetCloudGroups(t *testing.T) {
+	stateBase := vfs.NewMemFSPath(vfs.NewMemFSContext(), "baremetal")
+	cloud, err := NewCloud(nil, stateBase)
+	if err != nil {
+		t.Fatalf("error building cloud: %v", err)
+	}
+
+	ig := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: kops.InstanceGroupSpec{
+			BareMetal: &kops.BareMetalSpec{
+				Hosts: []kops.BareMetalHostSpec{
+					{Name: "node-1", Address: "10.0.0.11"},
+					{Name: "node-2", Address: "10.0.0.12"},
+					{Name: "node-3", Address: "10.0.0.13"},
+				},
+			},
+		},
+	}
+
+	// node-1 is up to date, node-2 was imaged with an older configuration and node-3 was never imaged
+	for host, hashes := range map[string][]string{"node-1": {"a", "a"}, "node-2": {"b", "a"}} {
+		if err := cloud.SetDesiredConfigHash(host, hashes[0]); err != nil {
+			t.Fatalf("error setting desired hash: %v", err)
+		}
+		if err := cloud.writeState(host, stateImaged, hashes[1]); err != nil {
+			t.Fatalf("error setting imaged hash: %v", err)
+		}
+	}
+
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2.example.com"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.12"}}},
+		},
+	}
+
+	groups, err := cloud.GetCloudGroups(&kops.Cluster{}, []*kops.InstanceGroup{ig}, false, nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	group := groups["nodes"]
+	if group == nil {
+		t.Fatalf("group not found: %v", groups)
+	}
+	if group.MinSize != 3 || group.MaxSize != 3 {
+		t.Errorf("unexpected size %d-%d", group.MinSize, group.MaxSize)
+	}
+
+	if len(group.Ready) != 1 || group.Ready[0].ID != "node-1" || group.Ready[0].Node == nil {
+		t.Errorf("expected node-1 to be ready, with its node: %v", group.Ready)
+	}
+
+	reasons := make(map[string][]string)
+	for _, m := range group.NeedUpdate {
+		reasons[m.ID] = m.UpdateReasons
+		if m.ID == "node-2" && (m.Node == nil || m.Node.Name != "node-2.example.com") {
+			t.Errorf("expected node-2 to be matched to its node by address")
+		}
+	}
+	expected := map[string][]string{
+		"node-2": {cloudinstances.ReasonConfigurationChanged},
+		"node-3": {ReasonNotImaged},
+	}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("unexpected update reasons %v, expected %v", reasons, expected)
+	}
+}
+
+func TestIPMINetworkBoot(t *testing.T) {
+	var commands []string
+	runIPMITool = func(env []string, args ...string) ([]byte, error) {
+		if !reflect.DeepEqual(env, []string{"IPMI_PASSWORD="}) {
+			t.Errorf("unexpected environment %v", env)
+		}
+		commands = append(commands, strings.Join(args, " "))
+		if args[len(args)-1] == "status" {
+			return []byte("Chassis Power is on\n"), nil
+		}
+		return nil, nil
+	}
+
+	host := &kops.BareMetalHostSpec{
+		Name: "node-1",
+		IPMI: &kops.IPMISpec{Address: "10.1.0.11", Username: "admin", PasswordEnv: "KOPS_TEST_UNSET_IPMI_PASSWORD"},
+	}
+	if err := ipmiNetworkBoot(host); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	prefix := "-I lanplus -H 10.1.0.11 -U admin -E "
+	expected := []string{
+		prefix + "chassis bootdev pxe",
+		prefix + "chassis power status",
+		prefix + "chassis power cycle",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("unexpected commands %v, expected %v", commands, expected)
+	}
+}
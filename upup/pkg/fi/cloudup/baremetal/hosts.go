@@ -0,0 +1,212 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// Enroller installs kops on a host that has just been imaged, so that it joins the cluster as a node
+type Enroller interface {
+	Enroll(ig *kops.InstanceGroup, host *kops.BareMetalHostSpec) error
+}
+
+// ReasonNotImaged is the update reason of hosts that kops has never imaged
+const ReasonNotImaged = "not imaged"
+
+const (
+	// stateDesired holds the hash of the configuration that update cluster last published for the host
+	stateDesired = "desired"
+	// stateImaged holds the hash of the configuration the host was last imaged with
+	stateImaged = "imaged"
+)
+
+// sshPollInterval is how often we check whether a host that is being imaged accepts SSH connections
+var sshPollInterval = 10 * time.Second
+
+// FindHost returns the host of the instance group with the given name, or nil if there is none
+func FindHost(ig *kops.InstanceGroup, name string) *kops.BareMetalHostSpec {
+	if ig.Spec.BareMetal == nil {
+		return nil
+	}
+	for i := range ig.Spec.BareMetal.Hosts {
+		if ig.Spec.BareMetal.Hosts[i].Name == name {
+			return &ig.Spec.BareMetal.Hosts[i]
+		}
+	}
+	return nil
+}
+
+// IsImaged returns true if kops has imaged the host before
+func (c *Cloud) IsImaged(host string) (bool, error) {
+	_, found, err := c.readState(host, stateImaged)
+	return found, err
+}
+
+// DesiredConfigHash returns the hash of the configuration that update cluster last published for the host, if any
+func (c *Cloud) DesiredConfigHash(host string) (string, bool, error) {
+	return c.readState(host, stateDesired)
+}
+
+// SetDesiredConfigHash records the hash of the configuration that the host should be imaged with
+func (c *Cloud) SetDesiredConfigHash(host string, hash string) error {
+	return c.writeState(host, stateDesired, hash)
+}
+
+// Reimage network boots the host to install the node image, waits for it to restart and enrolls it in the cluster.
+// The host then records the configuration it was imaged with, so that it isn't reported as needing an update.
+func (c *Cloud) Reimage(ig *kops.InstanceGroup, host *kops.BareMetalHostSpec) error {
+	if c.Enroller == nil {
+		return fmt.Errorf("cannot image host %q: no enroller is configured", host.Name)
+	}
+
+	desired, _, err := c.DesiredConfigHash(host.Name)
+	if err != nil {
+		return err
+	}
+
+	glog.Infof("network booting host %q to install the node image", host.Name)
+	if err := ipmiNetworkBoot(host); err != nil {
+		return err
+	}
+
+	if err := waitForSSH(host.Address, c.ImageTimeout); err != nil {
+		return fmt.Errorf("host %q did not come back after imaging: %v", host.Name, err)
+	}
+
+	glog.Infof("enrolling host %q", host.Name)
+	if err := c.Enroller.Enroll(ig, host); err != nil {
+		return fmt.Errorf("error enrolling host %q: %v", host.Name, err)
+	}
+
+	return c.writeState(host.Name, stateImaged, desired)
+}
+
+// updateReasons returns why the host needs to be imaged again, if it does
+func (c *Cloud) updateReasons(host string) ([]string, error) {
+	imaged, found, err := c.readState(host, stateImaged)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return []string{ReasonNotImaged}, nil
+	}
+
+	desired, found, err := c.DesiredConfigHash(host)
+	if err != nil {
+		return nil, err
+	}
+	if found && desired != imaged {
+		return []string{cloudinstances.ReasonConfigurationChanged}, nil
+	}
+	return nil, nil
+}
+
+// forgetHost removes the recorded state of the host
+func (c *Cloud) forgetHost(host string) error {
+	for _, name := range []string{stateDesired, stateImaged} {
+		p, err := c.statePath(host, name)
+		if err != nil {
+			return err
+		}
+		if err := p.Remove(); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %q: %v", p, err)
+		}
+	}
+	return nil
+}
+
+func (c *Cloud) statePath(host string, name string) (vfs.Path, error) {
+	if c.stateBase == nil {
+		return nil, fmt.Errorf("the config base of the cluster is not set, so the state of bare metal hosts can't be recorded")
+	}
+	return c.stateBase.Join(host, name), nil
+}
+
+func (c *Cloud) readState(host string, name string) (string, bool, error) {
+	p, err := c.statePath(host, name)
+	if err != nil {
+		return "", false, err
+	}
+	b, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("error reading %q: %v", p, err)
+	}
+	return string(b), true, nil
+}
+
+func (c *Cloud) writeState(host string, name string, value string) error {
+	p, err := c.statePath(host, name)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteFile(bytes.NewReader([]byte(value)), nil); err != nil {
+		return fmt.Errorf("error writing %q: %v", p, err)
+	}
+	return nil
+}
+
+// hostNodeMap maps the name of each host to its node, matching nodes by name or by address
+func hostNodeMap(hosts []kops.BareMetalHostSpec, nodes []v1.Node) map[string]*v1.Node {
+	nodeMap := make(map[string]*v1.Node)
+	for i := range nodes {
+		node := &nodes[i]
+		for _, host := range hosts {
+			if node.Name == host.Name {
+				nodeMap[host.Name] = node
+				continue
+			}
+			for _, address := range node.Status.Addresses {
+				if address.Address == host.Address {
+					nodeMap[host.Name] = node
+				}
+			}
+		}
+	}
+	return nodeMap
+}
+
+// waitForSSH waits until the host accepts connections on the SSH port
+func waitForSSH(address string, timeout time.Duration) error {
+	endpoint := net.JoinHostPort(address, "22")
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", endpoint, sshPollInterval)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for SSH on %s: %v", endpoint, err)
+		}
+		glog.V(2).Infof("waiting for SSH on %s: %v", endpoint, err)
+		time.Sleep(sshPollInterval)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// DefaultIPMIPasswordEnv is the environment variable the IPMI password is read from, unless the host names another
+const DefaultIPMIPasswordEnv = "IPMI_PASSWORD"
+
+// runIPMITool runs ipmitool with the given environment and arguments; it is replaced in tests
+var runIPMITool = func(env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("ipmitool", args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// ipmiTool runs an ipmitool command against the management controller of the host
+func ipmiTool(host *kops.BareMetalHostSpec, args ...string) (string, error) {
+	if host.IPMI == nil || host.IPMI.Address == "" {
+		return "", fmt.Errorf("host %q has no IPMI address", host.Name)
+	}
+
+	passwordEnv := host.IPMI.PasswordEnv
+	if passwordEnv == "" {
+		passwordEnv = DefaultIPMIPasswordEnv
+	}
+
+	argv := []string{"-I", "lanplus", "-H", host.IPMI.Address}
+	if host.IPMI.Username != "" {
+		argv = append(argv, "-U", host.IPMI.Username)
+	}
+	// -E reads the password from IPMI_PASSWORD, so that it doesn't appear in the process list
+	argv = append(argv, "-E")
+	argv = append(argv, args...)
+
+	out, err := runIPMITool([]string{DefaultIPMIPasswordEnv + "=" + os.Getenv(passwordEnv)}, argv...)
+	if err != nil {
+		return "", fmt.Errorf("error running ipmitool %s for host %q: %v: %s", strings.Join(args, " "), host.Name, err, out)
+	}
+	return string(out), nil
+}
+
+// ipmiNetworkBoot makes the next boot of the host a PXE boot, and then power cycles the host, or powers it on if it is off.
+// The boot device only applies to the next boot, so the host boots from disk once the node image is installed.
+func ipmiNetworkBoot(host *kops.BareMetalHostSpec) error {
+	if _, err := ipmiTool(host, "chassis", "bootdev", "pxe"); err != nil {
+		return err
+	}
+
+	status, err := ipmiTool(host, "chassis", "power", "status")
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(status, "is on") {
+		_, err = ipmiTool(host, "chassis", "power", "cycle")
+	} else {
+		_, err = ipmiTool(host, "chassis", "power", "on")
+	}
+	return err
+}
+
+// ipmiPowerOff powers off the host
+func ipmiPowerOff(host *kops.BareMetalHostSpec) error {
+	_, err := ipmiTool(host, "chassis", "power", "off")
+	return err
+}
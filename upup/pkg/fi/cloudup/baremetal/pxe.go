@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// PXEConfigFile returns the name pxelinux looks up for the interface with the MAC address, e.g. 01-52-54-00-12-34-56
+func PXEConfigFile(mac string) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address %q: %v", mac, err)
+	}
+	// 01 is the ARP type of ethernet
+	return "01-" + strings.Replace(hw.String(), ":", "-", -1), nil
+}
+
+// BuildPXEConfig returns the pxelinux configuration that boots the host into the installer of the node image
+func BuildPXEConfig(pxe *kops.PXESpec, host *kops.BareMetalHostSpec) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Managed by kops, for host %s\n", host.Name)
+	b.WriteString("DEFAULT kops\n")
+	b.WriteString("LABEL kops\n")
+	fmt.Fprintf(&b, "  KERNEL %s\n", pxe.Kernel)
+
+	var args []string
+	if pxe.Initrd != "" {
+		args = append(args, "initrd="+pxe.Initrd)
+	}
+	if pxe.Args != "" {
+		args = append(args, pxe.Args)
+	}
+	if len(args) != 0 {
+		fmt.Fprintf(&b, "  APPEND %s\n", strings.Join(args, " "))
+	}
+
+	return b.String()
+}
@@ -21,13 +21,13 @@ import (
 )
 
 type Target struct {
-	cloud *Cloud
+	Cloud *Cloud
 }
 
 var _ fi.Target = &Target{}
 
 func NewTarget(cloud *Cloud) *Target {
-	return &Target{cloud: cloud}
+	return &Target{Cloud: cloud}
 }
 
 func (t *Target) Finish(taskMap map[string]fi.Task) error {
@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetaltasks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/baremetal"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+//go:generate fitask -type=Host
+
+// Host publishes the configuration of a bare metal host: the pxelinux configuration that installs the node image
+// when the host network boots, and the hash of the node configuration, which tells rolling-update whether the host
+// needs to be imaged again
+type Host struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	// PXEConfigPath is the VFS path of the pxelinux configuration file of the host
+	PXEConfigPath *string
+	// PXEConfig is the pxelinux configuration of the host
+	PXEConfig *string
+	// ConfigHash is the hash of the configuration the host should be imaged with
+	ConfigHash *string
+}
+
+var _ fi.CompareWithID = &Host{}
+
+func (h *Host) CompareWithID() *string {
+	return h.Name
+}
+
+func (h *Host) Find(c *fi.Context) (*Host, error) {
+	cloud := c.Cloud.(*baremetal.Cloud)
+
+	actual := &Host{
+		Name:          h.Name,
+		Lifecycle:     h.Lifecycle,
+		PXEConfigPath: h.PXEConfigPath,
+	}
+	found := false
+
+	p, err := vfs.Context.BuildVfsPath(fi.StringValue(h.PXEConfigPath))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing PXE config path %q: %v", fi.StringValue(h.PXEConfigPath), err)
+	}
+	b, err := p.ReadFile()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading %q: %v", p, err)
+		}
+	} else {
+		actual.PXEConfig = fi.String(string(b))
+		found = true
+	}
+
+	hash, ok, err := cloud.DesiredConfigHash(fi.StringValue(h.Name))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		actual.ConfigHash = fi.String(hash)
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return actual, nil
+}
+
+func (h *Host) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(h, c)
+}
+
+func (_ *Host) CheckChanges(a, e, changes *Host) error {
+	if e.Name == nil {
+		return fi.RequiredField("Name")
+	}
+	if e.PXEConfigPath == nil {
+		return fi.RequiredField("PXEConfigPath")
+	}
+	return nil
+}
+
+func (_ *Host) RenderBareMetal(t *baremetal.Target, a, e, changes *Host) error {
+	if changes.PXEConfig != nil {
+		p, err := vfs.Context.BuildVfsPath(fi.StringValue(e.PXEConfigPath))
+		if err != nil {
+			return fmt.Errorf("error parsing PXE config path %q: %v", fi.StringValue(e.PXEConfigPath), err)
+		}
+		glog.V(2).Infof("writing PXE configuration of host %q to %s", fi.StringValue(e.Name), p)
+		if err := p.WriteFile(bytes.NewReader([]byte(fi.StringValue(e.PXEConfig))), nil); err != nil {
+			return fmt.Errorf("error writing %q: %v", p, err)
+		}
+	}
+
+	if changes.ConfigHash != nil {
+		if err := t.Cloud.SetDesiredConfigHash(fi.StringValue(e.Name), fi.StringValue(e.ConfigHash)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
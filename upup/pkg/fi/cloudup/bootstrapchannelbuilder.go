@@ -17,8 +17,13 @@ limitations under the License.
 package cloudup
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/golang/glog"
 	channelsapi "k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/assets"
@@ -110,10 +115,10 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 		manifests[key] = "addons/" + location
 	}
 
-	// @check if podsecuritypolicies are enabled and if so, push the default kube-system policy
+	// @check if podsecuritypolicies are enabled and if so, push the default kube-system policy, and the spec.podSecurity preset
 	if b.cluster.Spec.KubeAPIServer != nil && b.cluster.Spec.KubeAPIServer.HasAdmissionController("PodSecurityPolicy") {
 		key := "podsecuritypolicy.addons.k8s.io"
-		version := "0.0.4"
+		version := "0.0.5"
 
 		{
 			location := key + "/k8s-1.9.yaml"
@@ -147,6 +152,26 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 		}
 	}
 
+	if b.cluster.Spec.KopsControllerEnabled() {
+		key := "kops-controller.addons.k8s.io"
+		version := "1.0.0"
+
+		{
+			location := key + "/k8s-1.10.yaml"
+			id := "k8s-1.10"
+
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          map[string]string{"k8s-addon": key},
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.10.0",
+				Id:                id,
+			})
+			manifests[key+"-"+id] = "addons/" + location
+		}
+	}
+
 	if b.cluster.Spec.NodeAuthorization != nil {
 		{
 			key := "node-authorizer.addons.k8s.io"
@@ -193,7 +218,9 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 
 			{
 				location := key + "/k8s-1.6.yaml"
-				id := "k8s-1.6"
+				// The manifest depends on the dns options in the cluster spec; channels replaces an addon of the same
+				// version when its id changes, so we include a hash of those options in the id
+				id := "k8s-1.6" + dnsOptionsHashSuffix(kubeDNS)
 
 				addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
 					Name:              fi.String(key),
@@ -203,7 +230,7 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 					KubernetesVersion: ">=1.6.0",
 					Id:                id,
 				})
-				manifests[key+"-"+id] = "addons/" + location
+				manifests[key+"-k8s-1.6"] = "addons/" + location
 			}
 		}
 	}
@@ -211,11 +238,13 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 	if kubeDNS.Provider == "CoreDNS" {
 		{
 			key := "coredns.addons.k8s.io"
-			version := "1.2.2-kops.1"
+			version := "1.2.2-kops.2"
 
 			{
 				location := key + "/k8s-1.6.yaml"
-				id := "k8s-1.6"
+				// The manifest depends on the dns options in the cluster spec; channels replaces an addon of the same
+				// version when its id changes, so we include a hash of those options in the id
+				id := "k8s-1.6" + dnsOptionsHashSuffix(kubeDNS)
 
 				addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
 					Name:              fi.String(key),
@@ -225,11 +254,40 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 					KubernetesVersion: ">=1.6.0",
 					Id:                id,
 				})
-				manifests[key+"-"+id] = "addons/" + location
+				manifests[key+"-k8s-1.6"] = "addons/" + location
 			}
 		}
 	}
 
+	if kubeDNS.NodeLocalDNSEnabled() {
+		key := "nodelocaldns.addons.k8s.io"
+		version := "1.15.10"
+
+		{
+			location := key + "/k8s-1.10.yaml"
+			// The manifest depends on the node-local-dns options in the cluster spec; channels replaces an addon of the
+			// same version when its id changes, so we include a hash of those options in the id
+			optionsHash := sha256.Sum256([]byte(strings.Join([]string{
+				kubeDNS.Provider,
+				kubeDNS.NodeLocalDNS.LocalIP,
+				kubeDNS.NodeLocalDNS.CPURequest,
+				kubeDNS.NodeLocalDNS.MemoryRequest,
+				kubeDNS.NodeLocalDNS.MemoryLimit,
+			}, ",")))
+			id := "k8s-1.10-" + hex.EncodeToString(optionsHash[:])[:8]
+
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          map[string]string{"k8s-addon": key},
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.10.0",
+				Id:                id,
+			})
+			manifests[key+"-k8s-1.10"] = "addons/" + location
+		}
+	}
+
 	{
 		key := "rbac.addons.k8s.io"
 		version := "1.8.0"
@@ -347,6 +405,18 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 		{
 			id := "v1.7.0"
 			location := key + "/" + id + ".yaml"
+			manifestKey := key + "-" + id
+			if b.cluster.Spec.CloudConfig != nil && b.cluster.Spec.CloudConfig.DefaultStorageClass != nil {
+				// kops manages the default storage class, so the manifest depends on its options
+				id = "k8s-1.7-default" + storageClassOptionsHashSuffix(b.cluster.Spec.CloudConfig, b.cluster.Spec.CloudConfig.AWSEBSCSIDriver)
+				location = key + "/k8s-1.7-default.yaml"
+				manifestKey = key + "-k8s-1.7-default"
+			} else if b.cluster.Spec.CloudConfig != nil && b.cluster.Spec.CloudConfig.AWSEBSCSIDriver.IsEnabled() {
+				// The storage class of the CSI driver replaces that of the in-tree volume plugin as the default
+				id = "k8s-1.14-csi"
+				location = key + "/" + id + ".yaml"
+				manifestKey = key + "-" + id
+			}
 
 			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
 				Name:              fi.String(key),
@@ -356,7 +426,7 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 				KubernetesVersion: ">=1.7.0",
 				Id:                id,
 			})
-			manifests[key+"-"+id] = "addons/" + location
+			manifests[manifestKey] = "addons/" + location
 		}
 
 		{
@@ -373,6 +443,25 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 			})
 			manifests[key+"-"+id] = "addons/" + location
 		}
+
+		if b.cluster.Spec.CloudConfig != nil && b.cluster.Spec.CloudConfig.AWSEBSCSIDriver.IsEnabled() {
+			key := "aws-ebs-csi-driver.addons.k8s.io"
+			version := "0.4.0"
+			location := key + "/k8s-1.14.yaml"
+			// The manifest depends on the image of the driver, so we include a hash of it in the id
+			imageHash := sha256.Sum256([]byte(b.cluster.Spec.CloudConfig.AWSEBSCSIDriver.Image))
+			id := "k8s-1.14-" + hex.EncodeToString(imageHash[:])[:8]
+
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          map[string]string{"k8s-addon": key},
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.14.0",
+				Id:                id,
+			})
+			manifests[key+"-k8s-1.14"] = "addons/" + location
+		}
 	}
 
 	if kops.CloudProviderID(b.cluster.Spec.CloudProvider) == kops.CloudProviderDO {
@@ -417,6 +506,18 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 		{
 			id := "v1.7.0"
 			location := key + "/" + id + ".yaml"
+			manifestKey := key + "-" + id
+			if b.cluster.Spec.CloudConfig != nil && b.cluster.Spec.CloudConfig.DefaultStorageClass != nil {
+				// kops manages the default storage class, so the manifest depends on its options
+				id = "k8s-1.7-default" + storageClassOptionsHashSuffix(b.cluster.Spec.CloudConfig, b.cluster.Spec.CloudConfig.GCEPDCSIDriver)
+				location = key + "/k8s-1.7-default.yaml"
+				manifestKey = key + "-k8s-1.7-default"
+			} else if b.cluster.Spec.CloudConfig != nil && b.cluster.Spec.CloudConfig.GCEPDCSIDriver.IsEnabled() {
+				// The storage class of the CSI driver replaces that of the in-tree volume plugin as the default
+				id = "k8s-1.14-csi"
+				location = key + "/" + id + ".yaml"
+				manifestKey = key + "-" + id
+			}
 
 			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
 				Name:              fi.String(key),
@@ -426,7 +527,26 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 				KubernetesVersion: ">=1.7.0",
 				Id:                id,
 			})
-			manifests[key+"-"+id] = "addons/" + location
+			manifests[manifestKey] = "addons/" + location
+		}
+
+		if b.cluster.Spec.CloudConfig != nil && b.cluster.Spec.CloudConfig.GCEPDCSIDriver.IsEnabled() {
+			key := "gce-pd-csi-driver.addons.k8s.io"
+			version := "0.4.0"
+			location := key + "/k8s-1.14.yaml"
+			// The manifest depends on the image of the driver, so we include a hash of it in the id
+			imageHash := sha256.Sum256([]byte(b.cluster.Spec.CloudConfig.GCEPDCSIDriver.Image))
+			id := "k8s-1.14-" + hex.EncodeToString(imageHash[:])[:8]
+
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          map[string]string{"k8s-addon": key},
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.14.0",
+				Id:                id,
+			})
+			manifests[key+"-k8s-1.14"] = "addons/" + location
 		}
 	}
 
@@ -808,6 +928,55 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 		}
 	}
 
+	if b.cluster.Spec.NetworkPolicy != nil && b.cluster.Spec.NetworkPolicy.DefaultDeny {
+		key := "networkpolicy.addons.k8s.io"
+		version := "1.0.0"
+
+		{
+			location := key + "/k8s-1.8.yaml"
+			// The policies depend on the namespaces in the cluster spec; channels replaces an addon of the same version
+			// when its id changes, so we include a hash of the namespaces in the id
+			namespacesHash := sha256.Sum256([]byte(strings.Join(networkPolicyNamespaces(b.cluster), ",")))
+			id := "k8s-1.8-" + hex.EncodeToString(namespacesHash[:])[:8]
+
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          map[string]string{"k8s-addon": key},
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.8.0",
+				Id:                id,
+			})
+			manifests[key+"-k8s-1.8"] = "addons/" + location
+		}
+	}
+
+	if rbacBootstrapEnabled(b.cluster) {
+		key := "rbac-bootstrap.addons.k8s.io"
+		version := "1.0.0"
+
+		{
+			location := key + "/k8s-1.8.yaml"
+			// As with the network policies, the id includes a hash of the bindings rendered into the manifest
+			bindings, err := json.Marshal(rbacBootstrap(b.cluster))
+			if err != nil {
+				return nil, nil, fmt.Errorf("error encoding rbac bindings: %v", err)
+			}
+			bindingsHash := sha256.Sum256(bindings)
+			id := "k8s-1.8-" + hex.EncodeToString(bindingsHash[:])[:8]
+
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          map[string]string{"k8s-addon": key},
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.8.0",
+				Id:                id,
+			})
+			manifests[key+"-k8s-1.8"] = "addons/" + location
+		}
+	}
+
 	authenticationSelector := map[string]string{"role.kubernetes.io/authentication": "1"}
 
 	if b.cluster.Spec.Authentication != nil {
@@ -836,7 +1005,12 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 
 			{
 				location := key + "/k8s-1.10.yaml"
-				id := "k8s-1.10"
+				// As with dex, the id includes a hash of the configuration rendered into the manifest
+				optionsHash, err := awsIAMAuthenticatorOptionsHash(b.cluster.ObjectMeta.Name, b.cluster.Spec.Authentication.Aws)
+				if err != nil {
+					return nil, nil, err
+				}
+				id := "k8s-1.10-" + optionsHash
 
 				addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
 					Name:              fi.String(key),
@@ -846,28 +1020,61 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 					KubernetesVersion: ">=1.10.0",
 					Id:                id,
 				})
-				manifests[key+"-"+id] = "addons/" + location
+				manifests[key+"-k8s-1.10"] = "addons/" + location
 			}
 		}
 	}
 
-	if featureflag.EnableExternalCloudController.Enabled() && b.cluster.Spec.ExternalCloudControllerManager != nil {
+	if b.cluster.Spec.Authentication != nil && b.cluster.Spec.Authentication.OIDC != nil && b.cluster.Spec.Authentication.OIDC.Dex != nil {
+		key := "authentication.dex"
+		version := "2.10.0"
+
 		{
-			key := "core.addons.k8s.io"
-			version := "1.7.0"
+			location := key + "/k8s-1.8.yaml"
+			// channels replaces an addon of the same version when its id changes, so the id includes a hash of the
+			// dex configuration rendered into the manifest
+			optionsHash, err := dexOptionsHash(b.cluster.Spec.Authentication.OIDC)
+			if err != nil {
+				return nil, nil, err
+			}
+			id := "k8s-1.8-" + optionsHash
 
-			location := key + "/k8s-1.7.yaml"
-			id := "k8s-1.7-ccm"
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:              fi.String(key),
+				Version:           fi.String(version),
+				Selector:          authenticationSelector,
+				Manifest:          fi.String(location),
+				KubernetesVersion: ">=1.8.0",
+				Id:                id,
+			})
+			manifests[key+"-k8s-1.8"] = "addons/" + location
+		}
+	}
+
+	if b.cluster.Spec.ExternalCloudControllerManager != nil {
+		key := "cloud-controller-manager.addons.k8s.io"
+		version := "1.9.0"
+
+		{
+			location := key + "/k8s-1.9.yaml"
+			// The manifest depends on the cloud-controller-manager options in the cluster spec; channels replaces an
+			// addon of the same version when its id changes, so we include a hash of those options in the id
+			argv, err := cloudControllerManagerArgv(b.cluster)
+			if err != nil {
+				return nil, nil, err
+			}
+			optionsHash := sha256.Sum256([]byte(strings.Join(append(argv, b.cluster.Spec.ExternalCloudControllerManager.Image), ",")))
+			id := "k8s-1.9-" + hex.EncodeToString(optionsHash[:])[:8]
 
 			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
 				Name:              fi.String(key),
 				Version:           fi.String(version),
 				Selector:          map[string]string{"k8s-addon": key},
 				Manifest:          fi.String(location),
-				KubernetesVersion: ">=1.7.0",
+				KubernetesVersion: ">=1.9.0",
 				Id:                id,
 			})
-			manifests[key+"-"+id] = "addons/" + location
+			manifests[key+"-k8s-1.9"] = "addons/" + location
 		}
 	}
 
@@ -887,3 +1094,54 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 
 	return addons, manifests, nil
 }
+
+// storageClassOptionsHashSuffix returns a suffix for the storage addon id derived from the options of the default
+// storage class and whether the CSI driver is enabled, which are rendered into the manifest
+func storageClassOptionsHashSuffix(cloudConfig *kops.CloudConfiguration, csiDriver *kops.CSIDriverSpec) string {
+	options := struct {
+		DefaultStorageClass *kops.DefaultStorageClassSpec `json:"defaultStorageClass"`
+		CSIDriver           bool                          `json:"csiDriver,omitempty"`
+	}{
+		DefaultStorageClass: cloudConfig.DefaultStorageClass,
+		CSIDriver:           csiDriver.IsEnabled(),
+	}
+
+	b, err := json.Marshal(options)
+	if err != nil {
+		glog.Warningf("error encoding storage class options: %v", err)
+		return ""
+	}
+	hash := sha256.Sum256(b)
+	return "-" + hex.EncodeToString(hash[:])[:8]
+}
+
+// dnsOptionsHashSuffix returns a suffix for the dns addon id derived from the dns options which are rendered into the
+// manifest, or an empty string if none are set, so that clusters using the defaults keep a stable id
+func dnsOptionsHashSuffix(kubeDNS *kops.KubeDNSConfig) string {
+	options := struct {
+		StubDomains         map[string][]string       `json:"stubDomains,omitempty"`
+		UpstreamNameservers []string                  `json:"upstreamNameservers,omitempty"`
+		Autoscaler          *kops.DNSAutoscalerConfig `json:"autoscaler,omitempty"`
+		Replicas            int                       `json:"replicas,omitempty"`
+	}{
+		StubDomains:         kubeDNS.StubDomains,
+		UpstreamNameservers: kubeDNS.UpstreamNameservers,
+		Autoscaler:          kubeDNS.Autoscaler,
+	}
+	if kubeDNS.Autoscaler != nil && kubeDNS.Autoscaler.Disabled {
+		options.Replicas = kubeDNS.Replicas
+	}
+
+	if len(options.StubDomains) == 0 && len(options.UpstreamNameservers) == 0 && options.Autoscaler == nil {
+		return ""
+	}
+
+	// json encodes maps with sorted keys, so the hash is stable
+	b, err := json.Marshal(options)
+	if err != nil {
+		glog.Warningf("error encoding dns options: %v", err)
+		return ""
+	}
+	hash := sha256.Sum256(b)
+	return "-" + hex.EncodeToString(hash[:])[:8]
+}
@@ -46,6 +46,15 @@ func TestBootstrapChannelBuilder_BuildTasks(t *testing.T) {
 	runChannelBuilderTest(t, "kopeio-vxlan")
 	runChannelBuilderTest(t, "weave")
 	runChannelBuilderTest(t, "cilium")
+	runChannelBuilderTest(t, "podsecurity")
+	runChannelBuilderTest(t, "networkpolicy")
+	runChannelBuilderTest(t, "coredns")
+	runChannelBuilderTest(t, "nodelocaldns")
+	runChannelBuilderTest(t, "kopscontroller")
+	runChannelBuilderTest(t, "rbac")
+	runChannelBuilderTest(t, "cloudcontrollermanager")
+	runChannelBuilderTest(t, "awsebscsidriver")
+	runChannelBuilderTest(t, "defaultstorageclass")
 }
 
 func runChannelBuilderTest(t *testing.T, key string) {
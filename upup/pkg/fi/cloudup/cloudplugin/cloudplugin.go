@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudplugin lets cloud providers be added to kops without forking it.  A provider implements Provider and
+// is registered when kops starts: either compiled into a build of kops that calls Register, or built as a Go plugin
+// and loaded with Load, from the paths given to --cloud-plugin.
+package cloudplugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// Provider is a cloud provider that is implemented outside kops.  kops calls it wherever it would otherwise switch
+// on one of its built-in cloud providers: to validate the cluster spec, to build the cloud, to add the tasks that
+// create the cloud resources of the cluster, to apply them, and to find the resources to delete.
+//
+// The instances of a provider's clusters run nodeup like those on any other cloud, and the kubernetes components
+// are run with --cloud-provider=external, so the provider's cloud-controller-manager must be installed in the
+// cluster, e.g. as an addon.
+type Provider interface {
+	// ID is the spec.cloudProvider of the clusters the provider manages, e.g. "exoscale"
+	ID() kops.CloudProviderID
+
+	// ValidateCluster returns the problems with the cluster spec that are specific to the provider
+	ValidateCluster(cluster *kops.Cluster) field.ErrorList
+
+	// BuildCloud returns the cloud of the cluster.  kops uses it to find and delete the instances of the instance
+	// groups, e.g. in a rolling update, and for DNS unless the cluster uses gossip.
+	BuildCloud(cluster *kops.Cluster) (fi.Cloud, error)
+
+	// Region returns the region of the cloud, which is passed to the model builders and the terraform output
+	Region(cloud fi.Cloud) string
+
+	// TaskTypes are the types of the tasks the provider adds, by the name they are given in models
+	TaskTypes() map[string]interface{}
+
+	// ModelBuilders returns the builders that add the tasks for the cloud resources of the cluster and its
+	// instance groups, such as the network, firewall, load balancer and instances
+	ModelBuilders(context *ModelContext) ([]fi.ModelBuilder, error)
+
+	// NewTarget returns the target that applies the tasks to the cloud, for kops update cluster --yes
+	NewTarget(cloud fi.Cloud) (fi.Target, error)
+
+	// ListResources returns the cloud resources of the cluster, which kops delete cluster deletes
+	ListResources(cloud fi.Cloud, clusterName string) (map[string]*resources.Resource, error)
+}
+
+// ModelContext is the configuration the model builders of a provider are built from
+type ModelContext struct {
+	Cluster        *kops.Cluster
+	InstanceGroups []*kops.InstanceGroup
+
+	// Region is the region of the cloud, as returned by Provider.Region
+	Region string
+
+	// SSHPublicKeys are the SSH public keys of the cluster; the first is the primary key
+	SSHPublicKeys [][]byte
+
+	// UserData returns the bootstrap script of the instances of the instance group, which installs and runs nodeup
+	UserData func(ig *kops.InstanceGroup) (*fi.ResourceHolder, error)
+
+	// ClusterLifecycle, NetworkLifecycle and SecurityLifecycle are the lifecycles of the tasks in each phase
+	ClusterLifecycle  *fi.Lifecycle
+	NetworkLifecycle  *fi.Lifecycle
+	SecurityLifecycle *fi.Lifecycle
+}
+
+// builtIn are the cloud providers implemented in kops, which can't be replaced by a plugin
+var builtIn = map[kops.CloudProviderID]bool{
+	kops.CloudProviderALI:       true,
+	kops.CloudProviderAWS:       true,
+	kops.CloudProviderBareMetal: true,
+	kops.CloudProviderDO:        true,
+	kops.CloudProviderGCE:       true,
+	kops.CloudProviderHetzner:   true,
+	kops.CloudProviderOpenstack: true,
+	kops.CloudProviderScaleway:  true,
+	kops.CloudProviderVSphere:   true,
+}
+
+var (
+	providersMutex sync.Mutex
+	providers      = make(map[kops.CloudProviderID]Provider)
+)
+
+// Register adds the provider; it is an error to register a built-in cloud provider, or the same provider twice
+func Register(p Provider) error {
+	id := p.ID()
+	if id == "" {
+		return fmt.Errorf("cloud provider plugin %T has no ID", p)
+	}
+	if builtIn[id] {
+		return fmt.Errorf("cloud provider %q is built into kops, and can't be provided by a plugin", id)
+	}
+
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	if _, found := providers[id]; found {
+		return fmt.Errorf("cloud provider %q is registered more than once", id)
+	}
+	providers[id] = p
+	return nil
+}
+
+// Get returns the registered provider with the ID, or nil if there is none
+func Get(id kops.CloudProviderID) Provider {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	return providers[id]
+}
+
+// IDs returns the IDs of the registered providers, sorted
+func IDs() []string {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	var ids []string
+	for id := range providers {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	return ids
+}
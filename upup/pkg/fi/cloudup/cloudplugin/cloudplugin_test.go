@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudplugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+type fakeProvider struct {
+	id kops.CloudProviderID
+}
+
+var _ Provider = &fakeProvider{}
+
+func (p *fakeProvider) ID() kops.CloudProviderID { return p.id }
+
+func (p *fakeProvider) ValidateCluster(cluster *kops.Cluster) field.ErrorList { return nil }
+
+func (p *fakeProvider) BuildCloud(cluster *kops.Cluster) (fi.Cloud, error) { return nil, nil }
+
+func (p *fakeProvider) Region(cloud fi.Cloud) string { return "" }
+
+func (p *fakeProvider) TaskTypes() map[string]interface{} { return nil }
+
+func (p *fakeProvider) ModelBuilders(context *ModelContext) ([]fi.ModelBuilder, error) {
+	return nil, nil
+}
+
+func (p *fakeProvider) NewTarget(cloud fi.Cloud) (fi.Target, error) { return nil, nil }
+
+func (p *fakeProvider) ListResources(cloud fi.Cloud, clusterName string) (map[string]*resources.Resource, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	exoscale := &fakeProvider{id: "exoscale"}
+	if err := Register(exoscale); err != nil {
+		t.Fatalf("unexpected error registering provider: %v", err)
+	}
+	if err := Register(&fakeProvider{id: "oracle"}); err != nil {
+		t.Fatalf("unexpected error registering provider: %v", err)
+	}
+
+	if Get("exoscale") != exoscale {
+		t.Errorf("expected to get the registered provider")
+	}
+	if Get("linode") != nil {
+		t.Errorf("expected no provider for an unregistered ID")
+	}
+	if ids := IDs(); !reflect.DeepEqual(ids, []string{"exoscale", "oracle"}) {
+		t.Errorf("unexpected IDs %v", ids)
+	}
+
+	grid := []struct {
+		provider *fakeProvider
+		expected string
+	}{
+		{provider: &fakeProvider{id: "exoscale"}, expected: "registered more than once"},
+		{provider: &fakeProvider{id: kops.CloudProviderAWS}, expected: "built into kops"},
+		{provider: &fakeProvider{}, expected: "has no ID"},
+	}
+	for _, g := range grid {
+		err := Register(g.provider)
+		if err == nil || !strings.Contains(err.Error(), g.expected) {
+			t.Errorf("registering %q: expected error containing %q, got %v", g.provider.id, g.expected, err)
+		}
+	}
+}
+
+func TestPluginFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cloudplugin")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"b.so", "a.so", "README.md"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "c.so"), 0755); err != nil {
+		t.Fatalf("error creating dir: %v", err)
+	}
+
+	files, err := pluginFiles(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "a.so"), filepath.Join(dir, "b.so")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+
+	files, err = pluginFiles(expected[0])
+	if err != nil || !reflect.DeepEqual(files, expected[:1]) {
+		t.Errorf("expected a file to be its own plugin, got %v, %v", files, err)
+	}
+
+	if err := Load([]string{filepath.Join(dir, "missing.so")}); err == nil {
+		t.Errorf("expected an error loading a missing plugin")
+	}
+	if err := Load([]string{expected[0]}); err == nil {
+		t.Errorf("expected an error loading a file that is not a plugin")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudplugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// Symbol is the function a Go plugin exports to create its provider, with the signature
+//
+//	func NewProvider() cloudplugin.Provider
+//
+// A Go plugin can only be loaded by a kops binary built with the same version of Go and of kops.
+const Symbol = "NewProvider"
+
+// DefaultPluginDir returns the directory that plugins are loaded from if none are given: ~/.kops/plugins/cloud
+func DefaultPluginDir(home string) string {
+	return filepath.Join(home, ".kops", "plugins", "cloud")
+}
+
+// Load loads the Go plugins at the paths, and registers their providers.  A path that is a directory loads
+// every .so file in it.
+func Load(paths []string) error {
+	for _, p := range paths {
+		files, err := pluginFiles(p)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := loadPlugin(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pluginFiles returns the path, or the .so files in it if it is a directory
+func pluginFiles(path string) ([]string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cloud provider plugin %q: %v", path, err)
+	}
+	if !stat.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cloud provider plugin directory %q: %v", path, err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".so") {
+			continue
+		}
+		files = append(files, filepath.Join(path, e.Name()))
+	}
+	return files, nil
+}
+
+// loadPlugin opens the Go plugin, and registers the provider it creates
+func loadPlugin(path string) error {
+	glog.V(2).Infof("loading cloud provider plugin %q", path)
+
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("error loading cloud provider plugin %q: %v", path, err)
+	}
+	symbol, err := p.Lookup(Symbol)
+	if err != nil {
+		return fmt.Errorf("cloud provider plugin %q does not export %s: %v", path, Symbol, err)
+	}
+	newProvider, ok := symbol.(func() Provider)
+	if !ok {
+		return fmt.Errorf("%s of cloud provider plugin %q is a %T, not a func() cloudplugin.Provider", Symbol, path, symbol)
+	}
+
+	provider := newProvider()
+	if err := Register(provider); err != nil {
+		return fmt.Errorf("error registering cloud provider plugin %q: %v", path, err)
+	}
+	glog.V(2).Infof("registered cloud provider %q from plugin %q", provider.ID(), path)
+	return nil
+}
@@ -49,8 +49,8 @@ func PerformAssignments(c *kops.Cluster) error {
 		c.Spec.Topology = &kops.TopologySpec{Masters: kops.TopologyPublic, Nodes: kops.TopologyPublic}
 	}
 
-	// Currently only AWS uses NetworkCIDRs
-	setNetworkCIDR := (cloud.ProviderID() == kops.CloudProviderAWS) || (cloud.ProviderID() == kops.CloudProviderALI)
+	// Currently only AWS, ALI, Hetzner and Scaleway use NetworkCIDRs
+	setNetworkCIDR := (cloud.ProviderID() == kops.CloudProviderAWS) || (cloud.ProviderID() == kops.CloudProviderALI) || (cloud.ProviderID() == kops.CloudProviderHetzner) || (cloud.ProviderID() == kops.CloudProviderScaleway)
 	if setNetworkCIDR && c.Spec.NetworkCIDR == "" {
 		if c.SharedVPC() {
 			vpcInfo, err := cloud.FindVPCInfo(c.Spec.NetworkID)
@@ -70,6 +70,10 @@ func PerformAssignments(c *kops.Cluster) error {
 				c.Spec.NetworkCIDR = "172.20.0.0/16"
 			} else if cloud.ProviderID() == kops.CloudProviderALI {
 				c.Spec.NetworkCIDR = "192.168.0.0/16"
+			} else if cloud.ProviderID() == kops.CloudProviderHetzner {
+				c.Spec.NetworkCIDR = "10.0.0.0/16"
+			} else if cloud.ProviderID() == kops.CloudProviderScaleway {
+				c.Spec.NetworkCIDR = "172.16.0.0/16"
 			}
 		}
 
@@ -90,7 +94,7 @@ func PerformAssignments(c *kops.Cluster) error {
 
 	// We only assign subnet CIDRs on AWS
 	pd := cloud.ProviderID()
-	if pd == kops.CloudProviderAWS || pd == kops.CloudProviderOpenstack || pd == kops.CloudProviderALI {
+	if pd == kops.CloudProviderAWS || pd == kops.CloudProviderOpenstack || pd == kops.CloudProviderALI || pd == kops.CloudProviderHetzner || pd == kops.CloudProviderScaleway {
 		// TODO: Use vpcInfo
 		err = assignCIDRsToSubnets(c)
 		if err != nil {
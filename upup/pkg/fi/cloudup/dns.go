@@ -25,6 +25,9 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kops/dns-controller/pkg/dns"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/cloudflare"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/infoblox"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 	"k8s.io/kops/pkg/apis/kops"
 	kopsdns "k8s.io/kops/pkg/dns"
@@ -40,8 +43,30 @@ const (
 	PlaceholderTTL = 10
 )
 
+// buildDNSProvider returns the DNS provider that manages the cluster's records; this is the DNS service of the cloud,
+// unless a provider plugin is configured in spec.externalDns.provider
+func buildDNSProvider(cluster *kops.Cluster, cloud fi.Cloud) (dnsprovider.Interface, error) {
+	providerID := kopsdns.ExternalProviderID(cluster)
+	if providerID == "" {
+		return cloud.DNS()
+	}
+
+	config, err := dns.BuildProviderConfig(kopsdns.ExternalProviderConfig(cluster))
+	if err != nil {
+		return nil, err
+	}
+	provider, err := dnsprovider.GetDnsProvider(providerID, config)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("unknown DNS provider %q", providerID)
+	}
+	return provider, nil
+}
+
 func findZone(cluster *kops.Cluster, cloud fi.Cloud) (dnsprovider.Zone, error) {
-	dns, err := cloud.DNS()
+	dns, err := buildDNSProvider(cluster, cloud)
 	if err != nil {
 		return nil, fmt.Errorf("error building DNS provider: %v", err)
 	}
@@ -69,6 +94,24 @@ func findZone(cluster *kops.Cluster, cloud fi.Cloud) (dnsprovider.Zone, error) {
 		return nil, fmt.Errorf("cannot find DNS Zone %q.  Please pre-create the zone and set up NS records so that it resolves.", cluster.Spec.DNSZone)
 	}
 
+	if len(matches) > 1 {
+		// With split-horizon DNS there are public and private zones with the same name; prefer the one we are using
+		private := (&model.KopsModelContext{Cluster: cluster}).UsePrivateDNS()
+		var filtered []dnsprovider.Zone
+		for _, zone := range matches {
+			if awsZone, ok := zone.(*route53.Zone); ok {
+				hostedZone := awsZone.Route53HostedZone()
+				if hostedZone.Config != nil && fi.BoolValue(hostedZone.Config.PrivateZone) != private {
+					continue
+				}
+			}
+			filtered = append(filtered, zone)
+		}
+		if len(filtered) != 0 {
+			matches = filtered
+		}
+	}
+
 	if len(matches) > 1 {
 		glog.Infof("Found multiple DNS Zones matching %q, please specify --dns-zone=<id> to indicate the one you want", cluster.Spec.DNSZone)
 		for _, zone := range zones {
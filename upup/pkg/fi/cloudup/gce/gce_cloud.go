@@ -23,9 +23,11 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"github.com/golang/glog"
 	"golang.org/x/net/context"
+	xoauth2 "golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	compute "google.golang.org/api/compute/v0.beta"
 	"google.golang.org/api/iam/v1"
@@ -34,6 +36,9 @@ import (
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/google/clouddns"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudtrace"
+	"k8s.io/kops/pkg/discoverycache"
+	"k8s.io/kops/pkg/retrypolicy"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -78,7 +83,11 @@ func (c *gceCloudImplementation) ProviderID() kops.CloudProviderID {
 	return kops.CloudProviderGCE
 }
 
-var gceCloudInstances map[string]GCECloud = make(map[string]GCECloud)
+var (
+	// gceCloudInstancesMutex guards gceCloudInstances; clouds may be built concurrently in batch operations
+	gceCloudInstancesMutex sync.Mutex
+	gceCloudInstances      map[string]GCECloud = make(map[string]GCECloud)
+)
 
 // DefaultProject returns the current project configured in the gcloud SDK, ("", nil) if no project was set
 func DefaultProject() (string, error) {
@@ -112,6 +121,9 @@ func DefaultProject() (string, error) {
 }
 
 func NewGCECloud(region string, project string, labels map[string]string) (GCECloud, error) {
+	gceCloudInstancesMutex.Lock()
+	defer gceCloudInstancesMutex.Unlock()
+
 	i := gceCloudInstances[region+"::"+project]
 	if i != nil {
 		return i.(gceCloudInternal).WithLabels(labels), nil
@@ -130,6 +142,19 @@ func NewGCECloud(region string, project string, labels map[string]string) (GCECl
 	if err != nil {
 		return nil, fmt.Errorf("error building google API client: %v", err)
 	}
+	// Serve read-only requests from the discovery cache, if one has been configured.  The cache goes beneath the oauth2
+	// transport, so that the responses it keeps are keyed by the credentials of each request.
+	if t, ok := client.Transport.(*xoauth2.Transport); ok {
+		t.Base = discoverycache.WrapTransport(t.Base)
+	} else {
+		client.Transport = discoverycache.WrapTransport(client.Transport)
+	}
+	// Retry failed read-only requests and bound the duration of API calls, as configured by the retry policy
+	client.Transport = retrypolicy.WrapTransport(client.Transport)
+	client.Timeout = retrypolicy.Current().Timeout
+	// Record API calls, if tracing is enabled
+	client.Transport = cloudtrace.WrapTransport("gce", client.Transport)
+
 	computeService, err := compute.New(client)
 	if err != nil {
 		return nil, fmt.Errorf("error building compute API client: %v", err)
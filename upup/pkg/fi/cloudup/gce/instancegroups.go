@@ -22,11 +22,14 @@ import (
 	"hash/fnv"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	context "golang.org/x/net/context"
 	compute "google.golang.org/api/compute/v0.beta"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
 )
@@ -73,12 +76,22 @@ func recreateCloudInstanceGroupMember(c GCECloud, i *cloudinstances.CloudInstanc
 		return err
 	}
 
-	req := &compute.InstanceGroupManagersRecreateInstancesRequest{
-		Instances: []string{
-			i.ID,
-		},
+	var op *compute.Operation
+	if migURL.Region != "" {
+		req := &compute.RegionInstanceGroupManagersRecreateRequest{
+			Instances: []string{
+				i.ID,
+			},
+		}
+		op, err = c.Compute().RegionInstanceGroupManagers.RecreateInstances(migURL.Project, migURL.Region, migURL.Name, req).Do()
+	} else {
+		req := &compute.InstanceGroupManagersRecreateInstancesRequest{
+			Instances: []string{
+				i.ID,
+			},
+		}
+		op, err = c.Compute().InstanceGroupManagers.RecreateInstances(migURL.Project, migURL.Zone, migURL.Name, req).Do()
 	}
-	op, err := c.Compute().InstanceGroupManagers.RecreateInstances(migURL.Project, migURL.Zone, migURL.Name, req).Do()
 	if err != nil {
 		if IsNotFound(err) {
 			glog.Infof("Instance not found, assuming deleted: %q", i.ID)
@@ -90,6 +103,185 @@ func recreateCloudInstanceGroupMember(c GCECloud, i *cloudinstances.CloudInstanc
 	return c.WaitForOp(op)
 }
 
+// SetGroupSize resizes the MIG
+func (c *gceCloudImplementation) SetGroupSize(g *cloudinstances.CloudInstanceGroup, minSize int, maxSize int, desiredCapacity *int) error {
+	return setGroupSize(c, g, minSize, desiredCapacity)
+}
+
+// SetGroupSize resizes the MIG
+func (c *mockGCECloud) SetGroupSize(g *cloudinstances.CloudInstanceGroup, minSize int, maxSize int, desiredCapacity *int) error {
+	return setGroupSize(c, g, minSize, desiredCapacity)
+}
+
+// setGroupSize resizes a MIG to the desired capacity, or else to the minimum size: a MIG only has a target size, as
+// kops does not attach an autoscaler to it.  The size is that of the whole instance group, so a zonal MIG is resized
+// to its share of it, split across the zones of the instance group in the same way as the model.
+func setGroupSize(c GCECloud, g *cloudinstances.CloudInstanceGroup, minSize int, desiredCapacity *int) error {
+	mig := g.Raw.(*compute.InstanceGroupManager)
+
+	size := minSize
+	if desiredCapacity != nil {
+		size = *desiredCapacity
+	}
+
+	migURL, err := ParseGoogleCloudURL(mig.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	var op *compute.Operation
+	if migURL.Region != "" {
+		glog.V(2).Infof("Resizing MIG %s to %d", mig.Name, size)
+		op, err = c.Compute().RegionInstanceGroupManagers.Resize(migURL.Project, migURL.Region, migURL.Name, int64(size)).Do()
+	} else {
+		size, err = zoneTargetSize(g.InstanceGroup, migURL.Zone, size)
+		if err != nil {
+			return err
+		}
+		glog.V(2).Infof("Resizing MIG %s to %d", mig.Name, size)
+		op, err = c.Compute().InstanceGroupManagers.Resize(migURL.Project, migURL.Zone, migURL.Name, int64(size)).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("error resizing MIG %s: %v", mig.Name, err)
+	}
+	if err := c.WaitForOp(op); err != nil {
+		return fmt.Errorf("error resizing MIG %s: %v", mig.Name, err)
+	}
+
+	g.MinSize = size
+	g.MaxSize = size
+	return nil
+}
+
+// zoneTargetSize returns the share of size of the MIG of the instance group in the zone: the size is split evenly
+// across the zones, in sorted order, with the first zones taking one more instance each when it does not divide evenly
+func zoneTargetSize(ig *kops.InstanceGroup, zone string, size int) (int, error) {
+	zones := sets.NewString(ig.Spec.Zones...).List()
+	for i, z := range zones {
+		if z != zone {
+			continue
+		}
+		share := size / len(zones)
+		if i < size%len(zones) {
+			share++
+		}
+		return share, nil
+	}
+	return 0, fmt.Errorf("zone %q is not one of the zones %v of instance group %q", zone, zones, ig.ObjectMeta.Name)
+}
+
+const (
+	migUpdatePollInterval   = 10 * time.Second
+	migUpdatePollTimeout    = 60 * time.Minute
+	migUpdateTypeProactive  = "PROACTIVE"
+	migUpdateMinimalReplace = "REPLACE"
+)
+
+// RollingReplaceGroup replaces the out-of-date instances in a group, using the InstanceGroupManager's own rolling update
+func (c *gceCloudImplementation) RollingReplaceGroup(g *cloudinstances.CloudInstanceGroup, maxSurge int, maxUnavailable int) error {
+	return rollingReplaceCloudInstanceGroup(c, g, maxSurge, maxUnavailable)
+}
+
+// RollingReplaceGroup replaces the out-of-date instances in a group, using the InstanceGroupManager's own rolling update
+func (c *mockGCECloud) RollingReplaceGroup(g *cloudinstances.CloudInstanceGroup, maxSurge int, maxUnavailable int) error {
+	return rollingReplaceCloudInstanceGroup(c, g, maxSurge, maxUnavailable)
+}
+
+// rollingReplaceCloudInstanceGroup sets a proactive update policy on the InstanceGroupManager, targeting its current
+// InstanceTemplate, so that GCE replaces every instance running an older template.  It waits for the MIG to settle.
+func rollingReplaceCloudInstanceGroup(c GCECloud, g *cloudinstances.CloudInstanceGroup, maxSurge int, maxUnavailable int) error {
+	mig := g.Raw.(*compute.InstanceGroupManager)
+
+	if maxSurge <= 0 && maxUnavailable <= 0 {
+		return fmt.Errorf("at least one of maxSurge and maxUnavailable must be greater than zero")
+	}
+
+	migURL, err := ParseGoogleCloudURL(mig.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("Starting rolling replace of MIG %s to InstanceTemplate %s", mig.Name, mig.InstanceTemplate)
+
+	patch := &compute.InstanceGroupManager{
+		UpdatePolicy: &compute.InstanceGroupManagerUpdatePolicy{
+			Type:          migUpdateTypeProactive,
+			MinimalAction: migUpdateMinimalReplace,
+			MaxSurge: &compute.FixedOrPercent{
+				Fixed:           int64(maxSurge),
+				ForceSendFields: []string{"Fixed"},
+			},
+			MaxUnavailable: &compute.FixedOrPercent{
+				Fixed:           int64(maxUnavailable),
+				ForceSendFields: []string{"Fixed"},
+			},
+		},
+		Versions: []*compute.InstanceGroupManagerVersion{
+			{
+				InstanceTemplate: mig.InstanceTemplate,
+			},
+		},
+	}
+
+	var op *compute.Operation
+	if migURL.Region != "" {
+		op, err = c.Compute().RegionInstanceGroupManagers.Patch(migURL.Project, migURL.Region, migURL.Name, patch).Do()
+	} else {
+		op, err = c.Compute().InstanceGroupManagers.Patch(migURL.Project, migURL.Zone, migURL.Name, patch).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("error starting rolling replace of MIG %s: %v", mig.Name, err)
+	}
+	if err := c.WaitForOp(op); err != nil {
+		return fmt.Errorf("error starting rolling replace of MIG %s: %v", mig.Name, err)
+	}
+
+	return wait.Poll(migUpdatePollInterval, migUpdatePollTimeout, func() (bool, error) {
+		return isRollingReplaceComplete(c, migURL)
+	})
+}
+
+// isRollingReplaceComplete returns true if the MIG has no pending actions and all its instances run the current InstanceTemplate
+func isRollingReplaceComplete(c GCECloud, migURL *GoogleCloudURL) (bool, error) {
+	var mig *compute.InstanceGroupManager
+	var err error
+	if migURL.Region != "" {
+		mig, err = c.Compute().RegionInstanceGroupManagers.Get(migURL.Project, migURL.Region, migURL.Name).Do()
+	} else {
+		mig, err = c.Compute().InstanceGroupManagers.Get(migURL.Project, migURL.Zone, migURL.Name).Do()
+	}
+	if err != nil {
+		return false, fmt.Errorf("error getting MIG %s: %v", migURL.Name, err)
+	}
+
+	if a := mig.CurrentActions; a != nil {
+		pending := a.Abandoning + a.Creating + a.CreatingWithoutRetries + a.Deleting + a.Recreating + a.Refreshing + a.Restarting + a.Verifying
+		if pending != 0 {
+			glog.V(2).Infof("MIG %s has %d pending actions", mig.Name, pending)
+			return false, nil
+		}
+	}
+
+	instances, err := ListManagedInstances(c, mig)
+	if err != nil {
+		return false, err
+	}
+
+	outdated := 0
+	for _, i := range instances {
+		if i.Version == nil || i.Version.InstanceTemplate != mig.InstanceTemplate {
+			outdated++
+		}
+	}
+	if outdated != 0 {
+		glog.V(2).Infof("MIG %s has %d instances still to be replaced", mig.Name, outdated)
+		return false, nil
+	}
+
+	glog.Infof("Rolling replace of MIG %s is complete", mig.Name)
+	return true, nil
+}
+
 // GetCloudGroups returns a map of CloudGroup that backs a list of instance groups
 func (c *gceCloudImplementation) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
 	return getCloudGroups(c, cluster, instancegroups, warnUnmatched, nodes)
@@ -135,77 +327,91 @@ func getCloudGroups(c GCECloud, cluster *kops.Cluster, instancegroups []*kops.In
 		return nil, err
 	}
 
-	for _, zoneName := range zones {
-		err := c.Compute().InstanceGroupManagers.List(project, zoneName).Pages(ctx, func(page *compute.InstanceGroupManagerList) error {
-			for _, mig := range page.Items {
-				name := mig.Name
+	addGroup := func(mig *compute.InstanceGroupManager) error {
+		name := mig.Name
 
-				instanceTemplate := instanceTemplates[mig.InstanceTemplate]
-				if instanceTemplate == nil {
-					glog.V(2).Infof("ignoring MIG %s with unmanaged InstanceTemplate: %s", name, mig.InstanceTemplate)
-					continue
-				}
+		instanceTemplate := instanceTemplates[mig.InstanceTemplate]
+		if instanceTemplate == nil {
+			glog.V(2).Infof("ignoring MIG %s with unmanaged InstanceTemplate: %s", name, mig.InstanceTemplate)
+			return nil
+		}
 
-				ig, err := matchInstanceGroup(mig, cluster, instancegroups)
-				if err != nil {
-					return fmt.Errorf("error getting instance group for MIG %q", name)
-				}
-				if ig == nil {
-					if warnUnmatched {
-						glog.Warningf("Found MIG with no corresponding instance group %q", name)
-					}
-					continue
-				}
+		ig, err := matchInstanceGroup(mig, cluster, instancegroups)
+		if err != nil {
+			return fmt.Errorf("error getting instance group for MIG %q", name)
+		}
+		if ig == nil {
+			if warnUnmatched {
+				glog.Warningf("Found MIG with no corresponding instance group %q", name)
+			}
+			return nil
+		}
 
-				g := &cloudinstances.CloudInstanceGroup{
-					HumanName:     mig.Name,
-					InstanceGroup: ig,
-					MinSize:       int(mig.TargetSize),
-					MaxSize:       int(mig.TargetSize),
-					Raw:           mig,
-				}
-				groups[mig.Name] = g
+		g := &cloudinstances.CloudInstanceGroup{
+			HumanName:     mig.Name,
+			InstanceGroup: ig,
+			MinSize:       int(mig.TargetSize),
+			MaxSize:       int(mig.TargetSize),
+			Raw:           mig,
+		}
+		groups[mig.Name] = g
 
-				latestInstanceTemplate := mig.InstanceTemplate
+		latestInstanceTemplate := mig.InstanceTemplate
 
-				instances, err := ListManagedInstances(c, mig)
-				if err != nil {
-					return err
-				}
+		instances, err := ListManagedInstances(c, mig)
+		if err != nil {
+			return err
+		}
 
-				for _, i := range instances {
-					id := i.Instance
-					cm := &cloudinstances.CloudInstanceGroupMember{
-						ID:                 id,
-						CloudInstanceGroup: g,
-					}
-
-					// Try first by provider ID
-					name := LastComponent(id)
-					providerID := "gce://" + project + "/" + zoneName + "/" + name
-					node := nodesByProviderID[providerID]
-
-					// fall back to lookup by external id
-					if node == nil {
-						glog.V(8).Infof("unable to find node by provider id %q, falling back to legacy external id", providerID)
-
-						externalID := strconv.FormatUint(i.Id, 10)
-						node = nodesByExternalID[externalID]
-					}
-
-					if node != nil {
-						cm.Node = node
-					} else {
-						glog.V(8).Infof("unable to find node for instance: %s", id)
-					}
-
-					if i.Version != nil && latestInstanceTemplate == i.Version.InstanceTemplate {
-						g.Ready = append(g.Ready, cm)
-					} else {
-						g.NeedUpdate = append(g.NeedUpdate, cm)
-					}
-				}
+		for _, i := range instances {
+			id := i.Instance
+			cm := &cloudinstances.CloudInstanceGroupMember{
+				ID:                 id,
+				CloudInstanceGroup: g,
+			}
+
+			// The instances of a regional MIG are spread across zones, so we take the zone from the instance
+			zoneName := LastComponent(mig.Zone)
+			if u, err := ParseGoogleCloudURL(id); err == nil && u.Zone != "" {
+				zoneName = u.Zone
+			}
+
+			// Try first by provider ID
+			name := LastComponent(id)
+			providerID := "gce://" + project + "/" + zoneName + "/" + name
+			node := nodesByProviderID[providerID]
+
+			// fall back to lookup by external id
+			if node == nil {
+				glog.V(8).Infof("unable to find node by provider id %q, falling back to legacy external id", providerID)
+
+				externalID := strconv.FormatUint(i.Id, 10)
+				node = nodesByExternalID[externalID]
+			}
+
+			if node != nil {
+				cm.Node = node
+			} else {
+				glog.V(8).Infof("unable to find node for instance: %s", id)
+			}
+
+			if i.Version != nil && latestInstanceTemplate == i.Version.InstanceTemplate {
+				g.Ready = append(g.Ready, cm)
+			} else {
+				cm.UpdateReasons = []string{cloudinstances.ReasonConfigurationChanged}
+				g.NeedUpdate = append(g.NeedUpdate, cm)
+			}
+		}
+
+		return nil
+	}
 
+	for _, zoneName := range zones {
+		err := c.Compute().InstanceGroupManagers.List(project, zoneName).Pages(ctx, func(page *compute.InstanceGroupManagerList) error {
+			for _, mig := range page.Items {
+				if err := addGroup(mig); err != nil {
+					return err
+				}
 			}
 			return nil
 		})
@@ -215,6 +421,19 @@ func getCloudGroups(c GCECloud, cluster *kops.Cluster, instancegroups []*kops.In
 		}
 	}
 
+	// Regional MIGs span all the zones of the region
+	err = c.Compute().RegionInstanceGroupManagers.List(project, c.Region()).Pages(ctx, func(page *compute.RegionInstanceGroupManagerList) error {
+		for _, mig := range page.Items {
+			if err := addGroup(mig); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing RegionInstanceGroupManagers: %v", err)
+	}
+
 	return groups, nil
 }
 
@@ -230,6 +449,13 @@ func NameForInstanceGroupManager(c *kops.Cluster, ig *kops.InstanceGroup, zone s
 	return name
 }
 
+// NameForRegionInstanceGroupManager builds a name for the regional InstanceGroupManager of an instance group
+func NameForRegionInstanceGroupManager(c *kops.Cluster, ig *kops.InstanceGroup) string {
+	name := SafeObjectName(ig.ObjectMeta.Name, c.ObjectMeta.Name)
+	name = LimitedLengthName(name, 63)
+	return name
+}
+
 // LimitedLengthName returns a string subject to a maximum length
 func LimitedLengthName(s string, n int) string {
 	// We only use the hash if we need to
@@ -261,7 +487,12 @@ func matchInstanceGroup(mig *compute.InstanceGroupManager, c *kops.Cluster, inst
 	migName := LastComponent(mig.Name)
 	var matches []*kops.InstanceGroup
 	for _, ig := range instancegroups {
-		name := NameForInstanceGroupManager(c, ig, LastComponent(mig.Zone))
+		var name string
+		if mig.Region != "" {
+			name = NameForRegionInstanceGroupManager(c, ig)
+		} else {
+			name = NameForInstanceGroupManager(c, ig, LastComponent(mig.Zone))
+		}
 		if name == migName {
 			matches = append(matches, ig)
 		}
@@ -0,0 +1,59 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestZoneTargetSize(t *testing.T) {
+	ig := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: kops.InstanceGroupSpec{
+			Zones: []string{"us-central1-c", "us-central1-a", "us-central1-b"},
+		},
+	}
+
+	grid := []struct {
+		zone     string
+		size     int
+		expected int
+	}{
+		{zone: "us-central1-a", size: 5, expected: 2},
+		{zone: "us-central1-b", size: 5, expected: 2},
+		{zone: "us-central1-c", size: 5, expected: 1},
+		{zone: "us-central1-a", size: 0, expected: 0},
+		{zone: "us-central1-c", size: 6, expected: 2},
+	}
+	for _, g := range grid {
+		actual, err := zoneTargetSize(ig, g.zone, g.size)
+		if err != nil {
+			t.Errorf("zone %s, size %d: unexpected error: %v", g.zone, g.size, err)
+			continue
+		}
+		if actual != g.expected {
+			t.Errorf("zone %s, size %d: expected %d, got %d", g.zone, g.size, g.expected, actual)
+		}
+	}
+
+	if _, err := zoneTargetSize(ig, "us-central1-f", 5); err == nil {
+		t.Errorf("expected error for a zone outside the instance group")
+	}
+}
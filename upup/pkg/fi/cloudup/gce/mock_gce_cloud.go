@@ -43,6 +43,8 @@ var _ GCECloud = &mockGCECloud{}
 // InstallMockGCECloud registers a mockGCECloud implementation for the specified region & project
 func InstallMockGCECloud(region string, project string) *mockGCECloud {
 	i := buildMockGCECloud(region, project)
+	gceCloudInstancesMutex.Lock()
+	defer gceCloudInstancesMutex.Unlock()
 	gceCloudInstances[region+"::"+project] = i
 	return i
 }
@@ -32,7 +32,12 @@ func DeleteInstanceGroupManager(c GCECloud, t *compute.InstanceGroupManager) err
 		return err
 	}
 
-	op, err := c.Compute().InstanceGroupManagers.Delete(u.Project, u.Zone, u.Name).Do()
+	var op *compute.Operation
+	if u.Region != "" {
+		op, err = c.Compute().RegionInstanceGroupManagers.Delete(u.Project, u.Region, u.Name).Do()
+	} else {
+		op, err = c.Compute().InstanceGroupManagers.Delete(u.Project, u.Zone, u.Name).Do()
+	}
 	if err != nil {
 		if IsNotFound(err) {
 			glog.Infof("InstanceGroupManager not found, assuming deleted: %q", t.SelfLink)
@@ -89,6 +94,10 @@ func ListManagedInstances(c GCECloud, igm *compute.InstanceGroupManager) ([]*com
 	ctx := context.Background()
 	project := c.Project()
 
+	if igm.Region != "" {
+		return listRegionManagedInstances(c, igm)
+	}
+
 	zoneName := LastComponent(igm.Zone)
 
 	// TODO: Only select a subset of fields
@@ -110,3 +119,23 @@ func ListManagedInstances(c GCECloud, igm *compute.InstanceGroupManager) ([]*com
 
 	return instances, nil
 }
+
+// listRegionManagedInstances lists the instances of a regional InstanceGroupManager, in all its zones
+func listRegionManagedInstances(c GCECloud, igm *compute.InstanceGroupManager) ([]*compute.ManagedInstance, error) {
+	ctx := context.Background()
+	project := c.Project()
+
+	regionName := LastComponent(igm.Region)
+
+	var instances []*compute.ManagedInstance
+	err := c.Compute().RegionInstanceGroupManagers.ListManagedInstances(project, regionName, igm.Name).Pages(ctx,
+		func(page *compute.RegionInstanceGroupManagersListInstancesResponse) error {
+			instances = append(instances, page.ManagedInstances...)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error listing ManagedInstances in %s: %v", igm.Name, err)
+	}
+
+	return instances, nil
+}
@@ -19,6 +19,7 @@ package gcetasks
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	compute "google.golang.org/api/compute/v0.beta"
 	"k8s.io/kops/upup/pkg/fi"
@@ -31,7 +32,12 @@ type InstanceGroupManager struct {
 	Name      *string
 	Lifecycle *fi.Lifecycle
 
-	Zone             *string
+	// Zone is the zone of a zonal InstanceGroupManager
+	Zone *string
+	// Region is the region of a regional InstanceGroupManager, which spreads its instances across DistributionZones
+	Region            *string
+	DistributionZones []string
+
 	BaseInstanceName *string
 	InstanceTemplate *InstanceTemplate
 	TargetSize       *int64
@@ -48,7 +54,13 @@ func (e *InstanceGroupManager) CompareWithID() *string {
 func (e *InstanceGroupManager) Find(c *fi.Context) (*InstanceGroupManager, error) {
 	cloud := c.Cloud.(gce.GCECloud)
 
-	r, err := cloud.Compute().InstanceGroupManagers.Get(cloud.Project(), *e.Zone, *e.Name).Do()
+	var r *compute.InstanceGroupManager
+	var err error
+	if e.Region != nil {
+		r, err = cloud.Compute().RegionInstanceGroupManagers.Get(cloud.Project(), *e.Region, *e.Name).Do()
+	} else {
+		r, err = cloud.Compute().InstanceGroupManagers.Get(cloud.Project(), *e.Zone, *e.Name).Do()
+	}
 	if err != nil {
 		if gce.IsNotFound(err) {
 			return nil, nil
@@ -58,7 +70,17 @@ func (e *InstanceGroupManager) Find(c *fi.Context) (*InstanceGroupManager, error
 
 	actual := &InstanceGroupManager{}
 	actual.Name = &r.Name
-	actual.Zone = fi.String(lastComponent(r.Zone))
+	if r.Region != "" {
+		actual.Region = fi.String(lastComponent(r.Region))
+		if r.DistributionPolicy != nil {
+			for _, z := range r.DistributionPolicy.Zones {
+				actual.DistributionZones = append(actual.DistributionZones, lastComponent(z.Zone))
+			}
+			sort.Strings(actual.DistributionZones)
+		}
+	} else {
+		actual.Zone = fi.String(lastComponent(r.Zone))
+	}
 	actual.BaseInstanceName = &r.BaseInstanceName
 	actual.TargetSize = &r.TargetSize
 	actual.InstanceTemplate = &InstanceTemplate{ID: fi.String(lastComponent(r.InstanceTemplate))}
@@ -81,6 +103,23 @@ func (e *InstanceGroupManager) Run(c *fi.Context) error {
 }
 
 func (_ *InstanceGroupManager) CheckChanges(a, e, changes *InstanceGroupManager) error {
+	if (e.Zone == nil) == (e.Region == nil) {
+		return fmt.Errorf("exactly one of Zone and Region must be set on InstanceGroupManager %q", fi.StringValue(e.Name))
+	}
+	if e.Region != nil && len(e.DistributionZones) == 0 {
+		return fi.RequiredField("DistributionZones")
+	}
+	if a != nil {
+		if changes.Zone != nil {
+			return fi.CannotChangeField("Zone")
+		}
+		if changes.Region != nil {
+			return fi.CannotChangeField("Region")
+		}
+		if changes.DistributionZones != nil {
+			return fi.CannotChangeField("DistributionZones")
+		}
+	}
 	return nil
 }
 
@@ -94,7 +133,7 @@ func (_ *InstanceGroupManager) RenderGCE(t *gce.GCEAPITarget, a, e, changes *Ins
 
 	i := &compute.InstanceGroupManager{
 		Name:             *e.Name,
-		Zone:             *e.Zone,
+		Zone:             fi.StringValue(e.Zone),
 		BaseInstanceName: *e.BaseInstanceName,
 		TargetSize:       *e.TargetSize,
 		InstanceTemplate: instanceTemplateURL,
@@ -104,6 +143,10 @@ func (_ *InstanceGroupManager) RenderGCE(t *gce.GCEAPITarget, a, e, changes *Ins
 		i.TargetPools = append(i.TargetPools, targetPool.URL(t.Cloud))
 	}
 
+	if e.Region != nil {
+		return renderRegionInstanceGroupManager(t, a, e, changes, i)
+	}
+
 	if a == nil {
 		if i.TargetSize == 0 {
 			// TargetSize 0 will normally be omitted by the marshalling code; we need to force it
@@ -178,19 +221,104 @@ func (_ *InstanceGroupManager) RenderGCE(t *gce.GCEAPITarget, a, e, changes *Ins
 	return nil
 }
 
+// renderRegionInstanceGroupManager creates or updates a regional InstanceGroupManager
+func renderRegionInstanceGroupManager(t *gce.GCEAPITarget, a, e, changes *InstanceGroupManager, i *compute.InstanceGroupManager) error {
+	project := t.Cloud.Project()
+	region := *e.Region
+
+	if a == nil {
+		i.DistributionPolicy = &compute.DistributionPolicy{}
+		for _, zone := range e.DistributionZones {
+			zoneURL := &gce.GoogleCloudURL{Project: project, Type: "zones", Name: zone}
+			i.DistributionPolicy.Zones = append(i.DistributionPolicy.Zones, &compute.DistributionPolicyZoneConfiguration{
+				Zone: zoneURL.BuildURL(),
+			})
+		}
+
+		if i.TargetSize == 0 {
+			// TargetSize 0 will normally be omitted by the marshalling code; we need to force it
+			i.ForceSendFields = append(i.ForceSendFields, "TargetSize")
+		}
+		op, err := t.Cloud.Compute().RegionInstanceGroupManagers.Insert(project, region, i).Do()
+		if err != nil {
+			return fmt.Errorf("error creating RegionInstanceGroupManager: %v", err)
+		}
+
+		if err := t.Cloud.WaitForOp(op); err != nil {
+			return fmt.Errorf("error creating RegionInstanceGroupManager: %v", err)
+		}
+		return nil
+	}
+
+	if changes.TargetPools != nil {
+		request := &compute.RegionInstanceGroupManagersSetTargetPoolsRequest{
+			TargetPools: i.TargetPools,
+		}
+		op, err := t.Cloud.Compute().RegionInstanceGroupManagers.SetTargetPools(project, region, i.Name, request).Do()
+		if err != nil {
+			return fmt.Errorf("error updating TargetPools for RegionInstanceGroupManager: %v", err)
+		}
+
+		if err := t.Cloud.WaitForOp(op); err != nil {
+			return fmt.Errorf("error updating TargetPools for RegionInstanceGroupManager: %v", err)
+		}
+
+		changes.TargetPools = nil
+	}
+
+	if changes.InstanceTemplate != nil {
+		request := &compute.RegionInstanceGroupManagersSetTemplateRequest{
+			InstanceTemplate: i.InstanceTemplate,
+		}
+		op, err := t.Cloud.Compute().RegionInstanceGroupManagers.SetInstanceTemplate(project, region, i.Name, request).Do()
+		if err != nil {
+			return fmt.Errorf("error updating InstanceTemplate for RegionInstanceGroupManager: %v", err)
+		}
+
+		if err := t.Cloud.WaitForOp(op); err != nil {
+			return fmt.Errorf("error updating InstanceTemplate for RegionInstanceGroupManager: %v", err)
+		}
+
+		changes.InstanceTemplate = nil
+	}
+
+	if changes.TargetSize != nil {
+		op, err := t.Cloud.Compute().RegionInstanceGroupManagers.Resize(project, region, i.Name, i.TargetSize).Do()
+		if err != nil {
+			return fmt.Errorf("error resizing RegionInstanceGroupManager: %v", err)
+		}
+
+		if err := t.Cloud.WaitForOp(op); err != nil {
+			return fmt.Errorf("error resizing RegionInstanceGroupManager: %v", err)
+		}
+
+		changes.TargetSize = nil
+	}
+
+	empty := &InstanceGroupManager{}
+	if !reflect.DeepEqual(empty, changes) {
+		return fmt.Errorf("cannot apply changes to RegionInstanceGroupManager: %v", changes)
+	}
+
+	return nil
+}
+
 type terraformInstanceGroupManager struct {
-	Name             *string              `json:"name"`
-	Zone             *string              `json:"zone"`
-	BaseInstanceName *string              `json:"base_instance_name"`
-	InstanceTemplate *terraform.Literal   `json:"instance_template"`
-	TargetSize       *int64               `json:"target_size"`
-	TargetPools      []*terraform.Literal `json:"target_pools,omitempty"`
+	Name              *string              `json:"name"`
+	Zone              *string              `json:"zone,omitempty"`
+	Region            *string              `json:"region,omitempty"`
+	DistributionZones []string             `json:"distribution_policy_zones,omitempty"`
+	BaseInstanceName  *string              `json:"base_instance_name"`
+	InstanceTemplate  *terraform.Literal   `json:"instance_template"`
+	TargetSize        *int64               `json:"target_size"`
+	TargetPools       []*terraform.Literal `json:"target_pools,omitempty"`
 }
 
 func (_ *InstanceGroupManager) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *InstanceGroupManager) error {
 	tf := &terraformInstanceGroupManager{
 		Name:             e.Name,
 		Zone:             e.Zone,
+		Region:           e.Region,
 		BaseInstanceName: e.BaseInstanceName,
 		InstanceTemplate: e.InstanceTemplate.TerraformLink(),
 		TargetSize:       e.TargetSize,
@@ -200,5 +328,10 @@ func (_ *InstanceGroupManager) RenderTerraform(t *terraform.TerraformTarget, a,
 		tf.TargetPools = append(tf.TargetPools, targetPool.TerraformLink())
 	}
 
+	if e.Region != nil {
+		tf.DistributionZones = e.DistributionZones
+		return t.RenderResource("google_compute_region_instance_group_manager", *e.Name, tf)
+	}
+
 	return t.RenderResource("google_compute_instance_group_manager", *e.Name, tf)
 }
@@ -0,0 +1,436 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Location is a Hetzner Cloud location, e.g. fsn1
+type Location struct {
+	Name        string `json:"name"`
+	NetworkZone string `json:"network_zone"`
+}
+
+// IPv4 is a public IPv4 address
+type IPv4 struct {
+	IP string `json:"ip"`
+}
+
+// PublicNet holds the public addresses of a server or load balancer
+type PublicNet struct {
+	IPv4 IPv4 `json:"ipv4"`
+}
+
+// PrivateNet is the attachment of a server or load balancer to a network
+type PrivateNet struct {
+	Network int    `json:"network"`
+	IP      string `json:"ip"`
+}
+
+// Server is a Hetzner Cloud server
+type Server struct {
+	ID         int               `json:"id"`
+	Name       string            `json:"name"`
+	Status     string            `json:"status"`
+	Labels     map[string]string `json:"labels"`
+	PublicNet  PublicNet         `json:"public_net"`
+	PrivateNet []PrivateNet      `json:"private_net"`
+	ServerType struct {
+		Name string `json:"name"`
+	} `json:"server_type"`
+	Image *struct {
+		Name string `json:"name"`
+	} `json:"image"`
+	Datacenter struct {
+		Location Location `json:"location"`
+	} `json:"datacenter"`
+	PlacementGroup *PlacementGroup `json:"placement_group"`
+}
+
+// ServerCreateOpts are the options for creating a server
+type ServerCreateOpts struct {
+	Name             string            `json:"name"`
+	ServerType       string            `json:"server_type"`
+	Image            string            `json:"image"`
+	Location         string            `json:"location,omitempty"`
+	SSHKeys          []int             `json:"ssh_keys,omitempty"`
+	UserData         string            `json:"user_data,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Networks         []int             `json:"networks,omitempty"`
+	PlacementGroup   int               `json:"placement_group,omitempty"`
+	StartAfterCreate bool              `json:"start_after_create"`
+}
+
+// NetworkSubnet is a subnet of a network
+type NetworkSubnet struct {
+	Type        string `json:"type"`
+	IPRange     string `json:"ip_range"`
+	NetworkZone string `json:"network_zone"`
+}
+
+// Network is a Hetzner Cloud private network
+type Network struct {
+	ID      int               `json:"id"`
+	Name    string            `json:"name"`
+	IPRange string            `json:"ip_range"`
+	Subnets []NetworkSubnet   `json:"subnets"`
+	Servers []int             `json:"servers"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// NetworkCreateOpts are the options for creating a network
+type NetworkCreateOpts struct {
+	Name    string            `json:"name"`
+	IPRange string            `json:"ip_range"`
+	Subnets []NetworkSubnet   `json:"subnets,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// LoadBalancerTarget is a target of a load balancer; kops only uses label selector targets
+type LoadBalancerTarget struct {
+	Type          string `json:"type"`
+	LabelSelector *struct {
+		Selector string `json:"selector"`
+	} `json:"label_selector,omitempty"`
+	UsePrivateIP bool `json:"use_private_ip"`
+}
+
+// LoadBalancerHealthCheck is the health check of a load balancer service
+type LoadBalancerHealthCheck struct {
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+	Interval int    `json:"interval"`
+	Timeout  int    `json:"timeout"`
+	Retries  int    `json:"retries"`
+}
+
+// LoadBalancerService is a port forwarded by a load balancer
+type LoadBalancerService struct {
+	Protocol        string                   `json:"protocol"`
+	ListenPort      int                      `json:"listen_port"`
+	DestinationPort int                      `json:"destination_port"`
+	HealthCheck     *LoadBalancerHealthCheck `json:"health_check,omitempty"`
+}
+
+// LoadBalancer is a Hetzner Cloud load balancer
+type LoadBalancer struct {
+	ID               int               `json:"id"`
+	Name             string            `json:"name"`
+	Labels           map[string]string `json:"labels"`
+	PublicNet        PublicNet         `json:"public_net"`
+	PrivateNet       []PrivateNet      `json:"private_net"`
+	Location         Location          `json:"location"`
+	LoadBalancerType struct {
+		Name string `json:"name"`
+	} `json:"load_balancer_type"`
+	Services []LoadBalancerService `json:"services"`
+	Targets  []LoadBalancerTarget  `json:"targets"`
+}
+
+// LoadBalancerCreateOpts are the options for creating a load balancer
+type LoadBalancerCreateOpts struct {
+	Name             string                `json:"name"`
+	LoadBalancerType string                `json:"load_balancer_type"`
+	Location         string                `json:"location"`
+	Labels           map[string]string     `json:"labels,omitempty"`
+	Network          int                   `json:"network,omitempty"`
+	Services         []LoadBalancerService `json:"services,omitempty"`
+	Targets          []LoadBalancerTarget  `json:"targets,omitempty"`
+}
+
+// LabelSelectorTarget builds a load balancer target for the servers matching selector
+func LabelSelectorTarget(selector string, usePrivateIP bool) LoadBalancerTarget {
+	t := LoadBalancerTarget{Type: "label_selector", UsePrivateIP: usePrivateIP}
+	t.LabelSelector = &struct {
+		Selector string `json:"selector"`
+	}{Selector: selector}
+	return t
+}
+
+// PlacementGroupTypeSpread places each server of the group on a different physical host
+const PlacementGroupTypeSpread = "spread"
+
+// MaxSpreadPlacementGroupSize is the maximum number of servers in a spread placement group
+const MaxSpreadPlacementGroupSize = 10
+
+// PlacementGroup is a Hetzner Cloud placement group
+type PlacementGroup struct {
+	ID      int               `json:"id"`
+	Name    string            `json:"name"`
+	Type    string            `json:"type"`
+	Servers []int             `json:"servers"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// SSHKey is an SSH public key registered with Hetzner Cloud
+type SSHKey struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Fingerprint string            `json:"fingerprint"`
+	PublicKey   string            `json:"public_key"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// Volume is a Hetzner Cloud block storage volume
+type Volume struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Size        int               `json:"size"`
+	Server      *int              `json:"server"`
+	Location    Location          `json:"location"`
+	Labels      map[string]string `json:"labels"`
+	LinuxDevice string            `json:"linux_device"`
+}
+
+// VolumeCreateOpts are the options for creating a volume
+type VolumeCreateOpts struct {
+	Name     string            `json:"name"`
+	Size     int               `json:"size"`
+	Location string            `json:"location"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// LabelSelector builds a label selector matching all the given labels
+func LabelSelector(labels map[string]string) string {
+	var terms []string
+	for k, v := range labels {
+		terms = append(terms, k+"="+v)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, ",")
+}
+
+func (c *Client) ListServers(labelSelector string) ([]*Server, error) {
+	var servers []*Server
+	err := c.list("/servers", labelSelector, func(data json.RawMessage) error {
+		page := struct {
+			Servers []*Server `json:"servers"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding servers: %v", err)
+		}
+		servers = append(servers, page.Servers...)
+		return nil
+	})
+	return servers, err
+}
+
+func (c *Client) GetServer(id int) (*Server, error) {
+	resp := struct {
+		Server *Server `json:"server"`
+	}{}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/servers/%d", id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Server, nil
+}
+
+func (c *Client) CreateServer(opts *ServerCreateOpts) (*Server, error) {
+	resp := struct {
+		Server *Server `json:"server"`
+	}{}
+	if err := c.do(http.MethodPost, "/servers", nil, opts, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Server, nil
+}
+
+func (c *Client) DeleteServer(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/servers/%d", id), nil, nil, nil)
+}
+
+func (c *Client) ListNetworks(labelSelector string) ([]*Network, error) {
+	var networks []*Network
+	err := c.list("/networks", labelSelector, func(data json.RawMessage) error {
+		page := struct {
+			Networks []*Network `json:"networks"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding networks: %v", err)
+		}
+		networks = append(networks, page.Networks...)
+		return nil
+	})
+	return networks, err
+}
+
+func (c *Client) CreateNetwork(opts *NetworkCreateOpts) (*Network, error) {
+	resp := struct {
+		Network *Network `json:"network"`
+	}{}
+	if err := c.do(http.MethodPost, "/networks", nil, opts, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Network, nil
+}
+
+// AddNetworkSubnet adds a subnet to a network
+func (c *Client) AddNetworkSubnet(id int, subnet NetworkSubnet) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/networks/%d/actions/add_subnet", id), nil, subnet, nil)
+}
+
+func (c *Client) DeleteNetwork(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/networks/%d", id), nil, nil, nil)
+}
+
+func (c *Client) ListLoadBalancers(labelSelector string) ([]*LoadBalancer, error) {
+	var loadBalancers []*LoadBalancer
+	err := c.list("/load_balancers", labelSelector, func(data json.RawMessage) error {
+		page := struct {
+			LoadBalancers []*LoadBalancer `json:"load_balancers"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding load balancers: %v", err)
+		}
+		loadBalancers = append(loadBalancers, page.LoadBalancers...)
+		return nil
+	})
+	return loadBalancers, err
+}
+
+func (c *Client) CreateLoadBalancer(opts *LoadBalancerCreateOpts) (*LoadBalancer, error) {
+	resp := struct {
+		LoadBalancer *LoadBalancer `json:"load_balancer"`
+	}{}
+	if err := c.do(http.MethodPost, "/load_balancers", nil, opts, &resp); err != nil {
+		return nil, err
+	}
+	return resp.LoadBalancer, nil
+}
+
+func (c *Client) DeleteLoadBalancer(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/load_balancers/%d", id), nil, nil, nil)
+}
+
+func (c *Client) ListPlacementGroups(labelSelector string) ([]*PlacementGroup, error) {
+	var placementGroups []*PlacementGroup
+	err := c.list("/placement_groups", labelSelector, func(data json.RawMessage) error {
+		page := struct {
+			PlacementGroups []*PlacementGroup `json:"placement_groups"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding placement groups: %v", err)
+		}
+		placementGroups = append(placementGroups, page.PlacementGroups...)
+		return nil
+	})
+	return placementGroups, err
+}
+
+func (c *Client) CreatePlacementGroup(name string, labels map[string]string) (*PlacementGroup, error) {
+	req := &PlacementGroup{Name: name, Type: PlacementGroupTypeSpread, Labels: labels}
+	resp := struct {
+		PlacementGroup *PlacementGroup `json:"placement_group"`
+	}{}
+	if err := c.do(http.MethodPost, "/placement_groups", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PlacementGroup, nil
+}
+
+func (c *Client) DeletePlacementGroup(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/placement_groups/%d", id), nil, nil, nil)
+}
+
+func (c *Client) ListSSHKeys(labelSelector string) ([]*SSHKey, error) {
+	var keys []*SSHKey
+	err := c.list("/ssh_keys", labelSelector, func(data json.RawMessage) error {
+		page := struct {
+			SSHKeys []*SSHKey `json:"ssh_keys"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding ssh keys: %v", err)
+		}
+		keys = append(keys, page.SSHKeys...)
+		return nil
+	})
+	return keys, err
+}
+
+func (c *Client) CreateSSHKey(name string, publicKey string, labels map[string]string) (*SSHKey, error) {
+	req := &SSHKey{Name: name, PublicKey: publicKey, Labels: labels}
+	resp := struct {
+		SSHKey *SSHKey `json:"ssh_key"`
+	}{}
+	if err := c.do(http.MethodPost, "/ssh_keys", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.SSHKey, nil
+}
+
+func (c *Client) DeleteSSHKey(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/ssh_keys/%d", id), nil, nil, nil)
+}
+
+func (c *Client) ListVolumes(labelSelector string) ([]*Volume, error) {
+	var volumes []*Volume
+	err := c.list("/volumes", labelSelector, func(data json.RawMessage) error {
+		page := struct {
+			Volumes []*Volume `json:"volumes"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding volumes: %v", err)
+		}
+		volumes = append(volumes, page.Volumes...)
+		return nil
+	})
+	return volumes, err
+}
+
+func (c *Client) CreateVolume(opts *VolumeCreateOpts) (*Volume, error) {
+	resp := struct {
+		Volume *Volume `json:"volume"`
+	}{}
+	if err := c.do(http.MethodPost, "/volumes", nil, opts, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Volume, nil
+}
+
+// GetVolume returns a single volume by id
+func (c *Client) GetVolume(id int) (*Volume, error) {
+	resp := struct {
+		Volume *Volume `json:"volume"`
+	}{}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/volumes/%d", id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Volume, nil
+}
+
+// AttachVolume attaches a volume to a server, without mounting it
+func (c *Client) AttachVolume(volumeID int, serverID int) error {
+	req := struct {
+		Server    int  `json:"server"`
+		Automount bool `json:"automount"`
+	}{Server: serverID}
+	return c.do(http.MethodPost, fmt.Sprintf("/volumes/%d/actions/attach", volumeID), nil, req, nil)
+}
+
+// DetachVolume detaches a volume from the server it is attached to
+func (c *Client) DetachVolume(volumeID int) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/volumes/%d/actions/detach", volumeID), nil, nil, nil)
+}
+
+func (c *Client) DeleteVolume(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/volumes/%d", id), nil, nil, nil)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/cloudtrace"
+	"k8s.io/kops/pkg/retrypolicy"
+)
+
+// DefaultEndpoint is the base URL of the Hetzner Cloud API
+const DefaultEndpoint = "https://api.hetzner.cloud/v1"
+
+// TokenEnvVar is the environment variable holding the Hetzner Cloud API token
+const TokenEnvVar = "HCLOUD_TOKEN"
+
+// listPageSize is the number of items requested per page when listing resources
+const listPageSize = 50
+
+// Client is a minimal client for the Hetzner Cloud REST API
+type Client struct {
+	Endpoint   string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClientFromEnv builds a client authenticated with the token from HCLOUD_TOKEN
+func NewClientFromEnv() (*Client, error) {
+	token := os.Getenv(TokenEnvVar)
+	if token == "" {
+		return nil, fmt.Errorf("%s is required", TokenEnvVar)
+	}
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		Token:      token,
+		HTTPClient: newHTTPClient(),
+	}, nil
+}
+
+// defaultTimeout is the maximum duration of an API call, unless the retry policy sets one
+const defaultTimeout = 60 * time.Second
+
+// newHTTPClient builds the HTTP client for the API, which retries failed idempotent requests and bounds the duration
+// of calls as configured by the retry policy, and records calls if tracing is enabled
+func newHTTPClient() *http.Client {
+	timeout := defaultTimeout
+	if t := retrypolicy.Current().Timeout; t != 0 {
+		timeout = t
+	}
+	return &http.Client{
+		Transport: cloudtrace.WrapTransport("hetzner", retrypolicy.WrapTransport(http.DefaultTransport)),
+		Timeout:   timeout,
+	}
+}
+
+// APIError is an error returned by the Hetzner Cloud API
+type APIError struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("hetzner cloud API error (%d %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound returns true if err is an API error for a resource that does not exist
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && (apiErr.StatusCode == http.StatusNotFound || apiErr.Code == "not_found")
+}
+
+type errorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+type paginationMeta struct {
+	Meta struct {
+		Pagination struct {
+			NextPage *int `json:"next_page"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+// do sends a request to the API, decoding the JSON response into out if it is not nil
+func (c *Client) do(method string, path string, query url.Values, in interface{}, out interface{}) error {
+	u := c.Endpoint + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error encoding request to %s: %v", path, err)
+		}
+		body = b
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error building request to %s: %v", path, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	glog.V(4).Infof("hetzner cloud API request %s %s", method, u)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from %s %s: %v", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errResp := &errorResponse{}
+		if err := json.Unmarshal(data, errResp); err != nil || errResp.Error == nil {
+			errResp.Error = &APIError{Message: string(data)}
+		}
+		errResp.Error.StatusCode = resp.StatusCode
+		return errResp.Error
+	}
+
+	if out != nil && len(data) != 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("error decoding response from %s %s: %v", method, path, err)
+		}
+	}
+	return nil
+}
+
+// list fetches every page of a list endpoint, calling fn with the raw body of each page
+func (c *Client) list(path string, labelSelector string, fn func(data json.RawMessage) error) error {
+	page := 1
+	for {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(listPageSize))
+		if labelSelector != "" {
+			query.Set("label_selector", labelSelector)
+		}
+
+		var data json.RawMessage
+		if err := c.do(http.MethodGet, path, query, nil, &data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+
+		meta := &paginationMeta{}
+		if err := json.Unmarshal(data, meta); err != nil {
+			return fmt.Errorf("error decoding pagination from %s: %v", path, err)
+		}
+		if meta.Meta.Pagination.NextPage == nil || *meta.Meta.Pagination.NextPage <= page {
+			return nil
+		}
+		page = *meta.Meta.Pagination.NextPage
+	}
+}
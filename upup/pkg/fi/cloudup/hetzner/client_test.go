@@ -0,0 +1,117 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/retrypolicy"
+)
+
+func newTestClient(handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	client := &Client{
+		Endpoint: server.URL,
+		Token:    "token",
+	}
+	return client, server.Close
+}
+
+func TestListServersPaginates(t *testing.T) {
+	client, done := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("label_selector") != "kops.k8s.io/cluster=test.k8s.local" {
+			t.Errorf("unexpected label_selector %q", r.URL.Query().Get("label_selector"))
+		}
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `{"servers":[{"id":1,"name":"a"}],"meta":{"pagination":{"next_page":2}}}`)
+		case "2":
+			fmt.Fprint(w, `{"servers":[{"id":2,"name":"b"}],"meta":{"pagination":{"next_page":null}}}`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+	defer done()
+
+	servers, err := client.ListServers(LabelSelector(map[string]string{LabelCluster: "test.k8s.local"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(servers) != 2 || servers[0].Name != "a" || servers[1].Name != "b" {
+		t.Fatalf("unexpected servers: %v", servers)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	client, done := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"not_found","message":"server not found"}}`)
+	})
+	defer done()
+
+	_, err := client.GetServer(42)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	retrypolicy.Set(retrypolicy.Policy{Timeout: 5 * time.Second, MaxAttempts: 2, Backoff: time.Millisecond})
+	defer retrypolicy.Set(retrypolicy.Policy{})
+
+	attempts := 0
+	client, done := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"code":"unavailable","message":"try again"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"server":{"id":42,"name":"a"}}`)
+	})
+	defer done()
+
+	client.HTTPClient = newHTTPClient()
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("expected the timeout of the retry policy, got %v", client.HTTPClient.Timeout)
+	}
+
+	server, err := client.GetServer(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if server.Name != "a" || attempts != 2 {
+		t.Fatalf("unexpected server %v after %d attempts", server, attempts)
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	actual := LabelSelector(map[string]string{"b": "2", "a": "1"})
+	if actual != "a=1,b=2" {
+		t.Fatalf("unexpected selector %q", actual)
+	}
+}
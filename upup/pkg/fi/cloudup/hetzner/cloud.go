@@ -0,0 +1,338 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// LabelCluster is the label holding the name of the cluster a resource belongs to
+	LabelCluster = "kops.k8s.io/cluster"
+	// LabelInstanceGroup is the label holding the instance group of a server
+	LabelInstanceGroup = "kops.k8s.io/instance-group"
+	// LabelInstanceRole is the label holding the role of a server, e.g. master
+	LabelInstanceRole = "kops.k8s.io/instance-role"
+	// LabelConfigHash is the label holding the hash of the launch spec a server was created from
+	LabelConfigHash = "kops.k8s.io/config-hash"
+	// LabelEtcdCluster is the label holding the etcd cluster of an etcd volume
+	LabelEtcdCluster = "kops.k8s.io/etcd-cluster"
+	// LabelEtcdMember is the label holding the etcd member of an etcd volume
+	LabelEtcdMember = "kops.k8s.io/etcd-member"
+)
+
+// Locations are the Hetzner Cloud locations, which kops treats as zones
+var Locations = []string{"fsn1", "nbg1", "hel1"}
+
+// HetznerCloud is the cloud interface for Hetzner Cloud
+type HetznerCloud interface {
+	fi.Cloud
+
+	// Client returns the API client
+	Client() *Client
+	// Location returns the location of the cluster
+	Location() string
+	// ClusterName returns the name of the cluster
+	ClusterName() string
+	// ClusterLabels returns the labels identifying the resources of the cluster
+	ClusterLabels() map[string]string
+
+	ReadLaunchSpec(igName string) (*LaunchSpec, error)
+	WriteLaunchSpec(spec *LaunchSpec) error
+	DeleteLaunchSpec(igName string) error
+
+	// CreateServer creates a server of the instance group from its launch spec
+	CreateServer(spec *LaunchSpec) (*Server, error)
+
+	// GetApiIngressStatus returns the public address of the API load balancer, or of the masters if there is none
+	GetApiIngressStatus(cluster *kops.Cluster) ([]kops.ApiIngressStatus, error)
+	// FindClusterStatus discovers the status of the cluster from its etcd volumes
+	FindClusterStatus(cluster *kops.Cluster) (*kops.ClusterStatus, error)
+}
+
+type hetznerCloudImplementation struct {
+	client      *Client
+	location    string
+	clusterName string
+
+	// stateBase is where the launch specs of the instance groups are recorded, under the cluster's config base
+	stateBase vfs.Path
+}
+
+var _ fi.Cloud = &hetznerCloudImplementation{}
+
+// NewHetznerCloud builds a Hetzner Cloud for the cluster in location, authenticated with the token from HCLOUD_TOKEN
+func NewHetznerCloud(location string, clusterName string, stateBase vfs.Path) (HetznerCloud, error) {
+	client, err := NewClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &hetznerCloudImplementation{
+		client:      client,
+		location:    location,
+		clusterName: clusterName,
+		stateBase:   stateBase,
+	}, nil
+}
+
+func (c *hetznerCloudImplementation) ProviderID() kops.CloudProviderID {
+	return kops.CloudProviderHetzner
+}
+
+func (c *hetznerCloudImplementation) Client() *Client {
+	return c.client
+}
+
+func (c *hetznerCloudImplementation) Location() string {
+	return c.location
+}
+
+func (c *hetznerCloudImplementation) ClusterName() string {
+	return c.clusterName
+}
+
+func (c *hetznerCloudImplementation) ClusterLabels() map[string]string {
+	return map[string]string{LabelCluster: c.clusterName}
+}
+
+// DNS is not supported: Hetzner Cloud clusters use gossip or an external DNS provider
+func (c *hetznerCloudImplementation) DNS() (dnsprovider.Interface, error) {
+	return nil, fmt.Errorf("DNS is not supported on Hetzner Cloud, use a gossip cluster name ending in .k8s.local")
+}
+
+func (c *hetznerCloudImplementation) FindVPCInfo(id string) (*fi.VPCInfo, error) {
+	return nil, fmt.Errorf("hetzner FindVPCInfo not supported")
+}
+
+// ServerName returns a unique name for a new server of the instance group; it is also the hostname of the server
+func ServerName(clusterName string, igName string) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	suffix := make([]byte, 5)
+	for i := range suffix {
+		suffix[i] = letters[rand.Intn(len(letters))]
+	}
+	return fmt.Sprintf("%s-%s.%s", igName, suffix, clusterName)
+}
+
+func (c *hetznerCloudImplementation) CreateServer(spec *LaunchSpec) (*Server, error) {
+	hash, err := spec.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &ServerCreateOpts{
+		Name:       ServerName(spec.ClusterName, spec.InstanceGroup),
+		ServerType: spec.ServerType,
+		Image:      spec.Image,
+		Location:   spec.Location,
+		UserData:   spec.UserData,
+		Labels: map[string]string{
+			LabelCluster:       spec.ClusterName,
+			LabelInstanceGroup: spec.InstanceGroup,
+			LabelInstanceRole:  strings.ToLower(spec.Role),
+			LabelConfigHash:    hash,
+		},
+		PlacementGroup:   spec.PlacementGroupID,
+		StartAfterCreate: true,
+	}
+	if spec.SSHKeyID != 0 {
+		opts.SSHKeys = []int{spec.SSHKeyID}
+	}
+	if spec.NetworkID != 0 {
+		opts.Networks = []int{spec.NetworkID}
+	}
+
+	glog.V(2).Infof("creating server %q", opts.Name)
+	server, err := c.client.CreateServer(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating server %q: %v", opts.Name, err)
+	}
+	return server, nil
+}
+
+// ListServers returns the servers of the cluster, optionally only those of one instance group
+func ListServers(c HetznerCloud, igName string) ([]*Server, error) {
+	labels := c.ClusterLabels()
+	if igName != "" {
+		labels[LabelInstanceGroup] = igName
+	}
+	servers, err := c.Client().ListServers(LabelSelector(labels))
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %v", err)
+	}
+	return servers, nil
+}
+
+// GetCloudGroups returns a group for each instance group, with a member per server. Servers need updating when they
+// were created from a launch spec other than the one update cluster last published for their instance group.
+func (c *hetznerCloudImplementation) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
+	servers, err := ListServers(c, "")
+	if err != nil {
+		return nil, err
+	}
+
+	serversByGroup := make(map[string][]*Server)
+	for _, server := range servers {
+		igName := server.Labels[LabelInstanceGroup]
+		serversByGroup[igName] = append(serversByGroup[igName], server)
+	}
+
+	nodeMap := serverNodeMap(servers, nodes)
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	for _, ig := range instancegroups {
+		igName := ig.ObjectMeta.Name
+		spec, err := c.ReadLaunchSpec(igName)
+		if err != nil {
+			return nil, err
+		}
+		desired := ""
+		if spec != nil {
+			if desired, err = spec.Hash(); err != nil {
+				return nil, err
+			}
+		}
+
+		group := &cloudinstances.CloudInstanceGroup{
+			HumanName:     igName,
+			InstanceGroup: ig,
+			MinSize:       int(fi.Int32Value(ig.Spec.MinSize)),
+			MaxSize:       int(fi.Int32Value(ig.Spec.MinSize)),
+		}
+
+		for _, server := range serversByGroup[igName] {
+			var reasons []string
+			if desired != "" && server.Labels[LabelConfigHash] != desired {
+				reasons = append(reasons, cloudinstances.ReasonConfigurationChanged)
+			}
+			if err := group.NewCloudInstanceGroupMemberWithReasons(strconv.Itoa(server.ID), reasons, nodeMap); err != nil {
+				return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
+			}
+		}
+		delete(serversByGroup, igName)
+
+		groups[igName] = group
+	}
+
+	if warnUnmatched {
+		for igName, servers := range serversByGroup {
+			glog.Warningf("found %d servers with no corresponding instance group %q", len(servers), igName)
+		}
+	}
+
+	return groups, nil
+}
+
+// serverNodeMap maps the id of each server to its node. Hetzner Cloud has no in-tree cloud provider, so nodes have
+// no provider id; they are matched by hostname, which is the server name.
+func serverNodeMap(servers []*Server, nodes []v1.Node) map[string]*v1.Node {
+	byName := make(map[string]*v1.Node)
+	for i := range nodes {
+		node := &nodes[i]
+		byName[node.Name] = node
+		byName[strings.SplitN(node.Name, ".", 2)[0]] = node
+	}
+
+	nodeMap := make(map[string]*v1.Node)
+	for _, server := range servers {
+		node := byName[server.Name]
+		if node == nil {
+			node = byName[strings.SplitN(server.Name, ".", 2)[0]]
+		}
+		if node != nil {
+			nodeMap[strconv.Itoa(server.ID)] = node
+		}
+	}
+	return nodeMap
+}
+
+// DeleteGroup deletes the servers of the group and its launch spec
+func (c *hetznerCloudImplementation) DeleteGroup(g *cloudinstances.CloudInstanceGroup) error {
+	igName := g.InstanceGroup.ObjectMeta.Name
+	servers, err := ListServers(c, igName)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		glog.Infof("deleting server %q", server.Name)
+		if err := c.client.DeleteServer(server.ID); err != nil && !IsNotFound(err) {
+			return fmt.Errorf("error deleting server %q: %v", server.Name, err)
+		}
+	}
+	return c.DeleteLaunchSpec(igName)
+}
+
+// DeleteInstance replaces the server: Hetzner Cloud has nothing that recreates deleted servers, so a new server is
+// created from the current launch spec of the instance group before the old one is deleted.
+func (c *hetznerCloudImplementation) DeleteInstance(i *cloudinstances.CloudInstanceGroupMember) error {
+	id, err := strconv.Atoi(i.ID)
+	if err != nil {
+		return fmt.Errorf("invalid server id %q: %v", i.ID, err)
+	}
+
+	igName := i.CloudInstanceGroup.InstanceGroup.ObjectMeta.Name
+	spec, err := c.ReadLaunchSpec(igName)
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		return fmt.Errorf("no launch spec found for instance group %q, run update cluster first", igName)
+	}
+
+	glog.Infof("deleting server %s", i.ID)
+	if err := c.client.DeleteServer(id); err != nil && !IsNotFound(err) {
+		return fmt.Errorf("error deleting server %s: %v", i.ID, err)
+	}
+
+	// Placement groups only allow a limited number of servers, so the old server must be gone before its replacement is created
+	if spec.PlacementGroupID != 0 {
+		if err := c.waitForServerDeleted(id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.CreateServer(spec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *hetznerCloudImplementation) waitForServerDeleted(id int) error {
+	for attempt := 0; attempt < 60; attempt++ {
+		if _, err := c.client.GetServer(id); err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("error getting server %d: %v", id, err)
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("timeout waiting for server %d to be deleted", id)
+}
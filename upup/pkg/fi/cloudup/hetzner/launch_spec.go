@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+// launch_spec houses the launch specs of the instance groups. Hetzner Cloud has no launch configurations or
+// autoscaling groups, so update cluster publishes what a server of each instance group is created from to the state
+// store; servers that were created from an older spec need updating, and rolling-update replaces them from the
+// current spec.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// LaunchSpec is what the servers of an instance group are created from
+type LaunchSpec struct {
+	// ClusterName is the name of the cluster
+	ClusterName string `json:"clusterName"`
+	// InstanceGroup is the name of the instance group
+	InstanceGroup string `json:"instanceGroup"`
+	// Role is the role of the instance group
+	Role string `json:"role"`
+	// ServerType is the server type, e.g. cx21
+	ServerType string `json:"serverType"`
+	// Image is the image the servers boot from
+	Image string `json:"image"`
+	// Location is the location the servers are created in
+	Location string `json:"location"`
+	// SSHKeyID is the id of the SSH key installed for root
+	SSHKeyID int `json:"sshKeyID,omitempty"`
+	// NetworkID is the id of the private network the servers are attached to
+	NetworkID int `json:"networkID,omitempty"`
+	// PlacementGroupID is the id of the spread placement group of the instance group, if it has one
+	PlacementGroupID int `json:"placementGroupID,omitempty"`
+	// UserData is the nodeup bootstrap script
+	UserData string `json:"userData"`
+}
+
+// Hash returns the hash of the spec, which servers are labelled with. Label values are limited to 63 characters,
+// so the hash is truncated.
+func (s *LaunchSpec) Hash() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("error serializing launch spec: %v", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:16]), nil
+}
+
+func (c *hetznerCloudImplementation) launchSpecPath(igName string) (vfs.Path, error) {
+	if c.stateBase == nil {
+		return nil, fmt.Errorf("the config base of the cluster is not set, so Hetzner launch specs can't be recorded")
+	}
+	return c.stateBase.Join("launchspecs", igName), nil
+}
+
+// ReadLaunchSpec returns the launch spec of the instance group, or nil if update cluster has not published it
+func (c *hetznerCloudImplementation) ReadLaunchSpec(igName string) (*LaunchSpec, error) {
+	p, err := c.launchSpecPath(igName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %q: %v", p, err)
+	}
+
+	spec := &LaunchSpec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("error parsing %q: %v", p, err)
+	}
+	return spec, nil
+}
+
+// WriteLaunchSpec publishes the launch spec of its instance group
+func (c *hetznerCloudImplementation) WriteLaunchSpec(spec *LaunchSpec) error {
+	p, err := c.launchSpecPath(spec.InstanceGroup)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("error serializing launch spec: %v", err)
+	}
+	if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("error writing %q: %v", p, err)
+	}
+	return nil
+}
+
+// DeleteLaunchSpec deletes the launch spec of the instance group
+func (c *hetznerCloudImplementation) DeleteLaunchSpec(igName string) error {
+	p, err := c.launchSpecPath(igName)
+	if err != nil {
+		return err
+	}
+	if err := p.Remove(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting %q: %v", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// GetApiIngressStatus returns the public address of the API load balancer; a cluster without one is reached at the
+// public addresses of its masters
+func (c *hetznerCloudImplementation) GetApiIngressStatus(cluster *kops.Cluster) ([]kops.ApiIngressStatus, error) {
+	var ingresses []kops.ApiIngressStatus
+
+	loadBalancers, err := c.client.ListLoadBalancers(LabelSelector(c.ClusterLabels()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancers: %v", err)
+	}
+	for _, lb := range loadBalancers {
+		if lb.PublicNet.IPv4.IP != "" {
+			ingresses = append(ingresses, kops.ApiIngressStatus{IP: lb.PublicNet.IPv4.IP})
+		}
+	}
+	if len(ingresses) != 0 {
+		return ingresses, nil
+	}
+
+	labels := c.ClusterLabels()
+	labels[LabelInstanceRole] = "master"
+	masters, err := c.client.ListServers(LabelSelector(labels))
+	if err != nil {
+		return nil, fmt.Errorf("error listing masters: %v", err)
+	}
+	for _, server := range masters {
+		if server.PublicNet.IPv4.IP != "" {
+			ingresses = append(ingresses, kops.ApiIngressStatus{IP: server.PublicNet.IPv4.IP})
+		}
+	}
+	return ingresses, nil
+}
+
+// FindClusterStatus discovers the status of the cluster, by looking for the labelled etcd volumes
+func (c *hetznerCloudImplementation) FindClusterStatus(cluster *kops.Cluster) (*kops.ClusterStatus, error) {
+	volumes, err := c.client.ListVolumes(LabelSelector(c.ClusterLabels()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %v", err)
+	}
+
+	statusMap := make(map[string]*kops.EtcdClusterStatus)
+	var names []string
+	for _, volume := range volumes {
+		etcdClusterName := volume.Labels[LabelEtcdCluster]
+		memberName := volume.Labels[LabelEtcdMember]
+		if etcdClusterName == "" || memberName == "" {
+			continue
+		}
+
+		etcdStatus := statusMap[etcdClusterName]
+		if etcdStatus == nil {
+			etcdStatus = &kops.EtcdClusterStatus{Name: etcdClusterName}
+			statusMap[etcdClusterName] = etcdStatus
+			names = append(names, etcdClusterName)
+		}
+		etcdStatus.Members = append(etcdStatus.Members, &kops.EtcdMemberStatus{
+			Name:     memberName,
+			VolumeId: strconv.Itoa(volume.ID),
+		})
+	}
+
+	status := &kops.ClusterStatus{}
+	for _, name := range names {
+		status.EtcdClusters = append(status.EtcdClusters, *statusMap[name])
+	}
+	glog.V(2).Infof("Cluster status (from cloud): %v", fi.DebugAsJsonString(status))
+	return status, nil
+}
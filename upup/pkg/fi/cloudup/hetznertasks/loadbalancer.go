@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznertasks
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+//go:generate fitask -type=LoadBalancer
+
+// LoadBalancer forwards a TCP port to the private addresses of the servers matching a label selector
+type LoadBalancer struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID             *int
+	Location       *string
+	Type           *string
+	Network        *Network
+	Port           *int
+	TargetSelector *string
+}
+
+var _ fi.CompareWithID = &LoadBalancer{}
+
+func (l *LoadBalancer) CompareWithID() *string {
+	return l.Name
+}
+
+var _ fi.HasAddress = &LoadBalancer{}
+
+func (l *LoadBalancer) FindIPAddress(c *fi.Context) (*string, error) {
+	lb, err := findLoadBalancer(c.Cloud.(hetzner.HetznerCloud), fi.StringValue(l.Name))
+	if err != nil || lb == nil {
+		return nil, err
+	}
+	if lb.PublicNet.IPv4.IP == "" {
+		return nil, nil
+	}
+	return fi.String(lb.PublicNet.IPv4.IP), nil
+}
+
+func findLoadBalancer(cloud hetzner.HetznerCloud, name string) (*hetzner.LoadBalancer, error) {
+	loadBalancers, err := cloud.Client().ListLoadBalancers(hetzner.LabelSelector(cloud.ClusterLabels()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancers: %v", err)
+	}
+	for _, lb := range loadBalancers {
+		if lb.Name == name {
+			return lb, nil
+		}
+	}
+	return nil, nil
+}
+
+func (l *LoadBalancer) Find(c *fi.Context) (*LoadBalancer, error) {
+	lb, err := findLoadBalancer(c.Cloud.(hetzner.HetznerCloud), fi.StringValue(l.Name))
+	if err != nil || lb == nil {
+		return nil, err
+	}
+
+	actual := &LoadBalancer{
+		Name:      fi.String(lb.Name),
+		Lifecycle: l.Lifecycle,
+		ID:        fi.Int(lb.ID),
+		Location:  fi.String(lb.Location.Name),
+		Type:      fi.String(lb.LoadBalancerType.Name),
+	}
+	if l.Network != nil {
+		for _, privateNet := range lb.PrivateNet {
+			if privateNet.Network == fi.IntValue(l.Network.ID) {
+				actual.Network = l.Network
+			}
+		}
+	}
+	if len(lb.Services) != 0 {
+		actual.Port = fi.Int(lb.Services[0].ListenPort)
+	}
+	for _, target := range lb.Targets {
+		if target.LabelSelector != nil {
+			actual.TargetSelector = fi.String(target.LabelSelector.Selector)
+		}
+	}
+
+	l.ID = actual.ID
+	return actual, nil
+}
+
+func (l *LoadBalancer) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(l, c)
+}
+
+func (_ *LoadBalancer) CheckChanges(a, e, changes *LoadBalancer) error {
+	if a != nil {
+		if changes.Location != nil {
+			return fi.CannotChangeField("Location")
+		}
+		if changes.Type != nil {
+			return fi.CannotChangeField("Type")
+		}
+		if changes.Network != nil {
+			return fi.CannotChangeField("Network")
+		}
+		if changes.Port != nil {
+			return fi.CannotChangeField("Port")
+		}
+		if changes.TargetSelector != nil {
+			return fi.CannotChangeField("TargetSelector")
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.Location == nil {
+			return fi.RequiredField("Location")
+		}
+		if e.Type == nil {
+			return fi.RequiredField("Type")
+		}
+		if e.Port == nil {
+			return fi.RequiredField("Port")
+		}
+		if e.TargetSelector == nil {
+			return fi.RequiredField("TargetSelector")
+		}
+	}
+	return nil
+}
+
+func (_ *LoadBalancer) RenderHetzner(t *hetzner.HetznerAPITarget, a, e, changes *LoadBalancer) error {
+	if a != nil {
+		return nil
+	}
+
+	port := fi.IntValue(e.Port)
+	opts := &hetzner.LoadBalancerCreateOpts{
+		Name:             fi.StringValue(e.Name),
+		LoadBalancerType: fi.StringValue(e.Type),
+		Location:         fi.StringValue(e.Location),
+		Labels:           t.Cloud.ClusterLabels(),
+		Services: []hetzner.LoadBalancerService{
+			{
+				Protocol:        "tcp",
+				ListenPort:      port,
+				DestinationPort: port,
+				HealthCheck: &hetzner.LoadBalancerHealthCheck{
+					Protocol: "tcp",
+					Port:     port,
+					Interval: 15,
+					Timeout:  10,
+					Retries:  3,
+				},
+			},
+		},
+	}
+	// Targets are reached over the private network when the load balancer is attached to it
+	usePrivateIP := false
+	if e.Network != nil {
+		opts.Network = fi.IntValue(e.Network.ID)
+		usePrivateIP = true
+	}
+	opts.Targets = []hetzner.LoadBalancerTarget{hetzner.LabelSelectorTarget(fi.StringValue(e.TargetSelector), usePrivateIP)}
+
+	glog.V(2).Infof("Creating load balancer %q", opts.Name)
+	lb, err := t.Cloud.Client().CreateLoadBalancer(opts)
+	if err != nil {
+		return fmt.Errorf("error creating load balancer %q: %v", opts.Name, err)
+	}
+	e.ID = fi.Int(lb.ID)
+	return nil
+}
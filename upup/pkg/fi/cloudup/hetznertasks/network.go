@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznertasks
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+// NetworkZone is the network zone of the Hetzner Cloud locations
+const NetworkZone = "eu-central"
+
+//go:generate fitask -type=Network
+
+// Network is the private network of the cluster, with a subnet for each subnet of the cluster
+type Network struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID      *int
+	IPRange *string
+	Subnets []string
+}
+
+var _ fi.CompareWithID = &Network{}
+
+func (n *Network) CompareWithID() *string {
+	return n.Name
+}
+
+func (n *Network) Find(c *fi.Context) (*Network, error) {
+	cloud := c.Cloud.(hetzner.HetznerCloud)
+
+	networks, err := cloud.Client().ListNetworks(hetzner.LabelSelector(cloud.ClusterLabels()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing networks: %v", err)
+	}
+
+	for _, network := range networks {
+		if network.Name != fi.StringValue(n.Name) {
+			continue
+		}
+		actual := &Network{
+			Name:      fi.String(network.Name),
+			Lifecycle: n.Lifecycle,
+			ID:        fi.Int(network.ID),
+			IPRange:   fi.String(network.IPRange),
+		}
+		for _, subnet := range network.Subnets {
+			actual.Subnets = append(actual.Subnets, subnet.IPRange)
+		}
+		sort.Strings(actual.Subnets)
+		sort.Strings(n.Subnets)
+
+		n.ID = actual.ID
+		return actual, nil
+	}
+	return nil, nil
+}
+
+func (n *Network) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(n, c)
+}
+
+func (_ *Network) CheckChanges(a, e, changes *Network) error {
+	if a != nil {
+		if changes.IPRange != nil {
+			return fi.CannotChangeField("IPRange")
+		}
+		for _, subnet := range a.Subnets {
+			if !containsString(e.Subnets, subnet) {
+				return fmt.Errorf("subnet %s of network %q cannot be removed", subnet, fi.StringValue(e.Name))
+			}
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.IPRange == nil {
+			return fi.RequiredField("IPRange")
+		}
+	}
+	return nil
+}
+
+func (_ *Network) RenderHetzner(t *hetzner.HetznerAPITarget, a, e, changes *Network) error {
+	client := t.Cloud.Client()
+
+	if a == nil {
+		glog.V(2).Infof("Creating network %q", fi.StringValue(e.Name))
+		opts := &hetzner.NetworkCreateOpts{
+			Name:    fi.StringValue(e.Name),
+			IPRange: fi.StringValue(e.IPRange),
+			Labels:  t.Cloud.ClusterLabels(),
+		}
+		for _, subnet := range e.Subnets {
+			opts.Subnets = append(opts.Subnets, networkSubnet(subnet))
+		}
+		network, err := client.CreateNetwork(opts)
+		if err != nil {
+			return fmt.Errorf("error creating network %q: %v", fi.StringValue(e.Name), err)
+		}
+		e.ID = fi.Int(network.ID)
+		return nil
+	}
+
+	for _, subnet := range e.Subnets {
+		if containsString(a.Subnets, subnet) {
+			continue
+		}
+		glog.V(2).Infof("Adding subnet %s to network %q", subnet, fi.StringValue(e.Name))
+		if err := client.AddNetworkSubnet(fi.IntValue(a.ID), networkSubnet(subnet)); err != nil {
+			return fmt.Errorf("error adding subnet %s to network %q: %v", subnet, fi.StringValue(e.Name), err)
+		}
+	}
+	return nil
+}
+
+func networkSubnet(ipRange string) hetzner.NetworkSubnet {
+	return hetzner.NetworkSubnet{
+		Type:        "cloud",
+		IPRange:     ipRange,
+		NetworkZone: NetworkZone,
+	}
+}
+
+func containsString(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznertasks
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+//go:generate fitask -type=PlacementGroup
+
+// PlacementGroup is a spread placement group, which puts each server of an instance group on a different host
+type PlacementGroup struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID *int
+}
+
+var _ fi.CompareWithID = &PlacementGroup{}
+
+func (p *PlacementGroup) CompareWithID() *string {
+	return p.Name
+}
+
+func (p *PlacementGroup) Find(c *fi.Context) (*PlacementGroup, error) {
+	cloud := c.Cloud.(hetzner.HetznerCloud)
+
+	placementGroups, err := cloud.Client().ListPlacementGroups(hetzner.LabelSelector(cloud.ClusterLabels()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing placement groups: %v", err)
+	}
+
+	for _, pg := range placementGroups {
+		if pg.Name != fi.StringValue(p.Name) {
+			continue
+		}
+		actual := &PlacementGroup{
+			Name:      fi.String(pg.Name),
+			Lifecycle: p.Lifecycle,
+			ID:        fi.Int(pg.ID),
+		}
+		p.ID = actual.ID
+		return actual, nil
+	}
+	return nil, nil
+}
+
+func (p *PlacementGroup) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(p, c)
+}
+
+func (_ *PlacementGroup) CheckChanges(a, e, changes *PlacementGroup) error {
+	if a == nil {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+	}
+	return nil
+}
+
+func (_ *PlacementGroup) RenderHetzner(t *hetzner.HetznerAPITarget, a, e, changes *PlacementGroup) error {
+	if a != nil {
+		return nil
+	}
+
+	glog.V(2).Infof("Creating placement group %q", fi.StringValue(e.Name))
+	pg, err := t.Cloud.Client().CreatePlacementGroup(fi.StringValue(e.Name), t.Cloud.ClusterLabels())
+	if err != nil {
+		return fmt.Errorf("error creating placement group %q: %v", fi.StringValue(e.Name), err)
+	}
+	e.ID = fi.Int(pg.ID)
+	return nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznertasks
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+//go:generate fitask -type=ServerGroup
+
+// ServerGroup is the servers of an instance group. Hetzner Cloud has no autoscaling groups, so the group is the
+// servers labelled with the instance group, created from the launch spec the task publishes to the state store.
+type ServerGroup struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	InstanceGroup  *string
+	Role           *string
+	Count          *int
+	ServerType     *string
+	Image          *string
+	Location       *string
+	SSHKey         *SSHKey
+	Network        *Network
+	PlacementGroup *PlacementGroup
+	UserData       *fi.ResourceHolder
+}
+
+var _ fi.CompareWithID = &ServerGroup{}
+
+func (g *ServerGroup) CompareWithID() *string {
+	return g.Name
+}
+
+func (g *ServerGroup) Find(c *fi.Context) (*ServerGroup, error) {
+	cloud := c.Cloud.(hetzner.HetznerCloud)
+
+	spec, err := cloud.ReadLaunchSpec(fi.StringValue(g.InstanceGroup))
+	if err != nil {
+		return nil, err
+	}
+	servers, err := hetzner.ListServers(cloud, fi.StringValue(g.InstanceGroup))
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil && len(servers) == 0 {
+		return nil, nil
+	}
+
+	actual := &ServerGroup{
+		Name:          g.Name,
+		Lifecycle:     g.Lifecycle,
+		InstanceGroup: g.InstanceGroup,
+		Count:         fi.Int(len(servers)),
+	}
+	if spec != nil {
+		actual.Role = fi.String(spec.Role)
+		actual.ServerType = fi.String(spec.ServerType)
+		actual.Image = fi.String(spec.Image)
+		actual.Location = fi.String(spec.Location)
+		actual.UserData = fi.WrapResource(fi.NewStringResource(spec.UserData))
+		if g.SSHKey != nil && spec.SSHKeyID == fi.IntValue(g.SSHKey.ID) {
+			actual.SSHKey = g.SSHKey
+		}
+		if g.Network != nil && spec.NetworkID == fi.IntValue(g.Network.ID) {
+			actual.Network = g.Network
+		}
+		if g.PlacementGroup != nil && spec.PlacementGroupID == fi.IntValue(g.PlacementGroup.ID) {
+			actual.PlacementGroup = g.PlacementGroup
+		}
+	}
+	return actual, nil
+}
+
+func (g *ServerGroup) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(g, c)
+}
+
+func (_ *ServerGroup) CheckChanges(a, e, changes *ServerGroup) error {
+	if a == nil {
+		if e.InstanceGroup == nil {
+			return fi.RequiredField("InstanceGroup")
+		}
+		if e.ServerType == nil {
+			return fi.RequiredField("ServerType")
+		}
+		if e.Image == nil {
+			return fi.RequiredField("Image")
+		}
+		if e.Location == nil {
+			return fi.RequiredField("Location")
+		}
+	}
+	if e.PlacementGroup != nil && fi.IntValue(e.Count) > hetzner.MaxSpreadPlacementGroupSize {
+		return fmt.Errorf("instance group %q has more than %d servers, which a placement group can't hold", fi.StringValue(e.InstanceGroup), hetzner.MaxSpreadPlacementGroupSize)
+	}
+	return nil
+}
+
+// launchSpec builds the launch spec of the servers of the group
+func (e *ServerGroup) launchSpec(cloud hetzner.HetznerCloud) (*hetzner.LaunchSpec, error) {
+	spec := &hetzner.LaunchSpec{
+		ClusterName:   cloud.ClusterName(),
+		InstanceGroup: fi.StringValue(e.InstanceGroup),
+		Role:          fi.StringValue(e.Role),
+		ServerType:    fi.StringValue(e.ServerType),
+		Image:         fi.StringValue(e.Image),
+		Location:      fi.StringValue(e.Location),
+	}
+	if e.SSHKey != nil {
+		spec.SSHKeyID = fi.IntValue(e.SSHKey.ID)
+	}
+	if e.Network != nil {
+		spec.NetworkID = fi.IntValue(e.Network.ID)
+	}
+	if e.PlacementGroup != nil {
+		spec.PlacementGroupID = fi.IntValue(e.PlacementGroup.ID)
+	}
+	if e.UserData != nil {
+		userData, err := e.UserData.AsString()
+		if err != nil {
+			return nil, err
+		}
+		spec.UserData = userData
+	}
+	return spec, nil
+}
+
+// RenderHetzner publishes the launch spec, and creates or deletes servers to match the count. Servers that were
+// created from an older launch spec are replaced by rolling-update.
+func (_ *ServerGroup) RenderHetzner(t *hetzner.HetznerAPITarget, a, e, changes *ServerGroup) error {
+	cloud := t.Cloud
+
+	spec, err := e.launchSpec(cloud)
+	if err != nil {
+		return err
+	}
+	if err := cloud.WriteLaunchSpec(spec); err != nil {
+		return err
+	}
+
+	servers, err := hetzner.ListServers(cloud, spec.InstanceGroup)
+	if err != nil {
+		return err
+	}
+
+	count := fi.IntValue(e.Count)
+	for i := len(servers); i < count; i++ {
+		if _, err := cloud.CreateServer(spec); err != nil {
+			return err
+		}
+	}
+
+	if len(servers) > count {
+		hash, err := spec.Hash()
+		if err != nil {
+			return err
+		}
+		// Delete the servers that would need updating first
+		sort.SliceStable(servers, func(i, j int) bool {
+			return servers[i].Labels[hetzner.LabelConfigHash] != hash && servers[j].Labels[hetzner.LabelConfigHash] == hash
+		})
+		for _, server := range servers[:len(servers)-count] {
+			glog.V(2).Infof("Deleting server %q", server.Name)
+			if err := cloud.Client().DeleteServer(server.ID); err != nil && !hetzner.IsNotFound(err) {
+				return fmt.Errorf("error deleting server %q: %v", server.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznertasks
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
+)
+
+//go:generate fitask -type=Volume
+
+// Volume is a block storage volume, holding the data of an etcd member; protokube attaches it to a master
+type Volume struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID       *int
+	SizeGB   *int
+	Location *string
+	Labels   map[string]string
+}
+
+var _ fi.CompareWithID = &Volume{}
+
+func (v *Volume) CompareWithID() *string {
+	return v.Name
+}
+
+func (v *Volume) Find(c *fi.Context) (*Volume, error) {
+	cloud := c.Cloud.(hetzner.HetznerCloud)
+
+	volumes, err := cloud.Client().ListVolumes(hetzner.LabelSelector(cloud.ClusterLabels()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %v", err)
+	}
+
+	for _, volume := range volumes {
+		if volume.Name != fi.StringValue(v.Name) {
+			continue
+		}
+		actual := &Volume{
+			Name:      fi.String(volume.Name),
+			Lifecycle: v.Lifecycle,
+			ID:        fi.Int(volume.ID),
+			SizeGB:    fi.Int(volume.Size),
+			Location:  fi.String(volume.Location.Name),
+			Labels:    volume.Labels,
+		}
+		v.ID = actual.ID
+		return actual, nil
+	}
+	return nil, nil
+}
+
+func (v *Volume) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(v, c)
+}
+
+func (_ *Volume) CheckChanges(a, e, changes *Volume) error {
+	if a != nil {
+		if changes.SizeGB != nil {
+			return fi.CannotChangeField("SizeGB")
+		}
+		if changes.Location != nil {
+			return fi.CannotChangeField("Location")
+		}
+		if changes.Labels != nil {
+			return fi.CannotChangeField("Labels")
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.SizeGB == nil {
+			return fi.RequiredField("SizeGB")
+		}
+		if e.Location == nil {
+			return fi.RequiredField("Location")
+		}
+	}
+	return nil
+}
+
+func (_ *Volume) RenderHetzner(t *hetzner.HetznerAPITarget, a, e, changes *Volume) error {
+	if a != nil {
+		return nil
+	}
+
+	glog.V(2).Infof("Creating volume %q", fi.StringValue(e.Name))
+	volume, err := t.Cloud.Client().CreateVolume(&hetzner.VolumeCreateOpts{
+		Name:     fi.StringValue(e.Name),
+		Size:     fi.IntValue(e.SizeGB),
+		Location: fi.StringValue(e.Location),
+		Labels:   e.Labels,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating volume %q: %v", fi.StringValue(e.Name), err)
+	}
+	e.ID = fi.Int(volume.ID)
+	return nil
+}
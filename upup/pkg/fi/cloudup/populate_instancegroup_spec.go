@@ -31,19 +31,27 @@ import (
 
 // Default Machine types for various types of instance group machine
 const (
-	defaultNodeMachineTypeGCE     = "n1-standard-2"
-	defaultNodeMachineTypeVSphere = "vsphere_node"
-	defaultNodeMachineTypeDO      = "s-2vcpu-4gb"
-
-	defaultBastionMachineTypeGCE     = "f1-micro"
-	defaultBastionMachineTypeVSphere = "vsphere_bastion"
-
-	defaultMasterMachineTypeGCE     = "n1-standard-1"
-	defaultMasterMachineTypeVSphere = "vsphere_master"
-	defaultMasterMachineTypeDO      = "s-2vcpu-2gb"
-
-	defaultVSphereNodeImage = "kops_ubuntu_16_04.ova"
-	defaultDONodeImage      = "coreos-stable"
+	defaultNodeMachineTypeGCE      = "n1-standard-2"
+	defaultNodeMachineTypeVSphere  = "vsphere_node"
+	defaultNodeMachineTypeDO       = "s-2vcpu-4gb"
+	defaultNodeMachineTypeHetzner  = "cx31"
+	defaultNodeMachineTypeScaleway = "DEV1-L"
+
+	defaultBastionMachineTypeGCE      = "f1-micro"
+	defaultBastionMachineTypeVSphere  = "vsphere_bastion"
+	defaultBastionMachineTypeHetzner  = "cx11"
+	defaultBastionMachineTypeScaleway = "DEV1-S"
+
+	defaultMasterMachineTypeGCE      = "n1-standard-1"
+	defaultMasterMachineTypeVSphere  = "vsphere_master"
+	defaultMasterMachineTypeDO       = "s-2vcpu-2gb"
+	defaultMasterMachineTypeHetzner  = "cx21"
+	defaultMasterMachineTypeScaleway = "DEV1-M"
+
+	defaultVSphereNodeImage  = "kops_ubuntu_16_04.ova"
+	defaultDONodeImage       = "coreos-stable"
+	defaultHetznerNodeImage  = "ubuntu-16.04"
+	defaultScalewayNodeImage = "ubuntu_bionic"
 )
 
 var awsDedicatedInstanceExceptions = map[string]bool{
@@ -67,7 +75,7 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 	reflectutils.JsonMergeStruct(ig, input)
 
 	// TODO: Clean up
-	if ig.IsMaster() {
+	if ig.IsMaster() || ig.IsEtcd() || ig.IsAPIServer() {
 		if ig.Spec.MachineType == "" {
 			ig.Spec.MachineType, err = defaultMachineType(cluster, ig)
 			if err != nil {
@@ -128,6 +136,10 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 		if len(ig.Spec.Subnets) == 0 {
 			return nil, fmt.Errorf("Master InstanceGroup %s did not specify any Subnets", ig.ObjectMeta.Name)
 		}
+	} else if ig.IsEtcd() {
+		if len(ig.Spec.Subnets) == 0 {
+			return nil, fmt.Errorf("Etcd InstanceGroup %s did not specify any Subnets", ig.ObjectMeta.Name)
+		}
 	} else if ig.Spec.Role == kops.InstanceGroupRoleBastion {
 		if len(ig.Spec.Subnets) == 0 {
 			for _, subnet := range cluster.Spec.Subnets {
@@ -170,7 +182,7 @@ func defaultMachineType(cluster *kops.Cluster, ig *kops.InstanceGroup) (string,
 
 	case kops.CloudProviderGCE:
 		switch ig.Spec.Role {
-		case kops.InstanceGroupRoleMaster:
+		case kops.InstanceGroupRoleMaster, kops.InstanceGroupRoleEtcd, kops.InstanceGroupRoleAPIServer:
 			return defaultMasterMachineTypeGCE, nil
 
 		case kops.InstanceGroupRoleNode:
@@ -190,6 +202,30 @@ func defaultMachineType(cluster *kops.Cluster, ig *kops.InstanceGroup) (string,
 
 		}
 
+	case kops.CloudProviderHetzner:
+		switch ig.Spec.Role {
+		case kops.InstanceGroupRoleMaster:
+			return defaultMasterMachineTypeHetzner, nil
+
+		case kops.InstanceGroupRoleNode:
+			return defaultNodeMachineTypeHetzner, nil
+
+		case kops.InstanceGroupRoleBastion:
+			return defaultBastionMachineTypeHetzner, nil
+		}
+
+	case kops.CloudProviderScaleway:
+		switch ig.Spec.Role {
+		case kops.InstanceGroupRoleMaster:
+			return defaultMasterMachineTypeScaleway, nil
+
+		case kops.InstanceGroupRoleNode:
+			return defaultNodeMachineTypeScaleway, nil
+
+		case kops.InstanceGroupRoleBastion:
+			return defaultBastionMachineTypeScaleway, nil
+		}
+
 	case kops.CloudProviderVSphere:
 		switch ig.Spec.Role {
 		case kops.InstanceGroupRoleMaster:
@@ -229,6 +265,10 @@ func defaultImage(cluster *kops.Cluster, channel *kops.Channel) string {
 	switch kops.CloudProviderID(cluster.Spec.CloudProvider) {
 	case kops.CloudProviderDO:
 		return defaultDONodeImage
+	case kops.CloudProviderHetzner:
+		return defaultHetznerNodeImage
+	case kops.CloudProviderScaleway:
+		return defaultScalewayNodeImage
 	case kops.CloudProviderVSphere:
 		return defaultVSphereNodeImage
 	}
@@ -0,0 +1,642 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Tag builds a key=value tag; Scaleway tags are plain strings
+func Tag(key string, value string) string {
+	return key + "=" + value
+}
+
+// TagValue returns the value of the key=value tag with the given key, or "" if there is none
+func TagValue(tags []string, key string) string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, key+"=") {
+			return strings.TrimPrefix(tag, key+"=")
+		}
+	}
+	return ""
+}
+
+// HasTags returns true if tags holds every one of want
+func HasTags(tags []string, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, tag := range tags {
+			if tag == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ServerIP is the public address of a server
+type ServerIP struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// PrivateNIC is the attachment of a server to a private network
+type PrivateNIC struct {
+	ID               string `json:"id"`
+	PrivateNetworkID string `json:"private_network_id"`
+	MACAddress       string `json:"mac_address"`
+}
+
+// ServerVolume is a volume attached to a server; the root volume is at index "0"
+type ServerVolume struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	VolumeType string `json:"volume_type"`
+}
+
+// Server is a Scaleway instance
+type Server struct {
+	ID             string                   `json:"id"`
+	Name           string                   `json:"name"`
+	CommercialType string                   `json:"commercial_type"`
+	State          string                   `json:"state"`
+	Tags           []string                 `json:"tags"`
+	PublicIP       *ServerIP                `json:"public_ip"`
+	PrivateNICs    []*PrivateNIC            `json:"private_nics"`
+	Volumes        map[string]*ServerVolume `json:"volumes"`
+	Zone           string                   `json:"zone"`
+}
+
+// ServerCreateOpts are the options for creating a server
+type ServerCreateOpts struct {
+	Name              string   `json:"name"`
+	CommercialType    string   `json:"commercial_type"`
+	Image             string   `json:"image"`
+	Project           string   `json:"project"`
+	Tags              []string `json:"tags"`
+	DynamicIPRequired bool     `json:"dynamic_ip_required"`
+}
+
+// Volume is a Scaleway block volume
+type Volume struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Size       uint64   `json:"size"`
+	VolumeType string   `json:"volume_type"`
+	Tags       []string `json:"tags"`
+	Server     *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"server"`
+	Zone string `json:"zone"`
+}
+
+// VolumeCreateOpts are the options for creating a volume; the size is in bytes
+type VolumeCreateOpts struct {
+	Name       string   `json:"name"`
+	Project    string   `json:"project"`
+	Size       uint64   `json:"size"`
+	VolumeType string   `json:"volume_type"`
+	Tags       []string `json:"tags"`
+}
+
+// VolumeTypeBlockSSD is the type of the block volumes kops creates
+const VolumeTypeBlockSSD = "b_ssd"
+
+// PrivateNetwork is a Scaleway private network
+type PrivateNetwork struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags"`
+	Subnets []string `json:"subnets"`
+	Zone    string   `json:"zone"`
+}
+
+// PrivateNetworkCreateOpts are the options for creating a private network
+type PrivateNetworkCreateOpts struct {
+	Name      string   `json:"name"`
+	ProjectID string   `json:"project_id"`
+	Tags      []string `json:"tags"`
+	Subnets   []string `json:"subnets"`
+}
+
+// PrivateIP is an address that IPAM assigned to a private NIC
+type PrivateIP struct {
+	Address    string
+	ResourceID string
+	MACAddress string
+}
+
+// LoadBalancerIP is the public address of a load balancer
+type LoadBalancerIP struct {
+	ID        string `json:"id"`
+	IPAddress string `json:"ip_address"`
+}
+
+// LoadBalancer is a Scaleway load balancer
+type LoadBalancer struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+	Type   string            `json:"type"`
+	Tags   []string          `json:"tags"`
+	IP     []*LoadBalancerIP `json:"ip"`
+	Zone   string            `json:"zone"`
+}
+
+// LoadBalancerStatusReady is the status of a load balancer that can be configured
+const LoadBalancerStatusReady = "ready"
+
+// LoadBalancerCreateOpts are the options for creating a load balancer
+type LoadBalancerCreateOpts struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	ProjectID   string   `json:"project_id"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+}
+
+// LoadBalancerHealthCheck is the health check of a backend
+type LoadBalancerHealthCheck struct {
+	Port            int       `json:"port"`
+	CheckDelay      string    `json:"check_delay"`
+	CheckTimeout    string    `json:"check_timeout"`
+	CheckMaxRetries int       `json:"check_max_retries"`
+	TCPConfig       *struct{} `json:"tcp_config,omitempty"`
+}
+
+// LoadBalancerBackend is the set of servers a load balancer forwards to
+type LoadBalancerBackend struct {
+	ID                   string                   `json:"id,omitempty"`
+	Name                 string                   `json:"name"`
+	ForwardProtocol      string                   `json:"forward_protocol"`
+	ForwardPort          int                      `json:"forward_port"`
+	ForwardPortAlgorithm string                   `json:"forward_port_algorithm"`
+	StickySessions       string                   `json:"sticky_sessions"`
+	HealthCheck          *LoadBalancerHealthCheck `json:"health_check"`
+	ServerIP             []string                 `json:"server_ip,omitempty"`
+	Pool                 []string                 `json:"pool,omitempty"`
+}
+
+// LoadBalancerFrontend is a port a load balancer listens on
+type LoadBalancerFrontend struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	InboundPort int    `json:"inbound_port"`
+	BackendID   string `json:"backend_id"`
+	Backend     *struct {
+		ID string `json:"id"`
+	} `json:"backend,omitempty"`
+}
+
+// SSHKey is an SSH key of the project, which is installed on the servers created in it
+type SSHKey struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+	ProjectID string `json:"project_id"`
+}
+
+func instancePath(zone string, format string, args ...interface{}) string {
+	return "/instance/v1/zones/" + zone + fmt.Sprintf(format, args...)
+}
+
+func vpcPath(zone string, format string, args ...interface{}) string {
+	return "/vpc/v1/zones/" + zone + fmt.Sprintf(format, args...)
+}
+
+func lbPath(zone string, format string, args ...interface{}) string {
+	return "/lb/v1/zones/" + zone + fmt.Sprintf(format, args...)
+}
+
+// ListServers returns the servers in the zone that have all the tags
+func (c *Client) ListServers(zone string, tags []string) ([]*Server, error) {
+	query := url.Values{}
+	if len(tags) != 0 {
+		query.Set("tags", strings.Join(tags, ","))
+	}
+	var servers []*Server
+	err := c.list(instancePath(zone, "/servers"), query, func(data json.RawMessage) (int, error) {
+		page := struct {
+			Servers []*Server `json:"servers"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding servers: %v", err)
+		}
+		for _, server := range page.Servers {
+			if HasTags(server.Tags, tags) {
+				servers = append(servers, server)
+			}
+		}
+		return len(page.Servers), nil
+	})
+	return servers, err
+}
+
+// GetServer returns a single server by id
+func (c *Client) GetServer(zone string, id string) (*Server, error) {
+	resp := struct {
+		Server *Server `json:"server"`
+	}{}
+	if err := c.do(http.MethodGet, instancePath(zone, "/servers/%s", id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Server, nil
+}
+
+// CreateServer creates a server; it is created stopped
+func (c *Client) CreateServer(zone string, opts *ServerCreateOpts) (*Server, error) {
+	if opts.Project == "" {
+		opts.Project = c.ProjectID
+	}
+	resp := struct {
+		Server *Server `json:"server"`
+	}{}
+	if err := c.do(http.MethodPost, instancePath(zone, "/servers"), nil, opts, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Server, nil
+}
+
+// SetServerUserData sets a user data key of a server, e.g. cloud-init
+func (c *Client) SetServerUserData(zone string, serverID string, key string, data []byte) error {
+	return c.do(http.MethodPatch, instancePath(zone, "/servers/%s/user_data/%s", serverID, key), nil, data, nil)
+}
+
+// ServerAction performs an action on a server, e.g. poweron or terminate
+func (c *Client) ServerAction(zone string, serverID string, action string) error {
+	req := struct {
+		Action string `json:"action"`
+	}{Action: action}
+	return c.do(http.MethodPost, instancePath(zone, "/servers/%s/action", serverID), nil, req, nil)
+}
+
+// CreatePrivateNIC attaches a server to a private network
+func (c *Client) CreatePrivateNIC(zone string, serverID string, privateNetworkID string) (*PrivateNIC, error) {
+	req := struct {
+		PrivateNetworkID string `json:"private_network_id"`
+	}{PrivateNetworkID: privateNetworkID}
+	resp := struct {
+		PrivateNIC *PrivateNIC `json:"private_nic"`
+	}{}
+	if err := c.do(http.MethodPost, instancePath(zone, "/servers/%s/private_nics", serverID), nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PrivateNIC, nil
+}
+
+// AttachVolume attaches a block volume to a server
+func (c *Client) AttachVolume(zone string, serverID string, volumeID string) error {
+	req := struct {
+		VolumeID string `json:"volume_id"`
+	}{VolumeID: volumeID}
+	return c.do(http.MethodPost, instancePath(zone, "/servers/%s/attach-volume", serverID), nil, req, nil)
+}
+
+// DetachVolume detaches a block volume from a server
+func (c *Client) DetachVolume(zone string, serverID string, volumeID string) error {
+	req := struct {
+		VolumeID string `json:"volume_id"`
+	}{VolumeID: volumeID}
+	return c.do(http.MethodPost, instancePath(zone, "/servers/%s/detach-volume", serverID), nil, req, nil)
+}
+
+// ListVolumes returns the volumes in the zone that have all the tags
+func (c *Client) ListVolumes(zone string, tags []string) ([]*Volume, error) {
+	query := url.Values{}
+	if len(tags) != 0 {
+		query.Set("tags", strings.Join(tags, ","))
+	}
+	var volumes []*Volume
+	err := c.list(instancePath(zone, "/volumes"), query, func(data json.RawMessage) (int, error) {
+		page := struct {
+			Volumes []*Volume `json:"volumes"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding volumes: %v", err)
+		}
+		for _, volume := range page.Volumes {
+			if HasTags(volume.Tags, tags) {
+				volumes = append(volumes, volume)
+			}
+		}
+		return len(page.Volumes), nil
+	})
+	return volumes, err
+}
+
+// GetVolume returns a single volume by id
+func (c *Client) GetVolume(zone string, id string) (*Volume, error) {
+	resp := struct {
+		Volume *Volume `json:"volume"`
+	}{}
+	if err := c.do(http.MethodGet, instancePath(zone, "/volumes/%s", id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Volume, nil
+}
+
+// CreateVolume creates a volume
+func (c *Client) CreateVolume(zone string, opts *VolumeCreateOpts) (*Volume, error) {
+	if opts.Project == "" {
+		opts.Project = c.ProjectID
+	}
+	resp := struct {
+		Volume *Volume `json:"volume"`
+	}{}
+	if err := c.do(http.MethodPost, instancePath(zone, "/volumes"), nil, opts, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Volume, nil
+}
+
+// DeleteVolume deletes a volume, which must not be attached
+func (c *Client) DeleteVolume(zone string, id string) error {
+	return c.do(http.MethodDelete, instancePath(zone, "/volumes/%s", id), nil, nil, nil)
+}
+
+// ListPrivateNetworks returns the private networks in the zone that have all the tags
+func (c *Client) ListPrivateNetworks(zone string, tags []string) ([]*PrivateNetwork, error) {
+	query := url.Values{}
+	for _, tag := range tags {
+		query.Add("tags", tag)
+	}
+	var networks []*PrivateNetwork
+	err := c.list(vpcPath(zone, "/private-networks"), query, func(data json.RawMessage) (int, error) {
+		page := struct {
+			PrivateNetworks []*PrivateNetwork `json:"private_networks"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding private networks: %v", err)
+		}
+		for _, network := range page.PrivateNetworks {
+			if HasTags(network.Tags, tags) {
+				networks = append(networks, network)
+			}
+		}
+		return len(page.PrivateNetworks), nil
+	})
+	return networks, err
+}
+
+// CreatePrivateNetwork creates a private network
+func (c *Client) CreatePrivateNetwork(zone string, opts *PrivateNetworkCreateOpts) (*PrivateNetwork, error) {
+	if opts.ProjectID == "" {
+		opts.ProjectID = c.ProjectID
+	}
+	network := &PrivateNetwork{}
+	if err := c.do(http.MethodPost, vpcPath(zone, "/private-networks"), nil, opts, network); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// DeletePrivateNetwork deletes a private network, which must have no attached servers or load balancers
+func (c *Client) DeletePrivateNetwork(zone string, id string) error {
+	return c.do(http.MethodDelete, vpcPath(zone, "/private-networks/%s", id), nil, nil, nil)
+}
+
+// ListPrivateNetworkIPs returns the addresses IPAM assigned to the private NICs attached to a private network
+func (c *Client) ListPrivateNetworkIPs(region string, privateNetworkID string) ([]*PrivateIP, error) {
+	query := url.Values{}
+	query.Set("private_network_id", privateNetworkID)
+	query.Set("resource_type", "instance_private_nic")
+	var ips []*PrivateIP
+	err := c.list("/ipam/v1/regions/"+region+"/ips", query, func(data json.RawMessage) (int, error) {
+		page := struct {
+			IPs []struct {
+				Address  string `json:"address"`
+				Resource struct {
+					ID         string `json:"id"`
+					MACAddress string `json:"mac_address"`
+				} `json:"resource"`
+			} `json:"ips"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding ips: %v", err)
+		}
+		for _, ip := range page.IPs {
+			ips = append(ips, &PrivateIP{
+				// IPAM returns addresses in CIDR notation
+				Address:    strings.SplitN(ip.Address, "/", 2)[0],
+				ResourceID: ip.Resource.ID,
+				MACAddress: ip.Resource.MACAddress,
+			})
+		}
+		return len(page.IPs), nil
+	})
+	return ips, err
+}
+
+// ListLoadBalancers returns the load balancers in the zone that have all the tags
+func (c *Client) ListLoadBalancers(zone string, tags []string) ([]*LoadBalancer, error) {
+	query := url.Values{}
+	for _, tag := range tags {
+		query.Add("tags", tag)
+	}
+	var loadBalancers []*LoadBalancer
+	err := c.list(lbPath(zone, "/lbs"), query, func(data json.RawMessage) (int, error) {
+		page := struct {
+			LBs []*LoadBalancer `json:"lbs"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding load balancers: %v", err)
+		}
+		for _, lb := range page.LBs {
+			if HasTags(lb.Tags, tags) {
+				loadBalancers = append(loadBalancers, lb)
+			}
+		}
+		return len(page.LBs), nil
+	})
+	return loadBalancers, err
+}
+
+// GetLoadBalancer returns a single load balancer by id
+func (c *Client) GetLoadBalancer(zone string, id string) (*LoadBalancer, error) {
+	lb := &LoadBalancer{}
+	if err := c.do(http.MethodGet, lbPath(zone, "/lbs/%s", id), nil, nil, lb); err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+// CreateLoadBalancer creates a load balancer; it can only be configured once it is ready
+func (c *Client) CreateLoadBalancer(zone string, opts *LoadBalancerCreateOpts) (*LoadBalancer, error) {
+	if opts.ProjectID == "" {
+		opts.ProjectID = c.ProjectID
+	}
+	lb := &LoadBalancer{}
+	if err := c.do(http.MethodPost, lbPath(zone, "/lbs"), nil, opts, lb); err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+// DeleteLoadBalancer deletes a load balancer, releasing its address
+func (c *Client) DeleteLoadBalancer(zone string, id string) error {
+	query := url.Values{}
+	query.Set("release_ip", "true")
+	return c.do(http.MethodDelete, lbPath(zone, "/lbs/%s", id), query, nil, nil)
+}
+
+// ListBackends returns the backends of a load balancer
+func (c *Client) ListBackends(zone string, lbID string) ([]*LoadBalancerBackend, error) {
+	var backends []*LoadBalancerBackend
+	err := c.list(lbPath(zone, "/lbs/%s/backends", lbID), nil, func(data json.RawMessage) (int, error) {
+		page := struct {
+			Backends []*LoadBalancerBackend `json:"backends"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding backends: %v", err)
+		}
+		backends = append(backends, page.Backends...)
+		return len(page.Backends), nil
+	})
+	return backends, err
+}
+
+// CreateBackend adds a backend to a load balancer
+func (c *Client) CreateBackend(zone string, lbID string, backend *LoadBalancerBackend) (*LoadBalancerBackend, error) {
+	created := &LoadBalancerBackend{}
+	if err := c.do(http.MethodPost, lbPath(zone, "/lbs/%s/backends", lbID), nil, backend, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// SetBackendServers replaces the addresses of the servers a backend forwards to
+func (c *Client) SetBackendServers(zone string, backendID string, serverIPs []string) error {
+	req := struct {
+		ServerIP []string `json:"server_ip"`
+	}{ServerIP: serverIPs}
+	if req.ServerIP == nil {
+		req.ServerIP = []string{}
+	}
+	return c.do(http.MethodPut, lbPath(zone, "/backends/%s/servers", backendID), nil, req, nil)
+}
+
+// ListFrontends returns the frontends of a load balancer
+func (c *Client) ListFrontends(zone string, lbID string) ([]*LoadBalancerFrontend, error) {
+	var frontends []*LoadBalancerFrontend
+	err := c.list(lbPath(zone, "/lbs/%s/frontends", lbID), nil, func(data json.RawMessage) (int, error) {
+		page := struct {
+			Frontends []*LoadBalancerFrontend `json:"frontends"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding frontends: %v", err)
+		}
+		frontends = append(frontends, page.Frontends...)
+		return len(page.Frontends), nil
+	})
+	return frontends, err
+}
+
+// CreateFrontend adds a frontend to a load balancer
+func (c *Client) CreateFrontend(zone string, lbID string, frontend *LoadBalancerFrontend) (*LoadBalancerFrontend, error) {
+	created := &LoadBalancerFrontend{}
+	if err := c.do(http.MethodPost, lbPath(zone, "/lbs/%s/frontends", lbID), nil, frontend, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListSSHKeys returns the SSH keys of the project with the given name
+func (c *Client) ListSSHKeys(name string) ([]*SSHKey, error) {
+	query := url.Values{}
+	query.Set("project_id", c.ProjectID)
+	if name != "" {
+		query.Set("name", name)
+	}
+	var keys []*SSHKey
+	err := c.list("/iam/v1alpha1/ssh-keys", query, func(data json.RawMessage) (int, error) {
+		page := struct {
+			SSHKeys []*SSHKey `json:"ssh_keys"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, fmt.Errorf("error decoding ssh keys: %v", err)
+		}
+		for _, key := range page.SSHKeys {
+			// The name filter matches substrings
+			if name == "" || key.Name == name {
+				keys = append(keys, key)
+			}
+		}
+		return len(page.SSHKeys), nil
+	})
+	return keys, err
+}
+
+// CreateSSHKey adds an SSH key to the project
+func (c *Client) CreateSSHKey(name string, publicKey string) (*SSHKey, error) {
+	req := &SSHKey{
+		Name:      name,
+		PublicKey: publicKey,
+		ProjectID: c.ProjectID,
+	}
+	key := &SSHKey{}
+	if err := c.do(http.MethodPost, "/iam/v1alpha1/ssh-keys", nil, req, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeleteSSHKey removes an SSH key from the project
+func (c *Client) DeleteSSHKey(id string) error {
+	return c.do(http.MethodDelete, "/iam/v1alpha1/ssh-keys/"+id, nil, nil, nil)
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ResolveImage returns the id of the image to create a server of the commercial type from. The image is either an
+// id, or the label of a marketplace image, e.g. ubuntu_bionic.
+func (c *Client) ResolveImage(zone string, image string, commercialType string) (string, error) {
+	if uuidRegexp.MatchString(image) {
+		return image, nil
+	}
+
+	query := url.Values{}
+	query.Set("image_label", image)
+	query.Set("zone", zone)
+	query.Set("type", "instance_local")
+	resp := struct {
+		LocalImages []struct {
+			ID                        string   `json:"id"`
+			CompatibleCommercialTypes []string `json:"compatible_commercial_types"`
+		} `json:"local_images"`
+	}{}
+	if err := c.do(http.MethodGet, "/marketplace/v2/local-images", query, nil, &resp); err != nil {
+		return "", fmt.Errorf("error finding image %q: %v", image, err)
+	}
+	for _, localImage := range resp.LocalImages {
+		for _, t := range localImage.CompatibleCommercialTypes {
+			if strings.EqualFold(t, commercialType) {
+				return localImage.ID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("image %q is not available for %s servers in %s", image, commercialType, zone)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/cloudtrace"
+	"k8s.io/kops/pkg/retrypolicy"
+)
+
+// DefaultEndpoint is the base URL of the Scaleway APIs
+const DefaultEndpoint = "https://api.scaleway.com"
+
+// SecretKeyEnvVar is the environment variable holding the secret key of the Scaleway API key
+const SecretKeyEnvVar = "SCW_SECRET_KEY"
+
+// ProjectIDEnvVar is the environment variable holding the id of the project resources are created in
+const ProjectIDEnvVar = "SCW_DEFAULT_PROJECT_ID"
+
+// listPageSize is the number of items requested per page when listing resources
+const listPageSize = 50
+
+// Client is a minimal client for the Scaleway Instance, VPC, IPAM, Load Balancer and IAM APIs
+type Client struct {
+	Endpoint   string
+	SecretKey  string
+	ProjectID  string
+	HTTPClient *http.Client
+}
+
+// NewClientFromEnv builds a client authenticated with the key from SCW_SECRET_KEY, creating resources in the
+// project from SCW_DEFAULT_PROJECT_ID
+func NewClientFromEnv() (*Client, error) {
+	secretKey := os.Getenv(SecretKeyEnvVar)
+	if secretKey == "" {
+		return nil, fmt.Errorf("%s is required", SecretKeyEnvVar)
+	}
+	projectID := os.Getenv(ProjectIDEnvVar)
+	if projectID == "" {
+		return nil, fmt.Errorf("%s is required", ProjectIDEnvVar)
+	}
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		SecretKey:  secretKey,
+		ProjectID:  projectID,
+		HTTPClient: newHTTPClient(),
+	}, nil
+}
+
+// defaultTimeout is the maximum duration of an API call, unless the retry policy sets one
+const defaultTimeout = 60 * time.Second
+
+// newHTTPClient builds the HTTP client for the APIs, which retries failed idempotent requests and bounds the duration
+// of calls as configured by the retry policy, and records calls if tracing is enabled
+func newHTTPClient() *http.Client {
+	timeout := defaultTimeout
+	if t := retrypolicy.Current().Timeout; t != 0 {
+		timeout = t
+	}
+	return &http.Client{
+		Transport: cloudtrace.WrapTransport("scaleway", retrypolicy.WrapTransport(http.DefaultTransport)),
+		Timeout:   timeout,
+	}
+}
+
+// APIError is an error returned by a Scaleway API
+type APIError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("scaleway API error (%d %s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// IsNotFound returns true if err is an API error for a resource that does not exist
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && (apiErr.StatusCode == http.StatusNotFound || apiErr.Type == "not_found")
+}
+
+// RegionForZone returns the region of a zone, e.g. fr-par for fr-par-1
+func RegionForZone(zone string) string {
+	i := strings.LastIndex(zone, "-")
+	if i == -1 {
+		return zone
+	}
+	return zone[:i]
+}
+
+// do sends a request to the API, decoding the JSON response into out if it is not nil. A []byte body is sent as
+// text/plain, anything else is encoded as JSON.
+func (c *Client) do(method string, path string, query url.Values, in interface{}, out interface{}) error {
+	u := c.Endpoint + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body []byte
+	contentType := ""
+	switch v := in.(type) {
+	case nil:
+	case []byte:
+		body = v
+		contentType = "text/plain"
+	default:
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error encoding request to %s: %v", path, err)
+		}
+		body = b
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error building request to %s: %v", path, err)
+	}
+	req.Header.Set("X-Auth-Token", c.SecretKey)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	glog.V(4).Infof("scaleway API request %s %s", method, u)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from %s %s: %v", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{}
+		if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+			apiErr = &APIError{Message: string(data)}
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+
+	if out != nil && len(data) != 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("error decoding response from %s %s: %v", method, path, err)
+		}
+	}
+	return nil
+}
+
+// list fetches every page of a list endpoint. fn is called with the raw body of each page, and returns the number
+// of items on it; a page that isn't full is the last one.
+func (c *Client) list(path string, query url.Values, fn func(data json.RawMessage) (int, error)) error {
+	page := 1
+	for {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(listPageSize))
+
+		var data json.RawMessage
+		if err := c.do(http.MethodGet, path, q, nil, &data); err != nil {
+			return err
+		}
+		n, err := fn(data)
+		if err != nil {
+			return err
+		}
+		if n < listPageSize {
+			return nil
+		}
+		page++
+	}
+}
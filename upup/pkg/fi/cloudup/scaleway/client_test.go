@@ -0,0 +1,139 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/retrypolicy"
+)
+
+func newTestClient(handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	client := &Client{
+		Endpoint:  server.URL,
+		SecretKey: "secret",
+		ProjectID: "project",
+	}
+	return client, server.Close
+}
+
+func TestListServersPaginates(t *testing.T) {
+	client, done := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "secret" {
+			t.Errorf("unexpected X-Auth-Token header %q", r.Header.Get("X-Auth-Token"))
+		}
+		if r.URL.Path != "/instance/v1/zones/fr-par-1/servers" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		switch r.URL.Query().Get("page") {
+		case "1":
+			// A full page, so the client asks for the next one
+			var servers []string
+			for i := 0; i < listPageSize; i++ {
+				servers = append(servers, fmt.Sprintf(`{"id":"%d","tags":["kops.k8s.io/cluster=test.k8s.local"]}`, i))
+			}
+			fmt.Fprintf(w, `{"servers":[%s]}`, strings.Join(servers, ","))
+		case "2":
+			fmt.Fprint(w, `{"servers":[{"id":"last","tags":["kops.k8s.io/cluster=test.k8s.local"]},{"id":"other","tags":[]}]}`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+	defer done()
+
+	servers, err := client.ListServers("fr-par-1", []string{Tag(TagCluster, "test.k8s.local")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(servers) != listPageSize+1 || servers[listPageSize].ID != "last" {
+		t.Fatalf("unexpected servers: %d", len(servers))
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	client, done := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"type":"not_found","message":"resource is not found"}`)
+	})
+	defer done()
+
+	_, err := client.GetServer("fr-par-1", "42")
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	retrypolicy.Set(retrypolicy.Policy{Timeout: 5 * time.Second, MaxAttempts: 2, Backoff: time.Millisecond})
+	defer retrypolicy.Set(retrypolicy.Policy{})
+
+	attempts := 0
+	client, done := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"type":"service_unavailable","message":"try again"}`)
+			return
+		}
+		fmt.Fprint(w, `{"server":{"id":"42","name":"a"}}`)
+	})
+	defer done()
+
+	client.HTTPClient = newHTTPClient()
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("expected the timeout of the retry policy, got %v", client.HTTPClient.Timeout)
+	}
+
+	server, err := client.GetServer("fr-par-1", "42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if server.Name != "a" || attempts != 2 {
+		t.Fatalf("unexpected server %v after %d attempts", server, attempts)
+	}
+}
+
+func TestTags(t *testing.T) {
+	tags := []string{Tag(TagCluster, "test.k8s.local"), Tag(TagEtcdMember, "a")}
+	if v := TagValue(tags, TagEtcdMember); v != "a" {
+		t.Errorf("unexpected etcd member %q", v)
+	}
+	if v := TagValue(tags, TagEtcdCluster); v != "" {
+		t.Errorf("unexpected etcd cluster %q", v)
+	}
+	if !HasTags(tags, []string{Tag(TagCluster, "test.k8s.local")}) {
+		t.Errorf("expected tags to match")
+	}
+	if HasTags(tags, []string{Tag(TagCluster, "other.k8s.local")}) {
+		t.Errorf("expected tags not to match")
+	}
+}
+
+func TestRegionForZone(t *testing.T) {
+	if r := RegionForZone("nl-ams-1"); r != "nl-ams" {
+		t.Errorf("unexpected region %q", r)
+	}
+}
@@ -0,0 +1,430 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// TagCluster is the key of the tag holding the name of the cluster a resource belongs to
+	TagCluster = "kops.k8s.io/cluster"
+	// TagInstanceGroup is the key of the tag holding the instance group of a server
+	TagInstanceGroup = "kops.k8s.io/instance-group"
+	// TagInstanceRole is the key of the tag holding the role of a server, e.g. master
+	TagInstanceRole = "kops.k8s.io/instance-role"
+	// TagConfigHash is the key of the tag holding the hash of the instance pool a server was created from
+	TagConfigHash = "kops.k8s.io/config-hash"
+	// TagEtcdCluster is the key of the tag holding the etcd cluster of an etcd volume
+	TagEtcdCluster = "kops.k8s.io/etcd-cluster"
+	// TagEtcdMember is the key of the tag holding the etcd member of an etcd volume
+	TagEtcdMember = "kops.k8s.io/etcd-member"
+)
+
+// Zones are the Scaleway zones that kops supports
+var Zones = []string{"fr-par-1", "fr-par-2", "nl-ams-1", "pl-waw-1"}
+
+// ScalewayCloud is the cloud interface for Scaleway
+type ScalewayCloud interface {
+	fi.Cloud
+
+	// Client returns the API client
+	Client() *Client
+	// Zone returns the zone of the cluster
+	Zone() string
+	// Region returns the region of the zone of the cluster
+	Region() string
+	// ClusterName returns the name of the cluster
+	ClusterName() string
+	// ClusterTags returns the tags identifying the resources of the cluster
+	ClusterTags() []string
+
+	ReadInstancePool(igName string) (*InstancePool, error)
+	WriteInstancePool(pool *InstancePool) error
+	DeleteInstancePool(igName string) error
+
+	// CreateServer creates and starts a server of the instance pool
+	CreateServer(pool *InstancePool) (*Server, error)
+	// DeleteServer deletes a server, keeping the volumes that are attached to it besides its root volume
+	DeleteServer(server *Server) error
+	// UpdateAPILoadBalancerBackends points the backends of the API load balancer at the current masters
+	UpdateAPILoadBalancerBackends() error
+
+	// GetApiIngressStatus returns the public address of the API load balancer, or of the masters if there is none
+	GetApiIngressStatus(cluster *kops.Cluster) ([]kops.ApiIngressStatus, error)
+	// FindClusterStatus discovers the status of the cluster from its etcd volumes
+	FindClusterStatus(cluster *kops.Cluster) (*kops.ClusterStatus, error)
+}
+
+type scalewayCloudImplementation struct {
+	client      *Client
+	zone        string
+	clusterName string
+
+	// stateBase is where the instance pools of the instance groups are recorded, under the cluster's config base
+	stateBase vfs.Path
+}
+
+var _ fi.Cloud = &scalewayCloudImplementation{}
+
+// NewScalewayCloud builds a Scaleway cloud for the cluster in zone, authenticated with the key from SCW_SECRET_KEY
+func NewScalewayCloud(zone string, clusterName string, stateBase vfs.Path) (ScalewayCloud, error) {
+	client, err := NewClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &scalewayCloudImplementation{
+		client:      client,
+		zone:        zone,
+		clusterName: clusterName,
+		stateBase:   stateBase,
+	}, nil
+}
+
+func (c *scalewayCloudImplementation) ProviderID() kops.CloudProviderID {
+	return kops.CloudProviderScaleway
+}
+
+func (c *scalewayCloudImplementation) Client() *Client {
+	return c.client
+}
+
+func (c *scalewayCloudImplementation) Zone() string {
+	return c.zone
+}
+
+func (c *scalewayCloudImplementation) Region() string {
+	return RegionForZone(c.zone)
+}
+
+func (c *scalewayCloudImplementation) ClusterName() string {
+	return c.clusterName
+}
+
+func (c *scalewayCloudImplementation) ClusterTags() []string {
+	return []string{Tag(TagCluster, c.clusterName)}
+}
+
+// DNS is not supported: Scaleway clusters use gossip
+func (c *scalewayCloudImplementation) DNS() (dnsprovider.Interface, error) {
+	return nil, fmt.Errorf("DNS is not supported on Scaleway, use a gossip cluster name ending in .k8s.local")
+}
+
+func (c *scalewayCloudImplementation) FindVPCInfo(id string) (*fi.VPCInfo, error) {
+	return nil, fmt.Errorf("scaleway FindVPCInfo not supported")
+}
+
+// ServerName returns a unique name for a new server of the instance group; it is also the hostname of the server
+func ServerName(igName string) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	suffix := make([]byte, 5)
+	for i := range suffix {
+		suffix[i] = letters[rand.Intn(len(letters))]
+	}
+	return fmt.Sprintf("%s-%s", igName, suffix)
+}
+
+func (c *scalewayCloudImplementation) CreateServer(pool *InstancePool) (*Server, error) {
+	hash, err := pool.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := c.client.ResolveImage(pool.Zone, pool.Image, pool.CommercialType)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &ServerCreateOpts{
+		Name:           ServerName(pool.InstanceGroup),
+		CommercialType: pool.CommercialType,
+		Image:          image,
+		Tags: []string{
+			Tag(TagCluster, pool.ClusterName),
+			Tag(TagInstanceGroup, pool.InstanceGroup),
+			Tag(TagInstanceRole, strings.ToLower(pool.Role)),
+			Tag(TagConfigHash, hash),
+		},
+		DynamicIPRequired: true,
+	}
+
+	glog.V(2).Infof("creating server %q", opts.Name)
+	server, err := c.client.CreateServer(pool.Zone, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating server %q: %v", opts.Name, err)
+	}
+
+	// Servers are created stopped, so they can be configured before they boot
+	if err := c.client.SetServerUserData(pool.Zone, server.ID, "cloud-init", []byte(pool.UserData)); err != nil {
+		return nil, fmt.Errorf("error setting user data of server %q: %v", server.Name, err)
+	}
+	if pool.PrivateNetworkID != "" {
+		if _, err := c.client.CreatePrivateNIC(pool.Zone, server.ID, pool.PrivateNetworkID); err != nil {
+			return nil, fmt.Errorf("error attaching server %q to private network: %v", server.Name, err)
+		}
+	}
+	if err := c.client.ServerAction(pool.Zone, server.ID, "poweron"); err != nil {
+		return nil, fmt.Errorf("error starting server %q: %v", server.Name, err)
+	}
+	return server, nil
+}
+
+// DeleteServer terminates the server. Terminating a server deletes every volume attached to it, so the etcd volumes
+// of masters are detached first.
+func (c *scalewayCloudImplementation) DeleteServer(server *Server) error {
+	zone := server.Zone
+	if zone == "" {
+		zone = c.zone
+	}
+
+	detached := false
+	for index, volume := range server.Volumes {
+		if index == "0" {
+			continue
+		}
+		glog.V(2).Infof("detaching volume %q from server %q", volume.Name, server.Name)
+		if err := c.client.DetachVolume(zone, server.ID, volume.ID); err != nil && !IsNotFound(err) {
+			return fmt.Errorf("error detaching volume %q from server %q: %v", volume.Name, server.Name, err)
+		}
+		detached = true
+	}
+	if detached {
+		if err := c.waitForVolumesDetached(zone, server.ID); err != nil {
+			return err
+		}
+	}
+
+	glog.V(2).Infof("terminating server %q", server.Name)
+	if err := c.client.ServerAction(zone, server.ID, "terminate"); err != nil && !IsNotFound(err) {
+		return fmt.Errorf("error terminating server %q: %v", server.Name, err)
+	}
+	return nil
+}
+
+func (c *scalewayCloudImplementation) waitForVolumesDetached(zone string, serverID string) error {
+	for attempt := 0; attempt < 60; attempt++ {
+		server, err := c.client.GetServer(zone, serverID)
+		if err != nil {
+			if IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("error getting server %s: %v", serverID, err)
+		}
+		if len(server.Volumes) <= 1 {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("timeout waiting for the volumes of server %s to be detached", serverID)
+}
+
+// UpdateAPILoadBalancerBackends points every backend of the load balancers of the cluster at the public addresses of
+// the masters. Scaleway has nothing that follows the servers of a group, so this is done whenever masters change.
+func (c *scalewayCloudImplementation) UpdateAPILoadBalancerBackends() error {
+	loadBalancers, err := c.client.ListLoadBalancers(c.zone, c.ClusterTags())
+	if err != nil {
+		return fmt.Errorf("error listing load balancers: %v", err)
+	}
+	if len(loadBalancers) == 0 {
+		return nil
+	}
+
+	masters, err := c.client.ListServers(c.zone, append(c.ClusterTags(), Tag(TagInstanceRole, "master")))
+	if err != nil {
+		return fmt.Errorf("error listing masters: %v", err)
+	}
+	var ips []string
+	for _, server := range masters {
+		if server.PublicIP != nil && server.PublicIP.Address != "" {
+			ips = append(ips, server.PublicIP.Address)
+		}
+	}
+	sort.Strings(ips)
+
+	for _, lb := range loadBalancers {
+		backends, err := c.client.ListBackends(c.zone, lb.ID)
+		if err != nil {
+			return fmt.Errorf("error listing backends of load balancer %q: %v", lb.Name, err)
+		}
+		for _, backend := range backends {
+			pool := append([]string(nil), backend.Pool...)
+			sort.Strings(pool)
+			if strings.Join(pool, ",") == strings.Join(ips, ",") {
+				continue
+			}
+			glog.V(2).Infof("setting servers of backend %q of load balancer %q to %v", backend.Name, lb.Name, ips)
+			if err := c.client.SetBackendServers(c.zone, backend.ID, ips); err != nil {
+				return fmt.Errorf("error updating backend %q of load balancer %q: %v", backend.Name, lb.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ListServers returns the servers of the cluster, optionally only those of one instance group
+func ListServers(c ScalewayCloud, igName string) ([]*Server, error) {
+	tags := c.ClusterTags()
+	if igName != "" {
+		tags = append(tags, Tag(TagInstanceGroup, igName))
+	}
+	servers, err := c.Client().ListServers(c.Zone(), tags)
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %v", err)
+	}
+	return servers, nil
+}
+
+// GetCloudGroups returns a group for each instance group, with a member per server. Servers need updating when they
+// were created from an instance pool other than the one update cluster last published for their instance group.
+func (c *scalewayCloudImplementation) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
+	servers, err := ListServers(c, "")
+	if err != nil {
+		return nil, err
+	}
+
+	serversByGroup := make(map[string][]*Server)
+	for _, server := range servers {
+		igName := TagValue(server.Tags, TagInstanceGroup)
+		serversByGroup[igName] = append(serversByGroup[igName], server)
+	}
+
+	nodeMap := serverNodeMap(servers, nodes)
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	for _, ig := range instancegroups {
+		igName := ig.ObjectMeta.Name
+		pool, err := c.ReadInstancePool(igName)
+		if err != nil {
+			return nil, err
+		}
+		desired := ""
+		if pool != nil {
+			if desired, err = pool.Hash(); err != nil {
+				return nil, err
+			}
+		}
+
+		group := &cloudinstances.CloudInstanceGroup{
+			HumanName:     igName,
+			InstanceGroup: ig,
+			MinSize:       int(fi.Int32Value(ig.Spec.MinSize)),
+			MaxSize:       int(fi.Int32Value(ig.Spec.MinSize)),
+		}
+
+		for _, server := range serversByGroup[igName] {
+			var reasons []string
+			if desired != "" && TagValue(server.Tags, TagConfigHash) != desired {
+				reasons = append(reasons, cloudinstances.ReasonConfigurationChanged)
+			}
+			if err := group.NewCloudInstanceGroupMemberWithReasons(server.ID, reasons, nodeMap); err != nil {
+				return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
+			}
+		}
+		delete(serversByGroup, igName)
+
+		groups[igName] = group
+	}
+
+	if warnUnmatched {
+		for igName, servers := range serversByGroup {
+			glog.Warningf("found %d servers with no corresponding instance group %q", len(servers), igName)
+		}
+	}
+
+	return groups, nil
+}
+
+// serverNodeMap maps the id of each server to its node. Scaleway has no in-tree cloud provider, so nodes have no
+// provider id; they are matched by hostname, which is the server name.
+func serverNodeMap(servers []*Server, nodes []v1.Node) map[string]*v1.Node {
+	byName := make(map[string]*v1.Node)
+	for i := range nodes {
+		node := &nodes[i]
+		byName[node.Name] = node
+		byName[strings.SplitN(node.Name, ".", 2)[0]] = node
+	}
+
+	nodeMap := make(map[string]*v1.Node)
+	for _, server := range servers {
+		if node := byName[server.Name]; node != nil {
+			nodeMap[server.ID] = node
+		}
+	}
+	return nodeMap
+}
+
+// DeleteGroup deletes the servers of the group and its instance pool
+func (c *scalewayCloudImplementation) DeleteGroup(g *cloudinstances.CloudInstanceGroup) error {
+	igName := g.InstanceGroup.ObjectMeta.Name
+	servers, err := ListServers(c, igName)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		glog.Infof("deleting server %q", server.Name)
+		if err := c.DeleteServer(server); err != nil {
+			return err
+		}
+	}
+	return c.DeleteInstancePool(igName)
+}
+
+// DeleteInstance replaces the server: Scaleway has nothing that recreates deleted servers, so a new server is
+// created from the current instance pool of the instance group once the old one is deleted.
+func (c *scalewayCloudImplementation) DeleteInstance(i *cloudinstances.CloudInstanceGroupMember) error {
+	igName := i.CloudInstanceGroup.InstanceGroup.ObjectMeta.Name
+	pool, err := c.ReadInstancePool(igName)
+	if err != nil {
+		return err
+	}
+	if pool == nil {
+		return fmt.Errorf("no instance pool found for instance group %q, run update cluster first", igName)
+	}
+
+	server, err := c.client.GetServer(pool.Zone, i.ID)
+	if err != nil && !IsNotFound(err) {
+		return fmt.Errorf("error getting server %s: %v", i.ID, err)
+	}
+	if server != nil {
+		glog.Infof("deleting server %s", i.ID)
+		if err := c.DeleteServer(server); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.CreateServer(pool); err != nil {
+		return err
+	}
+
+	if i.CloudInstanceGroup.InstanceGroup.IsMaster() {
+		return c.UpdateAPILoadBalancerBackends()
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+// instance_pool houses the instance pools of the instance groups. Scaleway has no autoscaling groups, so update
+// cluster publishes what a server of each instance group is created from to the state store, and creates or deletes
+// tagged servers until the group has the desired size. Servers that were created from an older spec need updating,
+// and rolling-update replaces them from the current spec.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// InstancePool is what the servers of an instance group are created from
+type InstancePool struct {
+	// ClusterName is the name of the cluster
+	ClusterName string `json:"clusterName"`
+	// InstanceGroup is the name of the instance group
+	InstanceGroup string `json:"instanceGroup"`
+	// Role is the role of the instance group
+	Role string `json:"role"`
+	// CommercialType is the server type, e.g. DEV1-M
+	CommercialType string `json:"commercialType"`
+	// Image is the image id or marketplace label the servers boot from
+	Image string `json:"image"`
+	// Zone is the zone the servers are created in
+	Zone string `json:"zone"`
+	// PrivateNetworkID is the id of the private network the servers are attached to
+	PrivateNetworkID string `json:"privateNetworkID,omitempty"`
+	// UserData is the nodeup bootstrap script, passed to cloud-init
+	UserData string `json:"userData"`
+}
+
+// Hash returns the hash of the pool, which servers are tagged with
+func (p *InstancePool) Hash() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("error serializing instance pool: %v", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:16]), nil
+}
+
+func (c *scalewayCloudImplementation) instancePoolPath(igName string) (vfs.Path, error) {
+	if c.stateBase == nil {
+		return nil, fmt.Errorf("the config base of the cluster is not set, so Scaleway instance pools can't be recorded")
+	}
+	return c.stateBase.Join("instancepools", igName), nil
+}
+
+// ReadInstancePool returns the instance pool of the instance group, or nil if update cluster has not published it
+func (c *scalewayCloudImplementation) ReadInstancePool(igName string) (*InstancePool, error) {
+	p, err := c.instancePoolPath(igName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %q: %v", p, err)
+	}
+	pool := &InstancePool{}
+	if err := json.Unmarshal(data, pool); err != nil {
+		return nil, fmt.Errorf("error parsing %q: %v", p, err)
+	}
+	return pool, nil
+}
+
+// WriteInstancePool publishes the instance pool of its instance group
+func (c *scalewayCloudImplementation) WriteInstancePool(pool *InstancePool) error {
+	p, err := c.instancePoolPath(pool.InstanceGroup)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(pool)
+	if err != nil {
+		return fmt.Errorf("error serializing instance pool: %v", err)
+	}
+	if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("error writing %q: %v", p, err)
+	}
+	return nil
+}
+
+// DeleteInstancePool deletes the instance pool of the instance group
+func (c *scalewayCloudImplementation) DeleteInstancePool(igName string) error {
+	p, err := c.instancePoolPath(igName)
+	if err != nil {
+		return err
+	}
+	if err := p.Remove(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting %q: %v", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleway
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// GetApiIngressStatus returns the public address of the API load balancer; a cluster without one is reached at the
+// public addresses of its masters
+func (c *scalewayCloudImplementation) GetApiIngressStatus(cluster *kops.Cluster) ([]kops.ApiIngressStatus, error) {
+	var ingresses []kops.ApiIngressStatus
+
+	loadBalancers, err := c.client.ListLoadBalancers(c.zone, c.ClusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancers: %v", err)
+	}
+	for _, lb := range loadBalancers {
+		for _, ip := range lb.IP {
+			if ip.IPAddress != "" {
+				ingresses = append(ingresses, kops.ApiIngressStatus{IP: ip.IPAddress})
+			}
+		}
+	}
+	if len(ingresses) != 0 {
+		return ingresses, nil
+	}
+
+	masters, err := c.client.ListServers(c.zone, append(c.ClusterTags(), Tag(TagInstanceRole, "master")))
+	if err != nil {
+		return nil, fmt.Errorf("error listing masters: %v", err)
+	}
+	for _, server := range masters {
+		if server.PublicIP != nil && server.PublicIP.Address != "" {
+			ingresses = append(ingresses, kops.ApiIngressStatus{IP: server.PublicIP.Address})
+		}
+	}
+	return ingresses, nil
+}
+
+// FindClusterStatus discovers the status of the cluster, by looking for the tagged etcd volumes
+func (c *scalewayCloudImplementation) FindClusterStatus(cluster *kops.Cluster) (*kops.ClusterStatus, error) {
+	volumes, err := c.client.ListVolumes(c.zone, c.ClusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %v", err)
+	}
+
+	statusMap := make(map[string]*kops.EtcdClusterStatus)
+	var names []string
+	for _, volume := range volumes {
+		etcdClusterName := TagValue(volume.Tags, TagEtcdCluster)
+		memberName := TagValue(volume.Tags, TagEtcdMember)
+		if etcdClusterName == "" || memberName == "" {
+			continue
+		}
+
+		etcdStatus := statusMap[etcdClusterName]
+		if etcdStatus == nil {
+			etcdStatus = &kops.EtcdClusterStatus{Name: etcdClusterName}
+			statusMap[etcdClusterName] = etcdStatus
+			names = append(names, etcdClusterName)
+		}
+		etcdStatus.Members = append(etcdStatus.Members, &kops.EtcdMemberStatus{
+			Name:     memberName,
+			VolumeId: volume.ID,
+		})
+	}
+
+	status := &kops.ClusterStatus{}
+	for _, name := range names {
+		status.EtcdClusters = append(status.EtcdClusters, *statusMap[name])
+	}
+	glog.V(2).Infof("Cluster status (from cloud): %v", fi.DebugAsJsonString(status))
+	return status, nil
+}
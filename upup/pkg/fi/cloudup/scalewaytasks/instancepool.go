@@ -0,0 +1,183 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaytasks
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+//go:generate fitask -type=InstancePool
+
+// InstancePool is the servers of an instance group. Scaleway has no autoscaling groups, so the pool is the servers
+// tagged with the instance group, created from the instance pool the task publishes to the state store.
+type InstancePool struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	InstanceGroup  *string
+	Role           *string
+	Count          *int
+	CommercialType *string
+	Image          *string
+	Zone           *string
+	PrivateNetwork *PrivateNetwork
+	UserData       *fi.ResourceHolder
+
+	// SSHKey is installed on the servers when they are created, so it must exist first
+	SSHKey *SSHKey
+}
+
+var _ fi.CompareWithID = &InstancePool{}
+
+func (p *InstancePool) CompareWithID() *string {
+	return p.Name
+}
+
+func (p *InstancePool) Find(c *fi.Context) (*InstancePool, error) {
+	cloud := c.Cloud.(scaleway.ScalewayCloud)
+
+	pool, err := cloud.ReadInstancePool(fi.StringValue(p.InstanceGroup))
+	if err != nil {
+		return nil, err
+	}
+	servers, err := scaleway.ListServers(cloud, fi.StringValue(p.InstanceGroup))
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil && len(servers) == 0 {
+		return nil, nil
+	}
+
+	actual := &InstancePool{
+		Name:          p.Name,
+		Lifecycle:     p.Lifecycle,
+		InstanceGroup: p.InstanceGroup,
+		Count:         fi.Int(len(servers)),
+		SSHKey:        p.SSHKey,
+	}
+	if pool != nil {
+		actual.Role = fi.String(pool.Role)
+		actual.CommercialType = fi.String(pool.CommercialType)
+		actual.Image = fi.String(pool.Image)
+		actual.Zone = fi.String(pool.Zone)
+		actual.UserData = fi.WrapResource(fi.NewStringResource(pool.UserData))
+		if p.PrivateNetwork != nil && pool.PrivateNetworkID == fi.StringValue(p.PrivateNetwork.ID) {
+			actual.PrivateNetwork = p.PrivateNetwork
+		}
+	}
+	return actual, nil
+}
+
+func (p *InstancePool) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(p, c)
+}
+
+func (_ *InstancePool) CheckChanges(a, e, changes *InstancePool) error {
+	if a == nil {
+		if e.InstanceGroup == nil {
+			return fi.RequiredField("InstanceGroup")
+		}
+		if e.CommercialType == nil {
+			return fi.RequiredField("CommercialType")
+		}
+		if e.Image == nil {
+			return fi.RequiredField("Image")
+		}
+		if e.Zone == nil {
+			return fi.RequiredField("Zone")
+		}
+	}
+	return nil
+}
+
+// instancePool builds the instance pool the servers of the group are created from
+func (e *InstancePool) instancePool(cloud scaleway.ScalewayCloud) (*scaleway.InstancePool, error) {
+	pool := &scaleway.InstancePool{
+		ClusterName:    cloud.ClusterName(),
+		InstanceGroup:  fi.StringValue(e.InstanceGroup),
+		Role:           fi.StringValue(e.Role),
+		CommercialType: fi.StringValue(e.CommercialType),
+		Image:          fi.StringValue(e.Image),
+		Zone:           fi.StringValue(e.Zone),
+	}
+	if e.PrivateNetwork != nil {
+		pool.PrivateNetworkID = fi.StringValue(e.PrivateNetwork.ID)
+	}
+	if e.UserData != nil {
+		userData, err := e.UserData.AsString()
+		if err != nil {
+			return nil, err
+		}
+		pool.UserData = userData
+	}
+	return pool, nil
+}
+
+// RenderScaleway publishes the instance pool, and creates or deletes servers to match the count. Servers that were
+// created from an older instance pool are replaced by rolling-update.
+func (_ *InstancePool) RenderScaleway(t *scaleway.ScalewayAPITarget, a, e, changes *InstancePool) error {
+	cloud := t.Cloud
+
+	pool, err := e.instancePool(cloud)
+	if err != nil {
+		return err
+	}
+	if err := cloud.WriteInstancePool(pool); err != nil {
+		return err
+	}
+
+	servers, err := scaleway.ListServers(cloud, pool.InstanceGroup)
+	if err != nil {
+		return err
+	}
+
+	count := fi.IntValue(e.Count)
+	for i := len(servers); i < count; i++ {
+		if _, err := cloud.CreateServer(pool); err != nil {
+			return err
+		}
+	}
+
+	if len(servers) > count {
+		hash, err := pool.Hash()
+		if err != nil {
+			return err
+		}
+		// Delete the servers that would need updating first
+		sort.SliceStable(servers, func(i, j int) bool {
+			return scaleway.TagValue(servers[i].Tags, scaleway.TagConfigHash) != hash && scaleway.TagValue(servers[j].Tags, scaleway.TagConfigHash) == hash
+		})
+		for _, server := range servers[:len(servers)-count] {
+			glog.V(2).Infof("Deleting server %q", server.Name)
+			if err := cloud.DeleteServer(server); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(servers) != count && strings.EqualFold(pool.Role, string(kops.InstanceGroupRoleMaster)) {
+		return cloud.UpdateAPILoadBalancerBackends()
+	}
+	return nil
+}
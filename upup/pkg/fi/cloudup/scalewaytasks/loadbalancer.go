@@ -0,0 +1,214 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaytasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+//go:generate fitask -type=LoadBalancer
+
+// LoadBalancer forwards a TCP port to the masters. Its backend holds the public addresses of the masters, which the
+// cloud updates whenever masters are created or replaced.
+type LoadBalancer struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID   *string
+	Zone *string
+	Type *string
+	Port *int
+}
+
+var _ fi.CompareWithID = &LoadBalancer{}
+
+func (l *LoadBalancer) CompareWithID() *string {
+	return l.Name
+}
+
+var _ fi.HasAddress = &LoadBalancer{}
+
+func (l *LoadBalancer) FindIPAddress(c *fi.Context) (*string, error) {
+	lb, err := findLoadBalancer(c.Cloud.(scaleway.ScalewayCloud), fi.StringValue(l.Zone), fi.StringValue(l.Name))
+	if err != nil || lb == nil {
+		return nil, err
+	}
+	for _, ip := range lb.IP {
+		if ip.IPAddress != "" {
+			return fi.String(ip.IPAddress), nil
+		}
+	}
+	return nil, nil
+}
+
+func findLoadBalancer(cloud scaleway.ScalewayCloud, zone string, name string) (*scaleway.LoadBalancer, error) {
+	loadBalancers, err := cloud.Client().ListLoadBalancers(zone, cloud.ClusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancers: %v", err)
+	}
+	for _, lb := range loadBalancers {
+		if lb.Name == name {
+			return lb, nil
+		}
+	}
+	return nil, nil
+}
+
+func (l *LoadBalancer) Find(c *fi.Context) (*LoadBalancer, error) {
+	cloud := c.Cloud.(scaleway.ScalewayCloud)
+	zone := fi.StringValue(l.Zone)
+
+	lb, err := findLoadBalancer(cloud, zone, fi.StringValue(l.Name))
+	if err != nil || lb == nil {
+		return nil, err
+	}
+
+	actual := &LoadBalancer{
+		Name:      fi.String(lb.Name),
+		Lifecycle: l.Lifecycle,
+		ID:        fi.String(lb.ID),
+		Zone:      l.Zone,
+		Type:      fi.String(lb.Type),
+	}
+
+	frontends, err := cloud.Client().ListFrontends(zone, lb.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing frontends of load balancer %q: %v", lb.Name, err)
+	}
+	if len(frontends) != 0 {
+		actual.Port = fi.Int(frontends[0].InboundPort)
+	}
+
+	l.ID = actual.ID
+	return actual, nil
+}
+
+func (l *LoadBalancer) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(l, c)
+}
+
+func (_ *LoadBalancer) CheckChanges(a, e, changes *LoadBalancer) error {
+	if a != nil {
+		if changes.Zone != nil {
+			return fi.CannotChangeField("Zone")
+		}
+		if changes.Type != nil {
+			return fi.CannotChangeField("Type")
+		}
+		// A load balancer without a frontend was not fully configured, and is completed
+		if changes.Port != nil && a.Port != nil {
+			return fi.CannotChangeField("Port")
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.Zone == nil {
+			return fi.RequiredField("Zone")
+		}
+		if e.Type == nil {
+			return fi.RequiredField("Type")
+		}
+		if e.Port == nil {
+			return fi.RequiredField("Port")
+		}
+	}
+	return nil
+}
+
+func (_ *LoadBalancer) RenderScaleway(t *scaleway.ScalewayAPITarget, a, e, changes *LoadBalancer) error {
+	client := t.Cloud.Client()
+	zone := fi.StringValue(e.Zone)
+	name := fi.StringValue(e.Name)
+
+	var id string
+	if a == nil {
+		glog.V(2).Infof("Creating load balancer %q", name)
+		lb, err := client.CreateLoadBalancer(zone, &scaleway.LoadBalancerCreateOpts{
+			Name:        name,
+			Description: "Kubernetes API of " + t.Cloud.ClusterName(),
+			Type:        fi.StringValue(e.Type),
+			Tags:        t.Cloud.ClusterTags(),
+		})
+		if err != nil {
+			return fmt.Errorf("error creating load balancer %q: %v", name, err)
+		}
+		id = lb.ID
+		e.ID = fi.String(id)
+	} else {
+		id = fi.StringValue(a.ID)
+	}
+
+	if a != nil && a.Port != nil {
+		return nil
+	}
+
+	if err := waitForLoadBalancerReady(client, zone, id); err != nil {
+		return err
+	}
+
+	port := fi.IntValue(e.Port)
+	backend, err := client.CreateBackend(zone, id, &scaleway.LoadBalancerBackend{
+		Name:                 name,
+		ForwardProtocol:      "tcp",
+		ForwardPort:          port,
+		ForwardPortAlgorithm: "roundrobin",
+		StickySessions:       "none",
+		HealthCheck: &scaleway.LoadBalancerHealthCheck{
+			Port:            port,
+			CheckDelay:      "15s",
+			CheckTimeout:    "10s",
+			CheckMaxRetries: 3,
+			TCPConfig:       &struct{}{},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating backend of load balancer %q: %v", name, err)
+	}
+
+	if _, err := client.CreateFrontend(zone, id, &scaleway.LoadBalancerFrontend{
+		Name:        name,
+		InboundPort: port,
+		BackendID:   backend.ID,
+	}); err != nil {
+		return fmt.Errorf("error creating frontend of load balancer %q: %v", name, err)
+	}
+
+	return t.Cloud.UpdateAPILoadBalancerBackends()
+}
+
+// waitForLoadBalancerReady waits for a new load balancer to be provisioned; until then it can't be configured
+func waitForLoadBalancerReady(client *scaleway.Client, zone string, id string) error {
+	for attempt := 0; attempt < 60; attempt++ {
+		lb, err := client.GetLoadBalancer(zone, id)
+		if err != nil {
+			return fmt.Errorf("error getting load balancer %s: %v", id, err)
+		}
+		if lb.Status == scaleway.LoadBalancerStatusReady {
+			return nil
+		}
+		glog.V(2).Infof("waiting for load balancer %q to be ready, status is %q", lb.Name, lb.Status)
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("timeout waiting for load balancer %s to be ready", id)
+}
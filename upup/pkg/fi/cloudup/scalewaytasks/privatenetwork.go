@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaytasks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+//go:generate fitask -type=PrivateNetwork
+
+// PrivateNetwork is the private network of the cluster, with a subnet for each subnet of the cluster. IPAM assigns
+// the servers attached to it an address in one of its subnets.
+type PrivateNetwork struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID      *string
+	Zone    *string
+	Subnets []string
+}
+
+var _ fi.CompareWithID = &PrivateNetwork{}
+
+func (n *PrivateNetwork) CompareWithID() *string {
+	return n.Name
+}
+
+func (n *PrivateNetwork) Find(c *fi.Context) (*PrivateNetwork, error) {
+	cloud := c.Cloud.(scaleway.ScalewayCloud)
+
+	networks, err := cloud.Client().ListPrivateNetworks(fi.StringValue(n.Zone), cloud.ClusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("error listing private networks: %v", err)
+	}
+
+	for _, network := range networks {
+		if network.Name != fi.StringValue(n.Name) {
+			continue
+		}
+		actual := &PrivateNetwork{
+			Name:      fi.String(network.Name),
+			Lifecycle: n.Lifecycle,
+			ID:        fi.String(network.ID),
+			Zone:      n.Zone,
+			Subnets:   append([]string(nil), network.Subnets...),
+		}
+		sort.Strings(actual.Subnets)
+		sort.Strings(n.Subnets)
+
+		n.ID = actual.ID
+		return actual, nil
+	}
+	return nil, nil
+}
+
+func (n *PrivateNetwork) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(n, c)
+}
+
+func (_ *PrivateNetwork) CheckChanges(a, e, changes *PrivateNetwork) error {
+	if a != nil {
+		if changes.Zone != nil {
+			return fi.CannotChangeField("Zone")
+		}
+		if strings.Join(a.Subnets, ",") != strings.Join(e.Subnets, ",") {
+			return fmt.Errorf("the subnets of private network %q cannot be changed", fi.StringValue(e.Name))
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.Zone == nil {
+			return fi.RequiredField("Zone")
+		}
+	}
+	return nil
+}
+
+func (_ *PrivateNetwork) RenderScaleway(t *scaleway.ScalewayAPITarget, a, e, changes *PrivateNetwork) error {
+	if a != nil {
+		return nil
+	}
+
+	glog.V(2).Infof("Creating private network %q", fi.StringValue(e.Name))
+	network, err := t.Cloud.Client().CreatePrivateNetwork(fi.StringValue(e.Zone), &scaleway.PrivateNetworkCreateOpts{
+		Name:    fi.StringValue(e.Name),
+		Tags:    t.Cloud.ClusterTags(),
+		Subnets: e.Subnets,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating private network %q: %v", fi.StringValue(e.Name), err)
+	}
+	e.ID = fi.String(network.ID)
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaytasks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+//go:generate fitask -type=SSHKey
+
+// SSHKey is the SSH public key of the cluster. Scaleway installs the SSH keys of the project on the servers created
+// in it, so the key is added to the project.
+type SSHKey struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID        *string
+	PublicKey *string
+}
+
+var _ fi.CompareWithID = &SSHKey{}
+
+func (k *SSHKey) CompareWithID() *string {
+	return k.Name
+}
+
+func (k *SSHKey) Find(c *fi.Context) (*SSHKey, error) {
+	cloud := c.Cloud.(scaleway.ScalewayCloud)
+
+	keys, err := cloud.Client().ListSSHKeys(fi.StringValue(k.Name))
+	if err != nil {
+		return nil, fmt.Errorf("error listing ssh keys: %v", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	key := keys[0]
+	actual := &SSHKey{
+		Name:      fi.String(key.Name),
+		Lifecycle: k.Lifecycle,
+		ID:        fi.String(key.ID),
+		PublicKey: fi.String(key.PublicKey),
+	}
+	// The API drops the comment of the key
+	if sameSSHKey(key.PublicKey, fi.StringValue(k.PublicKey)) {
+		actual.PublicKey = k.PublicKey
+	}
+	k.ID = actual.ID
+	return actual, nil
+}
+
+// sameSSHKey compares the type and data of two public keys, ignoring their comments
+func sameSSHKey(l, r string) bool {
+	lf := strings.Fields(l)
+	rf := strings.Fields(r)
+	return len(lf) >= 2 && len(rf) >= 2 && lf[0] == rf[0] && lf[1] == rf[1]
+}
+
+func (k *SSHKey) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(k, c)
+}
+
+func (_ *SSHKey) CheckChanges(a, e, changes *SSHKey) error {
+	if a != nil {
+		if changes.PublicKey != nil {
+			return fi.CannotChangeField("PublicKey")
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.PublicKey == nil {
+			return fi.RequiredField("PublicKey")
+		}
+	}
+	return nil
+}
+
+func (_ *SSHKey) RenderScaleway(t *scaleway.ScalewayAPITarget, a, e, changes *SSHKey) error {
+	if a != nil {
+		return nil
+	}
+
+	glog.V(2).Infof("Creating SSH key %q", fi.StringValue(e.Name))
+	key, err := t.Cloud.Client().CreateSSHKey(fi.StringValue(e.Name), fi.StringValue(e.PublicKey))
+	if err != nil {
+		return fmt.Errorf("error creating ssh key %q: %v", fi.StringValue(e.Name), err)
+	}
+	e.ID = fi.String(key.ID)
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalewaytasks
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
+)
+
+//go:generate fitask -type=Volume
+
+// Volume is a block volume, holding the data of an etcd member; protokube attaches it to a master
+type Volume struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	ID     *string
+	SizeGB *int
+	Zone   *string
+	Tags   []string
+}
+
+var _ fi.CompareWithID = &Volume{}
+
+func (v *Volume) CompareWithID() *string {
+	return v.Name
+}
+
+func (v *Volume) Find(c *fi.Context) (*Volume, error) {
+	cloud := c.Cloud.(scaleway.ScalewayCloud)
+
+	volumes, err := cloud.Client().ListVolumes(fi.StringValue(v.Zone), cloud.ClusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %v", err)
+	}
+
+	for _, volume := range volumes {
+		if volume.Name != fi.StringValue(v.Name) {
+			continue
+		}
+		actual := &Volume{
+			Name:      fi.String(volume.Name),
+			Lifecycle: v.Lifecycle,
+			ID:        fi.String(volume.ID),
+			SizeGB:    fi.Int(int(volume.Size / bytesPerGB)),
+			Zone:      v.Zone,
+			Tags:      append([]string(nil), volume.Tags...),
+		}
+		sort.Strings(actual.Tags)
+		sort.Strings(v.Tags)
+
+		v.ID = actual.ID
+		return actual, nil
+	}
+	return nil, nil
+}
+
+// bytesPerGB is the unit of volume sizes; Scaleway sizes volumes in bytes, in multiples of 1GB
+const bytesPerGB = 1000 * 1000 * 1000
+
+func (v *Volume) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(v, c)
+}
+
+func (_ *Volume) CheckChanges(a, e, changes *Volume) error {
+	if a != nil {
+		if changes.SizeGB != nil {
+			return fi.CannotChangeField("SizeGB")
+		}
+		if changes.Zone != nil {
+			return fi.CannotChangeField("Zone")
+		}
+		if changes.Tags != nil {
+			return fi.CannotChangeField("Tags")
+		}
+	} else {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if e.SizeGB == nil {
+			return fi.RequiredField("SizeGB")
+		}
+		if e.Zone == nil {
+			return fi.RequiredField("Zone")
+		}
+	}
+	return nil
+}
+
+func (_ *Volume) RenderScaleway(t *scaleway.ScalewayAPITarget, a, e, changes *Volume) error {
+	if a != nil {
+		return nil
+	}
+
+	glog.V(2).Infof("Creating volume %q", fi.StringValue(e.Name))
+	volume, err := t.Cloud.Client().CreateVolume(fi.StringValue(e.Zone), &scaleway.VolumeCreateOpts{
+		Name:       fi.StringValue(e.Name),
+		Size:       uint64(fi.IntValue(e.SizeGB)) * bytesPerGB,
+		VolumeType: scaleway.VolumeTypeBlockSSD,
+		Tags:       e.Tags,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating volume %q: %v", fi.StringValue(e.Name), err)
+	}
+	e.ID = fi.String(volume.ID)
+	return nil
+}
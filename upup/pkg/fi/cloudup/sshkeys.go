@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// findAdditionalSSHPublicKeys returns the public keys of the SSH credentials other than the primary (admin) key,
+// sorted by name and then key so that the configuration built from them is stable
+func findAdditionalSSHPublicKeys(sshCredentialStore fi.SSHCredentialStore) ([][]byte, error) {
+	credentials, err := sshCredentialStore.ListSSHCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("error listing SSH credentials: %v", err)
+	}
+
+	type namedKey struct {
+		Name string
+		Key  string
+	}
+	var keys []namedKey
+	seen := make(map[string]bool)
+	for _, credential := range credentials {
+		if credential.Name == fi.SecretNameSSHPrimary {
+			continue
+		}
+		key := strings.TrimSpace(credential.Spec.PublicKey)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, namedKey{Name: credential.Name, Key: key})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	var publicKeys [][]byte
+	for _, k := range keys {
+		publicKeys = append(publicKeys, []byte(k.Key))
+	}
+	return publicKeys, nil
+}
+
+// buildSSHAuthorizedKeys returns the keys nodeup should authorize for the admin user: the primary keys followed by
+// the additional keys.  It is empty when there are no additional keys, as the cloud then installs the primary key.
+func buildSSHAuthorizedKeys(primary [][]byte, additional [][]byte) []string {
+	if len(additional) == 0 {
+		return nil
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, k := range append(append([][]byte{}, primary...), additional...) {
+		key := strings.TrimSpace(string(k))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
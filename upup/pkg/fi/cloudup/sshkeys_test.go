@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	testSSHKeyAdmin = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJ/skk63h1a1u0NKDm93DmCPdIT9fr1vekL/KqRE6t8t admin@example.com"
+	testSSHKeyAlice = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAP3zeAJbKJK0Mru+xBBoBI5Ef1EkLeebxMQy+I+BU5h alice@example.com"
+	testSSHKeyBob   = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINzmfzzPnRboJsCHAHf3vFBfqd81uwBIE/crM/1hgeDI bob@example.com"
+)
+
+func TestFindAdditionalSSHPublicKeys(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = "cluster.example.com"
+	store := fi.NewVFSCAStore(cluster, vfs.NewMemFSPath(vfs.NewMemFSContext(), "state/pki"), false)
+
+	keys, err := findAdditionalSSHPublicKeys(store)
+	if err != nil {
+		t.Fatalf("unexpected error with no credentials: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("expected no keys, got %q", keys)
+	}
+
+	for name, key := range map[string]string{
+		fi.SecretNameSSHPrimary: testSSHKeyAdmin,
+		"bob":                   testSSHKeyBob + "\n",
+		"alice":                 testSSHKeyAlice,
+	} {
+		if err := store.AddSSHPublicKey(name, []byte(key)); err != nil {
+			t.Fatalf("error adding key %q: %v", name, err)
+		}
+	}
+
+	keys, err = findAdditionalSSHPublicKeys(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	for _, k := range keys {
+		actual = append(actual, string(k))
+	}
+	expected := []string{testSSHKeyAlice, testSSHKeyBob}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected keys %q, expected %q", actual, expected)
+	}
+}
+
+func TestBuildSSHAuthorizedKeys(t *testing.T) {
+	primary := [][]byte{[]byte(testSSHKeyAdmin + "\n")}
+
+	if keys := buildSSHAuthorizedKeys(primary, nil); keys != nil {
+		t.Errorf("expected no keys without additional keys, got %q", keys)
+	}
+
+	keys := buildSSHAuthorizedKeys(primary, [][]byte{[]byte(testSSHKeyAlice), []byte(testSSHKeyAdmin)})
+	expected := []string{testSSHKeyAdmin, testSSHKeyAlice}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected keys %q, expected %q", keys, expected)
+	}
+}
@@ -17,48 +17,12 @@ limitations under the License.
 package cloudup
 
 import (
-	"net"
 	"reflect"
 	"testing"
 
 	"k8s.io/kops/pkg/apis/kops"
 )
 
-func Test_Split_Subnet(t *testing.T) {
-	tests := []struct {
-		parent   string
-		expected []string
-	}{
-		{
-			parent:   "1.2.3.0/24",
-			expected: []string{"1.2.3.0/27", "1.2.3.32/27", "1.2.3.64/27", "1.2.3.96/27", "1.2.3.128/27", "1.2.3.160/27", "1.2.3.192/27", "1.2.3.224/27"},
-		},
-		{
-			parent:   "1.2.3.0/27",
-			expected: []string{"1.2.3.0/30", "1.2.3.4/30", "1.2.3.8/30", "1.2.3.12/30", "1.2.3.16/30", "1.2.3.20/30", "1.2.3.24/30", "1.2.3.28/30"},
-		},
-	}
-	for _, test := range tests {
-		_, parent, err := net.ParseCIDR(test.parent)
-		if err != nil {
-			t.Fatalf("error parsing parent cidr %q: %v", test.parent, err)
-		}
-
-		subnets, err := splitInto8Subnets(parent)
-		if err != nil {
-			t.Fatalf("error splitting parent cidr %q: %v", parent, err)
-		}
-
-		var actual []string
-		for _, subnet := range subnets {
-			actual = append(actual, subnet.String())
-		}
-		if !reflect.DeepEqual(actual, test.expected) {
-			t.Fatalf("unexpected result of split: actual=%v, expected=%v", actual, test.expected)
-		}
-	}
-}
-
 func Test_AssignSubnets(t *testing.T) {
 	tests := []struct {
 		subnets  []kops.ClusterSubnetSpec
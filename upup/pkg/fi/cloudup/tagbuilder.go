@@ -31,6 +31,7 @@ import (
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 )
 
 func buildCloudupTags(cluster *api.Cluster) (sets.String, error) {
@@ -58,10 +59,19 @@ func buildCloudupTags(cluster *api.Cluster) (sets.String, error) {
 	case api.CloudProviderBareMetal:
 		// No tags
 
+	case api.CloudProviderHetzner:
+		// No tags
+
+	case api.CloudProviderScaleway:
+		// No tags
+
 	case api.CloudProviderOpenstack:
 
 	default:
-		return nil, fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
+		// Cloud provider plugins have no tags
+		if cloudplugin.Get(api.CloudProviderID(cluster.Spec.CloudProvider)) == nil {
+			return nil, fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
+		}
 	}
 
 	versionTag := ""
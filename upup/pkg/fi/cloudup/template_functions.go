@@ -28,6 +28,8 @@ When defining a new function:
 package cloudup
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -37,10 +39,12 @@ import (
 
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 
+	"github.com/ghodss/yaml"
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -63,6 +67,9 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap, secretStore fi.SecretS
 	dest["ToJSON"] = tf.ToJSON
 	dest["UseBootstrapTokens"] = tf.modelContext.UseBootstrapTokens
 	dest["UseEtcdTLS"] = tf.modelContext.UseEtcdTLS
+	dest["PodSecurityPreset"] = tf.PodSecurityPreset
+	dest["NetworkPolicyNamespaces"] = func() []string { return networkPolicyNamespaces(tf.cluster) }
+	dest["RBACBootstrap"] = func() *kops.RBACAuthorizationSpec { return rbacBootstrap(tf.cluster) }
 	// Remember that we may be on a different arch from the target.  Hard-code for now.
 	dest["Arch"] = func() string { return "amd64" }
 	dest["replace"] = func(s, find, replace string) string {
@@ -86,9 +93,28 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap, secretStore fi.SecretS
 	dest["KubeDNS"] = func() *kops.KubeDNSConfig {
 		return tf.cluster.Spec.KubeDNS
 	}
+	dest["DNSAutoscalerEnabled"] = tf.DNSAutoscalerEnabled
+	dest["DNSAutoscalerParams"] = tf.DNSAutoscalerParams
+	dest["DexConfig"] = tf.DexConfig
+	dest["DexConfigHash"] = func() (string, error) { return dexOptionsHash(tf.cluster.Spec.Authentication.OIDC) }
+	dest["DexImage"] = tf.DexImage
+	dest["AwsIAMAuthenticatorConfig"] = func() (string, error) {
+		return buildAwsIAMAuthenticatorConfig(tf.cluster.ObjectMeta.Name, tf.cluster.Spec.Authentication.Aws)
+	}
+	dest["AwsIAMAuthenticatorConfigHash"] = func() (string, error) {
+		return awsIAMAuthenticatorOptionsHash(tf.cluster.ObjectMeta.Name, tf.cluster.Spec.Authentication.Aws)
+	}
+	dest["AwsIAMAuthenticatorImage"] = tf.AwsIAMAuthenticatorImage
+	dest["ManagesAwsIAMAuthenticatorConfig"] = func() bool { return managesAwsIAMAuthenticatorConfig(tf.cluster.Spec.Authentication.Aws) }
 
+	dest["CloudControllerConfigArgv"] = func() ([]string, error) {
+		return cloudControllerManagerArgv(tf.cluster)
+	}
 	dest["DnsControllerArgv"] = tf.DnsControllerArgv
 	dest["ExternalDnsArgv"] = tf.ExternalDnsArgv
+	dest["ExternalDNSProvider"] = func() string {
+		return dns.ExternalProviderID(tf.cluster)
+	}
 
 	// TODO: Only for GCE?
 	dest["EncodeGCELabel"] = gce.EncodeGCELabel
@@ -152,6 +178,212 @@ func (tf *TemplateFunctions) SharedVPC() bool {
 	return tf.cluster.SharedVPC()
 }
 
+// PodSecurityPreset returns the pod security policy preset for workloads, or an empty string if none is set
+func (tf *TemplateFunctions) PodSecurityPreset() string {
+	if tf.cluster.Spec.PodSecurity == nil {
+		return ""
+	}
+	return tf.cluster.Spec.PodSecurity.Preset
+}
+
+// networkPolicyNamespaces returns the namespaces the default-deny network policies are installed in
+func networkPolicyNamespaces(cluster *kops.Cluster) []string {
+	if cluster.Spec.NetworkPolicy == nil || len(cluster.Spec.NetworkPolicy.Namespaces) == 0 {
+		return []string{"default"}
+	}
+	return cluster.Spec.NetworkPolicy.Namespaces
+}
+
+// rbacBootstrapEnabled returns true if the cluster spec binds any groups or service account to the default cluster roles
+func rbacBootstrapEnabled(cluster *kops.Cluster) bool {
+	if cluster.Spec.Authorization == nil || cluster.Spec.Authorization.RBAC == nil {
+		return false
+	}
+	rbac := cluster.Spec.Authorization.RBAC
+	return len(rbac.AdminGroups) != 0 || len(rbac.ReadOnlyGroups) != 0 || rbac.CIServiceAccount != nil
+}
+
+// rbacBootstrap returns the RBAC bindings of the cluster, with the defaults for the CI service account filled in
+func rbacBootstrap(cluster *kops.Cluster) *kops.RBACAuthorizationSpec {
+	rbac := *cluster.Spec.Authorization.RBAC
+	if rbac.CIServiceAccount != nil {
+		sa := *rbac.CIServiceAccount
+		if sa.Namespace == "" {
+			sa.Namespace = "kube-system"
+		}
+		if sa.ClusterRole == "" {
+			sa.ClusterRole = "edit"
+		}
+		rbac.CIServiceAccount = &sa
+	}
+	return &rbac
+}
+
+// DNSAutoscalerEnabled returns true if the cluster-proportional-autoscaler should size the dns deployment
+func (tf *TemplateFunctions) DNSAutoscalerEnabled() bool {
+	kubeDNS := tf.cluster.Spec.KubeDNS
+	return kubeDNS == nil || kubeDNS.Autoscaler == nil || !kubeDNS.Autoscaler.Disabled
+}
+
+// DNSAutoscalerParams returns the linear scaling parameters for the dns autoscaler, encoded as json
+func (tf *TemplateFunctions) DNSAutoscalerParams() (string, error) {
+	linear := struct {
+		CoresPerReplica           int  `json:"coresPerReplica"`
+		NodesPerReplica           int  `json:"nodesPerReplica"`
+		Min                       int  `json:"min,omitempty"`
+		Max                       int  `json:"max,omitempty"`
+		PreventSinglePointFailure bool `json:"preventSinglePointFailure"`
+	}{
+		CoresPerReplica:           256,
+		NodesPerReplica:           16,
+		PreventSinglePointFailure: true,
+	}
+
+	if tf.cluster.Spec.KubeDNS != nil && tf.cluster.Spec.KubeDNS.Autoscaler != nil {
+		autoscaler := tf.cluster.Spec.KubeDNS.Autoscaler
+		if autoscaler.CoresPerReplica != 0 {
+			linear.CoresPerReplica = autoscaler.CoresPerReplica
+		}
+		if autoscaler.NodesPerReplica != 0 {
+			linear.NodesPerReplica = autoscaler.NodesPerReplica
+		}
+		linear.Min = autoscaler.Min
+		linear.Max = autoscaler.Max
+	}
+
+	b, err := json.Marshal(linear)
+	if err != nil {
+		return "", fmt.Errorf("error encoding dns autoscaler params: %v", err)
+	}
+	return string(b), nil
+}
+
+// DefaultDexImage is the dex image run when the cluster does not specify one
+const DefaultDexImage = "quay.io/coreos/dex:v2.10.0"
+
+// DexImage returns the image for the managed dex addon
+func (tf *TemplateFunctions) DexImage() string {
+	oidc := tf.cluster.Spec.Authentication.OIDC
+	if oidc.Dex.Image != "" {
+		return oidc.Dex.Image
+	}
+	return DefaultDexImage
+}
+
+// DexConfig returns the configuration of the managed dex addon, encoded as json (which dex reads as yaml)
+func (tf *TemplateFunctions) DexConfig() (string, error) {
+	return buildDexConfig(tf.cluster.Spec.Authentication.OIDC)
+}
+
+// dexOptionsHash returns a short hash of the dex configuration and image, which changes whenever the manifest does
+func dexOptionsHash(oidc *kops.OIDCAuthenticationSpec) (string, error) {
+	config, err := buildDexConfig(oidc)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(config + "\n" + oidc.Dex.Image))
+	return hex.EncodeToString(hash[:])[:8], nil
+}
+
+func buildDexConfig(oidc *kops.OIDCAuthenticationSpec) (string, error) {
+	var connectors []map[string]interface{}
+	if err := yaml.Unmarshal([]byte(oidc.Dex.Connectors), &connectors); err != nil {
+		return "", fmt.Errorf("error parsing dex connectors: %v", err)
+	}
+
+	type storage struct {
+		Type   string          `json:"type"`
+		Config map[string]bool `json:"config"`
+	}
+	type client struct {
+		ID           string   `json:"id"`
+		Name         string   `json:"name"`
+		Public       bool     `json:"public"`
+		RedirectURIs []string `json:"redirectURIs,omitempty"`
+	}
+	config := struct {
+		Issuer        string                   `json:"issuer"`
+		Storage       storage                  `json:"storage"`
+		Web           map[string]string        `json:"web"`
+		OAuth2        map[string]bool          `json:"oauth2"`
+		StaticClients []client                 `json:"staticClients"`
+		Connectors    []map[string]interface{} `json:"connectors"`
+	}{
+		Issuer:  oidc.IssuerURL,
+		Storage: storage{Type: "kubernetes", Config: map[string]bool{"inCluster": true}},
+		// TLS is terminated in front of dex, wherever it is exposed at the issuer URL
+		Web:    map[string]string{"http": "0.0.0.0:5556"},
+		OAuth2: map[string]bool{"skipApprovalScreen": true},
+		// The client is public, so that command line tools (which cannot keep a secret) can log in
+		StaticClients: []client{{ID: oidc.ClientID, Name: oidc.ClientID, Public: true, RedirectURIs: oidc.Dex.RedirectURIs}},
+		Connectors:    connectors,
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error encoding dex config: %v", err)
+	}
+	return string(b), nil
+}
+
+// DefaultAwsIAMAuthenticatorImage is the aws-iam-authenticator image run when the cluster does not specify one
+const DefaultAwsIAMAuthenticatorImage = "gcr.io/heptio-images/authenticator:v0.3.0"
+
+// AwsIAMAuthenticatorImage returns the image for the aws-iam-authenticator addon
+func (tf *TemplateFunctions) AwsIAMAuthenticatorImage() string {
+	aws := tf.cluster.Spec.Authentication.Aws
+	if aws.Image != "" {
+		return aws.Image
+	}
+	return DefaultAwsIAMAuthenticatorImage
+}
+
+// managesAwsIAMAuthenticatorConfig is true when kops owns the aws-iam-authenticator config map.  Without any
+// mappings the config map is left to the user, as it was before mappings could be set in the cluster spec.
+func managesAwsIAMAuthenticatorConfig(aws *kops.AwsAuthenticationSpec) bool {
+	return len(aws.MapRoles) != 0 || len(aws.MapUsers) != 0
+}
+
+// awsIAMAuthenticatorOptionsHash returns a short hash of the aws-iam-authenticator configuration and image,
+// which changes whenever the manifest does
+func awsIAMAuthenticatorOptionsHash(clusterName string, aws *kops.AwsAuthenticationSpec) (string, error) {
+	config := ""
+	if managesAwsIAMAuthenticatorConfig(aws) {
+		c, err := buildAwsIAMAuthenticatorConfig(clusterName, aws)
+		if err != nil {
+			return "", err
+		}
+		config = c
+	}
+	hash := sha256.Sum256([]byte(config + "\n" + aws.Image))
+	return hex.EncodeToString(hash[:])[:8], nil
+}
+
+func buildAwsIAMAuthenticatorConfig(clusterName string, aws *kops.AwsAuthenticationSpec) (string, error) {
+	clusterID := aws.ClusterID
+	if clusterID == "" {
+		clusterID = clusterName
+	}
+
+	type server struct {
+		MapRoles []kops.AwsAuthenticationRoleMapping `json:"mapRoles,omitempty"`
+		MapUsers []kops.AwsAuthenticationUserMapping `json:"mapUsers,omitempty"`
+	}
+	config := struct {
+		ClusterID string `json:"clusterID"`
+		Server    server `json:"server"`
+	}{
+		ClusterID: clusterID,
+		Server:    server{MapRoles: aws.MapRoles, MapUsers: aws.MapUsers},
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error encoding aws-iam-authenticator config: %v", err)
+	}
+	return string(b), nil
+}
+
 // HasTag returns true if the specified tag is set
 func (tf *TemplateFunctions) HasTag(tag string) bool {
 	_, found := tf.tags[tag]
@@ -168,6 +400,28 @@ func (tf *TemplateFunctions) GetInstanceGroup(name string) (*kops.InstanceGroup,
 	return nil, fmt.Errorf("InstanceGroup %q not found", name)
 }
 
+// cloudControllerConfigPath is where nodeup writes the cloud config on the masters
+const cloudControllerConfigPath = "/etc/kubernetes/cloud.config"
+
+// cloudControllerManagerArgv returns the args to the external cloud-controller-manager
+func cloudControllerManagerArgv(cluster *kops.Cluster) ([]string, error) {
+	ccm := cluster.Spec.ExternalCloudControllerManager
+	if ccm == nil {
+		return nil, fmt.Errorf("cloudControllerManager is not set")
+	}
+
+	argv, err := flagbuilder.BuildFlagsList(ccm)
+	if err != nil {
+		return nil, fmt.Errorf("error building cloud-controller-manager flags: %v", err)
+	}
+
+	// As for kube-controller-manager
+	if cluster.Spec.CloudConfig != nil {
+		argv = append(argv, "--cloud-config="+cloudControllerConfigPath)
+	}
+	return argv, nil
+}
+
 // DnsControllerArgv returns the args to the DNS controller
 func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 	var argv []string
@@ -199,6 +453,11 @@ func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 	if dns.IsGossipHostname(tf.cluster.Spec.MasterInternalName) {
 		argv = append(argv, "--dns=gossip")
 		argv = append(argv, "--gossip-seed=127.0.0.1:3999")
+	} else if providerID := dns.ExternalProviderID(tf.cluster); providerID != "" {
+		argv = append(argv, "--dns="+providerID)
+		for _, config := range dns.ExternalProviderConfig(tf.cluster) {
+			argv = append(argv, "--dns-config="+config)
+		}
 	} else {
 		switch kops.CloudProviderID(tf.cluster.Spec.CloudProvider) {
 		case kops.CloudProviderAWS:
@@ -206,6 +465,9 @@ func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 				argv = append(argv, "--dns=gossip")
 			} else {
 				argv = append(argv, "--dns=aws-route53")
+				for _, config := range dns.AWSRoute53ProviderConfig(tf.cluster) {
+					argv = append(argv, "--dns-config="+config)
+				}
 			}
 		case kops.CloudProviderGCE:
 			argv = append(argv, "--dns=google-clouddns")
@@ -232,6 +494,10 @@ func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 	}
 	// permit wildcard updates
 	argv = append(argv, "--zone=*/*")
+	if tf.cluster.Spec.DNSZoneOptions != nil && tf.cluster.Spec.DNSZoneOptions.Private {
+		// With split-horizon DNS, the public zone has the same name
+		argv = append(argv, "--prefer-private-zones")
+	}
 	// Verbose, but not crazy logging
 	argv = append(argv, "-v=2")
 
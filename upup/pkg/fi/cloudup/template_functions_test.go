@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestBuildDexConfig(t *testing.T) {
+	oidc := &kops.OIDCAuthenticationSpec{
+		IssuerURL: "https://dex.example.com",
+		ClientID:  "kubernetes",
+		Dex: &kops.DexAuthenticationSpec{
+			Connectors: `
+- type: github
+  id: github
+  name: GitHub
+`,
+			RedirectURIs: []string{"http://localhost:8000"},
+		},
+	}
+
+	config, err := buildDexConfig(oidc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal([]byte(config), &actual); err != nil {
+		t.Fatalf("error parsing dex config %q: %v", config, err)
+	}
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"issuer": "https://dex.example.com",
+		"storage": {"type": "kubernetes", "config": {"inCluster": true}},
+		"web": {"http": "0.0.0.0:5556"},
+		"oauth2": {"skipApprovalScreen": true},
+		"staticClients": [{"id": "kubernetes", "name": "kubernetes", "public": true, "redirectURIs": ["http://localhost:8000"]}],
+		"connectors": [{"type": "github", "id": "github", "name": "GitHub"}]
+	}`), &expected); err != nil {
+		t.Fatalf("error parsing expected config: %v", err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected dex config %s", config)
+	}
+
+	hash, err := dexOptionsHash(oidc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oidc.Dex.Image = "example.com/dex:latest"
+	if changed, _ := dexOptionsHash(oidc); changed == hash {
+		t.Errorf("expected the hash to change with the image")
+	}
+}
+
+func TestBuildAwsIAMAuthenticatorConfig(t *testing.T) {
+	aws := &kops.AwsAuthenticationSpec{
+		MapRoles: []kops.AwsAuthenticationRoleMapping{
+			{RoleARN: "arn:aws:iam::000000000000:role/KubernetesAdmin", Username: "kubernetes-admin", Groups: []string{"system:masters"}},
+		},
+		MapUsers: []kops.AwsAuthenticationUserMapping{
+			{UserARN: "arn:aws:iam::000000000000:user/Alice", Username: "alice"},
+		},
+	}
+
+	config, err := buildAwsIAMAuthenticatorConfig("cluster.example.com", aws)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"clusterID":"cluster.example.com","server":{` +
+		`"mapRoles":[{"roleARN":"arn:aws:iam::000000000000:role/KubernetesAdmin","username":"kubernetes-admin","groups":["system:masters"]}],` +
+		`"mapUsers":[{"userARN":"arn:aws:iam::000000000000:user/Alice","username":"alice"}]}}`
+	if config != expected {
+		t.Errorf("unexpected aws-iam-authenticator config %s", config)
+	}
+
+	aws.ClusterID = "my-cluster"
+	if config, _ := buildAwsIAMAuthenticatorConfig("cluster.example.com", aws); !strings.Contains(config, `"clusterID":"my-cluster"`) {
+		t.Errorf("expected the cluster id to override the cluster name, got %s", config)
+	}
+
+	if !managesAwsIAMAuthenticatorConfig(aws) {
+		t.Errorf("expected the config map to be managed when mappings are set")
+	}
+	if managesAwsIAMAuthenticatorConfig(&kops.AwsAuthenticationSpec{}) {
+		t.Errorf("expected the config map to be left to the user without mappings")
+	}
+
+	hash, err := awsIAMAuthenticatorOptionsHash("cluster.example.com", aws)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aws.MapUsers = nil
+	if changed, _ := awsIAMAuthenticatorOptionsHash("cluster.example.com", aws); changed == hash {
+		t.Errorf("expected the hash to change with the mappings")
+	}
+}
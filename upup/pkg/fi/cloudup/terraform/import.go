@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"fmt"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// Importable is implemented by tasks whose existing cloud resources can be adopted into terraform state
+type Importable interface {
+	// TerraformImport returns the command to import the actual resource, or nil if it is not managed by terraform
+	TerraformImport(actual fi.Task) *ImportCommand
+}
+
+// ImportCommand adopts an existing cloud resource into terraform state, under the address used in the terraform output
+type ImportCommand struct {
+	ResourceType string
+	ResourceName string
+	ID           string
+}
+
+// NewImportCommand builds an ImportCommand, naming the resource as RenderResource does
+func NewImportCommand(resourceType string, resourceName string, id string) *ImportCommand {
+	return &ImportCommand{
+		ResourceType: resourceType,
+		ResourceName: tfSanitize(resourceName),
+		ID:           id,
+	}
+}
+
+// Address is the terraform address of the resource
+func (i *ImportCommand) Address() string {
+	return i.ResourceType + "." + i.ResourceName
+}
+
+func (i *ImportCommand) String() string {
+	return fmt.Sprintf("terraform import %s %s", i.Address(), i.ID)
+}
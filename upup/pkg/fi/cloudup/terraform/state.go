@@ -0,0 +1,281 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StateDiff describes how the generated terraform resources differ from an existing terraform state
+type StateDiff struct {
+	// Added are the addresses of generated resources that are not in the state
+	Added []string
+	// Removed are the addresses of managed resources in the state that are no longer generated
+	Removed []string
+	// Changed are the generated resources whose attributes differ from the state
+	Changed []*ResourceDiff
+}
+
+// ResourceDiff describes the attributes of a resource that differ from the terraform state
+type ResourceDiff struct {
+	Address    string
+	Attributes []*AttributeDiff
+}
+
+// AttributeDiff is an attribute whose generated value differs from the value in the terraform state
+type AttributeDiff struct {
+	Name      string
+	Generated string
+	// State is the value in the terraform state, or nil if the attribute is not set
+	State *string
+}
+
+// HasChanges returns true if the generated resources differ from the state
+func (d *StateDiff) HasChanges() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Changed) != 0
+}
+
+// Print writes a human readable report of the differences
+func (d *StateDiff) Print(out io.Writer) error {
+	b := &bytes.Buffer{}
+	if !d.HasChanges() {
+		fmt.Fprintf(b, "Terraform state matches the generated configuration\n")
+	}
+	if len(d.Added) != 0 {
+		fmt.Fprintf(b, "Resources not in the terraform state:\n")
+		for _, address := range d.Added {
+			fmt.Fprintf(b, "  + %s\n", address)
+		}
+		fmt.Fprintf(b, "\n")
+	}
+	if len(d.Removed) != 0 {
+		fmt.Fprintf(b, "Resources in the terraform state that are no longer generated:\n")
+		for _, address := range d.Removed {
+			fmt.Fprintf(b, "  - %s\n", address)
+		}
+		fmt.Fprintf(b, "\n")
+	}
+	if len(d.Changed) != 0 {
+		fmt.Fprintf(b, "Resources that differ from the terraform state:\n")
+		for _, r := range d.Changed {
+			fmt.Fprintf(b, "  ~ %s\n", r.Address)
+			for _, a := range r.Attributes {
+				state := "<unset>"
+				if a.State != nil {
+					state = fmt.Sprintf("%q", *a.State)
+				}
+				fmt.Fprintf(b, "      %s: %s => %q\n", a.Name, state, a.Generated)
+			}
+		}
+		fmt.Fprintf(b, "\n")
+	}
+	_, err := b.WriteTo(out)
+	return err
+}
+
+// DiffState compares the resources rendered to the target with the contents of a terraform.tfstate file.
+// Only scalar attributes, and maps of scalar attributes such as tags, are compared; references to other
+// resources and files are skipped, because their values are only known to terraform.
+func (t *TerraformTarget) DiffState(stateData []byte) (*StateDiff, error) {
+	state, err := parseState(stateData)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	diff := &StateDiff{}
+	generated := make(map[string]bool)
+	for _, res := range t.resources {
+		address := res.ResourceType + "." + tfSanitize(res.ResourceName)
+		generated[address] = true
+
+		stateAttributes, found := state[address]
+		if !found {
+			diff.Added = append(diff.Added, address)
+			continue
+		}
+
+		attributes, err := flattenGenerated(res.Item)
+		if err != nil {
+			return nil, fmt.Errorf("error reading attributes of %s: %v", address, err)
+		}
+
+		var names []string
+		for name := range attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		r := &ResourceDiff{Address: address}
+		for _, name := range names {
+			value := attributes[name]
+			stateValue, found := stateAttributes[name]
+			if found && stateValue == value {
+				continue
+			}
+			a := &AttributeDiff{Name: name, Generated: value}
+			if found {
+				a.State = &stateValue
+			}
+			r.Attributes = append(r.Attributes, a)
+		}
+		if len(r.Attributes) != 0 {
+			diff.Changed = append(diff.Changed, r)
+		}
+	}
+
+	for address := range state {
+		if !generated[address] {
+			diff.Removed = append(diff.Removed, address)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Address < diff.Changed[j].Address
+	})
+
+	return diff, nil
+}
+
+// terraformStateV3 is the state format written by terraform before 0.12
+type terraformStateV3 struct {
+	Modules []struct {
+		Path      []string `json:"path"`
+		Resources map[string]struct {
+			Type    string `json:"type"`
+			Primary struct {
+				ID         string            `json:"id"`
+				Attributes map[string]string `json:"attributes"`
+			} `json:"primary"`
+		} `json:"resources"`
+	} `json:"modules"`
+}
+
+// terraformStateV4 is the state format written by terraform 0.12 and later
+type terraformStateV4 struct {
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// parseState returns the flattened attributes of the managed resources in the root module of a terraform state
+func parseState(data []byte) (map[string]map[string]string, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("error parsing terraform state: %v", err)
+	}
+
+	resources := make(map[string]map[string]string)
+	switch header.Version {
+	case 1, 2, 3:
+		state := &terraformStateV3{}
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("error parsing terraform state: %v", err)
+		}
+		for _, module := range state.Modules {
+			if len(module.Path) != 1 || module.Path[0] != "root" {
+				continue
+			}
+			for address, r := range module.Resources {
+				if strings.HasPrefix(address, "data.") {
+					continue
+				}
+				resources[address] = r.Primary.Attributes
+			}
+		}
+
+	case 4:
+		state := &terraformStateV4{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(state); err != nil {
+			return nil, fmt.Errorf("error parsing terraform state: %v", err)
+		}
+		for _, r := range state.Resources {
+			if r.Module != "" || r.Mode != "managed" || len(r.Instances) == 0 {
+				continue
+			}
+			attributes := make(map[string]string)
+			flattenAttributes("", r.Instances[0].Attributes, attributes)
+			resources[r.Type+"."+r.Name] = attributes
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported terraform state version %d", header.Version)
+	}
+
+	return resources, nil
+}
+
+// flattenGenerated returns the scalar attributes of a generated resource, in the flattened form of the terraform state
+func flattenGenerated(item interface{}) (map[string]string, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]string)
+	flattenAttributes("", values, attributes)
+
+	for k, v := range attributes {
+		// References and files are interpolated by terraform
+		if strings.Contains(v, "${") {
+			delete(attributes, k)
+		}
+	}
+	return attributes, nil
+}
+
+// flattenAttributes adds the scalar values, and maps of scalar values, to attributes, keyed as in the terraform state
+func flattenAttributes(prefix string, values map[string]interface{}, attributes map[string]string) {
+	for k, v := range values {
+		switch v := v.(type) {
+		case string:
+			attributes[prefix+k] = v
+		case bool, json.Number:
+			attributes[prefix+k] = fmt.Sprintf("%v", v)
+		case map[string]interface{}:
+			if prefix == "" {
+				flattenAttributes(k+".", v, attributes)
+			}
+		}
+	}
+}
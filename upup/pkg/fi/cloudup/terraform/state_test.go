@@ -0,0 +1,155 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"testing"
+)
+
+type testVPC struct {
+	CIDR               *string           `json:"cidr_block"`
+	EnableDNSHostnames *bool             `json:"enable_dns_hostnames"`
+	Tags               map[string]string `json:"tags"`
+}
+
+type testSubnet struct {
+	VPCID *Literal `json:"vpc_id"`
+	CIDR  *string  `json:"cidr_block"`
+}
+
+func s(v string) *string {
+	return &v
+}
+
+func b(v bool) *bool {
+	return &v
+}
+
+func buildStateTestTarget() *TerraformTarget {
+	t := &TerraformTarget{}
+	t.RenderResource("aws_vpc", "minimal.example.com", &testVPC{
+		CIDR:               s("172.20.0.0/16"),
+		EnableDNSHostnames: b(true),
+		Tags:               map[string]string{"Name": "minimal.example.com"},
+	})
+	t.RenderResource("aws_subnet", "us-test-1a.minimal.example.com", &testSubnet{
+		VPCID: LiteralProperty("aws_vpc", "minimal.example.com", "id"),
+		CIDR:  s("172.20.32.0/19"),
+	})
+	return t
+}
+
+func checkStateDiff(t *testing.T, diff *StateDiff) {
+	if len(diff.Added) != 1 || diff.Added[0] != "aws_subnet.us-test-1a-minimal-example-com" {
+		t.Errorf("unexpected added resources %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "aws_internet_gateway.minimal-example-com" {
+		t.Errorf("unexpected removed resources %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Address != "aws_vpc.minimal-example-com" {
+		t.Fatalf("unexpected changed resources %v", diff.Changed)
+	}
+
+	attributes := diff.Changed[0].Attributes
+	if len(attributes) != 2 {
+		t.Fatalf("unexpected changed attributes %v", attributes)
+	}
+	if attributes[0].Name != "cidr_block" || attributes[0].Generated != "172.20.0.0/16" || *attributes[0].State != "10.0.0.0/16" {
+		t.Errorf("unexpected cidr_block diff %+v", attributes[0])
+	}
+	if attributes[1].Name != "tags.Name" || attributes[1].State != nil {
+		t.Errorf("unexpected tags diff %+v", attributes[1])
+	}
+}
+
+func TestDiffStateV3(t *testing.T) {
+	state := `{
+  "version": 3,
+  "modules": [
+    {
+      "path": ["root"],
+      "resources": {
+        "aws_vpc.minimal-example-com": {
+          "type": "aws_vpc",
+          "primary": {
+            "id": "vpc-12345678",
+            "attributes": {
+              "cidr_block": "10.0.0.0/16",
+              "enable_dns_hostnames": "true",
+              "tags.%": "0"
+            }
+          }
+        },
+        "aws_internet_gateway.minimal-example-com": {
+          "type": "aws_internet_gateway",
+          "primary": {"id": "igw-12345678", "attributes": {}}
+        },
+        "data.aws_region.current": {
+          "type": "aws_region",
+          "primary": {"id": "us-test-1", "attributes": {}}
+        }
+      }
+    }
+  ]
+}`
+
+	diff, err := buildStateTestTarget().DiffState([]byte(state))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkStateDiff(t, diff)
+}
+
+func TestDiffStateV4(t *testing.T) {
+	state := `{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "minimal-example-com",
+      "instances": [
+        {"attributes": {"cidr_block": "10.0.0.0/16", "enable_dns_hostnames": true, "tags": {}}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_internet_gateway",
+      "name": "minimal-example-com",
+      "instances": [{"attributes": {"id": "igw-12345678"}}]
+    },
+    {
+      "mode": "data",
+      "type": "aws_region",
+      "name": "current",
+      "instances": [{"attributes": {"name": "us-test-1"}}]
+    }
+  ]
+}`
+
+	diff, err := buildStateTestTarget().DiffState([]byte(state))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkStateDiff(t, diff)
+}
+
+func TestDiffStateUnsupportedVersion(t *testing.T) {
+	if _, err := buildStateTestTarget().DiffState([]byte(`{"version": 9}`)); err == nil {
+		t.Errorf("expected error for unsupported state version")
+	}
+}
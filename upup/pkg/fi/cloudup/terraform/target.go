@@ -88,6 +88,15 @@ func tfSanitize(name string) string {
 }
 
 func (t *TerraformTarget) AddFile(resourceType string, resourceName string, key string, r fi.Resource) (*Literal, error) {
+	return t.addFile(resourceType, resourceName, key, r, "${file(%q)}")
+}
+
+// AddFileBase64 is like AddFile, but the literal evaluates to the base64 encoding of the file contents
+func (t *TerraformTarget) AddFileBase64(resourceType string, resourceName string, key string, r fi.Resource) (*Literal, error) {
+	return t.addFile(resourceType, resourceName, key, r, "${base64encode(file(%q))}")
+}
+
+func (t *TerraformTarget) addFile(resourceType string, resourceName string, key string, r fi.Resource, expression string) (*Literal, error) {
 	id := resourceType + "_" + resourceName + "_" + key
 
 	d, err := fi.ResourceAsBytes(r)
@@ -101,7 +110,7 @@ func (t *TerraformTarget) AddFile(resourceType string, resourceName string, key
 	p := path.Join("data", id)
 	t.files[p] = d
 
-	l := LiteralExpression(fmt.Sprintf("${file(%q)}", path.Join("${path.module}", p)))
+	l := LiteralExpression(fmt.Sprintf(expression, path.Join("${path.module}", p)))
 	return l, nil
 }
 
@@ -28,10 +28,14 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/aliup"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/cloudup/baremetal"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudplugin"
 	"k8s.io/kops/upup/pkg/fi/cloudup/do"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/hetzner"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
+	"k8s.io/kops/upup/pkg/fi/cloudup/scaleway"
 	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 func BuildCloud(cluster *kops.Cluster) (fi.Cloud, error) {
@@ -81,7 +85,7 @@ func BuildCloud(cluster *kops.Cluster) (fi.Cloud, error) {
 
 			cloudTags := map[string]string{awsup.TagClusterName: cluster.ObjectMeta.Name}
 
-			awsCloud, err := awsup.NewAWSCloud(region, cloudTags)
+			awsCloud, err := awsup.NewAWSCloudWithAssumeRole(region, cloudTags, awsup.AssumeRoleForCluster(cluster), awsup.DNSAssumeRoleForCluster(cluster))
 			if err != nil {
 				return nil, err
 			}
@@ -126,12 +130,68 @@ func BuildCloud(cluster *kops.Cluster) (fi.Cloud, error) {
 				return nil, fmt.Errorf("Error building (k8s) DNS provider: %v", err)
 			}
 
-			baremetalCloud, err := baremetal.NewCloud(dns)
+			// The state of the hosts is recorded in the state store, alongside the cluster
+			var stateBase vfs.Path
+			if cluster.Spec.ConfigBase != "" {
+				configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
+				}
+				stateBase = configBase.Join("baremetal")
+			}
+
+			baremetalCloud, err := baremetal.NewCloud(dns, stateBase)
 			if err != nil {
 				return nil, err
 			}
 			cloud = baremetalCloud
 		}
+	case kops.CloudProviderHetzner:
+		{
+			// The launch specs of the instance groups are recorded in the state store, alongside the cluster
+			var stateBase vfs.Path
+			if cluster.Spec.ConfigBase != "" {
+				configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
+				}
+				stateBase = configBase.Join("hetzner")
+			}
+
+			if len(cluster.Spec.Subnets) == 0 {
+				return nil, fmt.Errorf("must specify at least one subnet for a Hetzner Cloud cluster")
+			}
+			location := cluster.Spec.Subnets[0].Zone
+
+			hetznerCloud, err := hetzner.NewHetznerCloud(location, cluster.ObjectMeta.Name, stateBase)
+			if err != nil {
+				return nil, err
+			}
+			cloud = hetznerCloud
+		}
+	case kops.CloudProviderScaleway:
+		{
+			// The instance pools of the instance groups are recorded in the state store, alongside the cluster
+			var stateBase vfs.Path
+			if cluster.Spec.ConfigBase != "" {
+				configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
+				}
+				stateBase = configBase.Join("scaleway")
+			}
+
+			if len(cluster.Spec.Subnets) == 0 {
+				return nil, fmt.Errorf("must specify at least one subnet for a Scaleway cluster")
+			}
+			zone := cluster.Spec.Subnets[0].Zone
+
+			scalewayCloud, err := scaleway.NewScalewayCloud(zone, cluster.ObjectMeta.Name, stateBase)
+			if err != nil {
+				return nil, err
+			}
+			cloud = scalewayCloud
+		}
 	case kops.CloudProviderOpenstack:
 		{
 			cloudTags := map[string]string{openstack.TagClusterName: cluster.ObjectMeta.Name}
@@ -158,7 +218,15 @@ func BuildCloud(cluster *kops.Cluster) (fi.Cloud, error) {
 			cloud = aliCloud
 		}
 	default:
-		return nil, fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
+		provider := cloudplugin.Get(kops.CloudProviderID(cluster.Spec.CloudProvider))
+		if provider == nil {
+			return nil, fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
+		}
+		pluginCloud, err := provider.BuildCloud(cluster)
+		if err != nil {
+			return nil, err
+		}
+		cloud = pluginCloud
 	}
 	return cloud, nil
 }
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/golang/glog"
@@ -42,6 +43,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 // VSphereCloud represents a vSphere cloud instance.
@@ -54,6 +56,9 @@ type VSphereCloud struct {
 	Client        *govmomi.Client
 	CoreDNSServer string
 	DNSZone       string
+
+	// stateBase is where the launch specs of the instance groups are kept, in the state store
+	stateBase vfs.Path
 }
 
 const (
@@ -100,28 +105,110 @@ func NewVSphereCloud(spec *kops.ClusterSpec) (*VSphereCloud, error) {
 	// Add retry functionality
 	c.RoundTripper = vim25.Retry(c.RoundTripper, vim25.TemporaryNetworkError(5))
 	vsphereCloud := &VSphereCloud{Server: server, Datacenter: datacenter, Cluster: cluster, Username: username, Password: password, Client: c, CoreDNSServer: dns_server, DNSZone: dns_zone}
+	if spec.ConfigBase != "" {
+		configBase, err := vfs.Context.BuildVfsPath(spec.ConfigBase)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing config base %q: %v", spec.ConfigBase, err)
+		}
+		vsphereCloud.stateBase = configBase.Join("vsphere")
+	}
 	spec.CloudConfig.VSphereUsername = fi.String(username)
 	spec.CloudConfig.VSpherePassword = fi.String(password)
 	glog.V(2).Infof("Created vSphere Cloud successfully: %+v", vsphereCloud)
 	return vsphereCloud, nil
 }
 
-// GetCloudGroups is not implemented yet, that needs to return the instances and groups that back a kops cluster.
+// GetCloudGroups returns a group for each instance group, with a member per VM. VMs need updating when they were
+// created from a launch spec other than the one last published by update cluster.
 func (c *VSphereCloud) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
-	glog.V(8).Infof("vSphere cloud provider GetCloudGroups not implemented yet")
-	return nil, fmt.Errorf("vSphere cloud provider does not support getting cloud groups at this time")
+	instances, err := c.ListInstances(cluster.ObjectMeta.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error listing VMs: %v", err)
+	}
+
+	// The hostname of the nodes is the name of their VM
+	nodeMap := make(map[string]*v1.Node)
+	for i := range nodes {
+		nodeMap[nodes[i].Name] = &nodes[i]
+	}
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	for _, ig := range instancegroups {
+		spec, err := c.ReadLaunchSpec(ig.ObjectMeta.Name)
+		if err != nil {
+			return nil, err
+		}
+		var hash string
+		if spec != nil {
+			if hash, err = spec.Hash(); err != nil {
+				return nil, err
+			}
+		}
+
+		group := &cloudinstances.CloudInstanceGroup{
+			HumanName:     ig.ObjectMeta.Name,
+			InstanceGroup: ig,
+			MinSize:       int(fi.Int32Value(ig.Spec.MinSize)),
+			MaxSize:       int(fi.Int32Value(ig.Spec.MaxSize)),
+		}
+		for _, instance := range instances {
+			if instance.InstanceGroup != ig.ObjectMeta.Name {
+				continue
+			}
+			var reasons []string
+			if hash != "" && instance.ConfigHash != hash {
+				reasons = append(reasons, cloudinstances.ReasonConfigurationChanged)
+			}
+			if err := group.NewCloudInstanceGroupMemberWithReasons(instance.Name, reasons, nodeMap); err != nil {
+				return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
+			}
+		}
+		groups[ig.ObjectMeta.Name] = group
+	}
+
+	if warnUnmatched {
+		for _, instance := range instances {
+			if groups[instance.InstanceGroup] == nil {
+				glog.Warningf("found VM %q of unknown instance group %q", instance.Name, instance.InstanceGroup)
+			}
+		}
+	}
+
+	return groups, nil
 }
 
-// DeleteGroup is not implemented yet, is a func that needs to delete a vSphere instance group.
+// DeleteGroup deletes the VMs of the instance group. The etcd disks of masters are kept.
 func (c *VSphereCloud) DeleteGroup(g *cloudinstances.CloudInstanceGroup) error {
-	glog.V(8).Infof("vSphere cloud provider DeleteGroup not implemented yet")
-	return fmt.Errorf("vSphere cloud provider does not support deleting cloud groups at this time.")
+	clusterName := g.InstanceGroup.ObjectMeta.Labels[kops.LabelClusterName]
+	spec, err := c.ReadLaunchSpec(g.InstanceGroup.ObjectMeta.Name)
+	if err != nil {
+		return err
+	}
+	if spec != nil {
+		clusterName = spec.ClusterName
+	}
+
+	for _, members := range [][]*cloudinstances.CloudInstanceGroupMember{g.Ready, g.NeedUpdate} {
+		for _, member := range members {
+			if err := c.DeleteVM(member.ID, clusterName); err != nil {
+				return err
+			}
+		}
+	}
+	return c.DeleteLaunchSpec(g.InstanceGroup.ObjectMeta.Name)
 }
 
-// DeleteInstance is not implemented yet, is func needs to delete a vSphereCloud instance.
+// DeleteInstance replaces the VM with a new one, created from the launch spec of its instance group
 func (c *VSphereCloud) DeleteInstance(i *cloudinstances.CloudInstanceGroupMember) error {
-	glog.V(8).Infof("vSphere cloud provider DeleteInstance not implemented yet")
-	return fmt.Errorf("vSphere cloud provider does not support deleting cloud instances at this time.")
+	igName := i.CloudInstanceGroup.InstanceGroup.ObjectMeta.Name
+	spec, err := c.ReadLaunchSpec(igName)
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		return fmt.Errorf("no launch spec found for instance group %q; run kops update cluster first", igName)
+	}
+	return c.ReplaceVM(i.ID, spec)
 }
 
 // DNS returns dnsprovider interface for this vSphere cloud.
@@ -148,8 +235,8 @@ func (c *VSphereCloud) FindVPCInfo(id string) (*fi.VPCInfo, error) {
 	return nil, nil
 }
 
-// CreateLinkClonedVm creates linked clone of given VM image. This method will perform all necessary steps, like creating snapshot if it's not already present.
-func (c *VSphereCloud) CreateLinkClonedVm(vmName, vmImage *string) (string, error) {
+// CreateLinkClonedVm creates linked clone of given VM image, with the given extraConfig. This method will perform all necessary steps, like creating snapshot if it's not already present.
+func (c *VSphereCloud) CreateLinkClonedVm(vmName, vmImage *string, extraConfig map[string]string) (string, error) {
 	f := find.NewFinder(c.Client.Client, true)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -196,11 +283,22 @@ func (c *VSphereCloud) CreateLinkClonedVm(vmName, vmImage *string) (string, erro
 	resPoolRef := resPool.Reference()
 	snapshotRef := snapshot.Reference()
 
+	var keys []string
+	for k := range extraConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var options []types.BaseOptionValue
+	for _, k := range keys {
+		options = append(options, &types.OptionValue{Key: k, Value: extraConfig[k]})
+	}
+
 	cloneSpec := &types.VirtualMachineCloneSpec{
 		Config: &types.VirtualMachineConfigSpec{
 			Flags: &types.VirtualMachineFlagInfo{
 				DiskUuidEnabled: fi.Bool(true),
 			},
+			ExtraConfig: options,
 		},
 		Location: types.VirtualMachineRelocateSpec{
 			Pool:         &resPoolRef,
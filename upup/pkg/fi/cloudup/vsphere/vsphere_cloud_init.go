@@ -0,0 +1,216 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+// vsphere_cloud_init builds the cloud-init ISO that configures a VM on its first boot.
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pborman/uuid"
+)
+
+// Template for user-data file in the cloud-init ISO
+const userDataTemplate = `#cloud-config
+write_files:
+  - content: |
+$SCRIPT
+    owner: root:root
+    path: /root/script.sh
+    permissions: "0644"
+  - content: |
+$DNS_SCRIPT
+    owner: root:root
+    path: /root/update_dns.sh
+    permissions: "0644"
+  - content: |
+$VM_UUID
+    owner: root:root
+    path: /etc/vmware/vm_uuid
+    permissions: "0644"
+  - content: |
+$VOLUME_SCRIPT
+    owner: root:root
+    path: /vol-metadata/metadata.json
+    permissions: "0644"
+$DISK_SETUP
+
+runcmd:
+  - bash /root/update_dns.sh 2>&1 > /var/log/update_dns.log
+  - bash /root/script.sh 2>&1 > /var/log/script.log`
+
+// Template for meta-data file in the cloud-init ISO
+const metaDataTemplate = `instance-id: $INSTANCE_ID
+local-hostname: $LOCAL_HOST_NAME`
+
+// CloudInit is the VM specific configuration of the cloud-init ISO of a VM
+type CloudInit struct {
+	// VMName is the name of the VM, which becomes its hostname
+	VMName string
+	// VMUUID is the UUID of the VM, needed by the vSphere cloud provider
+	VMUUID string
+	// StartupScript is the nodeup bootstrap script
+	StartupScript string
+	// DNSServer is the URL of the CoreDNS server the VM resolves names with
+	DNSServer string
+	// VolumeMetadata is the etcd volume metadata of masters, see BuildVolumeMetadata
+	VolumeMetadata string
+	// EtcdDisks are the etcd disks attached to masters, which are formatted (if they are new) and mounted
+	EtcdDisks []EtcdDisk
+}
+
+// CreateCloudInitISO writes the user-data and meta-data of the VM to dir, and builds the cloud-init ISO from them,
+// returning the path of the ISO
+func CreateCloudInitISO(dir string, ci *CloudInit) (string, error) {
+	err := createUserData(ci, dir)
+	if err != nil {
+		return "", err
+	}
+	err = createMetaData(dir, ci.VMName)
+	if err != nil {
+		return "", err
+	}
+
+	isoFile := filepath.Join(dir, ci.VMName+".iso")
+	var commandName string
+
+	switch os := runtime.GOOS; os {
+	case "darwin":
+		commandName = "mkisofs"
+	case "linux":
+		commandName = "genisoimage"
+
+	default:
+		return "", fmt.Errorf("Cannot generate ISO file %s. Unsupported operation system (%s)!!!", isoFile, os)
+	}
+	cmd := exec.Command(commandName, "-o", isoFile, "-volid", "cidata", "-joliet", "-rock", dir)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil {
+		glog.Errorf("Error %s occurred while executing command %+v", err, cmd)
+		return "", err
+	}
+	glog.V(4).Infof("%s std output : %s\n", commandName, out.String())
+	glog.V(4).Infof("%s std error : %s\n", commandName, stderr.String())
+	return isoFile, nil
+}
+
+// BuildUserData returns the cloud-init user-data of the VM
+func BuildUserData(ci *CloudInit) (string, error) {
+	// Populate nodeup initialization script.
+
+	// Update the startup script to add the extra spaces for
+	// indentation when copied to the user-data file.
+	strArray := strings.Split(ci.StartupScript, "\n")
+	for i, str := range strArray {
+		if len(str) > 0 {
+			strArray[i] = "       " + str
+		}
+	}
+	startupStr := strings.Join(strArray, "\n")
+	data := strings.Replace(userDataTemplate, "$SCRIPT", startupStr, -1)
+
+	// Populate script to update nameserver for the VM.
+	dnsURL, err := url.Parse(ci.DNSServer)
+	if err != nil {
+		return "", err
+	}
+	dnsHost, _, err := net.SplitHostPort(dnsURL.Host)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	lines = append(lines, "       echo \"nameserver "+dnsHost+"\" >> /etc/resolvconf/resolv.conf.d/head")
+	lines = append(lines, "       resolvconf -u")
+	dnsUpdateStr := strings.Join(lines, "\n")
+	data = strings.Replace(data, "$DNS_SCRIPT", dnsUpdateStr, -1)
+
+	// Populate VM UUID information.
+	vmUUIDStr := "       " + ci.VMUUID + "\n"
+	data = strings.Replace(data, "$VM_UUID", vmUUIDStr, -1)
+
+	// Populate volume metadata.
+	data = strings.Replace(data, "$VOLUME_SCRIPT", "       "+ci.VolumeMetadata, -1)
+	data = strings.Replace(data, "$DISK_SETUP", buildDiskSetup(ci.EtcdDisks), -1)
+
+	return data, nil
+}
+
+// buildDiskSetup returns the cloud-init configuration that formats the etcd disks and mounts them where protokube
+// expects them. Existing filesystems are not overwritten, so the etcd data survives the replacement of a master.
+func buildDiskSetup(disks []EtcdDisk) string {
+	if len(disks) == 0 {
+		return ""
+	}
+
+	var fsSetup, mounts []string
+	for _, disk := range disks {
+		device := disk.Device()
+		fsSetup = append(fsSetup,
+			"  - label: master-"+disk.VolumeId,
+			"    filesystem: ext4",
+			"    device: "+device,
+			"    overwrite: false")
+		mounts = append(mounts, "  - [ "+device+", "+GetMountPoint(disk.VolumeId)+", ext4, \"defaults,nofail\", \"0\", \"2\" ]")
+	}
+
+	return "\nfs_setup:\n" + strings.Join(fsSetup, "\n") + "\n\nmounts:\n" + strings.Join(mounts, "\n") + "\n"
+}
+
+func createUserData(ci *CloudInit, dir string) error {
+	data, err := BuildUserData(ci)
+	if err != nil {
+		return err
+	}
+
+	userDataFile := filepath.Join(dir, "user-data")
+	glog.V(4).Infof("User data file content: %s", data)
+
+	if err = ioutil.WriteFile(userDataFile, []byte(data), 0644); err != nil {
+		glog.Errorf("Unable to write user-data into file %s", userDataFile)
+		return err
+	}
+
+	return nil
+}
+
+func createMetaData(dir string, vmName string) error {
+	data := strings.Replace(metaDataTemplate, "$INSTANCE_ID", uuid.NewUUID().String(), -1)
+	data = strings.Replace(data, "$LOCAL_HOST_NAME", vmName, -1)
+
+	glog.V(4).Infof("Meta data file content: %s", string(data))
+
+	metaDataFile := filepath.Join(dir, "meta-data")
+	if err := ioutil.WriteFile(metaDataFile, []byte(data), 0644); err != nil {
+		glog.Errorf("Unable to write meta-data into file %s", metaDataFile)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestBuildUserData(t *testing.T) {
+	ci := &CloudInit{
+		VMName:         "master-1",
+		VMUUID:         "4207bb2d-5b5f-4e55-a2e4-d57d2e4d6d3a",
+		StartupScript:  "#!/bin/bash\necho hello",
+		DNSServer:      "http://10.0.0.2:2379",
+		VolumeMetadata: "[]",
+		EtcdDisks: []EtcdDisk{
+			{VolumeId: "01", Datastore: "vsanDatastore", Path: "kops-c/a.etcd-main.vmdk", SizeGB: 20},
+			{VolumeId: "02", Datastore: "vsanDatastore", Path: "kops-c/a.etcd-events.vmdk", SizeGB: 20},
+		},
+	}
+
+	data, err := BuildUserData(ci)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"       echo hello\n",
+		"       echo \"nameserver 10.0.0.2\" >> /etc/resolvconf/resolv.conf.d/head",
+		"       4207bb2d-5b5f-4e55-a2e4-d57d2e4d6d3a\n",
+		"    device: /dev/sdb\n    overwrite: false",
+		"    device: /dev/sdc\n    overwrite: false",
+		"  - [ /dev/sdb, /mnt/master-01, ext4, \"defaults,nofail\", \"0\", \"2\" ]",
+		"  - [ /dev/sdc, /mnt/master-02, ext4, \"defaults,nofail\", \"0\", \"2\" ]",
+	} {
+		if !strings.Contains(data, expected) {
+			t.Errorf("user-data does not contain %q:\n%s", expected, data)
+		}
+	}
+	if strings.Contains(data, "$") {
+		t.Errorf("user-data has unreplaced placeholders:\n%s", data)
+	}
+}
+
+func TestBuildUserDataWithoutDisks(t *testing.T) {
+	data, err := BuildUserData(&CloudInit{VMName: "nodes-1", DNSServer: "http://10.0.0.2:2379"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(data, "fs_setup") || strings.Contains(data, "mounts") {
+		t.Errorf("user-data of a node sets up disks:\n%s", data)
+	}
+}
+
+func TestBuildVolumeMetadata(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.EtcdClusters = []*kops.EtcdClusterSpec{
+		{
+			Name: "main",
+			Members: []*kops.EtcdMemberSpec{
+				{Name: "a", InstanceGroup: stringPtr("master-a")},
+				{Name: "b", InstanceGroup: stringPtr("master-b")},
+			},
+		},
+		{
+			Name: "events",
+			Members: []*kops.EtcdMemberSpec{
+				{Name: "a", InstanceGroup: stringPtr("master-a")},
+				{Name: "b", InstanceGroup: stringPtr("master-b")},
+			},
+		},
+	}
+
+	text, err := BuildVolumeMetadata(cluster, "master-b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	volumes, err := UnmarshalVolumeMetadata(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(volumes) != 2 {
+		t.Fatalf("expected 2 volumes, got %v", volumes)
+	}
+	if volumes[0].EtcdClusterName != "main" || volumes[0].VolumeId != "01" || volumes[0].EtcdNodeName != "b" {
+		t.Errorf("unexpected volume %+v", volumes[0])
+	}
+	if volumes[1].EtcdClusterName != "events" || volumes[1].VolumeId != "02" || len(volumes[1].Members) != 2 {
+		t.Errorf("unexpected volume %+v", volumes[1])
+	}
+
+	if _, err := BuildVolumeMetadata(cluster, "nodes"); err == nil {
+		t.Errorf("expected error for an instance group without etcd members")
+	}
+}
+
+func TestLaunchSpecHash(t *testing.T) {
+	spec := &LaunchSpec{ClusterName: "c", InstanceGroup: "nodes", Role: "Node", Template: "ubuntu", StartupScript: "#!/bin/bash"}
+	h1, err := spec.Hash()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec.StartupScript = "#!/bin/bash\necho changed"
+	h2, err := spec.Hash()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h1 == h2 {
+		t.Errorf("hash did not change with the startup script")
+	}
+
+	extraConfig, err := spec.ExtraConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if extraConfig[ExtraConfigConfigHash] != h2 || extraConfig[ExtraConfigInstanceGroup] != "nodes" || extraConfig[ExtraConfigCluster] != "c" {
+		t.Errorf("unexpected extraConfig %v", extraConfig)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+// vsphere_etcd_disks houses the etcd disks of the masters. The disks live in a directory of the cluster on the etcd
+// datastore, apart from the VMs, so that they can be detached from a master and attached to its replacement.
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// EtcdDisk is the disk of the volume of an etcd cluster on a master
+type EtcdDisk struct {
+	// VolumeId is the id of the volume in the volume metadata, which determines where the disk is mounted
+	VolumeId string `json:"volumeId"`
+	// Datastore is the name of the datastore of the disk
+	Datastore string `json:"datastore"`
+	// Path is the path of the disk on the datastore
+	Path string `json:"path"`
+	// SizeGB is the size the disk is created with
+	SizeGB int32 `json:"sizeGB"`
+}
+
+// EtcdDiskDirectory returns the directory of the etcd disks of the cluster
+func EtcdDiskDirectory(clusterName string) string {
+	return "kops-" + clusterName
+}
+
+// EtcdDiskPath returns the path of the disk of an etcd member
+func EtcdDiskPath(clusterName, etcdClusterName, memberName string) string {
+	return EtcdDiskDirectory(clusterName) + "/" + memberName + ".etcd-" + etcdClusterName + ".vmdk"
+}
+
+// Device returns the device the disk appears as on the master. The disks are attached in order of their volume ids,
+// after the boot disk of the template.
+func (d *EtcdDisk) Device() string {
+	n, err := strconv.Atoi(d.VolumeId)
+	if err != nil || n < 1 || n > 25 {
+		glog.Warningf("unexpected etcd volume id %q", d.VolumeId)
+		n = 1
+	}
+	return "/dev/sd" + string(rune('a'+n))
+}
+
+// AttachEtcdDisks attaches the etcd disks to the VM, creating the disks that don't exist yet
+func (c *VSphereCloud) AttachEtcdDisks(vmName string, disks []EtcdDisk) error {
+	f := find.NewFinder(c.Client.Client, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dc, err := f.Datacenter(ctx, c.Datacenter)
+	if err != nil {
+		return err
+	}
+	f.SetDatacenter(dc)
+
+	vmRef, err := f.VirtualMachine(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	for i := range disks {
+		disk := &disks[i]
+
+		ds, err := f.Datastore(ctx, disk.Datastore)
+		if err != nil {
+			return fmt.Errorf("error finding datastore %q for etcd disk: %v", disk.Datastore, err)
+		}
+
+		exists := true
+		_, err = ds.Stat(ctx, disk.Path)
+		if err != nil {
+			switch err.(type) {
+			case object.DatastoreNoSuchDirectoryError:
+				if err := makeDatastoreDirectory(ctx, c, dc, ds, path.Dir(disk.Path)); err != nil {
+					return err
+				}
+				exists = false
+			case object.DatastoreNoSuchFileError:
+				exists = false
+			default:
+				return fmt.Errorf("error checking etcd disk %q: %v", ds.Path(disk.Path), err)
+			}
+		}
+
+		devices, err := vmRef.Device(ctx)
+		if err != nil {
+			return err
+		}
+		controller, err := devices.FindDiskController("scsi")
+		if err != nil {
+			return fmt.Errorf("error finding disk controller of VM %q: %v", vmName, err)
+		}
+
+		device := devices.CreateDisk(controller, ds.Reference(), ds.Path(disk.Path))
+		if !exists {
+			glog.V(2).Infof("Creating etcd disk %q", ds.Path(disk.Path))
+			device.CapacityInKB = int64(disk.SizeGB) * 1024 * 1024
+		} else {
+			glog.V(2).Infof("Attaching existing etcd disk %q", ds.Path(disk.Path))
+		}
+
+		// The disks survive the VM, whatever the disk mode of the template
+		backing := device.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		backing.DiskMode = string(types.VirtualDiskModeIndependent_persistent)
+
+		if err := vmRef.AddDevice(ctx, device); err != nil {
+			return fmt.Errorf("error attaching etcd disk %q to VM %q: %v", ds.Path(disk.Path), vmName, err)
+		}
+	}
+
+	return nil
+}
+
+// detachEtcdDisks detaches the etcd disks from the VM, keeping the disk files, so that they are not destroyed with it
+func detachEtcdDisks(ctx context.Context, vmRef *object.VirtualMachine, clusterName string) error {
+	devices, err := vmRef.Device(ctx)
+	if err != nil {
+		return err
+	}
+
+	dir := EtcdDiskDirectory(clusterName) + "/"
+	var etcdDisks []types.BaseVirtualDevice
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		backing, ok := device.GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		if !ok {
+			continue
+		}
+		var p object.DatastorePath
+		if !p.FromString(backing.FileName) {
+			continue
+		}
+		if strings.HasPrefix(p.Path, dir) {
+			etcdDisks = append(etcdDisks, device)
+		}
+	}
+
+	if len(etcdDisks) == 0 {
+		return nil
+	}
+
+	glog.V(2).Infof("Detaching %d etcd disks from VM %q", len(etcdDisks), vmRef.Name())
+	return vmRef.RemoveDevice(ctx, true, etcdDisks...)
+}
+
+// makeDatastoreDirectory creates a directory on the datastore. Top-level directories on vSAN datastores are
+// namespaces, which need to be created through the namespace manager.
+func makeDatastoreDirectory(ctx context.Context, c *VSphereCloud, dc *object.Datacenter, ds *object.Datastore, dir string) error {
+	dsType, err := ds.Type(ctx)
+	if err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("Creating directory %q", ds.Path(dir))
+	if dsType == types.HostFileSystemVolumeFileSystemTypeVsan {
+		_, err = object.NewDatastoreNamespaceManager(c.Client.Client).CreateDirectory(ctx, ds, dir, "")
+	} else {
+		err = object.NewFileManager(c.Client.Client).MakeDirectory(ctx, ds.Path(dir), dc, true)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating directory %q: %v", ds.Path(dir), err)
+	}
+	return nil
+}
@@ -0,0 +1,453 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+// vsphere_instances houses the management of the VMs of instance groups. VMs are labelled with kops keys in their
+// extraConfig, which is how they are found again.
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	// ExtraConfigCluster is the extraConfig key of the name of the cluster of a VM
+	ExtraConfigCluster = "kops.cluster"
+	// ExtraConfigInstanceGroup is the extraConfig key of the name of the instance group of a VM
+	ExtraConfigInstanceGroup = "kops.instancegroup"
+	// ExtraConfigRole is the extraConfig key of the role of a VM
+	ExtraConfigRole = "kops.role"
+	// ExtraConfigConfigHash is the extraConfig key of the hash of the launch spec a VM was created from
+	ExtraConfigConfigHash = "kops.confighash"
+)
+
+// VMInstance is a VM of an instance group
+type VMInstance struct {
+	Name          string
+	InstanceGroup string
+	Role          string
+	ConfigHash    string
+	PoweredOn     bool
+}
+
+// MasterAntiAffinityRuleName returns the name of the DRS rule that keeps the masters of the cluster on separate hosts
+func MasterAntiAffinityRuleName(clusterName string) string {
+	return "kops-" + clusterName + "-masters"
+}
+
+// ExtraConfig returns the extraConfig of the VMs created from the spec
+func (s *LaunchSpec) ExtraConfig() (map[string]string, error) {
+	hash, err := s.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		ExtraConfigCluster:       s.ClusterName,
+		ExtraConfigInstanceGroup: s.InstanceGroup,
+		ExtraConfigRole:          s.Role,
+		ExtraConfigConfigHash:    hash,
+	}, nil
+}
+
+// ListInstances returns the VMs of the cluster
+func (c *VSphereCloud) ListInstances(clusterName string) ([]*VMInstance, error) {
+	f := find.NewFinder(c.Client.Client, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dc, err := f.Datacenter(ctx, c.Datacenter)
+	if err != nil {
+		return nil, err
+	}
+	f.SetDatacenter(dc)
+
+	vms, err := f.VirtualMachineList(ctx, "*")
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var refs []types.ManagedObjectReference
+	for _, vm := range vms {
+		refs = append(refs, vm.Reference())
+	}
+
+	var vmResults []mo.VirtualMachine
+	pc := property.DefaultCollector(c.Client.Client)
+	err = pc.Retrieve(ctx, refs, []string{"name", "config.extraConfig", "runtime.powerState"}, &vmResults)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VM properties: %v", err)
+	}
+
+	var instances []*VMInstance
+	for i := range vmResults {
+		vm := &vmResults[i]
+		if vm.Config == nil {
+			continue
+		}
+
+		extraConfig := make(map[string]string)
+		for _, option := range vm.Config.ExtraConfig {
+			o := option.GetOptionValue()
+			if v, ok := o.Value.(string); ok && strings.HasPrefix(o.Key, "kops.") {
+				extraConfig[o.Key] = v
+			}
+		}
+		if extraConfig[ExtraConfigCluster] != clusterName {
+			continue
+		}
+
+		instances = append(instances, &VMInstance{
+			Name:          vm.Name,
+			InstanceGroup: extraConfig[ExtraConfigInstanceGroup],
+			Role:          extraConfig[ExtraConfigRole],
+			ConfigHash:    extraConfig[ExtraConfigConfigHash],
+			PoweredOn:     vm.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn,
+		})
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Name < instances[j].Name })
+	return instances, nil
+}
+
+// LaunchVM creates a VM from the launch spec: it is cloned from the template, the etcd disks are attached for
+// masters, and it is powered on with its cloud-init ISO
+func (c *VSphereCloud) LaunchVM(vmName string, spec *LaunchSpec) error {
+	extraConfig, err := spec.ExtraConfig()
+	if err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("Creating VM %q from template %q", vmName, spec.Template)
+	if _, err := c.CreateLinkClonedVm(&vmName, &spec.Template, extraConfig); err != nil {
+		return fmt.Errorf("error cloning VM %q: %v", vmName, err)
+	}
+
+	if len(spec.EtcdDisks) != 0 {
+		if err := c.AttachEtcdDisks(vmName, spec.EtcdDisks); err != nil {
+			return err
+		}
+	}
+
+	if err := c.AttachCloudInit(vmName, spec); err != nil {
+		return err
+	}
+
+	return c.PowerOn(vmName)
+}
+
+// AttachCloudInit builds the cloud-init ISO of the VM from the launch spec, and attaches it to the VM
+func (c *VSphereCloud) AttachCloudInit(vmName string, spec *LaunchSpec) error {
+	dir, err := ioutil.TempDir("", vmName)
+	if err != nil {
+		return fmt.Errorf("error creating tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Need this in cloud config file for vSphere CloudProvider
+	vmUUID, err := c.FindVMUUID(&vmName)
+	if err != nil {
+		return err
+	}
+
+	volumeMetadata := spec.VolumeMetadata
+	if volumeMetadata == "" {
+		volumeMetadata = "No volume metadata needed for " + spec.Role + "."
+	}
+
+	isoFile, err := CreateCloudInitISO(dir, &CloudInit{
+		VMName:         vmName,
+		VMUUID:         vmUUID,
+		StartupScript:  spec.StartupScript,
+		DNSServer:      c.CoreDNSServer,
+		VolumeMetadata: volumeMetadata,
+		EtcdDisks:      spec.EtcdDisks,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating cloud-init ISO for VM %q: %v", vmName, err)
+	}
+
+	return c.UploadAndAttachISO(&vmName, isoFile)
+}
+
+// HasCloudInitISO returns true if the cloud-init ISO is inserted in the cd-rom of the VM
+func (c *VSphereCloud) HasCloudInitISO(vmName string) (bool, error) {
+	vmRef, err := c.FindVM(vmName)
+	if err != nil || vmRef == nil {
+		return false, err
+	}
+
+	devices, err := vmRef.Device(context.TODO())
+	if err != nil {
+		return false, err
+	}
+	cdrom, err := devices.FindCdrom("")
+	if err != nil {
+		return false, nil
+	}
+	backing, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo)
+	if !ok {
+		return false, nil
+	}
+	return strings.HasSuffix(backing.FileName, " "+getCloudInitFileName(vmName)), nil
+}
+
+// FindVM returns the VM with the given name, or nil if there is no such VM
+func (c *VSphereCloud) FindVM(vmName string) (*object.VirtualMachine, error) {
+	f := find.NewFinder(c.Client.Client, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dc, err := f.Datacenter(ctx, c.Datacenter)
+	if err != nil {
+		return nil, err
+	}
+	f.SetDatacenter(dc)
+
+	vmRef, err := f.VirtualMachine(ctx, vmName)
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return vmRef, nil
+}
+
+// IsPoweredOn returns true if the VM is powered on
+func (c *VSphereCloud) IsPoweredOn(vmName string) (bool, error) {
+	vmRef, err := c.FindVM(vmName)
+	if err != nil || vmRef == nil {
+		return false, err
+	}
+
+	state, err := vmRef.PowerState(context.TODO())
+	if err != nil {
+		return false, err
+	}
+	return state == types.VirtualMachinePowerStatePoweredOn, nil
+}
+
+// DeleteVM powers off and destroys the VM of the cluster. The etcd disks are detached first, so that they are kept.
+func (c *VSphereCloud) DeleteVM(vmName string, clusterName string) error {
+	vmRef, err := c.FindVM(vmName)
+	if err != nil {
+		return err
+	}
+	if vmRef == nil {
+		glog.Warningf("VM %q not found", vmName)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, err := vmRef.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	if state == types.VirtualMachinePowerStatePoweredOn {
+		glog.V(2).Infof("Powering off VM %q", vmName)
+		task, err := vmRef.PowerOff(ctx)
+		if err != nil {
+			return err
+		}
+		if err := task.Wait(ctx); err != nil {
+			return fmt.Errorf("error powering off VM %q: %v", vmName, err)
+		}
+	}
+
+	if err := detachEtcdDisks(ctx, vmRef, clusterName); err != nil {
+		return fmt.Errorf("error detaching etcd disks of VM %q: %v", vmName, err)
+	}
+
+	if err := c.DeleteCloudInitISO(&vmName); err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("Destroying VM %q", vmName)
+	task, err := vmRef.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("error destroying VM %q: %v", vmName, err)
+	}
+	return nil
+}
+
+// ReplaceVM deletes the VM and creates it again from the current launch spec of its instance group. For masters,
+// the etcd disks are moved to the new VM, and the masters are kept apart again.
+func (c *VSphereCloud) ReplaceVM(vmName string, spec *LaunchSpec) error {
+	if err := c.DeleteVM(vmName, spec.ClusterName); err != nil {
+		return err
+	}
+	if err := c.LaunchVM(vmName, spec); err != nil {
+		return err
+	}
+
+	if spec.Role == string(kops.InstanceGroupRoleMaster) {
+		instances, err := c.ListInstances(spec.ClusterName)
+		if err != nil {
+			return err
+		}
+		var masters []string
+		for _, instance := range instances {
+			if instance.Role == string(kops.InstanceGroupRoleMaster) {
+				masters = append(masters, instance.Name)
+			}
+		}
+		if err := c.EnsureAntiAffinityRule(MasterAntiAffinityRuleName(spec.ClusterName), masters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasAntiAffinityRule returns true if the DRS cluster has the anti-affinity rule, for exactly the given VMs
+func (c *VSphereCloud) HasAntiAffinityRule(ruleName string, vmNames []string) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, rule, refs, err := c.findAntiAffinityRule(ctx, ruleName, vmNames)
+	if err != nil {
+		return false, err
+	}
+	if rule == nil {
+		return false, nil
+	}
+	return sameReferences(rule.Vm, refs), nil
+}
+
+// EnsureAntiAffinityRule creates or updates the anti-affinity rule of the DRS cluster, so that DRS places the given
+// VMs on separate hosts. DRS needs at least two VMs for a rule, so with fewer VMs the rule is removed.
+func (c *VSphereCloud) EnsureAntiAffinityRule(ruleName string, vmNames []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cluster, rule, refs, err := c.findAntiAffinityRule(ctx, ruleName, vmNames)
+	if err != nil {
+		return err
+	}
+
+	var ruleSpec types.ClusterRuleSpec
+	if len(refs) < 2 {
+		if rule == nil {
+			return nil
+		}
+		glog.V(2).Infof("Removing anti-affinity rule %q", ruleName)
+		ruleSpec.Operation = types.ArrayUpdateOperationRemove
+		ruleSpec.RemoveKey = rule.Key
+	} else {
+		if rule != nil && sameReferences(rule.Vm, refs) {
+			return nil
+		}
+		info := &types.ClusterAntiAffinityRuleSpec{
+			ClusterRuleInfo: types.ClusterRuleInfo{
+				Name:    ruleName,
+				Enabled: types.NewBool(true),
+			},
+			Vm: refs,
+		}
+		ruleSpec.Operation = types.ArrayUpdateOperationAdd
+		if rule != nil {
+			ruleSpec.Operation = types.ArrayUpdateOperationEdit
+			info.Key = rule.Key
+		}
+		ruleSpec.Info = info
+		glog.V(2).Infof("Setting anti-affinity rule %q for VMs %v", ruleName, vmNames)
+	}
+
+	task, err := cluster.Reconfigure(ctx, &types.ClusterConfigSpecEx{RulesSpec: []types.ClusterRuleSpec{ruleSpec}}, true)
+	if err != nil {
+		return fmt.Errorf("error reconfiguring cluster %q: %v", c.Cluster, err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("error setting anti-affinity rule %q: %v", ruleName, err)
+	}
+	return nil
+}
+
+// findAntiAffinityRule returns the DRS cluster, the anti-affinity rule with the given name if it exists, and the
+// references of the given VMs
+func (c *VSphereCloud) findAntiAffinityRule(ctx context.Context, ruleName string, vmNames []string) (*object.ClusterComputeResource, *types.ClusterAntiAffinityRuleSpec, []types.ManagedObjectReference, error) {
+	f := find.NewFinder(c.Client.Client, true)
+
+	dc, err := f.Datacenter(ctx, c.Datacenter)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	f.SetDatacenter(dc)
+
+	cluster, err := f.ClusterComputeResource(ctx, c.Cluster)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	config, err := cluster.Configuration(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting configuration of cluster %q: %v", c.Cluster, err)
+	}
+
+	var rule *types.ClusterAntiAffinityRuleSpec
+	for _, r := range config.Rule {
+		if antiAffinity, ok := r.(*types.ClusterAntiAffinityRuleSpec); ok && antiAffinity.Name == ruleName {
+			rule = antiAffinity
+		}
+	}
+
+	var refs []types.ManagedObjectReference
+	for _, vmName := range vmNames {
+		vmRef, err := f.VirtualMachine(ctx, vmName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		refs = append(refs, vmRef.Reference())
+	}
+
+	return cluster, rule, refs, nil
+}
+
+func sameReferences(l, r []types.ManagedObjectReference) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	refs := make(map[types.ManagedObjectReference]bool)
+	for _, ref := range l {
+		refs[ref] = true
+	}
+	for _, ref := range r {
+		if !refs[ref] {
+			return false
+		}
+	}
+	return true
+}
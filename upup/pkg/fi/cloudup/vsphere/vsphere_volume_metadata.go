@@ -20,7 +20,11 @@ package vsphere
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
 )
 
 // VolumeMetadata represents metadata for vSphere volumes. Unlike aws and gce clouds, vSphere doesn't support tags for volumes/vmdks yet. This metadata is used to pass the information that aws and gce clouds associate with volumes using tags.
@@ -59,6 +63,48 @@ func UnmarshalVolumeMetadata(text string) ([]VolumeMetadata, error) {
 	return v, err
 }
 
+// BuildVolumeMetadata returns the marshaled metadata of the etcd volumes of the master in the given instance group,
+// with a volume for each etcd cluster.
+func BuildVolumeMetadata(cluster *kops.Cluster, igName string) (string, error) {
+	var volsMetadata []VolumeMetadata
+
+	// Creating VolumeMetadata using clusters EtcdClusterSpec
+	for i, etcd := range cluster.Spec.EtcdClusters {
+		volMetadata := VolumeMetadata{}
+		volMetadata.EtcdClusterName = etcd.Name
+		volMetadata.VolumeId = GetVolumeId(i + 1)
+
+		var members []EtcdMemberSpec
+		var thisNode string
+		for _, member := range etcd.Members {
+			if *member.InstanceGroup == igName {
+				thisNode = member.Name
+			}
+			etcdMember := EtcdMemberSpec{
+				Name:          member.Name,
+				InstanceGroup: *member.InstanceGroup,
+			}
+			members = append(members, etcdMember)
+		}
+
+		if thisNode == "" {
+			return "", fmt.Errorf("Failed to construct volume metadata for %v InstanceGroup.", igName)
+		}
+
+		volMetadata.EtcdNodeName = thisNode
+		volMetadata.Members = members
+		volsMetadata = append(volsMetadata, volMetadata)
+	}
+
+	glog.V(4).Infof("Marshaling master vol metadata : %v", volsMetadata)
+	volsString, err := MarshalVolumeMetadata(volsMetadata)
+	glog.V(4).Infof("Marshaled master vol metadata: %v", volsString)
+	if err != nil {
+		return "", err
+	}
+	return volsString, nil
+}
+
 // GetVolumeId returns given integer value to VolumeId format, eg: for i=2, volume id="02".
 func GetVolumeId(i int) string {
 	return "0" + strconv.Itoa(i)
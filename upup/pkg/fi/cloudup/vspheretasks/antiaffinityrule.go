@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vspheretasks
+
+// antiaffinityrule houses the task that keeps VMs on separate hosts of the DRS cluster.
+
+import (
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
+)
+
+//go:generate fitask -type=AntiAffinityRule
+
+// AntiAffinityRule represents a DRS anti-affinity rule, which makes DRS place the VMs on separate hosts.
+type AntiAffinityRule struct {
+	Name *string
+	VMs  []*VirtualMachine
+}
+
+var _ fi.HasName = &AntiAffinityRule{}
+var _ fi.HasDependencies = &AntiAffinityRule{}
+
+// GetDependencies returns map of tasks on which this task depends.
+func (o *AntiAffinityRule) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+	for _, vm := range o.VMs {
+		vmCreateTask := tasks["VirtualMachine/"+*vm.Name]
+		if vmCreateTask == nil {
+			glog.Fatalf("Unable to find create VM task %s dependency for AntiAffinityRule %s", *vm.Name, *o.Name)
+		}
+		deps = append(deps, vmCreateTask)
+	}
+	return deps
+}
+
+// GetName returns the Name of the object, implementing fi.HasName
+func (o *AntiAffinityRule) GetName() *string {
+	return o.Name
+}
+
+// SetName sets the Name of the object, implementing fi.SetName
+func (o *AntiAffinityRule) SetName(name string) {
+	o.Name = &name
+}
+
+// String is the stringer function for the task, producing readable output using fi.TaskAsString
+func (o *AntiAffinityRule) String() string {
+	return fi.TaskAsString(o)
+}
+
+func (e *AntiAffinityRule) vmNames() []string {
+	var names []string
+	for _, vm := range e.VMs {
+		names = append(names, *vm.Name)
+	}
+	return names
+}
+
+// Run invokes DefaultDeltaRunMethod for this task.
+func (e *AntiAffinityRule) Run(c *fi.Context) error {
+	glog.V(4).Info("AntiAffinityRule.Run invoked!")
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+// Find returns the task if the rule exists for exactly the VMs of the task.
+func (e *AntiAffinityRule) Find(c *fi.Context) (*AntiAffinityRule, error) {
+	cloud := c.Cloud.(*vsphere.VSphereCloud)
+
+	for _, vm := range e.VMs {
+		vmRef, err := cloud.FindVM(*vm.Name)
+		if err != nil || vmRef == nil {
+			return nil, err
+		}
+	}
+
+	found, err := cloud.HasAntiAffinityRule(*e.Name, e.vmNames())
+	if err != nil || !found {
+		return nil, err
+	}
+
+	a := *e
+	return &a, nil
+}
+
+// CheckChanges is a no-op for this task.
+func (_ *AntiAffinityRule) CheckChanges(a, e, changes *AntiAffinityRule) error {
+	return nil
+}
+
+// RenderVSphere creates or updates the rule on the DRS cluster.
+func (_ *AntiAffinityRule) RenderVSphere(t *vsphere.VSphereAPITarget, a, e, changes *AntiAffinityRule) error {
+	return t.Cloud.EnsureAntiAffinityRule(*e.Name, e.vmNames())
+}
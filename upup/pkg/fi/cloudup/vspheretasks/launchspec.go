@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vspheretasks
+
+// launchspec houses the task that publishes the launch spec of an instance group to the state store.
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
+)
+
+//go:generate fitask -type=LaunchSpec
+
+// LaunchSpec represents what the VMs of an instance group are created from, see vsphere.LaunchSpec.
+type LaunchSpec struct {
+	Name            *string
+	VMTemplateName  *string
+	EtcdDisks       []vsphere.EtcdDisk
+	IG              *kops.InstanceGroup
+	BootstrapScript *model.BootstrapScript
+	Cluster         *kops.Cluster
+}
+
+var _ fi.HasName = &LaunchSpec{}
+var _ fi.HasDependencies = &LaunchSpec{}
+
+// GetDependencies returns map of tasks on which this task depends.
+func (o *LaunchSpec) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	return nil
+}
+
+// GetName returns the Name of the object, implementing fi.HasName
+func (o *LaunchSpec) GetName() *string {
+	return o.Name
+}
+
+// SetName sets the Name of the object, implementing fi.SetName
+func (o *LaunchSpec) SetName(name string) {
+	o.Name = &name
+}
+
+// String is the stringer function for the task, producing readable output using fi.TaskAsString
+func (o *LaunchSpec) String() string {
+	return fi.TaskAsString(o)
+}
+
+// Build returns the launch spec of the instance group.
+func (e *LaunchSpec) Build() (*vsphere.LaunchSpec, error) {
+	startupScript, err := e.BootstrapScript.ResourceNodeUp(e.IG, e.Cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error on resource nodeup: %v", err)
+	}
+	startupStr, err := startupScript.AsString()
+	if err != nil {
+		return nil, fmt.Errorf("error rendering startup script: %v", err)
+	}
+
+	spec := &vsphere.LaunchSpec{
+		ClusterName:   e.Cluster.ObjectMeta.Name,
+		InstanceGroup: e.IG.ObjectMeta.Name,
+		Role:          string(e.IG.Spec.Role),
+		Template:      fi.StringValue(e.VMTemplateName),
+		StartupScript: startupStr,
+		EtcdDisks:     e.EtcdDisks,
+	}
+
+	if e.IG.Spec.Role == kops.InstanceGroupRoleMaster {
+		spec.VolumeMetadata, err = vsphere.BuildVolumeMetadata(e.Cluster, e.IG.ObjectMeta.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return spec, nil
+}
+
+// Run invokes DefaultDeltaRunMethod for this task.
+func (e *LaunchSpec) Run(c *fi.Context) error {
+	glog.V(4).Info("LaunchSpec.Run invoked!")
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+// Find returns the task if the published launch spec is current. An outdated launch spec is treated as missing, as
+// the difference is in the rendered startup script rather than in the fields of the task.
+func (e *LaunchSpec) Find(c *fi.Context) (*LaunchSpec, error) {
+	cloud := c.Cloud.(*vsphere.VSphereCloud)
+
+	actual, err := cloud.ReadLaunchSpec(e.IG.ObjectMeta.Name)
+	if err != nil || actual == nil {
+		return nil, err
+	}
+	actualHash, err := actual.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := e.Build()
+	if err != nil {
+		return nil, err
+	}
+	expectedHash, err := expected.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	if actualHash != expectedHash {
+		return nil, nil
+	}
+
+	a := *e
+	return &a, nil
+}
+
+// CheckChanges is a no-op for this task.
+func (_ *LaunchSpec) CheckChanges(a, e, changes *LaunchSpec) error {
+	return nil
+}
+
+// RenderVSphere publishes the launch spec to the state store.
+func (_ *LaunchSpec) RenderVSphere(t *vsphere.VSphereAPITarget, a, e, changes *LaunchSpec) error {
+	spec, err := e.Build()
+	if err != nil {
+		return err
+	}
+	return t.Cloud.WriteLaunchSpec(spec)
+}
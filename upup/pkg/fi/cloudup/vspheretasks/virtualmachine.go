@@ -29,10 +29,23 @@ import (
 type VirtualMachine struct {
 	Name           *string
 	VMTemplateName *string
+	LaunchSpec     *LaunchSpec
 }
 
 var _ fi.CompareWithID = &VirtualMachine{}
 var _ fi.HasName = &VirtualMachine{}
+var _ fi.HasDependencies = &VirtualMachine{}
+
+// GetDependencies returns map of tasks on which this task depends.
+func (o *VirtualMachine) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+	launchSpecTask := tasks["LaunchSpec/"+*o.LaunchSpec.Name]
+	if launchSpecTask == nil {
+		glog.Fatalf("Unable to find launch spec task %s dependency for VirtualMachine %s", *o.LaunchSpec.Name, *o.Name)
+	}
+	deps = append(deps, launchSpecTask)
+	return deps
+}
 
 // GetName returns the Name of the object, implementing fi.HasName
 func (o *VirtualMachine) GetName() *string {
@@ -55,10 +68,17 @@ func (e *VirtualMachine) CompareWithID() *string {
 	return e.Name
 }
 
-// Find is a no-op for vSphere.
+// Find returns the task if the VM exists. VMs created from an older launch spec are replaced by rolling-update.
 func (e *VirtualMachine) Find(c *fi.Context) (*VirtualMachine, error) {
 	glog.V(4).Info("VirtualMachine.Find invoked!")
-	return nil, nil
+	cloud := c.Cloud.(*vsphere.VSphereCloud)
+	vmRef, err := cloud.FindVM(*e.Name)
+	if err != nil || vmRef == nil {
+		return nil, err
+	}
+
+	a := *e
+	return &a, nil
 }
 
 // Run executes DefaultDeltaRunMethod for this task.
@@ -73,12 +93,23 @@ func (_ *VirtualMachine) CheckChanges(a, e, changes *VirtualMachine) error {
 	return nil
 }
 
-// RenderVSphere executes the actual VM clone creation for vSphere cloud.
+// RenderVSphere executes the actual VM clone creation for vSphere cloud, attaching the etcd disks of masters.
 func (_ *VirtualMachine) RenderVSphere(t *vsphere.VSphereAPITarget, a, e, changes *VirtualMachine) error {
 	glog.V(4).Infof("VirtualMachine.RenderVSphere invoked with a(%+v) e(%+v) and changes(%+v)", a, e, changes)
-	_, err := t.Cloud.CreateLinkClonedVm(changes.Name, changes.VMTemplateName)
+	spec, err := e.LaunchSpec.Build()
 	if err != nil {
 		return err
 	}
+	extraConfig, err := spec.ExtraConfig()
+	if err != nil {
+		return err
+	}
+	_, err = t.Cloud.CreateLinkClonedVm(e.Name, e.VMTemplateName, extraConfig)
+	if err != nil {
+		return err
+	}
+	if len(spec.EtcdDisks) != 0 {
+		return t.Cloud.AttachEtcdDisks(*e.Name, spec.EtcdDisks)
+	}
 	return nil
 }
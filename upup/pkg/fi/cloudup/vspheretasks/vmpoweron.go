@@ -61,10 +61,17 @@ func (e *VMPowerOn) Run(c *fi.Context) error {
 	return fi.DefaultDeltaRunMethod(e, c)
 }
 
-// Find is a no-op for vSphere cloud, for now.
+// Find returns the task if the VM is already powered on.
 func (e *VMPowerOn) Find(c *fi.Context) (*VMPowerOn, error) {
-	glog.Info("VMPowerOn.Find invoked!")
-	return nil, nil
+	glog.V(4).Info("VMPowerOn.Find invoked!")
+	cloud := c.Cloud.(*vsphere.VSphereCloud)
+	poweredOn, err := cloud.IsPoweredOn(*e.AttachISO.VM.Name)
+	if err != nil || !poweredOn {
+		return nil, err
+	}
+
+	a := *e
+	return &a, nil
 }
 
 // CheckChanges is a no-op for vSphere cloud, for now.
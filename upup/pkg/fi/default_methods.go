@@ -54,6 +54,10 @@ func DefaultDeltaRunMethod(e Task, c *Context) error {
 			}
 			return err
 		}
+
+		if dryRunTarget, ok := c.Target.(*DryRunTarget); ok && a != nil {
+			dryRunTarget.recordExisting(a, e)
+		}
 	}
 
 	if a == nil {
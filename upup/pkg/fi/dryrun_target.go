@@ -40,6 +40,9 @@ type DryRunTarget struct {
 	changes   []*render
 	deletions []Deletion
 
+	// existing records the actual state of each task that was found, keyed by the expected task
+	existing map[Task]Task
+
 	// The destination to which the final report will be printed on Finish()
 	out io.Writer
 
@@ -403,7 +406,38 @@ func (t *DryRunTarget) Finish(taskMap map[string]Task) error {
 	return t.PrintReport(taskMap, t.out)
 }
 
+// recordExisting records the actual state found for a task
+func (t *DryRunTarget) recordExisting(a, e Task) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.existing == nil {
+		t.existing = make(map[Task]Task)
+	}
+	t.existing[e] = a
+}
+
+// Existing returns the actual state found for the expected task, or nil if the task was not found
+func (t *DryRunTarget) Existing(e Task) Task {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.existing[e]
+}
+
 // HasChanges returns true iff any changes would have been made
 func (t *DryRunTarget) HasChanges() bool {
 	return len(t.changes)+len(t.deletions) != 0
 }
+
+// ChangedTasks returns the expected state of the tasks which would have been created or changed
+func (t *DryRunTarget) ChangedTasks() []Task {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var tasks []Task
+	for _, r := range t.changes {
+		tasks = append(tasks, r.e)
+	}
+	return tasks
+}
@@ -43,6 +43,15 @@ type taskState struct {
 type RunTasksOptions struct {
 	MaxTaskDuration         time.Duration
 	WaitAfterAllTasksFailed time.Duration
+
+	// TaskTimings, if set, is notified of every attempt to run a task
+	TaskTimings TaskTimingRecorder
+}
+
+// TaskTimingRecorder records how long each attempt to run a task took.
+// RecordTask is called concurrently, from the goroutines running the tasks.
+type TaskTimingRecorder interface {
+	RecordTask(key string, start time.Time, duration time.Duration, err error)
 }
 
 func (o *RunTasksOptions) InitDefaults() {
@@ -176,7 +185,11 @@ func (e *executor) forkJoin(tasks []*taskState) []error {
 			results[index] = fmt.Errorf("function panic")
 			defer wg.Done()
 			glog.V(2).Infof("Executing task %q: %v\n", ts.key, ts.task)
+			start := time.Now()
 			results[index] = ts.task.Run(e.context)
+			if e.options.TaskTimings != nil {
+				e.options.TaskTimings.RecordTask(ts.key, start, time.Since(start), results[index])
+			}
 		}(tasks[i], i)
 	}
 
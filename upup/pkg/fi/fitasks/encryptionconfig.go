@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fitasks
+
+import (
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/encryptionconfig"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+//go:generate fitask -type=EncryptionConfig
+type EncryptionConfig struct {
+	Name      *string
+	Lifecycle *fi.Lifecycle
+
+	// Provider configures the encryption provider used when generating the config
+	Provider *kops.EncryptionProviderSpec
+}
+
+var _ fi.HasCheckExisting = &EncryptionConfig{}
+
+// It's important always to check for the existing config, so we never regenerate (and so lose) the key material
+func (e *EncryptionConfig) CheckExisting(c *fi.Context) bool {
+	return true
+}
+
+func (e *EncryptionConfig) Find(c *fi.Context) (*EncryptionConfig, error) {
+	name := fi.StringValue(e.Name)
+	if name == "" {
+		return nil, nil
+	}
+
+	secret, err := c.SecretStore.FindSecret(name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, nil
+	}
+
+	actual := &EncryptionConfig{
+		Name: &name,
+	}
+
+	// Changing the provider of an existing config requires a key rotation, so we don't report it as a change
+	actual.Provider = e.Provider
+	actual.Lifecycle = e.Lifecycle
+
+	return actual, nil
+}
+
+func (e *EncryptionConfig) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (s *EncryptionConfig) CheckChanges(a, e, changes *EncryptionConfig) error {
+	if a != nil {
+		if changes.Name != nil {
+			return fi.CannotChangeField("Name")
+		}
+	}
+	return nil
+}
+
+func (_ *EncryptionConfig) Render(c *fi.Context, a, e, changes *EncryptionConfig) error {
+	name := fi.StringValue(e.Name)
+	if name == "" {
+		return fi.RequiredField("Name")
+	}
+
+	config, err := encryptionconfig.Build(e.Provider)
+	if err != nil {
+		return fmt.Errorf("error building encryption config %q: %v", name, err)
+	}
+
+	data, err := config.ToYAML()
+	if err != nil {
+		return fmt.Errorf("error serializing encryption config %q: %v", name, err)
+	}
+
+	_, _, err = c.SecretStore.GetOrCreateSecret(name, &fi.Secret{Data: data})
+	if err != nil {
+		return fmt.Errorf("error creating encryption config %q: %v", name, err)
+	}
+
+	return nil
+}
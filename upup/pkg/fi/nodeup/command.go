@@ -22,6 +22,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/golang/glog"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kops/nodeup/pkg/distros"
 	"k8s.io/kops/nodeup/pkg/model"
@@ -36,6 +38,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/bootreport"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
@@ -62,6 +65,8 @@ type NodeUpCommand struct {
 
 // Run is responsible for perform the nodeup process
 func (c *NodeUpCommand) Run(out io.Writer) error {
+	startTime := time.Now()
+
 	if c.FSRoot == "" {
 		return fmt.Errorf("FSRoot is required")
 	}
@@ -218,6 +223,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 	loader := NewLoader(c.config, c.cluster, assetStore, nodeTags)
 	loader.Builders = append(loader.Builders, &model.DirectoryBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.VolumesBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.UpdateServiceBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.DockerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.ProtokubeBuilder{NodeupModelContext: modelContext})
@@ -225,16 +231,20 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.FileAssetsBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.HookBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.NodeAuthorizationBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.KopsControllerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeletBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.PreloadImagesBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubectlBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.EtcdBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.LogrotateBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.ManifestsBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.PackagesBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.NTPBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.SecretBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.FirewallBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.NetworkBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.SysctlBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.SSHAuthorizedKeysBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeAPIServerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeControllerManagerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeSchedulerBuilder{NodeupModelContext: modelContext})
@@ -296,7 +306,13 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	var options fi.RunTasksOptions
 	options.InitDefaults()
 
+	timings := bootreport.NewRecorder()
+	options.TaskTimings = timings
+
 	err = context.RunTasks(options)
+	if c.Target == "direct" {
+		c.reportBootTimings(configBase, startTime, timings, err != nil)
+	}
 	if err != nil {
 		glog.Exitf("error running tasks: %v", err)
 	}
@@ -309,6 +325,46 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	return nil
 }
 
+// reportBootTimings logs the slowest tasks and, if enabled, publishes the boot report to the state store.
+// Failures are only logged; the report must never prevent the node from joining the cluster.
+func (c *NodeUpCommand) reportBootTimings(configBase vfs.Path, startTime time.Time, timings *bootreport.Recorder, failed bool) {
+	tasks := timings.Tasks()
+	for _, t := range bootreport.Slowest(tasks, 5) {
+		glog.Infof("task %q took %v (%d attempts)", t.Key, t.Duration.Duration, t.Attempts)
+	}
+
+	if !c.cluster.Spec.NodeBootReportsEnabled() {
+		return
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		glog.Warningf("unable to determine hostname for boot report: %v", err)
+		return
+	}
+
+	report := &bootreport.Report{
+		Hostname:      hostname,
+		InstanceGroup: c.config.InstanceGroupName,
+		StartTime:     metav1.NewTime(startTime),
+		EndTime:       metav1.Now(),
+		Failed:        failed,
+		Tasks:         tasks,
+	}
+	if bootTime, err := bootreport.BootTime(startTime); err != nil {
+		glog.Warningf("unable to determine boot time for boot report: %v", err)
+	} else {
+		t := metav1.NewTime(bootTime)
+		report.BootTime = &t
+	}
+
+	if err := bootreport.Write(configBase, report); err != nil {
+		glog.Warningf("unable to publish boot report: %v", err)
+		return
+	}
+	glog.Infof("published boot report for %s: nodeup took %v", hostname, report.Duration())
+}
+
 func evaluateSpec(c *api.Cluster) error {
 	var err error
 
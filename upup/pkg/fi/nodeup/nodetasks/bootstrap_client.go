@@ -0,0 +1,156 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/kopscontroller/bootstrap"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+)
+
+// renewBefore is how long before its certificate expires that we bootstrap again
+const renewBefore = 30 * 24 * time.Hour
+
+// BootstrapClientTask obtains a kubelet client certificate from the kops-controller, by proving the identity of the instance
+type BootstrapClientTask struct {
+	Name string
+	// Server is the url of the kops-controller
+	Server string
+	// CA is the PEM encoded certificate authority which signed the kops-controller's serving certificate
+	CA string
+	// CertPath and KeyPath are where the certificate and private key are written
+	CertPath string
+	KeyPath  string
+}
+
+var _ fi.Task = &BootstrapClientTask{}
+var _ fi.HasName = &BootstrapClientTask{}
+
+func (e *BootstrapClientTask) String() string {
+	return fmt.Sprintf("BootstrapClient: %s", e.Name)
+}
+
+func (e *BootstrapClientTask) GetName() *string {
+	return &e.Name
+}
+
+func (e *BootstrapClientTask) SetName(name string) {
+	glog.Fatalf("SetName not supported for BootstrapClient task")
+}
+
+// Find returns the task when we hold a certificate which is not about to expire
+func (e *BootstrapClientTask) Find(c *fi.Context) (*BootstrapClientTask, error) {
+	if _, err := os.Stat(e.KeyPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error checking private key %q: %v", e.KeyPath, err)
+	}
+
+	data, err := ioutil.ReadFile(e.CertPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading certificate %q: %v", e.CertPath, err)
+	}
+	certificate, err := pki.ParsePEMCertificate(data)
+	if err != nil {
+		glog.Warningf("ignoring unparseable certificate %q: %v", e.CertPath, err)
+		return nil, nil
+	}
+	if time.Now().Add(renewBefore).After(certificate.Certificate.NotAfter) {
+		glog.Infof("certificate %q expires at %v, renewing", e.CertPath, certificate.Certificate.NotAfter)
+		return nil, nil
+	}
+
+	actual := *e
+	return &actual, nil
+}
+
+func (e *BootstrapClientTask) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *BootstrapClientTask) CheckChanges(a, e, changes *BootstrapClientTask) error {
+	if e.Server == "" {
+		return fi.RequiredField("Server")
+	}
+	return nil
+}
+
+func (_ *BootstrapClientTask) RenderLocal(t *local.LocalTarget, a, e, changes *BootstrapClientTask) error {
+	if a != nil {
+		return nil
+	}
+
+	key, err := pki.GeneratePrivateKey()
+	if err != nil {
+		return err
+	}
+
+	client := &bootstrap.Client{
+		Server:   e.Server,
+		CA:       []byte(e.CA),
+		Identity: bootstrap.AWSIdentityDocument,
+	}
+	response, err := client.Bootstrap(key.Key.(*rsa.PrivateKey).Public())
+	if err != nil {
+		return err
+	}
+	glog.Infof("kops-controller issued a kubelet certificate for node %q", response.NodeName)
+
+	keyData, err := key.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	// the key is written first, so that Find never sees a certificate without its key
+	if err := writeFileAtomically(e.KeyPath, keyData, 0600); err != nil {
+		return err
+	}
+	return writeFileAtomically(e.CertPath, []byte(response.Certificate), 0644)
+}
+
+func (_ *BootstrapClientTask) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *BootstrapClientTask) error {
+	return fmt.Errorf("BootstrapClientTask::RenderCloudInit not implemented")
+}
+
+// writeFileAtomically writes the file through a temporary file in the same directory
+func writeFileAtomically(p string, data []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("error creating directory for %q: %v", p, err)
+	}
+	tmp := p + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("error writing %q: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("error renaming %q to %q: %v", tmp, p, err)
+	}
+	return nil
+}
@@ -38,6 +38,11 @@ type MountDiskTask struct {
 
 	Device     string `json:"device"`
 	Mountpoint string `json:"mountpoint"`
+
+	// Filesystem is the filesystem to format the device with, if it is not already formatted (defaults to ext4)
+	Filesystem string `json:"filesystem,omitempty"`
+	// MountOptions are the options used to mount the device
+	MountOptions []string `json:"mountOptions,omitempty"`
 }
 
 var _ fi.Task = &MountDiskTask{}
@@ -46,6 +51,16 @@ func (s *MountDiskTask) String() string {
 	return fmt.Sprintf("MountDisk: %s %s->%s", s.Name, s.Device, s.Mountpoint)
 }
 
+var _ fi.HasName = &MountDiskTask{}
+
+func (e *MountDiskTask) GetName() *string {
+	return &e.Name
+}
+
+func (e *MountDiskTask) SetName(name string) {
+	e.Name = name
+}
+
 var _ CreatesDir = &MountDiskTask{}
 
 // Dir implements CreatesDir::Dir
@@ -64,6 +79,19 @@ func (e *MountDiskTask) GetDependencies(tasks map[string]fi.Task) []fi.Task {
 		deps = append(deps, v)
 	}
 
+	for _, v := range tasks {
+		switch v := v.(type) {
+		case *Package:
+			// Requires the filesystem tools to be installed
+			deps = append(deps, v)
+		case *RaidArray:
+			// Requires the array to be assembled
+			if v.Device == e.Device {
+				deps = append(deps, v)
+			}
+		}
+	}
+
 	return deps
 }
 
@@ -88,6 +116,10 @@ func (e *MountDiskTask) Find(c *fi.Context) (*MountDiskTask, error) {
 
 	// If device is a symlink, it will show up by its final name
 	targetDevice, err := filepath.EvalSymlinks(e.Device)
+	if os.IsNotExist(err) {
+		// Not yet attached
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error resolving device symlinks for %q: %v", e.Device, err)
 	}
@@ -99,6 +131,10 @@ func (e *MountDiskTask) Find(c *fi.Context) (*MountDiskTask, error) {
 				Name:       e.Name,
 				Mountpoint: mp.Path,
 				Device:     e.Device, // Use our alias, to keep change detection happy
+
+				// The device is not reformatted or remounted
+				Filesystem:   e.Filesystem,
+				MountOptions: e.MountOptions,
 			}
 			return actual, nil
 		}
@@ -144,8 +180,11 @@ func (_ *MountDiskTask) RenderLocal(t *local.LocalTarget, a, e, changes *MountDi
 
 		mounter := &mount.SafeFormatAndMount{Interface: mount.New(""), Exec: mount.NewOsExec()}
 
-		fstype := ""
-		options := []string{}
+		fstype := e.Filesystem
+		options := e.MountOptions
+		if options == nil {
+			options = []string{}
+		}
 
 		err := mounter.FormatAndMount(e.Device, e.Mountpoint, fstype, options)
 		if err != nil {
@@ -0,0 +1,123 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+)
+
+const (
+	// pullImageAttempts is the number of times we try to pull an image before giving up
+	pullImageAttempts = 3
+	// pullImageRetryInterval is the time we wait between attempts to pull an image
+	pullImageRetryInterval = 10 * time.Second
+)
+
+// PullImageTask is responsible for pulling a docker image from its registry, so that it is
+// already present when the kubelet starts pods that use it
+type PullImageTask struct {
+	Image string
+}
+
+var _ fi.Task = &PullImageTask{}
+var _ fi.HasDependencies = &PullImageTask{}
+var _ fi.HasName = &PullImageTask{}
+
+func (t *PullImageTask) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	// PullImageTask depends on the docker service, as with LoadImageTask
+	var deps []fi.Task
+	for _, v := range tasks {
+		if svc, ok := v.(*Service); ok && svc.Name == dockerService {
+			deps = append(deps, v)
+		}
+	}
+	return deps
+}
+
+func (t *PullImageTask) GetName() *string {
+	return &t.Image
+}
+
+func (t *PullImageTask) SetName(name string) {
+	glog.Fatalf("SetName not supported for PullImageTask")
+}
+
+func (t *PullImageTask) String() string {
+	return fmt.Sprintf("PullImageTask: %s", t.Image)
+}
+
+func (e *PullImageTask) Find(c *fi.Context) (*PullImageTask, error) {
+	args := []string{"docker", "inspect", "--type=image", e.Image}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Run(); err != nil {
+		// docker inspect fails when the image is not present
+		glog.V(2).Infof("image %q not present: %v", e.Image, err)
+		return nil, nil
+	}
+
+	actual := &PullImageTask{
+		Image: e.Image,
+	}
+	return actual, nil
+}
+
+func (e *PullImageTask) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *PullImageTask) CheckChanges(a, e, changes *PullImageTask) error {
+	if e.Image == "" {
+		return fi.RequiredField("Image")
+	}
+	return nil
+}
+
+func (_ *PullImageTask) RenderLocal(t *local.LocalTarget, a, e, changes *PullImageTask) error {
+	args := []string{"docker", "pull", e.Image}
+	human := strings.Join(args, " ")
+
+	for attempt := 1; attempt <= pullImageAttempts; attempt++ {
+		glog.Infof("running command %s", human)
+		cmd := exec.Command(args[0], args[1:]...)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+
+		glog.Warningf("error pulling docker image with '%s' (attempt %d of %d): %v: %s", human, attempt, pullImageAttempts, err, string(output))
+		if attempt < pullImageAttempts {
+			time.Sleep(pullImageRetryInterval)
+		}
+	}
+
+	// Preloading is an optimization; the kubelet pulls the image itself when it is needed,
+	// so we don't want a registry outage to block the node from joining the cluster
+	glog.Warningf("unable to preload image %q; continuing without it", e.Image)
+	return nil
+}
+
+func (_ *PullImageTask) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *PullImageTask) error {
+	return fmt.Errorf("PullImageTask::RenderCloudInit not implemented")
+}
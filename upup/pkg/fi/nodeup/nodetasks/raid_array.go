@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+)
+
+// RaidArray is responsible for striping devices into a raid0 array with mdadm.
+// An existing array on the devices is assembled, so the data survives a reboot.
+type RaidArray struct {
+	Name string
+
+	// Device is the device of the array, e.g. /dev/md/kops-ephemeral
+	Device string `json:"device"`
+	// Devices are the devices the array is built from
+	Devices []string `json:"devices"`
+}
+
+var _ fi.Task = &RaidArray{}
+
+func (e *RaidArray) String() string {
+	return fmt.Sprintf("RaidArray: %s %s->%s", e.Name, e.Devices, e.Device)
+}
+
+var _ fi.HasName = &RaidArray{}
+
+func (e *RaidArray) GetName() *string {
+	return &e.Name
+}
+
+func (e *RaidArray) SetName(name string) {
+	e.Name = name
+}
+
+var _ fi.HasDependencies = &RaidArray{}
+
+// GetDependencies implements HasDependencies::GetDependencies
+func (e *RaidArray) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+
+	// Requires mdadm to be installed
+	for _, v := range tasks {
+		if _, ok := v.(*Package); ok {
+			deps = append(deps, v)
+		}
+	}
+
+	return deps
+}
+
+func (e *RaidArray) Find(c *fi.Context) (*RaidArray, error) {
+	_, err := os.Stat(e.Device)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error checking for device %q: %v", e.Device, err)
+	}
+
+	actual := &RaidArray{
+		Name:    e.Name,
+		Device:  e.Device,
+		Devices: e.Devices,
+	}
+	return actual, nil
+}
+
+func (e *RaidArray) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *RaidArray) CheckChanges(a, e, changes *RaidArray) error {
+	if a == nil {
+		if e.Device == "" {
+			return fi.RequiredField("Device")
+		}
+		if len(e.Devices) == 0 {
+			return fi.RequiredField("Devices")
+		}
+	}
+	return nil
+}
+
+func (_ *RaidArray) RenderLocal(t *local.LocalTarget, a, e, changes *RaidArray) error {
+	if a != nil {
+		return nil
+	}
+
+	args := append([]string{"--assemble", e.Device}, e.Devices...)
+	glog.Infof("running command mdadm %s", args)
+	output, err := exec.Command("mdadm", args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	glog.V(2).Infof("unable to assemble raid array %q, creating it: %v: %s", e.Device, err, string(output))
+
+	args = append([]string{"--create", e.Device, "--run", "--level=0", "--raid-devices=" + strconv.Itoa(len(e.Devices))}, e.Devices...)
+	glog.Infof("running command mdadm %s", args)
+	output, err = exec.Command("mdadm", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error creating raid array %q: %v: %s", e.Device, err, string(output))
+	}
+
+	return nil
+}
+
+func (_ *RaidArray) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *RaidArray) error {
+	return fmt.Errorf("RaidArray::RenderCloudInit not implemented")
+}
@@ -70,10 +70,16 @@ func (p *Service) GetDependencies(tasks map[string]fi.Task) []fi.Task {
 		// launching a custom Kubernetes build), they all depend on
 		// the "docker.service" Service task.
 		switch v.(type) {
-		case *File, *Package, *UpdatePackages, *UserTask, *GroupTask, *MountDiskTask:
+		case *File, *Package, *UpdatePackages, *UserTask, *GroupTask, *MountDiskTask, *BootstrapClientTask:
 			deps = append(deps, v)
 		case *Service, *LoadImageTask:
 			// ignore
+		case *PullImageTask:
+			// Other services (notably the kubelet) start after images are preloaded,
+			// but the pull itself needs docker to be running
+			if p.Name != dockerService {
+				deps = append(deps, v)
+			}
 		default:
 			glog.Warningf("Unhandled type %T in Service::GetDependencies: %v", v, v)
 			deps = append(deps, v)
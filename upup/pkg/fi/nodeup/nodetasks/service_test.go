@@ -37,6 +37,24 @@ func TestServiceTask_Deps(t *testing.T) {
 	}
 }
 
+func TestServiceTask_PullImageDeps(t *testing.T) {
+	tasks := make(map[string]fi.Task)
+	tasks["PullImageTask1"] = &PullImageTask{Image: "k8s.gcr.io/pause-amd64:3.0"}
+
+	kubelet := &Service{Name: "kubelet.service"}
+	deps := kubelet.GetDependencies(tasks)
+	expected := []fi.Task{tasks["PullImageTask1"]}
+	if !reflect.DeepEqual(expected, deps) {
+		t.Fatalf("unexpected deps.  expected=%v, actual=%v", expected, deps)
+	}
+
+	docker := &Service{Name: dockerService}
+	deps = docker.GetDependencies(tasks)
+	if len(deps) != 0 {
+		t.Fatalf("docker service should not depend on image pulls, actual=%v", deps)
+	}
+}
+
 type FakeTask struct {
 }
 
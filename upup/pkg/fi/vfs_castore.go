@@ -598,6 +598,9 @@ func (c *VFSCAStore) ListSSHCredentials() ([]*kops.SSHCredential, error) {
 		baseDir := c.basedir.Join("ssh", "public")
 		files, err := baseDir.ReadTree()
 		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
 			return nil, fmt.Errorf("error reading directory %q: %v", baseDir, err)
 		}
 
@@ -933,6 +936,10 @@ func (c *VFSCAStore) FindPrivateKeyset(name string) (*kops.Keyset, error) {
 		return nil, err
 	}
 
+	if keys == nil {
+		return nil, nil
+	}
+
 	o, err := keys.ToAPIObject(name, true)
 	if err != nil {
 		return nil, err
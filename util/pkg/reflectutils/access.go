@@ -0,0 +1,258 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reflectutils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetString sets the field identified by targetPath to newValue, parsing newValue according to the type of the field.
+//
+// targetPath is a dotted path of JSON field names, e.g. spec.kubernetesVersion.  Slice elements are addressed
+// with [n], or with [*] to address every element; map values are addressed by their key, e.g. spec.cloudLabels.team.
+// Nil pointers and maps along the path are allocated.  Setting a slice of primitive values appends newValue.
+func SetString(target interface{}, targetPath string, newValue string) error {
+	steps, err := parsePath(targetPath)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("cannot set field on non-pointer %T", target)
+	}
+
+	return setPath(v, "", steps, newValue)
+}
+
+// parsePath splits a path like spec.etcdClusters[*].version into its steps: spec, etcdClusters, [*], version
+func parsePath(targetPath string) ([]string, error) {
+	var steps []string
+	for _, token := range strings.Split(targetPath, ".") {
+		name := token
+		var indexes []string
+		for {
+			open := strings.Index(name, "[")
+			if open == -1 {
+				break
+			}
+			end := strings.Index(name[open:], "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated index in %q", targetPath)
+			}
+			indexes = append(indexes, name[open:open+end+1])
+			name = name[:open] + name[open+end+1:]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("empty field name in %q", targetPath)
+		}
+		steps = append(steps, name)
+		steps = append(steps, indexes...)
+	}
+	return steps, nil
+}
+
+func setPath(v reflect.Value, path string, steps []string, newValue string) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return fmt.Errorf("cannot set nil value at %q", path)
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if len(steps) == 0 {
+		if err := setValue(v, newValue); err != nil {
+			return fmt.Errorf("cannot set %q: %v", path, err)
+		}
+		return nil
+	}
+
+	step := steps[0]
+	if strings.HasPrefix(step, "[") {
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return fmt.Errorf("cannot index %s at %q", v.Type(), path)
+		}
+		index := strings.TrimSuffix(strings.TrimPrefix(step, "["), "]")
+		if index == "*" {
+			for i := 0; i < v.Len(); i++ {
+				if err := setPath(v.Index(i), fmt.Sprintf("%s[%d]", path, i), steps[1:], newValue); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			return fmt.Errorf("invalid index %q at %q", index, path)
+		}
+		if i < 0 || i >= v.Len() {
+			return fmt.Errorf("index %d out of range at %q (length %d)", i, path, v.Len())
+		}
+		return setPath(v.Index(i), path+step, steps[1:], newValue)
+	}
+
+	childPath := step
+	if path != "" {
+		childPath = path + "." + step
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		f, found := findJSONField(v, step)
+		if !found {
+			return fmt.Errorf("field %q not found in %s", childPath, v.Type())
+		}
+		return setPath(f, childPath, steps[1:], newValue)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot address map with key type %s at %q", v.Type().Key(), path)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(step).Convert(v.Type().Key())
+		// Map values are not addressable, so we work on a copy and store it back
+		mv := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			mv.Set(existing)
+		}
+		if err := setPath(mv, childPath, steps[1:], newValue); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, mv)
+		return nil
+
+	default:
+		return fmt.Errorf("field %q not found: %s has no fields", childPath, v.Type())
+	}
+}
+
+// findJSONField returns the field of the struct v with the specified JSON name, searching inlined fields
+func findJSONField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if structField.PkgPath != "" {
+			// Field not exported
+			continue
+		}
+
+		jsonName := structField.Name
+		inline := false
+		if tag := structField.Tag.Get("json"); tag != "" {
+			tokens := strings.Split(tag, ",")
+			if tokens[0] == "-" {
+				continue
+			}
+			if tokens[0] != "" {
+				jsonName = tokens[0]
+			}
+			for _, token := range tokens[1:] {
+				if token == "inline" {
+					inline = true
+				}
+			}
+		}
+
+		if inline || (structField.Anonymous && structField.Tag.Get("json") == "") {
+			f := v.Field(i)
+			if f.Kind() == reflect.Struct {
+				if found, ok := findJSONField(f, name); ok {
+					return found, true
+				}
+			}
+			continue
+		}
+
+		if jsonName == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func setValue(v reflect.Value, newValue string) error {
+	if !v.CanSet() {
+		return fmt.Errorf("value is not settable")
+	}
+
+	if v.Type() == reflect.TypeOf(metav1.Duration{}) {
+		d, err := time.ParseDuration(newValue)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %v", newValue, err)
+		}
+		v.Set(reflect.ValueOf(metav1.Duration{Duration: d}))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(newValue)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(newValue)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", newValue)
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(newValue, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", newValue)
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(newValue, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", newValue)
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(newValue, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", newValue)
+		}
+		v.SetFloat(n)
+
+	case reflect.Slice:
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if elem.Kind() == reflect.Struct || elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map {
+			return fmt.Errorf("cannot append to slice of %s", v.Type().Elem())
+		}
+		if err := setValue(elem, newValue); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+
+	default:
+		return fmt.Errorf("unhandled type %s", v.Type())
+	}
+	return nil
+}